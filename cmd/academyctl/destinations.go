@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/destination"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// runDestinationStart opens a dual-write validation window towards a second spreadsheet. The
+// engine only supports Google Sheets candidates, so migrations are Sheets to Sheets.
+func runDestinationStart(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("destination start", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "ID of the user")
+	spreadsheetID := flags.String("spreadsheet", "", "ID of the spreadsheet the user is moving to")
+	days := flags.Int("days", 14, "length of the validation window in days")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *userID <= 0 || *spreadsheetID == "" {
+		fmt.Fprintln(os.Stderr, "-user and -spreadsheet are required")
+		return exitUsage
+	}
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "-days must be at least 1")
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	until := time.Now().Add(time.Duration(*days) * 24 * time.Hour).UTC()
+	err := container.UserRepository.StartDestinationMigration(context.Background(), *userID, destination.TypeGoogleSheets, *spreadsheetID, until)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "user %d not found\n", *userID)
+		return exitFailure
+	}
+	if err != nil {
+		log.Critical("Failed to start destination migration", "user_id", *userID, "error", err.Error())
+		return exitFailure
+	}
+
+	log.Info("Started destination migration",
+		"user_id", *userID,
+		"pending_destination_id", *spreadsheetID,
+		"dual_write_until", until)
+	fmt.Printf("user %d: writing to both spreadsheets until %s\n", *userID, until.Format(time.RFC3339))
+	return exitOK
+}
+
+// runDestinationClear closes the user's dual-write validation window without cutting over
+func runDestinationClear(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("destination clear", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "ID of the user")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *userID <= 0 {
+		fmt.Fprintln(os.Stderr, "-user is required")
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	err := container.UserRepository.ClearDestinationMigration(context.Background(), *userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "user %d not found\n", *userID)
+		return exitFailure
+	}
+	if err != nil {
+		log.Critical("Failed to clear destination migration", "user_id", *userID, "error", err.Error())
+		return exitFailure
+	}
+
+	log.Info("Cleared destination migration", "user_id", *userID)
+	fmt.Printf("user %d: destination migration cleared\n", *userID)
+	return exitOK
+}
@@ -0,0 +1,133 @@
+// Command academyctl runs routine operator tasks against the database and job queue, so they
+// do not require psql or redis-cli.
+//
+// Usage:
+//
+//	academyctl users list [-after <id>] [-limit 50] [-json]
+//	academyctl users disable-automation -user <id>
+//	academyctl sync trigger -user <id> [-dry-run]
+//	academyctl destination start -user <id> -spreadsheet <id> [-days 14]
+//	academyctl destination clear -user <id>
+//	academyctl queue stats [-dead-letters <n>]
+//	academyctl queue requeue-dlq [-limit 100]
+//	academyctl tokens re-encrypt [-batch-size 100]
+//	academyctl migrate [up|status]
+//
+// It reads the same configuration as the services. tokens re-encrypt rewrites the stored OAuth
+// tokens and webhook secrets under ENCRYPTION_SECRET after it was rotated; the previous secret
+// is read from PREVIOUS_ENCRYPTION_SECRET so it never appears in the shell history.
+//
+// destination start opens a validation window in which the engine writes to the user's
+// spreadsheet and a second one and reports differences; destination clear closes it. Only
+// Google Sheets to Google Sheets migrations are supported.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitUsage   = 1
+	exitFailure = 2
+)
+
+// command runs one subcommand with its arguments and returns the exit code
+type command func(cfg *config.Config, log *logger.Logger, args []string) int
+
+var commands = map[string]map[string]command{
+	"users": {
+		"list":               runUsersList,
+		"disable-automation": runUsersDisableAutomation,
+	},
+	"sync": {
+		"trigger": runSyncTrigger,
+	},
+	"destination": {
+		"start": runDestinationStart,
+		"clear": runDestinationClear,
+	},
+	"queue": {
+		"stats":       runQueueStats,
+		"requeue-dlq": runQueueRequeueDLQ,
+	},
+	"tokens": {
+		"re-encrypt": runTokensReEncrypt,
+	},
+	"migrate": {
+		"up":     runMigrateUp,
+		"status": runMigrateStatus,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+	group, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	// migrate defaults to up; every other group needs a subcommand
+	name, args := "", os.Args[2:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	} else if os.Args[1] == "migrate" {
+		name = "up"
+	}
+	run, ok := group[name]
+	if !ok {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to load configuration: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if _, stop := app.CheckServiceConfig(cfg, config.ServiceAcademyctl, false); stop {
+		os.Exit(exitFailure)
+	}
+	log := app.NewLogger(cfg, "academyctl")
+
+	os.Exit(run(cfg, log, args))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  academyctl users list [-after <id>] [-limit 50] [-json]")
+	fmt.Fprintln(os.Stderr, "  academyctl users disable-automation -user <id>")
+	fmt.Fprintln(os.Stderr, "  academyctl sync trigger -user <id> [-dry-run]")
+	fmt.Fprintln(os.Stderr, "  academyctl destination start -user <id> -spreadsheet <id> [-days 14]")
+	fmt.Fprintln(os.Stderr, "  academyctl destination clear -user <id>")
+	fmt.Fprintln(os.Stderr, "  academyctl queue stats [-dead-letters <n>]")
+	fmt.Fprintln(os.Stderr, "  academyctl queue requeue-dlq [-limit 100]")
+	fmt.Fprintln(os.Stderr, "  academyctl tokens re-encrypt [-batch-size 100]")
+	fmt.Fprintln(os.Stderr, "  academyctl migrate [up|status]")
+}
+
+// openContainer connects to the database with the shared repositories
+func openContainer(cfg *config.Config, log *logger.Logger) (*app.Container, bool) {
+	container, err := app.Open(cfg, log, app.ProfileMaintenance)
+	if err != nil {
+		log.Critical("Failed to initialize academyctl dependencies", "error", err.Error())
+		return nil, false
+	}
+	return container, true
+}
+
+func printJSON(value interface{}) {
+	output, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(output))
+}
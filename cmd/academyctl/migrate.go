@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// runMigrateUp applies the migrations built into the binary that the database has not applied
+func runMigrateUp(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	applied, err := database.NewMigrator(container.DB).Up(context.Background())
+	for _, migration := range applied {
+		fmt.Printf("applied %d: %v\n", migration.Version, migration.Files)
+	}
+	if err != nil {
+		log.Critical("Migration failed", "error", err.Error())
+		return exitFailure
+	}
+	if len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
+	return exitOK
+}
+
+// runMigrateStatus prints the applied schema version and the pending migrations
+func runMigrateStatus(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	ctx := context.Background()
+	migrator := database.NewMigrator(container.DB)
+	version, dirty, err := migrator.Version(ctx)
+	if err != nil {
+		log.Critical("Failed to read schema version", "error", err.Error())
+		return exitFailure
+	}
+
+	output := map[string]interface{}{"version": version, "dirty": dirty}
+	if !dirty {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			log.Critical("Failed to list pending migrations", "error", err.Error())
+			return exitFailure
+		}
+		versions := []uint{}
+		for _, migration := range pending {
+			versions = append(versions, migration.Version)
+		}
+		output["pending"] = versions
+	}
+
+	printJSON(output)
+	if dirty {
+		return exitFailure
+	}
+	return exitOK
+}
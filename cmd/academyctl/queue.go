@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+// openJobQueue connects to the database and the job queue
+func openJobQueue(cfg *config.Config, log *logger.Logger) (*app.Container, *queue.Client, bool) {
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return nil, nil, false
+	}
+	jobQueue, err := container.ConnectJobQueue()
+	if err != nil {
+		log.Critical("Job queue unavailable", "error", err.Error())
+		container.Close()
+		return nil, nil, false
+	}
+	return container, jobQueue, true
+}
+
+// runSyncTrigger enqueues a manual sync for the user and prints its trace ID
+func runSyncTrigger(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("sync trigger", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "ID of the user to sync")
+	dryRun := flags.Bool("dry-run", false, "report the rows the sync would write without writing them")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *userID <= 0 {
+		fmt.Fprintln(os.Stderr, "-user is required")
+		return exitUsage
+	}
+
+	container, jobQueue, ok := openJobQueue(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	ctx := context.Background()
+	user, err := container.UserRepository.GetUserByID(ctx, *userID)
+	if err != nil {
+		log.Critical("Failed to read user", "user_id", *userID, "error", err.Error())
+		return exitFailure
+	}
+	if user == nil {
+		fmt.Fprintf(os.Stderr, "user %d not found\n", *userID)
+		return exitFailure
+	}
+
+	job := &queue.Job{
+		UserID:      *userID,
+		TriggerType: queue.TriggerManualSync,
+		DryRun:      *dryRun,
+	}
+	if err := jobQueue.Enqueue(ctx, job); err != nil {
+		log.Critical("Failed to enqueue sync", "user_id", *userID, "error", err.Error())
+		return exitFailure
+	}
+
+	fmt.Printf("user %d: sync enqueued with trace ID %s\n", *userID, job.TraceID)
+	return exitOK
+}
+
+// runQueueStats prints how many jobs are queued, deferred and dead-lettered
+func runQueueStats(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("queue stats", flag.ContinueOnError)
+	deadLetters := flags.Int("dead-letters", 0, "also print up to this many dead-lettered jobs, oldest first")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	container, jobQueue, ok := openJobQueue(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	ctx := context.Background()
+	stats, err := jobQueue.Stats(ctx)
+	if err != nil {
+		log.Critical("Failed to read queue stats", "error", err.Error())
+		return exitFailure
+	}
+
+	output := map[string]interface{}{"stats": stats}
+	if *deadLetters > 0 {
+		entries, err := jobQueue.ListDeadLetters(ctx, *deadLetters)
+		if err != nil {
+			log.Critical("Failed to list dead letters", "error", err.Error())
+			return exitFailure
+		}
+		output["dead_letters"] = entries
+	}
+
+	if maintenance, err := jobQueue.GetMaintenance(ctx); err != nil {
+		log.Warn("Failed to read maintenance state", "error", err.Error())
+	} else if maintenance != nil {
+		output["maintenance"] = maintenance
+	}
+
+	printJSON(output)
+	return exitOK
+}
+
+// runQueueRequeueDLQ moves dead-lettered jobs back onto the queue
+func runQueueRequeueDLQ(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("queue requeue-dlq", flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "maximum number of jobs to requeue, oldest first")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *limit < 1 {
+		fmt.Fprintln(os.Stderr, "-limit must be at least 1")
+		return exitUsage
+	}
+
+	container, jobQueue, ok := openJobQueue(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	requeued, err := jobQueue.RequeueDeadLetters(context.Background(), *limit)
+	if err != nil {
+		// Jobs requeued before the failure stay queued
+		log.Critical("Failed to requeue dead-lettered jobs", "requeued", requeued, "error", err.Error())
+		return exitFailure
+	}
+
+	fmt.Printf("requeued %d dead-lettered job(s)\n", requeued)
+	return exitOK
+}
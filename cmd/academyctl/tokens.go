@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// previousSecretEnv holds the encryption secret in use before the rotation
+const previousSecretEnv = "PREVIOUS_ENCRYPTION_SECRET"
+
+// runTokensReEncrypt rewrites every stored secret under the current ENCRYPTION_SECRET, one
+// transaction per batch of users
+func runTokensReEncrypt(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("tokens re-encrypt", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", 100, "users re-encrypted per transaction")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *batchSize < 1 {
+		fmt.Fprintln(os.Stderr, "-batch-size must be at least 1")
+		return exitUsage
+	}
+
+	previousSecret := os.Getenv(previousSecretEnv)
+	if cfg.EncryptionSecret == "" || previousSecret == "" {
+		fmt.Fprintf(os.Stderr, "ENCRYPTION_SECRET (the new secret) and %s (the old one) are required\n", previousSecretEnv)
+		return exitUsage
+	}
+	if previousSecret == cfg.EncryptionSecret {
+		fmt.Fprintf(os.Stderr, "%s is the same as ENCRYPTION_SECRET; nothing to re-encrypt\n", previousSecretEnv)
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	previous := auth.NewEncryptionService(previousSecret)
+	total := database.ReEncryptResult{}
+	for {
+		result, err := container.UserRepository.ReEncryptSecrets(context.Background(), previous, total.LastUserID, *batchSize)
+		if err != nil {
+			// Batches committed so far stay re-encrypted; running the command again resumes
+			log.Critical("Failed to re-encrypt secrets",
+				"after_user_id", total.LastUserID,
+				"error", err.Error())
+			printJSON(total)
+			return exitFailure
+		}
+
+		total.Users += result.Users
+		total.ReEncrypted += result.ReEncrypted
+		total.Current += result.Current
+		total.LastUserID = result.LastUserID
+		log.Info("Re-encrypted batch",
+			"users", result.Users,
+			"re_encrypted", result.ReEncrypted,
+			"last_user_id", result.LastUserID)
+
+		if result.Users < *batchSize {
+			break
+		}
+	}
+
+	printJSON(total)
+	return exitOK
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// runUsersList prints a page of users in ID order
+func runUsersList(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("users list", flag.ContinueOnError)
+	after := flags.Int("after", 0, "list users with an ID above this one")
+	limit := flags.Int("limit", 50, "maximum number of users to list")
+	asJSON := flags.Bool("json", false, "print the users as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *limit < 1 {
+		fmt.Fprintln(os.Stderr, "-limit must be at least 1")
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	users, err := container.UserRepository.ListUsers(context.Background(), *after, *limit)
+	if err != nil {
+		log.Critical("Failed to list users", "error", err.Error())
+		return exitFailure
+	}
+	if *asJSON {
+		printJSON(users)
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tROLE\tAUTOMATION\tSTRAVA\tSHEET\tSUSPENDED\tLAST LOGIN")
+	for _, user := range users {
+		lastLogin := "-"
+		if user.LastLoginAt != nil {
+			lastLogin = user.LastLoginAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%t\t%t\t%t\t%s\n", user.ID, user.Email, user.Role,
+			user.AutomationEnabled, user.StravaConnected, user.SheetConfigured, user.Suspended, lastLogin)
+	}
+	w.Flush()
+
+	if len(users) == *limit {
+		fmt.Fprintf(os.Stderr, "more users may follow: -after %d\n", users[len(users)-1].ID)
+	}
+	return exitOK
+}
+
+// runUsersDisableAutomation stops the user's scheduled syncs
+func runUsersDisableAutomation(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("users disable-automation", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "ID of the user")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *userID <= 0 {
+		fmt.Fprintln(os.Stderr, "-user is required")
+		return exitUsage
+	}
+
+	container, ok := openContainer(cfg, log)
+	if !ok {
+		return exitFailure
+	}
+	defer container.Close()
+
+	disabled, err := container.UserRepository.DisableAutomation(context.Background(), *userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "user %d not found\n", *userID)
+		return exitFailure
+	}
+	if err != nil {
+		log.Critical("Failed to disable automation", "user_id", *userID, "error", err.Error())
+		return exitFailure
+	}
+
+	if disabled {
+		log.Info("Disabled automation", "user_id", *userID)
+		fmt.Printf("user %d: automation disabled\n", *userID)
+	} else {
+		fmt.Printf("user %d: automation was already disabled\n", *userID)
+	}
+	return exitOK
+}
@@ -0,0 +1,341 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/handlers"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/server"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/devstub"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// The integration suite runs backend-api and the automation engine in-process against Postgres
+// and Redis containers, with Strava and Google Sheets served by the devstub provider server:
+//
+//	go test -tags integration ./cmd/automation-engine/...
+//
+// It requires Docker and is skipped when no container runtime is reachable.
+
+const (
+	integrationSpreadsheetID = "integration-spreadsheet"
+	integrationPollTimeout   = 30 * time.Second
+)
+
+// integrationEnv is a running stack: the API server, the engine's containers and the provider stub
+type integrationEnv struct {
+	cfg     *config.Config
+	log     *logger.Logger
+	api     *httptest.Server
+	backend *app.Container
+	engine  *app.Container
+	stub    *devstub.Server
+}
+
+func TestIntegration_ManualSync(t *testing.T) {
+	env := startIntegrationEnv(t, seededActivities(time.Now().UTC()))
+	ctx := context.Background()
+
+	userID := env.seedUser(t)
+	token := env.createAPIToken(t, userID, authz.ScopeSync, authz.ScopeRead)
+
+	// Trigger a manual sync through the API as a script would
+	var triggered handlers.TriggerSyncResponse
+	status := env.do(t, http.MethodPost, "/api/v1/sync", token, &triggered)
+	if status != http.StatusAccepted {
+		t.Fatalf("Expected 202 from POST /api/v1/sync, got %d", status)
+	}
+	if triggered.TraceID == "" {
+		t.Fatal("Expected a trace ID for the queued sync")
+	}
+
+	// The outbox relay publishes the job; the engine picks it up from Redis
+	jobQueue, err := env.engine.ConnectJobQueue()
+	if err != nil {
+		t.Fatalf("Failed to connect the engine to the job queue: %v", err)
+	}
+	worker, responseCache := newWorker(ctx, env.cfg, env.engine, env.log)
+	useJobQueue(worker, jobQueue, responseCache, env.cfg, env.engine, env.log)
+
+	job := dequeueJob(t, jobQueue)
+	if job.TraceID != triggered.TraceID || job.UserID != userID || job.TriggerType != queue.TriggerManualSync {
+		t.Fatalf("Unexpected job dequeued: %+v", job)
+	}
+	processJob(jobQueue, worker, env.engine.RunRepository, job, env.cfg.Engine, env.log)
+
+	// The result is polled through the API
+	var result queue.JobResult
+	if status := env.do(t, http.MethodGet, "/api/v1/sync/"+triggered.TraceID, token, &result); status != http.StatusOK {
+		t.Fatalf("Expected 200 from GET /api/v1/sync/{traceID}, got %d", status)
+	}
+	if result.Status != queue.JobStatusCompleted {
+		t.Fatalf("Expected the sync to complete, got %s: %s", result.Status, result.Result)
+	}
+	var processed struct {
+		Success         bool `json:"success"`
+		ActivitiesCount int  `json:"activities_count"`
+	}
+	if err := json.Unmarshal(result.Result, &processed); err != nil {
+		t.Fatalf("Failed to decode the job result: %v", err)
+	}
+	if !processed.Success || processed.ActivitiesCount != 2 {
+		t.Errorf("Expected 2 activities synced, got %s", result.Result)
+	}
+
+	// Both activities were written below the template header
+	rows := env.stub.Rows(integrationSpreadsheetID, "Sheet1")
+	if len(rows) != 3 {
+		t.Fatalf("Expected a header and 2 activity rows, got %d rows: %v", len(rows), rows)
+	}
+	written := fmt.Sprint(rows[1:])
+	for _, name := range []string{"Morning Run", "Evening Tempo"} {
+		if !strings.Contains(written, name) {
+			t.Errorf("Expected a row for %q, got %v", name, rows[1:])
+		}
+	}
+
+	// The run is recorded as a completed, non-test run
+	var runStatus string
+	var isTestMode bool
+	err = env.engine.DB.QueryRowContext(ctx,
+		`SELECT status, is_test_mode FROM automation_runs WHERE trace_id = $1`, triggered.TraceID).
+		Scan(&runStatus, &isTestMode)
+	if err != nil {
+		t.Fatalf("Failed to read the recorded run: %v", err)
+	}
+	if runStatus != database.RunStatusCompleted || isTestMode {
+		t.Errorf("Expected a completed non-test run, got status %q (test mode %v)", runStatus, isTestMode)
+	}
+}
+
+// startIntegrationEnv starts Postgres (with every migration applied), Redis, the provider stub
+// and the backend API, and opens the engine's container
+func startIntegrationEnv(t *testing.T, activities []strava.Activity) *integrationEnv {
+	t.Helper()
+	skipWithoutDocker(t)
+	ctx := context.Background()
+
+	migrations, err := filepath.Glob("../../internal/pkg/database/migrations/*.up.sql")
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("Failed to find migrations: %v", err)
+	}
+	pg, err := postgres.Run(ctx, "postgres:15-alpine",
+		postgres.WithDatabase("academy_sync"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.WithInitScripts(migrations...),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute)),
+	)
+	testcontainers.CleanupContainer(t, pg)
+	if err != nil {
+		t.Fatalf("Failed to start Postgres: %v", err)
+	}
+	databaseURL, err := pg.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to get the Postgres connection string: %v", err)
+	}
+
+	rdb, err := redis.Run(ctx, "redis:7-alpine")
+	testcontainers.CleanupContainer(t, rdb)
+	if err != nil {
+		t.Fatalf("Failed to start Redis: %v", err)
+	}
+	redisURL, err := rdb.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the Redis connection string: %v", err)
+	}
+
+	stub := devstub.New(devstub.Options{Activities: activities}, logger.New("devstub"))
+	stubServer := httptest.NewServer(stub.Handler())
+	t.Cleanup(stubServer.Close)
+
+	t.Setenv("APP_ENV", "local")
+	t.Setenv("DATABASE_URL", databaseURL)
+	t.Setenv("REDIS_URL", redisURL)
+	t.Setenv("JWT_SECRET", "integration-jwt-secret")
+	t.Setenv("ENCRYPTION_SECRET", "integration-encryption-secret-0123456789")
+	t.Setenv("GOOGLE_CLIENT_ID", "integration-google-client")
+	t.Setenv("GOOGLE_CLIENT_SECRET", "integration-google-secret")
+	t.Setenv("STRAVA_CLIENT_ID", "integration-strava-client")
+	t.Setenv("STRAVA_CLIENT_SECRET", "integration-strava-secret")
+	t.Setenv("PROVIDER_STUB_URL", stubServer.URL)
+	t.Setenv("API_OUTBOX_RELAY_INTERVAL", "100ms")
+	t.Setenv("ENGINE_QUEUE_POLL_TIMEOUT", "1s")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	log := logger.New("integration-test")
+
+	backend, err := app.Open(cfg, log, app.ProfileBackendAPI)
+	if err != nil {
+		t.Fatalf("Failed to open the backend container: %v", err)
+	}
+	t.Cleanup(backend.Close)
+
+	engine, err := app.Open(cfg, log, app.ProfileAutomationEngine)
+	if err != nil {
+		t.Fatalf("Failed to open the engine container: %v", err)
+	}
+	t.Cleanup(engine.Close)
+
+	// Background work of the API (the outbox relay among it) stops with the test
+	apiCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	api := httptest.NewServer(server.NewRouter(apiCtx, cfg, backend, log))
+	t.Cleanup(api.Close)
+
+	return &integrationEnv{cfg: cfg, log: log, api: api, backend: backend, engine: engine, stub: stub}
+}
+
+// skipWithoutDocker skips the test when no container runtime is reachable; the provider lookup
+// panics instead of failing when it finds no Docker host at all
+func skipWithoutDocker(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("Docker is not available: %v", r)
+		}
+	}()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+}
+
+// seedUser stores a user connected to both providers with valid tokens, a spreadsheet and a
+// timezone, with automation enabled
+func (e *integrationEnv) seedUser(t *testing.T) int {
+	t.Helper()
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	user, err := e.backend.UserRepository.CreateUser(ctx, &database.CreateUserRequest{
+		GoogleID:           "integration-google-id",
+		Email:              "athlete@example.com",
+		Name:               "Integration Athlete",
+		GoogleAccessToken:  "google-access-token",
+		GoogleRefreshToken: "google-refresh-token",
+		GoogleTokenExpiry:  &expiry,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	err = e.backend.UserRepository.UpdateStravaConnection(ctx, &database.UpdateStravaConnectionRequest{
+		UserID:       user.ID,
+		AccessToken:  "strava-access-token",
+		RefreshToken: "strava-refresh-token",
+		TokenExpiry:  &expiry,
+		AthleteID:    4242,
+		AthleteName:  "Integration Athlete",
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect Strava: %v", err)
+	}
+	if err := e.backend.UserRepository.UpdateSpreadsheetID(ctx, user.ID, integrationSpreadsheetID); err != nil {
+		t.Fatalf("Failed to set the spreadsheet: %v", err)
+	}
+	if _, err := e.backend.DB.ExecContext(ctx,
+		`UPDATE users SET automation_enabled = true, timezone = 'UTC' WHERE id = $1`, user.ID); err != nil {
+		t.Fatalf("Failed to enable automation: %v", err)
+	}
+	return user.ID
+}
+
+// createAPIToken stores a personal access token for the user and returns its secret
+func (e *integrationEnv) createAPIToken(t *testing.T, userID int, scopes ...authz.Scope) string {
+	t.Helper()
+	token, prefix, hash, err := auth.NewAPIToken()
+	if err != nil {
+		t.Fatalf("Failed to generate API token: %v", err)
+	}
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	err = e.backend.APITokenRepository.CreateAPIToken(context.Background(), &database.APIToken{
+		UserID:      userID,
+		Name:        "integration",
+		TokenHash:   hash,
+		TokenPrefix: prefix,
+		Scopes:      names,
+	})
+	if err != nil {
+		t.Fatalf("Failed to store API token: %v", err)
+	}
+	return token
+}
+
+// do sends an authenticated API request, decodes the JSON response into out and returns the status
+func (e *integrationEnv) do(t *testing.T, method, path, token string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, e.api.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("Failed to decode %s %s response: %v", method, path, err)
+	}
+	return resp.StatusCode
+}
+
+// dequeueJob waits for the next job on the queue
+func dequeueJob(t *testing.T, jobQueue *queue.Client) *queue.Job {
+	t.Helper()
+	deadline := time.Now().Add(integrationPollTimeout)
+	for time.Now().Before(deadline) {
+		job, err := jobQueue.Dequeue(context.Background(), time.Second)
+		if err != nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if job != nil {
+			return job
+		}
+	}
+	t.Fatal("Timed out waiting for the sync job to be queued")
+	return nil
+}
+
+// seededActivities are two runs from the last two days, inside the default lookback window
+func seededActivities(now time.Time) []strava.Activity {
+	return []strava.Activity{
+		{
+			ID: 1001, Name: "Morning Run", Type: "Run", SportType: "Run",
+			Distance: 10000, MovingTime: 3000, ElapsedTime: 3100,
+			StartDate: now.Add(-48 * time.Hour), StartDateLocal: now.Add(-48 * time.Hour), Timezone: "(GMT+00:00) UTC",
+		},
+		{
+			ID: 1002, Name: "Evening Tempo", Type: "Run", SportType: "Run",
+			Distance: 8000, MovingTime: 2200, ElapsedTime: 2300,
+			StartDate: now.Add(-24 * time.Hour), StartDateLocal: now.Add(-24 * time.Hour), Timezone: "(GMT+00:00) UTC",
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package processing
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// ActivityCache is the local store of activities fetched from Strava
+type ActivityCache interface {
+	IsCacheFresh(ctx context.Context, userID int, from time.Time, maxAge time.Duration) (bool, error)
+	GetActivitiesInRange(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error)
+	StoreFetchedActivities(ctx context.Context, userID int, from, to, fetchedAt time.Time, activities []strava.Activity) error
+	GetManualActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error)
+}
+
+// activityFetcher fetches a user's activities started after a point in time
+type activityFetcher func(ctx context.Context, after time.Time) ([]strava.Activity, error)
+
+// SetActivityCache enables the local activity cache for step 5.
+// Fetched activities are written to the cache, and runs whose window was refreshed within maxAge
+// (e.g. a manual sync right after a scheduled one) are served from it without calling Strava.
+func (w *Worker) SetActivityCache(cache ActivityCache, maxAge time.Duration) {
+	w.activityCache = cache
+	w.activityCacheMaxAge = maxAge
+}
+
+// loadActivities returns the user's activities since the given time, from the cache when it is fresh
+// and from Strava otherwise. Cache failures are logged and never fail the run.
+func (w *Worker) loadActivities(ctx context.Context, userID int, since time.Time, fetch activityFetcher) ([]strava.Activity, bool, error) {
+	if w.activityCache == nil {
+		activities, err := fetch(ctx, since)
+		return activities, false, err
+	}
+
+	fresh, err := w.activityCache.IsCacheFresh(ctx, userID, since, w.activityCacheMaxAge)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to check activity cache freshness, fetching from Strava",
+			"user_id", userID,
+			"error", err)
+	}
+	if fresh {
+		activities, err := w.activityCache.GetActivitiesInRange(ctx, userID, since, time.Now())
+		if err == nil {
+			return activities, true, nil
+		}
+		w.logger.Warn("⚠️ Failed to read activity cache, fetching from Strava",
+			"user_id", userID,
+			"error", err)
+	}
+
+	fetchedAt := time.Now()
+	activities, err := fetch(ctx, since)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := w.activityCache.StoreFetchedActivities(ctx, userID, since, fetchedAt, fetchedAt, activities); err != nil {
+		w.logger.Warn("⚠️ Failed to write activities to cache",
+			"user_id", userID,
+			"activity_count", len(activities),
+			"error", err)
+	}
+
+	return w.withManualActivities(ctx, userID, since, fetchedAt, activities), false, nil
+}
+
+// withManualActivities merges the activities the user logged by hand in [from, to) into activities
+// fetched from Strava, keeping them ordered by start date. Reads from the cache already include
+// them. A failed read is logged and leaves the manual activities for a later run.
+func (w *Worker) withManualActivities(ctx context.Context, userID int, from, to time.Time, activities []strava.Activity) []strava.Activity {
+	if w.activityCache == nil {
+		return activities
+	}
+
+	manual, err := w.activityCache.GetManualActivities(ctx, userID, from, to)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to read manual activities",
+			"user_id", userID,
+			"error", err)
+		return activities
+	}
+	if len(manual) == 0 {
+		return activities
+	}
+
+	merged := append(append(make([]strava.Activity, 0, len(activities)+len(manual)), activities...), manual...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].StartDate.Before(merged[j].StartDate) })
+	return merged
+}
@@ -0,0 +1,103 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type mockActivityCache struct {
+	fresh    bool
+	cached   []strava.Activity
+	stored   []strava.Activity
+	manual   []strava.Activity
+	storeErr error
+}
+
+func (m *mockActivityCache) IsCacheFresh(ctx context.Context, userID int, from time.Time, maxAge time.Duration) (bool, error) {
+	return m.fresh, nil
+}
+
+func (m *mockActivityCache) GetActivitiesInRange(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	return m.cached, nil
+}
+
+func (m *mockActivityCache) StoreFetchedActivities(ctx context.Context, userID int, from, to, fetchedAt time.Time, activities []strava.Activity) error {
+	m.stored = activities
+	return m.storeErr
+}
+
+func (m *mockActivityCache) GetManualActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	return m.manual, nil
+}
+
+func TestLoadActivities(t *testing.T) {
+	log := logger.New("test")
+	fetched := []strava.Activity{{ID: 1}, {ID: 2}}
+
+	tests := []struct {
+		name              string
+		cache             *mockActivityCache
+		expectedCount     int
+		expectedFromCache bool
+		expectedFetches   int
+	}{
+		{"No cache configured", nil, 2, false, 1},
+		{"Fresh cache", &mockActivityCache{fresh: true, cached: []strava.Activity{{ID: 1}}}, 1, true, 0},
+		{"Stale cache", &mockActivityCache{}, 2, false, 1},
+		{"Cache write failure", &mockActivityCache{storeErr: errors.New("db down")}, 2, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewWorker(nil, "", "", "", "", "", log)
+			if tt.cache != nil {
+				worker.SetActivityCache(tt.cache, time.Minute)
+			}
+
+			fetches := 0
+			fetch := func(ctx context.Context, after time.Time) ([]strava.Activity, error) {
+				fetches++
+				return fetched, nil
+			}
+
+			activities, fromCache, err := worker.loadActivities(context.Background(), 7, time.Now().AddDate(0, 0, -7), fetch)
+			if err != nil {
+				t.Fatalf("loadActivities failed: %v", err)
+			}
+			if len(activities) != tt.expectedCount || fromCache != tt.expectedFromCache || fetches != tt.expectedFetches {
+				t.Errorf("Expected %d activities (from cache %v, %d fetches), got %d (from cache %v, %d fetches)",
+					tt.expectedCount, tt.expectedFromCache, tt.expectedFetches, len(activities), fromCache, fetches)
+			}
+			if tt.cache != nil && !tt.cache.fresh && len(tt.cache.stored) != len(fetched) {
+				t.Errorf("Expected fetched activities to be written to the cache, got %d", len(tt.cache.stored))
+			}
+		})
+	}
+}
+
+func TestLoadActivities_MergesManualActivities(t *testing.T) {
+	day := time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC)
+	cache := &mockActivityCache{manual: []strava.Activity{{ID: -1, StartDate: day.AddDate(0, 0, 1), Source: "manual"}}}
+	worker := NewWorker(nil, "", "", "", "", "", logger.New("test"))
+	worker.SetActivityCache(cache, time.Minute)
+
+	fetch := func(ctx context.Context, after time.Time) ([]strava.Activity, error) {
+		return []strava.Activity{{ID: 1, StartDate: day}, {ID: 2, StartDate: day.AddDate(0, 0, 2)}}, nil
+	}
+	activities, _, err := worker.loadActivities(context.Background(), 7, day.AddDate(0, 0, -1), fetch)
+	if err != nil {
+		t.Fatalf("loadActivities failed: %v", err)
+	}
+
+	if len(activities) != 3 || activities[0].ID != 1 || activities[1].ID != -1 || activities[2].ID != 2 {
+		t.Errorf("Expected the manual activity between the Strava ones by start date, got %+v", activities)
+	}
+	if len(cache.stored) != 2 {
+		t.Errorf("Expected only the Strava activities to be stored as fetched, got %d", len(cache.stored))
+	}
+}
@@ -0,0 +1,443 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// backfillRecentPeriod matches Strava's "recent" athlete totals. Windows overlapping it are never
+// checkpointed, so they are re-imported on every backfill and can be verified against those totals.
+const backfillRecentPeriod = 28 * 24 * time.Hour
+
+// Verification scopes reported in backfill gaps
+const (
+	BackfillScopeRecent     = "recent"
+	BackfillScopeYearToDate = "year_to_date"
+	BackfillScopeAllTime    = "all_time"
+)
+
+// ErrorTypeBackfillContinues is reported for backfills that used up their time slice; the job is
+// queued again right away and resumes from its checkpoints
+const ErrorTypeBackfillContinues = "BACKFILL_CONTINUES"
+
+// BackfillCheckpoints persists completed backfill windows so interrupted imports resume
+type BackfillCheckpoints interface {
+	ListCompletedWindows(ctx context.Context, userID int, from time.Time) ([]database.BackfillWindow, error)
+	RecordWindow(ctx context.Context, window *database.BackfillWindow) error
+}
+
+// backfillSource is the Strava side of a backfill (implemented by *strava.Client)
+type backfillSource interface {
+	ForEachActivityPage(ctx context.Context, after, before time.Time, fn func(page []strava.Activity) error) error
+	GetAthleteStats(ctx context.Context, athleteID int64) (*strava.AthleteStats, error)
+}
+
+// backfillSink receives imported activities (implemented by *google.ActivityStream)
+type backfillSink interface {
+	Add(ctx context.Context, activities []strava.Activity) error
+	Flush(ctx context.Context) error
+}
+
+// BackfillWindowReport describes one monthly window of a backfill
+type BackfillWindowReport struct {
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	ActivityCount int            `json:"activity_count"`
+	TypeCounts    map[string]int `json:"type_counts,omitempty"`
+	// Resumed windows were imported by an earlier backfill and taken from its checkpoint
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// BackfillGap is a sport whose imported count is below what Strava's athlete stats report.
+// Stats only count public activities, so imports normally meet or exceed them; a shortfall
+// means Strava returned incomplete history for the range.
+type BackfillGap struct {
+	Scope    string `json:"scope"`
+	Sport    string `json:"sport"`
+	Expected int    `json:"expected"`
+	Imported int    `json:"imported"`
+}
+
+// BackfillReport is the outcome of a historical import
+type BackfillReport struct {
+	UserID             int                        `json:"user_id"`
+	From               time.Time                  `json:"from"`
+	To                 time.Time                  `json:"to"`
+	Windows            []BackfillWindowReport     `json:"windows"`
+	ActivitiesImported int                        `json:"activities_imported"`
+	WindowsResumed     int                        `json:"windows_resumed"`
+	Gaps               []BackfillGap              `json:"gaps,omitempty"`
+	VerificationError  string                     `json:"verification_error,omitempty"`
+	SheetResult        *google.ActivitySyncResult `json:"sheet_result,omitempty"`
+	Complete           bool                       `json:"complete"`
+	Error              string                     `json:"error,omitempty"`
+	// Deferred backfills stopped when the user's daily budget or the job's time slice ran out, or
+	// did not start because another job held the user's lock; DeferReason is the error type. The
+	// remaining windows are imported from DeferredUntil on, resuming from the checkpoints.
+	Deferred      bool       `json:"deferred,omitempty"`
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+	DeferReason   string     `json:"defer_reason,omitempty"`
+}
+
+// SetBackfillCheckpoints enables resuming interrupted backfills from their last completed window
+func (w *Worker) SetBackfillCheckpoints(checkpoints BackfillCheckpoints) {
+	w.backfillCheckpoints = checkpoints
+}
+
+// SetBackfillSlice bounds how long one backfill job imports windows, so years of history are
+// imported by a chain of short jobs instead of one that outlives its timeout. A backfill whose
+// slice is used up stops before its next window and is deferred with ErrorTypeBackfillContinues
+// to resume right away. Windows are only sliced with checkpoints enabled, and each slice
+// checkpoints at least one window, so the chain always progresses. Zero disables slicing.
+func (w *Worker) SetBackfillSlice(slice time.Duration) {
+	w.backfillSlice = slice
+}
+
+// Backfill imports the user's Strava history from from until now into their spreadsheet.
+// History is requested in monthly windows using Strava's after/before parameters, each window
+// paginated separately, and every completed window is checkpointed so a rerun resumes where an
+// interrupted one stopped. A zero from imports everything since the athlete joined Strava.
+// The imported counts are verified against the athlete's Strava stats and shortfalls are
+// reported as gaps. A partial report is returned alongside any error.
+func (w *Worker) Backfill(ctx context.Context, userID int, from time.Time) (*BackfillReport, error) {
+	startTime := time.Now()
+	report := &BackfillReport{UserID: userID, From: from, To: startTime}
+
+	lockedCtx, unlock, acquired := w.lockUser(ctx, userID)
+	if !acquired {
+		retryAt := time.Now().Add(userBusyRetryDelay)
+		report.Deferred = true
+		report.DeferredUntil = &retryAt
+		report.DeferReason = ErrorTypeUserBusy
+		return report, nil
+	}
+	defer unlock()
+	ctx = lockedCtx
+
+	config, err := w.configService.GetProcessingConfigForUser(ctx, userID)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	defer w.tokens.Hold(config.Tokens()...)()
+
+	budget := w.beginBudget(ctx, config)
+	if budget.exhausted() {
+		report.deferUntilReset(budget)
+		return report, nil
+	}
+	ctx = withJobBudget(ctx, budget)
+	defer w.recordBudget(ctx, budget)
+
+	stravaClient := w.newStravaClient(config)
+	fullHistory := from.IsZero()
+	if fullHistory {
+		if from, err = athleteCreatedAt(ctx, stravaClient); err != nil {
+			if report.deferForStravaBudget(err) {
+				return report, nil
+			}
+			report.Error = err.Error()
+			return report, err
+		}
+		report.From = from
+	}
+
+	w.logger.Info("📚 Starting historical backfill",
+		"user_id", userID,
+		"from", from.Format(time.RFC3339),
+		"full_history", fullHistory,
+		"spreadsheet_id", config.SpreadsheetID)
+
+	sheetsClient := w.newSheetsClient(config)
+	if _, err := sheetsClient.EnsureActivityHeader(ctx, config.SpreadsheetID); err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	stream, err := sheetsClient.NewActivityStream(ctx, config.SpreadsheetID, google.DefaultStreamChunkSize)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+
+	err = w.runBackfill(ctx, report, *config.StravaAthleteID, fullHistory, stravaClient, stream)
+	if err == nil {
+		// Backfills never flag deletions: windows resumed from checkpoints are not re-read
+		report.SheetResult, err = stream.Finish(ctx, time.Time{})
+	}
+	if err != nil {
+		report.Error = err.Error()
+		report.Complete = false
+	}
+
+	w.logger.Info("📚 Historical backfill finished",
+		"user_id", userID,
+		"backfill_report", map[string]interface{}{
+			"windows":             len(report.Windows),
+			"windows_resumed":     report.WindowsResumed,
+			"activities_imported": report.ActivitiesImported,
+			"gaps":                len(report.Gaps),
+			"verification_error":  report.VerificationError,
+			"complete":            report.Complete,
+			"deferred":            report.Deferred,
+			"defer_reason":        report.DeferReason,
+			"error":               report.Error,
+			"duration_ms":         time.Since(startTime).Milliseconds(),
+		})
+
+	return report, err
+}
+
+// runBackfill imports each monthly window of report.From..report.To, then verifies the counts
+func (w *Worker) runBackfill(ctx context.Context, report *BackfillReport, athleteID int64, fullHistory bool, source backfillSource, sink backfillSink) error {
+	now := report.To
+	recentFrom := now.Add(-backfillRecentPeriod)
+
+	completed := make(map[int64]database.BackfillWindow)
+	if w.backfillCheckpoints != nil {
+		windows, err := w.backfillCheckpoints.ListCompletedWindows(ctx, report.UserID, report.From)
+		if err != nil {
+			w.logger.Warn("⚠️ Failed to read backfill checkpoints, importing every window",
+				"user_id", report.UserID,
+				"error", err)
+		}
+		for _, window := range windows {
+			completed[window.WindowStart.Unix()] = window
+		}
+	}
+
+	if w.sheetsWriteSlots != nil {
+		sink = phasedSink{sink: sink, worker: w, userID: report.UserID}
+	}
+
+	budget := jobBudgetFrom(ctx)
+	sliceStart := time.Now()
+	checkpointed := 0
+	recentCounts := make(map[string]int)
+	for _, window := range monthlyWindows(report.From, now) {
+		if checkpoint, ok := completed[window.Start.Unix()]; ok && checkpoint.WindowEnd.Equal(window.End) {
+			window.ActivityCount = checkpoint.ActivityCount
+			window.TypeCounts = checkpoint.TypeCounts
+			window.Resumed = true
+			report.Windows = append(report.Windows, window)
+			report.WindowsResumed++
+			continue
+		}
+
+		// Completed windows are checkpointed, so a deferred backfill resumes at this window
+		if budget.exhausted() {
+			w.logger.Warn("⏳ Daily processing budget used up, deferring the rest of the backfill",
+				"user_id", report.UserID,
+				"next_window", window.Start.Format("2006-01"))
+			report.deferUntilReset(budget)
+			return nil
+		}
+
+		// Recent windows are never checkpointed, so the slice only ends before an older one
+		if w.backfillSliceUsed(sliceStart, checkpointed) && window.End.Before(recentFrom) {
+			w.logger.Info("⏳ Backfill time slice used up, continuing in a new job",
+				"user_id", report.UserID,
+				"windows_imported", checkpointed,
+				"next_window", window.Start.Format("2006-01"))
+			continueAt := time.Now()
+			report.Deferred = true
+			report.DeferredUntil = &continueAt
+			report.DeferReason = ErrorTypeBackfillContinues
+			return nil
+		}
+
+		// The Strava fetch slot is held for the whole window, whose pages are fetched as they are written
+		window.TypeCounts = make(map[string]int)
+		releaseFetch, err := w.enterPhase(ctx, w.stravaFetchSlots, report.UserID, StepStravaFetch)
+		if err == nil {
+			err = source.ForEachActivityPage(ctx, window.Start, window.End, func(page []strava.Activity) error {
+				for _, activity := range page {
+					window.TypeCounts[activity.Type]++
+					if !activity.StartDate.Before(recentFrom) {
+						recentCounts[strava.StatsSport(activity.Type)]++
+					}
+				}
+				window.ActivityCount += len(page)
+				return sink.Add(ctx, page)
+			})
+			releaseFetch()
+		}
+		if err == nil {
+			// Rows must reach the sheet before the window is checkpointed
+			err = sink.Flush(ctx)
+		}
+		if err != nil {
+			// Pages of the window already added are written again when it is re-imported
+			if report.deferForStravaBudget(err) {
+				w.logger.Warn("⏳ Daily Strava API budget used up, deferring the rest of the backfill",
+					"user_id", report.UserID,
+					"next_window", window.Start.Format("2006-01"))
+				return nil
+			}
+			return fmt.Errorf("backfill window %s: %w", window.Start.Format("2006-01"), err)
+		}
+
+		report.Windows = append(report.Windows, window)
+		report.ActivitiesImported += window.ActivityCount
+		if window.End.Before(recentFrom) && w.recordBackfillWindow(ctx, report.UserID, window) {
+			checkpointed++
+		}
+	}
+
+	stats, err := source.GetAthleteStats(ctx, athleteID)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to verify backfill against Strava athlete stats",
+			"user_id", report.UserID,
+			"error", err)
+		report.VerificationError = err.Error()
+		return nil
+	}
+
+	report.Gaps = verifyBackfill(report, stats, recentCounts, fullHistory)
+	report.Complete = len(report.Gaps) == 0
+	return nil
+}
+
+// deferUntilReset marks the backfill as stopped until the user's budget resets
+func (r *BackfillReport) deferUntilReset(budget *jobBudget) {
+	resetAt := budget.resetAt
+	r.Deferred = true
+	r.DeferredUntil = &resetAt
+	r.DeferReason = ErrorTypeBudgetExceeded
+}
+
+// backfillSliceUsed reports whether a backfill that started importing at sliceStart and has
+// checkpointed windows in this job should continue in a new job
+func (w *Worker) backfillSliceUsed(sliceStart time.Time, checkpointed int) bool {
+	return w.backfillSlice > 0 && checkpointed > 0 && time.Since(sliceStart) >= w.backfillSlice
+}
+
+// deferForStravaBudget marks the backfill as stopped until the Strava call budget resets when err
+// means it is used up, and reports whether it did
+func (r *BackfillReport) deferForStravaBudget(err error) bool {
+	var budgetErr *strava.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		return false
+	}
+	resetAt := budgetErr.ResetAt
+	r.Deferred = true
+	r.DeferredUntil = &resetAt
+	r.DeferReason = ErrorTypeStravaBudgetExceeded
+	return true
+}
+
+// recordBackfillWindow checkpoints a completed window and reports whether it was recorded;
+// failures only cost a re-import later
+func (w *Worker) recordBackfillWindow(ctx context.Context, userID int, window BackfillWindowReport) bool {
+	if w.backfillCheckpoints == nil {
+		return false
+	}
+
+	err := w.backfillCheckpoints.RecordWindow(ctx, &database.BackfillWindow{
+		UserID:        userID,
+		WindowStart:   window.Start,
+		WindowEnd:     window.End,
+		ActivityCount: window.ActivityCount,
+		TypeCounts:    window.TypeCounts,
+		CompletedAt:   time.Now(),
+	})
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to checkpoint backfill window",
+			"user_id", userID,
+			"window_start", window.Start.Format(time.RFC3339),
+			"error", err)
+		return false
+	}
+	return true
+}
+
+// verifyBackfill compares imported counts with the athlete stats for every scope the import covers.
+// Windows are UTC months while Strava's year-to-date totals follow the athlete's timezone, so an
+// activity on the year boundary can shift between years; only shortfalls are reported.
+func verifyBackfill(report *BackfillReport, stats *strava.AthleteStats, recentCounts map[string]int, fullHistory bool) []BackfillGap {
+	var gaps []BackfillGap
+	check := func(scope string, expected map[string]int, imported map[string]int) {
+		for _, sport := range []string{strava.StatsSportRun, strava.StatsSportRide, strava.StatsSportSwim} {
+			if imported[sport] < expected[sport] {
+				gaps = append(gaps, BackfillGap{Scope: scope, Sport: sport, Expected: expected[sport], Imported: imported[sport]})
+			}
+		}
+	}
+
+	now := report.To
+	if !report.From.After(now.Add(-backfillRecentPeriod)) {
+		check(BackfillScopeRecent, map[string]int{
+			strava.StatsSportRun:  stats.RecentRunTotals.Count,
+			strava.StatsSportRide: stats.RecentRideTotals.Count,
+			strava.StatsSportSwim: stats.RecentSwimTotals.Count,
+		}, recentCounts)
+	}
+
+	yearStart := time.Date(now.UTC().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if !report.From.After(yearStart) {
+		check(BackfillScopeYearToDate, map[string]int{
+			strava.StatsSportRun:  stats.YTDRunTotals.Count,
+			strava.StatsSportRide: stats.YTDRideTotals.Count,
+			strava.StatsSportSwim: stats.YTDSwimTotals.Count,
+		}, sportCounts(report.Windows, yearStart))
+	}
+
+	if fullHistory {
+		check(BackfillScopeAllTime, map[string]int{
+			strava.StatsSportRun:  stats.AllRunTotals.Count,
+			strava.StatsSportRide: stats.AllRideTotals.Count,
+			strava.StatsSportSwim: stats.AllSwimTotals.Count,
+		}, sportCounts(report.Windows, time.Time{}))
+	}
+
+	return gaps
+}
+
+// sportCounts sums the per-type counts of windows starting at or after from by stats sport
+func sportCounts(windows []BackfillWindowReport, from time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, window := range windows {
+		if window.Start.Before(from) {
+			continue
+		}
+		for activityType, count := range window.TypeCounts {
+			counts[strava.StatsSport(activityType)] += count
+		}
+	}
+	return counts
+}
+
+// monthlyWindows splits [from, to) into calendar months in UTC; the first and last windows may be partial
+func monthlyWindows(from, to time.Time) []BackfillWindowReport {
+	var windows []BackfillWindowReport
+	from, to = from.UTC(), to.UTC()
+	for start := from; start.Before(to); {
+		end := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, BackfillWindowReport{Start: start, End: end})
+		start = end
+	}
+	return windows
+}
+
+// athleteCreatedAt returns when the athlete joined Strava, the earliest possible activity date
+func athleteCreatedAt(ctx context.Context, client *strava.Client) (time.Time, error) {
+	profile, err := client.GetAthleteProfile(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	createdAt, _ := profile["created_at"].(string)
+	joined, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("athlete profile has no valid created_at: %q", createdAt)
+	}
+	return joined, nil
+}
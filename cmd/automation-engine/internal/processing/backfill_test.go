@@ -0,0 +1,210 @@
+package processing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type fakeBackfillSource struct {
+	activities []strava.Activity
+	stats      *strava.AthleteStats
+	requested  []time.Time
+}
+
+func (f *fakeBackfillSource) ForEachActivityPage(ctx context.Context, after, before time.Time, fn func(page []strava.Activity) error) error {
+	f.requested = append(f.requested, after)
+	var page []strava.Activity
+	for _, activity := range f.activities {
+		if activity.StartDate.After(after) && activity.StartDate.Before(before) {
+			page = append(page, activity)
+		}
+	}
+	if len(page) == 0 {
+		return nil
+	}
+	return fn(page)
+}
+
+func (f *fakeBackfillSource) GetAthleteStats(ctx context.Context, athleteID int64) (*strava.AthleteStats, error) {
+	return f.stats, nil
+}
+
+type fakeBackfillSink struct {
+	added   int
+	flushes int
+}
+
+func (f *fakeBackfillSink) Add(ctx context.Context, activities []strava.Activity) error {
+	f.added += len(activities)
+	return nil
+}
+
+func (f *fakeBackfillSink) Flush(ctx context.Context) error {
+	f.flushes++
+	return nil
+}
+
+type fakeCheckpoints struct {
+	windows []database.BackfillWindow
+}
+
+func (f *fakeCheckpoints) ListCompletedWindows(ctx context.Context, userID int, from time.Time) ([]database.BackfillWindow, error) {
+	return f.windows, nil
+}
+
+func (f *fakeCheckpoints) RecordWindow(ctx context.Context, window *database.BackfillWindow) error {
+	f.windows = append(f.windows, *window)
+	return nil
+}
+
+func TestMonthlyWindows(t *testing.T) {
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	windows := monthlyWindows(from, to)
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(windows))
+	}
+	if !windows[0].Start.Equal(from) || !windows[0].End.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first window: %+v", windows[0])
+	}
+	if !windows[2].End.Equal(to) {
+		t.Errorf("Expected last window to end at %v, got %v", to, windows[2].End)
+	}
+}
+
+func TestRunBackfill_ResumesAndCheckpoints(t *testing.T) {
+	now := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &fakeBackfillSource{
+		activities: []strava.Activity{
+			{ID: 1, Type: "Run", StartDate: time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC)},
+			{ID: 2, Type: "Run", StartDate: time.Date(2024, 2, 10, 7, 0, 0, 0, time.UTC)},
+			{ID: 3, Type: "Ride", StartDate: time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC)},
+		},
+		stats: &strava.AthleteStats{
+			RecentRideTotals: strava.ActivityTotals{Count: 1},
+			YTDRunTotals:     strava.ActivityTotals{Count: 2},
+			YTDRideTotals:    strava.ActivityTotals{Count: 1},
+		},
+	}
+	checkpoints := &fakeCheckpoints{windows: []database.BackfillWindow{{
+		UserID:        1,
+		WindowStart:   from,
+		WindowEnd:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		ActivityCount: 1,
+		TypeCounts:    map[string]int{"Run": 1},
+	}}}
+	sink := &fakeBackfillSink{}
+
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetBackfillCheckpoints(checkpoints)
+
+	report := &BackfillReport{UserID: 1, From: from, To: now}
+	if err := worker.runBackfill(context.Background(), report, 42, false, source, sink); err != nil {
+		t.Fatalf("runBackfill failed: %v", err)
+	}
+
+	if report.WindowsResumed != 1 || len(report.Windows) != 6 {
+		t.Errorf("Expected 6 windows with 1 resumed, got %d windows with %d resumed", len(report.Windows), report.WindowsResumed)
+	}
+	if source.requested[0].Equal(from) {
+		t.Error("Expected the checkpointed January window not to be fetched again")
+	}
+	if report.ActivitiesImported != 2 || sink.added != 2 || sink.flushes != 5 {
+		t.Errorf("Unexpected import: imported=%d added=%d flushes=%d", report.ActivitiesImported, sink.added, sink.flushes)
+	}
+	// February..April are checkpointed; May and June overlap the recent period
+	if len(checkpoints.windows) != 4 {
+		t.Errorf("Expected 4 checkpoints (1 existing + 3 new), got %d", len(checkpoints.windows))
+	}
+	if len(report.Gaps) != 0 || !report.Complete {
+		t.Errorf("Expected a complete backfill, got gaps %+v", report.Gaps)
+	}
+}
+
+func TestRunBackfill_ReportsGaps(t *testing.T) {
+	now := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	source := &fakeBackfillSource{
+		activities: []strava.Activity{
+			{ID: 1, Type: "Run", StartDate: time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC)},
+		},
+		stats: &strava.AthleteStats{
+			RecentRunTotals: strava.ActivityTotals{Count: 3},
+			AllRunTotals:    strava.ActivityTotals{Count: 10},
+		},
+	}
+
+	worker := &Worker{logger: logger.New("test")}
+	report := &BackfillReport{UserID: 1, From: time.Date(2023, 11, 5, 0, 0, 0, 0, time.UTC), To: now}
+	if err := worker.runBackfill(context.Background(), report, 42, true, source, &fakeBackfillSink{}); err != nil {
+		t.Fatalf("runBackfill failed: %v", err)
+	}
+
+	if report.Complete {
+		t.Error("Expected an incomplete backfill")
+	}
+	scopes := make(map[string]BackfillGap)
+	for _, gap := range report.Gaps {
+		scopes[gap.Scope] = gap
+	}
+	if gap := scopes[BackfillScopeRecent]; gap.Sport != strava.StatsSportRun || gap.Expected != 3 || gap.Imported != 1 {
+		t.Errorf("Unexpected recent gap: %+v", gap)
+	}
+	if gap := scopes[BackfillScopeAllTime]; gap.Expected != 10 || gap.Imported != 1 {
+		t.Errorf("Unexpected all-time gap: %+v", gap)
+	}
+}
+
+func TestRunBackfill_ContinuesAfterSlice(t *testing.T) {
+	now := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := &fakeBackfillSource{
+		activities: []strava.Activity{
+			{ID: 1, Type: "Run", StartDate: time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC)},
+			{ID: 2, Type: "Run", StartDate: time.Date(2024, 2, 10, 7, 0, 0, 0, time.UTC)},
+		},
+		stats: &strava.AthleteStats{YTDRunTotals: strava.ActivityTotals{Count: 2}},
+	}
+	checkpoints := &fakeCheckpoints{}
+
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetBackfillCheckpoints(checkpoints)
+	worker.SetBackfillSlice(time.Nanosecond)
+
+	// Each job imports one checkpointed window before handing over to the next
+	report := &BackfillReport{UserID: 1, From: from, To: now}
+	if err := worker.runBackfill(context.Background(), report, 42, false, source, &fakeBackfillSink{}); err != nil {
+		t.Fatalf("runBackfill failed: %v", err)
+	}
+	if !report.Deferred || report.DeferReason != ErrorTypeBackfillContinues || report.DeferredUntil == nil {
+		t.Fatalf("Expected the backfill to continue in a new job, got %+v", report)
+	}
+	if len(report.Windows) != 1 || len(checkpoints.windows) != 1 || report.Complete {
+		t.Errorf("Expected one checkpointed window, got %d windows and %d checkpoints", len(report.Windows), len(checkpoints.windows))
+	}
+
+	// The chain ends once the windows left after a checkpoint are the never-checkpointed recent ones
+	for jobs := 2; ; jobs++ {
+		if jobs > 6 {
+			t.Fatal("Expected the backfill to finish within six jobs")
+		}
+		report = &BackfillReport{UserID: 1, From: from, To: now}
+		if err := worker.runBackfill(context.Background(), report, 42, false, source, &fakeBackfillSink{}); err != nil {
+			t.Fatalf("runBackfill failed: %v", err)
+		}
+		if !report.Deferred {
+			break
+		}
+	}
+	// The last job imports April, the final checkpointed window, and then the recent windows
+	if !report.Complete || len(report.Windows) != 6 || report.WindowsResumed != 3 || len(checkpoints.windows) != 4 {
+		t.Errorf("Expected a complete backfill resumed from 3 windows, got %+v", report)
+	}
+}
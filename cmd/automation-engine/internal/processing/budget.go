@@ -0,0 +1,200 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// ErrorTypeBudgetExceeded is reported for jobs deferred because the user's daily budget is used up
+const ErrorTypeBudgetExceeded = "DAILY_BUDGET_EXCEEDED"
+
+// ErrorTypeStravaBudgetExceeded is reported for jobs deferred because the user's daily Strava API
+// call budget is used up
+const ErrorTypeStravaBudgetExceeded = "STRAVA_BUDGET_EXCEEDED"
+
+// budgetRecordTimeout bounds recording a job's usage after the job's own context has ended
+const budgetRecordTimeout = 5 * time.Second
+
+// BudgetStore keeps each user's provider usage per local day (YYYY-MM-DD)
+type BudgetStore interface {
+	GetBudgetUsage(ctx context.Context, userID int, day string) (calls, writes int, err error)
+	AddBudgetUsage(ctx context.Context, userID int, day string, calls, writes int) error
+}
+
+// BudgetLimits caps the provider calls (Strava and Google API requests) and the Sheets writes
+// among them that a user's jobs may consume per day; zero disables a limit
+type BudgetLimits struct {
+	ProviderCalls int
+	SheetsWrites  int
+}
+
+func (l BudgetLimits) enabled() bool {
+	return l.ProviderCalls > 0 || l.SheetsWrites > 0
+}
+
+// SetDailyBudget enables the per-user daily processing budget. Jobs started after a user's
+// budget is used up are deferred until the user's next local day; a backfill that runs out
+// stops after its current window and resumes from its checkpoints. A running sync is never cut
+// short, so a day's usage can exceed the limits by at most one job.
+func (w *Worker) SetDailyBudget(store BudgetStore, limits BudgetLimits) {
+	w.budgetStore = store
+	w.budgetLimits = limits
+}
+
+// SetStravaCallBudget caps each user's Strava API calls per UTC day at dailyLimit, counted in
+// counter as the calls are made, so one user's multi-year backfill cannot use up the
+// application-wide Strava rate limit. Unlike the daily budget, the ceiling is enforced by the
+// Strava client on every call: a sync that reaches it stops and is deferred to the next UTC day
+// with ErrorTypeStravaBudgetExceeded, and a backfill resumes from its checkpoints. Zero disables it.
+func (w *Worker) SetStravaCallBudget(counter strava.CallCounter, dailyLimit int) {
+	w.stravaCallCounter = counter
+	w.stravaCallLimit = dailyLimit
+}
+
+// deferForStravaBudget marks result as deferred until the Strava call budget resets when err
+// means it is used up, and reports whether it did
+func deferForStravaBudget(err error, result *ProcessingResult) bool {
+	var budgetErr *strava.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		return false
+	}
+	resetAt := budgetErr.ResetAt
+	result.Deferred = true
+	result.DeferredUntil = &resetAt
+	result.ErrorType = ErrorTypeStravaBudgetExceeded
+	result.Error = fmt.Sprintf("Daily Strava API budget of %d calls used up; remaining work deferred until %s",
+		budgetErr.Limit, resetAt.Format(time.RFC3339))
+	return true
+}
+
+// jobBudget tracks the provider usage of one job against the user's daily budget. A nil
+// jobBudget, used when the budget is disabled or unreadable, is never exhausted.
+type jobBudget struct {
+	userID int
+	day    string
+	// resetAt is the start of the user's next day, when deferred work may run
+	resetAt time.Time
+
+	limits     BudgetLimits
+	usedCalls  int
+	usedWrites int
+
+	// Counted by budgetTransport during the job
+	calls  atomic.Int64
+	writes atomic.Int64
+}
+
+// beginBudget reads the user's usage so far today. Store failures are logged and the job runs
+// unbudgeted rather than failing.
+func (w *Worker) beginBudget(ctx context.Context, config *automation.ProcessingConfig) *jobBudget {
+	if w.budgetStore == nil || !w.budgetLimits.enabled() {
+		return nil
+	}
+
+	loc, err := config.GetLocation()
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now().In(loc)
+	budget := &jobBudget{
+		userID:  config.UserID,
+		day:     now.Format("2006-01-02"),
+		resetAt: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc),
+		limits:  w.budgetLimits,
+	}
+
+	budget.usedCalls, budget.usedWrites, err = w.budgetStore.GetBudgetUsage(ctx, config.UserID, budget.day)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to read processing budget, running without it",
+			"user_id", config.UserID,
+			"error", err)
+		return nil
+	}
+	return budget
+}
+
+// exhausted reports whether the day's usage, including this job's so far, reached a limit
+func (b *jobBudget) exhausted() bool {
+	if b == nil {
+		return false
+	}
+	calls, writes := b.total()
+	return (b.limits.ProviderCalls > 0 && calls >= b.limits.ProviderCalls) ||
+		(b.limits.SheetsWrites > 0 && writes >= b.limits.SheetsWrites)
+}
+
+// total returns the day's usage including this job
+func (b *jobBudget) total() (calls, writes int) {
+	return b.usedCalls + int(b.calls.Load()), b.usedWrites + int(b.writes.Load())
+}
+
+// deferResult marks result as deferred to the user's next day
+func (b *jobBudget) deferResult(result *ProcessingResult) {
+	calls, writes := b.total()
+	resetAt := b.resetAt
+	result.Deferred = true
+	result.DeferredUntil = &resetAt
+	result.ErrorType = ErrorTypeBudgetExceeded
+	result.Error = fmt.Sprintf("Daily processing budget used up (%d provider calls, %d sheet writes today); remaining work deferred until %s",
+		calls, writes, resetAt.Format(time.RFC3339))
+}
+
+// recordBudget adds the job's usage to the user's day; it runs after the job, whose context may have expired
+func (w *Worker) recordBudget(ctx context.Context, b *jobBudget) {
+	if b == nil {
+		return
+	}
+	calls, writes := int(b.calls.Load()), int(b.writes.Load())
+	if calls == 0 && writes == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), budgetRecordTimeout)
+	defer cancel()
+	if err := w.budgetStore.AddBudgetUsage(ctx, b.userID, b.day, calls, writes); err != nil {
+		w.logger.Warn("⚠️ Failed to record processing budget usage",
+			"user_id", b.userID,
+			"provider_calls", calls,
+			"sheets_writes", writes,
+			"error", err)
+	}
+}
+
+type jobBudgetKey struct{}
+
+// withJobBudget makes the clients count the requests made with ctx against b
+func withJobBudget(ctx context.Context, b *jobBudget) context.Context {
+	if b == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, jobBudgetKey{}, b)
+}
+
+// jobBudgetFrom returns the budget attached to ctx by withJobBudget, or nil
+func jobBudgetFrom(ctx context.Context) *jobBudget {
+	b, _ := ctx.Value(jobBudgetKey{}).(*jobBudget)
+	return b
+}
+
+// budgetTransport counts API requests against the budget of the job that made them. Requests
+// other than GET count as Sheets writes when countWrites is set.
+type budgetTransport struct {
+	countWrites bool
+}
+
+func (t budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if b := jobBudgetFrom(req.Context()); b != nil {
+		b.calls.Add(1)
+		if t.countWrites && req.Method != http.MethodGet {
+			b.writes.Add(1)
+		}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,213 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type fakeBudgetStore struct {
+	calls, writes int
+	added         []int
+}
+
+func (f *fakeBudgetStore) GetBudgetUsage(ctx context.Context, userID int, day string) (int, int, error) {
+	return f.calls, f.writes, nil
+}
+
+func (f *fakeBudgetStore) AddBudgetUsage(ctx context.Context, userID int, day string, calls, writes int) error {
+	f.calls += calls
+	f.writes += writes
+	f.added = append(f.added, calls, writes)
+	return nil
+}
+
+// countingBackfillSource charges one provider call per window to the job's budget
+type countingBackfillSource struct {
+	fakeBackfillSource
+}
+
+func (c *countingBackfillSource) ForEachActivityPage(ctx context.Context, after, before time.Time, fn func(page []strava.Activity) error) error {
+	if b := jobBudgetFrom(ctx); b != nil {
+		b.calls.Add(1)
+	}
+	return c.fakeBackfillSource.ForEachActivityPage(ctx, after, before, fn)
+}
+
+func TestWorker_BeginBudget(t *testing.T) {
+	store := &fakeBudgetStore{calls: 10, writes: 4}
+	worker := &Worker{logger: logger.New("test")}
+	config := &automation.ProcessingConfig{UserID: 1, Timezone: "UTC"}
+
+	if budget := worker.beginBudget(context.Background(), config); budget != nil {
+		t.Fatal("Expected no budget before SetDailyBudget")
+	}
+
+	worker.SetDailyBudget(store, BudgetLimits{ProviderCalls: 100, SheetsWrites: 5})
+	budget := worker.beginBudget(context.Background(), config)
+	if budget == nil || budget.exhausted() {
+		t.Fatalf("Expected a budget with room left, got %+v", budget)
+	}
+	if !budget.resetAt.After(time.Now()) || budget.resetAt.Hour() != 0 {
+		t.Errorf("Expected the budget to reset at the next midnight, got %v", budget.resetAt)
+	}
+
+	budget.writes.Add(1)
+	if !budget.exhausted() {
+		t.Error("Expected the budget to be exhausted at the sheet write limit")
+	}
+
+	var result ProcessingResult
+	budget.deferResult(&result)
+	if !result.Deferred || result.ErrorType != ErrorTypeBudgetExceeded || !result.DeferredUntil.Equal(budget.resetAt) {
+		t.Errorf("Unexpected deferred result: %+v", result)
+	}
+
+	worker.recordBudget(context.Background(), budget)
+	if store.writes != 5 || len(store.added) != 2 {
+		t.Errorf("Expected the job's usage to be recorded, got %+v", store)
+	}
+}
+
+func TestBudgetTransport_CountsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	budget := &jobBudget{limits: BudgetLimits{ProviderCalls: 100}}
+	ctx := withJobBudget(context.Background(), budget)
+
+	client := &http.Client{Transport: budgetTransport{countWrites: true}}
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut} {
+		req, _ := http.NewRequestWithContext(ctx, method, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if calls, writes := budget.total(); calls != 3 || writes != 2 {
+		t.Errorf("Expected 3 calls including 2 writes, got %d calls and %d writes", calls, writes)
+	}
+}
+
+func TestRunBackfill_DefersWhenBudgetRunsOut(t *testing.T) {
+	now := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &countingBackfillSource{}
+	sink := &fakeBackfillSink{}
+	checkpoints := &fakeCheckpoints{}
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetBackfillCheckpoints(checkpoints)
+
+	resetAt := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	budget := &jobBudget{resetAt: resetAt, limits: BudgetLimits{ProviderCalls: 10}, usedCalls: 8}
+	ctx := withJobBudget(context.Background(), budget)
+
+	report := &BackfillReport{UserID: 1, From: from, To: now}
+	if err := worker.runBackfill(ctx, report, 42, false, source, sink); err != nil {
+		t.Fatalf("runBackfill failed: %v", err)
+	}
+
+	if !report.Deferred || !report.DeferredUntil.Equal(resetAt) {
+		t.Fatalf("Expected the backfill to be deferred until %v, got %+v", resetAt, report)
+	}
+	if len(report.Windows) != 2 || len(checkpoints.windows) != 2 {
+		t.Errorf("Expected 2 imported and checkpointed windows before deferring, got %d and %d", len(report.Windows), len(checkpoints.windows))
+	}
+	if report.Complete {
+		t.Error("Expected a deferred backfill not to be complete")
+	}
+}
+
+// fakeCallCounter counts Strava calls in memory, per user and day
+type fakeCallCounter struct {
+	counts map[string]int
+}
+
+func (f *fakeCallCounter) IncrStravaCalls(ctx context.Context, userID int, day string) (int, error) {
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	key := fmt.Sprintf("%s:%d", day, userID)
+	f.counts[key]++
+	return f.counts[key], nil
+}
+
+func TestStravaCallBudget_StopsCallsAndDefers(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	worker := &Worker{logger: logger.New("test"), tokens: secure.NewTokenCache(secure.DefaultTokenCacheSize)}
+	worker.SetProviderEndpoints(strava.Endpoints{APIBaseURL: server.URL}, google.Endpoints{})
+	worker.SetStravaCallBudget(&fakeCallCounter{}, 1)
+
+	expiry := time.Now().Add(time.Hour)
+	client := worker.newStravaClient(&automation.ProcessingConfig{
+		UserID:            1,
+		StravaAccessToken: secure.NewTokenString("access"),
+		StravaTokenExpiry: &expiry,
+	})
+
+	if _, err := client.GetActivities(context.Background(), time.Now().AddDate(0, 0, -7)); err != nil {
+		t.Fatalf("Expected the first call to be within budget, got %v", err)
+	}
+	_, err := client.GetActivities(context.Background(), time.Now().AddDate(0, 0, -7))
+	if !strava.IsBudgetExceeded(err) || requests != 1 {
+		t.Fatalf("Expected the second call to be refused without reaching Strava, got %v after %d requests", err, requests)
+	}
+
+	result := &ProcessingResult{}
+	if !deferForStravaBudget(err, result) || result.ErrorType != ErrorTypeStravaBudgetExceeded {
+		t.Fatalf("Expected the sync to be deferred, got %+v", result)
+	}
+	if reset := *result.DeferredUntil; !reset.After(time.Now()) || reset.Hour() != 0 || reset.Location() != time.UTC {
+		t.Errorf("Expected the sync to be deferred to the next UTC midnight, got %v", reset)
+	}
+}
+
+// budgetedBackfillSource refuses every window after the first with a used-up Strava budget
+type budgetedBackfillSource struct {
+	fakeBackfillSource
+	resetAt time.Time
+}
+
+func (b *budgetedBackfillSource) ForEachActivityPage(ctx context.Context, after, before time.Time, fn func(page []strava.Activity) error) error {
+	if len(b.requested) > 0 {
+		return &strava.BudgetExceededError{UserID: 1, Limit: 1, ResetAt: b.resetAt}
+	}
+	return b.fakeBackfillSource.ForEachActivityPage(ctx, after, before, fn)
+}
+
+func TestRunBackfill_DefersWhenStravaBudgetRunsOut(t *testing.T) {
+	resetAt := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	source := &budgetedBackfillSource{resetAt: resetAt}
+	checkpoints := &fakeCheckpoints{}
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetBackfillCheckpoints(checkpoints)
+
+	report := &BackfillReport{UserID: 1, From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)}
+	if err := worker.runBackfill(context.Background(), report, 42, false, source, &fakeBackfillSink{}); err != nil {
+		t.Fatalf("Expected a used-up budget to defer rather than fail, got %v", err)
+	}
+	if !report.Deferred || report.DeferReason != ErrorTypeStravaBudgetExceeded || !report.DeferredUntil.Equal(resetAt) {
+		t.Fatalf("Expected the backfill to be deferred until %v, got %+v", resetAt, report)
+	}
+	if len(checkpoints.windows) != 1 {
+		t.Errorf("Expected the first window to be checkpointed, got %d", len(checkpoints.windows))
+	}
+}
@@ -0,0 +1,32 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorTypeJobTimeout is reported for jobs that failed because they ran past their deadline
+const ErrorTypeJobTimeout = "JOB_TIMEOUT"
+
+// ErrorTypeJobCancelled is reported for jobs that failed because their context was cancelled,
+// for example when the user's lock was lost
+const ErrorTypeJobCancelled = "JOB_CANCELLED"
+
+// ClassifyCancellation reports a failed result whose job context has ended as a timeout or a
+// cancellation instead of the step that happened to be running, since the step did not fail on
+// its own. Successful and deferred results are left as they are.
+func ClassifyCancellation(ctx context.Context, result *ProcessingResult) {
+	if result.Success || result.Deferred || ctx.Err() == nil {
+		return
+	}
+
+	step := result.Error
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.ErrorType = ErrorTypeJobTimeout
+		result.Error = fmt.Sprintf("Job ran past its deadline: %s", step)
+		return
+	}
+	result.ErrorType = ErrorTypeJobCancelled
+	result.Error = fmt.Sprintf("Job was cancelled (%v): %s", context.Cause(ctx), step)
+}
@@ -0,0 +1,72 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyCancellation(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelled, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("user lock for user 7 was lost"))
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		result        ProcessingResult
+		wantErrorType string
+		wantError     string
+	}{
+		{
+			name:          "deadline exceeded",
+			ctx:           expired,
+			result:        ProcessingResult{ErrorType: "SHEETS_WRITE_ERROR", Error: "write failed"},
+			wantErrorType: ErrorTypeJobTimeout,
+			wantError:     "write failed",
+		},
+		{
+			name:          "cancelled",
+			ctx:           cancelled,
+			result:        ProcessingResult{ErrorType: "STRAVA_FETCH_ERROR", Error: "fetch failed"},
+			wantErrorType: ErrorTypeJobCancelled,
+			wantError:     "user lock for user 7 was lost",
+		},
+		{
+			name:          "live context keeps the step's error",
+			ctx:           context.Background(),
+			result:        ProcessingResult{ErrorType: "STRAVA_FETCH_ERROR", Error: "fetch failed"},
+			wantErrorType: "STRAVA_FETCH_ERROR",
+			wantError:     "fetch failed",
+		},
+		{
+			name:          "successful results are left alone",
+			ctx:           expired,
+			result:        ProcessingResult{Success: true},
+			wantErrorType: "",
+		},
+		{
+			name:          "deferred results are left alone",
+			ctx:           expired,
+			result:        ProcessingResult{Deferred: true, ErrorType: ErrorTypeUserBusy, Error: "busy"},
+			wantErrorType: ErrorTypeUserBusy,
+			wantError:     "busy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.result
+			ClassifyCancellation(tt.ctx, &result)
+			if result.ErrorType != tt.wantErrorType {
+				t.Errorf("Expected error type %q, got %q", tt.wantErrorType, result.ErrorType)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("Expected the error to mention %q, got %q", tt.wantError, result.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package processing
+
+import (
+	"context"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+// JobCheckpoints records the processing steps a job has completed
+type JobCheckpoints interface {
+	RecordJobCheckpoint(ctx context.Context, checkpoint *queue.JobCheckpoint) error
+}
+
+// SetJobCheckpoints makes queued jobs record a checkpoint after each processing step (config
+// loaded, tokens ready, destination validated, activities fetched, rows written), which the API
+// streams to the browser while the job runs. Runs without a trace ID record none.
+func (w *Worker) SetJobCheckpoints(checkpoints JobCheckpoints) {
+	w.jobCheckpoints = checkpoints
+}
+
+// checkpoint records that the job traced by traceID completed step. Checkpoints only report
+// progress, so failures are logged and never fail the job.
+func (w *Worker) checkpoint(ctx context.Context, traceID string, userID int, step queue.JobStep, counts map[string]int) {
+	if w.jobCheckpoints == nil || traceID == "" {
+		return
+	}
+
+	if err := w.jobCheckpoints.RecordJobCheckpoint(ctx, &queue.JobCheckpoint{
+		TraceID: traceID,
+		UserID:  userID,
+		Step:    step,
+		Counts:  counts,
+	}); err != nil {
+		w.logger.Warn("⚠️ Failed to record job checkpoint",
+			"user_id", userID,
+			"trace_id", traceID,
+			"step", string(step),
+			"error", err)
+	}
+}
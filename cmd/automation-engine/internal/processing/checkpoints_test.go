@@ -0,0 +1,51 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+// fakeJobCheckpoints records checkpoints in memory, or fails every one when err is set
+type fakeJobCheckpoints struct {
+	recorded []queue.JobCheckpoint
+	err      error
+}
+
+func (f *fakeJobCheckpoints) RecordJobCheckpoint(ctx context.Context, checkpoint *queue.JobCheckpoint) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.recorded = append(f.recorded, *checkpoint)
+	return nil
+}
+
+func TestWorker_Checkpoint(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	ctx := context.Background()
+
+	// Without a recorder checkpoints are skipped
+	worker.checkpoint(ctx, "trace-1", 7, queue.StepConfigLoaded, nil)
+
+	checkpoints := &fakeJobCheckpoints{}
+	worker.SetJobCheckpoints(checkpoints)
+
+	worker.checkpoint(ctx, "trace-1", 7, queue.StepActivitiesFetched, map[string]int{"activities": 3})
+	// Runs without a trace ID, such as test mode, have no job to report on
+	worker.checkpoint(ctx, "", 7, queue.StepRowsWritten, nil)
+
+	if len(checkpoints.recorded) != 1 {
+		t.Fatalf("Expected one checkpoint, got %+v", checkpoints.recorded)
+	}
+	recorded := checkpoints.recorded[0]
+	if recorded.TraceID != "trace-1" || recorded.UserID != 7 || recorded.Step != queue.StepActivitiesFetched || recorded.Counts["activities"] != 3 {
+		t.Errorf("Unexpected checkpoint %+v", recorded)
+	}
+
+	// Recording failures never reach the job
+	checkpoints.err = errors.New("redis down")
+	worker.checkpoint(ctx, "trace-1", 7, queue.StepRowsWritten, nil)
+}
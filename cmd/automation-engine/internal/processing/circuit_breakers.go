@@ -0,0 +1,88 @@
+package processing
+
+import (
+	"errors"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/circuit"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// providerUnavailableErrorTypes maps providers to the error type reported for a run skipped
+// because the provider's circuit is open
+var providerUnavailableErrorTypes = map[string]string{
+	reauthProviderStrava: "STRAVA_UNAVAILABLE",
+	reauthProviderGoogle: "GOOGLE_UNAVAILABLE",
+}
+
+// SetCircuitBreakers enables provider circuit breakers. Runs fail fast without calling a provider
+// whose circuit is open, and provider outages seen by runs count towards opening it. Either
+// breaker may be nil.
+func (w *Worker) SetCircuitBreakers(stravaBreaker, googleBreaker *circuit.Breaker) {
+	w.stravaBreaker = stravaBreaker
+	w.googleBreaker = googleBreaker
+}
+
+// openCircuit returns the first provider whose circuit is open and the reason, or "" when the
+// run may call both providers
+func (w *Worker) openCircuit() (string, error) {
+	if w.stravaBreaker != nil {
+		if err := w.stravaBreaker.Allow(); err != nil {
+			return reauthProviderStrava, err
+		}
+	}
+	if w.googleBreaker != nil {
+		if err := w.googleBreaker.Allow(); err != nil {
+			return reauthProviderGoogle, err
+		}
+	}
+	return "", nil
+}
+
+// recordStravaOutcome reports a Strava call to the breaker; user-specific errors are ignored
+func (w *Worker) recordStravaOutcome(err error) {
+	recordOutcome(w.stravaBreaker, err, isStravaOutage(err))
+}
+
+// recordGoogleOutcome reports a Google Sheets call to the breaker; user-specific errors are ignored
+func (w *Worker) recordGoogleOutcome(err error) {
+	recordOutcome(w.googleBreaker, err, isGoogleOutage(err))
+}
+
+func recordOutcome(breaker *circuit.Breaker, err error, outage bool) {
+	switch {
+	case breaker == nil:
+	case err == nil:
+		breaker.RecordSuccess()
+	case outage:
+		breaker.RecordFailure(err)
+	}
+}
+
+// isStravaOutage reports whether err means Strava itself is failing: a network error or a 5xx
+// response. Rate limiting and authorization errors are not outages.
+func isStravaOutage(err error) bool {
+	if strava.IsReauthRequired(err) {
+		return false
+	}
+	var networkErr *strava.NetworkError
+	if errors.As(err, &networkErr) {
+		return true
+	}
+	var apiErr *strava.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
+
+// isGoogleOutage reports whether err means the Sheets API itself is failing. Unrecognized
+// Sheets API errors, which include 5xx responses, are reported as network errors.
+func isGoogleOutage(err error) bool {
+	if google.IsReauthRequired(err) {
+		return false
+	}
+	var networkErr *google.NetworkError
+	if errors.As(err, &networkErr) {
+		return true
+	}
+	var apiErr *google.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
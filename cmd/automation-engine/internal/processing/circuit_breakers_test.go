@@ -0,0 +1,51 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/circuit"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestProcessUser_OpenCircuitSkipsProviders(t *testing.T) {
+	log := logger.New("test")
+	worker := NewWorker(automation.NewConfigService(mockConfiguredUserRepository{}, log), "id", "secret", "id", "secret", "", log)
+
+	googleBreaker := circuit.NewBreaker("google", 1, nil, log)
+	googleBreaker.RecordFailure(&google.NetworkError{Operation: "batch_update", Message: "503"})
+	worker.SetCircuitBreakers(circuit.NewBreaker("strava", 1, nil, log), googleBreaker)
+
+	// The circuit is checked before any client is built, so no request reaches Strava or Google
+	result := worker.ProcessUser(context.Background(), 7)
+	if result.ErrorType != "GOOGLE_UNAVAILABLE" || result.RequiresReauth {
+		t.Fatalf("Expected a provider unavailable failure, got %+v", result)
+	}
+}
+
+func TestProviderOutageClassification(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		outage bool
+		check  func(error) bool
+	}{
+		{"strava network error", &strava.NetworkError{Operation: "api_request"}, true, isStravaOutage},
+		{"strava 502", &strava.APIError{StatusCode: 502}, true, isStravaOutage},
+		{"strava rate limit", &strava.APIError{StatusCode: 429}, false, isStravaOutage},
+		{"strava revoked token", strava.ErrReauthRequired, false, isStravaOutage},
+		{"google unknown API error", &google.NetworkError{Operation: "append"}, true, isGoogleOutage},
+		{"google missing spreadsheet", &google.SheetsError{Type: "NOT_FOUND"}, false, isGoogleOutage},
+		{"google revoked token", &google.NetworkError{Operation: "token_refresh", Cause: errors.New("invalid_grant")}, false, isGoogleOutage},
+	}
+
+	for _, tt := range tests {
+		if got := tt.check(tt.err); got != tt.outage {
+			t.Errorf("%s: expected outage=%v, got %v", tt.name, tt.outage, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package processing
+
+import (
+	"context"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
+)
+
+// SetEventPublisher announces job outcomes and rejected credentials on the inter-service event
+// bus, so the notification service and other consumers react without polling or reading logs.
+// Publishing failures are logged and never fail a job.
+func (w *Worker) SetEventPublisher(publisher events.Publisher) {
+	w.eventPublisher = publisher
+}
+
+// PublishOutcome publishes a SyncCompleted or SyncFailed event for a finished job. It is called
+// once the run is recorded, so consumers reading the run history find it; deferred jobs have not
+// finished and publish nothing.
+func (w *Worker) PublishOutcome(ctx context.Context, result *ProcessingResult) {
+	if result.Deferred {
+		return
+	}
+
+	if result.Success {
+		w.publishEvent(ctx, events.SyncCompleted{
+			UserID:          result.UserID,
+			TraceID:         result.TraceID,
+			DryRun:          result.DryRun,
+			ActivitiesCount: result.ActivitiesCount,
+		})
+		return
+	}
+	w.publishEvent(ctx, events.SyncFailed{
+		UserID:    result.UserID,
+		TraceID:   result.TraceID,
+		DryRun:    result.DryRun,
+		ErrorType: result.ErrorType,
+		Error:     result.Error,
+	})
+}
+
+// publishEvent publishes payload when an event bus is configured
+func (w *Worker) publishEvent(ctx context.Context, payload events.Payload) {
+	if w.eventPublisher == nil {
+		return
+	}
+
+	if err := w.eventPublisher.Publish(ctx, payload); err != nil {
+		w.logger.Warn("⚠️ Failed to publish event",
+			"event_type", payload.EventType(),
+			"error", err)
+	}
+}
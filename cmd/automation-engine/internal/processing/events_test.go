@@ -0,0 +1,56 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// fakeEventPublisher records published events, failing every publish when err is set
+type fakeEventPublisher struct {
+	published []events.Payload
+	err       error
+}
+
+func (f *fakeEventPublisher) Publish(ctx context.Context, payload events.Payload) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, payload)
+	return nil
+}
+
+func TestPublishOutcome(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	publisher := &fakeEventPublisher{}
+	worker.SetEventPublisher(publisher)
+	ctx := context.Background()
+
+	worker.PublishOutcome(ctx, &ProcessingResult{UserID: 42, TraceID: "trace-1", Success: true, ActivitiesCount: 3})
+	worker.PublishOutcome(ctx, &ProcessingResult{UserID: 42, TraceID: "trace-2", ErrorType: "CONFIG_ERROR", Error: "no config"})
+	worker.PublishOutcome(ctx, &ProcessingResult{UserID: 42, TraceID: "trace-3", Deferred: true, ErrorType: ErrorTypeUserBusy})
+
+	want := []events.Payload{
+		events.SyncCompleted{UserID: 42, TraceID: "trace-1", ActivitiesCount: 3},
+		events.SyncFailed{UserID: 42, TraceID: "trace-2", ErrorType: "CONFIG_ERROR", Error: "no config"},
+	}
+	if len(publisher.published) != len(want) {
+		t.Fatalf("Expected %d events and none for the deferred job, got %+v", len(want), publisher.published)
+	}
+	for i := range want {
+		if publisher.published[i] != want[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want[i], publisher.published[i])
+		}
+	}
+}
+
+func TestPublishOutcome_IgnoresPublishFailures(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.PublishOutcome(context.Background(), &ProcessingResult{UserID: 42, Success: true}) // No bus
+
+	worker.SetEventPublisher(&fakeEventPublisher{err: errors.New("redis down")})
+	worker.PublishOutcome(context.Background(), &ProcessingResult{UserID: 42, Success: true})
+}
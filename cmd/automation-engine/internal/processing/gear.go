@@ -0,0 +1,102 @@
+package processing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// staleGearMaxAge accepts any cached gear, for when a refresh from Strava failed
+const staleGearMaxAge = 10 * 365 * 24 * time.Hour
+
+// GearCache is the local store of gear looked up on Strava
+type GearCache interface {
+	GetCachedGear(ctx context.Context, userID int, ids []string, maxAge time.Duration) (map[string]strava.Gear, error)
+	StoreGear(ctx context.Context, userID int, gear strava.Gear, fetchedAt time.Time) error
+}
+
+// gearFetcher looks up a piece of the athlete's gear on Strava
+type gearFetcher func(ctx context.Context, gearID string) (*strava.Gear, error)
+
+// SetGearCache enables gear name resolution after step 5. Gear looked up within maxAge is served
+// from the cache, so a run calls Strava at most once per piece of gear and day by default.
+func (w *Worker) SetGearCache(cache GearCache, maxAge time.Duration) {
+	w.gearCache = cache
+	w.gearCacheMaxAge = maxAge
+}
+
+// resolveGear fills in the gear name of activities recorded with gear. Gear missing from the cache
+// or older than the cache max age is fetched with fetch; a nil fetch only reads the cache. Gear is
+// resolved whether or not the user shows the Gear column, since the mileage summary reads the cache.
+// Failures are logged and leave the gear name empty; they never fail the run.
+func (w *Worker) resolveGear(ctx context.Context, userID int, activities []strava.Activity, fetch gearFetcher) {
+	if w.gearCache == nil {
+		return
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, activity := range activities {
+		if activity.GearID != "" && !seen[activity.GearID] {
+			seen[activity.GearID] = true
+			ids = append(ids, activity.GearID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	gear, err := w.gearCache.GetCachedGear(ctx, userID, ids, w.gearCacheMaxAge)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to read gear cache",
+			"user_id", userID,
+			"error", err)
+		gear = make(map[string]strava.Gear)
+	}
+
+	var failed []string
+	for _, id := range ids {
+		if _, ok := gear[id]; ok {
+			continue
+		}
+		if fetch == nil {
+			failed = append(failed, id)
+			continue
+		}
+
+		fetchedAt := time.Now()
+		fetched, err := fetch(ctx, id)
+		if err != nil {
+			w.logger.Warn("⚠️ Failed to look up gear on Strava",
+				"user_id", userID,
+				"gear_id", id,
+				"error", err)
+			failed = append(failed, id)
+			continue
+		}
+		gear[id] = *fetched
+		if err := w.gearCache.StoreGear(ctx, userID, *fetched, fetchedAt); err != nil {
+			w.logger.Warn("⚠️ Failed to write gear to cache",
+				"user_id", userID,
+				"gear_id", id,
+				"error", err)
+		}
+	}
+
+	// An outdated name beats an empty cell that would flip back on the next successful lookup
+	if len(failed) > 0 {
+		stale, err := w.gearCache.GetCachedGear(ctx, userID, failed, staleGearMaxAge)
+		if err == nil {
+			for id, g := range stale {
+				gear[id] = g
+			}
+		}
+	}
+
+	for i := range activities {
+		if g, ok := gear[activities[i].GearID]; ok {
+			activities[i].GearName = g.Name
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type mockGearCache struct {
+	fresh  map[string]strava.Gear
+	stale  map[string]strava.Gear
+	stored []strava.Gear
+}
+
+func (m *mockGearCache) GetCachedGear(ctx context.Context, userID int, ids []string, maxAge time.Duration) (map[string]strava.Gear, error) {
+	source := m.fresh
+	if maxAge == staleGearMaxAge {
+		source = m.stale
+	}
+	gear := make(map[string]strava.Gear)
+	for _, id := range ids {
+		if g, ok := source[id]; ok {
+			gear[id] = g
+		}
+	}
+	return gear, nil
+}
+
+func (m *mockGearCache) StoreGear(ctx context.Context, userID int, gear strava.Gear, fetchedAt time.Time) error {
+	m.stored = append(m.stored, gear)
+	return nil
+}
+
+func TestResolveGear(t *testing.T) {
+	cache := &mockGearCache{
+		fresh: map[string]strava.Gear{"g1": {ID: "g1", Name: "Daily Trainers"}},
+		stale: map[string]strava.Gear{"b3": {ID: "b3", Name: "Old Bike Name"}},
+	}
+	worker := NewWorker(nil, "", "", "", "", "", logger.New("test"))
+	worker.SetGearCache(cache, time.Hour)
+
+	var fetched []string
+	fetch := func(ctx context.Context, gearID string) (*strava.Gear, error) {
+		fetched = append(fetched, gearID)
+		if gearID == "b3" {
+			return nil, errors.New("strava unavailable")
+		}
+		return &strava.Gear{ID: gearID, Name: "Race Flats"}, nil
+	}
+
+	activities := []strava.Activity{
+		{ID: 1, GearID: "g1"},
+		{ID: 2, GearID: "g2"},
+		{ID: 3, GearID: "g2"},
+		{ID: 4, GearID: "b3"},
+		{ID: 5},
+	}
+	worker.resolveGear(context.Background(), 7, activities, fetch)
+
+	expected := []string{"Daily Trainers", "Race Flats", "Race Flats", "Old Bike Name", ""}
+	for i, name := range expected {
+		if activities[i].GearName != name {
+			t.Errorf("Activity %d: expected gear name %q, got %q", activities[i].ID, name, activities[i].GearName)
+		}
+	}
+	if len(fetched) != 2 || len(cache.stored) != 1 || cache.stored[0].ID != "g2" {
+		t.Errorf("Expected one lookup per uncached gear and the successful one cached, got fetched=%v stored=%+v", fetched, cache.stored)
+	}
+
+	// Without a fetcher only the cache is read
+	fetched = nil
+	replayed := []strava.Activity{{ID: 6, GearID: "g9"}}
+	worker.resolveGear(context.Background(), 7, replayed, nil)
+	if replayed[0].GearName != "" || len(fetched) != 0 {
+		t.Errorf("Expected uncached gear to stay unresolved on replay, got %+v", replayed[0])
+	}
+}
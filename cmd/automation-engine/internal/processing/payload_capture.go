@@ -0,0 +1,75 @@
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/capture"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/objectstore"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// captureStoreTimeout bounds storing a run's captured responses, which may happen after the
+// job's own context has ended
+const captureStoreTimeout = 30 * time.Second
+
+// PayloadStore stores captured Strava responses; *objectstore.GCSBucket satisfies it
+type PayloadStore interface {
+	Put(ctx context.Context, name, contentType string, data []byte) error
+}
+
+// SetPayloadCapture stores the raw Strava activity responses of every run that fetches from
+// Strava in store, one object per run named by capture.ObjectName, so the run can be replayed
+// with ProcessOptions.Replay. Activities served from the local cache are not captured.
+func (w *Worker) SetPayloadCapture(store PayloadStore) {
+	w.payloadStore = store
+}
+
+// captureOptions returns a recorder for the run's Strava responses and the client option that
+// feeds it, or nil when capture is disabled or the run is itself a replay
+func (w *Worker) captureOptions(opts ProcessOptions) (*capture.Recorder, []strava.Option) {
+	if w.payloadStore == nil || opts.Replay != nil {
+		return nil, nil
+	}
+	recorder := &capture.Recorder{}
+	return recorder, []strava.Option{strava.WithResponseRecorder(recorder)}
+}
+
+// storeCapture stores the responses recorded during the run. Failures are logged and never
+// fail the run.
+func (w *Worker) storeCapture(ctx context.Context, recorder *capture.Recorder, userID int, traceID string, startedAt time.Time) {
+	if recorder == nil {
+		return
+	}
+	payload := recorder.Payload(userID, traceID, startedAt)
+	if payload == nil {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to encode captured Strava responses",
+			"user_id", userID,
+			"error", err)
+		return
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), captureStoreTimeout)
+	defer cancel()
+
+	name := capture.ObjectName(userID, traceID, startedAt)
+	if err := w.payloadStore.Put(storeCtx, name, objectstore.ContentTypeJSON, data); err != nil {
+		w.logger.Warn("⚠️ Failed to store captured Strava responses",
+			"user_id", userID,
+			"trace_id", traceID,
+			"object", name,
+			"error", err)
+		return
+	}
+	w.logger.Debug("📦 Stored captured Strava responses",
+		"user_id", userID,
+		"trace_id", traceID,
+		"object", name,
+		"responses", len(payload.Responses))
+}
@@ -0,0 +1,69 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/capture"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type fakePayloadStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (f *fakePayloadStore) Put(ctx context.Context, name, contentType string, data []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.objects[name] = data
+	return nil
+}
+
+func TestWorker_PayloadCapture(t *testing.T) {
+	store := &fakePayloadStore{objects: make(map[string][]byte)}
+	worker := &Worker{logger: logger.New("test")}
+	if recorder, _ := worker.captureOptions(ProcessOptions{}); recorder != nil {
+		t.Fatal("Expected no capture without a payload store")
+	}
+
+	worker.SetPayloadCapture(store)
+	if recorder, _ := worker.captureOptions(ProcessOptions{Replay: []strava.Activity{}}); recorder != nil {
+		t.Error("Expected replays not to be captured again")
+	}
+	recorder, opts := worker.captureOptions(ProcessOptions{TraceID: "trace-1"})
+	if recorder == nil || len(opts) != 1 {
+		t.Fatalf("Expected a recorder and its client option, got %v %d", recorder, len(opts))
+	}
+
+	startedAt := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+
+	// Runs served from the activity cache record nothing and store nothing
+	worker.storeCapture(context.Background(), recorder, 7, "trace-1", startedAt)
+	if len(store.objects) != 0 {
+		t.Fatalf("Expected nothing stored without responses, got %v", store.objects)
+	}
+
+	recorder.RecordResponse("/athlete/activities?after=1&per_page=100", []byte(`[{"id":1,"name":"Morning Run"}]`))
+	worker.storeCapture(context.Background(), recorder, 7, "trace-1", startedAt)
+
+	data, ok := store.objects["strava/7/20261016T030000Z-trace-1.json"]
+	if !ok {
+		t.Fatalf("Expected the payload to be stored per user and run, got %v", store.objects)
+	}
+	payload, err := capture.Decode(data)
+	if err != nil {
+		t.Fatalf("Stored payload does not decode: %v", err)
+	}
+	if activities, err := payload.Activities(); err != nil || len(activities) != 1 || activities[0].Name != "Morning Run" {
+		t.Errorf("Expected the captured activity to replay, got %+v (%v)", activities, err)
+	}
+
+	// A store failure is logged and does not panic or fail the run
+	store.err = errors.New("bucket unavailable")
+	worker.storeCapture(context.Background(), recorder, 7, "trace-2", startedAt)
+}
@@ -0,0 +1,96 @@
+package processing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// ProviderConcurrency caps how many of the worker's jobs may be in a provider phase at once,
+// independently of how many jobs run concurrently; zero leaves a phase unlimited
+type ProviderConcurrency struct {
+	// StravaFetch limits jobs fetching activities from Strava, including backfill windows
+	StravaFetch int
+	// SheetsWrite limits jobs writing activity rows to Google Sheets
+	SheetsWrite int
+}
+
+// SetProviderConcurrency limits the jobs in the Strava fetch and Sheets write phases. The slots
+// are shared by every job of the worker, so ENGINE_WORKER_COUNT can be raised for jobs that
+// mostly wait while each provider still sees at most its limit of jobs. A job waits for a slot
+// with its own context, so the wait counts against the job timeout but not the step timeout.
+func (w *Worker) SetProviderConcurrency(limits ProviderConcurrency) {
+	w.stravaFetchSlots = newPhaseSlots(limits.StravaFetch)
+	w.sheetsWriteSlots = newPhaseSlots(limits.SheetsWrite)
+}
+
+// phaseSlots is a semaphore over a provider phase; a nil phaseSlots is unlimited
+type phaseSlots chan struct{}
+
+func newPhaseSlots(limit int) phaseSlots {
+	if limit <= 0 {
+		return nil
+	}
+	return make(phaseSlots, limit)
+}
+
+// acquire waits for a slot until ctx ends. The returned release must be called once the phase
+// is over; it is a no-op when no slot was taken.
+func (s phaseSlots) acquire(ctx context.Context) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, nil
+	default:
+	}
+
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, nil
+	case <-ctx.Done():
+		return func() {}, ctx.Err()
+	}
+}
+
+// enterPhase takes a slot of a provider phase for a job, logging when the job had to wait
+func (w *Worker) enterPhase(ctx context.Context, slots phaseSlots, userID int, step string) (release func(), err error) {
+	start := time.Now()
+	release, err = slots.acquire(ctx)
+	if waited := time.Since(start); waited >= time.Second {
+		w.logger.Info("⏳ Waited for a provider concurrency slot",
+			"user_id", userID,
+			"step", step,
+			"waited_ms", waited.Milliseconds(),
+			"acquired", err == nil)
+	}
+	return release, err
+}
+
+// phasedSink holds a Sheets write slot while a backfill adds pages to its sink, since adding a
+// page flushes full chunks to the sheet
+type phasedSink struct {
+	sink   backfillSink
+	worker *Worker
+	userID int
+}
+
+func (s phasedSink) Add(ctx context.Context, activities []strava.Activity) error {
+	release, err := s.worker.enterPhase(ctx, s.worker.sheetsWriteSlots, s.userID, StepSheetsWrite)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.sink.Add(ctx, activities)
+}
+
+func (s phasedSink) Flush(ctx context.Context) error {
+	release, err := s.worker.enterPhase(ctx, s.worker.sheetsWriteSlots, s.userID, StepSheetsWrite)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.sink.Flush(ctx)
+}
@@ -0,0 +1,105 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestPhaseSlots_LimitConcurrentJobs(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetProviderConcurrency(ProviderConcurrency{SheetsWrite: 2})
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := worker.enterPhase(context.Background(), worker.sheetsWriteSlots, 42, StepSheetsWrite)
+			if err != nil {
+				t.Errorf("enterPhase failed: %v", err)
+				return
+			}
+			defer release()
+
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("Expected at most 2 jobs writing at once, got %d", got)
+	}
+}
+
+func TestPhaseSlots_WaitEndsWithJob(t *testing.T) {
+	slots := newPhaseSlots(1)
+	release, err := slots.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	waitRelease, err := slots.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait to end with the job, got %v", err)
+	}
+	waitRelease() // A no-op, leaving the held slot alone
+
+	if len(slots) != 1 {
+		t.Errorf("Expected the first slot to stay held, got %d held", len(slots))
+	}
+}
+
+func TestPhaseSlots_ZeroIsUnlimited(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetProviderConcurrency(ProviderConcurrency{})
+	if worker.stravaFetchSlots != nil || worker.sheetsWriteSlots != nil {
+		t.Fatal("Expected no slots for zero limits")
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := worker.stravaFetchSlots.acquire(context.Background()); err != nil {
+			t.Fatalf("Expected unlimited slots, got %v", err)
+		}
+	}
+}
+
+func TestPhasedSink_HoldsWriteSlot(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetProviderConcurrency(ProviderConcurrency{SheetsWrite: 1})
+
+	// Another job is writing, so the backfill's page waits for it
+	release, _ := worker.sheetsWriteSlots.acquire(context.Background())
+	sink := phasedSink{sink: &fakeBackfillSink{}, worker: worker, userID: 42}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sink.Add(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the page to wait for the write slot, got %v", err)
+	}
+
+	release()
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(worker.sheetsWriteSlots) != 0 {
+		t.Errorf("Expected the write slot to be released after the flush")
+	}
+}
@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+)
+
+// Providers a reauth marker can be recorded for
+const (
+	reauthProviderStrava = "strava"
+	reauthProviderGoogle = "google"
+)
+
+// ReauthMarkers remembers, for a short time, that a provider rejected a user's credentials
+type ReauthMarkers interface {
+	MarkReauthRequired(ctx context.Context, userID int, provider, fingerprint string, ttl time.Duration) error
+	IsReauthRequired(ctx context.Context, userID int, provider, fingerprint string) (bool, error)
+}
+
+// SetReauthMarkers enables reauth markers. When a run fails because a provider requires
+// re-authorization, later jobs for the same user and credentials fail immediately for ttl instead
+// of each calling the provider, getting a 401 and attempting a refresh that cannot succeed.
+func (w *Worker) SetReauthMarkers(markers ReauthMarkers, ttl time.Duration) {
+	w.reauthMarkers = markers
+	w.reauthMarkerTTL = ttl
+}
+
+// reauthErrorTypes maps providers to the error type reported for a run that needs re-authorization
+var reauthErrorTypes = map[string]string{
+	reauthProviderStrava: "STRAVA_REAUTH_REQUIRED",
+	reauthProviderGoogle: "GOOGLE_REAUTH_REQUIRED",
+}
+
+// markedReauthProvider returns the provider whose credentials were recently rejected, or "" when
+// no marker matches the user's current credentials. Marker failures are logged and ignored.
+func (w *Worker) markedReauthProvider(ctx context.Context, userID int, config *automation.ProcessingConfig) string {
+	if w.reauthMarkers == nil {
+		return ""
+	}
+
+	for _, provider := range []string{reauthProviderStrava, reauthProviderGoogle} {
+		marked, err := w.reauthMarkers.IsReauthRequired(ctx, userID, provider, credentialFingerprint(config, provider))
+		if err != nil {
+			w.logger.Warn("⚠️ Failed to read reauth marker, calling provider",
+				"user_id", userID,
+				"provider", provider,
+				"error", err)
+			continue
+		}
+		if marked {
+			return provider
+		}
+	}
+	return ""
+}
+
+// markReauthRequired records that the run's credentials for the provider were rejected
+func (w *Worker) markReauthRequired(ctx context.Context, userID int, config *automation.ProcessingConfig, provider string) {
+	if w.reauthMarkers == nil {
+		return
+	}
+
+	if err := w.reauthMarkers.MarkReauthRequired(ctx, userID, provider, credentialFingerprint(config, provider), w.reauthMarkerTTL); err != nil {
+		w.logger.Warn("⚠️ Failed to store reauth marker",
+			"user_id", userID,
+			"provider", provider,
+			"error", err)
+	}
+}
+
+// credentialFingerprint identifies the user's refresh token for a provider without storing it
+func credentialFingerprint(config *automation.ProcessingConfig, provider string) string {
+	token := config.StravaRefreshToken
+	if provider == reauthProviderGoogle {
+		token = config.GoogleRefreshToken
+	}
+
+	h := sha256.New()
+	h.Write([]byte(provider + ":"))
+	token.Use(func(value []byte) { h.Write(value) })
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
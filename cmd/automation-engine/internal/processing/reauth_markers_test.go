@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
+)
+
+type mockReauthMarkers struct {
+	markers map[string]string // provider -> fingerprint
+	marked  int
+}
+
+func (m *mockReauthMarkers) MarkReauthRequired(ctx context.Context, userID int, provider, fingerprint string, ttl time.Duration) error {
+	m.markers[provider] = fingerprint
+	m.marked++
+	return nil
+}
+
+func (m *mockReauthMarkers) IsReauthRequired(ctx context.Context, userID int, provider, fingerprint string) (bool, error) {
+	return m.markers[provider] == fingerprint, nil
+}
+
+// mockConfiguredUserRepository returns a complete, automation-enabled configuration
+type mockConfiguredUserRepository struct{}
+
+func (mockConfiguredUserRepository) GetUserByID(ctx context.Context, userID int) (*database.User, error) {
+	return &database.User{ID: userID, AutomationEnabled: true}, nil
+}
+
+func (mockConfiguredUserRepository) GetProcessingConfigForUser(ctx context.Context, userID int) (*database.ProcessingTokens, error) {
+	athleteID := int64(42)
+	spreadsheetID := "sheet-1"
+	return &database.ProcessingTokens{
+		GoogleRefreshToken: secure.NewTokenString("google-refresh"),
+		StravaRefreshToken: secure.NewTokenString("strava-refresh"),
+		StravaAthleteID:    &athleteID,
+		SpreadsheetID:      &spreadsheetID,
+		Timezone:           "UTC",
+		Email:              "runner@example.com",
+	}, nil
+}
+
+func (mockConfiguredUserRepository) DecryptToken(encryptedToken []byte) (string, error) {
+	return string(encryptedToken), nil
+}
+
+func TestProcessUser_ReauthMarkerSkipsProviders(t *testing.T) {
+	log := logger.New("test")
+	worker := NewWorker(automation.NewConfigService(mockConfiguredUserRepository{}, log), "id", "secret", "id", "secret", "", log)
+
+	config := &automation.ProcessingConfig{StravaRefreshToken: secure.NewTokenString("strava-refresh")}
+	markers := &mockReauthMarkers{markers: map[string]string{
+		reauthProviderStrava: credentialFingerprint(config, reauthProviderStrava),
+	}}
+	worker.SetReauthMarkers(markers, time.Minute)
+
+	// The marker matches before any client is built, so no request reaches Strava or Google
+	result := worker.ProcessUser(context.Background(), 7)
+	if result.ErrorType != "STRAVA_REAUTH_REQUIRED" || !result.RequiresReauth {
+		t.Fatalf("Expected a reauth failure from the marker, got %+v", result)
+	}
+	if markers.marked != 0 {
+		t.Error("Expected a short-circuited run not to extend the marker")
+	}
+}
+
+func TestCredentialFingerprint(t *testing.T) {
+	before := &automation.ProcessingConfig{StravaRefreshToken: secure.NewTokenString("old"), GoogleRefreshToken: secure.NewTokenString("old")}
+	after := &automation.ProcessingConfig{StravaRefreshToken: secure.NewTokenString("new"), GoogleRefreshToken: secure.NewTokenString("old")}
+
+	if credentialFingerprint(before, reauthProviderStrava) == credentialFingerprint(after, reauthProviderStrava) {
+		t.Error("Expected reconnecting Strava to change its fingerprint")
+	}
+	if credentialFingerprint(before, reauthProviderGoogle) != credentialFingerprint(after, reauthProviderGoogle) {
+		t.Error("Expected the Google fingerprint to be unchanged")
+	}
+	if credentialFingerprint(before, reauthProviderStrava) == credentialFingerprint(before, reauthProviderGoogle) {
+		t.Error("Expected fingerprints to differ per provider")
+	}
+}
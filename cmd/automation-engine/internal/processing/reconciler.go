@@ -0,0 +1,262 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// ReconciliationInterval is how often each user's stored connection data is cross-checked
+const ReconciliationInterval = 7 * 24 * time.Hour
+
+// Drift detected by reconciliation
+const (
+	IssueConfigInvalid           = "config_invalid"
+	IssueStravaTokenInvalid      = "strava_token_invalid"
+	IssueStravaScopeMissing      = "strava_scope_missing"
+	IssueStravaAthleteMismatch   = "strava_athlete_mismatch"
+	IssueStravaCheckFailed       = "strava_check_failed"
+	IssueGoogleTokenInvalid      = "google_token_invalid"
+	IssueGoogleScopeMissing      = "google_scope_missing"
+	IssueGoogleCheckFailed       = "google_check_failed"
+	IssueSpreadsheetMissing      = "spreadsheet_missing"
+	IssueSpreadsheetAccessDenied = "spreadsheet_access_denied"
+)
+
+// Drift fixed automatically by reconciliation
+const (
+	FixStravaProfileUpdated = "strava_profile_updated"
+	FixSpreadsheetCleared   = "spreadsheet_cleared"
+)
+
+// sheetsScope is the OAuth scope the automation needs on the user's Google token
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// ReconciliationRepository persists reconciliation outcomes and drift fixes
+type ReconciliationRepository interface {
+	GetUserByID(ctx context.Context, userID int) (*database.User, error)
+	ListUsersDueForReconciliation(ctx context.Context, olderThan time.Time, limit int) ([]int, error)
+	UpdateStravaAthleteProfile(ctx context.Context, userID int, athleteName, profilePictureURL string) error
+	ClearSpreadsheetID(ctx context.Context, userID int) error
+	RecordReconciliation(ctx context.Context, userID int, issues []string, stravaReauthRequired, googleReauthRequired bool) error
+}
+
+// ReconciliationReport describes the drift found and fixed for a single user
+type ReconciliationReport struct {
+	UserID               int      `json:"user_id"`
+	Issues               []string `json:"issues,omitempty"`
+	Fixes                []string `json:"fixes,omitempty"`
+	StravaReauthRequired bool     `json:"strava_reauth_required"`
+	GoogleReauthRequired bool     `json:"google_reauth_required"`
+}
+
+// Reconciler runs the low-priority background job that cross-checks the users table against
+// provider reality (athlete identity, spreadsheet existence, token scopes). It fixes drift it
+// can repair on its own and flags the rest, so broken connections surface before syncs silently stop
+type Reconciler struct {
+	worker     *Worker
+	repository ReconciliationRepository
+	logger     *logger.Logger
+}
+
+// NewReconciler creates a reconciler that reuses the worker's configuration and API client setup
+func NewReconciler(worker *Worker, repository ReconciliationRepository, logger *logger.Logger) *Reconciler {
+	return &Reconciler{
+		worker:     worker,
+		repository: repository,
+		logger:     logger.WithContext("component", "reconciler"),
+	}
+}
+
+// ReconcileDueUsers reconciles up to limit users whose last reconciliation is older than the interval
+func (r *Reconciler) ReconcileDueUsers(ctx context.Context, limit int) []*ReconciliationReport {
+	userIDs, err := r.repository.ListUsersDueForReconciliation(ctx, time.Now().Add(-ReconciliationInterval), limit)
+	if err != nil {
+		r.logger.Error("❌ Failed to list users due for reconciliation",
+			"error", err)
+		return nil
+	}
+
+	if len(userIDs) == 0 {
+		r.logger.Debug("No users due for reconciliation")
+		return nil
+	}
+
+	reports := make([]*ReconciliationReport, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		reports = append(reports, r.ReconcileUser(ctx, userID))
+	}
+
+	return reports
+}
+
+// ReconcileUser cross-checks a single user's stored connection data against Strava and Google
+func (r *Reconciler) ReconcileUser(ctx context.Context, userID int) *ReconciliationReport {
+	startTime := time.Now()
+	report := &ReconciliationReport{UserID: userID}
+
+	r.logger.Debug("🔎 Starting background reconciliation for user",
+		"user_id", userID)
+
+	config, err := r.worker.configService.GetProcessingConfigForUser(ctx, userID)
+	if err != nil {
+		r.logger.Warn("⚠️ Skipping provider checks, user configuration is incomplete",
+			"user_id", userID,
+			"error", err)
+		report.Issues = append(report.Issues, IssueConfigInvalid)
+		r.record(ctx, report)
+		return report
+	}
+	defer r.worker.tokens.Hold(config.Tokens()...)()
+
+	r.reconcileStrava(ctx, r.worker.newStravaClient(config), *config.StravaAthleteID, report)
+	r.reconcileGoogle(ctx, r.worker.newSheetsClient(config), config.SpreadsheetID, report)
+	r.record(ctx, report)
+
+	r.logger.Info("✅ Completed background reconciliation for user",
+		"user_id", userID,
+		"reconciliation_report", map[string]interface{}{
+			"issues":                 report.Issues,
+			"fixes":                  report.Fixes,
+			"strava_reauth_required": report.StravaReauthRequired,
+			"google_reauth_required": report.GoogleReauthRequired,
+			"duration_ms":            time.Since(startTime).Milliseconds(),
+		})
+
+	return report
+}
+
+// reconcileStrava checks the athlete identity, keeps the stored profile current and verifies activity scope
+func (r *Reconciler) reconcileStrava(ctx context.Context, client *strava.Client, storedAthleteID int64, report *ReconciliationReport) {
+	profile, err := client.GetAthleteProfile(ctx)
+	if err != nil {
+		r.classifyStravaError(err, report)
+		return
+	}
+
+	athleteID, _ := profile["id"].(float64)
+	if int64(athleteID) != storedAthleteID {
+		// The token belongs to a different athlete than the one stored; never overwrite silently
+		r.logger.Warn("⚠️ Strava token belongs to a different athlete than stored",
+			"user_id", report.UserID,
+			"stored_athlete_id", storedAthleteID,
+			"token_athlete_id", int64(athleteID))
+		report.Issues = append(report.Issues, IssueStravaAthleteMismatch)
+		return
+	}
+
+	firstName, _ := profile["firstname"].(string)
+	lastName, _ := profile["lastname"].(string)
+	athleteName := strings.TrimSpace(firstName + " " + lastName)
+	pictureURL, _ := profile["profile"].(string)
+
+	user, err := r.repository.GetUserByID(ctx, report.UserID)
+	if err == nil && user != nil && athleteName != "" &&
+		(stringValue(user.StravaAthleteName) != athleteName || stringValue(user.StravaProfilePictureURL) != pictureURL) {
+		if err := r.repository.UpdateStravaAthleteProfile(ctx, report.UserID, athleteName, pictureURL); err != nil {
+			r.logger.Error("❌ Failed to update drifted Strava athlete profile",
+				"user_id", report.UserID,
+				"error", err)
+		} else {
+			report.Fixes = append(report.Fixes, FixStravaProfileUpdated)
+		}
+	}
+
+	if err := client.CheckActivityReadAccess(ctx); err != nil {
+		r.classifyStravaError(err, report)
+	}
+}
+
+// reconcileGoogle verifies the Sheets scope is still granted and the configured spreadsheet still exists
+func (r *Reconciler) reconcileGoogle(ctx context.Context, client *google.SheetsClient, spreadsheetID string, report *ReconciliationReport) {
+	scopes, err := client.GrantedScopes(ctx)
+	if err != nil {
+		if google.IsReauthRequired(err) {
+			report.Issues = append(report.Issues, IssueGoogleTokenInvalid)
+			report.GoogleReauthRequired = true
+			return
+		}
+		report.Issues = append(report.Issues, IssueGoogleCheckFailed)
+	} else if !containsString(scopes, sheetsScope) {
+		report.Issues = append(report.Issues, IssueGoogleScopeMissing)
+		report.GoogleReauthRequired = true
+		return
+	}
+
+	_, err = client.GetSpreadsheetInfo(ctx, spreadsheetID)
+	if err == nil {
+		return
+	}
+
+	var sheetsErr *google.SheetsError
+	switch {
+	case google.IsReauthRequired(err):
+		report.Issues = append(report.Issues, IssueGoogleTokenInvalid)
+		report.GoogleReauthRequired = true
+	case errors.As(err, &sheetsErr) && sheetsErr.Type == "NOT_FOUND":
+		// The spreadsheet was deleted: clear it so the dashboard asks the user to configure a new one
+		report.Issues = append(report.Issues, IssueSpreadsheetMissing)
+		if err := r.repository.ClearSpreadsheetID(ctx, report.UserID); err != nil {
+			r.logger.Error("❌ Failed to clear missing spreadsheet",
+				"user_id", report.UserID,
+				"spreadsheet_id", spreadsheetID,
+				"error", err)
+		} else {
+			report.Fixes = append(report.Fixes, FixSpreadsheetCleared)
+		}
+	case errors.As(err, &sheetsErr) && sheetsErr.Type == "PERMISSION_DENIED":
+		report.Issues = append(report.Issues, IssueSpreadsheetAccessDenied)
+	default:
+		report.Issues = append(report.Issues, IssueGoogleCheckFailed)
+	}
+}
+
+// classifyStravaError maps a Strava API error to a reconciliation issue
+func (r *Reconciler) classifyStravaError(err error, report *ReconciliationReport) {
+	var authErr *strava.AuthError
+	switch {
+	case strava.IsReauthRequired(err):
+		report.Issues = append(report.Issues, IssueStravaTokenInvalid)
+		report.StravaReauthRequired = true
+	case errors.As(err, &authErr):
+		report.Issues = append(report.Issues, IssueStravaScopeMissing)
+		report.StravaReauthRequired = true
+	default:
+		report.Issues = append(report.Issues, IssueStravaCheckFailed)
+	}
+}
+
+// record persists the report; failures only affect when the user is checked next
+func (r *Reconciler) record(ctx context.Context, report *ReconciliationReport) {
+	err := r.repository.RecordReconciliation(ctx, report.UserID, report.Issues, report.StravaReauthRequired, report.GoogleReauthRequired)
+	if err != nil {
+		r.logger.Error("❌ Failed to record reconciliation result",
+			"user_id", report.UserID,
+			"error", err)
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,109 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// mockReconciliationRepository implements both the automation user repository and the reconciliation repository
+type mockReconciliationRepository struct {
+	users    map[int]*database.User
+	dueUsers []int
+	recorded map[int][]string
+}
+
+func newMockReconciliationRepository() *mockReconciliationRepository {
+	return &mockReconciliationRepository{
+		users:    make(map[int]*database.User),
+		recorded: make(map[int][]string),
+	}
+}
+
+func (m *mockReconciliationRepository) GetUserByID(ctx context.Context, userID int) (*database.User, error) {
+	return m.users[userID], nil
+}
+
+func (m *mockReconciliationRepository) GetProcessingConfigForUser(ctx context.Context, userID int) (*database.ProcessingTokens, error) {
+	// Incomplete configuration: no tokens or spreadsheet
+	return &database.ProcessingTokens{Email: "runner@example.com", Timezone: "UTC"}, nil
+}
+
+func (m *mockReconciliationRepository) DecryptToken(encryptedToken []byte) (string, error) {
+	return string(encryptedToken), nil
+}
+
+func (m *mockReconciliationRepository) ListUsersDueForReconciliation(ctx context.Context, olderThan time.Time, limit int) ([]int, error) {
+	return m.dueUsers, nil
+}
+
+func (m *mockReconciliationRepository) UpdateStravaAthleteProfile(ctx context.Context, userID int, athleteName, profilePictureURL string) error {
+	return nil
+}
+
+func (m *mockReconciliationRepository) ClearSpreadsheetID(ctx context.Context, userID int) error {
+	return nil
+}
+
+func (m *mockReconciliationRepository) RecordReconciliation(ctx context.Context, userID int, issues []string, stravaReauthRequired, googleReauthRequired bool) error {
+	m.recorded[userID] = issues
+	return nil
+}
+
+func TestReconciler_RecordsIncompleteConfiguration(t *testing.T) {
+	log := logger.New("test")
+	repo := newMockReconciliationRepository()
+	repo.users[7] = &database.User{ID: 7, AutomationEnabled: true}
+	repo.dueUsers = []int{7}
+
+	worker := NewWorker(automation.NewConfigService(repo, log), "", "", "", "", "", log)
+	reconciler := NewReconciler(worker, repo, log)
+
+	reports := reconciler.ReconcileDueUsers(context.Background(), 10)
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report, got %d", len(reports))
+	}
+
+	issues, ok := repo.recorded[7]
+	if !ok {
+		t.Fatal("Expected reconciliation to be recorded for user 7")
+	}
+	if len(issues) != 1 || issues[0] != IssueConfigInvalid {
+		t.Errorf("Expected [%s], got %v", IssueConfigInvalid, issues)
+	}
+}
+
+func TestReconciler_ClassifyStravaError(t *testing.T) {
+	reconciler := &Reconciler{logger: logger.New("test")}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedIssue  string
+		expectedReauth bool
+	}{
+		{"Invalid refresh token", strava.ErrReauthRequired, IssueStravaTokenInvalid, true},
+		{"Missing scope", &strava.AuthError{Type: "FORBIDDEN", Message: "forbidden"}, IssueStravaScopeMissing, true},
+		{"Transient failure", errors.New("connection reset"), IssueStravaCheckFailed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &ReconciliationReport{}
+			reconciler.classifyStravaError(tt.err, report)
+
+			if len(report.Issues) != 1 || report.Issues[0] != tt.expectedIssue {
+				t.Errorf("Expected issue %s, got %v", tt.expectedIssue, report.Issues)
+			}
+			if report.StravaReauthRequired != tt.expectedReauth {
+				t.Errorf("Expected reauth=%v, got %v", tt.expectedReauth, report.StravaReauthRequired)
+			}
+		})
+	}
+}
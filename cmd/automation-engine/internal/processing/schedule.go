@@ -0,0 +1,50 @@
+package processing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+// RunSchedule reads users and records when they are next synced automatically
+type RunSchedule interface {
+	GetUserByID(ctx context.Context, id int) (*database.User, error)
+	UpdateNextRunAt(ctx context.Context, userID int, nextRunAt *time.Time) error
+}
+
+// SetRunSchedule keeps users' next_run_at current: after each job the user's next run moves to
+// their next daily run, in the timezone they have at that point, so timezone changes take effect
+// from the following run.
+func (w *Worker) SetRunSchedule(schedule RunSchedule) {
+	w.runSchedule = schedule
+}
+
+// ScheduleNextRun moves the user's next run to their next daily run after a finished job. Dry
+// runs and deferred jobs leave the schedule alone, as do users whose automation is off.
+// Failures are logged and never fail the job.
+func (w *Worker) ScheduleNextRun(ctx context.Context, result *ProcessingResult) {
+	if w.runSchedule == nil || result.DryRun || result.Deferred {
+		return
+	}
+
+	user, err := w.runSchedule.GetUserByID(ctx, result.UserID)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to read user to schedule the next run",
+			"user_id", result.UserID,
+			"error", err)
+		return
+	}
+	if user == nil || !user.AutomationEnabled {
+		return
+	}
+
+	next := automation.NextScheduledRun(user.Timezone, time.Now())
+	if err := w.runSchedule.UpdateNextRunAt(ctx, result.UserID, &next); err != nil {
+		w.logger.Warn("⚠️ Failed to schedule the next run",
+			"user_id", result.UserID,
+			"next_run_at", next,
+			"error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package processing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// mockRunSchedule serves users and records the next runs scheduled for them
+type mockRunSchedule struct {
+	users     map[int]*database.User
+	scheduled map[int]time.Time
+}
+
+func (m *mockRunSchedule) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return m.users[id], nil
+}
+
+func (m *mockRunSchedule) UpdateNextRunAt(ctx context.Context, userID int, nextRunAt *time.Time) error {
+	m.scheduled[userID] = *nextRunAt
+	return nil
+}
+
+func TestScheduleNextRun(t *testing.T) {
+	log := logger.New("test")
+	worker := NewWorker(automation.NewConfigService(mockConfiguredUserRepository{}, log), "id", "secret", "id", "secret", "", log)
+	schedule := &mockRunSchedule{
+		users: map[int]*database.User{
+			1: {ID: 1, AutomationEnabled: true, Timezone: "Europe/Madrid"},
+			2: {ID: 2, AutomationEnabled: false, Timezone: "UTC"},
+		},
+		scheduled: map[int]time.Time{},
+	}
+	worker.SetRunSchedule(schedule)
+
+	before := time.Now()
+	worker.ScheduleNextRun(context.Background(), &ProcessingResult{UserID: 1, Success: true})
+	want := automation.NextScheduledRun("Europe/Madrid", before)
+	if next, ok := schedule.scheduled[1]; !ok || !next.Equal(want) {
+		t.Errorf("Expected the next daily run %v, got %v", want, next)
+	}
+
+	schedule.scheduled = map[int]time.Time{}
+	worker.ScheduleNextRun(context.Background(), &ProcessingResult{UserID: 2, Success: true})
+	worker.ScheduleNextRun(context.Background(), &ProcessingResult{UserID: 1, DryRun: true})
+	worker.ScheduleNextRun(context.Background(), &ProcessingResult{UserID: 1, Deferred: true})
+	worker.ScheduleNextRun(context.Background(), &ProcessingResult{UserID: 3})
+	if len(schedule.scheduled) != 0 {
+		t.Errorf("Expected disabled users, dry runs, deferred jobs and unknown users to be left alone, got %v", schedule.scheduled)
+	}
+}
@@ -0,0 +1,65 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorTypeStepTimeout is reported for jobs that failed because one step ran past its own
+// timeout while the job still had time left, pointing at a slow provider rather than a hung job
+const ErrorTypeStepTimeout = "STEP_TIMEOUT"
+
+// Steps with their own timeout, as recorded in ProcessingResult.TimedOutStep
+const (
+	StepConfigFetch = "config_fetch"
+	StepStravaFetch = "strava_fetch"
+	StepSheetsWrite = "sheets_write"
+)
+
+// StepTimeouts bounds the steps of a sync job that call out to a dependency. Each step gets at
+// most its timeout and never more than what is left of the job's deadline; zero leaves a step
+// bounded by the job deadline only.
+type StepTimeouts struct {
+	ConfigFetch time.Duration
+	StravaFetch time.Duration
+	SheetsWrite time.Duration
+}
+
+// DefaultStepTimeouts returns the step timeouts used unless SetStepTimeouts is called
+func DefaultStepTimeouts() StepTimeouts {
+	return StepTimeouts{
+		ConfigFetch: 10 * time.Second,
+		StravaFetch: 2 * time.Minute,
+		SheetsWrite: 3 * time.Minute,
+	}
+}
+
+// SetStepTimeouts replaces the per-step timeouts of sync jobs
+func (w *Worker) SetStepTimeouts(timeouts StepTimeouts) {
+	w.stepTimeouts = timeouts
+}
+
+// stepContext derives the context of a step from the job context
+func stepContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// markStepTimeout records step on a failed result when stepCtx ran out of time. If the job
+// itself still had time left the step's own timeout fired and the failure becomes a
+// STEP_TIMEOUT; otherwise the job deadline did and ClassifyCancellation reports a JOB_TIMEOUT.
+func markStepTimeout(jobCtx, stepCtx context.Context, step string, timeout time.Duration, result *ProcessingResult) {
+	if !errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	result.TimedOutStep = step
+	if jobCtx.Err() != nil {
+		return
+	}
+	result.ErrorType = ErrorTypeStepTimeout
+	result.Error = fmt.Sprintf("The %s step ran past its %s timeout: %s", step, timeout, result.Error)
+}
@@ -0,0 +1,57 @@
+package processing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkStepTimeout(t *testing.T) {
+	t.Run("step timeout with time left in the job", func(t *testing.T) {
+		jobCtx, cancelJob := context.WithTimeout(context.Background(), time.Minute)
+		defer cancelJob()
+		stepCtx, cancelStep := stepContext(jobCtx, time.Millisecond)
+		<-stepCtx.Done()
+		cancelStep()
+
+		result := &ProcessingResult{ErrorType: "STRAVA_FETCH_ERROR", Error: "fetch failed"}
+		markStepTimeout(jobCtx, stepCtx, StepStravaFetch, time.Millisecond, result)
+		ClassifyCancellation(jobCtx, result)
+
+		if result.ErrorType != ErrorTypeStepTimeout || result.TimedOutStep != StepStravaFetch {
+			t.Errorf("Expected a strava_fetch step timeout, got %q in step %q", result.ErrorType, result.TimedOutStep)
+		}
+		if !strings.Contains(result.Error, "fetch failed") {
+			t.Errorf("Expected the step's error to be kept, got %q", result.Error)
+		}
+	})
+
+	t.Run("job deadline reached during the step", func(t *testing.T) {
+		jobCtx, cancelJob := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancelJob()
+		stepCtx, cancelStep := stepContext(jobCtx, time.Minute)
+		<-stepCtx.Done()
+		cancelStep()
+
+		result := &ProcessingResult{ErrorType: "SHEETS_WRITE_ERROR", Error: "write failed"}
+		markStepTimeout(jobCtx, stepCtx, StepSheetsWrite, time.Minute, result)
+		ClassifyCancellation(jobCtx, result)
+
+		if result.ErrorType != ErrorTypeJobTimeout || result.TimedOutStep != StepSheetsWrite {
+			t.Errorf("Expected a job timeout during sheets_write, got %q in step %q", result.ErrorType, result.TimedOutStep)
+		}
+	})
+
+	t.Run("step failure before its timeout", func(t *testing.T) {
+		stepCtx, cancelStep := stepContext(context.Background(), time.Minute)
+		cancelStep()
+
+		result := &ProcessingResult{ErrorType: "CONFIG_ERROR", Error: "not found"}
+		markStepTimeout(context.Background(), stepCtx, StepConfigFetch, time.Minute, result)
+
+		if result.ErrorType != "CONFIG_ERROR" || result.TimedOutStep != "" {
+			t.Errorf("Expected the config error to be kept, got %q in step %q", result.ErrorType, result.TimedOutStep)
+		}
+	})
+}
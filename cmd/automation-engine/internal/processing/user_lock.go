@@ -0,0 +1,118 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrorTypeUserBusy is reported for jobs deferred because another job is processing the same user
+const ErrorTypeUserBusy = "USER_BUSY"
+
+// userBusyRetryDelay is how long a job that found its user locked waits before running again
+const userBusyRetryDelay = time.Minute
+
+// userLockReleaseTimeout bounds releasing a lock after the job's context has ended
+const userLockReleaseTimeout = 5 * time.Second
+
+// UserLocks is a distributed lock per user, held while a job processes the user
+type UserLocks interface {
+	AcquireUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error)
+	RenewUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error)
+	ReleaseUserLock(ctx context.Context, userID int, owner string) error
+}
+
+// SetUserLocks makes jobs that write a user's sheet hold the user's lock, so a manual and a
+// scheduled sync for the same user cannot run concurrently and write the same rows twice. The
+// lock expires ttl after its last renewal; it is renewed every ttl/3 while the job runs. A job
+// that finds the lock held is deferred by a minute. Dry runs never write and take no lock.
+func (w *Worker) SetUserLocks(locks UserLocks, ttl time.Duration) {
+	w.userLocks = locks
+	w.userLockTTL = ttl
+}
+
+// lockUser takes the user's lock for a job. It returns false when another job holds the lock.
+// Otherwise the returned context is canceled if the lock is lost, so the job stops writing, and
+// release must be called when the job ends. Lock failures are logged and the job runs unlocked.
+func (w *Worker) lockUser(ctx context.Context, userID int) (jobCtx context.Context, release func(), acquired bool) {
+	if w.userLocks == nil {
+		return ctx, func() {}, true
+	}
+
+	owner := uuid.New().String()
+	acquired, err := w.userLocks.AcquireUserLock(ctx, userID, owner, w.userLockTTL)
+	if err != nil {
+		w.logger.Warn("⚠️ Failed to take user lock, processing without it",
+			"user_id", userID,
+			"error", err)
+		return ctx, func() {}, true
+	}
+	if !acquired {
+		return ctx, func() {}, false
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	stopRenewal := make(chan struct{})
+	renewalDone := make(chan struct{})
+	go func() {
+		defer close(renewalDone)
+		w.renewUserLock(jobCtx, cancel, userID, owner, stopRenewal)
+	}()
+
+	release = func() {
+		close(stopRenewal)
+		<-renewalDone
+		cancel(nil)
+
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), userLockReleaseTimeout)
+		defer cancelRelease()
+		if err := w.userLocks.ReleaseUserLock(releaseCtx, userID, owner); err != nil {
+			w.logger.Warn("⚠️ Failed to release user lock, it expires on its own",
+				"user_id", userID,
+				"error", err)
+		}
+	}
+	return jobCtx, release, true
+}
+
+// renewUserLock extends the lock every third of its TTL until stop is closed, canceling the job
+// once the lock is lost to expiry. Renewal errors are retried on the next tick.
+func (w *Worker) renewUserLock(ctx context.Context, cancel context.CancelCauseFunc, userID int, owner string, stop <-chan struct{}) {
+	ticker := time.NewTicker(w.userLockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewed, err := w.userLocks.RenewUserLock(ctx, userID, owner, w.userLockTTL)
+		if err != nil {
+			w.logger.Warn("⚠️ Failed to renew user lock",
+				"user_id", userID,
+				"error", err)
+			continue
+		}
+		if !renewed {
+			w.logger.Error("❌ User lock lost, stopping the job",
+				"user_id", userID)
+			cancel(fmt.Errorf("user lock for user %d was lost", userID))
+			return
+		}
+	}
+}
+
+// deferBusy marks result as deferred because another job holds the user's lock
+func deferBusy(result *ProcessingResult) {
+	retryAt := time.Now().Add(userBusyRetryDelay)
+	result.Deferred = true
+	result.DeferredUntil = &retryAt
+	result.ErrorType = ErrorTypeUserBusy
+	result.Error = fmt.Sprintf("Another sync is processing this user; the job runs again at %s", retryAt.Format(time.RFC3339))
+}
@@ -0,0 +1,119 @@
+package processing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// fakeUserLocks holds locks in memory; renewals fail once lost is set
+type fakeUserLocks struct {
+	mu       sync.Mutex
+	owners   map[int]string
+	lost     bool
+	renewals int
+	released int
+}
+
+func newFakeUserLocks() *fakeUserLocks {
+	return &fakeUserLocks{owners: make(map[int]string)}
+}
+
+func (f *fakeUserLocks) AcquireUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, held := f.owners[userID]; held {
+		return false, nil
+	}
+	f.owners[userID] = owner
+	return true, nil
+}
+
+func (f *fakeUserLocks) RenewUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renewals++
+	return !f.lost && f.owners[userID] == owner, nil
+}
+
+func (f *fakeUserLocks) ReleaseUserLock(ctx context.Context, userID int, owner string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.owners[userID] == owner {
+		delete(f.owners, userID)
+		f.released++
+	}
+	return nil
+}
+
+func TestWorker_ProcessUserDefersWhileUserIsLocked(t *testing.T) {
+	locks := newFakeUserLocks()
+	locks.owners[7] = "another-job"
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetUserLocks(locks, time.Minute)
+
+	// The job returns before reading the user's configuration
+	result := worker.ProcessUserWithOptions(context.Background(), 7, ProcessOptions{TraceID: "trace-1"})
+	if !result.Deferred || result.ErrorType != ErrorTypeUserBusy {
+		t.Fatalf("Expected the job to be deferred as USER_BUSY, got %+v", result)
+	}
+	if result.DeferredUntil == nil || time.Until(*result.DeferredUntil) > userBusyRetryDelay {
+		t.Errorf("Expected a retry within %s, got %v", userBusyRetryDelay, result.DeferredUntil)
+	}
+
+	report, err := worker.Backfill(context.Background(), 7, time.Time{})
+	if err != nil || !report.Deferred || report.DeferReason != ErrorTypeUserBusy {
+		t.Errorf("Expected the backfill to be deferred as USER_BUSY, got %+v (%v)", report, err)
+	}
+}
+
+func TestWorker_LockUserRenewsAndReleases(t *testing.T) {
+	locks := newFakeUserLocks()
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetUserLocks(locks, 30*time.Millisecond)
+
+	ctx, release, acquired := worker.lockUser(context.Background(), 7)
+	if !acquired {
+		t.Fatal("Expected to acquire a free lock")
+	}
+	if _, _, again := worker.lockUser(context.Background(), 7); again {
+		t.Error("Expected a second job for the same user to be refused")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatal("Expected the job to keep running while the lock is renewed")
+	}
+	release()
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if locks.renewals == 0 || locks.released != 1 || len(locks.owners) != 0 {
+		t.Errorf("Expected the lock to be renewed and released, got %d renewals, %d releases", locks.renewals, locks.released)
+	}
+}
+
+func TestWorker_LockUserCancelsJobWhenLockIsLost(t *testing.T) {
+	locks := newFakeUserLocks()
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetUserLocks(locks, 30*time.Millisecond)
+
+	ctx, release, _ := worker.lockUser(context.Background(), 7)
+	defer release()
+
+	locks.mu.Lock()
+	locks.lost = true
+	locks.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the job to be canceled once its lock was lost")
+	}
+	if context.Cause(ctx) == context.Canceled {
+		t.Error("Expected the cancellation to name the lost lock")
+	}
+}
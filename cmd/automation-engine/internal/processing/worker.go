@@ -2,13 +2,23 @@ package processing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/circuit"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/destination"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
 )
 
 // Worker handles processing automation jobs for individual users
@@ -26,12 +36,86 @@ type Worker struct {
 	configService       *automation.ConfigService
 	logger              *logger.Logger
 	
-	// OAuth credentials for API clients
+	// OAuth credentials for API clients; the secrets may be rotated (see SetStravaClientSecret)
+	credentialsMu       sync.RWMutex
 	stravaClientID      string
 	stravaClientSecret  string
 	googleClientID      string
 	googleClientSecret  string
 	googleRedirectURL   string
+	
+	// Optional local cache of fetched activities (see SetActivityCache)
+	activityCache       ActivityCache
+	activityCacheMaxAge time.Duration
+	
+	// Optional cache of gear names looked up on Strava (see SetGearCache)
+	gearCache           GearCache
+	gearCacheMaxAge     time.Duration
+	
+	// Optional checkpoints for resuming historical backfills (see SetBackfillCheckpoints)
+	backfillCheckpoints BackfillCheckpoints
+	backfillSlice       time.Duration
+	
+	// Optional short-lived markers of rejected credentials (see SetReauthMarkers)
+	reauthMarkers       ReauthMarkers
+	reauthMarkerTTL     time.Duration
+	
+	// Fetch window of regular syncs (see SetLookbackDays)
+	lookbackDays        int
+	
+	// Provider base URLs (see SetProviderEndpoints)
+	stravaEndpoints     strava.Endpoints
+	googleEndpoints     google.Endpoints
+	
+	// Read back written sheet rows (see SetWriteVerification)
+	verifyWrites        bool
+	
+	// Optional provider circuit breakers (see SetCircuitBreakers)
+	stravaBreaker       *circuit.Breaker
+	googleBreaker       *circuit.Breaker
+	
+	// Decrypted tokens of the jobs in progress, zeroed on completion or eviction
+	tokens              *secure.TokenCache
+	
+	// Optional per-user daily processing budget (see SetDailyBudget)
+	budgetStore         BudgetStore
+	budgetLimits        BudgetLimits
+	
+	// Optional per-user daily ceiling on Strava API calls (see SetStravaCallBudget)
+	stravaCallCounter   strava.CallCounter
+	stravaCallLimit     int
+	
+	// Optional limit on Sheets writes shared across engine instances (see SetSheetsWriteLimit)
+	sheetsWriteLimiter  *sheetsWriteLimiter
+	
+	// Optional caps on the jobs in the Strava fetch and Sheets write phases (see SetProviderConcurrency)
+	stravaFetchSlots    phaseSlots
+	sheetsWriteSlots    phaseSlots
+	
+	// Optional inter-service event bus for job outcomes (see SetEventPublisher)
+	eventPublisher      events.Publisher
+	
+	// Optional coordination of token refreshes across jobs (see SetTokenRefresher)
+	tokenRefresher      strava.TokenRefresher
+	
+	// Optional cache of athlete profiles and spreadsheet metadata (see SetResponseCache)
+	responseCache       *respcache.Cache
+	
+	// Optional distributed lock per user (see SetUserLocks)
+	userLocks           UserLocks
+	userLockTTL         time.Duration
+	
+	// Optional per-job record of completed processing steps (see SetJobCheckpoints)
+	jobCheckpoints      JobCheckpoints
+	
+	// Timeouts of the config fetch, Strava fetch and Sheets write steps (see SetStepTimeouts)
+	stepTimeouts        StepTimeouts
+	
+	// Optional store of raw Strava responses for replay (see SetPayloadCapture)
+	payloadStore        PayloadStore
+	
+	// Optional record of users' next automated runs (see SetRunSchedule)
+	runSchedule         RunSchedule
 }
 
 // NewWorker creates a new processing worker with required dependencies
@@ -48,10 +132,90 @@ func NewWorker(
 		googleClientID:      googleClientID,
 		googleClientSecret:  googleClientSecret,
 		googleRedirectURL:   googleRedirectURL,
+		lookbackDays:        DefaultLookbackDays,
+		stepTimeouts:        DefaultStepTimeouts(),
+		stravaEndpoints:     strava.DefaultEndpoints(),
+		googleEndpoints:     google.DefaultEndpoints(),
+		tokens:              secure.NewTokenCache(secure.DefaultTokenCacheSize),
 		logger:              logger.WithContext("component", "automation_worker"),
 	}
 }
 
+// DefaultLookbackDays is the fetch window of a regular sync
+const DefaultLookbackDays = 7
+
+// SetLookbackDays sets how many days back regular syncs fetch activities
+func (w *Worker) SetLookbackDays(days int) {
+	if days > 0 {
+		w.lookbackDays = days
+	}
+}
+
+// tokenCacheSlotsPerJob is the room each concurrent job gets in the decrypted token cache: the
+// user's Strava and Google tokens plus the Google tokens of up to 14 team sheets
+const tokenCacheSlotsPerJob = 32
+
+// SetWorkerCount sizes the decrypted token cache for count concurrent jobs and the reconciler,
+// so the cache does not evict and zero the tokens of a job still in progress. The cache never
+// shrinks below secure.DefaultTokenCacheSize.
+func (w *Worker) SetWorkerCount(count int) {
+	size := (count + 1) * tokenCacheSlotsPerJob
+	if size < secure.DefaultTokenCacheSize {
+		size = secure.DefaultTokenCacheSize
+	}
+	w.tokens = secure.NewTokenCache(size)
+}
+
+// SetWriteVerification reads back the rows written to each user's sheet and rewrites those that
+// differ from the intended values once; the outcome is reported in ProcessingResult.WriteVerification
+func (w *Worker) SetWriteVerification(enabled bool) {
+	w.verifyWrites = enabled
+}
+
+// SetStravaClientSecret replaces the Strava client secret after a rotation; jobs already
+// running keep the secret they started with
+func (w *Worker) SetStravaClientSecret(secret string) {
+	w.credentialsMu.Lock()
+	defer w.credentialsMu.Unlock()
+	
+	w.stravaClientSecret = secret
+}
+
+// SetGoogleClientSecret replaces the Google client secret after a rotation
+func (w *Worker) SetGoogleClientSecret(secret string) {
+	w.credentialsMu.Lock()
+	defer w.credentialsMu.Unlock()
+	
+	w.googleClientSecret = secret
+}
+
+// clientSecrets returns the current Strava and Google client secrets
+func (w *Worker) clientSecrets() (stravaSecret, googleSecret string) {
+	w.credentialsMu.RLock()
+	defer w.credentialsMu.RUnlock()
+	
+	return w.stravaClientSecret, w.googleClientSecret
+}
+
+// SetProviderEndpoints points the Strava and Google clients at alternate base URLs,
+// such as mock servers when staging runs against controlled backends
+func (w *Worker) SetProviderEndpoints(stravaEndpoints strava.Endpoints, googleEndpoints google.Endpoints) {
+	w.stravaEndpoints = stravaEndpoints
+	w.googleEndpoints = googleEndpoints
+}
+
+// SetTokenRefresher shares OAuth token refreshes between concurrent jobs for the same user, so a
+// manual and a scheduled sync do not invalidate each other's rotated refresh tokens
+func (w *Worker) SetTokenRefresher(refresher strava.TokenRefresher) {
+	w.tokenRefresher = refresher
+}
+
+// SetResponseCache serves the Strava athlete profile and spreadsheet metadata from cache across
+// jobs instead of fetching them on every sync; nil fetches them every time
+func (w *Worker) SetResponseCache(cache *respcache.Cache) {
+	w.responseCache = cache
+}
+
 // ProcessingResult represents the outcome of processing a user's automation job
 type ProcessingResult struct {
 	UserID           int           `json:"user_id"`
@@ -60,7 +224,57 @@ type ProcessingResult struct {
 	ProcessingTime   time.Duration `json:"processing_time"`
 	Error            string        `json:"error,omitempty"`
 	ErrorType        string        `json:"error_type,omitempty"`
+	
+	// TimedOutStep names the step that was running when the step or the job ran out of time
+	TimedOutStep     string        `json:"timed_out_step,omitempty"`
 	RequiresReauth   bool          `json:"requires_reauth"`
+	Warnings         []string      `json:"warnings,omitempty"`
+	
+	// DualWriteReport is set when the user is in a destination migration validation window
+	DualWriteReport  *destination.ValidationReport `json:"dual_write_report,omitempty"`
+	
+	// WriteVerification is set when written rows were read back (see SetWriteVerification)
+	WriteVerification *google.WriteVerification `json:"write_verification,omitempty"`
+	
+	// SheetWrites records the rows written to the user's spreadsheet so the run can be undone
+	SheetWrites      *google.SheetWrites `json:"-"`
+	
+	// Deferred jobs found the user's daily processing budget used up or were dequeued during a
+	// blackout window; their remaining work runs again at DeferredUntil (see SetDailyBudget)
+	Deferred         bool          `json:"deferred,omitempty"`
+	DeferredUntil    *time.Time    `json:"deferred_until,omitempty"`
+	
+	// TraceID links the result to the queued job that produced it
+	TraceID          string        `json:"trace_id,omitempty"`
+	
+	// DryRun results carry the would-be-written rows instead of writing them
+	DryRun           bool                 `json:"dry_run,omitempty"`
+	Preview          *destination.Preview `json:"preview,omitempty"`
+}
+
+// ProcessOptions controls how a single processing run behaves
+type ProcessOptions struct {
+	// TraceID of the job being processed, echoed in the result and logs
+	TraceID string
+	
+	// DryRun performs fetch, dedup and row conversion but skips every spreadsheet write
+	DryRun bool
+	
+	// From and To replace the default 7-day fetch window with a fixed range, used to correct rows
+	// written by a faulty run. Fixed ranges always refetch from Strava, skip weekly summaries and
+	// never flag deletions, since rows outside the range are not part of the fetch.
+	From time.Time
+	To   time.Time
+	
+	// Replay processes these activities, decoded from captured Strava responses, instead of
+	// fetching from Strava. Replays write only the user's sheet: they skip weekly summaries,
+	// webhooks and team sheets and never flag deletions, since the capture may be out of date.
+	Replay []strava.Activity
+}
+
+// hasFixedRange reports whether the run processes an explicit date range
+func (o ProcessOptions) hasFixedRange() bool {
+	return !o.From.IsZero() && !o.To.IsZero()
 }
 
 // ProcessUser processes automation for a single user
@@ -70,10 +284,20 @@ type ProcessingResult struct {
 // 3. Fetch activities and write to spreadsheet
 // 4. Handle errors gracefully with proper logging
 func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult {
+	return w.ProcessUserWithOptions(ctx, userID, ProcessOptions{})
+}
+
+// ProcessUserWithOptions processes automation for a single user with per-run options
+// In dry-run mode the spreadsheet is read to plan the sync but never written, and the planned
+// rows are returned in the result so users can preview a sync before trusting automation
+func (w *Worker) ProcessUserWithOptions(ctx context.Context, userID int, opts ProcessOptions) *ProcessingResult {
 	startTime := time.Now()
+	stravaClientSecret, googleClientSecret := w.clientSecrets()
 	
 	w.logger.Info("🚀 Starting automation processing for user",
 		"user_id", userID,
+		"trace_id", opts.TraceID,
+		"dry_run", opts.DryRun,
 		"context_deadline", func() string {
 			if deadline, ok := ctx.Deadline(); ok {
 				return deadline.Format(time.RFC3339)
@@ -82,23 +306,45 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		}(),
 		"worker_oauth_config", map[string]bool{
 			"has_strava_client_id":     w.stravaClientID != "",
-			"has_strava_client_secret": w.stravaClientSecret != "",
+			"has_strava_client_secret": stravaClientSecret != "",
 			"has_google_client_id":     w.googleClientID != "",
-			"has_google_client_secret": w.googleClientSecret != "",
+			"has_google_client_secret": googleClientSecret != "",
 		})
 	
 	result := &ProcessingResult{
 		UserID:     userID,
 		Success:    false,
 		ProcessingTime: 0,
+		TraceID:    opts.TraceID,
+		DryRun:     opts.DryRun,
+	}
+	
+	// Only one job at a time writes a user's sheet; dry runs never write
+	if !opts.DryRun {
+		lockedCtx, unlock, acquired := w.lockUser(ctx, userID)
+		if !acquired {
+			deferBusy(result)
+			result.ProcessingTime = time.Since(startTime)
+			w.logger.Info("🔒 Another job is processing the user, deferring job",
+				"user_id", userID,
+				"trace_id", opts.TraceID,
+				"retry_at", result.DeferredUntil.Format(time.RFC3339))
+			return result
+		}
+		defer unlock()
+		ctx = lockedCtx
 	}
+	// Runs before the lock is released, which cancels the locked context
+	defer ClassifyCancellation(ctx, result)
 	
 	// Step 1: Retrieve user configuration (US022)
 	w.logger.Debug("📋 Step 1/6: Retrieving user configuration for processing",
 		"user_id", userID,
 		"step", "config_retrieval")
 	
-	config, err := w.configService.GetProcessingConfigForUser(ctx, userID)
+	configCtx, cancelConfig := stepContext(ctx, w.stepTimeouts.ConfigFetch)
+	config, err := w.configService.GetProcessingConfigForUser(configCtx, userID)
+	cancelConfig()
 	if err != nil {
 		processingDuration := time.Since(startTime)
 		w.logger.Error("❌ FATAL: Failed to retrieve user configuration, skipping user processing",
@@ -115,8 +361,11 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		result.ProcessingTime = processingDuration
 		result.Error = fmt.Sprintf("Configuration retrieval failed: %v", err)
 		result.ErrorType = "CONFIG_ERROR"
+		markStepTimeout(ctx, configCtx, StepConfigFetch, w.stepTimeouts.ConfigFetch, result)
 		return result
 	}
+	// The decrypted tokens are zeroed when the job completes
+	defer w.tokens.Hold(config.Tokens()...)()
 	
 	// Validate that automation is enabled for this user
 	if !config.AutomationEnabled {
@@ -134,6 +383,80 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		return result
 	}
 	
+	// Jobs queued or deferred before an admin suspended the account must not run
+	if config.Suspended {
+		processingDuration := time.Since(startTime)
+		w.logger.Warn("⛔ Account suspended, skipping processing",
+			"user_id", userID,
+			"step", "suspension_check",
+			"processing_duration_ms", processingDuration.Milliseconds())
+		
+		result.ProcessingTime = processingDuration
+		result.Error = "The account is suspended"
+		result.ErrorType = "ACCOUNT_SUSPENDED"
+		return result
+	}
+	
+	// Fail fast when a recent job already found these credentials rejected, so a burst of queued
+	// jobs for the same user does not repeat the provider 401 and refresh attempt
+	if provider := w.markedReauthProvider(ctx, userID, config); provider != "" {
+		processingDuration := time.Since(startTime)
+		w.logger.Warn("🔐 Skipping provider calls - credentials were recently rejected",
+			"user_id", userID,
+			"step", "reauth_marker_check",
+			"provider", provider,
+			"processing_duration_ms", processingDuration.Milliseconds(),
+			"action_required", "User must re-authorize "+provider+" access")
+		
+		result.ProcessingTime = processingDuration
+		result.Error = fmt.Sprintf("%s access requires re-authorization (recently confirmed, provider not called)", provider)
+		result.ErrorType = reauthErrorTypes[provider]
+		result.RequiresReauth = true
+		return result
+	}
+	
+	// Fail fast while a provider is down; the circuit's synthetic probe, not this job, decides
+	// when to call it again
+	if provider, err := w.openCircuit(); err != nil {
+		processingDuration := time.Since(startTime)
+		w.logger.Warn("🚧 Skipping provider calls - circuit is open",
+			"user_id", userID,
+			"step", "circuit_check",
+			"provider", provider,
+			"error", err,
+			"processing_duration_ms", processingDuration.Milliseconds())
+		
+		result.ProcessingTime = processingDuration
+		result.Error = fmt.Sprintf("%s is unavailable, provider not called: %v", provider, err)
+		result.ErrorType = providerUnavailableErrorTypes[provider]
+		return result
+	}
+	
+	// Defer the job once the user's daily budget is used up; otherwise count its provider calls
+	budget := w.beginBudget(ctx, config)
+	if budget.exhausted() {
+		budget.deferResult(result)
+		result.ProcessingTime = time.Since(startTime)
+		w.logger.Warn("⏳ Daily processing budget used up, deferring job",
+			"user_id", userID,
+			"step", "budget_check",
+			"deferred_until", result.DeferredUntil.Format(time.RFC3339),
+			"error", result.Error)
+		return result
+	}
+	ctx = withJobBudget(ctx, budget)
+	defer w.recordBudget(ctx, budget)
+	
+	// Remember rejected credentials for the jobs queued behind this one
+	defer func() {
+		for provider, errorType := range reauthErrorTypes {
+			if result.RequiresReauth && result.ErrorType == errorType {
+				w.markReauthRequired(ctx, userID, config, provider)
+				w.publishEvent(ctx, events.ReauthRequired{UserID: userID, Provider: provider})
+			}
+		}
+	}()
+	
 	w.logger.Info("✅ Step 1/6: Successfully retrieved user configuration",
 		"user_id", userID,
 		"step", "config_retrieval",
@@ -149,25 +472,25 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 			"google_token_expiry":      config.GoogleTokenExpiry,
 			"strava_token_expiry":      config.StravaTokenExpiry,
 		})
+	w.checkpoint(ctx, opts.TraceID, userID, queue.StepConfigLoaded, nil)
 	
 	// Step 2: Create Strava API client with token management (US023)
 	w.logger.Debug("🏃 Step 2/6: Creating Strava API client with token management",
 		"user_id", userID,
 		"step", "strava_client_creation",
 		"strava_config", map[string]interface{}{
-			"has_refresh_token":    config.StravaRefreshToken != "",
-			"has_access_token":     config.StravaAccessToken != "",
+			"has_refresh_token":    !config.StravaRefreshToken.Empty(),
+			"has_access_token":     !config.StravaAccessToken.Empty(),
 			"token_valid":          config.HasValidStravaToken(),
 			"athlete_id":           config.StravaAthleteID,
-			"client_credentials":   w.stravaClientID != "" && w.stravaClientSecret != "",
+			"client_credentials":   w.stravaClientID != "" && stravaClientSecret != "",
 		})
 	
-	stravaClient := strava.NewClient(userID, config.StravaRefreshToken, w.logger)
-	stravaClient.SetOAuthCredentials(w.stravaClientID, w.stravaClientSecret)
+	// Raw activity responses are captured for replay when SetPayloadCapture is configured
+	recorder, captureOpts := w.captureOptions(opts)
+	stravaClient := w.newStravaClient(config, captureOpts...)
 	
-	// Set initial tokens if available
 	if config.HasValidStravaToken() {
-		stravaClient.SetInitialTokens(config.StravaAccessToken, *config.StravaTokenExpiry)
 		w.logger.Debug("✅ Set initial Strava tokens for client",
 			"user_id", userID,
 			"step", "strava_token_init",
@@ -177,7 +500,7 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		w.logger.Debug("⚠️ No valid Strava access token, will use refresh token",
 			"user_id", userID,
 			"step", "strava_token_init",
-			"has_refresh_token", config.StravaRefreshToken != "",
+			"has_refresh_token", !config.StravaRefreshToken.Empty(),
 			"token_expired", config.StravaTokenExpiry != nil && time.Now().After(*config.StravaTokenExpiry))
 	}
 	
@@ -186,19 +509,16 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		"user_id", userID,
 		"step", "google_client_creation",
 		"google_config", map[string]interface{}{
-			"has_refresh_token":    config.GoogleRefreshToken != "",
-			"has_access_token":     config.GoogleAccessToken != "",
+			"has_refresh_token":    !config.GoogleRefreshToken.Empty(),
+			"has_access_token":     !config.GoogleAccessToken.Empty(),
 			"token_valid":          config.HasValidGoogleToken(),
 			"spreadsheet_id":       config.SpreadsheetID,
-			"client_credentials":   w.googleClientID != "" && w.googleClientSecret != "",
+			"client_credentials":   w.googleClientID != "" && googleClientSecret != "",
 		})
 	
-	sheetsClient := google.NewSheetsClient(userID, config.GoogleRefreshToken, w.logger)
-	sheetsClient.SetOAuthCredentials(w.googleClientID, w.googleClientSecret, w.googleRedirectURL)
+	sheetsClient := w.newSheetsClient(config)
 	
-	// Set initial tokens if available
 	if config.HasValidGoogleToken() {
-		sheetsClient.SetInitialTokens(config.GoogleAccessToken, *config.GoogleTokenExpiry)
 		w.logger.Debug("✅ Set initial Google tokens for client",
 			"user_id", userID,
 			"step", "google_token_init",
@@ -208,18 +528,46 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		w.logger.Debug("⚠️ No valid Google access token, will use refresh token",
 			"user_id", userID,
 			"step", "google_token_init",
-			"has_refresh_token", config.GoogleRefreshToken != "",
+			"has_refresh_token", !config.GoogleRefreshToken.Empty(),
 			"token_expired", config.GoogleTokenExpiry != nil && time.Now().After(*config.GoogleTokenExpiry))
 	}
+	w.checkpoint(ctx, opts.TraceID, userID, queue.StepTokensReady, nil)
+	
+	// Activity fetch window for step 5 (also used for deletion detection in step 6)
+	// Get activities from the last lookbackDays days
+	since := time.Now().AddDate(0, 0, -w.lookbackDays)
+	
+	// Weekly summaries need complete weeks, so extend the window back to the start of the previous week
+	var summaryFrom time.Time
+	if opts.hasFixedRange() {
+		since = opts.From
+	} else if config.WeeklySummaryEnabled && opts.Replay == nil {
+		if loc, err := config.GetLocation(); err == nil {
+			summaryFrom = automation.StartOfWeek(time.Now().In(loc)).AddDate(0, 0, -7)
+			if summaryFrom.Before(since) {
+				since = summaryFrom
+			}
+		}
+	}
+	
+	// Build the output destination (wrapped for dual-write while a migration is being validated)
+	deletionWindowStart := since
+	if opts.hasFixedRange() || opts.Replay != nil {
+		deletionWindowStart = time.Time{}
+	}
+	dest := w.buildDestination(config, sheetsClient, deletionWindowStart)
 	
 	// Step 4: Validate spreadsheet access
 	w.logger.Debug("🔐 Step 4/6: Validating Google Sheets access",
 		"user_id", userID,
 		"step", "sheets_access_validation",
 		"spreadsheet_id", config.SpreadsheetID,
+		"destination", dest.Name(),
 		"validation_reason", "Ensuring user has read/write permissions before processing")
 	
-	if err := sheetsClient.ValidateAccess(ctx, config.SpreadsheetID); err != nil {
+	err = dest.ValidateAccess(ctx)
+	w.recordGoogleOutcome(err)
+	if err != nil {
 		processingDuration := time.Since(startTime)
 		
 		// Check if this requires re-authorization
@@ -263,25 +611,83 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		return result
 	}
 	
-	// Step 5: Fetch activities from Strava
-	// Get activities from the last 7 days (configurable in the future)
-	since := time.Now().AddDate(0, 0, -7)
+	// Prepare the destination layout (tab and header row) before writing; dry runs never modify it
+	if !opts.DryRun {
+		if err := dest.EnsureSchema(ctx); err != nil {
+			processingDuration := time.Since(startTime)
+			
+			w.logger.Error("❌ Failed to prepare destination schema",
+				"error", err,
+				"user_id", userID,
+				"step", "sheets_access_validation",
+				"destination", dest.Name(),
+				"requires_reauth", google.IsReauthRequired(err),
+				"processing_duration_ms", processingDuration.Milliseconds())
+			
+			result.ProcessingTime = processingDuration
+			if google.IsReauthRequired(err) {
+				result.Error = "Google Sheets access requires re-authorization"
+				result.ErrorType = "GOOGLE_REAUTH_REQUIRED"
+				result.RequiresReauth = true
+				return result
+			}
+			result.Error = fmt.Sprintf("Destination schema preparation failed: %v", err)
+			result.ErrorType = "SHEETS_SCHEMA_ERROR"
+			return result
+		}
+	}
+	w.checkpoint(ctx, opts.TraceID, userID, queue.StepDestinationValidated, nil)
 	
+	// Step 5: Fetch activities from Strava (window computed above)
 	w.logger.Debug("🏃 Step 5/6: Fetching activities from Strava",
 		"user_id", userID,
 		"step", "strava_activity_fetch",
 		"fetch_parameters", map[string]interface{}{
 			"since":            since.Format(time.RFC3339),
-			"days_back":        7,
+			"days_back":        w.lookbackDays,
 			"athlete_id":       config.StravaAthleteID,
 			"current_time":     time.Now().Format(time.RFC3339),
 			"timezone":         config.Timezone,
 		})
 	
-	activities, err := stravaClient.GetActivities(ctx, since)
+	var activities []strava.Activity
+	var fromCache bool
+	releaseFetch, err := w.enterPhase(ctx, w.stravaFetchSlots, userID, StepStravaFetch)
+	fetchCtx, cancelFetch := stepContext(ctx, w.stepTimeouts.StravaFetch)
+	switch {
+	case err != nil:
+		// The job ended while waiting for a Strava fetch slot
+	case opts.Replay != nil:
+		activities = opts.Replay
+	case opts.hasFixedRange():
+		activities, err = stravaClient.GetActivitiesInRange(fetchCtx, opts.From, opts.To)
+		if err == nil {
+			activities = w.withManualActivities(fetchCtx, userID, opts.From, opts.To, activities)
+		}
+	default:
+		activities, fromCache, err = w.loadActivities(fetchCtx, userID, since, stravaClient.GetActivities)
+	}
+	cancelFetch()
+	releaseFetch()
+	if !fromCache && opts.Replay == nil {
+		w.recordStravaOutcome(err)
+	}
+	// Captured even when the fetch failed, since a response that did not decode is worth replaying
+	w.storeCapture(ctx, recorder, userID, opts.TraceID, startTime)
 	if err != nil {
 		processingDuration := time.Since(startTime)
 		
+		if deferForStravaBudget(err, result) {
+			w.logger.Warn("⏳ Daily Strava API budget used up, deferring the sync",
+				"user_id", userID,
+				"step", "strava_activity_fetch",
+				"deferred_until", result.DeferredUntil.Format(time.RFC3339),
+				"processing_duration_ms", processingDuration.Milliseconds())
+			
+			result.ProcessingTime = processingDuration
+			return result
+		}
+		
 		// Check if this requires re-authorization
 		if strava.IsReauthRequired(err) {
 			w.logger.Warn("🔐 Strava access requires user re-authorization",
@@ -295,7 +701,7 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 					"strava_token_expired": !config.HasValidStravaToken(),
 					"fetch_parameters":    map[string]interface{}{
 						"since":     since.Format(time.RFC3339),
-						"days_back": 7,
+						"days_back": w.lookbackDays,
 					},
 				},
 				"processing_duration_ms", processingDuration.Milliseconds(),
@@ -320,7 +726,7 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 				"token_expiry":     config.StravaTokenExpiry,
 				"fetch_parameters": map[string]interface{}{
 					"since":     since.Format(time.RFC3339),
-					"days_back": 7,
+					"days_back": w.lookbackDays,
 				},
 			},
 			"processing_duration_ms", processingDuration.Milliseconds())
@@ -328,6 +734,7 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		result.ProcessingTime = processingDuration
 		result.Error = fmt.Sprintf("Strava activity fetch failed: %v", err)
 		result.ErrorType = "STRAVA_FETCH_ERROR"
+		markStepTimeout(ctx, fetchCtx, StepStravaFetch, w.stepTimeouts.StravaFetch, result)
 		return result
 	}
 	
@@ -336,6 +743,7 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 		"step", "strava_activity_fetch",
 		"fetch_results", map[string]interface{}{
 			"activity_count":   len(activities),
+			"from_cache":       fromCache,
 			"since":            since.Format(time.RFC3339),
 			"first_activity":   func() string {
 				if len(activities) > 0 {
@@ -350,9 +758,24 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 				return "none"
 			}(),
 		})
+	// Replays resolve gear from the cache only, so they never call Strava
+	if opts.Replay != nil {
+		w.resolveGear(ctx, userID, activities, nil)
+	} else {
+		w.resolveGear(ctx, userID, activities, stravaClient.GetGear)
+	}
+	w.checkpoint(ctx, opts.TraceID, userID, queue.StepActivitiesFetched, map[string]int{
+		"activities": len(activities),
+	})
 	
-	// Step 6: Write activities to Google Sheets
-	if len(activities) > 0 {
+	// Step 6: Reconcile activities with Google Sheets (append new, update changed, flag deleted)
+	// An empty fetch never flags deletions, so a transient empty response cannot mark every row deleted
+	if opts.DryRun {
+		if !w.previewActivities(ctx, dest, activities, config, result) {
+			result.ProcessingTime = time.Since(startTime)
+			return result
+		}
+	} else if len(activities) > 0 {
 		w.logger.Debug("📝 Step 6/6: Writing activities to Google Sheets",
 			"user_id", userID,
 			"step", "sheets_activity_write",
@@ -360,10 +783,19 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 				"activity_count":   len(activities),
 				"spreadsheet_id":   config.SpreadsheetID,
 				"target_sheet":     "Sheet1",
-				"write_range":      fmt.Sprintf("A2:I%d", len(activities)+1),
+				"sheet_template":   templates.GetOrDefault(config.SheetTemplate).ID,
 			})
 		
-		if err := sheetsClient.WriteActivities(ctx, config.SpreadsheetID, activities); err != nil {
+		var writeResult *destination.WriteResult
+		releaseWrite, err := w.enterPhase(ctx, w.sheetsWriteSlots, userID, StepSheetsWrite)
+		writeCtx, cancelWrite := stepContext(ctx, w.stepTimeouts.SheetsWrite)
+		if err == nil {
+			writeResult, err = dest.WriteActivities(writeCtx, activities)
+		}
+		cancelWrite()
+		releaseWrite()
+		w.recordGoogleOutcome(err)
+		if err != nil {
 			processingDuration := time.Since(startTime)
 			
 			// Check if this requires re-authorization
@@ -389,6 +821,13 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 				return result
 			}
 			
+			// Chunks written before the failure stay in the sheet; the next run resumes after them
+			var chunkErr *google.ChunkWriteError
+			chunksWritten, rowsWritten := 0, 0
+			if errors.As(err, &chunkErr) {
+				chunksWritten, rowsWritten = chunkErr.ChunksWritten, chunkErr.RowsWritten
+			}
+			
 			w.logger.Error("❌ Failed to write activities to Google Sheets",
 				"error", err,
 				"user_id", userID,
@@ -400,13 +839,16 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 					"spreadsheet_id":   config.SpreadsheetID,
 					"has_valid_token":  config.HasValidGoogleToken(),
 					"token_expiry":     config.GoogleTokenExpiry,
-					"write_range":      fmt.Sprintf("A2:I%d", len(activities)+1),
+					"sheet_template":   templates.GetOrDefault(config.SheetTemplate).ID,
+					"chunks_written":   chunksWritten,
+					"rows_written":     rowsWritten,
 				},
 				"processing_duration_ms", processingDuration.Milliseconds())
 			
 			result.ProcessingTime = processingDuration
 			result.Error = fmt.Sprintf("Sheets write failed: %v", err)
 			result.ErrorType = "SHEETS_WRITE_ERROR"
+			markStepTimeout(ctx, writeCtx, StepSheetsWrite, w.stepTimeouts.SheetsWrite, result)
 			return result
 		}
 		
@@ -416,8 +858,47 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 			"write_results", map[string]interface{}{
 				"activity_count":   len(activities),
 				"spreadsheet_id":   config.SpreadsheetID,
+				"rows_written":     writeResult.RowsWritten,
+				"rows_updated":     writeResult.RowsUpdated,
+				"rows_flagged_deleted": len(writeResult.DeletedActivityIDs),
 				"write_successful": true,
 			})
+		w.checkpoint(ctx, opts.TraceID, userID, queue.StepRowsWritten, map[string]int{
+			"written":         writeResult.RowsWritten,
+			"updated":         writeResult.RowsUpdated,
+			"flagged_deleted": len(writeResult.DeletedActivityIDs),
+		})
+		
+		if writeResult.Validation != nil {
+			result.DualWriteReport = writeResult.Validation
+		}
+		
+		if writes := writeResult.SheetWrites; writes != nil && len(writes.Rows) > 0 {
+			result.SheetWrites = writes
+		}
+		
+		if verification := writeResult.Verification; verification != nil {
+			result.WriteVerification = verification
+			switch verification.Status {
+			case google.VerificationMismatch:
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%d written rows still differ from the intended values after a retry: rows %v",
+					verification.RowsMismatched, verification.MismatchedRows))
+			case google.VerificationUnverified:
+				result.Warnings = append(result.Warnings, "Written rows could not be read back for verification")
+			}
+		}
+		
+		if config.WeeklySummaryEnabled && !summaryFrom.IsZero() {
+			w.writeWeeklySummaries(ctx, config, sheetsClient, activities, summaryFrom, result)
+		}
+		
+		if config.WebhookURL != "" && len(writeResult.NewActivityIDs) > 0 && opts.Replay == nil {
+			w.deliverWebhook(ctx, config, opts.TraceID, activities, writeResult.NewActivityIDs, result)
+		}
+		
+		if len(config.TeamSheets) > 0 && opts.Replay == nil {
+			w.writeTeamSheets(ctx, config, activities, deletionWindowStart, result)
+		}
 	} else {
 		w.logger.Info("ℹ️ Step 6/6: No new activities to write to Google Sheets",
 			"user_id", userID,
@@ -427,6 +908,11 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 				"since":            since.Format(time.RFC3339),
 				"skip_reason":      "No activities found in the specified time range",
 			})
+		w.checkpoint(ctx, opts.TraceID, userID, queue.StepRowsWritten, map[string]int{
+			"written":         0,
+			"updated":         0,
+			"flagged_deleted": 0,
+		})
 	}
 	
 	// Step 7: Complete processing successfully
@@ -451,6 +937,285 @@ func (w *Worker) ProcessUser(ctx context.Context, userID int) *ProcessingResult
 	return result
 }
 
+// previewActivities plans the step 6 writes without performing them and stores the preview in the result
+// It returns false when the preview could not be computed; the result then carries the error
+func (w *Worker) previewActivities(ctx context.Context, dest destination.Destination, activities []strava.Activity, config *automation.ProcessingConfig, result *ProcessingResult) bool {
+	w.logger.Debug("🔍 Step 6/6: Previewing Google Sheets writes (dry run)",
+		"user_id", result.UserID,
+		"trace_id", result.TraceID,
+		"step", "sheets_activity_preview",
+		"activity_count", len(activities),
+		"spreadsheet_id", config.SpreadsheetID)
+	
+	previewer, ok := dest.(destination.Previewer)
+	if !ok {
+		result.Error = fmt.Sprintf("Destination %s does not support dry runs", dest.Name())
+		result.ErrorType = "DRY_RUN_UNSUPPORTED"
+		return false
+	}
+	
+	if len(activities) == 0 {
+		result.Preview = &destination.Preview{Destination: dest.Name(), Rows: []destination.PreviewRow{}}
+		return true
+	}
+	
+	preview, err := previewer.PreviewActivities(ctx, activities)
+	if err != nil {
+		if google.IsReauthRequired(err) {
+			result.Error = "Google Sheets read requires re-authorization"
+			result.ErrorType = "GOOGLE_REAUTH_REQUIRED"
+			result.RequiresReauth = true
+			return false
+		}
+		
+		w.logger.Error("❌ Failed to preview Google Sheets writes",
+			"error", err,
+			"user_id", result.UserID,
+			"trace_id", result.TraceID,
+			"step", "sheets_activity_preview",
+			"spreadsheet_id", config.SpreadsheetID)
+		
+		result.Error = fmt.Sprintf("Sheets preview failed: %v", err)
+		result.ErrorType = "SHEETS_PREVIEW_ERROR"
+		return false
+	}
+	
+	w.logger.Info("✅ Step 6/6: Previewed Google Sheets writes without writing (dry run)",
+		"user_id", result.UserID,
+		"trace_id", result.TraceID,
+		"step", "sheets_activity_preview",
+		"preview_results", map[string]interface{}{
+			"activity_count":       len(activities),
+			"spreadsheet_id":       config.SpreadsheetID,
+			"rows_to_write":        preview.RowsToWrite,
+			"rows_to_update":       preview.RowsToUpdate,
+			"rows_to_flag_deleted": len(preview.DeletedActivityIDs),
+		})
+	w.checkpoint(ctx, result.TraceID, result.UserID, queue.StepRowsPreviewed, map[string]int{
+		"to_write":        preview.RowsToWrite,
+		"to_update":       preview.RowsToUpdate,
+		"to_flag_deleted": len(preview.DeletedActivityIDs),
+	})
+	
+	result.Preview = preview
+	return true
+}
+
+// newStravaClient creates a Strava client for the user, seeded with the stored access token while it is still valid
+func (w *Worker) newStravaClient(config *automation.ProcessingConfig, extra ...strava.Option) *strava.Client {
+	stravaClientSecret, _ := w.clientSecrets()
+	opts := []strava.Option{
+		strava.WithOAuthCredentials(w.stravaClientID, stravaClientSecret),
+		strava.WithEndpoints(w.stravaEndpoints),
+		strava.WithResponseCache(w.responseCache),
+	}
+	if w.tokenRefresher != nil {
+		opts = append(opts, strava.WithTokenRefresher(w.tokenRefresher))
+	}
+	if w.budgetStore != nil {
+		opts = append(opts, strava.WithHTTPClient(&http.Client{Timeout: strava.DefaultHTTPTimeout, Transport: budgetTransport{}}))
+	}
+	if w.stravaCallCounter != nil {
+		opts = append(opts, strava.WithCallBudget(w.stravaCallCounter, w.stravaCallLimit))
+	}
+	if config.HasValidStravaToken() {
+		opts = append(opts, strava.WithInitialToken(config.StravaAccessToken.Reveal(), *config.StravaTokenExpiry))
+	}
+	return strava.NewClient(config.UserID, config.StravaRefreshToken.Reveal(), w.logger, append(opts, extra...)...)
+}
+
+// newSheetsClient creates a Google Sheets client for the user, seeded with the stored access token while it is still valid
+func (w *Worker) newSheetsClient(config *automation.ProcessingConfig) *google.SheetsClient {
+	// Rows are written in the column layout of the template the user picked at onboarding
+	template := templates.GetOrDefault(config.SheetTemplate)
+	if config.GearColumn {
+		template = template.WithGear()
+	}
+	opts := append(w.sheetsClientOptions(),
+		google.WithTemplate(template),
+		google.WithChronologicalOrder(config.SortChronologically),
+	)
+	if config.HasValidGoogleToken() {
+		opts = append(opts, google.WithInitialToken(config.GoogleAccessToken.Reveal(), *config.GoogleTokenExpiry))
+	}
+	return google.NewSheetsClient(config.UserID, config.GoogleRefreshToken.Reveal(), w.logger, opts...)
+}
+
+// newTeamSheetsClient creates a Sheets client that writes the athlete's tab of a shared team
+// spreadsheet with the coach's Google tokens, in the default template
+func (w *Worker) newTeamSheetsClient(sheet automation.TeamSheet) *google.SheetsClient {
+	opts := append(w.sheetsClientOptions(), google.WithActivitySheet(sheet.Tab))
+	if sheet.HasValidGoogleToken() {
+		opts = append(opts, google.WithInitialToken(sheet.GoogleAccessToken.Reveal(), *sheet.GoogleTokenExpiry))
+	}
+	return google.NewSheetsClient(sheet.CoachUserID, sheet.GoogleRefreshToken.Reveal(), w.logger, opts...)
+}
+
+// sheetsClientOptions returns the Sheets client options shared by every spreadsheet the worker writes
+func (w *Worker) sheetsClientOptions() []google.Option {
+	_, googleClientSecret := w.clientSecrets()
+	opts := []google.Option{
+		google.WithOAuthCredentials(w.googleClientID, googleClientSecret, w.googleRedirectURL),
+		google.WithEndpoints(w.googleEndpoints),
+		google.WithReadbackVerification(w.verifyWrites),
+		google.WithResponseCache(w.responseCache),
+	}
+	if w.tokenRefresher != nil {
+		opts = append(opts, google.WithTokenRefresher(w.tokenRefresher))
+	}
+	if w.budgetStore != nil {
+		opts = append(opts, google.WithHTTPClient(&http.Client{Transport: budgetTransport{countWrites: true}}))
+	}
+	if w.sheetsWriteLimiter != nil {
+		opts = append(opts, google.WithWriteLimiter(w.sheetsWriteLimiter))
+	}
+	return opts
+}
+
+// writeWeeklySummaries updates the "Weekly" tab with totals for the complete weeks covered by this run
+// Failures are recorded as warnings because the daily activity rows have already been written
+func (w *Worker) writeWeeklySummaries(ctx context.Context, config *automation.ProcessingConfig, sheetsClient *google.SheetsClient, activities []strava.Activity, summaryFrom time.Time, result *ProcessingResult) {
+	loc, err := config.GetLocation()
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Weekly summary skipped: %v", err))
+		return
+	}
+	
+	summaries := automation.ComputeWeeklySummaries(activities, loc, summaryFrom)
+	rows := make([][]interface{}, len(summaries))
+	for i, summary := range summaries {
+		rows[i] = summary.ToRow()
+	}
+	
+	w.logger.Debug("📈 Writing weekly summary rows to Google Sheets",
+		"user_id", config.UserID,
+		"step", "weekly_summary_write",
+		"week_count", len(rows),
+		"summary_from", summaryFrom.Format("2006-01-02"),
+		"target_sheet", automation.WeeklySummarySheetTitle)
+	
+	if err := sheetsClient.UpsertRowsByKey(ctx, config.SpreadsheetID, automation.WeeklySummarySheetTitle, automation.WeeklySummaryHeader, rows); err != nil {
+		w.logger.Warn("⚠️ Failed to write weekly summary rows",
+			"user_id", config.UserID,
+			"step", "weekly_summary_write",
+			"error", err)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Weekly summary update failed: %v", err))
+		return
+	}
+	
+	w.logger.Info("✅ Updated weekly summary rows in Google Sheets",
+		"user_id", config.UserID,
+		"step", "weekly_summary_write",
+		"week_count", len(rows))
+}
+
+// deliverWebhook posts the newly synced activities to the user's webhook
+// Delivery failures never fail the run; they are reported as warnings
+func (w *Worker) deliverWebhook(ctx context.Context, config *automation.ProcessingConfig, traceID string, activities []strava.Activity, newIDs []int64, result *ProcessingResult) {
+	isNew := make(map[int64]bool, len(newIDs))
+	for _, id := range newIDs {
+		isNew[id] = true
+	}
+	newActivities := make([]strava.Activity, 0, len(newIDs))
+	for _, activity := range activities {
+		if isNew[activity.ID] {
+			newActivities = append(newActivities, activity)
+		}
+	}
+	
+	webhook := destination.NewWebhook(config.WebhookURL, config.WebhookSecret, config.UserID, traceID)
+	err := webhook.ValidateAccess(ctx)
+	if err == nil {
+		_, err = webhook.WriteActivities(ctx, newActivities)
+	}
+	if err != nil {
+		w.logger.Warn("⚠️ Webhook delivery failed",
+			"user_id", config.UserID,
+			"destination", webhook.Name(),
+			"activity_count", len(newActivities),
+			"error", err)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Webhook delivery failed: %v", err))
+		return
+	}
+	
+	w.logger.Info("📬 Delivered newly synced activities to webhook",
+		"user_id", config.UserID,
+		"destination", webhook.Name(),
+		"activity_count", len(newActivities))
+}
+
+// writeTeamSheets copies the activities to the athlete's tab of each shared team spreadsheet
+// Failures never fail the run; the athlete's own spreadsheet has already been written
+func (w *Worker) writeTeamSheets(ctx context.Context, config *automation.ProcessingConfig, activities []strava.Activity, windowStart time.Time, result *ProcessingResult) {
+	for _, sheet := range config.TeamSheets {
+		dest := destination.NewSheetsDestination(w.newTeamSheetsClient(sheet), sheet.SpreadsheetID, windowStart)
+		
+		err := dest.ValidateAccess(ctx)
+		if err == nil {
+			err = dest.EnsureSchema(ctx)
+		}
+		var writeResult *destination.WriteResult
+		if err == nil {
+			var release func()
+			release, err = w.enterPhase(ctx, w.sheetsWriteSlots, config.UserID, StepSheetsWrite)
+			if err == nil {
+				writeResult, err = dest.WriteActivities(ctx, activities)
+				release()
+			}
+		}
+		if err != nil {
+			w.logger.Warn("⚠️ Failed to write activities to team spreadsheet",
+				"user_id", config.UserID,
+				"team_id", sheet.TeamID,
+				"coach_user_id", sheet.CoachUserID,
+				"spreadsheet_id", sheet.SpreadsheetID,
+				"tab", sheet.Tab,
+				"requires_coach_reauth", google.IsReauthRequired(err),
+				"error", err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Team %d spreadsheet update failed: %v", sheet.TeamID, err))
+			continue
+		}
+		
+		w.logger.Info("👥 Wrote activities to team spreadsheet",
+			"user_id", config.UserID,
+			"team_id", sheet.TeamID,
+			"spreadsheet_id", sheet.SpreadsheetID,
+			"tab", sheet.Tab,
+			"rows_written", writeResult.RowsWritten,
+			"rows_updated", writeResult.RowsUpdated)
+	}
+}
+
+// buildDestination creates the user's primary destination and, while a destination migration
+// validation window is open, wraps it together with the pending destination for dual-write
+func (w *Worker) buildDestination(config *automation.ProcessingConfig, sheetsClient *google.SheetsClient, windowStart time.Time) destination.Destination {
+	primary := destination.NewSheetsDestination(sheetsClient, config.SpreadsheetID, windowStart)
+	
+	if !config.IsDualWriteActive(time.Now()) {
+		return primary
+	}
+	
+	var candidate destination.Destination
+	switch config.PendingDestinationType {
+	case destination.TypeGoogleSheets:
+		candidate = destination.NewSheetsDestination(sheetsClient, config.PendingDestinationID, windowStart)
+	default:
+		w.logger.Warn("⚠️ Unsupported pending destination type, skipping dual-write validation",
+			"user_id", config.UserID,
+			"pending_destination_type", config.PendingDestinationType,
+			"dual_write_until", config.DualWriteUntil)
+		return primary
+	}
+	
+	w.logger.Info("🔀 Destination migration in progress, enabling dual-write validation",
+		"user_id", config.UserID,
+		"primary", primary.Name(),
+		"candidate", candidate.Name(),
+		"dual_write_until", config.DualWriteUntil)
+	
+	return destination.NewDualWrite(primary, candidate, w.logger)
+}
+
 // ProcessUsers processes automation for multiple users
 // This method handles batch processing with individual error isolation
 func (w *Worker) ProcessUsers(ctx context.Context, userIDs []int) []*ProcessingResult {
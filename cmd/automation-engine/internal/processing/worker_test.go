@@ -0,0 +1,30 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
+)
+
+func TestWorker_SetWorkerCountKeepsTokensOfEveryJob(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetWorkerCount(100)
+
+	// Every job holds its tokens at once; none may be zeroed while the jobs run
+	var tokens []*secure.Token
+	for job := 0; job < 100; job++ {
+		held := []*secure.Token{
+			secure.NewTokenString("google-access"), secure.NewTokenString("google-refresh"),
+			secure.NewTokenString("strava-access"), secure.NewTokenString("strava-refresh"),
+		}
+		defer worker.tokens.Hold(held...)()
+		tokens = append(tokens, held...)
+	}
+
+	for i, token := range tokens {
+		if token.Zeroed() {
+			t.Fatalf("Expected the tokens of running jobs to be kept, token %d was zeroed", i)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package processing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// DefaultSheetsWriteBurst is how many Sheets writes may be made at once after the shared bucket
+// has been idle; it keeps a burst from one worker from using up the quota of the others
+const DefaultSheetsWriteBurst = 10
+
+// WriteTokenBucket is a token bucket shared by every engine instance, e.g. in Redis
+type WriteTokenBucket interface {
+	TakeSheetsWriteToken(ctx context.Context, perMinute, burst int) (time.Duration, error)
+}
+
+// SetSheetsWriteLimit makes every Sheets write call of the worker's jobs take a token from
+// bucket first, so concurrent jobs across engine instances stay within perMinute writes and
+// queue behind each other instead of failing with 429s. Zero disables the limit.
+func (w *Worker) SetSheetsWriteLimit(bucket WriteTokenBucket, perMinute int) {
+	if bucket == nil || perMinute <= 0 {
+		w.sheetsWriteLimiter = nil
+		return
+	}
+	w.sheetsWriteLimiter = &sheetsWriteLimiter{
+		bucket:    bucket,
+		perMinute: perMinute,
+		burst:     DefaultSheetsWriteBurst,
+		logger:    w.logger,
+	}
+}
+
+// sheetsWriteLimiter waits for a token of the shared bucket; it satisfies google.RateLimiter
+type sheetsWriteLimiter struct {
+	bucket    WriteTokenBucket
+	perMinute int
+	burst     int
+	logger    *logger.Logger
+}
+
+// Wait blocks until a write token is taken or ctx ends. A bucket that cannot be reached lets the
+// write through, like the other Redis coordination, rather than failing the job.
+func (l *sheetsWriteLimiter) Wait(ctx context.Context) error {
+	for {
+		wait, err := l.bucket.TakeSheetsWriteToken(ctx, l.perMinute, l.burst)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			l.logger.Warn("⚠️ Failed to take Sheets write token, writing without it",
+				"error", err)
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
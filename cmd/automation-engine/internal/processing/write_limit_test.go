@@ -0,0 +1,75 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// fakeWriteBucket answers token requests with the queued waits, then grants every request
+type fakeWriteBucket struct {
+	waits []time.Duration
+	err   error
+	takes int
+}
+
+func (f *fakeWriteBucket) TakeSheetsWriteToken(ctx context.Context, perMinute, burst int) (time.Duration, error) {
+	f.takes++
+	if f.err != nil {
+		return 0, f.err
+	}
+	if len(f.waits) == 0 {
+		return 0, nil
+	}
+	wait := f.waits[0]
+	f.waits = f.waits[1:]
+	return wait, nil
+}
+
+func TestSheetsWriteLimiter_WaitsForToken(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	bucket := &fakeWriteBucket{waits: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}}
+	worker.SetSheetsWriteLimit(bucket, 60)
+
+	start := time.Now()
+	if err := worker.sheetsWriteLimiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if bucket.takes != 3 {
+		t.Errorf("Expected the token to be taken on the third try, got %d tries", bucket.takes)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected to wait for the bucket to refill, waited %v", elapsed)
+	}
+}
+
+func TestSheetsWriteLimiter_StopsWhenCancelled(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetSheetsWriteLimit(&fakeWriteBucket{waits: []time.Duration{time.Hour}}, 60)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := worker.sheetsWriteLimiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestSheetsWriteLimiter_FailsOpen(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetSheetsWriteLimit(&fakeWriteBucket{err: errors.New("redis down")}, 60)
+
+	if err := worker.sheetsWriteLimiter.Wait(context.Background()); err != nil {
+		t.Errorf("Expected the write to go through when the bucket fails, got %v", err)
+	}
+}
+
+func TestSetSheetsWriteLimit_ZeroDisables(t *testing.T) {
+	worker := &Worker{logger: logger.New("test")}
+	worker.SetSheetsWriteLimit(&fakeWriteBucket{}, 0)
+	if worker.sheetsWriteLimiter != nil {
+		t.Error("Expected no limiter for a zero limit")
+	}
+}
@@ -2,23 +2,34 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"flag"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
-	_ "github.com/lib/pq"
-
 	"github.com/Perseverance/the-academy-sync-claude/cmd/automation-engine/internal/processing"
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/circuit"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/health"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/objectstore"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retention"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/tokenrefresh"
 )
 
+// reconciliationEveryCycles runs background reconciliation once an hour with the 60s test mode cycle
+const reconciliationEveryCycles = 60
+
+// jobResultRecordTimeout bounds recording a job's outcome after the job's own context has ended
+const jobResultRecordTimeout = 10 * time.Second
+
 // performStartupHealthChecks validates critical dependencies and fails fast if any are unavailable
 // This function implements the US046 fail-fast mechanism for automation engine dependencies
 func performStartupHealthChecks(cfg *config.Config, log *logger.Logger) error {
@@ -52,16 +63,27 @@ func performStartupHealthChecks(cfg *config.Config, log *logger.Logger) error {
 		return fmt.Errorf("database dependency check failed: %w", err)
 	}
 	
-	// TODO: Add Redis health check when Redis connectivity is implemented
-	// if cfg.RedisURL != "" {
-	//     // Redis health check logic here
-	// }
+	// Optional: verify the OAuth client credentials so an incorrectly rotated secret stops
+	// startup instead of failing every sign-in or token refresh
+	if cfg.Providers.VerifyCredentials {
+		if err := healthChecker.VerifyOAuthClients(ctx, app.OAuthClients(cfg)...); err != nil {
+			log.Critical("Critical dependency failed: OAuth client credentials rejected",
+				"error", err.Error())
+			return fmt.Errorf("OAuth credential check failed: %w", err)
+		}
+	}
+
+	// Redis is not critical: without it the engine falls back to test mode processing
 	
 	log.Info("All critical dependency health checks passed successfully")
 	return nil
 }
 
 func main() {
+	validateConfig := flag.Bool("validate-config", false, "Validate the configuration and exit (for CI smoke tests)")
+	replayFlags := registerReplayFlags()
+	flag.Parse()
+
 	// Load configuration using hybrid loading strategy
 	cfg, err := config.Load()
 	if err != nil {
@@ -70,8 +92,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Check the settings this service requires, reporting every missing one at once
+	if code, stop := app.CheckServiceConfig(cfg, config.ServiceAutomationEngine, *validateConfig); stop {
+		os.Exit(code)
+	}
+
 	// Initialize structured logger
-	log := logger.New("automation-engine")
+	log := app.NewLogger(cfg, "automation-engine")
 
 	log.Info("Automation Engine starting", 
 		"environment", cfg.Environment,
@@ -89,32 +116,96 @@ func main() {
 		os.Exit(2) // Exit code 2 indicates dependency failure
 	}
 
-	// Initialize database connection
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	// Construct repositories and services for job processing
+	container, err := app.Open(cfg, log, app.ProfileAutomationEngine)
 	if err != nil {
-		log.Critical("Failed to open database connection", "error", err.Error())
+		log.Critical("Failed to initialize automation engine dependencies", "error", err.Error())
 		os.Exit(3)
 	}
-	defer db.Close()
+	defer container.Close()
 
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Critical("Failed to ping database", "error", err.Error())
-		os.Exit(3)
+	// Initialize processing worker; circuit probes run until the process exits
+	worker, responseCache := newWorker(context.Background(), cfg, container, log)
+
+	// -replay reprocesses captured Strava responses through row conversion and sheet writing
+	if replayFlags.source != "" {
+		os.Exit(runReplay(context.Background(), worker, replayFlags, log))
 	}
 
-	log.Info("Database connection established successfully")
+	// Raw Strava activity responses are stored per run in ENGINE_STRAVA_CAPTURE_BUCKET for replay
+	if cfg.Engine.StravaCaptureBucket != "" {
+		if bucket, err := objectstore.NewGCSBucket(context.Background(), cfg.Engine.StravaCaptureBucket); err != nil {
+			log.Error("Strava response capture disabled - capture bucket unavailable",
+				"error", err.Error(),
+				"bucket", cfg.Engine.StravaCaptureBucket)
+		} else {
+			worker.SetPayloadCapture(bucket)
+		}
+	}
+
+	// Rotated OAuth client secrets are applied every SECRET_RELOAD_INTERVAL; running jobs keep
+	// the secret they started with
+	if secretWatcher, err := container.WatchSecrets(context.Background()); err != nil {
+		log.Warn("Secret reloading disabled", "error", err)
+	} else if secretWatcher != nil {
+		secretWatcher.OnChange(config.SecretStravaClientSecret, worker.SetStravaClientSecret)
+		secretWatcher.OnChange(config.SecretGoogleClientSecret, worker.SetGoogleClientSecret)
+		go container.RunSecretReloads(context.Background(), secretWatcher)
+	}
+
+	// Database pool statistics are logged every DB_STATS_INTERVAL
+	go container.RunPoolStats(context.Background())
+
+	// pprof profiles, goroutine and heap dumps on the internal DIAGNOSTICS_ADDR when enabled
+	go container.RunDiagnostics(context.Background())
 
-	// Initialize encryption service for token handling
-	encryptionService := auth.NewEncryptionService(cfg.EncryptionSecret)
+	// Background reconciliation of stored connection data vs provider reality (low priority)
+	reconciler := processing.NewReconciler(worker, container.UserRepository, log)
 
-	// Initialize repositories and services
-	userRepository := database.NewUserRepository(db, encryptionService)
-	configService := automation.NewConfigService(userRepository, log)
+	// Every run is recorded so test-mode and dry-run runs are distinguishable from real ones
+	runRepository := container.RunRepository
 
-	// Initialize processing worker
+	// Jobs are consumed from the Redis queue when it is reachable; otherwise fall back to the
+	// development test loop so the engine can still be exercised locally
+	jobQueue, err := container.ConnectJobQueue()
+	if err != nil {
+		log.Warn("Job queue unavailable - falling back to test mode processing",
+			"error", err.Error())
+		
+		// Test mode bypasses the queue and processes a fixed user, so it must never
+		// silently run against production data because Redis is misconfigured
+		if err := cfg.ValidateTestMode(); err != nil {
+			log.Critical("Refusing to start test mode processing - automation engine cannot continue",
+				"error", err.Error(),
+				"environment", cfg.Environment)
+			os.Exit(4) // Exit code 4 indicates test mode was refused
+		}
+		
+		startTestModeProcessing(cfg, worker, reconciler, runRepository, log)
+		return
+	}
+
+	// Redis-backed coordination between the engine's consumers and instances
+	useJobQueue(worker, jobQueue, responseCache, cfg, container, log)
+
+	// Automation runs and audit events past RETENTION_PERIOD are pruned by one instance every
+	// RETENTION_PRUNE_INTERVAL, exported to RETENTION_ARCHIVE_BUCKET first when it is set
+	startRetentionPruning(context.Background(), cfg, container, jobQueue, log)
+
+	log.Info("Automation engine initialized successfully, starting job queue processing",
+		"oauth_configured", cfg.StravaClientID != "" && cfg.GoogleClientID != "",
+		"worker_count", cfg.Engine.WorkerCount,
+		"lookback_days", cfg.Engine.LookbackDays)
+
+	startQueueProcessing(jobQueue, worker, reconciler, runRepository, container.BlackoutRepository, cfg.Engine, log)
+}
+
+// newWorker builds the processing worker with the provider endpoints, caches and circuit breakers
+// selected by cfg. The circuit breakers' probes run until ctx is cancelled. The response cache is
+// nil when disabled.
+func newWorker(ctx context.Context, cfg *config.Config, container *app.Container, log *logger.Logger) (*processing.Worker, *respcache.Cache) {
 	worker := processing.NewWorker(
-		configService,
+		container.AutomationConfig,
 		cfg.StravaClientID,
 		cfg.StravaClientSecret,
 		cfg.GoogleClientID,
@@ -123,7 +214,502 @@ func main() {
 		log,
 	)
 
-	log.Info("Automation engine initialized successfully, starting processing loop",
+	// Regular syncs fetch the last ENGINE_LOOKBACK_DAYS days of activities
+	worker.SetLookbackDays(cfg.Engine.LookbackDays)
+	worker.SetStepTimeouts(processing.StepTimeouts{
+		ConfigFetch: cfg.Engine.ConfigFetchTimeout,
+		StravaFetch: cfg.Engine.StravaFetchTimeout,
+		SheetsWrite: cfg.Engine.SheetsWriteTimeout,
+	})
+
+	// Every consumer holds its job's decrypted tokens in the shared cache until the job ends
+	worker.SetWorkerCount(cfg.Engine.WorkerCount)
+
+	// Fewer jobs than ENGINE_WORKER_COUNT may fetch from Strava or write to Sheets at once
+	worker.SetProviderConcurrency(processing.ProviderConcurrency{
+		StravaFetch: cfg.Engine.StravaFetchConcurrency,
+		SheetsWrite: cfg.Engine.SheetsWriteConcurrency,
+	})
+
+	// Provider base URLs default to production; staging may point them at mock servers
+	stravaEndpoints, googleEndpoints := app.StravaEndpoints(cfg), app.GoogleEndpoints(cfg)
+	worker.SetProviderEndpoints(stravaEndpoints, googleEndpoints)
+
+	// ENGINE_VERIFY_WRITES reads back written rows to catch truncation and locale coercion
+	worker.SetWriteVerification(cfg.Engine.VerifyWrites)
+
+	// Athlete profiles and spreadsheet metadata rarely change, so jobs reuse them for
+	// ENGINE_RESPONSE_CACHE_TTL; the cache is shared through Redis once the queue connects
+	var responseCache *respcache.Cache
+	if cfg.Engine.ResponseCacheTTL > 0 {
+		responseCache = respcache.New(cfg.Engine.ResponseCacheTTL, respcache.DefaultMaxEntries, log)
+		worker.SetResponseCache(responseCache)
+	}
+
+	// Fetched activities are cached locally so re-syncs and exports can skip Strava while fresh
+	worker.SetActivityCache(container.ActivityRepository, database.DefaultActivityCacheMaxAge)
+	worker.SetGearCache(container.ActivityRepository, database.DefaultGearCacheMaxAge)
+
+	// Each finished job moves the user's next_run_at to their next daily run
+	worker.SetRunSchedule(container.UserRepository)
+
+	// Backfill jobs checkpoint completed monthly windows so an interrupted import resumes, and hand
+	// the rest of a long import to a new job once their time slice is used up
+	worker.SetBackfillCheckpoints(container.BackfillRepository)
+	worker.SetBackfillSlice(cfg.Engine.BackfillSlice)
+
+	// Provider outages open a circuit so jobs fail fast; unauthenticated probes decide when it closes
+	probeClient := &http.Client{Timeout: circuit.DefaultProbeTimeout}
+	stravaProbe := circuit.HTTPProbe(probeClient, circuit.StravaProbeURL(stravaEndpoints.APIURL("")))
+	googleProbe := circuit.HTTPProbe(probeClient, circuit.GoogleProbeURL(googleEndpoints.SheetsEndpoint()))
+	stravaBreaker := circuit.NewBreaker("strava", cfg.Engine.CircuitFailureThreshold, stravaProbe, log)
+	googleBreaker := circuit.NewBreaker("google", cfg.Engine.CircuitFailureThreshold, googleProbe, log)
+	worker.SetCircuitBreakers(stravaBreaker, googleBreaker)
+	go circuit.RunProbes(ctx, cfg.Engine.CircuitProbeInterval, stravaBreaker, googleBreaker)
+
+	return worker, responseCache
+}
+
+// useJobQueue gives the worker the state it shares with other jobs through Redis: re-authorization
+// markers, user locks, job checkpoints, the response cache, token refreshes, daily budgets and
+// the Sheets write limit
+func useJobQueue(worker *processing.Worker, jobQueue *queue.Client, responseCache *respcache.Cache, cfg *config.Config, container *app.Container, log *logger.Logger) {
+	// Rejected credentials are remembered briefly so queued jobs for the same user fail fast
+	worker.SetReauthMarkers(jobQueue, queue.DefaultReauthMarkerTTL)
+
+	// A user is processed by one job at a time, so overlapping syncs cannot write rows twice
+	worker.SetUserLocks(jobQueue, queue.DefaultUserLockTTL)
+
+	// Completed processing steps are recorded per job and streamed to the user's browser
+	worker.SetJobCheckpoints(jobQueue)
+
+	if responseCache != nil {
+		responseCache.SetStore(jobQueue)
+	}
+
+	// Concurrent jobs for the same user share one OAuth token refresh instead of racing
+	worker.SetTokenRefresher(tokenrefresh.NewManager(jobQueue, container.Encryption, log))
+
+	// Each user's provider calls and Sheets writes are capped per day; jobs beyond the budget are
+	// deferred to the user's next day
+	worker.SetDailyBudget(jobQueue, processing.BudgetLimits{
+		ProviderCalls: cfg.Engine.DailyProviderCallBudget,
+		SheetsWrites:  cfg.Engine.DailySheetsWriteBudget,
+	})
+
+	// Each user's Strava calls are capped per UTC day so one user's import cannot use up the
+	// application-wide Strava rate limit
+	worker.SetStravaCallBudget(jobQueue, cfg.Engine.DailyStravaCallBudget)
+
+	// Sheets writes of all engine instances share one per-minute limit, so concurrent jobs queue
+	// their writes instead of exceeding the project's write quota
+	worker.SetSheetsWriteLimit(jobQueue, cfg.Engine.SheetsWritesPerMinute)
+
+	// Job outcomes and rejected credentials are announced to the notification service
+	if eventBus, err := container.ConnectEventBus(); err != nil {
+		log.Warn("Event bus unavailable - job outcome events disabled", "error", err.Error())
+	} else {
+		worker.SetEventPublisher(eventBus)
+	}
+}
+
+// startRetentionPruning prunes expired rows in the background. When the archive bucket cannot be
+// used nothing is pruned, so rows are never deleted without the export operators asked for.
+func startRetentionPruning(ctx context.Context, cfg *config.Config, container *app.Container, jobQueue *queue.Client, log *logger.Logger) {
+	var archiver retention.Archiver
+	if cfg.Retention.ArchiveBucket != "" {
+		bucket, err := objectstore.NewGCSBucket(ctx, cfg.Retention.ArchiveBucket)
+		if err != nil {
+			log.Error("Retention pruning disabled - archive bucket unavailable",
+				"error", err.Error(),
+				"bucket", cfg.Retention.ArchiveBucket)
+			return
+		}
+		archiver = bucket
+	}
+
+	pruner := retention.NewPruner(container.RetentionRepository, archiver, cfg.Retention.Period, cfg.Retention.BatchSize, log)
+	go pruner.Run(ctx, cfg.Retention.PruneInterval, jobQueue)
+}
+
+// startQueueProcessing consumes automation jobs from the queue with engine.WorkerCount concurrent
+// consumers and stores each job's result for polling. Reconciliation runs on the first consumer.
+func startQueueProcessing(jobQueue *queue.Client, worker *processing.Worker, reconciler *processing.Reconciler, runs *database.RunRepository, blackouts *database.BlackoutRepository, engine config.EngineConfig, log *logger.Logger) {
+	for i := 1; i < engine.WorkerCount; i++ {
+		go consumeJobs(jobQueue, worker, nil, runs, blackouts, engine, log)
+	}
+	consumeJobs(jobQueue, worker, reconciler, runs, blackouts, engine, log)
+}
+
+// consumeJobs processes queued jobs one at a time; reconciler may be nil
+func consumeJobs(jobQueue *queue.Client, worker *processing.Worker, reconciler *processing.Reconciler, runs *database.RunRepository, blackouts *database.BlackoutRepository, engine config.EngineConfig, log *logger.Logger) {
+	lastReconciliation := time.Now()
+	paused := false
+
+	for {
+		// Nothing is dequeued while operators have the service in maintenance mode
+		if paused = pauseForMaintenance(jobQueue, paused, log); paused {
+			time.Sleep(engine.QueuePollTimeout)
+			continue
+		}
+
+		// Low-priority reconciliation runs once an hour on a small batch of due users
+		if reconciler != nil && time.Since(lastReconciliation) >= engine.ReconciliationInterval {
+			runReconciliation(reconciler, engine.ReconciliationBatchSize, log)
+			lastReconciliation = time.Now()
+		}
+
+		// Jobs deferred by an exhausted daily budget or a blackout window return to the queue once they are due
+		if enqueued, err := jobQueue.EnqueueDueJobs(context.Background(), time.Now()); err != nil {
+			log.Warn("⚠️ Failed to enqueue deferred jobs",
+				"error", err.Error())
+		} else if enqueued > 0 {
+			log.Info("⏰ Deferred jobs returned to the queue",
+				"count", enqueued)
+		}
+
+		job, err := jobQueue.Dequeue(context.Background(), engine.QueuePollTimeout)
+		if err != nil {
+			log.Error("❌ Failed to dequeue automation job",
+				"error", err.Error())
+			time.Sleep(engine.QueuePollTimeout)
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		// Jobs dequeued during an operator-declared blackout wait for its end
+		if deferDuringBlackout(jobQueue, blackouts, job, log) {
+			continue
+		}
+
+		processJob(jobQueue, worker, runs, job, engine, log)
+	}
+}
+
+// deadLetterErrorTypes are transient failures whose jobs are set aside for operators to requeue
+// with `academyctl queue requeue-dlq` once the outage is over
+var deadLetterErrorTypes = map[string]bool{
+	"STRAVA_UNAVAILABLE":            true,
+	"GOOGLE_UNAVAILABLE":            true,
+	processing.ErrorTypeJobTimeout:  true,
+	processing.ErrorTypeStepTimeout: true,
+}
+
+// processJob runs a single queued job and records its outcome
+func processJob(jobQueue *queue.Client, worker *processing.Worker, runs *database.RunRepository, job *queue.Job, engine config.EngineConfig, log *logger.Logger) {
+	// Backfills page through years of history and get a longer timeout
+	timeout := engine.JobTimeout
+	if job.TriggerType == queue.TriggerBackfill {
+		timeout = engine.BackfillJobTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Info("📥 Processing automation job from queue",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"trigger_type", job.TriggerType,
+		"dry_run", job.DryRun,
+		"queue_wait_ms", time.Since(job.EnqueuedAt).Milliseconds())
+
+	jobResult := &queue.JobResult{
+		TraceID: job.TraceID,
+		UserID:  job.UserID,
+		Status:  queue.JobStatusRunning,
+		DryRun:  job.DryRun,
+	}
+	if err := jobQueue.SetResult(ctx, jobResult); err != nil {
+		log.Warn("⚠️ Failed to mark job as running",
+			"trace_id", job.TraceID,
+			"error", err.Error())
+	}
+
+	runID := recordRunStart(ctx, runs, &database.CreateRunRequest{
+		UserID:      job.UserID,
+		TraceID:     job.TraceID,
+		TriggerType: job.TriggerType,
+		DryRun:      job.DryRun,
+	}, log)
+
+	// Strava and Google client log lines carry the job's trace ID and run record ID (job_id)
+	ctx = logger.ContextWithAttrs(ctx, "trace_id", job.TraceID)
+	if runID != 0 {
+		ctx = logger.ContextWithAttrs(ctx, "job_id", runID)
+	}
+
+	var result *processing.ProcessingResult
+	var output interface{}
+	if job.TriggerType == queue.TriggerBackfill {
+		result, output = runBackfillJob(ctx, worker, job)
+		processing.ClassifyCancellation(ctx, result)
+	} else {
+		opts := processing.ProcessOptions{
+			TraceID: job.TraceID,
+			DryRun:  job.DryRun,
+		}
+		if job.SyncFrom != nil && job.SyncTo != nil {
+			opts.From, opts.To = *job.SyncFrom, *job.SyncTo
+		}
+		result = worker.ProcessUserWithOptions(ctx, job.UserID, opts)
+		output = result
+	}
+
+	// A job that ran past its deadline still records its outcome
+	ctx, cancelRecord := context.WithTimeout(context.WithoutCancel(ctx), jobResultRecordTimeout)
+	defer cancelRecord()
+
+	recordRunResult(ctx, runs, runID, result, log)
+	worker.PublishOutcome(ctx, result)
+	worker.ScheduleNextRun(ctx, result)
+
+	jobResult.Status = queue.JobStatusCompleted
+	if result.Deferred {
+		jobResult.Status = queue.JobStatusDeferred
+		if !job.DryRun && result.DeferredUntil != nil {
+			if err := jobQueue.Defer(ctx, job, *result.DeferredUntil); err != nil {
+				log.Error("❌ Failed to defer automation job",
+					"trace_id", job.TraceID,
+					"user_id", job.UserID,
+					"error", err.Error())
+			}
+		}
+	} else if !result.Success {
+		jobResult.Status = queue.JobStatusFailed
+		if !job.DryRun && deadLetterErrorTypes[result.ErrorType] {
+			if err := jobQueue.DeadLetterJob(ctx, job, result.ErrorType); err != nil {
+				log.Error("❌ Failed to dead-letter automation job",
+					"trace_id", job.TraceID,
+					"user_id", job.UserID,
+					"error", err.Error())
+			}
+		}
+	}
+
+	payload, err := json.Marshal(output)
+	if err != nil {
+		log.Error("❌ Failed to encode processing result",
+			"trace_id", job.TraceID,
+			"error", err.Error())
+	} else {
+		jobResult.Result = payload
+	}
+
+	if err := jobQueue.SetResult(ctx, jobResult); err != nil {
+		log.Error("❌ Failed to store job result",
+			"trace_id", job.TraceID,
+			"user_id", job.UserID,
+			"error", err.Error())
+	}
+
+	log.Info("📤 Automation job finished",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"status", jobResult.Status,
+		"dry_run", job.DryRun,
+		"activities_count", result.ActivitiesCount,
+		"error_type", result.ErrorType)
+}
+
+// pauseForMaintenance reports whether maintenance mode is on, logging when the engine pauses and
+// resumes. Failing to read the switch keeps the engine running.
+func pauseForMaintenance(jobQueue *queue.Client, paused bool, log *logger.Logger) bool {
+	maintenance, err := jobQueue.GetMaintenance(context.Background())
+	if err != nil {
+		log.Warn("⚠️ Failed to read maintenance state, processing jobs",
+			"error", err.Error())
+		return false
+	}
+
+	switch {
+	case maintenance != nil && !paused:
+		log.Warn("🚧 Maintenance mode on, job processing paused",
+			"message", maintenance.Message,
+			"ends_at", maintenance.EndsAt)
+	case maintenance == nil && paused:
+		log.Info("✅ Maintenance mode off, job processing resumed")
+	}
+	return maintenance != nil
+}
+
+// deferDuringBlackout holds a job dequeued during a blackout window until the window ends and
+// reports whether it did. No run is recorded, so users are not notified about work that never
+// started. Dry runs are only previews and are not queued again.
+func deferDuringBlackout(jobQueue *queue.Client, blackouts *database.BlackoutRepository, job *queue.Job, log *logger.Logger) bool {
+	if blackouts == nil {
+		return false
+	}
+	ctx := context.Background()
+
+	blackout, err := blackouts.GetActiveBlackout(ctx, time.Now())
+	if err != nil {
+		log.Warn("⚠️ Failed to read blackout windows, processing the job",
+			"trace_id", job.TraceID,
+			"error", err.Error())
+		return false
+	}
+	if blackout == nil {
+		return false
+	}
+
+	if !job.DryRun {
+		if err := jobQueue.Defer(ctx, job, blackout.EndsAt); err != nil {
+			log.Error("❌ Failed to defer automation job during blackout window",
+				"trace_id", job.TraceID,
+				"user_id", job.UserID,
+				"error", err.Error())
+			return false
+		}
+	}
+
+	resumeAt := blackout.EndsAt
+	result := &processing.ProcessingResult{
+		UserID:        job.UserID,
+		TraceID:       job.TraceID,
+		DryRun:        job.DryRun,
+		Deferred:      !job.DryRun,
+		DeferredUntil: &resumeAt,
+		ErrorType:     "BLACKOUT_WINDOW",
+		Error:         fmt.Sprintf("Syncing is paused until %s: %s", resumeAt.UTC().Format(time.RFC3339), blackout.Reason),
+	}
+	jobResult := &queue.JobResult{
+		TraceID: job.TraceID,
+		UserID:  job.UserID,
+		Status:  queue.JobStatusDeferred,
+		DryRun:  job.DryRun,
+	}
+	if payload, err := json.Marshal(result); err == nil {
+		jobResult.Result = payload
+	}
+	if err := jobQueue.SetResult(ctx, jobResult); err != nil {
+		log.Warn("⚠️ Failed to store deferred job result",
+			"trace_id", job.TraceID,
+			"error", err.Error())
+	}
+
+	log.Info("⏸️ Automation job deferred by blackout window",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"trigger_type", job.TriggerType,
+		"blackout_id", blackout.ID,
+		"resume_at", resumeAt)
+	return true
+}
+
+// runBackfillJob runs a historical import; the backfill report is stored as the job result
+// and a summary is returned for the run history
+func runBackfillJob(ctx context.Context, worker *processing.Worker, job *queue.Job) (*processing.ProcessingResult, *processing.BackfillReport) {
+	startTime := time.Now()
+
+	var from time.Time
+	if job.BackfillFrom != nil {
+		from = *job.BackfillFrom
+	}
+
+	report, err := worker.Backfill(ctx, job.UserID, from)
+	result := &processing.ProcessingResult{
+		UserID:          job.UserID,
+		Success:         err == nil,
+		ActivitiesCount: report.ActivitiesImported,
+		ProcessingTime:  time.Since(startTime),
+		TraceID:         job.TraceID,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorType = "BACKFILL_ERROR"
+	}
+	if report.Deferred {
+		result.Deferred = true
+		result.DeferredUntil = report.DeferredUntil
+		result.ErrorType = report.DeferReason
+		result.Error = fmt.Sprintf("Daily processing budget used up; the backfill continues at %s", report.DeferredUntil.Format(time.RFC3339))
+		switch report.DeferReason {
+		case processing.ErrorTypeUserBusy:
+			result.Error = fmt.Sprintf("Another sync is processing this user; the backfill starts at %s", report.DeferredUntil.Format(time.RFC3339))
+		case processing.ErrorTypeStravaBudgetExceeded:
+			result.Error = fmt.Sprintf("Daily Strava API budget used up; the backfill continues at %s", report.DeferredUntil.Format(time.RFC3339))
+		case processing.ErrorTypeBackfillContinues:
+			result.Error = fmt.Sprintf("Imported %d activities in this part of the backfill; the next part is queued", report.ActivitiesImported)
+		}
+	}
+	for _, gap := range report.Gaps {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: imported %d of %d activities reported by Strava",
+			gap.Scope, gap.Sport, gap.Imported, gap.Expected))
+	}
+
+	return result, report
+}
+
+// recordRunStart records the start of a run; failures are logged and never block processing
+func recordRunStart(ctx context.Context, runs *database.RunRepository, req *database.CreateRunRequest, log *logger.Logger) int {
+	runID, err := runs.CreateRun(ctx, req)
+	if err != nil {
+		log.Error("❌ Failed to record automation run start",
+			"user_id", req.UserID,
+			"trace_id", req.TraceID,
+			"is_test_mode", req.IsTestMode,
+			"error", err.Error())
+		return 0
+	}
+	return runID
+}
+
+// recordRunResult stores the outcome of a run started with recordRunStart
+func recordRunResult(ctx context.Context, runs *database.RunRepository, runID int, result *processing.ProcessingResult, log *logger.Logger) {
+	if runID == 0 {
+		return
+	}
+
+	status := database.RunStatusCompleted
+	if result.Deferred {
+		status = database.RunStatusDeferred
+	} else if !result.Success {
+		status = database.RunStatusFailed
+	}
+
+	if err := runs.CompleteRun(ctx, runID, status, result.ActivitiesCount, result.ErrorType, result.Error); err != nil {
+		log.Error("❌ Failed to record automation run result",
+			"run_id", runID,
+			"user_id", result.UserID,
+			"error", err.Error())
+	}
+
+	// Recorded sheet writes let the user undo the run from the API
+	if result.SheetWrites != nil {
+		writes, err := json.Marshal(result.SheetWrites)
+		if err == nil {
+			err = runs.RecordSheetWrites(ctx, runID, writes)
+		}
+		if err != nil {
+			log.Error("❌ Failed to record automation run sheet writes",
+				"run_id", runID,
+				"user_id", result.UserID,
+				"error", err.Error())
+		}
+	}
+}
+
+// runReconciliation reconciles a small batch of users that are due; the batch is kept small to
+// limit provider usage
+func runReconciliation(reconciler *processing.Reconciler, batchSize int, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	reports := reconciler.ReconcileDueUsers(ctx, batchSize)
+	log.Info("🔎 Background reconciliation batch completed",
+		"users_reconciled", len(reports))
+}
+
+// startTestModeProcessing runs the development loop that processes a single test user every minute
+// Callers must check cfg.ValidateTestMode first; every run is recorded with is_test_mode set
+func startTestModeProcessing(cfg *config.Config, worker *processing.Worker, reconciler *processing.Reconciler, runs *database.RunRepository, log *logger.Logger) {
+	testUserID := cfg.TestModeUserID
+	
+	log.Warn("🧪 Automation engine initialized in TEST MODE - processing a single configured user outside the job queue",
+		"test_mode", true,
+		"test_user_id", testUserID,
+		"environment", cfg.Environment,
 		"oauth_configured", cfg.StravaClientID != "" && cfg.GoogleClientID != "")
 
 	// Main processing loop
@@ -138,26 +724,31 @@ func main() {
 			"environment", cfg.Environment,
 			"next_cycle_in_seconds", 60)
 		
-		// For now, we'll implement a simple test cycle
-		// In the future, this would be replaced with job queue processing
+		// Test cycle used when the job queue is unavailable
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		
-		// Test processing with user ID 1 (if exists)
-		// This is a placeholder - real implementation would process from job queue
-		testUserID := 1
-		
 		log.Debug("🧪 Starting test processing for development user",
+			"test_mode", true,
 			"test_user_id", testUserID,
 			"cycle_number", cycleCount,
 			"timeout_minutes", 5,
 			"note", "This is a development test - production will use job queue")
 		
+		runID := recordRunStart(ctx, runs, &database.CreateRunRequest{
+			UserID:      testUserID,
+			TriggerType: queue.TriggerTestMode,
+			IsTestMode:  true,
+		}, log)
+		
 		result := worker.ProcessUser(ctx, testUserID)
 		
+		recordRunResult(ctx, runs, runID, result, log)
+		
 		cycleDuration := time.Since(cycleStartTime)
 		
 		if result.Success {
 			log.Info("✅ Test processing cycle completed successfully",
+				"test_mode", true,
 				"cycle_number", cycleCount,
 				"user_id", testUserID,
 				"cycle_results", map[string]interface{}{
@@ -168,6 +759,7 @@ func main() {
 				})
 		} else {
 			log.Warn("⚠️ Test processing cycle failed",
+				"test_mode", true,
 				"cycle_number", cycleCount,
 				"user_id", testUserID,
 				"cycle_results", map[string]interface{}{
@@ -179,7 +771,7 @@ func main() {
 					"success":                 false,
 				},
 				"troubleshooting", map[string]interface{}{
-					"check_user_exists":      "Verify user ID 1 exists in database",
+					"check_user_exists":      "Verify TEST_MODE_USER_ID exists in database (go run ./cmd/seed creates a demo user)",
 					"check_oauth_tokens":     "Verify user has valid OAuth tokens",
 					"check_spreadsheet_id":   "Verify user has configured spreadsheet ID",
 					"check_oauth_credentials": "Verify app OAuth credentials are configured",
@@ -188,6 +780,11 @@ func main() {
 		
 		cancel()
 		
+		// Low-priority reconciliation runs once an hour on a small batch of due users
+		if cycleCount%reconciliationEveryCycles == 0 {
+			runReconciliation(reconciler, cfg.Engine.ReconciliationBatchSize, log)
+		}
+		
 		// Wait before next cycle
 		log.Debug("💤 Automation processing cycle completed, waiting for next cycle",
 			"cycle_number", cycleCount,
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/cmd/automation-engine/internal/processing"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/capture"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/objectstore"
+)
+
+// Exit codes of -replay
+const (
+	exitReplayOK     = 0
+	exitReplayFailed = 5
+)
+
+// replayFlags select a captured payload and how to replay it
+type replayFlags struct {
+	source string
+	userID int
+	dryRun bool
+}
+
+func registerReplayFlags() *replayFlags {
+	f := &replayFlags{}
+	flag.StringVar(&f.source, "replay", "", "Replay a captured Strava payload (gs://bucket/object or a local file) and exit")
+	flag.IntVar(&f.userID, "replay-user", 0, "Write the replay with this user's settings and spreadsheet instead of the captured user's")
+	flag.BoolVar(&f.dryRun, "replay-dry-run", false, "Print the rows the replay would write without writing them")
+	return f
+}
+
+// runReplay processes a captured payload's activities through row conversion and sheet writing
+// without calling Strava, and prints the processing result
+func runReplay(ctx context.Context, worker *processing.Worker, flags *replayFlags, log *logger.Logger) int {
+	data, err := readPayload(ctx, flags.source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitReplayFailed
+	}
+	payload, err := capture.Decode(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", flags.source, err)
+		return exitReplayFailed
+	}
+	activities, err := payload.Activities()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", flags.source, err)
+		return exitReplayFailed
+	}
+
+	userID := payload.UserID
+	if flags.userID > 0 {
+		userID = flags.userID
+	}
+	log.Info("Replaying captured Strava responses",
+		"source", flags.source,
+		"captured_user_id", payload.UserID,
+		"user_id", userID,
+		"captured_at", payload.CapturedAt,
+		"activities", len(activities),
+		"dry_run", flags.dryRun)
+
+	traceID := "replay"
+	if payload.TraceID != "" {
+		traceID += "-" + payload.TraceID
+	}
+	result := worker.ProcessUserWithOptions(ctx, userID, processing.ProcessOptions{
+		TraceID: traceID,
+		DryRun:  flags.dryRun,
+		Replay:  activities,
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to encode the result: %v\n", err)
+	}
+	if !result.Success {
+		return exitReplayFailed
+	}
+	return exitReplayOK
+}
+
+// readPayload reads a payload from a gs:// URL or a local file
+func readPayload(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "gs://") {
+		return os.ReadFile(source)
+	}
+
+	bucketName, name, err := objectstore.ParseURL(source)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := objectstore.NewGCSBucket(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.Get(ctx, name)
+}
@@ -2,25 +2,18 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	_ "github.com/lib/pq"
-
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/handlers"
-	authMiddleware "github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/server"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/health"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
-	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
 )
 
 // performStartupHealthChecks validates critical dependencies and fails fast if any are unavailable
@@ -56,11 +49,24 @@ func performStartupHealthChecks(cfg *config.Config, log *logger.Logger) error {
 		return fmt.Errorf("database dependency check failed: %w", err)
 	}
 	
+	// Optional: verify the OAuth client credentials so an incorrectly rotated secret stops
+	// startup instead of failing every sign-in or token refresh
+	if cfg.Providers.VerifyCredentials {
+		if err := healthChecker.VerifyOAuthClients(ctx, app.OAuthClients(cfg)...); err != nil {
+			log.Critical("Critical dependency failed: OAuth client credentials rejected",
+				"error", err.Error())
+			return fmt.Errorf("OAuth credential check failed: %w", err)
+		}
+	}
+
 	log.Info("All critical dependency health checks passed successfully")
 	return nil
 }
 
 func main() {
+	validateConfig := flag.Bool("validate-config", false, "Validate the configuration and exit (for CI smoke tests)")
+	flag.Parse()
+
 	// Load configuration using hybrid loading strategy
 	cfg, err := config.Load()
 	if err != nil {
@@ -69,8 +75,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Check the settings this service requires, reporting every missing one at once
+	if code, stop := app.CheckServiceConfig(cfg, config.ServiceBackendAPI, *validateConfig); stop {
+		os.Exit(code)
+	}
+
 	// Initialize structured logger
-	log := logger.New("backend-api")
+	log := app.NewLogger(cfg, "backend-api")
 
 	log.Info("Backend API starting", 
 		"environment", cfg.Environment, 
@@ -88,147 +99,32 @@ func main() {
 		os.Exit(2) // Exit code 2 indicates dependency failure
 	}
 
-	// Initialize database connection
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	// Construct repositories, services and middleware for the API
+	container, err := app.Open(cfg, log, app.ProfileBackendAPI)
 	if err != nil {
-		log.Critical("Failed to connect to database", "error", err)
+		log.Critical("Failed to initialize backend dependencies", "error", err)
 		os.Exit(1)
 	}
-	defer db.Close()
-
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Critical("Failed to ping database", "error", err)
-		os.Exit(1)
-	}
-	log.Info("Database connection established successfully")
-
-	// Initialize services
-	jwtService := auth.NewJWTService(cfg.JWTSecret)
-	encryptionService := auth.NewEncryptionService(cfg.EncryptionSecret) // Use separate encryption secret
-	
-	// Construct OAuth redirect URLs using configurable base URL
-	googleRedirectURL := fmt.Sprintf("%s/api/auth/google/callback", cfg.BaseURL)
-	stravaRedirectURL := fmt.Sprintf("%s/api/connections/strava/callback", cfg.BaseURL)
-	oauthService := auth.NewOAuthService(
-		cfg.GoogleClientID, 
-		cfg.GoogleClientSecret, 
-		googleRedirectURL, 
-		cfg.StravaClientID, 
-		cfg.StravaClientSecret, 
-		stravaRedirectURL,
-	)
-
-	// Initialize repositories
-	userRepository := database.NewUserRepository(db, encryptionService)
-	sessionRepository := database.NewSessionRepository(db)
-
-	// Initialize services
-	sheetsService := services.NewSheetsService(userRepository, log)
-	configService := services.NewConfigService(userRepository, sheetsService, log)
-
-	// Initialize middleware
-	authMW := authMiddleware.NewAuthMiddleware(jwtService, sessionRepository, oauthService, userRepository, log.WithContext("component", "auth_middleware"))
-
-	// Initialize handlers
-	// Determine if running in development mode
-	isDevelopment := cfg.Environment == "local" || cfg.Environment == "development" || cfg.Environment == "dev"
-	
-	authHandler := handlers.NewAuthHandler(
-		oauthService,
-		jwtService,
-		userRepository,
-		sessionRepository,
-		cfg.FrontendURL,
-		isDevelopment,
-		log.WithContext("component", "auth_handler"),
-	)
+	defer container.Close()
 
-	stravaHandler := handlers.NewStravaHandler(
-		oauthService,
-		userRepository,
-		cfg.FrontendURL,
-		isDevelopment,
-		log.WithContext("component", "strava_handler"),
-	)
-
-	configHandler := handlers.NewConfigHandler(
-		configService,
-		log.WithContext("component", "config_handler"),
-	)
-
-	// Create router
-	r := chi.NewRouter()
-
-	// Global middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(authMiddleware.CORS(cfg.FrontendURL)) // Enable CORS for frontend communication
-
-	// Public routes (no authentication required)
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "Academy Sync Backend API is running in %s environment!", cfg.Environment)
-	})
-
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintf(w, `{"status": "healthy", "environment": "%s", "service": "backend-api"}`, cfg.Environment)
-	})
-
-	// Authentication routes (public)
-	r.Route("/api/auth", func(r chi.Router) {
-		r.Get("/google", authHandler.GoogleAuthURL)           // Get Google OAuth URL
-		r.Get("/google/callback", authHandler.GoogleCallback) // Handle OAuth callback
-		r.Post("/refresh", authHandler.RefreshToken)          // Refresh JWT token
-		
-		// Protected auth routes
-		r.Group(func(r chi.Router) {
-			r.Use(authMW.RequireAuth)
-			r.Get("/me", authHandler.GetCurrentUser) // Get current user info
-			r.Post("/logout", authHandler.Logout)    // Logout user
-		})
-	})
-
-	// Connection routes - mixed public and protected
-	r.Route("/api/connections", func(r chi.Router) {
-		// Public OAuth callback (Strava redirects here directly)
-		r.Get("/strava/callback", stravaHandler.StravaCallback) // Handle Strava OAuth callback (public)
-		
-		// Protected Strava endpoints (require authentication)
-		r.Group(func(r chi.Router) {
-			r.Use(authMW.RequireAuth)
-			r.Get("/strava", stravaHandler.StravaAuthURL)      // Get Strava OAuth URL
-			r.Delete("/strava", stravaHandler.DisconnectStrava) // Disconnect Strava account
-		})
-	})
-
-	// Protected API routes (authentication required)
-	r.Route("/api", func(r chi.Router) {
-		r.Use(authMW.RequireAuth)
-		
-		// User routes
-		r.Route("/users", func(r chi.Router) {
-			r.Get("/me", authHandler.GetCurrentUser) // Duplicate for convenience
-		})
-
-		// Configuration routes
-		r.Route("/config", func(r chi.Router) {
-			r.Post("/spreadsheet", configHandler.SetSpreadsheet)      // Set spreadsheet URL
-			r.Delete("/spreadsheet", configHandler.ClearSpreadsheet)  // Clear spreadsheet configuration
-		})
-
-		// Future protected endpoints will go here
-		// r.Route("/automation", func(r chi.Router) { ... })
-		// r.Route("/notifications", func(r chi.Router) { ... })
-	})
+	// Handlers, middleware and routes; background work stops when the process exits
+	router := server.NewRouter(context.Background(), cfg, container, log)
 
 	log.Info("Backend API server starting", 
 		"port", cfg.Port,
 		"base_url", cfg.BaseURL,
-		"google_oauth_redirect_url", googleRedirectURL,
-		"strava_oauth_redirect_url", stravaRedirectURL)
+		"google_oauth_redirect_url", app.GoogleRedirectURL(cfg),
+		"strava_oauth_redirect_url", app.StravaRedirectURL(cfg))
 	
-	if err := http.ListenAndServe(":"+cfg.Port, r); err != nil {
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.API.ReadHeaderTimeout,
+		ReadTimeout:       cfg.API.ReadTimeout,
+		WriteTimeout:      cfg.API.WriteTimeout,
+		IdleTimeout:       cfg.API.IdleTimeout,
+	}
+	if err := server.ListenAndServe(); err != nil {
 		log.Critical("Server failed to start", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,51 @@
+// Command devstub serves fake Strava and Google APIs so the whole stack runs locally without
+// real OAuth apps.
+//
+// Usage:
+//
+//	devstub [-addr :9090] [-days 60] [-email athlete@devstub.local] [-name "Dev Athlete"]
+//
+// Point backend-api, the automation engine and the notification service at it with
+// PROVIDER_STUB_URL=http://localhost:9090. Signing in with Google and connecting Strava then
+// redirect straight back, Strava serves a seeded history of the last -days days and
+// spreadsheets live in memory until the stub exits.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/devstub"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	days := flag.Int("days", 60, "days of seeded Strava history")
+	email := flag.String("email", devstub.DefaultEmail, "email of the Google account")
+	name := flag.String("name", devstub.DefaultName, "name of the Google account")
+	flag.Parse()
+
+	log := logger.New("devstub")
+	stub := devstub.New(devstub.Options{
+		Email:      *email,
+		Name:       *name,
+		Activities: devstub.SeedActivities(time.Now(), *days),
+	}, log)
+
+	log.Info("Provider stub server starting", "addr", *addr, "days", *days, "email", *email)
+	fmt.Fprintf(os.Stderr, "Set PROVIDER_STUB_URL=http://localhost%s to use the stub\n", *addr)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           stub.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := server.ListenAndServe(); err != nil {
+		log.Critical("Provider stub server failed", "error", err)
+		os.Exit(1)
+	}
+}
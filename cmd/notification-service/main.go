@@ -2,15 +2,17 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"time"
 
-	_ "github.com/lib/pq"
-
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/health"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
 )
 
@@ -65,6 +67,9 @@ func performStartupHealthChecks(cfg *config.Config, log *logger.Logger) error {
 }
 
 func main() {
+	validateConfig := flag.Bool("validate-config", false, "Validate the configuration and exit (for CI smoke tests)")
+	flag.Parse()
+
 	// Load configuration using hybrid loading strategy
 	cfg, err := config.Load()
 	if err != nil {
@@ -73,8 +78,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Check the settings this service requires, reporting every missing one at once
+	if code, stop := app.CheckServiceConfig(cfg, config.ServiceNotifier, *validateConfig); stop {
+		os.Exit(code)
+	}
+
 	// Initialize structured logger
-	log := logger.New("notification-service")
+	log := app.NewLogger(cfg, "notification-service")
 
 	log.Info("Notification Service starting", 
 		"environment", cfg.Environment,
@@ -82,7 +92,9 @@ func main() {
 	log.Info("Configuration status", 
 		"database_configured", cfg.DatabaseURL != "",
 		"redis_configured", cfg.RedisURL != "",
-		"smtp_configured", cfg.SMTPHost != "" && cfg.SMTPUsername != "")
+		"email_provider", cfg.EmailProvider,
+		"smtp_configured", cfg.SMTPHost != "" && cfg.SMTPUsername != "",
+		"sendgrid_configured", cfg.SendGridAPIKey != "")
 
 	// Dependency Health Check - US046 Fail Fast Mechanism
 	// Validate critical dependencies before starting processing loop
@@ -92,8 +104,155 @@ func main() {
 		os.Exit(2) // Exit code 2 indicates dependency failure
 	}
 
+	// The database is optional here; components that need it are nil without one
+	container, err := app.Open(cfg, log, app.ProfileNotificationService)
+	if err != nil {
+		log.Critical("Failed to initialize notification service dependencies", "error", err.Error())
+		os.Exit(3)
+	}
+	defer container.Close()
+
+	// A rotated SMTP password or SendGrid key is applied every SECRET_RELOAD_INTERVAL
+	if secretWatcher, err := container.WatchSecrets(context.Background()); err != nil {
+		log.Warn("Secret reloading disabled", "error", err)
+	} else {
+		go container.RunSecretReloads(context.Background(), secretWatcher)
+	}
+
+	// Database pool statistics are logged every DB_STATS_INTERVAL
+	go container.RunPoolStats(context.Background())
+
+	// pprof profiles, goroutine and heap dumps on the internal DIAGNOSTICS_ADDR when enabled
+	go container.RunDiagnostics(context.Background())
+
+	detector := container.QuietFailureDetector
+	runNotifier := container.RunNotifier
+	digestScheduler := container.DigestScheduler
+	quietHoursReleaser := container.QuietHoursReleaser
+	signInAlerter := container.SignInAlerter
+
+	// Syncs finished and credentials rejected by the engine wake the loop, so their notifications
+	// go out without waiting for NOTIFIER_POLL_INTERVAL. Polling still finds the runs of events
+	// missed while the service or Redis was down.
+	var wake <-chan struct{}
+	if runNotifier != nil {
+		wake = subscribeToRunEvents(container, log)
+	}
+
+	var lastQuietFailureCheck, lastDigestCheck time.Time
 	for {
 		log.Debug("Processing notification queue", "environment", cfg.Environment)
-		time.Sleep(30 * time.Second)
+		
+		if runNotifier != nil {
+			runRunNotifications(runNotifier, log)
+		}
+		
+		if signInAlerter != nil {
+			runSignInAlerts(signInAlerter, log)
+		}
+		
+		if quietHoursReleaser != nil {
+			runQuietHoursReleases(quietHoursReleaser, log)
+		}
+		
+		if digestScheduler != nil && time.Since(lastDigestCheck) >= cfg.Notifier.DigestCheckInterval {
+			runDigests(digestScheduler, log)
+			lastDigestCheck = time.Now()
+		}
+		
+		if detector != nil && time.Since(lastQuietFailureCheck) >= cfg.Notifier.QuietFailureCheckInterval {
+			runQuietFailureDetection(detector, log)
+			lastQuietFailureCheck = time.Now()
+		}
+		
+		select {
+		case <-time.After(cfg.Notifier.PollInterval):
+		case <-wake:
+		}
+	}
+}
+
+// subscribeToRunEvents returns a channel that receives a value when the engine publishes a run
+// outcome; it is nil, and never receives, without the event bus. A burst of events coalesces into
+// one wakeup.
+func subscribeToRunEvents(container *app.Container, log *logger.Logger) <-chan struct{} {
+	if container.Config.RedisURL == "" {
+		return nil
+	}
+	
+	bus, err := container.ConnectEventBus()
+	if err != nil {
+		log.Warn("Event bus unavailable - run notifications wait for the poll interval", "error", err.Error())
+		return nil
+	}
+	received, err := bus.Subscribe(context.Background(), events.TypeSyncCompleted, events.TypeSyncFailed, events.TypeReauthRequired)
+	if err != nil {
+		log.Warn("Failed to subscribe to run events - run notifications wait for the poll interval", "error", err.Error())
+		return nil
+	}
+	
+	wake := make(chan struct{}, 1)
+	go func() {
+		for event := range received {
+			log.Debug("Run event received",
+				"event_type", event.Type,
+				"event_id", event.ID,
+				"source", event.Source)
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return wake
+}
+
+// runQuietFailureDetection nudges users whose automation has gone quiet
+func runQuietFailureDetection(detector *notification.QuietFailureDetector, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	
+	if _, err := detector.Run(ctx); err != nil {
+		log.Error("Quiet failure detection failed", "error", err.Error())
+	}
+}
+
+// runRunNotifications posts summaries and failure alerts of recently finished runs to chat channels
+func runRunNotifications(notifier *notification.RunNotifier, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	
+	if _, err := notifier.Run(ctx); err != nil {
+		log.Error("Run notifications failed", "error", err.Error())
+	}
+}
+
+// runSignInAlerts emails users about sign-ins from new devices or locations
+func runSignInAlerts(alerter *notification.SignInAlerter, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	
+	if _, err := alerter.Run(ctx); err != nil {
+		log.Error("New sign-in alerts failed", "error", err.Error())
+	}
+}
+
+// runQuietHoursReleases delivers the notifications held until users' quiet hours ended
+func runQuietHoursReleases(releaser *notification.QuietHoursReleaser, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	
+	if _, err := releaser.Run(ctx); err != nil {
+		log.Error("Releasing held notifications failed", "error", err.Error())
+	}
+}
+
+// runDigests sends the daily digests that are due
+func runDigests(scheduler *notification.DigestScheduler, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	
+	if _, err := scheduler.Run(ctx); err != nil {
+		log.Error("Digest delivery failed", "error", err.Error())
 	}
 }
\ No newline at end of file
@@ -0,0 +1,267 @@
+// Command remediation re-processes a historical date range for every user affected by an incident.
+//
+// Usage:
+//
+//	remediation rerun -from 2024-05-01 -to 2024-05-02 [-lookback 168h] [-dry-run] [-wait]
+//	remediation status -batch <batch-id> [-wait]
+//
+// rerun finds users with runs started in [from, to) in the run history and enqueues one
+// correction job per user that refetches [from - lookback, to) from Strava and rewrites the
+// affected rows. The jobs are tracked as a batch whose progress status reports.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+const (
+	// defaultLookback matches the automation engine's fetch window: a run on day D writes rows
+	// for activities from the 7 days before it, so those days need correcting too
+	defaultLookback = 7 * 24 * time.Hour
+
+	// batchPollInterval is how often -wait checks the batch progress
+	batchPollInterval = 10 * time.Second
+)
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitUsage    = 1
+	exitFailure  = 2
+	exitJobsFail = 3
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to load configuration: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if _, stop := app.CheckServiceConfig(cfg, config.ServiceRemediation, false); stop {
+		os.Exit(exitFailure)
+	}
+	log := app.NewLogger(cfg, "remediation")
+
+	switch os.Args[1] {
+	case "rerun":
+		os.Exit(runRerun(cfg, log, os.Args[2:]))
+	case "status":
+		os.Exit(runStatus(cfg, log, os.Args[2:]))
+	default:
+		usage()
+		os.Exit(exitUsage)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  remediation rerun -from <date> -to <date> [-lookback 168h] [-dry-run] [-wait]")
+	fmt.Fprintln(os.Stderr, "  remediation status -batch <batch-id> [-wait]")
+}
+
+// runRerun enqueues correction jobs for every user with a run in the incident window
+func runRerun(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("rerun", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "start of the incident window (YYYY-MM-DD or RFC3339, UTC)")
+	toFlag := flags.String("to", "", "end of the incident window, exclusive (YYYY-MM-DD or RFC3339, UTC)")
+	lookback := flags.Duration("lookback", defaultLookback, "how far before -from the affected runs fetched activities")
+	dryRun := flags.Bool("dry-run", false, "enqueue dry-run jobs that report the corrections without writing")
+	wait := flags.Bool("wait", false, "wait until every job of the batch has finished")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+		return exitUsage
+	}
+	to, err := parseTime(*toFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -to: %v\n", err)
+		return exitUsage
+	}
+	if !from.Before(to) {
+		fmt.Fprintln(os.Stderr, "-from must be before -to")
+		return exitUsage
+	}
+
+	container, err := app.Open(cfg, log, app.ProfileMaintenance)
+	if err != nil {
+		log.Critical("Failed to initialize remediation dependencies", "error", err.Error())
+		return exitFailure
+	}
+	defer container.Close()
+
+	jobQueue, err := container.ConnectJobQueue()
+	if err != nil {
+		log.Critical("Job queue unavailable - cannot enqueue correction jobs", "error", err.Error())
+		return exitFailure
+	}
+
+	ctx := context.Background()
+	userIDs, err := container.RunRepository.ListUsersWithRuns(ctx, from, to)
+	if err != nil {
+		log.Critical("Failed to query run history", "error", err.Error())
+		return exitFailure
+	}
+
+	syncFrom := from.Add(-*lookback)
+	syncTo := to
+	batch := &queue.Batch{
+		Description: fmt.Sprintf("rerun %s..%s (sync range %s..%s, dry_run=%t)",
+			from.Format(time.RFC3339), to.Format(time.RFC3339),
+			syncFrom.Format(time.RFC3339), syncTo.Format(time.RFC3339), *dryRun),
+	}
+
+	log.Info("Enqueuing remediation jobs",
+		"incident_from", from.Format(time.RFC3339),
+		"incident_to", to.Format(time.RFC3339),
+		"sync_from", syncFrom.Format(time.RFC3339),
+		"sync_to", syncTo.Format(time.RFC3339),
+		"affected_users", len(userIDs),
+		"dry_run", *dryRun)
+
+	for _, userID := range userIDs {
+		job := &queue.Job{
+			UserID:      userID,
+			TriggerType: queue.TriggerRemediation,
+			DryRun:      *dryRun,
+			SyncFrom:    &syncFrom,
+			SyncTo:      &syncTo,
+		}
+		if err := jobQueue.Enqueue(ctx, job); err != nil {
+			// Jobs enqueued so far still run; save the partial batch so they stay trackable
+			log.Error("Failed to enqueue remediation job",
+				"user_id", userID,
+				"error", err.Error())
+			break
+		}
+		batch.TraceIDs = append(batch.TraceIDs, job.TraceID)
+	}
+
+	if err := jobQueue.SaveBatch(ctx, batch); err != nil {
+		log.Critical("Failed to save remediation batch", "error", err.Error())
+		return exitFailure
+	}
+
+	fmt.Printf("batch %s: enqueued %d of %d affected users\n", batch.ID, len(batch.TraceIDs), len(userIDs))
+	if len(batch.TraceIDs) < len(userIDs) {
+		return exitFailure
+	}
+
+	if !*wait {
+		return exitOK
+	}
+	return waitForBatch(ctx, jobQueue, batch)
+}
+
+// runStatus prints the progress of a remediation batch
+func runStatus(cfg *config.Config, log *logger.Logger, args []string) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	batchID := flags.String("batch", "", "batch ID printed by rerun")
+	wait := flags.Bool("wait", false, "wait until every job of the batch has finished")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *batchID == "" {
+		fmt.Fprintln(os.Stderr, "-batch is required")
+		return exitUsage
+	}
+
+	container, err := app.Open(cfg, log, app.ProfileMaintenance)
+	if err != nil {
+		log.Critical("Failed to initialize remediation dependencies", "error", err.Error())
+		return exitFailure
+	}
+	defer container.Close()
+
+	jobQueue, err := container.ConnectJobQueue()
+	if err != nil {
+		log.Critical("Job queue unavailable - cannot read batch status", "error", err.Error())
+		return exitFailure
+	}
+
+	ctx := context.Background()
+	batch, err := jobQueue.GetBatch(ctx, *batchID)
+	if err != nil {
+		log.Critical("Failed to read remediation batch", "error", err.Error())
+		return exitFailure
+	}
+	if batch == nil {
+		fmt.Fprintf(os.Stderr, "batch %s not found (it may have expired)\n", *batchID)
+		return exitFailure
+	}
+
+	if *wait {
+		return waitForBatch(ctx, jobQueue, batch)
+	}
+
+	progress, err := jobQueue.GetBatchProgress(ctx, batch)
+	if err != nil {
+		log.Critical("Failed to read batch progress", "error", err.Error())
+		return exitFailure
+	}
+	printProgress(batch, progress)
+	return progressExitCode(progress)
+}
+
+// waitForBatch polls the batch until no job is queued or running
+func waitForBatch(ctx context.Context, jobQueue *queue.Client, batch *queue.Batch) int {
+	for {
+		progress, err := jobQueue.GetBatchProgress(ctx, batch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read batch progress: %v\n", err)
+			return exitFailure
+		}
+		if progress.Done() {
+			printProgress(batch, progress)
+			return progressExitCode(progress)
+		}
+
+		fmt.Printf("batch %s: %d/%d finished\n", batch.ID, progress.Completed+progress.Failed, progress.Total)
+		time.Sleep(batchPollInterval)
+	}
+}
+
+func printProgress(batch *queue.Batch, progress *queue.BatchProgress) {
+	output, _ := json.MarshalIndent(map[string]interface{}{
+		"batch":    batch,
+		"progress": progress,
+		"finished": progress.Done(),
+	}, "", "  ")
+	fmt.Println(string(output))
+}
+
+func progressExitCode(progress *queue.BatchProgress) int {
+	if progress.Failed > 0 {
+		return exitJobsFail
+	}
+	return exitOK
+}
+
+// parseTime accepts a UTC date or an RFC3339 timestamp
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("value is required")
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
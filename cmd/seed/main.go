@@ -0,0 +1,148 @@
+// Command seed prepares a local database for development: it applies the migrations and creates
+// a demo user connected to the devstub providers, so the whole sync pipeline runs without real
+// OAuth apps.
+//
+// Usage:
+//
+//	seed [-spreadsheet devstub-demo-sheet] [-skip-migrate]
+//
+// The demo user is the account the stub's Google sign-in returns, with the stub's Strava athlete
+// and tokens and a spreadsheet the stub keeps in memory. Running seed again refreshes the user
+// instead of creating another. Run every service with PROVIDER_STUB_URL pointing at cmd/devstub.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/devstub"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// defaultSpreadsheetID is the demo user's spreadsheet; the stub accepts any ID
+const defaultSpreadsheetID = "devstub-demo-sheet"
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitUsage   = 1
+	exitFailure = 2
+)
+
+func main() {
+	spreadsheetID := flag.String("spreadsheet", defaultSpreadsheetID, "spreadsheet ID of the demo user")
+	skipMigrate := flag.Bool("skip-migrate", false, "do not apply pending migrations first")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to load configuration: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if cfg.IsProduction() {
+		fmt.Fprintf(os.Stderr, "ERROR: seeding is not allowed in the %s environment\n", cfg.Environment)
+		os.Exit(exitUsage)
+	}
+	if _, stop := app.CheckServiceConfig(cfg, config.ServiceSeed, false); stop {
+		os.Exit(exitFailure)
+	}
+	log := app.NewLogger(cfg, "seed")
+
+	if cfg.Providers.StubURL == "" {
+		log.Warn("PROVIDER_STUB_URL is not set - the demo user's tokens only work against cmd/devstub")
+	}
+
+	os.Exit(run(cfg, log, *spreadsheetID, !*skipMigrate))
+}
+
+func run(cfg *config.Config, log *logger.Logger, spreadsheetID string, migrate bool) int {
+	container, err := app.Open(cfg, log, app.ProfileMaintenance)
+	if err != nil {
+		log.Critical("Failed to initialize seed dependencies", "error", err.Error())
+		return exitFailure
+	}
+	defer container.Close()
+
+	ctx := context.Background()
+	if migrate {
+		applied, err := database.NewMigrator(container.DB).Up(ctx)
+		if err != nil {
+			log.Critical("Migration failed", "error", err.Error())
+			return exitFailure
+		}
+		log.Info("Migrations applied", "count", len(applied))
+	}
+
+	userID, err := seedDemoUser(ctx, container.UserRepository, spreadsheetID)
+	if errors.Is(err, database.ErrStravaAthleteConnected) {
+		log.Critical("The stub Strava athlete is connected to another user; disconnect it or reset the database",
+			"athlete_id", devstub.DefaultAthleteID)
+		return exitFailure
+	}
+	if err != nil {
+		log.Critical("Failed to seed the demo user", "error", err.Error())
+		return exitFailure
+	}
+
+	fmt.Printf("demo user %d (%s) is ready with spreadsheet %s\n", userID, devstub.DefaultEmail, spreadsheetID)
+	fmt.Printf("sync it now:        go run ./cmd/academyctl sync trigger -user %d\n", userID)
+	fmt.Printf("or without Redis:   TEST_MODE_USER_ID=%d go run ./cmd/automation-engine\n", userID)
+	return exitOK
+}
+
+// seedDemoUser creates or refreshes the stub identity's user with both connections, the
+// spreadsheet and automation due now, and returns its ID
+func seedDemoUser(ctx context.Context, users *database.UserRepository, spreadsheetID string) (int, error) {
+	expiry := time.Now().Add(time.Hour)
+
+	user, err := users.GetUserByGoogleID(ctx, devstub.DefaultGoogleID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		user, err = users.CreateUser(ctx, &database.CreateUserRequest{
+			GoogleID:           devstub.DefaultGoogleID,
+			Email:              devstub.DefaultEmail,
+			Name:               devstub.DefaultName,
+			GoogleAccessToken:  devstub.GoogleAccessToken,
+			GoogleRefreshToken: devstub.GoogleRefreshToken,
+			GoogleTokenExpiry:  &expiry,
+		})
+	} else {
+		err = users.UpdateUserTokens(ctx, &database.UpdateUserTokensRequest{
+			UserID:             user.ID,
+			GoogleAccessToken:  devstub.GoogleAccessToken,
+			GoogleRefreshToken: devstub.GoogleRefreshToken,
+			GoogleTokenExpiry:  &expiry,
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := users.UpdateStravaConnection(ctx, &database.UpdateStravaConnectionRequest{
+		UserID:       user.ID,
+		AccessToken:  devstub.StravaAccessToken,
+		RefreshToken: devstub.StravaRefreshToken,
+		TokenExpiry:  &expiry,
+		AthleteID:    devstub.DefaultAthleteID,
+		AthleteName:  devstub.DefaultName,
+	}); err != nil {
+		return 0, err
+	}
+	if err := users.UpdateSpreadsheetID(ctx, user.ID, spreadsheetID); err != nil {
+		return 0, err
+	}
+	// Due at once rather than at the next daily run, so the demo user is ready for processing
+	if err := users.EnableAutomation(ctx, user.ID, time.Now()); err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,106 @@
+// Package apierror writes the JSON error envelope returned by every API endpoint:
+//
+//	{"error": {"code": "STRAVA_NOT_CONNECTED", "message": "...", "details": {...}, "request_id": "..."}}
+//
+// Codes are stable identifiers clients can switch on; messages are for people and may change.
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/failures"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// RequestIDHeader carries the request ID set by the request ID middleware; error bodies echo it
+const RequestIDHeader = "X-Request-ID"
+
+// Codes shared by every endpoint. Endpoints add more specific codes, such as EMPTY_URL, where
+// clients need to tell failures apart.
+const (
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeTokenExpired         = "TOKEN_EXPIRED" // Refresh the access token and retry
+	CodeForbidden            = "FORBIDDEN"
+	CodeAccountSuspended     = "ACCOUNT_SUSPENDED"
+	CodeNotFound             = "NOT_FOUND"
+	CodeInvalidJSON          = "INVALID_JSON"
+	CodeValidation           = "VALIDATION_ERROR"
+	CodeStravaReauthRequired = "STRAVA_REAUTH_REQUIRED"
+	CodeGoogleReauthRequired = "GOOGLE_REAUTH_REQUIRED"
+	CodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// Response is the body of an error response
+type Response struct {
+	Error Error `json:"error"`
+}
+
+// Error describes what went wrong
+type Error struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	// RequestID identifies the request in the API's logs
+	RequestID string `json:"request_id,omitempty"`
+
+	// Set from the failure catalog for classified errors, so the web app can offer a fix such
+	// as reconnecting Strava without mapping error codes itself
+	HelpURL     string `json:"help_url,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// New builds an error response, attaching the catalog's help for code
+func New(code, message string) Response {
+	response := Response{Error: Error{Code: code, Message: message}}
+	if help, ok := failures.Lookup(code); ok {
+		response.Error.HelpURL = help.HelpURL
+		response.Error.Remediation = string(help.Remediation)
+	}
+	return response
+}
+
+// WithDetail returns r with key set in its details
+func (r Response) WithDetail(key string, value interface{}) Response {
+	details := make(map[string]interface{}, len(r.Error.Details)+1)
+	for k, v := range r.Error.Details {
+		details[k] = v
+	}
+	details[key] = value
+	r.Error.Details = details
+	return r
+}
+
+// Write writes response with statusCode, echoing the request ID of the response
+func Write(w http.ResponseWriter, statusCode int, response Response) error {
+	if response.Error.RequestID == "" {
+		response.Error.RequestID = w.Header().Get(RequestIDHeader)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// FromError maps the error types shared across the backend to a status and a stable code:
+// missing rows are NOT_FOUND, authorization refusals FORBIDDEN, and revoked provider grants
+// STRAVA_REAUTH_REQUIRED or GOOGLE_REAUTH_REQUIRED. Anything else is an INTERNAL_ERROR whose
+// details are not exposed.
+func FromError(err error) (int, Response) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound, New(CodeNotFound, "Resource not found")
+	case errors.Is(err, authz.ErrForbidden):
+		return http.StatusForbidden, New(CodeForbidden, "Not allowed to perform this action")
+	case strava.IsReauthRequired(err):
+		return http.StatusUnauthorized, New(CodeStravaReauthRequired, "Strava connection requires re-authorization")
+	case google.IsReauthRequired(err):
+		return http.StatusUnauthorized, New(CodeGoogleReauthRequired, "Google connection requires re-authorization")
+	default:
+		return http.StatusInternalServerError, New(CodeInternal, "An unexpected error occurred")
+	}
+}
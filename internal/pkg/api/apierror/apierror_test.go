@@ -0,0 +1,79 @@
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestWrite(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rr.Header().Set(RequestIDHeader, "req-123")
+
+	if err := Write(rr, http.StatusBadRequest, New("EMPTY_URL", "Spreadsheet URL cannot be empty").WithDetail("field", "url")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rr.Code)
+	}
+	if rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", rr.Header().Get("Content-Type"))
+	}
+
+	var body map[string]map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	envelope := body["error"]
+	if envelope["code"] != "EMPTY_URL" || envelope["message"] != "Spreadsheet URL cannot be empty" || envelope["request_id"] != "req-123" {
+		t.Errorf("Unexpected envelope: %v", envelope)
+	}
+	if details, _ := envelope["details"].(map[string]interface{}); details["field"] != "url" {
+		t.Errorf("Expected details.field=url, got %v", envelope["details"])
+	}
+}
+
+func TestWithDetail_DoesNotShareDetails(t *testing.T) {
+	base := New(CodeValidation, "Invalid request").WithDetail("field", "url")
+	extended := base.WithDetail("reason", "empty")
+
+	if _, ok := base.Error.Details["reason"]; ok {
+		t.Error("Expected WithDetail to leave the original response unchanged")
+	}
+	if extended.Error.Details["field"] != "url" || extended.Error.Details["reason"] != "empty" {
+		t.Errorf("Unexpected details: %v", extended.Error.Details)
+	}
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), http.StatusNotFound, CodeNotFound},
+		{"forbidden", authz.ErrForbidden, http.StatusForbidden, CodeForbidden},
+		{"strava reauth", fmt.Errorf("refresh: %w", strava.ErrReauthRequired), http.StatusUnauthorized, CodeStravaReauthRequired},
+		{"google reauth", fmt.Errorf("refresh: %w", google.ErrReauthRequired), http.StatusUnauthorized, CodeGoogleReauthRequired},
+		{"unknown", errors.New("connection reset"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := FromError(tt.err)
+			if status != tt.expectedStatus || response.Error.Code != tt.expectedCode {
+				t.Errorf("Expected %d %s, got %d %s", tt.expectedStatus, tt.expectedCode, status, response.Error.Code)
+			}
+		})
+	}
+}
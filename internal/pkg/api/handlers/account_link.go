@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+const (
+	// accountLinkCookie carries a pending account link from the OAuth callback to its confirmation
+	accountLinkCookie = "account_link"
+
+	// accountLinkTTL is how long the user has to confirm a pending account link
+	accountLinkTTL = 10 * time.Minute
+
+	// ErrorCodeAccountLinkExpired is returned when there is no pending account link to confirm
+	ErrorCodeAccountLinkExpired = "ACCOUNT_LINK_EXPIRED"
+)
+
+// AccountLinker finds users by email and links further Google accounts to them (implemented by
+// *database.UserRepository)
+type AccountLinker interface {
+	GetUserByEmail(ctx context.Context, email string) (*database.User, error)
+	LinkGoogleAccount(ctx context.Context, req *database.LinkGoogleAccountRequest) error
+}
+
+// pendingAccountLink is a Google sign-in waiting for the user to confirm linking it to the
+// existing user with the same verified email. It is encrypted into the account_link cookie, as it
+// holds the Google tokens.
+type pendingAccountLink struct {
+	UserID       int       `json:"user_id"`
+	UserEmail    string    `json:"user_email"`
+	GoogleID     string    `json:"google_id"`
+	GoogleEmail  string    `json:"google_email"`
+	GoogleName   string    `json:"google_name"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenExpiry  time.Time `json:"token_expiry"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// AccountLinkResponse describes a pending account link for the confirmation prompt
+type AccountLinkResponse struct {
+	Email       string    `json:"email"`        // Email of the existing Academy Sync account
+	GoogleEmail string    `json:"google_email"` // Email of the Google account being linked
+	GoogleName  string    `json:"google_name"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SetAccountLinking lets a Google account that is new to Academy Sync, but whose verified email
+// belongs to an existing user, be linked to that user once they confirm. Without it such a
+// sign-in fails, as emails are unique. encryptor seals the pending link's tokens.
+func (h *AuthHandler) SetAccountLinking(linker AccountLinker, encryptor *auth.EncryptionService) {
+	h.linker = linker
+	h.linkEncryptor = encryptor
+}
+
+// offerAccountLink handles a first sign-in with a Google account whose verified email belongs to
+// an existing user: the sign-in is held in the account_link cookie and the user is sent to the
+// web app to confirm linking the accounts. It reports whether it wrote the response.
+func (h *AuthHandler) offerAccountLink(w http.ResponseWriter, r *http.Request, userInfo *auth.GoogleUserInfo, token *oauth2.Token) bool {
+	if h.linker == nil || !userInfo.VerifiedEmail {
+		return false
+	}
+
+	existing, err := h.linker.GetUserByEmail(r.Context(), userInfo.Email)
+	if err != nil {
+		h.logger.Error("Database error while matching user by email", "error", err, "google_user_id", userInfo.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Database error")
+		return true
+	}
+	if existing == nil {
+		return false
+	}
+	if existing.Suspended() {
+		h.logger.Warn("Account link refused for suspended account",
+			"user_id", existing.ID,
+			"google_user_id", userInfo.ID)
+		h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeAccountSuspended, accountSuspendedMessage)
+		return true
+	}
+
+	pending := pendingAccountLink{
+		UserID:       existing.ID,
+		UserEmail:    existing.Email,
+		GoogleID:     userInfo.ID,
+		GoogleEmail:  userInfo.Email,
+		GoogleName:   userInfo.Name,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenExpiry:  token.Expiry,
+		ExpiresAt:    time.Now().Add(accountLinkTTL),
+	}
+	sealed, err := h.sealAccountLink(pending)
+	if err != nil {
+		h.logger.Error("Failed to seal pending account link", "error", err, "user_id", existing.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to start account linking")
+		return true
+	}
+	h.cookiePolicy().setCookie(w, accountLinkCookie, sealed, accountLinkTTL)
+
+	linkURL := h.frontendURL + "/link-account"
+	h.logger.Info("Sign-in with a new Google account matching an existing user, asking to link",
+		"user_id", existing.ID,
+		"google_user_id", userInfo.ID,
+		"client_ip", middleware.GetClientIP(r))
+	http.Redirect(w, r, linkURL, http.StatusTemporaryRedirect)
+	return true
+}
+
+// GetAccountLink handles GET /api/v1/auth/link, describing the pending account link so the web
+// app can ask the user to confirm it
+func (h *AuthHandler) GetAccountLink(w http.ResponseWriter, r *http.Request) {
+	pending, ok := h.pendingAccountLink(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(AccountLinkResponse{
+		Email:       pending.UserEmail,
+		GoogleEmail: pending.GoogleEmail,
+		GoogleName:  pending.GoogleName,
+		ExpiresAt:   pending.ExpiresAt,
+	}); err != nil {
+		h.logger.Error("Failed to encode account link response", "error", err)
+	}
+}
+
+// ConfirmAccountLink handles POST /api/v1/auth/link: the Google account is linked to the existing
+// user, its tokens replace the user's (keeping the refresh token when Google sent none) and the
+// user is signed in
+func (h *AuthHandler) ConfirmAccountLink(w http.ResponseWriter, r *http.Request) {
+	pending, ok := h.pendingAccountLink(w, r)
+	if !ok {
+		return
+	}
+	h.cookiePolicy().clearCookie(w, accountLinkCookie)
+
+	tokenExpiry := pending.TokenExpiry
+	err := h.linker.LinkGoogleAccount(r.Context(), &database.LinkGoogleAccountRequest{
+		UserID:             pending.UserID,
+		GoogleID:           pending.GoogleID,
+		Email:              pending.GoogleEmail,
+		GoogleAccessToken:  pending.AccessToken,
+		GoogleRefreshToken: pending.RefreshToken,
+		GoogleTokenExpiry:  &tokenExpiry,
+	})
+	if errors.Is(err, database.ErrGoogleAccountLinked) {
+		h.logger.Warn("Google account already belongs to another user",
+			"user_id", pending.UserID,
+			"google_user_id", pending.GoogleID)
+		h.writeErrorResponse(w, http.StatusConflict, "ACCOUNT_ALREADY_LINKED", "This Google account already signs in to another Academy Sync account")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to link Google account", "error", err, "user_id", pending.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to link Google account")
+		return
+	}
+
+	user, err := h.accounts.GetUserByID(r.Context(), pending.UserID)
+	if err != nil || user == nil {
+		h.logger.Error("Failed to load linked user", "error", err, "user_id", pending.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to sign in")
+		return
+	}
+	if user.Suspended() {
+		h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeAccountSuspended, accountSuspendedMessage)
+		return
+	}
+	if err := h.createUserSession(w, r, user); err != nil {
+		h.logger.Error("Failed to create user session", "error", err, "user_id", user.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create session")
+		return
+	}
+
+	h.logger.Info("Google account linked",
+		"user_id", user.ID,
+		"google_user_id", pending.GoogleID,
+		"client_ip", middleware.GetClientIP(r))
+	h.writeRefreshResponse(w, "Google account linked")
+}
+
+// CancelAccountLink handles DELETE /api/v1/auth/link, discarding the pending account link
+func (h *AuthHandler) CancelAccountLink(w http.ResponseWriter, r *http.Request) {
+	h.cookiePolicy().clearCookie(w, accountLinkCookie)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pendingAccountLink opens the account_link cookie, writing 404 ACCOUNT_LINK_EXPIRED when there
+// is no unexpired pending link
+func (h *AuthHandler) pendingAccountLink(w http.ResponseWriter, r *http.Request) (*pendingAccountLink, bool) {
+	cookie, err := r.Cookie(accountLinkCookie)
+	if err != nil || h.linker == nil {
+		h.writeErrorResponse(w, http.StatusNotFound, ErrorCodeAccountLinkExpired, "No account link is pending; sign in again")
+		return nil, false
+	}
+
+	pending, err := h.openAccountLink(cookie.Value)
+	if err != nil || time.Now().After(pending.ExpiresAt) {
+		h.logger.Warn("Rejected pending account link",
+			"error", err,
+			"client_ip", middleware.GetClientIP(r))
+		h.cookiePolicy().clearCookie(w, accountLinkCookie)
+		h.writeErrorResponse(w, http.StatusNotFound, ErrorCodeAccountLinkExpired, "The account link has expired; sign in again")
+		return nil, false
+	}
+	return pending, true
+}
+
+func (h *AuthHandler) sealAccountLink(pending pendingAccountLink) (string, error) {
+	plaintext, err := json.Marshal(pending)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := h.linkEncryptor.Encrypt(string(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+func (h *AuthHandler) openAccountLink(value string) (*pendingAccountLink, error) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed account link cookie: %w", err)
+	}
+	plaintext, err := h.linkEncryptor.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var pending pendingAccountLink
+	if err := json.Unmarshal([]byte(plaintext), &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
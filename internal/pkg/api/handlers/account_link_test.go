@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockAccountLinker struct {
+	users  map[string]*database.User
+	linked []database.LinkGoogleAccountRequest
+	err    error
+}
+
+func (m *mockAccountLinker) GetUserByEmail(ctx context.Context, email string) (*database.User, error) {
+	return m.users[strings.ToLower(email)], nil
+}
+
+func (m *mockAccountLinker) LinkGoogleAccount(ctx context.Context, req *database.LinkGoogleAccountRequest) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.linked = append(m.linked, *req)
+	return nil
+}
+
+func TestAccountLinking(t *testing.T) {
+	linker := &mockAccountLinker{users: map[string]*database.User{
+		"runner@example.com": {ID: 7, Email: "Runner@example.com"},
+	}}
+	handler := &AuthHandler{logger: logger.New("test"), frontendURL: "https://app.example.com", isDevelopment: true}
+	handler.SetAccountLinking(linker, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	offer := func(email string) (*httptest.ResponseRecorder, bool) {
+		req := httptest.NewRequest(http.MethodGet, "/auth/google/callback", nil)
+		rr := httptest.NewRecorder()
+		handled := handler.offerAccountLink(rr, req, &auth.GoogleUserInfo{ID: "g-2", Email: email, VerifiedEmail: true, Name: "Runner"}, token)
+		return rr, handled
+	}
+
+	// A new email signs up as usual
+	if _, handled := offer("new@example.com"); handled {
+		t.Fatal("Expected an unknown email not to be offered a link")
+	}
+
+	rr, handled := offer("runner@example.com")
+	if !handled || rr.Code != http.StatusTemporaryRedirect || rr.Header().Get("Location") != "https://app.example.com/link-account" {
+		t.Fatalf("Expected a redirect to the link prompt, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+	var pending *http.Cookie
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == accountLinkCookie {
+			pending = cookie
+		}
+	}
+	if pending == nil || strings.Contains(pending.Value, "refresh") {
+		t.Fatalf("Expected an encrypted account link cookie, got %+v", pending)
+	}
+
+	call := func(method string, cookie *http.Cookie, handle http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/auth/link", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+		return rr
+	}
+
+	rr = call(http.MethodGet, pending, handler.GetAccountLink)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"email":"Runner@example.com"`) {
+		t.Errorf("Expected the pending link, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := call(http.MethodGet, nil, handler.GetAccountLink); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a pending link, got %d", rr.Code)
+	}
+	tampered := &http.Cookie{Name: accountLinkCookie, Value: pending.Value[:len(pending.Value)-4] + "AAAA"}
+	if rr := call(http.MethodGet, tampered, handler.GetAccountLink); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a tampered link, got %d", rr.Code)
+	}
+
+	// A Google account that meanwhile signed in to another user is not moved
+	linker.err = database.ErrGoogleAccountLinked
+	if rr := call(http.MethodPost, pending, handler.ConfirmAccountLink); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := call(http.MethodDelete, pending, handler.CancelAccountLink); rr.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", rr.Code)
+	}
+}
+
+func TestAccountLinking_SuspendedUser(t *testing.T) {
+	suspendedAt := time.Now()
+	linker := &mockAccountLinker{users: map[string]*database.User{
+		"runner@example.com": {ID: 7, Email: "runner@example.com", SuspendedAt: &suspendedAt},
+	}}
+	handler := &AuthHandler{logger: logger.New("test")}
+	handler.SetAccountLinking(linker, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/google/callback", nil)
+	rr := httptest.NewRecorder()
+	handled := handler.offerAccountLink(rr, req, &auth.GoogleUserInfo{ID: "g-2", Email: "runner@example.com", VerifiedEmail: true}, &oauth2.Token{})
+	if !handled || rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), ErrorCodeAccountSuspended) {
+		t.Errorf("Expected status 403 ACCOUNT_SUSPENDED, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
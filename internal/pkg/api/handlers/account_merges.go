@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// AccountMerger merges a duplicate user into another
+type AccountMerger interface {
+	MergeUsers(ctx context.Context, userID, duplicateID int) error
+}
+
+// AccountMergeHandler lets admins merge the duplicate accounts of a person who signed up twice
+type AccountMergeHandler struct {
+	merger     AccountMerger
+	authorizer authz.Authorizer
+	publisher  events.Publisher // Announces deleted duplicates; may be nil
+	logger     *logger.Logger
+}
+
+// NewAccountMergeHandler creates a new account merge handler
+func NewAccountMergeHandler(merger AccountMerger, authorizer authz.Authorizer, logger *logger.Logger) *AccountMergeHandler {
+	return &AccountMergeHandler{
+		merger:     merger,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "account_merge_handler"),
+	}
+}
+
+// SetEventPublisher publishes a UserDeleted event for every duplicate merged away
+func (h *AccountMergeHandler) SetEventPublisher(publisher events.Publisher) {
+	h.publisher = publisher
+}
+
+// MergeUserRequest names the duplicate account to merge
+type MergeUserRequest struct {
+	DuplicateID int `json:"duplicate_id"`
+}
+
+// Validate checks a duplicate is named
+func (req *MergeUserRequest) Validate(v *validate.Validator) {
+	v.Check(req.DuplicateID > 0, "duplicate_id", validate.CodeRequired, "duplicate_id is required")
+}
+
+// MergeUserResponse reports a completed merge
+type MergeUserResponse struct {
+	UserID      int `json:"user_id"`
+	DuplicateID int `json:"duplicate_id"`
+}
+
+// Merge handles POST /api/v1/admin/users/{id}/merge with {"duplicate_id"}. The duplicate's Google
+// accounts then sign in to the user, its history moves over and it is deleted.
+func (h *AccountMergeHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Account merge called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || userID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid user ID is required")
+		return
+	}
+
+	var req MergeUserRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+	if req.DuplicateID == userID {
+		h.writeErrorResponse(w, http.StatusBadRequest, "CANNOT_MERGE_SELF", "An account cannot be merged into itself")
+		return
+	}
+
+	for _, id := range []int{userID, req.DuplicateID} {
+		if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.UserAccount(id)); err != nil {
+			h.logger.Warn("Account merge denied by authorization policy",
+				"error", err,
+				"user_id", subject.UserID,
+				"target_user_id", id)
+			h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may merge accounts")
+			return
+		}
+	}
+
+	if err := h.merger.MergeUsers(r.Context(), userID, req.DuplicateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+			return
+		}
+		h.logger.Error("Failed to merge accounts",
+			"error", err,
+			"user_id", subject.UserID,
+			"target_user_id", userID,
+			"duplicate_user_id", req.DuplicateID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to merge accounts")
+		return
+	}
+
+	h.logger.Warn("Duplicate account merged",
+		"user_id", subject.UserID,
+		"target_user_id", userID,
+		"duplicate_user_id", req.DuplicateID)
+	if h.publisher != nil {
+		if err := h.publisher.Publish(r.Context(), events.UserDeleted{UserID: req.DuplicateID, MergedInto: userID}); err != nil {
+			h.logger.Warn("Failed to publish user deletion event",
+				"error", err,
+				"duplicate_user_id", req.DuplicateID)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(MergeUserResponse{UserID: userID, DuplicateID: req.DuplicateID}); err != nil {
+		h.logger.Error("Failed to encode merge response", "error", err)
+	}
+}
+
+func (h *AccountMergeHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
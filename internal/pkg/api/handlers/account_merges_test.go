@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockAccountMerger struct {
+	users  map[int]bool
+	merged [][2]int
+}
+
+func (m *mockAccountMerger) MergeUsers(ctx context.Context, userID, duplicateID int) error {
+	if !m.users[userID] || !m.users[duplicateID] {
+		return sql.ErrNoRows
+	}
+	m.merged = append(m.merged, [2]int{userID, duplicateID})
+	delete(m.users, duplicateID)
+	return nil
+}
+
+// recordingPublisher records the events published by a handler
+type recordingPublisher struct {
+	published []events.Payload
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, payload events.Payload) error {
+	p.published = append(p.published, payload)
+	return nil
+}
+
+func TestAccountMergeHandler(t *testing.T) {
+	merger := &mockAccountMerger{users: map[int]bool{1: true, 7: true, 8: true}}
+	handler := NewAccountMergeHandler(merger, authz.DefaultPolicy(), logger.New("test"))
+	publisher := &recordingPublisher{}
+	handler.SetEventPublisher(publisher)
+
+	router := chi.NewRouter()
+	router.Post("/api/admin/users/{id}/merge", handler.Merge)
+
+	call := func(target, body string, userID int, roles ...authz.Role) *httptest.ResponseRecorder {
+		req := authenticatedRequest(http.MethodPost, target, body, userID)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, roles))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := call("/api/admin/users/7/merge", `{}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a duplicate, got %d", rr.Code)
+	}
+	if rr := call("/api/admin/users/7/merge", `{"duplicate_id": 7}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 merging an account into itself, got %d", rr.Code)
+	}
+	if rr := call("/api/admin/users/7/merge", `{"duplicate_id": 8}`, 4, authz.RoleAthlete, authz.RoleSupport); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for support staff, got %d", rr.Code)
+	}
+	if rr := call("/api/admin/users/7/merge", `{"duplicate_id": 9}`, 1, authz.RoleAdmin); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown duplicate, got %d", rr.Code)
+	}
+	if rr := call("/api/admin/users/7/merge", `{"duplicate_id": 8}`, 1, authz.RoleAdmin); rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(merger.merged) != 1 || merger.merged[0] != [2]int{7, 8} {
+		t.Errorf("Expected user 8 to be merged into 7, got %v", merger.merged)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != (events.UserDeleted{UserID: 8, MergedInto: 7}) {
+		t.Errorf("Expected one deletion event for user 8, got %+v", publisher.published)
+	}
+}
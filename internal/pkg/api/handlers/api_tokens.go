@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+const (
+	// maxAPITokenNameLength matches the api_tokens.name column
+	maxAPITokenNameLength = 100
+	// maxAPITokenLifetimeDays bounds expires_in_days; tokens may also be created without expiry
+	maxAPITokenLifetimeDays = 365
+)
+
+// APITokenStore creates, lists and revokes personal access tokens
+type APITokenStore interface {
+	CreateAPIToken(ctx context.Context, token *database.APIToken) error
+	ListAPITokens(ctx context.Context, userID int) ([]database.APIToken, error)
+	RevokeAPIToken(ctx context.Context, userID, id int) error
+}
+
+// APITokenHandler lets users manage the personal access tokens their scripts authenticate with
+type APITokenHandler struct {
+	store      APITokenStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(store APITokenStore, authorizer authz.Authorizer, logger *logger.Logger) *APITokenHandler {
+	return &APITokenHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "api_token_handler"),
+	}
+}
+
+// CreateAPITokenRequest creates a personal access token
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 creates a token that never expires
+}
+
+// Validate checks the token is named and granted at least one known scope
+func (req *CreateAPITokenRequest) Validate(v *validate.Validator) {
+	req.Name = strings.TrimSpace(req.Name)
+	if v.Check(req.Name != "", "name", validate.CodeRequired, "name is required") {
+		v.Check(len(req.Name) <= maxAPITokenNameLength, "name", validate.CodeInvalid, "name must be at most 100 characters")
+	}
+
+	if v.Check(len(req.Scopes) > 0, "scopes", validate.CodeRequired, "At least one scope is required") {
+		for _, scope := range req.Scopes {
+			if !validAPITokenScope(scope) {
+				v.Add("scopes", validate.CodeInvalid, "Unknown scope "+strconv.Quote(scope)+"; use read, sync or export")
+				break
+			}
+		}
+	}
+
+	v.Check(req.ExpiresInDays >= 0 && req.ExpiresInDays <= maxAPITokenLifetimeDays,
+		"expires_in_days", validate.CodeInvalid, "expires_in_days must be between 0 and 365")
+}
+
+func validAPITokenScope(scope string) bool {
+	for _, s := range authz.Scopes {
+		if string(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPITokenResponse returns a new token; the plaintext token is never shown again
+type CreateAPITokenResponse struct {
+	database.APIToken
+	Token string `json:"token"`
+}
+
+// APITokensResponse lists the user's tokens
+type APITokensResponse struct {
+	Tokens []database.APIToken `json:"tokens"`
+}
+
+// Create handles POST /api/v1/auth/tokens with {"name", "scopes", "expires_in_days"}
+func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	plaintext, prefix, hash, err := auth.NewAPIToken()
+	if err != nil {
+		h.logger.Error("Failed to generate API token",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create API token")
+		return
+	}
+
+	token := &database.APIToken{
+		UserID:      subject.UserID,
+		Name:        req.Name,
+		TokenHash:   hash,
+		TokenPrefix: prefix,
+		Scopes:      dedupeScopes(req.Scopes),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := h.store.CreateAPIToken(r.Context(), token); err != nil {
+		h.logger.Error("Failed to store API token",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create API token")
+		return
+	}
+
+	h.logger.Info("API token created",
+		"user_id", subject.UserID,
+		"api_token_id", token.ID,
+		"token_prefix", token.TokenPrefix,
+		"scopes", token.Scopes,
+		"expires_at", token.ExpiresAt)
+	h.writeJSON(w, http.StatusCreated, CreateAPITokenResponse{APIToken: *token, Token: plaintext})
+}
+
+// List handles GET /api/v1/auth/tokens, returning the user's tokens without their secrets
+func (h *APITokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.store.ListAPITokens(r.Context(), subject.UserID)
+	if err != nil {
+		h.logger.Error("Failed to list API tokens",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list API tokens")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, APITokensResponse{Tokens: tokens})
+}
+
+// Delete handles DELETE /api/v1/auth/tokens/{id}; the token stops working immediately
+func (h *APITokenHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid API token ID is required")
+		return
+	}
+
+	if err := h.store.RevokeAPIToken(r.Context(), subject.UserID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "API token not found")
+			return
+		}
+		h.logger.Error("Failed to revoke API token",
+			"error", err,
+			"user_id", subject.UserID,
+			"api_token_id", id)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke API token")
+		return
+	}
+
+	h.logger.Info("API token revoked",
+		"user_id", subject.UserID,
+		"api_token_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize checks that the caller may manage their own tokens, writing the error response if
+// not. Requests authenticated with an API token are refused, so a token cannot mint others.
+func (h *APITokenHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("API tokens called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.APITokens(subject.UserID)); err != nil {
+		h.logger.Warn("API tokens denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "API tokens can only be managed from a signed-in session")
+		return subject, false
+	}
+	return subject, true
+}
+
+// dedupeScopes drops repeated scopes, keeping their first-mentioned order
+func dedupeScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
+
+func (h *APITokenHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode API token response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *APITokenHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
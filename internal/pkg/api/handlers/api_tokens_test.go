@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockAPITokenStore struct {
+	tokens []database.APIToken
+}
+
+func (m *mockAPITokenStore) CreateAPIToken(ctx context.Context, token *database.APIToken) error {
+	token.ID = len(m.tokens) + 1
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *mockAPITokenStore) ListAPITokens(ctx context.Context, userID int) ([]database.APIToken, error) {
+	tokens := []database.APIToken{}
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *mockAPITokenStore) RevokeAPIToken(ctx context.Context, userID, id int) error {
+	for i, token := range m.tokens {
+		if token.ID == id && token.UserID == userID && token.RevokedAt == nil {
+			now := m.tokens[i].CreatedAt
+			m.tokens[i].RevokedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func TestAPITokenHandler(t *testing.T) {
+	store := &mockAPITokenStore{}
+	handler := NewAPITokenHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/auth/tokens", handler.List)
+	router.Post("/api/auth/tokens", handler.Create)
+	router.Delete("/api/auth/tokens/{id}", handler.Delete)
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := serve(authenticatedRequest(http.MethodPost, "/api/auth/tokens", `{"name": "cron", "scopes": ["admin"]}`, 1)); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown scope, got %d", rr.Code)
+	}
+	if rr := serve(authenticatedRequest(http.MethodPost, "/api/auth/tokens", `{"scopes": ["sync"]}`, 1)); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing name, got %d", rr.Code)
+	}
+
+	rr := serve(authenticatedRequest(http.MethodPost, "/api/auth/tokens", `{"name": "cron", "scopes": ["sync", "read", "sync"], "expires_in_days": 30}`, 1))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created CreateAPITokenResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !auth.IsAPIToken(created.Token) || created.ExpiresAt == nil || len(created.Scopes) != 2 {
+		t.Errorf("Unexpected token: %+v", created)
+	}
+	// Only the hash of the token is stored
+	if store.tokens[0].TokenHash != auth.HashAPIToken(created.Token) || store.tokens[0].UserID != 1 {
+		t.Errorf("Expected the token hash to be stored for its owner: %+v", store.tokens[0])
+	}
+
+	rr = serve(authenticatedRequest(http.MethodGet, "/api/auth/tokens", "", 1))
+	var listed APITokensResponse
+	if err := json.NewDecoder(rr.Body).Decode(&listed); err != nil || len(listed.Tokens) != 1 {
+		t.Fatalf("Expected one token, got %d (%v)", rr.Code, err)
+	}
+
+	// A request authenticated with an API token may not manage tokens
+	tokenReq := authenticatedRequest(http.MethodPost, "/api/auth/tokens", `{"name": "minted", "scopes": ["read"]}`, 1)
+	tokenReq = tokenReq.WithContext(context.WithValue(tokenReq.Context(), middleware.ScopesKey, []authz.Scope{authz.ScopeRead, authz.ScopeSync}))
+	if rr := serve(tokenReq); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a token-authenticated request, got %d", rr.Code)
+	}
+
+	remove := func(id string, userID int) int {
+		return serve(authenticatedRequest(http.MethodDelete, "/api/auth/tokens/"+id, "", userID)).Code
+	}
+	if code := remove("1", 2); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's token, got %d", code)
+	}
+	if code := remove("1", 1); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if code := remove("1", 1); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a revoked token, got %d", code)
+	}
+}
@@ -5,13 +5,16 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 )
@@ -21,12 +24,63 @@ type AuthHandler struct {
 	oauthService      *auth.OAuthService
 	jwtService        *auth.JWTService
 	userRepository    *database.UserRepository
-	sessionRepository *database.SessionRepository
+	sessionRepository SessionStore
+	// Looks up the user a refreshed session belongs to
+	accounts          accountLookup
 	frontendURL       string
 	isDevelopment     bool
+	// Configured cookie policy (see SetCookiePolicy); nil uses DefaultCookiePolicy
+	cookies           *CookiePolicy
+	// Who may sign up (see SetSignupPolicy); the zero value lets anyone sign up
+	signup            SignupPolicy
+	// Sign-in audit log (see SetSignInAudit); nil records nothing
+	signIns           SignInAudit
+	// Links a new Google account to the user with its email (see SetAccountLinking)
+	linker            AccountLinker
+	linkEncryptor     *auth.EncryptionService
+	// Last run, next run and re-authorization flags for GetCurrentUser (see SetAutomationStatus)
+	automation        AutomationStatusStore
 	logger            *logger.Logger
 }
 
+// AutomationStatusStore reports where a user's automation stands (implemented by *database.UserRepository)
+type AutomationStatusStore interface {
+	GetAutomationStatus(ctx context.Context, userID int) (*database.AutomationStatus, error)
+}
+
+// SetAutomationStatus makes GetCurrentUser report the user's last run, next scheduled run and
+// whether a provider needs re-authorization
+func (h *AuthHandler) SetAutomationStatus(store AutomationStatusStore) {
+	h.automation = store
+}
+
+// SessionStore persists user sessions and their refresh tokens (implemented by *database.SessionRepository)
+type SessionStore interface {
+	CreateSession(ctx context.Context, req *database.CreateSessionRequest) (*database.UserSession, error)
+	GetSessionByID(ctx context.Context, sessionID int) (*database.UserSession, error)
+	UpdateSessionToken(ctx context.Context, sessionID int, newToken string) error
+	DeactivateSession(ctx context.Context, sessionID int) error
+	SetRefreshTokenHash(ctx context.Context, sessionID int, hash string) (bool, error)
+	GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*database.UserSession, error)
+	RotateRefreshToken(ctx context.Context, sessionID int, currentHash, newHash string) (bool, error)
+	UpdateSessionLastUsed(ctx context.Context, sessionID int) (time.Time, error)
+}
+
+// accountLookup loads users by ID (implemented by *database.UserRepository)
+type accountLookup interface {
+	GetUserByID(ctx context.Context, id int) (*database.User, error)
+}
+
+// Cookie names of the short-lived access token and the rotating refresh token
+const (
+	accessTokenCookie  = "session_token"
+	refreshTokenCookie = "refresh_token"
+)
+
+// refreshReuseGrace is how long after a rotation the replaced refresh token is refused without
+// revoking the session, as two tabs refreshing at once present the same token
+const refreshReuseGrace = 10 * time.Second
+
 // NewAuthHandler creates a new authentication handler
 func NewAuthHandler(
 	oauthService *auth.OAuthService,
@@ -42,21 +96,32 @@ func NewAuthHandler(
 		jwtService:        jwtService,
 		userRepository:    userRepository,
 		sessionRepository: sessionRepository,
+		accounts:          userRepository,
 		frontendURL:       frontendURL,
 		isDevelopment:     isDevelopment,
 		logger:            logger,
 	}
 }
 
-// getCookieConfig returns appropriate cookie configuration for the environment
+// SetCookiePolicy replaces the environment's default cookie attributes and session lifetimes
+func (h *AuthHandler) SetCookiePolicy(policy CookiePolicy) {
+	h.cookies = &policy
+}
+
+// SetSignupPolicy restricts which Google accounts may create an account on first sign-in
+func (h *AuthHandler) SetSignupPolicy(policy SignupPolicy) {
+	h.signup = policy
+}
+
+// cookiePolicy returns the cookie policy in effect
+func (h *AuthHandler) cookiePolicy() CookiePolicy {
+	return cookiePolicyOrDefault(h.cookies, h.isDevelopment)
+}
+
+// getCookieConfig returns the cookie attributes in effect
 func (h *AuthHandler) getCookieConfig() (domain string, sameSite http.SameSite, secure bool) {
-	if h.isDevelopment {
-		// Development: Use .localhost domain to share cookies across ports
-		// SameSite=Lax allows cookies in top-level navigation (OAuth redirects)
-		return ".localhost", http.SameSiteLaxMode, false
-	}
-	// Production: Use secure settings
-	return "", http.SameSiteLaxMode, true
+	policy := h.cookiePolicy()
+	return policy.Domain, policy.SameSite, policy.Secure
 }
 
 // generateSecureState generates a cryptographically secure random state for OAuth CSRF protection
@@ -82,29 +147,25 @@ func (h *AuthHandler) GoogleAuthURL(w http.ResponseWriter, r *http.Request) {
 	state, err := generateSecureState()
 	if err != nil {
 		h.logger.Error("Failed to generate secure OAuth state", "error", err)
-		http.Error(w, "Failed to generate secure state", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to generate secure state")
 		return
 	}
 	
 	// Store state in session cookie for validation
-	domain, sameSite, secure := h.getCookieConfig()
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		Path:     "/",
-		Domain:   domain,
-		MaxAge:   300, // 5 minutes
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
-	})
+	cookies := h.cookiePolicy()
+	cookies.setCookie(w, "oauth_state", state, 5*time.Minute)
+
+	// An invite code (?invite=) is carried through the OAuth redirect for invite-only signup
+	if invite := r.URL.Query().Get("invite"); invite != "" {
+		cookies.setCookie(w, signupInviteCookie, invite, 5*time.Minute)
+	}
 
 	authURL := h.oauthService.GetAuthURL(state)
 	
 	h.logger.Debug("Generated Google OAuth URL", 
 		"state_length", len(state),
-		"cookie_domain", domain,
-		"cookie_secure", secure)
+		"cookie_domain", cookies.Domain,
+		"cookie_secure", cookies.Secure)
 
 	response := map[string]string{
 		"auth_url": authURL,
@@ -113,7 +174,7 @@ func (h *AuthHandler) GoogleAuthURL(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		h.logger.Error("Failed to encode OAuth URL response", "error", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode response")
 		return
 	}
 	
@@ -131,7 +192,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	stateParam := r.URL.Query().Get("state")
 	if stateParam == "" {
 		h.logger.Warn("OAuth callback missing state parameter", "client_ip", clientIP)
-		http.Error(w, "Missing state parameter", http.StatusBadRequest)
+		h.writeErrorResponse(w, http.StatusBadRequest, "MISSING_STATE", "Missing state parameter")
 		return
 	}
 
@@ -142,7 +203,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 		// In production, state cookie is required for CSRF protection
 		if !h.isDevelopment {
 			h.logger.Warn("OAuth callback missing state cookie in production", "client_ip", clientIP)
-			http.Error(w, "Missing state cookie - CSRF protection required", http.StatusBadRequest)
+			h.writeErrorResponse(w, http.StatusBadRequest, "MISSING_STATE_COOKIE", "Missing state cookie - CSRF protection required")
 			return
 		}
 		// In development, allow fallback validation for direct callback testing
@@ -150,7 +211,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 			h.logger.Warn("OAuth callback invalid state parameter format in development", 
 				"state_length", len(stateParam),
 				"has_oauth_prefix", false)
-			http.Error(w, "Invalid state parameter format", http.StatusBadRequest)
+			h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_STATE", "Invalid state parameter format")
 			return
 		}
 		h.logger.Debug("Using fallback state validation in development")
@@ -162,7 +223,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 				"cookie_state_length", len(stateCookie.Value),
 				"param_state_length", len(stateParam),
 				"states_match", false)
-			http.Error(w, "Invalid state parameter - CSRF protection failed", http.StatusBadRequest)
+			h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_STATE", "Invalid state parameter - CSRF protection failed")
 			return
 		}
 		h.logger.Debug("OAuth state validation successful")
@@ -170,24 +231,14 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 
 	// Clear the state cookie if it exists
 	if cookieErr == nil {
-		domain, sameSite, secure := h.getCookieConfig()
-		http.SetCookie(w, &http.Cookie{
-			Name:     "oauth_state",
-			Value:    "",
-			Path:     "/",
-			Domain:   domain,
-			MaxAge:   -1,
-			HttpOnly: true,
-			Secure:   secure,
-			SameSite: sameSite,
-		})
+		h.cookiePolicy().clearCookie(w, "oauth_state")
 	}
 
 	// Get authorization code
 	code := r.URL.Query().Get("code")
 	if code == "" {
 		h.logger.Warn("OAuth callback missing authorization code", "client_ip", clientIP)
-		http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		h.writeErrorResponse(w, http.StatusBadRequest, "MISSING_CODE", "Missing authorization code")
 		return
 	}
 	
@@ -197,7 +248,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	token, err := h.oauthService.ExchangeCodeForToken(context.Background(), code)
 	if err != nil {
 		h.logger.Error("Failed to exchange OAuth code for token", "error", err, "client_ip", clientIP)
-		http.Error(w, "Failed to exchange code for token", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "TOKEN_EXCHANGE_FAILED", "Failed to exchange code for token")
 		return
 	}
 	
@@ -207,7 +258,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	userInfo, err := h.oauthService.GetUserInfo(context.Background(), token)
 	if err != nil {
 		h.logger.Error("Failed to get user info from Google", "error", err, "client_ip", clientIP)
-		http.Error(w, "Failed to get user info", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "USER_INFO_FAILED", "Failed to get user info")
 		return
 	}
 	
@@ -217,12 +268,20 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	existingUser, err := h.userRepository.GetUserByGoogleID(r.Context(), userInfo.ID)
 	if err != nil {
 		h.logger.Error("Database error while checking existing user", "error", err, "google_user_id", userInfo.ID)
-		http.Error(w, "Database error", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Database error")
 		return
 	}
 
 	var user *database.User
 
+	if existingUser != nil && existingUser.Suspended() {
+		h.logger.Warn("Sign-in refused for suspended account",
+			"user_id", existingUser.ID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeAccountSuspended, accountSuspendedMessage)
+		return
+	}
+
 	if existingUser != nil {
 		h.logger.Info("Existing user login", "user_id", existingUser.ID)
 		// Update existing user's tokens and last login atomically
@@ -237,11 +296,32 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 
 		if err := h.userRepository.UpdateUserTokens(r.Context(), updateReq); err != nil {
 			h.logger.Error("Failed to update existing user tokens", "error", err, "user_id", user.ID)
-			http.Error(w, "Failed to update user tokens", http.StatusInternalServerError)
+			h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update user tokens")
 			return
 		}
 		h.logger.Debug("Updated existing user tokens successfully", "user_id", user.ID)
 	} else {
+		// A new Google account whose verified email belongs to a user is linked, not signed up
+		if h.offerAccountLink(w, r, userInfo, token) {
+			return
+		}
+
+		var inviteCode string
+		if cookie, err := r.Cookie(signupInviteCookie); err == nil {
+			inviteCode = cookie.Value
+			h.cookiePolicy().clearCookie(w, signupInviteCookie)
+		}
+		inviteHash, refusal := h.signup.admit(userInfo.Email, userInfo.VerifiedEmail, inviteCode)
+		if refusal != nil {
+			h.logger.Warn("Sign-up refused by signup policy",
+				"google_user_id", userInfo.ID,
+				"signup_policy", h.signup.Mode,
+				"reason", refusal.code,
+				"client_ip", clientIP)
+			h.writeErrorResponse(w, http.StatusForbidden, refusal.code, refusal.message)
+			return
+		}
+
 		h.logger.Info("Creating new user", "google_user_id", userInfo.ID)
 		// Create new user
 		createReq := &database.CreateUserRequest{
@@ -252,21 +332,36 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 			GoogleAccessToken:  token.AccessToken,
 			GoogleRefreshToken: token.RefreshToken,
 			GoogleTokenExpiry:  &token.Expiry,
+			InviteCodeHash:     inviteHash,
 		}
 
 		user, err = h.userRepository.CreateUser(r.Context(), createReq)
+		if errors.Is(err, database.ErrInvalidInviteCode) {
+			h.logger.Warn("Sign-up refused: invite code invalid or already used",
+				"google_user_id", userInfo.ID,
+				"client_ip", clientIP)
+			h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeSignupInviteInvalid, "The invite code is invalid, expired or has already been used")
+			return
+		}
 		if err != nil {
 			h.logger.Error("Failed to create new user", "error", err, "google_user_id", userInfo.ID)
-			http.Error(w, "Failed to create user", http.StatusInternalServerError)
+			h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create user")
 			return
 		}
 		h.logger.Info("Created new user successfully", "user_id", user.ID)
 	}
 
+	// Google returns the granted scopes with the tokens
+	if rawScopes, ok := token.Extra("scope").(string); ok {
+		if err := h.userRepository.UpdateGrantedScopes(r.Context(), user.ID, database.ProviderGoogleSheets, splitScopes(rawScopes, " ")); err != nil {
+			h.logger.Warn("Failed to record granted Google scopes", "error", err, "user_id", user.ID)
+		}
+	}
+
 	// Create session
 	if err := h.createUserSession(w, r, user); err != nil {
 		h.logger.Error("Failed to create user session", "error", err, "user_id", user.ID)
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create session")
 		return
 	}
 
@@ -285,12 +380,14 @@ func (h *AuthHandler) createUserSession(w http.ResponseWriter, r *http.Request,
 	userAgent := r.Header.Get("User-Agent")
 	ipAddress := middleware.GetClientIP(r)
 	
+	cookies := h.cookiePolicy()
+
 	// Create session record first to get the actual session ID
 	sessionReq := &database.CreateSessionRequest{
 		UserID:    user.ID,
 		UserAgent: &userAgent,
 		IPAddress: &ipAddress,
-		ExpiresAt: time.Now().Add(24 * time.Hour), // 24 hour session
+		ExpiresAt: time.Now().Add(cookies.SessionTTL),
 		// SessionToken will be set after generation
 	}
 
@@ -300,7 +397,7 @@ func (h *AuthHandler) createUserSession(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Generate JWT token once with the actual session ID
-	jwtToken, err := h.jwtService.GenerateToken(user.ID, user.Email, user.GoogleID, session.ID)
+	jwtToken, err := h.jwtService.GenerateTokenWithRole(user.ID, user.Email, user.GoogleID, session.ID, user.Role)
 	if err != nil {
 		return err
 	}
@@ -311,23 +408,28 @@ func (h *AuthHandler) createUserSession(w http.ResponseWriter, r *http.Request,
 		return err
 	}
 
-	// Set JWT as HttpOnly cookie
-	domain, sameSite, secure := h.getCookieConfig()
-	cookie := &http.Cookie{
-		Name:     "session_token",
-		Value:    jwtToken,
-		Path:     "/",
-		Domain:   domain,
-		MaxAge:   int((24 * time.Hour).Seconds()), // 24 hours
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
+	// Only the refresh token's hash is stored; the token itself lives in an HttpOnly cookie
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return err
+	}
+	if _, err := h.sessionRepository.SetRefreshTokenHash(r.Context(), session.ID, refreshHash); err != nil {
+		return err
 	}
 
-	http.SetCookie(w, cookie)
+	h.recordSignIn(r.Context(), session)
+
+	h.setSessionCookies(w, cookies, jwtToken, refreshToken, session.ExpiresAt)
 	return nil
 }
 
+// setSessionCookies sets the access token cookie, which lasts as long as the token, and the
+// refresh token cookie, which lasts until the session expires
+func (h *AuthHandler) setSessionCookies(w http.ResponseWriter, cookies CookiePolicy, accessToken, refreshToken string, sessionExpiresAt time.Time) {
+	cookies.setCookie(w, accessTokenCookie, accessToken, cookies.AccessTokenTTL)
+	cookies.setCookie(w, refreshTokenCookie, refreshToken, time.Until(sessionExpiresAt))
+}
+
 // GetCurrentUser returns the current authenticated user's information
 func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	sessionID, hasSession := middleware.GetSessionIDFromContext(r.Context())
@@ -345,7 +447,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		h.logger.Warn("GetCurrentUser called without valid user context", 
 			"client_ip", clientIP)
-		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not found in context")
 		return
 	}
 
@@ -355,7 +457,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 			"error", err, 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to get user", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get user")
 		return
 	}
 
@@ -363,7 +465,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		h.logger.Warn("User not found in database", 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "User not found", http.StatusNotFound)
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
 		return
 	}
 
@@ -375,6 +477,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		PublicUser:         publicUser,
 		RecentActivityLogs: []database.ActivityLog{}, // Empty for now, will be populated in future stories
 	}
+	h.addAutomationStatus(r.Context(), dashboardResponse, user)
 	
 	h.logger.Debug("Returning user information", 
 		"user_id", user.ID,
@@ -389,7 +492,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 			"error", err, 
 			"user_id", user.ID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to encode user data", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode user data")
 		return
 	}
 	
@@ -397,6 +500,32 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		"user_id", user.ID)
 }
 
+// addAutomationStatus fills in the dashboard's last run, next scheduled run and re-authorization
+// flags. They are left out, rather than failing the request, when they cannot be read.
+func (h *AuthHandler) addAutomationStatus(ctx context.Context, response *database.DashboardUserResponse, user *database.User) {
+	if h.automation == nil {
+		return
+	}
+
+	status, err := h.automation.GetAutomationStatus(ctx, user.ID)
+	if err != nil {
+		h.logger.Warn("Failed to read automation status",
+			"error", err,
+			"user_id", user.ID)
+		return
+	}
+
+	response.LastRun = status.LastRun
+	response.StravaReauthRequired = status.StravaReauthRequired
+	response.GoogleReauthRequired = status.GoogleReauthRequired
+
+	// Only users the scheduler picks up have a next run
+	if user.AutomationEnabled && !user.Suspended() && len(user.StravaRefreshToken) > 0 && len(user.GoogleRefreshToken) > 0 && response.HasSheetsConnection {
+		next := automation.NextRunAt(status.NextRunAt, user.Timezone, time.Now())
+		response.NextScheduledRunAt = &next
+	}
+}
+
 // Logout handles user logout by invalidating the session
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	userID, hasUserID := middleware.GetUserIDFromContext(r.Context())
@@ -423,62 +552,218 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Clear session cookie
-	domain, sameSite, secure := h.getCookieConfig()
-	cookie := &http.Cookie{
-		Name:     "session_token",
-		Value:    "",
-		Path:     "/",
-		Domain:   domain,
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
-	}
-
-	http.SetCookie(w, cookie)
+	// Clear session cookies
+	cookies := h.cookiePolicy()
+	cookies.clearCookie(w, accessTokenCookie)
+	cookies.clearCookie(w, refreshTokenCookie)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{
 		"message": "Logged out successfully",
 	}); err != nil {
-		http.Error(w, "Failed to encode logout response", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode logout response")
 		return
 	}
 }
 
-// RefreshToken refreshes the user's JWT token
+// RefreshToken exchanges the refresh token cookie for a new access token and a new refresh token.
+// Each refresh token works once: presenting a replaced one means it was copied, so the session is
+// revoked. Sessions created before refresh tokens are upgraded with their still-valid access token.
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	clientIP := middleware.GetClientIP(r)
 	h.logger.Debug("RefreshToken request initiated", 
 		"client_ip", clientIP,
 		"user_agent", r.Header.Get("User-Agent"))
 	
-	// Get current JWT token from cookie
-	cookie, err := r.Cookie("session_token")
+	cookies := h.cookiePolicy()
+	
+	// Access tokens are only replaced close to expiry so frequent refreshes do not churn sessions
+	if claims, ok := h.freshAccessToken(r, cookies); ok {
+		h.logger.Debug("Access token not yet within refresh threshold, keeping it",
+			"user_id", claims.UserID,
+			"session_id", claims.SessionID,
+			"expires_at", claims.ExpiresAt.Time)
+		h.writeRefreshResponse(w, "Token still valid")
+		return
+	}
+	
+	refreshCookie, err := r.Cookie(refreshTokenCookie)
 	if err != nil {
-		h.logger.Warn("RefreshToken request missing session token cookie", 
-			"cookie_error", err.Error(),
+		h.upgradeLegacySession(w, r, cookies)
+		return
+	}
+	
+	presentedHash := auth.HashRefreshToken(refreshCookie.Value)
+	session, err := h.sessionRepository.GetSessionByRefreshTokenHash(r.Context(), presentedHash)
+	if err != nil {
+		h.logger.Error("Failed to look up session by refresh token", 
+			"error", err,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to refresh token")
+		return
+	}
+	if session == nil {
+		h.logger.Warn("RefreshToken request with unknown refresh token", "client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid refresh token")
+		return
+	}
+	
+	if session.RefreshTokenHash == nil || *session.RefreshTokenHash != presentedHash {
+		// The token was already rotated: a concurrent refresh from another tab, or a stolen copy
+		if session.RefreshRotatedAt != nil && time.Since(*session.RefreshRotatedAt) < refreshReuseGrace {
+			h.logger.Debug("Refresh token rotated moments ago by a concurrent refresh",
+				"user_id", session.UserID,
+				"session_id", session.ID)
+			h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Refresh token already used")
+			return
+		}
+		
+		h.logger.Warn("Refresh token reuse detected - revoking session", 
+			"user_id", session.UserID,
+			"session_id", session.ID,
+			"client_ip", clientIP)
+		if err := h.sessionRepository.DeactivateSession(r.Context(), session.ID); err != nil {
+			h.logger.Error("Failed to revoke session after refresh token reuse", 
+				"error", err,
+				"user_id", session.UserID,
+				"session_id", session.ID)
+		}
+		cookies.clearCookie(w, accessTokenCookie)
+		cookies.clearCookie(w, refreshTokenCookie)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", "Refresh token was already used; the session has been revoked")
+		return
+	}
+	
+	if !session.IsActive || !session.ExpiresAt.After(time.Now()) {
+		h.logger.Warn("RefreshToken request for inactive or expired session", 
+			"session_id", session.ID,
+			"user_id", session.UserID,
+			"session_active", session.IsActive,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Session revoked or inactive")
+		return
+	}
+	
+	user, err := h.accounts.GetUserByID(r.Context(), session.UserID)
+	if err != nil {
+		h.logger.Error("Failed to read account status, refusing the refresh", 
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Account status is temporarily unavailable, please try again later")
+		return
+	}
+	if user == nil {
+		h.logger.Warn("RefreshToken request for a session without a user", 
+			"session_id", session.ID,
+			"user_id", session.UserID)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Session revoked or inactive")
+		return
+	}
+	if user.Suspended() {
+		h.refuseSuspended(w, cookies, user.ID, session.ID)
+		return
+	}
+	
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		h.logger.Error("Failed to generate refresh token", "error", err, "session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to refresh token")
+		return
+	}
+	rotated, err := h.sessionRepository.RotateRefreshToken(r.Context(), session.ID, presentedHash, refreshHash)
+	if err != nil {
+		h.logger.Error("Failed to rotate refresh token", 
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update session")
+		return
+	}
+	if !rotated {
+		// A concurrent refresh rotated the token between the lookup and the update
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Refresh token already used")
+		return
+	}
+	
+	accessToken, err := h.jwtService.GenerateTokenWithRole(user.ID, user.Email, user.GoogleID, session.ID, user.Role)
+	if err != nil {
+		h.logger.Error("Failed to generate access token during refresh", 
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to refresh token")
+		return
+	}
+	if err := h.sessionRepository.UpdateSessionToken(r.Context(), session.ID, accessToken); err != nil {
+		h.logger.Error("Failed to update session token in database", 
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID,
 			"client_ip", clientIP)
-		http.Error(w, "No session token", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update session")
 		return
 	}
+	
+	// A refresh is a use of the session: a sliding session is extended and the refresh token
+	// cookie follows its new expiry
+	expiresAt := session.ExpiresAt
+	if extended, err := h.sessionRepository.UpdateSessionLastUsed(r.Context(), session.ID); err != nil {
+		h.logger.Warn("Failed to extend session on refresh", 
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID)
+	} else {
+		expiresAt = extended
+	}
+	
+	h.setSessionCookies(w, cookies, accessToken, refreshToken, expiresAt)
+	h.writeRefreshResponse(w, "Token refreshed successfully")
+	
+	h.logger.Info("Token refresh completed successfully", 
+		"user_id", session.UserID,
+		"session_id", session.ID,
+		"session_expires_at", expiresAt)
+}
 
-	// Validate current token to get session ID
+// freshAccessToken returns the claims of the request's access token when it is valid and not yet
+// within the refresh threshold of its expiry
+func (h *AuthHandler) freshAccessToken(r *http.Request, cookies CookiePolicy) (*auth.JWTClaims, bool) {
+	cookie, err := r.Cookie(accessTokenCookie)
+	if err != nil {
+		return nil, false
+	}
+	claims, err := h.jwtService.ValidateToken(cookie.Value)
+	if err != nil || claims.ExpiresAt == nil {
+		return nil, false
+	}
+	return claims, time.Until(claims.ExpiresAt.Time) > cookies.RefreshThreshold
+}
+
+// upgradeLegacySession issues the first refresh token of a session created before refresh tokens,
+// authenticated by its still-valid access token. Sessions that already have a refresh token must
+// present it, so a stolen access token cannot mint refresh tokens.
+func (h *AuthHandler) upgradeLegacySession(w http.ResponseWriter, r *http.Request, cookies CookiePolicy) {
+	clientIP := middleware.GetClientIP(r)
+	
+	cookie, err := r.Cookie(accessTokenCookie)
+	if err != nil {
+		h.logger.Warn("RefreshToken request without refresh or session token cookie", 
+			"cookie_error", err.Error(),
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "No refresh token")
+		return
+	}
+	
 	claims, err := h.jwtService.ValidateToken(cookie.Value)
 	if err != nil {
 		h.logger.Warn("RefreshToken request with invalid JWT token", 
 			"validation_error", err.Error(),
 			"client_ip", clientIP)
-		http.Error(w, "Invalid session token", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid session token")
 		return
 	}
 	
-	h.logger.Debug("JWT token validated successfully", 
-		"user_id", claims.UserID,
-		"session_id", claims.SessionID)
-
-	// Check if session is still active
 	session, err := h.sessionRepository.GetSessionByID(r.Context(), claims.SessionID)
 	if err != nil || session == nil || !session.IsActive {
 		h.logger.Warn("RefreshToken request for inactive or revoked session", 
@@ -488,73 +773,101 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 			"session_active", session != nil && session.IsActive,
 			"db_error", err,
 			"client_ip", clientIP)
-		http.Error(w, "Session revoked or inactive", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Session revoked or inactive")
 		return
 	}
-
-	// Generate new token
-	newToken, err := h.jwtService.RefreshToken(cookie.Value)
+	// A failed lookup refuses the refresh rather than risk issuing tokens to a suspended account
+	user, err := h.accounts.GetUserByID(r.Context(), claims.UserID)
 	if err != nil {
-		h.logger.Error("Failed to generate new JWT token during refresh", 
+		h.logger.Error("Failed to read account status, refusing the refresh", 
 			"error", err,
 			"user_id", claims.UserID,
-			"session_id", claims.SessionID,
+			"session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Account status is temporarily unavailable, please try again later")
+		return
+	}
+	if user != nil && user.Suspended() {
+		h.refuseSuspended(w, cookies, user.ID, session.ID)
+		return
+	}
+	
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		h.logger.Error("Failed to generate refresh token", "error", err, "session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to refresh token")
+		return
+	}
+	issued, err := h.sessionRepository.SetRefreshTokenHash(r.Context(), session.ID, refreshHash)
+	if err != nil {
+		h.logger.Error("Failed to store refresh token", 
+			"error", err,
+			"user_id", claims.UserID,
+			"session_id", session.ID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update session")
+		return
+	}
+	if !issued {
+		h.logger.Warn("RefreshToken request without the session's refresh token", 
+			"user_id", claims.UserID,
+			"session_id", session.ID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to refresh token", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "No refresh token")
 		return
 	}
 	
-	h.logger.Debug("Generated new JWT token", 
-		"user_id", claims.UserID,
-		"session_id", claims.SessionID)
-
-	// Update session token in database
-	if err := h.sessionRepository.UpdateSessionToken(r.Context(), claims.SessionID, newToken); err != nil {
-		h.logger.Error("Failed to update session token in database", 
+	accessToken, err := h.jwtService.RefreshToken(cookie.Value)
+	if err != nil {
+		h.logger.Error("Failed to generate new JWT token during refresh", 
 			"error", err,
 			"user_id", claims.UserID,
 			"session_id", claims.SessionID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to update session", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "Failed to refresh token")
 		return
 	}
-
-	// Set new JWT as HttpOnly cookie
-	domain, sameSite, secure := h.getCookieConfig()
-	newCookie := &http.Cookie{
-		Name:     "session_token",
-		Value:    newToken,
-		Path:     "/",
-		Domain:   domain,
-		MaxAge:   int((24 * time.Hour).Seconds()),
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
-	}
-
-	http.SetCookie(w, newCookie)
-	
-	h.logger.Debug("Set new JWT cookie", 
-		"cookie_domain", domain,
-		"cookie_secure", secure,
-		"user_id", claims.UserID,
-		"session_id", claims.SessionID)
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]string{
-		"message": "Token refreshed successfully",
-	}); err != nil {
-		h.logger.Error("Failed to encode refresh token response", 
+	if err := h.sessionRepository.UpdateSessionToken(r.Context(), session.ID, accessToken); err != nil {
+		h.logger.Error("Failed to update session token in database", 
 			"error", err,
 			"user_id", claims.UserID,
+			"session_id", session.ID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to encode refresh response", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update session")
 		return
 	}
 	
-	h.logger.Info("Token refresh completed successfully", 
+	h.setSessionCookies(w, cookies, accessToken, refreshToken, session.ExpiresAt)
+	h.writeRefreshResponse(w, "Token refreshed successfully")
+	
+	h.logger.Info("Upgraded legacy session with a refresh token", 
 		"user_id", claims.UserID,
-		"session_id", claims.SessionID)
+		"session_id", session.ID)
 }
 
+// refuseSuspended signs a suspended user out instead of refreshing their session
+func (h *AuthHandler) refuseSuspended(w http.ResponseWriter, cookies CookiePolicy, userID, sessionID int) {
+	h.logger.Warn("Session refresh refused for suspended account",
+		"user_id", userID,
+		"session_id", sessionID)
+	cookies.clearCookie(w, accessTokenCookie)
+	cookies.clearCookie(w, refreshTokenCookie)
+	h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeAccountSuspended, accountSuspendedMessage)
+}
 
+// writeRefreshResponse writes the body of a successful refresh
+func (h *AuthHandler) writeRefreshResponse(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+	}); err != nil {
+		h.logger.Error("Failed to encode refresh token response", "error", err)
+	}
+}
+
+func (h *AuthHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
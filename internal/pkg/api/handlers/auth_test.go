@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 )
 
@@ -308,6 +313,30 @@ func TestGetCookieConfig(t *testing.T) {
 			t.Error("Expected secure=true in production mode")
 		}
 	})
+
+	t.Run("ConfiguredPolicy", func(t *testing.T) {
+		handler := &AuthHandler{isDevelopment: true}
+		handler.SetCookiePolicy(NewCookiePolicy(config.SessionConfig{
+			CookieDomain:     ".example.com",
+			CookieSecure:     "true",
+			CookieSameSite:   "strict",
+			TTL:              8 * time.Hour,
+			RefreshThreshold: time.Hour,
+		}, true))
+		
+		domain, sameSite, secure := handler.getCookieConfig()
+		
+		if domain != ".example.com" || sameSite != http.SameSiteStrictMode || !secure {
+			t.Errorf("Expected the configured attributes, got domain=%q sameSite=%v secure=%v", domain, sameSite, secure)
+		}
+		
+		w := httptest.NewRecorder()
+		handler.cookiePolicy().setCookie(w, "session_token", "token", handler.cookiePolicy().SessionTTL)
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].MaxAge != int((8*time.Hour).Seconds()) {
+			t.Errorf("Expected the session cookie to last the configured TTL, got %+v", cookies)
+		}
+	})
 }
 
 // TestGetCurrentUser tests the GetCurrentUser endpoint returns dashboard data structure
@@ -484,3 +513,295 @@ func (t *TestableAuthHandler) testGetCurrentUser(w http.ResponseWriter, r *http.
 	t.GetCurrentUser(w, r)
 }
 
+
+// mockSessionStore keeps sessions and their refresh token hashes in memory
+type mockSessionStore struct {
+	sessions map[int]*database.UserSession
+}
+
+func (m *mockSessionStore) CreateSession(ctx context.Context, req *database.CreateSessionRequest) (*database.UserSession, error) {
+	session := &database.UserSession{ID: len(m.sessions) + 1, UserID: req.UserID, ExpiresAt: req.ExpiresAt, IsActive: true}
+	m.sessions[session.ID] = session
+	return session, nil
+}
+
+func (m *mockSessionStore) GetSessionByID(ctx context.Context, sessionID int) (*database.UserSession, error) {
+	return m.sessions[sessionID], nil
+}
+
+func (m *mockSessionStore) UpdateSessionToken(ctx context.Context, sessionID int, newToken string) error {
+	m.sessions[sessionID].SessionToken = newToken
+	return nil
+}
+
+func (m *mockSessionStore) DeactivateSession(ctx context.Context, sessionID int) error {
+	m.sessions[sessionID].IsActive = false
+	return nil
+}
+
+func (m *mockSessionStore) SetRefreshTokenHash(ctx context.Context, sessionID int, hash string) (bool, error) {
+	session := m.sessions[sessionID]
+	if session.RefreshTokenHash != nil {
+		return false, nil
+	}
+	now := time.Now()
+	session.RefreshTokenHash, session.RefreshRotatedAt = &hash, &now
+	return true, nil
+}
+
+func (m *mockSessionStore) GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*database.UserSession, error) {
+	for _, session := range m.sessions {
+		if (session.RefreshTokenHash != nil && *session.RefreshTokenHash == hash) ||
+			(session.PreviousRefreshTokenHash != nil && *session.PreviousRefreshTokenHash == hash) {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSessionStore) RotateRefreshToken(ctx context.Context, sessionID int, currentHash, newHash string) (bool, error) {
+	session := m.sessions[sessionID]
+	if session.RefreshTokenHash == nil || *session.RefreshTokenHash != currentHash {
+		return false, nil
+	}
+	now := time.Now()
+	session.PreviousRefreshTokenHash, session.RefreshTokenHash, session.RefreshRotatedAt = session.RefreshTokenHash, &newHash, &now
+	return true, nil
+}
+
+// UpdateSessionLastUsed slides the session's expiry to a day from now, as the repository does
+func (m *mockSessionStore) UpdateSessionLastUsed(ctx context.Context, sessionID int) (time.Time, error) {
+	session := m.sessions[sessionID]
+	session.LastUsedAt = time.Now()
+	if extended := session.LastUsedAt.Add(24 * time.Hour); extended.After(session.ExpiresAt) {
+		session.ExpiresAt = extended
+	}
+	return session.ExpiresAt, nil
+}
+
+type mockAccounts struct{}
+
+func (mockAccounts) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return &database.User{ID: id, Email: "runner@example.com", GoogleID: "google-1"}, nil
+}
+
+// refresh calls RefreshToken with the given refresh token cookie and returns the response
+func refresh(handler *AuthHandler, refreshToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: refreshToken})
+	w := httptest.NewRecorder()
+	handler.RefreshToken(w, req)
+	return w
+}
+
+func responseCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestRefreshToken_RotatesAndDetectsReuse(t *testing.T) {
+	store := &mockSessionStore{sessions: make(map[int]*database.UserSession)}
+	handler := &AuthHandler{
+		jwtService:        auth.NewJWTService("test-secret-key"),
+		sessionRepository: store,
+		accounts:          mockAccounts{},
+		isDevelopment:     true,
+		logger:            logger.New("test"),
+	}
+	policy := DefaultCookiePolicy(true)
+	policy.RefreshThreshold = time.Minute
+	handler.SetCookiePolicy(policy)
+
+	session, _ := store.CreateSession(context.Background(), &database.CreateSessionRequest{UserID: 7, ExpiresAt: time.Now().Add(time.Hour)})
+	firstToken, firstHash, err := auth.NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	store.SetRefreshTokenHash(context.Background(), session.ID, firstHash)
+	rotatedAt := time.Now().Add(-time.Minute)
+	session.RefreshRotatedAt = &rotatedAt
+
+	w := refresh(handler, firstToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the refresh to succeed, got %d %s", w.Code, w.Body.String())
+	}
+	access, next := responseCookie(w, accessTokenCookie), responseCookie(w, refreshTokenCookie)
+	if access == nil || next == nil || next.Value == firstToken {
+		t.Fatalf("Expected a new access token and a rotated refresh token, got %v %v", access, next)
+	}
+	if access.MaxAge != int(policy.AccessTokenTTL.Seconds()) {
+		t.Errorf("Expected the access cookie to last %s, got %ds", policy.AccessTokenTTL, access.MaxAge)
+	}
+	// The refresh extended the session, and the refresh cookie follows its new expiry
+	if next.MaxAge <= int(time.Hour.Seconds()) || !session.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Expected the session and refresh cookie to be extended, got %ds until %s", next.MaxAge, session.ExpiresAt)
+	}
+	claims, err := handler.jwtService.ValidateToken(access.Value)
+	if err != nil || claims.UserID != 7 || claims.SessionID != session.ID || claims.Email != "runner@example.com" {
+		t.Fatalf("Expected an access token for the session, got %+v, %v", claims, err)
+	}
+
+	// Moments after a rotation the replaced token is refused without revoking the session, as
+	// two tabs may have refreshed at once
+	if w := refresh(handler, firstToken); w.Code != http.StatusUnauthorized || !session.IsActive {
+		t.Fatalf("Expected a concurrent refresh to be refused, got %d, active=%v", w.Code, session.IsActive)
+	}
+
+	// Later, the replaced token can only be a stolen copy
+	rotatedAt = time.Now().Add(-time.Minute)
+	session.RefreshRotatedAt = &rotatedAt
+	w = refresh(handler, firstToken)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "REFRESH_TOKEN_REUSED") {
+		t.Fatalf("Expected refresh token reuse to be detected, got %d %s", w.Code, w.Body.String())
+	}
+	if session.IsActive {
+		t.Error("Expected the session to be revoked after refresh token reuse")
+	}
+	if w := refresh(handler, next.Value); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the revoked session's current refresh token to stop working, got %d", w.Code)
+	}
+}
+
+// suspendedAccounts returns every user as suspended
+type suspendedAccounts struct{}
+
+func (suspendedAccounts) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	suspendedAt := time.Now().Add(-time.Hour)
+	return &database.User{ID: id, Email: "runner@example.com", GoogleID: "google-1", SuspendedAt: &suspendedAt}, nil
+}
+
+func TestRefreshToken_RefusesSuspendedUser(t *testing.T) {
+	store := &mockSessionStore{sessions: make(map[int]*database.UserSession)}
+	handler := &AuthHandler{
+		jwtService:        auth.NewJWTService("test-secret-key"),
+		sessionRepository: store,
+		accounts:          suspendedAccounts{},
+		isDevelopment:     true,
+		logger:            logger.New("test"),
+	}
+
+	session, _ := store.CreateSession(context.Background(), &database.CreateSessionRequest{UserID: 7, ExpiresAt: time.Now().Add(time.Hour)})
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	store.SetRefreshTokenHash(context.Background(), session.ID, hash)
+
+	w := refresh(handler, token)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), ErrorCodeAccountSuspended) {
+		t.Fatalf("Expected the refresh to be refused with ACCOUNT_SUSPENDED, got %d %s", w.Code, w.Body.String())
+	}
+	if cookie := responseCookie(w, refreshTokenCookie); cookie == nil || cookie.MaxAge >= 0 {
+		t.Errorf("Expected the refresh cookie to be cleared, got %v", cookie)
+	}
+	if *store.sessions[session.ID].RefreshTokenHash != hash {
+		t.Error("Expected the refresh token not to be rotated")
+	}
+}
+
+func TestRefreshToken_RefusedWhenAccountStatusUnavailable(t *testing.T) {
+	store := &mockSessionStore{sessions: make(map[int]*database.UserSession)}
+	handler := &AuthHandler{
+		jwtService:        auth.NewJWTService("test-secret-key"),
+		sessionRepository: store,
+		accounts:          failingAccounts{},
+		isDevelopment:     true,
+		logger:            logger.New("test"),
+	}
+	// Every access token is within the threshold, so each request refreshes
+	policy := DefaultCookiePolicy(true)
+	policy.RefreshThreshold = 365 * 24 * time.Hour
+	handler.SetCookiePolicy(policy)
+
+	// A session with a refresh token rotates it
+	session, _ := store.CreateSession(context.Background(), &database.CreateSessionRequest{UserID: 7, ExpiresAt: time.Now().Add(time.Hour)})
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	store.SetRefreshTokenHash(context.Background(), session.ID, hash)
+
+	w := refresh(handler, token)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the rotation to be refused with 503, got %d %s", w.Code, w.Body.String())
+	}
+	if responseCookie(w, accessTokenCookie) != nil || responseCookie(w, refreshTokenCookie) != nil {
+		t.Error("Expected no tokens to be issued on rotation")
+	}
+	if *store.sessions[session.ID].RefreshTokenHash != hash {
+		t.Error("Expected the refresh token not to be rotated")
+	}
+
+	// A legacy session without one is upgraded from its access token
+	session, _ = store.CreateSession(context.Background(), &database.CreateSessionRequest{UserID: 7, ExpiresAt: time.Now().Add(time.Hour)})
+	accessToken, err := handler.jwtService.GenerateTokenWithRole(7, "runner@example.com", "google-1", session.ID, "")
+	if err != nil {
+		t.Fatalf("GenerateTokenWithRole() failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: accessTokenCookie, Value: accessToken})
+	w = httptest.NewRecorder()
+	handler.RefreshToken(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the upgrade to be refused with 503, got %d %s", w.Code, w.Body.String())
+	}
+	if responseCookie(w, accessTokenCookie) != nil || responseCookie(w, refreshTokenCookie) != nil {
+		t.Error("Expected no tokens to be issued on upgrade")
+	}
+	if store.sessions[session.ID].RefreshTokenHash != nil {
+		t.Error("Expected no refresh token to be stored")
+	}
+}
+
+type mockAutomationStatusStore struct {
+	status *database.AutomationStatus
+}
+
+func (m *mockAutomationStatusStore) GetAutomationStatus(ctx context.Context, userID int) (*database.AutomationStatus, error) {
+	return m.status, nil
+}
+
+func TestAddAutomationStatus(t *testing.T) {
+	spreadsheetID := "sheet"
+	startedAt := time.Now().Add(-time.Hour)
+	store := &mockAutomationStatusStore{status: &database.AutomationStatus{
+		LastRun:              &database.LastRunSummary{Status: database.RunStatusCompleted, TriggerType: "manual", ActivitiesCount: 2, StartedAt: startedAt},
+		StravaReauthRequired: true,
+	}}
+	handler := &AuthHandler{logger: logger.New("test")}
+	handler.SetAutomationStatus(store)
+
+	user := &database.User{
+		ID:                 7,
+		Timezone:           "UTC",
+		AutomationEnabled:  true,
+		SpreadsheetID:      &spreadsheetID,
+		StravaAccessToken:  []byte("access"),
+		StravaRefreshToken: []byte("refresh"),
+		GoogleRefreshToken: []byte("refresh"),
+	}
+	response := &database.DashboardUserResponse{PublicUser: user.ToPublicUser()}
+	handler.addAutomationStatus(context.Background(), response, user)
+
+	if response.LastRun == nil || response.LastRun.ActivitiesCount != 2 || !response.StravaReauthRequired || response.GoogleReauthRequired {
+		t.Errorf("Unexpected automation status: %+v", response)
+	}
+	if response.NextScheduledRunAt == nil || !response.NextScheduledRunAt.After(time.Now()) || response.NextScheduledRunAt.In(time.UTC).Hour() != 3 {
+		t.Errorf("Expected the next daily run, got %v", response.NextScheduledRunAt)
+	}
+
+	// Users automation does not pick up have no next run
+	user.AutomationEnabled = false
+	response = &database.DashboardUserResponse{PublicUser: user.ToPublicUser()}
+	handler.addAutomationStatus(context.Background(), response, user)
+	if response.NextScheduledRunAt != nil {
+		t.Errorf("Expected no next run with automation off, got %v", response.NextScheduledRunAt)
+	}
+}
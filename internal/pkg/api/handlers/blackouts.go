@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// maxBlackoutDuration bounds a single window so a typo cannot pause syncing for months
+const maxBlackoutDuration = 7 * 24 * time.Hour
+
+// BlackoutStore lists, declares and removes blackout windows
+type BlackoutStore interface {
+	ListBlackoutWindows(ctx context.Context, after time.Time) ([]database.BlackoutWindow, error)
+	CreateBlackoutWindow(ctx context.Context, window *database.BlackoutWindow) error
+	DeleteBlackoutWindow(ctx context.Context, id int) error
+}
+
+// BlackoutHandler lets admins declare the windows during which syncing is paused
+type BlackoutHandler struct {
+	store      BlackoutStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewBlackoutHandler creates a new blackout window handler
+func NewBlackoutHandler(store BlackoutStore, authorizer authz.Authorizer, logger *logger.Logger) *BlackoutHandler {
+	return &BlackoutHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "blackout_handler"),
+	}
+}
+
+// CreateBlackoutRequest declares a blackout window
+type CreateBlackoutRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+// Validate checks the window is complete, ends in the future and is at most maxBlackoutDuration long
+func (req *CreateBlackoutRequest) Validate(v *validate.Validator) {
+	v.Check(!req.StartsAt.IsZero(), "starts_at", validate.CodeRequired, "starts_at is required")
+	if !v.Check(!req.EndsAt.IsZero(), "ends_at", validate.CodeRequired, "ends_at is required") || req.StartsAt.IsZero() {
+		return
+	}
+	switch {
+	case !req.EndsAt.After(req.StartsAt):
+		v.Add("ends_at", validate.CodeInvalid, "ends_at must be after starts_at")
+	case !req.EndsAt.After(time.Now()):
+		v.Add("ends_at", validate.CodeInvalid, "ends_at must be in the future")
+	case req.EndsAt.Sub(req.StartsAt) > maxBlackoutDuration:
+		v.Add("ends_at", validate.CodeInvalid, "A blackout window may last at most 7 days")
+	}
+}
+
+// BlackoutsResponse lists the current and upcoming blackout windows
+type BlackoutsResponse struct {
+	Blackouts []database.BlackoutWindow `json:"blackouts"`
+}
+
+// List handles GET /api/v1/admin/blackouts, returning the windows that have not ended yet
+func (h *BlackoutHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	windows, err := h.store.ListBlackoutWindows(r.Context(), time.Now())
+	if err != nil {
+		h.logger.Error("Failed to list blackout windows",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list blackout windows")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, BlackoutsResponse{Blackouts: windows})
+}
+
+// Create handles POST /api/v1/admin/blackouts with {"starts_at", "ends_at", "reason"} in RFC 3339
+func (h *BlackoutHandler) Create(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req CreateBlackoutRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	createdBy := subject.UserID
+	window := &database.BlackoutWindow{
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		Reason:    req.Reason,
+		CreatedBy: &createdBy,
+	}
+	if err := h.store.CreateBlackoutWindow(r.Context(), window); err != nil {
+		h.logger.Error("Failed to create blackout window",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create blackout window")
+		return
+	}
+
+	h.logger.Info("Blackout window declared",
+		"user_id", subject.UserID,
+		"blackout_id", window.ID,
+		"starts_at", window.StartsAt,
+		"ends_at", window.EndsAt,
+		"reason", window.Reason)
+	h.writeJSON(w, http.StatusCreated, window)
+}
+
+// Delete handles DELETE /api/v1/admin/blackouts/{id}; deleting a window in effect resumes syncing
+func (h *BlackoutHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid blackout window ID is required")
+		return
+	}
+
+	if err := h.store.DeleteBlackoutWindow(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Blackout window not found")
+			return
+		}
+		h.logger.Error("Failed to delete blackout window",
+			"error", err,
+			"user_id", subject.UserID,
+			"blackout_id", id)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete blackout window")
+		return
+	}
+
+	h.logger.Info("Blackout window deleted",
+		"user_id", subject.UserID,
+		"blackout_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize checks that the caller is an admin, writing the error response if not
+func (h *BlackoutHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Blackout windows called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.BlackoutWindows()); err != nil {
+		h.logger.Warn("Blackout windows denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may manage blackout windows")
+		return subject, false
+	}
+	return subject, true
+}
+
+func (h *BlackoutHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode blackout response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *BlackoutHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
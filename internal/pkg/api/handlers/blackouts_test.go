@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockBlackoutStore struct {
+	windows []database.BlackoutWindow
+}
+
+func (m *mockBlackoutStore) ListBlackoutWindows(ctx context.Context, after time.Time) ([]database.BlackoutWindow, error) {
+	return m.windows, nil
+}
+
+func (m *mockBlackoutStore) CreateBlackoutWindow(ctx context.Context, window *database.BlackoutWindow) error {
+	window.ID = len(m.windows) + 1
+	m.windows = append(m.windows, *window)
+	return nil
+}
+
+func (m *mockBlackoutStore) DeleteBlackoutWindow(ctx context.Context, id int) error {
+	for i, window := range m.windows {
+		if window.ID == id {
+			m.windows = append(m.windows[:i], m.windows[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *mockBlackoutStore) GetActiveBlackout(ctx context.Context, at time.Time) (*database.BlackoutWindow, error) {
+	return database.ActiveBlackout(m.windows, at), nil
+}
+
+func TestBlackoutHandler(t *testing.T) {
+	store := &mockBlackoutStore{}
+	handler := NewBlackoutHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/blackouts", handler.List)
+	router.Post("/api/admin/blackouts", handler.Create)
+	router.Delete("/api/admin/blackouts/{id}", handler.Delete)
+
+	create := func(startsAt, endsAt time.Time) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"starts_at": %q, "ends_at": %q, "reason": "Strava maintenance"}`, startsAt.Format(time.RFC3339), endsAt.Format(time.RFC3339))
+		req := authenticatedRequest(http.MethodPost, "/api/admin/blackouts", body, 1)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, []authz.Role{authz.RoleAthlete, authz.RoleAdmin}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	now := time.Now()
+	if rr := create(now.Add(time.Hour), now); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a window ending before it starts, got %d", rr.Code)
+	}
+	if rr := create(now, now.Add(30*24*time.Hour)); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a month-long window, got %d", rr.Code)
+	}
+	if rr := create(now.Add(-time.Minute), now.Add(time.Hour)); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(store.windows) != 1 || *store.windows[0].CreatedBy != 1 {
+		t.Errorf("Expected the window to be stored with its creator: %+v", store.windows)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("/api/admin/blackouts"))
+	var response BlackoutsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || len(response.Blackouts) != 1 {
+		t.Fatalf("Expected one blackout window, got %d (%v)", rr.Code, err)
+	}
+
+	// Athletes may not manage blackout windows
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, authenticatedRequest(http.MethodGet, "/api/admin/blackouts", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+
+	remove := func(id string) int {
+		req := adminRequest("/api/admin/blackouts/" + id)
+		req.Method = http.MethodDelete
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := remove("1"); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if code := remove("1"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a deleted window, got %d", code)
+	}
+}
+
+func TestSyncHandler_TriggerSyncDuringBlackout(t *testing.T) {
+	now := time.Now()
+	store := &mockBlackoutStore{windows: []database.BlackoutWindow{{ID: 1, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}}}
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetBlackouts(store, nil)
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("Expected status 503 with Retry-After, got %d", rr.Code)
+	}
+	var response ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	retryAfter, _ := time.Parse(time.RFC3339Nano, fmt.Sprint(response.Error.Details["retry_after"]))
+	if response.Error.Code != "SYNC_PAUSED" || response.Error.Remediation != "retry_later" || !strings.Contains(response.Error.Message, "try again after") || !retryAfter.Equal(store.windows[0].EndsAt) {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if len(jobQueue.enqueued) != 0 {
+		t.Error("Expected no job to be enqueued during a blackout")
+	}
+}
+
+func TestTryAgainAfter(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+
+	if got := tryAgainAfter(now.Add(2*time.Hour+30*time.Minute), now, madrid); got != "14:30" {
+		t.Errorf("Expected the local time, got %q", got)
+	}
+	if got := tryAgainAfter(now.Add(2*time.Hour), now, time.UTC); got != "12:00 UTC" {
+		t.Errorf("Expected the time in UTC, got %q", got)
+	}
+	if got := tryAgainAfter(now.Add(24*time.Hour), now, madrid); got != "Fri 21 Jun 12:00" {
+		t.Errorf("Expected the date for a later day, got %q", got)
+	}
+}
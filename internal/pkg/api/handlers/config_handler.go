@@ -5,28 +5,46 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
 )
 
 // ConfigHandler handles configuration-related HTTP requests
 type ConfigHandler struct {
 	configService *services.ConfigService
+	authorizer    authz.Authorizer
 	logger        *logger.Logger
 }
 
 // NewConfigHandler creates a new configuration handler
-func NewConfigHandler(configService *services.ConfigService, logger *logger.Logger) *ConfigHandler {
+func NewConfigHandler(configService *services.ConfigService, authorizer authz.Authorizer, logger *logger.Logger) *ConfigHandler {
 	return &ConfigHandler{
 		configService: configService,
+		authorizer:    authorizer,
 		logger:        logger.WithContext("component", "config_handler"),
 	}
 }
 
 // SetSpreadsheetRequest represents the request body for setting a spreadsheet URL
 type SetSpreadsheetRequest struct {
-	URL string `json:"url"`
+	URL        string `json:"url"`
+	TemplateID string `json:"template_id,omitempty"` // Catalog template the spreadsheet follows (default basic_log)
+}
+
+// maxURLLength bounds URLs pasted into configuration requests
+const maxURLLength = 2048
+
+// Validate checks the spreadsheet URL is present; ConfigService checks it names a spreadsheet
+func (req *SetSpreadsheetRequest) Validate(v *validate.Validator) {
+	if v.Required("url", req.URL, "Spreadsheet URL cannot be empty") {
+		v.MaxLength("url", req.URL, maxURLLength)
+	}
 }
 
 // SetSpreadsheetResponse represents the response for spreadsheet configuration
@@ -35,16 +53,18 @@ type SetSpreadsheetResponse struct {
 	Message string `json:"message"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Type    string `json:"type,omitempty"`
+// ErrorResponse is the error envelope shared by every handler (see package apierror)
+type ErrorResponse = apierror.Response
+
+// newErrorResponse builds an error response, attaching the catalog's help for errorCode
+func newErrorResponse(errorCode, message string) ErrorResponse {
+	return apierror.New(errorCode, message)
 }
 
-// SetSpreadsheet handles POST /api/config/spreadsheet requests
+// SetSpreadsheet handles POST /api/v1/config/spreadsheet requests
 func (h *ConfigHandler) SetSpreadsheet(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
 	clientIP := middleware.GetClientIP(r)
 	
 	h.logger.Info("SetSpreadsheet API request received",
@@ -61,36 +81,28 @@ func (h *ConfigHandler) SetSpreadsheet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse request body
-	var req SetSpreadsheetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("Invalid JSON in SetSpreadsheet request",
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetSpreadsheet denied by authorization policy",
 			"error", err,
-			"user_id", userID,
-			"client_ip", clientIP)
-		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_JSON", "Invalid JSON in request body", "")
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetSpreadsheetRequest
+	if !decodeRequest(w, r, &req, h.logger) {
 		return
 	}
 
 	h.logger.Debug("Parsed SetSpreadsheet request",
 		"user_id", userID,
-		"url_provided", req.URL != "",
 		"url_length", len(req.URL))
 
-	// Validate request
-	if strings.TrimSpace(req.URL) == "" {
-		h.logger.Warn("Empty URL provided in SetSpreadsheet request",
-			"user_id", userID,
-			"client_ip", clientIP)
-		h.writeErrorResponse(w, http.StatusBadRequest, "EMPTY_URL", "Spreadsheet URL cannot be empty", "")
-		return
-	}
-
 	// Call service to set spreadsheet
 	h.logger.Debug("Calling ConfigService.SetSpreadsheetURL",
 		"user_id", userID)
 
-	err := h.configService.SetSpreadsheetURL(r.Context(), userID, req.URL)
+	err := h.configService.SetSpreadsheetURL(r.Context(), userID, req.URL, req.TemplateID)
 	if err != nil {
 		// Handle different types of configuration errors
 		if configErr, ok := err.(*services.ConfigError); ok {
@@ -101,7 +113,7 @@ func (h *ConfigHandler) SetSpreadsheet(w http.ResponseWriter, r *http.Request) {
 				"client_ip", clientIP)
 
 			// Map service errors to HTTP status codes
-			statusCode := h.getStatusCodeForConfigError(configErr.Type)
+			statusCode := getStatusCodeForConfigError(configErr.Type)
 			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
 			return
 		}
@@ -135,9 +147,10 @@ func (h *ConfigHandler) SetSpreadsheet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// ClearSpreadsheet handles DELETE /api/config/spreadsheet requests
+// ClearSpreadsheet handles DELETE /api/v1/config/spreadsheet requests
 func (h *ConfigHandler) ClearSpreadsheet(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
 	clientIP := middleware.GetClientIP(r)
 	
 	h.logger.Info("ClearSpreadsheet API request received",
@@ -153,6 +166,14 @@ func (h *ConfigHandler) ClearSpreadsheet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("ClearSpreadsheet denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
 	// Call service to clear spreadsheet
 	h.logger.Debug("Calling ConfigService.ClearSpreadsheetURL",
 		"user_id", userID)
@@ -167,7 +188,7 @@ func (h *ConfigHandler) ClearSpreadsheet(w http.ResponseWriter, r *http.Request)
 				"user_id", userID,
 				"client_ip", clientIP)
 
-			statusCode := h.getStatusCodeForConfigError(configErr.Type)
+			statusCode := getStatusCodeForConfigError(configErr.Type)
 			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
 			return
 		}
@@ -201,8 +222,674 @@ func (h *ConfigHandler) ClearSpreadsheet(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// SetWebhookRequest represents the request body for configuring an outbound webhook
+type SetWebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"` // Optional; generated when empty
+}
+
+// Validate checks the webhook URL is present; ConfigService checks it is an https:// URL
+func (req *SetWebhookRequest) Validate(v *validate.Validator) {
+	if v.Required("url", req.URL, "Webhook URL cannot be empty") {
+		v.MaxLength("url", req.URL, maxURLLength)
+	}
+}
+
+// SetWebhookResponse returns the signing secret, which is only ever shown in this response
+type SetWebhookResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Secret  string `json:"secret"`
+}
+
+// SetWebhook handles PUT /api/v1/config/webhook requests
+func (h *ConfigHandler) SetWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+	
+	h.logger.Info("SetWebhook API request received",
+		"user_id", userID,
+		"has_user_id", ok,
+		"client_ip", clientIP,
+		"method", r.Method)
+
+	if !ok {
+		h.logger.Warn("SetWebhook called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetWebhook denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetWebhookRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	secret, err := h.configService.SetWebhook(r.Context(), userID, req.URL, req.Secret)
+	if err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			h.logger.Warn("ConfigService returned error during webhook configuration",
+				"error_type", configErr.Type,
+				"error_message", configErr.Message,
+				"user_id", userID,
+				"client_ip", clientIP)
+
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetWebhook",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	response := SetWebhookResponse{
+		Success: true,
+		Message: "Webhook configuration saved successfully. Store the secret now; it will not be shown again.",
+		Secret:  secret,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode SetWebhook response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// ClearWebhook handles DELETE /api/v1/config/webhook requests
+func (h *ConfigHandler) ClearWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("ClearWebhook called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("ClearWebhook denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	if err := h.configService.ClearWebhook(r.Context(), userID); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in ClearWebhook",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	h.logger.Info("ClearWebhook completed successfully",
+		"user_id", userID,
+		"client_ip", clientIP)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Webhook configuration cleared successfully"}); err != nil {
+		h.logger.Error("Failed to encode ClearWebhook response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetNotificationChannelRequest represents the request body for choosing a notification channel
+// and the kinds of notification to receive; either may be left out
+type SetNotificationChannelRequest struct {
+	Channel    string `json:"channel,omitempty"`     // email, slack or discord
+	WebhookURL string `json:"webhook_url,omitempty"` // Required for slack and discord
+
+	// Preferences turns notification preferences on or off by name; others keep their value
+	Preferences map[string]bool `json:"preferences,omitempty"`
+}
+
+// Validate checks the channel is known, chat channels come with a webhook URL and the
+// preferences are known
+func (req *SetNotificationChannelRequest) Validate(v *validate.Validator) {
+	for name := range req.Preferences {
+		v.OneOf("preferences", name, database.NotificationPreferenceNames()...)
+	}
+	if len(req.Preferences) > 0 && req.Channel == "" {
+		return
+	}
+	if !v.Required("channel", req.Channel, "Notification channel cannot be empty") {
+		return
+	}
+	v.OneOf("channel", req.Channel, notification.ChannelEmail, notification.ChannelSlack, notification.ChannelDiscord)
+	if notification.IsChatChannel(strings.ToLower(strings.TrimSpace(req.Channel))) {
+		v.Required("webhook_url", req.WebhookURL, "A webhook URL is required for chat notifications")
+	}
+}
+
+// NotificationSettingsResponse represents the response for reading notification settings. The
+// chat webhook URL is a credential, so only whether one is stored is returned.
+type NotificationSettingsResponse struct {
+	Channel           string                           `json:"channel"`
+	WebhookConfigured bool                             `json:"webhook_configured"`
+	Preferences       database.NotificationPreferences `json:"preferences"`
+	QuietHours        *QuietHoursResponse              `json:"quiet_hours"` // null when not set
+}
+
+// QuietHoursResponse is a quiet hours window in the user's timezone
+type QuietHoursResponse struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+// GetNotificationSettings handles GET /api/v1/config/notifications requests
+func (h *ConfigHandler) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("GetNotificationSettings called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Config(userID)); err != nil {
+		h.logger.Warn("GetNotificationSettings denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to read this configuration", "")
+		return
+	}
+
+	settings, err := h.configService.GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in GetNotificationSettings",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	response := NotificationSettingsResponse{
+		Channel:           settings.Channel,
+		WebhookConfigured: settings.WebhookURL != "",
+		Preferences:       database.DefaultNotificationPreferences(),
+	}
+	if settings.Preferences != nil {
+		response.Preferences = *settings.Preferences
+	}
+	if settings.QuietHoursStart != "" && settings.QuietHoursEnd != "" {
+		response.QuietHours = &QuietHoursResponse{Start: settings.QuietHoursStart, End: settings.QuietHoursEnd, Timezone: settings.Timezone}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode GetNotificationSettings response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetNotificationChannel handles PUT /api/v1/config/notifications requests
+func (h *ConfigHandler) SetNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetNotificationChannel called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetNotificationChannel denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetNotificationChannelRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	var err error
+	if req.Channel != "" {
+		err = h.configService.SetNotificationChannel(r.Context(), userID, req.Channel, req.WebhookURL)
+	}
+	if err == nil && len(req.Preferences) > 0 {
+		_, err = h.configService.SetNotificationPreferences(r.Context(), userID, req.Preferences)
+	}
+	if err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetNotificationChannel",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Notification settings saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetNotificationChannel response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetLocaleRequest represents the request body for choosing the notification locale
+type SetLocaleRequest struct {
+	Locale string `json:"locale"`
+}
+
+// Validate checks the locale is supported
+func (req *SetLocaleRequest) Validate(v *validate.Validator) {
+	if v.Required("locale", req.Locale, "Locale cannot be empty") {
+		v.OneOf("locale", req.Locale, notification.SupportedLocales()...)
+	}
+}
+
+// SetLocale handles PUT /api/v1/config/locale requests
+func (h *ConfigHandler) SetLocale(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetLocale called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetLocale denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetLocaleRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetLocale(r.Context(), userID, req.Locale); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetLocale",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Locale saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetLocale response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetDigestRequest represents the request body for choosing digest notifications
+type SetDigestRequest struct {
+	Enabled    bool   `json:"enabled"`
+	DigestTime string `json:"digest_time"` // Local HH:MM, e.g. "18:00"
+}
+
+// Validate checks the digest time is a 24-hour HH:MM time
+func (req *SetDigestRequest) Validate(v *validate.Validator) {
+	if v.Required("digest_time", req.DigestTime, "Digest time cannot be empty") {
+		_, _, err := notification.ParseDigestTime(strings.TrimSpace(req.DigestTime))
+		v.Check(err == nil, "digest_time", validate.CodeInvalid, "Digest time must be a 24-hour time in HH:MM format")
+	}
+}
+
+// SetDigest handles PUT /api/v1/config/notifications/digest requests
+func (h *ConfigHandler) SetDigest(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetDigest called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetDigest denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetDigestRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetDigest(r.Context(), userID, req.Enabled, req.DigestTime); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetDigest",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Digest settings saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetDigest response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetQuietHoursRequest represents the request body for the notification quiet hours; both times
+// empty turn quiet hours off
+type SetQuietHoursRequest struct {
+	Start string `json:"start"` // Local HH:MM, e.g. "22:00"
+	End   string `json:"end"`   // Local HH:MM; earlier than start for a window spanning midnight
+}
+
+// Validate checks both times are set, or neither, and form a window
+func (req *SetQuietHoursRequest) Validate(v *validate.Validator) {
+	start, end := strings.TrimSpace(req.Start), strings.TrimSpace(req.End)
+	if start == "" && end == "" {
+		return
+	}
+	if err := notification.ValidateQuietHours(start, end); err != nil {
+		v.Add("quiet_hours", validate.CodeInvalid, "Quiet hours need different start and end times in 24-hour HH:MM format")
+	}
+}
+
+// SetQuietHours handles PUT /api/v1/config/notifications/quiet-hours requests
+func (h *ConfigHandler) SetQuietHours(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetQuietHours called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetQuietHours denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetQuietHoursRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetQuietHours(r.Context(), userID, req.Start, req.End); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetQuietHours",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Quiet hours saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetQuietHours response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetChronologicalOrderRequest represents the request body for the spreadsheet row order setting
+type SetChronologicalOrderRequest struct {
+	Chronological bool `json:"chronological"`
+}
+
+// SetChronologicalOrder handles PUT /api/v1/config/spreadsheet/order requests
+func (h *ConfigHandler) SetChronologicalOrder(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetChronologicalOrder called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetChronologicalOrder denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetChronologicalOrderRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetChronologicalOrder(r.Context(), userID, req.Chronological); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetChronologicalOrder",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Row order setting saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetChronologicalOrder response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetGearColumnRequest represents the request body for the spreadsheet Gear column setting
+type SetGearColumnRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetGearColumn handles PUT /api/v1/config/spreadsheet/gear requests
+func (h *ConfigHandler) SetGearColumn(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetGearColumn called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetGearColumn denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetGearColumnRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetGearColumn(r.Context(), userID, req.Enabled); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetGearColumn",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Gear column setting saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetGearColumn response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
+// SetWeeklySummaryRequest represents the request body for the weekly summary tab setting
+type SetWeeklySummaryRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetWeeklySummary handles PUT /api/v1/config/spreadsheet/summary requests
+func (h *ConfigHandler) SetWeeklySummary(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("SetWeeklySummary called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("SetWeeklySummary denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration", "")
+		return
+	}
+
+	var req SetWeeklySummaryRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.configService.SetWeeklySummary(r.Context(), userID, req.Enabled); err != nil {
+		if configErr, ok := err.(*services.ConfigError); ok {
+			statusCode := getStatusCodeForConfigError(configErr.Type)
+			h.writeErrorResponse(w, statusCode, configErr.Type, configErr.Message, configErr.Type)
+			return
+		}
+
+		h.logger.Error("Unexpected error in SetWeeklySummary",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SetSpreadsheetResponse{Success: true, Message: "Weekly summary setting saved successfully"}); err != nil {
+		h.logger.Error("Failed to encode SetWeeklySummary response",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+	}
+}
+
 // getStatusCodeForConfigError maps configuration error types to HTTP status codes
-func (h *ConfigHandler) getStatusCodeForConfigError(errorType string) int {
+func getStatusCodeForConfigError(errorType string) int {
 	switch errorType {
 	case services.ConfigErrorInvalidURL:
 		return http.StatusBadRequest
@@ -223,19 +910,12 @@ func (h *ConfigHandler) getStatusCodeForConfigError(errorType string) int {
 
 // writeErrorResponse writes a standardized error response
 func (h *ConfigHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message, errorType string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	errorResponse := ErrorResponse{
-		Error:   errorCode,
-		Message: message,
-	}
-
+	errorResponse := newErrorResponse(errorCode, message)
 	if errorType != "" {
-		errorResponse.Type = errorType
+		errorResponse = errorResponse.WithDetail("type", errorType)
 	}
 
-	if err := json.NewEncoder(w).Encode(errorResponse); err != nil {
+	if err := apierror.Write(w, statusCode, errorResponse); err != nil {
 		h.logger.Error("Failed to encode error response",
 			"error", err,
 			"status_code", statusCode,
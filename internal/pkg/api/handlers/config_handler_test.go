@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+func TestConfigHandler_SetWeeklySummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := database.NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+	handler := NewConfigHandler(services.NewConfigService(repo, nil, logger.New("test")), authz.DefaultPolicy(), logger.New("test"))
+
+	mock.ExpectExec("UPDATE users SET weekly_summary_enabled").
+		WithArgs(true, sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rr := httptest.NewRecorder()
+	handler.SetWeeklySummary(rr, authenticatedRequest(http.MethodPut, "/api/v1/config/spreadsheet/summary", `{"enabled": true}`, 5))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.SetWeeklySummary(rr, authenticatedRequest(http.MethodPut, "/api/v1/config/spreadsheet/summary", `{"enabled": "yes"}`, 5))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.SetWeeklySummary(rr, httptest.NewRequest(http.MethodPut, "/api/v1/config/spreadsheet/summary", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without user, got %d", rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
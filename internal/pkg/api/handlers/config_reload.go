@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// SecretReloader re-fetches rotatable secrets from the secret store
+type SecretReloader interface {
+	Reload(ctx context.Context) ([]string, error)
+}
+
+// ConfigReloadHandler lets admins apply rotated secrets without a redeploy
+type ConfigReloadHandler struct {
+	reloader   SecretReloader
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewConfigReloadHandler creates a new config reload handler. reloader is nil when the
+// configuration was loaded from environment variables, which cannot be reloaded.
+func NewConfigReloadHandler(reloader SecretReloader, authorizer authz.Authorizer, logger *logger.Logger) *ConfigReloadHandler {
+	return &ConfigReloadHandler{
+		reloader:   reloader,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "config_reload_handler"),
+	}
+}
+
+// ConfigReloadResponse lists the secrets whose rotation was applied
+type ConfigReloadResponse struct {
+	Reloaded   []string  `json:"reloaded"`
+	ReloadedAt time.Time `json:"reloaded_at"`
+}
+
+// Reload handles POST /internal/config/reload requests
+func (h *ConfigReloadHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Config reload called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.ServiceConfig()); err != nil {
+		h.logger.Warn("Config reload denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may reload the configuration")
+		return
+	}
+
+	if h.reloader == nil {
+		h.writeErrorResponse(w, http.StatusConflict, "RELOAD_UNAVAILABLE", "Configuration was loaded from environment variables and cannot be reloaded")
+		return
+	}
+
+	reloaded, err := h.reloader.Reload(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to reload secrets",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusBadGateway, "SECRET_STORE_ERROR", "Failed to fetch secrets from the secret store")
+		return
+	}
+	if reloaded == nil {
+		reloaded = []string{}
+	}
+
+	h.logger.Info("Secrets reloaded on request",
+		"user_id", subject.UserID,
+		"reloaded", reloaded)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ConfigReloadResponse{Reloaded: reloaded, ReloadedAt: time.Now().UTC()}); err != nil {
+		h.logger.Error("Failed to encode config reload response",
+			"error", err)
+	}
+}
+
+func (h *ConfigReloadHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
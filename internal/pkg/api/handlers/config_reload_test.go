@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSecretReloader struct {
+	changed []string
+	calls   int
+}
+
+func (m *mockSecretReloader) Reload(ctx context.Context) ([]string, error) {
+	m.calls++
+	return m.changed, nil
+}
+
+func TestConfigReloadHandler(t *testing.T) {
+	reloader := &mockSecretReloader{changed: []string{"strava-client-secret"}}
+	handler := NewConfigReloadHandler(reloader, authz.DefaultPolicy(), logger.New("test"))
+
+	req := adminRequest("/internal/config/reload")
+	req.Method = http.MethodPost
+	rr := httptest.NewRecorder()
+	handler.Reload(rr, req)
+
+	var response ConfigReloadResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("Expected a reload response, got %d (%v)", rr.Code, err)
+	}
+	if len(response.Reloaded) != 1 || response.Reloaded[0] != "strava-client-secret" {
+		t.Errorf("Unexpected reloaded secrets: %v", response.Reloaded)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.Reload(rr, authenticatedRequest(http.MethodPost, "/internal/config/reload", "", 5))
+	if rr.Code != http.StatusForbidden || reloader.calls != 1 {
+		t.Errorf("Expected status 403 for a non-admin without a reload, got %d (%d reloads)", rr.Code, reloader.calls)
+	}
+
+	handler = NewConfigReloadHandler(nil, authz.DefaultPolicy(), logger.New("test"))
+	rr = httptest.NewRecorder()
+	handler.Reload(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 without a secret backend, got %d", rr.Code)
+	}
+}
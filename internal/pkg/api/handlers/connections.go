@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// ConnectionStatusStore reads the status of a user's provider connections
+type ConnectionStatusStore interface {
+	GetConnections(ctx context.Context, userID int) ([]database.Connection, error)
+}
+
+// ConnectionsHandler reports the status of the user's Strava and Google Sheets connections
+type ConnectionsHandler struct {
+	store      ConnectionStatusStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewConnectionsHandler creates a new connections handler
+func NewConnectionsHandler(store ConnectionStatusStore, authorizer authz.Authorizer, logger *logger.Logger) *ConnectionsHandler {
+	return &ConnectionsHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "connections_handler"),
+	}
+}
+
+// ConnectionsResponse is the body of GET /api/v1/connections
+type ConnectionsResponse struct {
+	Connections []database.Connection `json:"connections"`
+}
+
+// List handles GET /api/v1/connections requests. It replaces the has_strava_connection and
+// has_sheets_connection booleans of the user payload.
+func (h *ConnectionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Config(subject.UserID)); err != nil {
+		h.logger.Warn("Connections request denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to view these connections")
+		return
+	}
+
+	connections, err := h.store.GetConnections(r.Context(), subject.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+			return
+		}
+		h.logger.Error("Failed to load connections",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load connections")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, ConnectionsResponse{Connections: connections})
+}
+
+func (h *ConnectionsHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode connections response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *ConnectionsHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
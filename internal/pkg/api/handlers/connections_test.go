@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockConnectionStatusStore struct {
+	connections []database.Connection
+	err         error
+}
+
+func (m *mockConnectionStatusStore) GetConnections(ctx context.Context, userID int) ([]database.Connection, error) {
+	return m.connections, m.err
+}
+
+func TestConnectionsHandler_List(t *testing.T) {
+	athlete := "Jane Runner"
+	store := &mockConnectionStatusStore{connections: []database.Connection{
+		{Provider: database.ProviderStrava, Connected: true, AccountName: &athlete, Scopes: []string{"read"}, ReauthRequired: true},
+		{Provider: database.ProviderGoogleSheets, Scopes: []string{}},
+	}}
+	handler := NewConnectionsHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.List(rr, authenticatedRequest(http.MethodGet, "/api/connections", "", 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response ConnectionsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Connections) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(response.Connections))
+	}
+	if strava := response.Connections[0]; !strava.Connected || !strava.ReauthRequired || *strava.AccountName != athlete {
+		t.Errorf("Unexpected Strava connection: %+v", strava)
+	}
+	if sheets := response.Connections[1]; sheets.Provider != database.ProviderGoogleSheets || sheets.Connected {
+		t.Errorf("Unexpected Google Sheets connection: %+v", sheets)
+	}
+}
+
+func TestConnectionsHandler_List_Errors(t *testing.T) {
+	handler := NewConnectionsHandler(&mockConnectionStatusStore{err: sql.ErrNoRows}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.List(rr, authenticatedRequest(http.MethodGet, "/api/connections", "", 1))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown user, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.List(rr, httptest.NewRequest(http.MethodGet, "/api/connections", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a user, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+// CookiePolicy holds the attributes of the cookies the API sets and the session lifetimes
+type CookiePolicy struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	// SessionTTL is the lifetime of a session and its refresh token cookie
+	SessionTTL time.Duration
+	// AccessTokenTTL is the lifetime of the access token cookie, matching the token's own expiry
+	AccessTokenTTL time.Duration
+	// RefreshThreshold is how close to expiry an access token must be before RefreshToken replaces it
+	RefreshThreshold time.Duration
+}
+
+// DefaultCookiePolicy is the policy used when none is configured: .localhost cookies over plain
+// HTTP in development, so the UI and API share cookies across ports, and Secure host-only cookies
+// elsewhere. SameSite=Lax lets cookies follow top-level navigation such as OAuth redirects.
+func DefaultCookiePolicy(isDevelopment bool) CookiePolicy {
+	policy := CookiePolicy{
+		Secure:           true,
+		SameSite:         http.SameSiteLaxMode,
+		SessionTTL:       24 * time.Hour,
+		AccessTokenTTL:   auth.DefaultTokenTTL,
+		RefreshThreshold: 24 * time.Hour,
+	}
+	if isDevelopment {
+		policy.Domain = ".localhost"
+		policy.Secure = false
+	}
+	return policy
+}
+
+// NewCookiePolicy resolves the configured session settings; "auto" values take the default for
+// the environment
+func NewCookiePolicy(cfg config.SessionConfig, isDevelopment bool) CookiePolicy {
+	policy := DefaultCookiePolicy(isDevelopment)
+	if cfg.CookieDomain != "auto" {
+		policy.Domain = cfg.CookieDomain
+	}
+	if cfg.CookieSecure != "auto" {
+		policy.Secure = cfg.CookieSecure == "true"
+	}
+	switch cfg.CookieSameSite {
+	case "strict":
+		policy.SameSite = http.SameSiteStrictMode
+	case "none":
+		policy.SameSite = http.SameSiteNoneMode
+	}
+	if cfg.TTL > 0 {
+		policy.SessionTTL = cfg.TTL
+	}
+	if cfg.AccessTokenTTL > 0 {
+		policy.AccessTokenTTL = cfg.AccessTokenTTL
+	}
+	if cfg.RefreshThreshold > 0 {
+		policy.RefreshThreshold = cfg.RefreshThreshold
+	}
+	return policy
+}
+
+// cookiePolicyOrDefault returns policy when one was configured, otherwise the environment default
+func cookiePolicyOrDefault(policy *CookiePolicy, isDevelopment bool) CookiePolicy {
+	if policy != nil {
+		return *policy
+	}
+	return DefaultCookiePolicy(isDevelopment)
+}
+
+// setCookie sets an HttpOnly cookie under the policy that expires after maxAge
+func (p CookiePolicy) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   p.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   p.Secure,
+		SameSite: p.SameSite,
+	})
+}
+
+// clearCookie tells the browser to delete a cookie set under the policy
+func (p CookiePolicy) clearCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		Domain:   p.Domain,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   p.Secure,
+		SameSite: p.SameSite,
+	})
+}
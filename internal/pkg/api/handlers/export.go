@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+const (
+	// defaultExportDays is the export range used when from is omitted
+	defaultExportDays = 90
+	// maxExportDays bounds a single export to keep Strava API usage predictable
+	maxExportDays = 366
+)
+
+// ActivityExporter fetches a user's activities for export
+type ActivityExporter interface {
+	GetActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error)
+}
+
+// ExportHandler handles activity export requests
+type ExportHandler struct {
+	exporter   ActivityExporter
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exporter ActivityExporter, authorizer authz.Authorizer, logger *logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		exporter:   exporter,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "export_handler"),
+	}
+}
+
+// ExportActivities handles GET /api/v1/activities/export?format=csv|json&from=YYYY-MM-DD&to=YYYY-MM-DD
+// The response is a file download; to is inclusive and defaults to today, from defaults to 90 days earlier
+func (h *ExportHandler) ExportActivities(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("ExportActivities called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionExport, authz.Activities(userID)); err != nil {
+		h.logger.Warn("ExportActivities denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to export these activities")
+		return
+	}
+
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = services.ExportFormatCSV
+	}
+	if format != services.ExportFormatCSV && format != services.ExportFormatJSON {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_FORMAT", "format must be csv or json")
+		return
+	}
+
+	from, to, err := parseExportRange(query.Get("from"), query.Get("to"), time.Now().UTC())
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_RANGE", err.Error())
+		return
+	}
+
+	h.logger.Info("Activity export requested",
+		"user_id", userID,
+		"format", format,
+		"from", from.Format("2006-01-02"),
+		"to", to.Format("2006-01-02"),
+		"client_ip", clientIP)
+
+	activities, err := h.exporter.GetActivities(r.Context(), userID, from, to)
+	if err != nil {
+		h.handleExportError(w, userID, err)
+		return
+	}
+
+	filename := fmt.Sprintf("activities_%s_%s.%s", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == services.ExportFormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		err = services.WriteActivitiesJSON(w, activities)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = services.WriteActivitiesCSV(w, activities)
+	}
+
+	if err != nil {
+		// Headers are already sent; the client sees a truncated download
+		h.logger.Error("Failed to stream activity export",
+			"error", err,
+			"user_id", userID,
+			"format", format)
+		return
+	}
+
+	h.logger.Info("Activity export completed",
+		"user_id", userID,
+		"format", format,
+		"activity_count", len(activities))
+}
+
+// parseExportRange parses inclusive YYYY-MM-DD bounds into a [from, to) time range
+func parseExportRange(fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toParam != "" {
+		parsed, err := time.Parse("2006-01-02", toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be a date in YYYY-MM-DD format")
+		}
+		to = parsed
+	}
+	// Include the whole of the end date
+	to = to.AddDate(0, 0, 1)
+
+	from := to.AddDate(0, 0, -defaultExportDays)
+	if fromParam != "" {
+		parsed, err := time.Parse("2006-01-02", fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be a date in YYYY-MM-DD format")
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+	if to.Sub(from) > maxExportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("export range cannot exceed %d days", maxExportDays)
+	}
+
+	return from, to, nil
+}
+
+// handleExportError maps export service errors to HTTP responses
+func (h *ExportHandler) handleExportError(w http.ResponseWriter, userID int, err error) {
+	var exportErr *services.ExportError
+	if !errors.As(err, &exportErr) {
+		h.logger.Error("Unexpected error exporting activities",
+			"error", err,
+			"user_id", userID)
+		statusCode, response := apierror.FromError(err)
+		if writeErr := apierror.Write(w, statusCode, response); writeErr != nil {
+			h.logger.Error("Failed to encode error response", "error", writeErr, "status_code", statusCode)
+		}
+		return
+	}
+
+	h.logger.Warn("Activity export failed",
+		"error_type", exportErr.Type,
+		"error", err,
+		"user_id", userID)
+
+	statusCode := http.StatusInternalServerError
+	switch exportErr.Type {
+	case services.ExportErrorNotConnected:
+		statusCode = http.StatusConflict
+	case services.ExportErrorReauthRequired:
+		statusCode = http.StatusUnauthorized
+	case services.ExportErrorStrava:
+		statusCode = http.StatusBadGateway
+	case services.ExportErrorTooLarge:
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	h.writeErrorResponse(w, statusCode, exportErr.Type, exportErr.Message)
+}
+
+func (h *ExportHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
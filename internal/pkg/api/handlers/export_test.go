@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type mockActivityExporter struct {
+	activities []strava.Activity
+	err        error
+	from, to   time.Time
+}
+
+func (m *mockActivityExporter) GetActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	m.from, m.to = from, to
+	return m.activities, m.err
+}
+
+func TestExportHandler_ExportActivities(t *testing.T) {
+	exporter := &mockActivityExporter{activities: []strava.Activity{
+		{ID: 1, Name: "Run", Type: "Run", Distance: 5000, StartDateLocal: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)},
+	}}
+	handler := NewExportHandler(exporter, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.ExportActivities(rr, authenticatedRequest(http.MethodGet, "/api/activities/export?format=csv&from=2024-06-01&to=2024-06-30", "", 5))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("Expected CSV content type, got %s", rr.Header().Get("Content-Type"))
+	}
+	if disposition := rr.Header().Get("Content-Disposition"); !strings.Contains(disposition, "activities_2024-06-01_2024-06-30.csv") {
+		t.Errorf("Unexpected Content-Disposition: %s", disposition)
+	}
+	if lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n"); len(lines) != 2 {
+		t.Errorf("Expected header and 1 row, got %d lines", len(lines))
+	}
+
+	// The end date is inclusive
+	if !exporter.to.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected exclusive upper bound of 2024-07-01, got %s", exporter.to)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ExportActivities(rr, authenticatedRequest(http.MethodGet, "/api/activities/export?format=json&from=2024-06-01&to=2024-06-30", "", 5))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON export, got status %d and type %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		exportErr      error
+		expectedStatus int
+	}{
+		{"Unsupported format", "format=xlsx", nil, http.StatusBadRequest},
+		{"Invalid date", "from=June", nil, http.StatusBadRequest},
+		{"Reversed range", "from=2024-06-30&to=2024-06-01", nil, http.StatusBadRequest},
+		{"Range too long", "from=2020-01-01&to=2024-01-01", nil, http.StatusBadRequest},
+		{"Strava not connected", "", &services.ExportError{Type: services.ExportErrorNotConnected}, http.StatusConflict},
+		{"Strava reauth", "", &services.ExportError{Type: services.ExportErrorReauthRequired}, http.StatusUnauthorized},
+		{"Too many activities", "", &services.ExportError{Type: services.ExportErrorTooLarge}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewExportHandler(&mockActivityExporter{err: tt.exportErr}, authz.DefaultPolicy(), logger.New("test"))
+
+			rr := httptest.NewRecorder()
+			handler.ExportActivities(rr, authenticatedRequest(http.MethodGet, "/api/activities/export?"+tt.query, "", 5))
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportHandler_ErrorHelp(t *testing.T) {
+	handler := NewExportHandler(&mockActivityExporter{err: &services.ExportError{Type: services.ExportErrorReauthRequired, Message: "Reconnect Strava"}}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.ExportActivities(rr, authenticatedRequest(http.MethodGet, "/api/activities/export", "", 5))
+
+	var response ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Error.Remediation != "reconnect_strava" || response.Error.HelpURL != "/dashboard#strava" {
+		t.Errorf("Expected help to reconnect Strava, got %+v", response)
+	}
+
+	// Generic codes carry no help
+	rr = httptest.NewRecorder()
+	handler.ExportActivities(rr, authenticatedRequest(http.MethodGet, "/api/activities/export?format=xlsx", "", 5))
+	if strings.Contains(rr.Body.String(), "remediation") || strings.Contains(rr.Body.String(), "help_url") {
+		t.Errorf("Expected no help for a generic error, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// GoogleConnectionStore reads and clears a user's Google Sheets connection
+type GoogleConnectionStore interface {
+	GetDecryptedGoogleTokens(ctx context.Context, userID int) (accessToken, refreshToken string, expiry *time.Time, err error)
+	DisconnectGoogleSheets(ctx context.Context, userID int) error
+	GetUserByID(ctx context.Context, id int) (*database.User, error)
+}
+
+// GoogleTokenRevoker revokes Google OAuth tokens
+type GoogleTokenRevoker interface {
+	RevokeGoogleToken(ctx context.Context, token string) error
+}
+
+// GoogleConnectionHandler lets users withdraw the Google Sheets authorization used by automation
+type GoogleConnectionHandler struct {
+	store      GoogleConnectionStore
+	revoker    GoogleTokenRevoker
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewGoogleConnectionHandler creates a new Google connection handler
+func NewGoogleConnectionHandler(store GoogleConnectionStore, revoker GoogleTokenRevoker, authorizer authz.Authorizer, logger *logger.Logger) *GoogleConnectionHandler {
+	return &GoogleConnectionHandler{
+		store:      store,
+		revoker:    revoker,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "google_connection_handler"),
+	}
+}
+
+// DisconnectGoogleSheetsResponse reports the user's connections after disconnecting
+type DisconnectGoogleSheetsResponse struct {
+	Message string `json:"message"`
+	// Revoked is false when Google could not be reached; the user can revoke access from their
+	// Google account settings
+	Revoked     bool                 `json:"revoked"`
+	Connections *database.PublicUser `json:"connections"`
+}
+
+// DisconnectGoogleSheets handles DELETE /api/v1/connections/google-sheets. It clears the
+// spreadsheet, revokes and deletes the stored Google tokens and disables automation.
+//
+// Google issues a single grant for sign-in and the Sheets and Drive scopes, so the Sheets access
+// cannot be revoked on its own and the whole grant is. Sessions do not depend on the Google
+// tokens, so the user stays signed in; signing in again grants Sheets access anew.
+func (h *GoogleConnectionHandler) DisconnectGoogleSheets(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("DisconnectGoogleSheets called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("DisconnectGoogleSheets denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration")
+		return
+	}
+
+	// Revoke before clearing the tokens; once they are deleted the grant can no longer be revoked
+	revoked := h.revokeGoogleGrant(r.Context(), userID)
+
+	if err := h.store.DisconnectGoogleSheets(r.Context(), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+			return
+		}
+		h.logger.Error("Failed to disconnect Google Sheets",
+			"error", err,
+			"user_id", userID,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to disconnect Google Sheets")
+		return
+	}
+
+	user, err := h.store.GetUserByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.logger.Error("Failed to load user after disconnecting Google Sheets",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load connections")
+		return
+	}
+
+	h.logger.Info("Successfully disconnected Google Sheets",
+		"user_id", userID,
+		"revoked", revoked,
+		"client_ip", clientIP)
+	h.writeJSON(w, http.StatusOK, DisconnectGoogleSheetsResponse{
+		Message:     "Google Sheets disconnected successfully",
+		Revoked:     revoked,
+		Connections: user.ToPublicUser(),
+	})
+}
+
+// revokeGoogleGrant revokes the user's Google grant, reporting whether it did. Revoking the
+// refresh token ends the grant and every access token issued from it. Failures are logged and
+// do not stop the disconnect.
+func (h *GoogleConnectionHandler) revokeGoogleGrant(ctx context.Context, userID int) bool {
+	accessToken, refreshToken, _, err := h.store.GetDecryptedGoogleTokens(ctx, userID)
+	if err != nil {
+		h.logger.Warn("Could not load Google tokens to revoke, disconnecting locally only",
+			"error", err,
+			"user_id", userID)
+		return false
+	}
+
+	token := refreshToken
+	if token == "" {
+		token = accessToken
+	}
+	if token == "" {
+		return false // Nothing stored to revoke
+	}
+
+	if err := h.revoker.RevokeGoogleToken(ctx, token); err != nil {
+		h.logger.Warn("Failed to revoke Google authorization, disconnecting locally only",
+			"error", err,
+			"user_id", userID)
+		return false
+	}
+	h.logger.Info("Revoked Google authorization", "user_id", userID)
+	return true
+}
+
+func (h *GoogleConnectionHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode Google connection response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *GoogleConnectionHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
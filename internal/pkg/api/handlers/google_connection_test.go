@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockGoogleConnectionStore struct {
+	user         *database.User
+	refreshToken string
+}
+
+func (m *mockGoogleConnectionStore) GetDecryptedGoogleTokens(ctx context.Context, userID int) (string, string, *time.Time, error) {
+	return "access", m.refreshToken, nil, nil
+}
+
+func (m *mockGoogleConnectionStore) DisconnectGoogleSheets(ctx context.Context, userID int) error {
+	m.user.SpreadsheetID = nil
+	m.user.GoogleAccessToken = nil
+	m.user.GoogleRefreshToken = nil
+	m.user.AutomationEnabled = false
+	return nil
+}
+
+func (m *mockGoogleConnectionStore) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return m.user, nil
+}
+
+type mockGoogleRevoker struct {
+	revoked []string
+	err     error
+}
+
+func (m *mockGoogleRevoker) RevokeGoogleToken(ctx context.Context, token string) error {
+	m.revoked = append(m.revoked, token)
+	return m.err
+}
+
+func TestGoogleConnectionHandler_DisconnectGoogleSheets(t *testing.T) {
+	newStore := func() *mockGoogleConnectionStore {
+		spreadsheetID := "sheet-123"
+		return &mockGoogleConnectionStore{
+			user:         &database.User{ID: 1, SpreadsheetID: &spreadsheetID, AutomationEnabled: true, StravaAccessToken: []byte("x")},
+			refreshToken: "refresh",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		revokeErr   error
+		wantRevoked bool
+	}{
+		{"Revoked", nil, true},
+		// The connection is cleared locally even when Google cannot be reached
+		{"Revoke fails", errors.New("status 503"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore()
+			revoker := &mockGoogleRevoker{err: tt.revokeErr}
+			handler := NewGoogleConnectionHandler(store, revoker, authz.DefaultPolicy(), logger.New("test"))
+
+			rr := httptest.NewRecorder()
+			handler.DisconnectGoogleSheets(rr, authenticatedRequest(http.MethodDelete, "/api/connections/google-sheets", "", 1))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			var response DisconnectGoogleSheetsResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Revoked != tt.wantRevoked {
+				t.Errorf("Expected revoked=%v, got %v", tt.wantRevoked, response.Revoked)
+			}
+			if response.Connections.HasSheetsConnection || response.Connections.AutomationEnabled || !response.Connections.HasStravaConnection {
+				t.Errorf("Unexpected connections after disconnect: %+v", response.Connections)
+			}
+			// The refresh token is revoked, ending the whole grant
+			if len(revoker.revoked) != 1 || revoker.revoked[0] != "refresh" {
+				t.Errorf("Expected the refresh token to be revoked, got %v", revoker.revoked)
+			}
+		})
+	}
+}
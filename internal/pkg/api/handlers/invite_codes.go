@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+const (
+	// maxInviteNoteLength matches the invite_codes.note column
+	maxInviteNoteLength = 255
+	// maxInviteLifetimeDays bounds expires_in_days; codes may also be created without expiry
+	maxInviteLifetimeDays = 90
+)
+
+// InviteCodeStore creates, lists and revokes signup invite codes
+type InviteCodeStore interface {
+	CreateInviteCode(ctx context.Context, code *database.InviteCode) error
+	ListInviteCodes(ctx context.Context) ([]database.InviteCode, error)
+	RevokeInviteCode(ctx context.Context, id int) error
+}
+
+// InviteCodeHandler lets admins hand out the single-use codes required to sign up when
+// SIGNUP_POLICY is invite
+type InviteCodeHandler struct {
+	store      InviteCodeStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewInviteCodeHandler creates a new invite code handler
+func NewInviteCodeHandler(store InviteCodeStore, authorizer authz.Authorizer, logger *logger.Logger) *InviteCodeHandler {
+	return &InviteCodeHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "invite_code_handler"),
+	}
+}
+
+// CreateInviteCodeRequest creates an invite code
+type CreateInviteCodeRequest struct {
+	Note          string `json:"note"`
+	ExpiresInDays int    `json:"expires_in_days"` // 0 creates a code that never expires
+}
+
+// Validate checks the note length and lifetime
+func (req *CreateInviteCodeRequest) Validate(v *validate.Validator) {
+	v.MaxLength("note", req.Note, maxInviteNoteLength)
+	v.Check(req.ExpiresInDays >= 0 && req.ExpiresInDays <= maxInviteLifetimeDays,
+		"expires_in_days", validate.CodeInvalid, "expires_in_days must be between 0 and 90")
+}
+
+// CreateInviteCodeResponse returns a new code; the plaintext code is never shown again
+type CreateInviteCodeResponse struct {
+	database.InviteCode
+	Code string `json:"code"`
+}
+
+// InviteCodesResponse lists every invite code
+type InviteCodesResponse struct {
+	InviteCodes []database.InviteCode `json:"invite_codes"`
+}
+
+// Create handles POST /api/v1/admin/invite-codes with {"note", "expires_in_days"}
+func (h *InviteCodeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req CreateInviteCodeRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	plaintext, prefix, hash, err := auth.NewInviteCode()
+	if err != nil {
+		h.logger.Error("Failed to generate invite code",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create invite code")
+		return
+	}
+
+	createdBy := subject.UserID
+	code := &database.InviteCode{
+		CodeHash:   hash,
+		CodePrefix: prefix,
+		Note:       req.Note,
+		CreatedBy:  &createdBy,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		code.ExpiresAt = &expiresAt
+	}
+	if err := h.store.CreateInviteCode(r.Context(), code); err != nil {
+		h.logger.Error("Failed to store invite code",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create invite code")
+		return
+	}
+
+	h.logger.Info("Invite code created",
+		"user_id", subject.UserID,
+		"invite_code_id", code.ID,
+		"code_prefix", code.CodePrefix,
+		"expires_at", code.ExpiresAt)
+	h.writeJSON(w, http.StatusCreated, CreateInviteCodeResponse{InviteCode: *code, Code: plaintext})
+}
+
+// List handles GET /api/v1/admin/invite-codes, returning every code without its secret
+func (h *InviteCodeHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	codes, err := h.store.ListInviteCodes(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list invite codes",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list invite codes")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, InviteCodesResponse{InviteCodes: codes})
+}
+
+// Revoke handles DELETE /api/v1/admin/invite-codes/{id}; only unused codes can be revoked
+func (h *InviteCodeHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid invite code ID is required")
+		return
+	}
+
+	if err := h.store.RevokeInviteCode(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "No unused invite code with that ID")
+			return
+		}
+		h.logger.Error("Failed to revoke invite code",
+			"error", err,
+			"user_id", subject.UserID,
+			"invite_code_id", id)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke invite code")
+		return
+	}
+
+	h.logger.Info("Invite code revoked",
+		"user_id", subject.UserID,
+		"invite_code_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize checks that the caller is an admin, writing the error response if not
+func (h *InviteCodeHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Invite codes called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.InviteCodes()); err != nil {
+		h.logger.Warn("Invite codes denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may manage invite codes")
+		return subject, false
+	}
+	return subject, true
+}
+
+func (h *InviteCodeHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode invite code response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *InviteCodeHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockInviteCodeStore struct {
+	codes []database.InviteCode
+}
+
+func (m *mockInviteCodeStore) CreateInviteCode(ctx context.Context, code *database.InviteCode) error {
+	code.ID = len(m.codes) + 1
+	m.codes = append(m.codes, *code)
+	return nil
+}
+
+func (m *mockInviteCodeStore) ListInviteCodes(ctx context.Context) ([]database.InviteCode, error) {
+	return m.codes, nil
+}
+
+func (m *mockInviteCodeStore) RevokeInviteCode(ctx context.Context, id int) error {
+	for i := range m.codes {
+		if m.codes[i].ID == id && m.codes[i].RevokedAt == nil {
+			now := m.codes[i].CreatedAt
+			m.codes[i].RevokedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func TestInviteCodeHandler(t *testing.T) {
+	store := &mockInviteCodeStore{}
+	handler := NewInviteCodeHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/invite-codes", handler.List)
+	router.Post("/api/admin/invite-codes", handler.Create)
+	router.Delete("/api/admin/invite-codes/{id}", handler.Revoke)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := authenticatedRequest(http.MethodPost, "/api/admin/invite-codes", body, 1)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, []authz.Role{authz.RoleAthlete, authz.RoleAdmin}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := create(`{"expires_in_days": 365}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a year-long code, got %d", rr.Code)
+	}
+	rr := create(`{"note": "Spring intake", "expires_in_days": 14}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created CreateInviteCodeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(created.Code, auth.InviteCodePrefix) || created.ExpiresAt == nil {
+		t.Errorf("Unexpected invite code: %+v", created)
+	}
+	if len(store.codes) != 1 || store.codes[0].CodeHash != auth.HashInviteCode(created.Code) || *store.codes[0].CreatedBy != 1 {
+		t.Errorf("Expected only the hash to be stored with its creator: %+v", store.codes)
+	}
+	if strings.Contains(rr.Body.String(), store.codes[0].CodeHash) {
+		t.Error("Expected the code hash to be left out of the response")
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("/api/admin/invite-codes"))
+	var response InviteCodesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || len(response.InviteCodes) != 1 {
+		t.Fatalf("Expected one invite code, got %d (%v)", rr.Code, err)
+	}
+
+	// Athletes may not hand out invite codes
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, authenticatedRequest(http.MethodPost, "/api/admin/invite-codes", `{}`, 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+
+	revoke := func(id string) int {
+		req := adminRequest("/api/admin/invite-codes/" + id)
+		req.Method = http.MethodDelete
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := revoke("1"); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if code := revoke("1"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a revoked code, got %d", code)
+	}
+	if code := revoke("abc"); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a malformed ID, got %d", code)
+	}
+}
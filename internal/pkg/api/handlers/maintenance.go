@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+const (
+	// ErrorCodeMaintenance is returned by every gated route while maintenance mode is on
+	ErrorCodeMaintenance = "MAINTENANCE_MODE"
+
+	// maintenanceCheckInterval is how long the gate reuses the maintenance state it last read, so
+	// requests do not each read Redis; other instances notice a change within this interval
+	maintenanceCheckInterval = 5 * time.Second
+
+	// maxMaintenanceMessageLength bounds the message shown in the web app's banner
+	maxMaintenanceMessageLength = 500
+
+	defaultMaintenanceMessage = "Academy Sync is down for scheduled maintenance, please try again shortly"
+)
+
+// MaintenanceStore turns the global maintenance switch on and off
+type MaintenanceStore interface {
+	SetMaintenance(ctx context.Context, maintenance *queue.Maintenance) error
+	ClearMaintenance(ctx context.Context) (bool, error)
+	GetMaintenance(ctx context.Context) (*queue.Maintenance, error)
+}
+
+// MaintenanceHandler lets admins take the service down for operator work such as schema
+// migrations, serves the web app's maintenance banner and refuses other requests meanwhile
+type MaintenanceHandler struct {
+	store      MaintenanceStore // nil without Redis, when maintenance mode is unavailable
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+
+	mu        sync.Mutex
+	current   *queue.Maintenance
+	checkedAt time.Time
+}
+
+// NewMaintenanceHandler creates a new maintenance handler; store may be nil
+func NewMaintenanceHandler(store MaintenanceStore, authorizer authz.Authorizer, logger *logger.Logger) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "maintenance_handler"),
+	}
+}
+
+// StartMaintenanceRequest turns maintenance mode on
+type StartMaintenanceRequest struct {
+	Message string     `json:"message"`
+	EndsAt  *time.Time `json:"ends_at"` // Optional expected end, shown in the banner
+}
+
+// Validate checks the message length and that the expected end is in the future
+func (req *StartMaintenanceRequest) Validate(v *validate.Validator) {
+	v.MaxLength("message", req.Message, maxMaintenanceMessageLength)
+	if req.EndsAt != nil {
+		v.Check(req.EndsAt.After(time.Now()), "ends_at", validate.CodeInvalid, "ends_at must be in the future")
+	}
+}
+
+// MaintenanceStatusResponse is the body of GET /api/v1/maintenance
+type MaintenanceStatusResponse struct {
+	Active    bool       `json:"active"`
+	Message   string     `json:"message,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+// Status handles GET /api/v1/maintenance (public), which the web app polls to show its banner
+func (h *MaintenanceHandler) Status(w http.ResponseWriter, r *http.Request) {
+	response := MaintenanceStatusResponse{}
+	if maintenance := h.active(r.Context()); maintenance != nil {
+		startedAt := maintenance.StartedAt
+		response = MaintenanceStatusResponse{
+			Active:    true,
+			Message:   maintenanceMessage(maintenance),
+			StartedAt: &startedAt,
+			EndsAt:    maintenance.EndsAt,
+		}
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// Start handles PUT /api/v1/admin/maintenance with {"message", "ends_at"}
+func (h *MaintenanceHandler) Start(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	if h.store == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Maintenance mode requires Redis")
+		return
+	}
+
+	var req StartMaintenanceRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	maintenance := &queue.Maintenance{
+		Message:   strings.TrimSpace(req.Message),
+		StartedAt: time.Now().UTC(),
+		StartedBy: subject.UserID,
+		EndsAt:    req.EndsAt,
+	}
+	if err := h.store.SetMaintenance(r.Context(), maintenance); err != nil {
+		h.logger.Error("Failed to start maintenance",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to start maintenance")
+		return
+	}
+	h.remember(maintenance)
+
+	h.logger.Warn("Maintenance mode started",
+		"user_id", subject.UserID,
+		"message", maintenance.Message,
+		"ends_at", maintenance.EndsAt)
+	h.writeJSON(w, http.StatusOK, maintenance)
+}
+
+// Stop handles DELETE /api/v1/admin/maintenance
+func (h *MaintenanceHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	if h.store == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Maintenance mode requires Redis")
+		return
+	}
+
+	wasOn, err := h.store.ClearMaintenance(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to stop maintenance",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to stop maintenance")
+		return
+	}
+	h.remember(nil)
+
+	h.logger.Warn("Maintenance mode stopped",
+		"user_id", subject.UserID,
+		"was_on", wasOn)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Middleware refuses requests with 503 MAINTENANCE_MODE while maintenance is on, except for
+// paths under one of the exempt prefixes (admin routes, sign-in, health checks and the banner)
+func (h *MaintenanceHandler) Middleware(exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hasPathPrefix(r.URL.Path, exempt) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			maintenance := h.active(r.Context())
+			if maintenance == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.writeMaintenance(w, maintenance)
+		})
+	}
+}
+
+// active returns the maintenance in progress, reading it at most every maintenanceCheckInterval.
+// A failed read is treated as no maintenance, so a Redis outage does not take the API down.
+func (h *MaintenanceHandler) active(ctx context.Context) *queue.Maintenance {
+	if h.store == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Since(h.checkedAt) < maintenanceCheckInterval {
+		return h.current
+	}
+
+	maintenance, err := h.store.GetMaintenance(ctx)
+	if err != nil {
+		h.logger.Warn("Failed to read maintenance state, serving requests",
+			"error", err)
+		maintenance = nil
+	}
+	h.current, h.checkedAt = maintenance, time.Now()
+	return h.current
+}
+
+// remember caches a change made through this instance so it applies immediately
+func (h *MaintenanceHandler) remember(maintenance *queue.Maintenance) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.current, h.checkedAt = maintenance, time.Now()
+}
+
+func (h *MaintenanceHandler) writeMaintenance(w http.ResponseWriter, maintenance *queue.Maintenance) {
+	response := newErrorResponse(ErrorCodeMaintenance, maintenanceMessage(maintenance))
+	if maintenance.EndsAt != nil {
+		if retryAfter := time.Until(*maintenance.EndsAt).Round(time.Second); retryAfter >= time.Second {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+		}
+		response = response.WithDetail("ends_at", *maintenance.EndsAt)
+	}
+	if err := apierror.Write(w, http.StatusServiceUnavailable, response); err != nil {
+		h.logger.Error("Failed to encode error response", "error", err, "error_code", ErrorCodeMaintenance)
+	}
+}
+
+// maintenanceMessage is the operator's message, or a generic one when they gave none
+func maintenanceMessage(maintenance *queue.Maintenance) string {
+	if maintenance.Message == "" {
+		return defaultMaintenanceMessage
+	}
+	return maintenance.Message
+}
+
+// hasPathPrefix reports whether path is one of prefixes or lies beneath one
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks that the caller is an admin, writing the error response if not
+func (h *MaintenanceHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Maintenance called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.Maintenance()); err != nil {
+		h.logger.Warn("Maintenance denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may turn maintenance mode on or off")
+		return subject, false
+	}
+	return subject, true
+}
+
+func (h *MaintenanceHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode maintenance response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *MaintenanceHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
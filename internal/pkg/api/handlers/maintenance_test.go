@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+type mockMaintenanceStore struct {
+	maintenance *queue.Maintenance
+	reads       int
+}
+
+func (m *mockMaintenanceStore) SetMaintenance(ctx context.Context, maintenance *queue.Maintenance) error {
+	m.maintenance = maintenance
+	return nil
+}
+
+func (m *mockMaintenanceStore) ClearMaintenance(ctx context.Context) (bool, error) {
+	wasOn := m.maintenance != nil
+	m.maintenance = nil
+	return wasOn, nil
+}
+
+func (m *mockMaintenanceStore) GetMaintenance(ctx context.Context) (*queue.Maintenance, error) {
+	m.reads++
+	return m.maintenance, nil
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	store := &mockMaintenanceStore{}
+	handler := NewMaintenanceHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Use(handler.Middleware("/health", "/api/admin", "/api/maintenance"))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.Get("/health", ok)
+	router.Get("/api/config", ok)
+	router.Get("/api/maintenance", handler.Status)
+	router.Put("/api/admin/maintenance", handler.Start)
+	router.Delete("/api/admin/maintenance", handler.Stop)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, authenticatedRequest(http.MethodGet, target, "", 5))
+		return rr
+	}
+	asAdmin := func(method, body string) int {
+		req := authenticatedRequest(method, "/api/admin/maintenance", body, 1)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, []authz.Role{authz.RoleAthlete, authz.RoleAdmin}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if rr := get("/api/config"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected requests to be served outside maintenance, got %d", rr.Code)
+	}
+
+	// Athletes may not take the service down
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, authenticatedRequest(http.MethodPut, "/api/admin/maintenance", `{}`, 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+
+	endsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if code := asAdmin(http.MethodPut, `{"message": "Database upgrade", "ends_at": "`+endsAt.Format(time.RFC3339)+`"}`); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if store.maintenance == nil || store.maintenance.StartedBy != 1 {
+		t.Fatalf("Expected maintenance to be stored with who started it: %+v", store.maintenance)
+	}
+
+	rr = get("/api/config")
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("Expected status 503 with Retry-After during maintenance, got %d", rr.Code)
+	}
+	var refused ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&refused); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if refused.Error.Code != ErrorCodeMaintenance || refused.Error.Message != "Database upgrade" || refused.Error.Remediation != "retry_later" {
+		t.Errorf("Unexpected response: %+v", refused)
+	}
+
+	if rr := get("/health"); rr.Code != http.StatusOK {
+		t.Errorf("Expected exempt routes to be served during maintenance, got %d", rr.Code)
+	}
+	rr = get("/api/maintenance")
+	var status MaintenanceStatusResponse
+	if err := json.NewDecoder(rr.Body).Decode(&status); err != nil || !status.Active || status.EndsAt == nil || !status.EndsAt.Equal(endsAt) {
+		t.Errorf("Expected the banner to report maintenance, got %+v (%v)", status, err)
+	}
+
+	// The state is read once per interval rather than on every request
+	if store.reads > 1 {
+		t.Errorf("Expected the maintenance state to be cached, got %d reads", store.reads)
+	}
+
+	if code := asAdmin(http.MethodDelete, ""); code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", code)
+	}
+	if rr := get("/api/config"); rr.Code != http.StatusOK {
+		t.Errorf("Expected requests to be served after maintenance, got %d", rr.Code)
+	}
+}
+
+func TestMaintenanceHandler_WithoutStore(t *testing.T) {
+	handler := NewMaintenanceHandler(nil, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected requests to be served without Redis, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	req := adminRequest("/api/admin/maintenance")
+	req.Method = http.MethodPut
+	handler.Start(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected maintenance mode to be unavailable without Redis, got %d", rr.Code)
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	prefixes := []string{"/api/v1/admin", "/health"}
+	for path, want := range map[string]bool{
+		"/api/v1/admin":             true,
+		"/api/v1/admin/maintenance": true,
+		"/api/v1/administrators":    false,
+		"/health":                   true,
+		"/api/v1/config":            false,
+	} {
+		if got := hasPathPrefix(path, prefixes); got != want {
+			t.Errorf("hasPathPrefix(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// maxManualActivityDuration bounds the elapsed time of a manual activity to catch unit mistakes
+const maxManualActivityDuration = 48 * time.Hour
+
+// ManualActivityStore stores the activities users log by hand
+type ManualActivityStore interface {
+	CreateManualActivity(ctx context.Context, userID int, activity *strava.Activity) error
+}
+
+// ManualActivityHandler lets users log activities that were not recorded on Strava
+type ManualActivityHandler struct {
+	store      ManualActivityStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewManualActivityHandler creates a new manual activity handler
+func NewManualActivityHandler(store ManualActivityStore, authorizer authz.Authorizer, logger *logger.Logger) *ManualActivityHandler {
+	return &ManualActivityHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "manual_activity_handler"),
+	}
+}
+
+// CreateManualActivityRequest describes an activity in the units of Strava's activity API
+type CreateManualActivityRequest struct {
+	Name               string    `json:"name"`
+	Type               string    `json:"type"`       // e.g. Run, Ride, Swim
+	SportType          string    `json:"sport_type"` // Defaults to type
+	StartDate          time.Time `json:"start_date"` // RFC 3339 with the athlete's UTC offset, e.g. 2024-06-12T07:00:00+03:00
+	ElapsedTime        int       `json:"elapsed_time"`
+	MovingTime         int       `json:"moving_time"` // Defaults to elapsed_time
+	Distance           float64   `json:"distance"`
+	TotalElevationGain float64   `json:"total_elevation_gain"`
+	AverageHeartrate   float64   `json:"average_heartrate"`
+	MaxHeartrate       float64   `json:"max_heartrate"`
+}
+
+// Validate checks the activity is complete, has plausible values and did not start in the future
+func (req *CreateManualActivityRequest) Validate(v *validate.Validator) {
+	v.Required("name", req.Name, "name is required")
+	v.MaxLength("name", req.Name, 255)
+	v.Required("type", req.Type, "type is required")
+	v.MaxLength("type", req.Type, 64)
+	v.MaxLength("sport_type", req.SportType, 64)
+	if v.Check(!req.StartDate.IsZero(), "start_date", validate.CodeRequired, "start_date is required") {
+		v.Check(!req.StartDate.After(time.Now()), "start_date", validate.CodeInvalid, "start_date must not be in the future")
+	}
+	v.Check(req.ElapsedTime > 0 && time.Duration(req.ElapsedTime)*time.Second <= maxManualActivityDuration,
+		"elapsed_time", validate.CodeInvalid, "elapsed_time must be between 1 second and 48 hours")
+	v.Check(req.MovingTime >= 0 && req.MovingTime <= req.ElapsedTime,
+		"moving_time", validate.CodeInvalid, "moving_time must not exceed elapsed_time")
+	v.Check(req.Distance >= 0, "distance", validate.CodeInvalid, "distance must not be negative")
+	v.Check(req.TotalElevationGain >= 0, "total_elevation_gain", validate.CodeInvalid, "total_elevation_gain must not be negative")
+	v.Check(req.AverageHeartrate >= 0 && req.MaxHeartrate >= 0, "average_heartrate", validate.CodeInvalid, "heart rates must not be negative")
+}
+
+// Activity converts the request into the activity written to the cache and the sheet
+func (req *CreateManualActivityRequest) Activity() strava.Activity {
+	sportType := req.SportType
+	if sportType == "" {
+		sportType = req.Type
+	}
+	movingTime := req.MovingTime
+	if movingTime == 0 {
+		movingTime = req.ElapsedTime
+	}
+
+	// Like Strava, the local start date is the athlete's wall clock time expressed in UTC
+	local := req.StartDate
+	activity := strava.Activity{
+		Name:               req.Name,
+		Type:               req.Type,
+		SportType:          sportType,
+		Distance:           req.Distance,
+		MovingTime:         movingTime,
+		ElapsedTime:        req.ElapsedTime,
+		TotalElevationGain: req.TotalElevationGain,
+		StartDate:          req.StartDate.UTC(),
+		StartDateLocal:     time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC),
+		AverageHeartrate:   req.AverageHeartrate,
+		MaxHeartrate:       req.MaxHeartrate,
+	}
+	if movingTime > 0 {
+		activity.AverageSpeed = req.Distance / float64(movingTime)
+	}
+	return activity
+}
+
+// Create handles POST /api/v1/activities/manual
+// The activity is cached like a fetched one and written to the sheet by the next sync covering its date
+func (h *ManualActivityHandler) Create(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.logger.Warn("CreateManualActivity called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Activities(userID)); err != nil {
+		h.logger.Warn("CreateManualActivity denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to add activities for this user")
+		return
+	}
+
+	var req CreateManualActivityRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	activity := req.Activity()
+	if err := h.store.CreateManualActivity(r.Context(), userID, &activity); err != nil {
+		h.logger.Error("Failed to store manual activity",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to save activity")
+		return
+	}
+
+	h.logger.Info("Manual activity logged",
+		"user_id", userID,
+		"activity_id", activity.ID,
+		"type", activity.Type,
+		"start_date", activity.StartDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(activity); err != nil {
+		h.logger.Error("Failed to encode manual activity response",
+			"error", err,
+			"user_id", userID)
+	}
+}
+
+func (h *ManualActivityHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
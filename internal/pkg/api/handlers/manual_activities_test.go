@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+type mockManualActivityStore struct {
+	created []strava.Activity
+	err     error
+}
+
+func (m *mockManualActivityStore) CreateManualActivity(ctx context.Context, userID int, activity *strava.Activity) error {
+	if m.err != nil {
+		return m.err
+	}
+	activity.ID = -int64(len(m.created) + 1)
+	activity.Source = "manual"
+	m.created = append(m.created, *activity)
+	return nil
+}
+
+func TestManualActivityHandler_Create(t *testing.T) {
+	store := &mockManualActivityStore{}
+	handler := NewManualActivityHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	body := `{"name": "Treadmill intervals", "type": "Run", "start_date": "2024-06-12T07:00:00+03:00", "elapsed_time": 3000, "distance": 10000}`
+	rr := httptest.NewRecorder()
+	handler.Create(rr, authenticatedRequest(http.MethodPost, "/api/activities/manual", body, 5))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var decoded strava.Activity
+	if err := json.NewDecoder(rr.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if decoded.ID != -1 || decoded.Source != "manual" {
+		t.Errorf("Expected the stored activity in the response, got %+v", decoded)
+	}
+
+	activity := store.created[0]
+	if !activity.StartDate.Equal(time.Date(2024, 6, 12, 4, 0, 0, 0, time.UTC)) ||
+		!activity.StartDateLocal.Equal(time.Date(2024, 6, 12, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a UTC start and the athlete's wall clock start, got %v and %v", activity.StartDate, activity.StartDateLocal)
+	}
+	if activity.SportType != "Run" || activity.MovingTime != 3000 || activity.AverageSpeed != 10000.0/3000 {
+		t.Errorf("Expected defaults from type and elapsed time, got %+v", activity)
+	}
+}
+
+func TestManualActivityHandler_Create_Rejected(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Missing name", `{"type": "Run", "start_date": "2024-06-12T07:00:00Z", "elapsed_time": 600}`},
+		{"Future start", `{"name": "Run", "type": "Run", "start_date": "` + future + `", "elapsed_time": 600}`},
+		{"No duration", `{"name": "Run", "type": "Run", "start_date": "2024-06-12T07:00:00Z"}`},
+		{"Moving longer than elapsed", `{"name": "Run", "type": "Run", "start_date": "2024-06-12T07:00:00Z", "elapsed_time": 600, "moving_time": 900}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockManualActivityStore{}
+			handler := NewManualActivityHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, authenticatedRequest(http.MethodPost, "/api/activities/manual", tt.body, 5))
+			if rr.Code != http.StatusBadRequest || len(store.created) != 0 {
+				t.Errorf("Expected status 400 and nothing stored, got %d with %d stored", rr.Code, len(store.created))
+			}
+		})
+	}
+
+	handler := NewManualActivityHandler(&mockManualActivityStore{err: errors.New("db down")}, authz.DefaultPolicy(), logger.New("test"))
+	rr := httptest.NewRecorder()
+	handler.Create(rr, authenticatedRequest(http.MethodPost, "/api/activities/manual",
+		`{"name": "Run", "type": "Run", "start_date": "2024-06-12T07:00:00Z", "elapsed_time": 600}`, 5))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// APIDeprecations lists the endpoints and response fields slated for removal
+func APIDeprecations() *middleware.Deprecations {
+	deprecatedAt := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	return middleware.NewDeprecations(
+		middleware.Deprecation{
+			Method:       "*",
+			Path:         "/api/*",
+			DeprecatedAt: deprecatedAt,
+			Sunset:       &sunset,
+			Replacement:  "/api/v1/*",
+			Notes:        "Unversioned paths are aliases of /api/v1, except the OAuth callbacks registered with Google and Strava",
+		},
+		middleware.Deprecation{
+			Method:       http.MethodGet,
+			Path:         "/api/v1/users/me",
+			DeprecatedAt: deprecatedAt,
+			Sunset:       &sunset,
+			Replacement:  "/api/v1/auth/me",
+			Notes:        "Duplicate of GET /api/v1/auth/me",
+		},
+		middleware.Deprecation{
+			Method:       http.MethodGet,
+			Path:         "/api/v1/auth/me",
+			Field:        "recent_activity_logs",
+			DeprecatedAt: deprecatedAt,
+			Sunset:       &sunset,
+			Replacement:  "/api/v1/stats",
+			Notes:        "Always empty; dashboard activity comes from GET /api/v1/stats",
+		},
+		middleware.Deprecation{
+			Method:       http.MethodGet,
+			Path:         "/api/v1/auth/me",
+			Field:        "has_strava_connection",
+			DeprecatedAt: deprecatedAt,
+			Sunset:       &sunset,
+			Replacement:  "/api/v1/connections",
+			Notes:        "GET /api/v1/connections reports each connection's status, token expiry and whether re-authorization is required",
+		},
+		middleware.Deprecation{
+			Method:       http.MethodGet,
+			Path:         "/api/v1/auth/me",
+			Field:        "has_sheets_connection",
+			DeprecatedAt: deprecatedAt,
+			Sunset:       &sunset,
+			Replacement:  "/api/v1/connections",
+			Notes:        "GET /api/v1/connections reports each connection's status, token expiry and whether re-authorization is required",
+		},
+	)
+}
+
+// DeprecationsResponse is the body of GET /api/v1/meta/deprecations
+type DeprecationsResponse struct {
+	Deprecations []middleware.Deprecation `json:"deprecations"`
+}
+
+// MetaHandler serves information about the API itself
+type MetaHandler struct {
+	deprecations *middleware.Deprecations
+	logger       *logger.Logger
+}
+
+// NewMetaHandler creates a new meta handler
+func NewMetaHandler(deprecations *middleware.Deprecations, logger *logger.Logger) *MetaHandler {
+	return &MetaHandler{
+		deprecations: deprecations,
+		logger:       logger.WithContext("component", "meta_handler"),
+	}
+}
+
+// ListDeprecations handles GET /api/v1/meta/deprecations requests
+func (h *MetaHandler) ListDeprecations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(DeprecationsResponse{Deprecations: h.deprecations.List()}); err != nil {
+		h.logger.Error("Failed to encode deprecations response", "error", err)
+	}
+}
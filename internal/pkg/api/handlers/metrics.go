@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// MetricsHandler exposes runtime metrics of the backend API to admins
+type MetricsHandler struct {
+	db         *sql.DB
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewMetricsHandler creates a new metrics handler reporting on the db connection pool
+func NewMetricsHandler(db *sql.DB, authorizer authz.Authorizer, logger *logger.Logger) *MetricsHandler {
+	return &MetricsHandler{
+		db:         db,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "metrics_handler"),
+	}
+}
+
+// DatabasePool handles GET /internal/metrics/database requests
+func (h *MetricsHandler) DatabasePool(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.ServiceConfig()); err != nil {
+		h.logger.Warn("Metrics request denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may read service metrics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(database.Stats(h.db)); err != nil {
+		h.logger.Error("Failed to encode database pool metrics",
+			"error", err)
+	}
+}
+
+func (h *MetricsHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
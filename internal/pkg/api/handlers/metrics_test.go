@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestMetricsHandler_DatabasePool(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(12)
+
+	handler := NewMetricsHandler(db, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.DatabasePool(rr, adminRequest("/internal/metrics/database"))
+	var stats database.PoolStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("Expected pool statistics, got %d (%v)", rr.Code, err)
+	}
+	if stats.MaxOpenConnections != 12 {
+		t.Errorf("Expected the pool limit of 12, got %+v", stats)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.DatabasePool(rr, authenticatedRequest(http.MethodGet, "/internal/metrics/database", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
+)
+
+// NotificationPreviewHandler renders sample notifications so admins can review templates and translations
+type NotificationPreviewHandler struct {
+	dashboardURL string
+	authorizer   authz.Authorizer
+	logger       *logger.Logger
+}
+
+// NewNotificationPreviewHandler creates a new notification preview handler
+func NewNotificationPreviewHandler(dashboardURL string, authorizer authz.Authorizer, logger *logger.Logger) *NotificationPreviewHandler {
+	return &NotificationPreviewHandler{
+		dashboardURL: dashboardURL,
+		authorizer:   authorizer,
+		logger:       logger.WithContext("component", "notification_preview_handler"),
+	}
+}
+
+// NotificationPreviewResponse is the rendered email returned by format=json
+type NotificationPreviewResponse struct {
+	Type    string `json:"type"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// Preview handles GET /api/v1/admin/notifications/preview?type=sync_failed[&locale=es][&format=html]
+// format is html (default), text, json, slack or discord; the chat formats return the webhook payload
+func (h *NotificationPreviewHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Notification preview called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.NotificationTemplates()); err != nil {
+		h.logger.Warn("Notification preview denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may preview notifications")
+		return
+	}
+
+	query := r.URL.Query()
+	notificationType := query.Get("type")
+	n, err := notification.Preview(notificationType, query.Get("locale"), h.dashboardURL)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_TYPE",
+			"type must be one of: "+strings.Join(notification.PreviewTypes(), ", "))
+		return
+	}
+
+	format := query.Get("format")
+	switch format {
+	case "slack", "discord":
+		render := notification.RenderSlack
+		if format == "discord" {
+			render = notification.RenderDiscord
+		}
+		payload, err := render(n)
+		if err != nil {
+			h.renderFailed(w, notificationType, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(payload)
+		return
+	case "", "html", "text", "json":
+	default:
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_FORMAT", "format must be one of: html, text, json, slack, discord")
+		return
+	}
+
+	msg, err := notification.RenderEmail(notification.PreviewRecipient, n)
+	if err != nil {
+		h.renderFailed(w, notificationType, err)
+		return
+	}
+
+	switch format {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(msg.Body))
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		response := NotificationPreviewResponse{
+			Type:    notificationType,
+			Locale:  n.Locale,
+			Subject: msg.Subject,
+			Text:    msg.Body,
+			HTML:    msg.HTMLBody,
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			h.logger.Error("Failed to encode notification preview",
+				"error", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(msg.HTMLBody))
+	}
+}
+
+func (h *NotificationPreviewHandler) renderFailed(w http.ResponseWriter, notificationType string, err error) {
+	h.logger.Error("Failed to render notification preview",
+		"error", err,
+		"type", notificationType)
+	h.writeErrorResponse(w, http.StatusInternalServerError, "RENDER_ERROR", "Failed to render notification")
+}
+
+func (h *NotificationPreviewHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
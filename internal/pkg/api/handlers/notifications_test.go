@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func adminRequest(target string) *http.Request {
+	req := authenticatedRequest(http.MethodGet, target, "", 1)
+	return req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, []authz.Role{authz.RoleAthlete, authz.RoleAdmin}))
+}
+
+func TestNotificationPreviewHandler_Preview(t *testing.T) {
+	handler := NewNotificationPreviewHandler("https://app.example.com", authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.Preview(rr, adminRequest("/api/admin/notifications/preview?type=sync_failed"))
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("Expected an HTML preview, got %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rr.Body.String(), "Your activity sync failed") {
+		t.Errorf("Unexpected preview:\n%s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.Preview(rr, adminRequest("/api/admin/notifications/preview?type=quiet_failure&locale=es&format=json"))
+	var preview NotificationPreviewResponse
+	if err := json.NewDecoder(rr.Body).Decode(&preview); err != nil {
+		t.Fatalf("Failed to decode preview: %v", err)
+	}
+	if preview.Locale != "es" || preview.Text == "" || preview.HTML == "" {
+		t.Errorf("Unexpected JSON preview: %+v", preview)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.Preview(rr, adminRequest("/api/admin/notifications/preview?type=unknown"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown type, got %d", rr.Code)
+	}
+}
+
+func TestNotificationPreviewHandler_RequiresAdmin(t *testing.T) {
+	handler := NewNotificationPreviewHandler("https://app.example.com", authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.Preview(rr, authenticatedRequest(http.MethodGet, "/api/admin/notifications/preview?type=sync_failed", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+}
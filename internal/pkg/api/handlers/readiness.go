@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// ReadinessChecker builds a user's automation prerequisites checklist
+type ReadinessChecker interface {
+	CheckReadiness(ctx context.Context, userID int) (*automation.Readiness, error)
+}
+
+// ReadinessHandler shows users what they still need to set up before automation can run
+type ReadinessHandler struct {
+	checker    ReadinessChecker
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewReadinessHandler creates a new automation readiness handler
+func NewReadinessHandler(checker ReadinessChecker, authorizer authz.Authorizer, logger *logger.Logger) *ReadinessHandler {
+	return &ReadinessHandler{
+		checker:    checker,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "readiness_handler"),
+	}
+}
+
+// GetReadiness handles GET /api/v1/automation/readiness requests. The spreadsheet check opens
+// the spreadsheet with the user's Google token, so the response reflects access right now.
+func (h *ReadinessHandler) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Config(subject.UserID)); err != nil {
+		h.logger.Warn("Readiness request denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to view this configuration")
+		return
+	}
+
+	readiness, err := h.checker.CheckReadiness(r.Context(), subject.UserID)
+	if err != nil {
+		h.logger.Error("Failed to check automation readiness",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to check automation readiness")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, readiness)
+}
+
+func (h *ReadinessHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode readiness response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *ReadinessHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
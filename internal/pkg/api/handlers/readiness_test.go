@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockReadinessChecker struct {
+	readiness *automation.Readiness
+	err       error
+}
+
+func (m *mockReadinessChecker) CheckReadiness(ctx context.Context, userID int) (*automation.Readiness, error) {
+	return m.readiness, m.err
+}
+
+func TestReadinessHandler_GetReadiness(t *testing.T) {
+	checker := &mockReadinessChecker{readiness: &automation.Readiness{
+		Checks: []automation.ReadinessCheck{
+			{ID: automation.CheckStravaConnected, Passed: true},
+			{ID: automation.CheckSpreadsheetSet, Message: "Choose the Google Spreadsheet to sync activities to"},
+		},
+	}}
+	handler := NewReadinessHandler(checker, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.GetReadiness(rr, authenticatedRequest(http.MethodGet, "/api/automation/readiness", "", 1))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response automation.Readiness
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Ready || len(response.Checks) != 2 || response.Checks[1].Message == "" {
+		t.Errorf("Unexpected readiness: %+v", response)
+	}
+
+	checker.err = errors.New("database down")
+	rr = httptest.NewRecorder()
+	handler.GetReadiness(rr, authenticatedRequest(http.MethodGet, "/api/automation/readiness", "", 1))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+}
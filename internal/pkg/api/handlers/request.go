@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// decodeRequest decodes and validates the JSON body of r into req. When the body is unusable it
+// writes a 400 INVALID_JSON or VALIDATION_ERROR response, listing each invalid field under
+// details.fields, and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}, log *logger.Logger) bool {
+	return writeDecodeError(w, r, validate.Decode(r.Body, req), log)
+}
+
+// decodeOptionalRequest is decodeRequest for endpoints whose body may be omitted
+func decodeOptionalRequest(w http.ResponseWriter, r *http.Request, req interface{}, log *logger.Logger) bool {
+	return writeDecodeError(w, r, validate.DecodeOptional(r.Body, req), log)
+}
+
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error, log *logger.Logger) bool {
+	if err == nil {
+		return true
+	}
+
+	response := apierror.New(apierror.CodeInvalidJSON, "Invalid JSON in request body")
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		response = apierror.New(apierror.CodeValidation, fieldErrs.Messages()).WithDetail("fields", fieldErrs)
+	}
+
+	log.Warn("Rejected request body",
+		"path", r.URL.Path,
+		"error_code", response.Error.Code,
+		"error", err)
+	if writeErr := apierror.Write(w, http.StatusBadRequest, response); writeErr != nil {
+		log.Error("Failed to encode error response", "error", writeErr, "error_code", response.Error.Code)
+	}
+	return false
+}
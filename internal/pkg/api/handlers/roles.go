@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// RoleStore grants and revokes account roles and lists their audit trail
+type RoleStore interface {
+	GrantRole(ctx context.Context, userID int, role string, changedBy *int, reason string) (*database.RoleChange, error)
+	RevokeRole(ctx context.Context, userID int, changedBy *int, reason string) (*database.RoleChange, error)
+	ListRoleChanges(ctx context.Context, userID int) ([]database.RoleChange, error)
+}
+
+// RoleHandler lets admins grant the admin and support roles
+type RoleHandler struct {
+	store      RoleStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(store RoleStore, authorizer authz.Authorizer, logger *logger.Logger) *RoleHandler {
+	return &RoleHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "role_handler"),
+	}
+}
+
+// SetRoleRequest changes a user's account role
+type SetRoleRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+// Validate checks the role is known and the change is explained
+func (req *SetRoleRequest) Validate(v *validate.Validator) {
+	if v.Check(req.Role != "", "role", validate.CodeRequired, "role is required") {
+		v.Check(database.ValidUserRole(req.Role), "role", validate.CodeInvalid, "role must be user, admin, support or coach")
+	}
+	v.Check(req.Reason != "", "reason", validate.CodeRequired, "reason is required for the audit log")
+}
+
+// RoleChangesResponse lists a user's role changes, newest first
+type RoleChangesResponse struct {
+	Changes []database.RoleChange `json:"changes"`
+}
+
+// SetRole handles PUT /api/v1/admin/users/{id}/role with {"role", "reason"}; "user" revokes the
+// current role. The user's next access token carries the new role.
+func (h *RoleHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	subject, userID, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req SetRoleRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	// Admins cannot demote themselves, so the last admin cannot lock everyone out
+	if userID == subject.UserID {
+		h.writeErrorResponse(w, http.StatusBadRequest, "CANNOT_CHANGE_OWN_ROLE", "You cannot change your own role")
+		return
+	}
+
+	changedBy := subject.UserID
+	var change *database.RoleChange
+	var err error
+	if req.Role == database.UserRoleUser {
+		change, err = h.store.RevokeRole(r.Context(), userID, &changedBy, req.Reason)
+	} else {
+		change, err = h.store.GrantRole(r.Context(), userID, req.Role, &changedBy, req.Reason)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+			return
+		}
+		h.logger.Error("Failed to change user role",
+			"error", err,
+			"user_id", subject.UserID,
+			"target_user_id", userID,
+			"role", req.Role)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to change role")
+		return
+	}
+
+	if change == nil {
+		h.writeJSON(w, http.StatusOK, map[string]string{"message": "User already has role " + req.Role})
+		return
+	}
+
+	h.logger.Info("User role changed",
+		"user_id", subject.UserID,
+		"target_user_id", userID,
+		"old_role", change.OldRole,
+		"new_role", change.NewRole,
+		"reason", change.Reason,
+		"role_change_id", change.ID)
+	h.writeJSON(w, http.StatusOK, change)
+}
+
+// ListChanges handles GET /api/v1/admin/users/{id}/role-changes
+func (h *RoleHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	subject, userID, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	changes, err := h.store.ListRoleChanges(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list role changes",
+			"error", err,
+			"user_id", subject.UserID,
+			"target_user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list role changes")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, RoleChangesResponse{Changes: changes})
+}
+
+// authorize parses the target user ID and checks the caller may act on their role, writing the
+// error response if not
+func (h *RoleHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, int, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Roles called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, 0, false
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || userID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid user ID is required")
+		return subject, 0, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.UserRoles(userID)); err != nil {
+		h.logger.Warn("Roles denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID,
+			"target_user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may change roles")
+		return subject, 0, false
+	}
+	return subject, userID, true
+}
+
+func (h *RoleHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode role response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *RoleHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
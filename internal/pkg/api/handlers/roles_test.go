@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockRoleStore struct {
+	roles   map[int]string
+	changes []database.RoleChange
+}
+
+func (m *mockRoleStore) GrantRole(ctx context.Context, userID int, role string, changedBy *int, reason string) (*database.RoleChange, error) {
+	old, ok := m.roles[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if old == role {
+		return nil, nil
+	}
+	m.roles[userID] = role
+	change := database.RoleChange{ID: len(m.changes) + 1, UserID: userID, OldRole: old, NewRole: role, ChangedBy: changedBy, Reason: reason}
+	m.changes = append([]database.RoleChange{change}, m.changes...)
+	return &change, nil
+}
+
+func (m *mockRoleStore) RevokeRole(ctx context.Context, userID int, changedBy *int, reason string) (*database.RoleChange, error) {
+	return m.GrantRole(ctx, userID, database.UserRoleUser, changedBy, reason)
+}
+
+func (m *mockRoleStore) ListRoleChanges(ctx context.Context, userID int) ([]database.RoleChange, error) {
+	return m.changes, nil
+}
+
+func TestRoleHandler(t *testing.T) {
+	store := &mockRoleStore{roles: map[int]string{1: database.UserRoleAdmin, 7: database.UserRoleUser}}
+	handler := NewRoleHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Put("/api/admin/users/{id}/role", handler.SetRole)
+	router.Get("/api/admin/users/{id}/role-changes", handler.ListChanges)
+
+	withRoles := func(req *http.Request, roles ...authz.Role) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, roles))
+	}
+	setRole := func(target string, body string, userID int, roles ...authz.Role) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withRoles(authenticatedRequest(http.MethodPut, target, body, userID), roles...))
+		return rr
+	}
+
+	if rr := setRole("/api/admin/users/7/role", `{"role": "owner", "reason": "x"}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown role, got %d", rr.Code)
+	}
+	if rr := setRole("/api/admin/users/1/role", `{"role": "user", "reason": "stepping down"}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 when admins change their own role, got %d", rr.Code)
+	}
+	if rr := setRole("/api/admin/users/7/role", `{"role": "support", "reason": "x"}`, 4, authz.RoleAthlete, authz.RoleSupport); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for support staff, got %d", rr.Code)
+	}
+	if rr := setRole("/api/admin/users/9/role", `{"role": "support", "reason": "x"}`, 1, authz.RoleAdmin); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown user, got %d", rr.Code)
+	}
+
+	rr := setRole("/api/admin/users/7/role", `{"role": "support", "reason": "On-call rotation"}`, 1, authz.RoleAdmin)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if store.roles[7] != database.UserRoleSupport || *store.changes[0].ChangedBy != 1 {
+		t.Errorf("Expected the grant to be stored and audited: %+v", store.changes)
+	}
+
+	// Support staff may read the audit trail
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withRoles(authenticatedRequest(http.MethodGet, "/api/admin/users/7/role-changes", "", 4), authz.RoleAthlete, authz.RoleSupport))
+	var response RoleChangesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || len(response.Changes) != 1 {
+		t.Fatalf("Expected one role change, got %d (%v)", rr.Code, err)
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+// maxUndoTokenLength comfortably exceeds the length of issued undo confirmation tokens
+const maxUndoTokenLength = 128
+
+// RunUndoer undoes the spreadsheet rows written by a user's sync run once the undo is confirmed
+type RunUndoer interface {
+	RequestUndo(ctx context.Context, userID, runID int) (*services.UndoConfirmation, error)
+	ConfirmUndo(ctx context.Context, userID, runID int, token string) (*google.UndoResult, error)
+}
+
+// RunHandler lists a user's sync runs and undoes their spreadsheet writes
+type RunHandler struct {
+	runs       RunLog
+	undoer     RunUndoer
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewRunHandler creates a new run handler
+func NewRunHandler(runs RunLog, undoer RunUndoer, authorizer authz.Authorizer, logger *logger.Logger) *RunHandler {
+	return &RunHandler{
+		runs:       runs,
+		undoer:     undoer,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "run_handler"),
+	}
+}
+
+// UndoRunRequest is the optional body of an undo request; without a confirmation token the undo
+// is only planned
+type UndoRunRequest struct {
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// Validate bounds the token length; whether it matches the run is checked by the undoer
+func (req *UndoRunRequest) Validate(v *validate.Validator) {
+	v.MaxLength("confirmation_token", req.ConfirmationToken, maxUndoTokenLength)
+}
+
+// RunsResponse lists a user's runs
+type RunsResponse struct {
+	Runs []database.AutomationRun `json:"runs"`
+}
+
+// UndoConfirmationResponse asks the client to repeat the request with the confirmation token
+type UndoConfirmationResponse struct {
+	Status string `json:"status"`
+	*services.UndoConfirmation
+}
+
+// UndoRunResponse reports an undone run
+type UndoRunResponse struct {
+	Status string `json:"status"`
+	RunID  int    `json:"run_id"`
+	*google.UndoResult
+}
+
+// List handles GET /api/v1/sync/runs?limit=20, the user's most recent runs; runs whose sheet
+// writes can still be undone are marked undoable
+func (h *RunHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	limit := defaultRunLogLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxRunLogLimit {
+			h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.runs.ListRuns(r.Context(), subject.UserID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list runs",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load runs")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, RunsResponse{Runs: runs})
+}
+
+// Undo handles POST /api/v1/sync/runs/{id}/undo. Without a body it returns what the undo would
+// change and a confirmation token (status "confirmation_required"); repeating the request with
+// {"confirmation_token"} deletes the rows the run appended and clears the deletion flags it set.
+func (h *RunHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	runID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || runID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid run ID is required")
+		return
+	}
+
+	var req UndoRunRequest
+	if !decodeOptionalRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if req.ConfirmationToken == "" {
+		confirmation, err := h.undoer.RequestUndo(r.Context(), subject.UserID, runID)
+		if err != nil {
+			h.handleUndoError(w, subject.UserID, runID, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, UndoConfirmationResponse{Status: "confirmation_required", UndoConfirmation: confirmation})
+		return
+	}
+
+	result, err := h.undoer.ConfirmUndo(r.Context(), subject.UserID, runID, req.ConfirmationToken)
+	if err != nil {
+		h.handleUndoError(w, subject.UserID, runID, err)
+		return
+	}
+
+	h.logger.Info("Run undone",
+		"user_id", subject.UserID,
+		"run_id", runID,
+		"client_ip", middleware.GetClientIP(r))
+	h.writeJSON(w, http.StatusOK, UndoRunResponse{Status: "undone", RunID: runID, UndoResult: result})
+}
+
+// handleUndoError maps undo service errors to HTTP responses
+func (h *RunHandler) handleUndoError(w http.ResponseWriter, userID, runID int, err error) {
+	var undoErr *services.UndoError
+	if !errors.As(err, &undoErr) {
+		h.logger.Error("Unexpected error undoing run",
+			"error", err,
+			"user_id", userID,
+			"run_id", runID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	h.logger.Warn("Run undo failed",
+		"error_type", undoErr.Type,
+		"error", err,
+		"user_id", userID,
+		"run_id", runID)
+
+	statusCode := http.StatusInternalServerError
+	switch undoErr.Type {
+	case services.UndoErrorNotFound:
+		statusCode = http.StatusNotFound
+	case services.UndoErrorNotUndoable, services.UndoErrorUndone, services.UndoErrorSuperseded:
+		statusCode = http.StatusConflict
+	case services.UndoErrorInvalidToken:
+		statusCode = http.StatusBadRequest
+	case services.UndoErrorNotConnected:
+		statusCode = http.StatusUnauthorized
+	case services.UndoErrorGoogle:
+		statusCode = http.StatusBadGateway
+	}
+
+	h.writeErrorResponse(w, statusCode, undoErr.Type, undoErr.Message)
+}
+
+// authorize checks the signed-in user may act on their own runs
+func (h *RunHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Runs called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.Runs(subject.UserID)); err != nil {
+		h.logger.Warn("Runs denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID,
+			"action", action)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to access these runs")
+		return subject, false
+	}
+	return subject, true
+}
+
+func (h *RunHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *RunHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
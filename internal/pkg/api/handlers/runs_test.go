@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+// mockRunUndoer issues a fixed token for run 42 and accepts it once
+type mockRunUndoer struct {
+	undone int
+}
+
+func (m *mockRunUndoer) RequestUndo(ctx context.Context, userID, runID int) (*services.UndoConfirmation, error) {
+	if runID != 42 {
+		return nil, &services.UndoError{Type: services.UndoErrorNotFound, Message: "Run not found"}
+	}
+	return &services.UndoConfirmation{
+		RunID:             runID,
+		SpreadsheetID:     "sheet-1",
+		Plan:              google.UndoPlan{RowsToDelete: 2, UpdatesKept: 1},
+		ConfirmationToken: "undo_token",
+		ExpiresAt:         time.Now().Add(services.DefaultUndoTokenTTL),
+	}, nil
+}
+
+func (m *mockRunUndoer) ConfirmUndo(ctx context.Context, userID, runID int, token string) (*google.UndoResult, error) {
+	if token != "undo_token" || m.undone > 0 {
+		return nil, &services.UndoError{Type: services.UndoErrorInvalidToken, Message: "The confirmation token is invalid or has expired"}
+	}
+	m.undone++
+	return &google.UndoResult{RowsDeleted: 2, UpdatesKept: 1}, nil
+}
+
+func TestRunHandler_Undo(t *testing.T) {
+	undoer := &mockRunUndoer{}
+	handler := NewRunHandler(mockRunLog{}, undoer, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Post("/api/sync/runs/{id}/undo", handler.Undo)
+	undo := func(runID, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, authenticatedRequest(http.MethodPost, "/api/sync/runs/"+runID+"/undo", body, 5))
+		return rr
+	}
+
+	// Without a token the undo is only planned
+	rr := undo("42", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var confirmation UndoConfirmationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&confirmation); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if confirmation.Status != "confirmation_required" || confirmation.ConfirmationToken != "undo_token" || confirmation.Plan.RowsToDelete != 2 {
+		t.Errorf("Expected a confirmation request with the plan, got %+v", confirmation)
+	}
+	if undoer.undone != 0 {
+		t.Error("Expected nothing undone before confirmation")
+	}
+
+	if rr := undo("42", `{"confirmation_token": "wrong"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a wrong token, got %d", rr.Code)
+	}
+
+	rr = undo("42", `{"confirmation_token": "undo_token"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var undone UndoRunResponse
+	if err := json.NewDecoder(rr.Body).Decode(&undone); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if undone.Status != "undone" || undone.RunID != 42 || undone.UndoResult == nil || undone.RowsDeleted != 2 {
+		t.Errorf("Expected the run to be undone, got %+v", undone)
+	}
+
+	if rr := undo("7", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another run, got %d", rr.Code)
+	}
+	if rr := undo("abc", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid run ID, got %d", rr.Code)
+	}
+}
+
+func TestRunHandler_List(t *testing.T) {
+	handler := NewRunHandler(mockRunLog{}, &mockRunUndoer{}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.List(rr, authenticatedRequest(http.MethodGet, "/api/sync/runs", "", 5))
+	var response RunsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 with runs, got %d (%v)", rr.Code, err)
+	}
+	if len(response.Runs) != 1 || response.Runs[0].UserID != 5 {
+		t.Errorf("Expected the user's runs, got %+v", response.Runs)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.List(rr, authenticatedRequest(http.MethodGet, "/api/sync/runs?limit=500", "", 5))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized limit, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+// signInHistory is how far back earlier sessions and sign-ins count as known devices and
+// locations; coming back after longer is reported as new
+const signInHistory = 180 * 24 * time.Hour
+
+// SignInAudit records sign-ins and revokes sessions from new sign-in emails (implemented by
+// *database.SignInRepository)
+type SignInAudit interface {
+	ListSignInOrigins(ctx context.Context, userID, excludeSessionID int, since time.Time) ([]database.SignInOrigin, error)
+	RecordSignIn(ctx context.Context, event *database.SignInEvent) error
+	RevokeSignInSession(ctx context.Context, tokenHash string) (*database.SignInEvent, error)
+}
+
+// SetSignInAudit records every sign-in in the audit log, flagging those from a device or network
+// the user has not used before so the notification service emails them
+func (h *AuthHandler) SetSignInAudit(audit SignInAudit) {
+	h.signIns = audit
+}
+
+// recordSignIn adds the sign-in that created session to the audit log. A sign-in is new when the
+// user has signed in before but never with this browser and operating system (new device) or
+// from this network (new location). Failures are logged and never block the sign-in.
+func (h *AuthHandler) recordSignIn(ctx context.Context, session *database.UserSession) {
+	if h.signIns == nil {
+		return
+	}
+
+	event := &database.SignInEvent{
+		UserID:    session.UserID,
+		SessionID: &session.ID,
+	}
+	if session.UserAgent != nil {
+		event.UserAgent = *session.UserAgent
+	}
+	if session.IPAddress != nil {
+		event.IPAddress = *session.IPAddress
+	}
+	event.Device = auth.DescribeDevice(event.UserAgent)
+	event.Network = auth.NetworkOf(event.IPAddress)
+
+	origins, err := h.signIns.ListSignInOrigins(ctx, session.UserID, session.ID, time.Now().Add(-signInHistory))
+	if err != nil {
+		h.logger.Error("Failed to load earlier sign-ins, recording sign-in as known",
+			"error", err,
+			"user_id", session.UserID)
+	} else if len(origins) > 0 {
+		event.NewDevice, event.NewLocation = true, true
+		for _, origin := range origins {
+			if auth.DescribeDevice(origin.UserAgent) == event.Device {
+				event.NewDevice = false
+			}
+			if auth.NetworkOf(origin.IPAddress) == event.Network {
+				event.NewLocation = false
+			}
+		}
+	}
+
+	if err := h.signIns.RecordSignIn(ctx, event); err != nil {
+		h.logger.Error("Failed to record sign-in",
+			"error", err,
+			"user_id", session.UserID,
+			"session_id", session.ID)
+		return
+	}
+
+	if event.NewDevice || event.NewLocation {
+		h.logger.Warn("Sign-in from a new device or location",
+			"user_id", session.UserID,
+			"session_id", session.ID,
+			"device", event.Device,
+			"network", event.Network,
+			"new_device", event.NewDevice,
+			"new_location", event.NewLocation)
+	}
+}
+
+// RevokeSessionRequest carries the token of a new sign-in email's revoke link
+type RevokeSessionRequest struct {
+	Token string `json:"token"`
+}
+
+// Validate requires the token
+func (req *RevokeSessionRequest) Validate(v *validate.Validator) {
+	v.Required("token", req.Token, "Revoke link token cannot be empty")
+}
+
+// RevokeSession handles POST /api/v1/auth/sessions/revoke with {"token"} from a new sign-in
+// email (public). It signs out the session that sign-in created; each link works once.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if h.signIns == nil {
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Sign-in auditing is not enabled")
+		return
+	}
+
+	var req RevokeSessionRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	event, err := h.signIns.RevokeSignInSession(r.Context(), auth.HashSessionRevokeToken(req.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		h.writeErrorResponse(w, http.StatusNotFound, "INVALID_REVOKE_LINK", "This link has expired or was already used")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke session from sign-in email",
+			"error", err,
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to sign out the session")
+		return
+	}
+
+	h.logger.Warn("Session revoked from new sign-in email",
+		"user_id", event.UserID,
+		"session_id", event.SessionID,
+		"sign_in_id", event.ID,
+		"device", event.Device,
+		"client_ip", middleware.GetClientIP(r))
+	h.writeRefreshResponse(w, "Session signed out")
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSignInAudit struct {
+	origins  []database.SignInOrigin
+	recorded []database.SignInEvent
+	tokens   map[string]*database.SignInEvent
+}
+
+func (m *mockSignInAudit) ListSignInOrigins(ctx context.Context, userID, excludeSessionID int, since time.Time) ([]database.SignInOrigin, error) {
+	return m.origins, nil
+}
+
+func (m *mockSignInAudit) RecordSignIn(ctx context.Context, event *database.SignInEvent) error {
+	event.ID = len(m.recorded) + 1
+	m.recorded = append(m.recorded, *event)
+	return nil
+}
+
+func (m *mockSignInAudit) RevokeSignInSession(ctx context.Context, tokenHash string) (*database.SignInEvent, error) {
+	event, ok := m.tokens[tokenHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(m.tokens, tokenHash)
+	return event, nil
+}
+
+const (
+	firefoxWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0"
+	safariIPhone   = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1"
+)
+
+func TestRecordSignIn(t *testing.T) {
+	session := func(userAgent, ip string) *database.UserSession {
+		return &database.UserSession{ID: 5, UserID: 1, UserAgent: &userAgent, IPAddress: &ip}
+	}
+
+	tests := []struct {
+		name                  string
+		origins               []database.SignInOrigin
+		session               *database.UserSession
+		newDevice, newNetwork bool
+	}{
+		{"First sign-in", nil, session(firefoxWindows, "203.0.113.42"), false, false},
+		{"Known device on the same network", []database.SignInOrigin{{UserAgent: firefoxWindows, IPAddress: "203.0.113.7"}}, session(firefoxWindows, "203.0.113.42"), false, false},
+		{"New device", []database.SignInOrigin{{UserAgent: firefoxWindows, IPAddress: "203.0.113.7"}}, session(safariIPhone, "203.0.113.42"), true, false},
+		{"New location", []database.SignInOrigin{{UserAgent: firefoxWindows, IPAddress: "198.51.100.7"}}, session(firefoxWindows, "203.0.113.42"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &mockSignInAudit{origins: tt.origins}
+			handler := &AuthHandler{logger: logger.New("test")}
+			handler.SetSignInAudit(audit)
+
+			handler.recordSignIn(context.Background(), tt.session)
+
+			if len(audit.recorded) != 1 {
+				t.Fatalf("Expected the sign-in to be recorded, got %d events", len(audit.recorded))
+			}
+			event := audit.recorded[0]
+			if event.NewDevice != tt.newDevice || event.NewLocation != tt.newNetwork {
+				t.Errorf("Expected new device %v and new location %v, got %+v", tt.newDevice, tt.newNetwork, event)
+			}
+			if event.Network != "203.0.113.0/24" || *event.SessionID != 5 {
+				t.Errorf("Unexpected sign-in event: %+v", event)
+			}
+		})
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	token, hash, err := auth.NewSessionRevokeToken()
+	if err != nil {
+		t.Fatalf("NewSessionRevokeToken failed: %v", err)
+	}
+	sessionID := 5
+	audit := &mockSignInAudit{tokens: map[string]*database.SignInEvent{
+		hash: {ID: 1, UserID: 1, SessionID: &sessionID, Device: "Safari on iOS"},
+	}}
+	handler := &AuthHandler{logger: logger.New("test")}
+	handler.SetSignInAudit(audit)
+
+	revoke := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sessions/revoke", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.RevokeSession(rr, req)
+		return rr
+	}
+
+	if rr := revoke(`{}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a token, got %d", rr.Code)
+	}
+	if rr := revoke(`{"token": "` + token + `"}`); rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	// Each link works once
+	if rr := revoke(`{"token": "` + token + `"}`); rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), "INVALID_REVOKE_LINK") {
+		t.Errorf("Expected status 404 for a used link, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+// signupInviteCookie carries the invite code from GoogleAuthURL through the OAuth redirect
+const signupInviteCookie = "signup_invite"
+
+// Error codes of refused signups
+const (
+	ErrorCodeSignupDomainNotAllowed = "SIGNUP_DOMAIN_NOT_ALLOWED"
+	ErrorCodeSignupInviteRequired   = "SIGNUP_INVITE_REQUIRED"
+	ErrorCodeSignupInviteInvalid    = "SIGNUP_INVITE_INVALID"
+)
+
+// SignupPolicy decides who may create an account on their first Google sign-in
+type SignupPolicy struct {
+	// Mode is config.SignupPolicyOpen, SignupPolicyAllowlist or SignupPolicyInvite
+	Mode string
+	// AllowedDomains are the lower-case email domains allowed to sign up in allowlist mode
+	AllowedDomains []string
+	// AdminEmails may always sign up, so a new deployment can bootstrap its first admin
+	AdminEmails []string
+}
+
+// NewSignupPolicy resolves the configured signup settings
+func NewSignupPolicy(cfg config.SignupConfig, adminEmails []string) SignupPolicy {
+	policy := SignupPolicy{Mode: cfg.Policy, AdminEmails: adminEmails}
+	for _, domain := range cfg.AllowedDomains {
+		policy.AllowedDomains = append(policy.AllowedDomains, strings.ToLower(strings.TrimPrefix(domain, "@")))
+	}
+	return policy
+}
+
+// signupRefusal is the error response for a first sign-in that may not create an account
+type signupRefusal struct {
+	code    string
+	message string
+}
+
+// admit checks whether a new account may be created for the Google account's email. In invite
+// mode it returns the hash of the invite code to redeem with the account.
+func (p SignupPolicy) admit(email string, emailVerified bool, inviteCode string) (string, *signupRefusal) {
+	if emailVerified && p.isAdmin(email) {
+		return "", nil
+	}
+
+	switch p.Mode {
+	case config.SignupPolicyAllowlist:
+		if !emailVerified || !p.allowsDomain(email) {
+			return "", &signupRefusal{ErrorCodeSignupDomainNotAllowed, "Sign-up is limited to approved email domains"}
+		}
+	case config.SignupPolicyInvite:
+		if strings.TrimSpace(inviteCode) == "" {
+			return "", &signupRefusal{ErrorCodeSignupInviteRequired, "Sign-up requires an invite code"}
+		}
+		return auth.HashInviteCode(inviteCode), nil
+	}
+	return "", nil
+}
+
+// isAdmin reports whether email is one of the configured admin emails
+func (p SignupPolicy) isAdmin(email string) bool {
+	for _, admin := range p.AdminEmails {
+		if strings.EqualFold(strings.TrimSpace(admin), email) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsDomain reports whether email belongs to one of the allowed domains
+func (p SignupPolicy) allowsDomain(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range p.AllowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+func TestSignupPolicy(t *testing.T) {
+	open := NewSignupPolicy(config.SignupConfig{Policy: config.SignupPolicyOpen}, nil)
+	if hash, refusal := open.admit("anyone@gmail.com", false, ""); hash != "" || refusal != nil {
+		t.Errorf("Expected open signup to admit anyone, got %q %v", hash, refusal)
+	}
+
+	allowlist := NewSignupPolicy(config.SignupConfig{Policy: config.SignupPolicyAllowlist, AllowedDomains: []string{"Academy.org", "@club.example"}}, nil)
+	tests := []struct {
+		email    string
+		verified bool
+		want     bool
+	}{
+		{"coach@academy.org", true, true},
+		{"Runner@CLUB.example", true, true},
+		{"coach@academy.org", false, false},
+		{"coach@academy.org.evil.com", true, false},
+		{"someone@gmail.com", true, false},
+		{"not-an-email", true, false},
+	}
+	for _, tt := range tests {
+		_, refusal := allowlist.admit(tt.email, tt.verified, "")
+		if admitted := refusal == nil; admitted != tt.want {
+			t.Errorf("admit(%q, verified=%v) = %v, want %v", tt.email, tt.verified, admitted, tt.want)
+		}
+		if refusal != nil && refusal.code != ErrorCodeSignupDomainNotAllowed {
+			t.Errorf("Expected %s, got %s", ErrorCodeSignupDomainNotAllowed, refusal.code)
+		}
+	}
+
+	invite := NewSignupPolicy(config.SignupConfig{Policy: config.SignupPolicyInvite}, []string{"Owner@Academy.org"})
+	if _, refusal := invite.admit("new@gmail.com", true, ""); refusal == nil || refusal.code != ErrorCodeSignupInviteRequired {
+		t.Errorf("Expected an invite to be required, got %v", refusal)
+	}
+	if hash, refusal := invite.admit("new@gmail.com", true, "inv_abc"); refusal != nil || hash != auth.HashInviteCode("inv_abc") {
+		t.Errorf("Expected the invite code's hash, got %q %v", hash, refusal)
+	}
+
+	// Admins can always sign up, so the first admin needs no invite
+	if hash, refusal := invite.admit("owner@academy.org", true, ""); refusal != nil || hash != "" {
+		t.Errorf("Expected an admin to be admitted without an invite, got %q %v", hash, refusal)
+	}
+	if _, refusal := invite.admit("owner@academy.org", false, ""); refusal == nil {
+		t.Error("Expected an unverified admin email to need an invite")
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+// StatsProvider computes a user's dashboard stats and gear mileage
+type StatsProvider interface {
+	GetStats(ctx context.Context, userID int) (*services.DashboardStats, error)
+	GetGearMileage(ctx context.Context, userID int) (*services.GearMileageSummary, error)
+}
+
+// StatsHandler handles dashboard stats requests
+type StatsHandler struct {
+	stats      StatsProvider
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(stats StatsProvider, authorizer authz.Authorizer, logger *logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		stats:      stats,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "stats_handler"),
+	}
+}
+
+// GetStats handles GET /api/v1/stats requests
+// Stats are computed from the local activity cache, so they reflect the last successful sync
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.logger.Warn("GetStats called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Stats(userID)); err != nil {
+		h.logger.Warn("GetStats denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to view these stats")
+		return
+	}
+
+	stats, err := h.stats.GetStats(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to compute dashboard stats",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to compute stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Error("Failed to encode stats response",
+			"error", err,
+			"user_id", userID)
+	}
+}
+
+// GetGearMileage handles GET /api/v1/stats/gear requests
+// Totals come from the gear and activity caches, so they reflect the last successful sync
+func (h *StatsHandler) GetGearMileage(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.logger.Warn("GetGearMileage called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Stats(userID)); err != nil {
+		h.logger.Warn("GetGearMileage denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to view these stats")
+		return
+	}
+
+	summary, err := h.stats.GetGearMileage(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to summarize gear mileage",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to summarize gear mileage")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		h.logger.Error("Failed to encode gear mileage response",
+			"error", err,
+			"user_id", userID)
+	}
+}
+
+func (h *StatsHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+type mockStatsProvider struct {
+	stats *services.DashboardStats
+	gear  *services.GearMileageSummary
+	err   error
+}
+
+func (m *mockStatsProvider) GetStats(ctx context.Context, userID int) (*services.DashboardStats, error) {
+	return m.stats, m.err
+}
+
+func (m *mockStatsProvider) GetGearMileage(ctx context.Context, userID int) (*services.GearMileageSummary, error) {
+	return m.gear, m.err
+}
+
+func TestStatsHandler_GetStats(t *testing.T) {
+	stats := &services.DashboardStats{Timezone: "UTC", Streak: services.StreakStats{CurrentDays: 4}}
+	handler := NewStatsHandler(&mockStatsProvider{stats: stats}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.GetStats(rr, authenticatedRequest(http.MethodGet, "/api/stats", "", 5))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var decoded services.DashboardStats
+	if err := json.NewDecoder(rr.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if decoded.Streak.CurrentDays != 4 {
+		t.Errorf("Expected streak of 4 days, got %+v", decoded.Streak)
+	}
+
+	handler = NewStatsHandler(&mockStatsProvider{err: errors.New("db down")}, authz.DefaultPolicy(), logger.New("test"))
+	rr = httptest.NewRecorder()
+	handler.GetStats(rr, authenticatedRequest(http.MethodGet, "/api/stats", "", 5))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.GetStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without user, got %d", rr.Code)
+	}
+}
+
+func TestStatsHandler_GetStats_Forbidden(t *testing.T) {
+	denyAll := authz.NewPolicy()
+	handler := NewStatsHandler(&mockStatsProvider{stats: &services.DashboardStats{}}, denyAll, logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.GetStats(rr, authenticatedRequest(http.MethodGet, "/api/stats", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rr.Code)
+	}
+}
+
+func TestStatsHandler_GetGearMileage(t *testing.T) {
+	gear := &services.GearMileageSummary{Timezone: "UTC", Gear: []services.GearSummary{{GearID: "g1", Name: "Daily Trainers", TotalDistanceKm: 412}}}
+	handler := NewStatsHandler(&mockStatsProvider{gear: gear}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.GetGearMileage(rr, authenticatedRequest(http.MethodGet, "/api/stats/gear", "", 5))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var decoded services.GearMileageSummary
+	if err := json.NewDecoder(rr.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(decoded.Gear) != 1 || decoded.Gear[0].TotalDistanceKm != 412 {
+		t.Errorf("Unexpected gear mileage: %+v", decoded)
+	}
+
+	handler = NewStatsHandler(&mockStatsProvider{err: errors.New("db down")}, authz.DefaultPolicy(), logger.New("test"))
+	rr = httptest.NewRecorder()
+	handler.GetGearMileage(rr, authenticatedRequest(http.MethodGet, "/api/stats/gear", "", 5))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+}
@@ -5,14 +5,20 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 )
 
@@ -22,6 +28,11 @@ type StravaHandler struct {
 	userRepository    *database.UserRepository
 	frontendURL       string
 	isDevelopment     bool
+	// Configured cookie policy (see SetCookiePolicy); nil uses DefaultCookiePolicy
+	cookies           *CookiePolicy
+	// Announces new connections to other services (see SetEventPublisher); may be nil
+	publisher         events.Publisher
+	authorizer        authz.Authorizer
 	logger            *logger.Logger
 }
 
@@ -31,6 +42,7 @@ func NewStravaHandler(
 	userRepository *database.UserRepository,
 	frontendURL string,
 	isDevelopment bool,
+	authorizer authz.Authorizer,
 	logger *logger.Logger,
 ) *StravaHandler {
 	return &StravaHandler{
@@ -38,19 +50,25 @@ func NewStravaHandler(
 		userRepository: userRepository,
 		frontendURL:    frontendURL,
 		isDevelopment:  isDevelopment,
+		authorizer:     authorizer,
 		logger:         logger,
 	}
 }
 
-// getCookieConfig returns appropriate cookie configuration for the environment
+// SetCookiePolicy replaces the environment's default cookie attributes
+func (h *StravaHandler) SetCookiePolicy(policy CookiePolicy) {
+	h.cookies = &policy
+}
+
+// SetEventPublisher publishes a UserConnectedStrava event for every connected athlete
+func (h *StravaHandler) SetEventPublisher(publisher events.Publisher) {
+	h.publisher = publisher
+}
+
+// getCookieConfig returns the cookie attributes in effect
 func (h *StravaHandler) getCookieConfig() (domain string, sameSite http.SameSite, secure bool) {
-	if h.isDevelopment {
-		// Development: Use .localhost domain to share cookies across ports
-		// SameSite=Lax allows cookies in top-level navigation (OAuth redirects)
-		return ".localhost", http.SameSiteLaxMode, false
-	}
-	// Production: Use secure settings
-	return "", http.SameSiteLaxMode, true
+	policy := cookiePolicyOrDefault(h.cookies, h.isDevelopment)
+	return policy.Domain, policy.SameSite, policy.Secure
 }
 
 // generateSecureStravaState generates a cryptographically secure random state for OAuth CSRF protection
@@ -84,6 +102,17 @@ func parseUserIDFromStravaState(state string) (int, error) {
 	return userID, nil
 }
 
+// splitScopes splits an OAuth scope list, which Strava separates with commas and Google with spaces
+func splitScopes(raw, sep string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(raw, sep) {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
 // StravaAuthURL generates and returns the Strava OAuth authorization URL
 func (h *StravaHandler) StravaAuthURL(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -98,7 +127,7 @@ func (h *StravaHandler) StravaAuthURL(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		h.logger.Warn("StravaAuthURL called without valid user context", 
 			"client_ip", clientIP)
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
 		return
 	}
 	
@@ -107,7 +136,7 @@ func (h *StravaHandler) StravaAuthURL(w http.ResponseWriter, r *http.Request) {
 	state, err := generateSecureStravaState(userID)
 	if err != nil {
 		h.logger.Error("Failed to generate secure Strava OAuth state", "error", err, "user_id", userID)
-		http.Error(w, "Failed to generate secure state", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to generate secure state")
 		return
 	}
 	
@@ -128,7 +157,7 @@ func (h *StravaHandler) StravaAuthURL(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		h.logger.Error("Failed to encode Strava OAuth URL response", "error", err, "user_id", userID)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode response")
 		return
 	}
 	
@@ -147,7 +176,7 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 	stateParam := r.URL.Query().Get("state")
 	if stateParam == "" {
 		h.logger.Warn("Strava OAuth callback missing state parameter", "client_ip", clientIP)
-		http.Error(w, "Missing state parameter", http.StatusBadRequest)
+		h.writeErrorResponse(w, http.StatusBadRequest, "MISSING_STATE", "Missing state parameter")
 		return
 	}
 	
@@ -158,7 +187,7 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 			"error", err, 
 			"state", stateParam,
 			"client_ip", clientIP)
-		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_STATE", "Invalid state parameter")
 		return
 	}
 	
@@ -188,7 +217,7 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 		h.logger.Warn("Strava OAuth callback missing authorization code", 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		h.writeErrorResponse(w, http.StatusBadRequest, "MISSING_CODE", "Missing authorization code")
 		return
 	}
 	
@@ -203,7 +232,7 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 			"error", err, 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to exchange code for token", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "TOKEN_EXCHANGE_FAILED", "Failed to exchange code for token")
 		return
 	}
 	
@@ -221,7 +250,7 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 			"error", err, 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to get athlete info", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "USER_INFO_FAILED", "Failed to get athlete info")
 		return
 	}
 	
@@ -240,12 +269,33 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 	// Construct full athlete name
 	athleteName := fmt.Sprintf("%s %s", athleteInfo.FirstName, athleteInfo.LastName)
 	
-	if err := h.userRepository.UpdateStravaConnection(r.Context(), userID, token.AccessToken, token.RefreshToken, &token.Expiry, athleteInfo.ID, athleteName, athleteInfo.Profile); err != nil {
+	// A fresh authorization replaces whatever tokens are stored, so no expected token version is
+	// given; the write still bumps the version, failing any refresh that read the old tokens
+	updateReq := &database.UpdateStravaConnectionRequest{
+		UserID:            userID,
+		AccessToken:       token.AccessToken,
+		RefreshToken:      token.RefreshToken,
+		TokenExpiry:       &token.Expiry,
+		AthleteID:         athleteInfo.ID,
+		AthleteName:       athleteName,
+		ProfilePictureURL: athleteInfo.Profile,
+	}
+	err = h.userRepository.UpdateStravaConnection(r.Context(), updateReq)
+	if errors.Is(err, database.ErrStravaAthleteConnected) {
+		// The grant is not deauthorized, as Strava shares it with the user the athlete is connected to
+		h.logger.Warn("Strava athlete is already connected to another user",
+			"user_id", userID,
+			"athlete_id", athleteInfo.ID,
+			"client_ip", clientIP)
+		http.Redirect(w, r, stravaConflictURL(h.frontendURL, athleteInfo.ID, athleteName), http.StatusTemporaryRedirect)
+		return
+	}
+	if err != nil {
 		h.logger.Error("Failed to update user's Strava connection", 
 			"error", err, 
 			"user_id", userID,
 			"athlete_id", athleteInfo.ID)
-		http.Error(w, "Failed to save Strava connection", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to save Strava connection")
 		return
 	}
 
@@ -253,6 +303,24 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 		"user_id", userID,
 		"athlete_id", athleteInfo.ID)
 
+	// The connection is saved, so a failure to announce it only delays consumers' reaction
+	if h.publisher != nil {
+		if err := h.publisher.Publish(r.Context(), events.UserConnectedStrava{UserID: userID, AthleteID: athleteInfo.ID}); err != nil {
+			h.logger.Warn("Failed to publish Strava connection event",
+				"error", err,
+				"user_id", userID)
+		}
+	}
+
+	// Strava reports the scopes the athlete accepted, which may be fewer than were requested
+	if scopes := splitScopes(r.URL.Query().Get("scope"), ","); len(scopes) > 0 {
+		if err := h.userRepository.UpdateGrantedScopes(r.Context(), userID, database.ProviderStrava, scopes); err != nil {
+			h.logger.Warn("Failed to record granted Strava scopes",
+				"error", err,
+				"user_id", userID)
+		}
+	}
+
 	// Redirect to frontend dashboard (clean URL - frontend will detect connection automatically)
 	dashboardURL := h.frontendURL + "/dashboard"
 	h.logger.Info("Strava OAuth callback successful, redirecting to dashboard", 
@@ -264,9 +332,25 @@ func (h *StravaHandler) StravaCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, dashboardURL, http.StatusTemporaryRedirect)
 }
 
+// ErrorCodeStravaAthleteConflict reports that the Strava athlete a user authorized is already
+// connected to another Academy Sync account
+const ErrorCodeStravaAthleteConflict = "STRAVA_ATHLETE_CONFLICT"
+
+// stravaConflictURL is the dashboard URL the Strava callback redirects to when the athlete is
+// already connected to another user. The web app reads strava_error and shows which athlete is
+// taken from athlete_id and athlete_name.
+func stravaConflictURL(frontendURL string, athleteID int64, athleteName string) string {
+	query := url.Values{}
+	query.Set("strava_error", ErrorCodeStravaAthleteConflict)
+	query.Set("athlete_id", strconv.FormatInt(athleteID, 10))
+	query.Set("athlete_name", athleteName)
+	return frontendURL + "/dashboard?" + query.Encode()
+}
+
 // DisconnectStrava handles disconnecting the user's Strava account
 func (h *StravaHandler) DisconnectStrava(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
 	clientIP := middleware.GetClientIP(r)
 	
 	h.logger.Debug("Disconnecting Strava account", 
@@ -277,10 +361,22 @@ func (h *StravaHandler) DisconnectStrava(w http.ResponseWriter, r *http.Request)
 	if !ok {
 		h.logger.Warn("DisconnectStrava called without valid user context", 
 			"client_ip", clientIP)
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
 		return
 	}
 
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("DisconnectStrava denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration")
+		return
+	}
+
+	// Revoke the grant at Strava first; clearing only our copy of the tokens would leave the app
+	// authorized in the athlete's Strava settings
+	h.deauthorizeStrava(r.Context(), userID)
+
 	// Remove Strava connection from database
 	h.logger.Debug("Removing Strava connection from database", "user_id", userID)
 	
@@ -289,7 +385,7 @@ func (h *StravaHandler) DisconnectStrava(w http.ResponseWriter, r *http.Request)
 			"error", err, 
 			"user_id", userID,
 			"client_ip", clientIP)
-		http.Error(w, "Failed to disconnect Strava account", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to disconnect Strava account")
 		return
 	}
 
@@ -304,7 +400,51 @@ func (h *StravaHandler) DisconnectStrava(w http.ResponseWriter, r *http.Request)
 		h.logger.Error("Failed to encode disconnect response", 
 			"error", err, 
 			"user_id", userID)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode response")
+		return
+	}
+}
+
+// deauthorizeStrava revokes the app's access at Strava. Strava only accepts a valid access
+// token, so an expired one is refreshed first. Failures are logged and do not stop the
+// disconnect: the user can still revoke access from their Strava settings.
+func (h *StravaHandler) deauthorizeStrava(ctx context.Context, userID int) {
+	accessToken, refreshToken, expiry, _, err := h.userRepository.GetDecryptedStravaTokens(ctx, userID)
+	if err != nil {
+		h.logger.Warn("Could not load Strava tokens to revoke, disconnecting locally only",
+			"error", err,
+			"user_id", userID)
 		return
 	}
-}
\ No newline at end of file
+	if accessToken == "" {
+		return // Not connected
+	}
+
+	if expiry != nil && time.Until(*expiry) < time.Minute {
+		token, err := h.oauthService.RefreshStravaToken(ctx, refreshToken)
+		if err != nil {
+			h.logger.Warn("Could not refresh Strava token to revoke it, disconnecting locally only",
+				"error", err,
+				"user_id", userID)
+			return
+		}
+		accessToken = token.AccessToken
+	}
+
+	if err := h.oauthService.DeauthorizeStrava(ctx, accessToken); err != nil {
+		h.logger.Warn("Failed to revoke Strava authorization, disconnecting locally only",
+			"error", err,
+			"user_id", userID)
+		return
+	}
+	h.logger.Info("Revoked Strava authorization", "user_id", userID)
+}
+
+func (h *StravaHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestStravaConflictURL(t *testing.T) {
+	redirect, err := url.Parse(stravaConflictURL("https://app.example.com", 555, "Jane Runner"))
+	if err != nil {
+		t.Fatalf("Invalid redirect URL: %v", err)
+	}
+	if redirect.Path != "/dashboard" {
+		t.Errorf("Expected a redirect to the dashboard, got %q", redirect.Path)
+	}
+
+	query := redirect.Query()
+	if query.Get("strava_error") != ErrorCodeStravaAthleteConflict || query.Get("athlete_id") != "555" || query.Get("athlete_name") != "Jane Runner" {
+		t.Errorf("Unexpected conflict payload: %v", query)
+	}
+}
+
+func TestStravaHandler_DisconnectStravaRefusesReadScopedToken(t *testing.T) {
+	// The repository is never reached: the token is refused before anything is revoked or cleared
+	handler := NewStravaHandler(nil, nil, "https://app.example.com", false, authz.DefaultPolicy(), logger.New("test"))
+
+	req := authenticatedRequest(http.MethodDelete, "/api/v1/connections/strava", "", 1)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ScopesKey, []authz.Scope{authz.ScopeRead}))
+
+	rr := httptest.NewRecorder()
+	handler.DisconnectStrava(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a read-scoped token, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
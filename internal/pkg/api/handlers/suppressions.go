@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// maxSuppressionPageSize bounds the suppressions returned by one request
+const maxSuppressionPageSize = 200
+
+// EmailSuppressionStore lists and clears suppressed email addresses
+type EmailSuppressionStore interface {
+	ListEmailSuppressions(ctx context.Context, limit, offset int) ([]database.EmailSuppression, error)
+	DeleteEmailSuppression(ctx context.Context, email string) error
+}
+
+// EmailSuppressionHandler lets admins review and clear addresses suppressed after hard bounces
+type EmailSuppressionHandler struct {
+	store      EmailSuppressionStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewEmailSuppressionHandler creates a new email suppression handler
+func NewEmailSuppressionHandler(store EmailSuppressionStore, authorizer authz.Authorizer, logger *logger.Logger) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "email_suppression_handler"),
+	}
+}
+
+// EmailSuppressionsResponse is a page of suppressed addresses
+type EmailSuppressionsResponse struct {
+	Suppressions []database.EmailSuppression `json:"suppressions"`
+	Limit        int                         `json:"limit"`
+	Offset       int                         `json:"offset"`
+}
+
+// List handles GET /api/v1/admin/notifications/suppressions[?limit=50&offset=0]
+func (h *EmailSuppressionHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	limit, err := queryInt(r, "limit", 50)
+	if err != nil || limit <= 0 || limit > maxSuppressionPageSize {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and "+strconv.Itoa(maxSuppressionPageSize))
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be a non-negative number")
+		return
+	}
+
+	suppressions, err := h.store.ListEmailSuppressions(r.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list email suppressions",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list suppressions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := EmailSuppressionsResponse{Suppressions: suppressions, Limit: limit, Offset: offset}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode email suppressions",
+			"error", err)
+	}
+}
+
+// Delete handles DELETE /api/v1/admin/notifications/suppressions/{email}, so the address receives email again
+func (h *EmailSuppressionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorize(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+
+	email, err := url.PathUnescape(chi.URLParam(r, "email"))
+	if err != nil || !strings.Contains(email, "@") {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_EMAIL", "A valid email address is required")
+		return
+	}
+
+	if err := h.store.DeleteEmailSuppression(r.Context(), email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Email address is not suppressed")
+			return
+		}
+		h.logger.Error("Failed to clear email suppression",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to clear suppression")
+		return
+	}
+
+	h.logger.Info("Email suppression cleared",
+		"user_id", subject.UserID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize checks that the caller is an admin, writing the error response if not
+func (h *EmailSuppressionHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Email suppressions called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.EmailSuppressions()); err != nil {
+		h.logger.Warn("Email suppressions denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may manage email suppressions")
+		return subject, false
+	}
+	return subject, true
+}
+
+func (h *EmailSuppressionHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
+
+// queryInt parses an optional integer query parameter
+func queryInt(r *http.Request, name string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSuppressionStore struct {
+	suppressions []database.EmailSuppression
+}
+
+func (m *mockSuppressionStore) ListEmailSuppressions(ctx context.Context, limit, offset int) ([]database.EmailSuppression, error) {
+	return m.suppressions, nil
+}
+
+func (m *mockSuppressionStore) DeleteEmailSuppression(ctx context.Context, email string) error {
+	for i, s := range m.suppressions {
+		if s.Email == email {
+			m.suppressions = append(m.suppressions[:i], m.suppressions[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func TestEmailSuppressionHandler(t *testing.T) {
+	store := &mockSuppressionStore{suppressions: []database.EmailSuppression{{Email: "gone@example.com", SMTPCode: 550, Reason: "5.1.1 User unknown", BounceCount: 1}}}
+	handler := NewEmailSuppressionHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/notifications/suppressions", handler.List)
+	router.Delete("/api/admin/notifications/suppressions/{email}", handler.Delete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("/api/admin/notifications/suppressions"))
+	var page EmailSuppressionsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("Expected a suppression list, got %d (%v)", rr.Code, err)
+	}
+	if len(page.Suppressions) != 1 || page.Suppressions[0].Email != "gone@example.com" || page.Limit != 50 {
+		t.Errorf("Unexpected suppressions: %+v", page)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, adminRequest("/api/admin/notifications/suppressions?limit=1000"))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized limit, got %d", rr.Code)
+	}
+
+	remove := func() int {
+		req := adminRequest("/api/admin/notifications/suppressions/gone%40example.com")
+		req.Method = http.MethodDelete
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := remove(); code != http.StatusNoContent || len(store.suppressions) != 0 {
+		t.Errorf("Expected the suppression to be cleared, got %d", code)
+	}
+	if code := remove(); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an address that is not suppressed, got %d", code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, authenticatedRequest(http.MethodGet, "/api/admin/notifications/suppressions", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// ErrorCodeAccountSuspended is returned with 403 to suspended users on every path they are refused
+const ErrorCodeAccountSuspended = apierror.CodeAccountSuspended
+
+// accountSuspendedMessage is shown to suspended users
+const accountSuspendedMessage = "This account has been suspended; contact support"
+
+// SuspensionStore suspends accounts and lifts suspensions
+type SuspensionStore interface {
+	SuspendUser(ctx context.Context, userID int, suspendedBy *int, reason string) (*database.Suspension, error)
+	UnsuspendUser(ctx context.Context, userID int) (bool, error)
+	GetSuspension(ctx context.Context, userID int) (*database.Suspension, error)
+}
+
+// SuspensionHandler lets admins suspend abusive or compromised accounts
+type SuspensionHandler struct {
+	store      SuspensionStore
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+}
+
+// NewSuspensionHandler creates a new suspension handler
+func NewSuspensionHandler(store SuspensionStore, authorizer authz.Authorizer, logger *logger.Logger) *SuspensionHandler {
+	return &SuspensionHandler{
+		store:      store,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "suspension_handler"),
+	}
+}
+
+// SuspendUserRequest suspends an account
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate checks the suspension is explained
+func (req *SuspendUserRequest) Validate(v *validate.Validator) {
+	v.Check(req.Reason != "", "reason", validate.CodeRequired, "reason is required")
+}
+
+// SuspensionResponse reports whether an account is suspended
+type SuspensionResponse struct {
+	UserID     int                  `json:"user_id"`
+	Suspended  bool                 `json:"suspended"`
+	Suspension *database.Suspension `json:"suspension,omitempty"`
+}
+
+// Get handles GET /api/v1/admin/users/{id}/suspension
+func (h *SuspensionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	subject, userID, ok := h.authorize(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	suspension, err := h.store.GetSuspension(r.Context(), userID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, userID, "Failed to read suspension")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, SuspensionResponse{UserID: userID, Suspended: suspension != nil, Suspension: suspension})
+}
+
+// Suspend handles PUT /api/v1/admin/users/{id}/suspension with {"reason"}. The user is signed
+// out of every session and refused sign-in, session refresh and manual syncs with 403
+// ACCOUNT_SUSPENDED, and scheduled syncs skip them until the suspension is lifted.
+func (h *SuspensionHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	subject, userID, ok := h.authorize(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req SuspendUserRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if userID == subject.UserID {
+		h.writeErrorResponse(w, http.StatusBadRequest, "CANNOT_SUSPEND_SELF", "You cannot suspend your own account")
+		return
+	}
+
+	suspendedBy := subject.UserID
+	suspension, err := h.store.SuspendUser(r.Context(), userID, &suspendedBy, req.Reason)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, userID, "Failed to suspend user")
+		return
+	}
+	if suspension == nil {
+		h.writeJSON(w, http.StatusOK, map[string]string{"message": "User is already suspended"})
+		return
+	}
+
+	h.logger.Warn("User account suspended",
+		"user_id", subject.UserID,
+		"target_user_id", userID,
+		"reason", req.Reason)
+	h.writeJSON(w, http.StatusOK, SuspensionResponse{UserID: userID, Suspended: true, Suspension: suspension})
+}
+
+// Unsuspend handles DELETE /api/v1/admin/users/{id}/suspension
+func (h *SuspensionHandler) Unsuspend(w http.ResponseWriter, r *http.Request) {
+	subject, userID, ok := h.authorize(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+
+	lifted, err := h.store.UnsuspendUser(r.Context(), userID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, userID, "Failed to lift suspension")
+		return
+	}
+	if lifted {
+		h.logger.Info("User account suspension lifted",
+			"user_id", subject.UserID,
+			"target_user_id", userID)
+	}
+	h.writeJSON(w, http.StatusOK, SuspensionResponse{UserID: userID, Suspended: false})
+}
+
+// authorize parses the target user ID and checks the caller may act on their suspension,
+// writing the error response if not
+func (h *SuspensionHandler) authorize(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, int, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Suspensions called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return subject, 0, false
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || userID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid user ID is required")
+		return subject, 0, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.UserSuspension(userID)); err != nil {
+		h.logger.Warn("Suspensions denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID,
+			"target_user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only admins may suspend accounts")
+		return subject, 0, false
+	}
+	return subject, userID, true
+}
+
+func (h *SuspensionHandler) writeStoreError(w http.ResponseWriter, err error, userID, targetUserID int, message string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+		return
+	}
+	h.logger.Error(message,
+		"error", err,
+		"user_id", userID,
+		"target_user_id", targetUserID)
+	h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", message)
+}
+
+func (h *SuspensionHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode suspension response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *SuspensionHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
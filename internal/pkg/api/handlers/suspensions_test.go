@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSuspensionStore struct {
+	users       map[int]bool
+	suspensions map[int]*database.Suspension
+}
+
+func (m *mockSuspensionStore) SuspendUser(ctx context.Context, userID int, suspendedBy *int, reason string) (*database.Suspension, error) {
+	if !m.users[userID] {
+		return nil, sql.ErrNoRows
+	}
+	if m.suspensions[userID] != nil {
+		return nil, nil
+	}
+	suspension := &database.Suspension{UserID: userID, SuspendedAt: time.Now(), SuspendedBy: suspendedBy, Reason: reason}
+	m.suspensions[userID] = suspension
+	return suspension, nil
+}
+
+func (m *mockSuspensionStore) UnsuspendUser(ctx context.Context, userID int) (bool, error) {
+	if !m.users[userID] {
+		return false, sql.ErrNoRows
+	}
+	lifted := m.suspensions[userID] != nil
+	delete(m.suspensions, userID)
+	return lifted, nil
+}
+
+func (m *mockSuspensionStore) GetSuspension(ctx context.Context, userID int) (*database.Suspension, error) {
+	if !m.users[userID] {
+		return nil, sql.ErrNoRows
+	}
+	return m.suspensions[userID], nil
+}
+
+func TestSuspensionHandler(t *testing.T) {
+	store := &mockSuspensionStore{users: map[int]bool{1: true, 7: true}, suspensions: make(map[int]*database.Suspension)}
+	handler := NewSuspensionHandler(store, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/users/{id}/suspension", handler.Get)
+	router.Put("/api/admin/users/{id}/suspension", handler.Suspend)
+	router.Delete("/api/admin/users/{id}/suspension", handler.Unsuspend)
+
+	call := func(method, target, body string, userID int, roles ...authz.Role) *httptest.ResponseRecorder {
+		req := authenticatedRequest(method, target, body, userID)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, roles))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := call(http.MethodPut, "/api/admin/users/7/suspension", `{}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a reason, got %d", rr.Code)
+	}
+	if rr := call(http.MethodPut, "/api/admin/users/1/suspension", `{"reason": "x"}`, 1, authz.RoleAdmin); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 when admins suspend themselves, got %d", rr.Code)
+	}
+	if rr := call(http.MethodPut, "/api/admin/users/7/suspension", `{"reason": "x"}`, 4, authz.RoleAthlete, authz.RoleSupport); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for support staff, got %d", rr.Code)
+	}
+	if rr := call(http.MethodPut, "/api/admin/users/9/suspension", `{"reason": "x"}`, 1, authz.RoleAdmin); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown user, got %d", rr.Code)
+	}
+
+	rr := call(http.MethodPut, "/api/admin/users/7/suspension", `{"reason": "Compromised account"}`, 1, authz.RoleAdmin)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if suspension := store.suspensions[7]; suspension == nil || *suspension.SuspendedBy != 1 || suspension.Reason != "Compromised account" {
+		t.Errorf("Expected the suspension to be stored, got %+v", suspension)
+	}
+
+	// Support staff may check whether an account is suspended
+	rr = call(http.MethodGet, "/api/admin/users/7/suspension", "", 4, authz.RoleAthlete, authz.RoleSupport)
+	var response SuspensionResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || !response.Suspended {
+		t.Fatalf("Expected the account to be reported suspended, got %d %+v (%v)", rr.Code, response, err)
+	}
+
+	if rr := call(http.MethodDelete, "/api/admin/users/7/suspension", "", 1, authz.RoleAdmin); rr.Code != http.StatusOK || store.suspensions[7] != nil {
+		t.Errorf("Expected the suspension to be lifted, got %d", rr.Code)
+	}
+}
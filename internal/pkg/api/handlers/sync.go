@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+// JobQueue is the subset of the job queue used by the sync handler
+type JobQueue interface {
+	Enqueue(ctx context.Context, job *queue.Job) error
+	SetResult(ctx context.Context, result *queue.JobResult) error
+	GetResult(ctx context.Context, traceID string) (*queue.JobResult, error)
+}
+
+// JobOutbox records jobs in the database for the outbox relay to publish
+type JobOutbox interface {
+	AddOutboxJob(ctx context.Context, job *database.OutboxJob) error
+}
+
+// BlackoutSchedule reports the blackout window in effect, during which manual syncs are refused
+type BlackoutSchedule interface {
+	GetActiveBlackout(ctx context.Context, at time.Time) (*database.BlackoutWindow, error)
+}
+
+// UserTimezones looks up users, whose local time a blackout's end is shown in and sync date
+// ranges are read in
+type UserTimezones interface {
+	GetUserByID(ctx context.Context, id int) (*database.User, error)
+}
+
+// UserAccounts looks up users, whose manual syncs are refused while their account is suspended
+type UserAccounts interface {
+	GetUserByID(ctx context.Context, id int) (*database.User, error)
+}
+
+// JobEventSubscriber delivers a user's job status events until ctx is done
+type JobEventSubscriber interface {
+	SubscribeJobEvents(ctx context.Context, userID int) (<-chan queue.JobEvent, error)
+}
+
+// SyncHandler handles manual sync requests
+type SyncHandler struct {
+	jobQueue   JobQueue
+	authorizer authz.Authorizer
+	logger     *logger.Logger
+
+	// Optional; without them manual syncs are never paused and date ranges are read in UTC
+	blackouts BlackoutSchedule
+	users     UserTimezones
+
+	// Optional; without it jobs are pushed onto the queue directly
+	outbox JobOutbox
+
+	// Optional; without it suspended accounts are not refused
+	accounts UserAccounts
+
+	// Optional; without it the live status stream is unavailable
+	events JobEventSubscriber
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(jobQueue JobQueue, authorizer authz.Authorizer, logger *logger.Logger) *SyncHandler {
+	return &SyncHandler{
+		jobQueue:   jobQueue,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "sync_handler"),
+	}
+}
+
+// SetBlackouts makes manual syncs wait for the end of any blackout window in effect
+func (h *SyncHandler) SetBlackouts(blackouts BlackoutSchedule, users UserTimezones) {
+	h.blackouts = blackouts
+	h.users = users
+}
+
+// SetOutbox records manual syncs in the job outbox instead of pushing them onto the queue, so a
+// brief Redis outage cannot lose them; the outbox relay publishes them
+func (h *SyncHandler) SetOutbox(outbox JobOutbox) {
+	h.outbox = outbox
+}
+
+// SetAccounts refuses manual syncs and backfills of suspended accounts with 403 ACCOUNT_SUSPENDED,
+// and with 503 when the account status cannot be read
+func (h *SyncHandler) SetAccounts(accounts UserAccounts) {
+	h.accounts = accounts
+}
+
+// SetEvents enables the live sync status stream
+func (h *SyncHandler) SetEvents(events JobEventSubscriber) {
+	h.events = events
+}
+
+// syncDateLayout is the format of the from and to dates of a manual sync
+const syncDateLayout = "2006-01-02"
+
+// maxSyncRangeDays caps the date range of one manual sync; older history is imported by backfills
+const maxSyncRangeDays = 92
+
+// TriggerSyncRequest represents the optional request body for a manual sync
+type TriggerSyncRequest struct {
+	// DryRun previews the rows that would be written without modifying the spreadsheet
+	DryRun bool `json:"dry_run"`
+
+	// From and To (YYYY-MM-DD, inclusive, in the user's timezone) sync a fixed range of days
+	// instead of the default lookback window; both are set or neither
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// Validate checks that a date range, when given, is complete, ordered, in the past and capped
+func (req *TriggerSyncRequest) Validate(v *validate.Validator) {
+	if req.From == "" && req.To == "" {
+		return
+	}
+
+	from, fromErr := time.Parse(syncDateLayout, req.From)
+	to, toErr := time.Parse(syncDateLayout, req.To)
+	fromValid := v.Check(req.From != "", "from", validate.CodeRequired, "from is required when to is set") &&
+		v.Check(fromErr == nil, "from", validate.CodeInvalid, "from must be a date in YYYY-MM-DD format")
+	toValid := v.Check(req.To != "", "to", validate.CodeRequired, "to is required when from is set") &&
+		v.Check(toErr == nil, "to", validate.CodeInvalid, "to must be a date in YYYY-MM-DD format")
+	if !fromValid || !toValid {
+		return
+	}
+
+	// Dates are checked in UTC; a day of slack keeps users ahead of UTC from being refused today
+	switch {
+	case to.Before(from):
+		v.Add("to", validate.CodeInvalid, "to must not be before from")
+	case from.After(time.Now().UTC().AddDate(0, 0, 1)):
+		v.Add("from", validate.CodeInvalid, "from must not be in the future")
+	case to.Sub(from) >= maxSyncRangeDays*24*time.Hour:
+		v.Add("to", validate.CodeInvalid, fmt.Sprintf("A sync may cover at most %d days", maxSyncRangeDays))
+	}
+}
+
+// hasRange reports whether the request syncs a fixed date range
+func (req *TriggerSyncRequest) hasRange() bool {
+	return req.From != "" && req.To != ""
+}
+
+// TriggerSyncResponse represents the response for an accepted manual sync
+type TriggerSyncResponse struct {
+	TraceID string          `json:"trace_id"`
+	Status  queue.JobStatus `json:"status"`
+	DryRun  bool            `json:"dry_run"`
+	From    string          `json:"from,omitempty"`
+	To      string          `json:"to,omitempty"`
+}
+
+// TriggerSync handles POST /api/v1/sync requests
+func (h *SyncHandler) TriggerSync(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("TriggerSync called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionSync, authz.Activities(userID)); err != nil {
+		h.logger.Warn("TriggerSync denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to sync these activities")
+		return
+	}
+
+	if h.refuseSuspended(w, r.Context(), userID) {
+		return
+	}
+
+	// The body is optional; an empty body means a regular sync
+	var req TriggerSyncRequest
+	if !decodeOptionalRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if blackout := h.activeBlackout(r.Context(), userID); blackout != nil {
+		h.logger.Info("Manual sync refused during blackout window",
+			"user_id", userID,
+			"blackout_id", blackout.ID,
+			"ends_at", blackout.EndsAt)
+		h.writeSyncPaused(w, r.Context(), userID, blackout)
+		return
+	}
+
+	job := &queue.Job{
+		UserID:      userID,
+		TriggerType: queue.TriggerManualSync,
+		DryRun:      req.DryRun,
+	}
+	if req.hasRange() {
+		// The range covers whole days in the user's timezone; the worker treats SyncTo as exclusive
+		loc := h.userLocation(r.Context(), userID)
+		from, _ := time.ParseInLocation(syncDateLayout, req.From, loc)
+		to, _ := time.ParseInLocation(syncDateLayout, req.To, loc)
+		to = to.AddDate(0, 0, 1)
+		job.SyncFrom, job.SyncTo = &from, &to
+	}
+	if err := h.enqueue(r.Context(), job); err != nil {
+		h.logger.Error("Failed to enqueue manual sync job",
+			"error", err,
+			"user_id", userID,
+			"dry_run", req.DryRun,
+			"from", req.From,
+			"to", req.To,
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Sync could not be scheduled, please try again later")
+		return
+	}
+
+	h.logger.Info("Manual sync job enqueued",
+		"user_id", userID,
+		"trace_id", job.TraceID,
+		"dry_run", req.DryRun,
+		"from", req.From,
+		"to", req.To,
+		"client_ip", clientIP)
+
+	h.writeJSON(w, http.StatusAccepted, TriggerSyncResponse{
+		TraceID: job.TraceID,
+		Status:  queue.JobStatusQueued,
+		DryRun:  req.DryRun,
+		From:    req.From,
+		To:      req.To,
+	})
+}
+
+// TriggerBackfillRequest represents the optional request body for a historical backfill
+type TriggerBackfillRequest struct {
+	// From (YYYY-MM-DD, in the user's timezone) is where the import starts; empty imports the
+	// athlete's full Strava history
+	From string `json:"from,omitempty"`
+}
+
+// Validate checks that from, when given, is a past date
+func (req *TriggerBackfillRequest) Validate(v *validate.Validator) {
+	if req.From == "" {
+		return
+	}
+	from, err := time.Parse(syncDateLayout, req.From)
+	if v.Check(err == nil, "from", validate.CodeInvalid, "from must be a date in YYYY-MM-DD format") {
+		v.Check(from.Before(time.Now().UTC()), "from", validate.CodeInvalid, "from must be in the past")
+	}
+}
+
+// TriggerBackfill handles POST /api/v1/sync/backfill requests. The import runs as a chain of
+// backfill jobs sharing one trace ID, each importing monthly windows for a bounded time.
+func (h *SyncHandler) TriggerBackfill(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionSync, authz.Activities(userID)); err != nil {
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to sync these activities")
+		return
+	}
+
+	if h.refuseSuspended(w, r.Context(), userID) {
+		return
+	}
+
+	var req TriggerBackfillRequest
+	if !decodeOptionalRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	if blackout := h.activeBlackout(r.Context(), userID); blackout != nil {
+		h.writeSyncPaused(w, r.Context(), userID, blackout)
+		return
+	}
+
+	job := &queue.Job{
+		UserID:      userID,
+		TriggerType: queue.TriggerBackfill,
+	}
+	if req.From != "" {
+		from, _ := time.ParseInLocation(syncDateLayout, req.From, h.userLocation(r.Context(), userID))
+		job.BackfillFrom = &from
+	}
+	if err := h.enqueue(r.Context(), job); err != nil {
+		h.logger.Error("Failed to enqueue backfill job",
+			"error", err,
+			"user_id", userID,
+			"from", req.From)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Backfill could not be scheduled, please try again later")
+		return
+	}
+
+	h.logger.Info("Backfill job enqueued",
+		"user_id", userID,
+		"trace_id", job.TraceID,
+		"from", req.From)
+
+	h.writeJSON(w, http.StatusAccepted, TriggerSyncResponse{
+		TraceID: job.TraceID,
+		Status:  queue.JobStatusQueued,
+		From:    req.From,
+	})
+}
+
+// GetSyncResult handles GET /api/v1/sync/{traceID} requests
+func (h *SyncHandler) GetSyncResult(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	traceID := chi.URLParam(r, "traceID")
+	result, err := h.jobQueue.GetResult(r.Context(), traceID)
+	if err != nil {
+		h.logger.Error("Failed to read sync job result",
+			"error", err,
+			"user_id", userID,
+			"trace_id", traceID)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Sync status is temporarily unavailable")
+		return
+	}
+
+	// Jobs the user may not read are reported as not found so trace IDs cannot be probed
+	if result == nil || h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.SyncJob(result.UserID, traceID)) != nil {
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Sync job not found")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// enqueue records job in the outbox when one is configured, or pushes it onto the queue
+func (h *SyncHandler) enqueue(ctx context.Context, job *queue.Job) error {
+	if h.outbox == nil {
+		return h.jobQueue.Enqueue(ctx, job)
+	}
+
+	outboxJob, err := queue.NewOutboxJob(job)
+	if err != nil {
+		return err
+	}
+	if err := h.outbox.AddOutboxJob(ctx, outboxJob); err != nil {
+		return err
+	}
+
+	// Polling finds the job as queued before the relay publishes it; the relay sets the same
+	// status, so a failure here only delays it
+	if err := h.jobQueue.SetResult(ctx, &queue.JobResult{
+		TraceID: job.TraceID,
+		UserID:  job.UserID,
+		Status:  queue.JobStatusQueued,
+		DryRun:  job.DryRun,
+	}); err != nil {
+		h.logger.Warn("Failed to mark outbox job as queued",
+			"error", err,
+			"user_id", job.UserID,
+			"trace_id", job.TraceID)
+	}
+	return nil
+}
+
+// activeBlackout returns the blackout in effect, if any. Failing to read the windows lets the
+// sync through; the engine still defers jobs it dequeues during a blackout.
+func (h *SyncHandler) activeBlackout(ctx context.Context, userID int) *database.BlackoutWindow {
+	if h.blackouts == nil {
+		return nil
+	}
+	blackout, err := h.blackouts.GetActiveBlackout(ctx, time.Now())
+	if err != nil {
+		h.logger.Warn("Failed to read blackout windows, allowing the sync",
+			"error", err,
+			"user_id", userID)
+		return nil
+	}
+	return blackout
+}
+
+// refuseSuspended writes an error response and returns true when the user's account is
+// suspended or its status cannot be read; a failed lookup refuses the sync rather than risk
+// running one for a suspended account
+func (h *SyncHandler) refuseSuspended(w http.ResponseWriter, ctx context.Context, userID int) bool {
+	if h.accounts == nil {
+		return false
+	}
+	user, err := h.accounts.GetUserByID(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to read account status, refusing the sync",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Account status is temporarily unavailable, please try again later")
+		return true
+	}
+	if user != nil && user.Suspended() {
+		h.logger.Warn("Sync refused for suspended account",
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, ErrorCodeAccountSuspended, accountSuspendedMessage)
+		return true
+	}
+	return false
+}
+
+// userLocation returns the user's timezone, or UTC when it is unknown
+func (h *SyncHandler) userLocation(ctx context.Context, userID int) *time.Location {
+	if h.users != nil {
+		if user, err := h.users.GetUserByID(ctx, userID); err == nil && user != nil && user.Timezone != "" {
+			if loc, err := time.LoadLocation(user.Timezone); err == nil {
+				return loc
+			}
+		}
+	}
+	return time.UTC
+}
+
+// writeSyncPaused tells the user when to try again, in their own timezone
+func (h *SyncHandler) writeSyncPaused(w http.ResponseWriter, ctx context.Context, userID int, blackout *database.BlackoutWindow) {
+	loc := h.userLocation(ctx, userID)
+
+	retryAfter := time.Until(blackout.EndsAt).Round(time.Second)
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	message := fmt.Sprintf("Syncing is paused for scheduled maintenance, please try again after %s", tryAgainAfter(blackout.EndsAt, time.Now(), loc))
+	response := newErrorResponse("SYNC_PAUSED", message).WithDetail("retry_after", blackout.EndsAt)
+	if err := apierror.Write(w, http.StatusServiceUnavailable, response); err != nil {
+		h.logger.Error("Failed to encode error response", "error", err, "error_code", "SYNC_PAUSED")
+	}
+}
+
+// tryAgainAfter formats end as local HH:MM, with the date when it is not today and the zone
+// when the user's timezone is unknown
+func tryAgainAfter(end, now time.Time, loc *time.Location) string {
+	end, now = end.In(loc), now.In(loc)
+	layout := "15:04"
+	if end.YearDay() != now.YearDay() || end.Year() != now.Year() {
+		layout = "Mon 2 Jan 15:04"
+	}
+	if loc == time.UTC {
+		layout += " UTC"
+	}
+	return end.Format(layout)
+}
+
+func (h *SyncHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode sync response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *SyncHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+)
+
+// streamKeepAlive is how often an idle stream sends a comment, so proxies do not close it
+const streamKeepAlive = 15 * time.Second
+
+// streamRetry is the reconnect delay, in milliseconds, suggested to the browser's EventSource
+const streamRetry = 5000
+
+// StreamSyncStatus handles GET /api/v1/sync/stream requests. It pushes the user's job status
+// changes (queued, running, completed with the run summary, failed, deferred) as Server-Sent
+// Events named "status" until the client disconnects. Completed processing steps of running
+// jobs arrive as "checkpoint" events. Events are not replayed on reconnect;
+// GET /api/v1/sync/{traceID} returns the current state of a job.
+func (h *SyncHandler) StreamSyncStatus(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.SyncJob(userID, "")); err != nil {
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to follow these sync jobs")
+		return
+	}
+
+	if h.events == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "STREAM_UNAVAILABLE", "Live sync status is unavailable, poll the sync job instead")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logger.Error("Response writer does not support streaming", "user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "STREAM_UNAVAILABLE", "Live sync status is unavailable, poll the sync job instead")
+		return
+	}
+
+	events, err := h.events.SubscribeJobEvents(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to subscribe to job events",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "QUEUE_UNAVAILABLE", "Sync status is temporarily unavailable")
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debug("Could not clear write deadline for sync stream", "error", err, "user_id", userID)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	flusher.Flush()
+
+	h.logger.Info("Sync status stream opened", "user_id", userID)
+	defer h.logger.Info("Sync status stream closed", "user_id", userID)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Error("Failed to encode job event", "error", err, "user_id", userID, "trace_id", event.TraceID)
+				continue
+			}
+			name := "status"
+			if event.Checkpoint != nil {
+				name = "checkpoint"
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+type mockJobEvents struct {
+	events       []queue.JobEvent
+	subscribedTo int
+}
+
+func (m *mockJobEvents) SubscribeJobEvents(ctx context.Context, userID int) (<-chan queue.JobEvent, error) {
+	m.subscribedTo = userID
+	events := make(chan queue.JobEvent, len(m.events))
+	for _, event := range m.events {
+		events <- event
+	}
+	close(events)
+	return events, nil
+}
+
+func TestSyncHandler_StreamSyncStatus(t *testing.T) {
+	events := &mockJobEvents{events: []queue.JobEvent{
+		{TraceID: "trace-1", UserID: 5, Status: queue.JobStatusRunning},
+		{TraceID: "trace-1", UserID: 5, Status: queue.JobStatusRunning, Checkpoint: &queue.JobCheckpoint{TraceID: "trace-1", UserID: 5, Step: queue.StepConfigLoaded}},
+		{TraceID: "trace-1", UserID: 5, Status: queue.JobStatusCompleted},
+	}}
+	handler := NewSyncHandler(&mockJobQueue{}, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetEvents(events)
+
+	rr := httptest.NewRecorder()
+	handler.StreamSyncStatus(rr, authenticatedRequest(http.MethodGet, "/api/sync/stream", "", 5))
+
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if events.subscribedTo != 5 {
+		t.Errorf("Expected to subscribe to user 5, got %d", events.subscribedTo)
+	}
+
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, "retry: 5000\n\n") {
+		t.Errorf("Expected a retry hint first, got %q", body)
+	}
+	if strings.Count(body, "event: status\n") != 2 || !strings.Contains(body, `data: {"trace_id":"trace-1","user_id":5,"status":"completed"`) {
+		t.Errorf("Expected two status events, got %q", body)
+	}
+	if strings.Count(body, "event: checkpoint\n") != 1 || !strings.Contains(body, `"step":"config_loaded"`) {
+		t.Errorf("Expected one checkpoint event, got %q", body)
+	}
+}
+
+func TestSyncHandler_StreamSyncStatusUnavailable(t *testing.T) {
+	handler := NewSyncHandler(&mockJobQueue{}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.StreamSyncStatus(rr, authenticatedRequest(http.MethodGet, "/api/sync/stream", "", 5))
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), "STREAM_UNAVAILABLE") {
+		t.Errorf("Expected 503 STREAM_UNAVAILABLE, got %d %s", rr.Code, rr.Body.String())
+	}
+}
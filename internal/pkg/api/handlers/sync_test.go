@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+type mockJobQueue struct {
+	enqueued   []*queue.Job
+	results    map[string]*queue.JobResult
+	enqueueErr error
+}
+
+func (m *mockJobQueue) Enqueue(ctx context.Context, job *queue.Job) error {
+	if m.enqueueErr != nil {
+		return m.enqueueErr
+	}
+	job.TraceID = "trace-123"
+	m.enqueued = append(m.enqueued, job)
+	return nil
+}
+
+func (m *mockJobQueue) SetResult(ctx context.Context, result *queue.JobResult) error {
+	if m.results == nil {
+		m.results = make(map[string]*queue.JobResult)
+	}
+	m.results[result.TraceID] = result
+	return nil
+}
+
+func (m *mockJobQueue) GetResult(ctx context.Context, traceID string) (*queue.JobResult, error) {
+	return m.results[traceID], nil
+}
+
+func authenticatedRequest(method, target, body string, userID int) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+}
+
+func TestSyncHandler_TriggerSync(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedDryRun bool
+	}{
+		{"Empty body", "", false},
+		{"Dry run", `{"dry_run": true}`, true},
+		{"Explicit regular sync", `{"dry_run": false}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := &mockJobQueue{}
+			handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+
+			rr := httptest.NewRecorder()
+			handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", tt.body, 5))
+
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if len(jobQueue.enqueued) != 1 {
+				t.Fatalf("Expected 1 enqueued job, got %d", len(jobQueue.enqueued))
+			}
+
+			job := jobQueue.enqueued[0]
+			if job.UserID != 5 || job.TriggerType != queue.TriggerManualSync || job.DryRun != tt.expectedDryRun {
+				t.Errorf("Unexpected job: %+v", job)
+			}
+
+			var response TriggerSyncResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.TraceID != "trace-123" || response.DryRun != tt.expectedDryRun {
+				t.Errorf("Unexpected response: %+v", response)
+			}
+		})
+	}
+}
+
+func TestSyncHandler_TriggerSyncErrors(t *testing.T) {
+	handler := NewSyncHandler(&mockJobQueue{}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "{invalid", 5))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid JSON, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerSync(rr, httptest.NewRequest(http.MethodPost, "/api/sync", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without user, got %d", rr.Code)
+	}
+
+	handler = NewSyncHandler(&mockJobQueue{enqueueErr: errors.New("redis down")}, authz.DefaultPolicy(), logger.New("test"))
+	rr = httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when queue is down, got %d", rr.Code)
+	}
+}
+
+func TestSyncHandler_RefusesSuspendedAccount(t *testing.T) {
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetAccounts(suspendedAccounts{})
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), ErrorCodeAccountSuspended) {
+		t.Errorf("Expected status 403 ACCOUNT_SUSPENDED, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", "", 5))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a backfill, got %d", rr.Code)
+	}
+
+	if len(jobQueue.enqueued) != 0 {
+		t.Errorf("Expected no jobs for a suspended account, got %d", len(jobQueue.enqueued))
+	}
+}
+
+// failingAccounts fails every account lookup
+type failingAccounts struct{}
+
+func (failingAccounts) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestSyncHandler_RefusesWhenAccountStatusUnavailable(t *testing.T) {
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetAccounts(failingAccounts{})
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when the account status cannot be read, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", "", 5))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 for a backfill, got %d", rr.Code)
+	}
+
+	if len(jobQueue.enqueued) != 0 {
+		t.Errorf("Expected no jobs when the account status cannot be read, got %d", len(jobQueue.enqueued))
+	}
+}
+
+// mockUserTimezones returns every user in timezone
+type mockUserTimezones struct {
+	timezone string
+}
+
+func (m mockUserTimezones) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return &database.User{ID: id, Timezone: m.timezone}, nil
+}
+
+func TestSyncHandler_TriggerSyncDateRange(t *testing.T) {
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetBlackouts(&mockBlackoutStore{}, mockUserTimezones{timezone: "Europe/Sofia"})
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", `{"from": "2024-03-04", "to": "2024-03-10"}`, 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// Whole days in the user's timezone, with an exclusive end
+	job := jobQueue.enqueued[0]
+	if job.SyncFrom == nil || job.SyncTo == nil {
+		t.Fatalf("Expected the job to carry the range, got %+v", job)
+	}
+	if got := job.SyncFrom.UTC().Format(time.RFC3339); got != "2024-03-03T22:00:00Z" {
+		t.Errorf("Expected the range to start at local midnight, got %s", got)
+	}
+	if got := job.SyncTo.UTC().Format(time.RFC3339); got != "2024-03-10T22:00:00Z" {
+		t.Errorf("Expected the range to end after the last day, got %s", got)
+	}
+
+	var response TriggerSyncResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.From != "2024-03-04" || response.To != "2024-03-10" {
+		t.Errorf("Expected the range to be echoed, got %+v", response)
+	}
+
+	invalid := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"Missing to", `{"from": "2024-03-04"}`, `"field":"to"`},
+		{"Malformed from", `{"from": "04/03/2024", "to": "2024-03-10"}`, `"field":"from"`},
+		{"Reversed", `{"from": "2024-03-10", "to": "2024-03-04"}`, `"field":"to"`},
+		{"Too long", `{"from": "2023-01-01", "to": "2024-01-01"}`, `"field":"to"`},
+		{"Future", `{"from": "` + time.Now().AddDate(0, 0, 7).Format("2006-01-02") + `", "to": "` + time.Now().AddDate(0, 0, 8).Format("2006-01-02") + `"}`, `"field":"from"`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", tt.body, 5))
+			if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), tt.field) {
+				t.Errorf("Expected 400 for %s, got %d: %s", tt.field, rr.Code, rr.Body.String())
+			}
+		})
+	}
+	if len(jobQueue.enqueued) != 1 {
+		t.Errorf("Expected invalid ranges not to be enqueued, got %d jobs", len(jobQueue.enqueued))
+	}
+}
+
+func TestSyncHandler_TriggerBackfill(t *testing.T) {
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", "", 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if job := jobQueue.enqueued[0]; job.TriggerType != queue.TriggerBackfill || job.BackfillFrom != nil {
+		t.Errorf("Expected a full-history backfill job, got %+v", job)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", `{"from": "2021-01-01"}`, 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if job := jobQueue.enqueued[1]; job.BackfillFrom == nil || !job.BackfillFrom.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the backfill to start on 2021-01-01, got %+v", job.BackfillFrom)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", `{"from": "2999-01-01"}`, 5))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"field":"from"`) {
+		t.Errorf("Expected 400 for a future start, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// missingUsers finds no user, as for a still-valid token whose user was deleted
+type missingUsers struct{}
+
+func (missingUsers) GetUserByID(ctx context.Context, id int) (*database.User, error) {
+	return nil, nil
+}
+
+func TestSyncHandler_DateRangesForMissingUser(t *testing.T) {
+	jobQueue := &mockJobQueue{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetBlackouts(&mockBlackoutStore{}, missingUsers{})
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", `{"from": "2024-03-04", "to": "2024-03-10"}`, 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 for a sync, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if job := jobQueue.enqueued[0]; job.SyncFrom == nil || !job.SyncFrom.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the range to start at UTC midnight, got %+v", job.SyncFrom)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.TriggerBackfill(rr, authenticatedRequest(http.MethodPost, "/api/sync/backfill", `{"from": "2021-01-01"}`, 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 for a backfill, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if job := jobQueue.enqueued[1]; job.BackfillFrom == nil || !job.BackfillFrom.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the backfill to start at UTC midnight, got %+v", job.BackfillFrom)
+	}
+}
+
+func TestSyncHandler_GetSyncResult(t *testing.T) {
+	jobQueue := &mockJobQueue{results: map[string]*queue.JobResult{
+		"trace-mine":  {TraceID: "trace-mine", UserID: 5, Status: queue.JobStatusCompleted, DryRun: true},
+		"trace-other": {TraceID: "trace-other", UserID: 6, Status: queue.JobStatusCompleted},
+	}}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/sync/{traceID}", handler.GetSyncResult)
+
+	tests := []struct {
+		traceID        string
+		expectedStatus int
+	}{
+		{"trace-mine", http.StatusOK},
+		{"trace-other", http.StatusNotFound},
+		{"trace-unknown", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.traceID, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, authenticatedRequest(http.MethodGet, "/api/sync/"+tt.traceID, "", 5))
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+type mockJobOutbox struct {
+	jobs []*database.OutboxJob
+	err  error
+}
+
+func (m *mockJobOutbox) AddOutboxJob(ctx context.Context, job *database.OutboxJob) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.jobs = append(m.jobs, job)
+	return nil
+}
+
+func TestSyncHandler_TriggerSyncThroughOutbox(t *testing.T) {
+	// Redis is down, but the job is recorded in the outbox
+	jobQueue := &mockJobQueue{enqueueErr: errors.New("redis down")}
+	outbox := &mockJobOutbox{}
+	handler := NewSyncHandler(jobQueue, authz.DefaultPolicy(), logger.New("test"))
+	handler.SetOutbox(outbox)
+
+	rr := httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response TriggerSyncResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(outbox.jobs) != 1 || outbox.jobs[0].UserID != 5 || outbox.jobs[0].TraceID != response.TraceID {
+		t.Errorf("Expected the job in the outbox under the returned trace ID: %+v", outbox.jobs)
+	}
+	if result := jobQueue.results[response.TraceID]; result == nil || result.Status != queue.JobStatusQueued {
+		t.Errorf("Expected the job to be marked queued, got %+v", result)
+	}
+
+	outbox.err = errors.New("database down")
+	rr = httptest.NewRecorder()
+	handler.TriggerSync(rr, authenticatedRequest(http.MethodPost, "/api/sync", "", 5))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when the outbox is unavailable, got %d", rr.Code)
+	}
+}
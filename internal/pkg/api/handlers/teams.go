@@ -0,0 +1,588 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+// Run log page sizes for GET /teams/{id}/athletes/{userID}/runs
+const (
+	defaultRunLogLimit = 20
+	maxRunLogLimit     = 100
+)
+
+// maxTeamNameLength matches teams.name
+const maxTeamNameLength = 100
+
+// TeamStore manages coaches' teams, their invitations and their athletes' sync status
+type TeamStore interface {
+	CreateTeam(ctx context.Context, team *database.Team) error
+	GetTeam(ctx context.Context, teamID int) (*database.Team, error)
+	ListTeamsByCoach(ctx context.Context, coachID int) ([]database.Team, error)
+	InviteAthlete(ctx context.Context, teamID int, email string) (*database.TeamAthlete, error)
+	ListTeamAthletes(ctx context.Context, teamID int) ([]database.TeamAthlete, error)
+	ListInvitations(ctx context.Context, userID int) ([]database.TeamInvitation, error)
+	AcceptInvitation(ctx context.Context, teamID, userID int) error
+	RemoveTeamMember(ctx context.Context, teamID, userID int) error
+	ListAthleteStatuses(ctx context.Context, teamID int) ([]database.AthleteSyncStatus, error)
+	SetTeamSpreadsheet(ctx context.Context, teamID int, spreadsheetID *string) error
+}
+
+// SpreadsheetResolver extracts the spreadsheet ID from a Google Sheets URL and checks the user's
+// Google token can write it, returning a *services.ConfigError if not
+type SpreadsheetResolver interface {
+	ResolveSpreadsheet(ctx context.Context, userID int, spreadsheetURL string) (string, error)
+}
+
+// RunLog lists a user's automation runs
+type RunLog interface {
+	ListRuns(ctx context.Context, userID, limit int) ([]database.AutomationRun, error)
+}
+
+// TeamHandler lets coaches build teams of athletes and follow their syncs, and athletes answer
+// their invitations
+type TeamHandler struct {
+	store        TeamStore
+	runs         RunLog
+	spreadsheets SpreadsheetResolver // Optional; see SetSpreadsheets
+	authorizer   authz.Authorizer
+	logger       *logger.Logger
+}
+
+// NewTeamHandler creates a new team handler
+func NewTeamHandler(store TeamStore, runs RunLog, authorizer authz.Authorizer, logger *logger.Logger) *TeamHandler {
+	return &TeamHandler{
+		store:      store,
+		runs:       runs,
+		authorizer: authorizer,
+		logger:     logger.WithContext("component", "team_handler"),
+	}
+}
+
+// SetSpreadsheets enables shared team spreadsheets, validated against the coach's Google access
+func (h *TeamHandler) SetSpreadsheets(resolver SpreadsheetResolver) {
+	h.spreadsheets = resolver
+}
+
+// CreateTeamRequest creates a team
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate checks the team is named
+func (req *CreateTeamRequest) Validate(v *validate.Validator) {
+	if v.Required("name", strings.TrimSpace(req.Name), "name is required") {
+		v.MaxLength("name", req.Name, maxTeamNameLength)
+	}
+}
+
+// InviteAthleteRequest invites an athlete to a team by the email they signed in with
+type InviteAthleteRequest struct {
+	Email string `json:"email"`
+}
+
+// Validate checks an email is given
+func (req *InviteAthleteRequest) Validate(v *validate.Validator) {
+	v.Required("email", strings.TrimSpace(req.Email), "email is required")
+}
+
+// SetTeamSpreadsheetRequest points a team at a coach-owned spreadsheet
+type SetTeamSpreadsheetRequest struct {
+	URL string `json:"url"`
+}
+
+// Validate checks a URL is given
+func (req *SetTeamSpreadsheetRequest) Validate(v *validate.Validator) {
+	v.Required("url", strings.TrimSpace(req.URL), "url is required")
+}
+
+// TeamsResponse lists a coach's teams
+type TeamsResponse struct {
+	Teams []database.Team `json:"teams"`
+}
+
+// TeamAthletesResponse lists the athletes invited to a team
+type TeamAthletesResponse struct {
+	Team     *database.Team         `json:"team"`
+	Athletes []database.TeamAthlete `json:"athletes"`
+}
+
+// AthleteStatusResponse is the coach dashboard: the sync status of every athlete on a team
+type AthleteStatusResponse struct {
+	Team     *database.Team               `json:"team"`
+	Athletes []database.AthleteSyncStatus `json:"athletes"`
+}
+
+// AthleteRunsResponse is an athlete's run log
+type AthleteRunsResponse struct {
+	UserID int                      `json:"user_id"`
+	Runs   []database.AutomationRun `json:"runs"`
+}
+
+// InvitationsResponse lists the signed-in user's pending invitations
+type InvitationsResponse struct {
+	Invitations []database.TeamInvitation `json:"invitations"`
+}
+
+// List handles GET /api/v1/teams, returning the teams the coach owns
+func (h *TeamHandler) List(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorizeOwnTeams(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	teams, err := h.store.ListTeamsByCoach(r.Context(), subject.UserID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, 0, "Failed to list teams")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, TeamsResponse{Teams: teams})
+}
+
+// Create handles POST /api/v1/teams with {"name"}; only coaches may create teams
+func (h *TeamHandler) Create(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorizeOwnTeams(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req CreateTeamRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	team := &database.Team{Name: strings.TrimSpace(req.Name), CoachUserID: subject.UserID}
+	if err := h.store.CreateTeam(r.Context(), team); err != nil {
+		h.writeStoreError(w, err, subject.UserID, 0, "Failed to create team")
+		return
+	}
+
+	h.logger.Info("Team created",
+		"user_id", subject.UserID,
+		"team_id", team.ID)
+	h.writeJSON(w, http.StatusCreated, team)
+}
+
+// Athletes handles GET /api/v1/teams/{id}/athletes, listing invited athletes and members
+func (h *TeamHandler) Athletes(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	athletes, err := h.store.ListTeamAthletes(r.Context(), team.ID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to list athletes")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, TeamAthletesResponse{Team: team, Athletes: athletes})
+}
+
+// Invite handles POST /api/v1/teams/{id}/athletes with {"email"}. The athlete must have signed in
+// once; they join the team when they accept the invitation.
+func (h *TeamHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req InviteAthleteRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	athlete, err := h.store.InviteAthlete(r.Context(), team.ID, strings.TrimSpace(req.Email))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		h.writeErrorResponse(w, http.StatusNotFound, "USER_NOT_FOUND", "No athlete with that email has signed up yet")
+		return
+	case errors.Is(err, database.ErrInviteCoach):
+		h.writeErrorResponse(w, http.StatusBadRequest, "CANNOT_INVITE_SELF", "Coaches cannot join their own team")
+		return
+	case errors.Is(err, database.ErrAlreadyInvited):
+		h.writeErrorResponse(w, http.StatusConflict, "ALREADY_INVITED", "The athlete is already invited to or on this team")
+		return
+	case err != nil:
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to invite athlete")
+		return
+	}
+
+	h.logger.Info("Athlete invited to team",
+		"user_id", subject.UserID,
+		"team_id", team.ID,
+		"athlete_user_id", athlete.UserID)
+	h.writeJSON(w, http.StatusCreated, athlete)
+}
+
+// RemoveAthlete handles DELETE /api/v1/teams/{id}/athletes/{userID}, removing a member or
+// withdrawing their invitation
+func (h *TeamHandler) RemoveAthlete(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+	athleteID, ok := h.athleteID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveTeamMember(r.Context(), team.ID, athleteID); err != nil {
+		h.writeMemberError(w, err, subject.UserID, team.ID, "Failed to remove athlete")
+		return
+	}
+
+	h.logger.Info("Athlete removed from team",
+		"user_id", subject.UserID,
+		"team_id", team.ID,
+		"athlete_user_id", athleteID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetSpreadsheet handles PUT /api/v1/teams/{id}/spreadsheet with {"url"}. Each member's synced
+// activities are then also written to their own tab of the spreadsheet with the coach's Google
+// token, so the coach must be able to edit it.
+func (h *TeamHandler) SetSpreadsheet(w http.ResponseWriter, r *http.Request) {
+	if h.spreadsheets == nil {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Team spreadsheets are not available")
+		return
+	}
+	subject, team, ok := h.loadTeam(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req SetTeamSpreadsheetRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	spreadsheetID, err := h.spreadsheets.ResolveSpreadsheet(r.Context(), subject.UserID, strings.TrimSpace(req.URL))
+	if err != nil {
+		var configErr *services.ConfigError
+		if errors.As(err, &configErr) {
+			h.writeErrorResponse(w, getStatusCodeForConfigError(configErr.Type), configErr.Type, configErr.Message)
+			return
+		}
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to validate spreadsheet")
+		return
+	}
+
+	if err := h.store.SetTeamSpreadsheet(r.Context(), team.ID, &spreadsheetID); err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to save team spreadsheet")
+		return
+	}
+
+	h.logger.Info("Team spreadsheet set",
+		"user_id", subject.UserID,
+		"team_id", team.ID)
+	team.SpreadsheetID = &spreadsheetID
+	h.writeJSON(w, http.StatusOK, team)
+}
+
+// ClearSpreadsheet handles DELETE /api/v1/teams/{id}/spreadsheet, stopping writes to the shared
+// spreadsheet. The spreadsheet itself is left as it is.
+func (h *TeamHandler) ClearSpreadsheet(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	if err := h.store.SetTeamSpreadsheet(r.Context(), team.ID, nil); err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to clear team spreadsheet")
+		return
+	}
+
+	h.logger.Info("Team spreadsheet cleared",
+		"user_id", subject.UserID,
+		"team_id", team.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AthleteStatus handles GET /api/v1/teams/{id}/athletes/status, the coach dashboard: whether each
+// member's connections are set up, when they last synced and how their latest run went
+func (h *TeamHandler) AthleteStatus(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	statuses, err := h.store.ListAthleteStatuses(r.Context(), team.ID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to load athlete status")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, AthleteStatusResponse{Team: team, Athletes: statuses})
+}
+
+// AthleteRuns handles GET /api/v1/teams/{id}/athletes/{userID}/runs?limit=20, a member's most
+// recent runs with their error types
+func (h *TeamHandler) AthleteRuns(w http.ResponseWriter, r *http.Request) {
+	subject, team, ok := h.loadTeam(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+	athleteID, ok := h.athleteID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultRunLogLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxRunLogLimit {
+			h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	athletes, err := h.store.ListTeamAthletes(r.Context(), team.ID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to load run log")
+		return
+	}
+	if !activeMember(athletes, athleteID) {
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "The athlete is not a member of this team")
+		return
+	}
+
+	// Membership alone is not enough: the policy decides whether the subject may read the runs
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionRead, authz.Runs(athleteID)); err != nil {
+		h.logger.Warn("Run log denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID,
+			"team_id", team.ID,
+			"athlete_user_id", athleteID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Access denied")
+		return
+	}
+
+	runs, err := h.runs.ListRuns(r.Context(), athleteID, limit)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, team.ID, "Failed to load run log")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, AthleteRunsResponse{UserID: athleteID, Runs: runs})
+}
+
+// Invitations handles GET /api/v1/teams/invitations, the signed-in user's pending invitations
+func (h *TeamHandler) Invitations(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorizeMembership(w, r, authz.ActionRead)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.store.ListInvitations(r.Context(), subject.UserID)
+	if err != nil {
+		h.writeStoreError(w, err, subject.UserID, 0, "Failed to list invitations")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, InvitationsResponse{Invitations: invitations})
+}
+
+// AcceptInvitation handles POST /api/v1/teams/{id}/membership. From then on the team's coach may
+// read the athlete's sync status and run log.
+func (h *TeamHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorizeMembership(w, r, authz.ActionUpdate)
+	if !ok {
+		return
+	}
+	teamID, ok := h.teamID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.AcceptInvitation(r.Context(), teamID, subject.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "No pending invitation to this team")
+			return
+		}
+		h.writeStoreError(w, err, subject.UserID, teamID, "Failed to accept invitation")
+		return
+	}
+
+	h.logger.Info("Team invitation accepted",
+		"user_id", subject.UserID,
+		"team_id", teamID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LeaveTeam handles DELETE /api/v1/teams/{id}/membership, declining an invitation or leaving the
+// team. The coach loses access to the athlete's data right away.
+func (h *TeamHandler) LeaveTeam(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.authorizeMembership(w, r, authz.ActionDelete)
+	if !ok {
+		return
+	}
+	teamID, ok := h.teamID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveTeamMember(r.Context(), teamID, subject.UserID); err != nil {
+		h.writeMemberError(w, err, subject.UserID, teamID, "Failed to leave team")
+		return
+	}
+
+	h.logger.Info("Athlete left team",
+		"user_id", subject.UserID,
+		"team_id", teamID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// activeMember reports whether userID accepted their invitation
+func activeMember(athletes []database.TeamAthlete, userID int) bool {
+	for _, athlete := range athletes {
+		if athlete.UserID == userID && athlete.Status == database.TeamMemberActive {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeOwnTeams checks the caller may act on teams they own, writing the error response if not
+func (h *TeamHandler) authorizeOwnTeams(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.Team(subject.UserID, 0)); err != nil {
+		h.logger.Warn("Teams denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Only coaches may manage teams")
+		return subject, false
+	}
+	return subject, true
+}
+
+// authorizeMembership checks the caller may act on their own invitations, writing the error
+// response if not
+func (h *TeamHandler) authorizeMembership(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, bool) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return subject, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.TeamInvitations(subject.UserID)); err != nil {
+		h.logger.Warn("Team invitations denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Access denied")
+		return subject, false
+	}
+	return subject, true
+}
+
+// loadTeam looks up the team in the path and checks the caller may act on it, writing the error
+// response if not
+func (h *TeamHandler) loadTeam(w http.ResponseWriter, r *http.Request, action authz.Action) (authz.Subject, *database.Team, bool) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return subject, nil, false
+	}
+	teamID, ok := h.teamID(w, r)
+	if !ok {
+		return subject, nil, false
+	}
+
+	team, err := h.store.GetTeam(r.Context(), teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+			return subject, nil, false
+		}
+		h.writeStoreError(w, err, subject.UserID, teamID, "Failed to load team")
+		return subject, nil, false
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, action, authz.Team(team.CoachUserID, team.ID)); err != nil {
+		h.logger.Warn("Team denied by authorization policy",
+			"error", err,
+			"user_id", subject.UserID,
+			"team_id", team.ID)
+		// Other coaches' teams are reported as missing rather than forbidden
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+		return subject, nil, false
+	}
+	return subject, team, true
+}
+
+func (h *TeamHandler) subject(w http.ResponseWriter, r *http.Request) (authz.Subject, bool) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	if !ok {
+		h.logger.Warn("Teams called without valid user context",
+			"client_ip", middleware.GetClientIP(r))
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+	}
+	return subject, ok
+}
+
+func (h *TeamHandler) teamID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	teamID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || teamID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid team ID is required")
+		return 0, false
+	}
+	return teamID, true
+}
+
+func (h *TeamHandler) athleteID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	athleteID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil || athleteID <= 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "A valid user ID is required")
+		return 0, false
+	}
+	return athleteID, true
+}
+
+func (h *TeamHandler) writeMemberError(w http.ResponseWriter, err error, userID, teamID int, message string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		h.writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "The athlete is not invited to or on this team")
+		return
+	}
+	h.writeStoreError(w, err, userID, teamID, message)
+}
+
+func (h *TeamHandler) writeStoreError(w http.ResponseWriter, err error, userID, teamID int, message string) {
+	h.logger.Error(message,
+		"error", err,
+		"user_id", userID,
+		"team_id", teamID)
+	h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", message)
+}
+
+func (h *TeamHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode team response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *TeamHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
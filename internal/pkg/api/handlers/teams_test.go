@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+)
+
+// mockTeamStore keeps teams in memory; users maps emails to user IDs
+type mockTeamStore struct {
+	users   map[string]int
+	teams   map[int]*database.Team
+	members map[int]map[int]string // team ID -> user ID -> status
+}
+
+func newMockTeamStore(users map[string]int) *mockTeamStore {
+	return &mockTeamStore{users: users, teams: make(map[int]*database.Team), members: make(map[int]map[int]string)}
+}
+
+func (m *mockTeamStore) CreateTeam(ctx context.Context, team *database.Team) error {
+	team.ID = len(m.teams) + 1
+	team.CreatedAt = time.Now()
+	m.teams[team.ID] = team
+	m.members[team.ID] = make(map[int]string)
+	return nil
+}
+
+func (m *mockTeamStore) GetTeam(ctx context.Context, teamID int) (*database.Team, error) {
+	if team, ok := m.teams[teamID]; ok {
+		return team, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockTeamStore) ListTeamsByCoach(ctx context.Context, coachID int) ([]database.Team, error) {
+	teams := []database.Team{}
+	for _, team := range m.teams {
+		if team.CoachUserID == coachID {
+			teams = append(teams, *team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *mockTeamStore) InviteAthlete(ctx context.Context, teamID int, email string) (*database.TeamAthlete, error) {
+	userID, ok := m.users[email]
+	switch {
+	case !ok:
+		return nil, sql.ErrNoRows
+	case userID == m.teams[teamID].CoachUserID:
+		return nil, database.ErrInviteCoach
+	case m.members[teamID][userID] != "":
+		return nil, database.ErrAlreadyInvited
+	}
+	m.members[teamID][userID] = database.TeamMemberInvited
+	return &database.TeamAthlete{UserID: userID, Email: email, Status: database.TeamMemberInvited, InvitedAt: time.Now()}, nil
+}
+
+func (m *mockTeamStore) ListTeamAthletes(ctx context.Context, teamID int) ([]database.TeamAthlete, error) {
+	athletes := []database.TeamAthlete{}
+	for userID, status := range m.members[teamID] {
+		athletes = append(athletes, database.TeamAthlete{UserID: userID, Status: status})
+	}
+	return athletes, nil
+}
+
+func (m *mockTeamStore) ListInvitations(ctx context.Context, userID int) ([]database.TeamInvitation, error) {
+	invitations := []database.TeamInvitation{}
+	for teamID, members := range m.members {
+		if members[userID] == database.TeamMemberInvited {
+			invitations = append(invitations, database.TeamInvitation{TeamID: teamID, TeamName: m.teams[teamID].Name})
+		}
+	}
+	return invitations, nil
+}
+
+func (m *mockTeamStore) AcceptInvitation(ctx context.Context, teamID, userID int) error {
+	if m.members[teamID][userID] != database.TeamMemberInvited {
+		return sql.ErrNoRows
+	}
+	m.members[teamID][userID] = database.TeamMemberActive
+	return nil
+}
+
+func (m *mockTeamStore) RemoveTeamMember(ctx context.Context, teamID, userID int) error {
+	if m.members[teamID][userID] == "" {
+		return sql.ErrNoRows
+	}
+	delete(m.members[teamID], userID)
+	return nil
+}
+
+func (m *mockTeamStore) ListAthleteStatuses(ctx context.Context, teamID int) ([]database.AthleteSyncStatus, error) {
+	statuses := []database.AthleteSyncStatus{}
+	for userID, status := range m.members[teamID] {
+		if status == database.TeamMemberActive {
+			statuses = append(statuses, database.AthleteSyncStatus{UserID: userID})
+		}
+	}
+	return statuses, nil
+}
+
+func (m *mockTeamStore) CoachesAthlete(ctx context.Context, coachID, athleteID int) (bool, error) {
+	for teamID, members := range m.members {
+		if m.teams[teamID].CoachUserID == coachID && members[athleteID] == database.TeamMemberActive {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockTeamStore) SetTeamSpreadsheet(ctx context.Context, teamID int, spreadsheetID *string) error {
+	team, ok := m.teams[teamID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	team.SpreadsheetID = spreadsheetID
+	return nil
+}
+
+type mockRunLog struct{}
+
+func (mockRunLog) ListRuns(ctx context.Context, userID, limit int) ([]database.AutomationRun, error) {
+	return []database.AutomationRun{{ID: 1, UserID: userID, Status: database.RunStatusCompleted}}, nil
+}
+
+func TestTeamHandler(t *testing.T) {
+	const coachID, otherCoachID, athleteID = 2, 3, 7
+	store := newMockTeamStore(map[string]int{"coach@example.com": coachID, "runner@example.com": athleteID})
+	handler := NewTeamHandler(store, mockRunLog{}, authz.DefaultPolicy().With(authz.CoachRule(store)), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Get("/api/teams", handler.List)
+	router.Post("/api/teams", handler.Create)
+	router.Get("/api/teams/invitations", handler.Invitations)
+	router.Post("/api/teams/{id}/membership", handler.AcceptInvitation)
+	router.Delete("/api/teams/{id}/membership", handler.LeaveTeam)
+	router.Post("/api/teams/{id}/athletes", handler.Invite)
+	router.Get("/api/teams/{id}/athletes/status", handler.AthleteStatus)
+	router.Get("/api/teams/{id}/athletes/{userID}/runs", handler.AthleteRuns)
+
+	call := func(method, target, body string, userID int, roles ...authz.Role) *httptest.ResponseRecorder {
+		req := authenticatedRequest(method, target, body, userID)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, roles))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	coach := func(method, target, body string) *httptest.ResponseRecorder {
+		return call(method, target, body, coachID, authz.RoleAthlete, authz.RoleCoach)
+	}
+	athlete := func(method, target string) *httptest.ResponseRecorder {
+		return call(method, target, "", athleteID, authz.RoleAthlete)
+	}
+
+	if rr := call(http.MethodPost, "/api/teams", `{"name": "Squad"}`, athleteID, authz.RoleAthlete); rr.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 when an athlete creates a team, got %d", rr.Code)
+	}
+	if rr := coach(http.MethodPost, "/api/teams", `{"name": "Track Squad"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := coach(http.MethodPost, "/api/teams/1/athletes", `{"email": "coach@example.com"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 when coaches invite themselves, got %d", rr.Code)
+	}
+	if rr := coach(http.MethodPost, "/api/teams/1/athletes", `{"email": "stranger@example.com"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown email, got %d", rr.Code)
+	}
+	if rr := coach(http.MethodPost, "/api/teams/1/athletes", `{"email": "runner@example.com"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for the invitation, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := coach(http.MethodPost, "/api/teams/1/athletes", `{"email": "runner@example.com"}`); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a repeated invitation, got %d", rr.Code)
+	}
+
+	// The coach cannot read the run log until the athlete accepts
+	if rr := coach(http.MethodGet, "/api/teams/1/athletes/7/runs", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 before the invitation is accepted, got %d", rr.Code)
+	}
+
+	rr := athlete(http.MethodGet, "/api/teams/invitations")
+	var invitations InvitationsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&invitations); err != nil || len(invitations.Invitations) != 1 {
+		t.Fatalf("Expected one pending invitation, got %s (%v)", rr.Body.String(), err)
+	}
+	if rr := athlete(http.MethodPost, "/api/teams/1/membership"); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 when accepting, got %d", rr.Code)
+	}
+
+	rr = coach(http.MethodGet, "/api/teams/1/athletes/status", "")
+	var status AthleteStatusResponse
+	if err := json.NewDecoder(rr.Body).Decode(&status); err != nil || len(status.Athletes) != 1 || status.Athletes[0].UserID != athleteID {
+		t.Errorf("Expected the athlete on the dashboard, got %s (%v)", rr.Body.String(), err)
+	}
+	if rr := coach(http.MethodGet, "/api/teams/1/athletes/7/runs?limit=5", ""); rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for the run log, got %d", rr.Code)
+	}
+	if rr := coach(http.MethodGet, "/api/teams/1/athletes/7/runs?limit=500", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized limit, got %d", rr.Code)
+	}
+
+	// Other coaches and athletes cannot see the team
+	if rr := call(http.MethodGet, "/api/teams/1/athletes/status", "", otherCoachID, authz.RoleAthlete, authz.RoleCoach); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another coach, got %d", rr.Code)
+	}
+	if rr := athlete(http.MethodGet, "/api/teams/1/athletes/status"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a team member, got %d", rr.Code)
+	}
+
+	// Leaving the team revokes the coach's access
+	if rr := athlete(http.MethodDelete, "/api/teams/1/membership"); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 when leaving, got %d", rr.Code)
+	}
+	if rr := coach(http.MethodGet, "/api/teams/1/athletes/7/runs", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after the athlete left, got %d", rr.Code)
+	}
+}
+
+// mockSpreadsheetResolver accepts one spreadsheet URL for the coach
+type mockSpreadsheetResolver struct {
+	coachID int
+}
+
+func (m mockSpreadsheetResolver) ResolveSpreadsheet(ctx context.Context, userID int, spreadsheetURL string) (string, error) {
+	if userID != m.coachID || spreadsheetURL != "https://docs.google.com/spreadsheets/d/team-sheet/edit" {
+		return "", &services.ConfigError{Type: services.ConfigErrorPermission, Message: "No write access to the spreadsheet"}
+	}
+	return "team-sheet", nil
+}
+
+func TestTeamHandler_Spreadsheet(t *testing.T) {
+	const coachID, otherCoachID = 2, 3
+	store := newMockTeamStore(nil)
+	store.CreateTeam(context.Background(), &database.Team{Name: "Track Squad", CoachUserID: coachID})
+	handler := NewTeamHandler(store, mockRunLog{}, authz.DefaultPolicy(), logger.New("test"))
+
+	router := chi.NewRouter()
+	router.Put("/api/teams/{id}/spreadsheet", handler.SetSpreadsheet)
+	router.Delete("/api/teams/{id}/spreadsheet", handler.ClearSpreadsheet)
+
+	call := func(method, body string, userID int) *httptest.ResponseRecorder {
+		req := authenticatedRequest(method, "/api/teams/1/spreadsheet", body, userID)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RolesKey, []authz.Role{authz.RoleAthlete, authz.RoleCoach}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	body := `{"url": "https://docs.google.com/spreadsheets/d/team-sheet/edit"}`
+
+	if rr := call(http.MethodPut, body, coachID); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without a spreadsheet resolver, got %d", rr.Code)
+	}
+	handler.SetSpreadsheets(mockSpreadsheetResolver{coachID: coachID})
+
+	if rr := call(http.MethodPut, `{"url": "https://docs.google.com/spreadsheets/d/other/edit"}`, coachID); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a spreadsheet the coach cannot edit, got %d", rr.Code)
+	}
+	if rr := call(http.MethodPut, body, otherCoachID); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another coach, got %d", rr.Code)
+	}
+
+	rr := call(http.MethodPut, body, coachID)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var team database.Team
+	if err := json.NewDecoder(rr.Body).Decode(&team); err != nil || team.SpreadsheetID == nil || *team.SpreadsheetID != "team-sheet" {
+		t.Errorf("Expected the team with its spreadsheet, got %s (%v)", rr.Body.String(), err)
+	}
+
+	if rr := call(http.MethodDelete, "", coachID); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rr.Code)
+	}
+	if store.teams[1].SpreadsheetID != nil {
+		t.Error("Expected the team spreadsheet to be cleared")
+	}
+}
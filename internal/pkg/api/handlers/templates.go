@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// TemplateProvisioner lists the template catalog and provisions templates for users
+type TemplateProvisioner interface {
+	ListTemplates() []*templates.Template
+	ProvisionTemplate(ctx context.Context, userID int, templateID string) (*services.ProvisionedSpreadsheet, error)
+}
+
+// TemplateHandler handles spreadsheet template requests
+type TemplateHandler struct {
+	provisioner TemplateProvisioner
+	authorizer  authz.Authorizer
+	logger      *logger.Logger
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(provisioner TemplateProvisioner, authorizer authz.Authorizer, logger *logger.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		provisioner: provisioner,
+		authorizer:  authorizer,
+		logger:      logger.WithContext("component", "template_handler"),
+	}
+}
+
+// ProvisionTemplateRequest represents the request body for provisioning a template
+type ProvisionTemplateRequest struct {
+	TemplateID string `json:"template_id"`
+}
+
+// Validate checks a template was chosen; the provisioner checks it is in the catalog
+func (req *ProvisionTemplateRequest) Validate(v *validate.Validator) {
+	v.Required("template_id", req.TemplateID, "template_id cannot be empty")
+}
+
+// ListTemplatesResponse represents the template catalog response
+type ListTemplatesResponse struct {
+	Templates []*templates.Template `json:"templates"`
+}
+
+// ListTemplates handles GET /api/v1/templates requests
+func (h *TemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, ListTemplatesResponse{Templates: h.provisioner.ListTemplates()})
+}
+
+// ProvisionTemplate handles POST /api/v1/config/spreadsheet/template requests.
+// It creates a copy of the chosen template in the user's Drive and makes it their spreadsheet.
+func (h *TemplateHandler) ProvisionTemplate(w http.ResponseWriter, r *http.Request) {
+	subject, ok := middleware.GetSubjectFromContext(r.Context())
+	userID := subject.UserID
+	clientIP := middleware.GetClientIP(r)
+
+	if !ok {
+		h.logger.Warn("ProvisionTemplate called without valid user context",
+			"client_ip", clientIP)
+		h.writeErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	if err := h.authorizer.Authorize(r.Context(), subject, authz.ActionUpdate, authz.Config(userID)); err != nil {
+		h.logger.Warn("ProvisionTemplate denied by authorization policy",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusForbidden, "FORBIDDEN", "Not allowed to change this configuration")
+		return
+	}
+
+	var req ProvisionTemplateRequest
+	if !decodeRequest(w, r, &req, h.logger) {
+		return
+	}
+
+	h.logger.Info("Template provisioning requested",
+		"user_id", userID,
+		"template_id", req.TemplateID,
+		"client_ip", clientIP)
+
+	provisioned, err := h.provisioner.ProvisionTemplate(r.Context(), userID, req.TemplateID)
+	if err != nil {
+		h.handleTemplateError(w, userID, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, provisioned)
+}
+
+// handleTemplateError maps template service errors to HTTP responses
+func (h *TemplateHandler) handleTemplateError(w http.ResponseWriter, userID int, err error) {
+	var templateErr *services.TemplateError
+	if !errors.As(err, &templateErr) {
+		h.logger.Error("Unexpected error provisioning template",
+			"error", err,
+			"user_id", userID)
+		h.writeErrorResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	h.logger.Warn("Template provisioning failed",
+		"error_type", templateErr.Type,
+		"error", err,
+		"user_id", userID)
+
+	statusCode := http.StatusInternalServerError
+	switch templateErr.Type {
+	case services.TemplateErrorNotFound:
+		statusCode = http.StatusBadRequest
+	case services.TemplateErrorNotConnected, services.TemplateErrorReauth:
+		statusCode = http.StatusUnauthorized
+	case services.TemplateErrorGoogle:
+		statusCode = http.StatusBadGateway
+	}
+
+	h.writeErrorResponse(w, statusCode, templateErr.Type, templateErr.Message)
+}
+
+func (h *TemplateHandler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode response",
+			"error", err,
+			"status_code", statusCode)
+	}
+}
+
+func (h *TemplateHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	if err := apierror.Write(w, statusCode, newErrorResponse(errorCode, message)); err != nil {
+		h.logger.Error("Failed to encode error response",
+			"error", err,
+			"status_code", statusCode,
+			"error_code", errorCode)
+	}
+}
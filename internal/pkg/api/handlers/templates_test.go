@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/validate"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+type mockTemplateProvisioner struct {
+	err            error
+	provisionedFor int
+}
+
+func (m *mockTemplateProvisioner) ListTemplates() []*templates.Template {
+	return templates.All()
+}
+
+func (m *mockTemplateProvisioner) ProvisionTemplate(ctx context.Context, userID int, templateID string) (*services.ProvisionedSpreadsheet, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.provisionedFor = userID
+	return &services.ProvisionedSpreadsheet{TemplateID: templateID, SpreadsheetID: "sheet-1"}, nil
+}
+
+func TestTemplateHandler_ListTemplates(t *testing.T) {
+	handler := NewTemplateHandler(&mockTemplateProvisioner{}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.ListTemplates(rr, authenticatedRequest(http.MethodGet, "/api/templates", "", 5))
+
+	var response ListTemplatesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rr.Code != http.StatusOK || len(response.Templates) != len(templates.All()) {
+		t.Errorf("Expected full catalog, got status %d and %d templates", rr.Code, len(response.Templates))
+	}
+}
+
+func TestTemplateHandler_ProvisionTemplate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		provisionErr   error
+		expectedStatus int
+	}{
+		{"Provisioned", `{"template_id": "coach_plan"}`, nil, http.StatusCreated},
+		{"Empty template", `{}`, nil, http.StatusBadRequest},
+		{"Invalid JSON", `{`, nil, http.StatusBadRequest},
+		{"Unknown template", `{"template_id": "x"}`, &services.TemplateError{Type: services.TemplateErrorNotFound}, http.StatusBadRequest},
+		{"Google not connected", `{"template_id": "triathlon"}`, &services.TemplateError{Type: services.TemplateErrorNotConnected}, http.StatusUnauthorized},
+		{"Drive failure", `{"template_id": "triathlon"}`, &services.TemplateError{Type: services.TemplateErrorGoogle}, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provisioner := &mockTemplateProvisioner{err: tt.provisionErr}
+			handler := NewTemplateHandler(provisioner, authz.DefaultPolicy(), logger.New("test"))
+
+			rr := httptest.NewRecorder()
+			handler.ProvisionTemplate(rr, authenticatedRequest(http.MethodPost, "/api/config/spreadsheet/template", tt.body, 5))
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus == http.StatusCreated && provisioner.provisionedFor != 5 {
+				t.Errorf("Expected template provisioned for user 5, got %d", provisioner.provisionedFor)
+			}
+		})
+	}
+}
+
+func TestTemplateHandler_ProvisionTemplateValidation(t *testing.T) {
+	handler := NewTemplateHandler(&mockTemplateProvisioner{}, authz.DefaultPolicy(), logger.New("test"))
+
+	rr := httptest.NewRecorder()
+	handler.ProvisionTemplate(rr, authenticatedRequest(http.MethodPost, "/api/config/spreadsheet/template", `{"template_id": " "}`, 5))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rr.Code)
+	}
+
+	var response struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				Fields []validate.FieldError `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	fields := response.Error.Details.Fields
+	if response.Error.Code != "VALIDATION_ERROR" || len(fields) != 1 || fields[0].Field != "template_id" || fields[0].Code != validate.CodeRequired {
+		t.Errorf("Expected a required template_id field error, got %+v", response.Error)
+	}
+}
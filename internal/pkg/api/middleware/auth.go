@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 )
@@ -17,9 +20,17 @@ type AuthMiddleware struct {
 	sessionRepository *database.SessionRepository
 	oauthService      *auth.OAuthService
 	userRepository    *database.UserRepository
+	apiTokens         APITokenStore
+	adminEmails       map[string]bool
 	logger            *logger.Logger
 }
 
+// APITokenStore looks up personal access tokens presented as Bearer tokens
+type APITokenStore interface {
+	GetActiveAPIToken(ctx context.Context, tokenHash string) (*database.APIToken, error)
+	TouchAPIToken(ctx context.Context, id int) error
+}
+
 // NewAuthMiddleware creates a new authentication middleware
 func NewAuthMiddleware(jwtService *auth.JWTService, sessionRepository *database.SessionRepository, oauthService *auth.OAuthService, userRepository *database.UserRepository, logger *logger.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
@@ -31,6 +42,36 @@ func NewAuthMiddleware(jwtService *auth.JWTService, sessionRepository *database.
 	}
 }
 
+// SetAdminEmails grants the admin role to users signing in with one of the given emails
+func (a *AuthMiddleware) SetAdminEmails(emails []string) {
+	a.adminEmails = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		a.adminEmails[strings.ToLower(email)] = true
+	}
+}
+
+// SetAPITokens accepts personal access tokens in an Authorization: Bearer header alongside
+// session cookies. Without a store, Bearer tokens are rejected.
+func (a *AuthMiddleware) SetAPITokens(store APITokenStore) {
+	a.apiTokens = store
+}
+
+// rolesFor returns the roles of a user with the given email and the account role from their
+// access token. ADMIN_EMAILS grants admin regardless of the account role, so the first admin can
+// grant roles to others.
+func (a *AuthMiddleware) rolesFor(email, accountRole string) []authz.Role {
+	roles := []authz.Role{authz.RoleAthlete}
+	switch {
+	case accountRole == database.UserRoleAdmin || a.adminEmails[strings.ToLower(email)]:
+		roles = append(roles, authz.RoleAdmin)
+	case accountRole == database.UserRoleSupport:
+		roles = append(roles, authz.RoleSupport)
+	case accountRole == database.UserRoleCoach:
+		roles = append(roles, authz.RoleCoach)
+	}
+	return roles
+}
+
 // ContextKey is used for storing values in request context
 type ContextKey string
 
@@ -41,6 +82,12 @@ const (
 	SessionIDKey ContextKey = "session_id"
 	// EmailKey is the context key for user email
 	EmailKey ContextKey = "email"
+	// RolesKey is the context key for the user's authorization roles
+	RolesKey ContextKey = "roles"
+	// ScopesKey is the context key for the scopes of the API token the user authenticated with
+	ScopesKey ContextKey = "scopes"
+	// APITokenIDKey is the context key for the ID of the API token the user authenticated with
+	APITokenIDKey ContextKey = "api_token_id"
 )
 
 // RequireAuth middleware validates JWT tokens and ensures user is authenticated
@@ -53,26 +100,48 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			"method", r.Method,
 			"client_ip", clientIP,
 			"user_agent", r.Header.Get("User-Agent"))
+
+		// Scripts authenticate with a personal access token instead of the session cookie
+		if token, ok := bearerToken(r); ok {
+			a.requireAPIToken(w, r, token, next)
+			return
+		}
 		
 		// Get JWT token from cookie
 		cookie, err := r.Cookie("session_token")
 		if err != nil {
+			// The access token cookie lapses with the token; a refresh token means the browser
+			// should refresh rather than sign in again
+			if _, refreshErr := r.Cookie("refresh_token"); refreshErr == nil {
+				a.logger.Debug("Authentication failed: Access token expired, refresh token present",
+					"path", r.URL.Path,
+					"client_ip", clientIP)
+				apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeTokenExpired, "Access token expired"))
+				return
+			}
 			a.logger.Warn("Authentication failed: No session token cookie",
 				"path", r.URL.Path,
 				"client_ip", clientIP,
 				"cookie_error", err.Error())
-			http.Error(w, "Unauthorized: No session token", http.StatusUnauthorized)
+			apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "No session token"))
 			return
 		}
 
 		// Validate JWT token
 		claims, err := a.jwtService.ValidateToken(cookie.Value)
 		if err != nil {
+			if auth.IsTokenExpired(err) {
+				a.logger.Debug("Authentication failed: Access token expired",
+					"path", r.URL.Path,
+					"client_ip", clientIP)
+				apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeTokenExpired, "Access token expired"))
+				return
+			}
 			a.logger.Warn("Authentication failed: Invalid JWT token",
 				"path", r.URL.Path,
 				"client_ip", clientIP,
 				"validation_error", err.Error())
-			http.Error(w, "Unauthorized: Invalid session token", http.StatusUnauthorized)
+			apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "Invalid session token"))
 			return
 		}
 
@@ -85,7 +154,7 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 				"user_id", claims.UserID,
 				"session_id", claims.SessionID,
 				"error", err.Error())
-			http.Error(w, "Unauthorized: Session validation error", http.StatusUnauthorized)
+			apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "Session validation error"))
 			return
 		}
 
@@ -95,12 +164,12 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 				"client_ip", clientIP,
 				"user_id", claims.UserID,
 				"session_id", claims.SessionID)
-			http.Error(w, "Unauthorized: Session not found or expired", http.StatusUnauthorized)
+			apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "Session not found or expired"))
 			return
 		}
 
-		// Update session last used timestamp
-		if err := a.sessionRepository.UpdateSessionLastUsed(r.Context(), session.ID); err != nil {
+		// Update session last used timestamp, extending a sliding session's expiry
+		if _, err := a.sessionRepository.UpdateSessionLastUsed(r.Context(), session.ID); err != nil {
 			a.logger.Error("Failed to update session last used timestamp",
 				"session_id", session.ID,
 				"user_id", claims.UserID,
@@ -121,18 +190,135 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
+		ctx = context.WithValue(ctx, RolesKey, a.rolesFor(claims.Email, claims.Role))
 
 		// Continue to next handler with updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(header[len("Bearer "):]), true
+}
+
+// requireAPIToken authenticates the request with a personal access token. Token holders act as
+// athletes limited to the token's scopes, even when the owner is an admin.
+func (a *AuthMiddleware) requireAPIToken(w http.ResponseWriter, r *http.Request, token string, next http.Handler) {
+	clientIP := GetClientIP(r)
+
+	if a.apiTokens == nil || !auth.IsAPIToken(token) {
+		a.logger.Warn("Authentication failed: Unsupported bearer token",
+			"path", r.URL.Path,
+			"client_ip", clientIP)
+		apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "Invalid API token"))
+		return
+	}
+
+	apiToken, err := a.apiTokens.GetActiveAPIToken(r.Context(), auth.HashAPIToken(token))
+	if err != nil {
+		a.logger.Error("Authentication failed: API token lookup database error",
+			"path", r.URL.Path,
+			"client_ip", clientIP,
+			"error", err.Error())
+		apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "API token validation error"))
+		return
+	}
+	if apiToken == nil {
+		a.logger.Warn("Authentication failed: API token not found, revoked or expired",
+			"path", r.URL.Path,
+			"client_ip", clientIP)
+		apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "Invalid API token"))
+		return
+	}
+	if apiToken.OwnerSuspended {
+		a.logger.Warn("Authentication failed: API token owner is suspended",
+			"path", r.URL.Path,
+			"client_ip", clientIP,
+			"user_id", apiToken.UserID,
+			"api_token_id", apiToken.ID)
+		apierror.Write(w, http.StatusForbidden, apierror.New(apierror.CodeAccountSuspended, "This account has been suspended; contact support"))
+		return
+	}
+
+	if err := a.apiTokens.TouchAPIToken(r.Context(), apiToken.ID); err != nil {
+		a.logger.Error("Failed to update API token last used timestamp",
+			"api_token_id", apiToken.ID,
+			"user_id", apiToken.UserID,
+			"error", err.Error())
+		// Don't fail the request - this is a non-critical operation
+	}
+
+	a.logger.Debug("API token authentication successful",
+		"path", r.URL.Path,
+		"user_id", apiToken.UserID,
+		"api_token_id", apiToken.ID,
+		"client_ip", clientIP)
+
+	scopes := make([]authz.Scope, 0, len(apiToken.Scopes))
+	for _, scope := range apiToken.Scopes {
+		scopes = append(scopes, authz.Scope(scope))
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, apiToken.UserID)
+	ctx = context.WithValue(ctx, EmailKey, apiToken.Email)
+	ctx = context.WithValue(ctx, RolesKey, []authz.Role{authz.RoleAthlete})
+	ctx = context.WithValue(ctx, ScopesKey, scopes)
+	ctx = context.WithValue(ctx, APITokenIDKey, apiToken.ID)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireRole only lets through users holding at least one of roles. It must run after
+// RequireAuth; handlers still authorize each action, so this guards whole route groups such as
+// the admin API against callers who could never be allowed.
+func RequireRole(roles ...authz.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, ok := GetSubjectFromContext(r.Context())
+			if !ok {
+				apierror.Write(w, http.StatusUnauthorized, apierror.New(apierror.CodeUnauthorized, "User not authenticated"))
+				return
+			}
+			for _, role := range roles {
+				if subject.HasRole(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			apierror.Write(w, http.StatusForbidden, apierror.New(apierror.CodeForbidden, "Insufficient role"))
+		})
+	}
+}
+
 // GetUserIDFromContext extracts the user ID from the request context
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
 	return userID, ok
 }
 
+// GetSubjectFromContext returns the authenticated user as an authorization subject
+// Users act as athletes unless RequireAuth granted them additional roles, and API token
+// holders are limited to the token's scopes
+func GetSubjectFromContext(ctx context.Context) (authz.Subject, bool) {
+	userID, ok := GetUserIDFromContext(ctx)
+	if !ok {
+		return authz.Subject{}, false
+	}
+	subject := authz.User(userID, authz.RoleAthlete)
+	if roles, ok := ctx.Value(RolesKey).([]authz.Role); ok {
+		subject = authz.User(userID, roles...)
+	}
+	if scopes, ok := ctx.Value(ScopesKey).([]authz.Scope); ok {
+		subject = subject.WithScopes(scopes...)
+	}
+	return subject, true
+}
+
 // GetSessionIDFromContext extracts the session ID from the request context
 func GetSessionIDFromContext(ctx context.Context) (int, bool) {
 	sessionID, ok := ctx.Value(SessionIDKey).(int)
@@ -173,8 +359,8 @@ func (a *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		// Update session last used timestamp
-		if err := a.sessionRepository.UpdateSessionLastUsed(r.Context(), session.ID); err != nil {
+		// Update session last used timestamp, extending a sliding session's expiry
+		if _, err := a.sessionRepository.UpdateSessionLastUsed(r.Context(), session.ID); err != nil {
 			// Log error but don't fail the optional auth request
 			// In production, this should use a structured logger
 		}
@@ -183,6 +369,7 @@ func (a *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
+		ctx = context.WithValue(ctx, RolesKey, a.rolesFor(claims.Email, claims.Role))
 
 		// Continue to next handler with updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -198,6 +385,7 @@ func CORS(allowedOrigin string) func(http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Expose-Headers", "Deprecation, Sunset, Link, X-Request-ID")
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
@@ -291,8 +479,41 @@ func (a *AuthMiddleware) shouldRefreshStravaToken(user *database.User) bool {
 	return time.Until(*user.StravaTokenExpiry) < 5*time.Minute
 }
 
-// refreshGoogleOAuthToken refreshes the user's Google OAuth token
+// maxTokenRefreshAttempts bounds how often a background refresh is retried after losing a race
+// with another token write
+const maxTokenRefreshAttempts = 3
+
+// refreshGoogleOAuthToken refreshes the user's Google OAuth token. The new tokens are only
+// stored if no other writer, such as a concurrent request's refresh or a new login, stored
+// tokens since the user was read; otherwise the user is re-read and the refresh retried if the
+// stored token still needs it.
 func (a *AuthMiddleware) refreshGoogleOAuthToken(ctx context.Context, user *database.User) {
+	for attempt := 1; ; attempt++ {
+		err := a.tryRefreshGoogleOAuthToken(ctx, user)
+		if !errors.Is(err, database.ErrStaleTokenVersion) {
+			return
+		}
+		if attempt == maxTokenRefreshAttempts {
+			a.logger.Warn("Giving up Google OAuth token refresh after concurrent token updates",
+				"user_id", user.ID, "attempts", attempt)
+			return
+		}
+
+		fresh, err := a.userRepository.GetUserByID(ctx, user.ID)
+		if err != nil || fresh == nil {
+			return
+		}
+		if !a.shouldRefreshGoogleToken(fresh) {
+			a.logger.Debug("Google OAuth token was refreshed concurrently", "user_id", user.ID)
+			return
+		}
+		user = fresh
+	}
+}
+
+// tryRefreshGoogleOAuthToken makes one refresh attempt, returning database.ErrStaleTokenVersion
+// when the user's tokens changed since user was read. Other failures are logged and end the refresh.
+func (a *AuthMiddleware) tryRefreshGoogleOAuthToken(ctx context.Context, user *database.User) error {
 	a.logger.Debug("Starting background Google OAuth token refresh", "user_id", user.ID)
 	
 	// Decrypt the refresh token
@@ -300,7 +521,7 @@ func (a *AuthMiddleware) refreshGoogleOAuthToken(ctx context.Context, user *data
 	if err != nil {
 		a.logger.Error("Failed to decrypt Google refresh token for background refresh",
 			"user_id", user.ID, "error", err.Error())
-		return
+		return err
 	}
 
 	// Refresh the token with Google
@@ -308,27 +529,35 @@ func (a *AuthMiddleware) refreshGoogleOAuthToken(ctx context.Context, user *data
 	if err != nil {
 		a.logger.Error("Failed to refresh Google OAuth token",
 			"user_id", user.ID, "error", err.Error())
-		return
+		return err
 	}
 
 	// Update the user's tokens in the database (background refresh, don't update last login)
+	expectedVersion := user.TokenVersion
 	updateReq := &database.UpdateUserTokensRequest{
-		UserID:             user.ID,
-		GoogleAccessToken:  newToken.AccessToken,
-		GoogleRefreshToken: newToken.RefreshToken,
-		GoogleTokenExpiry:  &newToken.Expiry,
-		UpdateLastLogin:    false, // Don't update last login for background token refresh
+		UserID:               user.ID,
+		GoogleAccessToken:    newToken.AccessToken,
+		GoogleRefreshToken:   newToken.RefreshToken,
+		GoogleTokenExpiry:    &newToken.Expiry,
+		UpdateLastLogin:      false, // Don't update last login for background token refresh
+		ExpectedTokenVersion: &expectedVersion,
 	}
 
 	// Update in database
 	if err := a.userRepository.UpdateUserTokens(ctx, updateReq); err != nil {
+		if errors.Is(err, database.ErrStaleTokenVersion) {
+			a.logger.Info("Discarding refreshed Google OAuth token, tokens were updated concurrently",
+				"user_id", user.ID, "token_version", expectedVersion)
+			return err
+		}
 		a.logger.Error("Failed to update user tokens after Google OAuth refresh",
 			"user_id", user.ID, "error", err.Error())
-		return
+		return err
 	}
 	
 	a.logger.Info("Successfully refreshed Google OAuth token in background",
 		"user_id", user.ID, "new_expiry", newToken.Expiry.String())
+	return nil
 }
 
 // refreshStravaOAuthToken refreshes the user's Strava OAuth token
@@ -2,11 +2,18 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
 )
 
 // TestMiddlewareContextHelpers tests the context helper functions
@@ -352,6 +359,59 @@ func TestJWTLogoutIntegration(t *testing.T) {
 			t.Fatalf("Fresh token should be valid: %v", err)
 		}
 		
+		// RequireAuth asks the client to refresh expired access tokens instead of signing in again
+		expiring := auth.NewJWTService("test-secret-key")
+		expiring.SetTokenTTL(-time.Minute)
+		expired, err := expiring.GenerateToken(123, "test@example.com", "google123", 456)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		middleware := NewAuthMiddleware(jwtService, nil, nil, nil, logger.New("test"))
+		handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected the request to be rejected")
+		}))
+		for name, cookies := range map[string][]*http.Cookie{
+			"expired access token":  {{Name: "session_token", Value: expired}},
+			"lapsed access cookie": {{Name: "refresh_token", Value: "opaque"}},
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), apierror.CodeTokenExpired) {
+				t.Errorf("%s: expected 401 %s, got %d %s", name, apierror.CodeTokenExpired, w.Code, w.Body.String())
+			}
+		}
+		
+		// Tokens that are not merely expired must sign in again, even with a refresh token, so
+		// the client does not loop on refresh
+		forger := auth.NewJWTService("other-secret-key")
+		forged, err := forger.GenerateToken(123, "test@example.com", "google123", 456)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		forger.SetTokenTTL(-time.Minute)
+		forgedExpired, err := forger.GenerateToken(123, "test@example.com", "google123", 456)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		for name, value := range map[string]string{
+			"malformed token":             "not-a-jwt",
+			"bad signature":               forged,
+			"expired with bad signature": forgedExpired,
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			req.AddCookie(&http.Cookie{Name: "session_token", Value: value})
+			req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "opaque"})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized || strings.Contains(w.Body.String(), apierror.CodeTokenExpired) {
+				t.Errorf("%s: expected 401 %s, got %d %s", name, apierror.CodeUnauthorized, w.Code, w.Body.String())
+			}
+		}
+		
 		// Note: Testing expired tokens would require either:
 		// 1. Waiting for actual expiry (impractical for unit tests)
 		// 2. Manipulating internal JWT structures (not exposed)
@@ -444,4 +504,154 @@ func TestLogoutFlowComponents(t *testing.T) {
 			t.Errorf("Expected empty value for cleared cookie, got '%s'", clearedCookie.Value)
 		}
 	})
-}
\ No newline at end of file
+}
+type fakeAPITokenStore struct {
+	tokens  map[string]*database.APIToken
+	touched []int
+}
+
+func (s *fakeAPITokenStore) GetActiveAPIToken(ctx context.Context, tokenHash string) (*database.APIToken, error) {
+	return s.tokens[tokenHash], nil
+}
+
+func (s *fakeAPITokenStore) TouchAPIToken(ctx context.Context, id int) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+// TestRequireAuth_APIToken tests Bearer authentication with personal access tokens
+func TestRequireAuth_APIToken(t *testing.T) {
+	token, _, hash, err := auth.NewAPIToken()
+	if err != nil {
+		t.Fatalf("Failed to generate API token: %v", err)
+	}
+	store := &fakeAPITokenStore{tokens: map[string]*database.APIToken{
+		hash: {ID: 9, UserID: 123, Email: "admin@example.com", Scopes: []string{"sync"}},
+	}}
+
+	middleware := NewAuthMiddleware(auth.NewJWTService("test-secret-key"), nil, nil, nil, logger.New("test"))
+	middleware.SetAdminEmails([]string{"admin@example.com"})
+	middleware.SetAPITokens(store)
+
+	var subject authz.Subject
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject, _ = GetSubjectFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d %s", w.Code, w.Body.String())
+	}
+	// Token holders act as athletes within the token's scopes, even for admins
+	if subject.UserID != 123 || !subject.HasScope(authz.ScopeSync) || subject.HasScope(authz.ScopeRead) || subject.HasRole(authz.RoleAdmin) {
+		t.Errorf("Unexpected subject: %+v", subject)
+	}
+	if len(store.touched) != 1 || store.touched[0] != 9 {
+		t.Errorf("Expected the token's last use to be recorded, got %v", store.touched)
+	}
+
+	for name, header := range map[string]string{
+		"unknown token": "Bearer " + auth.APITokenPrefix + "unknown",
+		"session JWT":   "Bearer eyJhbGciOiJIUzI1NiJ9.e30.sig",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", name, w.Code)
+		}
+	}
+}
+
+// TestRequireAuth_APITokenSuspendedOwner tests that a suspended account's tokens are refused
+func TestRequireAuth_APITokenSuspendedOwner(t *testing.T) {
+	token, _, hash, err := auth.NewAPIToken()
+	if err != nil {
+		t.Fatalf("Failed to generate API token: %v", err)
+	}
+	store := &fakeAPITokenStore{tokens: map[string]*database.APIToken{
+		hash: {ID: 9, UserID: 123, Email: "athlete@example.com", Scopes: []string{"read", "export", "sync"}, OwnerSuspended: true},
+	}}
+
+	middleware := NewAuthMiddleware(auth.NewJWTService("test-secret-key"), nil, nil, nil, logger.New("test"))
+	middleware.SetAPITokens(store)
+
+	called := false
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/activities", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403, got %d %s", w.Code, w.Body.String())
+	}
+	var response apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Error.Code != apierror.CodeAccountSuspended {
+		t.Errorf("Expected code %s, got %s", apierror.CodeAccountSuspended, response.Error.Code)
+	}
+	if called {
+		t.Error("Expected the handler not to run for a suspended account")
+	}
+	if len(store.touched) != 0 {
+		t.Errorf("Expected a refused token's last use not to be recorded, got %v", store.touched)
+	}
+}
+
+// TestRequireRole tests that role checks use the account role from the access token
+func TestRequireRole(t *testing.T) {
+	middleware := NewAuthMiddleware(auth.NewJWTService("test-secret-key"), nil, nil, nil, logger.New("test"))
+	middleware.SetAdminEmails([]string{"founder@example.com"})
+
+	if roles := middleware.rolesFor("someone@example.com", "support"); len(roles) != 2 || roles[1] != authz.RoleSupport {
+		t.Errorf("Expected the support role from the token, got %v", roles)
+	}
+	if roles := middleware.rolesFor("someone@example.com", "coach"); len(roles) != 2 || roles[1] != authz.RoleCoach {
+		t.Errorf("Expected the coach role from the token, got %v", roles)
+	}
+	if roles := middleware.rolesFor("founder@example.com", ""); len(roles) != 2 || roles[1] != authz.RoleAdmin {
+		t.Errorf("Expected ADMIN_EMAILS to grant admin, got %v", roles)
+	}
+
+	handler := RequireRole(authz.RoleAdmin, authz.RoleSupport)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name  string
+		roles []authz.Role
+		want  int
+	}{
+		{"support", []authz.Role{authz.RoleAthlete, authz.RoleSupport}, http.StatusOK},
+		{"admin", []authz.Role{authz.RoleAthlete, authz.RoleAdmin}, http.StatusOK},
+		{"athlete", []authz.Role{authz.RoleAthlete}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/blackouts", nil)
+		ctx := context.WithValue(req.Context(), UserIDKey, 7)
+		ctx = context.WithValue(ctx, RolesKey, tt.roles)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/blackouts", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without authentication, got %d", w.Code)
+	}
+}
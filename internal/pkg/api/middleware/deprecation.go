@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeprecationsPath serves the machine-readable list of deprecations
+const DeprecationsPath = "/api/v1/meta/deprecations"
+
+// Deprecation describes an endpoint, or one field of its response, slated for removal
+type Deprecation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"` // Route pattern, e.g. /api/v1/users/me, or prefix/* for an Alias
+
+	// Field names a deprecated response field; empty when the whole endpoint is deprecated
+	Field string `json:"field,omitempty"`
+
+	DeprecatedAt time.Time  `json:"deprecated_at"`
+	Sunset       *time.Time `json:"sunset,omitempty"`
+	Replacement  string     `json:"replacement,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+// Deprecations is a registry of deprecated endpoints and fields
+type Deprecations struct {
+	entries []Deprecation
+}
+
+// NewDeprecations creates a registry with the given entries
+func NewDeprecations(entries ...Deprecation) *Deprecations {
+	return &Deprecations{entries: entries}
+}
+
+// List returns every registered deprecation
+func (d *Deprecations) List() []Deprecation {
+	return append([]Deprecation(nil), d.entries...)
+}
+
+// Middleware adds deprecation headers to responses of deprecated routes. A deprecated endpoint
+// gets Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link headers; an endpoint with only
+// deprecated fields gets a Link to the deprecations list. The route is matched on its chi pattern,
+// which is resolved once the response is written, so the middleware can be installed globally.
+func (d *Deprecations) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&deprecationWriter{ResponseWriter: w, request: r, deprecations: d}, r)
+	})
+}
+
+// Alias returns middleware for routes served under prefix as aliases of the same routes under
+// successor, such as the unversioned /api paths kept for clients of /api/v1. Every response gets
+// the headers of the deprecation registered for prefix+"/*", with a successor Link to the
+// request's path under successor; without a registered deprecation the routes are unmarked.
+func (d *Deprecations) Alias(prefix, successor string) func(http.Handler) http.Handler {
+	var entry *Deprecation
+	for i := range d.entries {
+		if d.entries[i].Path == prefix+"/*" {
+			entry = &d.entries[i]
+			break
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if entry == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"application/json\"", DeprecationsPath))
+			setEndpointHeaders(h, *entry, successor+strings.TrimPrefix(r.URL.Path, prefix))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setHeaders adds the headers for the request's route, if it is deprecated
+func (d *Deprecations) setHeaders(h http.Header, r *http.Request) {
+	pattern := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		pattern = rctx.RoutePattern()
+	}
+
+	linked := false
+	for _, entry := range d.entries {
+		if entry.Method != r.Method || entry.Path != pattern {
+			continue
+		}
+		if !linked {
+			h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"application/json\"", DeprecationsPath))
+			linked = true
+		}
+		if entry.Field != "" {
+			continue
+		}
+
+		setEndpointHeaders(h, entry, entry.Replacement)
+	}
+}
+
+// setEndpointHeaders adds the headers of a deprecated endpoint replaced by successor, if any
+func setEndpointHeaders(h http.Header, entry Deprecation, successor string) {
+	h.Set("Deprecation", fmt.Sprintf("@%d", entry.DeprecatedAt.Unix()))
+	if entry.Sunset != nil {
+		h.Set("Sunset", entry.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if successor != "" {
+		h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+	}
+}
+
+// deprecationWriter adds the deprecation headers just before the response header is written
+type deprecationWriter struct {
+	http.ResponseWriter
+	request      *http.Request
+	deprecations *Deprecations
+	wroteHeader  bool
+}
+
+func (w *deprecationWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.deprecations.setHeaders(w.Header(), w.request)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *deprecationWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses
+func (w *deprecationWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *deprecationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
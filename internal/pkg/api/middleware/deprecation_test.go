@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDeprecations_Middleware(t *testing.T) {
+	deprecatedAt := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	deprecations := NewDeprecations(
+		Deprecation{Method: http.MethodGet, Path: "/users/{id}", DeprecatedAt: deprecatedAt, Sunset: &sunset, Replacement: "/accounts/{id}"},
+		Deprecation{Method: http.MethodGet, Path: "/me", Field: "legacy", DeprecatedAt: deprecatedAt},
+	)
+
+	r := chi.NewRouter()
+	r.Use(deprecations.Middleware)
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }
+	r.Get("/users/{id}", ok)
+	r.Post("/users/{id}", ok)
+	r.Get("/me", ok)
+
+	get := func(method, target string) http.Header {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec.Header()
+	}
+
+	h := get(http.MethodGet, "/users/42")
+	if h.Get("Deprecation") != "@1792108800" {
+		t.Errorf("Expected Deprecation @1792108800, got %q", h.Get("Deprecation"))
+	}
+	if h.Get("Sunset") != "Sun, 31 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset header %q", h.Get("Sunset"))
+	}
+	links := strings.Join(h.Values("Link"), ", ")
+	if !strings.Contains(links, `</accounts/{id}>; rel="successor-version"`) || !strings.Contains(links, DeprecationsPath) {
+		t.Errorf("Unexpected Link headers %q", links)
+	}
+
+	if h := get(http.MethodPost, "/users/42"); h.Get("Deprecation") != "" || h.Get("Link") != "" {
+		t.Error("Expected other methods of the route to be unaffected")
+	}
+
+	// A deprecated field links to the list without deprecating the endpoint
+	h = get(http.MethodGet, "/me")
+	if h.Get("Deprecation") != "" || !strings.Contains(h.Get("Link"), `rel="deprecation"`) {
+		t.Errorf("Expected only a deprecation list link, got %v", h)
+	}
+}
+
+func TestDeprecations_Alias(t *testing.T) {
+	deprecatedAt := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	deprecations := NewDeprecations(
+		Deprecation{Method: "*", Path: "/api/*", DeprecatedAt: deprecatedAt, Sunset: &sunset, Replacement: "/api/v1/*"},
+	)
+
+	routes := func(r chi.Router) {
+		r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	}
+	r := chi.NewRouter()
+	r.Use(deprecations.Middleware)
+	r.Route("/api/v1", routes)
+	r.Group(func(r chi.Router) {
+		r.Use(deprecations.Alias("/api", "/api/v1"))
+		r.Route("/api", routes)
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	if rec := get("/api/v1/users/42"); rec.Body.String() != "ok" || rec.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected the versioned route to be served undeprecated, got %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec := get("/api/users/42")
+	if rec.Body.String() != "ok" {
+		t.Fatalf("Expected the alias to serve the same route, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "@1792108800" || rec.Header().Get("Sunset") != "Sun, 31 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Expected deprecation headers on the alias, got %v", rec.Header())
+	}
+	links := strings.Join(rec.Header().Values("Link"), ", ")
+	if !strings.Contains(links, `</api/v1/users/42>; rel="successor-version"`) || !strings.Contains(links, DeprecationsPath) {
+		t.Errorf("Expected a successor link to the versioned path, got %q", links)
+	}
+
+	// Without a registered deprecation the alias is left unmarked
+	unmarked := NewDeprecations().Alias("/api", "/api/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	plain := httptest.NewRecorder()
+	unmarked.ServeHTTP(plain, httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	if plain.Header().Get("Deprecation") != "" {
+		t.Error("Expected no headers without a registered alias deprecation")
+	}
+}
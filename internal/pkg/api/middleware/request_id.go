@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/apierror"
+)
+
+// RequestIDKey is the context key for the request ID
+const RequestIDKey ContextKey = "request_id"
+
+// validRequestID accepts caller-supplied IDs that are safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an ID, reusing a well-formed X-Request-ID header from the caller
+// (e.g. a load balancer) or generating one. The ID is stored in the request context and returned
+// in the X-Request-ID response header, and error responses echo it as request_id.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(apierror.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(apierror.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDKey, id)))
+	})
+}
+
+// GetRequestID returns the request ID set by RequestID, or an empty string
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r.Context())
+	}))
+
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("X-Request-ID", header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("lb-trace.42")
+	if seen != "lb-trace.42" || rr.Header().Get("X-Request-ID") != "lb-trace.42" {
+		t.Errorf("Expected the caller's request ID to be reused, got context %q header %q", seen, rr.Header().Get("X-Request-ID"))
+	}
+
+	rr = serve("bad id\nwith newline")
+	if seen == "" || seen == "bad id\nwith newline" || rr.Header().Get("X-Request-ID") != seen {
+		t.Errorf("Expected a generated request ID for a malformed header, got %q", seen)
+	}
+
+	rr = serve("")
+	if seen == "" || rr.Header().Get("X-Request-ID") != seen {
+		t.Errorf("Expected a generated request ID, got %q", seen)
+	}
+}
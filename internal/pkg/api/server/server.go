@@ -0,0 +1,442 @@
+// Package server assembles the backend API: handlers, middleware and the /api/v1 routes
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/handlers"
+	authMiddleware "github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/app"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+)
+
+// API path prefixes: routes are served under APIV1Prefix, and under LegacyAPIPrefix as
+// deprecated aliases
+const (
+	APIV1Prefix     = "/api/v1"
+	LegacyAPIPrefix = "/api"
+)
+
+// NewRouter builds the backend API handlers and routes from the container. Background work the
+// API depends on (the job outbox relay, secret reloads, pool statistics and diagnostics) runs
+// until ctx is cancelled.
+func NewRouter(ctx context.Context, cfg *config.Config, container *app.Container, log *logger.Logger) http.Handler {
+	// Initialize handlers
+	// Determine if running in development mode
+	isDevelopment := cfg.Environment == "local" || cfg.Environment == "development" || cfg.Environment == "dev"
+
+	authHandler := handlers.NewAuthHandler(
+		container.OAuthService,
+		container.JWTService,
+		container.UserRepository,
+		container.SessionRepository,
+		cfg.FrontendURL,
+		isDevelopment,
+		log.WithContext("component", "auth_handler"),
+	)
+	// Cookie attributes and session lifetimes come from SESSION_* settings
+	cookiePolicy := handlers.NewCookiePolicy(cfg.Session, isDevelopment)
+	authHandler.SetCookiePolicy(cookiePolicy)
+	// New accounts are admitted according to SIGNUP_POLICY (open, allowlist or invite)
+	authHandler.SetSignupPolicy(handlers.NewSignupPolicy(cfg.Signup, cfg.AdminEmails))
+	// Sign-ins from unseen devices or networks are flagged for the new sign-in email
+	authHandler.SetSignInAudit(container.SignIns)
+	// A second Google account with a user's verified email is linked to it once confirmed
+	authHandler.SetAccountLinking(container.UserRepository, container.Encryption)
+	authHandler.SetAutomationStatus(container.UserRepository)
+
+	stravaHandler := handlers.NewStravaHandler(
+		container.OAuthService,
+		container.UserRepository,
+		cfg.FrontendURL,
+		isDevelopment,
+		container.Policy,
+		log.WithContext("component", "strava_handler"),
+	)
+	stravaHandler.SetCookiePolicy(cookiePolicy)
+
+	googleConnectionHandler := handlers.NewGoogleConnectionHandler(
+		container.UserRepository,
+		container.OAuthService,
+		container.Policy,
+		log.WithContext("component", "google_connection_handler"),
+	)
+
+	readinessHandler := handlers.NewReadinessHandler(
+		container.Readiness,
+		container.Policy,
+		log.WithContext("component", "readiness_handler"),
+	)
+
+	connectionsHandler := handlers.NewConnectionsHandler(
+		container.UserRepository,
+		container.Policy,
+		log.WithContext("component", "connections_handler"),
+	)
+
+	configHandler := handlers.NewConfigHandler(
+		container.ConfigService,
+		container.Policy,
+		log.WithContext("component", "config_handler"),
+	)
+
+	exportHandler := handlers.NewExportHandler(
+		container.ExportService,
+		container.Policy,
+		log.WithContext("component", "export_handler"),
+	)
+
+	manualActivityHandler := handlers.NewManualActivityHandler(
+		container.ActivityRepository,
+		container.Policy,
+		log.WithContext("component", "manual_activity_handler"),
+	)
+
+	statsHandler := handlers.NewStatsHandler(
+		container.StatsService,
+		container.Policy,
+		log.WithContext("component", "stats_handler"),
+	)
+
+	runHandler := handlers.NewRunHandler(
+		container.RunRepository,
+		container.UndoService,
+		container.Policy,
+		log.WithContext("component", "run_handler"),
+	)
+
+	templateHandler := handlers.NewTemplateHandler(
+		container.TemplateService,
+		container.Policy,
+		log.WithContext("component", "template_handler"),
+	)
+
+	notificationPreviewHandler := handlers.NewNotificationPreviewHandler(
+		cfg.FrontendURL,
+		container.Policy,
+		log.WithContext("component", "notification_preview_handler"),
+	)
+
+	emailSuppressionHandler := handlers.NewEmailSuppressionHandler(
+		container.NotificationRepository,
+		container.Policy,
+		log.WithContext("component", "email_suppression_handler"),
+	)
+
+	// Manual sync requires the job queue; without Redis the sync endpoints are not registered
+	// and maintenance mode is unavailable
+	var syncHandler *handlers.SyncHandler
+	var maintenanceStore handlers.MaintenanceStore
+	if jobQueue, err := container.ConnectJobQueue(); err != nil {
+		log.Warn("Job queue unavailable - manual sync endpoints disabled", "error", err)
+	} else {
+		maintenanceStore = jobQueue
+		syncHandler = handlers.NewSyncHandler(
+			jobQueue,
+			container.Policy,
+			log.WithContext("component", "sync_handler"),
+		)
+		syncHandler.SetBlackouts(container.BlackoutRepository, container.UserRepository)
+		syncHandler.SetAccounts(container.UserRepository)
+		syncHandler.SetEvents(jobQueue)
+
+		// Manual syncs are written to the job outbox and published by the relay, so a brief
+		// Redis outage does not lose them
+		syncHandler.SetOutbox(container.OutboxRepository)
+		outboxRelay := queue.NewOutboxRelay(container.OutboxRepository, jobQueue, cfg.API.OutboxRetention, log)
+		go outboxRelay.Run(ctx, cfg.API.OutboxRelayInterval)
+	}
+
+	apiTokenHandler := handlers.NewAPITokenHandler(
+		container.APITokenRepository,
+		container.Policy,
+		log.WithContext("component", "api_token_handler"),
+	)
+
+	roleHandler := handlers.NewRoleHandler(
+		container.UserRepository,
+		container.Policy,
+		log.WithContext("component", "role_handler"),
+	)
+
+	suspensionHandler := handlers.NewSuspensionHandler(
+		container.UserRepository,
+		container.Policy,
+		log.WithContext("component", "suspension_handler"),
+	)
+
+	accountMergeHandler := handlers.NewAccountMergeHandler(
+		container.UserRepository,
+		container.Policy,
+		log.WithContext("component", "account_merge_handler"),
+	)
+
+	// Strava connections and deleted users are announced on the event bus for other services;
+	// without Redis the API works the same and nothing is published
+	if eventBus, err := container.ConnectEventBus(); err != nil {
+		log.Warn("Event bus unavailable - inter-service events disabled", "error", err)
+	} else {
+		stravaHandler.SetEventPublisher(eventBus)
+		accountMergeHandler.SetEventPublisher(eventBus)
+	}
+
+	teamHandler := handlers.NewTeamHandler(
+		container.TeamRepository,
+		container.RunRepository,
+		container.Policy,
+		log.WithContext("component", "team_handler"),
+	)
+	teamHandler.SetSpreadsheets(container.ConfigService)
+
+	blackoutHandler := handlers.NewBlackoutHandler(
+		container.BlackoutRepository,
+		container.Policy,
+		log.WithContext("component", "blackout_handler"),
+	)
+
+	maintenanceHandler := handlers.NewMaintenanceHandler(
+		maintenanceStore,
+		container.Policy,
+		log.WithContext("component", "maintenance_handler"),
+	)
+
+	inviteCodeHandler := handlers.NewInviteCodeHandler(
+		container.InviteCodes,
+		container.Policy,
+		log.WithContext("component", "invite_code_handler"),
+	)
+
+	// Rotated secrets are applied every SECRET_RELOAD_INTERVAL and on POST /internal/config/reload
+	var secretReloader handlers.SecretReloader
+	if secretWatcher, err := container.WatchSecrets(ctx); err != nil {
+		log.Warn("Secret reloading disabled", "error", err)
+	} else if secretWatcher != nil {
+		secretReloader = secretWatcher
+		go container.RunSecretReloads(ctx, secretWatcher)
+	}
+
+	// Database pool statistics are logged every DB_STATS_INTERVAL
+	go container.RunPoolStats(ctx)
+
+	// pprof profiles, goroutine and heap dumps on the internal DIAGNOSTICS_ADDR when enabled
+	go container.RunDiagnostics(ctx)
+
+	configReloadHandler := handlers.NewConfigReloadHandler(
+		secretReloader,
+		container.Policy,
+		log.WithContext("component", "config_reload_handler"),
+	)
+
+	metricsHandler := handlers.NewMetricsHandler(
+		container.DB,
+		container.Policy,
+		log.WithContext("component", "metrics_handler"),
+	)
+
+	// Endpoints slated for removal are announced with Deprecation and Sunset headers
+	deprecations := handlers.APIDeprecations()
+	metaHandler := handlers.NewMetaHandler(deprecations, log)
+
+	// Create router
+	r := chi.NewRouter()
+
+	// Global middleware
+	r.Use(authMiddleware.RequestID) // Tag requests with an ID that error responses echo
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	r.Use(authMiddleware.CORS(cfg.FrontendURL)) // Enable CORS for frontend communication
+	r.Use(deprecations.Middleware)
+	// During maintenance only admin routes, sign-in, health checks and the banner are served
+	r.Use(maintenanceHandler.Middleware(
+		"/health",
+		"/internal",
+		APIV1Prefix+"/admin", LegacyAPIPrefix+"/admin",
+		APIV1Prefix+"/auth", LegacyAPIPrefix+"/auth",
+		APIV1Prefix+"/maintenance", LegacyAPIPrefix+"/maintenance",
+	))
+
+	// Public routes (no authentication required)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Academy Sync Backend API is running in %s environment!", cfg.Environment)
+	})
+
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status": "healthy", "environment": "%s", "service": "backend-api"}`, cfg.Environment)
+	})
+
+	// API routes, served under /api/v1
+	apiRoutes := func(r chi.Router) {
+		// Machine-readable list of deprecated endpoints and fields (public)
+		r.Get("/meta/deprecations", metaHandler.ListDeprecations)
+
+		// Whether maintenance is in progress, for the web app's banner (public)
+		r.Get("/maintenance", maintenanceHandler.Status)
+
+		// Authentication routes (public)
+		r.Route("/auth", func(r chi.Router) {
+			r.Get("/google", authHandler.GoogleAuthURL)           // Get Google OAuth URL
+			r.Get("/google/callback", authHandler.GoogleCallback) // Handle OAuth callback
+			r.Post("/refresh", authHandler.RefreshToken)          // Refresh JWT token
+			r.Post("/sessions/revoke", authHandler.RevokeSession) // Sign out a session from a new sign-in email ({"token"})
+			r.Get("/link", authHandler.GetAccountLink)            // Pending link of a second Google account, for the confirmation prompt
+			r.Post("/link", authHandler.ConfirmAccountLink)       // Link the Google account and sign in
+			r.Delete("/link", authHandler.CancelAccountLink)      // Discard the pending link
+
+			// Protected auth routes
+			r.Group(func(r chi.Router) {
+				r.Use(container.AuthMiddleware.RequireAuth)
+				r.Get("/me", authHandler.GetCurrentUser) // Get current user info
+				r.Post("/logout", authHandler.Logout)    // Logout user
+
+				// Personal access tokens for scripts (Authorization: Bearer asy_...)
+				r.Get("/tokens", apiTokenHandler.List)           // List tokens (secrets are never returned again)
+				r.Post("/tokens", apiTokenHandler.Create)        // Create a token ({"name", "scopes", "expires_in_days"})
+				r.Delete("/tokens/{id}", apiTokenHandler.Delete) // Revoke a token
+			})
+		})
+
+		// Connection routes - mixed public and protected
+		r.Route("/connections", func(r chi.Router) {
+			// Public OAuth callback (Strava redirects here directly)
+			r.Get("/strava/callback", stravaHandler.StravaCallback) // Handle Strava OAuth callback (public)
+
+			// Protected Strava endpoints (require authentication)
+			r.Group(func(r chi.Router) {
+				r.Use(container.AuthMiddleware.RequireAuth)
+				r.Get("/", connectionsHandler.List)                                        // Status of each provider connection
+				r.Get("/strava", stravaHandler.StravaAuthURL)                              // Get Strava OAuth URL
+				r.Delete("/strava", stravaHandler.DisconnectStrava)                        // Disconnect Strava account
+				r.Delete("/google-sheets", googleConnectionHandler.DisconnectGoogleSheets) // Clear the spreadsheet and revoke Google access
+			})
+		})
+
+		// Protected API routes (authentication required)
+		r.Group(func(r chi.Router) {
+			r.Use(container.AuthMiddleware.RequireAuth)
+
+			// User routes
+			r.Route("/users", func(r chi.Router) {
+				r.Get("/me", authHandler.GetCurrentUser) // Duplicate for convenience
+			})
+
+			// Configuration routes
+			r.Route("/config", func(r chi.Router) {
+				r.Post("/spreadsheet", configHandler.SetSpreadsheet)               // Set spreadsheet URL
+				r.Delete("/spreadsheet", configHandler.ClearSpreadsheet)           // Clear spreadsheet configuration
+				r.Put("/webhook", configHandler.SetWebhook)                        // Configure outbound webhook
+				r.Delete("/webhook", configHandler.ClearWebhook)                   // Remove outbound webhook
+				r.Get("/notifications", configHandler.GetNotificationSettings)     // Read notification channel and preferences
+				r.Put("/notifications", configHandler.SetNotificationChannel)      // Choose the channel and which notifications to receive
+				r.Put("/notifications/digest", configHandler.SetDigest)            // Choose per-run or daily digest notifications
+				r.Put("/notifications/quiet-hours", configHandler.SetQuietHours)   // Hold non-critical notifications overnight
+				r.Put("/locale", configHandler.SetLocale)                          // Choose the notification language
+				r.Post("/spreadsheet/template", templateHandler.ProvisionTemplate) // Copy a catalog template into the user's Drive
+				r.Put("/spreadsheet/order", configHandler.SetChronologicalOrder)   // Keep activity rows sorted by date
+				r.Put("/spreadsheet/gear", configHandler.SetGearColumn)            // Add a Gear column to the activity sheet
+				r.Put("/spreadsheet/summary", configHandler.SetWeeklySummary)      // Maintain the weekly summary tab
+			})
+
+			// Dashboard stats (served from the activity cache)
+			r.Get("/stats", statsHandler.GetStats)
+			r.Get("/stats/gear", statsHandler.GetGearMileage)
+
+			// Spreadsheet template catalog
+			r.Get("/templates", templateHandler.ListTemplates)
+
+			// Activity routes
+			r.Route("/activities", func(r chi.Router) {
+				r.Get("/export", exportHandler.ExportActivities) // Download activities as CSV or JSON
+				r.Post("/manual", manualActivityHandler.Create)  // Log an activity not recorded on Strava
+			})
+
+			r.Route("/sync", func(r chi.Router) {
+				r.Get("/runs", runHandler.List)            // The user's recent runs (?limit=20)
+				r.Post("/runs/{id}/undo", runHandler.Undo) // Undo a run's sheet writes (confirmed with {"confirmation_token"})
+
+				// Manual sync routes (require the job queue)
+				if syncHandler != nil {
+					r.Post("/", syncHandler.TriggerSync)             // Enqueue a manual sync ({"dry_run": true} to preview)
+					r.Post("/backfill", syncHandler.TriggerBackfill) // Import history in chained monthly-window jobs
+					r.Get("/stream", syncHandler.StreamSyncStatus)   // Live job status as Server-Sent Events
+					r.Get("/{traceID}", syncHandler.GetSyncResult)   // Poll a sync job status and result
+				}
+			})
+
+			// Coach teams: coaches invite athletes and follow their syncs; athletes answer invitations
+			r.Route("/teams", func(r chi.Router) {
+				r.Get("/", teamHandler.List)                                   // Teams the coach owns
+				r.Post("/", teamHandler.Create)                                // Create a team ({"name"}; coaches only)
+				r.Get("/invitations", teamHandler.Invitations)                 // The user's pending invitations
+				r.Post("/{id}/membership", teamHandler.AcceptInvitation)       // Accept an invitation
+				r.Delete("/{id}/membership", teamHandler.LeaveTeam)            // Decline an invitation or leave the team
+				r.Get("/{id}/athletes", teamHandler.Athletes)                  // Invited athletes and members
+				r.Post("/{id}/athletes", teamHandler.Invite)                   // Invite an athlete ({"email"})
+				r.Get("/{id}/athletes/status", teamHandler.AthleteStatus)      // Coach dashboard: each member's sync status
+				r.Get("/{id}/athletes/{userID}/runs", teamHandler.AthleteRuns) // A member's recent runs (?limit=20)
+				r.Delete("/{id}/athletes/{userID}", teamHandler.RemoveAthlete) // Remove a member or withdraw an invitation
+				r.Put("/{id}/spreadsheet", teamHandler.SetSpreadsheet)         // Shared team spreadsheet ({"url"})
+				r.Delete("/{id}/spreadsheet", teamHandler.ClearSpreadsheet)    // Stop writing the shared spreadsheet
+			})
+
+			// Admin routes (admin or support role required, then authorized per handler; ADMIN_EMAILS
+			// are always admins, other roles are granted through /admin/users/{id}/role)
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(authMiddleware.RequireRole(authz.RoleAdmin, authz.RoleSupport))
+				r.Get("/notifications/preview", notificationPreviewHandler.Preview)             // Render a sample notification (?type=sync_failed&locale=es&format=html)
+				r.Get("/notifications/suppressions", emailSuppressionHandler.List)              // Addresses suppressed after hard bounces
+				r.Delete("/notifications/suppressions/{email}", emailSuppressionHandler.Delete) // Let a suppressed address receive email again
+				r.Get("/blackouts", blackoutHandler.List)                                       // Current and upcoming blackout windows
+				r.Post("/blackouts", blackoutHandler.Create)                                    // Pause syncing for a window ({"starts_at", "ends_at", "reason"})
+				r.Delete("/blackouts/{id}", blackoutHandler.Delete)                             // End or cancel a blackout window
+				r.Put("/maintenance", maintenanceHandler.Start)                                 // Take the service down ({"message", "ends_at"}; admins only)
+				r.Delete("/maintenance", maintenanceHandler.Stop)                               // Bring the service back up
+				r.Get("/invite-codes", inviteCodeHandler.List)                                  // Signup invite codes, newest first
+				r.Post("/invite-codes", inviteCodeHandler.Create)                               // Create a single-use code ({"note", "expires_in_days"}; shown once)
+				r.Delete("/invite-codes/{id}", inviteCodeHandler.Revoke)                        // Revoke an unused invite code
+				r.Put("/users/{id}/role", roleHandler.SetRole)                                  // Grant or revoke a role ({"role": "support", "reason"}; admins only)
+				r.Get("/users/{id}/role-changes", roleHandler.ListChanges)                      // Audit trail of the user's role changes
+				r.Get("/users/{id}/suspension", suspensionHandler.Get)                          // Whether the account is suspended, and why
+				r.Put("/users/{id}/suspension", suspensionHandler.Suspend)                      // Suspend an account ({"reason"}; admins only)
+				r.Delete("/users/{id}/suspension", suspensionHandler.Unsuspend)                 // Lift a suspension (admins only)
+				r.Post("/users/{id}/merge", accountMergeHandler.Merge)                          // Merge a duplicate account into the user ({"duplicate_id"}; admins only)
+			})
+
+			// Automation routes
+			r.Route("/automation", func(r chi.Router) {
+				r.Get("/readiness", readinessHandler.GetReadiness) // Checklist of what automation still needs
+			})
+
+			// Future protected endpoints will go here
+			// r.Route("/notifications", func(r chi.Router) { ... })
+		})
+	}
+	r.Route(APIV1Prefix, apiRoutes)
+
+	// The unversioned /api paths are kept as deprecated aliases of /api/v1 for deployed clients
+	r.Group(func(r chi.Router) {
+		r.Use(deprecations.Alias(LegacyAPIPrefix, APIV1Prefix))
+		r.Route(LegacyAPIPrefix, apiRoutes)
+	})
+
+	// OAuth callbacks stay at the unversioned URLs registered with Google and Strava
+	r.Get(app.GoogleCallbackPath, authHandler.GoogleCallback)
+	r.Get(app.StravaCallbackPath, stravaHandler.StravaCallback)
+
+	// Internal operator routes (authorized per handler; admins only)
+	r.Route("/internal", func(r chi.Router) {
+		r.Use(container.AuthMiddleware.RequireAuth)
+		r.Post("/config/reload", configReloadHandler.Reload)    // Apply rotated secrets without a restart
+		r.Get("/metrics/database", metricsHandler.DatabasePool) // Connection pool statistics
+	})
+
+	return r
+}
@@ -0,0 +1,134 @@
+// Package validate decodes JSON request bodies and checks them field by field. Request types
+// describe their own rules in a Validate method, and every failing field is reported at once so
+// the web app can mark each one in a form instead of fixing them one round trip at a time.
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Field error codes clients can switch on
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeOneOf    = "one_of"
+	CodeTooLong  = "too_long"
+)
+
+// FieldError describes one invalid field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors lists every invalid field of a request body
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fieldErr := range e {
+		parts[i] = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Messages joins the field messages into one sentence-per-field summary for people
+func (e Errors) Messages() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects field errors for one request body
+type Validator struct {
+	errs Errors
+}
+
+// Add records an invalid field
+func (v *Validator) Add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Check records an invalid field when ok is false, and returns ok
+func (v *Validator) Check(ok bool, field, code, message string) bool {
+	if !ok {
+		v.Add(field, code, message)
+	}
+	return ok
+}
+
+// Required records field as missing when value is blank, and reports whether it was present
+func (v *Validator) Required(field, value, message string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, CodeRequired, message)
+		return false
+	}
+	return true
+}
+
+// MaxLength records field as too long when value exceeds max bytes
+func (v *Validator) MaxLength(field, value string, max int) {
+	if len(value) > max {
+		v.Add(field, CodeTooLong, fmt.Sprintf("%s must be at most %d characters", field, max))
+	}
+}
+
+// OneOf records field as invalid unless value, trimmed and lowercased, is one of allowed
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.Add(field, CodeOneOf, fmt.Sprintf("%s must be one of %s", field, strings.Join(allowed, ", ")))
+}
+
+// Err returns the collected field errors, or nil when every field is valid
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Request is implemented by request bodies that check their own fields
+type Request interface {
+	Validate(v *Validator)
+}
+
+// ErrInvalidJSON is returned by Decode when the body is not JSON of the expected shape
+var ErrInvalidJSON = errors.New("invalid JSON in request body")
+
+// Decode decodes a JSON body into dst and, when dst implements Request, validates it.
+// Malformed bodies fail with ErrInvalidJSON and invalid fields with Errors.
+func Decode(body io.Reader, dst interface{}) error {
+	return decode(body, dst, false)
+}
+
+// DecodeOptional is Decode for endpoints whose body may be omitted; an empty body leaves dst at
+// its zero value, which is still validated
+func DecodeOptional(body io.Reader, dst interface{}) error {
+	return decode(body, dst, true)
+}
+
+func decode(body io.Reader, dst interface{}, optional bool) error {
+	if err := json.NewDecoder(body).Decode(dst); err != nil && !(optional && errors.Is(err, io.EOF)) {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	request, ok := dst.(Request)
+	if !ok {
+		return nil
+	}
+	var v Validator
+	request.Validate(&v)
+	return v.Err()
+}
@@ -0,0 +1,80 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testRequest struct {
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+}
+
+func (req *testRequest) Validate(v *Validator) {
+	v.Required("name", req.Name, "name cannot be empty")
+	v.OneOf("channel", req.Channel, "email", "slack")
+}
+
+func TestDecode(t *testing.T) {
+	var req testRequest
+	if err := Decode(strings.NewReader(`{"name": "Ana", "channel": " Slack "}`), &req); err != nil {
+		t.Fatalf("Expected a valid request, got %v", err)
+	}
+	if req.Name != "Ana" {
+		t.Errorf("Expected the body to be decoded, got %+v", req)
+	}
+}
+
+func TestDecode_ReportsEveryField(t *testing.T) {
+	err := Decode(strings.NewReader(`{"name": " ", "channel": "sms"}`), &testRequest{})
+
+	var fieldErrs Errors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("Expected field errors, got %v", err)
+	}
+	if len(fieldErrs) != 2 {
+		t.Fatalf("Expected 2 field errors, got %v", fieldErrs)
+	}
+	if fieldErrs[0].Field != "name" || fieldErrs[0].Code != CodeRequired {
+		t.Errorf("Unexpected name error: %+v", fieldErrs[0])
+	}
+	if fieldErrs[1].Field != "channel" || fieldErrs[1].Code != CodeOneOf || fieldErrs[1].Message != "channel must be one of email, slack" {
+		t.Errorf("Unexpected channel error: %+v", fieldErrs[1])
+	}
+}
+
+func TestDecode_InvalidJSON(t *testing.T) {
+	if err := Decode(strings.NewReader(`{"name": `), &testRequest{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if err := Decode(strings.NewReader(``), &testRequest{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for an empty body, got %v", err)
+	}
+}
+
+func TestDecodeOptional(t *testing.T) {
+	var body struct {
+		DryRun bool `json:"dry_run"`
+	}
+	if err := DecodeOptional(strings.NewReader(``), &body); err != nil {
+		t.Errorf("Expected an empty body to be accepted, got %v", err)
+	}
+
+	// The zero value is still validated
+	var fieldErrs Errors
+	if err := DecodeOptional(strings.NewReader(``), &testRequest{}); !errors.As(err, &fieldErrs) {
+		t.Errorf("Expected an empty body to be validated, got %v", err)
+	}
+}
+
+func TestValidator_MaxLength(t *testing.T) {
+	var v Validator
+	v.MaxLength("url", strings.Repeat("a", 11), 10)
+	v.MaxLength("name", "short", 10)
+
+	var fieldErrs Errors
+	if !errors.As(v.Err(), &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Code != CodeTooLong {
+		t.Errorf("Expected one too_long error, got %v", v.Err())
+	}
+}
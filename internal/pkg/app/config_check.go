@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+// CheckServiceConfig validates the settings service requires before it starts and reports
+// whether startup should stop with exitCode. With validateOnly (the --validate-config flag)
+// the result is printed and startup always stops, with exit code 0 when the configuration is
+// valid. Otherwise missing settings stop startup, except in local development where they
+// are printed as a warning so a service can run with part of its features.
+func CheckServiceConfig(cfg *config.Config, service string, validateOnly bool) (exitCode int, stop bool) {
+	err := cfg.ValidateFor(service)
+	if err == nil {
+		if validateOnly {
+			fmt.Printf("Configuration for %s is valid (environment: %s)\n", service, cfg.Environment)
+			return 0, true
+		}
+		return 0, false
+	}
+
+	report := err.Error()
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		report = validationErr.Report()
+	}
+
+	if !validateOnly && cfg.IsDevelopment() {
+		fmt.Printf("WARNING: %s\n", report)
+		return 0, false
+	}
+	fmt.Printf("ERROR: %s\n", report)
+	return 1, true
+}
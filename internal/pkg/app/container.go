@@ -0,0 +1,393 @@
+// Package app is the composition root shared by the service binaries. Repositories, services,
+// clients and middleware are constructed here once, and each binary selects the components it
+// needs through a profile instead of hand-wiring them in its main function.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/api/middleware"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/authz"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/events"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/health"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/queue"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/redisclient"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/services"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Profile selects the components built for a service binary
+type Profile string
+
+const (
+	ProfileBackendAPI          Profile = "backend-api"
+	ProfileAutomationEngine    Profile = "automation-engine"
+	ProfileNotificationService Profile = "notification-service"
+
+	// ProfileMaintenance is used by operator commands; it builds only the shared repositories
+	ProfileMaintenance Profile = "maintenance"
+)
+
+// requiresDatabase reports whether the profile cannot run without a database connection
+func (p Profile) requiresDatabase() bool {
+	return p != ProfileNotificationService
+}
+
+// Container holds the dependencies of one service. Components the profile does not use are nil.
+type Container struct {
+	Profile Profile
+	Config  *config.Config
+	Logger  *logger.Logger
+	DB      *sql.DB
+
+	// Redis hands out the process's shared Redis connections; it connects on first use
+	Redis *redisclient.Factory
+
+	// Shared by every profile with a database
+	Encryption             *auth.EncryptionService
+	UserRepository         *database.UserRepository
+	ActivityRepository     *database.ActivityRepository
+	RunRepository          *database.RunRepository
+	NotificationRepository *database.NotificationRepository
+	BlackoutRepository     *database.BlackoutRepository
+
+	// Backend API
+	JWTService         *auth.JWTService
+	OAuthService       *auth.OAuthService
+	SessionRepository  *database.SessionRepository
+	OutboxRepository   *database.OutboxRepository
+	APITokenRepository *database.APITokenRepository
+	TeamRepository     *database.TeamRepository
+	InviteCodes        *database.InviteCodeRepository
+	SignIns            *database.SignInRepository // Sign-in audit log; also read by the notification service
+	AuthMiddleware     *middleware.AuthMiddleware
+	Policy             *authz.Policy
+	ConfigService      *services.ConfigService
+	ExportService      *services.ExportService
+	StatsService       *services.StatsService
+	TemplateService    *services.TemplateService
+	UndoService        *services.UndoService
+	Readiness          *automation.ConfigService // Automation prerequisites checklist
+
+	// Automation engine
+	AutomationConfig    *automation.ConfigService
+	BackfillRepository  *database.BackfillRepository
+	RetentionRepository *database.RetentionRepository
+
+	// Notification service; nil without a database. EmailSender, QuietFailureDetector and
+	// SignInAlerter also need an email provider (SMTP or SendGrid), while chat notifications
+	// work without it.
+	EmailSender            notification.EmailSender
+	NotificationDispatcher *notification.Dispatcher
+	RunNotifier            *notification.RunNotifier
+	DigestScheduler        *notification.DigestScheduler
+	QuietHoursReleaser     *notification.QuietHoursReleaser
+	QuietFailureDetector   *notification.QuietFailureDetector
+	SignInAlerter          *notification.SignInAlerter
+
+	// emailProvider is the unwrapped SMTP or SendGrid sender, whose credentials may be rotated
+	emailProvider notification.EmailSender
+
+	closers []func() error
+}
+
+// Open connects to the database and builds the container for profile.
+// Profiles that require the database fail when it is unreachable; the notification
+// service runs without one when DATABASE_URL is not configured.
+func Open(cfg *config.Config, log *logger.Logger, profile Profile) (*Container, error) {
+	var db *sql.DB
+	if profile.requiresDatabase() {
+		var err error
+		if db, err = database.Connect(context.Background(), cfg); err != nil {
+			return nil, err
+		}
+		log.Info("Database connection established successfully",
+			"max_open_conns", cfg.Database.MaxOpenConns,
+			"max_idle_conns", cfg.Database.MaxIdleConns)
+	} else if cfg.DatabaseURL != "" {
+		var err error
+		if db, err = database.Open(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := Build(cfg, log, profile, db)
+	if err != nil {
+		if db != nil {
+			db.Close()
+		}
+		return nil, err
+	}
+	if db != nil {
+		c.closers = append(c.closers, db.Close)
+	}
+	return c, nil
+}
+
+// Build constructs the profile's components on an existing database connection
+// It performs no I/O, so tests can build containers on a mock database
+func Build(cfg *config.Config, log *logger.Logger, profile Profile, db *sql.DB) (*Container, error) {
+	if db == nil && profile.requiresDatabase() {
+		return nil, fmt.Errorf("%s requires a database connection", profile)
+	}
+
+	c := &Container{
+		Profile: profile,
+		Config:  cfg,
+		Logger:  log,
+		DB:      db,
+		Redis: redisclient.NewFactory(redisclient.Options{
+			PoolSize:        cfg.Redis.PoolSize,
+			MinIdleConns:    cfg.Redis.MinIdleConns,
+			PoolTimeout:     cfg.Redis.PoolTimeout,
+			DialTimeout:     cfg.Redis.DialTimeout,
+			ConnMaxIdleTime: cfg.Redis.ConnMaxIdleTime,
+		}),
+	}
+	c.closers = append(c.closers, c.Redis.Close)
+
+	if db != nil {
+		c.Encryption = auth.NewEncryptionService(cfg.EncryptionSecret)
+		c.UserRepository = database.NewUserRepository(db, c.Encryption)
+		c.ActivityRepository = database.NewActivityRepository(db)
+		c.RunRepository = database.NewRunRepository(db)
+		c.NotificationRepository = database.NewNotificationRepository(db)
+		c.BlackoutRepository = database.NewBlackoutRepository(db)
+	}
+
+	switch profile {
+	case ProfileBackendAPI:
+		c.buildBackendAPI()
+	case ProfileAutomationEngine:
+		c.AutomationConfig = automation.NewConfigService(c.UserRepository, log)
+		c.AutomationConfig.SetTeamSheetSource(c.UserRepository)
+		c.BackfillRepository = database.NewBackfillRepository(db)
+		c.RetentionRepository = database.NewRetentionRepository(db)
+	case ProfileNotificationService:
+		c.buildNotificationService()
+	case ProfileMaintenance:
+	default:
+		return nil, fmt.Errorf("unknown service profile %q", profile)
+	}
+
+	return c, nil
+}
+
+// OAuth callback paths registered with Google and Strava. They are not versioned with the
+// rest of the API, so the registered redirect URLs keep working across API versions.
+const (
+	GoogleCallbackPath = "/api/auth/google/callback"
+	StravaCallbackPath = "/api/connections/strava/callback"
+)
+
+// GoogleRedirectURL is the Google OAuth callback served by the backend API
+func GoogleRedirectURL(cfg *config.Config) string {
+	return cfg.BaseURL + GoogleCallbackPath
+}
+
+// StravaRedirectURL is the Strava OAuth callback served by the backend API
+func StravaRedirectURL(cfg *config.Config) string {
+	return cfg.BaseURL + StravaCallbackPath
+}
+
+// StravaEndpoints are the Strava base URLs selected by the provider settings
+func StravaEndpoints(cfg *config.Config) strava.Endpoints {
+	return strava.Endpoints{
+		APIBaseURL:   cfg.Providers.StravaAPIBaseURL,
+		OAuthBaseURL: cfg.Providers.StravaOAuthBaseURL,
+	}
+}
+
+// GoogleEndpoints are the Google URLs selected by the provider settings
+func GoogleEndpoints(cfg *config.Config) google.Endpoints {
+	return google.Endpoints{
+		AuthURL:          cfg.Providers.GoogleAuthURL,
+		TokenURL:         cfg.Providers.GoogleTokenURL,
+		RevokeURL:        cfg.Providers.GoogleRevokeURL,
+		OAuth2APIBaseURL: cfg.Providers.GoogleOAuth2APIBaseURL,
+		SheetsBaseURL:    cfg.Providers.GoogleSheetsBaseURL,
+		DriveBaseURL:     cfg.Providers.GoogleDriveBaseURL,
+	}
+}
+
+// OAuthClients are the configured Google and Strava OAuth apps, for startup credential checks
+func OAuthClients(cfg *config.Config) []health.OAuthClient {
+	var clients []health.OAuthClient
+	if cfg.GoogleClientID != "" {
+		clients = append(clients, health.OAuthClient{
+			Provider:     health.ProviderGoogle,
+			TokenURL:     GoogleEndpoints(cfg).OAuthEndpoint().TokenURL,
+			ClientID:     cfg.GoogleClientID,
+			ClientSecret: cfg.GoogleClientSecret,
+		})
+	}
+	if cfg.StravaClientID != "" {
+		clients = append(clients, health.OAuthClient{
+			Provider:     health.ProviderStrava,
+			TokenURL:     StravaEndpoints(cfg).OAuthEndpoint().TokenURL,
+			ClientID:     cfg.StravaClientID,
+			ClientSecret: cfg.StravaClientSecret,
+		})
+	}
+	return clients
+}
+
+func (c *Container) buildBackendAPI() {
+	cfg, log := c.Config, c.Logger
+
+	c.JWTService = auth.NewJWTService(cfg.JWTSecret)
+	if cfg.Session.AccessTokenTTL > 0 {
+		c.JWTService.SetTokenTTL(cfg.Session.AccessTokenTTL)
+	}
+	c.OAuthService = auth.NewOAuthService(
+		cfg.GoogleClientID,
+		cfg.GoogleClientSecret,
+		GoogleRedirectURL(cfg),
+		cfg.StravaClientID,
+		cfg.StravaClientSecret,
+		StravaRedirectURL(cfg),
+	)
+	c.OAuthService.SetEndpoints(GoogleEndpoints(cfg), StravaEndpoints(cfg))
+	c.SessionRepository = database.NewSessionRepository(c.DB)
+	// Each use extends a session to SESSION_TTL from then, up to SESSION_MAX_LIFETIME after sign-in
+	if cfg.Session.MaxLifetime > 0 {
+		c.SessionRepository.SetSlidingExpiration(cfg.Session.TTL, cfg.Session.MaxLifetime)
+	}
+	c.OutboxRepository = database.NewOutboxRepository(c.DB)
+	c.AuthMiddleware = middleware.NewAuthMiddleware(c.JWTService, c.SessionRepository, c.OAuthService, c.UserRepository, log.WithContext("component", "auth_middleware"))
+	c.AuthMiddleware.SetAdminEmails(cfg.AdminEmails)
+	c.APITokenRepository = database.NewAPITokenRepository(c.DB)
+	c.AuthMiddleware.SetAPITokens(c.APITokenRepository)
+	// Coaches may read the run history, stats and sync jobs of the athletes on their teams
+	c.TeamRepository = database.NewTeamRepository(c.DB)
+	c.Policy = authz.DefaultPolicy().With(authz.CoachRule(c.TeamRepository))
+	c.InviteCodes = database.NewInviteCodeRepository(c.DB)
+	c.SignIns = database.NewSignInRepository(c.DB)
+
+	sheetsService := services.NewSheetsService(c.UserRepository, log)
+	sheetsService.SetEndpoints(GoogleEndpoints(cfg))
+	c.ConfigService = services.NewConfigService(c.UserRepository, sheetsService, log)
+	c.Readiness = automation.NewConfigService(c.UserRepository, log)
+	c.Readiness.SetSpreadsheetAccessChecker(sheetsService)
+	c.ExportService = services.NewExportService(c.UserRepository, c.ActivityRepository, cfg.StravaClientID, cfg.StravaClientSecret, log)
+	c.ExportService.SetStravaEndpoints(StravaEndpoints(cfg))
+	c.StatsService = services.NewStatsService(c.UserRepository, c.ActivityRepository, log)
+	c.TemplateService = services.NewTemplateService(c.UserRepository, cfg.SheetTemplateSources, cfg.GoogleClientID, cfg.GoogleClientSecret, GoogleRedirectURL(cfg), log)
+	c.TemplateService.SetEndpoints(GoogleEndpoints(cfg))
+	c.UndoService = services.NewUndoService(c.UserRepository, c.RunRepository, cfg.GoogleClientID, cfg.GoogleClientSecret, GoogleRedirectURL(cfg), log)
+	c.UndoService.SetEndpoints(GoogleEndpoints(cfg))
+}
+
+func (c *Container) buildNotificationService() {
+	cfg := c.Config
+	if c.DB == nil {
+		c.Logger.Warn("Notifications disabled - database is not configured")
+		return
+	}
+
+	if sender := newEmailSender(cfg); sender != nil {
+		c.emailProvider = sender
+		c.EmailSender = notification.NewReliableSender(sender, c.NotificationRepository, c.Logger)
+	}
+	c.NotificationDispatcher = notification.NewDispatcher(c.EmailSender, notification.NewChatSender(), c.UserRepository, c.NotificationRepository, c.Logger)
+	c.QuietHoursReleaser = notification.NewQuietHoursReleaser(c.NotificationRepository, c.NotificationDispatcher, c.Logger)
+	c.RunNotifier = notification.NewRunNotifier(
+		c.NotificationRepository,
+		c.NotificationDispatcher,
+		notification.DefaultMinNotificationInterval,
+		cfg.FrontendURL,
+		c.Logger,
+	)
+	c.DigestScheduler = notification.NewDigestScheduler(c.NotificationRepository, c.NotificationDispatcher, cfg.FrontendURL, c.Logger)
+
+	if c.EmailSender == nil {
+		c.Logger.Warn("Quiet failure detection and new sign-in alerts disabled - an email provider must be configured")
+		return
+	}
+	c.QuietFailureDetector = notification.NewQuietFailureDetector(
+		c.NotificationRepository,
+		c.NotificationDispatcher,
+		notification.NewThrottle(c.NotificationRepository, notification.DefaultMinNotificationInterval),
+		notification.DefaultQuietFailureThresholds,
+		cfg.FrontendURL,
+		c.Logger,
+	)
+
+	// New sign-in alerts are security notices, so they are emailed whatever channel users chose,
+	// unless they turned security alerts off
+	c.SignIns = database.NewSignInRepository(c.DB)
+	c.SignInAlerter = notification.NewSignInAlerter(c.SignIns, notification.NewEmailDeliverer(c.EmailSender, c.UserRepository), cfg.FrontendURL, c.Logger)
+}
+
+// newEmailSender returns the sender for the configured email provider, or nil when the provider
+// is not configured (FROM_EMAIL and either SMTP_HOST or SENDGRID_API_KEY are required)
+func newEmailSender(cfg *config.Config) notification.EmailSender {
+	if cfg.FromEmail == "" {
+		return nil
+	}
+	switch cfg.EmailProvider {
+	case config.EmailProviderSendGrid:
+		if cfg.SendGridAPIKey == "" {
+			return nil
+		}
+		return notification.NewSendGridSender(cfg.SendGridAPIKey, cfg.FromEmail)
+	default:
+		if cfg.SMTPHost == "" {
+			return nil
+		}
+		return notification.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.FromEmail)
+	}
+}
+
+// ConnectJobQueue creates the job queue client on the shared Redis connection and verifies Redis
+// is reachable. The connection is closed with the container, not by the caller.
+func (c *Container) ConnectJobQueue() (*queue.Client, error) {
+	if c.Config.RedisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is not configured")
+	}
+
+	rdb, err := c.Redis.Client(c.Config.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := redisclient.Ping(context.Background(), rdb); err != nil {
+		return nil, err
+	}
+	return queue.NewClientFromRedis(rdb, c.Logger), nil
+}
+
+// ConnectEventBus creates the inter-service event bus on the shared Redis connection and verifies
+// Redis is reachable. Events are published with the profile as their source.
+func (c *Container) ConnectEventBus() (*events.RedisBus, error) {
+	if c.Config.RedisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is not configured")
+	}
+
+	rdb, err := c.Redis.Client(c.Config.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := redisclient.Ping(context.Background(), rdb); err != nil {
+		return nil, err
+	}
+	return events.NewRedisBus(rdb, string(c.Profile), c.Logger), nil
+}
+
+// Close releases the container's connections in reverse order of creation
+func (c *Container) Close() {
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		if err := c.closers[i](); err != nil {
+			c.Logger.Warn("Failed to close dependency", "error", err)
+		}
+	}
+	c.closers = nil
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		BaseURL:          "https://api.example.com",
+		FrontendURL:      "https://app.example.com",
+		JWTSecret:        "jwt-secret",
+		EncryptionSecret: "encryption-secret",
+		SMTPHost:         "smtp.example.com",
+		SMTPPort:         "587",
+		FromEmail:        "noreply@example.com",
+	}
+}
+
+func TestBuild_Profiles(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("backend api", func(t *testing.T) {
+		c, err := Build(testConfig(), logger.New("test"), ProfileBackendAPI, db)
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		if c.AuthMiddleware == nil || c.Policy == nil || c.ConfigService == nil || c.ExportService == nil ||
+			c.StatsService == nil || c.TemplateService == nil || c.SessionRepository == nil || c.Readiness == nil {
+			t.Errorf("Expected every backend component to be built: %+v", c)
+		}
+		if c.AutomationConfig != nil || c.QuietFailureDetector != nil {
+			t.Error("Expected components of other profiles to be nil")
+		}
+	})
+
+	t.Run("automation engine", func(t *testing.T) {
+		c, err := Build(testConfig(), logger.New("test"), ProfileAutomationEngine, db)
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		if c.AutomationConfig == nil || c.UserRepository == nil || c.ActivityRepository == nil || c.RunRepository == nil {
+			t.Errorf("Expected every engine component to be built: %+v", c)
+		}
+		if c.AuthMiddleware != nil {
+			t.Error("Expected backend components to be nil")
+		}
+	})
+
+	t.Run("notification service", func(t *testing.T) {
+		c, err := Build(testConfig(), logger.New("test"), ProfileNotificationService, db)
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		if c.EmailSender == nil || c.QuietFailureDetector == nil {
+			t.Error("Expected the quiet failure detector with database and SMTP configured")
+		}
+		if c.NotificationDispatcher == nil || c.RunNotifier == nil || c.DigestScheduler == nil {
+			t.Error("Expected run notifications with a database configured")
+		}
+	})
+}
+
+func TestNewEmailSender(t *testing.T) {
+	cfg := testConfig()
+	if _, ok := newEmailSender(cfg).(*notification.SMTPSender); !ok {
+		t.Error("Expected SMTP to be the default email provider")
+	}
+
+	cfg.EmailProvider = config.EmailProviderSendGrid
+	if newEmailSender(cfg) != nil {
+		t.Error("Expected no sender without a SendGrid API key")
+	}
+	cfg.SendGridAPIKey = "SG.key"
+	if _, ok := newEmailSender(cfg).(*notification.SendGridSender); !ok {
+		t.Error("Expected the SendGrid sender when selected")
+	}
+
+	cfg.FromEmail = ""
+	if newEmailSender(cfg) != nil {
+		t.Error("Expected no sender without FROM_EMAIL")
+	}
+}
+
+func TestBuild_WithoutDatabase(t *testing.T) {
+	if _, err := Build(testConfig(), logger.New("test"), ProfileBackendAPI, nil); err == nil {
+		t.Error("Expected the backend API to require a database")
+	}
+
+	c, err := Build(testConfig(), logger.New("test"), ProfileNotificationService, nil)
+	if err != nil {
+		t.Fatalf("Expected the notification service to build without a database: %v", err)
+	}
+	if c.QuietFailureDetector != nil {
+		t.Error("Expected quiet failure detection to be disabled without a database")
+	}
+
+	if _, err := Build(testConfig(), logger.New("test"), Profile("unknown"), nil); err == nil {
+		t.Error("Expected an unknown profile to fail")
+	}
+}
+
+func TestConnectJobQueue_NotConfigured(t *testing.T) {
+	c, err := Build(testConfig(), logger.New("test"), ProfileNotificationService, nil)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := c.ConnectJobQueue(); err == nil {
+		t.Error("Expected an error without REDIS_URL")
+	}
+	if _, err := c.ConnectEventBus(); err == nil {
+		t.Error("Expected the event bus to need REDIS_URL too")
+	}
+	c.Close()
+}
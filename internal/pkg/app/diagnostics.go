@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/diagnostics"
+)
+
+// diagnosticsShutdownTimeout bounds the wait for in-flight profiles when ctx is cancelled
+const diagnosticsShutdownTimeout = 5 * time.Second
+
+// RunDiagnostics serves the runtime diagnostics on DIAGNOSTICS_ADDR until ctx is cancelled. It
+// returns immediately when diagnostics are disabled; a failure to listen is logged and leaves
+// the service running without them.
+func (c *Container) RunDiagnostics(ctx context.Context) {
+	if !c.Config.Diagnostics.Enabled {
+		return
+	}
+
+	log := c.Logger.WithContext("component", "diagnostics")
+	server := &http.Server{
+		Addr:              c.Config.Diagnostics.Addr,
+		Handler:           diagnostics.NewHandler(c.Logger.ServiceName()),
+		ReadHeaderTimeout: 10 * time.Second,
+		// No write timeout: CPU profiles and traces stream for as long as requested
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), diagnosticsShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Diagnostics server listening", "addr", server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Diagnostics server failed", "addr", server.Addr, "error", err)
+	}
+}
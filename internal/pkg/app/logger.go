@@ -0,0 +1,39 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// NewLogger creates the logger of service from the loaded configuration. Entries below
+// LOG_LEVEL are never written; output is human-readable in local development and Cloud
+// Logging JSON elsewhere unless LOG_FORMAT says otherwise. LOG_ERROR_REPORTING makes JSON
+// errors show up in Cloud Error Reporting, grouped per service.
+func NewLogger(cfg *config.Config, service string) *logger.Logger {
+	format := strings.ToLower(cfg.Logging.Format)
+	if format == "" {
+		format = logger.FormatJSON
+		if cfg.IsDevelopment() {
+			format = logger.FormatConsole
+		}
+	}
+
+	opts := logger.Options{
+		Level:               cfg.LogLevel,
+		Format:              format,
+		DebugSampleBurst:    cfg.Logging.DebugSampleBurst,
+		DebugSampleInterval: cfg.Logging.DebugSampleInterval,
+		RedactFields:        cfg.Logging.RedactFields,
+	}
+	if cfg.Logging.ErrorReporting {
+		version := cfg.Logging.ServiceVersion
+		if version == "" {
+			version = os.Getenv("K_REVISION")
+		}
+		opts.ErrorReporting = &logger.ErrorReporting{ProjectID: cfg.GCPProjectID, Version: version}
+	}
+	return logger.NewWithOptions(service, opts)
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+// RunPoolStats logs the database pool statistics every DB_STATS_INTERVAL until ctx is cancelled.
+// It returns immediately when the logging is disabled or the container has no database.
+func (c *Container) RunPoolStats(ctx context.Context) {
+	interval := c.Config.Database.StatsInterval
+	if c.DB == nil || interval <= 0 {
+		return
+	}
+
+	log := c.Logger.WithContext("component", "database_pool")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Info("Database pool statistics", database.Stats(c.DB).LogValues()...)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
+)
+
+// WatchSecrets opens a watcher over the secret backend the configuration was loaded from and
+// registers the components built for the profile, so rotated OAuth client secrets and email
+// credentials are applied without a restart. Binaries register the components they build
+// themselves with OnChange. It returns nil when the configuration came from the environment.
+func (c *Container) WatchSecrets(ctx context.Context) (*config.SecretWatcher, error) {
+	watcher, err := config.OpenSecretWatcher(ctx, c.Config)
+	if err != nil || watcher == nil {
+		return nil, err
+	}
+	c.closers = append(c.closers, watcher.Close)
+
+	if c.OAuthService != nil {
+		watcher.OnChange(config.SecretGoogleClientSecret, c.OAuthService.SetGoogleClientSecret)
+		watcher.OnChange(config.SecretStravaClientSecret, c.OAuthService.SetStravaClientSecret)
+	}
+	if c.ExportService != nil {
+		watcher.OnChange(config.SecretStravaClientSecret, c.ExportService.SetStravaClientSecret)
+	}
+	if c.UndoService != nil {
+		watcher.OnChange(config.SecretGoogleClientSecret, c.UndoService.SetGoogleClientSecret)
+	}
+	if c.TemplateService != nil {
+		watcher.OnChange(config.SecretGoogleClientSecret, c.TemplateService.SetGoogleClientSecret)
+	}
+
+	switch sender := c.emailProvider.(type) {
+	case *notification.SMTPSender:
+		watcher.OnChange(config.SecretSMTPPassword, sender.SetPassword)
+	case *notification.SendGridSender:
+		watcher.OnChange(config.SecretSendGridAPIKey, sender.SetAPIKey)
+	}
+
+	return watcher, nil
+}
+
+// RunSecretReloads re-fetches the secrets every SECRET_RELOAD_INTERVAL until ctx is cancelled.
+// It returns immediately when periodic reloads are disabled or watcher is nil.
+func (c *Container) RunSecretReloads(ctx context.Context, watcher *config.SecretWatcher) {
+	interval := c.Config.Secrets.ReloadInterval
+	if watcher == nil || interval <= 0 {
+		return
+	}
+
+	watcher.Run(ctx, interval, func(changed []string, err error) {
+		if err != nil {
+			c.Logger.Warn("Periodic secret reload failed", "error", err)
+			return
+		}
+		c.Logger.Info("Rotated secrets applied", "secrets", changed)
+	})
+}
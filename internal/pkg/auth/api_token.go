@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APITokenPrefix starts every personal access token, so tokens are recognisable in scripts,
+// logs and secret scanners, and are told apart from session JWTs
+const APITokenPrefix = "asy_"
+
+// apiTokenDisplayLength is how much of a token is kept in plaintext to identify it in listings
+const apiTokenDisplayLength = len(APITokenPrefix) + 8
+
+// NewAPIToken generates a personal access token, the leading characters shown in listings and
+// the hash stored for it. The token itself is returned to the user once and never stored.
+func NewAPIToken() (token, displayPrefix, hash string, err error) {
+	// 32 bytes (256 bits) of cryptographically secure random data
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	return token, token[:apiTokenDisplayLength], HashAPIToken(token), nil
+}
+
+// IsAPIToken reports whether token looks like a personal access token rather than a JWT
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken returns the hex SHA-256 hash stored for a personal access token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAPIToken(t *testing.T) {
+	token, prefix, hash, err := NewAPIToken()
+	if err != nil {
+		t.Fatalf("NewAPIToken() failed: %v", err)
+	}
+	if !IsAPIToken(token) || len(token) < 40 {
+		t.Errorf("Expected a prefixed token with at least 256 bits, got %q", token)
+	}
+	if !strings.HasPrefix(token, prefix) || len(prefix) != 12 {
+		t.Errorf("Expected the display prefix to be the start of the token, got %q", prefix)
+	}
+	if hash != HashAPIToken(token) || len(hash) != 64 {
+		t.Errorf("Expected the hex SHA-256 of the token, got %q", hash)
+	}
+
+	if IsAPIToken("eyJhbGciOiJIUzI1NiJ9.e30.sig") {
+		t.Error("Expected a JWT not to be taken for an API token")
+	}
+}
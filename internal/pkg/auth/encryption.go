@@ -82,22 +82,32 @@ func (e *EncryptionService) Encrypt(plaintext string) ([]byte, error) {
 
 // Decrypt decrypts encrypted data and returns the plaintext
 func (e *EncryptionService) Decrypt(ciphertext []byte) (string, error) {
+	plaintext, err := e.DecryptBytes(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// DecryptBytes decrypts encrypted data into a new buffer the caller owns, so secrets can be
+// zeroed after use (see secure.NewToken). It returns nil for nil/empty ciphertext.
+func (e *EncryptionService) DecryptBytes(ciphertext []byte) ([]byte, error) {
 	if len(ciphertext) == 0 {
-		return "", nil // Return empty string for nil/empty bytes
+		return nil, nil
 	}
 
 	block, err := aes.NewCipher(e.key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if len(ciphertext) < gcm.NonceSize() {
-		return "", errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 
 	// Extract nonce and ciphertext
@@ -105,10 +115,5 @@ func (e *EncryptionService) Decrypt(ciphertext []byte) (string, error) {
 	ciphertext = ciphertext[gcm.NonceSize():]
 
 	// Decrypt the data
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
+	return gcm.Open(nil, nonce, ciphertext, nil)
 }
\ No newline at end of file
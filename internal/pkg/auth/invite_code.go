@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InviteCodePrefix starts every signup invite code, so codes are told apart from API tokens
+const InviteCodePrefix = "inv_"
+
+// inviteCodeDisplayLength is how much of a code is kept in plaintext to identify it in listings
+const inviteCodeDisplayLength = len(InviteCodePrefix) + 6
+
+// NewInviteCode generates a single-use signup invite code, the leading characters shown in
+// listings and the hash stored for it. The code itself is shown to the admin once.
+func NewInviteCode() (code, displayPrefix, hash string, err error) {
+	// 16 bytes (128 bits) keep codes short enough to paste into a link
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	code = InviteCodePrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	return code, code[:inviteCodeDisplayLength], HashInviteCode(code), nil
+}
+
+// HashInviteCode returns the hex SHA-256 hash stored for an invite code; surrounding whitespace
+// from copying the code is ignored
+func HashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	code, prefix, hash, err := NewInviteCode()
+	if err != nil {
+		t.Fatalf("NewInviteCode() failed: %v", err)
+	}
+	if !strings.HasPrefix(code, InviteCodePrefix) || len(code) < 24 {
+		t.Errorf("Expected a prefixed code with at least 128 bits, got %q", code)
+	}
+	if !strings.HasPrefix(code, prefix) || len(prefix) != 10 {
+		t.Errorf("Expected the display prefix to be the start of the code, got %q", prefix)
+	}
+	if hash != HashInviteCode(code) || len(hash) != 64 {
+		t.Errorf("Expected the hex SHA-256 of the code, got %q", hash)
+	}
+	if HashInviteCode(" "+code+"\n") != hash {
+		t.Error("Expected whitespace around a pasted code to be ignored")
+	}
+}
@@ -13,31 +13,51 @@ type JWTClaims struct {
 	Email     string `json:"email"`
 	GoogleID  string `json:"google_id"`
 	SessionID int    `json:"session_id"`
+	// Role is the user's account role when the token was issued; empty for plain users
+	Role string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// DefaultTokenTTL is the lifetime of generated access tokens unless SetTokenTTL changes it;
+// sessions outlive it through refresh tokens (see NewRefreshToken)
+const DefaultTokenTTL = 15 * time.Minute
+
 // JWTService handles JWT token generation and validation
 type JWTService struct {
 	secretKey []byte
+	tokenTTL  time.Duration
 }
 
 // NewJWTService creates a new JWT service with the provided secret key
 func NewJWTService(secretKey string) *JWTService {
 	return &JWTService{
 		secretKey: []byte(secretKey),
+		tokenTTL:  DefaultTokenTTL,
 	}
 }
 
+// SetTokenTTL sets the lifetime of generated access tokens
+func (j *JWTService) SetTokenTTL(ttl time.Duration) {
+	j.tokenTTL = ttl
+}
+
 // GenerateToken generates a new JWT token for the given user
 func (j *JWTService) GenerateToken(userID int, email, googleID string, sessionID int) (string, error) {
+	return j.GenerateTokenWithRole(userID, email, googleID, sessionID, "")
+}
+
+// GenerateTokenWithRole generates a new JWT token carrying the user's account role, so role
+// checks need no database lookup. Role changes take effect when the token is next refreshed.
+func (j *JWTService) GenerateTokenWithRole(userID int, email, googleID string, sessionID int, role string) (string, error) {
 	// Create claims with user information and standard claims
 	claims := JWTClaims{
 		UserID:    userID,
 		Email:     email,
 		GoogleID:  googleID,
 		SessionID: sessionID,
+		Role:      role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hour expiry
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "academy-sync",
@@ -57,6 +77,13 @@ func (j *JWTService) GenerateToken(userID int, email, googleID string, sessionID
 	return tokenString, nil
 }
 
+// IsTokenExpired reports whether err from ValidateToken means the token was valid but expired,
+// so the client should refresh it rather than sign in again. Malformed tokens and tokens with a
+// bad signature are not reported as expired, whatever their expiry.
+func IsTokenExpired(err error) bool {
+	return errors.Is(err, jwt.ErrTokenExpired)
+}
+
 // ValidateToken parses and validates a JWT token, returning the claims if valid
 func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	// Parse the token
@@ -80,7 +107,7 @@ func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	// Check if token is expired
 	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, errors.New("token has expired")
+		return nil, jwt.ErrTokenExpired
 	}
 
 	return claims, nil
@@ -95,5 +122,5 @@ func (j *JWTService) RefreshToken(tokenString string) (string, error) {
 	}
 
 	// Generate a new token with the same user information
-	return j.GenerateToken(claims.UserID, claims.Email, claims.GoogleID, claims.SessionID)
+	return j.GenerateTokenWithRole(claims.UserID, claims.Email, claims.GoogleID, claims.SessionID, claims.Role)
 }
\ No newline at end of file
@@ -142,6 +142,9 @@ func TestJWTService(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error for expired token")
 		}
+		if !IsTokenExpired(err) {
+			t.Errorf("Expected the error to report expiry, got %v", err)
+		}
 	})
 
 	t.Run("RefreshToken", func(t *testing.T) {
@@ -190,6 +193,28 @@ func TestJWTService(t *testing.T) {
 		}
 	})
 
+	t.Run("RoleClaim", func(t *testing.T) {
+		service := setupJWTService()
+		
+		token, err := service.GenerateTokenWithRole(123, "test@example.com", "google123", 456, "support")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		refreshed, err := service.RefreshToken(token)
+		if err != nil {
+			t.Fatalf("Failed to refresh token: %v", err)
+		}
+		
+		// The role survives a refresh
+		claims, err := service.ValidateToken(refreshed)
+		if err != nil {
+			t.Fatalf("Failed to validate refreshed token: %v", err)
+		}
+		if claims.Role != "support" {
+			t.Errorf("Expected Role support, got %q", claims.Role)
+		}
+	})
+
 	t.Run("RefreshInvalidToken", func(t *testing.T) {
 		service := setupJWTService()
 		
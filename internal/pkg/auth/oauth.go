@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
 )
 
 // GoogleUserInfo represents the user information returned by Google's userinfo API
@@ -35,8 +38,11 @@ type StravaUserInfo struct {
 
 // OAuthService handles OAuth 2.0 authentication for Google and Strava
 type OAuthService struct {
-	googleConfig *oauth2.Config
-	stravaConfig *oauth2.Config
+	mu              sync.RWMutex // Guards the configs, whose client secrets may be rotated
+	googleConfig    *oauth2.Config
+	stravaConfig    *oauth2.Config
+	googleEndpoints google.Endpoints
+	stravaEndpoints strava.Endpoints
 }
 
 // NewOAuthService creates a new OAuth service with Google and Strava configurations
@@ -50,8 +56,9 @@ func NewOAuthService(googleClientID, googleClientSecret, googleRedirectURL, stra
 			"profile",
 			"email",
 			"https://www.googleapis.com/auth/spreadsheets", // Google Sheets access for automation
+			"https://www.googleapis.com/auth/drive.file",   // Copy catalog templates into the user's Drive
 		},
-		Endpoint: google.Endpoint,
+		Endpoint: google.DefaultEndpoints().OAuthEndpoint(),
 	}
 
 	stravaConfig := &oauth2.Config{
@@ -61,31 +68,76 @@ func NewOAuthService(googleClientID, googleClientSecret, googleRedirectURL, stra
 		Scopes: []string{
 			"activity:read_all", // Read all activities from Strava
 		},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  "https://www.strava.com/oauth/authorize",
-			TokenURL: "https://www.strava.com/api/v3/oauth/token",
-		},
+		Endpoint: strava.DefaultEndpoints().OAuthEndpoint(),
 	}
 
 	return &OAuthService{
-		googleConfig: googleConfig,
-		stravaConfig: stravaConfig,
+		googleConfig:    googleConfig,
+		stravaConfig:    stravaConfig,
+		googleEndpoints: google.DefaultEndpoints(),
+		stravaEndpoints: strava.DefaultEndpoints(),
 	}
 }
 
+// SetEndpoints points sign-in and account linking at alternate Google and Strava URLs,
+// such as mock servers in staging
+func (o *OAuthService) SetEndpoints(googleEndpoints google.Endpoints, stravaEndpoints strava.Endpoints) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.googleEndpoints = googleEndpoints
+	o.stravaEndpoints = stravaEndpoints
+	o.googleConfig.Endpoint = googleEndpoints.OAuthEndpoint()
+	o.stravaConfig.Endpoint = stravaEndpoints.OAuthEndpoint()
+}
+
+// SetGoogleClientSecret replaces the Google client secret after a rotation
+func (o *OAuthService) SetGoogleClientSecret(secret string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.googleConfig.ClientSecret = secret
+}
+
+// SetStravaClientSecret replaces the Strava client secret after a rotation
+func (o *OAuthService) SetStravaClientSecret(secret string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.stravaConfig.ClientSecret = secret
+}
+
+// googleOAuth returns a snapshot of the Google OAuth config
+func (o *OAuthService) googleOAuth() *oauth2.Config {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	config := *o.googleConfig
+	return &config
+}
+
+// stravaOAuth returns a snapshot of the Strava OAuth config
+func (o *OAuthService) stravaOAuth() *oauth2.Config {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	config := *o.stravaConfig
+	return &config
+}
+
 // GetAuthURL generates the Google OAuth authorization URL
 func (o *OAuthService) GetAuthURL(state string) string {
-	return o.googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent"))
+	return o.googleOAuth().AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent"))
 }
 
 // GetStravaAuthURL generates the Strava OAuth authorization URL
 func (o *OAuthService) GetStravaAuthURL(state string) string {
-	return o.stravaConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("approval_prompt", "force"))
+	return o.stravaOAuth().AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("approval_prompt", "force"))
 }
 
 // ExchangeCodeForToken exchanges an authorization code for Google OAuth tokens
 func (o *OAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := o.googleConfig.Exchange(ctx, code)
+	token, err := o.googleOAuth().Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
@@ -95,7 +147,7 @@ func (o *OAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*
 
 // ExchangeStravaCodeForToken exchanges an authorization code for Strava OAuth tokens
 func (o *OAuthService) ExchangeStravaCodeForToken(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := o.stravaConfig.Exchange(ctx, code)
+	token, err := o.stravaOAuth().Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange Strava code for token: %w", err)
 	}
@@ -105,9 +157,9 @@ func (o *OAuthService) ExchangeStravaCodeForToken(ctx context.Context, code stri
 
 // GetUserInfo retrieves user information from Google using the access token
 func (o *OAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*GoogleUserInfo, error) {
-	client := o.googleConfig.Client(ctx, token)
+	client := o.googleOAuth().Client(ctx, token)
 
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+	resp, err := client.Get(o.googleEndpoints.UserInfoURL())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -132,9 +184,9 @@ func (o *OAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*G
 
 // GetStravaUserInfo retrieves athlete information from Strava using the access token
 func (o *OAuthService) GetStravaUserInfo(ctx context.Context, token *oauth2.Token) (*StravaUserInfo, error) {
-	client := o.stravaConfig.Client(ctx, token)
+	client := o.stravaOAuth().Client(ctx, token)
 
-	resp, err := client.Get("https://www.strava.com/api/v3/athlete")
+	resp, err := client.Get(o.stravaEndpoints.APIURL("/athlete"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Strava athlete info: %w", err)
 	}
@@ -163,7 +215,7 @@ func (o *OAuthService) RefreshToken(ctx context.Context, refreshToken string) (*
 		RefreshToken: refreshToken,
 	}
 
-	tokenSource := o.googleConfig.TokenSource(ctx, token)
+	tokenSource := o.googleOAuth().TokenSource(ctx, token)
 	newToken, err := tokenSource.Token()
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh Google token: %w", err)
@@ -178,7 +230,7 @@ func (o *OAuthService) RefreshStravaToken(ctx context.Context, refreshToken stri
 		RefreshToken: refreshToken,
 	}
 
-	tokenSource := o.stravaConfig.TokenSource(ctx, token)
+	tokenSource := o.stravaOAuth().TokenSource(ctx, token)
 	newToken, err := tokenSource.Token()
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh Strava token: %w", err)
@@ -0,0 +1,24 @@
+package auth
+
+import "testing"
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	if len(token) < 40 {
+		t.Errorf("Expected at least 256 bits of token, got %q", token)
+	}
+	if hash != HashRefreshToken(token) || len(hash) != 64 {
+		t.Errorf("Expected the hex SHA-256 of the token, got %q", hash)
+	}
+
+	other, _, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	if other == token {
+		t.Error("Expected every refresh token to be unique")
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// revokeTimeout bounds a revocation call so disconnecting an account never hangs on the provider
+const revokeTimeout = 10 * time.Second
+
+// DeauthorizeStrava revokes the app's access to the athlete's Strava account, invalidating
+// every token issued for it. Strava requires a valid (unexpired) access token.
+func (o *OAuthService) DeauthorizeStrava(ctx context.Context, accessToken string) error {
+	o.mu.RLock()
+	deauthorizeURL := o.stravaEndpoints.DeauthorizeURL()
+	o.mu.RUnlock()
+
+	if err := postRevocation(ctx, deauthorizeURL, url.Values{"access_token": {accessToken}}); err != nil {
+		return fmt.Errorf("failed to deauthorize Strava: %w", err)
+	}
+	return nil
+}
+
+// RevokeGoogleToken revokes a Google access or refresh token. Revoking a refresh token also
+// revokes the access tokens issued from it, ending the whole grant.
+func (o *OAuthService) RevokeGoogleToken(ctx context.Context, token string) error {
+	o.mu.RLock()
+	revokeURL := o.googleEndpoints.RevocationURL()
+	o.mu.RUnlock()
+
+	if err := postRevocation(ctx, revokeURL, url.Values{"token": {token}}); err != nil {
+		return fmt.Errorf("failed to revoke Google token: %w", err)
+	}
+	return nil
+}
+
+// postRevocation posts form to a provider revocation endpoint. It uses the HTTP client in ctx
+// under oauth2.HTTPClient when there is one, like the token exchanges do.
+func postRevocation(ctx context.Context, endpoint string, form url.Values) error {
+	ctx, cancel := context.WithTimeout(ctx, revokeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		client = c
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
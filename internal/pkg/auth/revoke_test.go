@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestOAuthService_Revocation(t *testing.T) {
+	var paths, tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		paths = append(paths, r.URL.Path)
+		tokens = append(tokens, r.PostForm.Get("access_token")+r.PostForm.Get("token"))
+		if r.PostForm.Get("token") == "already-revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_token"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewOAuthService("google-id", "google-secret", "", "strava-id", "strava-secret", "")
+	service.SetEndpoints(
+		google.Endpoints{RevokeURL: server.URL + "/revoke"},
+		strava.Endpoints{OAuthBaseURL: server.URL + "/oauth"},
+	)
+
+	if err := service.DeauthorizeStrava(context.Background(), "strava-access"); err != nil {
+		t.Fatalf("DeauthorizeStrava failed: %v", err)
+	}
+	if err := service.RevokeGoogleToken(context.Background(), "google-refresh"); err != nil {
+		t.Fatalf("RevokeGoogleToken failed: %v", err)
+	}
+	if err := service.RevokeGoogleToken(context.Background(), "already-revoked"); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("Expected the provider error to be reported, got %v", err)
+	}
+
+	if len(paths) != 3 || paths[0] != "/oauth/deauthorize" || paths[1] != "/revoke" {
+		t.Errorf("Unexpected revocation requests: %v", paths)
+	}
+	if tokens[0] != "strava-access" || tokens[1] != "google-refresh" {
+		t.Errorf("Unexpected revoked tokens: %v", tokens)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SessionRevokeTokenPrefix starts the token in a new sign-in email's revoke link
+const SessionRevokeTokenPrefix = "rvk_"
+
+// userAgentBrowsers and userAgentSystems map user agent markers to names, most specific first:
+// Edge and Opera also announce Chrome, and Chrome also announces Safari
+var (
+	userAgentBrowsers = []struct{ marker, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	userAgentSystems = []struct{ marker, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Macintosh", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// DescribeDevice names the browser and operating system of a user agent, e.g. "Firefox on
+// Windows". Versions are left out so that browser updates do not look like a new device.
+func DescribeDevice(userAgent string) string {
+	browser, system := "Unknown browser", "unknown system"
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.marker) {
+			system = s.name
+			break
+		}
+	}
+	return browser + " on " + system
+}
+
+// NetworkOf returns the network a client address belongs to: its IPv4 /24 or IPv6 /48, which
+// stay the same as a home or office connection's address changes. Unparseable addresses are
+// returned unchanged.
+func NetworkOf(ipAddress string) string {
+	ip := net.ParseIP(strings.TrimSpace(ipAddress))
+	if ip == nil {
+		return ipAddress
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// NewSessionRevokeToken generates the token of a new sign-in email's revoke link and the hash
+// stored for it
+func NewSessionRevokeToken() (token, hash string, err error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate revoke token: %w", err)
+	}
+
+	token = SessionRevokeTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	return token, HashSessionRevokeToken(token), nil
+}
+
+// HashSessionRevokeToken returns the hex SHA-256 hash stored for a revoke token
+func HashSessionRevokeToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribeDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0", "Firefox on Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36", "Chrome on macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"curl/8.5.0", "Unknown browser on unknown system"},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeDevice(tt.userAgent); got != tt.expected {
+			t.Errorf("DescribeDevice(%q) = %q, want %q", tt.userAgent, got, tt.expected)
+		}
+	}
+}
+
+func TestNetworkOf(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.42":          "203.0.113.0/24",
+		"2001:db8:1234:5678::1": "2001:db8:1234::/48",
+		"::ffff:198.51.100.7":   "198.51.100.0/24",
+		"not-an-ip":             "not-an-ip",
+	}
+
+	for ip, expected := range tests {
+		if got := NetworkOf(ip); got != expected {
+			t.Errorf("NetworkOf(%q) = %q, want %q", ip, got, expected)
+		}
+	}
+}
+
+func TestNewSessionRevokeToken(t *testing.T) {
+	token, hash, err := NewSessionRevokeToken()
+	if err != nil {
+		t.Fatalf("NewSessionRevokeToken() failed: %v", err)
+	}
+	if !strings.HasPrefix(token, SessionRevokeTokenPrefix) || len(token) < 40 {
+		t.Errorf("Expected a prefixed token with 256 bits, got %q", token)
+	}
+	if hash != HashSessionRevokeToken(token) || len(hash) != 64 {
+		t.Errorf("Expected the hex SHA-256 of the token, got %q", hash)
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UndoTokenPrefix starts the token confirming the undo of a sync run
+const UndoTokenPrefix = "undo_"
+
+// NewUndoToken generates the token a user sends back to confirm undoing a sync run and the hash
+// stored for it
+func NewUndoToken() (token, hash string, err error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate undo token: %w", err)
+	}
+
+	token = UndoTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	return token, HashUndoToken(token), nil
+}
+
+// HashUndoToken returns the hex SHA-256 hash stored for an undo confirmation token
+func HashUndoToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
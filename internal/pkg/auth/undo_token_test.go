@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewUndoToken(t *testing.T) {
+	token, hash, err := NewUndoToken()
+	if err != nil {
+		t.Fatalf("NewUndoToken() failed: %v", err)
+	}
+	if !strings.HasPrefix(token, UndoTokenPrefix) || len(token) < 40 {
+		t.Errorf("Expected a prefixed token with 256 bits, got %q", token)
+	}
+	if hash != HashUndoToken(token) || len(hash) != 64 {
+		t.Errorf("Expected the hex SHA-256 of the token, got %q", hash)
+	}
+
+	other, _, err := NewUndoToken()
+	if err != nil || other == token {
+		t.Errorf("Expected a fresh token on every call, got %q twice", token)
+	}
+}
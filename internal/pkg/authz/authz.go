@@ -0,0 +1,250 @@
+// Package authz decides whether a subject may perform an action on a resource.
+// Handlers describe what is being accessed and who owns it; the policy decides,
+// so permission logic stays out of handlers as coach and admin roles are added.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Role is a class of subjects with shared permissions
+type Role string
+
+const (
+	// RoleAthlete is an individual user managing their own data
+	RoleAthlete Role = "athlete"
+	// RoleCoach may access the data of athletes they coach
+	RoleCoach Role = "coach"
+	// RoleAdmin may access every user's data
+	RoleAdmin Role = "admin"
+	// RoleSupport may read every user's data to investigate problems, but not change it
+	RoleSupport Role = "support"
+)
+
+// Scope limits what a subject authenticated with an API token may do
+type Scope string
+
+const (
+	// ScopeRead allows reading the user's stats, activities and sync jobs
+	ScopeRead Scope = "read"
+	// ScopeSync allows triggering syncs and backfills
+	ScopeSync Scope = "sync"
+	// ScopeExport allows downloading activity exports
+	ScopeExport Scope = "export"
+)
+
+// Scopes lists every scope an API token may be granted
+var Scopes = []Scope{ScopeRead, ScopeSync, ScopeExport}
+
+// Subject is the authenticated principal performing an action
+type Subject struct {
+	UserID int
+	Roles  []Role
+	// Scopes is nil for browser sessions, which are unrestricted; API token subjects may only
+	// perform the actions their scopes grant
+	Scopes []Scope
+}
+
+// User returns the subject for an authenticated user
+func User(userID int, roles ...Role) Subject {
+	return Subject{UserID: userID, Roles: roles}
+}
+
+// HasRole reports whether the subject holds role
+func (s Subject) HasRole(role Role) bool {
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// WithScopes returns the subject restricted to scopes
+func (s Subject) WithScopes(scopes ...Scope) Subject {
+	s.Scopes = append([]Scope{}, scopes...)
+	return s
+}
+
+// Scoped reports whether the subject authenticated with an API token
+func (s Subject) Scoped() bool {
+	return s.Scopes != nil
+}
+
+// HasScope reports whether the subject was granted scope
+func (s Subject) HasScope(scope Scope) bool {
+	for _, sc := range s.Scopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Action is an operation on a resource
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionSync   Action = "sync"
+	ActionExport Action = "export"
+)
+
+// ResourceType identifies the kind of resource being accessed
+type ResourceType string
+
+const (
+	ResourceConfig     ResourceType = "config"
+	ResourceActivities ResourceType = "activities"
+	ResourceStats      ResourceType = "stats"
+	ResourceSyncJob    ResourceType = "sync_job"
+	ResourceRuns       ResourceType = "runs"
+
+	ResourceNotificationTemplates ResourceType = "notification_templates"
+	ResourceEmailSuppressions     ResourceType = "email_suppressions"
+	ResourceServiceConfig         ResourceType = "service_config"
+	ResourceBlackoutWindows       ResourceType = "blackout_windows"
+	ResourceAPITokens             ResourceType = "api_tokens"
+	ResourceUserRoles             ResourceType = "user_roles"
+	ResourceUserSuspension        ResourceType = "user_suspension"
+	ResourceUserAccount           ResourceType = "user_account"
+	ResourceTeam                  ResourceType = "team"
+	ResourceTeamInvitations       ResourceType = "team_invitations"
+	ResourceInviteCodes           ResourceType = "invite_codes"
+	ResourceMaintenance           ResourceType = "maintenance"
+)
+
+// Resource is the target of an action, identified by its type, owner and optional ID
+type Resource struct {
+	Type    ResourceType
+	OwnerID int
+	ID      string
+}
+
+// Config is a user's sync configuration (spreadsheet, template)
+func Config(ownerID int) Resource {
+	return Resource{Type: ResourceConfig, OwnerID: ownerID}
+}
+
+// Activities is a user's synced activity data
+func Activities(ownerID int) Resource {
+	return Resource{Type: ResourceActivities, OwnerID: ownerID}
+}
+
+// Stats is a user's dashboard stats
+func Stats(ownerID int) Resource {
+	return Resource{Type: ResourceStats, OwnerID: ownerID}
+}
+
+// SyncJob is a queued or finished sync job identified by its trace ID
+func SyncJob(ownerID int, traceID string) Resource {
+	return Resource{Type: ResourceSyncJob, OwnerID: ownerID, ID: traceID}
+}
+
+// Runs is a user's automation run history
+func Runs(ownerID int) Resource {
+	return Resource{Type: ResourceRuns, OwnerID: ownerID}
+}
+
+// NotificationTemplates are the email and chat notification templates
+// They belong to no user, so only admins may access them
+func NotificationTemplates() Resource {
+	return Resource{Type: ResourceNotificationTemplates}
+}
+
+// EmailSuppressions is the list of addresses excluded from email after hard bounces
+// Like the templates it belongs to no user, so only admins may access it
+func EmailSuppressions() Resource {
+	return Resource{Type: ResourceEmailSuppressions}
+}
+
+// ServiceConfig is the running service's configuration, such as its secrets
+// It belongs to no user, so only admins may reload it
+func ServiceConfig() Resource {
+	return Resource{Type: ResourceServiceConfig}
+}
+
+// BlackoutWindows are the global periods during which syncing is paused
+// They belong to no user, so only admins may manage them
+func BlackoutWindows() Resource {
+	return Resource{Type: ResourceBlackoutWindows}
+}
+
+// APITokens are a user's personal access tokens
+func APITokens(ownerID int) Resource {
+	return Resource{Type: ResourceAPITokens, OwnerID: ownerID}
+}
+
+// UserRoles is a user's account role and its change history
+// It has no owner, so users cannot change their own role and only admins may grant roles
+func UserRoles(userID int) Resource {
+	return Resource{Type: ResourceUserRoles, ID: strconv.Itoa(userID)}
+}
+
+// UserSuspension is whether a user's account is suspended
+// It has no owner, so users cannot lift their own suspension and only admins may suspend accounts
+func UserSuspension(userID int) Resource {
+	return Resource{Type: ResourceUserSuspension, ID: strconv.Itoa(userID)}
+}
+
+// UserAccount is a user's account as a whole, such as when merging a duplicate into it
+// It has no owner, so only admins may merge accounts
+func UserAccount(userID int) Resource {
+	return Resource{Type: ResourceUserAccount, ID: strconv.Itoa(userID)}
+}
+
+// Team is a coach's team and its roster; teamID is 0 for a team about to be created
+func Team(coachID, teamID int) Resource {
+	resource := Resource{Type: ResourceTeam, OwnerID: coachID}
+	if teamID > 0 {
+		resource.ID = strconv.Itoa(teamID)
+	}
+	return resource
+}
+
+// TeamInvitations are a user's invitations to and memberships of coaches' teams
+func TeamInvitations(ownerID int) Resource {
+	return Resource{Type: ResourceTeamInvitations, OwnerID: ownerID}
+}
+
+// InviteCodes are the single-use codes that let people sign up under invite-only signup
+// They belong to no user, so only admins may create or revoke them
+func InviteCodes() Resource {
+	return Resource{Type: ResourceInviteCodes}
+}
+
+// Maintenance is the global switch that takes the service down for operator work
+// It belongs to no user, so only admins may turn it on or off
+func Maintenance() Resource {
+	return Resource{Type: ResourceMaintenance}
+}
+
+// ErrForbidden is matched by every authorization denial
+var ErrForbidden = errors.New("forbidden")
+
+// DeniedError describes a denied authorization check
+type DeniedError struct {
+	Subject  Subject
+	Action   Action
+	Resource Resource
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("user %d may not %s %s owned by user %d", e.Subject.UserID, e.Action, e.Resource.Type, e.Resource.OwnerID)
+}
+
+// Unwrap allows errors.Is(err, ErrForbidden)
+func (e *DeniedError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Authorizer checks whether a subject may perform an action on a resource
+// It returns nil when allowed and an error matching ErrForbidden otherwise
+type Authorizer interface {
+	Authorize(ctx context.Context, subject Subject, action Action, resource Resource) error
+}
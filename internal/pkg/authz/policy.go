@@ -0,0 +1,146 @@
+package authz
+
+import "context"
+
+// Effect is a rule's verdict on a request
+type Effect int
+
+const (
+	// Abstain leaves the decision to other rules
+	Abstain Effect = iota
+	// Allow grants the request unless another rule denies it
+	Allow
+	// Deny refuses the request regardless of other rules
+	Deny
+)
+
+// Rule evaluates a single aspect of the policy
+type Rule func(ctx context.Context, subject Subject, action Action, resource Resource) Effect
+
+// Policy combines rules: any Deny refuses the request, otherwise at least one Allow is required
+type Policy struct {
+	rules []Rule
+}
+
+// NewPolicy creates a policy from rules
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// DefaultPolicy lets users act on their own resources, admins act on any resource and support
+// staff read any resource, within the scopes of the API token they authenticated with. Only
+// coaches own teams; add CoachRule to let them read their athletes' data.
+func DefaultPolicy() *Policy {
+	return NewPolicy(OwnerRule, AdminRule, SupportRule, ScopeRule, TeamRule)
+}
+
+// With returns a copy of the policy with additional rules
+func (p *Policy) With(rules ...Rule) *Policy {
+	combined := make([]Rule, 0, len(p.rules)+len(rules))
+	combined = append(combined, p.rules...)
+	combined = append(combined, rules...)
+	return &Policy{rules: combined}
+}
+
+// Authorize implements Authorizer
+func (p *Policy) Authorize(ctx context.Context, subject Subject, action Action, resource Resource) error {
+	allowed := false
+	for _, rule := range p.rules {
+		switch rule(ctx, subject, action, resource) {
+		case Deny:
+			return &DeniedError{Subject: subject, Action: action, Resource: resource}
+		case Allow:
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return &DeniedError{Subject: subject, Action: action, Resource: resource}
+	}
+	return nil
+}
+
+// OwnerRule allows authenticated users every action on resources they own
+func OwnerRule(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+	if subject.UserID > 0 && subject.UserID == resource.OwnerID {
+		return Allow
+	}
+	return Abstain
+}
+
+// AdminRule allows admins every action on every resource
+func AdminRule(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+	if subject.HasRole(RoleAdmin) {
+		return Allow
+	}
+	return Abstain
+}
+
+// SupportRule allows support staff to read every resource
+func SupportRule(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+	if subject.HasRole(RoleSupport) && action == ActionRead {
+		return Allow
+	}
+	return Abstain
+}
+
+// scopeActions maps the actions API tokens may perform to the scope that grants them
+var scopeActions = map[Action]Scope{
+	ActionRead:   ScopeRead,
+	ActionSync:   ScopeSync,
+	ActionExport: ScopeExport,
+}
+
+// ScopeRule denies API token subjects the actions their scopes do not grant. Tokens never update
+// or delete anything, and may not manage API tokens, so a leaked token cannot mint new ones.
+func ScopeRule(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+	if !subject.Scoped() {
+		return Abstain
+	}
+	if resource.Type == ResourceAPITokens {
+		return Deny
+	}
+	if scope, ok := scopeActions[action]; ok && subject.HasScope(scope) {
+		return Abstain
+	}
+	return Deny
+}
+
+// TeamRule denies team ownership to users who are not coaches, so an athlete cannot create a team
+// and a coach whose role is revoked loses access to their teams
+func TeamRule(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+	if resource.Type != ResourceTeam || subject.UserID != resource.OwnerID {
+		return Abstain
+	}
+	if subject.HasRole(RoleCoach) || subject.HasRole(RoleAdmin) {
+		return Abstain
+	}
+	return Deny
+}
+
+// Roster reports whether a coach coaches an athlete
+type Roster interface {
+	CoachesAthlete(ctx context.Context, coachID, athleteID int) (bool, error)
+}
+
+// coachReadable are the resources coaches may read for the athletes on their teams
+var coachReadable = map[ResourceType]bool{
+	ResourceRuns:    true,
+	ResourceStats:   true,
+	ResourceSyncJob: true,
+}
+
+// CoachRule allows coaches to read the run history, stats and sync jobs of athletes who accepted
+// an invitation to one of their teams. A roster lookup that fails grants nothing.
+func CoachRule(roster Roster) Rule {
+	return func(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+		if action != ActionRead || !subject.HasRole(RoleCoach) || !coachReadable[resource.Type] || resource.OwnerID <= 0 {
+			return Abstain
+		}
+		coaches, err := roster.CoachesAthlete(ctx, subject.UserID, resource.OwnerID)
+		if err != nil || !coaches {
+			return Abstain
+		}
+		return Allow
+	}
+}
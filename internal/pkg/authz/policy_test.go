@@ -0,0 +1,142 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		resource Resource
+		allowed  bool
+	}{
+		{"owner reads own stats", User(1), ActionRead, Stats(1), true},
+		{"owner syncs own job", User(1), ActionRead, SyncJob(1, "trace"), true},
+		{"other user reads job", User(2), ActionRead, SyncJob(1, "trace"), false},
+		{"coach without relation", User(2, RoleCoach), ActionRead, Activities(1), false},
+		{"admin reads any job", User(3, RoleAdmin), ActionRead, SyncJob(1, "trace"), true},
+		{"support reads any job", User(4, RoleSupport), ActionRead, SyncJob(1, "trace"), true},
+		{"support updates config", User(4, RoleSupport), ActionUpdate, Config(1), false},
+		{"user grants own role", User(1), ActionUpdate, UserRoles(1), false},
+		{"admin grants role", User(3, RoleAdmin), ActionUpdate, UserRoles(1), true},
+		{"user lifts own suspension", User(1), ActionDelete, UserSuspension(1), false},
+		{"support suspends user", User(4, RoleSupport), ActionUpdate, UserSuspension(1), false},
+		{"admin suspends user", User(3, RoleAdmin), ActionUpdate, UserSuspension(1), true},
+		{"user merges own accounts", User(1), ActionUpdate, UserAccount(1), false},
+		{"admin merges accounts", User(3, RoleAdmin), ActionUpdate, UserAccount(1), true},
+		{"coach creates team", User(2, RoleCoach), ActionUpdate, Team(2, 0), true},
+		{"athlete creates team", User(1), ActionUpdate, Team(1, 0), false},
+		{"coach reads other coach's team", User(2, RoleCoach), ActionRead, Team(5, 7), false},
+		{"anonymous subject", Subject{}, ActionRead, Resource{Type: ResourceStats}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(ctx, tt.subject, tt.action, tt.resource)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected access, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_DenyOverridesAllow(t *testing.T) {
+	noExports := func(ctx context.Context, subject Subject, action Action, resource Resource) Effect {
+		if action == ActionExport {
+			return Deny
+		}
+		return Abstain
+	}
+	policy := DefaultPolicy().With(noExports)
+
+	if err := policy.Authorize(context.Background(), User(1), ActionExport, Activities(1)); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected the deny rule to win, got %v", err)
+	}
+	if err := policy.Authorize(context.Background(), User(1), ActionRead, Activities(1)); err != nil {
+		t.Errorf("Expected reads to remain allowed, got %v", err)
+	}
+}
+
+func TestScopeRule(t *testing.T) {
+	policy := DefaultPolicy()
+	ctx := context.Background()
+	syncToken := User(1, RoleAthlete).WithScopes(ScopeSync, ScopeRead)
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		resource Resource
+		allowed  bool
+	}{
+		{"token syncs own activities", syncToken, ActionSync, Activities(1), true},
+		{"token reads own job", syncToken, ActionRead, SyncJob(1, "trace"), true},
+		{"token exports without scope", syncToken, ActionExport, Activities(1), false},
+		{"token updates config", syncToken, ActionUpdate, Config(1), false},
+		{"token syncs another user", syncToken, ActionSync, Activities(2), false},
+		{"token lists tokens", syncToken, ActionRead, APITokens(1), false},
+		{"token without scopes", User(1).WithScopes(), ActionRead, Stats(1), false},
+		{"admin token keeps admin scope limits", User(3, RoleAdmin).WithScopes(ScopeRead), ActionUpdate, BlackoutWindows(), false},
+		{"session manages tokens", User(1), ActionUpdate, APITokens(1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(ctx, tt.subject, tt.action, tt.resource)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected access, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
+
+type fakeRoster map[[2]int]bool
+
+func (r fakeRoster) CoachesAthlete(ctx context.Context, coachID, athleteID int) (bool, error) {
+	return r[[2]int{coachID, athleteID}], nil
+}
+
+func TestCoachRule(t *testing.T) {
+	policy := DefaultPolicy().With(CoachRule(fakeRoster{{2, 1}: true}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		resource Resource
+		allowed  bool
+	}{
+		{"coach reads athlete runs", User(2, RoleCoach), ActionRead, Runs(1), true},
+		{"coach reads athlete stats", User(2, RoleCoach), ActionRead, Stats(1), true},
+		{"coach syncs for athlete", User(2, RoleCoach), ActionSync, SyncJob(1, ""), false},
+		{"coach reads athlete config", User(2, RoleCoach), ActionRead, Config(1), false},
+		{"coach reads other athlete", User(2, RoleCoach), ActionRead, Runs(3), false},
+		{"former coach reads athlete", User(2), ActionRead, Runs(1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(ctx, tt.subject, tt.action, tt.resource)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected access, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
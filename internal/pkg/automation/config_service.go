@@ -21,6 +21,8 @@ type UserRepository interface {
 // operational configurations from the database for a specific user's processing run.
 type ConfigService struct {
 	userRepository UserRepository
+	spreadsheets   SpreadsheetAccessChecker // Optional; see SetSpreadsheetAccessChecker
+	teamSheets     TeamSheetSource          // Optional; see SetTeamSheetSource
 	logger         *logger.Logger
 }
 
@@ -75,10 +77,10 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 
 	s.logger.Debug("Successfully retrieved decrypted processing tokens",
 		"user_id", userID,
-		"has_google_access_token", tokens.GoogleAccessToken != "",
-		"has_google_refresh_token", tokens.GoogleRefreshToken != "",
-		"has_strava_access_token", tokens.StravaAccessToken != "",
-		"has_strava_refresh_token", tokens.StravaRefreshToken != "",
+		"has_google_access_token", !tokens.GoogleAccessToken.Empty(),
+		"has_google_refresh_token", !tokens.GoogleRefreshToken.Empty(),
+		"has_strava_access_token", !tokens.StravaAccessToken.Empty(),
+		"has_strava_refresh_token", !tokens.StravaRefreshToken.Empty(),
 		"has_strava_athlete_id", tokens.StravaAthleteID != nil,
 		"has_spreadsheet_id", tokens.SpreadsheetID != nil,
 		"google_token_expiry", tokens.GoogleTokenExpiry,
@@ -107,6 +109,15 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 		// User preferences
 		EmailNotificationsEnabled: user.EmailNotificationsEnabled,
 		AutomationEnabled:         user.AutomationEnabled,
+		WeeklySummaryEnabled:      tokens.WeeklySummaryEnabled,
+		Suspended:                 user.Suspended(),
+		SheetTemplate:             tokens.SheetTemplate,
+		SortChronologically:       tokens.SortChronologically,
+		GearColumn:                tokens.GearColumn,
+
+		// Outbound webhook (optional)
+		WebhookURL:    tokens.WebhookURL,
+		WebhookSecret: tokens.WebhookSecret,
 	}
 
 	// Handle spreadsheet ID (can be nil)
@@ -114,6 +125,17 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 		config.SpreadsheetID = *tokens.SpreadsheetID
 	}
 
+	// Handle pending destination migration (all optional)
+	if tokens.PendingDestinationType != nil {
+		config.PendingDestinationType = *tokens.PendingDestinationType
+	}
+	if tokens.PendingDestinationID != nil {
+		config.PendingDestinationID = *tokens.PendingDestinationID
+	}
+	config.DualWriteUntil = tokens.DualWriteUntil
+
+	s.loadTeamSheets(ctx, config)
+
 	s.logger.Debug("Built processing configuration from user data",
 		"user_id", userID,
 		"config_summary", config.String())
@@ -132,8 +154,8 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 				"error_message":  err.Error(),
 				"config_summary": config.String(),
 				"validation_checklist": map[string]interface{}{
-					"has_google_refresh":   config.GoogleRefreshToken != "",
-					"has_strava_refresh":   config.StravaRefreshToken != "",
+					"has_google_refresh":   !config.GoogleRefreshToken.Empty(),
+					"has_strava_refresh":   !config.StravaRefreshToken.Empty(),
 					"has_athlete_id":       config.StravaAthleteID != nil,
 					"has_spreadsheet_id":   config.SpreadsheetID != "",
 					"has_timezone":         config.Timezone != "",
@@ -162,8 +184,8 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 		"user_id", userID,
 		"token_analysis", map[string]interface{}{
 			"google": map[string]interface{}{
-				"has_access_token":    config.GoogleAccessToken != "",
-				"has_refresh_token":   config.GoogleRefreshToken != "",
+				"has_access_token":    !config.GoogleAccessToken.Empty(),
+				"has_refresh_token":   !config.GoogleRefreshToken.Empty(),
 				"token_valid":         config.HasValidGoogleToken(),
 				"token_expiry":        config.GoogleTokenExpiry,
 				"minutes_until_expiry": func() float64 {
@@ -174,8 +196,8 @@ func (s *ConfigService) GetProcessingConfigForUser(ctx context.Context, userID i
 				}(),
 			},
 			"strava": map[string]interface{}{
-				"has_access_token":     config.StravaAccessToken != "",
-				"has_refresh_token":    config.StravaRefreshToken != "",
+				"has_access_token":     !config.StravaAccessToken.Empty(),
+				"has_refresh_token":    !config.StravaRefreshToken.Empty(),
 				"token_valid":          config.HasValidStravaToken(),
 				"token_expiry":         config.StravaTokenExpiry,
 				"athlete_id":           config.StravaAthleteID,
@@ -224,7 +246,25 @@ func (s *ConfigService) ValidateUserCanBeProcessed(ctx context.Context, userID i
 		return fmt.Errorf("user not found: %d", userID)
 	}
 
-	// Check essential fields without decryption
+	missingFields := missingProcessingFields(user)
+
+	if len(missingFields) > 0 {
+		s.logger.Warn("User missing essential configuration for processing",
+			"user_id", userID,
+			"missing_fields", missingFields)
+		return fmt.Errorf("user missing essential configuration: %v", missingFields)
+	}
+
+	s.logger.Debug("User passed quick processing validation",
+		"user_id", userID,
+		"automation_enabled", user.AutomationEnabled)
+
+	return nil
+}
+
+// missingProcessingFields lists the fields a user is missing for processing, checked without
+// decrypting any token
+func missingProcessingFields(user *database.User) []string {
 	var missingFields []string
 
 	if len(user.GoogleRefreshToken) == 0 {
@@ -259,16 +299,5 @@ func (s *ConfigService) ValidateUserCanBeProcessed(ctx context.Context, userID i
 		}
 	}
 
-	if len(missingFields) > 0 {
-		s.logger.Warn("User missing essential configuration for processing",
-			"user_id", userID,
-			"missing_fields", missingFields)
-		return fmt.Errorf("user missing essential configuration: %v", missingFields)
-	}
-
-	s.logger.Debug("User passed quick processing validation",
-		"user_id", userID,
-		"automation_enabled", user.AutomationEnabled)
-
-	return nil
-}
\ No newline at end of file
+	return missingFields
+}
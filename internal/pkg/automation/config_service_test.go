@@ -7,6 +7,7 @@ import (
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
 )
 
 // MockUserRepository implements a mock user repository for testing
@@ -81,11 +82,11 @@ func TestConfigService_GetProcessingConfigForUser(t *testing.T) {
 		}
 
 		tokens := &database.ProcessingTokens{
-			GoogleAccessToken:  "google-access-token",
-			GoogleRefreshToken: "google-refresh-token",
+			GoogleAccessToken:  secure.NewTokenString("google-access-token"),
+			GoogleRefreshToken: secure.NewTokenString("google-refresh-token"),
 			GoogleTokenExpiry:  &futureExpiry,
-			StravaAccessToken:  "strava-access-token",
-			StravaRefreshToken: "strava-refresh-token",
+			StravaAccessToken:  secure.NewTokenString("strava-access-token"),
+			StravaRefreshToken: secure.NewTokenString("strava-refresh-token"),
 			StravaTokenExpiry:  &futureExpiry,
 			StravaAthleteID:    &athleteID,
 			SpreadsheetID:      &spreadsheetID,
@@ -111,11 +112,11 @@ func TestConfigService_GetProcessingConfigForUser(t *testing.T) {
 		if config.Email != "test@example.com" {
 			t.Errorf("Expected email test@example.com, got %s", config.Email)
 		}
-		if config.GoogleRefreshToken != "google-refresh-token" {
-			t.Errorf("Expected Google refresh token, got %s", config.GoogleRefreshToken)
+		if config.GoogleRefreshToken.Reveal() != "google-refresh-token" {
+			t.Errorf("Expected Google refresh token, got %s", config.GoogleRefreshToken.Reveal())
 		}
-		if config.StravaRefreshToken != "strava-refresh-token" {
-			t.Errorf("Expected Strava refresh token, got %s", config.StravaRefreshToken)
+		if config.StravaRefreshToken.Reveal() != "strava-refresh-token" {
+			t.Errorf("Expected Strava refresh token, got %s", config.StravaRefreshToken.Reveal())
 		}
 		if config.SpreadsheetID != "test-spreadsheet-id" {
 			t.Errorf("Expected spreadsheet ID, got %s", config.SpreadsheetID)
@@ -141,11 +142,11 @@ func TestConfigService_GetProcessingConfigForUser(t *testing.T) {
 		}
 
 		tokens := &database.ProcessingTokens{
-			GoogleAccessToken:  "google-access-token",
-			GoogleRefreshToken: "google-refresh-token",
+			GoogleAccessToken:  secure.NewTokenString("google-access-token"),
+			GoogleRefreshToken: secure.NewTokenString("google-refresh-token"),
 			GoogleTokenExpiry:  &futureExpiry,
-			StravaAccessToken:  "", // Empty
-			StravaRefreshToken: "", // Empty
+			StravaAccessToken:  nil, // Empty
+			StravaRefreshToken: nil, // Empty
 			StravaTokenExpiry:  nil,
 			StravaAthleteID:    nil, // Missing
 			SpreadsheetID:      &spreadsheetID,
@@ -176,9 +177,9 @@ func TestProcessingConfig_Validate(t *testing.T) {
 		config := &ProcessingConfig{
 			UserID:             1,
 			Email:              "test@example.com",
-			GoogleRefreshToken: "google-refresh-token",
+			GoogleRefreshToken: secure.NewTokenString("google-refresh-token"),
 			GoogleTokenExpiry:  &futureExpiry,
-			StravaRefreshToken: "strava-refresh-token",
+			StravaRefreshToken: secure.NewTokenString("strava-refresh-token"),
 			StravaTokenExpiry:  &futureExpiry,
 			StravaAthleteID:    &athleteID,
 			SpreadsheetID:      "test-spreadsheet-id",
@@ -196,8 +197,8 @@ func TestProcessingConfig_Validate(t *testing.T) {
 		config := &ProcessingConfig{
 			UserID:             1,
 			Email:              "test@example.com",
-			GoogleRefreshToken: "", // Missing
-			StravaRefreshToken: "strava-refresh-token",
+			GoogleRefreshToken: nil, // Missing
+			StravaRefreshToken: secure.NewTokenString("strava-refresh-token"),
 			StravaAthleteID:    &athleteID,
 			SpreadsheetID:      "test-spreadsheet-id",
 			Timezone:           "America/New_York",
@@ -221,8 +222,8 @@ func TestProcessingConfig_Validate(t *testing.T) {
 		config := &ProcessingConfig{
 			UserID:             1,
 			Email:              "test@example.com",
-			GoogleRefreshToken: "google-refresh-token",
-			StravaRefreshToken: "strava-refresh-token",
+			GoogleRefreshToken: secure.NewTokenString("google-refresh-token"),
+			StravaRefreshToken: secure.NewTokenString("strava-refresh-token"),
 			StravaAthleteID:    &athleteID,
 			SpreadsheetID:      "test-spreadsheet-id",
 			Timezone:           "Invalid/Timezone",
@@ -249,7 +250,7 @@ func TestProcessingConfig_TokenValidation(t *testing.T) {
 	// Test case 1: Valid Google token
 	t.Run("ValidGoogleToken", func(t *testing.T) {
 		config := &ProcessingConfig{
-			GoogleAccessToken: "valid-token",
+			GoogleAccessToken: secure.NewTokenString("valid-token"),
 			GoogleTokenExpiry: &futureExpiry,
 		}
 
@@ -261,7 +262,7 @@ func TestProcessingConfig_TokenValidation(t *testing.T) {
 	// Test case 2: Expired Google token
 	t.Run("ExpiredGoogleToken", func(t *testing.T) {
 		config := &ProcessingConfig{
-			GoogleAccessToken: "expired-token",
+			GoogleAccessToken: secure.NewTokenString("expired-token"),
 			GoogleTokenExpiry: &pastExpiry,
 		}
 
@@ -273,7 +274,7 @@ func TestProcessingConfig_TokenValidation(t *testing.T) {
 	// Test case 3: Soon-to-expire Google token (within buffer)
 	t.Run("SoonExpiredGoogleToken", func(t *testing.T) {
 		config := &ProcessingConfig{
-			GoogleAccessToken: "soon-expired-token",
+			GoogleAccessToken: secure.NewTokenString("soon-expired-token"),
 			GoogleTokenExpiry: &soonExpiry,
 		}
 
@@ -285,7 +286,7 @@ func TestProcessingConfig_TokenValidation(t *testing.T) {
 	// Test case 4: Missing Google token
 	t.Run("MissingGoogleToken", func(t *testing.T) {
 		config := &ProcessingConfig{
-			GoogleAccessToken: "",
+			GoogleAccessToken: nil,
 			GoogleTokenExpiry: &futureExpiry,
 		}
 
@@ -293,4 +294,22 @@ func TestProcessingConfig_TokenValidation(t *testing.T) {
 			t.Error("Expected invalid Google token for missing token")
 		}
 	})
-}
\ No newline at end of file
+}
+func TestProcessingConfig_ZeroTokens(t *testing.T) {
+	config := &ProcessingConfig{
+		GoogleAccessToken:  secure.NewTokenString("google-access-token"),
+		GoogleRefreshToken: secure.NewTokenString("google-refresh-token"),
+		StravaRefreshToken: secure.NewTokenString("strava-refresh-token"),
+	}
+
+	config.ZeroTokens()
+
+	for _, token := range config.Tokens() {
+		if !token.Empty() {
+			t.Errorf("Expected every token to be wiped, got %q", token.Reveal())
+		}
+	}
+	if !config.GoogleRefreshToken.Zeroed() || config.StravaAccessToken != nil {
+		t.Error("Expected stored tokens to be zeroed and missing ones to stay nil")
+	}
+}
@@ -3,6 +3,8 @@ package automation
 import (
 	"fmt"
 	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
 )
 
 // ProcessingConfig contains all configuration required for processing a user's automation job
@@ -13,15 +15,15 @@ type ProcessingConfig struct {
 	UserID int `json:"user_id"`
 	Email  string `json:"email"`
 	
-	// Google OAuth credentials (decrypted)
-	GoogleAccessToken  string     `json:"-"` // Never serialize sensitive tokens
-	GoogleRefreshToken string     `json:"-"` // Never serialize sensitive tokens
-	GoogleTokenExpiry  *time.Time `json:"-"` // Never serialize sensitive tokens
+	// Google OAuth credentials (decrypted into zeroable buffers, see ZeroTokens)
+	GoogleAccessToken  *secure.Token `json:"-"` // Never serialize sensitive tokens
+	GoogleRefreshToken *secure.Token `json:"-"` // Never serialize sensitive tokens
+	GoogleTokenExpiry  *time.Time    `json:"-"` // Never serialize sensitive tokens
 	
-	// Strava OAuth credentials (decrypted)
-	StravaAccessToken  string     `json:"-"` // Never serialize sensitive tokens
-	StravaRefreshToken string     `json:"-"` // Never serialize sensitive tokens
-	StravaTokenExpiry  *time.Time `json:"-"` // Never serialize sensitive tokens
+	// Strava OAuth credentials (decrypted into zeroable buffers, see ZeroTokens)
+	StravaAccessToken  *secure.Token `json:"-"` // Never serialize sensitive tokens
+	StravaRefreshToken *secure.Token `json:"-"` // Never serialize sensitive tokens
+	StravaTokenExpiry  *time.Time    `json:"-"` // Never serialize sensitive tokens
 	StravaAthleteID    *int64     `json:"strava_athlete_id"`
 	
 	// Target configuration
@@ -31,6 +33,32 @@ type ProcessingConfig struct {
 	// User preferences
 	EmailNotificationsEnabled bool `json:"email_notifications_enabled"`
 	AutomationEnabled         bool `json:"automation_enabled"`
+	WeeklySummaryEnabled      bool `json:"weekly_summary_enabled"`
+
+	// Suspended is set while an admin has suspended the account; suspended users are never processed
+	Suspended bool `json:"suspended"`
+	
+	// SheetTemplate selects the column layout of the spreadsheet (empty means the default template)
+	SheetTemplate string `json:"sheet_template"`
+	
+	// SortChronologically re-sorts the activity rows by date when a run appends out of order
+	SortChronologically bool `json:"sort_chronologically"`
+
+	// GearColumn appends a Gear column with the shoes or bike each activity was recorded with
+	GearColumn bool `json:"gear_column"`
+	
+	// WebhookURL receives newly synced activities after each run (empty disables it)
+	WebhookURL    string `json:"webhook_url,omitempty"`
+	WebhookSecret string `json:"-"` // Never serialize the signing secret
+	
+	// Destination migration: while the dual-write window is open the engine
+	// writes to both the current and the pending destination
+	PendingDestinationType string     `json:"pending_destination_type,omitempty"`
+	PendingDestinationID   string     `json:"pending_destination_id,omitempty"`
+	DualWriteUntil         *time.Time `json:"dual_write_until,omitempty"`
+
+	// TeamSheets are coach-owned spreadsheets the activities are also copied to
+	TeamSheets []TeamSheet `json:"team_sheets,omitempty"`
 }
 
 // ValidationError represents a configuration validation failure
@@ -66,7 +94,7 @@ func (c *ProcessingConfig) Validate() error {
 	}
 	
 	// Validate Google OAuth tokens - both are required for Sheets access
-	if c.GoogleRefreshToken == "" {
+	if c.GoogleRefreshToken.Empty() {
 		return &ValidationError{
 			Field:   "google_refresh_token",
 			Message: "is required for Google Sheets API access",
@@ -75,7 +103,7 @@ func (c *ProcessingConfig) Validate() error {
 	
 	// Note: Google access token may be empty (will be refreshed if needed)
 	// but we validate that token expiry is present if access token exists
-	if c.GoogleAccessToken != "" && c.GoogleTokenExpiry == nil {
+	if !c.GoogleAccessToken.Empty() && c.GoogleTokenExpiry == nil {
 		return &ValidationError{
 			Field:   "google_token_expiry",
 			Message: "is required when access token is present",
@@ -91,7 +119,7 @@ func (c *ProcessingConfig) Validate() error {
 	}
 	
 	// Validate Strava OAuth tokens - refresh token is essential
-	if c.StravaRefreshToken == "" {
+	if c.StravaRefreshToken.Empty() {
 		return &ValidationError{
 			Field:   "strava_refresh_token",
 			Message: "is required for Strava API access",
@@ -108,7 +136,7 @@ func (c *ProcessingConfig) Validate() error {
 	
 	// Note: Strava access token may be empty (will be refreshed if needed)
 	// but we validate that token expiry is present if access token exists
-	if c.StravaAccessToken != "" && c.StravaTokenExpiry == nil {
+	if !c.StravaAccessToken.Empty() && c.StravaTokenExpiry == nil {
 		return &ValidationError{
 			Field:   "strava_token_expiry",
 			Message: "is required when access token is present",
@@ -152,7 +180,7 @@ func (c *ProcessingConfig) Validate() error {
 
 // HasValidGoogleToken checks if the Google access token is present and not expired
 func (c *ProcessingConfig) HasValidGoogleToken() bool {
-	if c.GoogleAccessToken == "" || c.GoogleTokenExpiry == nil {
+	if c.GoogleAccessToken.Empty() || c.GoogleTokenExpiry == nil {
 		return false
 	}
 	
@@ -162,7 +190,7 @@ func (c *ProcessingConfig) HasValidGoogleToken() bool {
 
 // HasValidStravaToken checks if the Strava access token is present and not expired
 func (c *ProcessingConfig) HasValidStravaToken() bool {
-	if c.StravaAccessToken == "" || c.StravaTokenExpiry == nil {
+	if c.StravaAccessToken.Empty() || c.StravaTokenExpiry == nil {
 		return false
 	}
 	
@@ -170,6 +198,30 @@ func (c *ProcessingConfig) HasValidStravaToken() bool {
 	return time.Now().Add(5 * time.Minute).Before(*c.StravaTokenExpiry)
 }
 
+// Tokens returns the decrypted OAuth tokens, e.g. to hold them in a secure.TokenCache for the job
+func (c *ProcessingConfig) Tokens() []*secure.Token {
+	tokens := []*secure.Token{c.GoogleAccessToken, c.GoogleRefreshToken, c.StravaAccessToken, c.StravaRefreshToken}
+	for _, sheet := range c.TeamSheets {
+		tokens = append(tokens, sheet.GoogleAccessToken, sheet.GoogleRefreshToken)
+	}
+	return tokens
+}
+
+// ZeroTokens wipes the decrypted OAuth tokens once the job no longer needs them
+func (c *ProcessingConfig) ZeroTokens() {
+	for _, token := range c.Tokens() {
+		token.Zero()
+	}
+}
+
+// IsDualWriteActive reports whether a destination migration validation window is open at the given time
+func (c *ProcessingConfig) IsDualWriteActive(now time.Time) bool {
+	if c.PendingDestinationType == "" || c.PendingDestinationID == "" || c.DualWriteUntil == nil {
+		return false
+	}
+	return now.Before(*c.DualWriteUntil)
+}
+
 // GetLocation returns the parsed timezone location for date/time operations
 func (c *ProcessingConfig) GetLocation() (*time.Location, error) {
 	loc, err := time.LoadLocation(c.Timezone)
@@ -188,5 +240,5 @@ func (c *ProcessingConfig) String() string {
 	return fmt.Sprintf("ProcessingConfig{UserID: %d, Email: %s, SpreadsheetID: %s, Timezone: %s, "+
 		"HasGoogleTokens: %t, HasStravaTokens: %t, StravaAthleteID: %v, AutomationEnabled: %t}",
 		c.UserID, c.Email, c.SpreadsheetID, c.Timezone,
-		c.GoogleRefreshToken != "", c.StravaRefreshToken != "", c.StravaAthleteID, c.AutomationEnabled)
+		!c.GoogleRefreshToken.Empty(), !c.StravaRefreshToken.Empty(), c.StravaAthleteID, c.AutomationEnabled)
 }
\ No newline at end of file
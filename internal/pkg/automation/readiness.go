@@ -0,0 +1,119 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+)
+
+// Automation readiness checks, in the order users complete them during onboarding
+const (
+	CheckStravaConnected       = "strava_connected"
+	CheckGoogleTokenValid      = "google_token_valid"
+	CheckSpreadsheetSet        = "spreadsheet_set"
+	CheckSpreadsheetAccessible = "spreadsheet_accessible"
+	CheckTimezoneSet           = "timezone_set"
+)
+
+// SpreadsheetAccessChecker verifies that a user's Google token can open and write a spreadsheet
+type SpreadsheetAccessChecker interface {
+	ValidateSpreadsheetAccess(ctx context.Context, userID int, spreadsheetID string) error
+}
+
+// ReadinessCheck is one item of the automation prerequisites checklist
+type ReadinessCheck struct {
+	ID     string `json:"id"`
+	Passed bool   `json:"passed"`
+	// Message tells the user what to do when the check did not pass
+	Message string `json:"message,omitempty"`
+}
+
+// Readiness reports whether a user has everything automation needs
+type Readiness struct {
+	// Ready is true when every check passed; automation can then be enabled
+	Ready             bool             `json:"ready"`
+	AutomationEnabled bool             `json:"automation_enabled"`
+	Checks            []ReadinessCheck `json:"checks"`
+}
+
+// SetSpreadsheetAccessChecker makes CheckReadiness verify the Google token and spreadsheet access
+// against Google. Without one, only the stored configuration is checked.
+func (s *ConfigService) SetSpreadsheetAccessChecker(checker SpreadsheetAccessChecker) {
+	s.spreadsheets = checker
+}
+
+// CheckReadiness returns the checklist of automation prerequisites for a user, built from the
+// same fields ValidateUserCanBeProcessed checks, so onboarding can show what is missing
+func (s *ConfigService) CheckReadiness(ctx context.Context, userID int) (*Readiness, error) {
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found: %d", userID)
+	}
+
+	missing := make(map[string]bool)
+	for _, field := range missingProcessingFields(user) {
+		missing[field] = true
+	}
+
+	strava := ReadinessCheck{ID: CheckStravaConnected, Passed: !missing["strava_refresh_token"] && !missing["strava_athlete_id"]}
+	if !strava.Passed {
+		strava.Message = "Connect your Strava account"
+	}
+
+	googleToken := ReadinessCheck{ID: CheckGoogleTokenValid, Passed: !missing["google_refresh_token"]}
+	if !googleToken.Passed {
+		googleToken.Message = "Sign in with Google again to grant Google Sheets access"
+	}
+
+	spreadsheetSet := ReadinessCheck{ID: CheckSpreadsheetSet, Passed: !missing["spreadsheet_id"]}
+	if !spreadsheetSet.Passed {
+		spreadsheetSet.Message = "Choose the Google Spreadsheet to sync activities to"
+	}
+
+	spreadsheetAccessible := ReadinessCheck{ID: CheckSpreadsheetAccessible}
+	switch {
+	case !spreadsheetSet.Passed:
+		spreadsheetAccessible.Message = "Choose a spreadsheet first"
+	case !googleToken.Passed:
+		spreadsheetAccessible.Message = "Grant Google Sheets access first"
+	case s.spreadsheets == nil:
+		spreadsheetAccessible.Passed = true
+	default:
+		err := s.spreadsheets.ValidateSpreadsheetAccess(ctx, userID, *user.SpreadsheetID)
+		switch {
+		case err == nil:
+			spreadsheetAccessible.Passed = true
+		case google.IsReauthRequired(err) || len(user.GoogleAccessToken) == 0:
+			// Google rejected the stored token, so the spreadsheet could not be checked either
+			googleToken.Passed = false
+			googleToken.Message = "Sign in with Google again to grant Google Sheets access"
+			spreadsheetAccessible.Message = "Grant Google Sheets access first"
+		default:
+			s.logger.Warn("Spreadsheet not accessible during readiness check",
+				"user_id", userID,
+				"error", err)
+			spreadsheetAccessible.Message = "Make sure the spreadsheet exists and your Google account can edit it"
+		}
+	}
+
+	timezone := ReadinessCheck{ID: CheckTimezoneSet, Passed: !missing["timezone"] && !missing["valid_timezone"]}
+	if !timezone.Passed {
+		timezone.Message = "Set your timezone"
+	}
+
+	readiness := &Readiness{
+		Ready:             true,
+		AutomationEnabled: user.AutomationEnabled,
+		Checks:            []ReadinessCheck{strava, googleToken, spreadsheetSet, spreadsheetAccessible, timezone},
+	}
+	for _, check := range readiness.Checks {
+		if !check.Passed {
+			readiness.Ready = false
+		}
+	}
+	return readiness, nil
+}
@@ -0,0 +1,118 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSpreadsheetAccessChecker struct {
+	err error
+}
+
+func (m *mockSpreadsheetAccessChecker) ValidateSpreadsheetAccess(ctx context.Context, userID int, spreadsheetID string) error {
+	return m.err
+}
+
+func readyUser() *database.User {
+	spreadsheetID := "sheet-123"
+	athleteID := int64(42)
+	return &database.User{
+		ID:                 1,
+		GoogleAccessToken:  []byte("google-access"),
+		GoogleRefreshToken: []byte("google-refresh"),
+		StravaRefreshToken: []byte("strava-refresh"),
+		StravaAthleteID:    &athleteID,
+		SpreadsheetID:      &spreadsheetID,
+		Timezone:           "Europe/Sofia",
+	}
+}
+
+func failedChecks(readiness *Readiness) []string {
+	var failed []string
+	for _, check := range readiness.Checks {
+		if !check.Passed {
+			failed = append(failed, check.ID)
+		}
+	}
+	return failed
+}
+
+func TestConfigService_CheckReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(user *database.User)
+		accessErr  error
+		wantFailed []string
+	}{
+		{
+			name:   "Ready",
+			modify: func(user *database.User) {},
+		},
+		{
+			name:       "Nothing connected",
+			modify:     func(user *database.User) { *user = database.User{ID: 1} },
+			wantFailed: []string{CheckStravaConnected, CheckGoogleTokenValid, CheckSpreadsheetSet, CheckSpreadsheetAccessible, CheckTimezoneSet},
+		},
+		{
+			name:       "Invalid timezone",
+			modify:     func(user *database.User) { user.Timezone = "Mars/Olympus" },
+			wantFailed: []string{CheckTimezoneSet},
+		},
+		{
+			name:       "Spreadsheet deleted",
+			modify:     func(user *database.User) {},
+			accessErr:  errors.New("NOT_FOUND: Spreadsheet not found"),
+			wantFailed: []string{CheckSpreadsheetAccessible},
+		},
+		{
+			name:       "Google grant revoked",
+			modify:     func(user *database.User) {},
+			accessErr:  errors.New("oauth2: cannot fetch token: invalid_grant"),
+			wantFailed: []string{CheckGoogleTokenValid, CheckSpreadsheetAccessible},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepository()
+			user := readyUser()
+			tt.modify(user)
+			repo.AddUser(1, user)
+
+			service := NewConfigService(repo, logger.New("test"))
+			service.SetSpreadsheetAccessChecker(&mockSpreadsheetAccessChecker{err: tt.accessErr})
+
+			readiness, err := service.CheckReadiness(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			failed := failedChecks(readiness)
+			if len(failed) != len(tt.wantFailed) {
+				t.Fatalf("Expected failed checks %v, got %v", tt.wantFailed, failed)
+			}
+			for i := range failed {
+				if failed[i] != tt.wantFailed[i] {
+					t.Errorf("Expected failed checks %v, got %v", tt.wantFailed, failed)
+				}
+			}
+			if readiness.Ready != (len(tt.wantFailed) == 0) {
+				t.Errorf("Expected ready=%v, got %v", len(tt.wantFailed) == 0, readiness.Ready)
+			}
+			for _, check := range readiness.Checks {
+				if !check.Passed && check.Message == "" {
+					t.Errorf("Check %s failed without a message", check.ID)
+				}
+			}
+		})
+	}
+
+	service := NewConfigService(NewMockUserRepository(), logger.New("test"))
+	if _, err := service.CheckReadiness(context.Background(), 99); err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+}
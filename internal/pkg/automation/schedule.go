@@ -0,0 +1,32 @@
+package automation
+
+import "time"
+
+// ScheduledRunHour is the local hour at which the daily automated run starts; runs are spread over
+// the following two hours
+const ScheduledRunHour = 3
+
+// NextScheduledRun returns when the daily automated run next starts for a user in timezone: the
+// next ScheduledRunHour:00 in their local time after now. Unknown timezones are read as UTC.
+func NextScheduledRun(timezone string, now time.Time) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), ScheduledRunHour, 0, 0, 0, loc)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, ScheduledRunHour, 0, 0, 0, loc)
+	}
+	return next
+}
+
+// NextRunAt returns when the user is next synced automatically: the scheduler's next_run_at when
+// it is still ahead, otherwise the next daily run in their timezone
+func NextRunAt(scheduled *time.Time, timezone string, now time.Time) time.Time {
+	if scheduled != nil && scheduled.After(now) {
+		return *scheduled
+	}
+	return NextScheduledRun(timezone, now)
+}
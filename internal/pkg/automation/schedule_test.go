@@ -0,0 +1,46 @@
+package automation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextScheduledRun(t *testing.T) {
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		now      time.Time
+		want     time.Time
+	}{
+		{"Before today's run", "Europe/Sofia", time.Date(2026, 10, 16, 1, 30, 0, 0, sofia), time.Date(2026, 10, 16, 3, 0, 0, 0, sofia)},
+		{"After today's run", "Europe/Sofia", time.Date(2026, 10, 16, 9, 0, 0, 0, sofia), time.Date(2026, 10, 17, 3, 0, 0, 0, sofia)},
+		{"At the run", "UTC", time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+		{"Unknown timezone", "Mars/Olympus", time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextScheduledRun(tt.timezone, tt.now); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNextRunAt(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	scheduled := now.Add(2 * time.Hour)
+	if got := NextRunAt(&scheduled, "UTC", now); !got.Equal(scheduled) {
+		t.Errorf("Expected the scheduler's time, got %v", got)
+	}
+
+	past := now.Add(-time.Hour)
+	if got := NextRunAt(&past, "UTC", now); !got.Equal(time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the next daily run for a stale schedule, got %v", got)
+	}
+}
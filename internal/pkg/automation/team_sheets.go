@@ -0,0 +1,87 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
+)
+
+// maxTabNameLength keeps team sheet tab titles well under the Google Sheets limit of 100 characters
+const maxTabNameLength = 80
+
+// TeamSheetSource lists the shared team spreadsheets an athlete's activities are copied to
+type TeamSheetSource interface {
+	GetTeamSheetsForUser(ctx context.Context, userID int) ([]database.TeamSheetTokens, error)
+}
+
+// TeamSheet is a coach-owned spreadsheet that collects the activities of a team's athletes,
+// one tab per athlete. It is written with the coach's Google tokens.
+type TeamSheet struct {
+	TeamID        int    `json:"team_id"`
+	SpreadsheetID string `json:"spreadsheet_id"`
+	CoachUserID   int    `json:"coach_user_id"`
+	// Tab is the athlete's tab in the team spreadsheet
+	Tab string `json:"tab"`
+
+	GoogleAccessToken  *secure.Token `json:"-"` // Never serialize sensitive tokens
+	GoogleRefreshToken *secure.Token `json:"-"` // Never serialize sensitive tokens
+	GoogleTokenExpiry  *time.Time    `json:"-"` // Never serialize sensitive tokens
+}
+
+// HasValidGoogleToken checks if the coach's Google access token is present and not expired
+func (s TeamSheet) HasValidGoogleToken() bool {
+	if s.GoogleAccessToken.Empty() || s.GoogleTokenExpiry == nil {
+		return false
+	}
+	return time.Now().Add(5 * time.Minute).Before(*s.GoogleTokenExpiry)
+}
+
+// TeamSheetTab names an athlete's tab in a team spreadsheet. The user ID keeps tabs of athletes
+// with the same name apart.
+func TeamSheetTab(name string, userID int) string {
+	if utf8.RuneCountInString(name) > maxTabNameLength {
+		name = string([]rune(name)[:maxTabNameLength])
+	}
+	if name == "" {
+		return fmt.Sprintf("Athlete %d", userID)
+	}
+	return fmt.Sprintf("%s (%d)", name, userID)
+}
+
+// SetTeamSheetSource makes GetProcessingConfigForUser include the user's shared team spreadsheets.
+// Without one, activities are only written to the user's own spreadsheet.
+func (s *ConfigService) SetTeamSheetSource(source TeamSheetSource) {
+	s.teamSheets = source
+}
+
+// loadTeamSheets adds the user's shared team spreadsheets to config. A failed lookup is logged
+// and leaves them out, so it never blocks the sync to the user's own spreadsheet.
+func (s *ConfigService) loadTeamSheets(ctx context.Context, config *ProcessingConfig) {
+	if s.teamSheets == nil {
+		return
+	}
+
+	sheets, err := s.teamSheets.GetTeamSheetsForUser(ctx, config.UserID)
+	if err != nil {
+		s.logger.Warn("Failed to load team spreadsheets, skipping them for this run",
+			"user_id", config.UserID,
+			"error", err)
+		return
+	}
+
+	for _, sheet := range sheets {
+		config.TeamSheets = append(config.TeamSheets, TeamSheet{
+			TeamID:             sheet.TeamID,
+			SpreadsheetID:      sheet.SpreadsheetID,
+			CoachUserID:        sheet.CoachUserID,
+			Tab:                TeamSheetTab(sheet.AthleteName, config.UserID),
+			GoogleAccessToken:  sheet.GoogleAccessToken,
+			GoogleRefreshToken: sheet.GoogleRefreshToken,
+			GoogleTokenExpiry:  sheet.GoogleTokenExpiry,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
+)
+
+type mockTeamSheetSource struct {
+	sheets []database.TeamSheetTokens
+	err    error
+}
+
+func (m *mockTeamSheetSource) GetTeamSheetsForUser(ctx context.Context, userID int) ([]database.TeamSheetTokens, error) {
+	return m.sheets, m.err
+}
+
+func TestConfigService_TeamSheets(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	spreadsheetID := "athlete-sheet"
+	athleteID := int64(12345)
+	mockRepo := NewMockUserRepository()
+	mockRepo.AddUser(7, &database.User{ID: 7, AutomationEnabled: true})
+	mockRepo.AddProcessingTokens(7, &database.ProcessingTokens{
+		GoogleAccessToken:  secure.NewTokenString("athlete-access"),
+		GoogleRefreshToken: secure.NewTokenString("athlete-refresh"),
+		GoogleTokenExpiry:  &expiry,
+		StravaAccessToken:  secure.NewTokenString("strava-access"),
+		StravaRefreshToken: secure.NewTokenString("strava-refresh"),
+		StravaTokenExpiry:  &expiry,
+		StravaAthleteID:    &athleteID,
+		SpreadsheetID:      &spreadsheetID,
+		Timezone:           "UTC",
+		Email:              "ana@example.com",
+	})
+
+	source := &mockTeamSheetSource{sheets: []database.TeamSheetTokens{{
+		TeamID:             3,
+		SpreadsheetID:      "team-sheet",
+		CoachUserID:        2,
+		AthleteName:        "Ana",
+		GoogleAccessToken:  secure.NewTokenString("coach-access"),
+		GoogleRefreshToken: secure.NewTokenString("coach-refresh"),
+		GoogleTokenExpiry:  &expiry,
+	}}}
+	service := NewConfigService(mockRepo, logger.New("test"))
+	service.SetTeamSheetSource(source)
+
+	config, err := service.GetProcessingConfigForUser(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.TeamSheets) != 1 {
+		t.Fatalf("Expected 1 team sheet, got %d", len(config.TeamSheets))
+	}
+	sheet := config.TeamSheets[0]
+	if sheet.SpreadsheetID != "team-sheet" || sheet.CoachUserID != 2 || sheet.Tab != "Ana (7)" || !sheet.HasValidGoogleToken() {
+		t.Errorf("Unexpected team sheet: %+v", sheet)
+	}
+	if len(config.Tokens()) != 6 {
+		t.Errorf("Expected the coach's tokens to be held with the job's, got %d tokens", len(config.Tokens()))
+	}
+
+	// A failed lookup leaves the team sheets out instead of failing the run
+	source.err = errors.New("connection refused")
+	config, err = service.GetProcessingConfigForUser(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Expected the run to proceed without team sheets, got %v", err)
+	}
+	if len(config.TeamSheets) != 0 {
+		t.Errorf("Expected no team sheets, got %d", len(config.TeamSheets))
+	}
+}
+
+func TestTeamSheetTab(t *testing.T) {
+	if tab := TeamSheetTab("Ana", 7); tab != "Ana (7)" {
+		t.Errorf("Expected %q, got %q", "Ana (7)", tab)
+	}
+	if tab := TeamSheetTab("", 7); tab != "Athlete 7" {
+		t.Errorf("Expected %q, got %q", "Athlete 7", tab)
+	}
+	if tab := TeamSheetTab(strings.Repeat("é", 120), 7); len([]rune(tab)) > 100 {
+		t.Errorf("Expected the tab title to fit Google Sheets, got %d characters", len([]rune(tab)))
+	}
+}
@@ -0,0 +1,83 @@
+package automation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/format"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// WeeklySummarySheetTitle is the spreadsheet tab that holds weekly totals
+const WeeklySummarySheetTitle = "Weekly"
+
+// WeeklySummaryHeader is the header row of the weekly summary tab
+var WeeklySummaryHeader = []interface{}{
+	"Week Starting", "Distance", "Moving Time", "Elevation Gain", "Runs", "Activities",
+}
+
+// WeeklySummary aggregates a user's activities over a Monday-to-Sunday week
+type WeeklySummary struct {
+	WeekStart           time.Time
+	DistanceMeters      float64
+	MovingTimeSeconds   int
+	ElevationGainMeters float64
+	RunCount            int
+	ActivityCount       int
+}
+
+// StartOfWeek returns midnight of the Monday of the week containing t, in t's location
+func StartOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.AddDate(0, 0, -daysSinceMonday).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// ComputeWeeklySummaries groups activities by week in the user's location and totals them
+// Weeks starting before completeFrom are dropped because the fetched activities may not cover them fully
+func ComputeWeeklySummaries(activities []strava.Activity, loc *time.Location, completeFrom time.Time) []WeeklySummary {
+	byWeek := make(map[time.Time]*WeeklySummary)
+
+	for _, activity := range activities {
+		weekStart := StartOfWeek(activity.StartDate.In(loc))
+		if weekStart.Before(completeFrom) {
+			continue
+		}
+
+		summary, ok := byWeek[weekStart]
+		if !ok {
+			summary = &WeeklySummary{WeekStart: weekStart}
+			byWeek[weekStart] = summary
+		}
+
+		summary.DistanceMeters += activity.Distance
+		summary.MovingTimeSeconds += activity.MovingTime
+		summary.ElevationGainMeters += activity.TotalElevationGain
+		summary.ActivityCount++
+		if activity.Type == "Run" {
+			summary.RunCount++
+		}
+	}
+
+	summaries := make([]WeeklySummary, 0, len(byWeek))
+	for _, summary := range byWeek {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].WeekStart.Before(summaries[j].WeekStart)
+	})
+
+	return summaries
+}
+
+// ToRow formats the summary as a spreadsheet row keyed by the week start date
+func (s WeeklySummary) ToRow() []interface{} {
+	return []interface{}{
+		s.WeekStart.Format("2006-01-02"),
+		format.Default.Distance(s.DistanceMeters),
+		format.Duration(s.MovingTimeSeconds),
+		format.Default.Elevation(s.ElevationGainMeters),
+		s.RunCount,
+		s.ActivityCount,
+	}
+}
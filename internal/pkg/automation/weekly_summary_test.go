@@ -0,0 +1,78 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Sofia")
+
+	tests := []struct {
+		name     string
+		input    time.Time
+		expected string
+	}{
+		{"Monday", time.Date(2024, 6, 3, 8, 0, 0, 0, loc), "2024-06-03"},
+		{"Wednesday", time.Date(2024, 6, 5, 23, 59, 0, 0, loc), "2024-06-03"},
+		{"Sunday", time.Date(2024, 6, 9, 12, 0, 0, 0, loc), "2024-06-03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StartOfWeek(tt.input)
+			if result.Format("2006-01-02") != tt.expected {
+				t.Errorf("Expected week start %s, got %s", tt.expected, result.Format("2006-01-02"))
+			}
+			if result.Hour() != 0 || result.Minute() != 0 {
+				t.Errorf("Expected midnight, got %s", result.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestComputeWeeklySummaries(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Sofia")
+	completeFrom := time.Date(2024, 6, 3, 0, 0, 0, 0, loc)
+
+	activities := []strava.Activity{
+		// Previous, partially fetched week - must be dropped
+		{ID: 1, Type: "Run", Distance: 10000, MovingTime: 3000, StartDate: time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)},
+		// Week of 2024-06-03
+		{ID: 2, Type: "Run", Distance: 5000, MovingTime: 1500, TotalElevationGain: 20, StartDate: time.Date(2024, 6, 3, 6, 0, 0, 0, time.UTC)},
+		{ID: 3, Type: "Ride", Distance: 20000, MovingTime: 3600, TotalElevationGain: 150, StartDate: time.Date(2024, 6, 5, 17, 0, 0, 0, time.UTC)},
+		// Sunday 22:30 UTC is already Monday in Sofia - belongs to the next week
+		{ID: 4, Type: "Run", Distance: 8000, MovingTime: 2400, TotalElevationGain: 40, StartDate: time.Date(2024, 6, 9, 22, 30, 0, 0, time.UTC)},
+	}
+
+	summaries := ComputeWeeklySummaries(activities, loc, completeFrom)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 weekly summaries, got %d", len(summaries))
+	}
+
+	first := summaries[0]
+	if first.WeekStart.Format("2006-01-02") != "2024-06-03" {
+		t.Errorf("Expected first week 2024-06-03, got %s", first.WeekStart.Format("2006-01-02"))
+	}
+	if first.DistanceMeters != 25000 || first.MovingTimeSeconds != 5100 || first.ElevationGainMeters != 170 {
+		t.Errorf("Unexpected totals for first week: %+v", first)
+	}
+	if first.RunCount != 1 || first.ActivityCount != 2 {
+		t.Errorf("Expected 1 run and 2 activities, got %d runs and %d activities", first.RunCount, first.ActivityCount)
+	}
+
+	second := summaries[1]
+	if second.WeekStart.Format("2006-01-02") != "2024-06-10" {
+		t.Errorf("Expected second week 2024-06-10, got %s", second.WeekStart.Format("2006-01-02"))
+	}
+
+	row := first.ToRow()
+	expected := []interface{}{"2024-06-03", "25.00 km", "01:25:00", "170 m", 1, 2}
+	for i := range expected {
+		if row[i] != expected[i] {
+			t.Errorf("Row column %d: expected %v, got %v", i, expected[i], row[i])
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package capture records the raw Strava activity responses of a sync, so the sync can later be
+// replayed through row conversion and sheet writing without calling Strava again.
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Response is one Strava response as it was received
+type Response struct {
+	Endpoint string `json:"endpoint"`
+	// Body is the response when it is valid JSON; Text holds any other body, such as a
+	// truncated response that failed to decode
+	Body json.RawMessage `json:"body,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// Payload is the capture of one run's Strava activity responses
+type Payload struct {
+	UserID     int        `json:"user_id"`
+	TraceID    string     `json:"trace_id,omitempty"`
+	CapturedAt time.Time  `json:"captured_at"`
+	Responses  []Response `json:"responses"`
+}
+
+// Recorder collects responses for a payload; it satisfies strava.ResponseRecorder and is safe
+// for concurrent use
+type Recorder struct {
+	mu        sync.Mutex
+	responses []Response
+}
+
+// RecordResponse keeps a copy of body
+func (r *Recorder) RecordResponse(endpoint string, body []byte) {
+	response := Response{Endpoint: endpoint}
+	if json.Valid(body) {
+		response.Body = append(json.RawMessage(nil), body...)
+	} else {
+		response.Text = string(body)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, response)
+}
+
+// Payload returns the responses recorded so far, or nil when there are none
+func (r *Recorder) Payload(userID int, traceID string, capturedAt time.Time) *Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.responses) == 0 {
+		return nil
+	}
+	return &Payload{
+		UserID:     userID,
+		TraceID:    traceID,
+		CapturedAt: capturedAt.UTC(),
+		Responses:  append([]Response(nil), r.responses...),
+	}
+}
+
+// ObjectName names a run's payload by user and capture time, e.g.
+// strava/7/20261016T030000Z-3f2a.json; runs without a trace ID are named by time alone
+func ObjectName(userID int, traceID string, capturedAt time.Time) string {
+	name := fmt.Sprintf("strava/%d/%s", userID, capturedAt.UTC().Format("20060102T150405Z"))
+	if traceID != "" {
+		name += "-" + traceID
+	}
+	return name + ".json"
+}
+
+// Decode parses a stored payload
+func Decode(data []byte) (*Payload, error) {
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if payload.UserID <= 0 {
+		return nil, fmt.Errorf("invalid payload: user_id is missing")
+	}
+	return &payload, nil
+}
+
+// Activities decodes the captured activity lists and single activities, in the order they were
+// received. It fails on a response that was not valid JSON, since the original run could not
+// decode it either.
+func (p *Payload) Activities() ([]strava.Activity, error) {
+	activities := []strava.Activity{}
+	for _, response := range p.Responses {
+		if response.Body == nil {
+			return nil, fmt.Errorf("response to %s is not valid JSON", response.Endpoint)
+		}
+
+		if body := bytes.TrimSpace(response.Body); len(body) > 0 && body[0] == '[' {
+			var page []strava.Activity
+			if err := json.Unmarshal(body, &page); err != nil {
+				return nil, fmt.Errorf("failed to decode response to %s: %w", response.Endpoint, err)
+			}
+			activities = append(activities, page...)
+			continue
+		}
+
+		var activity strava.Activity
+		if err := json.Unmarshal(response.Body, &activity); err != nil {
+			return nil, fmt.Errorf("failed to decode response to %s: %w", response.Endpoint, err)
+		}
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
@@ -0,0 +1,64 @@
+package capture
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Payload(t *testing.T) {
+	var recorder Recorder
+	capturedAt := time.Date(2026, 10, 16, 5, 0, 0, 0, time.FixedZone("EEST", 3*3600))
+	if payload := recorder.Payload(7, "trace-1", capturedAt); payload != nil {
+		t.Fatalf("Expected no payload before a response, got %+v", payload)
+	}
+
+	recorder.RecordResponse("/athlete/activities?after=1&per_page=100", []byte(`[{"id":1,"name":"Morning Run","distance":5012.3}]`))
+	recorder.RecordResponse("/activities/2", []byte(`{"id":2,"name":"Lunch Ride"}`))
+	recorder.RecordResponse("/athlete/activities?page=2", []byte(`[{"id":3,`))
+
+	payload := recorder.Payload(7, "trace-1", capturedAt)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Failed to encode payload: %v", err)
+	}
+	if !strings.Contains(string(data), `"body":[{"id":1,"name":"Morning Run","distance":5012.3}]`) || !strings.Contains(string(data), `"text":"[{\"id\":3,"`) {
+		t.Errorf("Expected the responses as received, got %s", data)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded.UserID != 7 || decoded.TraceID != "trace-1" || !decoded.CapturedAt.Equal(capturedAt) || len(decoded.Responses) != 3 {
+		t.Errorf("Unexpected decoded payload: %+v", decoded)
+	}
+
+	// The truncated page fails the replay, as it failed the run
+	if _, err := decoded.Activities(); err == nil || !strings.Contains(err.Error(), "page=2") {
+		t.Errorf("Expected the invalid response to be reported, got %v", err)
+	}
+	decoded.Responses = decoded.Responses[:2]
+	activities, err := decoded.Activities()
+	if err != nil {
+		t.Fatalf("Activities failed: %v", err)
+	}
+	if len(activities) != 2 || activities[0].Name != "Morning Run" || activities[0].Distance != 5012.3 || activities[1].ID != 2 {
+		t.Errorf("Unexpected activities: %+v", activities)
+	}
+
+	if _, err := Decode([]byte(`{"responses":[]}`)); err == nil {
+		t.Error("Expected a payload without a user to be refused")
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	at := time.Date(2026, 10, 16, 5, 0, 0, 0, time.FixedZone("EEST", 3*3600))
+	if name := ObjectName(7, "3f2a", at); name != "strava/7/20261016T020000Z-3f2a.json" {
+		t.Errorf("Unexpected object name %q", name)
+	}
+	if name := ObjectName(7, "", at); name != "strava/7/20261016T020000Z.json" {
+		t.Errorf("Unexpected object name without a trace ID %q", name)
+	}
+}
@@ -0,0 +1,208 @@
+// Package circuit implements per-provider circuit breakers. A breaker opens after consecutive
+// provider failures so jobs stop calling a provider that is down. While it is open, a synthetic
+// probe, not a user job, decides when the provider has recovered and the circuit closes again.
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Breaker defaults
+const (
+	DefaultFailureThreshold = 5
+	DefaultProbeInterval    = 30 * time.Second
+	DefaultProbeTimeout     = 10 * time.Second
+)
+
+// State of a breaker
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open" // a probe is in flight
+)
+
+// ErrOpen is returned by Allow while the circuit is open
+var ErrOpen = errors.New("circuit open")
+
+// Probe is a cheap synthetic request that succeeds when the provider is reachable and healthy.
+// It must not depend on any user's credentials.
+type Probe func(ctx context.Context) error
+
+// Breaker tracks the health of one provider. Callers check Allow before calling the provider and
+// report each provider-side outcome with RecordSuccess or RecordFailure; errors caused by the
+// user (revoked tokens, missing spreadsheets) must not be reported as failures.
+type Breaker struct {
+	name      string
+	threshold int
+	probe     Probe
+	logger    *logger.Logger
+	now       func() time.Time
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	lastProbeAt         time.Time
+	lastProbeErr        error
+}
+
+// NewBreaker creates a closed breaker that opens after threshold consecutive failures
+func NewBreaker(name string, threshold int, probe Probe, log *logger.Logger) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		probe:     probe,
+		logger:    log.WithContext("component", "circuit_breaker", "provider", name),
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// Name returns the provider name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow returns ErrOpen while the circuit is open or being probed. Real requests are never used
+// to test a failing provider; only Probe closes the circuit.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateClosed {
+		return nil
+	}
+	return fmt.Errorf("%s %w since %s", b.name, ErrOpen, b.openedAt.Format(time.RFC3339))
+}
+
+// RecordSuccess resets the consecutive failure count
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateClosed {
+		b.consecutiveFailures = 0
+	}
+}
+
+// RecordFailure counts a provider-side failure and opens the circuit at the threshold
+func (b *Breaker) RecordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateClosed {
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.consecutiveFailures < b.threshold {
+		return
+	}
+
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.logger.Error("Circuit opened - provider calls suspended until a probe succeeds",
+		"consecutive_failures", b.consecutiveFailures,
+		"error", err)
+}
+
+// Probe runs the synthetic probe if the circuit is open and closes it when the probe succeeds.
+// It returns the state after probing; closed breakers are not probed.
+func (b *Breaker) Probe(ctx context.Context) State {
+	b.mu.Lock()
+	if b.state != StateOpen || b.probe == nil {
+		state := b.state
+		b.mu.Unlock()
+		return state
+	}
+	b.state = StateHalfOpen
+	b.mu.Unlock()
+
+	err := b.probe(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastProbeAt = b.now()
+	b.lastProbeErr = err
+	if err != nil {
+		b.state = StateOpen
+		b.logger.Warn("Circuit probe failed, keeping circuit open",
+			"open_for", b.now().Sub(b.openedAt).Round(time.Second).String(),
+			"error", err)
+		return b.state
+	}
+
+	b.state = StateClosed
+	b.consecutiveFailures = 0
+	b.logger.Info("Circuit probe succeeded, circuit closed",
+		"open_for", b.now().Sub(b.openedAt).Round(time.Second).String())
+	return b.state
+}
+
+// Status is a point-in-time snapshot of a breaker
+type Status struct {
+	Provider            string     `json:"provider"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastProbeAt         *time.Time `json:"last_probe_at,omitempty"`
+	LastProbeError      string     `json:"last_probe_error,omitempty"`
+}
+
+// Status returns a snapshot of the breaker
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{
+		Provider:            b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if !b.lastProbeAt.IsZero() {
+		lastProbeAt := b.lastProbeAt
+		status.LastProbeAt = &lastProbeAt
+	}
+	if b.lastProbeErr != nil {
+		status.LastProbeError = b.lastProbeErr.Error()
+	}
+	return status
+}
+
+// RunProbes probes every open breaker each interval until ctx is done
+func RunProbes(ctx context.Context, interval time.Duration, breakers ...*Breaker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, b := range breakers {
+				probeCtx, cancel := context.WithTimeout(ctx, DefaultProbeTimeout)
+				b.Probe(probeCtx)
+				cancel()
+			}
+		}
+	}
+}
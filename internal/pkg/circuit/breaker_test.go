@@ -0,0 +1,93 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestBreaker_OpensAndClosesOnlyThroughProbe(t *testing.T) {
+	probeErr := errors.New("still down")
+	probes := 0
+	breaker := NewBreaker("strava", 2, func(ctx context.Context) error {
+		probes++
+		return probeErr
+	}, logger.New("test"))
+
+	outage := errors.New("503")
+	breaker.RecordFailure(outage)
+	breaker.RecordSuccess()
+	breaker.RecordFailure(outage)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected a success to reset the failure count, got %v", err)
+	}
+
+	breaker.RecordFailure(outage)
+	if err := breaker.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected ErrOpen after reaching the threshold, got %v", err)
+	}
+
+	// Real requests never close the circuit
+	breaker.RecordSuccess()
+	if breaker.State() != StateOpen {
+		t.Fatalf("Expected the circuit to stay open, got %s", breaker.State())
+	}
+
+	if state := breaker.Probe(context.Background()); state != StateOpen {
+		t.Errorf("Expected a failed probe to keep the circuit open, got %s", state)
+	}
+	if status := breaker.Status(); status.LastProbeError != "still down" || status.OpenedAt == nil {
+		t.Errorf("Unexpected status after failed probe: %+v", status)
+	}
+
+	probeErr = nil
+	if state := breaker.Probe(context.Background()); state != StateClosed {
+		t.Errorf("Expected a successful probe to close the circuit, got %s", state)
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Errorf("Expected requests to be allowed after closing, got %v", err)
+	}
+
+	// Closed breakers are not probed
+	breaker.Probe(context.Background())
+	if probes != 2 {
+		t.Errorf("Expected 2 probes, got %d", probes)
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	tests := []struct {
+		status  int
+		healthy bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusBadGateway, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Probe must not send credentials")
+			}
+			w.WriteHeader(tt.status)
+		}))
+
+		err := HTTPProbe(server.Client(), server.URL)(context.Background())
+		if (err == nil) != tt.healthy {
+			t.Errorf("Status %d: expected healthy=%v, got error %v", tt.status, tt.healthy, err)
+		}
+		server.Close()
+	}
+
+	if err := HTTPProbe(http.DefaultClient, "http://127.0.0.1:1")(context.Background()); err == nil {
+		t.Error("Expected a connection failure to fail the probe")
+	}
+}
@@ -0,0 +1,46 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Probe endpoints used by the automation engine. Both are called without credentials: the
+// provider answering 401 or 403 proves its API is up without spending any user's rate limit.
+
+// StravaProbeURL returns the athlete endpoint under the Strava API base URL
+func StravaProbeURL(apiBaseURL string) string {
+	return strings.TrimRight(apiBaseURL, "/") + "/athlete"
+}
+
+// GoogleProbeURL returns a nonexistent spreadsheet under the Sheets API base URL
+func GoogleProbeURL(sheetsBaseURL string) string {
+	return strings.TrimRight(sheetsBaseURL, "/") + "/v4/spreadsheets/circuit-probe"
+}
+
+// HTTPProbe returns a probe that issues an unauthenticated GET to url. Any response below 500
+// other than 429 counts as healthy; network errors, rate limiting and server errors do not.
+func HTTPProbe(client *http.Client, url string) Probe {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create probe request: %w", err)
+		}
+		req.Header.Set("User-Agent", "academy-sync-circuit-probe")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("probe request failed: %w", err)
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("probe returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -1,5 +1,6 @@
 // Package config provides a hybrid configuration loading mechanism that supports
-// both local development (.env files) and production environments (Google Secret Manager).
+// both local development (.env files) and production environments (a secret store:
+// Google Secret Manager, HashiCorp Vault or AWS Secrets Manager).
 package config
 
 import (
@@ -9,8 +10,6 @@ import (
 	"strconv"
 	"strings"
 
-	secretmanager "cloud.google.com/go/secretmanager/apiv1"
-	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/joho/godotenv"
 )
 
@@ -55,16 +54,68 @@ type Config struct {
 	SMTPPassword string `json:"smtp_password"`
 	FromEmail    string `json:"from_email"`
 
+	// Email provider: smtp (default) or sendgrid
+	EmailProvider  string `json:"email_provider"`
+	SendGridAPIKey string `json:"sendgrid_api_key"`
+
 	// GCP configuration
 	GCPProjectID string `json:"gcp_project_id"`
 
+	// Secret store the configuration was loaded from (gcp, vault, aws); empty when loaded
+	// from environment variables only
+	SecretBackend string `json:"secret_backend"`
+
 	// Fail-fast configuration
 	FailFastEnabled bool `json:"fail_fast_enabled"`
+
+	// Test mode configuration (automation engine without a job queue)
+	TestModeUserID int `json:"test_mode_user_id"`
+
+	// Spreadsheet template sources: template ID -> Drive file ID copied at onboarding
+	SheetTemplateSources map[string]string `json:"sheet_template_sources"`
+
+	// Admin access: emails of users granted the admin role
+	AdminEmails []string `json:"admin_emails"`
+
+	// Per-service settings (see sections.go)
+	Engine   EngineConfig   `json:"engine"`
+	API      APIConfig      `json:"api"`
+	Session  SessionConfig  `json:"session"`
+	Signup   SignupConfig   `json:"signup"`
+	Notifier NotifierConfig `json:"notifier"`
+
+	// Pruning and archival of old automation runs and audit events
+	Retention RetentionConfig `json:"retention"`
+
+	// Database connection pool, shared by every service
+	Database DatabaseConfig `json:"database"`
+
+	// Redis connection pool, shared by every Redis user of a service
+	Redis RedisConfig `json:"redis"`
+
+	// Strava and Google endpoints, overridable for staging against controlled backends
+	Providers ProviderConfig `json:"providers"`
+
+	// Periodic re-fetch of rotatable secrets (see reload.go)
+	Secrets SecretsConfig `json:"secrets"`
+
+	// Log output format and sampling
+	Logging LoggingConfig `json:"logging"`
+
+	// Internal pprof and runtime diagnostics port
+	Diagnostics DiagnosticsConfig `json:"diagnostics"`
 }
 
+// Email providers selectable with EMAIL_PROVIDER
+const (
+	EmailProviderSMTP     = "smtp"
+	EmailProviderSendGrid = "sendgrid"
+)
+
 // Load loads configuration based on the environment.
 // In local environments (APP_ENV=local), it loads from .env file.
-// In production environments, it loads from Google Secret Manager.
+// In production environments, it loads from the secret store selected by SECRET_BACKEND
+// (gcp by default, vault or aws).
 func Load() (*Config, error) {
 	env := getEnv("APP_ENV", getEnv("GO_ENV", "local"))
 
@@ -72,7 +123,7 @@ func Load() (*Config, error) {
 	case "local", "development", "dev":
 		return loadFromEnv()
 	case "production", "prod", "staging":
-		return loadFromSecretManager()
+		return loadFromSecretStore()
 	default:
 		return loadFromEnv() // Default to local for unknown environments
 	}
@@ -122,11 +173,24 @@ func loadFromEnv() (*Config, error) {
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 		FromEmail:    getEnv("FROM_EMAIL", ""),
 
+		// Email provider
+		EmailProvider:  strings.ToLower(getEnv("EMAIL_PROVIDER", EmailProviderSMTP)),
+		SendGridAPIKey: getEnv("SENDGRID_API_KEY", ""),
+
 		// GCP
 		GCPProjectID: getEnv("GCP_PROJECT_ID", ""),
 
 		// Fail-fast
 		FailFastEnabled: getEnvBool("FAIL_FAST_ENABLED", false),
+
+		// Test mode
+		TestModeUserID: getEnvInt("TEST_MODE_USER_ID", 0),
+
+		// Spreadsheet templates
+		SheetTemplateSources: parseKeyValueList(getEnv("SHEET_TEMPLATE_SOURCES", "")),
+
+		// Admin access
+		AdminEmails: parseList(getEnv("ADMIN_EMAILS", "")),
 	}
 
 	// Build database URL if not provided
@@ -152,6 +216,11 @@ func loadFromEnv() (*Config, error) {
 	// Build FrontendURL if not provided
 	config.buildFrontendURL()
 
+	// Load per-service settings
+	if err := config.loadServiceSections(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
 	// Validate required configuration
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -160,6 +229,23 @@ func loadFromEnv() (*Config, error) {
 	return config, nil
 }
 
+// loadFromSecretStore loads configuration from the secret store selected by SECRET_BACKEND.
+func loadFromSecretStore() (*Config, error) {
+	backend := strings.ToLower(getEnv("SECRET_BACKEND", SecretBackendGCP))
+	if backend == SecretBackendGCP {
+		return loadFromSecretManager()
+	}
+
+	ctx := context.Background()
+	provider, err := NewSecretProvider(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	defer provider.Close()
+
+	return loadFromSecretProvider(ctx, provider)
+}
+
 // loadFromSecretManager loads configuration from Google Secret Manager.
 func loadFromSecretManager() (*Config, error) {
 	ctx := context.Background()
@@ -170,18 +256,24 @@ func loadFromSecretManager() (*Config, error) {
 	}
 
 	// Try to create Secret Manager client
-	client, err := secretmanager.NewClient(ctx)
+	provider, err := newGCPSecretProvider(ctx, projectID)
 	if err != nil {
 		// If Secret Manager is not available, fall back to environment variables
 		// This allows graceful degradation in environments without Secret Manager access
 		fmt.Printf("Warning: Could not create Secret Manager client (%v), falling back to environment variables\n", err)
 		return loadFromEnvForProduction()
 	}
-	defer client.Close()
+	defer provider.Close()
 
-	fmt.Printf("Info: Loading configuration from Google Secret Manager for project: %s\n", projectID)
+	return loadFromSecretProvider(ctx, provider)
+}
 
-	// Define secrets to fetch from Secret Manager
+// loadFromSecretProvider loads configuration from a secret store, falling back to environment
+// variables for every secret the store does not hold.
+func loadFromSecretProvider(ctx context.Context, provider SecretProvider) (*Config, error) {
+	fmt.Printf("Info: Loading configuration from %s secret backend\n", provider.Name())
+
+	// Define secrets to fetch from the secret store
 	secrets := map[string]*string{
 		"database-url":           new(string),
 		"redis-url":              new(string),
@@ -194,13 +286,14 @@ func loadFromSecretManager() (*Config, error) {
 		"smtp-username":          new(string),
 		"smtp-password":          new(string),
 		"from-email":             new(string),
+		"sendgrid-api-key":       new(string),
 		"database-password":      new(string),
 	}
 
 	// Fetch each secret
 	secretsLoaded := 0
 	for secretName, value := range secrets {
-		secretValue, err := getSecret(ctx, client, projectID, secretName)
+		secretValue, err := provider.GetSecret(ctx, secretName)
 		if err != nil {
 			// Log warning but don't fail for optional secrets
 			fmt.Printf("Warning: failed to get secret %s: %v\n", secretName, err)
@@ -210,7 +303,7 @@ func loadFromSecretManager() (*Config, error) {
 		secretsLoaded++
 	}
 
-	fmt.Printf("Info: Successfully loaded %d secrets from Secret Manager\n", secretsLoaded)
+	fmt.Printf("Info: Successfully loaded %d secrets from %s secret backend\n", secretsLoaded, provider.Name())
 
 	config := &Config{
 		Environment: getEnv("APP_ENV", "production"),
@@ -231,11 +324,13 @@ func loadFromSecretManager() (*Config, error) {
 		SMTPUsername:       getValueOrEnv(secrets["smtp-username"], "SMTP_USERNAME", ""),
 		SMTPPassword:       getValueOrEnv(secrets["smtp-password"], "SMTP_PASSWORD", ""),
 		FromEmail:          getValueOrEnv(secrets["from-email"], "FROM_EMAIL", ""),
+		SendGridAPIKey:     getValueOrEnv(secrets["sendgrid-api-key"], "SENDGRID_API_KEY", ""),
 
 		// These typically come from environment in GCP
-		SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
-		SMTPPort:     getEnv("SMTP_PORT", "587"),
-		GCPProjectID: projectID,
+		SMTPHost:      getEnv("SMTP_HOST", "smtp.gmail.com"),
+		SMTPPort:      getEnv("SMTP_PORT", "587"),
+		EmailProvider: strings.ToLower(getEnv("EMAIL_PROVIDER", EmailProviderSMTP)),
+		GCPProjectID:  getEnv("GCP_PROJECT_ID", ""),
 
 		// Database components (for URL construction if needed)
 		PostgresDB:   getEnv("POSTGRES_DB", "academy_sync"),
@@ -249,8 +344,19 @@ func loadFromSecretManager() (*Config, error) {
 
 		// Fail-fast
 		FailFastEnabled: getEnvBool("FAIL_FAST_ENABLED", false),
+
+		// Test mode
+		TestModeUserID: getEnvInt("TEST_MODE_USER_ID", 0),
+
+		// Spreadsheet templates
+		SheetTemplateSources: parseKeyValueList(getEnv("SHEET_TEMPLATE_SOURCES", "")),
+
+		// Admin access
+		AdminEmails: parseList(getEnv("ADMIN_EMAILS", "")),
 	}
 
+	config.SecretBackend = provider.Name()
+
 	// Build database URL if not provided from secrets
 	if config.DatabaseURL == "" {
 		// Try to get database password from secrets or env
@@ -279,6 +385,11 @@ func loadFromSecretManager() (*Config, error) {
 	// Build FrontendURL if not provided
 	config.buildFrontendURL()
 
+	// Load per-service settings
+	if err := config.loadServiceSections(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
 	// Validate required configuration
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -287,20 +398,6 @@ func loadFromSecretManager() (*Config, error) {
 	return config, nil
 }
 
-// getSecret retrieves a secret from Google Secret Manager.
-func getSecret(ctx context.Context, client *secretmanager.Client, projectID, secretName string) (string, error) {
-	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", projectID, secretName),
-	}
-
-	result, err := client.AccessSecretVersion(ctx, req)
-	if err != nil {
-		return "", fmt.Errorf("failed to access secret %s: %w", secretName, err)
-	}
-
-	return string(result.Payload.Data), nil
-}
-
 // loadFromEnvForProduction loads configuration from environment variables for production.
 // This is used as a fallback when Secret Manager is not available or when running
 // in environments that use environment variables instead of Secret Manager.
@@ -344,11 +441,24 @@ func loadFromEnvForProduction() (*Config, error) {
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 		FromEmail:    getEnv("FROM_EMAIL", ""),
 
+		// Email provider
+		EmailProvider:  strings.ToLower(getEnv("EMAIL_PROVIDER", EmailProviderSMTP)),
+		SendGridAPIKey: getEnv("SENDGRID_API_KEY", ""),
+
 		// GCP
 		GCPProjectID: getEnv("GCP_PROJECT_ID", ""),
 
 		// Fail-fast
 		FailFastEnabled: getEnvBool("FAIL_FAST_ENABLED", false),
+
+		// Test mode
+		TestModeUserID: getEnvInt("TEST_MODE_USER_ID", 0),
+
+		// Spreadsheet templates
+		SheetTemplateSources: parseKeyValueList(getEnv("SHEET_TEMPLATE_SOURCES", "")),
+
+		// Admin access
+		AdminEmails: parseList(getEnv("ADMIN_EMAILS", "")),
 	}
 
 	// Build database URL if not provided
@@ -374,6 +484,11 @@ func loadFromEnvForProduction() (*Config, error) {
 	// Build FrontendURL if not provided
 	config.buildFrontendURL()
 
+	// Load per-service settings
+	if err := config.loadServiceSections(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
 	// Validate required configuration
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -382,7 +497,7 @@ func loadFromEnvForProduction() (*Config, error) {
 	return config, nil
 }
 
-// validate performs basic validation on the configuration.
+// validate performs the checks shared by all services; see ValidateFor for per-service settings.
 func (c *Config) validate() error {
 	var errors []string
 
@@ -422,6 +537,17 @@ func (c *Config) validate() error {
 		}
 	}
 
+	// Validate email provider
+	switch c.EmailProvider {
+	case "", EmailProviderSMTP:
+	case EmailProviderSendGrid:
+		if c.SendGridAPIKey == "" {
+			errors = append(errors, "SENDGRID_API_KEY is required when EMAIL_PROVIDER is sendgrid")
+		}
+	default:
+		errors = append(errors, "EMAIL_PROVIDER must be smtp or sendgrid")
+	}
+
 	// Validate log level
 	if c.LogLevel != "" {
 		validLevels := map[string]struct{}{
@@ -434,7 +560,7 @@ func (c *Config) validate() error {
 	}
 
 	if len(errors) > 0 {
-		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+		return &ValidationError{Problems: errors}
 	}
 
 	return nil
@@ -461,6 +587,41 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvInt gets an integer environment variable with a fallback default value.
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs, skipping malformed entries.
+func parseKeyValueList(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(pair, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found || key == "" || val == "" {
+			continue
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// parseList parses a comma-separated list, skipping empty entries.
+func parseList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // getValueOrEnv returns the secret value if available, otherwise falls back to environment variable.
 func getValueOrEnv(secretValue *string, envKey, defaultValue string) string {
 	if secretValue != nil && *secretValue != "" {
@@ -469,6 +630,30 @@ func getValueOrEnv(secretValue *string, envKey, defaultValue string) string {
 	return getEnv(envKey, defaultValue)
 }
 
+// IsProduction reports whether the configuration targets the production environment.
+func (c *Config) IsProduction() bool {
+	switch strings.ToLower(c.Environment) {
+	case "production", "prod":
+		return true
+	}
+	return false
+}
+
+// ValidateTestMode checks that the automation engine may run its test mode loop.
+// Test mode processes a single user outside the job queue, so it is refused in production
+// and requires TEST_MODE_USER_ID to name the user explicitly.
+func (c *Config) ValidateTestMode() error {
+	if c.IsProduction() {
+		return fmt.Errorf("test mode processing is not allowed in the %s environment", c.Environment)
+	}
+
+	if c.TestModeUserID <= 0 {
+		return fmt.Errorf("TEST_MODE_USER_ID must be set to a positive user ID to run test mode processing (go run ./cmd/seed creates a demo user and prints its ID)")
+	}
+
+	return nil
+}
+
 // buildBaseURL constructs the base URL if not provided for development environments only
 func (c *Config) buildBaseURL() {
 	if c.BaseURL == "" {
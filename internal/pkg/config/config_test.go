@@ -209,6 +209,26 @@ func TestValidateConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "port must be a valid number",
 		},
+		{
+			name: "sendgrid without API key",
+			config: Config{
+				Environment:   "local",
+				Port:          "8080",
+				EmailProvider: EmailProviderSendGrid,
+			},
+			expectError: true,
+			errorMsg:    "SENDGRID_API_KEY is required",
+		},
+		{
+			name: "unknown email provider",
+			config: Config{
+				Environment:   "local",
+				Port:          "8080",
+				EmailProvider: "mailgun",
+			},
+			expectError: true,
+			errorMsg:    "EMAIL_PROVIDER must be smtp or sendgrid",
+		},
 	}
 
 	for _, tt := range tests {
@@ -495,3 +515,56 @@ func TestLoadFromSecretManagerFallback(t *testing.T) {
 	}
 }
 
+
+func TestValidateTestMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		userID      int
+		expectError bool
+	}{
+		{"Local with test user", "local", 7, false},
+		{"Staging with test user", "staging", 7, false},
+		{"Local without test user", "local", 0, true},
+		{"Production refused", "production", 7, true},
+		{"Prod alias refused", "prod", 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Environment: tt.environment, TestModeUserID: tt.userID}
+			err := cfg.ValidateTestMode()
+			if tt.expectError && err == nil {
+				t.Error("Expected validation error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no validation error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	defer os.Unsetenv("TEST_INT_VAR")
+
+	os.Setenv("TEST_INT_VAR", "12")
+	if result := getEnvInt("TEST_INT_VAR", 0); result != 12 {
+		t.Errorf("Expected 12, got %d", result)
+	}
+
+	os.Setenv("TEST_INT_VAR", "not-a-number")
+	if result := getEnvInt("TEST_INT_VAR", 3); result != 3 {
+		t.Errorf("Expected default 3 for invalid value, got %d", result)
+	}
+}
+
+func TestParseKeyValueList(t *testing.T) {
+	result := parseKeyValueList(" basic_log=abc , coach_plan=def,broken,=x,triathlon=")
+	if len(result) != 2 || result["basic_log"] != "abc" || result["coach_plan"] != "def" {
+		t.Errorf("Unexpected parse result: %v", result)
+	}
+
+	if result := parseKeyValueList(""); len(result) != 0 {
+		t.Errorf("Expected empty map, got %v", result)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Secrets that may be rotated in the secret store without restarting the services
+const (
+	SecretGoogleClientSecret = "google-client-secret"
+	SecretStravaClientSecret = "strava-client-secret"
+	SecretSMTPPassword       = "smtp-password"
+	SecretSendGridAPIKey     = "sendgrid-api-key"
+)
+
+// ReloadableSecrets lists the secrets re-fetched by a SecretWatcher
+var ReloadableSecrets = []string{
+	SecretGoogleClientSecret,
+	SecretStravaClientSecret,
+	SecretSMTPPassword,
+	SecretSendGridAPIKey,
+}
+
+// SecretWatcher re-fetches the reloadable secrets from the secret store and notifies the
+// components holding them when a value changes, so rotations take effect without a redeploy
+type SecretWatcher struct {
+	provider SecretProvider
+
+	mu        sync.Mutex
+	values    map[string]string
+	callbacks map[string][]func(value string)
+	lastCheck time.Time
+}
+
+// NewSecretWatcher creates a watcher over provider, seeded with the secrets the configuration
+// was loaded with so only later rotations trigger callbacks
+func NewSecretWatcher(provider SecretProvider, cfg *Config) *SecretWatcher {
+	return &SecretWatcher{
+		provider: provider,
+		values: map[string]string{
+			SecretGoogleClientSecret: cfg.GoogleClientSecret,
+			SecretStravaClientSecret: cfg.StravaClientSecret,
+			SecretSMTPPassword:       cfg.SMTPPassword,
+			SecretSendGridAPIKey:     cfg.SendGridAPIKey,
+		},
+		callbacks: make(map[string][]func(value string)),
+	}
+}
+
+// OpenSecretWatcher connects to the secret backend the configuration was loaded from. It returns
+// nil when the configuration came from environment variables, which cannot change at runtime.
+func OpenSecretWatcher(ctx context.Context, cfg *Config) (*SecretWatcher, error) {
+	if cfg.SecretBackend == "" {
+		return nil, nil
+	}
+
+	provider, err := NewSecretProvider(ctx, cfg.SecretBackend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s secret backend: %w", cfg.SecretBackend, err)
+	}
+	return NewSecretWatcher(provider, cfg), nil
+}
+
+// OnChange registers fn to be called with the new value whenever the named secret changes
+func (w *SecretWatcher) OnChange(name string, fn func(value string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks[name] = append(w.callbacks[name], fn)
+}
+
+// Reload re-fetches every reloadable secret and runs the callbacks of those that changed. Secrets
+// that cannot be fetched keep their current value; the names of the changed secrets are returned.
+func (w *SecretWatcher) Reload(ctx context.Context) ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed, failed []string
+	for _, name := range ReloadableSecrets {
+		value, err := w.provider.GetSecret(ctx, name)
+		if err != nil {
+			failed = append(failed, name)
+			continue
+		}
+		if value == "" || value == w.values[name] {
+			continue
+		}
+
+		w.values[name] = value
+		changed = append(changed, name)
+		for _, fn := range w.callbacks[name] {
+			fn(value)
+		}
+	}
+	w.lastCheck = time.Now()
+
+	sort.Strings(changed)
+	if len(failed) == len(ReloadableSecrets) {
+		return changed, fmt.Errorf("failed to fetch secrets from %s secret backend", w.provider.Name())
+	}
+	return changed, nil
+}
+
+// LastCheck returns when the secrets were last re-fetched
+func (w *SecretWatcher) LastCheck() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lastCheck
+}
+
+// Run reloads the secrets every interval until ctx is cancelled, reporting each rotation or
+// failure to report (which may be nil)
+func (w *SecretWatcher) Run(ctx context.Context, interval time.Duration, report func(changed []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := w.Reload(ctx)
+			if report != nil && (err != nil || len(changed) > 0) {
+				report(changed, err)
+			}
+		}
+	}
+}
+
+// Close releases the secret backend connection
+func (w *SecretWatcher) Close() error {
+	return w.provider.Close()
+}
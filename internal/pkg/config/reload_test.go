@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSecretWatcherReload(t *testing.T) {
+	provider := &fakeSecretProvider{secrets: map[string]string{
+		SecretStravaClientSecret: "strava-v1",
+		SecretSMTPPassword:       "smtp-v1",
+	}}
+	watcher := NewSecretWatcher(provider, &Config{StravaClientSecret: "strava-v1", SMTPPassword: "smtp-v1"})
+
+	var applied []string
+	watcher.OnChange(SecretStravaClientSecret, func(value string) { applied = append(applied, value) })
+
+	changed, err := watcher.Reload(context.Background())
+	if err != nil || len(changed) != 0 || len(applied) != 0 {
+		t.Fatalf("Expected no change for the secrets loaded at startup, got %v %v (%v)", changed, applied, err)
+	}
+
+	provider.secrets[SecretStravaClientSecret] = "strava-v2"
+	changed, err = watcher.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != SecretStravaClientSecret {
+		t.Errorf("Expected only the Strava secret to change, got %v", changed)
+	}
+	if len(applied) != 1 || applied[0] != "strava-v2" {
+		t.Errorf("Expected the callback to receive the rotated secret, got %v", applied)
+	}
+
+	// A secret deleted from the store keeps its current value
+	delete(provider.secrets, SecretStravaClientSecret)
+	if changed, _ := watcher.Reload(context.Background()); len(changed) != 0 || len(applied) != 1 {
+		t.Errorf("Expected a missing secret to be ignored, got %v %v", changed, applied)
+	}
+
+	provider.secrets = map[string]string{}
+	if _, err := watcher.Reload(context.Background()); err == nil {
+		t.Error("Expected an error when no secret can be fetched")
+	}
+}
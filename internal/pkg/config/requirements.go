@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Services that load the configuration; each requires a different set of settings
+const (
+	ServiceBackendAPI       = "backend-api"
+	ServiceAutomationEngine = "automation-engine"
+	ServiceNotifier         = "notification-service"
+	ServiceRemediation      = "remediation"
+	ServiceAcademyctl       = "academyctl"
+	ServiceSeed             = "seed"
+)
+
+// ValidationError lists every configuration problem found, so they can all be fixed in one pass
+type ValidationError struct {
+	Service  string // Empty for the checks shared by all services
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Service == "" {
+		return fmt.Sprintf("validation errors: %s", strings.Join(e.Problems, ", "))
+	}
+	return fmt.Sprintf("%s configuration is invalid: %s", e.Service, strings.Join(e.Problems, ", "))
+}
+
+// Report formats the problems one per line, for printing before the logger is available
+func (e *ValidationError) Report() string {
+	var b strings.Builder
+	if e.Service == "" {
+		fmt.Fprintf(&b, "%d configuration problem(s):", len(e.Problems))
+	} else {
+		fmt.Fprintf(&b, "%s: %d configuration problem(s):", e.Service, len(e.Problems))
+	}
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s", problem)
+	}
+	return b.String()
+}
+
+// requirement is a setting a service cannot run without
+type requirement struct {
+	setting string // Environment variable or secret name(s) shown to the operator
+	missing func(c *Config) bool
+	reason  string
+}
+
+func required(setting string, value func(c *Config) string, reason string) requirement {
+	return requirement{
+		setting: setting,
+		missing: func(c *Config) bool { return value(c) == "" },
+		reason:  reason,
+	}
+}
+
+var (
+	requireDatabase = required("DATABASE_URL", func(c *Config) string { return c.DatabaseURL },
+		"set DATABASE_URL or the POSTGRES_* variables it is built from")
+	requireEncryption = required("ENCRYPTION_SECRET", func(c *Config) string { return c.EncryptionSecret },
+		"needed to decrypt the stored OAuth tokens")
+	requireJWT = required("JWT_SECRET", func(c *Config) string { return c.JWTSecret },
+		"needed to sign session tokens")
+	requireGoogleID = required("GOOGLE_CLIENT_ID", func(c *Config) string { return c.GoogleClientID },
+		"create an OAuth client in the Google Cloud console")
+	requireGoogleSecret = required("GOOGLE_CLIENT_SECRET", func(c *Config) string { return c.GoogleClientSecret },
+		"create an OAuth client in the Google Cloud console")
+	requireStravaID = required("STRAVA_CLIENT_ID", func(c *Config) string { return c.StravaClientID },
+		"see https://www.strava.com/settings/api")
+	requireStravaSecret = required("STRAVA_CLIENT_SECRET", func(c *Config) string { return c.StravaClientSecret },
+		"see https://www.strava.com/settings/api")
+	requireFromEmail = required("FROM_EMAIL", func(c *Config) string { return c.FromEmail },
+		"the sender address of notification emails")
+
+	// The notifier needs the credentials of whichever email provider is selected
+	requireEmailCredentials = requirement{
+		setting: "SMTP_HOST, SMTP_USERNAME and SMTP_PASSWORD (or SENDGRID_API_KEY with EMAIL_PROVIDER=sendgrid)",
+		missing: func(c *Config) bool {
+			if c.EmailProvider == EmailProviderSendGrid {
+				return c.SendGridAPIKey == ""
+			}
+			return c.SMTPHost == "" || c.SMTPUsername == "" || c.SMTPPassword == ""
+		},
+		reason: "needed to send notification emails",
+	}
+)
+
+// serviceRequirements is the matrix of settings each service requires
+var serviceRequirements = map[string][]requirement{
+	ServiceBackendAPI: {
+		requireDatabase, requireJWT, requireEncryption,
+		requireGoogleID, requireGoogleSecret, requireStravaID, requireStravaSecret,
+	},
+	ServiceAutomationEngine: {
+		requireDatabase, requireEncryption,
+		requireStravaID, requireStravaSecret, requireGoogleID, requireGoogleSecret,
+	},
+	ServiceNotifier: {
+		requireDatabase, requireFromEmail, requireEmailCredentials,
+	},
+	ServiceRemediation: {
+		requireDatabase,
+	},
+	// tokens re-encrypt also needs ENCRYPTION_SECRET and checks it itself
+	ServiceAcademyctl: {
+		requireDatabase,
+	},
+	ServiceSeed: {
+		requireDatabase, requireEncryption,
+	},
+}
+
+// ValidateFor checks that every setting service requires is present, returning a
+// *ValidationError naming all missing settings
+func (c *Config) ValidateFor(service string) error {
+	requirements, ok := serviceRequirements[service]
+	if !ok {
+		return fmt.Errorf("unknown service %q", service)
+	}
+
+	var problems []string
+	for _, req := range requirements {
+		if req.missing(c) {
+			problems = append(problems, fmt.Sprintf("%s is required (%s)", req.setting, req.reason))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Service: service, Problems: problems}
+	}
+	return nil
+}
+
+// IsDevelopment reports whether the configuration targets local development, where missing
+// service settings are tolerated so a service can run with only part of its features
+func (c *Config) IsDevelopment() bool {
+	switch c.Environment {
+	case "local", "development", "dev":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateFor(t *testing.T) {
+	complete := func() *Config {
+		return &Config{
+			Environment:        "production",
+			DatabaseURL:        "postgres://db/academy_sync",
+			JWTSecret:          "jwt-secret",
+			EncryptionSecret:   "0123456789abcdef0123456789abcdef",
+			GoogleClientID:     "google-id",
+			GoogleClientSecret: "google-secret",
+			StravaClientID:     "strava-id",
+			StravaClientSecret: "strava-secret",
+			FromEmail:          "noreply@example.com",
+			EmailProvider:      EmailProviderSMTP,
+			SMTPHost:           "smtp.example.com",
+			SMTPUsername:       "user",
+			SMTPPassword:       "password",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		modify  func(c *Config)
+		missing []string
+	}{
+		{name: "complete backend", service: ServiceBackendAPI},
+		{name: "complete engine", service: ServiceAutomationEngine},
+		{name: "complete notifier", service: ServiceNotifier},
+		{
+			name:    "engine reports every missing credential",
+			service: ServiceAutomationEngine,
+			modify: func(c *Config) {
+				c.EncryptionSecret = ""
+				c.StravaClientSecret = ""
+				c.GoogleClientID = ""
+			},
+			missing: []string{"ENCRYPTION_SECRET", "STRAVA_CLIENT_SECRET", "GOOGLE_CLIENT_ID"},
+		},
+		{
+			name:    "engine does not need a JWT secret",
+			service: ServiceAutomationEngine,
+			modify:  func(c *Config) { c.JWTSecret = "" },
+		},
+		{
+			name:    "notifier needs SMTP credentials",
+			service: ServiceNotifier,
+			modify:  func(c *Config) { c.SMTPPassword = "" },
+			missing: []string{"SMTP_PASSWORD"},
+		},
+		{
+			name:    "notifier on sendgrid does not need SMTP",
+			service: ServiceNotifier,
+			modify: func(c *Config) {
+				c.EmailProvider = EmailProviderSendGrid
+				c.SendGridAPIKey = "SG.key"
+				c.SMTPHost, c.SMTPUsername, c.SMTPPassword = "", "", ""
+			},
+		},
+		{
+			name:    "notifier on sendgrid needs an API key",
+			service: ServiceNotifier,
+			modify:  func(c *Config) { c.EmailProvider = EmailProviderSendGrid },
+			missing: []string{"SENDGRID_API_KEY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := complete()
+			if tt.modify != nil {
+				tt.modify(cfg)
+			}
+
+			err := cfg.ValidateFor(tt.service)
+			if len(tt.missing) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a ValidationError, got %v", err)
+			}
+			if len(validationErr.Problems) != len(tt.missing) {
+				t.Errorf("Expected %d problems, got %v", len(tt.missing), validationErr.Problems)
+			}
+			report := validationErr.Report()
+			for _, setting := range tt.missing {
+				if !strings.Contains(report, setting) {
+					t.Errorf("Expected the report to name %s, got %q", setting, report)
+				}
+			}
+		})
+	}
+
+	if err := complete().ValidateFor("scheduler"); err == nil {
+		t.Error("Expected an error for an unknown service")
+	}
+}
@@ -0,0 +1,441 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+)
+
+// Secret backends selectable with SECRET_BACKEND
+const (
+	SecretBackendGCP   = "gcp"
+	SecretBackendVault = "vault"
+	SecretBackendAWS   = "aws"
+)
+
+// secretRequestTimeout bounds a single secret fetch from Vault or AWS
+const secretRequestTimeout = 10 * time.Second
+
+// SecretProvider fetches secrets by their kebab-case name (e.g. "jwt-secret") from a secret store
+type SecretProvider interface {
+	// Name returns the backend name, e.g. "vault"
+	Name() string
+	GetSecret(ctx context.Context, name string) (string, error)
+	Close() error
+}
+
+// NewSecretProvider creates the provider for backend, configured from the environment:
+//   - gcp: GCP_PROJECT_ID, with Application Default Credentials
+//   - vault: VAULT_ADDR, VAULT_TOKEN (or VAULT_ROLE_ID and VAULT_SECRET_ID for AppRole login at
+//     VAULT_APPROLE_MOUNT, default approle), VAULT_SECRET_PATH (default secret/data/academy-sync)
+//     and optionally VAULT_NAMESPACE; every secret is a key of the one KV secret at the path
+//   - aws: AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, optionally AWS_SESSION_TOKEN,
+//     AWS_SECRET_PREFIX (default academy-sync/) and AWS_SECRETS_MANAGER_ENDPOINT
+func NewSecretProvider(ctx context.Context, backend string) (SecretProvider, error) {
+	switch backend {
+	case SecretBackendGCP:
+		projectID := getEnv("GCP_PROJECT_ID", "")
+		if projectID == "" {
+			return nil, fmt.Errorf("GCP_PROJECT_ID environment variable is required for Secret Manager")
+		}
+		return newGCPSecretProvider(ctx, projectID)
+	case SecretBackendVault:
+		if roleID := getEnv("VAULT_ROLE_ID", ""); roleID != "" && getEnv("VAULT_TOKEN", "") == "" {
+			return newVaultAppRoleProvider(
+				ctx,
+				getEnv("VAULT_ADDR", ""),
+				getEnv("VAULT_APPROLE_MOUNT", "approle"),
+				roleID,
+				getEnv("VAULT_SECRET_ID", ""),
+				getEnv("VAULT_SECRET_PATH", "secret/data/academy-sync"),
+				getEnv("VAULT_NAMESPACE", ""),
+			)
+		}
+		return newVaultSecretProvider(
+			getEnv("VAULT_ADDR", ""),
+			getEnv("VAULT_TOKEN", ""),
+			getEnv("VAULT_SECRET_PATH", "secret/data/academy-sync"),
+			getEnv("VAULT_NAMESPACE", ""),
+		)
+	case SecretBackendAWS:
+		return newAWSSecretProvider(
+			getEnv("AWS_REGION", getEnv("AWS_DEFAULT_REGION", "")),
+			getEnv("AWS_ACCESS_KEY_ID", ""),
+			getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			getEnv("AWS_SESSION_TOKEN", ""),
+			getEnv("AWS_SECRET_PREFIX", "academy-sync/"),
+			getEnv("AWS_SECRETS_MANAGER_ENDPOINT", ""),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported SECRET_BACKEND %q (expected %s, %s or %s)", backend, SecretBackendGCP, SecretBackendVault, SecretBackendAWS)
+	}
+}
+
+// gcpSecretProvider reads the latest version of each secret from Google Secret Manager
+type gcpSecretProvider struct {
+	client    *secretmanager.Client
+	projectID string
+}
+
+func newGCPSecretProvider(ctx context.Context, projectID string) (*gcpSecretProvider, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gcpSecretProvider{client: client, projectID: projectID}, nil
+}
+
+func (p *gcpSecretProvider) Name() string {
+	return SecretBackendGCP
+}
+
+// GetSecret retrieves a secret from Google Secret Manager.
+func (p *gcpSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	req := &secretmanagerpb.AccessSecretVersionRequest{
+		Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", p.projectID, name),
+	}
+
+	result, err := p.client.AccessSecretVersion(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret %s: %w", name, err)
+	}
+
+	return string(result.Payload.Data), nil
+}
+
+func (p *gcpSecretProvider) Close() error {
+	return p.client.Close()
+}
+
+// vaultSecretProvider reads secrets from one HashiCorp Vault KV secret (version 1 or 2),
+// authenticating with a token
+type vaultSecretProvider struct {
+	addr       string
+	path       string
+	namespace  string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token string
+
+	// login obtains a new token when the current one is rejected; nil for static tokens
+	login func(ctx context.Context) (string, error)
+}
+
+func newVaultSecretProvider(addr, token, path, namespace string) (*vaultSecretProvider, error) {
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required for the vault secret backend")
+	}
+	return &vaultSecretProvider{
+		addr:       strings.TrimRight(addr, "/"),
+		token:      token,
+		path:       strings.Trim(path, "/"),
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: secretRequestTimeout},
+	}, nil
+}
+
+// newVaultAppRoleProvider logs in with an AppRole and reads secrets with the issued token, logging
+// in again when the token expires so long-running services keep their access
+func newVaultAppRoleProvider(ctx context.Context, addr, mount, roleID, secretID, path, namespace string) (*vaultSecretProvider, error) {
+	if addr == "" || roleID == "" || secretID == "" {
+		return nil, fmt.Errorf("VAULT_ADDR, VAULT_ROLE_ID and VAULT_SECRET_ID are required for vault AppRole login")
+	}
+
+	p := &vaultSecretProvider{
+		addr:       strings.TrimRight(addr, "/"),
+		path:       strings.Trim(path, "/"),
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: secretRequestTimeout},
+	}
+	p.login = func(ctx context.Context) (string, error) {
+		return p.appRoleLogin(ctx, strings.Trim(mount, "/"), roleID, secretID)
+	}
+
+	token, err := p.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.token = token
+	return p, nil
+}
+
+// appRoleLogin exchanges the role and secret IDs for a client token
+func (p *vaultSecretProvider) appRoleLogin(ctx context.Context, mount, roleID, secretID string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"role_id": roleID, "secret_id": secretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode vault login: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.addr+"/v1/auth/"+mount+"/login", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("vault AppRole login returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault login response: %w", err)
+	}
+	if body.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault AppRole login returned no token")
+	}
+	return body.Auth.ClientToken, nil
+}
+
+func (p *vaultSecretProvider) Name() string {
+	return SecretBackendVault
+}
+
+// GetSecret reads the secret at the configured path and returns its name key
+func (p *vaultSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	token := p.token
+	p.mu.Unlock()
+
+	resp, err := p.read(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	// An expired AppRole token is rejected with 403; log in again and retry once
+	if resp.StatusCode == http.StatusForbidden && p.login != nil {
+		resp.Body.Close()
+		if token, err = p.login(ctx); err != nil {
+			return "", err
+		}
+		p.mu.Lock()
+		p.token = token
+		p.mu.Unlock()
+
+		if resp, err = p.read(ctx, token); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, p.path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV version 2 nests the secret's keys under data.data, next to data.metadata
+	data := body.Data
+	if nested, ok := data["data"]; ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("failed to decode vault secret data: %w", err)
+			}
+		}
+	}
+
+	raw, ok := data[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found in vault path %s", name, p.path)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret %s is not a string", name)
+	}
+	return value, nil
+}
+
+// read requests the KV secret at the configured path
+func (p *vaultSecretProvider) read(ctx context.Context, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", p.path, err)
+	}
+	return resp, nil
+}
+
+func (p *vaultSecretProvider) Close() error {
+	return nil
+}
+
+// awsSecretProvider reads secrets named <prefix><name> from AWS Secrets Manager, signing requests
+// with Signature Version 4
+type awsSecretProvider struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	prefix          string
+	endpoint        string
+	httpClient      *http.Client
+	now             func() time.Time
+}
+
+func newAWSSecretProvider(region, accessKeyID, secretAccessKey, sessionToken, prefix, endpoint string) (*awsSecretProvider, error) {
+	if region == "" || accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws secret backend")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+	return &awsSecretProvider{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		prefix:          prefix,
+		endpoint:        strings.TrimRight(endpoint, "/") + "/",
+		httpClient:      &http.Client{Timeout: secretRequestTimeout},
+		now:             time.Now,
+	}, nil
+}
+
+func (p *awsSecretProvider) Name() string {
+	return SecretBackendAWS
+}
+
+// GetSecret calls GetSecretValue for the prefixed secret name
+func (p *awsSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	secretID := p.prefix + name
+	payload, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create secrets manager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload, p.now().UTC())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", secretID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&awsErr)
+		return "", fmt.Errorf("secrets manager returned status %d for %s: %s", resp.StatusCode, secretID, awsErr.Type)
+	}
+
+	var result struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+	if result.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", secretID)
+	}
+	return *result.SecretString, nil
+}
+
+// sign adds the Signature Version 4 headers for a Secrets Manager request
+func (p *awsSecretProvider) sign(req *http.Request, payload []byte, at time.Time) {
+	amzDate := at.Format("20060102T150405Z")
+	date := at.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.sessionToken)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	scope := date + "/" + p.region + "/secretsmanager/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+p.secretAccessKey), date)
+	key = hmacSHA256(key, p.region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKeyID, scope, signedHeaders, signature))
+}
+
+func (p *awsSecretProvider) Close() error {
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSecretProvider struct {
+	secrets map[string]string
+}
+
+func (p *fakeSecretProvider) Name() string { return "fake" }
+
+func (p *fakeSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := p.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", name)
+	}
+	return value, nil
+}
+
+func (p *fakeSecretProvider) Close() error { return nil }
+
+func TestLoadFromSecretProvider(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("BASE_URL", "https://api.example.com")
+	t.Setenv("JWT_SECRET", "env-jwt-secret")
+	t.Setenv("STRAVA_CLIENT_SECRET", "env-strava-secret")
+	t.Setenv("ENCRYPTION_SECRET", "")
+
+	provider := &fakeSecretProvider{secrets: map[string]string{
+		"jwt-secret":        "store-jwt-secret",
+		"encryption-secret": "this-is-a-32-character-encryption-secret-key",
+	}}
+
+	config, err := loadFromSecretProvider(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("loadFromSecretProvider() failed: %v", err)
+	}
+	if config.JWTSecret != "store-jwt-secret" {
+		t.Errorf("Expected the stored JWT secret to win over the environment, got '%s'", config.JWTSecret)
+	}
+	if config.StravaClientSecret != "env-strava-secret" {
+		t.Errorf("Expected missing secrets to fall back to the environment, got '%s'", config.StravaClientSecret)
+	}
+	if config.SecretBackend != "fake" {
+		t.Errorf("Expected SecretBackend 'fake', got '%s'", config.SecretBackend)
+	}
+}
+
+func TestNewSecretProviderErrors(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	for _, backend := range []string{SecretBackendVault, SecretBackendAWS, "azure"} {
+		if _, err := NewSecretProvider(context.Background(), backend); err == nil {
+			t.Errorf("Expected an error for unconfigured backend %q", backend)
+		}
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"KV version 2", "secret/data/academy-sync", `{"data":{"data":{"jwt-secret":"vault-jwt"},"metadata":{"version":3}}}`},
+		{"KV version 1", "kv/academy-sync", `{"data":{"jwt-secret":"vault-jwt"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/"+tt.path || r.Header.Get("X-Vault-Token") != "test-token" || r.Header.Get("X-Vault-Namespace") != "team" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider, err := newVaultSecretProvider(server.URL, "test-token", tt.path, "team")
+			if err != nil {
+				t.Fatalf("newVaultSecretProvider() failed: %v", err)
+			}
+
+			value, err := provider.GetSecret(context.Background(), "jwt-secret")
+			if err != nil || value != "vault-jwt" {
+				t.Errorf("Expected 'vault-jwt', got '%s' (%v)", value, err)
+			}
+			if _, err := provider.GetSecret(context.Background(), "smtp-password"); err == nil {
+				t.Error("Expected an error for a key missing from the vault secret")
+			}
+		})
+	}
+}
+
+func TestVaultAppRoleProvider(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var req struct {
+				RoleID   string `json:"role_id"`
+				SecretID string `json:"secret_id"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RoleID != "role" || req.SecretID != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			logins++
+			fmt.Fprintf(w, `{"auth":{"client_token":"token-%d"}}`, logins)
+		case "/v1/secret/data/academy-sync":
+			// Only the newest token is valid, as if earlier ones had expired
+			if r.Header.Get("X-Vault-Token") != fmt.Sprintf("token-%d", logins) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"jwt-secret":"vault-jwt"},"metadata":{"version":1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := newVaultAppRoleProvider(context.Background(), server.URL, "approle", "role", "secret", "secret/data/academy-sync", "")
+	if err != nil {
+		t.Fatalf("newVaultAppRoleProvider() failed: %v", err)
+	}
+	if value, err := provider.GetSecret(context.Background(), "jwt-secret"); err != nil || value != "vault-jwt" {
+		t.Errorf("Expected 'vault-jwt', got '%s' (%v)", value, err)
+	}
+
+	// Expire the token: the provider logs in again and retries
+	logins++
+	if value, err := provider.GetSecret(context.Background(), "jwt-secret"); err != nil || value != "vault-jwt" {
+		t.Errorf("Expected a fresh login after the token expired, got '%s' (%v)", value, err)
+	}
+	if logins != 3 {
+		t.Errorf("Expected a second login, got %d logins", logins)
+	}
+
+	if _, err := newVaultAppRoleProvider(context.Background(), server.URL, "approle", "role", "wrong", "secret/data/academy-sync", ""); err == nil {
+		t.Error("Expected a rejected AppRole login to fail")
+	}
+}
+
+func TestAWSSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			SecretId string
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240620/eu-west-1/secretsmanager/aws4_request, ") ||
+			!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,") ||
+			r.Header.Get("X-Amz-Date") != "20240620T083000Z" || r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if req.SecretId != "academy-sync/jwt-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		w.Write([]byte(`{"Name":"academy-sync/jwt-secret","SecretString":"aws-jwt"}`))
+	}))
+	defer server.Close()
+
+	provider, err := newAWSSecretProvider("eu-west-1", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "session", "academy-sync/", server.URL)
+	if err != nil {
+		t.Fatalf("newAWSSecretProvider() failed: %v", err)
+	}
+	provider.now = func() time.Time { return time.Date(2024, 6, 20, 8, 30, 0, 0, time.UTC) }
+
+	value, err := provider.GetSecret(context.Background(), "jwt-secret")
+	if err != nil || value != "aws-jwt" {
+		t.Errorf("Expected 'aws-jwt', got '%s' (%v)", value, err)
+	}
+
+	_, err = provider.GetSecret(context.Background(), "smtp-password")
+	if err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,499 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Per-service settings are declared as struct fields tagged with the environment variable they are
+// read from and a default, e.g. `env:"ENGINE_JOB_TIMEOUT" default:"5m"`. Supported field types
+// are string, bool, int, time.Duration (Go duration syntax) and []string (comma-separated).
+
+// EngineConfig holds the automation engine settings
+type EngineConfig struct {
+	// WorkerCount is the number of jobs processed concurrently from the queue
+	WorkerCount int `json:"worker_count" env:"ENGINE_WORKER_COUNT" default:"1"`
+	// LookbackDays is the fetch window of a regular sync
+	LookbackDays int `json:"lookback_days" env:"ENGINE_LOOKBACK_DAYS" default:"7"`
+
+	JobTimeout         time.Duration `json:"job_timeout" env:"ENGINE_JOB_TIMEOUT" default:"5m"`
+	BackfillJobTimeout time.Duration `json:"backfill_job_timeout" env:"ENGINE_BACKFILL_JOB_TIMEOUT" default:"30m"`
+	// Per-step timeouts of a sync job, each capped by what is left of JobTimeout
+	ConfigFetchTimeout time.Duration `json:"config_fetch_timeout" env:"ENGINE_CONFIG_FETCH_TIMEOUT" default:"10s"`
+	StravaFetchTimeout time.Duration `json:"strava_fetch_timeout" env:"ENGINE_STRAVA_FETCH_TIMEOUT" default:"2m"`
+	SheetsWriteTimeout time.Duration `json:"sheets_write_timeout" env:"ENGINE_SHEETS_WRITE_TIMEOUT" default:"3m"`
+	// Caps on the jobs fetching from Strava and writing to Sheets at once, independent of
+	// WorkerCount; zero leaves a phase limited by WorkerCount only
+	StravaFetchConcurrency int `json:"strava_fetch_concurrency" env:"ENGINE_STRAVA_FETCH_CONCURRENCY" default:"0"`
+	SheetsWriteConcurrency int `json:"sheets_write_concurrency" env:"ENGINE_SHEETS_WRITE_CONCURRENCY" default:"0"`
+	// BackfillSlice is how long one backfill job imports monthly windows before queuing a job for
+	// the rest; it must leave the current window time to finish within BackfillJobTimeout
+	BackfillSlice time.Duration `json:"backfill_slice" env:"ENGINE_BACKFILL_SLICE" default:"8m"`
+	// QueuePollTimeout bounds each blocking dequeue so periodic work still runs when the queue is idle
+	QueuePollTimeout time.Duration `json:"queue_poll_timeout" env:"ENGINE_QUEUE_POLL_TIMEOUT" default:"5s"`
+
+	ReconciliationInterval  time.Duration `json:"reconciliation_interval" env:"ENGINE_RECONCILIATION_INTERVAL" default:"1h"`
+	ReconciliationBatchSize int           `json:"reconciliation_batch_size" env:"ENGINE_RECONCILIATION_BATCH_SIZE" default:"10"`
+
+	CircuitFailureThreshold int           `json:"circuit_failure_threshold" env:"ENGINE_CIRCUIT_FAILURE_THRESHOLD" default:"5"`
+	CircuitProbeInterval    time.Duration `json:"circuit_probe_interval" env:"ENGINE_CIRCUIT_PROBE_INTERVAL" default:"30s"`
+
+	// VerifyWrites reads back each written chunk of sheet rows and rewrites mismatched rows once
+	VerifyWrites bool `json:"verify_writes" env:"ENGINE_VERIFY_WRITES" default:"false"`
+
+	// Daily per-user budget of Strava and Google API calls, and of the Sheets writes among them;
+	// jobs beyond it are deferred to the user's next day. Zero disables a limit.
+	DailyProviderCallBudget int `json:"daily_provider_call_budget" env:"ENGINE_DAILY_PROVIDER_CALL_BUDGET" default:"1000"`
+	DailySheetsWriteBudget  int `json:"daily_sheets_write_budget" env:"ENGINE_DAILY_SHEETS_WRITE_BUDGET" default:"300"`
+	// DailyStravaCallBudget caps each user's Strava API calls per UTC day, enforced on every call
+	// because Strava's rate limits are shared by all users; zero disables it
+	DailyStravaCallBudget int `json:"daily_strava_call_budget" env:"ENGINE_DAILY_STRAVA_CALL_BUDGET" default:"200"`
+	// SheetsWritesPerMinute caps the Sheets write calls of all engine instances together, below
+	// the project's per-minute write quota; zero disables it
+	SheetsWritesPerMinute int `json:"sheets_writes_per_minute" env:"ENGINE_SHEETS_WRITES_PER_MINUTE" default:"240"`
+
+	// ResponseCacheTTL is how long Strava athlete profiles and spreadsheet metadata are reused
+	// across jobs; zero disables the cache
+	ResponseCacheTTL time.Duration `json:"response_cache_ttl" env:"ENGINE_RESPONSE_CACHE_TTL" default:"15m"`
+
+	// StravaCaptureBucket is the Cloud Storage bucket the raw Strava activity responses of each
+	// run are stored in for replay; empty disables the capture
+	StravaCaptureBucket string `json:"strava_capture_bucket" env:"ENGINE_STRAVA_CAPTURE_BUCKET" default:""`
+}
+
+// APIConfig holds the backend API server settings; a zero timeout disables it
+type APIConfig struct {
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout" env:"API_READ_HEADER_TIMEOUT" default:"10s"`
+	ReadTimeout       time.Duration `json:"read_timeout" env:"API_READ_TIMEOUT" default:"30s"`
+	WriteTimeout      time.Duration `json:"write_timeout" env:"API_WRITE_TIMEOUT" default:"0s"`
+	IdleTimeout       time.Duration `json:"idle_timeout" env:"API_IDLE_TIMEOUT" default:"2m"`
+
+	// OutboxRelayInterval is how often jobs in the outbox are published to the queue; published
+	// jobs are kept for OutboxRetention so they can be replayed
+	OutboxRelayInterval time.Duration `json:"outbox_relay_interval" env:"API_OUTBOX_RELAY_INTERVAL" default:"1s"`
+	OutboxRetention     time.Duration `json:"outbox_retention" env:"API_OUTBOX_RETENTION" default:"168h"`
+}
+
+// SessionConfig holds the backend API's cookie policy and session lifetimes, so deployments behind
+// other domains or proxies can adjust them without code changes
+type SessionConfig struct {
+	// CookieDomain is the Domain attribute of the API's cookies; auto uses .localhost in local
+	// development, so the UI and API share cookies across ports, and the API's host elsewhere
+	CookieDomain string `json:"cookie_domain" env:"SESSION_COOKIE_DOMAIN" default:"auto"`
+	// CookieSecure is true, false or auto, which marks cookies Secure outside local development
+	CookieSecure string `json:"cookie_secure" env:"SESSION_COOKIE_SECURE" default:"auto"`
+	// CookieSameSite is lax, strict or none; lax lets the session cookie follow OAuth redirects
+	CookieSameSite string `json:"cookie_same_site" env:"SESSION_COOKIE_SAMESITE" default:"lax"`
+	// TTL is how long a session and its refresh token cookie last without use; each use extends
+	// the session to TTL from then, up to MaxLifetime
+	TTL time.Duration `json:"ttl" env:"SESSION_TTL" default:"24h"`
+	// MaxLifetime is the absolute lifetime of a session however actively it is used; zero keeps
+	// sessions at TTL from sign-in
+	MaxLifetime time.Duration `json:"max_lifetime" env:"SESSION_MAX_LIFETIME" default:"168h"`
+	// AccessTokenTTL is the lifetime of the access token authenticating each request; the
+	// browser exchanges its refresh token for a new one
+	AccessTokenTTL time.Duration `json:"access_token_ttl" env:"SESSION_ACCESS_TOKEN_TTL" default:"15m"`
+	// RefreshThreshold is how close to expiry an access token must be before a refresh issues a
+	// new one; refreshes of newer tokens keep the current one
+	RefreshThreshold time.Duration `json:"refresh_threshold" env:"SESSION_REFRESH_THRESHOLD" default:"24h"`
+}
+
+// Signup policies selectable with SIGNUP_POLICY
+const (
+	SignupPolicyOpen      = "open"
+	SignupPolicyAllowlist = "allowlist"
+	SignupPolicyInvite    = "invite"
+)
+
+// SignupConfig decides who may create an account on their first Google sign-in; existing users
+// always sign in
+type SignupConfig struct {
+	// Policy is open (anyone), allowlist (verified emails in AllowedDomains) or invite (a
+	// single-use invite code created by an admin)
+	Policy string `json:"policy" env:"SIGNUP_POLICY" default:"open"`
+	// AllowedDomains are the email domains that may sign up under the allowlist policy
+	AllowedDomains []string `json:"allowed_domains" env:"SIGNUP_ALLOWED_DOMAINS" default:""`
+}
+
+// NotifierConfig holds the notification service settings
+type NotifierConfig struct {
+	// PollInterval is how often finished runs are checked for notifications
+	PollInterval              time.Duration `json:"poll_interval" env:"NOTIFIER_POLL_INTERVAL" default:"30s"`
+	DigestCheckInterval       time.Duration `json:"digest_check_interval" env:"NOTIFIER_DIGEST_CHECK_INTERVAL" default:"5m"`
+	QuietFailureCheckInterval time.Duration `json:"quiet_failure_check_interval" env:"NOTIFIER_QUIET_FAILURE_CHECK_INTERVAL" default:"1h"`
+}
+
+// RetentionConfig holds how long automation runs and audit events (sign-ins and role changes) are
+// kept. The automation engine prunes older rows, exporting them first when ArchiveBucket is set.
+type RetentionConfig struct {
+	// Period is the age at which rows are pruned; zero keeps them forever
+	Period        time.Duration `json:"period" env:"RETENTION_PERIOD" default:"4320h"`
+	PruneInterval time.Duration `json:"prune_interval" env:"RETENTION_PRUNE_INTERVAL" default:"24h"`
+	// BatchSize is the number of rows exported and deleted at a time
+	BatchSize int `json:"batch_size" env:"RETENTION_BATCH_SIZE" default:"1000"`
+	// ArchiveBucket is the Cloud Storage bucket pruned rows are exported to as newline-delimited
+	// JSON; empty deletes them without an export
+	ArchiveBucket string `json:"archive_bucket" env:"RETENTION_ARCHIVE_BUCKET" default:""`
+}
+
+// ProviderConfig holds the Strava and Google endpoints. Defaults are the production APIs;
+// staging can point them at mock servers, corporate proxies or provider sandboxes.
+type ProviderConfig struct {
+	StravaAPIBaseURL   string `json:"strava_api_base_url" env:"STRAVA_API_BASE_URL" default:"https://www.strava.com/api/v3"`
+	StravaOAuthBaseURL string `json:"strava_oauth_base_url" env:"STRAVA_OAUTH_BASE_URL" default:"https://www.strava.com/oauth"`
+
+	GoogleAuthURL          string `json:"google_auth_url" env:"GOOGLE_AUTH_URL" default:"https://accounts.google.com/o/oauth2/auth"`
+	GoogleTokenURL         string `json:"google_token_url" env:"GOOGLE_TOKEN_URL" default:"https://oauth2.googleapis.com/token"`
+	GoogleRevokeURL        string `json:"google_revoke_url" env:"GOOGLE_REVOKE_URL" default:"https://oauth2.googleapis.com/revoke"`
+	GoogleOAuth2APIBaseURL string `json:"google_oauth2_api_base_url" env:"GOOGLE_OAUTH2_API_BASE_URL" default:"https://www.googleapis.com/oauth2"`
+	GoogleSheetsBaseURL    string `json:"google_sheets_base_url" env:"GOOGLE_SHEETS_BASE_URL" default:"https://sheets.googleapis.com/"`
+	GoogleDriveBaseURL     string `json:"google_drive_base_url" env:"GOOGLE_DRIVE_BASE_URL" default:"https://www.googleapis.com/drive/v3/"`
+
+	// StubURL points every provider endpoint above at a devstub server (cmd/devstub), so the
+	// stack runs without real OAuth apps; not allowed in production
+	StubURL string `json:"stub_url" env:"PROVIDER_STUB_URL" default:""`
+
+	// VerifyCredentials probes the Google and Strava token endpoints at startup and stops the
+	// service when they reject the OAuth client ID or secret
+	VerifyCredentials bool `json:"verify_credentials" env:"PROVIDER_VERIFY_CREDENTIALS" default:"false"`
+}
+
+// Paths the devstub server (internal/pkg/devstub) serves each provider endpoint under
+const (
+	StubStravaAPIPath       = "/strava/api/v3"
+	StubStravaOAuthPath     = "/strava/oauth"
+	StubGoogleAuthPath      = "/google/auth"
+	StubGoogleTokenPath     = "/google/token"
+	StubGoogleRevokePath    = "/google/revoke"
+	StubGoogleOAuth2APIPath = "/google/oauth2"
+	StubGoogleSheetsPath    = "/sheets/"
+	StubGoogleDrivePath     = "/drive/v3/"
+)
+
+// useStub replaces every provider endpoint with its path on the devstub server at StubURL
+func (p *ProviderConfig) useStub() {
+	base := strings.TrimRight(p.StubURL, "/")
+	p.StravaAPIBaseURL = base + StubStravaAPIPath
+	p.StravaOAuthBaseURL = base + StubStravaOAuthPath
+	p.GoogleAuthURL = base + StubGoogleAuthPath
+	p.GoogleTokenURL = base + StubGoogleTokenPath
+	p.GoogleRevokeURL = base + StubGoogleRevokePath
+	p.GoogleOAuth2APIBaseURL = base + StubGoogleOAuth2APIPath
+	p.GoogleSheetsBaseURL = base + StubGoogleSheetsPath
+	p.GoogleDriveBaseURL = base + StubGoogleDrivePath
+}
+
+// DatabaseConfig holds the PostgreSQL connection pool settings shared by every service
+type DatabaseConfig struct {
+	// MaxOpenConns caps the connections in use and idle; zero means unlimited
+	MaxOpenConns int `json:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	// MaxIdleConns is the number of idle connections kept for reuse
+	MaxIdleConns    int           `json:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"5"`
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	// StatsInterval is how often the pool statistics are logged; zero disables the logging
+	StatsInterval time.Duration `json:"stats_interval" env:"DB_STATS_INTERVAL" default:"0s"`
+}
+
+// RedisConfig holds the Redis connection pool, shared by every Redis user of a process
+type RedisConfig struct {
+	// PoolSize caps the connections per process; zero uses the client default of 10 per CPU
+	PoolSize int `json:"pool_size" env:"REDIS_POOL_SIZE" default:"0"`
+	// MinIdleConns is the number of idle connections kept open for reuse
+	MinIdleConns int `json:"min_idle_conns" env:"REDIS_MIN_IDLE_CONNS" default:"0"`
+	// PoolTimeout is how long a command waits for a free connection when all are in use; zero
+	// uses the client default of the read timeout plus a second
+	PoolTimeout time.Duration `json:"pool_timeout" env:"REDIS_POOL_TIMEOUT" default:"0s"`
+	DialTimeout time.Duration `json:"dial_timeout" env:"REDIS_DIAL_TIMEOUT" default:"5s"`
+	// ConnMaxIdleTime closes connections idle for longer; zero keeps them open
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time" env:"REDIS_CONN_MAX_IDLE_TIME" default:"30m"`
+}
+
+// SecretsConfig holds the secret rotation settings, used when loading from a secret backend
+type SecretsConfig struct {
+	// ReloadInterval is how often rotatable secrets are re-fetched; zero disables periodic reloads
+	ReloadInterval time.Duration `json:"reload_interval" env:"SECRET_RELOAD_INTERVAL" default:"0s"`
+}
+
+// LoggingConfig holds the log output settings; the level is LOG_LEVEL (Config.LogLevel)
+type LoggingConfig struct {
+	// Format is json (Cloud Logging structured entries) or console; empty picks console in
+	// local development and json elsewhere
+	Format string `json:"format" env:"LOG_FORMAT" default:""`
+	// DebugSampleBurst is the number of DEBUG entries with the same message written per
+	// DebugSampleInterval; the rest are dropped. Zero writes every entry.
+	DebugSampleBurst    int           `json:"debug_sample_burst" env:"LOG_DEBUG_SAMPLE_BURST" default:"20"`
+	DebugSampleInterval time.Duration `json:"debug_sample_interval" env:"LOG_DEBUG_SAMPLE_INTERVAL" default:"1s"`
+	// RedactFields names additional log attributes whose values are never written; emails,
+	// spreadsheet IDs and tokens are always redacted
+	RedactFields []string `json:"redact_fields" env:"LOG_REDACT_FIELDS" default:""`
+	// ErrorReporting adds the Cloud Error Reporting fields to ERROR and CRITICAL JSON entries and
+	// links trace_id attributes to Cloud Trace in GCP_PROJECT_ID
+	ErrorReporting bool `json:"error_reporting" env:"LOG_ERROR_REPORTING" default:"false"`
+	// ServiceVersion is the version errors are reported under; Cloud Run's K_REVISION when unset
+	ServiceVersion string `json:"service_version" env:"SERVICE_VERSION" default:""`
+}
+
+// DiagnosticsConfig holds the runtime diagnostics server, which serves pprof profiles, goroutine
+// and heap dumps and build information on a port separate from the service's own
+type DiagnosticsConfig struct {
+	Enabled bool `json:"enabled" env:"DIAGNOSTICS_ENABLED" default:"false"`
+	// Addr is the listen address; the endpoints are unauthenticated, so it should stay on
+	// localhost or an internal interface
+	Addr string `json:"addr" env:"DIAGNOSTICS_ADDR" default:"127.0.0.1:6060"`
+}
+
+// loadServiceSections loads the per-service sections from the environment and checks their ranges
+func (c *Config) loadServiceSections() error {
+	var errs []string
+	for _, section := range []interface{}{&c.Engine, &c.API, &c.Session, &c.Signup, &c.Notifier, &c.Retention, &c.Database, &c.Redis, &c.Providers, &c.Secrets, &c.Logging, &c.Diagnostics} {
+		if err := loadSection(section); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		if c.Providers.StubURL != "" {
+			c.Providers.useStub()
+		}
+		errs = c.validateServiceSections()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid service settings: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// loadSection sets each tagged field of the struct dst points to from its environment variable,
+// or from its default when the variable is unset. Every malformed value is reported.
+func loadSection(dst interface{}) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	var errs []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		raw, set := os.LookupEnv(key)
+		if !set || raw == "" {
+			raw = field.Tag.Get("default")
+		}
+		if err := setField(v.Field(i), strings.TrimSpace(raw)); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// setField parses raw into a field of a supported type
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		if d < 0 {
+			return fmt.Errorf("duration must not be negative")
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", field.Type())
+		}
+		field.Set(reflect.ValueOf(parseList(raw)))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// validateServiceSections checks the ranges of the per-service settings
+func (c *Config) validateServiceSections() []string {
+	var errs []string
+	if c.Engine.WorkerCount < 1 {
+		errs = append(errs, "ENGINE_WORKER_COUNT must be at least 1")
+	}
+	if c.Engine.LookbackDays < 1 {
+		errs = append(errs, "ENGINE_LOOKBACK_DAYS must be at least 1")
+	}
+	if c.Engine.ReconciliationBatchSize < 1 {
+		errs = append(errs, "ENGINE_RECONCILIATION_BATCH_SIZE must be at least 1")
+	}
+	if c.Engine.CircuitFailureThreshold < 1 {
+		errs = append(errs, "ENGINE_CIRCUIT_FAILURE_THRESHOLD must be at least 1")
+	}
+	if c.Engine.DailyProviderCallBudget < 0 || c.Engine.DailySheetsWriteBudget < 0 {
+		errs = append(errs, "ENGINE_DAILY_PROVIDER_CALL_BUDGET and ENGINE_DAILY_SHEETS_WRITE_BUDGET must not be negative")
+	}
+	if c.Engine.DailyStravaCallBudget < 0 {
+		errs = append(errs, "ENGINE_DAILY_STRAVA_CALL_BUDGET must not be negative")
+	}
+	if c.Engine.StravaFetchConcurrency < 0 || c.Engine.SheetsWriteConcurrency < 0 {
+		errs = append(errs, "ENGINE_STRAVA_FETCH_CONCURRENCY and ENGINE_SHEETS_WRITE_CONCURRENCY must not be negative")
+	}
+	if c.Engine.SheetsWritesPerMinute < 0 {
+		errs = append(errs, "ENGINE_SHEETS_WRITES_PER_MINUTE must not be negative")
+	}
+	if c.Engine.ResponseCacheTTL < 0 {
+		errs = append(errs, "ENGINE_RESPONSE_CACHE_TTL must not be negative")
+	}
+	if c.Engine.BackfillSlice >= c.Engine.BackfillJobTimeout && c.Engine.BackfillJobTimeout > 0 {
+		errs = append(errs, "ENGINE_BACKFILL_SLICE must be shorter than ENGINE_BACKFILL_JOB_TIMEOUT")
+	}
+	switch c.Session.CookieSecure {
+	case "auto", "true", "false":
+	default:
+		errs = append(errs, "SESSION_COOKIE_SECURE must be auto, true or false")
+	}
+	switch c.Session.CookieSameSite {
+	case "lax", "strict":
+	case "none":
+		// Browsers drop SameSite=None cookies that are not Secure
+		if c.Session.CookieSecure != "true" {
+			errs = append(errs, "SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE=true")
+		}
+	default:
+		errs = append(errs, "SESSION_COOKIE_SAMESITE must be lax, strict or none")
+	}
+	switch c.Signup.Policy {
+	case SignupPolicyOpen, SignupPolicyInvite:
+	case SignupPolicyAllowlist:
+		if len(c.Signup.AllowedDomains) == 0 {
+			errs = append(errs, "SIGNUP_ALLOWED_DOMAINS is required when SIGNUP_POLICY is allowlist")
+		}
+	default:
+		errs = append(errs, "SIGNUP_POLICY must be open, allowlist or invite")
+	}
+	if c.Session.RefreshThreshold > c.Session.TTL {
+		errs = append(errs, "SESSION_REFRESH_THRESHOLD must not exceed SESSION_TTL")
+	}
+	if c.Session.AccessTokenTTL > c.Session.TTL {
+		errs = append(errs, "SESSION_ACCESS_TOKEN_TTL must not exceed SESSION_TTL")
+	}
+	if c.Session.MaxLifetime != 0 && c.Session.MaxLifetime < c.Session.TTL {
+		errs = append(errs, "SESSION_MAX_LIFETIME must be zero or at least SESSION_TTL")
+	}
+	if c.Retention.BatchSize < 1 {
+		errs = append(errs, "RETENTION_BATCH_SIZE must be at least 1")
+	}
+	if c.Redis.PoolSize < 0 || c.Redis.MinIdleConns < 0 {
+		errs = append(errs, "REDIS_POOL_SIZE and REDIS_MIN_IDLE_CONNS must not be negative")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, "DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	} else if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "", "json", "console":
+	default:
+		errs = append(errs, "LOG_FORMAT must be json or console")
+	}
+	if c.Diagnostics.Enabled {
+		if _, _, err := net.SplitHostPort(c.Diagnostics.Addr); err != nil {
+			errs = append(errs, "DIAGNOSTICS_ADDR must be a host:port address")
+		}
+	}
+	if c.Logging.DebugSampleBurst < 0 {
+		errs = append(errs, "LOG_DEBUG_SAMPLE_BURST must not be negative")
+	} else if c.Logging.DebugSampleBurst > 0 && c.Logging.DebugSampleInterval <= 0 {
+		errs = append(errs, "LOG_DEBUG_SAMPLE_INTERVAL must be greater than zero when LOG_DEBUG_SAMPLE_BURST is set")
+	}
+
+	required := map[string]time.Duration{
+		"ENGINE_JOB_TIMEOUT":                    c.Engine.JobTimeout,
+		"ENGINE_BACKFILL_JOB_TIMEOUT":           c.Engine.BackfillJobTimeout,
+		"ENGINE_CONFIG_FETCH_TIMEOUT":           c.Engine.ConfigFetchTimeout,
+		"ENGINE_STRAVA_FETCH_TIMEOUT":           c.Engine.StravaFetchTimeout,
+		"ENGINE_SHEETS_WRITE_TIMEOUT":           c.Engine.SheetsWriteTimeout,
+		"ENGINE_BACKFILL_SLICE":                 c.Engine.BackfillSlice,
+		"ENGINE_QUEUE_POLL_TIMEOUT":             c.Engine.QueuePollTimeout,
+		"ENGINE_RECONCILIATION_INTERVAL":        c.Engine.ReconciliationInterval,
+		"ENGINE_CIRCUIT_PROBE_INTERVAL":         c.Engine.CircuitProbeInterval,
+		"API_OUTBOX_RELAY_INTERVAL":             c.API.OutboxRelayInterval,
+		"SESSION_TTL":                           c.Session.TTL,
+		"SESSION_ACCESS_TOKEN_TTL":              c.Session.AccessTokenTTL,
+		"SESSION_REFRESH_THRESHOLD":             c.Session.RefreshThreshold,
+		"NOTIFIER_POLL_INTERVAL":                c.Notifier.PollInterval,
+		"NOTIFIER_DIGEST_CHECK_INTERVAL":        c.Notifier.DigestCheckInterval,
+		"NOTIFIER_QUIET_FAILURE_CHECK_INTERVAL": c.Notifier.QuietFailureCheckInterval,
+		"RETENTION_PRUNE_INTERVAL":              c.Retention.PruneInterval,
+	}
+	var missing []string
+	for key, d := range required {
+		if d <= 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		errs = append(errs, strings.Join(missing, ", ")+" must be greater than zero")
+	}
+
+	return append(errs, c.validateProviderEndpoints()...)
+}
+
+// validateProviderEndpoints checks that every provider endpoint is an absolute URL. Production
+// only talks to providers over HTTPS; plain HTTP is allowed elsewhere for local mock servers.
+func (c *Config) validateProviderEndpoints() []string {
+	endpoints := []struct {
+		key   string
+		value string
+	}{
+		{"STRAVA_API_BASE_URL", c.Providers.StravaAPIBaseURL},
+		{"STRAVA_OAUTH_BASE_URL", c.Providers.StravaOAuthBaseURL},
+		{"GOOGLE_AUTH_URL", c.Providers.GoogleAuthURL},
+		{"GOOGLE_TOKEN_URL", c.Providers.GoogleTokenURL},
+		{"GOOGLE_REVOKE_URL", c.Providers.GoogleRevokeURL},
+		{"GOOGLE_OAUTH2_API_BASE_URL", c.Providers.GoogleOAuth2APIBaseURL},
+		{"GOOGLE_SHEETS_BASE_URL", c.Providers.GoogleSheetsBaseURL},
+		{"GOOGLE_DRIVE_BASE_URL", c.Providers.GoogleDriveBaseURL},
+	}
+
+	var errs []string
+	if c.Providers.StubURL != "" && c.IsProduction() {
+		errs = append(errs, "PROVIDER_STUB_URL must not be set in production")
+	}
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint.value)
+		switch {
+		case err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http"):
+			errs = append(errs, fmt.Sprintf("%s must be an absolute http(s) URL", endpoint.key))
+		case u.Scheme != "https" && c.IsProduction():
+			errs = append(errs, fmt.Sprintf("%s must use https in production", endpoint.key))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadServiceSections(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var c Config
+		if err := c.loadServiceSections(); err != nil {
+			t.Fatalf("loadServiceSections() failed: %v", err)
+		}
+		if c.Engine.WorkerCount != 1 || c.Engine.LookbackDays != 7 || c.Engine.JobTimeout != 5*time.Minute {
+			t.Errorf("Unexpected engine defaults: %+v", c.Engine)
+		}
+		if c.Engine.DailyProviderCallBudget != 1000 || c.Engine.DailySheetsWriteBudget != 300 {
+			t.Errorf("Unexpected daily budget defaults: %+v", c.Engine)
+		}
+		if c.API.ReadHeaderTimeout != 10*time.Second || c.API.WriteTimeout != 0 || c.API.OutboxRelayInterval != time.Second {
+			t.Errorf("Unexpected API defaults: %+v", c.API)
+		}
+		if c.Session.CookieDomain != "auto" || c.Session.CookieSameSite != "lax" || c.Session.TTL != 24*time.Hour || c.Session.AccessTokenTTL != 15*time.Minute || c.Session.MaxLifetime != 7*24*time.Hour {
+			t.Errorf("Unexpected session defaults: %+v", c.Session)
+		}
+		if c.Signup.Policy != SignupPolicyOpen || len(c.Signup.AllowedDomains) != 0 {
+			t.Errorf("Unexpected signup defaults: %+v", c.Signup)
+		}
+		if c.Notifier.PollInterval != 30*time.Second || c.Notifier.QuietFailureCheckInterval != time.Hour {
+			t.Errorf("Unexpected notifier defaults: %+v", c.Notifier)
+		}
+		if c.Retention.Period != 180*24*time.Hour || c.Retention.PruneInterval != 24*time.Hour || c.Retention.BatchSize != 1000 || c.Retention.ArchiveBucket != "" {
+			t.Errorf("Unexpected retention defaults: %+v", c.Retention)
+		}
+		if c.Database.MaxOpenConns != 25 || c.Database.MaxIdleConns != 5 || c.Database.ConnMaxLifetime != 30*time.Minute || c.Database.StatsInterval != 0 {
+			t.Errorf("Unexpected database defaults: %+v", c.Database)
+		}
+		if c.Redis.PoolSize != 0 || c.Redis.DialTimeout != 5*time.Second || c.Redis.ConnMaxIdleTime != 30*time.Minute {
+			t.Errorf("Unexpected Redis defaults: %+v", c.Redis)
+		}
+		if c.Logging.Format != "" || c.Logging.DebugSampleBurst != 20 || c.Logging.DebugSampleInterval != time.Second {
+			t.Errorf("Unexpected logging defaults: %+v", c.Logging)
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		t.Setenv("ENGINE_WORKER_COUNT", "4")
+		t.Setenv("ENGINE_BACKFILL_JOB_TIMEOUT", "1h30m")
+		t.Setenv("NOTIFIER_DIGEST_CHECK_INTERVAL", "90s")
+
+		var c Config
+		if err := c.loadServiceSections(); err != nil {
+			t.Fatalf("loadServiceSections() failed: %v", err)
+		}
+		if c.Engine.WorkerCount != 4 || c.Engine.BackfillJobTimeout != 90*time.Minute || c.Notifier.DigestCheckInterval != 90*time.Second {
+			t.Errorf("Expected environment values, got %+v %+v", c.Engine, c.Notifier)
+		}
+	})
+
+	t.Run("malformed and out of range values", func(t *testing.T) {
+		t.Setenv("ENGINE_JOB_TIMEOUT", "5")
+		t.Setenv("ENGINE_LOOKBACK_DAYS", "week")
+		t.Setenv("API_IDLE_TIMEOUT", "-1s")
+
+		var c Config
+		err := c.loadServiceSections()
+		if err == nil {
+			t.Fatal("Expected malformed values to be rejected")
+		}
+		for _, want := range []string{"ENGINE_JOB_TIMEOUT", "ENGINE_LOOKBACK_DAYS", "API_IDLE_TIMEOUT"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected the error to name %s, got %v", want, err)
+			}
+		}
+
+		t.Setenv("ENGINE_JOB_TIMEOUT", "")
+		t.Setenv("ENGINE_LOOKBACK_DAYS", "")
+		t.Setenv("API_IDLE_TIMEOUT", "")
+		t.Setenv("ENGINE_WORKER_COUNT", "0")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "ENGINE_WORKER_COUNT must be at least 1") {
+			t.Errorf("Expected a range error, got %v", err)
+		}
+
+		t.Setenv("ENGINE_WORKER_COUNT", "")
+		t.Setenv("DB_MAX_OPEN_CONNS", "4")
+		t.Setenv("DB_MAX_IDLE_CONNS", "8")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS") {
+			t.Errorf("Expected a pool size error, got %v", err)
+		}
+
+		t.Setenv("DB_MAX_OPEN_CONNS", "")
+		t.Setenv("DB_MAX_IDLE_CONNS", "")
+		t.Setenv("ENGINE_BACKFILL_SLICE", "2h")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "ENGINE_BACKFILL_SLICE must be shorter than ENGINE_BACKFILL_JOB_TIMEOUT") {
+			t.Errorf("Expected a backfill slice error, got %v", err)
+		}
+
+		t.Setenv("ENGINE_BACKFILL_SLICE", "")
+		t.Setenv("SESSION_COOKIE_SAMESITE", "none")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE=true") {
+			t.Errorf("Expected a SameSite error, got %v", err)
+		}
+
+		t.Setenv("SESSION_COOKIE_SAMESITE", "")
+		t.Setenv("SESSION_REFRESH_THRESHOLD", "48h")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "SESSION_REFRESH_THRESHOLD must not exceed SESSION_TTL") {
+			t.Errorf("Expected a refresh threshold error, got %v", err)
+		}
+
+		t.Setenv("SESSION_REFRESH_THRESHOLD", "")
+		t.Setenv("SESSION_MAX_LIFETIME", "12h")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "SESSION_MAX_LIFETIME must be zero or at least SESSION_TTL") {
+			t.Errorf("Expected a max lifetime error, got %v", err)
+		}
+
+		t.Setenv("SESSION_MAX_LIFETIME", "")
+		t.Setenv("SIGNUP_POLICY", "allowlist")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "SIGNUP_ALLOWED_DOMAINS is required when SIGNUP_POLICY is allowlist") {
+			t.Errorf("Expected an allowlist error, got %v", err)
+		}
+		t.Setenv("SIGNUP_POLICY", "closed")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "SIGNUP_POLICY must be open, allowlist or invite") {
+			t.Errorf("Expected a signup policy error, got %v", err)
+		}
+
+		t.Setenv("SIGNUP_POLICY", "")
+		t.Setenv("LOG_FORMAT", "pretty")
+		if err := c.loadServiceSections(); err == nil || !strings.Contains(err.Error(), "LOG_FORMAT must be json or console") {
+			t.Errorf("Expected a log format error, got %v", err)
+		}
+	})
+}
+
+func TestProviderEndpoints(t *testing.T) {
+	t.Run("defaults are the production APIs", func(t *testing.T) {
+		var c Config
+		if err := c.loadServiceSections(); err != nil {
+			t.Fatalf("loadServiceSections() failed: %v", err)
+		}
+		if c.Providers.StravaAPIBaseURL != "https://www.strava.com/api/v3" || c.Providers.GoogleTokenURL != "https://oauth2.googleapis.com/token" || c.Providers.VerifyCredentials {
+			t.Errorf("Unexpected provider defaults: %+v", c.Providers)
+		}
+	})
+
+	t.Run("mock servers outside production", func(t *testing.T) {
+		t.Setenv("STRAVA_API_BASE_URL", "http://localhost:9090/strava/api/v3")
+		t.Setenv("GOOGLE_SHEETS_BASE_URL", "http://localhost:9090/sheets/")
+
+		c := Config{Environment: "staging"}
+		if err := c.loadServiceSections(); err != nil {
+			t.Fatalf("loadServiceSections() failed: %v", err)
+		}
+		if c.Providers.StravaAPIBaseURL != "http://localhost:9090/strava/api/v3" {
+			t.Errorf("Expected the Strava override, got %q", c.Providers.StravaAPIBaseURL)
+		}
+
+		c = Config{Environment: "production"}
+		err := c.loadServiceSections()
+		if err == nil || !strings.Contains(err.Error(), "STRAVA_API_BASE_URL must use https in production") {
+			t.Errorf("Expected plain HTTP to be refused in production, got %v", err)
+		}
+	})
+
+	t.Run("devstub server", func(t *testing.T) {
+		t.Setenv("PROVIDER_STUB_URL", "http://localhost:9090/")
+		t.Setenv("STRAVA_API_BASE_URL", "https://strava.example.com/api/v3")
+
+		var c Config
+		if err := c.loadServiceSections(); err != nil {
+			t.Fatalf("loadServiceSections() failed: %v", err)
+		}
+		if c.Providers.StravaAPIBaseURL != "http://localhost:9090/strava/api/v3" ||
+			c.Providers.GoogleTokenURL != "http://localhost:9090/google/token" ||
+			c.Providers.GoogleSheetsBaseURL != "http://localhost:9090/sheets/" {
+			t.Errorf("Expected every endpoint on the stub server, got %+v", c.Providers)
+		}
+
+		c = Config{Environment: "production"}
+		err := c.loadServiceSections()
+		if err == nil || !strings.Contains(err.Error(), "PROVIDER_STUB_URL must not be set in production") {
+			t.Errorf("Expected the stub server to be refused in production, got %v", err)
+		}
+	})
+
+	t.Run("relative URL", func(t *testing.T) {
+		t.Setenv("GOOGLE_TOKEN_URL", "/token")
+
+		var c Config
+		err := c.loadServiceSections()
+		if err == nil || !strings.Contains(err.Error(), "GOOGLE_TOKEN_URL must be an absolute http(s) URL") {
+			t.Errorf("Expected a relative URL to be rejected, got %v", err)
+		}
+	})
+}
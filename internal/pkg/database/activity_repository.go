@@ -0,0 +1,321 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// DefaultActivityCacheMaxAge is how long a cached activity window is served without re-fetching from Strava
+const DefaultActivityCacheMaxAge = 15 * time.Minute
+
+// DefaultGearCacheMaxAge is how long a cached gear lookup is used before re-fetching it from Strava.
+// Gear names rarely change, but the mileage Strava reports grows with every activity.
+const DefaultGearCacheMaxAge = 24 * time.Hour
+
+// Sources of cached activities
+const (
+	ActivitySourceStrava = "strava"
+	ActivitySourceManual = "manual"
+)
+
+// ActivityRepository handles the local cache of activities fetched from Strava
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+// NewActivityRepository creates a new activity cache repository
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// StoreFetchedActivities caches the result of fetching a user's activities started in [from, to).
+// Activities are upserted by Strava ID and cached activities in the range that Strava no longer
+// returned are removed. When the range reaches fetchedAt the user's cache window is extended, so
+// later reads within the freshness policy can skip Strava.
+func (r *ActivityRepository) StoreFetchedActivities(ctx context.Context, userID int, from, to, fetchedAt time.Time, activities []strava.Activity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin activity cache transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsertQuery := `
+		INSERT INTO activities (
+			strava_id, user_id, name, type, sport_type, distance, moving_time, elapsed_time,
+			total_elevation_gain, start_date, start_date_local, timezone, average_speed, max_speed,
+			average_heartrate, max_heartrate, kudos, comments, gear_id, fetched_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (strava_id) DO UPDATE SET
+			name = EXCLUDED.name, type = EXCLUDED.type, sport_type = EXCLUDED.sport_type,
+			distance = EXCLUDED.distance, moving_time = EXCLUDED.moving_time, elapsed_time = EXCLUDED.elapsed_time,
+			total_elevation_gain = EXCLUDED.total_elevation_gain, start_date = EXCLUDED.start_date,
+			start_date_local = EXCLUDED.start_date_local, timezone = EXCLUDED.timezone,
+			average_speed = EXCLUDED.average_speed, max_speed = EXCLUDED.max_speed,
+			average_heartrate = EXCLUDED.average_heartrate, max_heartrate = EXCLUDED.max_heartrate,
+			kudos = EXCLUDED.kudos, comments = EXCLUDED.comments, gear_id = EXCLUDED.gear_id,
+			fetched_at = EXCLUDED.fetched_at
+		WHERE activities.user_id = EXCLUDED.user_id
+	`
+
+	ids := make([]int64, 0, len(activities))
+	for _, activity := range activities {
+		_, err := tx.ExecContext(ctx, upsertQuery,
+			activity.ID, userID, activity.Name, activity.Type, activity.SportType,
+			activity.Distance, activity.MovingTime, activity.ElapsedTime, activity.TotalElevationGain,
+			activity.StartDate, activity.StartDateLocal, activity.Timezone,
+			activity.AverageSpeed, activity.MaxSpeed, activity.AverageHeartrate, activity.MaxHeartrate,
+			activity.Kudos, activity.Comments, activity.GearID, fetchedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upsert activity %d: %w", activity.ID, err)
+		}
+		ids = append(ids, activity.ID)
+	}
+
+	// Activities deleted on Strava disappear from the fetch, so drop them from the cache too.
+	// Manual activities are never returned by Strava and stay.
+	deleteQuery := `
+		DELETE FROM activities
+		WHERE user_id = $1 AND start_date >= $2 AND start_date < $3 AND source = 'strava'
+			AND NOT (strava_id = ANY($4))
+	`
+	if _, err := tx.ExecContext(ctx, deleteQuery, userID, from, to, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to remove deleted activities from cache: %w", err)
+	}
+
+	if !to.Before(fetchedAt) {
+		// The new window joins the previous one only if it starts before the previous refresh;
+		// otherwise there may be a gap and coverage restarts at from
+		stateQuery := `
+			INSERT INTO activity_cache_state (user_id, covered_from, refreshed_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO UPDATE SET
+				covered_from = CASE
+					WHEN EXCLUDED.covered_from <= activity_cache_state.refreshed_at
+					THEN LEAST(activity_cache_state.covered_from, EXCLUDED.covered_from)
+					ELSE EXCLUDED.covered_from
+				END,
+				refreshed_at = EXCLUDED.refreshed_at
+		`
+		if _, err := tx.ExecContext(ctx, stateQuery, userID, from, fetchedAt); err != nil {
+			return fmt.Errorf("failed to update activity cache state: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit activity cache transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IsCacheFresh reports whether the cache holds every activity the user started since from and was
+// refreshed from Strava within maxAge. Older activities rarely change, so only the refresh time of
+// the window as a whole is considered.
+func (r *ActivityRepository) IsCacheFresh(ctx context.Context, userID int, from time.Time, maxAge time.Duration) (bool, error) {
+	query := `
+		SELECT covered_from, refreshed_at
+		FROM activity_cache_state
+		WHERE user_id = $1
+	`
+
+	var coveredFrom, refreshedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&coveredFrom, &refreshedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !from.Before(coveredFrom) && time.Since(refreshedAt) <= maxAge, nil
+}
+
+// GetCacheRefreshedAt returns when the user's cached activities were last refreshed from Strava, or nil
+func (r *ActivityRepository) GetCacheRefreshedAt(ctx context.Context, userID int) (*time.Time, error) {
+	query := `
+		SELECT refreshed_at
+		FROM activity_cache_state
+		WHERE user_id = $1
+	`
+
+	var refreshedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&refreshedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &refreshedAt, nil
+}
+
+// GetActivitiesInRange returns the user's cached activities started in [from, to), oldest first
+func (r *ActivityRepository) GetActivitiesInRange(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	query := `
+		SELECT strava_id, name, type, sport_type, distance, moving_time, elapsed_time,
+			total_elevation_gain, start_date, start_date_local, timezone, average_speed, max_speed,
+			average_heartrate, max_heartrate, kudos, comments, gear_id, source
+		FROM activities
+		WHERE user_id = $1 AND start_date >= $2 AND start_date < $3
+		ORDER BY start_date ASC
+	`
+
+	return r.queryActivities(ctx, query, userID, from, to)
+}
+
+// GetManualActivities returns the activities the user logged by hand started in [from, to), oldest first
+func (r *ActivityRepository) GetManualActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	query := `
+		SELECT strava_id, name, type, sport_type, distance, moving_time, elapsed_time,
+			total_elevation_gain, start_date, start_date_local, timezone, average_speed, max_speed,
+			average_heartrate, max_heartrate, kudos, comments, gear_id, source
+		FROM activities
+		WHERE user_id = $1 AND start_date >= $2 AND start_date < $3 AND source = 'manual'
+		ORDER BY start_date ASC
+	`
+
+	return r.queryActivities(ctx, query, userID, from, to)
+}
+
+// queryActivities scans the activities selected by query
+func (r *ActivityRepository) queryActivities(ctx context.Context, query string, args ...interface{}) ([]strava.Activity, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []strava.Activity
+	for rows.Next() {
+		var activity strava.Activity
+		err := rows.Scan(
+			&activity.ID, &activity.Name, &activity.Type, &activity.SportType,
+			&activity.Distance, &activity.MovingTime, &activity.ElapsedTime, &activity.TotalElevationGain,
+			&activity.StartDate, &activity.StartDateLocal, &activity.Timezone,
+			&activity.AverageSpeed, &activity.MaxSpeed, &activity.AverageHeartrate, &activity.MaxHeartrate,
+			&activity.Kudos, &activity.Comments, &activity.GearID, &activity.Source,
+		)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, rows.Err()
+}
+
+// CreateManualActivity stores an activity the user logged by hand, filling in its ID and source.
+// Manual activities are numbered with negated IDs from their own sequence, so they never collide
+// with Strava activities, and are written to the sheet by the next run that covers their start.
+func (r *ActivityRepository) CreateManualActivity(ctx context.Context, userID int, activity *strava.Activity) error {
+	query := `
+		INSERT INTO activities (
+			strava_id, user_id, source, name, type, sport_type, distance, moving_time, elapsed_time,
+			total_elevation_gain, start_date, start_date_local, timezone, average_speed, max_speed,
+			average_heartrate, max_heartrate, fetched_at
+		) VALUES (-nextval('manual_activity_id_seq'), $1, 'manual', $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING strava_id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		userID, activity.Name, activity.Type, activity.SportType,
+		activity.Distance, activity.MovingTime, activity.ElapsedTime, activity.TotalElevationGain,
+		activity.StartDate, activity.StartDateLocal, activity.Timezone,
+		activity.AverageSpeed, activity.MaxSpeed, activity.AverageHeartrate, activity.MaxHeartrate,
+		time.Now(),
+	).Scan(&activity.ID)
+	if err != nil {
+		return fmt.Errorf("failed to store manual activity: %w", err)
+	}
+
+	activity.Source = ActivitySourceManual
+	return nil
+}
+
+// GetCachedGear returns the user's cached gear among ids fetched within maxAge, keyed by gear ID
+func (r *ActivityRepository) GetCachedGear(ctx context.Context, userID int, ids []string, maxAge time.Duration) (map[string]strava.Gear, error) {
+	query := `
+		SELECT gear_id, name, brand_name, model_name, distance, retired
+		FROM gear
+		WHERE user_id = $1 AND gear_id = ANY($2) AND fetched_at > $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(ids), time.Now().Add(-maxAge))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gear := make(map[string]strava.Gear)
+	for rows.Next() {
+		var g strava.Gear
+		if err := rows.Scan(&g.ID, &g.Name, &g.BrandName, &g.ModelName, &g.Distance, &g.Retired); err != nil {
+			return nil, err
+		}
+		gear[g.ID] = g
+	}
+
+	return gear, rows.Err()
+}
+
+// StoreGear caches a gear lookup for the user
+func (r *ActivityRepository) StoreGear(ctx context.Context, userID int, gear strava.Gear, fetchedAt time.Time) error {
+	query := `
+		INSERT INTO gear (user_id, gear_id, name, brand_name, model_name, distance, retired, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, gear_id) DO UPDATE SET
+			name = EXCLUDED.name, brand_name = EXCLUDED.brand_name, model_name = EXCLUDED.model_name,
+			distance = EXCLUDED.distance, retired = EXCLUDED.retired, fetched_at = EXCLUDED.fetched_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, gear.ID, gear.Name, gear.BrandName, gear.ModelName, gear.Distance, gear.Retired, fetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store gear %s: %w", gear.ID, err)
+	}
+	return nil
+}
+
+// GetGearMileage returns the user's cached gear with the cached activities recorded with each,
+// active gear first and most recently used first
+func (r *ActivityRepository) GetGearMileage(ctx context.Context, userID int) ([]GearMileage, error) {
+	query := `
+		SELECT g.gear_id, g.name, g.brand_name, g.model_name, g.distance, g.retired, g.fetched_at,
+			COUNT(a.strava_id), COALESCE(SUM(a.distance), 0), MAX(a.start_date)
+		FROM gear g
+		LEFT JOIN activities a ON a.user_id = g.user_id AND a.gear_id = g.gear_id
+		WHERE g.user_id = $1
+		GROUP BY g.user_id, g.gear_id
+		ORDER BY g.retired, MAX(a.start_date) DESC NULLS LAST, g.gear_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mileage := []GearMileage{}
+	for rows.Next() {
+		var gear GearMileage
+		var lastUsed sql.NullTime
+		err := rows.Scan(&gear.GearID, &gear.Name, &gear.BrandName, &gear.ModelName, &gear.Distance, &gear.Retired,
+			&gear.FetchedAt, &gear.ActivityCount, &gear.SyncedDistance, &lastUsed)
+		if err != nil {
+			return nil, err
+		}
+		if lastUsed.Valid {
+			gear.LastUsedAt = &lastUsed.Time
+		}
+		mileage = append(mileage, gear)
+	}
+
+	return mileage, rows.Err()
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestStoreFetchedActivities(t *testing.T) {
+	now := time.Now()
+	from := now.AddDate(0, 0, -7)
+	activities := []strava.Activity{
+		{ID: 101, Name: "Morning Run", Type: "Run", StartDate: now.Add(-time.Hour)},
+		{ID: 102, Name: "Evening Ride", Type: "Ride", StartDate: now.Add(-2 * time.Hour)},
+	}
+
+	t.Run("WindowReachesNow", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO activities").WithArgs(
+			int64(101), 7, "Morning Run", "Run", "", 0.0, 0, 0, 0.0, sqlmock.AnyArg(), sqlmock.AnyArg(), "",
+			0.0, 0.0, 0.0, 0.0, 0, 0, "", now,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO activities").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM activities").
+			WithArgs(7, from, now, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO activity_cache_state").
+			WithArgs(7, from, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := NewActivityRepository(db)
+		if err := repo.StoreFetchedActivities(context.Background(), 7, from, now, now, activities); err != nil {
+			t.Fatalf("StoreFetchedActivities failed: %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("HistoricalRangeDoesNotExtendWindow", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		to := now.AddDate(0, 0, -1)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM activities").
+			WithArgs(7, from, to, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		repo := NewActivityRepository(db)
+		if err := repo.StoreFetchedActivities(context.Background(), 7, from, to, now, nil); err != nil {
+			t.Fatalf("StoreFetchedActivities failed: %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestIsCacheFresh(t *testing.T) {
+	now := time.Now()
+	coveredFrom := now.AddDate(0, 0, -14)
+
+	tests := []struct {
+		name        string
+		from        time.Time
+		refreshedAt time.Time
+		expected    bool
+	}{
+		{"Fresh window", now.AddDate(0, 0, -7), now.Add(-time.Minute), true},
+		{"Range starts before window", now.AddDate(0, 0, -30), now.Add(-time.Minute), false},
+		{"Stale window", now.AddDate(0, 0, -7), now.Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectQuery("SELECT covered_from, refreshed_at FROM activity_cache_state").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"covered_from", "refreshed_at"}).AddRow(coveredFrom, tt.refreshedAt))
+
+			fresh, err := NewActivityRepository(db).IsCacheFresh(context.Background(), 7, tt.from, DefaultActivityCacheMaxAge)
+			if err != nil {
+				t.Fatalf("IsCacheFresh failed: %v", err)
+			}
+			if fresh != tt.expected {
+				t.Errorf("Expected fresh=%v, got %v", tt.expected, fresh)
+			}
+		})
+	}
+
+	t.Run("NoCacheState", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT covered_from, refreshed_at FROM activity_cache_state").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"covered_from", "refreshed_at"}))
+
+		fresh, err := NewActivityRepository(db).IsCacheFresh(context.Background(), 7, now, DefaultActivityCacheMaxAge)
+		if err != nil || fresh {
+			t.Errorf("Expected stale cache without error, got fresh=%v err=%v", fresh, err)
+		}
+	})
+}
+
+func TestGetActivitiesInRange(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	start := from.Add(48 * time.Hour)
+
+	columns := []string{"strava_id", "name", "type", "sport_type", "distance", "moving_time", "elapsed_time",
+		"total_elevation_gain", "start_date", "start_date_local", "timezone", "average_speed", "max_speed",
+		"average_heartrate", "max_heartrate", "kudos", "comments", "gear_id", "source"}
+	mock.ExpectQuery("SELECT strava_id, name, type").
+		WithArgs(7, from, to).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(101), "Morning Run", "Run", "Run", 5000.0, 1500, 1600, 12.0, start, start, "UTC", 3.3, 4.1, 150.0, 170.0, 3, 1, "g1001", "strava"))
+
+	activities, err := NewActivityRepository(db).GetActivitiesInRange(context.Background(), 7, from, to)
+	if err != nil {
+		t.Fatalf("GetActivitiesInRange failed: %v", err)
+	}
+	if len(activities) != 1 || activities[0].ID != 101 || activities[0].Distance != 5000 || activities[0].Kudos != 3 || activities[0].GearID != "g1001" {
+		t.Errorf("Unexpected activities: %+v", activities)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGearCache(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewActivityRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO gear").
+		WithArgs(7, "g1001", "Daily Trainers", "Acme", "Glide 3", 412000.0, false, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	gear := strava.Gear{ID: "g1001", Name: "Daily Trainers", BrandName: "Acme", ModelName: "Glide 3", Distance: 412000}
+	if err := repo.StoreGear(context.Background(), 7, gear, now); err != nil {
+		t.Fatalf("StoreGear failed: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT gear_id, name, brand_name, model_name, distance, retired FROM gear").
+		WithArgs(7, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"gear_id", "name", "brand_name", "model_name", "distance", "retired"}).
+			AddRow("g1001", "Daily Trainers", "Acme", "Glide 3", 412000.0, false))
+	cached, err := repo.GetCachedGear(context.Background(), 7, []string{"g1001", "b2002"}, DefaultGearCacheMaxAge)
+	if err != nil {
+		t.Fatalf("GetCachedGear failed: %v", err)
+	}
+	if len(cached) != 1 || cached["g1001"].Name != "Daily Trainers" {
+		t.Errorf("Unexpected cached gear: %+v", cached)
+	}
+
+	lastUsed := now.Add(-24 * time.Hour)
+	mock.ExpectQuery("FROM gear g LEFT JOIN activities a").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"gear_id", "name", "brand_name", "model_name", "distance", "retired",
+			"fetched_at", "count", "sum", "max"}).
+			AddRow("g1001", "Daily Trainers", "Acme", "Glide 3", 412000.0, false, now, 12, 96000.0, lastUsed).
+			AddRow("g0999", "Old Racers", "Acme", "Fly 1", 780000.0, true, now, 0, 0.0, nil))
+	mileage, err := repo.GetGearMileage(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetGearMileage failed: %v", err)
+	}
+	if len(mileage) != 2 || mileage[0].ActivityCount != 12 || mileage[0].LastUsedAt == nil || mileage[1].LastUsedAt != nil {
+		t.Errorf("Unexpected gear mileage: %+v", mileage)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestCreateManualActivity(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2024, 6, 12, 4, 0, 0, 0, time.UTC)
+	activity := &strava.Activity{Name: "Treadmill", Type: "Run", SportType: "Run", Distance: 8000, MovingTime: 2400,
+		ElapsedTime: 2400, StartDate: start, StartDateLocal: start.Add(3 * time.Hour), AverageSpeed: 8000.0 / 2400}
+
+	mock.ExpectQuery("INSERT INTO activities .* VALUES \\(-nextval\\('manual_activity_id_seq'\\), \\$1, 'manual'").
+		WithArgs(7, "Treadmill", "Run", "Run", 8000.0, 2400, 2400, 0.0, start, start.Add(3*time.Hour), "",
+			8000.0/2400, 0.0, 0.0, 0.0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"strava_id"}).AddRow(int64(-3)))
+
+	if err := NewActivityRepository(db).CreateManualActivity(context.Background(), 7, activity); err != nil {
+		t.Fatalf("CreateManualActivity failed: %v", err)
+	}
+	if activity.ID != -3 || activity.Source != ActivitySourceManual {
+		t.Errorf("Expected the stored activity to get its ID and the manual source, got %+v", activity)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// apiTokenTouchInterval limits how often authenticated requests update a token's last_used_at
+const apiTokenTouchInterval = time.Minute
+
+// APITokenRepository handles database operations for personal access tokens
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// CreateAPIToken stores token, filling in its ID and creation time
+func (r *APITokenRepository) CreateAPIToken(ctx context.Context, token *APIToken) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.Name, token.TokenHash, token.TokenPrefix, pq.Array(token.Scopes), token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+}
+
+// ListAPITokens returns the user's tokens that have not been revoked, newest first
+// Expired tokens are included so the user can see and delete them
+func (r *APITokenRepository) ListAPITokens(ctx context.Context, userID int) ([]APIToken, error) {
+	query := `
+		SELECT id, name, token_prefix, scopes, created_at, last_used_at, expires_at
+		FROM api_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token := APIToken{UserID: userID}
+		var lastUsedAt, expiresAt sql.NullTime
+		if err := rows.Scan(&token.ID, &token.Name, &token.TokenPrefix, pq.Array(&token.Scopes), &token.CreatedAt, &lastUsedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			token.LastUsedAt = &lastUsedAt.Time
+		}
+		if expiresAt.Valid {
+			token.ExpiresAt = &expiresAt.Time
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// GetActiveAPIToken returns the unrevoked, unexpired token with the given hash together with its
+// owner's email and whether the owner is suspended, or nil when there is none
+func (r *APITokenRepository) GetActiveAPIToken(ctx context.Context, tokenHash string) (*APIToken, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.token_prefix, t.scopes, t.created_at, t.last_used_at, t.expires_at, u.email,
+			u.suspended_at IS NOT NULL
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1
+		AND t.revoked_at IS NULL
+		AND (t.expires_at IS NULL OR t.expires_at > NOW())
+	`
+
+	token := APIToken{TokenHash: tokenHash}
+	var lastUsedAt, expiresAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, pq.Array(&token.Scopes),
+		&token.CreatedAt, &lastUsedAt, &expiresAt, &token.Email, &token.OwnerSuspended)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return &token, nil
+}
+
+// TouchAPIToken records that the token was used. Scripts may call the API in tight loops, so the
+// timestamp is only written when it is more than apiTokenTouchInterval old.
+func (r *APITokenRepository) TouchAPIToken(ctx context.Context, id int) error {
+	query := `
+		UPDATE api_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1
+		AND (last_used_at IS NULL OR last_used_at < NOW() - $2 * INTERVAL '1 second')
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, int(apiTokenTouchInterval.Seconds()))
+	return err
+}
+
+// RevokeAPIToken revokes one of the user's tokens, returning sql.ErrNoRows when the user has no
+// such unrevoked token
+func (r *APITokenRepository) RevokeAPIToken(ctx context.Context, userID, id int) error {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAPITokenRepository_CreateAndGetActive(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(30 * 24 * time.Hour)
+
+	mock.ExpectQuery("INSERT INTO api_tokens").
+		WithArgs(7, "cron sync", "hash", "asy_AbCdEfGh", sqlmock.AnyArg(), &expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+
+	mock.ExpectQuery("SELECT (.+) FROM api_tokens t JOIN users u").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "token_prefix", "scopes", "created_at", "last_used_at", "expires_at", "email", "suspended"}).
+			AddRow(3, 7, "cron sync", "asy_AbCdEfGh", "{read,sync}", now, nil, expiresAt, "runner@example.com", true))
+
+	repo := NewAPITokenRepository(db)
+	token := &APIToken{UserID: 7, Name: "cron sync", TokenHash: "hash", TokenPrefix: "asy_AbCdEfGh", Scopes: []string{"read", "sync"}, ExpiresAt: &expiresAt}
+	if err := repo.CreateAPIToken(context.Background(), token); err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+	if token.ID != 3 || !token.CreatedAt.Equal(now) {
+		t.Errorf("Expected the ID and creation time to be filled in, got %+v", token)
+	}
+
+	active, err := repo.GetActiveAPIToken(context.Background(), "hash")
+	if err != nil {
+		t.Fatalf("GetActiveAPIToken failed: %v", err)
+	}
+	if active == nil || active.UserID != 7 || active.Email != "runner@example.com" || len(active.Scopes) != 2 || active.Scopes[1] != "sync" {
+		t.Errorf("Unexpected token: %+v", active)
+	}
+	if active.LastUsedAt != nil || active.ExpiresAt == nil {
+		t.Errorf("Expected only the expiry to be set, got %+v", active)
+	}
+	if !active.OwnerSuspended {
+		t.Errorf("Expected the owner's suspension to be reported, got %+v", active)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAPITokenRepository_GetActiveNotFound(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_tokens t JOIN users u").
+		WithArgs("revoked").
+		WillReturnError(sql.ErrNoRows)
+
+	token, err := NewAPITokenRepository(db).GetActiveAPIToken(context.Background(), "revoked")
+	if err != nil || token != nil {
+		t.Errorf("Expected no token and no error, got %+v, %v", token, err)
+	}
+}
+
+func TestAPITokenRepository_Revoke(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE api_tokens SET revoked_at = NOW\\(\\)").
+		WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Another user's token, or one already revoked, is not found
+	mock.ExpectExec("UPDATE api_tokens SET revoked_at = NOW\\(\\)").
+		WithArgs(3, 8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewAPITokenRepository(db)
+	if err := repo.RevokeAPIToken(context.Background(), 7, 3); err != nil {
+		t.Fatalf("RevokeAPIToken failed: %v", err)
+	}
+	if err := repo.RevokeAPIToken(context.Background(), 8, 3); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAPITokenRepository_TouchIsThrottled(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE api_tokens SET last_used_at = NOW\\(\\) WHERE id = \\$1 AND \\(last_used_at IS NULL OR last_used_at < NOW\\(\\) - \\$2").
+		WithArgs(3, 60).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := NewAPITokenRepository(db).TouchAPIToken(context.Background(), 3); err != nil {
+		t.Fatalf("TouchAPIToken failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// GetAutomationStatus returns the user's latest real run (test-mode and dry runs are left out),
+// when the scheduler next runs them and whether either provider needs re-authorization. It
+// returns sql.ErrNoRows for an unknown user.
+func (r *UserRepository) GetAutomationStatus(ctx context.Context, userID int) (*AutomationStatus, error) {
+	query := `
+		SELECT u.next_run_at,
+		       COALESCE(u.strava_reauth_required, false),
+		       COALESCE(u.google_reauth_required, false),
+		       lr.status, lr.trigger_type, lr.activities_count, lr.error_type, lr.started_at, lr.completed_at
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT ar.status, ar.trigger_type, ar.activities_count, ar.error_type, ar.started_at, ar.completed_at
+			FROM automation_runs ar
+			WHERE ar.user_id = u.id AND NOT ar.is_test_mode AND NOT ar.dry_run
+			ORDER BY ar.started_at DESC, ar.id DESC
+			LIMIT 1
+		) lr ON true
+		WHERE u.id = $1
+	`
+
+	var (
+		status          AutomationStatus
+		runStatus       *string
+		triggerType     *string
+		activitiesCount *int
+		errorType       *string
+		startedAt       *time.Time
+		completedAt     *time.Time
+	)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&status.NextRunAt, &status.StravaReauthRequired, &status.GoogleReauthRequired,
+		&runStatus, &triggerType, &activitiesCount, &errorType, &startedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if runStatus != nil {
+		status.LastRun = &LastRunSummary{
+			Status:      *runStatus,
+			TriggerType: *triggerType,
+			StartedAt:   *startedAt,
+			CompletedAt: completedAt,
+			ErrorType:   errorType,
+		}
+		if activitiesCount != nil {
+			status.LastRun.ActivitiesCount = *activitiesCount
+		}
+	}
+	return &status, nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserRepository_GetAutomationStatus(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, nil)
+
+	nextRunAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	startedAt := time.Date(2026, 10, 16, 0, 1, 0, 0, time.UTC)
+	completedAt := startedAt.Add(time.Minute)
+	columns := []string{"next_run_at", "strava_reauth_required", "google_reauth_required",
+		"status", "trigger_type", "activities_count", "error_type", "started_at", "completed_at"}
+
+	mock.ExpectQuery("LEFT JOIN LATERAL").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(nextRunAt, true, false, RunStatusCompleted, "schedule", 3, nil, startedAt, completedAt))
+	// A user who never synced
+	mock.ExpectQuery("LEFT JOIN LATERAL").
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(nil, false, false, nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("LEFT JOIN LATERAL").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	status, err := repo.GetAutomationStatus(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetAutomationStatus failed: %v", err)
+	}
+	if status.LastRun == nil || status.LastRun.Status != RunStatusCompleted || status.LastRun.ActivitiesCount != 3 || !status.LastRun.StartedAt.Equal(startedAt) {
+		t.Errorf("Unexpected last run: %+v", status.LastRun)
+	}
+	if status.NextRunAt == nil || !status.NextRunAt.Equal(nextRunAt) || !status.StravaReauthRequired || status.GoogleReauthRequired {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	status, err = repo.GetAutomationStatus(context.Background(), 8)
+	if err != nil {
+		t.Fatalf("GetAutomationStatus failed: %v", err)
+	}
+	if status.LastRun != nil || status.NextRunAt != nil {
+		t.Errorf("Expected no runs, got %+v", status)
+	}
+
+	if _, err := repo.GetAutomationStatus(context.Background(), 9); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// BackfillRepository handles database operations for historical import checkpoints
+type BackfillRepository struct {
+	db *sql.DB
+}
+
+// NewBackfillRepository creates a new backfill checkpoint repository
+func NewBackfillRepository(db *sql.DB) *BackfillRepository {
+	return &BackfillRepository{db: db}
+}
+
+// ListCompletedWindows returns the user's completed backfill windows starting at or after from, oldest first
+func (r *BackfillRepository) ListCompletedWindows(ctx context.Context, userID int, from time.Time) ([]BackfillWindow, error) {
+	query := `
+		SELECT user_id, window_start, window_end, activity_count, type_counts, completed_at
+		FROM backfill_windows
+		WHERE user_id = $1 AND window_start >= $2
+		ORDER BY window_start ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []BackfillWindow
+	for rows.Next() {
+		var window BackfillWindow
+		var typeCounts []byte
+		if err := rows.Scan(&window.UserID, &window.WindowStart, &window.WindowEnd, &window.ActivityCount, &typeCounts, &window.CompletedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(typeCounts, &window.TypeCounts); err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+// RecordWindow stores a completed window, replacing an earlier checkpoint for the same window start
+func (r *BackfillRepository) RecordWindow(ctx context.Context, window *BackfillWindow) error {
+	query := `
+		INSERT INTO backfill_windows (user_id, window_start, window_end, activity_count, type_counts, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, window_start) DO UPDATE SET
+			window_end = EXCLUDED.window_end,
+			activity_count = EXCLUDED.activity_count,
+			type_counts = EXCLUDED.type_counts,
+			completed_at = EXCLUDED.completed_at
+	`
+
+	typeCounts, err := json.Marshal(window.TypeCounts)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query, window.UserID, window.WindowStart, window.WindowEnd, window.ActivityCount, typeCounts, window.CompletedAt)
+	return err
+}
+
+// ClearWindows deletes the user's checkpoints so the next backfill re-imports every window
+func (r *BackfillRepository) ClearWindows(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM backfill_windows WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestBackfillRepository_RecordAndListWindows(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	completedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO backfill_windows").
+		WithArgs(7, start, end, 12, []byte(`{"Ride":2,"Run":10}`), completedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery("SELECT user_id, window_start, window_end, activity_count, type_counts, completed_at FROM backfill_windows").
+		WithArgs(7, start).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "window_start", "window_end", "activity_count", "type_counts", "completed_at"}).
+			AddRow(7, start, end, 12, []byte(`{"Ride":2,"Run":10}`), completedAt))
+
+	repo := NewBackfillRepository(db)
+	err := repo.RecordWindow(context.Background(), &BackfillWindow{
+		UserID:        7,
+		WindowStart:   start,
+		WindowEnd:     end,
+		ActivityCount: 12,
+		TypeCounts:    map[string]int{"Run": 10, "Ride": 2},
+		CompletedAt:   completedAt,
+	})
+	if err != nil {
+		t.Fatalf("RecordWindow failed: %v", err)
+	}
+
+	windows, err := repo.ListCompletedWindows(context.Background(), 7, start)
+	if err != nil {
+		t.Fatalf("ListCompletedWindows failed: %v", err)
+	}
+	if len(windows) != 1 || windows[0].TypeCounts["Run"] != 10 || !windows[0].WindowEnd.Equal(end) {
+		t.Errorf("Unexpected windows: %+v", windows)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
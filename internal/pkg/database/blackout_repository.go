@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// BlackoutRepository handles database operations for operator-declared blackout windows
+type BlackoutRepository struct {
+	db *sql.DB
+}
+
+// NewBlackoutRepository creates a new blackout window repository
+func NewBlackoutRepository(db *sql.DB) *BlackoutRepository {
+	return &BlackoutRepository{db: db}
+}
+
+// CreateBlackoutWindow stores window, filling in its ID and creation time
+func (r *BlackoutRepository) CreateBlackoutWindow(ctx context.Context, window *BlackoutWindow) error {
+	query := `
+		INSERT INTO blackout_windows (starts_at, ends_at, reason, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, window.StartsAt, window.EndsAt, window.Reason, window.CreatedBy).
+		Scan(&window.ID, &window.CreatedAt)
+}
+
+// ListBlackoutWindows returns the windows ending after after, earliest first
+func (r *BlackoutRepository) ListBlackoutWindows(ctx context.Context, after time.Time) ([]BlackoutWindow, error) {
+	query := `
+		SELECT id, starts_at, ends_at, reason, created_by, created_at
+		FROM blackout_windows
+		WHERE ends_at > $1
+		ORDER BY starts_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []BlackoutWindow{}
+	for rows.Next() {
+		var window BlackoutWindow
+		var createdBy sql.NullInt64
+		if err := rows.Scan(&window.ID, &window.StartsAt, &window.EndsAt, &window.Reason, &createdBy, &window.CreatedAt); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			id := int(createdBy.Int64)
+			window.CreatedBy = &id
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+// GetActiveBlackout returns the blackout in effect at at, or nil when there is none.
+// Windows that overlap or adjoin it are merged, so EndsAt is when syncing may resume.
+func (r *BlackoutRepository) GetActiveBlackout(ctx context.Context, at time.Time) (*BlackoutWindow, error) {
+	windows, err := r.ListBlackoutWindows(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+	return ActiveBlackout(windows, at), nil
+}
+
+// ActiveBlackout finds the window covering at among windows sorted by start, extending its end
+// through the windows that overlap or adjoin it. It returns nil when no window covers at.
+func ActiveBlackout(windows []BlackoutWindow, at time.Time) *BlackoutWindow {
+	var active *BlackoutWindow
+	for _, window := range windows {
+		switch {
+		case active == nil && window.Active(at):
+			merged := window
+			active = &merged
+		case active != nil && !window.StartsAt.After(active.EndsAt) && window.EndsAt.After(active.EndsAt):
+			active.EndsAt = window.EndsAt
+		}
+	}
+	return active
+}
+
+// DeleteBlackoutWindow removes a window, ending it early if it is in effect
+func (r *BlackoutRepository) DeleteBlackoutWindow(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM blackout_windows WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestBlackoutRepository_CreateAndGetActive(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	createdBy := 1
+	columns := []string{"id", "starts_at", "ends_at", "reason", "created_by", "created_at"}
+
+	mock.ExpectQuery("INSERT INTO blackout_windows").
+		WithArgs(now.Add(-time.Hour), now.Add(time.Hour), "Strava maintenance", &createdBy).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+
+	mock.ExpectQuery("SELECT id, starts_at, ends_at, reason, created_by, created_at FROM blackout_windows").
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, now.Add(-time.Hour), now.Add(time.Hour), "Strava maintenance", 1, now).
+			AddRow(4, now.Add(time.Hour), now.Add(2*time.Hour), "Deploy", nil, now).
+			AddRow(5, now.Add(5*time.Hour), now.Add(6*time.Hour), "Later", nil, now))
+
+	repo := NewBlackoutRepository(db)
+	window := &BlackoutWindow{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Reason: "Strava maintenance", CreatedBy: &createdBy}
+	if err := repo.CreateBlackoutWindow(context.Background(), window); err != nil {
+		t.Fatalf("CreateBlackoutWindow failed: %v", err)
+	}
+	if window.ID != 3 {
+		t.Errorf("Expected the window ID to be filled in, got %d", window.ID)
+	}
+
+	active, err := repo.GetActiveBlackout(context.Background(), now)
+	if err != nil {
+		t.Fatalf("GetActiveBlackout failed: %v", err)
+	}
+	// The adjoining deploy window extends the blackout; the later one does not
+	if active == nil || active.ID != 3 || !active.EndsAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("Unexpected active blackout: %+v", active)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestActiveBlackout_NoneInEffect(t *testing.T) {
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	windows := []BlackoutWindow{{ID: 1, StartsAt: now.Add(time.Minute), EndsAt: now.Add(time.Hour)}}
+
+	if active := ActiveBlackout(windows, now); active != nil {
+		t.Errorf("Expected no blackout before the window starts, got %+v", active)
+	}
+	if active := ActiveBlackout(windows, now.Add(time.Hour)); active != nil {
+		t.Errorf("Expected the window to end at its end time, got %+v", active)
+	}
+}
+
+func TestBlackoutRepository_DeleteMissing(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM blackout_windows").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := NewBlackoutRepository(db).DeleteBlackoutWindow(context.Background(), 9)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
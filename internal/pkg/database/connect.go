@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+// Open creates the PostgreSQL connection pool for DATABASE_URL with the configured pool
+// settings. Like sql.Open it does not connect; the first query or Ping does.
+func Open(cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	ConfigurePool(db, cfg.Database)
+	return db, nil
+}
+
+// Connect opens the connection pool and verifies the database is reachable. Every service
+// obtains its pool here so pool behavior is the same across services.
+func Connect(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+// ConfigurePool applies the pool limits to db
+func ConfigurePool(db *sql.DB, pool config.DatabaseConfig) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
+
+// PoolStats is a snapshot of the connection pool, as reported by sql.DB.Stats
+type PoolStats struct {
+	MaxOpenConnections int `json:"max_open_connections"`
+
+	OpenConnections int `json:"open_connections"`
+	InUse           int `json:"in_use"`
+	Idle            int `json:"idle"`
+
+	// Counters since the pool was opened
+	WaitCount         int64         `json:"wait_count"`
+	WaitDuration      time.Duration `json:"wait_duration_ns"`
+	MaxIdleClosed     int64         `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64         `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64         `json:"max_lifetime_closed"`
+}
+
+// Stats returns the current pool statistics of db
+func Stats(db *sql.DB) PoolStats {
+	s := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDuration:       s.WaitDuration,
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
+
+// LogValues returns the statistics as key/value pairs for a log entry
+func (s PoolStats) LogValues() []interface{} {
+	return []interface{}{
+		"max_open_connections", s.MaxOpenConnections,
+		"open_connections", s.OpenConnections,
+		"in_use", s.InUse,
+		"idle", s.Idle,
+		"wait_count", s.WaitCount,
+		"wait_duration_ms", s.WaitDuration.Milliseconds(),
+		"max_idle_closed", s.MaxIdleClosed,
+		"max_idle_time_closed", s.MaxIdleTimeClosed,
+		"max_lifetime_closed", s.MaxLifetimeClosed,
+	}
+}
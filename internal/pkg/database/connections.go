@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetConnections returns the status of the user's Strava and Google Sheets connections.
+// It returns sql.ErrNoRows for an unknown user.
+func (r *UserRepository) GetConnections(ctx context.Context, userID int) ([]Connection, error) {
+	query := `
+		SELECT COALESCE(length(u.strava_access_token), 0) > 0,
+		       u.strava_athlete_name,
+		       u.strava_scopes,
+		       u.strava_token_expiry,
+		       COALESCE(u.strava_reauth_required, false),
+		       COALESCE(length(u.google_refresh_token), 0) > 0,
+		       u.email,
+		       u.google_scopes,
+		       u.google_token_expiry,
+		       COALESCE(u.google_reauth_required, false),
+		       u.spreadsheet_id,
+		       (SELECT MAX(ar.completed_at) FROM automation_runs ar
+		        WHERE ar.user_id = u.id AND ar.status = $2
+		          AND NOT ar.is_test_mode AND NOT ar.dry_run)
+		FROM users u
+		WHERE u.id = $1
+	`
+
+	var (
+		strava, google             Connection
+		hasStravaToken, hasGoogle  bool
+		googleEmail                string
+		stravaScopes, googleScopes []string
+		lastSyncAt                 *time.Time
+	)
+	err := r.db.QueryRowContext(ctx, query, userID, RunStatusCompleted).Scan(
+		&hasStravaToken,
+		&strava.AccountName,
+		pq.Array(&stravaScopes),
+		&strava.TokenExpiresAt,
+		&strava.ReauthRequired,
+		&hasGoogle,
+		&googleEmail,
+		pq.Array(&googleScopes),
+		&google.TokenExpiresAt,
+		&google.ReauthRequired,
+		&google.SpreadsheetID,
+		&lastSyncAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	strava.Provider = ProviderStrava
+	strava.Connected = hasStravaToken
+	strava.Scopes = nonNilStrings(stravaScopes)
+	strava.LastSuccessfulSyncAt = lastSyncAt
+
+	// Sheets needs both the Google grant and a spreadsheet to write to
+	google.Provider = ProviderGoogleSheets
+	google.Connected = hasGoogle && google.SpreadsheetID != nil && *google.SpreadsheetID != ""
+	google.AccountName = &googleEmail
+	google.Scopes = nonNilStrings(googleScopes)
+	google.LastSuccessfulSyncAt = lastSyncAt
+
+	return []Connection{strava, google}, nil
+}
+
+// UpdateGrantedScopes records the scopes a provider granted when the user connected it
+func (r *UserRepository) UpdateGrantedScopes(ctx context.Context, userID int, provider string, scopes []string) error {
+	var column string
+	switch provider {
+	case ProviderStrava:
+		column = "strava_scopes"
+	case ProviderGoogleSheets:
+		column = "google_scopes"
+	default:
+		return fmt.Errorf("unknown provider %q", provider)
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET %s = $1, updated_at = $2 WHERE id = $3`, column)
+	_, err := r.db.ExecContext(ctx, query, pq.Array(scopes), time.Now(), userID)
+	return err
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+func TestUserRepository_GetConnections(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	expiry := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	lastSync := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	columns := []string{"strava", "strava_athlete_name", "strava_scopes", "strava_token_expiry", "strava_reauth_required",
+		"google", "email", "google_scopes", "google_token_expiry", "google_reauth_required", "spreadsheet_id", "last_sync"}
+
+	mock.ExpectQuery(`SELECT .* FROM users u\s+WHERE u.id = \$1`).
+		WithArgs(1, RunStatusCompleted).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(
+			true, "Jane Runner", "{read,activity:read_all}", expiry, true,
+			true, "jane@example.com", nil, expiry, false, "sheet-123", lastSync))
+
+	connections, err := repo.GetConnections(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(connections) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(connections))
+	}
+
+	strava, google := connections[0], connections[1]
+	if strava.Provider != ProviderStrava || !strava.Connected || !strava.ReauthRequired {
+		t.Errorf("Unexpected Strava connection: %+v", strava)
+	}
+	if strava.AccountName == nil || *strava.AccountName != "Jane Runner" {
+		t.Errorf("Expected the athlete name, got %v", strava.AccountName)
+	}
+	if len(strava.Scopes) != 2 || strava.Scopes[1] != "activity:read_all" {
+		t.Errorf("Unexpected Strava scopes: %v", strava.Scopes)
+	}
+	if google.Provider != ProviderGoogleSheets || !google.Connected || google.ReauthRequired {
+		t.Errorf("Unexpected Google Sheets connection: %+v", google)
+	}
+	if google.Scopes == nil || len(google.Scopes) != 0 {
+		t.Errorf("Expected empty, non-nil scopes when none are recorded, got %v", google.Scopes)
+	}
+	if google.LastSuccessfulSyncAt == nil || !google.LastSuccessfulSyncAt.Equal(lastSync) {
+		t.Errorf("Expected the last successful sync time, got %v", google.LastSuccessfulSyncAt)
+	}
+
+	mock.ExpectQuery(`SELECT .* FROM users u`).
+		WithArgs(999, RunStatusCompleted).
+		WillReturnError(sql.ErrNoRows)
+	if _, err := repo.GetConnections(context.Background(), 999); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateGrantedScopes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	mock.ExpectExec(`UPDATE users SET strava_scopes = \$1`).
+		WithArgs(pq.Array([]string{"read", "activity:read_all"}), sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateGrantedScopes(context.Background(), 1, ProviderStrava, []string{"read", "activity:read_all"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.UpdateGrantedScopes(context.Background(), 1, "dropbox", nil); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrInvalidInviteCode is returned by CreateUser when the invite code is unknown, already used,
+// revoked or expired
+var ErrInvalidInviteCode = errors.New("invite code is invalid or has already been used")
+
+// rowQuerier runs single-row queries on a database or within a transaction
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// InviteCodeRepository handles database operations for signup invite codes
+type InviteCodeRepository struct {
+	db *sql.DB
+}
+
+// NewInviteCodeRepository creates a new invite code repository
+func NewInviteCodeRepository(db *sql.DB) *InviteCodeRepository {
+	return &InviteCodeRepository{db: db}
+}
+
+// CreateInviteCode stores code, filling in its ID and creation time
+func (r *InviteCodeRepository) CreateInviteCode(ctx context.Context, code *InviteCode) error {
+	query := `
+		INSERT INTO invite_codes (code_hash, code_prefix, note, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, code.CodeHash, code.CodePrefix, code.Note, code.CreatedBy, code.ExpiresAt).
+		Scan(&code.ID, &code.CreatedAt)
+}
+
+// ListInviteCodes returns every invite code, newest first
+func (r *InviteCodeRepository) ListInviteCodes(ctx context.Context) ([]InviteCode, error) {
+	query := `
+		SELECT id, code_prefix, note, created_by, created_at, expires_at, used_by, used_at, revoked_at
+		FROM invite_codes
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []InviteCode{}
+	for rows.Next() {
+		var code InviteCode
+		if err := rows.Scan(&code.ID, &code.CodePrefix, &code.Note, &code.CreatedBy, &code.CreatedAt,
+			&code.ExpiresAt, &code.UsedBy, &code.UsedAt, &code.RevokedAt); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// RevokeInviteCode withdraws an unused invite code. It returns sql.ErrNoRows if there is no such
+// code or it was already used or revoked.
+func (r *InviteCodeRepository) RevokeInviteCode(ctx context.Context, id int) error {
+	query := `
+		UPDATE invite_codes SET revoked_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// claimInviteCode marks the code with the given hash used and returns its ID, or
+// ErrInvalidInviteCode if it cannot be redeemed. The row lock it takes makes concurrent
+// redemptions of the same code wait, so only one succeeds.
+func claimInviteCode(ctx context.Context, q rowQuerier, codeHash string) (int, error) {
+	query := `
+		UPDATE invite_codes SET used_at = NOW()
+		WHERE code_hash = $1 AND used_at IS NULL AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING id
+	`
+
+	var id int
+	err := q.QueryRowContext(ctx, query, codeHash).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidInviteCode
+	}
+	return id, err
+}
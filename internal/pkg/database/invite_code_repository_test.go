@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+func TestUserRepository_CreateUserRedeemsInviteCode(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	claim := "UPDATE invite_codes SET used_at = NOW\\(\\)"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs("code-hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, now, now))
+	mock.ExpectExec("UPDATE invite_codes SET used_by").
+		WithArgs(9, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A used, revoked or expired code creates no account
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs("used-hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+	req := &CreateUserRequest{
+		GoogleID:           "google-9",
+		Email:              "new@example.com",
+		Name:               "New Runner",
+		GoogleAccessToken:  "access",
+		GoogleRefreshToken: "refresh",
+		InviteCodeHash:     "code-hash",
+	}
+	user, err := repo.CreateUser(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if user.ID != 9 {
+		t.Errorf("Expected user 9, got %d", user.ID)
+	}
+
+	req.InviteCodeHash = "used-hash"
+	if _, err := repo.CreateUser(context.Background(), req); !errors.Is(err, ErrInvalidInviteCode) {
+		t.Errorf("Expected ErrInvalidInviteCode, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestInviteCodeRepository_RevokeInviteCode(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE invite_codes SET revoked_at").
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE invite_codes SET revoked_at").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewInviteCodeRepository(db)
+	if err := repo.RevokeInviteCode(context.Background(), 4); err != nil {
+		t.Fatalf("RevokeInviteCode failed: %v", err)
+	}
+	if err := repo.RevokeInviteCode(context.Background(), 5); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a used code, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockID is the advisory lock held while migrating, so two operators cannot apply the
+// same migrations concurrently
+const migrationLockID = 7314190323
+
+// Migration is one schema version and the up files that make it, in name order. Version 2 has
+// two independent up files; both are applied as that version.
+type Migration struct {
+	Version uint
+	Files   []string
+}
+
+// Migrator applies the embedded up migrations. It records the schema version in the
+// schema_migrations table of golang-migrate, so the migrate CLI described in the README keeps
+// working on the same database, e.g. for down migrations.
+type Migrator struct {
+	db     *sql.DB
+	source fs.FS
+}
+
+// NewMigrator creates a migrator for the migrations built into the binary
+func NewMigrator(db *sql.DB) *Migrator {
+	source, _ := fs.Sub(embeddedMigrations, "migrations")
+	return &Migrator{db: db, source: source}
+}
+
+// Migrations lists the available migrations by version
+func (m *Migrator) Migrations() ([]Migration, error) {
+	names, err := fs.Glob(m.source, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var migrations []Migration
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version == uint(version) {
+			migrations[n-1].Files = append(migrations[n-1].Files, name)
+			continue
+		}
+		migrations = append(migrations, Migration{Version: uint(version), Files: []string{name}})
+	}
+	return migrations, nil
+}
+
+// Version returns the applied schema version, 0 when no migration was applied, and whether a
+// failed migration left the schema dirty
+func (m *Migrator) Version(ctx context.Context) (uint, bool, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return 0, false, err
+	}
+	return m.readVersion(ctx)
+}
+
+// Pending lists the migrations above the applied version
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("schema version %d is dirty; fix the schema and force the version with the migrate CLI", version)
+	}
+
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations in order and returns those applied. Each version is applied
+// with its version update in one transaction, so a failed migration leaves the schema at the
+// previous version instead of dirty.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range pending {
+		if err := m.apply(ctx, conn, migration); err != nil {
+			return applied, err
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range migration.Files {
+		statements, err := fs.ReadFile(m.source, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(statements)); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) readVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMigrator_EmbeddedMigrations(t *testing.T) {
+	migrations, err := NewMigrator(nil).Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("Expected the embedded migrations from version 1, got %+v", migrations)
+	}
+	for i, migration := range migrations {
+		if migration.Version != uint(i+1) {
+			t.Errorf("Expected consecutive versions, got %d at position %d", migration.Version, i)
+		}
+		for _, name := range migration.Files {
+			if !strings.HasSuffix(name, ".up.sql") {
+				t.Errorf("Expected only up migrations, got %s", name)
+			}
+		}
+	}
+	if len(migrations[1].Files) != 2 {
+		t.Errorf("Expected both version 2 up migrations, got %v", migrations[1].Files)
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	migrator := &Migrator{db: db, source: fstest.MapFS{
+		"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id SERIAL)")},
+		"000001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+		"000002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT")},
+		"000003_add_name.up.sql":       {Data: []byte("ALTER TABLE users ADD COLUMN name TEXT")},
+	}}
+
+	// Version 1 is applied; 2 is applied and 3 fails, leaving the schema at version 2
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE users ADD COLUMN email TEXT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("TRUNCATE schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE users ADD COLUMN name TEXT").WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "000003_add_name.up.sql") {
+		t.Errorf("Expected the failing migration to be reported, got %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("Expected version 2 applied, got %+v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_DirtySchema(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(5, true))
+
+	if _, err := NewMigrator(db).Pending(context.Background()); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Errorf("Expected a dirty schema to be refused, got %v", err)
+	}
+}
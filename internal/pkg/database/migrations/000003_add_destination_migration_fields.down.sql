@@ -0,0 +1,5 @@
+-- Remove destination migration fields from users table
+ALTER TABLE users 
+DROP COLUMN pending_destination_type,
+DROP COLUMN pending_destination_id,
+DROP COLUMN dual_write_until;
@@ -0,0 +1,12 @@
+-- Add destination migration fields to users table
+-- While dual_write_until is in the future the automation engine writes to both the
+-- current destination and the pending one, and reports discrepancies before cutover
+ALTER TABLE users 
+ADD COLUMN pending_destination_type VARCHAR(50),
+ADD COLUMN pending_destination_id VARCHAR(255),
+ADD COLUMN dual_write_until TIMESTAMPTZ;
+
+-- Add comment explaining the fields
+COMMENT ON COLUMN users.pending_destination_type IS 'Destination type the user is migrating to (e.g. google_sheets)';
+COMMENT ON COLUMN users.pending_destination_id IS 'Identifier of the pending destination (e.g. spreadsheet ID)';
+COMMENT ON COLUMN users.dual_write_until IS 'End of the dual-write validation window for the pending destination';
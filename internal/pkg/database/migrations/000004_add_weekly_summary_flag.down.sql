@@ -0,0 +1,3 @@
+-- Remove weekly summary preference from users table
+ALTER TABLE users 
+DROP COLUMN weekly_summary_enabled;
@@ -0,0 +1,6 @@
+-- Add weekly summary preference to users table
+ALTER TABLE users 
+ADD COLUMN weekly_summary_enabled BOOLEAN DEFAULT false;
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.weekly_summary_enabled IS 'Whether the automation engine maintains a "Weekly" totals tab in the spreadsheet';
@@ -0,0 +1,8 @@
+-- Remove provider reconciliation fields from users table
+DROP INDEX IF EXISTS idx_users_last_reconciled_at;
+
+ALTER TABLE users 
+DROP COLUMN strava_reauth_required,
+DROP COLUMN google_reauth_required,
+DROP COLUMN last_reconciled_at,
+DROP COLUMN reconciliation_issues;
@@ -0,0 +1,16 @@
+-- Add provider reconciliation fields to users table
+-- A weekly background job cross-checks stored connection data against Strava and Google
+ALTER TABLE users 
+ADD COLUMN strava_reauth_required BOOLEAN DEFAULT false,
+ADD COLUMN google_reauth_required BOOLEAN DEFAULT false,
+ADD COLUMN last_reconciled_at TIMESTAMPTZ,
+ADD COLUMN reconciliation_issues TEXT[];
+
+-- Index for picking users due for reconciliation
+CREATE INDEX idx_users_last_reconciled_at ON users(last_reconciled_at);
+
+-- Add comment explaining the fields
+COMMENT ON COLUMN users.strava_reauth_required IS 'Strava tokens were rejected or lack required scopes; user must reconnect';
+COMMENT ON COLUMN users.google_reauth_required IS 'Google tokens were rejected or lack required scopes; user must reconnect';
+COMMENT ON COLUMN users.last_reconciled_at IS 'When the background reconciliation job last checked this user';
+COMMENT ON COLUMN users.reconciliation_issues IS 'Drift found by the last reconciliation that could not be fixed automatically';
@@ -0,0 +1,4 @@
+-- Drop automation run history
+DROP INDEX IF EXISTS idx_automation_runs_trace_id;
+DROP INDEX IF EXISTS idx_automation_runs_user_started;
+DROP TABLE IF EXISTS automation_runs;
@@ -0,0 +1,31 @@
+-- Record every automation run so results, failures and test-mode runs are auditable
+CREATE TABLE automation_runs (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    trace_id VARCHAR(64),                                     -- Job trace ID (queued runs only)
+    trigger_type VARCHAR(32) NOT NULL,                        -- What started the run (schedule, manual_sync, test_mode)
+    
+    -- Run flags
+    is_test_mode BOOLEAN NOT NULL DEFAULT false,              -- Run came from the development test loop, not the job queue
+    dry_run BOOLEAN NOT NULL DEFAULT false,                   -- Run previewed writes without modifying the spreadsheet
+    
+    -- Outcome
+    status VARCHAR(32) NOT NULL DEFAULT 'running',            -- running, completed or failed
+    activities_count INTEGER NOT NULL DEFAULT 0,              -- Activities fetched from Strava
+    error_type VARCHAR(64),                                   -- Machine readable error category
+    error_message TEXT,                                       -- Human readable error
+    
+    -- Timing
+    started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    
+    CONSTRAINT fk_automation_runs_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index for listing a user's recent runs
+CREATE INDEX idx_automation_runs_user_started ON automation_runs(user_id, started_at DESC);
+-- Index for finding runs by job trace ID
+CREATE INDEX idx_automation_runs_trace_id ON automation_runs(trace_id);
+
+COMMENT ON TABLE automation_runs IS 'History of automation processing runs per user';
+COMMENT ON COLUMN automation_runs.is_test_mode IS 'True for runs from the development test loop; never expected in production';
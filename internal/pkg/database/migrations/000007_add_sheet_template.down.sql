@@ -0,0 +1,3 @@
+-- Remove spreadsheet template selection from users table
+ALTER TABLE users 
+DROP COLUMN sheet_template;
@@ -0,0 +1,7 @@
+-- Add spreadsheet template selection to users table
+-- The automation engine writes activity rows in the column layout of the selected template
+ALTER TABLE users 
+ADD COLUMN sheet_template VARCHAR(32) DEFAULT 'basic_log';
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.sheet_template IS 'Spreadsheet template from the catalog (basic_log, coach_plan, triathlon)';
@@ -0,0 +1,4 @@
+-- Drop the activity cache
+DROP TABLE IF EXISTS activity_cache_state;
+DROP INDEX IF EXISTS idx_activities_user_start_date;
+DROP TABLE IF EXISTS activities;
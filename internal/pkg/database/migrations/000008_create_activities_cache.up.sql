@@ -0,0 +1,45 @@
+-- Cache of activities fetched from Strava so re-syncs and exports can be served without
+-- re-hitting the Strava API while the cached window is fresh
+CREATE TABLE activities (
+    strava_id BIGINT PRIMARY KEY,                             -- Strava activity ID
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    
+    -- Activity data as returned by Strava
+    name TEXT NOT NULL DEFAULT '',
+    type VARCHAR(64) NOT NULL DEFAULT '',
+    sport_type VARCHAR(64) NOT NULL DEFAULT '',
+    distance DOUBLE PRECISION NOT NULL DEFAULT 0,             -- meters
+    moving_time INTEGER NOT NULL DEFAULT 0,                   -- seconds
+    elapsed_time INTEGER NOT NULL DEFAULT 0,                  -- seconds
+    total_elevation_gain DOUBLE PRECISION NOT NULL DEFAULT 0, -- meters
+    start_date TIMESTAMPTZ NOT NULL,
+    start_date_local TIMESTAMP NOT NULL,                      -- Athlete's wall clock time
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    average_speed DOUBLE PRECISION NOT NULL DEFAULT 0,        -- meters per second
+    max_speed DOUBLE PRECISION NOT NULL DEFAULT 0,            -- meters per second
+    average_heartrate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_heartrate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    kudos INTEGER NOT NULL DEFAULT 0,
+    comments INTEGER NOT NULL DEFAULT 0,
+    
+    -- Cache bookkeeping
+    fetched_at TIMESTAMPTZ NOT NULL,                          -- When Strava last returned this activity
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_activities_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index for range reads of a user's activities
+CREATE INDEX idx_activities_user_start_date ON activities(user_id, start_date);
+
+-- Window of each user's activities the cache holds completely
+CREATE TABLE activity_cache_state (
+    user_id INTEGER PRIMARY KEY,                              -- Foreign key to users table
+    covered_from TIMESTAMPTZ NOT NULL,                        -- Earliest start date fetched without gaps up to refreshed_at
+    refreshed_at TIMESTAMPTZ NOT NULL,                        -- When the window was last refreshed from Strava
+    
+    CONSTRAINT fk_activity_cache_state_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE activities IS 'Activities fetched from Strava, upserted by Strava ID';
+COMMENT ON TABLE activity_cache_state IS 'Per-user activity cache coverage used to decide freshness';
@@ -0,0 +1,5 @@
+-- Drop notification history
+DROP INDEX IF EXISTS idx_automation_runs_user_status_completed;
+DROP INDEX IF EXISTS idx_notification_log_user_kind_sent;
+DROP INDEX IF EXISTS idx_notification_log_user_sent;
+DROP TABLE IF EXISTS notification_log;
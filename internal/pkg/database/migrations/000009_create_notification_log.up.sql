@@ -0,0 +1,19 @@
+-- Record notifications sent to users so throttling and escalation rules can be enforced
+CREATE TABLE notification_log (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    kind VARCHAR(64) NOT NULL,                                -- Notification type (e.g. quiet_failure)
+    level INTEGER NOT NULL DEFAULT 0,                         -- Escalation level for escalating notifications
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_notification_log_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index for finding a user's most recent notification (optionally of one kind)
+CREATE INDEX idx_notification_log_user_sent ON notification_log(user_id, sent_at DESC);
+CREATE INDEX idx_notification_log_user_kind_sent ON notification_log(user_id, kind, sent_at DESC);
+
+-- Index for finding a user's last successful run when detecting quiet failures
+CREATE INDEX idx_automation_runs_user_status_completed ON automation_runs(user_id, status, completed_at DESC);
+
+COMMENT ON TABLE notification_log IS 'Notifications sent to users, used for throttling and escalation';
@@ -0,0 +1,2 @@
+-- Drop backfill checkpoints
+DROP TABLE IF EXISTS backfill_windows;
@@ -0,0 +1,16 @@
+-- Checkpoints for historical imports: one row per monthly window that was fully imported,
+-- so an interrupted backfill resumes after the last completed window
+CREATE TABLE backfill_windows (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    window_start TIMESTAMPTZ NOT NULL,                        -- Inclusive start of the window (Strava "after")
+    window_end TIMESTAMPTZ NOT NULL,                          -- Exclusive end of the window (Strava "before")
+    activity_count INTEGER NOT NULL DEFAULT 0,                -- Activities Strava returned for the window
+    type_counts JSONB NOT NULL DEFAULT '{}',                  -- Activity count per Strava activity type
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_backfill_windows_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_backfill_windows_user_start UNIQUE (user_id, window_start)
+);
+
+COMMENT ON TABLE backfill_windows IS 'Completed monthly windows of historical Strava imports, used as resume checkpoints';
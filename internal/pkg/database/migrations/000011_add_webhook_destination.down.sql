@@ -0,0 +1,4 @@
+-- Remove the outbound webhook from users table
+ALTER TABLE users
+DROP COLUMN IF EXISTS webhook_secret,
+DROP COLUMN IF EXISTS webhook_url;
@@ -0,0 +1,9 @@
+-- Add an outbound webhook to users table
+-- After each run the automation engine posts newly synced activities to the URL, signed with the secret
+ALTER TABLE users
+ADD COLUMN webhook_url TEXT,
+ADD COLUMN webhook_secret BYTEA;
+
+-- Add comments explaining the fields
+COMMENT ON COLUMN users.webhook_url IS 'HTTPS endpoint receiving a JSON payload of newly synced activities after each run';
+COMMENT ON COLUMN users.webhook_secret IS 'Encrypted shared secret used to sign webhook payloads with HMAC-SHA256';
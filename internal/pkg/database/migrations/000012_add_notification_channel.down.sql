@@ -0,0 +1,6 @@
+-- Remove notification channel preference from users table
+DROP INDEX IF EXISTS idx_automation_runs_completed_at;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS chat_webhook_url,
+DROP COLUMN IF EXISTS notification_channel;
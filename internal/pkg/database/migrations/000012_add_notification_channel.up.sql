@@ -0,0 +1,12 @@
+-- Add notification channel preference to users table
+-- Users can receive run summaries and failure alerts in Slack or Discord instead of email
+ALTER TABLE users
+ADD COLUMN notification_channel VARCHAR(16) NOT NULL DEFAULT 'email',
+ADD COLUMN chat_webhook_url BYTEA;
+
+-- Add comments explaining the fields
+COMMENT ON COLUMN users.notification_channel IS 'Preferred notification channel (email, slack, discord)';
+COMMENT ON COLUMN users.chat_webhook_url IS 'Encrypted Slack or Discord incoming webhook URL';
+
+-- Index for the notification service polling finished runs of chat users
+CREATE INDEX idx_automation_runs_completed_at ON automation_runs(completed_at);
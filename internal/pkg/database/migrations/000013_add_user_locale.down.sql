@@ -0,0 +1,3 @@
+-- Remove locale from users table
+ALTER TABLE users
+DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,7 @@
+-- Add locale to users table
+-- Notifications are rendered in the user's locale, falling back to English for unsupported locales
+ALTER TABLE users
+ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT 'en';
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.locale IS 'Locale notifications are rendered in (e.g. en, es)';
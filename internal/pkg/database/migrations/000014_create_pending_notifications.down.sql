@@ -0,0 +1,7 @@
+-- Remove daily digest support
+DROP TABLE IF EXISTS pending_notifications;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS last_digest_at,
+DROP COLUMN IF EXISTS digest_time,
+DROP COLUMN IF EXISTS notification_mode;
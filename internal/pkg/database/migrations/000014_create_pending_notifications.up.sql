@@ -0,0 +1,30 @@
+-- Add digest delivery settings to users table
+-- In digest mode, per-run notifications are collected and sent once a day at the user's local digest time
+ALTER TABLE users
+ADD COLUMN notification_mode VARCHAR(16) NOT NULL DEFAULT 'immediate',
+ADD COLUMN digest_time VARCHAR(5) NOT NULL DEFAULT '18:00',
+ADD COLUMN last_digest_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN users.notification_mode IS 'Per-run notification delivery (immediate, digest)';
+COMMENT ON COLUMN users.digest_time IS 'Local time (HH:MM, in the user timezone) the daily digest is sent';
+COMMENT ON COLUMN users.last_digest_at IS 'When the last daily digest was sent';
+
+-- Events waiting for a user's next daily digest
+CREATE TABLE pending_notifications (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    kind VARCHAR(64) NOT NULL,                                -- Event type (run_summary, sync_failed)
+    run_id INTEGER,                                           -- Run that produced the event, if any
+    trigger_type VARCHAR(32) NOT NULL DEFAULT '',
+    activities_count INTEGER NOT NULL DEFAULT 0,
+    error_type VARCHAR(64) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,                         -- When the run finished
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT fk_pending_notifications_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index for collecting a user's pending events in order
+CREATE INDEX idx_pending_notifications_user_occurred ON pending_notifications(user_id, occurred_at);
+
+COMMENT ON TABLE pending_notifications IS 'Per-run events collected for the daily digest; deleted once the digest is sent';
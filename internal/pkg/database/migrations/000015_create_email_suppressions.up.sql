@@ -0,0 +1,15 @@
+-- Create email_suppressions table
+-- Addresses whose mail server permanently rejected a notification; no further email is sent to them
+CREATE TABLE email_suppressions (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    email VARCHAR(255) NOT NULL,                              -- Lower-cased recipient address
+    smtp_code INTEGER NOT NULL,                               -- SMTP reply code of the rejection (e.g. 550)
+    reason TEXT NOT NULL,                                     -- SMTP reply text of the last rejection
+    bounce_count INTEGER NOT NULL DEFAULT 1,                  -- Permanent failures recorded for the address
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_bounced_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    CONSTRAINT uq_email_suppressions_email UNIQUE (email)
+);
+
+COMMENT ON TABLE email_suppressions IS 'Hard-bounced addresses excluded from email notifications until an admin clears them';
@@ -0,0 +1,3 @@
+-- Remove chronological row ordering preference from users table
+ALTER TABLE users 
+DROP COLUMN sort_rows_chronologically;
@@ -0,0 +1,6 @@
+-- Add chronological row ordering preference to users table
+ALTER TABLE users 
+ADD COLUMN sort_rows_chronologically BOOLEAN DEFAULT false;
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.sort_rows_chronologically IS 'Whether the automation engine re-sorts the training log by activity date after appending rows';
@@ -0,0 +1,5 @@
+-- Remove next scheduled automation run from users table
+DROP INDEX IF EXISTS idx_users_ready_for_processing;
+
+ALTER TABLE users 
+DROP COLUMN next_run_at;
@@ -0,0 +1,9 @@
+-- Add the next scheduled automation run to users table
+ALTER TABLE users 
+ADD COLUMN next_run_at TIMESTAMPTZ;
+
+-- Index for listing the users due in a scheduling window, page by page in (next_run_at, id) order
+CREATE INDEX idx_users_ready_for_processing ON users(next_run_at, id) WHERE automation_enabled = true;
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.next_run_at IS 'When the scheduler next runs the automation for this user; NULL if not scheduled';
@@ -0,0 +1,2 @@
+-- Drop blackout_windows table
+DROP TABLE IF EXISTS blackout_windows;
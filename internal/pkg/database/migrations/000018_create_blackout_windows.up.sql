@@ -0,0 +1,17 @@
+-- Create blackout_windows table
+-- Operator-declared periods (provider maintenance, our own deploys) during which no sync runs
+CREATE TABLE blackout_windows (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    starts_at TIMESTAMPTZ NOT NULL,                           -- Start of the blackout
+    ends_at TIMESTAMPTZ NOT NULL,                             -- End of the blackout; deferred jobs run from here on
+    reason TEXT NOT NULL DEFAULT '',                          -- Shown to operators, e.g. "Strava API maintenance"
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL, -- Admin who declared the window
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_blackout_windows_range CHECK (ends_at > starts_at)
+);
+
+-- Active and upcoming windows are looked up by their end
+CREATE INDEX idx_blackout_windows_ends_at ON blackout_windows(ends_at);
+
+COMMENT ON TABLE blackout_windows IS 'Global windows during which scheduled jobs are deferred and manual syncs are refused';
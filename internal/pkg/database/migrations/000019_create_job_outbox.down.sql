@@ -0,0 +1,2 @@
+-- Drop job_outbox table
+DROP TABLE IF EXISTS job_outbox;
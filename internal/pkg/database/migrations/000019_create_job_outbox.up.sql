@@ -0,0 +1,23 @@
+-- Create job_outbox table
+-- Jobs are written here in the same transaction as the change that triggers them and published
+-- to the Redis queue by a relay, so a brief Redis outage cannot lose them
+CREATE TABLE job_outbox (
+    id BIGSERIAL PRIMARY KEY,                                 -- Publication order
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    trace_id VARCHAR(64) NOT NULL,                            -- Trace ID of the queued job, returned to the caller
+    payload JSONB NOT NULL,                                   -- The queue job as published
+    attempts INTEGER NOT NULL DEFAULT 0,                      -- Publication attempts, including the successful one
+    last_error TEXT,                                          -- Error of the last failed attempt
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMPTZ,                                 -- NULL until the job reached the queue
+
+    CONSTRAINT uq_job_outbox_trace_id UNIQUE (trace_id)
+);
+
+-- The relay scans unpublished jobs in order
+CREATE INDEX idx_job_outbox_unpublished ON job_outbox(id) WHERE published_at IS NULL;
+
+-- Published jobs are pruned after the retention period
+CREATE INDEX idx_job_outbox_published_at ON job_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE job_outbox IS 'Transactional outbox of queue jobs; published rows are kept for replay until pruned';
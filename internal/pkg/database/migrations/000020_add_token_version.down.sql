@@ -0,0 +1,3 @@
+-- Remove the OAuth token version counter from users table
+ALTER TABLE users 
+DROP COLUMN token_version;
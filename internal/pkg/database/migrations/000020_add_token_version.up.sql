@@ -0,0 +1,6 @@
+-- Add a version counter to the OAuth tokens stored on users
+ALTER TABLE users 
+ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+
+-- Add comment explaining the field
+COMMENT ON COLUMN users.token_version IS 'Incremented on every write of the Google or Strava tokens; writers pass the version they read so a stale refresh cannot overwrite newer tokens';
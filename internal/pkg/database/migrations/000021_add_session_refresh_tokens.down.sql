@@ -0,0 +1,8 @@
+-- Remove rotating refresh tokens from user sessions
+DROP INDEX IF EXISTS idx_user_sessions_previous_refresh_token_hash;
+DROP INDEX IF EXISTS idx_user_sessions_refresh_token_hash;
+
+ALTER TABLE user_sessions
+DROP COLUMN refresh_rotated_at,
+DROP COLUMN previous_refresh_token_hash,
+DROP COLUMN refresh_token_hash;
@@ -0,0 +1,13 @@
+-- Add rotating refresh tokens to user sessions; only SHA-256 hashes are stored
+ALTER TABLE user_sessions
+ADD COLUMN refresh_token_hash VARCHAR(64),
+ADD COLUMN previous_refresh_token_hash VARCHAR(64),
+ADD COLUMN refresh_rotated_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX idx_user_sessions_refresh_token_hash ON user_sessions(refresh_token_hash);
+CREATE INDEX idx_user_sessions_previous_refresh_token_hash ON user_sessions(previous_refresh_token_hash);
+
+-- Add comments explaining the fields
+COMMENT ON COLUMN user_sessions.refresh_token_hash IS 'SHA-256 hash of the refresh token currently issued for the session';
+COMMENT ON COLUMN user_sessions.previous_refresh_token_hash IS 'Hash of the refresh token replaced by the last rotation; presenting it again means the token was stolen and revokes the session';
+COMMENT ON COLUMN user_sessions.refresh_rotated_at IS 'When the refresh token was last rotated';
@@ -0,0 +1,2 @@
+-- Drop api_tokens table
+DROP TABLE IF EXISTS api_tokens;
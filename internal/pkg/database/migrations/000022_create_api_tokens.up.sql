@@ -0,0 +1,19 @@
+-- Create api_tokens table
+-- Personal access tokens let users call the API from scripts with an Authorization: Bearer header
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Owner; the token acts as this user
+    name VARCHAR(100) NOT NULL,                               -- Label chosen by the user, e.g. "cron sync"
+    token_hash VARCHAR(64) NOT NULL UNIQUE,                   -- SHA-256 hash; the token itself is only shown once
+    token_prefix VARCHAR(16) NOT NULL,                        -- Leading characters of the token, to tell tokens apart
+    scopes TEXT[] NOT NULL,                                   -- Granted scopes: read, sync, export
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,                                 -- Last authenticated request, updated at most once a minute
+    expires_at TIMESTAMPTZ,                                   -- NULL for tokens that never expire
+    revoked_at TIMESTAMPTZ                                    -- Set when the user deletes the token
+);
+
+-- Tokens are listed per user
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+COMMENT ON TABLE api_tokens IS 'Scoped personal access tokens for programmatic API access, hashed at rest';
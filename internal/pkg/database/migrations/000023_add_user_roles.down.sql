@@ -0,0 +1,6 @@
+-- Remove account roles and their audit log
+DROP TABLE IF EXISTS role_changes;
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS chk_users_role,
+DROP COLUMN role;
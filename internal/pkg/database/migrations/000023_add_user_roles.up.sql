@@ -0,0 +1,23 @@
+-- Add an account role to users and an audit trail of role changes
+-- Users start as 'user'; admins and support staff are granted their role by an admin
+ALTER TABLE users
+ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user',
+ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin', 'support'));
+
+COMMENT ON COLUMN users.role IS 'Account role embedded in access tokens: user, admin or support';
+
+-- Create role_changes table
+CREATE TABLE role_changes (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- User whose role changed
+    old_role VARCHAR(20) NOT NULL,
+    new_role VARCHAR(20) NOT NULL,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL, -- Admin who made the change
+    reason TEXT NOT NULL DEFAULT '',                          -- Why the role was granted or revoked
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Changes are listed per user, newest first
+CREATE INDEX idx_role_changes_user_id ON role_changes(user_id, created_at DESC);
+
+COMMENT ON TABLE role_changes IS 'Audit log of account role grants and revocations';
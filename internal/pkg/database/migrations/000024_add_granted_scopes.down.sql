@@ -0,0 +1,4 @@
+-- Remove the recorded OAuth scopes
+ALTER TABLE users
+DROP COLUMN google_scopes,
+DROP COLUMN strava_scopes;
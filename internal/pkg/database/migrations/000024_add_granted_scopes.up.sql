@@ -0,0 +1,8 @@
+-- Record the OAuth scopes each provider granted, for the connections status endpoint
+-- Strava reports the accepted scopes on its callback; Google returns them with the tokens
+ALTER TABLE users
+ADD COLUMN strava_scopes TEXT[],
+ADD COLUMN google_scopes TEXT[];
+
+COMMENT ON COLUMN users.strava_scopes IS 'Scopes the athlete accepted when connecting Strava';
+COMMENT ON COLUMN users.google_scopes IS 'Scopes granted with the stored Google tokens';
@@ -0,0 +1,5 @@
+-- Remove account suspension
+ALTER TABLE users
+DROP COLUMN suspended_by,
+DROP COLUMN suspension_reason,
+DROP COLUMN suspended_at;
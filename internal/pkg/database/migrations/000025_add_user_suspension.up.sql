@@ -0,0 +1,10 @@
+-- Let admins suspend abusive or compromised accounts
+-- Suspended users cannot refresh their session, sign in, sync manually or be synced on schedule
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMPTZ,
+ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN suspended_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN users.suspended_at IS 'When an admin suspended the account; NULL for active accounts';
+COMMENT ON COLUMN users.suspension_reason IS 'Why the account was suspended, for support staff';
+COMMENT ON COLUMN users.suspended_by IS 'Admin who suspended the account';
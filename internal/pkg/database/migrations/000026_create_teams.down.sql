@@ -0,0 +1,11 @@
+-- Remove teams and the coach role
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+
+UPDATE users SET role = 'user' WHERE role = 'coach';
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS chk_users_role,
+ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin', 'support'));
+
+COMMENT ON COLUMN users.role IS 'Account role embedded in access tokens: user, admin or support';
@@ -0,0 +1,37 @@
+-- Let coaches own teams of athletes and read their sync status and run history
+-- Coaches are granted their role by an admin like support staff
+ALTER TABLE users
+DROP CONSTRAINT chk_users_role,
+ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin', 'support', 'coach'));
+
+COMMENT ON COLUMN users.role IS 'Account role embedded in access tokens: user, admin, support or coach';
+
+-- Create teams table
+CREATE TABLE teams (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    name VARCHAR(100) NOT NULL,                               -- Shown to invited athletes
+    coach_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Coach who owns the team
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Teams are listed per coach
+CREATE INDEX idx_teams_coach_user_id ON teams(coach_user_id);
+
+-- Create team_members table
+-- A coach invites an athlete, who becomes a member once they accept
+CREATE TABLE team_members (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Invited athlete
+    status VARCHAR(16) NOT NULL DEFAULT 'invited',            -- invited or active
+    invited_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMPTZ,                                  -- Set when the athlete accepts
+
+    PRIMARY KEY (team_id, user_id),
+    CONSTRAINT chk_team_members_status CHECK (status IN ('invited', 'active'))
+);
+
+-- Athletes' invitations and coach access checks look members up by user
+CREATE INDEX idx_team_members_user_id ON team_members(user_id, status);
+
+COMMENT ON TABLE teams IS 'Groups of athletes whose sync status a coach may read';
+COMMENT ON TABLE team_members IS 'Athletes invited to or accepted onto a team';
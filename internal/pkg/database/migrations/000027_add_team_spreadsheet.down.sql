@@ -0,0 +1,3 @@
+-- Remove the shared team spreadsheet
+ALTER TABLE teams
+DROP COLUMN IF EXISTS spreadsheet_id;
@@ -0,0 +1,5 @@
+-- Let a coach collect their athletes' activities in one spreadsheet, one tab per athlete
+ALTER TABLE teams
+ADD COLUMN spreadsheet_id VARCHAR(255);                       -- Coach-owned spreadsheet; NULL disables the shared sheet
+
+COMMENT ON COLUMN teams.spreadsheet_id IS 'Shared team spreadsheet written with the coach''s Google token';
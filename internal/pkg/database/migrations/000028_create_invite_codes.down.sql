@@ -0,0 +1,2 @@
+-- Drop signup invite codes
+DROP TABLE IF EXISTS invite_codes;
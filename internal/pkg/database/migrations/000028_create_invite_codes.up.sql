@@ -0,0 +1,15 @@
+-- Single-use invite codes admins hand out when SIGNUP_POLICY is invite
+CREATE TABLE invite_codes (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    code_hash VARCHAR(64) NOT NULL UNIQUE,                    -- Hex SHA-256 of the code; the code itself is never stored
+    code_prefix VARCHAR(16) NOT NULL,                         -- Leading characters shown in listings
+    note VARCHAR(255) NOT NULL DEFAULT '',                    -- Who the code is meant for
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL, -- Admin who created the code
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ,                                   -- NULL never expires
+    used_by INTEGER REFERENCES users(id) ON DELETE SET NULL,  -- Account created with the code
+    used_at TIMESTAMPTZ,                                      -- Set when the code is redeemed
+    revoked_at TIMESTAMPTZ                                    -- Set when an admin withdraws the code
+);
+
+COMMENT ON TABLE invite_codes IS 'Single-use signup invite codes for invite-only onboarding';
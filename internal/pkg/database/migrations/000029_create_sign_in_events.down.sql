@@ -0,0 +1,2 @@
+-- Drop the sign-in audit log
+DROP TABLE IF EXISTS sign_in_events;
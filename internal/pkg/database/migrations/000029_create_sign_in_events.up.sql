@@ -0,0 +1,23 @@
+-- Audit log of sign-ins, flagging those from a device or network the user had not used before
+CREATE TABLE sign_in_events (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id INTEGER REFERENCES user_sessions(id) ON DELETE SET NULL, -- Session the sign-in created
+    ip_address INET,                                          -- Client IP address
+    user_agent TEXT,                                          -- Browser/client user agent string
+    device VARCHAR(100) NOT NULL,                             -- Browser and operating system, e.g. "Firefox on Windows"
+    network VARCHAR(64) NOT NULL,                             -- Client network: the IPv4 /24 or IPv6 /48 prefix
+    new_device BOOLEAN NOT NULL DEFAULT false,                -- No earlier session or sign-in used this device
+    new_location BOOLEAN NOT NULL DEFAULT false,              -- No earlier session or sign-in came from this network
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMPTZ,                                  -- Set when the new sign-in email was sent
+    revoke_token_hash VARCHAR(64) UNIQUE,                     -- Hex SHA-256 of the emailed revoke link's token
+    revoke_expires_at TIMESTAMPTZ,                            -- The revoke link stops working after this
+    revoked_at TIMESTAMPTZ                                    -- Set when the session was signed out from the link
+);
+
+CREATE INDEX idx_sign_in_events_user_id ON sign_in_events(user_id, created_at DESC);
+CREATE INDEX idx_sign_in_events_unnotified ON sign_in_events(created_at)
+    WHERE (new_device OR new_location) AND notified_at IS NULL;
+
+COMMENT ON TABLE sign_in_events IS 'Sign-in audit log with new device and location detection';
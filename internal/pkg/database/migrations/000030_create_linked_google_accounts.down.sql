@@ -0,0 +1,3 @@
+-- Drop linked Google accounts
+DROP INDEX IF EXISTS idx_users_email_lower;
+DROP TABLE IF EXISTS linked_google_accounts;
@@ -0,0 +1,14 @@
+-- Further Google accounts a user has linked, so each of them signs in to the same user
+CREATE TABLE linked_google_accounts (
+    google_id VARCHAR(255) PRIMARY KEY,                       -- Google OAuth user ID of the linked account
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,                              -- Verified email of the linked account when it was linked
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_linked_google_accounts_user_id ON linked_google_accounts(user_id);
+
+-- Index for matching a new Google account to an existing user by email, whatever its case
+CREATE INDEX idx_users_email_lower ON users(LOWER(email));
+
+COMMENT ON TABLE linked_google_accounts IS 'Additional Google accounts that sign in to an existing user';
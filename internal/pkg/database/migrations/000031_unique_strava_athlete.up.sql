@@ -0,0 +1,28 @@
+-- A Strava athlete may be connected to only one user. Existing duplicates are never disconnected
+-- here; the migration aborts and lists them so they can be resolved first
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(format('athlete %s: users %s', strava_athlete_id, user_ids), '; ')
+    INTO duplicates
+    FROM (
+        SELECT strava_athlete_id, string_agg(id::TEXT, ', ' ORDER BY id) AS user_ids
+        FROM users
+        WHERE strava_athlete_id IS NOT NULL
+        GROUP BY strava_athlete_id
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION
+            'Cannot add users_strava_athlete_id_key: Strava athletes are connected to several users (%). ' ||
+            'Merge the duplicate accounts or have all but one user disconnect Strava before running this migration.',
+            duplicates;
+    END IF;
+END $$;
+
+-- Deferrable so that merging duplicate users can move a connection from one user to the other
+-- within a transaction
+ALTER TABLE users
+    ADD CONSTRAINT users_strava_athlete_id_key UNIQUE (strava_athlete_id) DEFERRABLE INITIALLY IMMEDIATE;
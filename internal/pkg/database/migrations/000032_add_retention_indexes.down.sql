@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_role_changes_created_at;
+DROP INDEX IF EXISTS idx_sign_in_events_created_at;
+DROP INDEX IF EXISTS idx_automation_runs_started_at;
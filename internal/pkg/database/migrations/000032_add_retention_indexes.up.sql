@@ -0,0 +1,5 @@
+-- The retention pruner deletes automation runs and audit events older than the retention period,
+-- which it finds by these columns across all users
+CREATE INDEX idx_automation_runs_started_at ON automation_runs(started_at);
+CREATE INDEX idx_sign_in_events_created_at ON sign_in_events(created_at);
+CREATE INDEX idx_role_changes_created_at ON role_changes(created_at);
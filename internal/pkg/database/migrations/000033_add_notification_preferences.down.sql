@@ -0,0 +1,3 @@
+-- Remove per-event notification preferences; email_notifications_enabled is kept in sync with them
+ALTER TABLE users
+DROP COLUMN IF EXISTS notification_preferences;
@@ -0,0 +1,11 @@
+-- Replace the single email notification switch with per-event preferences
+-- Keys missing from the object are enabled, so new notification kinds are sent until users opt out
+ALTER TABLE users
+ADD COLUMN notification_preferences JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN users.notification_preferences IS 'Per-event notification opt-outs (success_summary, failures, reauth_alerts, digest, security_alerts)';
+
+-- Users who turned email notifications off keep receiving security alerts only
+UPDATE users
+SET notification_preferences = '{"success_summary": false, "failures": false, "reauth_alerts": false, "digest": false}'::jsonb
+WHERE email_notifications_enabled = false;
@@ -0,0 +1,6 @@
+-- Remove quiet hours
+DROP TABLE IF EXISTS held_notifications;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS quiet_hours_end,
+DROP COLUMN IF EXISTS quiet_hours_start;
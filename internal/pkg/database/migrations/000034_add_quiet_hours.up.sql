@@ -0,0 +1,27 @@
+-- Add quiet hours to users table
+-- During quiet hours non-critical notifications are held and delivered when the window ends
+ALTER TABLE users
+ADD COLUMN quiet_hours_start VARCHAR(5),
+ADD COLUMN quiet_hours_end VARCHAR(5);
+
+COMMENT ON COLUMN users.quiet_hours_start IS 'Local time (HH:MM, in the user timezone) quiet hours begin; NULL when not set';
+COMMENT ON COLUMN users.quiet_hours_end IS 'Local time (HH:MM, in the user timezone) quiet hours end; may be earlier than the start to span midnight';
+
+-- Notifications held during a user's quiet hours
+CREATE TABLE held_notifications (
+    id SERIAL PRIMARY KEY,                                    -- Auto-incrementing primary key
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    email VARCHAR(255) NOT NULL DEFAULT '',                   -- Recipient address for email delivery
+    chat_only BOOLEAN NOT NULL DEFAULT false,                 -- Dropped instead of emailed without a chat channel
+    kind VARCHAR(64) NOT NULL,                                -- Notification kind (run_summary, sync_failed, ...)
+    payload JSONB NOT NULL,                                   -- The rendered-ready notification
+    release_at TIMESTAMPTZ NOT NULL,                          -- When the user's quiet hours end
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_held_notifications_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index for releasing due notifications in order
+CREATE INDEX idx_held_notifications_release_at ON held_notifications(release_at);
+
+COMMENT ON TABLE held_notifications IS 'Notifications held during quiet hours; deleted once delivered';
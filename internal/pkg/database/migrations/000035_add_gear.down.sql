@@ -0,0 +1,8 @@
+-- Remove gear tracking
+DROP TABLE IF EXISTS gear;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS sheet_gear_column;
+
+ALTER TABLE activities
+DROP COLUMN IF EXISTS gear_id;
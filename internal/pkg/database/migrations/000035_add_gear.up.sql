@@ -0,0 +1,28 @@
+-- Add gear (shoes/bikes) tracking
+-- Activities keep the Strava gear ID; gear names and mileage are cached per user
+ALTER TABLE activities
+ADD COLUMN gear_id VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN activities.gear_id IS 'Strava gear ID the activity was recorded with; empty when none';
+
+ALTER TABLE users
+ADD COLUMN sheet_gear_column BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN users.sheet_gear_column IS 'Whether the activity sheet includes a Gear column';
+
+-- Cached Strava gear lookups
+CREATE TABLE gear (
+    user_id INTEGER NOT NULL,                                 -- Foreign key to users table
+    gear_id VARCHAR(32) NOT NULL,                             -- Strava gear ID (g... for shoes, b... for bikes)
+    name VARCHAR(255) NOT NULL DEFAULT '',                    -- Name the athlete gave the gear
+    brand_name VARCHAR(255) NOT NULL DEFAULT '',
+    model_name VARCHAR(255) NOT NULL DEFAULT '',
+    distance DOUBLE PRECISION NOT NULL DEFAULT 0,             -- Meters Strava has recorded on the gear
+    retired BOOLEAN NOT NULL DEFAULT false,                   -- Whether the athlete retired the gear
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- When the gear was last fetched from Strava
+
+    PRIMARY KEY (user_id, gear_id),
+    CONSTRAINT fk_gear_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE gear IS 'Strava gear cached for resolving activity gear names and summarizing mileage';
@@ -0,0 +1,7 @@
+-- Remove the source of cached activities, dropping manual activities
+DELETE FROM activities WHERE source = 'manual';
+
+DROP SEQUENCE IF EXISTS manual_activity_id_seq;
+
+ALTER TABLE activities
+DROP COLUMN IF EXISTS source;
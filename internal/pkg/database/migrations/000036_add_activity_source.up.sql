@@ -0,0 +1,11 @@
+-- Add the source of cached activities
+-- Users can log activities not recorded on Strava; they are cached next to the fetched ones
+ALTER TABLE activities
+ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'strava';
+
+COMMENT ON COLUMN activities.source IS 'Where the activity came from: strava (fetched) or manual (logged in the app)';
+
+-- Manual activities use negative IDs so they never collide with Strava activity IDs
+CREATE SEQUENCE manual_activity_id_seq;
+
+COMMENT ON SEQUENCE manual_activity_id_seq IS 'Negated to number manual activities';
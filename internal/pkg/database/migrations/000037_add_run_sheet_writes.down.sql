@@ -0,0 +1,6 @@
+-- Remove the recorded sheet writes of runs
+ALTER TABLE automation_runs
+DROP COLUMN IF EXISTS undone_at,
+DROP COLUMN IF EXISTS undo_token_expires_at,
+DROP COLUMN IF EXISTS undo_token_hash,
+DROP COLUMN IF EXISTS sheet_writes;
@@ -0,0 +1,11 @@
+-- Record the sheet rows each run wrote so a run can be undone
+ALTER TABLE automation_runs
+ADD COLUMN sheet_writes JSONB,
+ADD COLUMN undo_token_hash VARCHAR(64),
+ADD COLUMN undo_token_expires_at TIMESTAMPTZ,
+ADD COLUMN undone_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN automation_runs.sheet_writes IS 'Rows the run wrote to the spreadsheet (action, activity ID, range); NULL when it wrote none';
+COMMENT ON COLUMN automation_runs.undo_token_hash IS 'SHA-256 hash of the token confirming an undo of the run';
+COMMENT ON COLUMN automation_runs.undo_token_expires_at IS 'When the undo confirmation token expires';
+COMMENT ON COLUMN automation_runs.undone_at IS 'When the run''s sheet writes were undone';
@@ -0,0 +1,2 @@
+-- Drop notifier_cursors table
+DROP TABLE IF EXISTS notifier_cursors;
@@ -0,0 +1,11 @@
+-- Create notifier_cursors table
+-- Notifiers that follow finished runs store the last run they handled here, so runs that finish
+-- while the notification service restarts are still handled after it comes back
+CREATE TABLE notifier_cursors (
+    name VARCHAR(64) PRIMARY KEY,                             -- Notifier owning the cursor
+    completed_at TIMESTAMPTZ NOT NULL,                        -- Completion time of the last run handled
+    run_id INTEGER NOT NULL DEFAULT 0,                        -- ID of the last run handled, breaking completion time ties
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE notifier_cursors IS 'Position of each run notifier in the stream of finished runs';
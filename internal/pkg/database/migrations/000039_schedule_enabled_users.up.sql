@@ -0,0 +1,5 @@
+-- Schedule users whose automation was enabled before next_run_at was maintained
+-- They become due at once; their first run then schedules the next daily run in their timezone
+UPDATE users
+SET next_run_at = NOW()
+WHERE automation_enabled = true AND next_run_at IS NULL;
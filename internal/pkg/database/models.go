@@ -27,6 +27,14 @@ type User struct {
 	CreatedAt                time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt                time.Time `json:"updated_at" db:"updated_at"`
 	LastLoginAt              *time.Time `json:"last_login_at" db:"last_login_at"`
+	TokenVersion             int       `json:"-" db:"token_version"` // Incremented on every token write
+	Role                     string    `json:"role" db:"role"` // Account role: user, admin, support or coach
+	SuspendedAt              *time.Time `json:"suspended_at,omitempty" db:"suspended_at"` // Set while an admin has suspended the account
+}
+
+// Suspended reports whether an admin has suspended the account
+func (u *User) Suspended() bool {
+	return u.SuspendedAt != nil
 }
 
 // UserSession represents a user session in the system
@@ -40,6 +48,11 @@ type UserSession struct {
 	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
 	LastUsedAt   time.Time `json:"last_used_at" db:"last_used_at"`
 	IsActive     bool      `json:"is_active" db:"is_active"`
+
+	// Refresh token hashes, only loaded by GetSessionByRefreshTokenHash
+	RefreshTokenHash         *string    `json:"-" db:"refresh_token_hash"`
+	PreviousRefreshTokenHash *string    `json:"-" db:"previous_refresh_token_hash"`
+	RefreshRotatedAt         *time.Time `json:"-" db:"refresh_rotated_at"`
 }
 
 // CreateUserRequest represents the data needed to create a new user
@@ -51,6 +64,8 @@ type CreateUserRequest struct {
 	GoogleAccessToken    string
 	GoogleRefreshToken   string
 	GoogleTokenExpiry    *time.Time
+	// InviteCodeHash, when set, is redeemed in the same transaction that creates the user
+	InviteCodeHash       string
 }
 
 // UpdateUserTokensRequest represents the data needed to update user's OAuth tokens
@@ -60,6 +75,24 @@ type UpdateUserTokensRequest struct {
 	GoogleRefreshToken   string
 	GoogleTokenExpiry    *time.Time
 	UpdateLastLogin      bool  // If true, also updates last_login_at
+
+	// ExpectedTokenVersion, when set, makes the update fail with ErrStaleTokenVersion unless the
+	// stored tokens are still at this version, i.e. nobody wrote tokens since they were read
+	ExpectedTokenVersion *int
+}
+
+// UpdateStravaConnectionRequest represents the data needed to store a user's Strava tokens and athlete profile
+type UpdateStravaConnectionRequest struct {
+	UserID            int
+	AccessToken       string
+	RefreshToken      string
+	TokenExpiry       *time.Time
+	AthleteID         int64
+	AthleteName       string
+	ProfilePictureURL string
+
+	// ExpectedTokenVersion works as in UpdateUserTokensRequest
+	ExpectedTokenVersion *int
 }
 
 // CreateSessionRequest represents the data needed to create a new session
@@ -84,6 +117,8 @@ type PublicUser struct {
 	Timezone                  string `json:"timezone"`
 	EmailNotificationsEnabled bool   `json:"email_notifications_enabled"`
 	AutomationEnabled         bool   `json:"automation_enabled"`
+
+	// Deprecated: GET /api/v1/connections reports each connection's status
 	HasStravaConnection       bool   `json:"has_strava_connection"`
 	HasSheetsConnection       bool   `json:"has_sheets_connection"`
 }
@@ -107,6 +142,27 @@ func (u *User) ToPublicUser() *PublicUser {
 	}
 }
 
+// Connection providers
+const (
+	ProviderStrava       = "strava"
+	ProviderGoogleSheets = "google_sheets"
+)
+
+// Connection is the status of a user's connection to one provider
+type Connection struct {
+	Provider       string     `json:"provider"`
+	Connected      bool       `json:"connected"`
+	AccountName    *string    `json:"account_name"` // Strava athlete name or Google account email
+	Scopes         []string   `json:"scopes"`       // Empty when not recorded, e.g. connected before scopes were stored
+	TokenExpiresAt *time.Time `json:"token_expires_at"`
+	// LastSuccessfulSyncAt is when a real sync, which needs both connections, last completed
+	LastSuccessfulSyncAt *time.Time `json:"last_successful_sync_at"`
+	// ReauthRequired is set by the engine's reconciliation when the provider rejected the
+	// stored tokens or a required scope is missing
+	ReauthRequired bool    `json:"reauth_required"`
+	SpreadsheetID  *string `json:"spreadsheet_id,omitempty"`
+}
+
 // ActivityLog represents an activity log entry for the dashboard
 type ActivityLog struct {
 	ID      string `json:"id"`
@@ -119,4 +175,513 @@ type ActivityLog struct {
 type DashboardUserResponse struct {
 	*PublicUser
 	RecentActivityLogs []ActivityLog  `json:"recent_activity_logs"`
-}
\ No newline at end of file
+
+	// LastRun is the user's latest sync, manual or scheduled; nil before the first one
+	LastRun *LastRunSummary `json:"last_run"`
+	// NextScheduledRunAt is when automation next syncs the user; nil while automation is off or
+	// a connection is missing
+	NextScheduledRunAt   *time.Time `json:"next_scheduled_run_at"`
+	StravaReauthRequired bool       `json:"strava_reauth_required"`
+	GoogleReauthRequired bool       `json:"google_reauth_required"`
+}
+
+// LastRunSummary is the outcome of a user's latest run, as shown on the dashboard
+type LastRunSummary struct {
+	Status          string     `json:"status"`
+	TriggerType     string     `json:"trigger_type"`
+	ActivitiesCount int        `json:"activities_count"`
+	ErrorType       *string    `json:"error_type,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// AutomationStatus is where a user's automation stands (see GetAutomationStatus)
+type AutomationStatus struct {
+	LastRun              *LastRunSummary
+	NextRunAt            *time.Time // Set when automation is enabled and after each run; nil when not scheduled
+	StravaReauthRequired bool
+	GoogleReauthRequired bool
+}
+
+// Automation run statuses
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	// RunStatusDeferred runs stopped because the user's daily processing budget was used up
+	RunStatusDeferred = "deferred"
+)
+
+// AutomationRun represents a single automation processing run for a user
+type AutomationRun struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	TraceID         *string    `json:"trace_id,omitempty" db:"trace_id"`
+	TriggerType     string     `json:"trigger_type" db:"trigger_type"`
+	IsTestMode      bool       `json:"is_test_mode" db:"is_test_mode"`
+	DryRun          bool       `json:"dry_run" db:"dry_run"`
+	Status          string     `json:"status" db:"status"`
+	ActivitiesCount int        `json:"activities_count" db:"activities_count"`
+	ErrorType       *string    `json:"error_type,omitempty" db:"error_type"`
+	ErrorMessage    *string    `json:"error_message,omitempty" db:"error_message"`
+	StartedAt       time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	Undoable        bool       `json:"undoable"` // The run's sheet writes were recorded and not undone yet
+	UndoneAt        *time.Time `json:"undone_at,omitempty" db:"undone_at"`
+}
+
+// RunUndo is the state of a run needed to undo its sheet writes
+type RunUndo struct {
+	RunID              int
+	SheetWrites        []byte // JSON-encoded google.SheetWrites; nil when the run wrote no rows
+	UndoTokenHash      *string
+	UndoTokenExpiresAt *time.Time
+	UndoneAt           *time.Time
+	// Superseded reports that a later run of the user wrote to the sheet and was not undone, so
+	// undoing this run could clash with the rows that run wrote
+	Superseded bool
+}
+
+// CreateRunRequest represents the data needed to record the start of an automation run
+type CreateRunRequest struct {
+	UserID      int
+	TraceID     string
+	TriggerType string
+	IsTestMode  bool
+	DryRun      bool
+}
+
+// NotificationRecord represents a notification sent to a user
+type NotificationRecord struct {
+	ID     int       `json:"id" db:"id"`
+	UserID int       `json:"user_id" db:"user_id"`
+	Kind   string    `json:"kind" db:"kind"`
+	Level  int       `json:"level" db:"level"`
+	SentAt time.Time `json:"sent_at" db:"sent_at"`
+}
+
+// BackfillWindow is a completed window of a historical import, used as a resume checkpoint
+type BackfillWindow struct {
+	UserID        int            `json:"user_id" db:"user_id"`
+	WindowStart   time.Time      `json:"window_start" db:"window_start"`
+	WindowEnd     time.Time      `json:"window_end" db:"window_end"`
+	ActivityCount int            `json:"activity_count" db:"activity_count"`
+	TypeCounts    map[string]int `json:"type_counts" db:"type_counts"`
+	CompletedAt   time.Time      `json:"completed_at" db:"completed_at"`
+}
+
+// QuietUser is an automation-enabled user with no successful run for a while, plus diagnostics
+type QuietUser struct {
+	UserID           int
+	Email            string
+	Name             string
+	Locale           string
+	LastSuccessAt    *time.Time // nil if the user never had a successful run
+	QuietSince       time.Time  // Last successful run, or account creation
+	FailedRuns       int        // Failed runs since QuietSince
+	LastErrorType    string
+	LastErrorMessage string
+	HasStrava        bool
+	HasGoogle        bool
+	HasSpreadsheet   bool
+}
+
+// NotificationChannel is where a user wants notifications delivered
+type NotificationChannel struct {
+	Channel    string // email, slack or discord
+	WebhookURL string // Decrypted chat webhook URL; empty for email
+
+	// Preferences are the kinds of notification the user wants; nil allows every kind
+	Preferences *NotificationPreferences
+
+	// Quiet hours in the user's timezone (local HH:MM); both empty when not set
+	Timezone        string
+	QuietHoursStart string
+	QuietHoursEnd   string
+}
+
+// Notification preferences a user can opt out of
+const (
+	PreferenceSuccessSummary = "success_summary" // Summaries of runs that synced activities
+	PreferenceFailures       = "failures"        // Failed and deferred runs, quiet failure nudges
+	PreferenceReauthAlerts   = "reauth_alerts"   // Failures fixed by reconnecting Strava or Google
+	PreferenceDigest         = "digest"          // The daily digest
+	PreferenceSecurityAlerts = "security_alerts" // Sign-ins from a new device or location
+)
+
+// NotificationPreferenceNames lists every notification preference
+func NotificationPreferenceNames() []string {
+	return []string{PreferenceSuccessSummary, PreferenceFailures, PreferenceReauthAlerts, PreferenceDigest, PreferenceSecurityAlerts}
+}
+
+// NotificationPreferences are the kinds of notification a user receives, stored as JSON in
+// users.notification_preferences. Keys missing from the stored object are enabled.
+type NotificationPreferences struct {
+	SuccessSummary bool `json:"success_summary"`
+	Failures       bool `json:"failures"`
+	ReauthAlerts   bool `json:"reauth_alerts"`
+	Digest         bool `json:"digest"`
+	SecurityAlerts bool `json:"security_alerts"`
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who never changed them
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{SuccessSummary: true, Failures: true, ReauthAlerts: true, Digest: true, SecurityAlerts: true}
+}
+
+// Allows reports whether the named preference is enabled. Unknown names and nil preferences
+// allow the notification.
+func (p *NotificationPreferences) Allows(preference string) bool {
+	if p == nil {
+		return true
+	}
+	switch preference {
+	case PreferenceSuccessSummary:
+		return p.SuccessSummary
+	case PreferenceFailures:
+		return p.Failures
+	case PreferenceReauthAlerts:
+		return p.ReauthAlerts
+	case PreferenceDigest:
+		return p.Digest
+	case PreferenceSecurityAlerts:
+		return p.SecurityAlerts
+	}
+	return true
+}
+
+// Set enables or disables the named preference and reports whether the name is known
+func (p *NotificationPreferences) Set(preference string, enabled bool) bool {
+	switch preference {
+	case PreferenceSuccessSummary:
+		p.SuccessSummary = enabled
+	case PreferenceFailures:
+		p.Failures = enabled
+	case PreferenceReauthAlerts:
+		p.ReauthAlerts = enabled
+	case PreferenceDigest:
+		p.Digest = enabled
+	case PreferenceSecurityAlerts:
+		p.SecurityAlerts = enabled
+	default:
+		return false
+	}
+	return true
+}
+
+// SyncNotificationsEnabled reports whether any sync notification is enabled; it is stored as
+// users.email_notifications_enabled for code that predates per-event preferences
+func (p NotificationPreferences) SyncNotificationsEnabled() bool {
+	return p.SuccessSummary || p.Failures || p.ReauthAlerts || p.Digest
+}
+
+// Notification modes for per-run notifications
+const (
+	NotificationModeImmediate = "immediate"
+	NotificationModeDigest    = "digest"
+)
+
+// FinishedRun is a completed or failed run of a user who receives per-run notifications
+// (chat users, and digest users whose runs are collected for the daily digest)
+type FinishedRun struct {
+	RunID           int
+	UserID          int
+	Email           string
+	Name            string
+	Locale          string
+	TriggerType     string
+	Status          string
+	ActivitiesCount int
+	ErrorType       string
+	ErrorMessage    string
+	CompletedAt     time.Time
+	Digest          bool // The user is in digest mode
+}
+
+// RunCursor is a position in the stream of finished runs: the completion time and ID of the last
+// run handled. Runs are ordered by completion time, then ID, so runs sharing a completion time
+// are neither skipped nor handled twice.
+type RunCursor struct {
+	CompletedAt time.Time
+	RunID       int
+}
+
+// PendingNotification is a per-run event waiting for the user's next daily digest
+type PendingNotification struct {
+	ID              int
+	UserID          int
+	Kind            string
+	RunID           *int
+	TriggerType     string
+	ActivitiesCount int
+	ErrorType       string
+	OccurredAt      time.Time
+}
+
+// HeldNotification is a notification held during the user's quiet hours
+type HeldNotification struct {
+	ID        int
+	UserID    int
+	Email     string
+	ChatOnly  bool
+	Kind      string
+	Payload   []byte // JSON-encoded notification
+	ReleaseAt time.Time
+	CreatedAt time.Time
+}
+
+// GearMileage is a piece of cached Strava gear with the synced activities recorded with it
+type GearMileage struct {
+	GearID         string
+	Name           string
+	BrandName      string
+	ModelName      string
+	Distance       float64 // meters, over every activity Strava has recorded with the gear
+	Retired        bool
+	FetchedAt      time.Time
+	ActivityCount  int
+	SyncedDistance float64 // meters, over the cached activities only
+	LastUsedAt     *time.Time
+}
+
+// DigestUser is a digest-mode user with pending events
+type DigestUser struct {
+	UserID       int
+	Email        string
+	Name         string
+	Locale       string
+	Timezone     string
+	DigestTime   string     // Local HH:MM
+	LastDigestAt *time.Time // nil if no digest was sent yet
+}
+
+// EmailSuppression is an address excluded from email after a permanent delivery failure
+type EmailSuppression struct {
+	Email         string    `json:"email"`
+	SMTPCode      int       `json:"smtp_code"`
+	Reason        string    `json:"reason"`
+	BounceCount   int       `json:"bounce_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastBouncedAt time.Time `json:"last_bounced_at"`
+}
+
+// ReadyUser is the scheduling view of a user whose next automated run falls in a window;
+// it carries no credentials so a whole window can be listed cheaply
+type ReadyUser struct {
+	ID                int
+	Timezone          string
+	AutomationEnabled bool
+	NextRunAt         time.Time
+}
+
+// ReadyUserCursor is the position after which the next page of ready users starts; the zero
+// value starts at the beginning of the window
+type ReadyUserCursor struct {
+	NextRunAt time.Time
+	UserID    int
+}
+
+// Cursor returns the position that continues the listing after u
+func (u ReadyUser) Cursor() ReadyUserCursor {
+	return ReadyUserCursor{NextRunAt: u.NextRunAt, UserID: u.ID}
+}
+
+// BlackoutWindow is a global period, such as announced provider maintenance or one of our
+// deploys, during which scheduled jobs are deferred and manual syncs are refused
+type BlackoutWindow struct {
+	ID        int       `json:"id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active reports whether the window covers at
+func (w BlackoutWindow) Active(at time.Time) bool {
+	return !at.Before(w.StartsAt) && at.Before(w.EndsAt)
+}
+
+// OutboxJob is a queue job recorded in the database until the relay publishes it to Redis
+type OutboxJob struct {
+	ID          int64
+	UserID      int
+	TraceID     string
+	Payload     []byte // JSON-encoded queue job
+	Attempts    int
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
+
+// APIToken is a personal access token a user created for programmatic access. Only the hash of
+// the token is stored; TokenPrefix identifies it in listings.
+type APIToken struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"-"`
+	Name        string     `json:"name"`
+	TokenHash   string     `json:"-"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"-"`
+
+	// Email is the owner's email, filled in when the token is looked up for authentication
+	Email string `json:"-"`
+	// OwnerSuspended is set when the token is looked up for authentication and its owner is suspended
+	OwnerSuspended bool `json:"-"`
+}
+
+// InviteCode is a single-use code that lets one person sign up while SIGNUP_POLICY is invite
+type InviteCode struct {
+	ID         int        `json:"id"`
+	CodeHash   string     `json:"-"`
+	CodePrefix string     `json:"code_prefix"`
+	Note       string     `json:"note"`
+	CreatedBy  *int       `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	UsedBy     *int       `json:"used_by,omitempty"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SignInEvent is an audit record of a sign-in and whether it came from a device or network the
+// user had not used before
+type SignInEvent struct {
+	ID          int
+	UserID      int
+	SessionID   *int // nil once the session is deleted
+	IPAddress   string
+	UserAgent   string
+	Device      string // Browser and operating system, e.g. "Firefox on Windows"
+	Network     string // The IPv4 /24 or IPv6 /48 the sign-in came from
+	NewDevice   bool
+	NewLocation bool
+	CreatedAt   time.Time
+}
+
+// SignInOrigin is the user agent and IP address of an earlier session or sign-in
+type SignInOrigin struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SignInAlert is a new-device or new-location sign-in waiting for its notification email
+type SignInAlert struct {
+	SignInEvent
+	Email  string
+	Name   string
+	Locale string
+}
+
+// LinkGoogleAccountRequest links a further Google account to an existing user
+type LinkGoogleAccountRequest struct {
+	UserID             int
+	GoogleID           string
+	Email              string
+	GoogleAccessToken  string
+	GoogleRefreshToken string // Empty keeps the user's refresh token
+	GoogleTokenExpiry  *time.Time
+}
+
+// Account roles stored in users.role
+const (
+	UserRoleUser    = "user"
+	UserRoleAdmin   = "admin"
+	UserRoleSupport = "support"
+	UserRoleCoach   = "coach"
+)
+
+// ValidUserRole reports whether role is one of the account roles
+func ValidUserRole(role string) bool {
+	return role == UserRoleUser || role == UserRoleAdmin || role == UserRoleSupport || role == UserRoleCoach
+}
+
+// Suspension describes an account an admin suspended
+type Suspension struct {
+	UserID      int       `json:"user_id"`
+	SuspendedAt time.Time `json:"suspended_at"`
+	SuspendedBy *int      `json:"suspended_by,omitempty"`
+	Reason      string    `json:"reason"`
+}
+
+// RoleChange is an audit record of a user's role being granted or revoked
+type RoleChange struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	OldRole   string    `json:"old_role"`
+	NewRole   string    `json:"new_role"`
+	ChangedBy *int      `json:"changed_by,omitempty"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team member statuses
+const (
+	TeamMemberInvited = "invited"
+	TeamMemberActive  = "active"
+)
+
+// Team is a group of athletes whose sync status a coach may read
+type Team struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	CoachUserID   int       `json:"coach_user_id"`
+	SpreadsheetID *string   `json:"spreadsheet_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TeamInvitation is an athlete's pending invitation to a team
+type TeamInvitation struct {
+	TeamID    int       `json:"team_id"`
+	TeamName  string    `json:"team_name"`
+	CoachName string    `json:"coach_name"`
+	InvitedAt time.Time `json:"invited_at"`
+}
+
+// TeamAthlete is an athlete invited to a team
+type TeamAthlete struct {
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Status     string     `json:"status"`
+	InvitedAt  time.Time  `json:"invited_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// AthleteSyncStatus is what a coach sees of an athlete on their team
+type AthleteSyncStatus struct {
+	UserID            int            `json:"user_id"`
+	Name              string         `json:"name"`
+	Email             string         `json:"email"`
+	AutomationEnabled bool           `json:"automation_enabled"`
+	Suspended         bool           `json:"suspended"`
+	StravaConnected   bool           `json:"strava_connected"`
+	SheetConfigured   bool           `json:"sheet_configured"`
+	LastSyncAt        *time.Time     `json:"last_sync_at,omitempty"` // Last real run that completed
+	LastRun           *AutomationRun `json:"last_run,omitempty"`
+}
+
+// UserSummary is a user as listed to operators
+type UserSummary struct {
+	ID                int        `json:"id"`
+	Email             string     `json:"email"`
+	Name              string     `json:"name"`
+	Role              string     `json:"role"`
+	AutomationEnabled bool       `json:"automation_enabled"`
+	Suspended         bool       `json:"suspended"`
+	StravaConnected   bool       `json:"strava_connected"`
+	SheetConfigured   bool       `json:"sheet_configured"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// ReEncryptResult counts the users and values ReEncryptSecrets went through
+type ReEncryptResult struct {
+	Users       int `json:"users"`
+	ReEncrypted int `json:"re_encrypted"` // Values rewritten under the current key
+	Current     int `json:"current"`      // Values already under the current key
+	LastUserID  int `json:"last_user_id"`
+}
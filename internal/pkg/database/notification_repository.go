@@ -0,0 +1,401 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotificationRepository handles database operations for sent notifications
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// ListQuietUsers returns automation-enabled users who want failure or reconnect notifications and
+// are due a nudge of the given kind, longest quiet first. A user is quiet when they have had no
+// successful (non dry-run, non test-mode) run since their quiet streak began; thresholds are the
+// ascending quiet day counts that escalate the nudge. Users already nudged during the streak at
+// the level their quiet days have reached are left out, so they cannot crowd out the batch.
+func (r *NotificationRepository) ListQuietUsers(ctx context.Context, kind string, now time.Time, thresholds []int, limit int) ([]QuietUser, error) {
+	query := `
+		SELECT u.id, u.email, u.name, u.locale, s.last_success_at,
+			COALESCE(s.last_success_at, u.created_at) AS quiet_since,
+			(SELECT COUNT(*) FROM automation_runs f
+				WHERE f.user_id = u.id AND f.status = $1 AND f.started_at > COALESCE(s.last_success_at, u.created_at)),
+			COALESCE(e.error_type, ''), COALESCE(e.error_message, ''),
+			u.strava_refresh_token IS NOT NULL, u.google_refresh_token IS NOT NULL, u.spreadsheet_id IS NOT NULL
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT MAX(completed_at) AS last_success_at FROM automation_runs
+			WHERE user_id = u.id AND status = $2 AND NOT dry_run AND NOT is_test_mode
+		) s ON true
+		LEFT JOIN LATERAL (
+			SELECT error_type, error_message FROM automation_runs
+			WHERE user_id = u.id AND status = $1
+			ORDER BY started_at DESC LIMIT 1
+		) e ON true
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS level FROM unnest($4::integer[]) AS t(days)
+			WHERE COALESCE(s.last_success_at, u.created_at) < $3::timestamptz - make_interval(days => t.days)
+		) due ON true
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(MAX(n.level), 0) AS level FROM notification_log n
+			WHERE n.user_id = u.id AND n.kind = $5 AND n.sent_at > COALESCE(s.last_success_at, u.created_at)
+		) nudged ON true
+		WHERE u.automation_enabled = true
+			AND (COALESCE((u.notification_preferences->>'failures')::boolean, true)
+				OR COALESCE((u.notification_preferences->>'reauth_alerts')::boolean, true))
+			AND due.level > nudged.level
+		ORDER BY quiet_since ASC, u.id ASC
+		LIMIT $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, RunStatusFailed, RunStatusCompleted, now, pq.Array(thresholds), kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []QuietUser
+	for rows.Next() {
+		var user QuietUser
+		err := rows.Scan(
+			&user.UserID, &user.Email, &user.Name, &user.Locale, &user.LastSuccessAt, &user.QuietSince,
+			&user.FailedRuns, &user.LastErrorType, &user.LastErrorMessage,
+			&user.HasStrava, &user.HasGoogle, &user.HasSpreadsheet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetLastNotification returns the user's most recent notification of the given kind, or of any
+// kind when kind is empty. It returns nil if no notification was sent.
+func (r *NotificationRepository) GetLastNotification(ctx context.Context, userID int, kind string) (*NotificationRecord, error) {
+	query := `
+		SELECT id, user_id, kind, level, sent_at
+		FROM notification_log
+		WHERE user_id = $1 AND ($2 = '' OR kind = $2)
+		ORDER BY sent_at DESC
+		LIMIT 1
+	`
+
+	var record NotificationRecord
+	err := r.db.QueryRowContext(ctx, query, userID, kind).Scan(
+		&record.ID, &record.UserID, &record.Kind, &record.Level, &record.SentAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// RecordNotification records that a notification was sent to the user
+func (r *NotificationRepository) RecordNotification(ctx context.Context, userID int, kind string, level int) error {
+	query := `
+		INSERT INTO notification_log (user_id, kind, level, sent_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, kind, level, time.Now())
+	return err
+}
+
+// ListFinishedRuns returns real runs that finished after the cursor for users with a chat
+// notification channel or in digest mode, plus every user's deferred runs, in cursor order
+func (r *NotificationRepository) ListFinishedRuns(ctx context.Context, after RunCursor, limit int) ([]FinishedRun, error) {
+	query := `
+		SELECT r.id, r.user_id, COALESCE(u.email, ''), COALESCE(u.name, ''), u.locale, r.trigger_type, r.status,
+			r.activities_count, COALESCE(r.error_type, ''), COALESCE(r.error_message, ''), r.completed_at,
+			u.notification_mode = $4
+		FROM automation_runs r
+		JOIN users u ON u.id = r.user_id
+		WHERE (r.completed_at, r.id) > ($1, $7) AND r.status IN ($2, $3, $6)
+			AND NOT r.dry_run AND NOT r.is_test_mode
+			AND (r.status = $6 OR u.notification_mode = $4 OR (u.notification_channel <> 'email' AND u.chat_webhook_url IS NOT NULL))
+		ORDER BY r.completed_at ASC, r.id ASC
+		LIMIT $5
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after.CompletedAt, RunStatusCompleted, RunStatusFailed, NotificationModeDigest, limit,
+		RunStatusDeferred, after.RunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []FinishedRun
+	for rows.Next() {
+		var run FinishedRun
+		err := rows.Scan(
+			&run.RunID, &run.UserID, &run.Email, &run.Name, &run.Locale, &run.TriggerType, &run.Status,
+			&run.ActivitiesCount, &run.ErrorType, &run.ErrorMessage, &run.CompletedAt, &run.Digest,
+		)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetRunCursor returns the stored position of the named run notifier, or nil if it has none
+func (r *NotificationRepository) GetRunCursor(ctx context.Context, name string) (*RunCursor, error) {
+	query := `
+		SELECT completed_at, run_id
+		FROM notifier_cursors
+		WHERE name = $1
+	`
+
+	var cursor RunCursor
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&cursor.CompletedAt, &cursor.RunID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+// SaveRunCursor stores the position of the named run notifier
+func (r *NotificationRepository) SaveRunCursor(ctx context.Context, name string, cursor RunCursor) error {
+	query := `
+		INSERT INTO notifier_cursors (name, completed_at, run_id, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET completed_at = EXCLUDED.completed_at, run_id = EXCLUDED.run_id, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, name, cursor.CompletedAt, cursor.RunID, time.Now())
+	return err
+}
+
+// AddPendingNotification stores an event for the user's next daily digest
+func (r *NotificationRepository) AddPendingNotification(ctx context.Context, event PendingNotification) error {
+	query := `
+		INSERT INTO pending_notifications (user_id, kind, run_id, trigger_type, activities_count, error_type, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, event.UserID, event.Kind, event.RunID, event.TriggerType,
+		event.ActivitiesCount, event.ErrorType, event.OccurredAt)
+	return err
+}
+
+// ListDigestUsers returns up to limit digest-mode users with pending events whose ID is greater
+// than afterID, in ID order, so callers page through all of them with the last ID returned.
+// Whether a digest is due depends on the user's local time and is decided by the caller.
+func (r *NotificationRepository) ListDigestUsers(ctx context.Context, afterID, limit int) ([]DigestUser, error) {
+	query := `
+		SELECT u.id, u.email, u.name, u.locale, COALESCE(u.timezone, 'UTC'), u.digest_time, u.last_digest_at
+		FROM users u
+		WHERE u.notification_mode = $1 AND u.id > $2
+			AND EXISTS (SELECT 1 FROM pending_notifications p WHERE p.user_id = u.id)
+		ORDER BY u.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, NotificationModeDigest, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []DigestUser
+	for rows.Next() {
+		var user DigestUser
+		err := rows.Scan(&user.UserID, &user.Email, &user.Name, &user.Locale, &user.Timezone, &user.DigestTime, &user.LastDigestAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// ListPendingNotifications returns the user's pending events, oldest first
+func (r *NotificationRepository) ListPendingNotifications(ctx context.Context, userID int) ([]PendingNotification, error) {
+	query := `
+		SELECT id, user_id, kind, run_id, trigger_type, activities_count, error_type, occurred_at
+		FROM pending_notifications
+		WHERE user_id = $1
+		ORDER BY occurred_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []PendingNotification
+	for rows.Next() {
+		var event PendingNotification
+		var runID sql.NullInt64
+		err := rows.Scan(&event.ID, &event.UserID, &event.Kind, &runID, &event.TriggerType,
+			&event.ActivitiesCount, &event.ErrorType, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		if runID.Valid {
+			id := int(runID.Int64)
+			event.RunID = &id
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// CompleteDigest removes the events included in a sent digest and records when it was sent.
+// Events added while the digest was being sent are kept for the next one.
+func (r *NotificationRepository) CompleteDigest(ctx context.Context, userID int, eventIDs []int, sentAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin digest transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pending_notifications WHERE user_id = $1 AND id = ANY($2)`, userID, pq.Array(eventIDs)); err != nil {
+		return fmt.Errorf("failed to remove digested events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET last_digest_at = $1 WHERE id = $2`, sentAt, userID); err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// HoldNotification stores a notification until the user's quiet hours end
+func (r *NotificationRepository) HoldNotification(ctx context.Context, held HeldNotification) error {
+	query := `
+		INSERT INTO held_notifications (user_id, email, chat_only, kind, payload, release_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, held.UserID, held.Email, held.ChatOnly, held.Kind, held.Payload, held.ReleaseAt)
+	return err
+}
+
+// ListDueHeldNotifications returns held notifications whose release time has passed, oldest first
+func (r *NotificationRepository) ListDueHeldNotifications(ctx context.Context, now time.Time, limit int) ([]HeldNotification, error) {
+	query := `
+		SELECT id, user_id, email, chat_only, kind, payload, release_at, created_at
+		FROM held_notifications
+		WHERE release_at <= $1
+		ORDER BY release_at ASC, id ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var held []HeldNotification
+	for rows.Next() {
+		var n HeldNotification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Email, &n.ChatOnly, &n.Kind, &n.Payload, &n.ReleaseAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		held = append(held, n)
+	}
+
+	return held, rows.Err()
+}
+
+// DeleteHeldNotification removes a released notification
+func (r *NotificationRepository) DeleteHeldNotification(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM held_notifications WHERE id = $1`, id)
+	return err
+}
+
+// IsEmailSuppressed reports whether email is on the suppression list
+func (r *NotificationRepository) IsEmailSuppressed(ctx context.Context, email string) (bool, error) {
+	var suppressed bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM email_suppressions WHERE email = $1)`,
+		strings.ToLower(email)).Scan(&suppressed)
+	return suppressed, err
+}
+
+// SuppressEmail adds email to the suppression list, or counts another bounce if it is already listed
+func (r *NotificationRepository) SuppressEmail(ctx context.Context, email string, smtpCode int, reason string) error {
+	query := `
+		INSERT INTO email_suppressions (email, smtp_code, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO UPDATE
+		SET smtp_code = EXCLUDED.smtp_code, reason = EXCLUDED.reason,
+			bounce_count = email_suppressions.bounce_count + 1, last_bounced_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.db.ExecContext(ctx, query, strings.ToLower(email), smtpCode, reason)
+	return err
+}
+
+// ListEmailSuppressions returns suppressed addresses, most recently bounced first
+func (r *NotificationRepository) ListEmailSuppressions(ctx context.Context, limit, offset int) ([]EmailSuppression, error) {
+	query := `
+		SELECT email, smtp_code, reason, bounce_count, created_at, last_bounced_at
+		FROM email_suppressions
+		ORDER BY last_bounced_at DESC, email
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppressions := []EmailSuppression{}
+	for rows.Next() {
+		var s EmailSuppression
+		if err := rows.Scan(&s.Email, &s.SMTPCode, &s.Reason, &s.BounceCount, &s.CreatedAt, &s.LastBouncedAt); err != nil {
+			return nil, err
+		}
+		suppressions = append(suppressions, s)
+	}
+
+	return suppressions, rows.Err()
+}
+
+// DeleteEmailSuppression removes email from the suppression list so it receives mail again
+func (r *NotificationRepository) DeleteEmailSuppression(ctx context.Context, email string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM email_suppressions WHERE email = $1`, strings.ToLower(email))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
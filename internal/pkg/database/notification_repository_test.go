@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetLastNotification(t *testing.T) {
+	t.Run("Found", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		sentAt := time.Now().Add(-time.Hour)
+		mock.ExpectQuery("SELECT id, user_id, kind, level, sent_at FROM notification_log").
+			WithArgs(7, "quiet_failure").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "level", "sent_at"}).
+				AddRow(3, 7, "quiet_failure", 2, sentAt))
+
+		record, err := NewNotificationRepository(db).GetLastNotification(context.Background(), 7, "quiet_failure")
+		if err != nil {
+			t.Fatalf("GetLastNotification failed: %v", err)
+		}
+		if record == nil || record.Level != 2 || !record.SentAt.Equal(sentAt) {
+			t.Errorf("Unexpected record: %+v", record)
+		}
+	})
+
+	t.Run("NoneSent", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, user_id, kind, level, sent_at FROM notification_log").
+			WithArgs(7, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "level", "sent_at"}))
+
+		record, err := NewNotificationRepository(db).GetLastNotification(context.Background(), 7, "")
+		if err != nil || record != nil {
+			t.Errorf("Expected no record and no error, got %+v, %v", record, err)
+		}
+	})
+}
+
+func TestListQuietUsers(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	quietSince := now.AddDate(0, 0, -8)
+	// Nudges already sent at the reached level are excluded in the query, not after the limit
+	mock.ExpectQuery(`SELECT u.id, u.email, u.name.*FROM notification_log n\s+WHERE n.user_id = u.id AND n.kind = \$5.*AND due.level > nudged.level`).
+		WithArgs(RunStatusFailed, RunStatusCompleted, now, sqlmock.AnyArg(), "quiet_failure", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "locale", "last_success_at", "quiet_since", "failed_runs",
+			"error_type", "error_message", "has_strava", "has_google", "has_spreadsheet"}).
+			AddRow(7, "runner@example.com", "Runner", "en", quietSince, quietSince, 3, "STRAVA_REAUTH_REQUIRED", "expired", true, true, false))
+
+	users, err := NewNotificationRepository(db).ListQuietUsers(context.Background(), "quiet_failure", now, []int{5, 10, 20}, 50)
+	if err != nil {
+		t.Fatalf("ListQuietUsers failed: %v", err)
+	}
+	if len(users) != 1 || users[0].FailedRuns != 3 || users[0].LastSuccessAt == nil || users[0].HasSpreadsheet {
+		t.Errorf("Unexpected quiet users: %+v", users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestListFinishedRuns(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	after := RunCursor{CompletedAt: time.Now().Add(-time.Minute), RunID: 10}
+	completedAt := time.Now()
+	mock.ExpectQuery(`SELECT r.id, r.user_id.*\(r.completed_at, r.id\) > \(\$1, \$7\).*ORDER BY r.completed_at ASC, r.id ASC`).
+		WithArgs(after.CompletedAt, RunStatusCompleted, RunStatusFailed, NotificationModeDigest, 100, RunStatusDeferred, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "email", "name", "locale", "trigger_type", "status",
+			"activities_count", "error_type", "error_message", "completed_at", "digest"}).
+			AddRow(11, 7, "runner@example.com", "Runner", "en", "schedule", RunStatusCompleted, 2, "", "", completedAt, false).
+			AddRow(12, 8, "other@example.com", "Other", "es", "manual_sync", RunStatusFailed, 0, "SHEETS_API_ERROR", "quota", completedAt, true))
+
+	runs, err := NewNotificationRepository(db).ListFinishedRuns(context.Background(), after, 100)
+	if err != nil {
+		t.Fatalf("ListFinishedRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ActivitiesCount != 2 || runs[1].ErrorType != "SHEETS_API_ERROR" || runs[0].Digest || !runs[1].Digest {
+		t.Errorf("Unexpected finished runs: %+v", runs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetRunCursor(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	completedAt := time.Now()
+	mock.ExpectQuery("SELECT completed_at, run_id FROM notifier_cursors").
+		WithArgs("run_notifier").
+		WillReturnRows(sqlmock.NewRows([]string{"completed_at", "run_id"}).AddRow(completedAt, 12))
+	mock.ExpectQuery("SELECT completed_at, run_id FROM notifier_cursors").
+		WithArgs("other").
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewNotificationRepository(db)
+	cursor, err := repo.GetRunCursor(context.Background(), "run_notifier")
+	if err != nil {
+		t.Fatalf("GetRunCursor failed: %v", err)
+	}
+	if cursor == nil || cursor.RunID != 12 || !cursor.CompletedAt.Equal(completedAt) {
+		t.Errorf("Unexpected cursor: %+v", cursor)
+	}
+
+	cursor, err = repo.GetRunCursor(context.Background(), "other")
+	if err != nil || cursor != nil {
+		t.Errorf("Expected no cursor, got %+v, %v", cursor, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSaveRunCursor(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	completedAt := time.Now()
+	mock.ExpectExec("INSERT INTO notifier_cursors .* ON CONFLICT \\(name\\) DO UPDATE").
+		WithArgs("run_notifier", completedAt, 12, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := NewNotificationRepository(db).SaveRunCursor(context.Background(), "run_notifier", RunCursor{CompletedAt: completedAt, RunID: 12})
+	if err != nil {
+		t.Fatalf("SaveRunCursor failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestListDigestUsers(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	lastDigestAt := time.Now().Add(-24 * time.Hour)
+	mock.ExpectQuery("SELECT u.id, u.email, u.name, u.locale").
+		WithArgs(NotificationModeDigest, 6, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "locale", "timezone", "digest_time", "last_digest_at"}).
+			AddRow(7, "runner@example.com", "Runner", "en", "Europe/Madrid", "18:00", lastDigestAt).
+			AddRow(8, "other@example.com", "Other", "es", "UTC", "07:30", nil))
+
+	users, err := NewNotificationRepository(db).ListDigestUsers(context.Background(), 6, 50)
+	if err != nil {
+		t.Fatalf("ListDigestUsers failed: %v", err)
+	}
+	if len(users) != 2 || users[0].Timezone != "Europe/Madrid" || users[0].LastDigestAt == nil || users[1].LastDigestAt != nil {
+		t.Errorf("Unexpected digest users: %+v", users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPendingNotifications(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewNotificationRepository(db)
+
+	occurredAt := time.Now()
+	runID := 11
+	mock.ExpectExec("INSERT INTO pending_notifications").
+		WithArgs(7, "run_summary", &runID, "schedule", 2, "", occurredAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, user_id, kind, run_id").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "run_id", "trigger_type", "activities_count", "error_type", "occurred_at"}).
+			AddRow(1, 7, "run_summary", 11, "schedule", 2, "", occurredAt).
+			AddRow(2, 7, "sync_failed", nil, "manual_sync", 0, "SHEETS_API_ERROR", occurredAt))
+
+	err := repo.AddPendingNotification(context.Background(), PendingNotification{
+		UserID: 7, Kind: "run_summary", RunID: &runID, TriggerType: "schedule", ActivitiesCount: 2, OccurredAt: occurredAt,
+	})
+	if err != nil {
+		t.Fatalf("AddPendingNotification failed: %v", err)
+	}
+
+	events, err := repo.ListPendingNotifications(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("ListPendingNotifications failed: %v", err)
+	}
+	if len(events) != 2 || events[0].RunID == nil || *events[0].RunID != 11 || events[1].RunID != nil {
+		t.Errorf("Unexpected pending notifications: %+v", events)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestCompleteDigest(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	sentAt := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM pending_notifications").
+		WithArgs(7, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE users SET last_digest_at").
+		WithArgs(sentAt, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := NewNotificationRepository(db).CompleteDigest(context.Background(), 7, []int{1, 2}, sentAt); err != nil {
+		t.Fatalf("CompleteDigest failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestHeldNotifications(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewNotificationRepository(db)
+
+	now := time.Now()
+	payload := []byte(`{"Kind":"run_summary","Title":"2 activities synced"}`)
+	mock.ExpectExec("INSERT INTO held_notifications").
+		WithArgs(7, "runner@example.com", true, "run_summary", payload, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, user_id, email, chat_only, kind, payload, release_at, created_at\\s+FROM held_notifications\\s+WHERE release_at <= \\$1").
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "email", "chat_only", "kind", "payload", "release_at", "created_at"}).
+			AddRow(1, 7, "runner@example.com", true, "run_summary", payload, now, now))
+	mock.ExpectExec("DELETE FROM held_notifications WHERE id = \\$1").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.HoldNotification(context.Background(), HeldNotification{
+		UserID: 7, Email: "runner@example.com", ChatOnly: true, Kind: "run_summary", Payload: payload, ReleaseAt: now,
+	})
+	if err != nil {
+		t.Fatalf("HoldNotification failed: %v", err)
+	}
+
+	held, err := repo.ListDueHeldNotifications(context.Background(), now, 100)
+	if err != nil {
+		t.Fatalf("ListDueHeldNotifications failed: %v", err)
+	}
+	if len(held) != 1 || held[0].ID != 1 || !held[0].ChatOnly || string(held[0].Payload) != string(payload) {
+		t.Errorf("Unexpected held notifications: %+v", held)
+	}
+
+	if err := repo.DeleteHeldNotification(context.Background(), 1); err != nil {
+		t.Fatalf("DeleteHeldNotification failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestEmailSuppressions(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewNotificationRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("runner@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("INSERT INTO email_suppressions").
+		WithArgs("runner@example.com", 550, "5.1.1 User unknown").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT email, smtp_code, reason, bounce_count").
+		WithArgs(50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"email", "smtp_code", "reason", "bounce_count", "created_at", "last_bounced_at"}).
+			AddRow("runner@example.com", 550, "5.1.1 User unknown", 2, now, now))
+	mock.ExpectExec("DELETE FROM email_suppressions").
+		WithArgs("runner@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	suppressed, err := repo.IsEmailSuppressed(context.Background(), "Runner@Example.com")
+	if err != nil || suppressed {
+		t.Errorf("Expected the address not to be suppressed, got %v (%v)", suppressed, err)
+	}
+	if err := repo.SuppressEmail(context.Background(), "Runner@Example.com", 550, "5.1.1 User unknown"); err != nil {
+		t.Errorf("SuppressEmail failed: %v", err)
+	}
+	suppressions, err := repo.ListEmailSuppressions(context.Background(), 50, 0)
+	if err != nil || len(suppressions) != 1 || suppressions[0].BounceCount != 2 {
+		t.Errorf("Unexpected suppressions: %+v (%v)", suppressions, err)
+	}
+	if err := repo.DeleteEmailSuppression(context.Background(), "runner@example.com"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an address that is not suppressed, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
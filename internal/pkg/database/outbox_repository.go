@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OutboxRepository handles database operations for the transactional job outbox
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new job outbox repository
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// queryRower is satisfied by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// AddOutboxJob records a job for publication, filling in its ID and creation time
+func (r *OutboxRepository) AddOutboxJob(ctx context.Context, job *OutboxJob) error {
+	return addOutboxJob(ctx, r.db, job)
+}
+
+// AddOutboxJobTx records a job within tx, so it is published only if the change that
+// triggered it commits
+func (r *OutboxRepository) AddOutboxJobTx(ctx context.Context, tx *sql.Tx, job *OutboxJob) error {
+	return addOutboxJob(ctx, tx, job)
+}
+
+func addOutboxJob(ctx context.Context, q queryRower, job *OutboxJob) error {
+	query := `
+		INSERT INTO job_outbox (user_id, trace_id, payload)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	return q.QueryRowContext(ctx, query, job.UserID, job.TraceID, job.Payload).Scan(&job.ID, &job.CreatedAt)
+}
+
+// PublishOutboxJobs hands up to limit unpublished jobs, oldest first, to publish and marks each
+// one published once publish succeeds. Rows are locked with SKIP LOCKED, so several relays can
+// run at once. The first failure is recorded on its row and ends the batch, keeping jobs in
+// order while the queue is down; it is returned along with the number of jobs published.
+// A job whose publication cannot be recorded is published again, so delivery is at least once.
+func (r *OutboxRepository) PublishOutboxJobs(ctx context.Context, limit int, publish func(ctx context.Context, job OutboxJob) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, user_id, trace_id, payload, attempts, created_at
+		FROM job_outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	var jobs []OutboxJob
+	for rows.Next() {
+		var job OutboxJob
+		if err := rows.Scan(&job.ID, &job.UserID, &job.TraceID, &job.Payload, &job.Attempts, &job.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var publishErr error
+	for _, job := range jobs {
+		if publishErr = publish(ctx, job); publishErr != nil {
+			if _, err := tx.ExecContext(ctx, `UPDATE job_outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, job.ID, publishErr.Error()); err != nil {
+				return 0, fmt.Errorf("failed to record outbox failure: %w", err)
+			}
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE job_outbox SET attempts = attempts + 1, published_at = CURRENT_TIMESTAMP WHERE id = $1`, job.ID); err != nil {
+			return 0, fmt.Errorf("failed to mark outbox job published: %w", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox transaction: %w", err)
+	}
+	return published, publishErr
+}
+
+// PruneOutboxJobs deletes jobs published before before and returns how many were removed
+func (r *OutboxRepository) PruneOutboxJobs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM job_outbox WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOutboxRepository_PublishOutboxJobs(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "trace_id", "payload", "attempts", "created_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, user_id, trace_id, payload, attempts, created_at FROM job_outbox WHERE published_at IS NULL ORDER BY id LIMIT \\$1 FOR UPDATE SKIP LOCKED").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 5, "trace-1", []byte(`{}`), 0, now).
+			AddRow(2, 5, "trace-2", []byte(`{}`), 0, now).
+			AddRow(3, 6, "trace-3", []byte(`{}`), 0, now))
+	mock.ExpectExec("UPDATE job_outbox SET attempts = attempts \\+ 1, published_at = CURRENT_TIMESTAMP").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE job_outbox SET attempts = attempts \\+ 1, last_error = \\$2").
+		WithArgs(int64(2), "redis down").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var traceIDs []string
+	published, err := NewOutboxRepository(db).PublishOutboxJobs(context.Background(), 10, func(ctx context.Context, job OutboxJob) error {
+		traceIDs = append(traceIDs, job.TraceID)
+		if job.ID == 2 {
+			return errors.New("redis down")
+		}
+		return nil
+	})
+
+	if err == nil || err.Error() != "redis down" {
+		t.Errorf("Expected the publish error, got %v", err)
+	}
+	// The batch stops at the first failure so jobs stay in order
+	if published != 1 || len(traceIDs) != 2 {
+		t.Errorf("Expected 1 published job and 2 attempts, got %d and %v", published, traceIDs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
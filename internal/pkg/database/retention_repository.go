@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetentionTables are the tables whose rows are pruned once older than the retention period:
+// automation runs and the sign-in and role change audit events
+var RetentionTables = []string{"automation_runs", "sign_in_events", "role_changes"}
+
+// retentionColumns is the timestamp that dates the rows of each retention table
+var retentionColumns = map[string]string{
+	"automation_runs": "started_at",
+	"sign_in_events":  "created_at",
+	"role_changes":    "created_at",
+}
+
+// ExpiredRow is a row past the retention period, with the whole row as a JSON object
+type ExpiredRow struct {
+	ID   int64
+	Data json.RawMessage
+}
+
+// RetentionRepository finds and deletes rows past the retention period
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db *sql.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// ListExpiredRows returns up to limit rows of table dated before before, oldest ID first
+func (r *RetentionRepository) ListExpiredRows(ctx context.Context, table string, before time.Time, limit int) ([]ExpiredRow, error) {
+	column, ok := retentionColumns[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention table %q", table)
+	}
+
+	// The table and column come from retentionColumns, never from input
+	query := fmt.Sprintf(`SELECT t.id, row_to_json(t) FROM %s t WHERE t.%s < $1 ORDER BY t.id LIMIT $2`, table, column)
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var expired []ExpiredRow
+	for rows.Next() {
+		var row ExpiredRow
+		var data []byte
+		if err := rows.Scan(&row.ID, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan expired %s row: %w", table, err)
+		}
+		row.Data = data
+		expired = append(expired, row)
+	}
+	return expired, rows.Err()
+}
+
+// DeleteRows deletes the rows of table with the given IDs and returns how many were removed
+func (r *RetentionRepository) DeleteRows(ctx context.Context, table string, ids []int64) (int64, error) {
+	if _, ok := retentionColumns[table]; !ok {
+		return 0, fmt.Errorf("unknown retention table %q", table)
+	}
+
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, table), pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestRetentionRepository(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewRetentionRepository(db)
+
+	before := time.Now().AddDate(0, 0, -180)
+	mock.ExpectQuery("SELECT t.id, row_to_json\\(t\\) FROM automation_runs t WHERE t.started_at < \\$1 ORDER BY t.id LIMIT \\$2").
+		WithArgs(before, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "row_to_json"}).
+			AddRow(3, []byte(`{"id":3,"status":"success"}`)).
+			AddRow(5, []byte(`{"id":5,"status":"failed"}`)))
+	mock.ExpectExec("DELETE FROM automation_runs WHERE id = ANY\\(\\$1\\)").
+		WithArgs(pq.Array([]int64{3, 5})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	rows, err := repo.ListExpiredRows(context.Background(), "automation_runs", before, 2)
+	if err != nil {
+		t.Fatalf("ListExpiredRows failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ID != 3 || string(rows[1].Data) != `{"id":5,"status":"failed"}` {
+		t.Errorf("Unexpected expired rows: %+v", rows)
+	}
+
+	deleted, err := repo.DeleteRows(context.Background(), "automation_runs", []int64{3, 5})
+	if err != nil || deleted != 2 {
+		t.Errorf("Expected 2 deleted rows, got %d (%v)", deleted, err)
+	}
+
+	// Only the retention tables may be pruned
+	if _, err := repo.ListExpiredRows(context.Background(), "users", before, 2); err == nil {
+		t.Error("Expected an unknown table to be refused")
+	}
+	if _, err := repo.DeleteRows(context.Background(), "users", []int64{1}); err == nil {
+		t.Error("Expected an unknown table to be refused")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
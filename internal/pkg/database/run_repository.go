@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RunRepository handles database operations for automation run records
+type RunRepository struct {
+	db *sql.DB
+}
+
+// NewRunRepository creates a new automation run repository
+func NewRunRepository(db *sql.DB) *RunRepository {
+	return &RunRepository{db: db}
+}
+
+// CreateRun records the start of an automation run and returns its ID
+func (r *RunRepository) CreateRun(ctx context.Context, req *CreateRunRequest) (int, error) {
+	query := `
+		INSERT INTO automation_runs (
+			user_id, trace_id, trigger_type, is_test_mode, dry_run, status, started_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var traceID *string
+	if req.TraceID != "" {
+		traceID = &req.TraceID
+	}
+
+	var id int
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		req.UserID,
+		traceID,
+		req.TriggerType,
+		req.IsTestMode,
+		req.DryRun,
+		RunStatusRunning,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CompleteRun records the outcome of an automation run
+func (r *RunRepository) CompleteRun(ctx context.Context, runID int, status string, activitiesCount int, errorType, errorMessage string) error {
+	query := `
+		UPDATE automation_runs 
+		SET status = $1, activities_count = $2, error_type = $3, error_message = $4, completed_at = $5
+		WHERE id = $6
+	`
+
+	result, err := r.db.ExecContext(ctx, query, status, activitiesCount, nullIfEmpty(errorType), nullIfEmpty(errorMessage), time.Now(), runID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListUsersWithRuns returns the IDs of users with at least one real run started in [from, to)
+// Test-mode and dry-run runs are excluded because they never wrote to a user's destination
+func (r *RunRepository) ListUsersWithRuns(ctx context.Context, from, to time.Time) ([]int, error) {
+	query := `
+		SELECT DISTINCT user_id FROM automation_runs
+		WHERE started_at >= $1 AND started_at < $2
+		  AND is_test_mode = false AND dry_run = false
+		ORDER BY user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// ListRuns returns a user's most recent runs, newest first, leaving out test-mode runs
+func (r *RunRepository) ListRuns(ctx context.Context, userID, limit int) ([]AutomationRun, error) {
+	query := `
+		SELECT id, user_id, trace_id, trigger_type, is_test_mode, dry_run, status, activities_count,
+		       error_type, error_message, started_at, completed_at,
+		       sheet_writes IS NOT NULL AND undone_at IS NULL AS undoable, undone_at
+		FROM automation_runs
+		WHERE user_id = $1 AND is_test_mode = false
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []AutomationRun{}
+	for rows.Next() {
+		var run AutomationRun
+		if err := rows.Scan(&run.ID, &run.UserID, &run.TraceID, &run.TriggerType, &run.IsTestMode, &run.DryRun,
+			&run.Status, &run.ActivitiesCount, &run.ErrorType, &run.ErrorMessage, &run.StartedAt, &run.CompletedAt,
+			&run.Undoable, &run.UndoneAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// RecordSheetWrites stores the JSON-encoded rows a run wrote to the spreadsheet so it can be undone
+func (r *RunRepository) RecordSheetWrites(ctx context.Context, runID int, writes []byte) error {
+	query := `UPDATE automation_runs SET sheet_writes = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, writes, runID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetRunUndo returns the undo state of one of a user's runs, or sql.ErrNoRows when the user has
+// no such run
+func (r *RunRepository) GetRunUndo(ctx context.Context, userID, runID int) (*RunUndo, error) {
+	query := `
+		SELECT r.id, r.sheet_writes, r.undo_token_hash, r.undo_token_expires_at, r.undone_at,
+		       EXISTS (
+		           SELECT 1 FROM automation_runs later
+		           WHERE later.user_id = r.user_id AND later.id > r.id
+		             AND later.sheet_writes IS NOT NULL AND later.undone_at IS NULL
+		       ) AS superseded
+		FROM automation_runs r
+		WHERE r.id = $1 AND r.user_id = $2
+	`
+
+	var undo RunUndo
+	err := r.db.QueryRowContext(ctx, query, runID, userID).Scan(
+		&undo.RunID,
+		&undo.SheetWrites,
+		&undo.UndoTokenHash,
+		&undo.UndoTokenExpiresAt,
+		&undo.UndoneAt,
+		&undo.Superseded,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &undo, nil
+}
+
+// SetUndoToken stores the hash of the token confirming an undo of the run, replacing any earlier one
+func (r *RunRepository) SetUndoToken(ctx context.Context, runID int, tokenHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE automation_runs
+		SET undo_token_hash = $1, undo_token_expires_at = $2
+		WHERE id = $3 AND undone_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tokenHash, expiresAt, runID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimRunUndo marks the run undone if tokenHash matches its unexpired undo token, consuming the
+// token. It returns sql.ErrNoRows when the token does not match, has expired or the run was
+// already undone, so concurrent confirmations undo the run at most once.
+func (r *RunRepository) ClaimRunUndo(ctx context.Context, runID int, tokenHash string, now time.Time) error {
+	query := `
+		UPDATE automation_runs
+		SET undone_at = $1, undo_token_hash = NULL, undo_token_expires_at = NULL
+		WHERE id = $2 AND undo_token_hash = $3 AND undo_token_expires_at > $1 AND undone_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, now, runID, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReleaseRunUndo clears the undone mark set by ClaimRunUndo after the undo itself failed
+func (r *RunRepository) ReleaseRunUndo(ctx context.Context, runID int) error {
+	query := `UPDATE automation_runs SET undone_at = NULL WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, runID)
+	return err
+}
+
+// nullIfEmpty maps empty strings to NULL
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const createRunQuery = `
+		INSERT INTO automation_runs (
+			user_id, trace_id, trigger_type, is_test_mode, dry_run, status, started_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+const completeRunQuery = `
+		UPDATE automation_runs 
+		SET status = $1, activities_count = $2, error_type = $3, error_message = $4, completed_at = $5
+		WHERE id = $6
+	`
+
+func TestCreateRun(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRunRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(createRunQuery)).
+		WithArgs(1, nil, "test_mode", true, false, RunStatusRunning, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+
+	runID, err := repo.CreateRun(context.Background(), &CreateRunRequest{
+		UserID:      1,
+		TriggerType: "test_mode",
+		IsTestMode:  true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	if runID != 42 {
+		t.Errorf("Expected run ID 42, got %d", runID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestCompleteRun(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+
+		mock.ExpectExec(regexp.QuoteMeta(completeRunQuery)).
+			WithArgs(RunStatusCompleted, 3, nil, nil, sqlmock.AnyArg(), 42).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		if err := repo.CompleteRun(context.Background(), 42, RunStatusCompleted, 3, "", ""); err != nil {
+			t.Fatalf("CompleteRun failed: %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("RunNotFound", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+
+		mock.ExpectExec(regexp.QuoteMeta(completeRunQuery)).
+			WithArgs(RunStatusFailed, 0, "SHEETS_WRITE_ERROR", "write failed", sqlmock.AnyArg(), 99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.CompleteRun(context.Background(), 99, RunStatusFailed, 0, "SHEETS_WRITE_ERROR", "write failed")
+		if err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
+
+func TestListUsersWithRuns(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRunRepository(db)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	mock.ExpectQuery("SELECT DISTINCT user_id FROM automation_runs").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(3).AddRow(8))
+
+	userIDs, err := repo.ListUsersWithRuns(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("ListUsersWithRuns failed: %v", err)
+	}
+	if len(userIDs) != 2 || userIDs[0] != 3 || userIDs[1] != 8 {
+		t.Errorf("Expected users [3 8], got %v", userIDs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRecordSheetWrites(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRunRepository(db)
+	writes := []byte(`{"spreadsheet_id":"sheet-1","rows":[{"action":"append","activity_id":7}]}`)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE automation_runs SET sheet_writes = $1 WHERE id = $2`)).
+		WithArgs(writes, 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordSheetWrites(context.Background(), 42, writes); err != nil {
+		t.Fatalf("RecordSheetWrites failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetRunUndo(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+		expiresAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+		mock.ExpectQuery("FROM automation_runs r").
+			WithArgs(42, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sheet_writes", "undo_token_hash", "undo_token_expires_at", "undone_at", "superseded"}).
+				AddRow(42, []byte(`{"rows":[]}`), "abc", expiresAt, nil, true))
+
+		undo, err := repo.GetRunUndo(context.Background(), 1, 42)
+		if err != nil {
+			t.Fatalf("GetRunUndo failed: %v", err)
+		}
+		if undo.RunID != 42 || string(undo.SheetWrites) != `{"rows":[]}` || undo.UndoTokenHash == nil || *undo.UndoTokenHash != "abc" ||
+			undo.UndoTokenExpiresAt == nil || !undo.UndoTokenExpiresAt.Equal(expiresAt) || undo.UndoneAt != nil || !undo.Superseded {
+			t.Errorf("Unexpected undo state: %+v", undo)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("RunNotFound", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+
+		mock.ExpectQuery("FROM automation_runs r").
+			WithArgs(99, 1).
+			WillReturnError(sql.ErrNoRows)
+
+		if _, err := repo.GetRunUndo(context.Background(), 1, 99); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
+
+func TestClaimRunUndo(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+
+		mock.ExpectExec("UPDATE automation_runs SET undone_at = \\$1").
+			WithArgs(now, 42, "abc").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		if err := repo.ClaimRunUndo(context.Background(), 42, "abc", now); err != nil {
+			t.Fatalf("ClaimRunUndo failed: %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("TokenMismatch", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewRunRepository(db)
+
+		mock.ExpectExec("UPDATE automation_runs SET undone_at = \\$1").
+			WithArgs(now, 42, "wrong").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		if err := repo.ClaimRunUndo(context.Background(), 42, "wrong", now); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
@@ -3,12 +3,17 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
 // SessionRepository handles database operations for user sessions
 type SessionRepository struct {
 	db *sql.DB
+
+	// Sliding expiration (see SetSlidingExpiration); zero keeps the expiry set at creation
+	idleTimeout time.Duration
+	maxLifetime time.Duration
 }
 
 // NewSessionRepository creates a new session repository
@@ -16,6 +21,24 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
+// SetSlidingExpiration makes each use of a session extend its expiry to idleTimeout from then,
+// but never beyond maxLifetime after the session was created. Sessions older than maxLifetime
+// are treated as expired even if they were extended under a longer lifetime.
+func (r *SessionRepository) SetSlidingExpiration(idleTimeout, maxLifetime time.Duration) {
+	r.idleTimeout = idleTimeout
+	r.maxLifetime = maxLifetime
+}
+
+// withinLifetime adds the absolute lifetime condition to a session query and its arguments, so
+// sessions created more than maxLifetime before now do not match
+func (r *SessionRepository) withinLifetime(query string, args []interface{}, now time.Time) (string, []interface{}) {
+	if r.maxLifetime <= 0 {
+		return query, args
+	}
+	args = append(args, now.Add(-r.maxLifetime))
+	return query + fmt.Sprintf(" AND created_at > $%d", len(args)), args
+}
+
 // CreateSession creates a new user session in the database
 func (r *SessionRepository) CreateSession(ctx context.Context, req *CreateSessionRequest) (*UserSession, error) {
 	query := `
@@ -71,9 +94,11 @@ func (r *SessionRepository) GetSessionByID(ctx context.Context, sessionID int) (
 		FROM user_sessions 
 		WHERE id = $1 AND is_active = true AND expires_at > $2
 	`
+	now := time.Now()
+	query, args := r.withinLifetime(query, []interface{}{sessionID, now}, now)
 
 	var session UserSession
-	err := r.db.QueryRowContext(ctx, query, sessionID, time.Now()).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&session.ID, &session.UserID, &session.SessionToken,
 		&session.UserAgent, &session.IPAddress,
 		&session.CreatedAt, &session.ExpiresAt, &session.LastUsedAt, &session.IsActive,
@@ -97,9 +122,11 @@ func (r *SessionRepository) GetSessionByToken(ctx context.Context, token string)
 		FROM user_sessions 
 		WHERE session_token = $1 AND is_active = true AND expires_at > $2
 	`
+	now := time.Now()
+	query, args := r.withinLifetime(query, []interface{}{token, now}, now)
 
 	var session UserSession
-	err := r.db.QueryRowContext(ctx, query, token, time.Now()).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&session.ID, &session.UserID, &session.SessionToken,
 		&session.UserAgent, &session.IPAddress,
 		&session.CreatedAt, &session.ExpiresAt, &session.LastUsedAt, &session.IsActive,
@@ -115,11 +142,27 @@ func (r *SessionRepository) GetSessionByToken(ctx context.Context, token string)
 	return &session, nil
 }
 
-// UpdateSessionLastUsed updates the last used timestamp for a session
-func (r *SessionRepository) UpdateSessionLastUsed(ctx context.Context, sessionID int) error {
-	query := `UPDATE user_sessions SET last_used_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), sessionID)
-	return err
+// UpdateSessionLastUsed updates the last used timestamp for a session and returns its expiry.
+// With sliding expiration the expiry of an active session moves to idleTimeout from now, capped
+// at maxLifetime after creation; it returns sql.ErrNoRows when the session has already expired.
+func (r *SessionRepository) UpdateSessionLastUsed(ctx context.Context, sessionID int) (time.Time, error) {
+	now := time.Now()
+	query := `UPDATE user_sessions SET last_used_at = $1 WHERE id = $2 RETURNING expires_at`
+	args := []interface{}{now, sessionID}
+	if r.idleTimeout > 0 && r.maxLifetime > 0 {
+		query = `
+			UPDATE user_sessions
+			SET last_used_at = $1,
+			    expires_at = GREATEST(expires_at, LEAST($3::timestamptz, created_at + $4 * INTERVAL '1 second'))
+			WHERE id = $2 AND is_active = true AND expires_at > $1
+			RETURNING expires_at
+		`
+		args = append(args, now.Add(r.idleTimeout), r.maxLifetime.Seconds())
+	}
+
+	var expiresAt time.Time
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&expiresAt)
+	return expiresAt, err
 }
 
 // UpdateSessionToken updates the session token for an existing session
@@ -129,6 +172,76 @@ func (r *SessionRepository) UpdateSessionToken(ctx context.Context, sessionID in
 	return err
 }
 
+// SetRefreshTokenHash stores the hash of a session's first refresh token. Sessions that already
+// have one are left alone, as only rotation may replace it; the result reports whether it was set.
+func (r *SessionRepository) SetRefreshTokenHash(ctx context.Context, sessionID int, hash string) (bool, error) {
+	query := `
+		UPDATE user_sessions SET refresh_token_hash = $1, refresh_rotated_at = $2
+		WHERE id = $3 AND refresh_token_hash IS NULL AND is_active = true
+	`
+	result, err := r.db.ExecContext(ctx, query, hash, time.Now(), sessionID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// GetSessionByRefreshTokenHash retrieves the session whose current or previous refresh token has
+// the given hash, whether or not it is still active, so callers can detect reuse of a rotated token
+func (r *SessionRepository) GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*UserSession, error) {
+	query := `
+		SELECT id, user_id, session_token, user_agent, ip_address,
+			   created_at, expires_at, last_used_at, is_active,
+			   refresh_token_hash, previous_refresh_token_hash, refresh_rotated_at
+		FROM user_sessions
+		WHERE refresh_token_hash = $1 OR previous_refresh_token_hash = $1
+		LIMIT 1
+	`
+
+	var session UserSession
+	err := r.db.QueryRowContext(ctx, query, hash).Scan(
+		&session.ID, &session.UserID, &session.SessionToken,
+		&session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.ExpiresAt, &session.LastUsedAt, &session.IsActive,
+		&session.RefreshTokenHash, &session.PreviousRefreshTokenHash, &session.RefreshRotatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Unknown refresh token
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RotateRefreshToken replaces the session's refresh token hash currentHash with newHash, keeping
+// currentHash to recognize its reuse. It reports false when currentHash is no longer current,
+// e.g. because a concurrent refresh rotated it first.
+func (r *SessionRepository) RotateRefreshToken(ctx context.Context, sessionID int, currentHash, newHash string) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET previous_refresh_token_hash = refresh_token_hash, refresh_token_hash = $1, refresh_rotated_at = $2
+		WHERE id = $3 AND refresh_token_hash = $4 AND is_active = true AND expires_at > $2
+	`
+	now := time.Now()
+	query, args := r.withinLifetime(query, []interface{}{newHash, now, sessionID, currentHash}, now)
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
 // DeactivateSession marks a session as inactive (logout)
 func (r *SessionRepository) DeactivateSession(ctx context.Context, sessionID int) error {
 	query := `UPDATE user_sessions SET is_active = false WHERE id = $1`
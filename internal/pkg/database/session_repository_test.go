@@ -353,6 +353,152 @@ func TestDeactivateSession(t *testing.T) {
 	})
 }
 
+func TestRotateRefreshToken(t *testing.T) {
+	t.Run("CurrentToken", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewSessionRepository(db)
+
+		mock.ExpectExec(regexp.QuoteMeta("SET previous_refresh_token_hash = refresh_token_hash, refresh_token_hash = $1")).
+			WithArgs("new-hash", sqlmock.AnyArg(), 1, "current-hash").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		rotated, err := repo.RotateRefreshToken(context.Background(), 1, "current-hash", "new-hash")
+		if err != nil {
+			t.Fatalf("RotateRefreshToken failed: %v", err)
+		}
+		if !rotated {
+			t.Error("Expected the current refresh token to be rotated")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("AlreadyRotated", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewSessionRepository(db)
+
+		mock.ExpectExec(regexp.QuoteMeta("SET previous_refresh_token_hash = refresh_token_hash, refresh_token_hash = $1")).
+			WithArgs("new-hash", sqlmock.AnyArg(), 1, "stale-hash").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		rotated, err := repo.RotateRefreshToken(context.Background(), 1, "stale-hash", "new-hash")
+		if err != nil {
+			t.Fatalf("RotateRefreshToken failed: %v", err)
+		}
+		if rotated {
+			t.Error("Expected a stale refresh token not to be rotated")
+		}
+	})
+}
+
+func TestGetSessionByRefreshTokenHash(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE refresh_token_hash = $1 OR previous_refresh_token_hash = $1")).
+		WithArgs("old-hash").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "session_token", "user_agent", "ip_address",
+			"created_at", "expires_at", "last_used_at", "is_active",
+			"refresh_token_hash", "previous_refresh_token_hash", "refresh_rotated_at",
+		}).AddRow(1, 123, "access", nil, nil, now, now.Add(time.Hour), now, true, "new-hash", "old-hash", now))
+
+	session, err := repo.GetSessionByRefreshTokenHash(context.Background(), "old-hash")
+	if err != nil {
+		t.Fatalf("GetSessionByRefreshTokenHash failed: %v", err)
+	}
+	if session == nil || session.PreviousRefreshTokenHash == nil || *session.PreviousRefreshTokenHash != "old-hash" {
+		t.Fatalf("Expected the session rotated away from old-hash, got %+v", session)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE refresh_token_hash = $1 OR previous_refresh_token_hash = $1")).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+	if session, err := repo.GetSessionByRefreshTokenHash(context.Background(), "unknown"); err != nil || session != nil {
+		t.Errorf("Expected no session for an unknown hash, got %+v, %v", session, err)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestUpdateSessionLastUsed(t *testing.T) {
+	t.Run("FixedExpiry", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		expiresAt := time.Now().Add(time.Hour)
+		mock.ExpectQuery(regexp.QuoteMeta("UPDATE user_sessions SET last_used_at = $1 WHERE id = $2 RETURNING expires_at")).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(expiresAt))
+
+		got, err := NewSessionRepository(db).UpdateSessionLastUsed(context.Background(), 1)
+		if err != nil || !got.Equal(expiresAt) {
+			t.Errorf("Expected the unchanged expiry, got %s, %v", got, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("SlidingExpiry", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		defer db.Close()
+
+		repo := NewSessionRepository(db)
+		repo.SetSlidingExpiration(24*time.Hour, 7*24*time.Hour)
+
+		extended := time.Now().Add(24 * time.Hour)
+		mock.ExpectQuery(regexp.QuoteMeta("expires_at = GREATEST(expires_at, LEAST($3::timestamptz, created_at + $4 * INTERVAL '1 second'))")).
+			WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg(), float64(7*24*60*60)).
+			WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(extended))
+		// An expired session is not revived
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $2 AND is_active = true AND expires_at > $1")).
+			WithArgs(sqlmock.AnyArg(), 2, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"expires_at"}))
+
+		got, err := repo.UpdateSessionLastUsed(context.Background(), 1)
+		if err != nil || !got.Equal(extended) {
+			t.Errorf("Expected the extended expiry, got %s, %v", got, err)
+		}
+		if _, err := repo.UpdateSessionLastUsed(context.Background(), 2); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows for an expired session, got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestGetSessionByToken_AbsoluteLifetime(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	repo.SetSlidingExpiration(24*time.Hour, 7*24*time.Hour)
+
+	// Sessions created more than the maximum lifetime ago no longer match
+	mock.ExpectQuery(regexp.QuoteMeta("AND expires_at > $2 AND created_at > $3")).
+		WithArgs("token", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "session_token", "user_agent", "ip_address",
+			"created_at", "expires_at", "last_used_at", "is_active",
+		}))
+
+	session, err := repo.GetSessionByToken(context.Background(), "token")
+	if err != nil || session != nil {
+		t.Errorf("Expected no session, got %+v, %v", session, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
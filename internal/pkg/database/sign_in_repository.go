@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SignInRepository handles database operations for the sign-in audit log
+type SignInRepository struct {
+	db *sql.DB
+}
+
+// NewSignInRepository creates a new sign-in repository
+func NewSignInRepository(db *sql.DB) *SignInRepository {
+	return &SignInRepository{db: db}
+}
+
+// ListSignInOrigins returns the distinct user agents and IP addresses of the user's sessions and
+// sign-ins since the given time, except those of excludeSessionID (the sign-in being checked)
+func (r *SignInRepository) ListSignInOrigins(ctx context.Context, userID, excludeSessionID int, since time.Time) ([]SignInOrigin, error) {
+	query := `
+		SELECT COALESCE(user_agent, ''), COALESCE(host(ip_address), '')
+		FROM user_sessions
+		WHERE user_id = $1 AND id <> $2 AND created_at > $3
+		UNION
+		SELECT COALESCE(user_agent, ''), COALESCE(host(ip_address), '')
+		FROM sign_in_events
+		WHERE user_id = $1 AND session_id IS DISTINCT FROM $2 AND created_at > $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, excludeSessionID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var origins []SignInOrigin
+	for rows.Next() {
+		var origin SignInOrigin
+		if err := rows.Scan(&origin.UserAgent, &origin.IPAddress); err != nil {
+			return nil, err
+		}
+		origins = append(origins, origin)
+	}
+
+	return origins, rows.Err()
+}
+
+// RecordSignIn adds event to the audit log, filling in its ID and creation time
+func (r *SignInRepository) RecordSignIn(ctx context.Context, event *SignInEvent) error {
+	query := `
+		INSERT INTO sign_in_events (user_id, session_id, ip_address, user_agent, device, network, new_device, new_location)
+		VALUES ($1, $2, NULLIF($3, '')::inet, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, event.UserID, event.SessionID, event.IPAddress, event.UserAgent,
+		event.Device, event.Network, event.NewDevice, event.NewLocation).Scan(&event.ID, &event.CreatedAt)
+}
+
+// ListSignInAlerts returns new-device and new-location sign-ins made after the given time whose
+// notification has not been sent, oldest first
+func (r *SignInRepository) ListSignInAlerts(ctx context.Context, after time.Time, limit int) ([]SignInAlert, error) {
+	query := `
+		SELECT e.id, e.user_id, e.session_id, COALESCE(host(e.ip_address), ''), COALESCE(e.user_agent, ''),
+			e.device, e.network, e.new_device, e.new_location, e.created_at,
+			COALESCE(u.email, ''), COALESCE(u.name, ''), u.locale
+		FROM sign_in_events e
+		JOIN users u ON u.id = e.user_id
+		WHERE (e.new_device OR e.new_location) AND e.notified_at IS NULL AND e.created_at > $1
+		ORDER BY e.created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []SignInAlert
+	for rows.Next() {
+		var alert SignInAlert
+		err := rows.Scan(
+			&alert.ID, &alert.UserID, &alert.SessionID, &alert.IPAddress, &alert.UserAgent,
+			&alert.Device, &alert.Network, &alert.NewDevice, &alert.NewLocation, &alert.CreatedAt,
+			&alert.Email, &alert.Name, &alert.Locale,
+		)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// SetSignInRevokeToken stores the hash of the token in a sign-in's revoke link, replacing any
+// link issued for it before
+func (r *SignInRepository) SetSignInRevokeToken(ctx context.Context, eventID int, tokenHash string, expiresAt time.Time) error {
+	query := `UPDATE sign_in_events SET revoke_token_hash = $1, revoke_expires_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, tokenHash, expiresAt, eventID)
+	return err
+}
+
+// MarkSignInNotified records that the sign-in's notification was sent
+func (r *SignInRepository) MarkSignInNotified(ctx context.Context, eventID int, notifiedAt time.Time) error {
+	query := `UPDATE sign_in_events SET notified_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, notifiedAt, eventID)
+	return err
+}
+
+// RevokeSignInSession signs out the session of the sign-in whose revoke link carries the token
+// with tokenHash, returning the sign-in. Each link works once and only until it expires;
+// sql.ErrNoRows is returned for unknown, used and expired links.
+func (r *SignInRepository) RevokeSignInSession(ctx context.Context, tokenHash string) (*SignInEvent, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin revoke transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sign_in_events SET revoked_at = $2
+		WHERE revoke_token_hash = $1 AND revoked_at IS NULL AND revoke_expires_at > $2
+		RETURNING id, user_id, session_id, device, network, created_at
+	`
+
+	var event SignInEvent
+	err = tx.QueryRowContext(ctx, query, tokenHash, time.Now()).
+		Scan(&event.ID, &event.UserID, &event.SessionID, &event.Device, &event.Network, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.SessionID != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE user_sessions SET is_active = false WHERE id = $1`, *event.SessionID); err != nil {
+			return nil, fmt.Errorf("failed to deactivate session: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit revoke transaction: %w", err)
+	}
+	return &event, nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSignInRepository_RevokeSignInSession(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	claim := "UPDATE sign_in_events SET revoked_at"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs("token-hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "session_id", "device", "network", "created_at"}).
+			AddRow(3, 1, 5, "Safari on iOS", "203.0.113.0/24", createdAt))
+	mock.ExpectExec("UPDATE user_sessions SET is_active = false").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A used or expired link signs nothing out
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs("used-hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "session_id", "device", "network", "created_at"}))
+	mock.ExpectRollback()
+
+	repo := NewSignInRepository(db)
+	event, err := repo.RevokeSignInSession(context.Background(), "token-hash")
+	if err != nil {
+		t.Fatalf("RevokeSignInSession failed: %v", err)
+	}
+	if event.ID != 3 || event.SessionID == nil || *event.SessionID != 5 {
+		t.Errorf("Unexpected sign-in: %+v", event)
+	}
+
+	if _, err := repo.RevokeSignInSession(context.Background(), "used-hash"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a used link, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
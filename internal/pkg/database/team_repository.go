@@ -0,0 +1,336 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Errors returned by InviteAthlete
+var (
+	// ErrAlreadyInvited is returned when the athlete is already invited to or on the team
+	ErrAlreadyInvited = errors.New("athlete is already invited to the team")
+	// ErrInviteCoach is returned when a coach invites themselves to their own team
+	ErrInviteCoach = errors.New("coaches cannot join their own team")
+)
+
+// TeamRepository handles database operations for coaches' teams and their athletes
+type TeamRepository struct {
+	db *sql.DB
+}
+
+// NewTeamRepository creates a new team repository
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// CreateTeam stores team, filling in its ID and creation time
+func (r *TeamRepository) CreateTeam(ctx context.Context, team *Team) error {
+	query := `
+		INSERT INTO teams (name, coach_user_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, team.Name, team.CoachUserID).Scan(&team.ID, &team.CreatedAt)
+}
+
+// GetTeam returns a team, or sql.ErrNoRows if it does not exist
+func (r *TeamRepository) GetTeam(ctx context.Context, teamID int) (*Team, error) {
+	query := `SELECT id, name, coach_user_id, spreadsheet_id, created_at FROM teams WHERE id = $1`
+
+	var team Team
+	err := r.db.QueryRowContext(ctx, query, teamID).Scan(&team.ID, &team.Name, &team.CoachUserID, &team.SpreadsheetID, &team.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// ListTeamsByCoach returns the teams a coach owns, oldest first
+func (r *TeamRepository) ListTeamsByCoach(ctx context.Context, coachID int) ([]Team, error) {
+	query := `
+		SELECT id, name, coach_user_id, spreadsheet_id, created_at
+		FROM teams
+		WHERE coach_user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, coachID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.CoachUserID, &team.SpreadsheetID, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
+
+// SetTeamSpreadsheet sets or, with a nil ID, clears the team's shared spreadsheet. It returns
+// sql.ErrNoRows if the team does not exist.
+func (r *TeamRepository) SetTeamSpreadsheet(ctx context.Context, teamID int, spreadsheetID *string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE teams SET spreadsheet_id = $1 WHERE id = $2`, spreadsheetID, teamID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// InviteAthlete invites the user with email to a team. It returns sql.ErrNoRows if no user has
+// that email, ErrInviteCoach if they are the team's coach and ErrAlreadyInvited if they are
+// already invited or a member.
+func (r *TeamRepository) InviteAthlete(ctx context.Context, teamID int, email string) (*TeamAthlete, error) {
+	lookup := `
+		SELECT u.id, u.name, u.email, u.id = t.coach_user_id
+		FROM users u, teams t
+		WHERE t.id = $1 AND LOWER(u.email) = LOWER($2)
+	`
+
+	athlete := TeamAthlete{Status: TeamMemberInvited}
+	var isCoach bool
+	err := r.db.QueryRowContext(ctx, lookup, teamID, email).Scan(&athlete.UserID, &athlete.Name, &athlete.Email, &isCoach)
+	if err != nil {
+		return nil, err
+	}
+	if isCoach {
+		return nil, ErrInviteCoach
+	}
+
+	query := `
+		INSERT INTO team_members (team_id, user_id, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_id, user_id) DO NOTHING
+		RETURNING invited_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query, teamID, athlete.UserID, TeamMemberInvited).Scan(&athlete.InvitedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlreadyInvited
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &athlete, nil
+}
+
+// ListTeamAthletes returns the athletes invited to a team, members first, then by name
+func (r *TeamRepository) ListTeamAthletes(ctx context.Context, teamID int) ([]TeamAthlete, error) {
+	query := `
+		SELECT u.id, u.name, u.email, tm.status, tm.invited_at, tm.accepted_at
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1
+		ORDER BY tm.status = $2 DESC, u.name, u.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, teamID, TeamMemberActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	athletes := []TeamAthlete{}
+	for rows.Next() {
+		var athlete TeamAthlete
+		if err := rows.Scan(&athlete.UserID, &athlete.Name, &athlete.Email, &athlete.Status, &athlete.InvitedAt, &athlete.AcceptedAt); err != nil {
+			return nil, err
+		}
+		athletes = append(athletes, athlete)
+	}
+
+	return athletes, rows.Err()
+}
+
+// ListInvitations returns a user's pending team invitations, newest first
+func (r *TeamRepository) ListInvitations(ctx context.Context, userID int) ([]TeamInvitation, error) {
+	query := `
+		SELECT t.id, t.name, c.name, tm.invited_at
+		FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id
+		JOIN users c ON c.id = t.coach_user_id
+		WHERE tm.user_id = $1 AND tm.status = $2
+		ORDER BY tm.invited_at DESC, t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, TeamMemberInvited)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []TeamInvitation{}
+	for rows.Next() {
+		var invitation TeamInvitation
+		if err := rows.Scan(&invitation.TeamID, &invitation.TeamName, &invitation.CoachName, &invitation.InvitedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// AcceptInvitation makes the user a member of the team they were invited to. It returns
+// sql.ErrNoRows if there is no pending invitation.
+func (r *TeamRepository) AcceptInvitation(ctx context.Context, teamID, userID int) error {
+	query := `
+		UPDATE team_members
+		SET status = $1, accepted_at = $2
+		WHERE team_id = $3 AND user_id = $4 AND status = $5
+	`
+
+	result, err := r.db.ExecContext(ctx, query, TeamMemberActive, time.Now(), teamID, userID, TeamMemberInvited)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RemoveTeamMember removes a member from a team or withdraws their invitation. It returns
+// sql.ErrNoRows if the user was neither invited nor a member.
+func (r *TeamRepository) RemoveTeamMember(ctx context.Context, teamID, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CoachesAthlete reports whether the athlete has accepted an invitation to one of the coach's teams
+func (r *TeamRepository) CoachesAthlete(ctx context.Context, coachID, athleteID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM team_members tm
+			JOIN teams t ON t.id = tm.team_id
+			WHERE t.coach_user_id = $1 AND tm.user_id = $2 AND tm.status = $3
+		)
+	`
+
+	var coaches bool
+	err := r.db.QueryRowContext(ctx, query, coachID, athleteID, TeamMemberActive).Scan(&coaches)
+	return coaches, err
+}
+
+// ListAthleteStatuses returns the sync status of a team's members, by name. Athletes who have not
+// accepted their invitation are left out.
+func (r *TeamRepository) ListAthleteStatuses(ctx context.Context, teamID int) ([]AthleteSyncStatus, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.automation_enabled, u.suspended_at IS NOT NULL,
+		       u.strava_refresh_token IS NOT NULL, u.spreadsheet_id IS NOT NULL,
+		       (SELECT MAX(ar.completed_at) FROM automation_runs ar
+		        WHERE ar.user_id = u.id AND ar.status = $3
+		          AND NOT ar.is_test_mode AND NOT ar.dry_run),
+		       lr.id, lr.trace_id, lr.trigger_type, lr.dry_run, lr.status, lr.activities_count,
+		       lr.error_type, lr.error_message, lr.started_at, lr.completed_at
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		LEFT JOIN LATERAL (
+			SELECT id, trace_id, trigger_type, dry_run, status, activities_count,
+			       error_type, error_message, started_at, completed_at
+			FROM automation_runs
+			WHERE user_id = u.id AND NOT is_test_mode
+			ORDER BY started_at DESC
+			LIMIT 1
+		) lr ON true
+		WHERE tm.team_id = $1 AND tm.status = $2
+		ORDER BY u.name, u.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, teamID, TeamMemberActive, RunStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []AthleteSyncStatus{}
+	for rows.Next() {
+		var (
+			status          AthleteSyncStatus
+			runID           sql.NullInt64
+			traceID         sql.NullString
+			triggerType     sql.NullString
+			dryRun          sql.NullBool
+			runStatus       sql.NullString
+			activitiesCount sql.NullInt64
+			errorType       sql.NullString
+			errorMessage    sql.NullString
+			startedAt       sql.NullTime
+			completedAt     sql.NullTime
+		)
+		if err := rows.Scan(
+			&status.UserID, &status.Name, &status.Email, &status.AutomationEnabled, &status.Suspended,
+			&status.StravaConnected, &status.SheetConfigured, &status.LastSyncAt,
+			&runID, &traceID, &triggerType, &dryRun, &runStatus, &activitiesCount,
+			&errorType, &errorMessage, &startedAt, &completedAt,
+		); err != nil {
+			return nil, err
+		}
+		if runID.Valid {
+			status.LastRun = &AutomationRun{
+				ID:              int(runID.Int64),
+				UserID:          status.UserID,
+				TraceID:         nullStringPtr(traceID),
+				TriggerType:     triggerType.String,
+				DryRun:          dryRun.Bool,
+				Status:          runStatus.String,
+				ActivitiesCount: int(activitiesCount.Int64),
+				ErrorType:       nullStringPtr(errorType),
+				ErrorMessage:    nullStringPtr(errorMessage),
+				StartedAt:       startedAt.Time,
+			}
+			if completedAt.Valid {
+				status.LastRun.CompletedAt = &completedAt.Time
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
+
+// nullStringPtr maps NULL to nil
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
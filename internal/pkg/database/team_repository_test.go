@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTeamRepository_InviteAthlete(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	lookup := "SELECT u.id, u.name, u.email, u.id = t.coach_user_id"
+	insert := "INSERT INTO team_members \\(team_id, user_id, status\\)"
+
+	mock.ExpectQuery(lookup).
+		WithArgs(3, "Runner@Example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "is_coach"}).AddRow(7, "Runner", "runner@example.com", false))
+	mock.ExpectQuery(insert).
+		WithArgs(3, 7, TeamMemberInvited).
+		WillReturnRows(sqlmock.NewRows([]string{"invited_at"}).AddRow(now))
+
+	// Inviting the same athlete again is a conflict
+	mock.ExpectQuery(lookup).
+		WithArgs(3, "runner@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "is_coach"}).AddRow(7, "Runner", "runner@example.com", false))
+	mock.ExpectQuery(insert).
+		WithArgs(3, 7, TeamMemberInvited).
+		WillReturnRows(sqlmock.NewRows([]string{"invited_at"}))
+
+	// The coach cannot join their own team
+	mock.ExpectQuery(lookup).
+		WithArgs(3, "coach@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "is_coach"}).AddRow(2, "Coach", "coach@example.com", true))
+
+	mock.ExpectQuery(lookup).
+		WithArgs(3, "nobody@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "is_coach"}))
+
+	repo := NewTeamRepository(db)
+	athlete, err := repo.InviteAthlete(context.Background(), 3, "Runner@Example.com")
+	if err != nil {
+		t.Fatalf("InviteAthlete failed: %v", err)
+	}
+	if athlete.UserID != 7 || athlete.Status != TeamMemberInvited || !athlete.InvitedAt.Equal(now) {
+		t.Errorf("Unexpected invitation: %+v", athlete)
+	}
+
+	if _, err := repo.InviteAthlete(context.Background(), 3, "runner@example.com"); !errors.Is(err, ErrAlreadyInvited) {
+		t.Errorf("Expected ErrAlreadyInvited, got %v", err)
+	}
+	if _, err := repo.InviteAthlete(context.Background(), 3, "coach@example.com"); !errors.Is(err, ErrInviteCoach) {
+		t.Errorf("Expected ErrInviteCoach, got %v", err)
+	}
+	if _, err := repo.InviteAthlete(context.Background(), 3, "nobody@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown email, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestTeamRepository_AcceptInvitation(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE team_members").
+		WithArgs(TeamMemberActive, sqlmock.AnyArg(), 3, 7, TeamMemberInvited).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE team_members").
+		WithArgs(TeamMemberActive, sqlmock.AnyArg(), 3, 8, TeamMemberInvited).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewTeamRepository(db)
+	if err := repo.AcceptInvitation(context.Background(), 3, 7); err != nil {
+		t.Fatalf("AcceptInvitation failed: %v", err)
+	}
+	if err := repo.AcceptInvitation(context.Background(), 3, 8); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows without a pending invitation, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestTeamRepository_ListAthleteStatuses(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	started := time.Date(2024, 6, 20, 6, 0, 0, 0, time.UTC)
+	completed := started.Add(time.Minute)
+	columns := []string{
+		"id", "name", "email", "automation_enabled", "suspended", "strava_connected", "sheet_configured", "last_sync_at",
+		"run_id", "trace_id", "trigger_type", "dry_run", "status", "activities_count",
+		"error_type", "error_message", "started_at", "completed_at",
+	}
+
+	mock.ExpectQuery("FROM team_members tm").
+		WithArgs(3, TeamMemberActive, RunStatusCompleted).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(7, "Ana", "ana@example.com", true, false, true, true, completed,
+				41, "trace-1", "schedule", false, RunStatusFailed, 0, "STRAVA_REAUTH_REQUIRED", "token revoked", started, completed).
+			AddRow(8, "Ben", "ben@example.com", true, false, false, false, nil,
+				nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+	statuses, err := NewTeamRepository(db).ListAthleteStatuses(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ListAthleteStatuses failed: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 athletes, got %d", len(statuses))
+	}
+
+	ana := statuses[0]
+	if ana.LastRun == nil || ana.LastRun.ID != 41 || ana.LastRun.ErrorType == nil || *ana.LastRun.ErrorType != "STRAVA_REAUTH_REQUIRED" {
+		t.Errorf("Expected the latest failed run, got %+v", ana.LastRun)
+	}
+	if ana.LastSyncAt == nil || !ana.LastSyncAt.Equal(completed) {
+		t.Errorf("Expected the last sync time, got %v", ana.LastSyncAt)
+	}
+	if ben := statuses[1]; ben.LastRun != nil || ben.LastSyncAt != nil || ben.StravaConnected {
+		t.Errorf("Expected no runs for an athlete who never synced, got %+v", ben)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestTeamRepository_SetTeamSpreadsheet(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	spreadsheetID := "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+	mock.ExpectExec("UPDATE teams SET spreadsheet_id").
+		WithArgs(&spreadsheetID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE teams SET spreadsheet_id").
+		WithArgs(nil, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewTeamRepository(db)
+	if err := repo.SetTeamSpreadsheet(context.Background(), 3, &spreadsheetID); err != nil {
+		t.Fatalf("SetTeamSpreadsheet failed: %v", err)
+	}
+	if err := repo.SetTeamSpreadsheet(context.Background(), 4, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing team, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrGoogleAccountLinked is returned by LinkGoogleAccount when the Google account already signs
+// in to another user
+var ErrGoogleAccountLinked = errors.New("Google account is already linked to another user")
+
+// GetUserByEmail retrieves the user with the given email, ignoring case. When case variants of
+// the address belong to several users, the oldest is returned.
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+		SELECT id, google_id, email, name, profile_picture_url,
+			   google_access_token, google_refresh_token, google_token_expiry,
+			   strava_access_token, strava_refresh_token, strava_token_expiry, strava_athlete_id,
+			   strava_athlete_name, strava_profile_picture_url,
+			   spreadsheet_id, timezone, email_notifications_enabled, automation_enabled,
+			   created_at, updated_at, last_login_at, token_version, role, suspended_at
+		FROM users WHERE LOWER(email) = LOWER($1)
+		ORDER BY id
+		LIMIT 1
+	`
+
+	var user User
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.ProfilePictureURL,
+		&user.GoogleAccessToken, &user.GoogleRefreshToken, &user.GoogleTokenExpiry,
+		&user.StravaAccessToken, &user.StravaRefreshToken, &user.StravaTokenExpiry, &user.StravaAthleteID,
+		&user.StravaAthleteName, &user.StravaProfilePictureURL,
+		&user.SpreadsheetID, &user.Timezone, &user.EmailNotificationsEnabled, &user.AutomationEnabled,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.TokenVersion, &user.Role, &user.SuspendedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// LinkGoogleAccount lets a further Google account sign in to an existing user and stores the
+// tokens it was signed in with. The user's refresh token is kept when Google returned none.
+// ErrGoogleAccountLinked is returned when the Google account already belongs to another user.
+func (r *UserRepository) LinkGoogleAccount(ctx context.Context, req *LinkGoogleAccountRequest) error {
+	encryptedAccessToken, err := r.encryptor.Encrypt(req.GoogleAccessToken)
+	if err != nil {
+		return err
+	}
+	// A nil interface, not a nil slice, so the driver sends NULL and the stored token is kept
+	var encryptedRefreshToken interface{}
+	if req.GoogleRefreshToken != "" {
+		encrypted, err := r.encryptor.Encrypt(req.GoogleRefreshToken)
+		if err != nil {
+			return err
+		}
+		encryptedRefreshToken = encrypted
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin link transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM users WHERE google_id = $1
+		UNION ALL
+		SELECT user_id FROM linked_google_accounts WHERE google_id = $1
+	`, req.GoogleID).Scan(&owner)
+	if err == nil && owner != req.UserID {
+		return ErrGoogleAccountLinked
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		query := `INSERT INTO linked_google_accounts (google_id, user_id, email) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, req.GoogleID, req.UserID, req.Email); err != nil {
+			return fmt.Errorf("failed to link Google account: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE users
+		SET google_access_token = $1,
+			google_refresh_token = COALESCE($2, google_refresh_token),
+			google_token_expiry = $3,
+			last_login_at = NOW(),
+			updated_at = NOW(),
+			token_version = token_version + 1
+		WHERE id = $4
+	`
+	result, err := tx.ExecContext(ctx, query, encryptedAccessToken, encryptedRefreshToken, req.GoogleTokenExpiry, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to store linked account tokens: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit link transaction: %w", err)
+	}
+	return nil
+}
+
+// mergeUserStatements move what belongs to the duplicate user ($2) to the user it is merged
+// into ($1). Rows the user already has an equivalent of are left to be deleted with the
+// duplicate; its sessions are not moved, so it is signed out everywhere.
+var mergeUserStatements = []string{
+	// Sign-in: the duplicate's Google accounts sign in to the user from now on
+	`INSERT INTO linked_google_accounts (google_id, user_id, email)
+		SELECT google_id, $1, email FROM users WHERE id = $2
+		ON CONFLICT (google_id) DO UPDATE SET user_id = $1`,
+	`UPDATE linked_google_accounts SET user_id = $1 WHERE user_id = $2`,
+
+	// Connections and settings the user lacks are taken from the duplicate
+	`UPDATE users u SET
+		strava_access_token = d.strava_access_token,
+		strava_refresh_token = d.strava_refresh_token,
+		strava_token_expiry = d.strava_token_expiry,
+		strava_athlete_id = d.strava_athlete_id,
+		strava_athlete_name = d.strava_athlete_name,
+		strava_profile_picture_url = d.strava_profile_picture_url,
+		strava_scopes = d.strava_scopes,
+		updated_at = NOW()
+		FROM users d
+		WHERE u.id = $1 AND d.id = $2 AND u.strava_refresh_token IS NULL AND d.strava_refresh_token IS NOT NULL`,
+	`UPDATE users u SET spreadsheet_id = d.spreadsheet_id, updated_at = NOW()
+		FROM users d
+		WHERE u.id = $1 AND d.id = $2 AND u.spreadsheet_id IS NULL AND d.spreadsheet_id IS NOT NULL`,
+
+	// History and data
+	`UPDATE automation_runs SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE activities SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE backfill_windows d SET user_id = $1 WHERE user_id = $2
+		AND NOT EXISTS (SELECT 1 FROM backfill_windows u WHERE u.user_id = $1 AND u.window_start = d.window_start)`,
+	`UPDATE notification_log SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE pending_notifications SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE held_notifications SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE job_outbox SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE api_tokens SET user_id = $1 WHERE user_id = $2`,
+	`UPDATE sign_in_events SET user_id = $1, session_id = NULL WHERE user_id = $2`,
+	`UPDATE role_changes SET user_id = $1 WHERE user_id = $2`,
+
+	// Teams
+	`UPDATE teams SET coach_user_id = $1 WHERE coach_user_id = $2`,
+	`UPDATE team_members d SET user_id = $1 WHERE user_id = $2
+		AND NOT EXISTS (SELECT 1 FROM team_members u WHERE u.user_id = $1 AND u.team_id = d.team_id)`,
+
+	// References kept for audit
+	`UPDATE role_changes SET changed_by = $1 WHERE changed_by = $2`,
+	`UPDATE users SET suspended_by = $1 WHERE suspended_by = $2`,
+	`UPDATE blackout_windows SET created_by = $1 WHERE created_by = $2`,
+	`UPDATE invite_codes SET created_by = $1 WHERE created_by = $2`,
+	`UPDATE invite_codes SET used_by = $1 WHERE used_by = $2`,
+}
+
+// MergeUsers merges the duplicate user into userID and deletes the duplicate, so a person who
+// ended up with two accounts keeps one. The duplicate's Google accounts are linked to the user,
+// its history moves over, and its Strava connection and spreadsheet are taken when the user has
+// none. The merged user's sync cache (activity_cache_state) is rebuilt on its next sync. It
+// returns sql.ErrNoRows when either user does not exist.
+func (r *UserRepository) MergeUsers(ctx context.Context, userID, duplicateID int) error {
+	if userID == duplicateID {
+		return fmt.Errorf("cannot merge user %d into itself", userID)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin merge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both users so neither changes while their rows move
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM (SELECT id FROM users WHERE id IN ($1, $2) FOR UPDATE) l`, userID, duplicateID).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if locked != 2 {
+		return sql.ErrNoRows
+	}
+
+	// The duplicate keeps its Strava athlete until it is deleted, so the user may only take it over
+	// once the uniqueness check runs at commit
+	if _, err := tx.ExecContext(ctx, `SET CONSTRAINTS `+stravaAthleteConstraint+` DEFERRED`); err != nil {
+		return err
+	}
+
+	for _, statement := range mergeUserStatements {
+		if _, err := tx.ExecContext(ctx, statement, userID, duplicateID); err != nil {
+			return fmt.Errorf("failed to merge user %d into %d: %w", duplicateID, userID, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM activity_cache_state WHERE user_id IN ($1, $2)`, userID, duplicateID); err != nil {
+		return fmt.Errorf("failed to reset activity cache: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, duplicateID); err != nil {
+		return fmt.Errorf("failed to delete merged user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge transaction: %w", err)
+	}
+	return nil
+}
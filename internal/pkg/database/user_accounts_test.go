@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+func TestUserRepository_LinkGoogleAccount(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	expiry := time.Now().Add(time.Hour)
+	req := &LinkGoogleAccountRequest{UserID: 7, GoogleID: "g-2", Email: "runner@example.com", GoogleAccessToken: "access", GoogleTokenExpiry: &expiry}
+	owner := "SELECT id FROM users WHERE google_id"
+
+	// A new Google account is linked; without a refresh token the user's is kept
+	mock.ExpectBegin()
+	mock.ExpectQuery(owner).WithArgs("g-2").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT INTO linked_google_accounts").
+		WithArgs("g-2", 7, "runner@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").
+		WithArgs(sqlmock.AnyArg(), nil, &expiry, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A Google account that signs in to another user is refused
+	mock.ExpectBegin()
+	mock.ExpectQuery(owner).WithArgs("g-2").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	mock.ExpectRollback()
+
+	if err := repo.LinkGoogleAccount(context.Background(), req); err != nil {
+		t.Fatalf("LinkGoogleAccount failed: %v", err)
+	}
+	if err := repo.LinkGoogleAccount(context.Background(), req); err != ErrGoogleAccountLinked {
+		t.Errorf("Expected ErrGoogleAccountLinked, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_MergeUsers(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	lock := "SELECT COUNT\\(\\*\\) FROM \\(SELECT id FROM users WHERE id IN"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lock).WithArgs(7, 8).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("SET CONSTRAINTS users_strava_athlete_id_key DEFERRED").WillReturnResult(sqlmock.NewResult(0, 0))
+	for range mergeUserStatements {
+		mock.ExpectExec(".+").WithArgs(7, 8).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("DELETE FROM activity_cache_state").WithArgs(7, 8).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM users WHERE id").WithArgs(8).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// An unknown duplicate merges nothing
+	mock.ExpectBegin()
+	mock.ExpectQuery(lock).WithArgs(7, 9).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	if err := repo.MergeUsers(context.Background(), 7, 8); err != nil {
+		t.Fatalf("MergeUsers failed: %v", err)
+	}
+	if err := repo.MergeUsers(context.Background(), 7, 9); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+	if err := repo.MergeUsers(context.Background(), 7, 7); err == nil {
+		t.Error("Expected merging a user into itself to fail")
+	}
+
+	// Notifications still waiting to be delivered follow the user instead of cascading away
+	// with the duplicate
+	for _, table := range []string{"pending_notifications", "held_notifications"} {
+		moved := false
+		for _, statement := range mergeUserStatements {
+			if strings.HasPrefix(statement, "UPDATE "+table+" SET user_id = $1") {
+				moved = true
+			}
+		}
+		if !moved {
+			t.Errorf("Expected %s to be moved to the merged user", table)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
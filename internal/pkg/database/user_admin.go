@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+// encryptedUserColumns are the users columns holding values encrypted with ENCRYPTION_SECRET
+var encryptedUserColumns = []string{
+	"google_access_token",
+	"google_refresh_token",
+	"strava_access_token",
+	"strava_refresh_token",
+	"webhook_secret",
+	"chat_webhook_url",
+}
+
+// ListUsers returns up to limit users with an ID above afterID, in ID order
+func (r *UserRepository) ListUsers(ctx context.Context, afterID, limit int) ([]UserSummary, error) {
+	query := `
+		SELECT id, email, name, role, COALESCE(automation_enabled, false), suspended_at IS NOT NULL,
+		       strava_refresh_token IS NOT NULL, spreadsheet_id IS NOT NULL, last_login_at, created_at
+		FROM users
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []UserSummary{}
+	for rows.Next() {
+		var user UserSummary
+		if err := rows.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.AutomationEnabled,
+			&user.Suspended, &user.StravaConnected, &user.SheetConfigured, &user.LastLoginAt, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// EnableAutomation turns on scheduled syncs for the user, whose first run is due at nextRunAt;
+// pass automation.NextScheduledRun to wait for the daily run. Each run then schedules the next.
+// It returns sql.ErrNoRows when there is no such user.
+func (r *UserRepository) EnableAutomation(ctx context.Context, userID int, nextRunAt time.Time) error {
+	query := `UPDATE users SET automation_enabled = true, next_run_at = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, nextRunAt, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DisableAutomation stops scheduled syncs for the user, clearing their next run, and reports
+// whether automation was enabled. It returns sql.ErrNoRows when there is no such user.
+func (r *UserRepository) DisableAutomation(ctx context.Context, userID int) (bool, error) {
+	query := `
+		UPDATE users SET automation_enabled = false, next_run_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND automation_enabled = true
+	`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected > 0 {
+		return true, nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, sql.ErrNoRows
+	}
+	return false, nil
+}
+
+// ReEncryptSecrets rewrites the encrypted values of up to limit users with an ID above afterID
+// under the repository's current key, after ENCRYPTION_SECRET was rotated. Values that do not
+// decrypt with the current key are decrypted with previous; a value neither key decrypts fails
+// the page, which is rolled back. Values already under the current key are left alone, so the
+// pass can be repeated safely. Pages continue after the returned LastUserID; a page of fewer
+// than limit users is the last one.
+func (r *UserRepository) ReEncryptSecrets(ctx context.Context, previous *auth.EncryptionService, afterID, limit int) (*ReEncryptResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin re-encryption transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`SELECT id, %s FROM users WHERE id > $1 ORDER BY id LIMIT $2 FOR UPDATE`,
+		strings.Join(encryptedUserColumns, ", "))
+	rows, err := tx.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted values: %w", err)
+	}
+
+	type userSecrets struct {
+		id     int
+		values [][]byte
+	}
+	var users []userSecrets
+	for rows.Next() {
+		user := userSecrets{values: make([][]byte, len(encryptedUserColumns))}
+		dest := []interface{}{&user.id}
+		for i := range user.values {
+			dest = append(dest, &user.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ReEncryptResult{Users: len(users), LastUserID: afterID}
+	for _, user := range users {
+		result.LastUserID = user.id
+
+		var assignments []string
+		var args []interface{}
+		for i, value := range user.values {
+			if len(value) == 0 {
+				continue
+			}
+			if plaintext, err := r.encryptor.DecryptBytes(value); err == nil {
+				clear(plaintext)
+				result.Current++
+				continue
+			}
+
+			plaintext, err := previous.DecryptBytes(value)
+			if err != nil {
+				return nil, fmt.Errorf("user %d: %s decrypts with neither the current nor the previous key", user.id, encryptedUserColumns[i])
+			}
+			encrypted, err := r.encryptor.Encrypt(string(plaintext))
+			clear(plaintext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt %s of user %d: %w", encryptedUserColumns[i], user.id, err)
+			}
+
+			args = append(args, encrypted)
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", encryptedUserColumns[i], len(args)))
+		}
+		if len(assignments) == 0 {
+			continue
+		}
+
+		args = append(args, user.id)
+		update := fmt.Sprintf(`UPDATE users SET %s WHERE id = $%d`, strings.Join(assignments, ", "), len(args))
+		if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+			return nil, fmt.Errorf("failed to store re-encrypted values of user %d: %w", user.id, err)
+		}
+		result.ReEncrypted += len(assignments)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit re-encryption transaction: %w", err)
+	}
+	return result, nil
+}
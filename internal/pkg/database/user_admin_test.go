@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+func TestUserRepository_ListUsers(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	createdAt := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, email, name, role, .* FROM users\\s+WHERE id > \\$1\\s+ORDER BY id\\s+LIMIT \\$2").
+		WithArgs(10, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "role", "automation_enabled", "suspended",
+			"strava_connected", "sheet_configured", "last_login_at", "created_at"}).
+			AddRow(11, "runner@example.com", "Runner", "user", true, false, true, true, createdAt, createdAt).
+			AddRow(12, "coach@example.com", "Coach", "coach", false, true, false, false, nil, createdAt))
+
+	users, err := NewUserRepository(db, nil).ListUsers(context.Background(), 10, 2)
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != 11 || !users[0].AutomationEnabled || users[0].LastLoginAt == nil ||
+		users[1].Role != "coach" || !users[1].Suspended || users[1].LastLoginAt != nil {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_EnableAutomation(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	nextRunAt := time.Date(2024, 6, 21, 3, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE users SET automation_enabled = true, next_run_at = \\$1").
+		WithArgs(nextRunAt, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET automation_enabled = true, next_run_at = \\$1").
+		WithArgs(nextRunAt, 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewUserRepository(db, nil)
+	if err := repo.EnableAutomation(context.Background(), 7, nextRunAt); err != nil {
+		t.Errorf("EnableAutomation failed: %v", err)
+	}
+	if err := repo.EnableAutomation(context.Background(), 9, nextRunAt); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_DisableAutomation(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users SET automation_enabled = false, next_run_at = NULL").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET automation_enabled = false, next_run_at = NULL").
+		WithArgs(8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec("UPDATE users SET automation_enabled = false, next_run_at = NULL").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	repo := NewUserRepository(db, nil)
+	if disabled, err := repo.DisableAutomation(context.Background(), 7); err != nil || !disabled {
+		t.Errorf("Expected automation to be disabled, got %t, %v", disabled, err)
+	}
+	if disabled, err := repo.DisableAutomation(context.Background(), 8); err != nil || disabled {
+		t.Errorf("Expected no change when automation is already off, got %t, %v", disabled, err)
+	}
+	if _, err := repo.DisableAutomation(context.Background(), 9); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// decryptsTo matches a ciphertext argument that decrypts to plaintext with encryptor
+type decryptsTo struct {
+	encryptor *auth.EncryptionService
+	plaintext string
+}
+
+func (m decryptsTo) Match(v driver.Value) bool {
+	ciphertext, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	plaintext, err := m.encryptor.Decrypt(ciphertext)
+	return err == nil && plaintext == m.plaintext
+}
+
+func TestUserRepository_ReEncryptSecrets(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	previous := auth.NewEncryptionService("old-secret")
+	current := auth.NewEncryptionService("new-secret")
+	encrypt := func(e *auth.EncryptionService, value string) []byte {
+		ciphertext, err := e.Encrypt(value)
+		if err != nil {
+			t.Fatalf("Encrypt failed: %v", err)
+		}
+		return ciphertext
+	}
+	columns := []string{"id", "google_access_token", "google_refresh_token", "strava_access_token",
+		"strava_refresh_token", "webhook_secret", "chat_webhook_url"}
+
+	// User 1 still has old-key values; user 2 was already re-encrypted
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, google_access_token, .*, chat_webhook_url FROM users WHERE id > \\$1 ORDER BY id LIMIT \\$2 FOR UPDATE").
+		WithArgs(0, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, encrypt(previous, "g-access"), encrypt(previous, "g-refresh"), nil, nil, encrypt(current, "whsec"), nil).
+			AddRow(2, encrypt(current, "g-access-2"), nil, nil, nil, nil, nil))
+	mock.ExpectExec("UPDATE users SET google_access_token = \\$1, google_refresh_token = \\$2 WHERE id = \\$3").
+		WithArgs(decryptsTo{current, "g-access"}, decryptsTo{current, "g-refresh"}, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A value neither key decrypts rolls the page back
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, google_access_token").
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, encrypt(auth.NewEncryptionService("unknown-secret"), "g-access"), nil, nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	repo := NewUserRepository(db, current)
+	result, err := repo.ReEncryptSecrets(context.Background(), previous, 0, 2)
+	if err != nil {
+		t.Fatalf("ReEncryptSecrets failed: %v", err)
+	}
+	if result.Users != 2 || result.ReEncrypted != 2 || result.Current != 2 || result.LastUserID != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := repo.ReEncryptSecrets(context.Background(), previous, 2, 2); err == nil || !strings.Contains(err.Error(), "user 3: google_access_token") {
+		t.Errorf("Expected the undecryptable value to be reported, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
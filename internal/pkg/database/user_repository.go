@@ -3,9 +3,15 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/secure"
 )
 
 // UserRepository handles database operations for users
@@ -16,16 +22,44 @@ type UserRepository struct {
 
 // ProcessingTokens contains all decrypted tokens and configuration needed for automation processing
 type ProcessingTokens struct {
-	GoogleAccessToken  string
-	GoogleRefreshToken string
+	// OAuth tokens are decrypted into zeroable buffers; nil when not stored
+	GoogleAccessToken  *secure.Token
+	GoogleRefreshToken *secure.Token
 	GoogleTokenExpiry  *time.Time
-	StravaAccessToken  string
-	StravaRefreshToken string
+	StravaAccessToken  *secure.Token
+	StravaRefreshToken *secure.Token
 	StravaTokenExpiry  *time.Time
 	StravaAthleteID    *int64
 	SpreadsheetID      *string
 	Timezone           string
 	Email              string
+
+	// Destination migration (dual-write validation window)
+	PendingDestinationType *string
+	PendingDestinationID   *string
+	DualWriteUntil         *time.Time
+
+	// Spreadsheet features
+	WeeklySummaryEnabled bool
+	SheetTemplate        string
+	SortChronologically  bool
+	GearColumn           bool
+
+	// Outbound webhook (secret decrypted); empty URL means no webhook
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// TeamSheetTokens is a shared team spreadsheet an athlete's activities are copied to, with the
+// coach's decrypted Google tokens that own it
+type TeamSheetTokens struct {
+	TeamID             int
+	SpreadsheetID      string
+	CoachUserID        int
+	AthleteName        string
+	GoogleAccessToken  *secure.Token
+	GoogleRefreshToken *secure.Token
+	GoogleTokenExpiry  *time.Time
 }
 
 // NewUserRepository creates a new user repository
@@ -63,7 +97,23 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *CreateUserRequest)
 	var id int
 	var createdAt, updatedAt time.Time
 
-	err = r.db.QueryRowContext(
+	// An invite code is redeemed in the transaction that creates the account, so each code
+	// signs up exactly one user
+	var q rowQuerier = r.db
+	var tx *sql.Tx
+	var inviteID int
+	if req.InviteCodeHash != "" {
+		if tx, err = r.db.BeginTx(ctx, nil); err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		if inviteID, err = claimInviteCode(ctx, tx, req.InviteCodeHash); err != nil {
+			return nil, err
+		}
+		q = tx
+	}
+
+	err = q.QueryRowContext(
 		ctx,
 		query,
 		req.GoogleID,
@@ -82,6 +132,15 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *CreateUserRequest)
 		return nil, err
 	}
 
+	if tx != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE invite_codes SET used_by = $1 WHERE id = $2`, id, inviteID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build the user object
 	user = User{
 		ID:                        id,
@@ -95,6 +154,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *CreateUserRequest)
 		Timezone:                  "UTC", // Default timezone
 		EmailNotificationsEnabled: true,  // Default enabled
 		AutomationEnabled:         false, // Default disabled
+		Role:                      UserRoleUser,
 		CreatedAt:                 createdAt,
 		UpdatedAt:                 updatedAt,
 		LastLoginAt:               &now,
@@ -103,7 +163,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *CreateUserRequest)
 	return &user, nil
 }
 
-// GetUserByGoogleID retrieves a user by their Google ID
+// GetUserByGoogleID retrieves a user by their Google ID or one of their linked Google accounts
 func (r *UserRepository) GetUserByGoogleID(ctx context.Context, googleID string) (*User, error) {
 	query := `
 		SELECT id, google_id, email, name, profile_picture_url,
@@ -111,8 +171,9 @@ func (r *UserRepository) GetUserByGoogleID(ctx context.Context, googleID string)
 			   strava_access_token, strava_refresh_token, strava_token_expiry, strava_athlete_id,
 			   strava_athlete_name, strava_profile_picture_url,
 			   spreadsheet_id, timezone, email_notifications_enabled, automation_enabled,
-			   created_at, updated_at, last_login_at
-		FROM users WHERE google_id = $1
+			   created_at, updated_at, last_login_at, token_version, role, suspended_at
+		FROM users
+		WHERE google_id = $1 OR id = (SELECT user_id FROM linked_google_accounts WHERE google_id = $1)
 	`
 
 	var user User
@@ -122,7 +183,7 @@ func (r *UserRepository) GetUserByGoogleID(ctx context.Context, googleID string)
 		&user.StravaAccessToken, &user.StravaRefreshToken, &user.StravaTokenExpiry, &user.StravaAthleteID,
 		&user.StravaAthleteName, &user.StravaProfilePictureURL,
 		&user.SpreadsheetID, &user.Timezone, &user.EmailNotificationsEnabled, &user.AutomationEnabled,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.TokenVersion, &user.Role, &user.SuspendedAt,
 	)
 
 	if err != nil {
@@ -143,7 +204,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*User, error)
 			   strava_access_token, strava_refresh_token, strava_token_expiry, strava_athlete_id,
 			   strava_athlete_name, strava_profile_picture_url,
 			   spreadsheet_id, timezone, email_notifications_enabled, automation_enabled,
-			   created_at, updated_at, last_login_at
+			   created_at, updated_at, last_login_at, token_version, role, suspended_at
 		FROM users WHERE id = $1
 	`
 
@@ -154,7 +215,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*User, error)
 		&user.StravaAccessToken, &user.StravaRefreshToken, &user.StravaTokenExpiry, &user.StravaAthleteID,
 		&user.StravaAthleteName, &user.StravaProfilePictureURL,
 		&user.SpreadsheetID, &user.Timezone, &user.EmailNotificationsEnabled, &user.AutomationEnabled,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.TokenVersion, &user.Role, &user.SuspendedAt,
 	)
 
 	if err != nil {
@@ -167,6 +228,10 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*User, error)
 	return &user, nil
 }
 
+// ErrStaleTokenVersion is returned by a token update whose ExpectedTokenVersion no longer
+// matches because tokens were written since they were read; re-read the user and retry
+var ErrStaleTokenVersion = errors.New("tokens were updated concurrently")
+
 // UpdateUserTokens updates a user's Google OAuth tokens and optionally last login timestamp
 func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTokensRequest) error {
 	// Encrypt OAuth tokens
@@ -193,8 +258,9 @@ func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTo
 				google_refresh_token = $2,
 				google_token_expiry = $3,
 				last_login_at = $4,
-				updated_at = $5
-			WHERE id = $6
+				updated_at = $5,
+				token_version = token_version + 1
+			WHERE id = $6 AND ($7::integer IS NULL OR token_version = $7)
 		`
 		args = []interface{}{
 			encryptedAccessToken,
@@ -203,6 +269,7 @@ func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTo
 			now,
 			now,
 			req.UserID,
+			req.ExpectedTokenVersion,
 		}
 	} else {
 		query = `
@@ -210,8 +277,9 @@ func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTo
 			SET google_access_token = $1,
 				google_refresh_token = $2,
 				google_token_expiry = $3,
-				updated_at = $4
-			WHERE id = $5
+				updated_at = $4,
+				token_version = token_version + 1
+			WHERE id = $5 AND ($6::integer IS NULL OR token_version = $6)
 		`
 		args = []interface{}{
 			encryptedAccessToken,
@@ -219,6 +287,7 @@ func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTo
 			req.GoogleTokenExpiry,
 			now,
 			req.UserID,
+			req.ExpectedTokenVersion,
 		}
 	}
 
@@ -234,12 +303,30 @@ func (r *UserRepository) UpdateUserTokens(ctx context.Context, req *UpdateUserTo
 	}
 	
 	if rowsAffected == 0 {
-		return sql.ErrNoRows // No user found with the given ID
+		return r.missedTokenUpdate(ctx, req.UserID, req.ExpectedTokenVersion)
 	}
 	
 	return nil
 }
 
+// missedTokenUpdate explains a token update that matched no row: either the user does not
+// exist (sql.ErrNoRows) or its tokens moved past the expected version (ErrStaleTokenVersion)
+func (r *UserRepository) missedTokenUpdate(ctx context.Context, userID int, expectedVersion *int) error {
+	if expectedVersion == nil {
+		return sql.ErrNoRows // No user found with the given ID
+	}
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return ErrStaleTokenVersion
+}
+
 // UpdateLastLoginAt updates the user's last login timestamp
 func (r *UserRepository) UpdateLastLoginAt(ctx context.Context, userID int) error {
 	query := `UPDATE users SET last_login_at = $1, updated_at = $2 WHERE id = $3`
@@ -297,15 +384,23 @@ func (r *UserRepository) DecryptToken(encryptedToken []byte) (string, error) {
 	return r.encryptor.Decrypt(encryptedToken)
 }
 
-// UpdateStravaConnection updates the user's Strava connection with encrypted tokens and profile information
-func (r *UserRepository) UpdateStravaConnection(ctx context.Context, userID int, accessToken, refreshToken string, expiry *time.Time, athleteID int64, athleteName, profilePictureURL string) error {
+// ErrStravaAthleteConnected is returned by UpdateStravaConnection when the Strava athlete is
+// already connected to another user
+var ErrStravaAthleteConnected = errors.New("Strava athlete is already connected to another user")
+
+// stravaAthleteConstraint is the unique constraint on users.strava_athlete_id
+const stravaAthleteConstraint = "users_strava_athlete_id_key"
+
+// UpdateStravaConnection updates the user's Strava connection with encrypted tokens and profile information.
+// It returns ErrStravaAthleteConnected when another user has connected the same athlete.
+func (r *UserRepository) UpdateStravaConnection(ctx context.Context, req *UpdateStravaConnectionRequest) error {
 	// Encrypt Strava tokens
-	encryptedAccessToken, err := r.encryptor.Encrypt(accessToken)
+	encryptedAccessToken, err := r.encryptor.Encrypt(req.AccessToken)
 	if err != nil {
 		return err
 	}
 
-	encryptedRefreshToken, err := r.encryptor.Encrypt(refreshToken)
+	encryptedRefreshToken, err := r.encryptor.Encrypt(req.RefreshToken)
 	if err != nil {
 		return err
 	}
@@ -318,22 +413,89 @@ func (r *UserRepository) UpdateStravaConnection(ctx context.Context, userID int,
 		    strava_athlete_id = $4, 
 		    strava_athlete_name = $5,
 		    strava_profile_picture_url = $6,
-		    updated_at = $7 
-		WHERE id = $8
+		    updated_at = $7,
+		    token_version = token_version + 1
+		WHERE id = $8 AND ($9::integer IS NULL OR token_version = $9)
+		  AND NOT EXISTS (SELECT 1 FROM users WHERE strava_athlete_id = $4 AND id <> $8)
 	`
 
 	now := time.Now()
-	_, err = r.db.ExecContext(ctx, query, 
+	result, err := r.db.ExecContext(ctx, query, 
 		encryptedAccessToken, 
 		encryptedRefreshToken, 
-		expiry, 
-		athleteID, 
-		athleteName,
-		profilePictureURL,
+		req.TokenExpiry, 
+		req.AthleteID, 
+		req.AthleteName,
+		req.ProfilePictureURL,
 		now, 
-		userID)
+		req.UserID,
+		req.ExpectedTokenVersion)
+	if err != nil {
+		// Another user connected the athlete since the check above
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == stravaAthleteConstraint {
+			return ErrStravaAthleteConnected
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var connected bool
+		query := `SELECT EXISTS(SELECT 1 FROM users WHERE strava_athlete_id = $1 AND id <> $2)`
+		if err := r.db.QueryRowContext(ctx, query, req.AthleteID, req.UserID).Scan(&connected); err != nil {
+			return err
+		}
+		if connected {
+			return ErrStravaAthleteConnected
+		}
+		return r.missedTokenUpdate(ctx, req.UserID, req.ExpectedTokenVersion)
+	}
 	
-	return err
+	return nil
+}
+
+// SaveStravaTokens stores Strava tokens refreshed outside the OAuth callback, such as by an export.
+// A user who disconnected Strava in the meantime is left disconnected and sql.ErrNoRows is returned.
+func (r *UserRepository) SaveStravaTokens(ctx context.Context, userID int, accessToken, refreshToken string, expiry time.Time) error {
+	encryptedAccessToken, err := r.encryptor.Encrypt(accessToken)
+	if err != nil {
+		return err
+	}
+
+	encryptedRefreshToken, err := r.encryptor.Encrypt(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE users
+		SET strava_access_token = $1,
+		    strava_refresh_token = $2,
+		    strava_token_expiry = $3,
+		    updated_at = $4,
+		    token_version = token_version + 1
+		WHERE id = $5 AND strava_refresh_token IS NOT NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, encryptedAccessToken, encryptedRefreshToken, expiry, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
 
 // RemoveStravaConnection removes the user's Strava connection by clearing tokens and athlete ID
@@ -346,7 +508,9 @@ func (r *UserRepository) RemoveStravaConnection(ctx context.Context, userID int)
 		    strava_athlete_id = NULL, 
 		    strava_athlete_name = NULL,
 		    strava_profile_picture_url = NULL,
-		    updated_at = $1 
+		    strava_scopes = NULL,
+		    updated_at = $1,
+		    token_version = token_version + 1
 		WHERE id = $2
 	`
 
@@ -355,6 +519,39 @@ func (r *UserRepository) RemoveStravaConnection(ctx context.Context, userID int)
 	return err
 }
 
+// DisconnectGoogleSheets clears the user's spreadsheet and Google tokens and disables
+// automation, which cannot run without them. It returns sql.ErrNoRows for an unknown user.
+func (r *UserRepository) DisconnectGoogleSheets(ctx context.Context, userID int) error {
+	query := `
+		UPDATE users 
+		SET spreadsheet_id = NULL,
+		    google_access_token = NULL, 
+		    google_refresh_token = NULL, 
+		    google_token_expiry = NULL, 
+		    google_scopes = NULL,
+		    automation_enabled = false,
+		    updated_at = $1,
+		    token_version = token_version + 1
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 // GetDecryptedStravaTokens retrieves and decrypts a user's Strava OAuth tokens
 func (r *UserRepository) GetDecryptedStravaTokens(ctx context.Context, userID int) (accessToken, refreshToken string, expiry *time.Time, athleteID *int64, err error) {
 	query := `
@@ -413,6 +610,32 @@ func (r *UserRepository) UpdateSpreadsheetID(ctx context.Context, userID int, sp
 	return nil
 }
 
+// UpdateSpreadsheetTemplate sets the user's Google Spreadsheet ID together with the template it follows
+func (r *UserRepository) UpdateSpreadsheetTemplate(ctx context.Context, userID int, spreadsheetID, templateID string) error {
+	query := `
+		UPDATE users 
+		SET spreadsheet_id = $1, sheet_template = $2, updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, spreadsheetID, templateID, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 // ClearSpreadsheetID clears the user's Google Spreadsheet ID
 func (r *UserRepository) ClearSpreadsheetID(ctx context.Context, userID int) error {
 	query := `
@@ -439,72 +662,691 @@ func (r *UserRepository) ClearSpreadsheetID(ctx context.Context, userID int) err
 	return nil
 }
 
-// GetProcessingConfigForUser retrieves all necessary data for automation processing for a specific user
-// This method is optimized for the automation engine and fetches all required fields in a single query.
-// It returns decrypted tokens ready for use by API clients.
-func (r *UserRepository) GetProcessingConfigForUser(ctx context.Context, userID int) (*ProcessingTokens, error) {
+// UpdateWebhook sets the user's outbound webhook URL and encrypts its signing secret
+func (r *UserRepository) UpdateWebhook(ctx context.Context, userID int, webhookURL, secret string) error {
+	encryptedSecret, err := r.encryptor.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		SELECT google_access_token, google_refresh_token, google_token_expiry,
-			   strava_access_token, strava_refresh_token, strava_token_expiry, strava_athlete_id,
-			   spreadsheet_id, COALESCE(timezone, ''), COALESCE(email, '')
-		FROM users WHERE id = $1
+		UPDATE users 
+		SET webhook_url = $1, webhook_secret = $2, updated_at = $3 
+		WHERE id = $4
 	`
 
-	var encryptedGoogleAccessToken, encryptedGoogleRefreshToken []byte
-	var encryptedStravaAccessToken, encryptedStravaRefreshToken []byte
-	var googleExpiry, stravaExpiry *time.Time
-	var athleteID *int64
-	var spreadsheetID *string
-	var timezone, email string
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, webhookURL, encryptedSecret, now, userID)
+	if err != nil {
+		return err
+	}
 
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&encryptedGoogleAccessToken, &encryptedGoogleRefreshToken, &googleExpiry,
-		&encryptedStravaAccessToken, &encryptedStravaRefreshToken, &stravaExpiry, &athleteID,
-		&spreadsheetID, &timezone, &email,
-	)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearWebhook removes the user's outbound webhook and its secret
+func (r *UserRepository) ClearWebhook(ctx context.Context, userID int) error {
+	query := `
+		UPDATE users 
+		SET webhook_url = NULL, webhook_secret = NULL, updated_at = $1 
+		WHERE id = $2
+	`
 
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, now, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	result := &ProcessingTokens{
-		GoogleTokenExpiry: googleExpiry,
-		StravaTokenExpiry: stravaExpiry,
-		StravaAthleteID:   athleteID,
-		SpreadsheetID:     spreadsheetID,
-		Timezone:          timezone,
-		Email:             email,
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
 	}
 
-	// Decrypt Google tokens
-	if len(encryptedGoogleAccessToken) > 0 {
-		result.GoogleAccessToken, err = r.encryptor.Decrypt(encryptedGoogleAccessToken)
-		if err != nil {
-			return nil, err
-		}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
 	}
 
-	if len(encryptedGoogleRefreshToken) > 0 {
-		result.GoogleRefreshToken, err = r.encryptor.Decrypt(encryptedGoogleRefreshToken)
+	return nil
+}
+
+// UpdateNotificationChannel sets the user's notification channel; the chat webhook URL is encrypted
+// because anyone holding it can post to the channel. An empty webhookURL clears it.
+func (r *UserRepository) UpdateNotificationChannel(ctx context.Context, userID int, channel, webhookURL string) error {
+	var encryptedURL []byte
+	if webhookURL != "" {
+		var err error
+		encryptedURL, err = r.encryptor.Encrypt(webhookURL)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// Decrypt Strava tokens
-	if len(encryptedStravaAccessToken) > 0 {
-		result.StravaAccessToken, err = r.encryptor.Decrypt(encryptedStravaAccessToken)
-		if err != nil {
-			return nil, err
-		}
+	query := `
+		UPDATE users 
+		SET notification_channel = $1, chat_webhook_url = $2, updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, channel, encryptedURL, now, userID)
+	if err != nil {
+		return err
 	}
 
-	if len(encryptedStravaRefreshToken) > 0 {
-		result.StravaRefreshToken, err = r.encryptor.Decrypt(encryptedStravaRefreshToken)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetNotificationChannel returns the user's notification channel with the chat webhook URL decrypted
+func (r *UserRepository) GetNotificationChannel(ctx context.Context, userID int) (*NotificationChannel, error) {
+	query := `
+		SELECT COALESCE(notification_channel, 'email'), chat_webhook_url, notification_preferences,
+			COALESCE(timezone, 'UTC'), COALESCE(quiet_hours_start, ''), COALESCE(quiet_hours_end, '')
+		FROM users WHERE id = $1
+	`
+
+	var channel NotificationChannel
+	var encryptedURL, preferences []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&channel.Channel, &encryptedURL, &preferences,
+		&channel.Timezone, &channel.QuietHoursStart, &channel.QuietHoursEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	channel.Preferences, err = decodeNotificationPreferences(preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encryptedURL) > 0 {
+		channel.WebhookURL, err = r.encryptor.Decrypt(encryptedURL)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return result, nil
-}
\ No newline at end of file
+	return &channel, nil
+}
+
+// UpdateNotificationPreferences stores the kinds of notification the user receives, and keeps
+// email_notifications_enabled set while any sync notification is enabled
+func (r *UserRepository) UpdateNotificationPreferences(ctx context.Context, userID int, preferences NotificationPreferences) error {
+	encoded, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE users 
+		SET notification_preferences = $1, email_notifications_enabled = $2, updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, encoded, preferences.SyncNotificationsEnabled(), now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateQuietHours sets the local HH:MM times the user's quiet hours begin and end; empty times
+// turn quiet hours off
+func (r *UserRepository) UpdateQuietHours(ctx context.Context, userID int, start, end string) error {
+	query := `
+		UPDATE users 
+		SET quiet_hours_start = NULLIF($1, ''), quiet_hours_end = NULLIF($2, ''), updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, start, end, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// decodeNotificationPreferences reads a stored preferences object over the defaults, so keys it
+// does not have stay enabled
+func decodeNotificationPreferences(raw []byte) (*NotificationPreferences, error) {
+	preferences := DefaultNotificationPreferences()
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &preferences); err != nil {
+			return nil, fmt.Errorf("invalid notification preferences: %w", err)
+		}
+	}
+	return &preferences, nil
+}
+
+// UpdateLocale sets the locale notifications are rendered in for the user
+func (r *UserRepository) UpdateLocale(ctx context.Context, userID int, locale string) error {
+	query := `
+		UPDATE users 
+		SET locale = $1, updated_at = $2 
+		WHERE id = $3
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, locale, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateDigestSettings sets how per-run notifications are delivered (immediate or digest) and the
+// local HH:MM time the daily digest is sent
+func (r *UserRepository) UpdateDigestSettings(ctx context.Context, userID int, mode, digestTime string) error {
+	query := `
+		UPDATE users 
+		SET notification_mode = $1, digest_time = $2, updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, mode, digestTime, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateChronologicalOrder sets whether the automation engine keeps the user's activity rows sorted by date
+func (r *UserRepository) UpdateChronologicalOrder(ctx context.Context, userID int, enabled bool) error {
+	query := `
+		UPDATE users 
+		SET sort_rows_chronologically = $1, updated_at = $2 
+		WHERE id = $3
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, enabled, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// StartDestinationMigration records a pending destination and opens a dual-write validation window
+// Until the window closes the automation engine writes to both destinations and compares the results.
+// The engine only writes Google Sheets candidates; other types are recorded but skipped.
+func (r *UserRepository) StartDestinationMigration(ctx context.Context, userID int, destinationType, destinationID string, until time.Time) error {
+	query := `
+		UPDATE users 
+		SET pending_destination_type = $1, pending_destination_id = $2, dual_write_until = $3, updated_at = $4 
+		WHERE id = $5
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, destinationType, destinationID, until, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearDestinationMigration ends the dual-write validation window without cutting over
+func (r *UserRepository) ClearDestinationMigration(ctx context.Context, userID int) error {
+	query := `
+		UPDATE users 
+		SET pending_destination_type = NULL, pending_destination_id = NULL, dual_write_until = NULL, updated_at = $1 
+		WHERE id = $2
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetWeeklySummaryEnabled sets whether the automation engine maintains the weekly summary tab in
+// the user's spreadsheet
+func (r *UserRepository) SetWeeklySummaryEnabled(ctx context.Context, userID int, enabled bool) error {
+	query := `
+		UPDATE users 
+		SET weekly_summary_enabled = $1, updated_at = $2 
+		WHERE id = $3
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, enabled, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateGearColumn sets whether the user's activity sheet includes a Gear column
+func (r *UserRepository) UpdateGearColumn(ctx context.Context, userID int, enabled bool) error {
+	query := `
+		UPDATE users 
+		SET sheet_gear_column = $1, updated_at = $2 
+		WHERE id = $3
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, enabled, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetProcessingConfigForUser retrieves all necessary data for automation processing for a specific user
+// This method is optimized for the automation engine and fetches all required fields in a single query.
+// It returns decrypted tokens ready for use by API clients.
+func (r *UserRepository) GetProcessingConfigForUser(ctx context.Context, userID int) (*ProcessingTokens, error) {
+	query := `
+		SELECT google_access_token, google_refresh_token, google_token_expiry,
+			   strava_access_token, strava_refresh_token, strava_token_expiry, strava_athlete_id,
+			   spreadsheet_id, COALESCE(timezone, ''), COALESCE(email, ''),
+			   pending_destination_type, pending_destination_id, dual_write_until,
+			   COALESCE(weekly_summary_enabled, false), COALESCE(sheet_template, ''),
+			   COALESCE(sort_rows_chronologically, false), COALESCE(sheet_gear_column, false),
+			   COALESCE(webhook_url, ''), webhook_secret
+		FROM users WHERE id = $1
+	`
+
+	var encryptedGoogleAccessToken, encryptedGoogleRefreshToken []byte
+	var encryptedStravaAccessToken, encryptedStravaRefreshToken []byte
+	var googleExpiry, stravaExpiry *time.Time
+	var athleteID *int64
+	var spreadsheetID *string
+	var timezone, email string
+	var pendingDestinationType, pendingDestinationID *string
+	var dualWriteUntil *time.Time
+	var weeklySummaryEnabled bool
+	var sheetTemplate string
+	var sortChronologically, gearColumn bool
+	var webhookURL string
+	var encryptedWebhookSecret []byte
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&encryptedGoogleAccessToken, &encryptedGoogleRefreshToken, &googleExpiry,
+		&encryptedStravaAccessToken, &encryptedStravaRefreshToken, &stravaExpiry, &athleteID,
+		&spreadsheetID, &timezone, &email,
+		&pendingDestinationType, &pendingDestinationID, &dualWriteUntil,
+		&weeklySummaryEnabled, &sheetTemplate,
+		&sortChronologically, &gearColumn,
+		&webhookURL, &encryptedWebhookSecret,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcessingTokens{
+		GoogleTokenExpiry: googleExpiry,
+		StravaTokenExpiry: stravaExpiry,
+		StravaAthleteID:   athleteID,
+		SpreadsheetID:     spreadsheetID,
+		Timezone:          timezone,
+		Email:             email,
+
+		PendingDestinationType: pendingDestinationType,
+		PendingDestinationID:   pendingDestinationID,
+		DualWriteUntil:         dualWriteUntil,
+
+		WeeklySummaryEnabled: weeklySummaryEnabled,
+		SheetTemplate:        sheetTemplate,
+		SortChronologically:  sortChronologically,
+		GearColumn:           gearColumn,
+
+		WebhookURL: webhookURL,
+	}
+
+	// Decrypt Google tokens
+	if len(encryptedGoogleAccessToken) > 0 {
+		if result.GoogleAccessToken, err = r.decryptToken(encryptedGoogleAccessToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(encryptedGoogleRefreshToken) > 0 {
+		if result.GoogleRefreshToken, err = r.decryptToken(encryptedGoogleRefreshToken); err != nil {
+			return nil, err
+		}
+	}
+
+	// Decrypt Strava tokens
+	if len(encryptedStravaAccessToken) > 0 {
+		if result.StravaAccessToken, err = r.decryptToken(encryptedStravaAccessToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(encryptedStravaRefreshToken) > 0 {
+		if result.StravaRefreshToken, err = r.decryptToken(encryptedStravaRefreshToken); err != nil {
+			return nil, err
+		}
+	}
+
+	// Decrypt webhook signing secret
+	if len(encryptedWebhookSecret) > 0 {
+		result.WebhookSecret, err = r.encryptor.Decrypt(encryptedWebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetTeamSheetsForUser returns the shared spreadsheets of the teams the user is an active member of.
+// Teams whose coach has no Google connection, was suspended or is no longer a coach are skipped.
+func (r *UserRepository) GetTeamSheetsForUser(ctx context.Context, userID int) ([]TeamSheetTokens, error) {
+	query := `
+		SELECT t.id, t.spreadsheet_id, c.id, a.name,
+		       c.google_access_token, c.google_refresh_token, c.google_token_expiry
+		FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id
+		JOIN users a ON a.id = tm.user_id
+		JOIN users c ON c.id = t.coach_user_id
+		WHERE tm.user_id = $1 AND tm.status = $2
+		  AND t.spreadsheet_id IS NOT NULL
+		  AND c.google_refresh_token IS NOT NULL
+		  AND c.suspended_at IS NULL
+		  AND c.role IN ($3, $4)
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, TeamMemberActive, UserRoleCoach, UserRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sheets []TeamSheetTokens
+	for rows.Next() {
+		var sheet TeamSheetTokens
+		var encryptedAccessToken, encryptedRefreshToken []byte
+		if err := rows.Scan(&sheet.TeamID, &sheet.SpreadsheetID, &sheet.CoachUserID, &sheet.AthleteName,
+			&encryptedAccessToken, &encryptedRefreshToken, &sheet.GoogleTokenExpiry); err != nil {
+			return nil, err
+		}
+		if len(encryptedAccessToken) > 0 {
+			if sheet.GoogleAccessToken, err = r.decryptToken(encryptedAccessToken); err != nil {
+				return nil, err
+			}
+		}
+		if sheet.GoogleRefreshToken, err = r.decryptToken(encryptedRefreshToken); err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets, rows.Err()
+}
+
+// decryptToken decrypts a stored token into a buffer that can be zeroed once the job is done
+func (r *UserRepository) decryptToken(ciphertext []byte) (*secure.Token, error) {
+	plaintext, err := r.encryptor.DecryptBytes(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return secure.NewToken(plaintext), nil
+}
+
+// ListUsersDueForReconciliation returns IDs of connected, unsuspended users not reconciled since olderThan
+// Users that were never reconciled come first
+func (r *UserRepository) ListUsersDueForReconciliation(ctx context.Context, olderThan time.Time, limit int) ([]int, error) {
+	query := `
+		SELECT id FROM users 
+		WHERE strava_refresh_token IS NOT NULL 
+		  AND google_refresh_token IS NOT NULL 
+		  AND suspended_at IS NULL 
+		  AND (last_reconciled_at IS NULL OR last_reconciled_at < $1)
+		ORDER BY last_reconciled_at ASC NULLS FIRST, id ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, olderThan, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// ListUsersReadyForProcessing returns a page of automation-enabled, unsuspended users with both connections
+// whose next run falls in [windowStart, windowEnd), ordered by next run and ID. Pages continue
+// after the cursor of the previous page's last user; a page shorter than limit is the last one.
+// next_run_at is set by EnableAutomation and moved to the next daily run after each job.
+func (r *UserRepository) ListUsersReadyForProcessing(ctx context.Context, windowStart, windowEnd time.Time, after ReadyUserCursor, limit int) ([]ReadyUser, error) {
+	query := `
+		SELECT id, COALESCE(timezone, 'UTC'), automation_enabled, next_run_at
+		FROM users 
+		WHERE automation_enabled = true 
+		  AND next_run_at >= $1 AND next_run_at < $2 
+		  AND (next_run_at, id) > ($3, $4) 
+		  AND strava_refresh_token IS NOT NULL 
+		  AND google_refresh_token IS NOT NULL
+		  AND suspended_at IS NULL
+		ORDER BY next_run_at ASC, id ASC
+		LIMIT $5
+	`
+
+	if after.NextRunAt.IsZero() {
+		after = ReadyUserCursor{NextRunAt: windowStart}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, windowStart, windowEnd, after.NextRunAt, after.UserID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []ReadyUser
+	for rows.Next() {
+		var user ReadyUser
+		if err := rows.Scan(&user.ID, &user.Timezone, &user.AutomationEnabled, &user.NextRunAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateNextRunAt schedules the user's next automated run; nil removes the user from scheduling
+func (r *UserRepository) UpdateNextRunAt(ctx context.Context, userID int, nextRunAt *time.Time) error {
+	query := `
+		UPDATE users 
+		SET next_run_at = $1, updated_at = $2 
+		WHERE id = $3
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, nextRunAt, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateStravaAthleteProfile refreshes the stored Strava athlete name and picture without touching tokens
+func (r *UserRepository) UpdateStravaAthleteProfile(ctx context.Context, userID int, athleteName, profilePictureURL string) error {
+	query := `
+		UPDATE users 
+		SET strava_athlete_name = $1, strava_profile_picture_url = $2, updated_at = $3 
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, athleteName, profilePictureURL, now, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordReconciliation stores the outcome of a background reconciliation run for a user
+func (r *UserRepository) RecordReconciliation(ctx context.Context, userID int, issues []string, stravaReauthRequired, googleReauthRequired bool) error {
+	query := `
+		UPDATE users 
+		SET last_reconciled_at = $1, 
+		    reconciliation_issues = $2, 
+		    strava_reauth_required = $3, 
+		    google_reauth_required = $4, 
+		    updated_at = $1 
+		WHERE id = $5
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, now, pq.Array(issues), stravaReauthRequired, googleReauthRequired, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
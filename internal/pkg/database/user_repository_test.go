@@ -2,10 +2,14 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
 )
 
@@ -44,6 +48,376 @@ func TestUserRepository_UpdateSpreadsheetID(t *testing.T) {
 	}
 }
 
+func TestUserRepository_UpdateSpreadsheetTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	ctx := context.Background()
+	userID := 123
+	spreadsheetID := "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+
+	mock.ExpectExec("UPDATE users SET spreadsheet_id = \\$1, sheet_template = \\$2, updated_at = \\$3 WHERE id = \\$4").
+		WithArgs(spreadsheetID, "coach_plan", sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateSpreadsheetTemplate(ctx, userID, spreadsheetID, "coach_plan"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	ctx := context.Background()
+	userID := 123
+	webhookURL := "https://example.com/hooks/academy"
+
+	mock.ExpectExec("UPDATE users SET webhook_url = \\$1, webhook_secret = \\$2, updated_at = \\$3 WHERE id = \\$4").
+		WithArgs(webhookURL, sqlmock.AnyArg(), sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET webhook_url = NULL, webhook_secret = NULL, updated_at = \\$1 WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.UpdateWebhook(ctx, userID, webhookURL, "s3cret"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.ClearWebhook(ctx, userID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a missing user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_NotificationChannel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	ctx := context.Background()
+	userID := 123
+	webhookURL := "https://hooks.slack.com/services/T000/B000/XXXX"
+	encryptedURL, err := encryptionService.Encrypt(webhookURL)
+	if err != nil {
+		t.Fatalf("Failed to encrypt webhook URL: %v", err)
+	}
+
+	mock.ExpectExec("UPDATE users SET notification_channel = \\$1, chat_webhook_url = \\$2, updated_at = \\$3 WHERE id = \\$4").
+		WithArgs("slack", sqlmock.AnyArg(), sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COALESCE\\(notification_channel, 'email'\\), chat_webhook_url, notification_preferences, .* FROM users WHERE id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"notification_channel", "chat_webhook_url", "notification_preferences",
+			"timezone", "quiet_hours_start", "quiet_hours_end"}).
+			AddRow("slack", encryptedURL, []byte(`{"success_summary": false}`), "Europe/Sofia", "22:00", "07:00"))
+
+	if err := repo.UpdateNotificationChannel(ctx, userID, "slack", webhookURL); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	channel, err := repo.GetNotificationChannel(ctx, userID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if channel.Channel != "slack" || channel.WebhookURL != webhookURL {
+		t.Errorf("Unexpected notification channel: %+v", channel)
+	}
+	if channel.Timezone != "Europe/Sofia" || channel.QuietHoursStart != "22:00" || channel.QuietHoursEnd != "07:00" {
+		t.Errorf("Unexpected quiet hours: %+v", channel)
+	}
+	// Keys missing from the stored preferences stay enabled
+	if channel.Preferences.Allows(PreferenceSuccessSummary) || !channel.Preferences.Allows(PreferenceFailures) {
+		t.Errorf("Unexpected notification preferences: %+v", channel.Preferences)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateNotificationPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, nil)
+
+	// Only security alerts left on: no sync notifications, so the legacy email flag is cleared
+	preferences := NotificationPreferences{SecurityAlerts: true}
+	mock.ExpectExec("UPDATE users SET notification_preferences = \\$1, email_notifications_enabled = \\$2, updated_at = \\$3 WHERE id = \\$4").
+		WithArgs([]byte(`{"success_summary":false,"failures":false,"reauth_alerts":false,"digest":false,"security_alerts":true}`), false, sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET notification_preferences").
+		WithArgs(sqlmock.AnyArg(), true, sqlmock.AnyArg(), 124).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.UpdateNotificationPreferences(context.Background(), 123, preferences); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.UpdateNotificationPreferences(context.Background(), 124, DefaultNotificationPreferences()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateQuietHours(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, nil)
+
+	mock.ExpectExec("UPDATE users SET quiet_hours_start = NULLIF\\(\\$1, ''\\), quiet_hours_end = NULLIF\\(\\$2, ''\\), updated_at = \\$3 WHERE id = \\$4").
+		WithArgs("22:00", "07:00", sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateQuietHours(context.Background(), 123, "22:00", "07:00"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateLocale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	mock.ExpectExec("UPDATE users SET locale = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs("es", sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateLocale(context.Background(), 123, "es"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateDigestSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	mock.ExpectExec("UPDATE users SET notification_mode = \\$1, digest_time = \\$2, updated_at = \\$3 WHERE id = \\$4").
+		WithArgs(NotificationModeDigest, "07:30", sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateDigestSettings(context.Background(), 123, NotificationModeDigest, "07:30"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateGearColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	mock.ExpectExec("UPDATE users SET sheet_gear_column = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(true, sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET sheet_gear_column = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(true, sqlmock.AnyArg(), 456).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.UpdateGearColumn(context.Background(), 123, true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.UpdateGearColumn(context.Background(), 456, true); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_SetWeeklySummaryEnabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	mock.ExpectExec("UPDATE users SET weekly_summary_enabled = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(true, sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET weekly_summary_enabled = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(false, sqlmock.AnyArg(), 456).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.SetWeeklySummaryEnabled(context.Background(), 123, true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.SetWeeklySummaryEnabled(context.Background(), 456, false); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateChronologicalOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	mock.ExpectExec("UPDATE users SET sort_rows_chronologically = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(true, sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET sort_rows_chronologically = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(false, sqlmock.AnyArg(), 456).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.UpdateChronologicalOrder(context.Background(), 123, true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.UpdateChronologicalOrder(context.Background(), 456, false); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_ListUsersReadyForProcessing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	windowStart := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	windowEnd := windowStart.Add(time.Hour)
+	first := windowStart.Add(5 * time.Minute)
+
+	// The first page starts at the window start; the next one after the last user returned
+	mock.ExpectQuery("SELECT id, COALESCE\\(timezone, 'UTC'\\), automation_enabled, next_run_at FROM users").
+		WithArgs(windowStart, windowEnd, windowStart, 0, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "timezone", "automation_enabled", "next_run_at"}).
+			AddRow(7, "Europe/Madrid", true, first).
+			AddRow(3, "UTC", true, first.Add(time.Minute)))
+	mock.ExpectQuery("SELECT id, COALESCE\\(timezone, 'UTC'\\), automation_enabled, next_run_at FROM users").
+		WithArgs(windowStart, windowEnd, first.Add(time.Minute), 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "timezone", "automation_enabled", "next_run_at"}).
+			AddRow(9, "America/New_York", true, first.Add(time.Minute)))
+
+	page, err := repo.ListUsersReadyForProcessing(context.Background(), windowStart, windowEnd, ReadyUserCursor{}, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 7 || page[0].Timezone != "Europe/Madrid" || !page[0].NextRunAt.Equal(first) {
+		t.Fatalf("Unexpected first page: %+v", page)
+	}
+
+	page, err = repo.ListUsersReadyForProcessing(context.Background(), windowStart, windowEnd, page[len(page)-1].Cursor(), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != 9 {
+		t.Errorf("Unexpected last page: %+v", page)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateNextRunAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	encryptionService := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	repo := NewUserRepository(db, encryptionService)
+
+	next := time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE users SET next_run_at = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(&next, sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET next_run_at = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(nil, sqlmock.AnyArg(), 456).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.UpdateNextRunAt(context.Background(), 123, &next); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.UpdateNextRunAt(context.Background(), 456, nil); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
 func TestUserRepository_ClearSpreadsheetID(t *testing.T) {
 	// Create mock database
 	db, mock, err := sqlmock.New()
@@ -199,4 +573,174 @@ func TestUserRepository_UpdateSpreadsheetID_TimestampValidation(t *testing.T) {
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unmet expectations: %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestUserRepository_UpdateUserTokens_TokenVersion(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	version := 4
+
+	tests := []struct {
+		name        string
+		expected    *int
+		rows        int64
+		exists      bool
+		expectedErr error
+	}{
+		{"Unconditional update", nil, 1, true, nil},
+		{"Version matches", &version, 1, true, nil},
+		{"Stale version", &version, 0, true, ErrStaleTokenVersion},
+		{"Missing user", &version, 0, false, sql.ErrNoRows},
+		{"Missing user without version", nil, 0, false, sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+			repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+			var expectedArg interface{}
+			if tt.expected != nil {
+				expectedArg = int64(*tt.expected)
+			}
+			mock.ExpectExec(`UPDATE users\s+SET google_access_token = \$1,.*token_version = token_version \+ 1\s+WHERE id = \$5 AND \(\$6::integer IS NULL OR token_version = \$6\)`).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), &expiry, sqlmock.AnyArg(), 7, expectedArg).
+				WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			if tt.rows == 0 && tt.expected != nil {
+				mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE id = \$1\)`).
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			}
+
+			err := repo.UpdateUserTokens(context.Background(), &UpdateUserTokensRequest{
+				UserID:               7,
+				GoogleAccessToken:    "access",
+				GoogleRefreshToken:   "refresh",
+				GoogleTokenExpiry:    &expiry,
+				ExpectedTokenVersion: tt.expected,
+			})
+			if err != tt.expectedErr {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepository_UpdateStravaConnection_StaleVersion(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	expiry := time.Now().Add(6 * time.Hour)
+	version := 2
+	mock.ExpectExec(`UPDATE users\s+SET strava_access_token = \$1,.*token_version = token_version \+ 1\s+WHERE id = \$8 AND \(\$9::integer IS NULL OR token_version = \$9\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), &expiry, int64(555), "Jane Runner", "https://example.com/p.jpg", sqlmock.AnyArg(), 7, int64(version)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE strava_athlete_id = \$1 AND id <> \$2\)`).
+		WithArgs(int64(555), 7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE id = \$1\)`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err := repo.UpdateStravaConnection(context.Background(), &UpdateStravaConnectionRequest{
+		UserID:               7,
+		AccessToken:          "access",
+		RefreshToken:         "refresh",
+		TokenExpiry:          &expiry,
+		AthleteID:            555,
+		AthleteName:          "Jane Runner",
+		ProfilePictureURL:    "https://example.com/p.jpg",
+		ExpectedTokenVersion: &version,
+	})
+	if err != ErrStaleTokenVersion {
+		t.Errorf("Expected ErrStaleTokenVersion, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateStravaConnection_AthleteConnected(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	req := &UpdateStravaConnectionRequest{UserID: 7, AccessToken: "access", RefreshToken: "refresh", AthleteID: 555, AthleteName: "Jane Runner"}
+	update := `UPDATE users\s+SET strava_access_token = \$1,.*AND NOT EXISTS \(SELECT 1 FROM users WHERE strava_athlete_id = \$4 AND id <> \$8\)`
+
+	// Another user has connected the athlete
+	mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE strava_athlete_id = \$1 AND id <> \$2\)`).
+		WithArgs(int64(555), 7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// ...or connects it concurrently
+	mock.ExpectExec(update).WillReturnError(&pq.Error{Code: "23505", Constraint: "users_strava_athlete_id_key"})
+
+	for i := 0; i < 2; i++ {
+		if err := repo.UpdateStravaConnection(context.Background(), req); err != ErrStravaAthleteConnected {
+			t.Errorf("Expected ErrStravaAthleteConnected, got %v", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_SaveStravaTokens(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	expiry := time.Now().Add(6 * time.Hour)
+	update := `UPDATE users\s+SET strava_access_token = \$1,.*token_version = token_version \+ 1\s+WHERE id = \$5 AND strava_refresh_token IS NOT NULL`
+
+	mock.ExpectExec(update).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), expiry, sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The user disconnected Strava while the token was being refreshed
+	mock.ExpectExec(update).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), expiry, sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.SaveStravaTokens(context.Background(), 7, "access", "rotated", expiry); err != nil {
+		t.Fatalf("SaveStravaTokens failed: %v", err)
+	}
+	if err := repo.SaveStravaTokens(context.Background(), 7, "access", "rotated", expiry); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a disconnected user, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_DisconnectGoogleSheets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db, auth.NewEncryptionService("test-key-32-characters-long!!!"))
+
+	mock.ExpectExec(`UPDATE users\s+SET spreadsheet_id = NULL,\s+google_access_token = NULL,.*automation_enabled = false,.*token_version = token_version \+ 1\s+WHERE id = \$2`).
+		WithArgs(sqlmock.AnyArg(), 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").
+		WithArgs(sqlmock.AnyArg(), 999).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.DisconnectGoogleSheets(context.Background(), 123); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := repo.DisconnectGoogleSheets(context.Background(), 999); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
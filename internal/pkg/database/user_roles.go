@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// GrantRole sets the user's account role and records the change, returning the audit record.
+// It returns nil when the user already holds role and sql.ErrNoRows when there is no such user.
+// The new role reaches the user's access token the next time it is refreshed.
+func (r *UserRepository) GrantRole(ctx context.Context, userID int, role string, changedBy *int, reason string) (*RoleChange, error) {
+	if !ValidUserRole(role) {
+		return nil, fmt.Errorf("unknown role %q", role)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin role change transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldRole string
+	if err := tx.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&oldRole); err != nil {
+		return nil, err
+	}
+	if oldRole == role {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`, role, userID); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	change := &RoleChange{UserID: userID, OldRole: oldRole, NewRole: role, ChangedBy: changedBy, Reason: reason}
+	auditQuery := `
+		INSERT INTO role_changes (user_id, old_role, new_role, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRowContext(ctx, auditQuery, userID, oldRole, role, changedBy, reason).Scan(&change.ID, &change.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to record role change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit role change transaction: %w", err)
+	}
+	return change, nil
+}
+
+// RevokeRole returns the user to the plain user role, recording the change like GrantRole
+func (r *UserRepository) RevokeRole(ctx context.Context, userID int, changedBy *int, reason string) (*RoleChange, error) {
+	return r.GrantRole(ctx, userID, UserRoleUser, changedBy, reason)
+}
+
+// ListRoleChanges returns the user's role changes, newest first
+func (r *UserRepository) ListRoleChanges(ctx context.Context, userID int) ([]RoleChange, error) {
+	query := `
+		SELECT id, user_id, old_role, new_role, changed_by, reason, created_at
+		FROM role_changes
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []RoleChange{}
+	for rows.Next() {
+		var change RoleChange
+		var changedBy sql.NullInt64
+		if err := rows.Scan(&change.ID, &change.UserID, &change.OldRole, &change.NewRole, &changedBy, &change.Reason, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		if changedBy.Valid {
+			id := int(changedBy.Int64)
+			change.ChangedBy = &id
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserRepository_GrantRole(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	adminID := 1
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT role FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(UserRoleUser))
+	mock.ExpectExec("UPDATE users SET role = \\$1").
+		WithArgs(UserRoleSupport, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO role_changes").
+		WithArgs(7, UserRoleUser, UserRoleSupport, &adminID, "On-call rotation").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, now))
+	mock.ExpectCommit()
+
+	// Granting a role the user already holds changes nothing and is not audited
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT role FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(UserRoleSupport))
+	mock.ExpectRollback()
+
+	repo := NewUserRepository(db, nil)
+	change, err := repo.GrantRole(context.Background(), 7, UserRoleSupport, &adminID, "On-call rotation")
+	if err != nil {
+		t.Fatalf("GrantRole failed: %v", err)
+	}
+	if change == nil || change.ID != 4 || change.OldRole != UserRoleUser || change.NewRole != UserRoleSupport {
+		t.Errorf("Unexpected role change: %+v", change)
+	}
+
+	change, err = repo.GrantRole(context.Background(), 7, UserRoleSupport, &adminID, "again")
+	if err != nil || change != nil {
+		t.Errorf("Expected no change for the current role, got %+v, %v", change, err)
+	}
+
+	if _, err := repo.GrantRole(context.Background(), 7, "owner", &adminID, ""); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_ListRoleChanges(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, user_id, old_role, new_role, changed_by, reason, created_at FROM role_changes").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "old_role", "new_role", "changed_by", "reason", "created_at"}).
+			AddRow(5, 7, UserRoleSupport, UserRoleUser, nil, "Left the team", now).
+			AddRow(4, 7, UserRoleUser, UserRoleSupport, 1, "On-call rotation", now.Add(-time.Hour)))
+
+	changes, err := NewUserRepository(db, nil).ListRoleChanges(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("ListRoleChanges failed: %v", err)
+	}
+	if len(changes) != 2 || changes[0].ChangedBy != nil || changes[1].ChangedBy == nil || *changes[1].ChangedBy != 1 {
+		t.Errorf("Unexpected role changes: %+v", changes)
+	}
+}
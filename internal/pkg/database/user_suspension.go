@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SuspendUser suspends the account and signs it out of every session, returning the suspension.
+// It returns nil when the account is already suspended and sql.ErrNoRows when there is no such
+// user. The automation engine's Worker.ProcessUserWithOptions skips jobs of suspended users.
+func (r *UserRepository) SuspendUser(ctx context.Context, userID int, suspendedBy *int, reason string) (*Suspension, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin suspension transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var suspendedAt sql.NullTime
+	if err := tx.QueryRowContext(ctx, `SELECT suspended_at FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&suspendedAt); err != nil {
+		return nil, err
+	}
+	if suspendedAt.Valid {
+		return nil, nil
+	}
+
+	suspension := &Suspension{UserID: userID, SuspendedBy: suspendedBy, Reason: reason}
+	query := `
+		UPDATE users SET suspended_at = NOW(), suspension_reason = $1, suspended_by = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING suspended_at
+	`
+	if err := tx.QueryRowContext(ctx, query, reason, suspendedBy, userID).Scan(&suspension.SuspendedAt); err != nil {
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE user_sessions SET is_active = false WHERE user_id = $1 AND is_active = true`, userID); err != nil {
+		return nil, fmt.Errorf("failed to end sessions of suspended user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit suspension transaction: %w", err)
+	}
+	return suspension, nil
+}
+
+// UnsuspendUser lifts the account's suspension and reports whether it was suspended. It
+// returns sql.ErrNoRows when there is no such user. The user signs in again to get a session.
+func (r *UserRepository) UnsuspendUser(ctx context.Context, userID int) (bool, error) {
+	query := `
+		UPDATE users SET suspended_at = NULL, suspension_reason = '', suspended_by = NULL, updated_at = NOW()
+		WHERE id = $1 AND suspended_at IS NOT NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected > 0 {
+		return true, nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, sql.ErrNoRows
+	}
+	return false, nil
+}
+
+// GetSuspension returns the account's suspension, nil when it is active, and sql.ErrNoRows when
+// there is no such user
+func (r *UserRepository) GetSuspension(ctx context.Context, userID int) (*Suspension, error) {
+	query := `SELECT suspended_at, suspension_reason, suspended_by FROM users WHERE id = $1`
+
+	var suspendedAt sql.NullTime
+	var reason string
+	var suspendedBy sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&suspendedAt, &reason, &suspendedBy); err != nil {
+		return nil, err
+	}
+	if !suspendedAt.Valid {
+		return nil, nil
+	}
+
+	suspension := &Suspension{UserID: userID, SuspendedAt: suspendedAt.Time, Reason: reason}
+	if suspendedBy.Valid {
+		id := int(suspendedBy.Int64)
+		suspension.SuspendedBy = &id
+	}
+	return suspension, nil
+}
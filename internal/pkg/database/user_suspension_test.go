@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserRepository_SuspendUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC)
+	adminID := 1
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT suspended_at FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"suspended_at"}).AddRow(nil))
+	mock.ExpectQuery("UPDATE users SET suspended_at = NOW\\(\\)").
+		WithArgs("Credential stuffing", &adminID, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"suspended_at"}).AddRow(now))
+	mock.ExpectExec("UPDATE user_sessions SET is_active = false WHERE user_id = \\$1").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Suspending a suspended account changes nothing
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT suspended_at FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"suspended_at"}).AddRow(now))
+	mock.ExpectRollback()
+
+	repo := NewUserRepository(db, nil)
+	suspension, err := repo.SuspendUser(context.Background(), 7, &adminID, "Credential stuffing")
+	if err != nil {
+		t.Fatalf("SuspendUser failed: %v", err)
+	}
+	if suspension == nil || !suspension.SuspendedAt.Equal(now) || *suspension.SuspendedBy != adminID {
+		t.Errorf("Unexpected suspension: %+v", suspension)
+	}
+
+	suspension, err = repo.SuspendUser(context.Background(), 7, &adminID, "again")
+	if err != nil || suspension != nil {
+		t.Errorf("Expected no change for a suspended account, got %+v, %v", suspension, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UnsuspendUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users SET suspended_at = NULL").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET suspended_at = NULL").
+		WithArgs(8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec("UPDATE users SET suspended_at = NULL").
+		WithArgs(99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(99).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	repo := NewUserRepository(db, nil)
+	if lifted, err := repo.UnsuspendUser(context.Background(), 7); err != nil || !lifted {
+		t.Errorf("Expected the suspension to be lifted, got %v, %v", lifted, err)
+	}
+	if lifted, err := repo.UnsuspendUser(context.Background(), 8); err != nil || lifted {
+		t.Errorf("Expected an active account to be left alone, got %v, %v", lifted, err)
+	}
+	if _, err := repo.UnsuspendUser(context.Background(), 99); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing user, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+)
+
+func TestUserRepository_GetTeamSheetsForUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	encryption := auth.NewEncryptionService("test-key-32-characters-long!!!")
+	accessToken, err := encryption.Encrypt("coach-access")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	refreshToken, err := encryption.Encrypt("coach-refresh")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	expiry := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM team_members tm").
+		WithArgs(7, TeamMemberActive, UserRoleCoach, UserRoleAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spreadsheet_id", "coach_id", "name",
+			"google_access_token", "google_refresh_token", "google_token_expiry"}).
+			AddRow(3, "sheet-3", 2, "Ana", accessToken, refreshToken, expiry).
+			AddRow(5, "sheet-5", 4, "Ana", nil, refreshToken, nil))
+
+	sheets, err := NewUserRepository(db, encryption).GetTeamSheetsForUser(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetTeamSheetsForUser failed: %v", err)
+	}
+	if len(sheets) != 2 {
+		t.Fatalf("Expected 2 team sheets, got %d", len(sheets))
+	}
+
+	first := sheets[0]
+	if first.TeamID != 3 || first.SpreadsheetID != "sheet-3" || first.CoachUserID != 2 || first.AthleteName != "Ana" {
+		t.Errorf("Unexpected team sheet: %+v", first)
+	}
+	if first.GoogleAccessToken.Reveal() != "coach-access" || first.GoogleRefreshToken.Reveal() != "coach-refresh" {
+		t.Error("Expected the coach's tokens to be decrypted")
+	}
+	if second := sheets[1]; !second.GoogleAccessToken.Empty() || second.GoogleRefreshToken.Reveal() != "coach-refresh" {
+		t.Error("Expected a missing access token to stay empty")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
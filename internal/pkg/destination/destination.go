@@ -0,0 +1,137 @@
+package destination
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// Supported destination types
+const (
+	TypeGoogleSheets = "google_sheets"
+	TypeWebhook      = "webhook"
+)
+
+// Destination is an output target the automation engine writes activities to
+type Destination interface {
+	// Name returns a human readable identifier used in logs and reports
+	Name() string
+
+	// ValidateAccess checks that the destination can be written to
+	ValidateAccess(ctx context.Context) error
+
+	// EnsureSchema prepares the destination's structure (tabs, header rows, fields) for writing
+	// It must be idempotent and leave user customisations such as renamed columns untouched
+	EnsureSchema(ctx context.Context) error
+
+	// WriteActivities persists activities and reports what was written
+	WriteActivities(ctx context.Context, activities []strava.Activity) (*WriteResult, error)
+}
+
+// WriteResult describes the outcome of writing activities to a destination
+type WriteResult struct {
+	Destination string `json:"destination"`
+	RowsWritten int    `json:"rows_written"`
+	RowsUpdated int    `json:"rows_updated"`
+
+	// DeletedActivityIDs lists activities flagged in the destination as deleted on Strava
+	DeletedActivityIDs []int64 `json:"deleted_activity_ids,omitempty"`
+
+	// NewActivityIDs lists activities the destination had not seen before this write
+	NewActivityIDs []int64 `json:"new_activity_ids,omitempty"`
+
+	// Records maps Strava activity IDs to a fingerprint of the data the destination stored for them.
+	// Destinations implementing RecordReader leave it empty; dual-write reads their records back.
+	Records map[int64]string `json:"-"`
+
+	// Validation is populated when the write went through a dual-write wrapper
+	Validation *ValidationReport `json:"validation,omitempty"`
+
+	// Verification reports the readback of the written rows when readback verification is enabled
+	Verification *google.WriteVerification `json:"verification,omitempty"`
+
+	// SheetWrites records the spreadsheet rows written so the write can be undone; nil for other destinations
+	SheetWrites *google.SheetWrites `json:"-"`
+}
+
+// fingerprintFields are the fields every catalog template writes. Fingerprints cover only these,
+// in their written form, so rows read back from a sheet compare with data stored elsewhere.
+var fingerprintFields = []templates.Field{
+	templates.FieldActivityID,
+	templates.FieldDate,
+	templates.FieldName,
+	templates.FieldDistance,
+	templates.FieldDuration,
+	templates.FieldHeartRate,
+}
+
+// Fingerprint returns a destination-independent digest of the activity fields written by the engine
+// Two destinations that stored the same version of an activity produce the same fingerprint
+func Fingerprint(activity strava.Activity) string {
+	return fingerprintCells(func(field templates.Field) string {
+		return strings.TrimPrefix(fmt.Sprint(templates.FormatField(field, activity)), "'")
+	})
+}
+
+// fingerprintCells digests the written form of each fingerprint field
+func fingerprintCells(cell func(templates.Field) string) string {
+	h := sha256.New()
+	for _, field := range fingerprintFields {
+		h.Write([]byte(cell(field)))
+		h.Write([]byte{0x1f})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// recordsFor builds the Records map for a set of activities
+func recordsFor(activities []strava.Activity) map[int64]string {
+	records := make(map[int64]string, len(activities))
+	for _, activity := range activities {
+		records[activity.ID] = Fingerprint(activity)
+	}
+	return records
+}
+
+// RecordReader is implemented by destinations that can read back what they store. Dual-write
+// compares the records read back rather than what a write reports, so activities a destination
+// dropped or stored differently show up as discrepancies.
+type RecordReader interface {
+	// ReadRecords fingerprints the stored data of the given activities; activities the
+	// destination does not hold are absent from the result
+	ReadRecords(ctx context.Context, activityIDs []int64) (map[int64]string, error)
+}
+
+// Previewer is implemented by destinations that can report the writes they would perform
+// without modifying anything, used by dry-run syncs
+type Previewer interface {
+	PreviewActivities(ctx context.Context, activities []strava.Activity) (*Preview, error)
+}
+
+// Preview describes the writes a destination would perform for a set of activities
+type Preview struct {
+	Destination  string `json:"destination"`
+	RowsToWrite  int    `json:"rows_to_write"`
+	RowsToUpdate int    `json:"rows_to_update"`
+
+	// DeletedActivityIDs lists activities that would be flagged as deleted on Strava
+	DeletedActivityIDs []int64 `json:"deleted_activity_ids,omitempty"`
+
+	Rows []PreviewRow `json:"rows"`
+}
+
+// PreviewRow is a single would-be write
+type PreviewRow struct {
+	// Action is one of append, update or flag_deleted
+	Action string `json:"action"`
+
+	// Location identifies where the row would be written (e.g. an A1 range)
+	Location string `json:"location"`
+
+	Values []interface{} `json:"values"`
+}
@@ -0,0 +1,213 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// ValidationReport summarizes how a candidate destination compared to the primary during dual-write
+type ValidationReport struct {
+	Primary   string `json:"primary"`
+	Candidate string `json:"candidate"`
+
+	PrimaryRows   int `json:"primary_rows"`
+	CandidateRows int `json:"candidate_rows"`
+
+	// Activity IDs written to the primary but not the candidate
+	MissingInCandidate []int64 `json:"missing_in_candidate,omitempty"`
+	// Activity IDs written to the candidate but not the primary
+	UnexpectedInCandidate []int64 `json:"unexpected_in_candidate,omitempty"`
+	// Activity IDs present in both with different data
+	Mismatched []int64 `json:"mismatched,omitempty"`
+
+	// CandidateError is set when the candidate could not be validated, written or read back
+	CandidateError string `json:"candidate_error,omitempty"`
+	// PrimaryError is set when the primary's stored records could not be read back, leaving the
+	// comparison incomplete; the primary write itself succeeded
+	PrimaryError string `json:"primary_error,omitempty"`
+}
+
+// HasDiscrepancies reports whether the candidate diverged from the primary in any way
+func (r *ValidationReport) HasDiscrepancies() bool {
+	return r.CandidateError != "" ||
+		r.PrimaryError != "" ||
+		len(r.MissingInCandidate) > 0 ||
+		len(r.UnexpectedInCandidate) > 0 ||
+		len(r.Mismatched) > 0
+}
+
+// DualWrite wraps a primary destination and a candidate the user is migrating to
+// The primary remains authoritative: its errors fail the write, while candidate failures
+// and differences are only recorded in the validation report so cutover can be decided safely
+type DualWrite struct {
+	primary   Destination
+	candidate Destination
+	logger    *logger.Logger
+}
+
+// NewDualWrite creates a dual-write wrapper around the primary and candidate destinations
+func NewDualWrite(primary, candidate Destination, logger *logger.Logger) *DualWrite {
+	return &DualWrite{
+		primary:   primary,
+		candidate: candidate,
+		logger: logger.WithContext(
+			"component", "dual_write_destination",
+			"primary", primary.Name(),
+			"candidate", candidate.Name()),
+	}
+}
+
+// Name returns the primary destination name
+func (d *DualWrite) Name() string {
+	return d.primary.Name()
+}
+
+// ValidateAccess validates the primary destination; candidate access problems are logged but not fatal
+func (d *DualWrite) ValidateAccess(ctx context.Context) error {
+	if err := d.primary.ValidateAccess(ctx); err != nil {
+		return err
+	}
+
+	if err := d.candidate.ValidateAccess(ctx); err != nil {
+		d.logger.Warn("⚠️ Candidate destination failed access validation during dual-write window",
+			"error", err)
+	}
+
+	return nil
+}
+
+// EnsureSchema prepares the primary destination; candidate failures are logged but not fatal
+func (d *DualWrite) EnsureSchema(ctx context.Context) error {
+	if err := d.primary.EnsureSchema(ctx); err != nil {
+		return err
+	}
+
+	if err := d.candidate.EnsureSchema(ctx); err != nil {
+		d.logger.Warn("⚠️ Candidate destination failed schema preparation during dual-write window",
+			"error", err)
+	}
+
+	return nil
+}
+
+// WriteActivities writes to both destinations and attaches a comparison report to the primary result
+func (d *DualWrite) WriteActivities(ctx context.Context, activities []strava.Activity) (*WriteResult, error) {
+	primaryResult, err := d.primary.WriteActivities(ctx, activities)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateResult, candidateErr := d.candidate.WriteActivities(ctx, activities)
+
+	activityIDs := make([]int64, len(activities))
+	for i, activity := range activities {
+		activityIDs[i] = activity.ID
+	}
+	primaryReadErr := readBackRecords(ctx, d.primary, primaryResult, activityIDs)
+	if candidateErr == nil {
+		candidateErr = readBackRecords(ctx, d.candidate, candidateResult, activityIDs)
+	}
+
+	report := Compare(primaryResult, candidateResult)
+	report.Primary = d.primary.Name()
+	report.Candidate = d.candidate.Name()
+	if candidateErr != nil {
+		report.CandidateError = candidateErr.Error()
+	}
+	if primaryReadErr != nil {
+		// Without the primary's records there is nothing to compare the candidate's with
+		report.PrimaryError = primaryReadErr.Error()
+		report.MissingInCandidate, report.UnexpectedInCandidate, report.Mismatched = nil, nil, nil
+	}
+
+	if report.HasDiscrepancies() {
+		d.logger.Warn("⚠️ Dual-write validation found discrepancies between destinations",
+			"validation_report", map[string]interface{}{
+				"primary_rows":            report.PrimaryRows,
+				"candidate_rows":          report.CandidateRows,
+				"missing_in_candidate":    report.MissingInCandidate,
+				"unexpected_in_candidate": report.UnexpectedInCandidate,
+				"mismatched":              report.Mismatched,
+				"candidate_error":         report.CandidateError,
+				"primary_error":           report.PrimaryError,
+			})
+	} else {
+		d.logger.Info("✅ Dual-write validation matched between destinations",
+			"rows", report.PrimaryRows)
+	}
+
+	primaryResult.Validation = report
+	return primaryResult, nil
+}
+
+// readBackRecords replaces the result's records with those read back from destinations that
+// implement RecordReader
+func readBackRecords(ctx context.Context, dest Destination, result *WriteResult, activityIDs []int64) error {
+	reader, ok := dest.(RecordReader)
+	if !ok || result == nil {
+		return nil
+	}
+
+	records, err := reader.ReadRecords(ctx, activityIDs)
+	if err != nil {
+		result.Records = nil
+		return fmt.Errorf("failed to read back written activities: %w", err)
+	}
+	result.Records = records
+	return nil
+}
+
+// PreviewActivities previews the primary destination only; the candidate is never written in a dry run
+func (d *DualWrite) PreviewActivities(ctx context.Context, activities []strava.Activity) (*Preview, error) {
+	previewer, ok := d.primary.(Previewer)
+	if !ok {
+		return nil, fmt.Errorf("destination %s does not support previews", d.primary.Name())
+	}
+	return previewer.PreviewActivities(ctx, activities)
+}
+
+// Compare builds a validation report from the results of writing to the primary and candidate
+// A nil candidate result is treated as nothing having been written to the candidate
+func Compare(primary, candidate *WriteResult) *ValidationReport {
+	report := &ValidationReport{}
+	if primary == nil {
+		return report
+	}
+	report.PrimaryRows = primary.RowsWritten
+
+	candidateRecords := map[int64]string{}
+	if candidate != nil {
+		report.CandidateRows = candidate.RowsWritten
+		candidateRecords = candidate.Records
+	}
+
+	for id, fingerprint := range primary.Records {
+		candidateFingerprint, ok := candidateRecords[id]
+		switch {
+		case !ok:
+			report.MissingInCandidate = append(report.MissingInCandidate, id)
+		case candidateFingerprint != fingerprint:
+			report.Mismatched = append(report.Mismatched, id)
+		}
+	}
+
+	for id := range candidateRecords {
+		if _, ok := primary.Records[id]; !ok {
+			report.UnexpectedInCandidate = append(report.UnexpectedInCandidate, id)
+		}
+	}
+
+	sortIDs(report.MissingInCandidate)
+	sortIDs(report.UnexpectedInCandidate)
+	sortIDs(report.Mismatched)
+
+	return report
+}
+
+func sortIDs(ids []int64) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
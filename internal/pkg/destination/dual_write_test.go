@@ -0,0 +1,272 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// mockDestination records writes in memory and can be configured to fail or alter data
+type mockDestination struct {
+	name        string
+	validateErr error
+	schemaErr   error
+	writeErr    error
+	skipIDs     map[int64]bool
+	alterIDs    map[int64]bool
+	writes      int
+	schemaCalls int
+}
+
+func (m *mockDestination) Name() string { return m.name }
+
+func (m *mockDestination) ValidateAccess(ctx context.Context) error { return m.validateErr }
+
+func (m *mockDestination) EnsureSchema(ctx context.Context) error {
+	m.schemaCalls++
+	return m.schemaErr
+}
+
+func (m *mockDestination) WriteActivities(ctx context.Context, activities []strava.Activity) (*WriteResult, error) {
+	m.writes++
+	if m.writeErr != nil {
+		return nil, m.writeErr
+	}
+
+	records := map[int64]string{}
+	for _, activity := range activities {
+		if m.skipIDs[activity.ID] {
+			continue
+		}
+		if m.alterIDs[activity.ID] {
+			activity.Name = activity.Name + " (altered)"
+		}
+		records[activity.ID] = Fingerprint(activity)
+	}
+
+	return &WriteResult{Destination: m.name, RowsWritten: len(records), Records: records}, nil
+}
+
+func testActivities() []strava.Activity {
+	return []strava.Activity{
+		{ID: 1, Name: "Morning Run", Type: "Run", Distance: 5000, MovingTime: 1500},
+		{ID: 2, Name: "Evening Ride", Type: "Ride", Distance: 20000, MovingTime: 3600},
+		{ID: 3, Name: "Recovery Run", Type: "Run", Distance: 3000, MovingTime: 1100},
+	}
+}
+
+func TestDualWrite_MatchingDestinations(t *testing.T) {
+	primary := &mockDestination{name: "primary"}
+	candidate := &mockDestination{name: "candidate"}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	result, err := dual.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Validation == nil {
+		t.Fatal("Expected validation report to be attached")
+	}
+	if result.Validation.HasDiscrepancies() {
+		t.Errorf("Expected no discrepancies, got %+v", result.Validation)
+	}
+	if result.RowsWritten != 3 || result.Validation.CandidateRows != 3 {
+		t.Errorf("Expected 3 rows in both destinations, got primary=%d candidate=%d",
+			result.RowsWritten, result.Validation.CandidateRows)
+	}
+	if dual.Name() != "primary" {
+		t.Errorf("Expected wrapper to report primary name, got %s", dual.Name())
+	}
+}
+
+func TestDualWrite_ReportsDiscrepancies(t *testing.T) {
+	primary := &mockDestination{name: "primary"}
+	candidate := &mockDestination{
+		name:     "candidate",
+		skipIDs:  map[int64]bool{2: true},
+		alterIDs: map[int64]bool{3: true},
+	}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	result, err := dual.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := result.Validation
+	if !report.HasDiscrepancies() {
+		t.Fatal("Expected discrepancies to be reported")
+	}
+	if len(report.MissingInCandidate) != 1 || report.MissingInCandidate[0] != 2 {
+		t.Errorf("Expected activity 2 missing in candidate, got %v", report.MissingInCandidate)
+	}
+	if len(report.Mismatched) != 1 || report.Mismatched[0] != 3 {
+		t.Errorf("Expected activity 3 mismatched, got %v", report.Mismatched)
+	}
+}
+
+// readBackDestination reports every write as stored intact, but reads back what it actually holds
+type readBackDestination struct {
+	mockDestination
+	stored  map[int64]string
+	readErr error
+}
+
+func (r *readBackDestination) ReadRecords(ctx context.Context, activityIDs []int64) (map[int64]string, error) {
+	if r.readErr != nil {
+		return nil, r.readErr
+	}
+	records := map[int64]string{}
+	for _, id := range activityIDs {
+		if fingerprint, ok := r.stored[id]; ok {
+			records[id] = fingerprint
+		}
+	}
+	return records, nil
+}
+
+func TestDualWrite_ComparesReadBackRecords(t *testing.T) {
+	activities := testActivities()
+	altered := activities[2]
+	altered.Distance = 3100
+
+	primary := &readBackDestination{
+		mockDestination: mockDestination{name: "primary"},
+		stored:          recordsFor(activities),
+	}
+	candidate := &readBackDestination{
+		mockDestination: mockDestination{name: "candidate"},
+		stored: map[int64]string{
+			activities[0].ID: Fingerprint(activities[0]),
+			altered.ID:       Fingerprint(altered),
+		},
+	}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	result, err := dual.WriteActivities(context.Background(), activities)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := result.Validation
+	if len(report.MissingInCandidate) != 1 || report.MissingInCandidate[0] != 2 {
+		t.Errorf("Expected activity 2 missing in candidate, got %v", report.MissingInCandidate)
+	}
+	if len(report.Mismatched) != 1 || report.Mismatched[0] != 3 {
+		t.Errorf("Expected activity 3 mismatched, got %v", report.Mismatched)
+	}
+}
+
+func TestDualWrite_ReadBackFailures(t *testing.T) {
+	primary := &readBackDestination{
+		mockDestination: mockDestination{name: "primary"},
+		stored:          recordsFor(testActivities()),
+	}
+	candidate := &readBackDestination{
+		mockDestination: mockDestination{name: "candidate"},
+		readErr:         errors.New("quota exceeded"),
+	}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	result, err := dual.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("Expected candidate readback failure to be tolerated, got %v", err)
+	}
+	if result.Validation.CandidateError == "" || len(result.Validation.MissingInCandidate) != 3 {
+		t.Errorf("Expected candidate readback error and all activities missing, got %+v", result.Validation)
+	}
+
+	primary.readErr = errors.New("quota exceeded")
+	candidate.readErr = nil
+	candidate.stored = map[int64]string{}
+	result, err = dual.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("Expected primary readback failure to be tolerated, got %v", err)
+	}
+	report := result.Validation
+	if report.PrimaryError == "" || !report.HasDiscrepancies() {
+		t.Errorf("Expected primary readback error to be reported, got %+v", report)
+	}
+	if len(report.MissingInCandidate) != 0 || len(report.Mismatched) != 0 {
+		t.Errorf("Expected no comparison without the primary's records, got %+v", report)
+	}
+}
+
+func TestDualWrite_CandidateFailureIsNotFatal(t *testing.T) {
+	primary := &mockDestination{name: "primary"}
+	candidate := &mockDestination{
+		name:        "candidate",
+		validateErr: errors.New("no access"),
+		writeErr:    errors.New("write failed"),
+	}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	if err := dual.ValidateAccess(context.Background()); err != nil {
+		t.Errorf("Expected candidate validation failure to be tolerated, got %v", err)
+	}
+
+	result, err := dual.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("Expected candidate write failure to be tolerated, got %v", err)
+	}
+	if result.Validation.CandidateError != "write failed" {
+		t.Errorf("Expected candidate error in report, got %q", result.Validation.CandidateError)
+	}
+	if len(result.Validation.MissingInCandidate) != 3 {
+		t.Errorf("Expected all activities missing in candidate, got %v", result.Validation.MissingInCandidate)
+	}
+}
+
+func TestDualWrite_PrimaryFailureIsFatal(t *testing.T) {
+	primary := &mockDestination{name: "primary", writeErr: errors.New("primary down")}
+	candidate := &mockDestination{name: "candidate"}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	if _, err := dual.WriteActivities(context.Background(), testActivities()); err == nil {
+		t.Fatal("Expected primary failure to be returned")
+	}
+	if candidate.writes != 0 {
+		t.Errorf("Expected candidate not to be written when primary fails, got %d writes", candidate.writes)
+	}
+}
+
+func TestDualWrite_EnsureSchema(t *testing.T) {
+	primary := &mockDestination{name: "primary"}
+	candidate := &mockDestination{name: "candidate", schemaErr: errors.New("no access")}
+	dual := NewDualWrite(primary, candidate, logger.New("test"))
+
+	if err := dual.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("Expected candidate schema failures to be tolerated, got %v", err)
+	}
+	if primary.schemaCalls != 1 || candidate.schemaCalls != 1 {
+		t.Errorf("Expected both destinations to be prepared, got %d and %d", primary.schemaCalls, candidate.schemaCalls)
+	}
+
+	primary.schemaErr = errors.New("quota exceeded")
+	if err := dual.EnsureSchema(context.Background()); err == nil {
+		t.Error("Expected primary schema failures to fail the call")
+	}
+}
+
+// TestFingerprint_MatchesSheetCells checks that rows read back from any template fingerprint the
+// same as the activity they were written from
+func TestFingerprint_MatchesSheetCells(t *testing.T) {
+	activity := strava.Activity{ID: 7, Name: "Tempo Run", Type: "Run", Distance: 8000, MovingTime: 2400, AverageHeartrate: 151}
+
+	for _, template := range templates.All() {
+		row := template.Row(activity)
+		cells := fingerprintCells(func(field templates.Field) string {
+			return strings.TrimPrefix(fmt.Sprint(row[template.ColumnIndex(field)]), "'")
+		})
+		if cells != Fingerprint(activity) {
+			t.Errorf("Expected %s rows to fingerprint like the activity", template.ID)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// SheetsDestination writes activities to a single Google Spreadsheet
+type SheetsDestination struct {
+	client        *google.SheetsClient
+	spreadsheetID string
+
+	// windowStart is the start of the fetch window; rows after it missing from Strava are flagged deleted
+	windowStart time.Time
+}
+
+// NewSheetsDestination creates a destination backed by the given Sheets client and spreadsheet
+// windowStart is the start of the activity fetch window used for deletion detection (zero disables it)
+func NewSheetsDestination(client *google.SheetsClient, spreadsheetID string, windowStart time.Time) *SheetsDestination {
+	return &SheetsDestination{
+		client:        client,
+		spreadsheetID: spreadsheetID,
+		windowStart:   windowStart,
+	}
+}
+
+// Name returns the destination identifier
+func (d *SheetsDestination) Name() string {
+	return fmt.Sprintf("%s:%s", TypeGoogleSheets, d.spreadsheetID)
+}
+
+// ValidateAccess checks read/write access to the spreadsheet
+func (d *SheetsDestination) ValidateAccess(ctx context.Context) error {
+	return d.client.ValidateAccess(ctx, d.spreadsheetID)
+}
+
+// EnsureSchema creates the activities tab if needed and fills in missing template header cells
+func (d *SheetsDestination) EnsureSchema(ctx context.Context) error {
+	_, err := d.client.EnsureActivityHeader(ctx, d.spreadsheetID)
+	return err
+}
+
+// WriteActivities reconciles the activities with the rows already in the spreadsheet
+func (d *SheetsDestination) WriteActivities(ctx context.Context, activities []strava.Activity) (*WriteResult, error) {
+	syncResult, err := d.client.SyncActivities(ctx, d.spreadsheetID, activities, d.windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WriteResult{
+		Destination:        d.Name(),
+		RowsWritten:        syncResult.Appended + syncResult.Updated,
+		RowsUpdated:        syncResult.Updated,
+		DeletedActivityIDs: syncResult.DeletedActivityIDs,
+		NewActivityIDs:     syncResult.AppendedActivityIDs,
+		Verification:       syncResult.Verification,
+		SheetWrites:        syncResult.Writes,
+	}, nil
+}
+
+// ReadRecords reads back the rows holding the activities and fingerprints their stored cells
+func (d *SheetsDestination) ReadRecords(ctx context.Context, activityIDs []int64) (map[int64]string, error) {
+	cells, err := d.client.ReadActivityCells(ctx, d.spreadsheetID, activityIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[int64]string, len(cells))
+	for id, fields := range cells {
+		records[id] = fingerprintCells(func(field templates.Field) string { return fields[field] })
+	}
+	return records, nil
+}
+
+// PreviewActivities reports the row writes WriteActivities would perform without modifying the spreadsheet
+func (d *SheetsDestination) PreviewActivities(ctx context.Context, activities []strava.Activity) (*Preview, error) {
+	syncPreview, err := d.client.PreviewActivitySync(ctx, d.spreadsheetID, activities, d.windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{
+		Destination:        d.Name(),
+		RowsToWrite:        syncPreview.Appended + syncPreview.Updated,
+		RowsToUpdate:       syncPreview.Updated,
+		DeletedActivityIDs: syncPreview.DeletedActivityIDs,
+		Rows:               make([]PreviewRow, 0, len(syncPreview.Rows)),
+	}
+	for _, row := range syncPreview.Rows {
+		preview.Rows = append(preview.Rows, PreviewRow{
+			Action:   row.Action,
+			Location: row.Range,
+			Values:   row.Values,
+		})
+	}
+
+	return preview, nil
+}
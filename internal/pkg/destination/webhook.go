@@ -0,0 +1,232 @@
+package destination
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-Academy-Signature"
+	WebhookTimestampHeader = "X-Academy-Timestamp"
+	WebhookEventHeader     = "X-Academy-Event"
+)
+
+// WebhookEventSyncCompleted is sent after a run synced new activities
+const WebhookEventSyncCompleted = "sync.completed"
+
+// webhookTimeout bounds a single delivery so a slow receiver cannot stall the run
+const webhookTimeout = 10 * time.Second
+
+// webhookResolveTimeout bounds the DNS lookup done when a webhook URL is validated
+const webhookResolveTimeout = 5 * time.Second
+
+// WebhookPayload is the JSON body posted to a user's webhook
+type WebhookPayload struct {
+	Event      string            `json:"event"`
+	UserID     int               `json:"user_id"`
+	TraceID    string            `json:"trace_id,omitempty"`
+	SentAt     time.Time         `json:"sent_at"`
+	Activities []strava.Activity `json:"activities"`
+}
+
+// Webhook posts newly synced activities to a user-configured URL
+// Each request is signed with HMAC-SHA256 over "<timestamp>.<body>" using the user's shared secret,
+// sent as "sha256=<hex>" in X-Academy-Signature alongside the Unix timestamp in X-Academy-Timestamp
+type Webhook struct {
+	url        string
+	secret     string
+	userID     int
+	traceID    string
+	httpClient *http.Client
+}
+
+// NewWebhook creates a webhook destination for one user's run
+func NewWebhook(webhookURL, secret string, userID int, traceID string) *Webhook {
+	return &Webhook{
+		url:        webhookURL,
+		secret:     secret,
+		userID:     userID,
+		traceID:    traceID,
+		httpClient: newWebhookClient(isBlockedWebhookIP),
+	}
+}
+
+// newWebhookClient returns a client that refuses to connect to blocked addresses
+// The check runs on the resolved address at dial time so a DNS answer that changes after
+// validation cannot point the request at an internal host; redirects are never followed
+func newWebhookClient(blocked func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || blocked(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Name returns the destination identifier; only the host is included to keep URL tokens out of logs
+func (d *Webhook) Name() string {
+	host := d.url
+	if parsed, err := url.Parse(d.url); err == nil {
+		host = parsed.Host
+	}
+	return fmt.Sprintf("%s:%s", TypeWebhook, host)
+}
+
+// ValidateAccess checks the webhook is configured; the receiver itself is only contacted on write
+func (d *Webhook) ValidateAccess(ctx context.Context) error {
+	if d.secret == "" {
+		return fmt.Errorf("webhook has no signing secret")
+	}
+	return ValidateWebhookURL(ctx, d.url)
+}
+
+// EnsureSchema is a no-op: the payload format is fixed
+func (d *Webhook) EnsureSchema(ctx context.Context) error {
+	return nil
+}
+
+// WriteActivities posts the activities in a single signed request
+// Any non-2xx response, including a redirect, is an error; deliveries are not retried within a run
+func (d *Webhook) WriteActivities(ctx context.Context, activities []strava.Activity) (*WriteResult, error) {
+	sentAt := time.Now().UTC()
+	body, err := json.Marshal(WebhookPayload{
+		Event:      WebhookEventSyncCompleted,
+		UserID:     d.userID,
+		TraceID:    d.traceID,
+		SentAt:     sentAt,
+		Activities: activities,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, WebhookEventSyncCompleted)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook receiver returned status %d", resp.StatusCode)
+	}
+
+	// The receiver cannot be read back, so records describe the payload it acknowledged
+	var delivered WebhookPayload
+	if err := json.Unmarshal(body, &delivered); err != nil {
+		return nil, fmt.Errorf("failed to decode delivered webhook payload: %w", err)
+	}
+
+	return &WriteResult{
+		Destination: d.Name(),
+		RowsWritten: len(delivered.Activities),
+		Records:     recordsFor(delivered.Activities),
+	}, nil
+}
+
+// SignWebhook returns the signature header value for a payload sent at timestamp
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature matches the payload, in constant time
+// Receivers should also reject timestamps too far from their own clock to prevent replays
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+// ValidateWebhookURL requires an absolute https URL whose host resolves only to public addresses
+// Loopback, private, link-local and unspecified addresses are rejected so a webhook cannot be
+// used to reach internal services; deliveries repeat the check when they connect
+func ValidateWebhookURL(ctx context.Context, webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute URL")
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("webhook URL must use https")
+	}
+
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if isBlockedWebhookIP(ip) {
+			return fmt.Errorf("webhook URL must not point to an internal address")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookResolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isBlockedWebhookIP(addr.IP) {
+			return fmt.Errorf("webhook URL must not point to an internal address")
+		}
+	}
+	return nil
+}
+
+// isBlockedWebhookIP reports whether ip is an address webhooks may not be delivered to
+func isBlockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast()
+}
@@ -0,0 +1,115 @@
+package destination
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// allowLoopback lets tests deliver to httptest servers while every other internal address stays blocked
+func allowLoopback(ip net.IP) bool {
+	return !ip.IsLoopback() && isBlockedWebhookIP(ip)
+}
+
+func TestWebhook_WriteActivitiesSignsPayload(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature("s3cret", r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "s3cret", 7, "trace-1")
+	webhook.httpClient = newWebhookClient(allowLoopback)
+	result, err := webhook.WriteActivities(context.Background(), testActivities())
+	if err != nil {
+		t.Fatalf("WriteActivities failed: %v", err)
+	}
+
+	if result.RowsWritten != 3 || len(payload.Activities) != 3 {
+		t.Errorf("Expected 3 delivered activities, got result %d and payload %d", result.RowsWritten, len(payload.Activities))
+	}
+	if payload.Event != WebhookEventSyncCompleted || payload.UserID != 7 || payload.TraceID != "trace-1" {
+		t.Errorf("Unexpected payload envelope: %+v", payload)
+	}
+}
+
+func TestWebhook_WriteActivitiesRejectedSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature("other", r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "s3cret", 7, "")
+	webhook.httpClient = newWebhookClient(allowLoopback)
+	if _, err := webhook.WriteActivities(context.Background(), testActivities()); err == nil {
+		t.Error("Expected an error when the receiver rejects the signature")
+	}
+}
+
+func TestWebhook_WriteActivitiesRefusesLoopbackAtDial(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	_, err := NewWebhook(server.URL, "s3cret", 7, "").WriteActivities(context.Background(), testActivities())
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("Expected the loopback connection to be refused, got %v", err)
+	}
+	if hit {
+		t.Error("Expected the receiver not to be contacted")
+	}
+}
+
+func TestWebhook_WriteActivitiesDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://10.0.0.1/internal", http.StatusFound)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "s3cret", 7, "")
+	webhook.httpClient = newWebhookClient(allowLoopback)
+	_, err := webhook.WriteActivities(context.Background(), testActivities())
+	if err == nil || !strings.Contains(err.Error(), "status 302") {
+		t.Errorf("Expected the redirect to be reported instead of followed, got %v", err)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://93.184.215.14/hooks/academy": true,
+		"https://127.0.0.1/hook":              false,
+		"https://[::1]/hook":                  false,
+		"https://169.254.169.254/latest":      false,
+		"https://10.1.2.3/hook":               false,
+		"https://0.0.0.0/hook":                false,
+		"http://localhost:8080/hook":          false,
+		"http://example.com/hook":             false,
+		"ftp://example.com/hook":              false,
+		"/relative/hook":                      false,
+	}
+
+	for webhookURL, valid := range tests {
+		if err := ValidateWebhookURL(context.Background(), webhookURL); (err == nil) != valid {
+			t.Errorf("ValidateWebhookURL(%q) error = %v, expected valid=%v", webhookURL, err, valid)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package devstub serves fake Strava and Google APIs for local development and tests.
+//
+// One server answers for every provider endpoint under the paths in config (StubStravaAPIPath,
+// StubGoogleSheetsPath, ...), so setting PROVIDER_STUB_URL to its address is enough to run the
+// whole stack without real OAuth apps. OAuth consent screens redirect straight back with a code,
+// every token is accepted, Strava serves a seeded activity history and spreadsheets are kept in
+// memory; any spreadsheet ID is valid and starts out empty.
+package devstub
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Defaults for the stub identities
+const (
+	DefaultEmail     = "athlete@devstub.local"
+	DefaultName      = "Dev Athlete"
+	DefaultAthleteID = 424242
+	// DefaultGoogleID is the Google account ID of the stub identity
+	DefaultGoogleID = "devstub-google-user"
+)
+
+// Options configure the stub identities and Strava history
+type Options struct {
+	// Email and Name are returned by the Google user info endpoint
+	Email string
+	Name  string
+
+	// AthleteID identifies the Strava athlete; Activities is its history, newest or oldest first
+	AthleteID  int64
+	Activities []strava.Activity
+}
+
+// Server is the stub provider server; it is safe for concurrent use
+type Server struct {
+	opts   Options
+	logger *logger.Logger
+
+	mu           sync.Mutex
+	spreadsheets map[string]*spreadsheet
+	nextCopy     int
+}
+
+// New creates a stub server; empty options fall back to the defaults and no activities
+func New(opts Options, log *logger.Logger) *Server {
+	if opts.Email == "" {
+		opts.Email = DefaultEmail
+	}
+	if opts.Name == "" {
+		opts.Name = DefaultName
+	}
+	if opts.AthleteID == 0 {
+		opts.AthleteID = DefaultAthleteID
+	}
+
+	return &Server{
+		opts:         opts,
+		logger:       log.WithContext("component", "devstub"),
+		spreadsheets: make(map[string]*spreadsheet),
+	}
+}
+
+// Handler returns the routes of every stubbed provider
+func (s *Server) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(s.logRequests)
+
+	// Strava (STRAVA_OAUTH_BASE_URL and STRAVA_API_BASE_URL)
+	r.Get(config.StubStravaOAuthPath+"/authorize", s.authorize("read,activity:read_all"))
+	r.Post(config.StubStravaOAuthPath+"/token", s.stravaToken)
+	r.Post(config.StubStravaOAuthPath+"/deauthorize", s.accept)
+	r.Get(config.StubStravaAPIPath+"/athlete", s.stravaAthlete)
+	r.Get(config.StubStravaAPIPath+"/athlete/activities", s.stravaActivities)
+	r.Get(config.StubStravaAPIPath+"/activities/{id}", s.stravaActivity)
+	r.Get(config.StubStravaAPIPath+"/athletes/{id}/stats", s.stravaStats)
+	r.Get(config.StubStravaAPIPath+"/gear/{id}", s.stravaGear)
+
+	// Google sign-in (GOOGLE_AUTH_URL, GOOGLE_TOKEN_URL, GOOGLE_REVOKE_URL, GOOGLE_OAUTH2_API_BASE_URL)
+	r.Get(config.StubGoogleAuthPath, s.authorize(googleScopes))
+	r.Post(config.StubGoogleTokenPath, s.googleToken)
+	r.Post(config.StubGoogleRevokePath, s.accept)
+	r.Get(config.StubGoogleOAuth2APIPath+"/v2/userinfo", s.googleUserInfo)
+	r.Get(config.StubGoogleOAuth2APIPath+"/v3/tokeninfo", s.googleTokenInfo)
+
+	// Google Sheets and Drive (GOOGLE_SHEETS_BASE_URL, GOOGLE_DRIVE_BASE_URL)
+	r.Post(config.StubGoogleSheetsPath+"v4/spreadsheets", s.createSpreadsheet)
+	r.HandleFunc(config.StubGoogleSheetsPath+"v4/spreadsheets/*", s.sheets)
+	r.Post(config.StubGoogleDrivePath+"files/{id}/copy", s.copyFile)
+
+	return r
+}
+
+// Rows returns the rows of a spreadsheet tab from row 1 to its last written row
+func (s *Server) Rows(spreadsheetID, title string) [][]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spreadsheet(spreadsheetID).read(title, 1, 0)
+}
+
+// authorize answers an OAuth consent screen by redirecting straight back with a code
+func (s *Server) authorize(scope string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		redirectURI := r.URL.Query().Get("redirect_uri")
+		if redirectURI == "" {
+			http.Error(w, "redirect_uri is required", http.StatusBadRequest)
+			return
+		}
+		target, err := addQuery(redirectURI, map[string]string{
+			"code":  "devstub-code",
+			"state": r.URL.Query().Get("state"),
+			"scope": scope,
+		})
+		if err != nil {
+			http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+	}
+}
+
+// accept answers revocation endpoints, which only need to succeed
+func (s *Server) accept(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{})
+}
+
+// logRequests logs every stubbed call at debug level
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		s.logger.Debug("Stub provider request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"duration_ms", time.Since(start).Milliseconds())
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
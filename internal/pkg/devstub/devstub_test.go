@@ -0,0 +1,191 @@
+package devstub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func newTestServer(t *testing.T, days int) (*Server, *httptest.Server) {
+	t.Helper()
+	stub := New(Options{Activities: SeedActivities(time.Now(), days)}, logger.New("test"))
+	srv := httptest.NewServer(stub.Handler())
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func TestStubServesProviderClients(t *testing.T) {
+	stub, srv := newTestServer(t, 14)
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	stravaClient := strava.NewClient(1, "refresh-token", logger.New("test"), strava.WithEndpoints(strava.Endpoints{
+		APIBaseURL:   srv.URL + config.StubStravaAPIPath,
+		OAuthBaseURL: srv.URL + config.StubStravaOAuthPath,
+	}), strava.WithInitialToken("access-token", expiry))
+
+	activities, err := stravaClient.GetActivities(ctx, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("GetActivities() failed: %v", err)
+	}
+	// One rest day a week, so a 7 day window holds 5 or 6 workouts depending on the weekday
+	if len(activities) < 5 || len(activities) > 6 {
+		t.Fatalf("Expected the last week of seeded activities, got %d", len(activities))
+	}
+
+	gear, err := stravaClient.GetGear(ctx, SeedShoesID)
+	if err != nil {
+		t.Fatalf("GetGear() failed: %v", err)
+	}
+	if gear.Name == "" || gear.Distance <= 0 {
+		t.Errorf("Expected named shoes with the seeded run distance, got %+v", gear)
+	}
+
+	sheetsClient := google.NewSheetsClient(1, "refresh-token", logger.New("test"), google.WithEndpoints(google.Endpoints{
+		TokenURL:         srv.URL + config.StubGoogleTokenPath,
+		OAuth2APIBaseURL: srv.URL + config.StubGoogleOAuth2APIPath,
+		SheetsBaseURL:    srv.URL + config.StubGoogleSheetsPath,
+		DriveBaseURL:     srv.URL + config.StubGoogleDrivePath,
+	}), google.WithInitialToken("access-token", expiry))
+
+	if err := sheetsClient.ValidateAccess(ctx, "dev-sheet"); err != nil {
+		t.Fatalf("ValidateAccess() failed: %v", err)
+	}
+	if _, err := sheetsClient.EnsureActivityHeader(ctx, "dev-sheet"); err != nil {
+		t.Fatalf("EnsureActivityHeader() failed: %v", err)
+	}
+	windowStart := time.Now().AddDate(0, 0, -7)
+	result, err := sheetsClient.SyncActivities(ctx, "dev-sheet", activities, windowStart)
+	if err != nil {
+		t.Fatalf("SyncActivities() failed: %v", err)
+	}
+	if result.Appended != len(activities) {
+		t.Errorf("Expected %d appended rows, got %+v", len(activities), result)
+	}
+	if rows := stub.Rows("dev-sheet", "Sheet1"); len(rows) != len(activities)+1 {
+		t.Fatalf("Expected a header and %d activity rows, got %d rows", len(activities), len(rows))
+	}
+
+	// A second sync of the same activities leaves the sheet as it is
+	result, err = sheetsClient.SyncActivities(ctx, "dev-sheet", activities, windowStart)
+	if err != nil {
+		t.Fatalf("Second SyncActivities() failed: %v", err)
+	}
+	if result.Appended != 0 || result.Unchanged != len(activities) {
+		t.Errorf("Expected every row unchanged on re-sync, got %+v", result)
+	}
+
+	scopes, err := sheetsClient.GrantedScopes(ctx)
+	if err != nil || !strings.Contains(strings.Join(scopes, " "), "spreadsheets") {
+		t.Errorf("Expected the Sheets scope to be granted, got %v (%v)", scopes, err)
+	}
+}
+
+func TestStubUndoesSyncWrites(t *testing.T) {
+	stub, srv := newTestServer(t, 14)
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	activities := stub.opts.Activities
+	if len(activities) < 4 {
+		t.Fatalf("Expected at least 4 seeded activities, got %d", len(activities))
+	}
+	sheetsClient := google.NewSheetsClient(1, "refresh-token", logger.New("test"), google.WithEndpoints(google.Endpoints{
+		TokenURL:      srv.URL + config.StubGoogleTokenPath,
+		SheetsBaseURL: srv.URL + config.StubGoogleSheetsPath,
+	}), google.WithInitialToken("access-token", expiry))
+	if _, err := sheetsClient.EnsureActivityHeader(ctx, "dev-sheet"); err != nil {
+		t.Fatalf("EnsureActivityHeader() failed: %v", err)
+	}
+	if _, err := sheetsClient.SyncActivities(ctx, "dev-sheet", activities[:len(activities)-2], time.Time{}); err != nil {
+		t.Fatalf("SyncActivities() failed: %v", err)
+	}
+	before := stub.Rows("dev-sheet", "Sheet1")
+
+	// The second sync appends the last two activities and flags the first as deleted
+	result, err := sheetsClient.SyncActivities(ctx, "dev-sheet", activities[1:], time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("Second SyncActivities() failed: %v", err)
+	}
+	if result.Appended != 2 || len(result.DeletedActivityIDs) != 1 || result.Writes == nil {
+		t.Fatalf("Expected 2 appended rows, 1 flagged row and recorded writes, got %+v", result)
+	}
+
+	undo, err := sheetsClient.UndoWrites(ctx, result.Writes)
+	if err != nil {
+		t.Fatalf("UndoWrites() failed: %v", err)
+	}
+	if undo.RowsDeleted != 2 || undo.FlagsCleared != 1 || undo.RowsMissing != 0 {
+		t.Errorf("Expected 2 rows deleted and 1 flag cleared, got %+v", undo)
+	}
+	after := stub.Rows("dev-sheet", "Sheet1")
+	if len(after) != len(before) {
+		t.Fatalf("Expected the sheet back at %d rows, got %d", len(before), len(after))
+	}
+	for i := range before {
+		for col := range before[i] {
+			if fmt.Sprint(after[i][col]) != fmt.Sprint(before[i][col]) {
+				t.Errorf("Row %d differs after undo: %v, want %v", i+1, after[i], before[i])
+				break
+			}
+		}
+	}
+}
+
+func TestStubAuthorizeRedirectsBack(t *testing.T) {
+	_, srv := newTestServer(t, 0)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(srv.URL + config.StubGoogleAuthPath + "?redirect_uri=" +
+		url.QueryEscape("http://localhost:8080/auth/google/callback") + "&state=abc")
+	if err != nil {
+		t.Fatalf("Authorize request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("Expected a redirect, got %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid redirect: %v", err)
+	}
+	if location.Path != "/auth/google/callback" || location.Query().Get("state") != "abc" || location.Query().Get("code") == "" {
+		t.Errorf("Expected a redirect to the callback with a code and the state, got %s", location)
+	}
+
+	resp, err = http.PostForm(srv.URL+config.StubStravaOAuthPath+"/token", url.Values{"grant_type": {"password"}})
+	if err != nil {
+		t.Fatalf("Token request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an unsupported grant to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestSeedActivities(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	activities := SeedActivities(now, 28)
+
+	// Four weeks with one rest day each
+	if len(activities) != 24 {
+		t.Fatalf("Expected 24 activities, got %d", len(activities))
+	}
+	if !activities[0].StartDate.After(activities[1].StartDate) {
+		t.Error("Expected the newest activity first")
+	}
+	again := SeedActivities(now.Add(time.Hour), 28)
+	if again[0].ID != activities[0].ID || again[0].Name != activities[0].Name {
+		t.Errorf("Expected the same history within a day, got %+v and %+v", again[0], activities[0])
+	}
+}
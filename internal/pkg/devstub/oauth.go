@@ -0,0 +1,103 @@
+package devstub
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// googleScopes are the scopes every stub Google token is granted
+const googleScopes = "openid profile email https://www.googleapis.com/auth/spreadsheets https://www.googleapis.com/auth/drive.file"
+
+// tokenLifetime is the lifetime of stub access tokens, matching the providers' one hour
+const tokenLifetime = time.Hour
+
+// Stub tokens; any token is accepted, so these only make stub traffic easy to spot. cmd/seed
+// stores them for its demo user.
+const (
+	GoogleAccessToken  = "devstub-google-access"
+	GoogleRefreshToken = "devstub-google-refresh"
+	StravaAccessToken  = "devstub-strava-access"
+	StravaRefreshToken = "devstub-strava-refresh"
+)
+
+// googleToken exchanges an authorization code or refresh token for a new access token
+func (s *Server) googleToken(w http.ResponseWriter, r *http.Request) {
+	if !validGrant(r) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  GoogleAccessToken,
+		"refresh_token": GoogleRefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(tokenLifetime.Seconds()),
+		"scope":         googleScopes,
+	})
+}
+
+// googleUserInfo returns the stub Google account
+func (s *Server) googleUserInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":             DefaultGoogleID,
+		"email":          s.opts.Email,
+		"verified_email": true,
+		"name":           s.opts.Name,
+		"locale":         "en",
+	})
+}
+
+// googleTokenInfo reports the scopes of any access token
+func (s *Server) googleTokenInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"scope":      googleScopes,
+		"email":      s.opts.Email,
+		"expires_in": int(tokenLifetime.Seconds()),
+	})
+}
+
+// stravaToken exchanges an authorization code or refresh token; like Strava, the response
+// includes the athlete
+func (s *Server) stravaToken(w http.ResponseWriter, r *http.Request) {
+	if !validGrant(r) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Bad Request"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token_type":    "Bearer",
+		"access_token":  StravaAccessToken,
+		"refresh_token": StravaRefreshToken,
+		"expires_in":    int(tokenLifetime.Seconds()),
+		"expires_at":    time.Now().Add(tokenLifetime).Unix(),
+		"athlete":       s.athlete(),
+	})
+}
+
+// validGrant reports whether the token request uses a grant the stub supports
+func validGrant(r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code", "refresh_token":
+		return true
+	default:
+		return false
+	}
+}
+
+// addQuery returns rawURL with the non-empty params added to its query
+func addQuery(rawURL string, params map[string]string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,81 @@
+package devstub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// seedWorkout is one entry of the weekly training pattern the seeded history repeats
+type seedWorkout struct {
+	name      string
+	kind      string
+	distance  float64 // meters
+	pace      float64 // seconds per kilometer
+	elevation float64 // meters
+	heartrate float64
+	gear      string
+}
+
+// Stub gear IDs; runs are recorded with the shoes and rides with the bike
+const (
+	SeedShoesID = "g1001"
+	SeedBikeID  = "b1001"
+)
+
+// seedGear names the stub gear
+var seedGear = map[string]string{
+	SeedShoesID: "Dev Trainers",
+	SeedBikeID:  "Dev Road Bike",
+}
+
+// seedWeek is a runner's week with one ride; a missing day is a rest day
+var seedWeek = []*seedWorkout{
+	{name: "Easy Run", kind: "Run", distance: 8000, pace: 340, elevation: 45, heartrate: 138, gear: SeedShoesID},
+	{name: "Track Intervals", kind: "Run", distance: 10000, pace: 290, elevation: 12, heartrate: 162, gear: SeedShoesID},
+	nil,
+	{name: "Tempo Run", kind: "Run", distance: 12000, pace: 275, elevation: 60, heartrate: 158, gear: SeedShoesID},
+	{name: "Recovery Ride", kind: "Ride", distance: 30000, pace: 120, elevation: 210, heartrate: 121, gear: SeedBikeID},
+	{name: "Long Run", kind: "Run", distance: 24000, pace: 330, elevation: 180, heartrate: 146, gear: SeedShoesID},
+	{name: "Shakeout Run", kind: "Run", distance: 5000, pace: 360, elevation: 20, heartrate: 130, gear: SeedShoesID},
+}
+
+// SeedActivities returns a deterministic training history covering the given number of days
+// before now, newest first. Workouts start at 07:00 UTC and IDs encode the date, so the same
+// history is produced for every call on the same day.
+func SeedActivities(now time.Time, days int) []strava.Activity {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	var activities []strava.Activity
+	for day := 1; day <= days; day++ {
+		date := today.AddDate(0, 0, -day)
+		workout := seedWeek[int(date.Weekday())%len(seedWeek)]
+		if workout == nil {
+			continue
+		}
+
+		start := date.Add(7 * time.Hour)
+		moving := int(workout.distance / 1000 * workout.pace)
+		activities = append(activities, strava.Activity{
+			ID:                 date.Unix() / 86400 * 10,
+			Name:               fmt.Sprintf("%s %s", date.Weekday(), workout.name),
+			Type:               workout.kind,
+			SportType:          workout.kind,
+			Distance:           workout.distance,
+			MovingTime:         moving,
+			ElapsedTime:        moving + 120,
+			TotalElevationGain: workout.elevation,
+			StartDate:          start,
+			StartDateLocal:     start,
+			Timezone:           "(GMT+00:00) Etc/UTC",
+			AverageSpeed:       workout.distance / float64(moving),
+			MaxSpeed:           workout.distance / float64(moving) * 1.3,
+			AverageHeartrate:   workout.heartrate,
+			MaxHeartrate:       workout.heartrate + 20,
+			Kudos:              day % 5,
+			GearID:             workout.gear,
+		})
+	}
+	return activities
+}
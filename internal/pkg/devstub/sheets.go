@@ -0,0 +1,382 @@
+package devstub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/config"
+)
+
+// defaultSheetTitle is the tab every new spreadsheet starts with, as in a new Google Spreadsheet
+const defaultSheetTitle = "Sheet1"
+
+// tab is one sheet of a spreadsheet; rows are keyed by 1-based row number
+type tab struct {
+	id    int64
+	title string
+	rows  map[int][]interface{}
+}
+
+// spreadsheet is an in-memory Google Spreadsheet
+type spreadsheet struct {
+	id    string
+	title string
+	tabs  []*tab
+}
+
+// spreadsheet returns the spreadsheet with the given ID, creating an empty one on first use.
+// Callers must hold s.mu.
+func (s *Server) spreadsheet(id string) *spreadsheet {
+	sheet, ok := s.spreadsheets[id]
+	if !ok {
+		sheet = &spreadsheet{id: id, title: "Dev Training Log"}
+		sheet.addTab(defaultSheetTitle)
+		s.spreadsheets[id] = sheet
+	}
+	return sheet
+}
+
+// addTab appends a tab and returns it
+func (sp *spreadsheet) addTab(title string) *tab {
+	t := &tab{id: int64(len(sp.tabs)), title: title, rows: make(map[int][]interface{})}
+	sp.tabs = append(sp.tabs, t)
+	return t
+}
+
+// tab returns the tab with the given title, or the first tab when title is empty
+func (sp *spreadsheet) tab(title string) *tab {
+	if title == "" {
+		return sp.tabs[0]
+	}
+	for _, t := range sp.tabs {
+		if t.title == title {
+			return t
+		}
+	}
+	return nil
+}
+
+// metadata is the spreadsheet resource returned by spreadsheets.get and spreadsheets.create
+func (sp *spreadsheet) metadata() map[string]interface{} {
+	sheets := make([]map[string]interface{}, len(sp.tabs))
+	for i, t := range sp.tabs {
+		sheets[i] = map[string]interface{}{"properties": map[string]interface{}{"sheetId": t.id, "title": t.title, "index": i}}
+	}
+	return map[string]interface{}{
+		"spreadsheetId":  sp.id,
+		"spreadsheetUrl": "https://docs.google.com/spreadsheets/d/" + sp.id,
+		"properties":     map[string]interface{}{"title": sp.title},
+		"sheets":         sheets,
+	}
+}
+
+// read returns rows first..last of a tab (last 0 reads to the last written row). Ranges are
+// resolved by row only, so whole rows are returned; gaps are empty rows and, like the API,
+// trailing empty rows are dropped.
+func (sp *spreadsheet) read(title string, first, last int) [][]interface{} {
+	t := sp.tab(title)
+	if t == nil {
+		return nil
+	}
+	if last == 0 {
+		for row := range t.rows {
+			last = max(last, row)
+		}
+	}
+	var rows [][]interface{}
+	for row := first; row <= last; row++ {
+		rows = append(rows, t.rows[row])
+	}
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+// write stores values starting at the first row of the A1 range; null cells keep the stored value
+func (sp *spreadsheet) write(a1 string, values [][]interface{}) error {
+	title, first, _ := parseRange(a1)
+	t := sp.tab(title)
+	if t == nil {
+		return fmt.Errorf("unable to parse range: %s", a1)
+	}
+	for i, cells := range values {
+		row := append([]interface{}(nil), t.rows[first+i]...)
+		for col, cell := range cells {
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			if cell != nil {
+				row[col] = cell
+			}
+		}
+		t.rows[first+i] = row
+	}
+	return nil
+}
+
+// sortRows sorts rows [startRowIndex+1, endRowIndex] of the tab by the given columns, comparing
+// cell text, which orders ISO dates correctly
+func (t *tab) sortRows(startRowIndex, endRowIndex int, columns []int, descending []bool) {
+	var rows [][]interface{}
+	for row := startRowIndex + 1; row <= endRowIndex; row++ {
+		rows = append(rows, t.rows[row])
+	}
+	cell := func(row []interface{}, col int) string {
+		if col < len(row) {
+			return fmt.Sprint(row[col])
+		}
+		return ""
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, col := range columns {
+			a, b := cell(rows[i], col), cell(rows[j], col)
+			if a != b {
+				return (a < b) != descending[k]
+			}
+		}
+		return false
+	})
+	for i, row := range rows {
+		t.rows[startRowIndex+1+i] = row
+	}
+}
+
+// deleteRows removes rows [startIndex+1, endIndex] of the tab, shifting the rows below them up
+func (t *tab) deleteRows(startIndex, endIndex int) {
+	deleted := endIndex - startIndex
+	if deleted <= 0 {
+		return
+	}
+	last := 0
+	for row := range t.rows {
+		last = max(last, row)
+	}
+	for row := startIndex + 1; row <= last; row++ {
+		if moved, ok := t.rows[row+deleted]; ok {
+			t.rows[row] = moved
+		} else {
+			delete(t.rows, row)
+		}
+	}
+}
+
+// parseRange splits an A1 range such as Sheet1!A2:X, 'Weekly Summary'!A:A or A1:A1 into its tab
+// title (empty for the first tab) and row bounds; last is 0 when the range is open-ended
+func parseRange(a1 string) (title string, first, last int) {
+	if sheet, cells, ok := strings.Cut(a1, "!"); ok {
+		title, a1 = strings.Trim(sheet, "'"), cells
+	}
+	start, end, hasEnd := strings.Cut(a1, ":")
+	first = max(rowOf(start), 1)
+	if hasEnd {
+		last = rowOf(end)
+	} else {
+		last = first
+	}
+	return title, first, last
+}
+
+// rowOf returns the row number of a cell reference such as B12, or 0 for a column such as A
+func rowOf(cell string) int {
+	row, _ := strconv.Atoi(strings.TrimLeft(strings.ToUpper(cell), "ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	return row
+}
+
+// createSpreadsheet handles spreadsheets.create
+func (s *Server) createSpreadsheet(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Properties struct {
+			Title string `json:"title"`
+		} `json:"properties"`
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeSheetsError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextCopy++
+	sp := &spreadsheet{id: fmt.Sprintf("devstub-spreadsheet-%d", s.nextCopy), title: request.Properties.Title}
+	for _, sheet := range request.Sheets {
+		sp.addTab(sheet.Properties.Title)
+	}
+	if len(sp.tabs) == 0 {
+		sp.addTab(defaultSheetTitle)
+	}
+	s.spreadsheets[sp.id] = sp
+	writeJSON(w, http.StatusOK, sp.metadata())
+}
+
+// copyFile handles Drive files.copy; the copy is an empty spreadsheet, which the engine fills in
+// with the template header on its first sync
+func (s *Server) copyFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextCopy++
+	id := fmt.Sprintf("devstub-copy-%d-of-%s", s.nextCopy, chi.URLParam(r, "id"))
+	s.spreadsheet(id)
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "kind": "drive#file"})
+}
+
+// sheets handles the spreadsheet routes: spreadsheets.get, spreadsheets.batchUpdate (addSheet,
+// sortRange and deleteDimension), values.get, values.update and values.batchUpdate
+func (s *Server) sheets(w http.ResponseWriter, r *http.Request) {
+	// {id}, {id}:batchUpdate, {id}/values/{range} or {id}/values:batchUpdate
+	path := strings.TrimPrefix(r.URL.Path, config.StubGoogleSheetsPath+"v4/spreadsheets/")
+	id, rest, _ := strings.Cut(path, "/")
+	id, method, _ := strings.Cut(id, ":")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp := s.spreadsheet(id)
+
+	switch {
+	case rest == "" && method == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, sp.metadata())
+
+	case rest == "" && method == "batchUpdate" && r.Method == http.MethodPost:
+		s.batchUpdate(w, r, sp)
+
+	case rest == "values:batchUpdate" && r.Method == http.MethodPost:
+		var request struct {
+			Data []struct {
+				Range  string          `json:"range"`
+				Values [][]interface{} `json:"values"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeSheetsError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, data := range request.Data {
+			if err := sp.write(data.Range, data.Values); err != nil {
+				writeSheetsError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"spreadsheetId": id, "totalUpdatedRows": len(request.Data)})
+
+	case strings.HasPrefix(rest, "values/") && r.Method == http.MethodPut:
+		a1 := strings.TrimPrefix(rest, "values/")
+		var valueRange struct {
+			Values [][]interface{} `json:"values"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&valueRange); err != nil {
+			writeSheetsError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := sp.write(a1, valueRange.Values); err != nil {
+			writeSheetsError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"spreadsheetId": id, "updatedRange": a1, "updatedRows": len(valueRange.Values)})
+
+	case strings.HasPrefix(rest, "values/") && r.Method == http.MethodGet:
+		a1 := strings.TrimPrefix(rest, "values/")
+		title, first, last := parseRange(a1)
+		if sp.tab(title) == nil {
+			writeSheetsError(w, http.StatusBadRequest, "Unable to parse range: "+a1)
+			return
+		}
+		response := map[string]interface{}{"range": a1, "majorDimension": "ROWS"}
+		if rows := sp.read(title, first, last); len(rows) > 0 {
+			response["values"] = rows
+		}
+		writeJSON(w, http.StatusOK, response)
+
+	default:
+		writeSheetsError(w, http.StatusNotFound, "Requested entity was not found.")
+	}
+}
+
+// batchUpdate applies addSheet, sortRange and deleteDimension (rows) requests; other requests are
+// acknowledged and ignored
+func (s *Server) batchUpdate(w http.ResponseWriter, r *http.Request, sp *spreadsheet) {
+	var request struct {
+		Requests []struct {
+			AddSheet *struct {
+				Properties struct {
+					Title string `json:"title"`
+				} `json:"properties"`
+			} `json:"addSheet"`
+			SortRange *struct {
+				Range struct {
+					SheetID       int64 `json:"sheetId"`
+					StartRowIndex int   `json:"startRowIndex"`
+					EndRowIndex   int   `json:"endRowIndex"`
+				} `json:"range"`
+				SortSpecs []struct {
+					DimensionIndex int    `json:"dimensionIndex"`
+					SortOrder      string `json:"sortOrder"`
+				} `json:"sortSpecs"`
+			} `json:"sortRange"`
+			DeleteDimension *struct {
+				Range struct {
+					SheetID    int64  `json:"sheetId"`
+					Dimension  string `json:"dimension"`
+					StartIndex int    `json:"startIndex"`
+					EndIndex   int    `json:"endIndex"`
+				} `json:"range"`
+			} `json:"deleteDimension"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeSheetsError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	replies := make([]map[string]interface{}, 0, len(request.Requests))
+	for _, req := range request.Requests {
+		reply := map[string]interface{}{}
+		switch {
+		case req.AddSheet != nil:
+			if sp.tab(req.AddSheet.Properties.Title) != nil {
+				writeSheetsError(w, http.StatusBadRequest, fmt.Sprintf("A sheet with the name %q already exists.", req.AddSheet.Properties.Title))
+				return
+			}
+			t := sp.addTab(req.AddSheet.Properties.Title)
+			reply["addSheet"] = map[string]interface{}{"properties": map[string]interface{}{"sheetId": t.id, "title": t.title}}
+		case req.SortRange != nil:
+			if req.SortRange.Range.SheetID < 0 || int(req.SortRange.Range.SheetID) >= len(sp.tabs) {
+				writeSheetsError(w, http.StatusBadRequest, "No grid with id: "+strconv.FormatInt(req.SortRange.Range.SheetID, 10))
+				return
+			}
+			columns := make([]int, len(req.SortRange.SortSpecs))
+			descending := make([]bool, len(req.SortRange.SortSpecs))
+			for i, spec := range req.SortRange.SortSpecs {
+				columns[i], descending[i] = spec.DimensionIndex, spec.SortOrder == "DESCENDING"
+			}
+			sp.tabs[req.SortRange.Range.SheetID].sortRows(req.SortRange.Range.StartRowIndex, req.SortRange.Range.EndRowIndex, columns, descending)
+		case req.DeleteDimension != nil && req.DeleteDimension.Range.Dimension == "ROWS":
+			if req.DeleteDimension.Range.SheetID < 0 || int(req.DeleteDimension.Range.SheetID) >= len(sp.tabs) {
+				writeSheetsError(w, http.StatusBadRequest, "No grid with id: "+strconv.FormatInt(req.DeleteDimension.Range.SheetID, 10))
+				return
+			}
+			sp.tabs[req.DeleteDimension.Range.SheetID].deleteRows(req.DeleteDimension.Range.StartIndex, req.DeleteDimension.Range.EndIndex)
+		}
+		replies = append(replies, reply)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"spreadsheetId": sp.id, "replies": replies})
+}
+
+// writeSheetsError writes an error in the Google API error format
+func writeSheetsError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"code": status, "message": message},
+	})
+}
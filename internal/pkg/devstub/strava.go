@@ -0,0 +1,139 @@
+package devstub
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// stravaDefaultPageSize is Strava's page size when per_page is not given
+const stravaDefaultPageSize = 30
+
+// athlete is the stub Strava athlete
+func (s *Server) athlete() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        s.opts.AthleteID,
+		"firstname": "Dev",
+		"lastname":  "Athlete",
+		"city":      "Sofia",
+		"country":   "Bulgaria",
+	}
+}
+
+// stravaAthlete returns the authenticated athlete
+func (s *Server) stravaAthlete(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.athlete())
+}
+
+// stravaActivities lists the athlete's activities, filtered and paginated like Strava:
+// newest first, or oldest first when an after bound is given
+func (s *Server) stravaActivities(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	after, hasAfter := unixParam(query.Get("after"))
+	before, hasBefore := unixParam(query.Get("before"))
+	page := intParam(query.Get("page"), 1)
+	perPage := intParam(query.Get("per_page"), stravaDefaultPageSize)
+
+	activities := make([]strava.Activity, 0, len(s.opts.Activities))
+	for _, activity := range s.opts.Activities {
+		if hasAfter && !activity.StartDate.After(after) {
+			continue
+		}
+		if hasBefore && !activity.StartDate.Before(before) {
+			continue
+		}
+		activities = append(activities, activity)
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		if hasAfter {
+			return activities[i].StartDate.Before(activities[j].StartDate)
+		}
+		return activities[i].StartDate.After(activities[j].StartDate)
+	})
+
+	start := min((page-1)*perPage, len(activities))
+	end := min(start+perPage, len(activities))
+	writeJSON(w, http.StatusOK, activities[start:end])
+}
+
+// stravaActivity returns one activity by ID
+func (s *Server) stravaActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err == nil {
+		for _, activity := range s.opts.Activities {
+			if activity.ID == id {
+				writeJSON(w, http.StatusOK, activity)
+				return
+			}
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Record Not Found"})
+}
+
+// stravaStats totals the seeded activities; every period covers the whole history
+func (s *Server) stravaStats(w http.ResponseWriter, r *http.Request) {
+	totals := map[string]*strava.ActivityTotals{
+		strava.StatsSportRun:  {},
+		strava.StatsSportRide: {},
+		strava.StatsSportSwim: {},
+	}
+	for _, activity := range s.opts.Activities {
+		t, ok := totals[strava.StatsSport(activity.Type)]
+		if !ok {
+			continue
+		}
+		t.Count++
+		t.Distance += activity.Distance
+		t.MovingTime += activity.MovingTime
+		t.ElapsedTime += activity.ElapsedTime
+		t.ElevationGain += activity.TotalElevationGain
+	}
+
+	run, ride, swim := *totals[strava.StatsSportRun], *totals[strava.StatsSportRide], *totals[strava.StatsSportSwim]
+	writeJSON(w, http.StatusOK, strava.AthleteStats{
+		RecentRunTotals: run, RecentRideTotals: ride, RecentSwimTotals: swim,
+		YTDRunTotals: run, YTDRideTotals: ride, YTDSwimTotals: swim,
+		AllRunTotals: run, AllRideTotals: ride, AllSwimTotals: swim,
+	})
+}
+
+// stravaGear returns a piece of stub gear with the distance of the seeded activities recorded with it
+func (s *Server) stravaGear(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	name, ok := seedGear[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Record Not Found"})
+		return
+	}
+
+	gear := strava.Gear{ID: id, Name: name, BrandName: "Devstub"}
+	for _, activity := range s.opts.Activities {
+		if activity.GearID == id {
+			gear.Distance += activity.Distance
+		}
+	}
+	writeJSON(w, http.StatusOK, gear)
+}
+
+// unixParam parses an epoch seconds query parameter
+func unixParam(raw string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// intParam parses a positive integer query parameter, or returns def
+func intParam(raw string, def int) int {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
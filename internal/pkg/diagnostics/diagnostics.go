@@ -0,0 +1,138 @@
+// Package diagnostics serves runtime profiles and dumps of a running service: the net/http/pprof
+// profiles, goroutine and heap dumps, runtime statistics and build information. The endpoints
+// are unauthenticated and meant for a localhost or internal port only.
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/outbound"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/redisclient"
+)
+
+// BuildInfo describes the binary serving the diagnostics
+type BuildInfo struct {
+	Service   string            `json:"service"`
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Module    string            `json:"module,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+}
+
+// RuntimeStats summarises the memory and scheduler state of the process
+type RuntimeStats struct {
+	Goroutines   int       `json:"goroutines"`
+	NumCPU       int       `json:"num_cpu"`
+	GOMAXPROCS   int       `json:"gomaxprocs"`
+	HeapAlloc    uint64    `json:"heap_alloc_bytes"`
+	HeapInuse    uint64    `json:"heap_inuse_bytes"`
+	HeapObjects  uint64    `json:"heap_objects"`
+	Sys          uint64    `json:"sys_bytes"`
+	TotalAlloc   uint64    `json:"total_alloc_bytes"`
+	NumGC        uint32    `json:"num_gc"`
+	LastGC       time.Time `json:"last_gc,omitempty"`
+	PauseTotalNs uint64    `json:"gc_pause_total_ns"`
+	Uptime       string    `json:"uptime"`
+}
+
+// NewHandler returns the diagnostics endpoints of service:
+//
+//	/debug/pprof/            pprof index and profiles (profile, trace, heap, allocs, ...)
+//	/debug/dump/goroutines   stacks of all goroutines
+//	/debug/dump/heap         heap profile taken after a garbage collection
+//	/debug/runtime           memory, GC and goroutine statistics
+//	/debug/buildinfo         Go version, module versions and VCS settings
+//	/debug/outbound          request, status, retry and latency counters of calls to Strava and Google
+//	/debug/redis             command, error and latency counters and pool statistics of Redis clients
+func NewHandler(service string) http.Handler {
+	startedAt := time.Now()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/dump/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	mux.HandleFunc("/debug/dump/heap", func(w http.ResponseWriter, r *http.Request) {
+		// Collect first so the profile reflects live memory rather than garbage awaiting collection
+		runtime.GC()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-heap-%s.pprof"`, service, time.Now().UTC().Format("20060102T150405Z")))
+		_ = rpprof.Lookup("heap").WriteTo(w, 0)
+	})
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ReadRuntimeStats(startedAt))
+	})
+	mux.HandleFunc("/debug/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ReadBuildInfo(service, startedAt))
+	})
+	mux.HandleFunc("/debug/outbound", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, outbound.Snapshot())
+	})
+	mux.HandleFunc("/debug/redis", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, redisclient.Snapshot())
+	})
+	return mux
+}
+
+// ReadRuntimeStats reads the current runtime statistics; startedAt is when the process started
+func ReadRuntimeStats(startedAt time.Time) RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		TotalAlloc:   mem.TotalAlloc,
+		NumGC:        mem.NumGC,
+		PauseTotalNs: mem.PauseTotalNs,
+		Uptime:       time.Since(startedAt).Round(time.Second).String(),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC)).UTC()
+	}
+	return stats
+}
+
+// ReadBuildInfo describes the running binary; the module fields are empty when it was built
+// without module support
+func ReadBuildInfo(service string, startedAt time.Time) BuildInfo {
+	info := BuildInfo{Service: service, GoVersion: runtime.Version(), StartedAt: startedAt.UTC()}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = build.Path
+	info.Module = build.Main.Path
+	info.Version = build.Main.Version
+	info.Settings = make(map[string]string, len(build.Settings))
+	for _, setting := range build.Settings {
+		info.Settings[setting.Key] = setting.Value
+	}
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
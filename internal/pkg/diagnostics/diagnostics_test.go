@@ -0,0 +1,53 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s returned %d: %s", target, rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+func TestHandler_Endpoints(t *testing.T) {
+	handler := NewHandler("automation-engine")
+
+	if body := get(t, handler, "/debug/pprof/").Body.String(); !strings.Contains(body, "goroutine") || !strings.Contains(body, "heap") {
+		t.Errorf("Expected the pprof index to list the profiles, got %q", body)
+	}
+
+	if body := get(t, handler, "/debug/dump/goroutines").Body.String(); !strings.Contains(body, "goroutine ") || !strings.Contains(body, "TestHandler_Endpoints") {
+		t.Errorf("Expected a full goroutine dump, got %q", body)
+	}
+
+	heap := get(t, handler, "/debug/dump/heap")
+	if heap.Body.Len() == 0 || !strings.Contains(heap.Header().Get("Content-Disposition"), "automation-engine-heap-") {
+		t.Errorf("Expected a heap profile download, got %d bytes with %q", heap.Body.Len(), heap.Header().Get("Content-Disposition"))
+	}
+
+	var stats RuntimeStats
+	if err := json.Unmarshal(get(t, handler, "/debug/runtime").Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to parse runtime stats: %v", err)
+	}
+	if stats.Goroutines == 0 || stats.HeapAlloc == 0 || stats.NumGC == 0 {
+		t.Errorf("Expected live runtime statistics after the heap dump's collection, got %+v", stats)
+	}
+
+	var info BuildInfo
+	if err := json.Unmarshal(get(t, handler, "/debug/buildinfo").Body.Bytes(), &info); err != nil {
+		t.Fatalf("Failed to parse build info: %v", err)
+	}
+	if info.Service != "automation-engine" || info.GoVersion != runtime.Version() {
+		t.Errorf("Expected the service and Go version, got %+v", info)
+	}
+}
@@ -0,0 +1,109 @@
+// Package events is the bus services use to tell each other what happened, such as a finished
+// sync or a user whose credentials were rejected, instead of one service inferring it from
+// another's logs or database rows. Events are typed: each payload struct names its Type, and
+// subscribers decode the envelope back into it.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Type names a kind of event; each type is published on its own channel
+type Type string
+
+const (
+	TypeUserConnectedStrava Type = "user.connected_strava"
+	TypeSyncCompleted       Type = "sync.completed"
+	TypeSyncFailed          Type = "sync.failed"
+	TypeReauthRequired      Type = "user.reauth_required"
+	TypeUserDeleted         Type = "user.deleted"
+)
+
+// Payload is the body of an event
+type Payload interface {
+	EventType() Type
+}
+
+// UserConnectedStrava is published by the backend API when a user connects a Strava athlete
+type UserConnectedStrava struct {
+	UserID    int   `json:"user_id"`
+	AthleteID int64 `json:"athlete_id"`
+}
+
+// SyncCompleted is published by the automation engine when a job finished successfully
+type SyncCompleted struct {
+	UserID          int    `json:"user_id"`
+	TraceID         string `json:"trace_id,omitempty"`
+	DryRun          bool   `json:"dry_run,omitempty"`
+	ActivitiesCount int    `json:"activities_count"`
+}
+
+// SyncFailed is published by the automation engine when a job failed
+type SyncFailed struct {
+	UserID    int    `json:"user_id"`
+	TraceID   string `json:"trace_id,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+	ErrorType string `json:"error_type"`
+	Error     string `json:"error"`
+}
+
+// ReauthRequired is published by the automation engine when a provider rejected a user's
+// credentials, so the user must connect the provider again
+type ReauthRequired struct {
+	UserID   int    `json:"user_id"`
+	Provider string `json:"provider"` // "strava" or "google"
+}
+
+// UserDeleted is published by the backend API when a user is deleted
+type UserDeleted struct {
+	UserID int `json:"user_id"`
+	// MergedInto is the user that took over the account's history when it was merged away
+	MergedInto int `json:"merged_into,omitempty"`
+}
+
+func (UserConnectedStrava) EventType() Type { return TypeUserConnectedStrava }
+func (SyncCompleted) EventType() Type       { return TypeSyncCompleted }
+func (SyncFailed) EventType() Type          { return TypeSyncFailed }
+func (ReauthRequired) EventType() Type      { return TypeReauthRequired }
+func (UserDeleted) EventType() Type         { return TypeUserDeleted }
+
+// Event is the envelope a payload is published in
+type Event struct {
+	ID      string          `json:"id"`
+	Type    Type            `json:"type"`
+	Source  string          `json:"source"` // The publishing service, e.g. "automation-engine"
+	At      time.Time       `json:"at"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Decode unmarshals the event's payload into v, which must be of the event's type
+func (e Event) Decode(v Payload) error {
+	if v.EventType() != e.Type {
+		return fmt.Errorf("cannot decode %s event into %s", e.Type, v.EventType())
+	}
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Publisher publishes events. Delivery is at most once: subscribers that are not connected
+// miss the event, so consumers keep a fallback such as polling the database.
+type Publisher interface {
+	Publish(ctx context.Context, payload Payload) error
+}
+
+// Subscriber delivers the events of the given types until ctx is done, when the returned
+// channel is closed
+type Subscriber interface {
+	Subscribe(ctx context.Context, types ...Type) (<-chan Event, error)
+}
+
+// Bus publishes and subscribes to events
+type Bus interface {
+	Publisher
+	Subscriber
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// channelPrefix prefixes the pub/sub channel of each event type
+const channelPrefix = "academy-sync:events:"
+
+// subscriberBuffer is how many events a subscriber may fall behind before delivery blocks
+const subscriberBuffer = 64
+
+// RedisBus is a Bus on Redis pub/sub
+type RedisBus struct {
+	redis  *redis.Client
+	source string
+	logger *logger.Logger
+}
+
+// NewRedisBus creates a bus on rdb, which stays owned by the caller. source names the service
+// in the events it publishes.
+func NewRedisBus(rdb *redis.Client, source string, logger *logger.Logger) *RedisBus {
+	return &RedisBus{
+		redis:  rdb,
+		source: source,
+		logger: logger.WithContext("component", "event_bus"),
+	}
+}
+
+// Publish publishes payload on its type's channel
+func (b *RedisBus) Publish(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", payload.EventType(), err)
+	}
+	event, err := json.Marshal(Event{
+		ID:      uuid.NewString(),
+		Type:    payload.EventType(),
+		Source:  b.source,
+		At:      time.Now(),
+		Payload: body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", payload.EventType(), err)
+	}
+
+	if err := b.redis.Publish(ctx, channel(payload.EventType()), event).Err(); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", payload.EventType(), err)
+	}
+	return nil
+}
+
+// Subscribe delivers the events of types until ctx is done. Events published before Subscribe
+// returns are not delivered.
+func (b *RedisBus) Subscribe(ctx context.Context, types ...Type) (<-chan Event, error) {
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no event types to subscribe to")
+	}
+	channels := make([]string, len(types))
+	for i, t := range types {
+		channels[i] = channel(t)
+	}
+
+	pubsub := b.redis.Subscribe(ctx, channels...)
+
+	// Wait for every subscription to be confirmed, so no event published after we return is lost
+	for range channels {
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+			return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+		}
+	}
+
+	events := make(chan Event, subscriberBuffer)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event Event
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					b.logger.Warn("Dropping malformed event",
+						"error", err,
+						"channel", message.Channel)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func channel(t Type) string {
+	return channelPrefix + string(t)
+}
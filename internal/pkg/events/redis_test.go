@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func newTestBus(t *testing.T, source string) *RedisBus {
+	t.Helper()
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewRedisBus(rdb, source, logger.New("test"))
+}
+
+func receive(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return Event{}
+	}
+}
+
+func TestRedisBus_DeliversSubscribedTypes(t *testing.T) {
+	bus := newTestBus(t, "automation-engine")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := bus.Subscribe(ctx, TypeSyncCompleted, TypeSyncFailed)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	if err := bus.Publish(ctx, ReauthRequired{UserID: 7, Provider: "strava"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := bus.Publish(ctx, SyncFailed{UserID: 7, TraceID: "trace-1", ErrorType: "STRAVA_REAUTH_REQUIRED"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	event := receive(t, events)
+	if event.Type != TypeSyncFailed || event.Source != "automation-engine" || event.ID == "" || event.At.IsZero() {
+		t.Fatalf("Expected the sync failure in its envelope, got %+v", event)
+	}
+	var failed SyncFailed
+	if err := event.Decode(&failed); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if failed.UserID != 7 || failed.TraceID != "trace-1" || failed.ErrorType != "STRAVA_REAUTH_REQUIRED" {
+		t.Errorf("Unexpected payload: %+v", failed)
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("Expected no events of other types, got %+v", event)
+	default:
+	}
+}
+
+func TestRedisBus_ClosesWithContext(t *testing.T) {
+	bus := newTestBus(t, "backend-api")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := bus.Subscribe(ctx, TypeUserDeleted)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no events after the context ended")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the channel to close with the context")
+	}
+}
+
+func TestEvent_DecodeRejectsOtherType(t *testing.T) {
+	event := Event{Type: TypeUserDeleted, Payload: []byte(`{"user_id":3}`)}
+
+	var connected UserConnectedStrava
+	if err := event.Decode(&connected); err == nil {
+		t.Error("Expected an error decoding into another event type")
+	}
+	var deleted UserDeleted
+	if err := event.Decode(&deleted); err != nil || deleted.UserID != 3 {
+		t.Errorf("Expected user 3, got %+v (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package failures is the catalog of classified failures: for each error type reported by the
+// API or recorded on a run, the action that fixes it and where the web app lets the user take it.
+package failures
+
+// Remediation names an action that fixes a failure; the web app renders a "Fix it" button for it
+type Remediation string
+
+const (
+	ReconnectStrava  Remediation = "reconnect_strava"
+	ReconnectGoogle  Remediation = "reconnect_google"
+	ShareSpreadsheet Remediation = "share_spreadsheet"
+	// ChooseSpreadsheet asks for a different spreadsheet, e.g. one following a supported template
+	ChooseSpreadsheet Remediation = "choose_spreadsheet"
+	// RetryLater means nothing needs fixing; the user should try again after the stated time
+	RetryLater Remediation = "retry_later"
+)
+
+// Help tells the user how to fix a failure. HelpURL is a path in the web app and is empty when
+// there is nothing to open.
+type Help struct {
+	HelpURL     string
+	Remediation Remediation
+}
+
+// Paths in the web app where each connection is managed
+const (
+	stravaSettingsURL      = "/dashboard#strava"
+	googleSettingsURL      = "/dashboard#google"
+	spreadsheetSettingsURL = "/dashboard#spreadsheet-url"
+)
+
+// catalog maps error types to their fix. Only types with a single meaning belong here; generic
+// codes such as NOT_FOUND or INVALID_URL are used by several endpoints for different things.
+var catalog = map[string]Help{
+	// Reported by the API
+	"STRAVA_NOT_CONNECTED": {stravaSettingsURL, ReconnectStrava},
+	"GOOGLE_NOT_CONNECTED": {googleSettingsURL, ReconnectGoogle},
+	"PERMISSION_ERROR":     {spreadsheetSettingsURL, ShareSpreadsheet},
+	"SYNC_PAUSED":          {"", RetryLater},
+	"MAINTENANCE_MODE":     {"", RetryLater},
+
+	// Recorded on runs by the automation engine, and by the API for exports
+	"STRAVA_REAUTH_REQUIRED": {stravaSettingsURL, ReconnectStrava},
+	"GOOGLE_REAUTH_REQUIRED": {googleSettingsURL, ReconnectGoogle},
+	"SHEETS_ACCESS_ERROR":    {spreadsheetSettingsURL, ShareSpreadsheet},
+	"SHEETS_SCHEMA_ERROR":    {spreadsheetSettingsURL, ChooseSpreadsheet},
+	"STRAVA_UNAVAILABLE":     {"", RetryLater},
+	"GOOGLE_UNAVAILABLE":     {"", RetryLater},
+	"DAILY_BUDGET_EXCEEDED":  {"", RetryLater},
+	"BLACKOUT_WINDOW":        {"", RetryLater},
+	"USER_BUSY":              {"", RetryLater},
+	"JOB_TIMEOUT":            {"", RetryLater},
+	"JOB_CANCELLED":          {"", RetryLater},
+	"STEP_TIMEOUT":           {"", RetryLater},
+}
+
+// Lookup returns the help for errorType, and false for unclassified types
+func Lookup(errorType string) (Help, bool) {
+	help, ok := catalog[errorType]
+	return help, ok
+}
@@ -0,0 +1,21 @@
+package failures
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	help, ok := Lookup("STRAVA_REAUTH_REQUIRED")
+	if !ok || help.Remediation != ReconnectStrava || help.HelpURL != "/dashboard#strava" {
+		t.Errorf("Unexpected help for STRAVA_REAUTH_REQUIRED: %+v", help)
+	}
+
+	if help, ok := Lookup("BLACKOUT_WINDOW"); !ok || help.Remediation != RetryLater || help.HelpURL != "" {
+		t.Errorf("Expected a blackout to only ask for a retry: %+v", help)
+	}
+
+	// Generic codes mean different things on different endpoints and carry no help
+	for _, errorType := range []string{"NOT_FOUND", "INVALID_URL", "INTERNAL_ERROR", ""} {
+		if _, ok := Lookup(errorType); ok {
+			t.Errorf("Expected %q to be unclassified", errorType)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package format renders activity measurements (durations, paces, distances, speeds) the same
+// way across the sheet destinations, notification emails and API responses.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Units selects the measurement system
+type Units string
+
+const (
+	Metric   Units = "metric"
+	Imperial Units = "imperial"
+)
+
+const (
+	metersPerKilometer = 1000.0
+	metersPerMile      = 1609.344
+	metersPer100Yards  = 91.44
+	feetPerMeter       = 3.28084
+)
+
+// commaLocales write decimals with a comma ("5,20 km")
+var commaLocales = map[string]bool{
+	"de": true, "es": true, "fr": true, "it": true, "nl": true, "pl": true, "pt": true, "ru": true,
+}
+
+// Formatter renders measurements in one unit system and locale. The zero value formats metric
+// values with a decimal point, which is what the sheet templates write.
+type Formatter struct {
+	units        Units
+	decimalComma bool
+}
+
+// New returns a formatter for a locale tag such as "es" or "pt-BR" and a unit system;
+// unknown units fall back to metric
+func New(locale string, units Units) Formatter {
+	base, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-"), "-")
+	if units != Imperial {
+		units = Metric
+	}
+	return Formatter{units: units, decimalComma: commaLocales[base]}
+}
+
+// Default formats metric values with a decimal point
+var Default = Formatter{}
+
+// Duration formats seconds as HH:MM:SS, e.g. 3725 -> "01:02:05". Hours are not wrapped at a day.
+func Duration(seconds int) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// Clock formats a time.Duration as HH:MM:SS, truncated to whole seconds
+func Clock(d time.Duration) string {
+	return Duration(int(d / time.Second))
+}
+
+// Distance formats meters in kilometers or miles with two decimals, e.g. "10.55 km"
+func (f Formatter) Distance(meters float64) string {
+	if f.units == Imperial {
+		return f.decimal(meters/metersPerMile, 2) + " mi"
+	}
+	return f.decimal(meters/metersPerKilometer, 2) + " km"
+}
+
+// Elevation formats meters of climbing as whole meters or feet, e.g. "120 m"
+func (f Formatter) Elevation(meters float64) string {
+	if f.units == Imperial {
+		return fmt.Sprintf("%.0f ft", meters*feetPerMeter)
+	}
+	return fmt.Sprintf("%.0f m", meters)
+}
+
+// Pace formats the time per kilometer or mile as M:SS, e.g. "5:03 /km". It is empty when
+// either value is not positive.
+func (f Formatter) Pace(movingSeconds int, meters float64) string {
+	if f.units == Imperial {
+		return PacePer(movingSeconds, meters, metersPerMile, "/mi")
+	}
+	return PacePer(movingSeconds, meters, metersPerKilometer, "/km")
+}
+
+// SwimPace formats the time per 100 meters or 100 yards, e.g. "1:52 /100m"
+func (f Formatter) SwimPace(movingSeconds int, meters float64) string {
+	if f.units == Imperial {
+		return PacePer(movingSeconds, meters, metersPer100Yards, "/100yd")
+	}
+	return PacePer(movingSeconds, meters, 100, "/100m")
+}
+
+// Speed formats the average speed with one decimal, e.g. "28.4 km/h". It is empty when the
+// moving time is not positive.
+func (f Formatter) Speed(movingSeconds int, meters float64) string {
+	if movingSeconds <= 0 {
+		return ""
+	}
+	hours := float64(movingSeconds) / 3600
+	if f.units == Imperial {
+		return f.decimal(meters/metersPerMile/hours, 1) + " mph"
+	}
+	return f.decimal(meters/metersPerKilometer/hours, 1) + " km/h"
+}
+
+// HeartRate formats an average heart rate, e.g. "152 bpm"; it is empty when no rate was recorded
+func (f Formatter) HeartRate(bpm float64) string {
+	if bpm <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.0f bpm", bpm)
+}
+
+// PacePer formats the time per unitMeters as M:SS followed by suffix. It is empty when either
+// value is not positive.
+func PacePer(movingSeconds int, meters, unitMeters float64, suffix string) string {
+	if movingSeconds <= 0 || meters <= 0 {
+		return ""
+	}
+	pace := float64(movingSeconds) / (meters / unitMeters)
+	return fmt.Sprintf("%d:%02d %s", int(pace/60), int(pace)%60, suffix)
+}
+
+func (f Formatter) decimal(value float64, places int) string {
+	s := fmt.Sprintf("%.*f", places, value)
+	if f.decimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
@@ -0,0 +1,141 @@
+package format
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{0, "00:00:00"},
+		{59, "00:00:59"},
+		{3725, "01:02:05"},
+		{86399, "23:59:59"},
+		{90061, "25:01:01"}, // Not wrapped at a day
+		{-5, "00:00:00"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.seconds); got != tt.want {
+			t.Errorf("Duration(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+
+	if got := Clock(90*time.Minute + 1500*time.Millisecond); got != "01:30:01" {
+		t.Errorf("Clock truncates to whole seconds, got %q", got)
+	}
+}
+
+func TestPace(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       Formatter
+		seconds int
+		meters  float64
+		want    string
+	}{
+		{"five minute kilometers", Default, 1500, 5000, "5:00 /km"},
+		{"truncates seconds", Default, 6300, 21100, "4:58 /km"},
+		{"imperial", New("en", Imperial), 2400, 8046.72, "8:00 /mi"},
+		{"no distance", Default, 1500, 0, ""},
+		{"no time", Default, 0, 5000, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Pace(tt.seconds, tt.meters); got != tt.want {
+				t.Errorf("Pace = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_LocaleAndUnits(t *testing.T) {
+	tests := []struct {
+		locale string
+		units  Units
+		want   string
+	}{
+		{"en", Metric, "10.55 km"},
+		{"", "", "10.55 km"},
+		{"es", Metric, "10,55 km"},
+		{"pt_BR", Metric, "10,55 km"},
+		{"DE-at", Metric, "10,55 km"},
+		{"en-US", Imperial, "6.56 mi"},
+		{"fr", Imperial, "6,56 mi"},
+		{"en", "furlongs", "10.55 km"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.locale, tt.units).Distance(10550); got != tt.want {
+			t.Errorf("New(%q, %q).Distance = %q, want %q", tt.locale, tt.units, got, tt.want)
+		}
+	}
+}
+
+// TestGolden renders a fixed set of activities with every formatter so any change to the
+// output the sheets, emails and API responses show is reviewed as a diff of testdata/measurements.golden
+func TestGolden(t *testing.T) {
+	activities := []struct {
+		label      string
+		seconds    int
+		meters     float64
+		elevation  float64
+		heartRate  float64
+		swimLength bool
+	}{
+		{"easy run", 1843, 6012.4, 38.2, 141.6, false},
+		{"marathon", 12034, 42195, 212, 158.2, false},
+		{"long ride", 10812, 91234.5, 1204.7, 0, false},
+		{"pool swim", 2310, 2000, 0, 0, true},
+		{"treadmill without distance", 1800, 0, 0, 120, false},
+		{"manual entry without time", 0, 5000, 0, 0, false},
+	}
+	formatters := []struct {
+		name string
+		f    Formatter
+	}{
+		{"default", Default},
+		{"es metric", New("es", Metric)},
+		{"en imperial", New("en", Imperial)},
+	}
+
+	var b strings.Builder
+	for _, activity := range activities {
+		fmt.Fprintf(&b, "%s\n  duration: %s\n", activity.label, Duration(activity.seconds))
+		for _, formatter := range formatters {
+			f := formatter.f
+			pace := f.Pace(activity.seconds, activity.meters)
+			if activity.swimLength {
+				pace = f.SwimPace(activity.seconds, activity.meters)
+			}
+			fmt.Fprintf(&b, "  %-12s %q %q %q %q %q\n", formatter.name+":",
+				f.Distance(activity.meters),
+				pace,
+				f.Speed(activity.seconds, activity.meters),
+				f.Elevation(activity.elevation),
+				f.HeartRate(activity.heartRate))
+		}
+	}
+
+	path := filepath.Join("testdata", "measurements.golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run go test -update to create it): %v", err)
+	}
+	if got := b.String(); got != string(want) {
+		t.Errorf("Output differs from %s (run go test -update and review the diff):\n%s", path, got)
+	}
+}
@@ -0,0 +1,98 @@
+package google
+
+import (
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Production Google API endpoints; OAuth consent and token URLs default to google.Endpoint
+const (
+	DefaultOAuth2APIBaseURL = "https://www.googleapis.com/oauth2"
+	DefaultSheetsBaseURL    = "https://sheets.googleapis.com/"
+	DefaultDriveBaseURL     = "https://www.googleapis.com/drive/v3/"
+	DefaultRevokeURL        = "https://oauth2.googleapis.com/revoke"
+)
+
+// Endpoints are the Google URLs used for sign-in, token refresh and the Sheets and Drive APIs.
+// Staging environments point them at a mock server, corporate proxy or sandbox.
+type Endpoints struct {
+	AuthURL          string // OAuth consent screen
+	TokenURL         string // OAuth token exchange and refresh
+	RevokeURL        string // OAuth token revocation
+	OAuth2APIBaseURL string // Serves /v2/userinfo and /v3/tokeninfo
+	SheetsBaseURL    string
+	DriveBaseURL     string
+}
+
+// DefaultEndpoints returns the production Google endpoints
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		AuthURL:          google.Endpoint.AuthURL,
+		TokenURL:         google.Endpoint.TokenURL,
+		RevokeURL:        DefaultRevokeURL,
+		OAuth2APIBaseURL: DefaultOAuth2APIBaseURL,
+		SheetsBaseURL:    DefaultSheetsBaseURL,
+		DriveBaseURL:     DefaultDriveBaseURL,
+	}
+}
+
+// withDefaults fills empty URLs with the production endpoints
+func (e Endpoints) withDefaults() Endpoints {
+	defaults := DefaultEndpoints()
+	if e.AuthURL == "" {
+		e.AuthURL = defaults.AuthURL
+	}
+	if e.TokenURL == "" {
+		e.TokenURL = defaults.TokenURL
+	}
+	if e.RevokeURL == "" {
+		e.RevokeURL = defaults.RevokeURL
+	}
+	if e.OAuth2APIBaseURL == "" {
+		e.OAuth2APIBaseURL = defaults.OAuth2APIBaseURL
+	}
+	if e.SheetsBaseURL == "" {
+		e.SheetsBaseURL = defaults.SheetsBaseURL
+	}
+	if e.DriveBaseURL == "" {
+		e.DriveBaseURL = defaults.DriveBaseURL
+	}
+	return e
+}
+
+// OAuthEndpoint returns the authorization and token URLs
+func (e Endpoints) OAuthEndpoint() oauth2.Endpoint {
+	e = e.withDefaults()
+	return oauth2.Endpoint{
+		AuthURL:   e.AuthURL,
+		TokenURL:  e.TokenURL,
+		AuthStyle: google.Endpoint.AuthStyle,
+	}
+}
+
+// RevocationURL returns the endpoint that revokes OAuth tokens
+func (e Endpoints) RevocationURL() string {
+	return e.withDefaults().RevokeURL
+}
+
+// UserInfoURL returns the OpenID user info endpoint
+func (e Endpoints) UserInfoURL() string {
+	return strings.TrimRight(e.withDefaults().OAuth2APIBaseURL, "/") + "/v2/userinfo"
+}
+
+// TokenInfoURL returns the endpoint reporting the scopes of an access token
+func (e Endpoints) TokenInfoURL() string {
+	return strings.TrimRight(e.withDefaults().OAuth2APIBaseURL, "/") + "/v3/tokeninfo"
+}
+
+// SheetsEndpoint returns the Sheets API base path, with the trailing slash the client library expects
+func (e Endpoints) SheetsEndpoint() string {
+	return strings.TrimRight(e.withDefaults().SheetsBaseURL, "/") + "/"
+}
+
+// DriveEndpoint returns the Drive API base path, with the trailing slash the client library expects
+func (e Endpoints) DriveEndpoint() string {
+	return strings.TrimRight(e.withDefaults().DriveBaseURL, "/") + "/"
+}
@@ -0,0 +1,27 @@
+package google
+
+import "testing"
+
+func TestEndpoints(t *testing.T) {
+	mock := Endpoints{
+		TokenURL:         "http://localhost:9090/token",
+		OAuth2APIBaseURL: "http://localhost:9090/oauth2/",
+		SheetsBaseURL:    "http://localhost:9090/sheets",
+	}
+
+	if got := mock.OAuthEndpoint().TokenURL; got != "http://localhost:9090/token" {
+		t.Errorf("Expected the mock token URL, got %q", got)
+	}
+	if got := mock.OAuthEndpoint().AuthURL; got != DefaultEndpoints().AuthURL {
+		t.Errorf("Expected an unset URL to fall back to production, got %q", got)
+	}
+	if got := mock.TokenInfoURL(); got != "http://localhost:9090/oauth2/v3/tokeninfo" {
+		t.Errorf("Unexpected token info URL %q", got)
+	}
+	if got := mock.SheetsEndpoint(); got != "http://localhost:9090/sheets/" {
+		t.Errorf("Expected the Sheets endpoint to end with a slash, got %q", got)
+	}
+	if got := mock.DriveEndpoint(); got != DefaultDriveBaseURL {
+		t.Errorf("Expected the production Drive endpoint, got %q", got)
+	}
+}
@@ -3,7 +3,11 @@ package google
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
 )
 
 // ErrReauthRequired is returned when the refresh token is invalid and user re-authorization is needed
@@ -31,6 +35,11 @@ func (e *AuthError) Unwrap() error {
 	return e.Cause
 }
 
+// Permanent reports that retrying with the same credentials cannot succeed, so retry.DefaultClassifier aborts
+func (e *AuthError) Permanent() bool {
+	return true
+}
+
 // IsReauthRequired checks if an error indicates that user re-authorization is required
 func IsReauthRequired(err error) bool {
 	if err == nil {
@@ -56,12 +65,32 @@ func IsReauthRequired(err error) bool {
 		   strings.Contains(errStr, "refresh token is invalid")
 }
 
+// IsInsufficientScope reports whether Google refused a call because the access token was not
+// granted a scope the call needs, e.g. a user who signed in before the app asked for Drive access.
+// Signing in again with the missing scope fixes it.
+func IsInsufficientScope(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			return true
+		}
+	}
+	return strings.Contains(apiErr.Message, "insufficient authentication scopes") ||
+		strings.Contains(apiErr.Body, "ACCESS_TOKEN_SCOPE_INSUFFICIENT")
+}
+
 // APIError represents general Google API errors
 type APIError struct {
 	StatusCode int
 	Message    string
 	Type       string
 	Cause      error
+
+	// RetryDelay is the Retry-After of a rate-limited response; zero when none was given
+	RetryDelay time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -77,6 +106,11 @@ func (e *APIError) Unwrap() error {
 	return e.Cause
 }
 
+// RetryAfter returns how long to wait before retrying, read by retry.DefaultClassifier
+func (e *APIError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
 // NetworkError represents network-related errors during API calls
 type NetworkError struct {
 	Operation string
@@ -134,4 +168,29 @@ func (e *SheetsError) Error() string {
 
 func (e *SheetsError) Unwrap() error {
 	return e.Cause
+}
+
+// Permanent reports that the spreadsheet rejected the request itself (missing, not shared or
+// malformed), so retry.DefaultClassifier does not send it again
+func (e *SheetsError) Permanent() bool {
+	return true
+}
+
+// ChunkWriteError reports a sync that failed part-way through writing its rows. The chunks before
+// the failed one stay written; running the sync again finds their rows unchanged and resumes
+// with the failed chunk.
+type ChunkWriteError struct {
+	Chunk         int // 1-based number of the chunk that failed
+	ChunksWritten int
+	RowsWritten   int
+	Cause         error
+}
+
+func (e *ChunkWriteError) Error() string {
+	return fmt.Sprintf("google sheets write failed at chunk %d after %d chunks (%d rows) were written: %v",
+		e.Chunk, e.ChunksWritten, e.RowsWritten, e.Cause)
+}
+
+func (e *ChunkWriteError) Unwrap() error {
+	return e.Cause
 }
\ No newline at end of file
@@ -0,0 +1,157 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// Option configures a SheetsClient at construction; a client is not reconfigured afterwards
+type Option func(*SheetsClient)
+
+// RateLimiter paces API calls; Wait blocks until the next call may be made. *rate.Limiter from
+// golang.org/x/time/rate satisfies it.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithEndpoints points the client at alternate Google URLs, such as the devstub server or a
+// mock server in staging; empty URLs keep the production endpoints
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(c *SheetsClient) {
+		c.endpoints = endpoints.withDefaults()
+	}
+}
+
+// WithBaseURL points Sheets API requests at an alternate base URL, keeping the other endpoints
+func WithBaseURL(sheetsBaseURL string) Option {
+	return func(c *SheetsClient) {
+		c.endpoints.SheetsBaseURL = sheetsBaseURL
+		c.endpoints = c.endpoints.withDefaults()
+	}
+}
+
+// WithOAuthCredentials sets the application's OAuth client credentials used for token refresh
+func WithOAuthCredentials(clientID, clientSecret, redirectURL string) Option {
+	return func(c *SheetsClient) {
+		c.oauthConfig.ClientID = clientID
+		c.oauthConfig.ClientSecret = clientSecret
+		c.oauthConfig.RedirectURL = redirectURL
+	}
+}
+
+// WithInitialToken seeds the client with a stored access token, used until shortly before expiry
+// instead of refreshing on the first call
+func WithInitialToken(accessToken string, expiry time.Time) Option {
+	return func(c *SheetsClient) {
+		c.accessToken = accessToken
+		c.tokenExpiry = expiry
+	}
+}
+
+// WithHTTPClient makes API requests with the transport and timeout of httpClient, e.g. to count
+// them; the OAuth layer still authorizes each request, and token refreshes are not affected
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *SheetsClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRateLimiter waits on limiter before every Sheets API request; cancelling the request's
+// context stops the wait
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *SheetsClient) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithWriteLimiter waits on limiter before every Sheets API request that writes (any method
+// but GET), e.g. a limiter shared by all workers so their writes stay within the project's
+// per-minute write quota; reads are not held back
+func WithWriteLimiter(limiter RateLimiter) Option {
+	return func(c *SheetsClient) {
+		c.writeLimiter = limiter
+	}
+}
+
+// WithTokenRefresher routes the client's token refreshes through refresher
+func WithTokenRefresher(refresher TokenRefresher) Option {
+	return func(c *SheetsClient) {
+		c.tokenRefresher = refresher
+	}
+}
+
+// WithResponseCache serves spreadsheet metadata (title, URL and tabs) from cache while it is
+// fresh. Entries are keyed by the refresh token and spreadsheet, so reconnecting Google or
+// choosing another spreadsheet reads it again; creating a tab invalidates the entry.
+func WithResponseCache(cache *respcache.Cache) Option {
+	return func(c *SheetsClient) {
+		c.responseCache = cache
+	}
+}
+
+// WithTemplate selects the spreadsheet template whose column layout activity rows are written in
+func WithTemplate(template *templates.Template) Option {
+	return func(c *SheetsClient) {
+		c.template = template
+	}
+}
+
+// WithActivitySheet writes activity rows to the tab with the given title instead of Sheet1; the
+// tab is created by EnsureActivityHeader when missing
+func WithActivitySheet(title string) Option {
+	return func(c *SheetsClient) {
+		if title != "" {
+			c.activitySheet = title
+		}
+	}
+}
+
+// WithChronologicalOrder re-sorts activity rows by date after a sync that appended an activity
+// older than the rows above it; otherwise new rows stay in the order they were appended
+func WithChronologicalOrder(enabled bool) Option {
+	return func(c *SheetsClient) {
+		c.chronological = enabled
+	}
+}
+
+// WithReadbackVerification reads back every flushed chunk and rewrites rows whose cells differ
+// from what was written. It costs one extra read per chunk and is off by default.
+func WithReadbackVerification(enabled bool) Option {
+	return func(c *SheetsClient) {
+		c.verifyWrites = enabled
+	}
+}
+
+// WithChunkRetry sets how a failed chunk of activity row writes is retried before the sync
+// fails; see DefaultChunkRetryConfig
+func WithChunkRetry(cfg retry.Config) Option {
+	return func(c *SheetsClient) {
+		c.chunkRetry = cfg
+	}
+}
+
+// rateLimitedTransport waits on the limiter before passing each request to base; with
+// writesOnly, GET requests pass without waiting
+type rateLimitedTransport struct {
+	limiter    RateLimiter
+	base       http.RoundTripper
+	writesOnly bool
+}
+
+func (t rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.writesOnly || req.Method != http.MethodGet {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,131 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// countingLimiter counts waits and never blocks
+type countingLimiter struct{ waits int }
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return ctx.Err()
+}
+
+func TestWithEndpoints(t *testing.T) {
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"), WithEndpoints(Endpoints{TokenURL: "http://localhost:9090/token"}))
+	if got := client.oauthConfig.Endpoint.TokenURL; got != "http://localhost:9090/token" {
+		t.Errorf("Expected token refreshes to use the configured URL, got %q", got)
+	}
+	if got := client.endpoints.SheetsEndpoint(); got != DefaultSheetsBaseURL {
+		t.Errorf("Expected the production Sheets endpoint, got %q", got)
+	}
+
+	if got := NewSheetsClient(1, "refresh-token", logger.New("test")).endpoints; got != DefaultEndpoints() {
+		t.Errorf("Expected the production endpoints by default, got %+v", got)
+	}
+}
+
+func TestOptionsConfigureRequests(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"spreadsheetId": "sheet-1",
+			"properties":    map[string]string{"title": "Training Log"},
+		})
+	}))
+	defer server.Close()
+
+	limiter := &countingLimiter{}
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"),
+		WithBaseURL(server.URL),
+		WithOAuthCredentials("client-id", "client-secret", "http://localhost/callback"),
+		WithInitialToken("stored-access-token", time.Now().Add(time.Hour)),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithRateLimiter(limiter))
+
+	if client.oauthConfig.ClientID != "client-id" || client.oauthConfig.RedirectURL != "http://localhost/callback" {
+		t.Errorf("Expected the OAuth credentials to be set, got %+v", client.oauthConfig)
+	}
+	if got := client.endpoints.OAuthEndpoint().TokenURL; got != DefaultEndpoints().TokenURL {
+		t.Errorf("Expected WithBaseURL to keep the production token URL, got %q", got)
+	}
+
+	info, err := client.GetSpreadsheetInfo(context.Background(), "sheet-1")
+	if err != nil {
+		t.Fatalf("GetSpreadsheetInfo() failed: %v", err)
+	}
+	if info.Title != "Training Log" {
+		t.Errorf("Expected the spreadsheet title, got %q", info.Title)
+	}
+	if authorization != "Bearer stored-access-token" {
+		t.Errorf("Expected the initial token to authorize the request, got %q", authorization)
+	}
+	if limiter.waits != 1 {
+		t.Errorf("Expected one rate limiter wait, got %d", limiter.waits)
+	}
+}
+
+func TestWithWriteLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := &countingLimiter{}
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"), WithWriteLimiter(limiter))
+
+	resp, err := client.httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if limiter.waits != 0 {
+		t.Errorf("Expected reads not to wait on the write limiter, got %d waits", limiter.waits)
+	}
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		req, _ := http.NewRequest(method, server.URL, nil)
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		resp.Body.Close()
+	}
+	if limiter.waits != 2 {
+		t.Errorf("Expected both writes to wait on the write limiter, got %d waits", limiter.waits)
+	}
+}
+
+func TestWithActivitySheet(t *testing.T) {
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"), WithActivitySheet("Ana (7)"))
+	if got := client.activityLayout().readRange(); got != "'Ana (7)'!A2:J" {
+		t.Errorf("Expected reads from the athlete's tab, got %q", got)
+	}
+
+	if got := NewSheetsClient(1, "refresh-token", logger.New("test"), WithActivitySheet("")).activitySheet; got != activitiesSheetTitle {
+		t.Errorf("Expected an empty title to keep the default tab, got %q", got)
+	}
+}
+
+func TestSheetRange(t *testing.T) {
+	tests := map[string]string{
+		"Weekly":     "Weekly!A1",
+		"Ana (7)":    "'Ana (7)'!A1",
+		"O'Neil (8)": "'O''Neil (8)'!A1",
+	}
+	for title, want := range tests {
+		if got := sheetRange(title, "A1"); got != want {
+			t.Errorf("sheetRange(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+)
+
+// ensureSheet makes sure a tab with the given title exists, creating it with a header row if missing
+// Callers must hold a valid token (ensureValidToken) before calling
+func (c *SheetsClient) ensureSheet(ctx context.Context, spreadsheetID, title string, header []interface{}) error {
+	spreadsheet, err := c.spreadsheetMetadata(ctx, spreadsheetID)
+	if err != nil {
+		return c.handleSheetsAPIError(ctx, err, "list sheets", spreadsheetID)
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil && sheet.Properties.Title == title {
+			return nil
+		}
+	}
+
+	c.log(ctx).Info("Creating missing tab in Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"sheet_title", title)
+
+	addSheet := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: title}}},
+		},
+	}
+	if _, err := c.sheetsService.Spreadsheets.BatchUpdate(spreadsheetID, addSheet).Context(ctx).Do(); err != nil {
+		return c.handleSheetsAPIError(ctx, err, "create sheet", spreadsheetID)
+	}
+	c.invalidateSpreadsheetMetadata(ctx, spreadsheetID)
+
+	if len(header) == 0 {
+		return nil
+	}
+
+	headerRange := &sheets.ValueRange{Values: [][]interface{}{header}}
+	_, err = c.sheetsService.Spreadsheets.Values.Update(spreadsheetID, sheetRange(title, "A1"), headerRange).
+		ValueInputOption("USER_ENTERED").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return c.handleSheetsAPIError(ctx, err, "write sheet header", spreadsheetID)
+	}
+
+	return nil
+}
+
+// spreadsheetMetadata returns the spreadsheet's ID, URL, title and tab properties, from the
+// response cache when fresh. Errors are returned unwrapped for handleSheetsAPIError.
+// Callers must hold a valid token (ensureValidToken) before calling
+func (c *SheetsClient) spreadsheetMetadata(ctx context.Context, spreadsheetID string) (*sheets.Spreadsheet, error) {
+	c.mu.RLock()
+	cache, cacheKey := c.responseCache, respcache.SpreadsheetKey(c.userID, c.refreshToken, spreadsheetID)
+	c.mu.RUnlock()
+
+	var spreadsheet sheets.Spreadsheet
+	if cache.Get(ctx, cacheKey, &spreadsheet) {
+		return &spreadsheet, nil
+	}
+
+	fetched, err := c.sheetsService.Spreadsheets.Get(spreadsheetID).
+		Fields("spreadsheetId,spreadsheetUrl,properties.title,sheets.properties").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Set(ctx, cacheKey, fetched)
+	return fetched, nil
+}
+
+// invalidateSpreadsheetMetadata drops cached metadata after the client changed the spreadsheet's tabs
+func (c *SheetsClient) invalidateSpreadsheetMetadata(ctx context.Context, spreadsheetID string) {
+	c.mu.RLock()
+	cache, cacheKey := c.responseCache, respcache.SpreadsheetKey(c.userID, c.refreshToken, spreadsheetID)
+	c.mu.RUnlock()
+
+	cache.Invalidate(ctx, cacheKey)
+}
+
+// sheetID returns the numeric ID of the tab with the given title
+// Callers must hold a valid token (ensureValidToken) before calling
+func (c *SheetsClient) sheetID(ctx context.Context, spreadsheetID, title string) (int64, error) {
+	spreadsheet, err := c.spreadsheetMetadata(ctx, spreadsheetID)
+	if err != nil {
+		return 0, c.handleSheetsAPIError(ctx, err, "list sheets", spreadsheetID)
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil && sheet.Properties.Title == title {
+			return sheet.Properties.SheetId, nil
+		}
+	}
+	return 0, fmt.Errorf("sheet %q not found in spreadsheet %s", title, spreadsheetID)
+}
+
+// EnsureActivityHeader makes sure the activities tab exists and its header row names every template column
+// Empty header cells are filled in (e.g. columns added to the template after the sheet was created);
+// headers the user renamed are left alone. It reports whether anything was written.
+func (c *SheetsClient) EnsureActivityHeader(ctx context.Context, spreadsheetID string) (bool, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return false, err
+	}
+
+	header := c.template.Header()
+	if err := c.ensureSheet(ctx, spreadsheetID, c.activitySheet, header); err != nil {
+		return false, err
+	}
+
+	existing, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, sheetRange(c.activitySheet, "A1:"+c.template.LastColumn()+"1")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, c.handleSheetsAPIError(ctx, err, "read sheet header", spreadsheetID)
+	}
+
+	var current []interface{}
+	if len(existing.Values) > 0 {
+		current = existing.Values[0]
+	}
+	missing, ok := missingHeaderCells(current, header)
+	if !ok {
+		return false, nil
+	}
+
+	c.log(ctx).Info("Filling missing activity header cells in Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"template", c.template.ID)
+
+	headerRange := &sheets.ValueRange{Values: [][]interface{}{missing}}
+	_, err = c.sheetsService.Spreadsheets.Values.Update(spreadsheetID, sheetRange(c.activitySheet, "A1"), headerRange).
+		ValueInputOption("USER_ENTERED").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, c.handleSheetsAPIError(ctx, err, "write sheet header", spreadsheetID)
+	}
+
+	return true, nil
+}
+
+// missingHeaderCells returns a header row holding only the cells that are empty in current
+// Present cells are nil so the Sheets API leaves them untouched; ok is false when nothing is missing
+func missingHeaderCells(current, header []interface{}) ([]interface{}, bool) {
+	missing := make([]interface{}, len(header))
+	ok := false
+	for i, title := range header {
+		if i < len(current) && strings.TrimSpace(fmt.Sprint(current[i])) != "" {
+			continue
+		}
+		missing[i] = title
+		ok = true
+	}
+	return missing, ok
+}
+
+// UpsertRowsByKey writes rows into a tab keyed by their first column
+// Rows whose key already exists in column A are updated in place; the rest are appended
+// The tab (and its header row) is created when missing
+func (c *SheetsClient) UpsertRowsByKey(ctx context.Context, spreadsheetID, title string, header []interface{}, rows [][]interface{}) error {
+	startTime := time.Now()
+	c.log(ctx).Debug("Upserting keyed rows in Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"sheet_title", title,
+		"row_count", len(rows))
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := c.ensureValidToken(ctx); err != nil {
+		return err
+	}
+
+	if err := c.ensureSheet(ctx, spreadsheetID, title, header); err != nil {
+		return err
+	}
+
+	// Read existing keys from column A to find rows that need updating
+	existing, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, sheetRange(title, "A:A")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return c.handleSheetsAPIError(ctx, err, "read row keys", spreadsheetID)
+	}
+
+	rowByKey := make(map[string]int, len(existing.Values))
+	for i, value := range existing.Values {
+		if len(value) > 0 {
+			rowByKey[fmt.Sprint(value[0])] = i + 1 // Sheets rows are 1-based
+		}
+	}
+
+	nextRow := len(existing.Values) + 1
+	if nextRow < 2 {
+		nextRow = 2 // Keep row 1 for the header
+	}
+
+	data := make([]*sheets.ValueRange, 0, len(rows))
+	updated, appended := 0, 0
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+
+		rowNumber, ok := rowByKey[fmt.Sprint(row[0])]
+		if ok {
+			updated++
+		} else {
+			rowNumber = nextRow
+			nextRow++
+			appended++
+		}
+
+		data = append(data, &sheets.ValueRange{
+			Range:  sheetRange(title, fmt.Sprintf("A%d", rowNumber)),
+			Values: [][]interface{}{row},
+		})
+	}
+
+	request := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             data,
+	}
+	if _, err := c.sheetsService.Spreadsheets.Values.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+		return c.handleSheetsAPIError(ctx, err, "upsert keyed rows", spreadsheetID)
+	}
+
+	c.log(ctx).Info("Successfully upserted keyed rows in Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"sheet_title", title,
+		"rows_updated", updated,
+		"rows_appended", appended,
+		"write_duration_ms", time.Since(startTime).Milliseconds())
+
+	return nil
+}
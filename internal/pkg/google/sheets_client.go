@@ -2,18 +2,25 @@ package google
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/outbound"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
 )
 
 // SpreadsheetInfo contains metadata about a Google Spreadsheet
@@ -37,6 +44,7 @@ type ActivityRow struct {
 	ElevationGain  string  `json:"elevation_gain"`  // formatted with units
 	HeartRate      string  `json:"heart_rate"`      // formatted average HR
 	Kudos          int     `json:"kudos"`
+	ActivityID     string  `json:"activity_id"`     // Strava activity ID, used to reconcile rows
 }
 
 // SheetsClient provides Google Sheets API access with automatic token lifecycle management
@@ -56,59 +64,100 @@ type SheetsClient struct {
 	// OAuth configuration for token refresh
 	oauthConfig *oauth2.Config
 	
+	// Coordinates refreshes with concurrent jobs for the same user; nil refreshes directly
+	tokenRefresher TokenRefresher
+	
+	// Google OAuth and API URLs
+	endpoints Endpoints
+	
+	// Column layout of the user's spreadsheet template
+	template *templates.Template
+	
+	// Tab the activity rows are written to
+	activitySheet string
+	
+	// Keep activity rows sorted by date when older activities are appended
+	chronological bool
+	
+	// Read back written rows and rewrite those that differ from the intended values
+	verifyWrites bool
+	
+	// How a failed chunk of row writes is retried (see WithChunkRetry)
+	chunkRetry retry.Config
+	
+	// HTTP client whose transport and timeout API requests use beneath OAuth
+	httpClient *http.Client
+	
+	// Optional limit on the rate of API calls (see WithRateLimiter)
+	rateLimiter RateLimiter
+	
+	// Optional limit on the rate of write calls (see WithWriteLimiter)
+	writeLimiter RateLimiter
+	
+	// Optional cache of spreadsheet metadata (see WithResponseCache)
+	responseCache *respcache.Cache
+	
 	// Logger for debugging external API interactions
 	logger *logger.Logger
 }
 
 // NewSheetsClient creates a new Google Sheets API client for a specific user
 // The client is designed to be instantiated per-user for each processing job
-func NewSheetsClient(userID int, refreshToken string, logger *logger.Logger) *SheetsClient {
-	// Create OAuth2 config for token refresh operations
-	oauthConfig := &oauth2.Config{
-		Scopes: []string{
-			"https://www.googleapis.com/auth/spreadsheets",
+func NewSheetsClient(userID int, refreshToken string, logger *logger.Logger, opts ...Option) *SheetsClient {
+	c := &SheetsClient{
+		userID:       userID,
+		refreshToken: refreshToken,
+		// OAuth2 config for token refresh operations; client credentials come from WithOAuthCredentials
+		oauthConfig: &oauth2.Config{
+			Scopes: []string{
+				"https://www.googleapis.com/auth/spreadsheets",
+			},
 		},
-		Endpoint: google.Endpoint,
-		// Note: Client ID and Secret should be injected via config
+		endpoints:     DefaultEndpoints(),
+		template:      templates.GetOrDefault(templates.DefaultTemplateID),
+		activitySheet: activitiesSheetTitle,
+		chunkRetry:    DefaultChunkRetryConfig(),
+		logger:        logger.WithContext("component", "google_sheets_client", "user_id", userID),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.oauthConfig.Endpoint = c.endpoints.OAuthEndpoint()
 
-	return &SheetsClient{
-		userID:       userID,
-		refreshToken: refreshToken,
-		oauthConfig:  oauthConfig,
-		logger:       logger.WithContext("component", "google_sheets_client", "user_id", userID),
+	// API requests go through the rate limiters and the instrumented transport, on top of any
+	// transport of WithHTTPClient
+	var base http.RoundTripper
+	var timeout time.Duration
+	if c.httpClient != nil {
+		base, timeout = c.httpClient.Transport, c.httpClient.Timeout
+	}
+	if c.rateLimiter != nil {
+		base = rateLimitedTransport{limiter: c.rateLimiter, base: base}
+	}
+	if c.writeLimiter != nil {
+		base = rateLimitedTransport{limiter: c.writeLimiter, base: base, writesOnly: true}
 	}
+	c.httpClient = &http.Client{Timeout: timeout, Transport: outbound.NewTransport(outbound.ProviderGoogle, c.logger, base)}
+
+	c.logger.Debug("Google Sheets client created",
+		"client_id", c.oauthConfig.ClientID,
+		"has_client_secret", c.oauthConfig.ClientSecret != "",
+		"redirect_url", c.oauthConfig.RedirectURL,
+		"has_access_token", c.accessToken != "",
+		"token_expiry", c.tokenExpiry)
+	return c
 }
 
-// SetOAuthCredentials configures the OAuth client credentials for token refresh
-// This should be called during client initialization with application credentials
-func (c *SheetsClient) SetOAuthCredentials(clientID, clientSecret, redirectURL string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	c.oauthConfig.ClientID = clientID
-	c.oauthConfig.ClientSecret = clientSecret
-	c.oauthConfig.RedirectURL = redirectURL
-	
-	c.logger.Debug("OAuth credentials configured for Google Sheets client",
-		"client_id", clientID,
-		"has_client_secret", clientSecret != "",
-		"redirect_url", redirectURL)
+// log returns the client's logger with the attributes carried by ctx, such as the trace_id and
+// job_id of the job the call is made for
+func (c *SheetsClient) log(ctx context.Context) *logger.Logger {
+	return c.logger.ForContext(ctx)
 }
 
-// SetInitialTokens sets initial access token and expiry if available
-// This allows the client to use existing valid tokens before falling back to refresh
-func (c *SheetsClient) SetInitialTokens(accessToken string, expiry time.Time) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	c.accessToken = accessToken
-	c.tokenExpiry = expiry
-	
-	c.logger.Debug("Initial access token set for Google Sheets client",
-		"has_access_token", accessToken != "",
-		"token_expiry", expiry,
-		"token_valid", time.Now().Before(expiry))
+// TokenRefresher performs token refreshes on behalf of clients, e.g. so concurrent jobs for the
+// same user share one refresh; refresh makes the OAuth call
+type TokenRefresher interface {
+	Refresh(ctx context.Context, provider string, userID int, refreshToken string, refresh func(context.Context) (*oauth2.Token, error)) (*oauth2.Token, error)
 }
 
 // ensureValidToken implements the "check-then-fetch" token management logic
@@ -117,21 +166,21 @@ func (c *SheetsClient) ensureValidToken(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	c.logger.Debug("Checking token validity before Google Sheets API call",
+	c.log(ctx).Debug("Checking token validity before Google Sheets API call",
 		"has_access_token", c.accessToken != "",
 		"token_expiry", c.tokenExpiry,
 		"time_until_expiry_minutes", time.Until(c.tokenExpiry).Minutes())
 	
 	// Check if current access token is valid (with 5-minute buffer)
 	if c.accessToken != "" && time.Now().Add(5*time.Minute).Before(c.tokenExpiry) {
-		c.logger.Debug("Using existing valid access token for Google Sheets API call",
+		c.log(ctx).Debug("Using existing valid access token for Google Sheets API call",
 			"token_expiry", c.tokenExpiry,
 			"minutes_until_expiry", time.Until(c.tokenExpiry).Minutes())
 		
 		// Ensure we have a sheets service with current token
 		if c.sheetsService == nil {
 			if err := c.createSheetsService(ctx); err != nil {
-				c.logger.Error("Failed to create Sheets service with existing token",
+				c.log(ctx).Error("Failed to create Sheets service with existing token",
 					"error", err,
 					"user_id", c.userID)
 				return err
@@ -141,34 +190,29 @@ func (c *SheetsClient) ensureValidToken(ctx context.Context) error {
 	}
 	
 	// Need to refresh token
-	c.logger.Debug("Access token invalid or expired, refreshing via Google OAuth",
+	c.log(ctx).Debug("Access token invalid or expired, refreshing via Google OAuth",
 		"current_token_expired", c.accessToken != "" && time.Now().After(c.tokenExpiry),
 		"current_token_missing", c.accessToken == "",
 		"refresh_token_available", c.refreshToken != "")
 	
 	if c.refreshToken == "" {
-		c.logger.Error("No refresh token available for Google token refresh",
+		c.log(ctx).Error("No refresh token available for Google token refresh",
 			"user_id", c.userID)
 		return ErrReauthRequired
 	}
 	
 	// Call Google OAuth token endpoint to refresh access token
 	startTime := time.Now()
-	c.logger.Debug("Making token refresh request to Google OAuth endpoint",
+	c.log(ctx).Debug("Making token refresh request to Google OAuth endpoint",
 		"endpoint", c.oauthConfig.Endpoint.TokenURL,
 		"user_id", c.userID)
 	
-	token := &oauth2.Token{
-		RefreshToken: c.refreshToken,
-	}
-	
-	tokenSource := c.oauthConfig.TokenSource(ctx, token)
-	newToken, err := tokenSource.Token()
+	newToken, err := c.refreshAccessToken(ctx)
 	
 	requestDuration := time.Since(startTime)
 	
 	if err != nil {
-		c.logger.Error("Failed to refresh Google access token via OAuth endpoint",
+		c.log(ctx).Error("Failed to refresh Google access token via OAuth endpoint",
 			"error", err,
 			"user_id", c.userID,
 			"request_duration_ms", requestDuration.Milliseconds(),
@@ -176,7 +220,7 @@ func (c *SheetsClient) ensureValidToken(ctx context.Context) error {
 		
 		// Check if this is an invalid grant error (requires re-authorization)
 		if IsReauthRequired(err) {
-			c.logger.Warn("Google refresh token is invalid, user re-authorization required",
+			c.log(ctx).Warn("Google refresh token is invalid, user re-authorization required",
 				"user_id", c.userID,
 				"error", err)
 			return &AuthError{
@@ -193,23 +237,28 @@ func (c *SheetsClient) ensureValidToken(ctx context.Context) error {
 		}
 	}
 	
-	// Update cached token
+	// Update cached token, adopting the refresh token if the provider rotated it
 	c.accessToken = newToken.AccessToken
 	c.tokenExpiry = newToken.Expiry
+	if newToken.RefreshToken != "" {
+		c.refreshToken = newToken.RefreshToken
+	}
 	
-	// Create new Sheets service with refreshed token
-	if err := c.createSheetsService(ctx); err != nil {
-		c.logger.Error("Failed to create Sheets service with refreshed token",
-			"error", err,
-			"user_id", c.userID)
-		return &NetworkError{
-			Operation: "service_creation",
-			Message:   "Failed to create Sheets service after token refresh",
-			Cause:     err,
+	// The service picks up the refreshed token with its next request
+	if c.sheetsService == nil {
+		if err := c.createSheetsService(ctx); err != nil {
+			c.log(ctx).Error("Failed to create Sheets service with refreshed token",
+				"error", err,
+				"user_id", c.userID)
+			return &NetworkError{
+				Operation: "service_creation",
+				Message:   "Failed to create Sheets service after token refresh",
+				Cause:     err,
+			}
 		}
 	}
 	
-	c.logger.Info("Successfully refreshed Google access token and created Sheets service",
+	c.log(ctx).Info("Successfully refreshed Google access token",
 		"user_id", c.userID,
 		"new_token_expiry", newToken.Expiry,
 		"token_valid_hours", time.Until(newToken.Expiry).Hours(),
@@ -218,24 +267,81 @@ func (c *SheetsClient) ensureValidToken(ctx context.Context) error {
 	return nil
 }
 
-// createSheetsService creates a new Google Sheets API service with the current access token
-func (c *SheetsClient) createSheetsService(ctx context.Context) error {
-	c.logger.Debug("Creating Google Sheets API service",
-		"user_id", c.userID)
+// refreshAccessToken exchanges the refresh token for a new access token, through the token
+// refresher when one is set. The caller must hold c.mu.
+func (c *SheetsClient) refreshAccessToken(ctx context.Context) (*oauth2.Token, error) {
+	refreshToken := c.refreshToken
+	refresh := func(ctx context.Context) (*oauth2.Token, error) {
+		return c.oauthConfig.TokenSource(tokenContext(ctx, c.logger), &oauth2.Token{RefreshToken: refreshToken}).Token()
+	}
+	
+	if c.tokenRefresher == nil {
+		return refresh(ctx)
+	}
+	return c.tokenRefresher.Refresh(ctx, "google", c.userID, refreshToken, refresh)
+}
+
+// tokenExpiryDelta refreshes tokens this long before they expire, so none expires in flight
+const tokenExpiryDelta = time.Minute
+
+// requestToken returns an access token for a request made with ctx, refreshing it with ctx
+// when it is about to expire so cancelling the request cancels the refresh too
+func (c *SheetsClient) requestToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	
-	// Create token source with current access token
-	token := &oauth2.Token{
-		AccessToken:  c.accessToken,
-		RefreshToken: c.refreshToken,
-		Expiry:       c.tokenExpiry,
+	if c.accessToken != "" && time.Now().Add(tokenExpiryDelta).Before(c.tokenExpiry) {
+		return c.accessToken, nil
 	}
 	
-	tokenSource := c.oauthConfig.TokenSource(ctx, token)
+	c.log(ctx).Debug("Access token expired mid-job, refreshing before Google Sheets API call",
+		"user_id", c.userID,
+		"token_expiry", c.tokenExpiry)
 	
-	// Create Sheets service with authenticated client
-	sheetsService, err := sheets.NewService(ctx, option.WithTokenSource(tokenSource))
+	newToken, err := c.refreshAccessToken(ctx)
 	if err != nil {
-		c.logger.Error("Failed to create Google Sheets service",
+		return "", err
+	}
+	c.accessToken = newToken.AccessToken
+	c.tokenExpiry = newToken.Expiry
+	if newToken.RefreshToken != "" {
+		c.refreshToken = newToken.RefreshToken
+	}
+	return c.accessToken, nil
+}
+
+// tokenTransport authorizes each request with the client's current access token
+type tokenTransport struct {
+	client *SheetsClient
+	base   http.RoundTripper
+}
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.client.requestToken(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// createSheetsService creates the Google Sheets API service. Requests are authorized with the
+// client's access token at the time they are sent; see tokenTransport.
+func (c *SheetsClient) createSheetsService(ctx context.Context) error {
+	c.log(ctx).Debug("Creating Google Sheets API service",
+		"user_id", c.userID)
+	
+	auth := option.WithHTTPClient(&http.Client{
+		Transport: tokenTransport{client: c, base: c.httpClient.Transport},
+		Timeout:   c.httpClient.Timeout,
+	})
+	sheetsService, err := sheets.NewService(ctx, auth, option.WithEndpoint(c.endpoints.SheetsEndpoint()))
+	if err != nil {
+		c.log(ctx).Error("Failed to create Google Sheets service",
 			"error", err,
 			"user_id", c.userID)
 		return &NetworkError{
@@ -247,7 +353,7 @@ func (c *SheetsClient) createSheetsService(ctx context.Context) error {
 	
 	c.sheetsService = sheetsService
 	
-	c.logger.Debug("Successfully created Google Sheets API service",
+	c.log(ctx).Debug("Successfully created Google Sheets API service",
 		"user_id", c.userID)
 	
 	return nil
@@ -257,13 +363,13 @@ func (c *SheetsClient) createSheetsService(ctx context.Context) error {
 // This enhances the existing validation with better error handling and logging
 func (c *SheetsClient) ValidateAccess(ctx context.Context, spreadsheetID string) error {
 	startTime := time.Now()
-	c.logger.Debug("Starting Google Sheets access validation",
+	c.log(ctx).Debug("Starting Google Sheets access validation",
 		"user_id", c.userID,
 		"spreadsheet_id", spreadsheetID)
 	
 	// Ensure we have a valid token and service
 	if err := c.ensureValidToken(ctx); err != nil {
-		c.logger.Error("Failed to ensure valid token for access validation",
+		c.log(ctx).Error("Failed to ensure valid token for access validation",
 			"error", err,
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
@@ -271,16 +377,16 @@ func (c *SheetsClient) ValidateAccess(ctx context.Context, spreadsheetID string)
 	}
 	
 	// Test read access by getting spreadsheet metadata
-	c.logger.Debug("Testing read access to Google Spreadsheet",
+	c.log(ctx).Debug("Testing read access to Google Spreadsheet",
 		"spreadsheet_id", spreadsheetID,
 		"user_id", c.userID)
 	
-	spreadsheet, err := c.sheetsService.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	spreadsheet, err := c.spreadsheetMetadata(ctx, spreadsheetID)
 	if err != nil {
-		return c.handleSheetsAPIError(err, "read access validation", spreadsheetID)
+		return c.handleSheetsAPIError(ctx, err, "read access validation", spreadsheetID)
 	}
 	
-	c.logger.Debug("Successfully retrieved spreadsheet metadata",
+	c.log(ctx).Debug("Successfully retrieved spreadsheet metadata",
 		"spreadsheet_id", spreadsheetID,
 		"spreadsheet_title", spreadsheet.Properties.Title,
 		"sheet_count", len(spreadsheet.Sheets),
@@ -288,18 +394,18 @@ func (c *SheetsClient) ValidateAccess(ctx context.Context, spreadsheetID string)
 	
 	// Test write access by attempting to read a range
 	testRange := "A1:A1"
-	c.logger.Debug("Testing write access permissions",
+	c.log(ctx).Debug("Testing write access permissions",
 		"spreadsheet_id", spreadsheetID,
 		"test_range", testRange,
 		"user_id", c.userID)
 	
 	_, err = c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, testRange).Context(ctx).Do()
 	if err != nil {
-		return c.handleSheetsAPIError(err, "write access validation", spreadsheetID)
+		return c.handleSheetsAPIError(ctx, err, "write access validation", spreadsheetID)
 	}
 	
 	duration := time.Since(startTime)
-	c.logger.Info("Google Sheets access validation successful",
+	c.log(ctx).Info("Google Sheets access validation successful",
 		"user_id", c.userID,
 		"spreadsheet_id", spreadsheetID,
 		"spreadsheet_title", spreadsheet.Properties.Title,
@@ -310,7 +416,7 @@ func (c *SheetsClient) ValidateAccess(ctx context.Context, spreadsheetID string)
 
 // GetSpreadsheetInfo retrieves metadata about a Google Spreadsheet
 func (c *SheetsClient) GetSpreadsheetInfo(ctx context.Context, spreadsheetID string) (*SpreadsheetInfo, error) {
-	c.logger.Debug("Retrieving Google Spreadsheet metadata",
+	c.log(ctx).Debug("Retrieving Google Spreadsheet metadata",
 		"user_id", c.userID,
 		"spreadsheet_id", spreadsheetID)
 	
@@ -319,13 +425,13 @@ func (c *SheetsClient) GetSpreadsheetInfo(ctx context.Context, spreadsheetID str
 		return nil, err
 	}
 	
-	spreadsheet, err := c.sheetsService.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	spreadsheet, err := c.spreadsheetMetadata(ctx, spreadsheetID)
 	if err != nil {
-		c.logger.Error("Failed to retrieve spreadsheet metadata",
+		c.log(ctx).Error("Failed to retrieve spreadsheet metadata",
 			"error", err,
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
-		return nil, c.handleSheetsAPIError(err, "get spreadsheet info", spreadsheetID)
+		return nil, c.handleSheetsAPIError(ctx, err, "get spreadsheet info", spreadsheetID)
 	}
 	
 	info := &SpreadsheetInfo{
@@ -337,7 +443,7 @@ func (c *SheetsClient) GetSpreadsheetInfo(ctx context.Context, spreadsheetID str
 		UpdatedAt:  time.Now(), // Current time as proxy
 	}
 	
-	c.logger.Info("Successfully retrieved Google Spreadsheet metadata",
+	c.log(ctx).Info("Successfully retrieved Google Spreadsheet metadata",
 		"user_id", c.userID,
 		"spreadsheet_id", spreadsheetID,
 		"title", info.Title,
@@ -348,117 +454,23 @@ func (c *SheetsClient) GetSpreadsheetInfo(ctx context.Context, spreadsheetID str
 }
 
 // WriteActivities writes Strava activities to a Google Spreadsheet
-// This implements the core automation functionality
+// This implements the core automation functionality. Rows are reconciled by activity ID,
+// see SyncActivities; no deletion detection is performed.
 func (c *SheetsClient) WriteActivities(ctx context.Context, spreadsheetID string, activities []strava.Activity) error {
-	startTime := time.Now()
-	c.logger.Debug("Writing activities to Google Spreadsheet",
-		"user_id", c.userID,
-		"spreadsheet_id", spreadsheetID,
-		"activity_count", len(activities))
-	
-	if len(activities) == 0 {
-		c.logger.Debug("No activities to write to spreadsheet",
-			"user_id", c.userID,
-			"spreadsheet_id", spreadsheetID)
-		return nil
-	}
-	
-	// Ensure we have a valid token and service
-	if err := c.ensureValidToken(ctx); err != nil {
-		return err
-	}
-	
-	// Convert activities to spreadsheet rows
-	rows := c.convertActivitiesToRows(activities)
-	
-	// Prepare the range for writing (assume we're writing to Sheet1, starting from A2)
-	writeRange := "Sheet1!A2:I" + fmt.Sprintf("%d", len(rows)+1)
-	
-	c.logger.Debug("Preparing to write activity data to spreadsheet",
-		"user_id", c.userID,
-		"spreadsheet_id", spreadsheetID,
-		"write_range", writeRange,
-		"row_count", len(rows))
-	
-	// Create the value range
-	valueRange := &sheets.ValueRange{
-		Values: rows,
-	}
-	
-	// Write to spreadsheet
-	_, err := c.sheetsService.Spreadsheets.Values.Update(spreadsheetID, writeRange, valueRange).
-		ValueInputOption("USER_ENTERED").
-		Context(ctx).
-		Do()
-	
-	if err != nil {
-		c.logger.Error("Failed to write activities to Google Spreadsheet",
-			"error", err,
-			"user_id", c.userID,
-			"spreadsheet_id", spreadsheetID,
-			"activity_count", len(activities))
-		return c.handleSheetsAPIError(err, "write activities", spreadsheetID)
-	}
-	
-	duration := time.Since(startTime)
-	c.logger.Info("Successfully wrote activities to Google Spreadsheet",
-		"user_id", c.userID,
-		"spreadsheet_id", spreadsheetID,
-		"activity_count", len(activities),
-		"write_range", writeRange,
-		"write_duration_ms", duration.Milliseconds())
-	
-	return nil
+	_, err := c.SyncActivities(ctx, spreadsheetID, activities, time.Time{})
+	return err
 }
 
-// convertActivitiesToRows converts Strava activities to spreadsheet row format
+// convertActivitiesToRows converts Strava activities to rows in the client's template layout
 func (c *SheetsClient) convertActivitiesToRows(activities []strava.Activity) [][]interface{} {
 	rows := make([][]interface{}, len(activities))
 	
 	for i, activity := range activities {
-		// Format duration as HH:MM:SS
-		duration := time.Duration(activity.MovingTime) * time.Second
-		durationStr := fmt.Sprintf("%02d:%02d:%02d", 
-			int(duration.Hours()), 
-			int(duration.Minutes())%60, 
-			int(duration.Seconds())%60)
-		
-		// Format distance in kilometers
-		distanceKm := activity.Distance / 1000
-		distanceStr := fmt.Sprintf("%.2f km", distanceKm)
-		
-		// Format elevation gain in meters
-		elevationStr := fmt.Sprintf("%.0f m", activity.TotalElevationGain)
-		
-		// Format average heart rate
-		heartRateStr := ""
-		if activity.AverageHeartrate > 0 {
-			heartRateStr = fmt.Sprintf("%.0f bpm", activity.AverageHeartrate)
-		}
-		
-		// Calculate pace (for running activities)
-		paceStr := ""
-		if activity.Type == "Run" && activity.MovingTime > 0 && activity.Distance > 0 {
-			pacePerKm := float64(activity.MovingTime) / (activity.Distance / 1000)
-			paceMinutes := int(pacePerKm / 60)
-			paceSeconds := int(pacePerKm) % 60
-			paceStr = fmt.Sprintf("%d:%02d /km", paceMinutes, paceSeconds)
-		}
-		
-		rows[i] = []interface{}{
-			activity.StartDateLocal.Format("2006-01-02"),
-			activity.Name,
-			activity.Type,
-			distanceStr,
-			durationStr,
-			paceStr,
-			elevationStr,
-			heartRateStr,
-			activity.Kudos,
-		}
+		rows[i] = c.template.Row(activity)
 	}
 	
 	c.logger.Debug("Converted activities to spreadsheet rows",
+		"template", c.template.ID,
 		"activity_count", len(activities),
 		"row_count", len(rows))
 	
@@ -466,8 +478,8 @@ func (c *SheetsClient) convertActivitiesToRows(activities []strava.Activity) [][
 }
 
 // handleSheetsAPIError processes Google Sheets API errors and returns appropriate error types
-func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID string) error {
-	c.logger.Error("Google Sheets API error",
+func (c *SheetsClient) handleSheetsAPIError(ctx context.Context, err error, operation, spreadsheetID string) error {
+	c.log(ctx).Error("Google Sheets API error",
 		"error", err,
 		"operation", operation,
 		"user_id", c.userID,
@@ -478,7 +490,7 @@ func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID
 	// Parse common Google API error patterns
 	switch {
 	case strings.Contains(errorString, "403") || strings.Contains(errorString, "Forbidden"):
-		c.logger.Warn("Permission denied for Google Sheets access",
+		c.log(ctx).Warn("Permission denied for Google Sheets access",
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
 		return &SheetsError{
@@ -488,7 +500,7 @@ func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID
 			Cause:         err,
 		}
 	case strings.Contains(errorString, "404") || strings.Contains(errorString, "Not Found"):
-		c.logger.Warn("Google Spreadsheet not found",
+		c.log(ctx).Warn("Google Spreadsheet not found",
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
 		return &SheetsError{
@@ -498,7 +510,7 @@ func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID
 			Cause:         err,
 		}
 	case strings.Contains(errorString, "400") || strings.Contains(errorString, "Bad Request"):
-		c.logger.Warn("Invalid request to Google Sheets API",
+		c.log(ctx).Warn("Invalid request to Google Sheets API",
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
 		return &SheetsError{
@@ -508,17 +520,22 @@ func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID
 			Cause:         err,
 		}
 	case strings.Contains(errorString, "429"):
-		c.logger.Warn("Google Sheets API rate limit exceeded",
+		c.log(ctx).Warn("Google Sheets API rate limit exceeded",
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
-		return &APIError{
+		rateLimited := &APIError{
 			StatusCode: 429,
 			Type:       "RATE_LIMITED",
 			Message:    "Google Sheets API rate limit exceeded",
 			Cause:      err,
 		}
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			rateLimited.RetryDelay = retry.ParseRetryAfter(apiErr.Header.Get("Retry-After"), time.Now())
+		}
+		return rateLimited
 	default:
-		c.logger.Error("Unknown Google Sheets API error",
+		c.log(ctx).Error("Unknown Google Sheets API error",
 			"error", err,
 			"user_id", c.userID,
 			"spreadsheet_id", spreadsheetID)
@@ -528,4 +545,72 @@ func (c *SheetsClient) handleSheetsAPIError(err error, operation, spreadsheetID
 			Cause:     err,
 		}
 	}
-}
\ No newline at end of file
+}
+
+// GrantedScopes returns the OAuth scopes granted to the current access token
+// This is used by background reconciliation to detect tokens that lost the Sheets scope
+func (c *SheetsClient) GrantedScopes(ctx context.Context) ([]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+	
+	c.mu.RLock()
+	accessToken := c.accessToken
+	tokenInfoURL := c.endpoints.TokenInfoURL()
+	c.mu.RUnlock()
+	
+	endpoint := tokenInfoURL + "?access_token=" + url.QueryEscape(accessToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, &NetworkError{
+			Operation: "token_info",
+			Message:   "Failed to create token info request",
+			Cause:     err,
+		}
+	}
+	
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &NetworkError{
+			Operation: "token_info",
+			Message:   "Failed to query Google token info",
+			Cause:     err,
+		}
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, &AuthError{
+			Type:    "REAUTH_REQUIRED",
+			Message: "Google access token was rejected by token info endpoint",
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Type:       "TOKEN_INFO_FAILED",
+			Message:    "Unexpected response from Google token info endpoint",
+		}
+	}
+	
+	var tokenInfo struct {
+		Scope string `json:"scope"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenInfo); err != nil {
+		return nil, &NetworkError{
+			Operation: "token_info",
+			Message:   "Failed to decode Google token info response",
+			Cause:     err,
+		}
+	}
+	
+	return strings.Fields(tokenInfo.Scope), nil
+}
+
+// tokenRefreshTimeout bounds each call to the token endpoint
+const tokenRefreshTimeout = 30 * time.Second
+
+// tokenContext makes token refreshes made with ctx go through the instrumented transport
+func tokenContext(ctx context.Context, log *logger.Logger) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, outbound.NewClient(outbound.ProviderGoogle, log, tokenRefreshTimeout))
+}
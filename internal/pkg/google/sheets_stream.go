@@ -0,0 +1,454 @@
+package google
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+const (
+	// DefaultStreamChunkSize is the number of row writes buffered before they are flushed to the sheet
+	DefaultStreamChunkSize = 500
+	// DefaultStreamPageBuffer is the number of fetched pages that may wait for the sheet writer
+	DefaultStreamPageBuffer = 2
+)
+
+// DefaultChunkRetryConfig retries a failed chunk write twice with jittered backoff. Rate-limited
+// responses wait their Retry-After, and rejected requests (SheetsError, AuthError) are not retried.
+func DefaultChunkRetryConfig() retry.Config {
+	return retry.Config{
+		MaxAttempts: 3,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    10 * time.Second,
+		Jitter:      true,
+		MaxElapsed:  time.Minute,
+	}
+}
+
+// ActivityPageSource produces activities one page at a time, e.g. strava.Client.ForEachActivityPage
+type ActivityPageSource func(ctx context.Context, fn func(page []strava.Activity) error) error
+
+// pendingWrite is a buffered row write and the action it performs
+type pendingWrite struct {
+	action     string
+	activityID int64
+	rowNumber  int
+	valueRange *sheets.ValueRange
+
+	// previousName is the name cell before a row was flagged as deleted
+	previousName string
+}
+
+// indexedRow is the compact form of an existing sheet row kept while streaming.
+// Only a hash of the managed cells is retained, so memory grows with the number of
+// rows in the sheet rather than with their content or the number of activities fetched.
+type indexedRow struct {
+	rowNumber int
+	hash      uint64
+	date      string // Only for rows with an activity ID, used for deletion detection
+	name      string // Only for rows with an activity ID, used to flag deletions
+	seen      bool
+}
+
+// ActivityStream reconciles activities with the sheet incrementally: activities are converted
+// and compared as they arrive and the resulting writes are flushed in chunks, so a sync never
+// holds more than one chunk of rows in memory regardless of how many activities are fetched.
+type ActivityStream struct {
+	layout     activityLayout
+	byID       map[int64]*indexedRow
+	byLegacy   map[string]*indexedRow
+	nextRow    int
+	chunkSize  int
+	pending    []pendingWrite
+	flush      func(ctx context.Context, writes []pendingWrite) error
+	result     ActivitySyncResult
+	maxPending int
+
+	// sort re-sorts the activity rows by date once all writes are flushed; nil keeps the append order.
+	// It only runs when an appended row is dated before a row above it (outOfOrder).
+	sort       func(ctx context.Context, lastRow int) error
+	latestDate string
+	outOfOrder bool
+
+	// verify reads back flushed writes and returns those whose cells differ; nil skips verification.
+	// Mismatched writes are rewritten once and checked again.
+	verify func(ctx context.Context, writes []pendingWrite) ([]pendingWrite, error)
+
+	// writes records the flushed writes for undo; nil when the writes are not recorded
+	writes *SheetWrites
+
+	// retry runs a chunk write, retrying it on transient errors; nil writes each chunk once.
+	// Only the failed chunk is sent again, never the chunks written before it.
+	retry func(ctx context.Context, write func() error) error
+
+	// rowsWritten counts the rows of the chunks written so far, reported when a chunk fails
+	rowsWritten int
+}
+
+// NewActivityStream reads the sheet's existing rows and returns a stream that writes to it in chunks
+func (c *SheetsClient) NewActivityStream(ctx context.Context, spreadsheetID string, chunkSize int) (*ActivityStream, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	layout := c.activityLayout()
+	existing, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, layout.readRange()).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, c.handleSheetsAPIError(ctx, err, "read existing activities", spreadsheetID)
+	}
+
+	flush := func(ctx context.Context, writes []pendingWrite) error {
+		data := make([]*sheets.ValueRange, len(writes))
+		for i, write := range writes {
+			data[i] = write.valueRange
+		}
+		request := &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "USER_ENTERED",
+			Data:             data,
+		}
+		if _, err := c.sheetsService.Spreadsheets.Values.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+			c.log(ctx).Error("Failed to write activity chunk to Google Spreadsheet",
+				"error", err,
+				"user_id", c.userID,
+				"spreadsheet_id", spreadsheetID,
+				"chunk_size", len(writes))
+			return c.handleSheetsAPIError(ctx, err, "write activities", spreadsheetID)
+		}
+		return nil
+	}
+
+	stream := newActivityStream(layout, existing.Values, chunkSize, flush)
+	stream.writes = layout.sheetWrites(spreadsheetID)
+	stream.retry = func(ctx context.Context, write func() error) error {
+		return retry.WithExponentialBackoff(ctx, c.chunkRetry, c.log(ctx), "write_activity_chunk", write)
+	}
+	if c.chronological {
+		stream.sort = func(ctx context.Context, lastRow int) error {
+			return c.sortActivityRows(ctx, spreadsheetID, layout, lastRow)
+		}
+	}
+	if c.verifyWrites {
+		stream.verify = func(ctx context.Context, writes []pendingWrite) ([]pendingWrite, error) {
+			return c.readBackMismatches(ctx, spreadsheetID, layout, writes)
+		}
+	}
+	return stream, nil
+}
+
+// newActivityStream indexes the existing rows (starting at row 2); existing is not retained
+func newActivityStream(layout activityLayout, existing [][]interface{}, chunkSize int, flush func(ctx context.Context, writes []pendingWrite) error) *ActivityStream {
+	if chunkSize <= 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+
+	s := &ActivityStream{
+		layout:    layout,
+		byID:      make(map[int64]*indexedRow, len(existing)),
+		byLegacy:  make(map[string]*indexedRow),
+		nextRow:   len(existing) + 2,
+		chunkSize: chunkSize,
+		flush:     flush,
+	}
+
+	// Index existing rows by activity ID, falling back to date|name|type for rows written
+	// before the activity ID column existed
+	for i, row := range existing {
+		if date := cellString(row, layout.dateColumn); date > s.latestDate {
+			s.latestDate = date
+		}
+
+		entry := &indexedRow{rowNumber: i + 2, hash: layout.rowHash(row)}
+		if id, ok := layout.rowActivityID(row); ok {
+			entry.date = cellString(row, layout.dateColumn)
+			entry.name = cellString(row, layout.nameColumn)
+			s.byID[id] = entry
+		} else if key := layout.legacyRowKey(row); key != "" {
+			s.byLegacy[key] = entry
+		}
+	}
+
+	return s
+}
+
+// Add converts and reconciles a batch of activities, flushing full chunks to the sheet
+func (s *ActivityStream) Add(ctx context.Context, activities []strava.Activity) error {
+	for _, activity := range activities {
+		if err := s.addRow(ctx, activity.ID, s.layout.template.Row(activity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRow reconciles a single converted activity row
+func (s *ActivityStream) addRow(ctx context.Context, activityID int64, row []interface{}) error {
+	entry, ok := s.byID[activityID]
+	if !ok {
+		key := s.layout.legacyRowKey(row)
+		if entry, ok = s.byLegacy[key]; ok {
+			// A legacy row is matched once, then tracked by its activity ID
+			delete(s.byLegacy, key)
+			s.byID[activityID] = entry
+		}
+	}
+
+	hash := s.layout.rowHash(row)
+	action := RowActionUpdate
+	switch {
+	case !ok:
+		entry = &indexedRow{rowNumber: s.nextRow}
+		s.byID[activityID] = entry
+		s.nextRow++
+		action = RowActionAppend
+		s.trackAppendOrder(cellString(row, s.layout.dateColumn))
+		s.result.Appended++
+		s.result.AppendedActivityIDs = append(s.result.AppendedActivityIDs, activityID)
+	case entry.hash == hash:
+		entry.seen = true
+		s.result.Unchanged++
+		return nil
+	default:
+		s.result.Updated++
+	}
+	entry.seen = true
+	entry.hash = hash
+
+	return s.queue(ctx, pendingWrite{
+		action:     action,
+		activityID: activityID,
+		rowNumber:  entry.rowNumber,
+		valueRange: s.layout.rowRange(entry.rowNumber, s.layout.managedValues(row)),
+	})
+}
+
+// Consume fetches pages from source while earlier pages are being written, holding at most
+// pageBuffer pages between the two so a slow sheet write applies backpressure to the fetch
+func (s *ActivityStream) Consume(ctx context.Context, source ActivityPageSource, pageBuffer int) error {
+	if pageBuffer <= 0 {
+		pageBuffer = DefaultStreamPageBuffer
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make(chan []strava.Activity, pageBuffer)
+	fetchErr := make(chan error, 1)
+	go func() {
+		defer close(pages)
+		fetchErr <- source(ctx, func(page []strava.Activity) error {
+			select {
+			case pages <- page:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var writeErr error
+	for page := range pages {
+		if writeErr != nil {
+			continue // Drain until the fetcher notices the cancellation
+		}
+		if err := s.Add(ctx, page); err != nil {
+			writeErr = err
+			cancel()
+		}
+	}
+
+	if err := <-fetchErr; writeErr == nil {
+		return err
+	}
+	return writeErr
+}
+
+// Finish flags rows dated after windowStart whose activity was not streamed as deleted, flushes
+// the remaining writes and returns the sync result. A zero windowStart disables deletion detection.
+func (s *ActivityStream) Finish(ctx context.Context, windowStart time.Time) (*ActivitySyncResult, error) {
+	if !windowStart.IsZero() {
+		// Rows on the boundary day are left alone
+		windowDate := windowStart.Format("2006-01-02")
+		for id, entry := range s.byID {
+			if entry.seen || entry.date <= windowDate || strings.HasPrefix(entry.name, DeletedActivityMarker) {
+				continue
+			}
+
+			// Only the name cell changes; nil cells are left untouched by the Sheets API
+			flagged := make([]interface{}, len(s.layout.template.Columns))
+			flagged[s.layout.nameColumn] = DeletedActivityMarker + entry.name
+
+			write := pendingWrite{
+				action:       RowActionFlagDeleted,
+				activityID:   id,
+				rowNumber:    entry.rowNumber,
+				valueRange:   s.layout.rowRange(entry.rowNumber, flagged),
+				previousName: entry.name,
+			}
+			if err := s.queue(ctx, write); err != nil {
+				return nil, err
+			}
+			s.result.DeletedActivityIDs = append(s.result.DeletedActivityIDs, id)
+		}
+	}
+
+	if err := s.flushPending(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.sort != nil && s.outOfOrder {
+		if err := s.sort(ctx, s.nextRow-1); err != nil {
+			return nil, err
+		}
+		s.result.Sorted = true
+	}
+	s.result.Writes = s.writes
+	return &s.result, nil
+}
+
+// trackAppendOrder notes whether an appended row breaks the chronological order of the sheet
+func (s *ActivityStream) trackAppendOrder(date string) {
+	if date == "" {
+		return
+	}
+	if date < s.latestDate {
+		s.outOfOrder = true
+		return
+	}
+	s.latestDate = date
+}
+
+// Flush writes the buffered rows now, e.g. before recording progress that depends on them
+func (s *ActivityStream) Flush(ctx context.Context) error {
+	return s.flushPending(ctx)
+}
+
+// MaxBufferedWrites reports the largest number of row writes held in memory at once
+func (s *ActivityStream) MaxBufferedWrites() int {
+	return s.maxPending
+}
+
+func (s *ActivityStream) queue(ctx context.Context, write pendingWrite) error {
+	s.pending = append(s.pending, write)
+	if len(s.pending) > s.maxPending {
+		s.maxPending = len(s.pending)
+	}
+	if len(s.pending) >= s.chunkSize {
+		return s.flushPending(ctx)
+	}
+	return nil
+}
+
+func (s *ActivityStream) flushPending(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	// Stop between chunks once the job is cancelled; the chunks already written stay
+	if err := ctx.Err(); err != nil {
+		return s.chunkError(err)
+	}
+	if err := s.writeChunk(ctx, s.pending); err != nil {
+		return s.chunkError(err)
+	}
+	s.result.ChunksWritten++
+	s.rowsWritten += len(s.pending)
+	s.writes.record(s.pending)
+	if err := s.verifyFlushed(ctx, s.pending); err != nil {
+		return err
+	}
+	// Reuse the buffer; the flushed writes are no longer referenced
+	for i := range s.pending {
+		s.pending[i] = pendingWrite{}
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// writeChunk writes a chunk through the retry hook, counting the extra attempts it took
+func (s *ActivityStream) writeChunk(ctx context.Context, writes []pendingWrite) error {
+	if s.retry == nil {
+		return s.flush(ctx, writes)
+	}
+	attempts := 0
+	err := s.retry(ctx, func() error {
+		attempts++
+		return s.flush(ctx, writes)
+	})
+	if attempts > 1 {
+		s.result.ChunkRetries += attempts - 1
+	}
+	return err
+}
+
+// chunkError reports a failed chunk along with the chunks written before it
+func (s *ActivityStream) chunkError(err error) error {
+	return &ChunkWriteError{
+		Chunk:         s.result.ChunksWritten + 1,
+		ChunksWritten: s.result.ChunksWritten,
+		RowsWritten:   s.rowsWritten,
+		Cause:         err,
+	}
+}
+
+// verifyFlushed reads back the flushed writes and rewrites the mismatched ones once. A failed
+// readback leaves the chunk unverified rather than failing the sync, since the writes succeeded.
+func (s *ActivityStream) verifyFlushed(ctx context.Context, writes []pendingWrite) error {
+	if s.verify == nil {
+		return nil
+	}
+	if s.result.Verification == nil {
+		s.result.Verification = &WriteVerification{Status: VerificationVerified}
+	}
+	v := s.result.Verification
+	v.RowsChecked += len(writes)
+
+	mismatched, err := s.verify(ctx, writes)
+	if err != nil {
+		v.record(VerificationUnverified)
+		return nil
+	}
+	if len(mismatched) == 0 {
+		return nil
+	}
+
+	v.RowsRetried += len(mismatched)
+	if err := s.writeChunk(ctx, mismatched); err != nil {
+		return err
+	}
+	remaining, err := s.verify(ctx, mismatched)
+	if err != nil {
+		v.record(VerificationUnverified)
+		return nil
+	}
+	if len(remaining) == 0 {
+		v.record(VerificationRetried)
+		return nil
+	}
+
+	v.RowsMismatched += len(remaining)
+	for _, write := range remaining {
+		v.MismatchedRows = append(v.MismatchedRows, write.rowNumber)
+	}
+	v.record(VerificationMismatch)
+	return nil
+}
+
+// rowHash fingerprints the engine-managed cells of a row so rows can be compared without keeping them
+func (l activityLayout) rowHash(row []interface{}) uint64 {
+	h := fnv.New64a()
+	for col := range l.template.Columns {
+		if l.template.IsManual(col) {
+			continue
+		}
+		h.Write([]byte(cellString(row, col)))
+		h.Write([]byte{0x1f})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,468 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// pagedActivities returns a source producing count synthetic activities in pages of pageSize,
+// generating each page on demand the way the Strava pagination does
+func pagedActivities(count, pageSize int) ActivityPageSource {
+	start := time.Date(2020, 1, 1, 7, 0, 0, 0, time.UTC)
+	return func(ctx context.Context, fn func(page []strava.Activity) error) error {
+		for offset := 0; offset < count; offset += pageSize {
+			page := make([]strava.Activity, 0, pageSize)
+			for i := offset; i < offset+pageSize && i < count; i++ {
+				page = append(page, strava.Activity{
+					ID:             int64(i + 1),
+					Name:           fmt.Sprintf("Run %d", i+1),
+					Type:           "Run",
+					Distance:       5000 + float64(i%100)*10,
+					MovingTime:     1500 + i%300,
+					StartDateLocal: start.Add(time.Duration(i) * 6 * time.Hour),
+				})
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestActivityStream_ChunkedWrites(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+
+	var flushes, written int
+	flush := func(ctx context.Context, writes []pendingWrite) error {
+		flushes++
+		written += len(writes)
+		return nil
+	}
+
+	stream := newActivityStream(layout, nil, 100, flush)
+	if err := stream.Consume(context.Background(), pagedActivities(1050, 100), 2); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	result, err := stream.Finish(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	if result.Appended != 1050 || written != 1050 {
+		t.Errorf("Expected 1050 appended rows, got %d appended and %d written", result.Appended, written)
+	}
+	if flushes != 11 {
+		t.Errorf("Expected 11 chunked writes, got %d", flushes)
+	}
+	if stream.MaxBufferedWrites() > 100 {
+		t.Errorf("Expected at most 100 buffered writes, got %d", stream.MaxBufferedWrites())
+	}
+}
+
+func TestActivityStream_ConsumeStopsOnWriteError(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	writeErr := errors.New("quota exceeded")
+
+	stream := newActivityStream(layout, nil, 10, func(ctx context.Context, writes []pendingWrite) error {
+		return writeErr
+	})
+
+	pagesFetched := 0
+	source := func(ctx context.Context, fn func(page []strava.Activity) error) error {
+		return pagedActivities(10000, 10)(ctx, func(page []strava.Activity) error {
+			pagesFetched++
+			return fn(page)
+		})
+	}
+
+	if err := stream.Consume(context.Background(), source, 1); !errors.Is(err, writeErr) {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+	if pagesFetched > 5 {
+		t.Errorf("Expected fetching to stop shortly after the write error, fetched %d pages", pagesFetched)
+	}
+}
+
+func TestActivityStream_StopsBetweenChunksWhenCancelled(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The job is cancelled while the first chunk is being written
+	flushes := 0
+	stream := newActivityStream(layout, nil, 10, func(ctx context.Context, writes []pendingWrite) error {
+		flushes++
+		cancel()
+		return nil
+	})
+
+	err := stream.Consume(ctx, pagedActivities(100, 10), 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation, got %v", err)
+	}
+	if flushes != 1 {
+		t.Errorf("Expected no chunk written after the cancellation, got %d flushes", flushes)
+	}
+}
+
+// retryTwice is a chunk retry hook that makes up to three attempts without waiting
+func retryTwice(ctx context.Context, write func() error) error {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if err = write(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func TestActivityStream_RetriesOnlyTheFailedChunk(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+
+	// The second chunk fails once
+	var chunkSizes []int
+	failed := false
+	stream := newActivityStream(layout, nil, 10, func(ctx context.Context, writes []pendingWrite) error {
+		chunkSizes = append(chunkSizes, len(writes))
+		if len(chunkSizes) == 2 && !failed {
+			failed = true
+			return errors.New("backend error")
+		}
+		return nil
+	})
+	stream.retry = retryTwice
+
+	if err := stream.Consume(context.Background(), pagedActivities(30, 10), 1); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	result, err := stream.Finish(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	if len(chunkSizes) != 4 {
+		t.Errorf("Expected 3 chunks and 1 retry, got %d writes", len(chunkSizes))
+	}
+	if result.ChunksWritten != 3 || result.ChunkRetries != 1 {
+		t.Errorf("Expected 3 chunks written with 1 retry, got %d chunks and %d retries", result.ChunksWritten, result.ChunkRetries)
+	}
+	if result.Appended != 30 {
+		t.Errorf("Expected 30 appended rows, got %d", result.Appended)
+	}
+}
+
+func TestActivityStream_ResumesAfterFailedChunk(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	sheet := &fakeSheet{rows: make(map[int][]interface{})}
+	writeErr := errors.New("quota exceeded")
+
+	// The third chunk keeps failing, so the first sync stops after two chunks
+	chunks := 0
+	stream := newActivityStream(layout, nil, 10, func(ctx context.Context, writes []pendingWrite) error {
+		chunks++
+		if chunks == 3 {
+			return writeErr
+		}
+		return sheet.flush(ctx, writes)
+	})
+	err := stream.Consume(context.Background(), pagedActivities(45, 10), 1)
+
+	var chunkErr *ChunkWriteError
+	if !errors.As(err, &chunkErr) || !errors.Is(err, writeErr) {
+		t.Fatalf("Expected a chunk write error wrapping the write error, got %v", err)
+	}
+	if chunkErr.Chunk != 3 || chunkErr.ChunksWritten != 2 || chunkErr.RowsWritten != 20 {
+		t.Errorf("Expected chunk 3 to fail after 2 chunks (20 rows), got %+v", chunkErr)
+	}
+
+	// Running the sync again skips the rows already written
+	existing := make([][]interface{}, len(sheet.rows))
+	for rowNumber, row := range sheet.rows {
+		existing[rowNumber-2] = row
+	}
+	var rewritten int
+	stream = newActivityStream(layout, existing, 10, func(ctx context.Context, writes []pendingWrite) error {
+		rewritten += len(writes)
+		return sheet.flush(ctx, writes)
+	})
+	if err := stream.Consume(context.Background(), pagedActivities(45, 10), 1); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	result, err := stream.Finish(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	if result.Unchanged != 20 || result.Appended != 25 || rewritten != 25 {
+		t.Errorf("Expected 20 unchanged and 25 appended rows, got %d unchanged, %d appended and %d written",
+			result.Unchanged, result.Appended, rewritten)
+	}
+	if len(sheet.rows) != 45 {
+		t.Errorf("Expected 45 rows in the sheet, got %d", len(sheet.rows))
+	}
+}
+
+// BenchmarkActivityStream measures streaming backfills into a sheet that already holds the
+// activities. Retained heap after the backfill stays at the size of the row index, and the
+// buffered writes never exceed one chunk, however many activities are streamed.
+func BenchmarkActivityStream(b *testing.B) {
+	for _, count := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("activities=%d", count), func(b *testing.B) {
+			layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+
+			// Half the activities already exist in the sheet, half of those with stale values
+			var existing [][]interface{}
+			_ = pagedActivities(count/2, 100)(context.Background(), func(page []strava.Activity) error {
+				for _, activity := range page {
+					if activity.ID%2 == 0 {
+						activity.Name += " (old)"
+					}
+					existing = append(existing, layout.template.Row(activity))
+				}
+				return nil
+			})
+
+			discard := func(ctx context.Context, writes []pendingWrite) error { return nil }
+
+			var maxBuffered int
+			var before, after runtime.MemStats
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				stream := newActivityStream(layout, existing, DefaultStreamChunkSize, discard)
+				if err := stream.Consume(context.Background(), pagedActivities(count, 100), DefaultStreamPageBuffer); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := stream.Finish(context.Background(), time.Time{}); err != nil {
+					b.Fatal(err)
+				}
+
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				if stream.MaxBufferedWrites() > maxBuffered {
+					maxBuffered = stream.MaxBufferedWrites()
+				}
+				runtime.KeepAlive(stream)
+			}
+
+			b.ReportMetric(float64(maxBuffered), "max-buffered-writes")
+			b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(count), "retained-B/activity")
+		})
+	}
+}
+
+func TestActivityStream_ChronologicalOrder(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	row := func(id int64, date string) []interface{} {
+		return layout.template.Row(strava.Activity{ID: id, Name: "Run", Type: "Run", StartDateLocal: mustDate(t, date)})
+	}
+	existing := [][]interface{}{row(1, "2024-06-01"), row(2, "2024-06-10")}
+	noFlush := func(ctx context.Context, writes []pendingWrite) error { return nil }
+
+	tests := []struct {
+		name       string
+		appended   []string
+		expectSort bool
+	}{
+		{"Newer activities keep the append order", []string{"2024-06-11", "2024-06-12"}, false},
+		{"Late upload of an older activity", []string{"2024-06-12", "2024-06-05"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sortedThrough int
+			stream := newActivityStream(layout, existing, 100, noFlush)
+			stream.sort = func(ctx context.Context, lastRow int) error {
+				sortedThrough = lastRow
+				return nil
+			}
+
+			for i, date := range tt.appended {
+				stream.addRow(context.Background(), int64(10+i), row(int64(10+i), date))
+			}
+			result, err := stream.Finish(context.Background(), time.Time{})
+			if err != nil {
+				t.Fatalf("Finish failed: %v", err)
+			}
+
+			if result.Sorted != tt.expectSort {
+				t.Errorf("Expected sorted %v, got %v", tt.expectSort, result.Sorted)
+			}
+			if tt.expectSort && sortedThrough != 5 {
+				t.Errorf("Expected rows 2-5 to be sorted, got last row %d", sortedThrough)
+			}
+		})
+	}
+}
+
+func TestSortRequest(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	request := layout.sortRequest(42, 12).SortRange
+
+	if request.Range.SheetId != 42 || request.Range.StartRowIndex != 1 || request.Range.EndRowIndex != 12 {
+		t.Errorf("Unexpected sort range: %+v", request.Range)
+	}
+	// A column the user added right of the template (index len(Columns)) must move with its row,
+	// so the range is left unbounded on the right rather than ending at the template
+	body, err := json.Marshal(request.Range)
+	if err != nil {
+		t.Fatalf("Failed to encode sort range: %v", err)
+	}
+	if strings.Contains(string(body), "endColumnIndex") {
+		t.Errorf("Expected the sort to move whole rows including user column %d, got range %s", len(layout.template.Columns), body)
+	}
+	if len(request.SortSpecs) == 0 || request.SortSpecs[0].DimensionIndex != int64(layout.dateColumn) {
+		t.Errorf("Expected the rows to be sorted by date first: %+v", request.SortSpecs)
+	}
+}
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("Invalid date %q: %v", value, err)
+	}
+	return date
+}
+
+// fakeSheet stores written rows by row number; corrupt may alter a row as it is written
+type fakeSheet struct {
+	rows    map[int][]interface{}
+	corrupt func(rowNumber int, row []interface{}) []interface{}
+}
+
+func (f *fakeSheet) flush(ctx context.Context, writes []pendingWrite) error {
+	for _, write := range writes {
+		row := append([]interface{}(nil), write.valueRange.Values[0]...)
+		if f.corrupt != nil {
+			row = f.corrupt(write.rowNumber, row)
+		}
+		f.rows[write.rowNumber] = row
+	}
+	return nil
+}
+
+func (f *fakeSheet) verify(ctx context.Context, writes []pendingWrite) ([]pendingWrite, error) {
+	first, last := writes[0].rowNumber, writes[0].rowNumber
+	for _, write := range writes {
+		first, last = min(first, write.rowNumber), max(last, write.rowNumber)
+	}
+	rows := make([][]interface{}, 0, last-first+1)
+	for row := first; row <= last; row++ {
+		rows = append(rows, f.rows[row])
+	}
+	return mismatchedWrites(writes, rows, first), nil
+}
+
+func TestActivityStream_ReadbackVerification(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	truncate := func(row []interface{}) []interface{} {
+		row[layout.nameColumn] = "Ru"
+		return row
+	}
+
+	tests := []struct {
+		name           string
+		corrupt        func() func(rowNumber int, row []interface{}) []interface{}
+		wantStatus     string
+		wantRetried    int
+		wantMismatched []int
+	}{
+		{
+			name:       "rows match",
+			wantStatus: VerificationVerified,
+		},
+		{
+			name: "transient truncation is fixed by the retry",
+			corrupt: func() func(int, []interface{}) []interface{} {
+				truncated := false
+				return func(rowNumber int, row []interface{}) []interface{} {
+					if rowNumber == 4 && !truncated {
+						truncated = true
+						return truncate(row)
+					}
+					return row
+				}
+			},
+			wantStatus:  VerificationRetried,
+			wantRetried: 1,
+		},
+		{
+			name: "persistent truncation is reported",
+			corrupt: func() func(int, []interface{}) []interface{} {
+				return func(rowNumber int, row []interface{}) []interface{} {
+					if rowNumber == 4 {
+						return truncate(row)
+					}
+					return row
+				}
+			},
+			wantStatus:     VerificationMismatch,
+			wantRetried:    1,
+			wantMismatched: []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := &fakeSheet{rows: make(map[int][]interface{})}
+			if tt.corrupt != nil {
+				sheet.corrupt = tt.corrupt()
+			}
+
+			stream := newActivityStream(layout, nil, 2, sheet.flush)
+			stream.verify = sheet.verify
+			if err := stream.Consume(context.Background(), pagedActivities(5, 5), 1); err != nil {
+				t.Fatalf("Consume failed: %v", err)
+			}
+			result, err := stream.Finish(context.Background(), time.Time{})
+			if err != nil {
+				t.Fatalf("Finish failed: %v", err)
+			}
+
+			v := result.Verification
+			if v == nil {
+				t.Fatal("Expected a verification report")
+			}
+			if v.Status != tt.wantStatus || v.RowsChecked != 5 || v.RowsRetried != tt.wantRetried {
+				t.Errorf("Expected %s with 5 checked and %d retried, got %+v", tt.wantStatus, tt.wantRetried, v)
+			}
+			if fmt.Sprint(v.MismatchedRows) != fmt.Sprint(tt.wantMismatched) || v.RowsMismatched != len(tt.wantMismatched) {
+				t.Errorf("Expected mismatched rows %v, got %+v", tt.wantMismatched, v)
+			}
+		})
+	}
+}
+
+func TestActivityStream_ReadbackFailureLeavesRowsUnverified(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	sheet := &fakeSheet{rows: make(map[int][]interface{})}
+
+	stream := newActivityStream(layout, nil, 10, sheet.flush)
+	stream.verify = func(ctx context.Context, writes []pendingWrite) ([]pendingWrite, error) {
+		return nil, errors.New("read quota exceeded")
+	}
+	if err := stream.Consume(context.Background(), pagedActivities(3, 3), 1); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	result, err := stream.Finish(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Expected the sync to succeed despite the failed readback, got %v", err)
+	}
+	if result.Appended != 3 || result.Verification == nil || result.Verification.Status != VerificationUnverified {
+		t.Errorf("Expected 3 appended rows left unverified, got %+v", result)
+	}
+}
@@ -0,0 +1,385 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+const (
+	// activitiesSheetTitle is the default tab the automation writes activity rows to (row 1 is the
+	// header); see WithActivitySheet
+	activitiesSheetTitle = "Sheet1"
+
+	// DeletedActivityMarker prefixes the name of rows whose activity no longer exists on Strava
+	DeletedActivityMarker = "[Deleted on Strava] "
+)
+
+// Row write actions reported by a sync preview
+const (
+	RowActionAppend      = "append"
+	RowActionUpdate      = "update"
+	RowActionFlagDeleted = "flag_deleted"
+)
+
+// ActivitySyncResult summarizes how fetched activities were reconciled against the sheet
+type ActivitySyncResult struct {
+	Appended           int     `json:"appended"`
+	Updated            int     `json:"updated"`
+	Unchanged          int     `json:"unchanged"`
+	DeletedActivityIDs []int64 `json:"deleted_activity_ids,omitempty"`
+
+	// AppendedActivityIDs lists activities that got a new row, i.e. were synced for the first time
+	AppendedActivityIDs []int64 `json:"appended_activity_ids,omitempty"`
+
+	// Sorted reports that the rows were re-sorted by date because an older activity was appended
+	Sorted bool `json:"sorted,omitempty"`
+
+	// ChunksWritten counts the batchUpdate calls that wrote rows; ChunkRetries counts the extra
+	// attempts transient errors cost them
+	ChunksWritten int `json:"chunks_written,omitempty"`
+	ChunkRetries  int `json:"chunk_retries,omitempty"`
+
+	// Verification reports the readback of the written rows; nil when readback verification is off
+	Verification *WriteVerification `json:"verification,omitempty"`
+
+	// Writes records the rows the sync wrote so they can be undone; nil for previews
+	Writes *SheetWrites `json:"-"`
+}
+
+// PlannedRowWrite is a single row write a sync would perform
+type PlannedRowWrite struct {
+	Action string        `json:"action"`
+	Range  string        `json:"range"`
+	Values []interface{} `json:"values"`
+}
+
+// ActivitySyncPreview is the outcome of a sync that was planned but not written
+type ActivitySyncPreview struct {
+	ActivitySyncResult
+	Rows []PlannedRowWrite `json:"rows"`
+}
+
+// activityLayout locates the columns the sync logic relies on within a template's activity rows
+type activityLayout struct {
+	template         *templates.Template
+	sheet            string
+	dateColumn       int
+	nameColumn       int
+	typeColumn       int
+	activityIDColumn int
+}
+
+func newActivityLayout(template *templates.Template) activityLayout {
+	return activityLayout{
+		template:         template,
+		sheet:            activitiesSheetTitle,
+		dateColumn:       template.ColumnIndex(templates.FieldDate),
+		nameColumn:       template.ColumnIndex(templates.FieldName),
+		typeColumn:       template.ColumnIndex(templates.FieldType),
+		activityIDColumn: template.ColumnIndex(templates.FieldActivityID),
+	}
+}
+
+// activityLayout is the layout of the client's activities tab
+func (c *SheetsClient) activityLayout() activityLayout {
+	layout := newActivityLayout(c.template)
+	layout.sheet = c.activitySheet
+	return layout
+}
+
+// readRange is the A1 range holding all activity rows below the header
+func (l activityLayout) readRange() string {
+	return sheetRange(l.sheet, "A2:"+l.template.LastColumn())
+}
+
+// sheetRange builds the A1 notation of cells on the tab with the given title, quoting titles that
+// are not plain words, e.g. 'Ana Lopez (7)'!A1
+func sheetRange(title, cells string) string {
+	for _, r := range title {
+		if !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return "'" + strings.ReplaceAll(title, "'", "''") + "'!" + cells
+		}
+	}
+	return title + "!" + cells
+}
+
+// activitySyncPlan is the set of row writes needed to bring the sheet in line with Strava
+type activitySyncPlan struct {
+	writes  []*sheets.ValueRange
+	actions []string
+	result  ActivitySyncResult
+}
+
+// SyncActivities reconciles Strava activities with the rows already in the spreadsheet
+// Rows are matched by the activity ID column: changed activities are updated in place, new ones are
+// appended, and rows dated after windowStart whose activity was not returned by Strava are flagged
+// as deleted. A zero windowStart disables deletion detection. Writes are sent in chunks of
+// DefaultStreamChunkSize rows; see ActivityStream for syncing activities as they are fetched.
+func (c *SheetsClient) SyncActivities(ctx context.Context, spreadsheetID string, activities []strava.Activity, windowStart time.Time) (*ActivitySyncResult, error) {
+	startTime := time.Now()
+	c.log(ctx).Debug("Reconciling activities with Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"activity_count", len(activities),
+		"window_start", windowStart)
+
+	if len(activities) == 0 && windowStart.IsZero() {
+		c.log(ctx).Debug("No activities to write to spreadsheet",
+			"user_id", c.userID,
+			"spreadsheet_id", spreadsheetID)
+		return &ActivitySyncResult{}, nil
+	}
+
+	stream, err := c.NewActivityStream(ctx, spreadsheetID, DefaultStreamChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Add(ctx, activities); err != nil {
+		return nil, err
+	}
+	result, err := stream.Finish(ctx, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log(ctx).Info("Successfully reconciled activities with Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"activity_count", len(activities),
+		"rows_appended", result.Appended,
+		"rows_updated", result.Updated,
+		"rows_unchanged", result.Unchanged,
+		"rows_flagged_deleted", len(result.DeletedActivityIDs),
+		"write_duration_ms", time.Since(startTime).Milliseconds())
+
+	return result, nil
+}
+
+// PreviewActivitySync computes the writes SyncActivities would perform without modifying the spreadsheet
+func (c *SheetsClient) PreviewActivitySync(ctx context.Context, spreadsheetID string, activities []strava.Activity, windowStart time.Time) (*ActivitySyncPreview, error) {
+	c.log(ctx).Debug("Previewing activity sync with Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"activity_count", len(activities),
+		"window_start", windowStart)
+
+	preview := &ActivitySyncPreview{Rows: []PlannedRowWrite{}}
+	if len(activities) == 0 && windowStart.IsZero() {
+		return preview, nil
+	}
+
+	plan, err := c.planSync(ctx, spreadsheetID, activities, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.ActivitySyncResult = plan.result
+	for i, write := range plan.writes {
+		preview.Rows = append(preview.Rows, PlannedRowWrite{
+			Action: plan.actions[i],
+			Range:  write.Range,
+			Values: write.Values[0],
+		})
+	}
+
+	return preview, nil
+}
+
+// planSync reads the existing activity rows and plans the writes for the given activities
+func (c *SheetsClient) planSync(ctx context.Context, spreadsheetID string, activities []strava.Activity, windowStart time.Time) (*activitySyncPlan, error) {
+	// Ensure we have a valid token and service
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	layout := c.activityLayout()
+	existing, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, layout.readRange()).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, c.handleSheetsAPIError(ctx, err, "read existing activities", spreadsheetID)
+	}
+
+	rows := c.convertActivitiesToRows(activities)
+	plan := planActivitySync(layout, existing.Values, activities, rows, windowStart)
+	return &plan, nil
+}
+
+// planActivitySync compares existing sheet rows (starting at row 2) with freshly converted rows
+// Manual columns of the template are never compared or written, so user notes survive updates.
+// It runs the same reconciliation as ActivityStream but collects the writes instead of sending them.
+func planActivitySync(layout activityLayout, existing [][]interface{}, activities []strava.Activity, rows [][]interface{}, windowStart time.Time) activitySyncPlan {
+	var plan activitySyncPlan
+	collect := func(ctx context.Context, writes []pendingWrite) error {
+		for _, write := range writes {
+			plan.writes = append(plan.writes, write.valueRange)
+			plan.actions = append(plan.actions, write.action)
+		}
+		return nil
+	}
+
+	// Collecting never fails, so neither do the stream operations
+	ctx := context.Background()
+	stream := newActivityStream(layout, existing, len(activities)+len(existing)+1, collect)
+	for i, activity := range activities {
+		stream.addRow(ctx, activity.ID, rows[i])
+	}
+	result, _ := stream.Finish(ctx, windowStart)
+	plan.result = *result
+
+	return plan
+}
+
+// sortActivityRows sorts rows 2..lastRow of the activities tab by date, then activity ID.
+// Whole rows move, so manual columns stay with their activity.
+func (c *SheetsClient) sortActivityRows(ctx context.Context, spreadsheetID string, layout activityLayout, lastRow int) error {
+	sheetID, err := c.sheetID(ctx, spreadsheetID, layout.sheet)
+	if err != nil {
+		return err
+	}
+
+	request := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{layout.sortRequest(sheetID, lastRow)},
+	}
+	if _, err := c.sheetsService.Spreadsheets.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+		return c.handleSheetsAPIError(ctx, err, "sort activities", spreadsheetID)
+	}
+
+	c.log(ctx).Info("Sorted activity rows chronologically",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"last_row", lastRow)
+	return nil
+}
+
+// sortRequest sorts the activity rows below the header, up to lastRow (1-based, inclusive). The
+// range has no end column, so whole rows move and columns users added to the right of the template
+// stay with their activities.
+func (l activityLayout) sortRequest(sheetID int64, lastRow int) *sheets.Request {
+	specs := []*sheets.SortSpec{{DimensionIndex: int64(l.dateColumn), SortOrder: "ASCENDING"}}
+	if l.activityIDColumn >= 0 {
+		specs = append(specs, &sheets.SortSpec{DimensionIndex: int64(l.activityIDColumn), SortOrder: "ASCENDING"})
+	}
+
+	return &sheets.Request{
+		SortRange: &sheets.SortRangeRequest{
+			Range: &sheets.GridRange{
+				SheetId:          sheetID,
+				StartRowIndex:    1, // Keep the header row in place
+				EndRowIndex:      int64(lastRow),
+				StartColumnIndex: 0,
+				ForceSendFields:  []string{"SheetId", "StartColumnIndex"},
+			},
+			SortSpecs: specs,
+		},
+	}
+}
+
+// rowRange builds the write for a single activity row
+func (l activityLayout) rowRange(rowNumber int, row []interface{}) *sheets.ValueRange {
+	last := l.template.LastColumn()
+	return &sheets.ValueRange{
+		Range:  sheetRange(l.sheet, fmt.Sprintf("A%d:%s%d", rowNumber, last, rowNumber)),
+		Values: [][]interface{}{row},
+	}
+}
+
+// managedValues replaces manual columns with nil so writes leave user-entered cells untouched
+func (l activityLayout) managedValues(row []interface{}) []interface{} {
+	values := make([]interface{}, len(row))
+	for col := range row {
+		if !l.template.IsManual(col) {
+			values[col] = row[col]
+		}
+	}
+	return values
+}
+
+// rowActivityID parses the activity ID column of a sheet row
+func (l activityLayout) rowActivityID(row []interface{}) (int64, bool) {
+	value := cellString(row, l.activityIDColumn)
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// legacyRowKey identifies rows written before the activity ID column was introduced
+func (l activityLayout) legacyRowKey(row []interface{}) string {
+	date := cellString(row, l.dateColumn)
+	if date == "" {
+		return ""
+	}
+	return strings.Join([]string{date, cellString(row, l.nameColumn), cellString(row, l.typeColumn)}, "|")
+}
+
+// cellString returns the displayed value of a cell, ignoring the text-forcing apostrophe
+func cellString(row []interface{}, col int) string {
+	if col < 0 || col >= len(row) || row[col] == nil {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprint(row[col]), "'")
+}
+
+// ReadActivityCells reads the activities tab and returns the cells of the rows holding the given
+// activities, keyed by activity ID and template field. Manual columns are left out, and activities
+// without a row are absent from the result.
+func (c *SheetsClient) ReadActivityCells(ctx context.Context, spreadsheetID string, activityIDs []int64) (map[int64]map[templates.Field]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	layout := c.activityLayout()
+	stored, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, layout.readRange()).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, c.handleSheetsAPIError(ctx, err, "read stored activities", spreadsheetID)
+	}
+
+	return activityCells(layout, stored.Values, activityIDs), nil
+}
+
+// activityCells picks the rows holding activityIDs out of the sheet rows and maps their managed
+// cells by field; when an activity has several rows the first one wins, as it does for updates
+func activityCells(layout activityLayout, rows [][]interface{}, activityIDs []int64) map[int64]map[templates.Field]string {
+	wanted := make(map[int64]bool, len(activityIDs))
+	for _, id := range activityIDs {
+		wanted[id] = true
+	}
+
+	cells := make(map[int64]map[templates.Field]string, len(activityIDs))
+	for _, row := range rows {
+		id, ok := layout.rowActivityID(row)
+		if !ok || !wanted[id] {
+			continue
+		}
+		if _, seen := cells[id]; seen {
+			continue
+		}
+
+		fields := make(map[templates.Field]string, len(layout.template.Columns))
+		for col, column := range layout.template.Columns {
+			if layout.template.IsManual(col) {
+				continue
+			}
+			if _, set := fields[column.Field]; !set {
+				fields[column.Field] = cellString(row, col)
+			}
+		}
+		cells[id] = fields
+	}
+	return cells
+}
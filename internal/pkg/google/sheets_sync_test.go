@@ -0,0 +1,184 @@
+package google
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+func TestPlanActivitySync(t *testing.T) {
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"))
+
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 7, 0, 0, 0, time.UTC) }
+	unchanged := strava.Activity{ID: 101, Name: "Easy Run", Type: "Run", Distance: 5000, MovingTime: 1500, StartDateLocal: day(3)}
+	edited := strava.Activity{ID: 102, Name: "Tempo Run (edited)", Type: "Run", Distance: 8000, MovingTime: 2400, StartDateLocal: day(4)}
+	added := strava.Activity{ID: 104, Name: "Long Run", Type: "Run", Distance: 20000, MovingTime: 6600, StartDateLocal: day(6)}
+
+	// Build the existing sheet from the same converter so unchanged rows compare equal
+	existingActivities := []strava.Activity{
+		unchanged,
+		{ID: 102, Name: "Tempo Run", Type: "Run", Distance: 8000, MovingTime: 2400, StartDateLocal: day(4)},
+		{ID: 103, Name: "Deleted Ride", Type: "Ride", Distance: 30000, MovingTime: 3600, StartDateLocal: day(5)},
+		{ID: 90, Name: "Old Run", Type: "Run", Distance: 5000, MovingTime: 1500, StartDateLocal: day(1)},
+	}
+	existing := client.convertActivitiesToRows(existingActivities)
+
+	activities := []strava.Activity{unchanged, edited, added}
+	rows := client.convertActivitiesToRows(activities)
+
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	plan := planActivitySync(layout, existing, activities, rows, day(2))
+
+	if plan.result.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged row, got %d", plan.result.Unchanged)
+	}
+	if plan.result.Updated != 1 {
+		t.Errorf("Expected 1 updated row, got %d", plan.result.Updated)
+	}
+	if plan.result.Appended != 1 {
+		t.Errorf("Expected 1 appended row, got %d", plan.result.Appended)
+	}
+	// Activity 90 is before the window and must not be flagged
+	if len(plan.result.DeletedActivityIDs) != 1 || plan.result.DeletedActivityIDs[0] != 103 {
+		t.Fatalf("Expected activity 103 flagged as deleted, got %v", plan.result.DeletedActivityIDs)
+	}
+
+	ranges := map[string]string{}
+	actions := map[string]string{}
+	for i, write := range plan.writes {
+		ranges[write.Range] = cellString(write.Values[0], layout.nameColumn)
+		actions[write.Range] = plan.actions[i]
+	}
+
+	expectedActions := map[string]string{
+		"Sheet1!A3:J3": RowActionUpdate,
+		"Sheet1!A6:J6": RowActionAppend,
+		"Sheet1!A4:J4": RowActionFlagDeleted,
+	}
+	for rng, action := range expectedActions {
+		if actions[rng] != action {
+			t.Errorf("Expected %s action for %s, got %q", action, rng, actions[rng])
+		}
+	}
+
+	if ranges["Sheet1!A3:J3"] != "Tempo Run (edited)" {
+		t.Errorf("Expected edited activity updated in place at row 3, got writes %v", ranges)
+	}
+	if ranges["Sheet1!A6:J6"] != "Long Run" {
+		t.Errorf("Expected new activity appended at row 6, got writes %v", ranges)
+	}
+	if ranges["Sheet1!A4:J4"] != DeletedActivityMarker+"Deleted Ride" {
+		t.Errorf("Expected deleted activity flagged at row 4, got writes %v", ranges)
+	}
+}
+
+func TestPlanActivitySync_LegacyRowsAndFlaggedRows(t *testing.T) {
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"))
+	activity := strava.Activity{ID: 200, Name: "Morning Run", Type: "Run", Distance: 5000, MovingTime: 1500,
+		StartDateLocal: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)}
+
+	rows := client.convertActivitiesToRows([]strava.Activity{activity})
+
+	// A row written before the activity ID column existed (no column J)
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	legacy := append([]interface{}{}, rows[0][:layout.activityIDColumn]...)
+	// A row already flagged as deleted must not be flagged again
+	flagged := []interface{}{"2024-06-04", DeletedActivityMarker + "Gone", "Run", "", "", "", "", "", "0", "'201"}
+
+	plan := planActivitySync(layout, [][]interface{}{legacy, flagged}, []strava.Activity{activity}, rows, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if plan.result.Appended != 0 || plan.result.Updated != 1 {
+		t.Errorf("Expected legacy row to be matched and updated with its ID, got %+v", plan.result)
+	}
+	if len(plan.result.DeletedActivityIDs) != 0 {
+		t.Errorf("Expected already flagged row to be left alone, got %v", plan.result.DeletedActivityIDs)
+	}
+	if len(plan.writes) != 1 || plan.writes[0].Range != "Sheet1!A2:J2" {
+		t.Errorf("Expected a single write to row 2, got %d writes", len(plan.writes))
+	}
+}
+
+func TestPlanActivitySync_PreservesManualColumns(t *testing.T) {
+	template := templates.GetOrDefault(templates.CoachPlan)
+	client := NewSheetsClient(1, "refresh-token", logger.New("test"), WithTemplate(template))
+	layout := newActivityLayout(template)
+
+	activity := strava.Activity{ID: 300, Name: "Intervals", Type: "Run", Distance: 8000, MovingTime: 2400,
+		StartDateLocal: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)}
+	rows := client.convertActivitiesToRows([]strava.Activity{activity})
+
+	// The coach filled in the planned session and a comment; the activity is otherwise unchanged
+	existing := append([]interface{}{}, rows[0]...)
+	existing[1] = "6x800m"
+	existing[9] = "Great pacing"
+
+	plan := planActivitySync(layout, [][]interface{}{existing}, []strava.Activity{activity}, rows, time.Time{})
+	if plan.result.Unchanged != 1 || len(plan.writes) != 0 {
+		t.Fatalf("Expected manual edits to be ignored when comparing, got %+v", plan.result)
+	}
+
+	// An edit on Strava rewrites managed cells only
+	edited := activity
+	edited.Name = "Intervals (edited)"
+	rows = client.convertActivitiesToRows([]strava.Activity{edited})
+	plan = planActivitySync(layout, [][]interface{}{existing}, []strava.Activity{edited}, rows, time.Time{})
+	if plan.result.Updated != 1 || len(plan.writes) != 1 {
+		t.Fatalf("Expected a single update, got %+v", plan.result)
+	}
+
+	write := plan.writes[0]
+	if write.Range != "Sheet1!A2:K2" {
+		t.Errorf("Expected write to the coach plan range A2:K2, got %s", write.Range)
+	}
+	for col, value := range write.Values[0] {
+		if template.IsManual(col) && value != nil {
+			t.Errorf("Expected manual column %d to be skipped, got %v", col, value)
+		}
+	}
+}
+
+func TestMissingHeaderCells(t *testing.T) {
+	header := []interface{}{"Date", "Name", "Type", "Activity ID"}
+
+	missing, ok := missingHeaderCells(nil, header)
+	if !ok || len(missing) != 4 || missing[0] != "Date" {
+		t.Errorf("Expected the full header for an empty row, got %v", missing)
+	}
+
+	// A renamed header is kept; the column added later is filled in
+	missing, ok = missingHeaderCells([]interface{}{"Day", "Name", "Type"}, header)
+	if !ok || missing[0] != nil || missing[3] != "Activity ID" {
+		t.Errorf("Expected only the missing column, got %v", missing)
+	}
+
+	if _, ok := missingHeaderCells([]interface{}{"Day", "Name", "Type", "ID"}, header); ok {
+		t.Error("Expected no write for a complete header")
+	}
+}
+
+func TestActivityCells(t *testing.T) {
+	template := templates.GetOrDefault(templates.CoachPlan)
+	layout := newActivityLayout(template)
+
+	stored := template.Row(strava.Activity{ID: 301, Name: "Intervals", Type: "Run", Distance: 10000, MovingTime: 2700})
+	stored[template.ColumnIndex(templates.FieldManual)] = "6x800m"
+	rows := [][]interface{}{
+		template.Row(strava.Activity{ID: 300, Name: "Not Synced Now", Type: "Run"}),
+		stored,
+		template.Row(strava.Activity{ID: 301, Name: "Duplicate Row", Type: "Run"}),
+	}
+
+	cells := activityCells(layout, rows, []int64{301, 302})
+	if len(cells) != 1 {
+		t.Fatalf("Expected cells of activity 301 only, got %v", cells)
+	}
+	if cells[301][templates.FieldName] != "Intervals" || cells[301][templates.FieldActivityID] != "301" {
+		t.Errorf("Expected the first row of activity 301, got %v", cells[301])
+	}
+	if _, ok := cells[301][templates.FieldManual]; ok {
+		t.Errorf("Expected manual columns to be left out, got %v", cells[301])
+	}
+}
@@ -0,0 +1,209 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// WrittenRow is a row write a sync performed
+type WrittenRow struct {
+	Action     string `json:"action"`
+	ActivityID int64  `json:"activity_id"`
+	Range      string `json:"range"`
+
+	// PreviousName is the name cell before the row was flagged as deleted; flag_deleted rows only
+	PreviousName string `json:"previous_name,omitempty"`
+}
+
+// SheetWrites records the rows a sync wrote to a spreadsheet's activities tab so the sync can be
+// undone. Rows are found again by activity ID when undoing, because sorting and later syncs move
+// them away from the range they were written to.
+type SheetWrites struct {
+	SpreadsheetID    string       `json:"spreadsheet_id"`
+	Sheet            string       `json:"sheet"`
+	ActivityIDColumn int          `json:"activity_id_column"`
+	NameColumn       int          `json:"name_column"`
+	Rows             []WrittenRow `json:"rows"`
+}
+
+// UndoPlan counts what undoing a sync's writes changes in the sheet
+type UndoPlan struct {
+	RowsToDelete int `json:"rows_to_delete"`
+	FlagsToClear int `json:"flags_to_clear"`
+	// UpdatesKept counts rows the sync updated in place. Only a hash of their previous cells was
+	// retained, and the new values mirror Strava, so undo leaves them as they are.
+	UpdatesKept int `json:"updates_kept"`
+}
+
+// UndoResult summarizes an undo of a sync's writes
+type UndoResult struct {
+	RowsDeleted  int `json:"rows_deleted"`
+	FlagsCleared int `json:"flags_cleared"`
+	UpdatesKept  int `json:"updates_kept"`
+	// RowsMissing counts written rows no longer in the sheet, e.g. removed by hand since the sync
+	RowsMissing int `json:"rows_missing"`
+}
+
+// sheetWrites starts the record of the writes made to the layout's tab
+func (l activityLayout) sheetWrites(spreadsheetID string) *SheetWrites {
+	return &SheetWrites{
+		SpreadsheetID:    spreadsheetID,
+		Sheet:            l.sheet,
+		ActivityIDColumn: l.activityIDColumn,
+		NameColumn:       l.nameColumn,
+	}
+}
+
+// record appends flushed writes; a nil record ignores them
+func (w *SheetWrites) record(writes []pendingWrite) {
+	if w == nil {
+		return
+	}
+	for _, write := range writes {
+		w.Rows = append(w.Rows, WrittenRow{
+			Action:       write.action,
+			ActivityID:   write.activityID,
+			Range:        write.valueRange.Range,
+			PreviousName: write.previousName,
+		})
+	}
+}
+
+// Plan counts what undoing the writes would change
+func (w *SheetWrites) Plan() UndoPlan {
+	var plan UndoPlan
+	for _, row := range w.Rows {
+		switch row.Action {
+		case RowActionAppend:
+			plan.RowsToDelete++
+		case RowActionFlagDeleted:
+			plan.FlagsToClear++
+		case RowActionUpdate:
+			plan.UpdatesKept++
+		}
+	}
+	return plan
+}
+
+// UndoWrites reverts the rows a sync wrote: appended rows are deleted and rows flagged as deleted
+// get their previous name back. Updated rows are kept (see UndoPlan). Rows are located by activity
+// ID; flags the user already cleared are left alone.
+func (c *SheetsClient) UndoWrites(ctx context.Context, writes *SheetWrites) (*UndoResult, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+	if writes.ActivityIDColumn < 0 {
+		return nil, fmt.Errorf("sync wrote no activity ID column, so its rows cannot be located")
+	}
+
+	spreadsheetID := writes.SpreadsheetID
+	lastColumn := templates.ColumnLetter(max(writes.ActivityIDColumn, writes.NameColumn))
+	existing, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, sheetRange(writes.Sheet, "A2:"+lastColumn)).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, c.handleSheetsAPIError(ctx, err, "read activities to undo", spreadsheetID)
+	}
+
+	idLayout := activityLayout{activityIDColumn: writes.ActivityIDColumn}
+	rowsByID := make(map[int64]int, len(existing.Values))
+	for i, row := range existing.Values {
+		id, ok := idLayout.rowActivityID(row)
+		if _, seen := rowsByID[id]; ok && !seen {
+			rowsByID[id] = i
+		}
+	}
+
+	result := &UndoResult{}
+	var restores []*sheets.ValueRange
+	var deletes []int
+	for _, written := range writes.Rows {
+		if written.Action == RowActionUpdate {
+			result.UpdatesKept++
+			continue
+		}
+		index, ok := rowsByID[written.ActivityID]
+		if !ok {
+			result.RowsMissing++
+			continue
+		}
+
+		switch written.Action {
+		case RowActionAppend:
+			deletes = append(deletes, index)
+		case RowActionFlagDeleted:
+			if !strings.HasPrefix(cellString(existing.Values[index], writes.NameColumn), DeletedActivityMarker) {
+				continue
+			}
+			// As when flagging, only the name cell changes; nil cells are left untouched
+			restored := make([]interface{}, writes.NameColumn+1)
+			restored[writes.NameColumn] = written.PreviousName
+			rowNumber := index + 2
+			restores = append(restores, &sheets.ValueRange{
+				Range:  sheetRange(writes.Sheet, fmt.Sprintf("A%d:%s%d", rowNumber, templates.ColumnLetter(writes.NameColumn), rowNumber)),
+				Values: [][]interface{}{restored},
+			})
+		}
+	}
+
+	// Names are restored first, while the row numbers read above still hold
+	if len(restores) > 0 {
+		request := &sheets.BatchUpdateValuesRequest{ValueInputOption: "USER_ENTERED", Data: restores}
+		if _, err := c.sheetsService.Spreadsheets.Values.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+			return nil, c.handleSheetsAPIError(ctx, err, "restore flagged activities", spreadsheetID)
+		}
+		result.FlagsCleared = len(restores)
+	}
+
+	if len(deletes) > 0 {
+		sheetID, err := c.sheetID(ctx, spreadsheetID, writes.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		request := &sheets.BatchUpdateSpreadsheetRequest{Requests: deleteRowRequests(sheetID, deletes)}
+		if _, err := c.sheetsService.Spreadsheets.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+			return nil, c.handleSheetsAPIError(ctx, err, "delete appended activities", spreadsheetID)
+		}
+		result.RowsDeleted = len(deletes)
+	}
+
+	c.log(ctx).Info("Undid sync writes in Google Spreadsheet",
+		"user_id", c.userID,
+		"spreadsheet_id", spreadsheetID,
+		"rows_deleted", result.RowsDeleted,
+		"flags_cleared", result.FlagsCleared,
+		"updates_kept", result.UpdatesKept,
+		"rows_missing", result.RowsMissing)
+
+	return result, nil
+}
+
+// deleteRowRequests deletes the activity rows at the given indexes (0 is row 2, below the
+// header). Requests in a batch apply in order, so rows are deleted bottom-up to keep the
+// remaining indexes valid.
+func deleteRowRequests(sheetID int64, indexes []int) []*sheets.Request {
+	sorted := append([]int(nil), indexes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	requests := make([]*sheets.Request, 0, len(sorted))
+	for _, index := range sorted {
+		requests = append(requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:         sheetID,
+					Dimension:       "ROWS",
+					StartIndex:      int64(index + 1),
+					EndIndex:        int64(index + 2),
+					ForceSendFields: []string{"SheetId"},
+				},
+			},
+		})
+	}
+	return requests
+}
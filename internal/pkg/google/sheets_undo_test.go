@@ -0,0 +1,68 @@
+package google
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+func TestActivityStream_RecordsWrites(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	row := func(id int64, name, date string) []interface{} {
+		return layout.template.Row(strava.Activity{ID: id, Name: name, Type: "Run", StartDateLocal: mustDate(t, date)})
+	}
+	existing := [][]interface{}{row(1, "Easy run", "2024-06-01"), row(2, "Tempo", "2024-06-10")}
+	sheet := &fakeSheet{rows: make(map[int][]interface{})}
+
+	stream := newActivityStream(layout, existing, 100, sheet.flush)
+	stream.writes = layout.sheetWrites("sheet-1")
+	stream.addRow(context.Background(), 1, row(1, "Easy run (renamed)", "2024-06-01"))
+	stream.addRow(context.Background(), 3, row(3, "Long run", "2024-06-12"))
+	result, err := stream.Finish(context.Background(), mustDate(t, "2024-06-02"))
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	writes := result.Writes
+	if writes == nil || writes.SpreadsheetID != "sheet-1" || len(writes.Rows) != 3 {
+		t.Fatalf("Expected 3 recorded writes to sheet-1, got %+v", writes)
+	}
+	recorded := map[int64]WrittenRow{}
+	for _, written := range writes.Rows {
+		recorded[written.ActivityID] = written
+	}
+	if recorded[1].Action != RowActionUpdate || recorded[3].Action != RowActionAppend || recorded[3].Range != layout.rowRange(4, nil).Range {
+		t.Errorf("Unexpected recorded writes: %+v", writes.Rows)
+	}
+	if recorded[2].Action != RowActionFlagDeleted || recorded[2].PreviousName != "Tempo" {
+		t.Errorf("Expected the flagged row to keep its previous name, got %+v", recorded[2])
+	}
+	if plan := writes.Plan(); plan != (UndoPlan{RowsToDelete: 1, FlagsToClear: 1, UpdatesKept: 1}) {
+		t.Errorf("Unexpected undo plan: %+v", plan)
+	}
+}
+
+func TestActivityStream_WritesNotRecordedWithoutRecord(t *testing.T) {
+	layout := newActivityLayout(templates.GetOrDefault(templates.BasicLog))
+	plan := planActivitySync(layout, nil, []strava.Activity{{ID: 1}}, [][]interface{}{layout.template.Row(strava.Activity{ID: 1})}, time.Time{})
+	if plan.result.Writes != nil {
+		t.Errorf("Expected previews not to record writes, got %+v", plan.result.Writes)
+	}
+}
+
+func TestDeleteRowRequests(t *testing.T) {
+	requests := deleteRowRequests(7, []int{0, 5, 2})
+	want := []int64{6, 3, 1}
+	if len(requests) != len(want) {
+		t.Fatalf("Expected %d requests, got %d", len(want), len(requests))
+	}
+	for i, request := range requests {
+		r := request.DeleteDimension.Range
+		if r.SheetId != 7 || r.Dimension != "ROWS" || r.StartIndex != want[i] || r.EndIndex != want[i]+1 {
+			t.Errorf("Request %d: expected rows [%d, %d) bottom-up, got %+v", i, want[i], want[i]+1, r)
+		}
+	}
+}
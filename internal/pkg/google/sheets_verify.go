@@ -0,0 +1,114 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// Write verification statuses, from best to worst
+const (
+	// VerificationVerified means every written row read back as intended
+	VerificationVerified = "verified"
+	// VerificationRetried means some rows differed but matched after being rewritten once
+	VerificationRetried = "retried"
+	// VerificationUnverified means a readback failed, so some writes could not be checked
+	VerificationUnverified = "unverified"
+	// VerificationMismatch means some rows still differed after the retry
+	VerificationMismatch = "mismatch"
+)
+
+var verificationSeverity = map[string]int{
+	VerificationVerified:   0,
+	VerificationRetried:    1,
+	VerificationUnverified: 2,
+	VerificationMismatch:   3,
+}
+
+// WriteVerification reports whether the rows written by a sync read back as intended. The
+// readback catches silent truncation and locale coercion, e.g. a sheet whose locale turns
+// "5.20" into "5,2" or a date into a serial number.
+type WriteVerification struct {
+	Status         string `json:"status"`
+	RowsChecked    int    `json:"rows_checked"`
+	RowsRetried    int    `json:"rows_retried,omitempty"`
+	RowsMismatched int    `json:"rows_mismatched,omitempty"`
+
+	// MismatchedRows lists the sheet rows that still differed after the retry
+	MismatchedRows []int `json:"mismatched_rows,omitempty"`
+}
+
+// record lowers the status to status when it is worse than the current one
+func (v *WriteVerification) record(status string) {
+	if verificationSeverity[status] > verificationSeverity[v.Status] {
+		v.Status = status
+	}
+}
+
+// readBackMismatches reads the rows covered by writes and returns the writes whose cells differ.
+// The chunk is read as one range spanning its first to last row, which keeps it to a single request.
+func (c *SheetsClient) readBackMismatches(ctx context.Context, spreadsheetID string, layout activityLayout, writes []pendingWrite) ([]pendingWrite, error) {
+	if len(writes) == 0 {
+		return nil, nil
+	}
+
+	first, last := writes[0].rowNumber, writes[0].rowNumber
+	for _, write := range writes[1:] {
+		if write.rowNumber < first {
+			first = write.rowNumber
+		}
+		if write.rowNumber > last {
+			last = write.rowNumber
+		}
+	}
+
+	readRange := sheetRange(layout.sheet, fmt.Sprintf("A%d:%s%d", first, layout.template.LastColumn(), last))
+	written, err := c.sheetsService.Spreadsheets.Values.Get(spreadsheetID, readRange).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, c.handleSheetsAPIError(ctx, err, "read back written activities", spreadsheetID)
+	}
+
+	mismatched := mismatchedWrites(writes, written.Values, first)
+	if len(mismatched) > 0 {
+		c.log(ctx).Warn("Written activity rows differ from the intended values",
+			"user_id", c.userID,
+			"spreadsheet_id", spreadsheetID,
+			"rows_checked", len(writes),
+			"rows_mismatched", len(mismatched))
+	}
+	return mismatched, nil
+}
+
+// mismatchedWrites compares each write with the row read back at its position; rows holds the
+// sheet rows starting at firstRow, with trailing empty rows omitted as the Sheets API does
+func mismatchedWrites(writes []pendingWrite, rows [][]interface{}, firstRow int) []pendingWrite {
+	var mismatched []pendingWrite
+	for _, write := range writes {
+		intended := write.valueRange.Values[0]
+
+		var actual []interface{}
+		if i := write.rowNumber - firstRow; i >= 0 && i < len(rows) {
+			actual = rows[i]
+		}
+		if writtenChecksum(intended, intended) != writtenChecksum(intended, actual) {
+			mismatched = append(mismatched, write)
+		}
+	}
+	return mismatched
+}
+
+// writtenChecksum fingerprints the cells of row that intended writes; nil cells were left
+// untouched by the write (manual columns, or everything but the name when flagging a deletion)
+func writtenChecksum(intended, row []interface{}) uint64 {
+	h := fnv.New64a()
+	for col := range intended {
+		if intended[col] == nil {
+			continue
+		}
+		h.Write([]byte(cellString(row, col)))
+		h.Write([]byte{0x1f})
+	}
+	return h.Sum64()
+}
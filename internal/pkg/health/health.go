@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
@@ -11,7 +12,8 @@ import (
 
 // HealthChecker provides functionality to check the health of various dependencies
 type HealthChecker struct {
-	log *logger.Logger
+	log        *logger.Logger
+	httpClient *http.Client // Used to probe OAuth token endpoints
 }
 
 // HealthCheckResult represents the result of a health check operation
@@ -24,7 +26,7 @@ type HealthCheckResult struct {
 
 // NewHealthChecker creates a new health checker instance
 func NewHealthChecker(log *logger.Logger) *HealthChecker {
-	return &HealthChecker{log: log}
+	return &HealthChecker{log: log, httpClient: &http.Client{Timeout: 10 * time.Second}}
 }
 
 // CheckDatabase performs a health check on the database connection
@@ -0,0 +1,173 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuth providers whose token endpoint responses CheckOAuthClient understands
+const (
+	ProviderGoogle = "google"
+	ProviderStrava = "strava"
+)
+
+// probeRefreshToken is the deliberately invalid refresh token sent to token endpoints. A provider
+// that accepts the client credentials rejects it as an invalid grant.
+const probeRefreshToken = "academy-sync-credential-probe"
+
+// ErrInvalidClient is returned when a provider rejects the OAuth client ID or secret, typically
+// after a secret was rotated incorrectly
+var ErrInvalidClient = errors.New("OAuth client credentials rejected")
+
+// OAuthClient is an OAuth app whose credentials are verified against its provider's token endpoint
+type OAuthClient struct {
+	Provider     string // ProviderGoogle or ProviderStrava
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+}
+
+// CheckOAuthClient verifies client's credentials by exchanging a deliberately invalid refresh
+// token. An invalid grant means the provider accepted the client; a rejected client is
+// reported with an error wrapping ErrInvalidClient. Other failures, such as the provider being
+// unreachable, leave the credentials unverified and are reported with a different error.
+func (h *HealthChecker) CheckOAuthClient(ctx context.Context, client OAuthClient) *HealthCheckResult {
+	start := time.Now()
+	result := &HealthCheckResult{
+		Service: client.Provider + "_oauth_client",
+		Status:  "healthy",
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result.Error = h.probeTokenEndpoint(checkCtx, client)
+	result.Latency = time.Since(start)
+	switch {
+	case result.Error == nil:
+		h.log.Debug("OAuth client credentials accepted",
+			"provider", client.Provider,
+			"latency_ms", result.Latency.Milliseconds())
+	case errors.Is(result.Error, ErrInvalidClient):
+		result.Status = "unhealthy"
+		h.log.Error("OAuth client credentials rejected",
+			"provider", client.Provider,
+			"error", result.Error.Error())
+	default:
+		result.Status = "unknown"
+		h.log.Warn("OAuth client credentials could not be verified",
+			"provider", client.Provider,
+			"error", result.Error.Error())
+	}
+	return result
+}
+
+// VerifyOAuthClients checks every client and returns an error naming those whose credentials
+// were rejected. Clients that could not be verified are logged and do not fail the check, so
+// a provider outage does not stop startup.
+func (h *HealthChecker) VerifyOAuthClients(ctx context.Context, clients ...OAuthClient) error {
+	var rejected []string
+	for _, client := range clients {
+		result := h.CheckOAuthClient(ctx, client)
+		if errors.Is(result.Error, ErrInvalidClient) {
+			rejected = append(rejected, client.Provider)
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%w by %s", ErrInvalidClient, strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
+// probeTokenEndpoint posts the refresh grant and classifies the provider's response
+func (h *HealthChecker) probeTokenEndpoint(ctx context.Context, client OAuthClient) error {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {probeRefreshToken},
+		"client_id":     {client.ClientID},
+		"client_secret": {client.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token endpoint unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Stub providers accept any token; the credentials are then as good as they can be
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	switch client.Provider {
+	case ProviderGoogle:
+		return classifyGoogleTokenError(resp.StatusCode, body)
+	case ProviderStrava:
+		return classifyStravaTokenError(resp.StatusCode, body)
+	default:
+		return fmt.Errorf("unknown OAuth provider %q", client.Provider)
+	}
+}
+
+// classifyGoogleTokenError reads Google's RFC 6749 error response: invalid_grant means the
+// client was authenticated, invalid_client and unauthorized_client that it was not
+func classifyGoogleTokenError(status int, body []byte) error {
+	var response struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("unexpected token endpoint response (status %d)", status)
+	}
+
+	switch response.Error {
+	case "invalid_grant":
+		return nil
+	case "invalid_client", "unauthorized_client":
+		return fmt.Errorf("%w: google answered %s (%s); check GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET", ErrInvalidClient, response.Error, response.Description)
+	default:
+		return fmt.Errorf("unexpected token endpoint response (status %d, error %q)", status, response.Error)
+	}
+}
+
+// classifyStravaTokenError reads Strava's fault response, which names the offending resource:
+// RefreshToken when the client was authenticated, Application when it was not
+func classifyStravaTokenError(status int, body []byte) error {
+	var response struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Resource string `json:"resource"`
+			Field    string `json:"field"`
+			Code     string `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("unexpected token endpoint response (status %d)", status)
+	}
+
+	for _, fault := range response.Errors {
+		if fault.Resource == "Application" || fault.Field == "client_id" || fault.Field == "client_secret" {
+			return fmt.Errorf("%w: strava rejected %s %s; check STRAVA_CLIENT_ID and STRAVA_CLIENT_SECRET", ErrInvalidClient, fault.Resource, fault.Field)
+		}
+	}
+	for _, fault := range response.Errors {
+		if fault.Resource == "RefreshToken" || fault.Field == "refresh_token" {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected token endpoint response (status %d, message %q)", status, response.Message)
+}
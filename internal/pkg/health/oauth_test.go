@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestHealthChecker_CheckOAuthClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   string
+		status     int
+		body       string
+		wantStatus string
+		rejected   bool
+	}{
+		{"Google invalid grant", ProviderGoogle, http.StatusBadRequest, `{"error": "invalid_grant", "error_description": "Bad Request"}`, "healthy", false},
+		{"Google invalid client", ProviderGoogle, http.StatusUnauthorized, `{"error": "invalid_client", "error_description": "Unauthorized"}`, "unhealthy", true},
+		{"Google unauthorized client", ProviderGoogle, http.StatusBadRequest, `{"error": "unauthorized_client"}`, "unhealthy", true},
+		{"Strava invalid refresh token", ProviderStrava, http.StatusBadRequest, `{"message": "Bad Request", "errors": [{"resource": "RefreshToken", "field": "refresh_token", "code": "invalid"}]}`, "healthy", false},
+		{"Strava invalid client secret", ProviderStrava, http.StatusBadRequest, `{"message": "Bad Request", "errors": [{"resource": "Application", "field": "client_secret", "code": "invalid"}]}`, "unhealthy", true},
+		{"Stub accepts any token", ProviderStrava, http.StatusOK, `{"access_token": "devstub"}`, "healthy", false},
+		{"Provider outage", ProviderGoogle, http.StatusServiceUnavailable, `<html>unavailable</html>`, "unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("client_id") != "client" {
+					t.Errorf("Unexpected token request: %v", r.PostForm)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			checker := NewHealthChecker(logger.New("test"))
+			result := checker.CheckOAuthClient(context.Background(), OAuthClient{
+				Provider:     tt.provider,
+				TokenURL:     server.URL,
+				ClientID:     "client",
+				ClientSecret: "secret",
+			})
+			if result.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s (%v)", tt.wantStatus, result.Status, result.Error)
+			}
+			if errors.Is(result.Error, ErrInvalidClient) != tt.rejected {
+				t.Errorf("Expected rejected=%t, got %v", tt.rejected, result.Error)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_VerifyOAuthClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid_client"}`))
+	}))
+	defer server.Close()
+
+	checker := NewHealthChecker(logger.New("test"))
+	err := checker.VerifyOAuthClients(context.Background(),
+		OAuthClient{Provider: ProviderGoogle, TokenURL: server.URL, ClientID: "client", ClientSecret: "rotated"},
+		// An unreachable provider leaves its credentials unverified without failing startup
+		OAuthClient{Provider: ProviderStrava, TokenURL: "http://127.0.0.1:1/token", ClientID: "client", ClientSecret: "secret"},
+	)
+	if !errors.Is(err, ErrInvalidClient) || err.Error() != "OAuth client credentials rejected by google" {
+		t.Errorf("Expected only Google to be reported, got %v", err)
+	}
+}
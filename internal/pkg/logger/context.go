@@ -0,0 +1,49 @@
+package logger
+
+import "context"
+
+type contextAttrsKey struct{}
+
+// ContextWithAttrs returns a copy of ctx carrying log attributes, such as the trace_id of the
+// job being processed, that ForContext adds to log entries. Attributes already on ctx are kept.
+func ContextWithAttrs(ctx context.Context, args ...any) context.Context {
+	if len(args) == 0 {
+		return ctx
+	}
+	existing, _ := ctx.Value(contextAttrsKey{}).([]any)
+	attrs := make([]any, 0, len(existing)+len(args))
+	attrs = append(attrs, existing...)
+	attrs = append(attrs, args...)
+	return context.WithValue(ctx, contextAttrsKey{}, attrs)
+}
+
+// ForContext returns a logger that adds the attributes carried by ctx to every entry, so code
+// that only receives a context, like the Strava and Google clients, logs which job it serves
+func (l *Logger) ForContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return l
+	}
+	attrs, _ := ctx.Value(contextAttrsKey{}).([]any)
+	if len(attrs) == 0 {
+		return l
+	}
+	return l.WithContext(attrs...)
+}
+
+// ContextAttr returns the value of the log attribute key carried by ctx, such as the trace_id of
+// the job being processed
+func ContextAttr(ctx context.Context, key string) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	attrs, _ := ctx.Value(contextAttrsKey{}).([]any)
+	var value any
+	found := false
+	// Later attributes win, as they do in log entries
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if k, ok := attrs[i].(string); ok && k == key {
+			value, found = attrs[i+1], true
+		}
+	}
+	return value, found
+}
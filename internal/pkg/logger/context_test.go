@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestForContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithOptions("test-service", Options{Output: &buf})
+
+	ctx := ContextWithAttrs(context.Background(), "trace_id", "trace-123")
+	ctx = ContextWithAttrs(ctx, "job_id", 42)
+	log.ForContext(ctx).Info("Calling Strava API")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log entry: %v", err)
+	}
+	if entry["trace_id"] != "trace-123" || entry["job_id"] != float64(42) {
+		t.Errorf("Expected trace_id and job_id from the context, got %v", entry)
+	}
+
+	// A context without attributes leaves the logger unchanged
+	if log.ForContext(context.Background()) != log {
+		t.Error("Expected the same logger for a context without attributes")
+	}
+}
+
+func TestContextAttr(t *testing.T) {
+	ctx := ContextWithAttrs(context.Background(), "trace_id", "trace-123", "job_id", 42)
+	ctx = ContextWithAttrs(ctx, "trace_id", "trace-456")
+
+	if value, ok := ContextAttr(ctx, "trace_id"); !ok || value != "trace-456" {
+		t.Errorf("Expected the latest trace_id, got %v (%v)", value, ok)
+	}
+	if _, ok := ContextAttr(ctx, "user_id"); ok {
+		t.Error("Expected a missing attribute not to be found")
+	}
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// reportedErrorEventType marks a structured entry as an error event for Cloud Error Reporting
+const reportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// maxStackDepth bounds the frames captured for a reported error
+const maxStackDepth = 32
+
+// ErrorReporting enables the Cloud Error Reporting fields in JSON output. ERROR and CRITICAL
+// entries become error events carrying the service context and the stack of the log call, so
+// Error Reporting groups them per service and call site. Entries with a trace_id attribute are
+// linked to the trace, so the entries of one request or job are shown together across services.
+type ErrorReporting struct {
+	// ProjectID is the GCP project of the trace links; empty leaves trace_id attributes unlinked
+	ProjectID string
+	// Version is reported as serviceContext.version, e.g. the release or Cloud Run revision
+	Version string
+}
+
+// errorReportingHandler adds the Error Reporting and Cloud Trace fields to JSON entries
+type errorReportingHandler struct {
+	slog.Handler
+	config  ErrorReporting
+	service string
+	// traceID is the trace_id attribute added with WithContext, if any
+	traceID string
+}
+
+func (h *errorReportingHandler) Handle(ctx context.Context, r slog.Record) error {
+	traceID := h.traceID
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "trace_id" {
+			traceID = a.Value.String()
+			return false
+		}
+		return true
+	})
+
+	var extra []slog.Attr
+	if trace := h.traceResource(traceID); trace != "" {
+		extra = append(extra, slog.String("logging.googleapis.com/trace", trace))
+	}
+	if r.Level >= slog.LevelError {
+		extra = append(extra,
+			slog.String("@type", reportedErrorEventType),
+			slog.Group("serviceContext", serviceContextAttrs(h.service, h.config.Version)...))
+
+		frames := callerFrames(r.PC)
+		if len(frames) > 0 {
+			top := frames[0]
+			extra = append(extra,
+				slog.String("stack_trace", stackTrace(r.Message, frames)),
+				slog.Group("context", slog.Group("reportLocation",
+					"filePath", top.File,
+					"lineNumber", top.Line,
+					"functionName", top.Function)))
+		}
+	}
+	if len(extra) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	r = r.Clone()
+	r.AddAttrs(extra...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *errorReportingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.Handler = h.Handler.WithAttrs(attrs)
+	for _, a := range attrs {
+		if a.Key == "trace_id" {
+			clone.traceID = a.Value.String()
+		}
+	}
+	return &clone
+}
+
+func (h *errorReportingHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.Handler = h.Handler.WithGroup(name)
+	return &clone
+}
+
+// traceResource returns the Cloud Trace resource name of traceID. Job trace IDs are UUIDs; the
+// dashes are dropped to give the 32 hex digits Cloud Trace uses.
+func (h *errorReportingHandler) traceResource(traceID string) string {
+	if h.config.ProjectID == "" || traceID == "" {
+		return ""
+	}
+	return fmt.Sprintf("projects/%s/traces/%s", h.config.ProjectID, strings.ReplaceAll(traceID, "-", ""))
+}
+
+func serviceContextAttrs(service, version string) []any {
+	attrs := []any{"service", service}
+	if version != "" {
+		attrs = append(attrs, "version", version)
+	}
+	return attrs
+}
+
+// callerFrames returns the stack from the log call at pc outwards. The handler runs on the
+// logging goroutine, so the current stack contains pc below the logger's own frames.
+func callerFrames(pc uintptr) []runtime.Frame {
+	if pc == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth+16)
+	n := runtime.Callers(1, pcs)
+	pcs = pcs[:n]
+
+	start := -1
+	for i, p := range pcs {
+		if p == pc {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		// Logged from another goroutine's record; report the call site alone
+		pcs = []uintptr{pc}
+	} else {
+		pcs = pcs[start:]
+	}
+	if len(pcs) > maxStackDepth {
+		pcs = pcs[:maxStackDepth]
+	}
+
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		if frame.Function != "" {
+			frames = append(frames, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// stackTrace formats frames like runtime.Stack, the Go format Error Reporting parses
+func stackTrace(msg string, frames []runtime.Frame) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	b.WriteString("\n\ngoroutine 1 [running]:\n")
+	for _, frame := range frames {
+		fmt.Fprintf(&b, "%s(...)\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse JSON output %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestErrorReporting_ErrorEntries(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("automation-engine", Options{
+		Output:         &buf,
+		ErrorReporting: &ErrorReporting{ProjectID: "academy-sync", Version: "rev-12"},
+	})
+
+	logger.Info("job started")
+	logger.Error("job failed", "error", "sheet not found")
+	logger.Critical("database unavailable")
+
+	entries := decodeEntries(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+
+	if _, exists := entries[0]["@type"]; exists {
+		t.Error("Expected info entries not to be reported as errors")
+	}
+
+	for _, entry := range entries[1:] {
+		if entry["@type"] != reportedErrorEventType {
+			t.Errorf("Expected an error event, got %v", entry["@type"])
+		}
+		serviceContext, _ := entry["serviceContext"].(map[string]interface{})
+		if serviceContext["service"] != "automation-engine" || serviceContext["version"] != "rev-12" {
+			t.Errorf("Expected the service context, got %v", entry["serviceContext"])
+		}
+
+		// Both entries are attributed to this test, not to the logger
+		stack, _ := entry["stack_trace"].(string)
+		if !strings.HasPrefix(stack, entry["message"].(string)+"\n\ngoroutine 1 [running]:\n") ||
+			!strings.Contains(stack, "TestErrorReporting_ErrorEntries") {
+			t.Errorf("Expected a Go stack trace from the log call, got %q", stack)
+		}
+		location := entry["context"].(map[string]interface{})["reportLocation"].(map[string]interface{})
+		if !strings.HasSuffix(location["functionName"].(string), "TestErrorReporting_ErrorEntries") ||
+			!strings.HasSuffix(location["filePath"].(string), "errorreporting_test.go") {
+			t.Errorf("Expected the report location of the log call, got %v", location)
+		}
+	}
+}
+
+func TestErrorReporting_TraceLinks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("backend-api", Options{
+		Output:         &buf,
+		ErrorReporting: &ErrorReporting{ProjectID: "academy-sync"},
+	})
+
+	logger.Info("sync queued", "trace_id", "3f2b8c1e-9d4a-4e7b-8a6c-1b2c3d4e5f60")
+	logger.WithContext("trace_id", "0a1b2c3d-0000-4000-8000-000000000001").Warn("retrying")
+	logger.Info("no trace")
+
+	entries := decodeEntries(t, &buf)
+	if entries[0]["logging.googleapis.com/trace"] != "projects/academy-sync/traces/3f2b8c1e9d4a4e7b8a6c1b2c3d4e5f60" {
+		t.Errorf("Expected the trace link of the entry's trace ID, got %v", entries[0]["logging.googleapis.com/trace"])
+	}
+	if entries[1]["logging.googleapis.com/trace"] != "projects/academy-sync/traces/0a1b2c3d000040008000000000000001" {
+		t.Errorf("Expected the trace link of the context's trace ID, got %v", entries[1]["logging.googleapis.com/trace"])
+	}
+	if _, exists := entries[2]["logging.googleapis.com/trace"]; exists {
+		t.Error("Expected no trace link without a trace ID")
+	}
+}
+
+func TestErrorReporting_DisabledByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("backend-api", Options{Output: &buf})
+
+	logger.Error("request failed", "trace_id", "3f2b8c1e-9d4a-4e7b-8a6c-1b2c3d4e5f60")
+
+	entry := decodeEntries(t, &buf)[0]
+	for _, key := range []string{"@type", "serviceContext", "stack_trace", "logging.googleapis.com/trace"} {
+		if _, exists := entry[key]; exists {
+			t.Errorf("Expected no %s without Error Reporting, got %v", key, entry[key])
+		}
+	}
+}
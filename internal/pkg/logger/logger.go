@@ -4,9 +4,13 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 )
 
 // LogLevel represents the available log levels.
@@ -25,6 +29,36 @@ const (
 	LevelCritical LogLevel = "CRITICAL"
 )
 
+// Output formats selectable with LOG_FORMAT
+const (
+	// FormatJSON writes one Cloud Logging structured entry per line, with severity and message fields
+	FormatJSON = "json"
+	// FormatConsole writes human-readable key=value lines for local development
+	FormatConsole = "console"
+)
+
+// slogLevelCritical is the slog level of Critical entries, above slog.LevelError
+const slogLevelCritical = slog.LevelError + 4
+
+// Options configures a logger created with NewWithOptions.
+type Options struct {
+	// Level is the minimum LogLevel written; empty or invalid values mean INFO
+	Level string
+	// Format is FormatJSON or FormatConsole; other values mean FormatJSON
+	Format string
+	// DebugSampleBurst is the number of DEBUG entries with the same message written per
+	// DebugSampleInterval; the rest are dropped. Zero writes every entry.
+	DebugSampleBurst    int
+	DebugSampleInterval time.Duration
+	// RedactFields names attributes removed from every entry, in addition to the emails,
+	// spreadsheet IDs, tokens and other secrets that are always redacted
+	RedactFields []string
+	// ErrorReporting adds the Cloud Error Reporting and trace fields to JSON entries
+	ErrorReporting *ErrorReporting
+	// Output defaults to stdout
+	Output io.Writer
+}
+
 // Logger wraps slog.Logger with additional functionality for our application.
 type Logger struct {
 	*slog.Logger
@@ -39,20 +73,50 @@ type Logger struct {
 //   - serviceName: The name of the service using this logger (e.g., "backend-api")
 //
 // The logger outputs JSON-formatted logs to stdout/stderr as required by the MVP.
-// Log levels are parsed from the LOG_LEVEL environment variable, defaulting to INFO.
+// Log levels are parsed from the LOG_LEVEL environment variable, defaulting to INFO,
+// and LOG_FORMAT=console switches to human-readable output. Services create their
+// logger from the loaded configuration with NewWithOptions instead.
 //
 // Example usage:
 //
 //	logger := logger.New("backend-api")
 //	logger.Info("Service starting", "port", 8080, "environment", "production")
 func New(serviceName string) *Logger {
-	// Parse log level from environment variable
-	level := parseLogLevel(os.Getenv("LOG_LEVEL"))
-
-	// Create JSON handler that writes to stdout
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
+	return NewWithOptions(serviceName, Options{
+		Level:  os.Getenv("LOG_LEVEL"),
+		Format: strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))),
 	})
+}
+
+// NewWithOptions creates a structured logger writing entries at or above opts.Level in
+// opts.Format, with high-frequency DEBUG messages sampled as configured. Sensitive values are
+// redacted from every entry before it is written: emails are masked, spreadsheet IDs hashed
+// and tokens removed, both by attribute name and wherever they appear in a logged string.
+func NewWithOptions(serviceName string, opts Options) *Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	var handler slog.Handler
+	if opts.Format == FormatConsole {
+		handler = slog.NewTextHandler(output, &slog.HandlerOptions{
+			Level:       parseLogLevel(opts.Level),
+			ReplaceAttr: consoleAttr,
+		})
+	} else {
+		handler = slog.NewJSONHandler(output, &slog.HandlerOptions{
+			Level:       parseLogLevel(opts.Level),
+			ReplaceAttr: cloudLoggingAttr,
+		})
+		if opts.ErrorReporting != nil {
+			handler = &errorReportingHandler{Handler: handler, config: *opts.ErrorReporting, service: serviceName}
+		}
+	}
+	handler = &redactingHandler{Handler: handler, redactor: newRedactor(opts.RedactFields)}
+	if opts.DebugSampleBurst > 0 && opts.DebugSampleInterval > 0 {
+		handler = newSamplingHandler(handler, opts.DebugSampleBurst, opts.DebugSampleInterval)
+	}
 
 	// Create logger with service name attribute
 	slogger := slog.New(handler).With("service", serviceName)
@@ -63,6 +127,53 @@ func New(serviceName string) *Logger {
 	}
 }
 
+// cloudLoggingAttr renames the built-in attributes to the fields Cloud Logging reads from
+// structured entries: severity (DEBUG through CRITICAL) and message.
+func cloudLoggingAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		level, _ := a.Value.Any().(slog.Level)
+		return slog.String("severity", severity(level))
+	case slog.MessageKey:
+		a.Key = "message"
+	}
+	return a
+}
+
+// consoleAttr shortens the timestamp and names the levels as LOG_LEVEL does
+func consoleAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String(slog.TimeKey, a.Value.Time().Format("15:04:05.000"))
+	case slog.LevelKey:
+		level, _ := a.Value.Any().(slog.Level)
+		return slog.String(slog.LevelKey, severity(level))
+	}
+	return a
+}
+
+// severity returns the LogLevel name of an slog level
+func severity(level slog.Level) string {
+	switch {
+	case level >= slogLevelCritical:
+		return string(LevelCritical)
+	case level >= slog.LevelError:
+		return string(LevelError)
+	case level >= slog.LevelWarn:
+		return string(LevelWarning)
+	case level >= slog.LevelInfo:
+		return string(LevelInfo)
+	default:
+		return string(LevelDebug)
+	}
+}
+
 // parseLogLevel converts a string log level to slog.Level.
 // Returns slog.LevelInfo as default for invalid or empty input.
 func parseLogLevel(levelStr string) slog.Level {
@@ -87,11 +198,19 @@ func parseLogLevel(levelStr string) slog.Level {
 }
 
 // Critical logs a critical error message. These are severe errors that may
-// stop system operation. Maps to slog.LevelError internally.
+// stop system operation. They are logged above slog.LevelError and reported
+// with the CRITICAL severity, so they are written whenever errors are.
 func (l *Logger) Critical(msg string, args ...any) {
-	// Add critical indicator to help distinguish from regular errors
-	args = append([]any{"severity", "critical"}, args...)
-	l.Logger.Error(msg, args...)
+	ctx := context.Background()
+	if !l.Enabled(ctx, slogLevelCritical) {
+		return
+	}
+	// Record the caller of Critical rather than Critical itself as the source of the entry
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	r := slog.NewRecord(time.Now(), slogLevelCritical, msg, pcs[0])
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
 }
 
 // ServiceName returns the service name associated with this logger.
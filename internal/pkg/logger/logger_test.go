@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -171,14 +172,8 @@ func TestCriticalLogging(t *testing.T) {
 	// Capture output
 	var buf bytes.Buffer
 	
-	// Create logger with JSON handler writing to buffer
-	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
-		Level: slog.LevelError,
-	})
-	logger := &Logger{
-		Logger:      slog.New(handler).With("service", "test-service"),
-		serviceName: "test-service",
-	}
+	// Critical entries are written at the ERROR threshold
+	logger := NewWithOptions("test-service", Options{Level: "ERROR", Output: &buf})
 	
 	// Log a critical message
 	logger.Critical("critical error occurred", "errorCode", "CRIT001")
@@ -189,17 +184,13 @@ func TestCriticalLogging(t *testing.T) {
 		t.Fatalf("Failed to parse JSON output: %v", err)
 	}
 	
-	// Verify it's logged as ERROR level with severity indicator
-	if logEntry["level"] != "ERROR" {
-		t.Errorf("Expected level='ERROR', got %v", logEntry["level"])
+	// Verify it's reported with the CRITICAL severity
+	if logEntry["severity"] != "CRITICAL" {
+		t.Errorf("Expected severity='CRITICAL', got %v", logEntry["severity"])
 	}
 	
-	if logEntry["severity"] != "critical" {
-		t.Errorf("Expected severity='critical', got %v", logEntry["severity"])
-	}
-	
-	if logEntry["msg"] != "critical error occurred" {
-		t.Errorf("Expected msg='critical error occurred', got %v", logEntry["msg"])
+	if logEntry["message"] != "critical error occurred" {
+		t.Errorf("Expected message='critical error occurred', got %v", logEntry["message"])
 	}
 	
 	if logEntry["errorCode"] != "CRIT001" {
@@ -266,4 +257,100 @@ func TestLogLevelConstants(t *testing.T) {
 			t.Errorf("Expected %s, got %s", expected, string(level))
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestNewWithOptions_CloudLoggingFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("test-service", Options{Level: "WARNING", Format: FormatJSON, Output: &buf})
+
+	logger.Info("filtered out")
+	logger.Warn("disk almost full", "percent", 91)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warning to be written, got %q", buf.String())
+	}
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &logEntry); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if logEntry["severity"] != "WARNING" || logEntry["message"] != "disk almost full" {
+		t.Errorf("Expected a Cloud Logging entry with severity and message, got %v", logEntry)
+	}
+	if _, exists := logEntry["level"]; exists {
+		t.Error("Expected 'level' to be replaced by 'severity'")
+	}
+	if logEntry["service"] != "test-service" || logEntry["percent"] != float64(91) {
+		t.Errorf("Expected the service and entry fields, got %v", logEntry)
+	}
+}
+
+func TestNewWithOptions_ConsoleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("test-service", Options{Level: "DEBUG", Format: FormatConsole, Output: &buf})
+
+	logger.Debug("fetching activities", "user_id", 7)
+
+	output := buf.String()
+	if strings.HasPrefix(output, "{") {
+		t.Fatalf("Expected console output, got JSON: %s", output)
+	}
+	for _, want := range []string{"level=DEBUG", `msg="fetching activities"`, "service=test-service", "user_id=7"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in console output %q", want, output)
+		}
+	}
+}
+
+func TestNewWithOptions_SamplesDebugMessages(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOptions("test-service", Options{
+		Level:               "DEBUG",
+		Output:              &buf,
+		DebugSampleBurst:    3,
+		DebugSampleInterval: time.Hour,
+	})
+
+	// Derived loggers share the sampling budget
+	requestLogger := logger.WithContext("request_id", "req-1")
+	for i := 0; i < 10; i++ {
+		logger.Debug("polling queue")
+		requestLogger.Debug("polling queue")
+	}
+	logger.Debug("other message")
+	for i := 0; i < 5; i++ {
+		logger.Info("job finished")
+	}
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var logEntry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
+			t.Fatalf("Failed to parse JSON output: %v", err)
+		}
+		counts[logEntry["message"].(string)]++
+	}
+
+	if counts["polling queue"] != 3 {
+		t.Errorf("Expected 3 sampled debug entries, got %d", counts["polling queue"])
+	}
+	if counts["other message"] != 1 {
+		t.Errorf("Expected other debug messages to have their own budget, got %d", counts["other message"])
+	}
+	if counts["job finished"] != 5 {
+		t.Errorf("Expected every info entry to be written, got %d", counts["job finished"])
+	}
+}
+
+func TestSampler_ResetsEachInterval(t *testing.T) {
+	s := &sampler{burst: 1, interval: time.Minute, counts: make(map[string]int)}
+	start := time.Now()
+
+	if !s.allow("tick", start) || s.allow("tick", start.Add(time.Second)) {
+		t.Fatal("Expected one entry per interval")
+	}
+	if !s.allow("tick", start.Add(time.Minute)) {
+		t.Error("Expected the budget to reset in the next interval")
+	}
+}
@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces secrets that must not be logged in any form
+const redactedValue = "[REDACTED]"
+
+// redaction is how a sensitive value is written
+type redaction int
+
+const (
+	// redactRemove drops the value entirely
+	redactRemove redaction = iota
+	// redactMask keeps an email's first letter and domain, which is enough to tell users apart
+	redactMask
+	// redactHash writes a short hash, so entries about the same value can still be correlated
+	redactHash
+)
+
+// defaultRedactedFields are the attribute names redacted in every logger. Names are compared
+// without case, underscores or dashes and match as suffixes, so user_email, googleEmail and
+// strava_access_token are covered too.
+var defaultRedactedFields = map[string]redaction{
+	"email":         redactMask,
+	"spreadsheetid": redactHash,
+	"accesstoken":   redactRemove,
+	"refreshtoken":  redactRemove,
+	"idtoken":       redactRemove,
+	"token":         redactRemove,
+	"password":      redactRemove,
+	"secret":        redactRemove,
+	"authorization": redactRemove,
+	"apikey":        redactRemove,
+	"cookie":        redactRemove,
+}
+
+// valuePatterns find secrets inside any logged string, including messages and errors, whatever
+// the attribute is called
+var valuePatterns = []struct {
+	pattern *regexp.Regexp
+	replace func(match string) string
+}{
+	// Bearer credentials in headers or error messages
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`), func(string) string { return "Bearer " + redactedValue }},
+	// JWTs, such as session tokens and Google ID tokens
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), func(string) string { return redactedValue }},
+	// Google OAuth access and refresh tokens
+	{regexp.MustCompile(`\bya29\.[A-Za-z0-9._-]+|\b1//[A-Za-z0-9._-]{20,}`), func(string) string { return redactedValue }},
+	// token=..., "access_token":"..." and similar pairs in query strings and response bodies
+	{regexp.MustCompile(`(?i)("?\b(?:access_token|refresh_token|id_token|client_secret)"?\s*[:=]\s*"?)[^"&\s,}]+`), nil},
+	// Spreadsheet IDs in sheet URLs
+	{regexp.MustCompile(`(docs\.google\.com/spreadsheets/d/)([A-Za-z0-9_-]+)`), nil},
+	// Email addresses
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), maskEmail},
+}
+
+// redactor applies the field and value rules of one logger
+type redactor struct {
+	fields map[string]redaction
+}
+
+// newRedactor creates a redactor with the default fields plus extraFields, which are removed
+func newRedactor(extraFields []string) *redactor {
+	fields := make(map[string]redaction, len(defaultRedactedFields)+len(extraFields))
+	for name, how := range defaultRedactedFields {
+		fields[name] = how
+	}
+	for _, name := range extraFields {
+		if name = normalizeFieldName(name); name != "" {
+			fields[name] = redactRemove
+		}
+	}
+	return &redactor{fields: fields}
+}
+
+// fieldRule returns how an attribute named key is redacted, if it is sensitive
+func (r *redactor) fieldRule(key string) (redaction, bool) {
+	name := normalizeFieldName(key)
+	if how, ok := r.fields[name]; ok {
+		return how, true
+	}
+	for field, how := range r.fields {
+		if strings.HasSuffix(name, field) {
+			return how, true
+		}
+	}
+	return 0, false
+}
+
+// attr returns a with sensitive values redacted, descending into groups and maps
+func (r *redactor) attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = r.attr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	}
+
+	if how, ok := r.fieldRule(a.Key); ok {
+		if s, isText := textOf(a.Value); isText {
+			return slog.String(a.Key, applyRedaction(how, s))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: r.value(a.Value)}
+}
+
+// value redacts the secrets found by pattern in v
+func (r *redactor) value(v slog.Value) slog.Value {
+	if v.Kind() == slog.KindString {
+		return slog.StringValue(redactString(v.String()))
+	}
+	if v.Kind() != slog.KindAny {
+		return v
+	}
+
+	switch x := v.Any().(type) {
+	case error:
+		return slog.StringValue(redactString(x.Error()))
+	case []byte:
+		return slog.StringValue(redactString(string(x)))
+	case fmt.Stringer:
+		return slog.StringValue(redactString(x.String()))
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(x))
+		for key, item := range x {
+			redacted[key] = r.attr(slog.Any(key, item)).Value.Any()
+		}
+		return slog.AnyValue(redacted)
+	case map[string]string:
+		redacted := make(map[string]string, len(x))
+		for key, item := range x {
+			redacted[key] = r.attr(slog.String(key, item)).Value.String()
+		}
+		return slog.AnyValue(redacted)
+	case []string:
+		redacted := make([]string, len(x))
+		for i, item := range x {
+			redacted[i] = redactString(item)
+		}
+		return slog.AnyValue(redacted)
+	}
+	return v
+}
+
+// textOf returns the text of values that can hold a secret; numbers, booleans and times cannot
+func textOf(v slog.Value) (string, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String(), true
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error(), true
+		case []byte:
+			return string(x), true
+		case fmt.Stringer:
+			return x.String(), true
+		case *string:
+			if x != nil {
+				return *x, true
+			}
+		}
+	}
+	return "", false
+}
+
+// applyRedaction writes s as the rule how says; empty values stay empty so "not set" is still visible
+func applyRedaction(how redaction, s string) string {
+	if s == "" {
+		return s
+	}
+	switch how {
+	case redactMask:
+		return redactString(s)
+	case redactHash:
+		return hashValue(s)
+	default:
+		return redactedValue
+	}
+}
+
+// redactString replaces the secrets found by pattern in s
+func redactString(s string) string {
+	for _, p := range valuePatterns {
+		if p.replace != nil {
+			s = p.pattern.ReplaceAllStringFunc(s, p.replace)
+			continue
+		}
+		s = p.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			groups := p.pattern.FindStringSubmatch(match)
+			if strings.Contains(groups[1], "spreadsheets/d/") {
+				return groups[1] + hashValue(groups[2])
+			}
+			return groups[1] + redactedValue
+		})
+	}
+	return s
+}
+
+// maskEmail keeps the first letter of the local part and the domain
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redactedValue
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// hashValue returns a short, stable hash of s
+func hashValue(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// normalizeFieldName lowercases name and drops underscores, dashes and spaces
+func normalizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// redactingHandler redacts every entry before the next handler formats it, including the
+// attributes added with WithContext
+type redactingHandler struct {
+	slog.Handler
+	redactor *redactor
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.attr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.attr(a)
+	}
+	return &redactingHandler{Handler: h.Handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{Handler: h.Handler.WithGroup(name), redactor: h.redactor}
+}
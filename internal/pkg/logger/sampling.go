@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplingHandler drops DEBUG entries whose message was already written burst times in the
+// current interval, so a debug message logged in a tight loop cannot flood the output.
+// Entries at INFO and above are always written.
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(next slog.Handler, burst int, interval time.Duration) *samplingHandler {
+	return &samplingHandler{
+		Handler: next,
+		sampler: &sampler{burst: burst, interval: interval, counts: make(map[string]int)},
+	}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelInfo && !h.sampler.allow(r.Message, r.Time) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs and WithGroup keep sharing the sampler, so loggers derived with WithContext
+// count towards the same budget
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
+
+// sampler counts entries per message in fixed windows of interval
+type sampler struct {
+	burst    int
+	interval time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// allow reports whether an entry with msg logged at now is within the burst of its window
+func (s *sampler) allow(msg string, now time.Time) bool {
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.windowStart) >= s.interval {
+		s.windowStart = now
+		clear(s.counts)
+	}
+	s.counts[msg]++
+	return s.counts[msg] <= s.burst
+}
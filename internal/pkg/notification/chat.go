@@ -0,0 +1,108 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// chatPostTimeout bounds a single webhook post
+const chatPostTimeout = 10 * time.Second
+
+// ChatPoster posts notifications to a chat channel's incoming webhook
+type ChatPoster interface {
+	Post(ctx context.Context, channel, webhookURL string, n Notification) error
+}
+
+// ChatSender posts notifications to Slack and Discord incoming webhooks
+type ChatSender struct {
+	httpClient *http.Client
+}
+
+// NewChatSender creates a new chat sender
+func NewChatSender() *ChatSender {
+	return &ChatSender{httpClient: &http.Client{Timeout: chatPostTimeout}}
+}
+
+// Post renders the notification for the channel and posts it to the webhook
+func (s *ChatSender) Post(ctx context.Context, channel, webhookURL string, n Notification) error {
+	var payload []byte
+	var err error
+	switch channel {
+	case ChannelSlack:
+		payload, err = RenderSlack(n)
+	case ChannelDiscord:
+		payload, err = RenderDiscord(n)
+	default:
+		return fmt.Errorf("unsupported chat channel %q", channel)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render %s message: %w", channel, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", channel, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", channel, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook returned status %d", channel, resp.StatusCode)
+	}
+	return nil
+}
+
+// chatWebhookHosts are the hosts that serve each channel's incoming webhooks
+var chatWebhookHosts = map[string][]string{
+	ChannelSlack:   {"hooks.slack.com"},
+	ChannelDiscord: {"discord.com", "discordapp.com"},
+}
+
+// ValidChannel reports whether channel is a supported notification channel
+func ValidChannel(channel string) bool {
+	_, chat := chatWebhookHosts[channel]
+	return channel == ChannelEmail || chat
+}
+
+// IsChatChannel reports whether channel delivers through a chat webhook
+func IsChatChannel(channel string) bool {
+	_, ok := chatWebhookHosts[channel]
+	return ok
+}
+
+// ValidateChatWebhookURL checks that webhookURL is an https incoming webhook of the channel's service,
+// so the notification service never posts user data to arbitrary hosts
+func ValidateChatWebhookURL(channel, webhookURL string) error {
+	hosts, ok := chatWebhookHosts[channel]
+	if !ok {
+		return fmt.Errorf("unsupported chat channel %q", channel)
+	}
+
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" {
+		return fmt.Errorf("%s webhook URL must be an https URL", channel)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range hosts {
+		if host == allowed {
+			if channel == ChannelDiscord && !strings.HasPrefix(parsed.Path, "/api/webhooks/") {
+				return fmt.Errorf("discord webhook URL must point to /api/webhooks/")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s webhook URL must be on %s", channel, strings.Join(hosts, " or "))
+}
@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestValidateChatWebhookURL(t *testing.T) {
+	tests := []struct {
+		channel string
+		url     string
+		valid   bool
+	}{
+		{ChannelSlack, "https://hooks.slack.com/services/T000/B000/XXXX", true},
+		{ChannelSlack, "http://hooks.slack.com/services/T000/B000/XXXX", false},
+		{ChannelSlack, "https://hooks.slack.com.evil.example/services/T000", false},
+		{ChannelDiscord, "https://discord.com/api/webhooks/123/abc", true},
+		{ChannelDiscord, "https://discordapp.com/api/webhooks/123/abc", true},
+		{ChannelDiscord, "https://discord.com/channels/123", false},
+		{ChannelEmail, "https://hooks.slack.com/services/T000", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateChatWebhookURL(tt.channel, tt.url)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateChatWebhookURL(%q, %q) = %v, expected valid=%t", tt.channel, tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestRenderChat_EscapesUserText(t *testing.T) {
+	n := Notification{
+		Title:      "Your activity sync failed",
+		Severity:   SeverityError,
+		Paragraphs: []string{"Error from <@here> *bold*"},
+		LinkURL:    "https://app.example.com",
+	}
+
+	slack, err := RenderSlack(n)
+	if err != nil {
+		t.Fatalf("RenderSlack failed: %v", err)
+	}
+	var slackPayload struct {
+		Attachments []struct {
+			Color string `json:"color"`
+			Text  string `json:"text"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(slack, &slackPayload); err != nil {
+		t.Fatalf("Invalid Slack payload: %v", err)
+	}
+	if len(slackPayload.Attachments) != 1 || slackPayload.Attachments[0].Color != slackColors[SeverityError] ||
+		!strings.Contains(slackPayload.Attachments[0].Text, "&lt;@here&gt;") {
+		t.Errorf("Unexpected Slack payload: %s", slack)
+	}
+
+	discord, err := RenderDiscord(n)
+	if err != nil {
+		t.Fatalf("RenderDiscord failed: %v", err)
+	}
+	var discordPayload struct {
+		Embeds []struct {
+			Description string `json:"description"`
+		} `json:"embeds"`
+		AllowedMentions struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+	}
+	if err := json.Unmarshal(discord, &discordPayload); err != nil {
+		t.Fatalf("Invalid Discord payload: %v", err)
+	}
+	if len(discordPayload.Embeds) != 1 || !strings.Contains(discordPayload.Embeds[0].Description, `\*bold\*`) ||
+		discordPayload.AllowedMentions.Parse == nil {
+		t.Errorf("Unexpected Discord payload: %s", discord)
+	}
+}
+
+func TestChatSender_Post(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewChatSender()
+	n := Notification{Title: "Synced 2 activities to your spreadsheet"}
+
+	if err := sender.Post(context.Background(), ChannelDiscord, server.URL+"/ok", n); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if !strings.Contains(string(body), "Synced 2 activities") {
+		t.Errorf("Unexpected request body: %s", body)
+	}
+
+	if err := sender.Post(context.Background(), ChannelSlack, server.URL+"/fail", n); err == nil {
+		t.Error("Expected an error for a non-2xx response")
+	}
+	if err := sender.Post(context.Background(), ChannelEmail, server.URL, n); err == nil {
+		t.Error("Expected an error for a non-chat channel")
+	}
+}
+
+type mockChannelPreferences map[int]*database.NotificationChannel
+
+func (m mockChannelPreferences) GetNotificationChannel(ctx context.Context, userID int) (*database.NotificationChannel, error) {
+	return m[userID], nil
+}
+
+type mockChatPoster struct {
+	posts []string
+}
+
+func (m *mockChatPoster) Post(ctx context.Context, channel, webhookURL string, n Notification) error {
+	m.posts = append(m.posts, channel+" "+n.Title)
+	return nil
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	prefs := mockChannelPreferences{
+		1: {Channel: ChannelEmail},
+		2: {Channel: ChannelSlack, WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX"},
+		3: {Channel: ChannelDiscord}, // Webhook URL missing, falls back to email
+	}
+	sender := &mockSender{}
+	chat := &mockChatPoster{}
+	dispatcher := NewDispatcher(sender, chat, prefs, nil, logger.New("test"))
+	n := Notification{Title: "Hello"}
+
+	for userID := 1; userID <= 3; userID++ {
+		if err := dispatcher.Deliver(context.Background(), Recipient{UserID: userID, Email: "runner@example.com"}, n); err != nil {
+			t.Fatalf("Deliver to user %d failed: %v", userID, err)
+		}
+	}
+	if len(sender.sent) != 2 || len(chat.posts) != 1 || chat.posts[0] != "slack Hello" {
+		t.Errorf("Unexpected deliveries: emails=%d, posts=%v", len(sender.sent), chat.posts)
+	}
+
+	// Chat-only notifications are never emailed
+	if err := dispatcher.Deliver(context.Background(), Recipient{UserID: 1, ChatOnly: true}, n); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if len(sender.sent) != 2 {
+		t.Errorf("Expected chat-only notification to be dropped for an email user")
+	}
+
+	// Without SMTP, email users cannot be reached
+	if err := NewDispatcher(nil, chat, prefs, nil, logger.New("test")).Deliver(context.Background(), Recipient{UserID: 1}, n); err == nil {
+		t.Error("Expected an error when email delivery is not configured")
+	}
+}
+
+func TestDispatcher_DeliverRespectsPreferences(t *testing.T) {
+	preferences := database.DefaultNotificationPreferences()
+	preferences.SuccessSummary = false
+	preferences.ReauthAlerts = false
+	prefs := mockChannelPreferences{
+		1: {Channel: ChannelSlack, WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX", Preferences: &preferences},
+	}
+	sender := &mockSender{}
+	chat := &mockChatPoster{}
+	dispatcher := NewDispatcher(sender, chat, prefs, nil, logger.New("test"))
+
+	summary := BuildRunSummaryNotification(database.FinishedRun{UserID: 1, ActivitiesCount: 2}, "")
+	reauth := BuildFailureAlertNotification(database.FinishedRun{UserID: 1, ErrorType: "STRAVA_REAUTH_REQUIRED"}, "")
+	failure := BuildFailureAlertNotification(database.FinishedRun{UserID: 1, ErrorType: "SHEETS_WRITE_FAILED"}, "")
+	for _, n := range []Notification{summary, reauth, failure} {
+		if err := dispatcher.Deliver(context.Background(), Recipient{UserID: 1}, n); err != nil {
+			t.Fatalf("Deliver %s failed: %v", n.Kind, err)
+		}
+	}
+	if len(chat.posts) != 1 || chat.posts[0] != "slack "+failure.Title {
+		t.Errorf("Expected only the non-reconnect failure to be posted, got %v", chat.posts)
+	}
+
+	// The email deliverer used for security alerts honours the same preferences
+	preferences.SecurityAlerts = false
+	signIn := BuildNewSignInNotification(database.SignInAlert{}, "")
+	if err := NewEmailDeliverer(sender, prefs).Deliver(context.Background(), Recipient{UserID: 1, Email: "runner@example.com"}, signIn); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("Expected the security alert to be dropped, got %d emails", len(sender.sent))
+	}
+}
@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Retry defaults for ReliableSender
+const (
+	DefaultSendAttempts = 3
+	DefaultSendBackoff  = 2 * time.Second
+)
+
+// SuppressionList holds the addresses that permanently rejected mail
+type SuppressionList interface {
+	IsEmailSuppressed(ctx context.Context, email string) (bool, error)
+	SuppressEmail(ctx context.Context, email string, smtpCode int, reason string) error
+}
+
+// ReliableSender wraps an EmailSender with retries and a suppression list. Transient failures
+// (SMTP 4xx replies, HTTP API rate limits and server errors, connection errors) are retried with
+// exponential backoff; permanent failures (SMTP 5xx, other HTTP API 4xx) are not, and a hard
+// bounce adds the recipient to the suppression list. Suppressed recipients are skipped without
+// error, so callers treat the notification as handled instead of retrying it.
+type ReliableSender struct {
+	sender       EmailSender
+	suppressions SuppressionList
+	attempts     int
+	backoff      time.Duration
+	logger       *logger.Logger
+	sleep        func(ctx context.Context, d time.Duration) error
+}
+
+// NewReliableSender creates a sender that makes up to DefaultSendAttempts attempts per message
+func NewReliableSender(sender EmailSender, suppressions SuppressionList, logger *logger.Logger) *ReliableSender {
+	return &ReliableSender{
+		sender:       sender,
+		suppressions: suppressions,
+		attempts:     DefaultSendAttempts,
+		backoff:      DefaultSendBackoff,
+		logger:       logger.WithContext("component", "reliable_sender"),
+		sleep:        sleepContext,
+	}
+}
+
+// Send delivers the message unless the recipient is suppressed
+func (s *ReliableSender) Send(ctx context.Context, msg Message) error {
+	suppressed, err := s.suppressions.IsEmailSuppressed(ctx, msg.To)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	if suppressed {
+		s.logger.Info("Skipping email to suppressed recipient",
+			"subject", msg.Subject)
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = s.sender.Send(ctx, msg)
+		if err == nil {
+			return nil
+		}
+
+		code, permanent := classifyDeliveryError(err)
+		if permanent {
+			s.recordPermanentFailure(ctx, msg.To, code, err)
+			return fmt.Errorf("permanent delivery failure: %w", err)
+		}
+		if attempt >= s.attempts || ctx.Err() != nil {
+			return fmt.Errorf("delivery failed after %d attempts: %w", attempt, err)
+		}
+
+		delay := s.backoff << (attempt - 1)
+		s.logger.Warn("Transient email delivery failure, retrying",
+			"error", err,
+			"attempt", attempt,
+			"retry_in", delay.String())
+		if err := s.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// recordPermanentFailure suppresses the recipient if the failure is a hard bounce
+func (s *ReliableSender) recordPermanentFailure(ctx context.Context, to string, code int, err error) {
+	if !isHardBounce(err) {
+		s.logger.Error("Permanent email delivery failure",
+			"error", err,
+			"smtp_code", code)
+		return
+	}
+
+	s.logger.Warn("Recipient hard-bounced, suppressing further email",
+		"error", err,
+		"smtp_code", code)
+	if err := s.suppressions.SuppressEmail(ctx, to, code, err.Error()); err != nil {
+		s.logger.Error("Failed to record email suppression",
+			"error", err)
+	}
+}
+
+// classifyDeliveryError returns the SMTP reply or HTTP status code of err, if any, and whether
+// retrying cannot help. Errors without a reply (connection failures, timeouts) are transient.
+func classifyDeliveryError(err error) (int, bool) {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode, providerErr.Permanent()
+	}
+	var reply *textproto.Error
+	if !errors.As(err, &reply) {
+		return 0, false
+	}
+	return reply.Code, reply.Code >= 500
+}
+
+// isHardBounce reports whether err rejects the recipient address itself: a 550, 551 or 553 reply
+// whose enhanced status code, when present, is an addressing (5.1.x) or disabled-mailbox (5.2.1)
+// error. Rejections for other reasons, such as policy or authentication, do not suppress the address.
+func isHardBounce(err error) bool {
+	var reply *textproto.Error
+	if !errors.As(err, &reply) {
+		return false
+	}
+	switch reply.Code {
+	case 550, 551, 553:
+	default:
+		return false
+	}
+
+	status, _, _ := strings.Cut(strings.TrimSpace(reply.Msg), " ")
+	if !strings.HasPrefix(status, "5.") {
+		return true
+	}
+	return strings.HasPrefix(status, "5.1.") || status == "5.2.1"
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
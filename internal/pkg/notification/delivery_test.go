@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type scriptedSender struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedSender) Send(ctx context.Context, msg Message) error {
+	s.calls++
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+type mockSuppressionList struct {
+	suppressed map[string]int
+}
+
+func (m *mockSuppressionList) IsEmailSuppressed(ctx context.Context, email string) (bool, error) {
+	_, ok := m.suppressed[email]
+	return ok, nil
+}
+
+func (m *mockSuppressionList) SuppressEmail(ctx context.Context, email string, smtpCode int, reason string) error {
+	m.suppressed[email] = smtpCode
+	return nil
+}
+
+func smtpReply(code int, msg string) error {
+	return fmt.Errorf("failed to send email via smtp.example.com:587: %w", &textproto.Error{Code: code, Msg: msg})
+}
+
+func TestReliableSender_Send(t *testing.T) {
+	tests := []struct {
+		name           string
+		errs           []error
+		expectErr      bool
+		expectCalls    int
+		expectSuppress bool
+	}{
+		{"Delivered", nil, false, 1, false},
+		{"Transient failure retried", []error{smtpReply(451, "4.3.0 Try again later"), errors.New("connection reset")}, false, 3, false},
+		{"Gives up after max attempts", []error{smtpReply(421, "busy"), smtpReply(421, "busy"), smtpReply(421, "busy")}, true, 3, false},
+		{"Hard bounce suppresses", []error{smtpReply(550, "5.1.1 User unknown")}, true, 1, true},
+		{"Policy rejection is not a bounce", []error{smtpReply(550, "5.7.1 Message rejected as spam")}, true, 1, false},
+		{"Authentication failure is not a bounce", []error{smtpReply(535, "5.7.8 Authentication failed")}, true, 1, false},
+		{"API rate limit retried", []error{&ProviderError{Provider: "sendgrid", StatusCode: 429}}, false, 2, false},
+		{"API rejection not retried", []error{&ProviderError{Provider: "sendgrid", StatusCode: 400}}, true, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &scriptedSender{errs: tt.errs}
+			suppressions := &mockSuppressionList{suppressed: map[string]int{}}
+			var delays []time.Duration
+			reliable := NewReliableSender(sender, suppressions, logger.New("test"))
+			reliable.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			err := reliable.Send(context.Background(), Message{To: "runner@example.com", Subject: "Test"})
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, got %v", tt.expectErr, err)
+			}
+			if sender.calls != tt.expectCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.expectCalls, sender.calls)
+			}
+			if _, ok := suppressions.suppressed["runner@example.com"]; ok != tt.expectSuppress {
+				t.Errorf("Expected suppressed %v, got %v", tt.expectSuppress, ok)
+			}
+			for i, delay := range delays {
+				if delay != DefaultSendBackoff<<i {
+					t.Errorf("Expected exponential backoff, got %v", delays)
+				}
+			}
+		})
+	}
+}
+
+func TestReliableSender_SkipsSuppressedRecipients(t *testing.T) {
+	sender := &scriptedSender{}
+	suppressions := &mockSuppressionList{suppressed: map[string]int{"runner@example.com": 550}}
+
+	err := NewReliableSender(sender, suppressions, logger.New("test")).Send(context.Background(), Message{To: "runner@example.com"})
+	if err != nil || sender.calls != 0 {
+		t.Errorf("Expected the suppressed recipient to be skipped, got %v after %d attempts", err, sender.calls)
+	}
+}
@@ -0,0 +1,190 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// KindDigest is the daily summary of a digest-mode user's runs
+const KindDigest = "digest"
+
+// digestBatchSize is the number of users loaded at a time; a run pages through all of them
+const digestBatchSize = 500
+
+// DigestStore holds the events collected for digest-mode users
+type DigestStore interface {
+	ListDigestUsers(ctx context.Context, afterID, limit int) ([]database.DigestUser, error)
+	ListPendingNotifications(ctx context.Context, userID int) ([]database.PendingNotification, error)
+	CompleteDigest(ctx context.Context, userID int, eventIDs []int, sentAt time.Time) error
+}
+
+// DigestScheduler sends each digest-mode user one summary of the day's runs at their chosen local
+// time. Users with no pending events get nothing.
+type DigestScheduler struct {
+	store        DigestStore
+	deliverer    Deliverer
+	dashboardURL string
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewDigestScheduler creates a new digest scheduler; dashboardURL is linked from the digest
+func NewDigestScheduler(store DigestStore, deliverer Deliverer, dashboardURL string, logger *logger.Logger) *DigestScheduler {
+	return &DigestScheduler{
+		store:        store,
+		deliverer:    deliverer,
+		dashboardURL: dashboardURL,
+		logger:       logger.WithContext("component", "digest_scheduler"),
+		now:          time.Now,
+	}
+}
+
+// Run sends the digests that are due and returns the number sent
+func (s *DigestScheduler) Run(ctx context.Context) (int, error) {
+	now := s.now()
+	sent, checked, afterID := 0, 0, 0
+	for {
+		users, err := s.store.ListDigestUsers(ctx, afterID, digestBatchSize)
+		if err != nil {
+			return sent, fmt.Errorf("failed to list digest users: %w", err)
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			checked++
+			if !DigestDue(now, user.Timezone, user.DigestTime, user.LastDigestAt) {
+				continue
+			}
+
+			// A failed digest stays pending and is retried on the next run
+			delivered, err := s.send(ctx, user, now)
+			if err != nil {
+				s.logger.Error("Failed to send digest",
+					"error", err,
+					"user_id", user.UserID)
+				continue
+			}
+			if delivered {
+				sent++
+			}
+		}
+
+		if len(users) < digestBatchSize {
+			break
+		}
+		afterID = users[len(users)-1].UserID
+	}
+
+	if sent > 0 {
+		s.logger.Info("Digests sent",
+			"users_checked", checked,
+			"digests_sent", sent)
+	}
+	return sent, nil
+}
+
+// send delivers one user's digest and clears the events it covered. It reports false without
+// sending when the events were cleared since the user was listed.
+func (s *DigestScheduler) send(ctx context.Context, user database.DigestUser, now time.Time) (bool, error) {
+	events, err := s.store.ListPendingNotifications(ctx, user.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list pending notifications: %w", err)
+	}
+	if len(events) == 0 {
+		return false, nil
+	}
+
+	to := Recipient{UserID: user.UserID, Email: user.Email}
+	if err := s.deliverer.Deliver(ctx, to, BuildDigestNotification(user, events, s.dashboardURL)); err != nil {
+		return false, err
+	}
+
+	ids := make([]int, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return true, s.store.CompleteDigest(ctx, user.UserID, ids, now)
+}
+
+// ParseDigestTime parses a local digest time in 24-hour HH:MM form
+func ParseDigestTime(value string) (hour, minute int, err error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil || len(value) != 5 {
+		return 0, 0, fmt.Errorf("digest time must be in HH:MM format")
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// DigestDue reports whether a digest scheduled at digestTime in timezone is due at now: today's
+// local digest time has passed and no digest was sent since. Unknown timezones are treated as UTC.
+func DigestDue(now time.Time, timezone, digestTime string, lastDigestAt *time.Time) bool {
+	hour, minute, err := ParseDigestTime(digestTime)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if local.Before(scheduled) {
+		return false
+	}
+	return lastDigestAt == nil || lastDigestAt.Before(scheduled)
+}
+
+// BuildDigestNotification summarizes the pending events in the user's locale, with event times in
+// the user's timezone
+func BuildDigestNotification(user database.DigestUser, events []database.PendingNotification, dashboardURL string) Notification {
+	t := NewTranslator(user.Locale)
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	var synced, activities, failed int
+	items := make([]string, 0, len(events))
+	for _, event := range events {
+		at := event.OccurredAt.In(loc).Format("15:04")
+		trigger := triggerLabel(t, event.TriggerType)
+		if event.Kind == KindSyncFailed {
+			failed++
+			items = append(items, t.T("digest.item_failed", at, trigger))
+			continue
+		}
+		synced++
+		activities += event.ActivitiesCount
+		items = append(items, t.T("digest.item_synced", at, trigger, event.ActivitiesCount))
+	}
+
+	paragraphs := []string{t.T("digest.synced", synced, activities)}
+	severity := SeverityInfo
+	if failed > 0 {
+		paragraphs = append(paragraphs, t.T("digest.failed", failed))
+		severity = SeverityWarning
+	}
+
+	return Notification{
+		Kind:         KindDigest,
+		Severity:     severity,
+		Preference:   database.PreferenceDigest,
+		Locale:       t.Locale(),
+		Title:        t.T("digest.title", t.Date(events[len(events)-1].OccurredAt.In(loc))),
+		Greeting:     t.T("common.greeting", user.Name),
+		Paragraphs:   paragraphs,
+		ItemsHeading: t.T("digest.items_heading"),
+		Items:        items,
+		LinkText:     t.T("run_summary.link_text"),
+		LinkLabel:    t.T("run_summary.link_label"),
+		LinkURL:      dashboardURL,
+		Note:         t.T("digest.note"),
+	}
+}
@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockDigestStore struct {
+	users     []database.DigestUser
+	pending   map[int][]database.PendingNotification
+	completed map[int][]int
+}
+
+func (m *mockDigestStore) ListDigestUsers(ctx context.Context, afterID, limit int) ([]database.DigestUser, error) {
+	var users []database.DigestUser
+	for _, user := range m.users {
+		if user.UserID > afterID && len(users) < limit {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockDigestStore) ListPendingNotifications(ctx context.Context, userID int) ([]database.PendingNotification, error) {
+	return m.pending[userID], nil
+}
+
+func (m *mockDigestStore) CompleteDigest(ctx context.Context, userID int, eventIDs []int, sentAt time.Time) error {
+	m.completed[userID] = eventIDs
+	return nil
+}
+
+func TestDigestDue(t *testing.T) {
+	// 17:30 UTC is 19:30 in Madrid (CEST) and 13:30 in New York (EDT)
+	now := time.Date(2024, 6, 20, 17, 30, 0, 0, time.UTC)
+	sentAt := func(hour int) *time.Time {
+		at := time.Date(2024, 6, 20, hour, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	tests := []struct {
+		name         string
+		timezone     string
+		digestTime   string
+		lastDigestAt *time.Time
+		expected     bool
+	}{
+		{"Past digest time, never sent", "Europe/Madrid", "18:00", nil, true},
+		{"Before digest time", "America/New_York", "18:00", nil, false},
+		{"Already sent today", "Europe/Madrid", "18:00", sentAt(16), false},
+		{"Sent before today's digest time", "Europe/Madrid", "18:00", sentAt(15), true},
+		{"Unknown timezone uses UTC", "Mars/Olympus", "17:00", nil, true},
+		{"Invalid digest time", "UTC", "7pm", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DigestDue(now, tt.timezone, tt.digestTime, tt.lastDigestAt); got != tt.expected {
+				t.Errorf("DigestDue() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDigestTime(t *testing.T) {
+	if hour, minute, err := ParseDigestTime("07:45"); err != nil || hour != 7 || minute != 45 {
+		t.Errorf("Expected 07:45 to parse, got %d:%d, %v", hour, minute, err)
+	}
+	for _, value := range []string{"", "7:45", "24:00", "18:60", "18:00:00"} {
+		if _, _, err := ParseDigestTime(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestDigestScheduler_Run(t *testing.T) {
+	now := time.Date(2024, 6, 20, 19, 0, 0, 0, time.UTC)
+	store := &mockDigestStore{
+		users: []database.DigestUser{
+			{UserID: 1, Email: "due@example.com", Name: "Due", Timezone: "UTC", DigestTime: "18:00"},
+			{UserID: 2, Email: "later@example.com", Name: "Later", Timezone: "UTC", DigestTime: "21:00"},
+		},
+		pending: map[int][]database.PendingNotification{
+			1: {
+				{ID: 10, UserID: 1, Kind: KindRunSummary, TriggerType: "schedule", ActivitiesCount: 2, OccurredAt: now.Add(-10 * time.Hour)},
+				{ID: 11, UserID: 1, Kind: KindSyncFailed, TriggerType: "manual_sync", OccurredAt: now.Add(-2 * time.Hour)},
+			},
+			2: {{ID: 12, UserID: 2, Kind: KindRunSummary, ActivitiesCount: 1, OccurredAt: now.Add(-time.Hour)}},
+		},
+		completed: map[int][]int{},
+	}
+	deliverer := &mockDeliverer{}
+	scheduler := NewDigestScheduler(store, deliverer, "https://app.example.com", logger.New("test"))
+	scheduler.now = func() time.Time { return now }
+
+	sent, err := scheduler.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(deliverer.delivered) != 1 {
+		t.Fatalf("Expected 1 digest, got %d", sent)
+	}
+	if to := deliverer.recipients[0]; to.UserID != 1 || to.ChatOnly {
+		t.Errorf("Unexpected recipient: %+v", to)
+	}
+	if ids := store.completed[1]; len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+		t.Errorf("Expected the digested events to be completed, got %v", ids)
+	}
+	if _, ok := store.completed[2]; ok {
+		t.Error("Expected the digest that is not due to stay pending")
+	}
+
+	n := deliverer.delivered[0]
+	if n.Kind != KindDigest || n.Severity != SeverityWarning || len(n.Items) != 2 {
+		t.Errorf("Unexpected digest: %+v", n)
+	}
+	if !strings.Contains(n.Paragraphs[0], "1, with 2 activities") || !strings.Contains(n.Items[1], "manual sync failed") {
+		t.Errorf("Unexpected digest content: %+v", n)
+	}
+}
+
+func TestDigestScheduler_RunPagesPastFirstBatch(t *testing.T) {
+	now := time.Date(2024, 6, 20, 19, 0, 0, 0, time.UTC)
+	sentToday := now.Add(-30 * time.Minute)
+	store := &mockDigestStore{pending: map[int][]database.PendingNotification{}, completed: map[int][]int{}}
+
+	// A full batch of users whose digest already went out today, then one that is due
+	for id := 1; id <= digestBatchSize; id++ {
+		store.users = append(store.users, database.DigestUser{UserID: id, Timezone: "UTC", DigestTime: "18:00", LastDigestAt: &sentToday})
+	}
+	dueID := digestBatchSize + 1
+	store.users = append(store.users, database.DigestUser{UserID: dueID, Email: "due@example.com", Timezone: "UTC", DigestTime: "18:00"})
+	store.pending[dueID] = []database.PendingNotification{{ID: 20, UserID: dueID, Kind: KindRunSummary, OccurredAt: now.Add(-time.Hour)}}
+	// Listed but its events were cleared since: nothing is sent and nothing counted
+	emptyID := digestBatchSize + 2
+	store.users = append(store.users, database.DigestUser{UserID: emptyID, Timezone: "UTC", DigestTime: "18:00"})
+
+	deliverer := &mockDeliverer{}
+	scheduler := NewDigestScheduler(store, deliverer, "https://app.example.com", logger.New("test"))
+	scheduler.now = func() time.Time { return now }
+
+	sent, err := scheduler.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(deliverer.delivered) != 1 || deliverer.recipients[0].UserID != dueID {
+		t.Errorf("Expected only the user past the first batch to get a digest, got %d sent", sent)
+	}
+	if _, ok := store.completed[emptyID]; ok {
+		t.Error("Expected no digest to be recorded for a user without events")
+	}
+}
@@ -0,0 +1,168 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Recipient identifies who a notification is for
+type Recipient struct {
+	UserID int
+	Email  string
+
+	// ChatOnly notifications (e.g. per-run summaries) are dropped instead of emailed
+	// when the user has not chosen a chat channel
+	ChatOnly bool
+}
+
+// Deliverer delivers a notification to a user over their preferred channel
+type Deliverer interface {
+	Deliver(ctx context.Context, to Recipient, n Notification) error
+}
+
+// ChannelPreferences looks up the channel a user wants notifications delivered to and the kinds
+// of notification they want
+type ChannelPreferences interface {
+	GetNotificationChannel(ctx context.Context, userID int) (*database.NotificationChannel, error)
+}
+
+// Dispatcher delivers notifications over each user's preferred channel, falling back to email
+// when the chat channel is not fully configured. Notifications the user opted out of are dropped,
+// and non-critical ones sent during the user's quiet hours are held until the hours end.
+type Dispatcher struct {
+	email  EmailSender
+	chat   ChatPoster
+	prefs  ChannelPreferences
+	holder NotificationHolder
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewDispatcher creates a dispatcher. email may be nil when SMTP is not configured, in which case
+// only chat notifications can be delivered. holder may be nil to ignore quiet hours.
+func NewDispatcher(email EmailSender, chat ChatPoster, prefs ChannelPreferences, holder NotificationHolder, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		email:  email,
+		chat:   chat,
+		prefs:  prefs,
+		holder: holder,
+		logger: logger.WithContext("component", "notification_dispatcher"),
+		now:    time.Now,
+	}
+}
+
+// Deliver sends the notification to the recipient's preferred channel
+func (d *Dispatcher) Deliver(ctx context.Context, to Recipient, n Notification) error {
+	pref, err := d.prefs.GetNotificationChannel(ctx, to.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to read notification channel: %w", err)
+	}
+	if !allowed(pref, n) {
+		d.logger.Debug("Notification skipped by user preference",
+			"user_id", to.UserID,
+			"kind", n.Kind,
+			"preference", n.Preference)
+		return nil
+	}
+	if held, err := d.hold(ctx, to, pref, n); held || err != nil {
+		return err
+	}
+
+	if pref != nil && IsChatChannel(pref.Channel) && pref.WebhookURL != "" {
+		if err := d.chat.Post(ctx, pref.Channel, pref.WebhookURL, n); err != nil {
+			return err
+		}
+		d.logger.Debug("Notification posted to chat",
+			"user_id", to.UserID,
+			"kind", n.Kind,
+			"channel", pref.Channel)
+		return nil
+	}
+
+	if to.ChatOnly {
+		return nil
+	}
+	if d.email == nil {
+		return fmt.Errorf("email delivery is not configured")
+	}
+	msg, err := RenderEmail(to.Email, n)
+	if err != nil {
+		return err
+	}
+	return d.email.Send(ctx, msg)
+}
+
+// hold stores the notification for later when the user is in quiet hours, and reports whether it did
+func (d *Dispatcher) hold(ctx context.Context, to Recipient, pref *database.NotificationChannel, n Notification) (bool, error) {
+	if d.holder == nil || pref == nil || n.Critical() {
+		return false, nil
+	}
+	releaseAt, quiet := QuietHoursEnd(d.now(), pref.Timezone, pref.QuietHoursStart, pref.QuietHoursEnd)
+	if !quiet {
+		return false, nil
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return false, err
+	}
+	err = d.holder.HoldNotification(ctx, database.HeldNotification{
+		UserID:    to.UserID,
+		Email:     to.Email,
+		ChatOnly:  to.ChatOnly,
+		Kind:      n.Kind,
+		Payload:   payload,
+		ReleaseAt: releaseAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to hold notification: %w", err)
+	}
+	d.logger.Debug("Notification held for quiet hours",
+		"user_id", to.UserID,
+		"kind", n.Kind,
+		"release_at", releaseAt)
+	return true, nil
+}
+
+// allowed reports whether the user's preferences let the notification through
+func allowed(pref *database.NotificationChannel, n Notification) bool {
+	return n.Preference == "" || pref == nil || pref.Preferences.Allows(n.Preference)
+}
+
+// EmailDeliverer delivers every notification by email
+type EmailDeliverer struct {
+	sender EmailSender
+	prefs  ChannelPreferences
+}
+
+// NewEmailDeliverer creates a deliverer that ignores the chosen channel but drops notifications the
+// user opted out of. prefs may be nil to deliver every notification.
+func NewEmailDeliverer(sender EmailSender, prefs ChannelPreferences) *EmailDeliverer {
+	return &EmailDeliverer{sender: sender, prefs: prefs}
+}
+
+// Deliver emails the notification; chat-only notifications are dropped
+func (d *EmailDeliverer) Deliver(ctx context.Context, to Recipient, n Notification) error {
+	if to.ChatOnly {
+		return nil
+	}
+	if d.prefs != nil && n.Preference != "" {
+		pref, err := d.prefs.GetNotificationChannel(ctx, to.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to read notification preferences: %w", err)
+		}
+		if !allowed(pref, n) {
+			return nil
+		}
+	}
+	msg, err := RenderEmail(to.Email, n)
+	if err != nil {
+		return err
+	}
+	return d.sender.Send(ctx, msg)
+}
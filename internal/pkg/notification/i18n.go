@@ -0,0 +1,136 @@
+package notification
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/format"
+)
+
+// DefaultLocale is used for users without a locale and for messages missing from a catalog
+const DefaultLocale = "en"
+
+//go:embed templates
+var templateFS embed.FS
+
+// catalogs maps each supported locale to its messages, loaded from templates/locales/<locale>.json
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := templateFS.ReadDir("templates/locales")
+	if err != nil {
+		panic(fmt.Sprintf("notification: failed to read embedded locales: %v", err))
+	}
+
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := templateFS.ReadFile(path.Join("templates/locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("notification: failed to read locale %s: %v", entry.Name(), err))
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("notification: invalid locale %s: %v", entry.Name(), err))
+		}
+		if len(strings.Fields(messages["calendar.months"])) != 12 || len(strings.Fields(messages["calendar.weekdays"])) != 7 {
+			panic(fmt.Sprintf("notification: locale %s needs 12 month and 7 weekday names", entry.Name()))
+		}
+		result[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+
+	if _, ok := result[DefaultLocale]; !ok {
+		panic("notification: default locale catalog is missing")
+	}
+	return result
+}
+
+// SupportedLocales returns the locales notifications can be rendered in
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// ResolveLocale maps a locale tag such as "es-ES" to a supported locale, falling back to the default
+func ResolveLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if _, ok := catalogs[locale]; ok {
+		return locale
+	}
+	if base, _, found := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-"); found {
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return DefaultLocale
+}
+
+// Translator looks up the messages of one locale
+type Translator struct {
+	locale   string
+	messages map[string]string
+}
+
+// NewTranslator returns a translator for the locale, resolved with ResolveLocale
+func NewTranslator(locale string) *Translator {
+	locale = ResolveLocale(locale)
+	return &Translator{locale: locale, messages: catalogs[locale]}
+}
+
+// Locale returns the resolved locale
+func (t *Translator) Locale() string {
+	return t.locale
+}
+
+// T formats the message for key with args. Messages missing from the locale fall back to the
+// default locale, and unknown keys render as the key so gaps are visible rather than blank.
+func (t *Translator) T(key string, args ...interface{}) string {
+	message, ok := t.messages[key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Date formats a calendar date, e.g. "Thursday, 20 June 2024"
+func (t *Translator) Date(date time.Time) string {
+	return t.formatTime("format.date", date)
+}
+
+// DateTime formats a time of day and date, e.g. "15:04 on 20 June 2024"
+func (t *Translator) DateTime(date time.Time) string {
+	return t.formatTime("format.datetime", date)
+}
+
+// Measurements returns a formatter for distances, paces and speeds in the locale's number format
+func (t *Translator) Measurements() format.Formatter {
+	return format.New(t.locale, format.Metric)
+}
+
+func (t *Translator) formatTime(key string, date time.Time) string {
+	months := strings.Fields(t.T("calendar.months"))
+	weekdays := strings.Fields(t.T("calendar.weekdays"))
+
+	return strings.NewReplacer(
+		"{weekday}", weekdays[date.Weekday()],
+		"{day}", strconv.Itoa(date.Day()),
+		"{month}", months[date.Month()-1],
+		"{year}", strconv.Itoa(date.Year()),
+		"{time}", date.Format("15:04"),
+	).Replace(t.T(key))
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for _, locale := range SupportedLocales() {
+		for key := range catalogs[DefaultLocale] {
+			if _, ok := catalogs[locale][key]; !ok {
+				t.Errorf("Locale %s is missing %s", locale, key)
+			}
+		}
+		for key := range catalogs[locale] {
+			if _, ok := catalogs[DefaultLocale][key]; !ok {
+				t.Errorf("Locale %s has %s, which the default locale lacks", locale, key)
+			}
+		}
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	tests := map[string]string{
+		"":      DefaultLocale,
+		"es":    "es",
+		"ES":    "es",
+		"es-MX": "es",
+		"es_AR": "es",
+		"fr":    DefaultLocale,
+	}
+	for input, expected := range tests {
+		if got := ResolveLocale(input); got != expected {
+			t.Errorf("ResolveLocale(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestTranslator_Dates(t *testing.T) {
+	date := time.Date(2024, 6, 20, 6, 30, 0, 0, time.UTC)
+
+	if got := NewTranslator("en").Date(date); got != "Thursday, 20 June 2024" {
+		t.Errorf("Unexpected English date: %s", got)
+	}
+	if got := NewTranslator("es").DateTime(date); got != "06:30 del 20 de junio de 2024" {
+		t.Errorf("Unexpected Spanish date and time: %s", got)
+	}
+}
+
+func TestTranslator_Measurements(t *testing.T) {
+	if got := NewTranslator("en").Measurements().Distance(5200); got != "5.20 km" {
+		t.Errorf("Unexpected English distance: %s", got)
+	}
+	if got := NewTranslator("es-ES").Measurements().Distance(5200); got != "5,20 km" {
+		t.Errorf("Unexpected Spanish distance: %s", got)
+	}
+}
+
+func TestRenderEmail_LocalizedWithHTMLAlternative(t *testing.T) {
+	run := database.FinishedRun{
+		Name:        "<Alex>",
+		Locale:      "es",
+		TriggerType: "schedule",
+		Status:      database.RunStatusFailed,
+		ErrorType:   "GOOGLE_REAUTH_REQUIRED",
+		CompletedAt: time.Date(2024, 6, 20, 6, 30, 0, 0, time.UTC),
+	}
+
+	msg, err := RenderEmail("runner@example.com", BuildFailureAlertNotification(run, "https://app.example.com"))
+	if err != nil {
+		t.Fatalf("RenderEmail failed: %v", err)
+	}
+	if msg.Subject != "La sincronización de tus actividades ha fallado" {
+		t.Errorf("Unexpected subject: %s", msg.Subject)
+	}
+	for _, expected := range []string{"Hola <Alex>:", "programada", "Vuelve a iniciar sesión", "- El equipo de Academy Sync"} {
+		if !strings.Contains(msg.Body, expected) {
+			t.Errorf("Expected text body to contain %q:\n%s", expected, msg.Body)
+		}
+	}
+	for _, expected := range []string{`lang="es"`, "Hola &lt;Alex&gt;:", `href="https://app.example.com"`} {
+		if !strings.Contains(msg.HTMLBody, expected) {
+			t.Errorf("Expected HTML body to contain %q:\n%s", expected, msg.HTMLBody)
+		}
+	}
+	if strings.Contains(msg.HTMLBody, "<Alex>") {
+		t.Error("Expected user text to be escaped in the HTML body")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	for _, notificationType := range PreviewTypes() {
+		for _, locale := range SupportedLocales() {
+			n, err := Preview(notificationType, locale, "https://app.example.com")
+			if err != nil {
+				t.Fatalf("Preview(%s, %s) failed: %v", notificationType, locale, err)
+			}
+			if n.Kind != notificationType || n.Locale != locale || n.Title == "" {
+				t.Errorf("Unexpected preview for %s/%s: %+v", notificationType, locale, n)
+			}
+			if _, err := RenderEmail(PreviewRecipient, n); err != nil {
+				t.Errorf("RenderEmail(%s, %s) failed: %v", notificationType, locale, err)
+			}
+		}
+	}
+
+	if _, err := Preview("unknown", "en", ""); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+func TestBuildMIMEMessage_MultipartAlternative(t *testing.T) {
+	raw := string(buildMIMEMessage("sync@example.com", Message{
+		To:       "runner@example.com",
+		Subject:  "Sincronización",
+		Body:     "plain",
+		HTMLBody: "<p>html</p>",
+	}, time.Now()))
+
+	for _, expected := range []string{"multipart/alternative; boundary=", "Subject: =?UTF-8?q?", "text/plain; charset=UTF-8\r\n\r\nplain", "text/html; charset=UTF-8\r\n\r\n<p>html</p>"} {
+		if !strings.Contains(raw, expected) {
+			t.Errorf("Expected message to contain %q:\n%s", expected, raw)
+		}
+	}
+}
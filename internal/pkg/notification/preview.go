@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+// PreviewRecipient is the address previews are rendered for
+const PreviewRecipient = "runner@example.com"
+
+// previewUser is the sample user previews are rendered for
+var previewUser = database.QuietUser{
+	UserID:         1,
+	Email:          PreviewRecipient,
+	Name:           "Alex",
+	FailedRuns:     3,
+	LastErrorType:  "STRAVA_REAUTH_REQUIRED",
+	HasStrava:      true,
+	HasGoogle:      true,
+	HasSpreadsheet: true,
+}
+
+// previewBuilders build a sample notification of each type from fixed data, so previews are stable
+var previewBuilders = map[string]func(locale, dashboardURL string) Notification{
+	KindQuietFailure: func(locale, dashboardURL string) Notification {
+		user := previewUser
+		user.Locale = locale
+		lastSuccess := time.Date(2024, 6, 20, 8, 0, 0, 0, time.UTC)
+		user.LastSuccessAt = &lastSuccess
+		return BuildQuietFailureNotification(user, 10, 2, dashboardURL)
+	},
+	KindRunSummary: func(locale, dashboardURL string) Notification {
+		return BuildRunSummaryNotification(previewRun(locale, database.RunStatusCompleted, ""), dashboardURL)
+	},
+	KindSyncFailed: func(locale, dashboardURL string) Notification {
+		return BuildFailureAlertNotification(previewRun(locale, database.RunStatusFailed, "SHEETS_ACCESS_ERROR"), dashboardURL)
+	},
+	KindSyncDeferred: func(locale, dashboardURL string) Notification {
+		return BuildDeferralNotification(previewRun(locale, database.RunStatusDeferred, "DAILY_BUDGET_EXCEEDED"), dashboardURL)
+	},
+	KindDigest: func(locale, dashboardURL string) Notification {
+		user := database.DigestUser{UserID: previewUser.UserID, Email: previewUser.Email, Name: previewUser.Name, Locale: locale, Timezone: "UTC", DigestTime: "18:00"}
+		events := []database.PendingNotification{
+			{ID: 1, Kind: KindRunSummary, TriggerType: "schedule", ActivitiesCount: 2, OccurredAt: time.Date(2024, 6, 20, 6, 30, 0, 0, time.UTC)},
+			{ID: 2, Kind: KindSyncFailed, TriggerType: "schedule", ErrorType: "SHEETS_ACCESS_ERROR", OccurredAt: time.Date(2024, 6, 20, 12, 30, 0, 0, time.UTC)},
+			{ID: 3, Kind: KindRunSummary, TriggerType: "manual_sync", ActivitiesCount: 1, OccurredAt: time.Date(2024, 6, 20, 17, 5, 0, 0, time.UTC)},
+		}
+		return BuildDigestNotification(user, events, dashboardURL)
+	},
+	KindNewSignIn: func(locale, dashboardURL string) Notification {
+		alert := database.SignInAlert{
+			SignInEvent: database.SignInEvent{
+				ID:          1,
+				UserID:      previewUser.UserID,
+				IPAddress:   "203.0.113.42",
+				Device:      "Firefox on Windows",
+				Network:     "203.0.113.0/24",
+				NewDevice:   true,
+				NewLocation: true,
+				CreatedAt:   time.Date(2024, 6, 20, 21, 15, 0, 0, time.UTC),
+			},
+			Email:  previewUser.Email,
+			Name:   previewUser.Name,
+			Locale: locale,
+		}
+		return BuildNewSignInNotification(alert, RevokeSessionURL(dashboardURL, "rvk_preview"))
+	},
+}
+
+func previewRun(locale, status, errorType string) database.FinishedRun {
+	return database.FinishedRun{
+		RunID:           1,
+		UserID:          previewUser.UserID,
+		Email:           previewUser.Email,
+		Name:            previewUser.Name,
+		Locale:          locale,
+		TriggerType:     "schedule",
+		Status:          status,
+		ActivitiesCount: 3,
+		ErrorType:       errorType,
+		CompletedAt:     time.Date(2024, 6, 20, 6, 30, 0, 0, time.UTC),
+	}
+}
+
+// PreviewTypes returns the notification types that can be previewed
+func PreviewTypes() []string {
+	types := make([]string, 0, len(previewBuilders))
+	for notificationType := range previewBuilders {
+		types = append(types, notificationType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Preview builds a sample notification of the given type in the given locale
+func Preview(notificationType, locale, dashboardURL string) (Notification, error) {
+	build, ok := previewBuilders[notificationType]
+	if !ok {
+		return Notification{}, fmt.Errorf("unknown notification type %q", notificationType)
+	}
+	return build(ResolveLocale(locale), dashboardURL), nil
+}
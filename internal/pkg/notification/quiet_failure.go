@@ -0,0 +1,218 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// DefaultQuietFailureThresholds are the quiet periods, in days, at which the nudge escalates
+var DefaultQuietFailureThresholds = []int{5, 10, 20}
+
+// quietFailureBatchSize bounds the users examined in a single detection run
+const quietFailureBatchSize = 200
+
+// QuietUserRepository finds users whose automation has gone quiet
+type QuietUserRepository interface {
+	NotificationLog
+	ListQuietUsers(ctx context.Context, kind string, now time.Time, thresholds []int, limit int) ([]database.QuietUser, error)
+}
+
+// QuietFailureDetector finds users whose automation has produced no successful run for several days,
+// whatever the cause, and sends them a nudge with diagnostics. Each quiet streak gets at most one email
+// per escalation level; a successful run starts a new streak.
+type QuietFailureDetector struct {
+	repo         QuietUserRepository
+	deliverer    Deliverer
+	throttle     *Throttle
+	thresholds   []int
+	dashboardURL string
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewQuietFailureDetector creates a new quiet failure detector.
+// thresholds must be ascending day counts; dashboardURL is linked from the nudge.
+func NewQuietFailureDetector(repo QuietUserRepository, deliverer Deliverer, throttle *Throttle, thresholds []int, dashboardURL string, logger *logger.Logger) *QuietFailureDetector {
+	return &QuietFailureDetector{
+		repo:         repo,
+		deliverer:    deliverer,
+		throttle:     throttle,
+		thresholds:   thresholds,
+		dashboardURL: dashboardURL,
+		logger:       logger.WithContext("component", "quiet_failure_detector"),
+		now:          time.Now,
+	}
+}
+
+// Run detects quiet users and sends any due nudges, returning the number of nudges sent
+func (d *QuietFailureDetector) Run(ctx context.Context) (int, error) {
+	if len(d.thresholds) == 0 {
+		return 0, nil
+	}
+
+	now := d.now()
+	users, err := d.repo.ListQuietUsers(ctx, KindQuietFailure, now, d.thresholds, quietFailureBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list quiet users: %w", err)
+	}
+
+	sent := 0
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		ok, err := d.nudge(ctx, user, now)
+		if err != nil {
+			d.logger.Error("Failed to send quiet failure nudge",
+				"error", err,
+				"user_id", user.UserID)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	d.logger.Info("Quiet failure detection completed",
+		"quiet_users", len(users),
+		"nudges_sent", sent)
+
+	return sent, nil
+}
+
+// nudge sends the user's due escalation, if any, and reports whether a nudge was sent
+func (d *QuietFailureDetector) nudge(ctx context.Context, user database.QuietUser, now time.Time) (bool, error) {
+	quietDays := int(now.Sub(user.QuietSince).Hours() / 24)
+	level := EscalationLevel(quietDays, d.thresholds)
+	if level == 0 {
+		return false, nil
+	}
+
+	// Only nudges sent during the current quiet streak count towards escalation
+	last, err := d.repo.GetLastNotification(ctx, user.UserID, KindQuietFailure)
+	if err != nil {
+		return false, err
+	}
+	if last != nil && last.SentAt.After(user.QuietSince) && last.Level >= level {
+		return false, nil
+	}
+
+	allowed, err := d.throttle.Allow(ctx, user.UserID, now)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		d.logger.Debug("Quiet failure nudge throttled",
+			"user_id", user.UserID,
+			"level", level)
+		return false, nil
+	}
+
+	to := Recipient{UserID: user.UserID, Email: user.Email}
+	if err := d.deliverer.Deliver(ctx, to, BuildQuietFailureNotification(user, quietDays, level, d.dashboardURL)); err != nil {
+		return false, err
+	}
+
+	if err := d.repo.RecordNotification(ctx, user.UserID, KindQuietFailure, level); err != nil {
+		// The nudge went out; failing to record it risks a duplicate at the next run
+		d.logger.Error("Failed to record quiet failure nudge",
+			"error", err,
+			"user_id", user.UserID,
+			"level", level)
+	}
+
+	d.logger.Info("Quiet failure nudge sent",
+		"user_id", user.UserID,
+		"quiet_days", quietDays,
+		"level", level,
+		"failed_runs", user.FailedRuns,
+		"last_error_type", user.LastErrorType)
+
+	return true, nil
+}
+
+// EscalationLevel returns how many thresholds quietDays has reached (0 if none)
+func EscalationLevel(quietDays int, thresholds []int) int {
+	level := 0
+	for _, threshold := range thresholds {
+		if quietDays >= threshold {
+			level++
+		}
+	}
+	return level
+}
+
+// BuildQuietFailureNotification describes the nudge with the diagnostics known for the user,
+// in the user's locale
+func BuildQuietFailureNotification(user database.QuietUser, quietDays, level int, dashboardURL string) Notification {
+	t := NewTranslator(user.Locale)
+	n := Notification{
+		Kind:         KindQuietFailure,
+		Severity:     SeverityWarning,
+		Preference:   failurePreference(user.LastErrorType),
+		Locale:       t.Locale(),
+		Title:        t.T("quiet_failure.title", quietDays),
+		Greeting:     t.T("common.greeting", user.Name),
+		ItemsHeading: t.T("common.items_heading"),
+		Items:        quietFailureDiagnostics(t, user),
+		LinkText:     t.T("common.check_connections_text"),
+		LinkLabel:    t.T("common.check_connections_label"),
+		LinkURL:      dashboardURL,
+	}
+	if level > 1 {
+		n.Severity = SeverityError
+		n.Title = t.T("quiet_failure.title_escalated", quietDays)
+		n.Note = t.T("quiet_failure.follow_up")
+	}
+
+	if user.LastSuccessAt != nil {
+		n.Paragraphs = []string{t.T("quiet_failure.last_success", t.Date(*user.LastSuccessAt))}
+	} else {
+		n.Paragraphs = []string{t.T("quiet_failure.never_synced")}
+	}
+	return n
+}
+
+// BuildQuietFailureMessage renders the nudge as an email
+func BuildQuietFailureMessage(user database.QuietUser, quietDays, level int, dashboardURL string) (Message, error) {
+	return RenderEmail(user.Email, BuildQuietFailureNotification(user, quietDays, level, dashboardURL))
+}
+
+// quietFailureDiagnostics explains the likely causes of a quiet streak in user terms
+func quietFailureDiagnostics(t *Translator, user database.QuietUser) []string {
+	var lines []string
+	if !user.HasStrava {
+		lines = append(lines, t.T("diagnostic.no_strava"))
+	}
+	if !user.HasGoogle {
+		lines = append(lines, t.T("diagnostic.no_google"))
+	}
+	if !user.HasSpreadsheet {
+		lines = append(lines, t.T("diagnostic.no_spreadsheet"))
+	}
+
+	switch user.LastErrorType {
+	case "":
+	case "STRAVA_REAUTH_REQUIRED":
+		lines = append(lines, t.T("diagnostic.strava_reauth"))
+	case "GOOGLE_REAUTH_REQUIRED":
+		lines = append(lines, t.T("diagnostic.google_reauth"))
+	case "SHEETS_ACCESS_ERROR", "SHEETS_SCHEMA_ERROR":
+		lines = append(lines, t.T("diagnostic.sheets_access"))
+	default:
+		lines = append(lines, t.T("diagnostic.other_error", user.LastErrorType))
+	}
+
+	if user.FailedRuns > 0 {
+		lines = append(lines, t.T("diagnostic.failed_runs", user.FailedRuns))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, t.T("diagnostic.none"))
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockQuietUserRepository struct {
+	users    []database.QuietUser
+	log      []database.NotificationRecord
+	recorded []database.NotificationRecord
+}
+
+func (m *mockQuietUserRepository) ListQuietUsers(ctx context.Context, kind string, now time.Time, thresholds []int, limit int) ([]database.QuietUser, error) {
+	var users []database.QuietUser
+	for _, user := range m.users {
+		level := EscalationLevel(int(now.Sub(user.QuietSince).Hours()/24), thresholds)
+		nudged := 0
+		for _, record := range m.log {
+			if record.UserID == user.UserID && record.Kind == kind && record.SentAt.After(user.QuietSince) && record.Level > nudged {
+				nudged = record.Level
+			}
+		}
+		if level > nudged && len(users) < limit {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockQuietUserRepository) GetLastNotification(ctx context.Context, userID int, kind string) (*database.NotificationRecord, error) {
+	var last *database.NotificationRecord
+	for i, record := range m.log {
+		if record.UserID == userID && (kind == "" || record.Kind == kind) && (last == nil || record.SentAt.After(last.SentAt)) {
+			last = &m.log[i]
+		}
+	}
+	return last, nil
+}
+
+func (m *mockQuietUserRepository) RecordNotification(ctx context.Context, userID int, kind string, level int) error {
+	m.recorded = append(m.recorded, database.NotificationRecord{UserID: userID, Kind: kind, Level: level})
+	return nil
+}
+
+type mockSender struct {
+	sent []Message
+}
+
+func (m *mockSender) Send(ctx context.Context, msg Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestQuietFailureDetector_Run(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	tests := []struct {
+		name          string
+		quietDays     int
+		log           []database.NotificationRecord
+		expectedLevel int // 0 means no email
+	}{
+		{"Not quiet long enough", 3, nil, 0},
+		{"First nudge", 6, nil, 1},
+		{"Already nudged at this level", 8, []database.NotificationRecord{{UserID: 1, Kind: KindQuietFailure, Level: 1, SentAt: daysAgo(2)}}, 0},
+		{"Escalates", 11, []database.NotificationRecord{{UserID: 1, Kind: KindQuietFailure, Level: 1, SentAt: daysAgo(5)}}, 2},
+		{"Skips to current level", 25, nil, 3},
+		{"Nudge from previous streak ignored", 6, []database.NotificationRecord{{UserID: 1, Kind: KindQuietFailure, Level: 3, SentAt: daysAgo(40)}}, 1},
+		{"Throttled by recent notification", 6, []database.NotificationRecord{{UserID: 1, Kind: "other", SentAt: now.Add(-time.Hour)}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockQuietUserRepository{
+				users: []database.QuietUser{{UserID: 1, Email: "runner@example.com", Name: "Runner", QuietSince: daysAgo(tt.quietDays)}},
+				log:   tt.log,
+			}
+			sender := &mockSender{}
+			detector := NewQuietFailureDetector(repo, NewEmailDeliverer(sender, nil), NewThrottle(repo, DefaultMinNotificationInterval),
+				DefaultQuietFailureThresholds, "https://app.example.com", logger.New("test"))
+			detector.now = func() time.Time { return now }
+
+			sent, err := detector.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if tt.expectedLevel == 0 {
+				if sent != 0 || len(repo.recorded) != 0 {
+					t.Errorf("Expected no nudge, got %d sent", sent)
+				}
+				return
+			}
+			if sent != 1 || len(sender.sent) != 1 {
+				t.Fatalf("Expected 1 nudge, got %d", sent)
+			}
+			if len(repo.recorded) != 1 || repo.recorded[0].Level != tt.expectedLevel {
+				t.Errorf("Expected level %d to be recorded, got %+v", tt.expectedLevel, repo.recorded)
+			}
+		})
+	}
+}
+
+func TestQuietFailureDetector_RunReachesUsersPastFullyNudgedOnes(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	repo := &mockQuietUserRepository{}
+
+	// More long-quiet users than a batch, all already nudged at the highest level
+	for id := 1; id <= quietFailureBatchSize+50; id++ {
+		repo.users = append(repo.users, database.QuietUser{UserID: id, Email: "runner@example.com", QuietSince: now.AddDate(0, 0, -60)})
+		repo.log = append(repo.log, database.NotificationRecord{UserID: id, Kind: KindQuietFailure, Level: 3, SentAt: now.AddDate(0, 0, -30)})
+	}
+	newlyQuiet := quietFailureBatchSize + 51
+	repo.users = append(repo.users, database.QuietUser{UserID: newlyQuiet, Email: "new@example.com", QuietSince: now.AddDate(0, 0, -6)})
+
+	detector := NewQuietFailureDetector(repo, NewEmailDeliverer(&mockSender{}, nil), NewThrottle(repo, DefaultMinNotificationInterval),
+		DefaultQuietFailureThresholds, "https://app.example.com", logger.New("test"))
+	detector.now = func() time.Time { return now }
+
+	sent, err := detector.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(repo.recorded) != 1 || repo.recorded[0].UserID != newlyQuiet {
+		t.Errorf("Expected the newly quiet user to be nudged, got %d sent: %+v", sent, repo.recorded)
+	}
+}
+
+func TestBuildQuietFailureMessage(t *testing.T) {
+	lastSuccess := time.Date(2024, 6, 20, 8, 0, 0, 0, time.UTC)
+	user := database.QuietUser{
+		Email:         "runner@example.com",
+		Name:          "Runner",
+		LastSuccessAt: &lastSuccess,
+		FailedRuns:    4,
+		LastErrorType: "STRAVA_REAUTH_REQUIRED",
+		HasStrava:     true, HasGoogle: true, HasSpreadsheet: true,
+	}
+
+	msg, err := BuildQuietFailureMessage(user, 10, 2, "https://app.example.com")
+	if err != nil {
+		t.Fatalf("BuildQuietFailureMessage failed: %v", err)
+	}
+	if msg.To != user.Email || !strings.HasPrefix(msg.Subject, "Action needed") {
+		t.Errorf("Unexpected recipient or subject: %s / %s", msg.To, msg.Subject)
+	}
+	for _, expected := range []string{"Thursday, 20 June 2024", "reconnect Strava", "4 sync attempts", "https://app.example.com"} {
+		if !strings.Contains(msg.Body, expected) {
+			t.Errorf("Expected body to contain %q:\n%s", expected, msg.Body)
+		}
+	}
+}
+
+func TestBuildMIMEMessage_StripsHeaderInjection(t *testing.T) {
+	raw := string(buildMIMEMessage("sync@example.com", Message{
+		To:      "runner@example.com\r\nBcc: victim@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two",
+	}, time.Now()))
+
+	if strings.Contains(raw, "\r\nBcc:") {
+		t.Errorf("Header injection was not stripped:\n%s", raw)
+	}
+	if !strings.Contains(raw, "line one\r\nline two") {
+		t.Errorf("Expected CRLF line endings in body:\n%s", raw)
+	}
+}
@@ -0,0 +1,144 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// heldReleaseBatchSize bounds the held notifications released in a single run
+const heldReleaseBatchSize = 500
+
+// NotificationHolder stores notifications held during quiet hours
+type NotificationHolder interface {
+	HoldNotification(ctx context.Context, held database.HeldNotification) error
+}
+
+// HeldNotificationStore lists and removes held notifications once quiet hours are over
+type HeldNotificationStore interface {
+	ListDueHeldNotifications(ctx context.Context, now time.Time, limit int) ([]database.HeldNotification, error)
+	DeleteHeldNotification(ctx context.Context, id int) error
+}
+
+// Critical reports whether the notification is delivered during quiet hours. Only security alerts
+// are; anything else can wait until the morning.
+func (n Notification) Critical() bool {
+	return n.Preference == database.PreferenceSecurityAlerts
+}
+
+// ValidateQuietHours checks a quiet hours window of local HH:MM times. The end may be earlier than
+// the start for a window spanning midnight, but the two must differ.
+func ValidateQuietHours(start, end string) error {
+	if _, _, err := ParseDigestTime(start); err != nil {
+		return fmt.Errorf("quiet hours start must be in HH:MM format")
+	}
+	if _, _, err := ParseDigestTime(end); err != nil {
+		return fmt.Errorf("quiet hours end must be in HH:MM format")
+	}
+	if start == end {
+		return fmt.Errorf("quiet hours start and end must differ")
+	}
+	return nil
+}
+
+// QuietHoursEnd reports whether now falls in the quiet hours from start to end (local HH:MM in
+// timezone) and, if so, when they end. Invalid or unset windows are never quiet; unknown
+// timezones are treated as UTC.
+func QuietHoursEnd(now time.Time, timezone, start, end string) (time.Time, bool) {
+	if ValidateQuietHours(start, end) != nil {
+		return time.Time{}, false
+	}
+	startHour, startMinute, _ := ParseDigestTime(start)
+	endHour, endMinute, _ := ParseDigestTime(end)
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	windowStart := time.Date(local.Year(), local.Month(), local.Day(), startHour, startMinute, 0, 0, loc)
+	windowEnd := time.Date(local.Year(), local.Month(), local.Day(), endHour, endMinute, 0, 0, loc)
+
+	if windowStart.Before(windowEnd) {
+		if !local.Before(windowStart) && local.Before(windowEnd) {
+			return windowEnd, true
+		}
+		return time.Time{}, false
+	}
+
+	// The window spans midnight: quiet from the start until midnight and from midnight until the end
+	if !local.Before(windowStart) {
+		return time.Date(local.Year(), local.Month(), local.Day()+1, endHour, endMinute, 0, 0, loc), true
+	}
+	if local.Before(windowEnd) {
+		return windowEnd, true
+	}
+	return time.Time{}, false
+}
+
+// QuietHoursReleaser delivers the notifications held during users' quiet hours once the hours are
+// over. Delivery goes through the dispatcher again, so preferences changed in the meantime apply.
+type QuietHoursReleaser struct {
+	store     HeldNotificationStore
+	deliverer Deliverer
+	logger    *logger.Logger
+	now       func() time.Time
+}
+
+// NewQuietHoursReleaser creates a releaser delivering through deliverer
+func NewQuietHoursReleaser(store HeldNotificationStore, deliverer Deliverer, logger *logger.Logger) *QuietHoursReleaser {
+	return &QuietHoursReleaser{
+		store:     store,
+		deliverer: deliverer,
+		logger:    logger.WithContext("component", "quiet_hours_releaser"),
+		now:       time.Now,
+	}
+}
+
+// Run delivers the held notifications that are due and returns the number delivered
+func (r *QuietHoursReleaser) Run(ctx context.Context) (int, error) {
+	held, err := r.store.ListDueHeldNotifications(ctx, r.now(), heldReleaseBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list held notifications: %w", err)
+	}
+
+	sent := 0
+	for _, h := range held {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		// Like the deliveries they replace, failed releases are not retried
+		if err := r.release(ctx, h); err != nil {
+			r.logger.Error("Failed to release held notification",
+				"error", err,
+				"user_id", h.UserID,
+				"kind", h.Kind)
+		} else {
+			sent++
+		}
+		if err := r.store.DeleteHeldNotification(ctx, h.ID); err != nil {
+			return sent, fmt.Errorf("failed to delete held notification: %w", err)
+		}
+	}
+
+	if len(held) > 0 {
+		r.logger.Info("Held notifications released",
+			"held", len(held),
+			"delivered", sent)
+	}
+	return sent, nil
+}
+
+// release delivers one held notification
+func (r *QuietHoursReleaser) release(ctx context.Context, h database.HeldNotification) error {
+	var n Notification
+	if err := json.Unmarshal(h.Payload, &n); err != nil {
+		return fmt.Errorf("invalid held notification: %w", err)
+	}
+	return r.deliverer.Deliver(ctx, Recipient{UserID: h.UserID, Email: h.Email, ChatOnly: h.ChatOnly}, n)
+}
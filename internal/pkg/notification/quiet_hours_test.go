@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockHeldStore struct {
+	held    []database.HeldNotification
+	deleted []int
+}
+
+func (m *mockHeldStore) HoldNotification(ctx context.Context, held database.HeldNotification) error {
+	held.ID = len(m.held) + 1
+	m.held = append(m.held, held)
+	return nil
+}
+
+func (m *mockHeldStore) ListDueHeldNotifications(ctx context.Context, now time.Time, limit int) ([]database.HeldNotification, error) {
+	var due []database.HeldNotification
+	for _, held := range m.held {
+		if !held.ReleaseAt.After(now) {
+			due = append(due, held)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockHeldStore) DeleteHeldNotification(ctx context.Context, id int) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		start, end string
+		wantQuiet  bool
+		wantEnd    time.Time
+	}{
+		{"Before an overnight window", time.Date(2024, 6, 10, 21, 59, 0, 0, sofia), "22:00", "07:00", false, time.Time{}},
+		{"Evening in an overnight window", time.Date(2024, 6, 10, 23, 30, 0, 0, sofia), "22:00", "07:00", true, time.Date(2024, 6, 11, 7, 0, 0, 0, sofia)},
+		{"Morning in an overnight window", time.Date(2024, 6, 11, 6, 15, 0, 0, sofia), "22:00", "07:00", true, time.Date(2024, 6, 11, 7, 0, 0, 0, sofia)},
+		{"Window end is not quiet", time.Date(2024, 6, 11, 7, 0, 0, 0, sofia), "22:00", "07:00", false, time.Time{}},
+		{"Inside a daytime window", time.Date(2024, 6, 11, 13, 0, 0, 0, sofia), "12:00", "14:00", true, time.Date(2024, 6, 11, 14, 0, 0, 0, sofia)},
+		{"Outside a daytime window", time.Date(2024, 6, 11, 15, 0, 0, 0, sofia), "12:00", "14:00", false, time.Time{}},
+		{"No quiet hours", time.Date(2024, 6, 11, 3, 0, 0, 0, sofia), "", "", false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user's timezone applies whatever zone now is expressed in
+			end, quiet := QuietHoursEnd(tt.now.UTC(), "Europe/Sofia", tt.start, tt.end)
+			if quiet != tt.wantQuiet || !end.Equal(tt.wantEnd) {
+				t.Errorf("QuietHoursEnd() = %v, %t; want %v, %t", end, quiet, tt.wantEnd, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	if err := ValidateQuietHours("22:00", "07:00"); err != nil {
+		t.Errorf("Expected an overnight window to be valid, got %v", err)
+	}
+	for _, window := range [][2]string{{"22:00", "22:00"}, {"10pm", "07:00"}, {"22:00", "24:00"}} {
+		if err := ValidateQuietHours(window[0], window[1]); err == nil {
+			t.Errorf("Expected %v to be rejected", window)
+		}
+	}
+}
+
+func TestDispatcher_HoldsDuringQuietHours(t *testing.T) {
+	now := time.Date(2024, 6, 10, 23, 0, 0, 0, time.UTC)
+	prefs := mockChannelPreferences{
+		1: {Channel: ChannelEmail, Timezone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"},
+	}
+	sender := &mockSender{}
+	holder := &mockHeldStore{}
+	dispatcher := NewDispatcher(sender, &mockChatPoster{}, prefs, holder, logger.New("test"))
+	dispatcher.now = func() time.Time { return now }
+	to := Recipient{UserID: 1, Email: "runner@example.com"}
+
+	failure := BuildFailureAlertNotification(database.FinishedRun{UserID: 1, ErrorType: "SHEETS_API_ERROR"}, "")
+	if err := dispatcher.Deliver(context.Background(), to, failure); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if len(sender.sent) != 0 || len(holder.held) != 1 || !holder.held[0].ReleaseAt.Equal(time.Date(2024, 6, 11, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected the failure alert to be held until 07:00, got emails=%d held=%+v", len(sender.sent), holder.held)
+	}
+
+	// Security alerts bypass quiet hours
+	if err := dispatcher.Deliver(context.Background(), to, BuildNewSignInNotification(database.SignInAlert{}, "")); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if len(sender.sent) != 1 || len(holder.held) != 1 {
+		t.Errorf("Expected the security alert to be emailed immediately, got emails=%d held=%d", len(sender.sent), len(holder.held))
+	}
+
+	// After the window the releaser delivers the held alert through the dispatcher
+	dispatcher.now = func() time.Time { return now.Add(8 * time.Hour) }
+	releaser := NewQuietHoursReleaser(holder, dispatcher, logger.New("test"))
+	releaser.now = dispatcher.now
+	sent, err := releaser.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(sender.sent) != 2 || sender.sent[1].Subject != failure.Title || len(holder.deleted) != 1 {
+		t.Errorf("Expected the held alert to be released, got sent=%d emails=%d deleted=%v", sent, len(sender.sent), holder.deleted)
+	}
+}
@@ -0,0 +1,210 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Notification channels a user can choose from
+const (
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+)
+
+// Notification severities, used for chat colours
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Notification is a channel-independent notification; each channel renders it in its own format
+type Notification struct {
+	Kind     string
+	Severity string
+
+	// Preference names the notification preference (database.Preference*) that lets users opt
+	// out of this notification; empty notifications are always delivered
+	Preference string
+
+	// Locale selects the language of the text added while rendering (signature, footer);
+	// the builders produce the other fields already translated
+	Locale string
+
+	// Title is the email subject and the chat heading
+	Title string
+
+	// Greeting opens emails only (e.g. "Hi Sam,"); chat messages start with the title
+	Greeting   string
+	Paragraphs []string
+
+	// Items are rendered as a bulleted list under ItemsHeading
+	ItemsHeading string
+	Items        []string
+
+	// LinkText introduces LinkURL in emails; LinkLabel names the link in chat
+	LinkText  string
+	LinkLabel string
+	LinkURL   string
+
+	// Note is a closing remark, e.g. that this is a follow-up
+	Note string
+}
+
+// Email templates, embedded with the locale catalogs
+var (
+	emailTextTemplate = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/email.txt.tmpl"))
+	emailHTMLTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/email.html.tmpl"))
+)
+
+// emailData is the data the email templates are executed with
+type emailData struct {
+	Notification
+	Color     string
+	Signature string
+	Footer    string
+}
+
+// RenderEmail renders the notification as an email with a plain-text body and an HTML alternative
+func RenderEmail(to string, n Notification) (Message, error) {
+	t := NewTranslator(n.Locale)
+	n.Locale = t.Locale()
+	if n.LinkLabel == "" {
+		n.LinkLabel = t.T("common.open_app")
+	}
+
+	color, ok := slackColors[n.Severity]
+	if !ok {
+		color = slackColors[SeverityInfo]
+	}
+	data := emailData{
+		Notification: n,
+		Color:        color,
+		Signature:    t.T("email.signature"),
+		Footer:       t.T("email.footer"),
+	}
+
+	var text, html strings.Builder
+	if err := emailTextTemplate.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s email text: %w", n.Kind, err)
+	}
+	if err := emailHTMLTemplate.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s email HTML: %w", n.Kind, err)
+	}
+
+	return Message{To: to, Subject: n.Title, Body: text.String(), HTMLBody: html.String()}, nil
+}
+
+// slackColors and discordColors map severities to attachment/embed colours; emails use the Slack colours
+var (
+	slackColors = map[string]string{
+		SeverityInfo:    "#2eb67d",
+		SeverityWarning: "#ecb22e",
+		SeverityError:   "#e01e5a",
+	}
+	discordColors = map[string]int{
+		SeverityInfo:    0x2eb67d,
+		SeverityWarning: 0xecb22e,
+		SeverityError:   0xe01e5a,
+	}
+)
+
+// RenderSlack renders the notification as a Slack incoming-webhook payload using mrkdwn
+func RenderSlack(n Notification) ([]byte, error) {
+	var b strings.Builder
+	for _, paragraph := range n.Paragraphs {
+		b.WriteString(slackEscape(paragraph) + "\n\n")
+	}
+	if len(n.Items) > 0 {
+		if n.ItemsHeading != "" {
+			b.WriteString("*" + slackEscape(n.ItemsHeading) + "*\n")
+		}
+		for _, item := range n.Items {
+			b.WriteString("• " + slackEscape(item) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if n.LinkURL != "" {
+		fmt.Fprintf(&b, "<%s|%s>\n", n.LinkURL, slackEscape(chatLinkLabel(n)))
+	}
+	if n.Note != "" {
+		b.WriteString("_" + slackEscape(n.Note) + "_\n")
+	}
+
+	color, ok := slackColors[n.Severity]
+	if !ok {
+		color = slackColors[SeverityInfo]
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"text": n.Title, // Shown in push notifications and clients without attachments
+		"attachments": []map[string]interface{}{{
+			"color":     color,
+			"title":     n.Title,
+			"text":      strings.TrimSpace(b.String()),
+			"mrkdwn_in": []string{"text"},
+		}},
+	})
+}
+
+// RenderDiscord renders the notification as a Discord webhook payload with a single embed
+func RenderDiscord(n Notification) ([]byte, error) {
+	var b strings.Builder
+	for _, paragraph := range n.Paragraphs {
+		b.WriteString(discordEscape(paragraph) + "\n\n")
+	}
+	if len(n.Items) > 0 {
+		if n.ItemsHeading != "" {
+			b.WriteString("**" + discordEscape(n.ItemsHeading) + "**\n")
+		}
+		for _, item := range n.Items {
+			b.WriteString("- " + discordEscape(item) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if n.LinkURL != "" {
+		fmt.Fprintf(&b, "[%s](%s)\n", discordEscape(chatLinkLabel(n)), n.LinkURL)
+	}
+
+	color, ok := discordColors[n.Severity]
+	if !ok {
+		color = discordColors[SeverityInfo]
+	}
+
+	embed := map[string]interface{}{
+		"title":       n.Title,
+		"description": strings.TrimSpace(b.String()),
+		"color":       color,
+	}
+	if n.Note != "" {
+		embed["footer"] = map[string]string{"text": n.Note}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"embeds": []interface{}{embed},
+		// Never ping anyone from user-controlled text
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+}
+
+// chatLinkLabel returns the chat label of the notification's link
+func chatLinkLabel(n Notification) string {
+	if n.LinkLabel != "" {
+		return n.LinkLabel
+	}
+	return NewTranslator(n.Locale).T("common.open_app")
+}
+
+// slackEscape escapes the characters Slack treats as control sequences
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// discordEscape escapes markdown so names and error messages render literally
+func discordEscape(text string) string {
+	return strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "~", "\\~", "`", "\\`", "|", "\\|", "[", "\\[", "]", "\\]").Replace(text)
+}
@@ -0,0 +1,298 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/failures"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Notification kinds posted per run
+const (
+	KindRunSummary   = "run_summary"
+	KindSyncFailed   = "sync_failed"
+	KindSyncDeferred = "sync_deferred"
+)
+
+// budgetDeferralErrorType is recorded on runs the engine deferred because the user's daily
+// processing budget was used up
+const budgetDeferralErrorType = "DAILY_BUDGET_EXCEEDED"
+
+// runAlertBatchSize bounds the runs handled in a single poll
+const runAlertBatchSize = 500
+
+// runNotifierCursor names the run notifier's stored position among finished runs
+const runNotifierCursor = "run_notifier"
+
+// RunFeed lists finished runs of users who receive per-run notifications and collects events for
+// users in digest mode
+type RunFeed interface {
+	NotificationLog
+	ListFinishedRuns(ctx context.Context, after database.RunCursor, limit int) ([]database.FinishedRun, error)
+	AddPendingNotification(ctx context.Context, event database.PendingNotification) error
+	GetRunCursor(ctx context.Context, name string) (*database.RunCursor, error)
+	SaveRunCursor(ctx context.Context, name string, cursor database.RunCursor) error
+}
+
+// RunNotifier posts a summary of every run that synced activities, and an alert when a run fails,
+// to users who chose a chat channel. Email users are not notified per run; quiet failure nudges
+// cover them. Failure alerts are limited to one per user per minInterval so a stuck account does
+// not post after every scheduled run. Runs of users in digest mode are stored for the
+// DigestScheduler instead of being posted. Runs deferred by the daily processing budget are
+// announced to every user over their channel, email included, at most once per minInterval.
+// The position among finished runs is stored after every poll, so runs that finish while the
+// service restarts are handled once it is back.
+type RunNotifier struct {
+	feed         RunFeed
+	deliverer    Deliverer
+	minInterval  time.Duration
+	dashboardURL string
+	logger       *logger.Logger
+
+	// cursor is the last run handled; nil until loaded from the feed by the first poll
+	cursor *database.RunCursor
+}
+
+// NewRunNotifier creates a run notifier that resumes after the last run it handled. The first
+// time it runs it starts with runs finishing after now.
+func NewRunNotifier(feed RunFeed, deliverer Deliverer, minInterval time.Duration, dashboardURL string, logger *logger.Logger) *RunNotifier {
+	return &RunNotifier{
+		feed:         feed,
+		deliverer:    deliverer,
+		minInterval:  minInterval,
+		dashboardURL: dashboardURL,
+		logger:       logger.WithContext("component", "run_notifier"),
+	}
+}
+
+// Run handles the runs finished since the previous call and returns the number of posts sent
+func (n *RunNotifier) Run(ctx context.Context) (int, error) {
+	if err := n.loadCursor(ctx); err != nil {
+		return 0, err
+	}
+
+	runs, err := n.feed.ListFinishedRuns(ctx, *n.cursor, runAlertBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list finished runs: %w", err)
+	}
+
+	sent := 0
+	for _, run := range runs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Delivery failures are not retried; a webhook that is down only loses these posts
+		ok, err := n.notify(ctx, run)
+		if err != nil {
+			n.logger.Error("Failed to post run notification",
+				"error", err,
+				"user_id", run.UserID,
+				"run_id", run.RunID)
+		}
+		if ok {
+			sent++
+		}
+		n.cursor = &database.RunCursor{CompletedAt: run.CompletedAt, RunID: run.RunID}
+	}
+
+	// Progress is kept even when the poll is cancelled; a run handled but not stored is posted
+	// again after a restart
+	if len(runs) > 0 {
+		if err := n.feed.SaveRunCursor(context.WithoutCancel(ctx), runNotifierCursor, *n.cursor); err != nil {
+			return sent, fmt.Errorf("failed to save run notifier cursor: %w", err)
+		}
+	}
+	if ctx.Err() != nil {
+		return sent, ctx.Err()
+	}
+
+	if len(runs) > 0 {
+		n.logger.Info("Run notifications processed",
+			"runs", len(runs),
+			"posts_sent", sent)
+	}
+	return sent, nil
+}
+
+// loadCursor reads the stored position on the first poll. Without one the notifier starts at
+// now, and stores it so runs finishing before the first poll that finds any are not skipped.
+func (n *RunNotifier) loadCursor(ctx context.Context) error {
+	if n.cursor != nil {
+		return nil
+	}
+
+	cursor, err := n.feed.GetRunCursor(ctx, runNotifierCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load run notifier cursor: %w", err)
+	}
+	if cursor == nil {
+		cursor = &database.RunCursor{CompletedAt: time.Now()}
+		if err := n.feed.SaveRunCursor(ctx, runNotifierCursor, *cursor); err != nil {
+			return fmt.Errorf("failed to save run notifier cursor: %w", err)
+		}
+	}
+
+	n.cursor = cursor
+	return nil
+}
+
+// notify posts the notification for one run, if it warrants one
+func (n *RunNotifier) notify(ctx context.Context, run database.FinishedRun) (bool, error) {
+	if run.Status == database.RunStatusDeferred {
+		// Only budget deferrals are announced; a job deferred behind another job for the same
+		// user runs again within minutes
+		if run.ErrorType != budgetDeferralErrorType {
+			return false, nil
+		}
+		to := Recipient{UserID: run.UserID, Email: run.Email}
+		return n.alert(ctx, run, to, BuildDeferralNotification(run, n.dashboardURL))
+	}
+	if run.Digest {
+		return false, n.collect(ctx, run)
+	}
+
+	to := Recipient{UserID: run.UserID, Email: run.Email, ChatOnly: true}
+
+	if run.Status != database.RunStatusFailed {
+		if run.ActivitiesCount == 0 {
+			return false, nil
+		}
+		return true, n.deliverer.Deliver(ctx, to, BuildRunSummaryNotification(run, n.dashboardURL))
+	}
+
+	return n.alert(ctx, run, to, BuildFailureAlertNotification(run, n.dashboardURL))
+}
+
+// alert delivers a throttled notification: at most one of its kind per user per minInterval
+func (n *RunNotifier) alert(ctx context.Context, run database.FinishedRun, to Recipient, notification Notification) (bool, error) {
+	last, err := n.feed.GetLastNotification(ctx, run.UserID, notification.Kind)
+	if err != nil {
+		return false, err
+	}
+	if last != nil && run.CompletedAt.Sub(last.SentAt) < n.minInterval {
+		return false, nil
+	}
+
+	if err := n.deliverer.Deliver(ctx, to, notification); err != nil {
+		return false, err
+	}
+	if err := n.feed.RecordNotification(ctx, run.UserID, notification.Kind, 0); err != nil {
+		n.logger.Error("Failed to record run alert",
+			"error", err,
+			"user_id", run.UserID,
+			"kind", notification.Kind)
+	}
+	return true, nil
+}
+
+// collect stores the run's event for the user's next digest. Every failure is kept; the digest
+// is sent at most once a day, so throttling is not needed.
+func (n *RunNotifier) collect(ctx context.Context, run database.FinishedRun) error {
+	event := database.PendingNotification{
+		UserID:          run.UserID,
+		Kind:            KindRunSummary,
+		TriggerType:     run.TriggerType,
+		ActivitiesCount: run.ActivitiesCount,
+		OccurredAt:      run.CompletedAt,
+	}
+	if run.RunID != 0 {
+		event.RunID = &run.RunID
+	}
+
+	if run.Status == database.RunStatusFailed {
+		event.Kind = KindSyncFailed
+		event.ErrorType = run.ErrorType
+	} else if run.ActivitiesCount == 0 {
+		return nil
+	}
+
+	return n.feed.AddPendingNotification(ctx, event)
+}
+
+// BuildRunSummaryNotification describes a successful run in the user's locale
+func BuildRunSummaryNotification(run database.FinishedRun, dashboardURL string) Notification {
+	t := NewTranslator(run.Locale)
+	title := t.T("run_summary.title_other", run.ActivitiesCount)
+	if run.ActivitiesCount == 1 {
+		title = t.T("run_summary.title_one", run.ActivitiesCount)
+	}
+
+	return Notification{
+		Kind:       KindRunSummary,
+		Severity:   SeverityInfo,
+		Preference: database.PreferenceSuccessSummary,
+		Locale:     t.Locale(),
+		Title:      title,
+		Greeting:   t.T("common.greeting", run.Name),
+		Paragraphs: []string{t.T("run_summary.finished", triggerLabel(t, run.TriggerType), t.DateTime(run.CompletedAt.UTC()))},
+		LinkText:   t.T("run_summary.link_text"),
+		LinkLabel:  t.T("run_summary.link_label"),
+		LinkURL:    dashboardURL,
+	}
+}
+
+// BuildFailureAlertNotification describes a failed run in the user's locale. Connection state is
+// not part of the run, so only its error is diagnosed.
+func BuildFailureAlertNotification(run database.FinishedRun, dashboardURL string) Notification {
+	t := NewTranslator(run.Locale)
+	return Notification{
+		Kind:         KindSyncFailed,
+		Severity:     SeverityError,
+		Preference:   failurePreference(run.ErrorType),
+		Locale:       t.Locale(),
+		Title:        t.T("sync_failed.title"),
+		Greeting:     t.T("common.greeting", run.Name),
+		Paragraphs:   []string{t.T("sync_failed.failed", triggerLabel(t, run.TriggerType), t.DateTime(run.CompletedAt.UTC()))},
+		ItemsHeading: t.T("common.items_heading"),
+		Items:        quietFailureDiagnostics(t, database.QuietUser{LastErrorType: run.ErrorType, HasStrava: true, HasGoogle: true, HasSpreadsheet: true}),
+		LinkText:     t.T("common.check_connections_text"),
+		LinkLabel:    t.T("common.check_connections_label"),
+		LinkURL:      dashboardURL,
+		Note:         t.T("sync_failed.note"),
+	}
+}
+
+// BuildDeferralNotification tells the user that a run was postponed because their daily
+// processing budget is used up
+func BuildDeferralNotification(run database.FinishedRun, dashboardURL string) Notification {
+	t := NewTranslator(run.Locale)
+	return Notification{
+		Kind:       KindSyncDeferred,
+		Severity:   SeverityWarning,
+		Preference: database.PreferenceFailures,
+		Locale:     t.Locale(),
+		Title:      t.T("sync_deferred.title"),
+		Greeting:   t.T("common.greeting", run.Name),
+		Paragraphs: []string{t.T("sync_deferred.deferred", triggerLabel(t, run.TriggerType), t.DateTime(run.CompletedAt.UTC()))},
+		LinkText:   t.T("run_summary.link_text"),
+		LinkLabel:  t.T("run_summary.link_label"),
+		LinkURL:    dashboardURL,
+		Note:       t.T("sync_deferred.note"),
+	}
+}
+
+// failurePreference is the preference covering a failure of errorType: failures the user fixes by
+// reconnecting an account are reconnect alerts, the rest are failures
+func failurePreference(errorType string) string {
+	help, _ := failures.Lookup(errorType)
+	if help.Remediation == failures.ReconnectStrava || help.Remediation == failures.ReconnectGoogle {
+		return database.PreferenceReauthAlerts
+	}
+	return database.PreferenceFailures
+}
+
+// triggerLabel names a run trigger in user terms
+func triggerLabel(t *Translator, triggerType string) string {
+	switch triggerType {
+	case "manual_sync":
+		return t.T("trigger.manual")
+	case "schedule":
+		return t.T("trigger.scheduled")
+	default:
+		return triggerType
+	}
+}
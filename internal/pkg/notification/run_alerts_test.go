@@ -0,0 +1,218 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockRunFeed struct {
+	mockQuietUserRepository
+	runs    []database.FinishedRun
+	pending []database.PendingNotification
+	cursor  *database.RunCursor
+}
+
+func (m *mockRunFeed) AddPendingNotification(ctx context.Context, event database.PendingNotification) error {
+	m.pending = append(m.pending, event)
+	return nil
+}
+
+func (m *mockRunFeed) ListFinishedRuns(ctx context.Context, after database.RunCursor, limit int) ([]database.FinishedRun, error) {
+	var runs []database.FinishedRun
+	for _, run := range m.runs {
+		if run.CompletedAt.After(after.CompletedAt) || (run.CompletedAt.Equal(after.CompletedAt) && run.RunID > after.RunID) {
+			runs = append(runs, run)
+		}
+		if len(runs) == limit {
+			break
+		}
+	}
+	return runs, nil
+}
+
+func (m *mockRunFeed) GetRunCursor(ctx context.Context, name string) (*database.RunCursor, error) {
+	return m.cursor, nil
+}
+
+func (m *mockRunFeed) SaveRunCursor(ctx context.Context, name string, cursor database.RunCursor) error {
+	m.cursor = &cursor
+	return nil
+}
+
+type mockDeliverer struct {
+	recipients []Recipient
+	delivered  []Notification
+}
+
+func (m *mockDeliverer) Deliver(ctx context.Context, to Recipient, n Notification) error {
+	m.recipients = append(m.recipients, to)
+	m.delivered = append(m.delivered, n)
+	return nil
+}
+
+func TestRunNotifier_Run(t *testing.T) {
+	start := time.Now()
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	feed := &mockRunFeed{
+		mockQuietUserRepository: mockQuietUserRepository{
+			log: []database.NotificationRecord{{UserID: 3, Kind: KindSyncFailed, SentAt: at(-60)}},
+		},
+		runs: []database.FinishedRun{
+			{RunID: 1, UserID: 1, Name: "Runner", Status: database.RunStatusCompleted, ActivitiesCount: 2, CompletedAt: at(1)},
+			{RunID: 2, UserID: 1, Status: database.RunStatusCompleted, ActivitiesCount: 0, CompletedAt: at(2)},
+			{RunID: 3, UserID: 2, Status: database.RunStatusFailed, ErrorType: "SHEETS_API_ERROR", CompletedAt: at(3)},
+			{RunID: 4, UserID: 3, Status: database.RunStatusFailed, CompletedAt: at(4)}, // Alerted an hour ago
+		},
+	}
+	deliverer := &mockDeliverer{}
+	notifier := NewRunNotifier(feed, deliverer, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+
+	sent, err := notifier.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 2 || len(deliverer.delivered) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", sent)
+	}
+	for _, to := range deliverer.recipients {
+		if !to.ChatOnly {
+			t.Errorf("Expected run notifications to be chat-only: %+v", to)
+		}
+	}
+	if deliverer.delivered[0].Kind != KindRunSummary || deliverer.delivered[0].Title != "Synced 2 activities to your spreadsheet" {
+		t.Errorf("Unexpected run summary: %+v", deliverer.delivered[0])
+	}
+	if deliverer.delivered[1].Kind != KindSyncFailed {
+		t.Errorf("Unexpected failure alert: %+v", deliverer.delivered[1])
+	}
+	if len(feed.recorded) != 1 || feed.recorded[0].UserID != 2 || feed.recorded[0].Kind != KindSyncFailed {
+		t.Errorf("Expected the failure alert to be recorded: %+v", feed.recorded)
+	}
+
+	// Runs already handled are not posted again
+	if sent, _ := notifier.Run(context.Background()); sent != 0 {
+		t.Errorf("Expected no notifications on the second run, got %d", sent)
+	}
+}
+
+func TestRunNotifier_ResumesFromStoredCursor(t *testing.T) {
+	start := time.Now()
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// Runs 1 and 2 finished at the same instant; the previous process handled run 1 and stopped
+	feed := &mockRunFeed{
+		runs: []database.FinishedRun{
+			{RunID: 1, UserID: 1, Status: database.RunStatusCompleted, ActivitiesCount: 1, CompletedAt: at(-10)},
+			{RunID: 2, UserID: 2, Status: database.RunStatusCompleted, ActivitiesCount: 1, CompletedAt: at(-10)},
+			{RunID: 3, UserID: 3, Status: database.RunStatusCompleted, ActivitiesCount: 1, CompletedAt: at(-5)},
+		},
+		cursor: &database.RunCursor{CompletedAt: at(-10), RunID: 1},
+	}
+	deliverer := &mockDeliverer{}
+	notifier := NewRunNotifier(feed, deliverer, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+
+	sent, err := notifier.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 2 || deliverer.recipients[0].UserID != 2 || deliverer.recipients[1].UserID != 3 {
+		t.Fatalf("Expected runs 2 and 3 to be posted, got %+v", deliverer.recipients)
+	}
+	if feed.cursor == nil || feed.cursor.RunID != 3 || !feed.cursor.CompletedAt.Equal(at(-5)) {
+		t.Errorf("Expected the cursor to be stored at run 3, got %+v", feed.cursor)
+	}
+
+	// A new notifier picks up where this one stopped
+	restarted := NewRunNotifier(feed, deliverer, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+	if sent, _ := restarted.Run(context.Background()); sent != 0 {
+		t.Errorf("Expected no notifications after a restart, got %d", sent)
+	}
+}
+
+func TestRunNotifier_StoresStartingCursor(t *testing.T) {
+	feed := &mockRunFeed{}
+	notifier := NewRunNotifier(feed, &mockDeliverer{}, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+
+	before := time.Now()
+	if _, err := notifier.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if feed.cursor == nil || feed.cursor.CompletedAt.Before(before) {
+		t.Errorf("Expected the starting cursor to be stored, got %+v", feed.cursor)
+	}
+}
+
+func TestRunNotifier_DigestUsers(t *testing.T) {
+	start := time.Now()
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	feed := &mockRunFeed{
+		runs: []database.FinishedRun{
+			{RunID: 1, UserID: 1, Status: database.RunStatusCompleted, ActivitiesCount: 2, CompletedAt: at(1), Digest: true},
+			{RunID: 2, UserID: 1, Status: database.RunStatusCompleted, ActivitiesCount: 0, CompletedAt: at(2), Digest: true},
+			{RunID: 3, UserID: 1, Status: database.RunStatusFailed, ErrorType: "SHEETS_API_ERROR", CompletedAt: at(3), Digest: true},
+		},
+	}
+	deliverer := &mockDeliverer{}
+	notifier := NewRunNotifier(feed, deliverer, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+
+	sent, err := notifier.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 0 || len(deliverer.delivered) != 0 {
+		t.Fatalf("Expected digest users not to be notified per run, got %d", sent)
+	}
+	if len(feed.pending) != 2 {
+		t.Fatalf("Expected 2 pending events, got %+v", feed.pending)
+	}
+	if feed.pending[0].Kind != KindRunSummary || feed.pending[0].ActivitiesCount != 2 || *feed.pending[0].RunID != 1 {
+		t.Errorf("Unexpected run summary event: %+v", feed.pending[0])
+	}
+	if feed.pending[1].Kind != KindSyncFailed || feed.pending[1].ErrorType != "SHEETS_API_ERROR" {
+		t.Errorf("Unexpected failure event: %+v", feed.pending[1])
+	}
+}
+
+func TestRunNotifier_DeferredRuns(t *testing.T) {
+	start := time.Now()
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	feed := &mockRunFeed{
+		mockQuietUserRepository: mockQuietUserRepository{
+			log: []database.NotificationRecord{{UserID: 2, Kind: KindSyncDeferred, SentAt: at(-60)}},
+		},
+		runs: []database.FinishedRun{
+			{RunID: 1, UserID: 1, Email: "runner@example.com", Status: database.RunStatusDeferred, ErrorType: "DAILY_BUDGET_EXCEEDED", CompletedAt: at(1), Digest: true},
+			{RunID: 2, UserID: 2, Status: database.RunStatusDeferred, ErrorType: "DAILY_BUDGET_EXCEEDED", CompletedAt: at(2)}, // Notified an hour ago
+			{RunID: 3, UserID: 3, Status: database.RunStatusDeferred, ErrorType: "USER_BUSY", CompletedAt: at(3)},             // Retried within minutes
+		},
+	}
+	deliverer := &mockDeliverer{}
+	notifier := NewRunNotifier(feed, deliverer, DefaultMinNotificationInterval, "https://app.example.com", logger.New("test"))
+
+	sent, err := notifier.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(deliverer.delivered) != 1 {
+		t.Fatalf("Expected 1 deferral notice, got %d", sent)
+	}
+	if deliverer.recipients[0].ChatOnly || deliverer.recipients[0].Email != "runner@example.com" {
+		t.Errorf("Expected deferral notices to reach email users too: %+v", deliverer.recipients[0])
+	}
+	if deliverer.delivered[0].Kind != KindSyncDeferred || deliverer.delivered[0].Title != "Your activity sync was postponed" {
+		t.Errorf("Unexpected deferral notice: %+v", deliverer.delivered[0])
+	}
+	if len(feed.pending) != 0 {
+		t.Errorf("Expected deferred runs not to be collected for the digest: %+v", feed.pending)
+	}
+	if len(feed.recorded) != 1 || feed.recorded[0].Kind != KindSyncDeferred {
+		t.Errorf("Expected the deferral notice to be recorded: %+v", feed.recorded)
+	}
+}
@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSendGridEndpoint is the SendGrid v3 mail send API
+const DefaultSendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridTimeout bounds a single API call
+const sendGridTimeout = 15 * time.Second
+
+// ProviderError is a rejected request to an HTTP email API
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Permanent reports whether retrying the same request cannot succeed. Rate limiting,
+// timeouts and server errors are transient.
+func (e *ProviderError) Permanent() bool {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode == http.StatusRequestTimeout:
+		return false
+	default:
+		return e.StatusCode >= 400 && e.StatusCode < 500
+	}
+}
+
+// SendGridSender delivers messages through the SendGrid HTTP API, for environments such as
+// Cloud Run that block outbound SMTP
+type SendGridSender struct {
+	mu         sync.RWMutex
+	apiKey     string
+	from       string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewSendGridSender creates a sender authenticated with a SendGrid API key that has the
+// Mail Send permission
+func NewSendGridSender(apiKey, from string) *SendGridSender {
+	return &SendGridSender{
+		apiKey:     apiKey,
+		from:       from,
+		endpoint:   DefaultSendGridEndpoint,
+		httpClient: &http.Client{Timeout: sendGridTimeout},
+	}
+}
+
+// SetAPIKey replaces the API key after a rotation
+func (s *SendGridSender) SetAPIKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKey = apiKey
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Send delivers the message; SendGrid accepts it with 202 and delivers asynchronously
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	body := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: s.from},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	}
+	if msg.HTMLBody != "" {
+		body.Content = append(body.Content, sendGridContent{Type: "text/html", Value: msg.HTMLBody})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode sendgrid request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create sendgrid request: %w", err)
+	}
+	s.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	s.mu.RUnlock()
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email via sendgrid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// Errors are reported as {"errors": [{"message": "...", "field": "..."}]}
+	var apiErr struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+	message := http.StatusText(resp.StatusCode)
+	if len(apiErr.Errors) > 0 {
+		message = apiErr.Errors[0].Message
+	}
+	return &ProviderError{Provider: "sendgrid", StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendGridSender_Send(t *testing.T) {
+	var got sendGridRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer SG.test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid, expired, or revoked"}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewSendGridSender("SG.test", "noreply@example.com")
+	sender.endpoint = server.URL
+
+	msg := Message{To: "runner@example.com", Subject: "Sync failed", Body: "plain", HTMLBody: "<p>html</p>"}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if got.From.Email != "noreply@example.com" || got.Personalizations[0].To[0].Email != "runner@example.com" || got.Subject != "Sync failed" {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+	if len(got.Content) != 2 || got.Content[0].Type != "text/plain" || got.Content[1].Value != "<p>html</p>" {
+		t.Errorf("Expected plain text followed by HTML content, got %+v", got.Content)
+	}
+
+	sender.apiKey = "SG.revoked"
+	err := sender.Send(context.Background(), msg)
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized || !providerErr.Permanent() {
+		t.Fatalf("Expected a permanent provider error, got %v", err)
+	}
+	if providerErr.Message != "The provided authorization grant is invalid, expired, or revoked" {
+		t.Errorf("Expected the API error message, got %q", providerErr.Message)
+	}
+}
@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// KindNewSignIn is the security email sent after a sign-in from a new device or location
+const KindNewSignIn = "new_sign_in"
+
+const (
+	// signInAlertBatchSize bounds the sign-ins handled in a single poll
+	signInAlertBatchSize = 100
+
+	// signInAlertMaxAge is how old a sign-in may be and still be emailed; after a long outage
+	// the backlog is skipped rather than sending stale alerts
+	signInAlertMaxAge = 24 * time.Hour
+
+	// revokeLinkLifetime is how long the link to sign out the new session works
+	revokeLinkLifetime = 7 * 24 * time.Hour
+)
+
+// SignInAlertStore lists new-device and new-location sign-ins and records their emails
+// (implemented by *database.SignInRepository)
+type SignInAlertStore interface {
+	ListSignInAlerts(ctx context.Context, after time.Time, limit int) ([]database.SignInAlert, error)
+	SetSignInRevokeToken(ctx context.Context, eventID int, tokenHash string, expiresAt time.Time) error
+	MarkSignInNotified(ctx context.Context, eventID int, notifiedAt time.Time) error
+}
+
+// SignInAlerter emails users when their account is signed in to from a device or network it
+// had not been used from, with a link that signs the new session out. The alert is a security
+// notice, so it is always emailed whatever channel the user chose for sync notifications.
+type SignInAlerter struct {
+	store       SignInAlertStore
+	deliverer   Deliverer
+	frontendURL string
+	logger      *logger.Logger
+	now         func() time.Time
+}
+
+// NewSignInAlerter creates a new sign-in alerter; the revoke links point at frontendURL
+func NewSignInAlerter(store SignInAlertStore, deliverer Deliverer, frontendURL string, logger *logger.Logger) *SignInAlerter {
+	return &SignInAlerter{
+		store:       store,
+		deliverer:   deliverer,
+		frontendURL: frontendURL,
+		logger:      logger.WithContext("component", "sign_in_alerter"),
+		now:         time.Now,
+	}
+}
+
+// Run emails the pending sign-in alerts and returns the number sent
+func (a *SignInAlerter) Run(ctx context.Context) (int, error) {
+	now := a.now()
+	alerts, err := a.store.ListSignInAlerts(ctx, now.Add(-signInAlertMaxAge), signInAlertBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sign-in alerts: %w", err)
+	}
+
+	sent := 0
+	for _, alert := range alerts {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		// A failed alert stays pending and is retried, with a new link, on the next run
+		if err := a.send(ctx, alert, now); err != nil {
+			a.logger.Error("Failed to send new sign-in alert",
+				"error", err,
+				"user_id", alert.UserID,
+				"sign_in_id", alert.ID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		a.logger.Info("New sign-in alerts sent",
+			"alerts", len(alerts),
+			"sent", sent)
+	}
+	return sent, nil
+}
+
+// send stores a fresh revoke link for the sign-in, emails it and marks the sign-in notified.
+// The link is stored first so the emailed one always works.
+func (a *SignInAlerter) send(ctx context.Context, alert database.SignInAlert, now time.Time) error {
+	token, hash, err := auth.NewSessionRevokeToken()
+	if err != nil {
+		return err
+	}
+	if err := a.store.SetSignInRevokeToken(ctx, alert.ID, hash, now.Add(revokeLinkLifetime)); err != nil {
+		return fmt.Errorf("failed to store revoke link: %w", err)
+	}
+
+	to := Recipient{UserID: alert.UserID, Email: alert.Email}
+	if err := a.deliverer.Deliver(ctx, to, BuildNewSignInNotification(alert, RevokeSessionURL(a.frontendURL, token))); err != nil {
+		return err
+	}
+
+	if err := a.store.MarkSignInNotified(ctx, alert.ID, now); err != nil {
+		return fmt.Errorf("failed to mark sign-in notified: %w", err)
+	}
+	return nil
+}
+
+// RevokeSessionURL is the web app page that signs out a session with a new sign-in email's token
+func RevokeSessionURL(frontendURL, token string) string {
+	return strings.TrimSuffix(frontendURL, "/") + "/sessions/revoke?token=" + url.QueryEscape(token)
+}
+
+// BuildNewSignInNotification describes a sign-in from a new device or location in the user's
+// locale, linking to revokeURL to sign the session out
+func BuildNewSignInNotification(alert database.SignInAlert, revokeURL string) Notification {
+	t := NewTranslator(alert.Locale)
+
+	items := []string{t.T("new_sign_in.device", alert.Device)}
+	if alert.IPAddress != "" {
+		items = append(items, t.T("new_sign_in.ip_address", alert.IPAddress))
+	}
+	if alert.NewDevice {
+		items = append(items, t.T("new_sign_in.new_device"))
+	}
+	if alert.NewLocation {
+		items = append(items, t.T("new_sign_in.new_location"))
+	}
+
+	return Notification{
+		Kind:         KindNewSignIn,
+		Severity:     SeverityWarning,
+		Preference:   database.PreferenceSecurityAlerts,
+		Locale:       t.Locale(),
+		Title:        t.T("new_sign_in.title"),
+		Greeting:     t.T("common.greeting", alert.Name),
+		Paragraphs:   []string{t.T("new_sign_in.signed_in", t.DateTime(alert.CreatedAt.UTC()))},
+		ItemsHeading: t.T("new_sign_in.items_heading"),
+		Items:        items,
+		LinkText:     t.T("new_sign_in.link_text"),
+		LinkLabel:    t.T("new_sign_in.link_label"),
+		LinkURL:      revokeURL,
+		Note:         t.T("new_sign_in.note", int(revokeLinkLifetime.Hours()/24)),
+	}
+}
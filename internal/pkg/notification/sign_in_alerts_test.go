@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockSignInAlertStore struct {
+	alerts   []database.SignInAlert
+	tokens   map[int]string
+	notified map[int]time.Time
+}
+
+func (m *mockSignInAlertStore) ListSignInAlerts(ctx context.Context, after time.Time, limit int) ([]database.SignInAlert, error) {
+	var alerts []database.SignInAlert
+	for _, alert := range m.alerts {
+		if _, done := m.notified[alert.ID]; !done && alert.CreatedAt.After(after) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *mockSignInAlertStore) SetSignInRevokeToken(ctx context.Context, eventID int, tokenHash string, expiresAt time.Time) error {
+	m.tokens[eventID] = tokenHash
+	return nil
+}
+
+func (m *mockSignInAlertStore) MarkSignInNotified(ctx context.Context, eventID int, notifiedAt time.Time) error {
+	m.notified[eventID] = notifiedAt
+	return nil
+}
+
+type failingDeliverer struct{}
+
+func (failingDeliverer) Deliver(ctx context.Context, to Recipient, n Notification) error {
+	return errors.New("smtp unavailable")
+}
+
+func TestSignInAlerter_Run(t *testing.T) {
+	now := time.Now()
+	store := &mockSignInAlertStore{
+		alerts: []database.SignInAlert{
+			{
+				SignInEvent: database.SignInEvent{ID: 1, UserID: 7, IPAddress: "203.0.113.42", Device: "Firefox on Windows", NewDevice: true, CreatedAt: now.Add(-time.Minute)},
+				Email:       "runner@example.com",
+				Name:        "Runner",
+			},
+			{
+				// Too old to be worth an alert
+				SignInEvent: database.SignInEvent{ID: 2, UserID: 8, Device: "Safari on iOS", NewLocation: true, CreatedAt: now.Add(-48 * time.Hour)},
+				Email:       "late@example.com",
+			},
+		},
+		tokens:   map[int]string{},
+		notified: map[int]time.Time{},
+	}
+	deliverer := &mockDeliverer{}
+	alerter := NewSignInAlerter(store, deliverer, "https://app.example.com/", logger.New("test"))
+
+	sent, err := alerter.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sent != 1 || len(deliverer.delivered) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", sent)
+	}
+	if _, ok := store.notified[1]; !ok {
+		t.Error("Expected the sign-in to be marked notified")
+	}
+
+	n := deliverer.delivered[0]
+	if deliverer.recipients[0].Email != "runner@example.com" || deliverer.recipients[0].ChatOnly {
+		t.Errorf("Expected the alert to be emailed, got %+v", deliverer.recipients[0])
+	}
+	if n.Kind != KindNewSignIn || !strings.Contains(strings.Join(n.Items, "\n"), "Firefox on Windows") {
+		t.Errorf("Unexpected alert: %+v", n)
+	}
+
+	// The emailed link carries the token whose hash was stored
+	prefix := "https://app.example.com/sessions/revoke?token="
+	if !strings.HasPrefix(n.LinkURL, prefix) {
+		t.Fatalf("Expected a revoke link, got %q", n.LinkURL)
+	}
+	if token := strings.TrimPrefix(n.LinkURL, prefix); auth.HashSessionRevokeToken(token) != store.tokens[1] {
+		t.Error("Expected the stored hash to match the emailed token")
+	}
+
+	// A failed email leaves the sign-in pending for the next run
+	store.alerts[0].ID = 3
+	failing := NewSignInAlerter(store, failingDeliverer{}, "https://app.example.com", logger.New("test"))
+	if sent, err := failing.Run(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("Expected no alert to be sent, got %d, %v", sent, err)
+	}
+	if _, ok := store.notified[3]; ok {
+		t.Error("Expected a failed alert to stay pending")
+	}
+}
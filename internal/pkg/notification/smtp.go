@@ -0,0 +1,132 @@
+// Package notification sends user-facing notifications and enforces the rules that decide
+// when a notification may be sent.
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Plain text, always sent
+
+	// HTMLBody is sent as an alternative to Body when set
+	HTMLBody string
+}
+
+// EmailSender delivers email notifications; SMTPSender is the default implementation and
+// SendGridSender delivers over HTTPS where outbound SMTP is blocked
+type EmailSender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender delivers messages through an SMTP server using PLAIN auth and STARTTLS
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	from     string
+
+	mu       sync.RWMutex
+	password string
+}
+
+// NewSMTPSender creates a new SMTP sender
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// SetPassword replaces the SMTP password after a rotation
+func (s *SMTPSender) SetPassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.password = password
+}
+
+// Send delivers the message. net/smtp does not accept a context, so cancellation is only
+// checked before the connection is opened.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		s.mu.RLock()
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+		s.mu.RUnlock()
+	}
+
+	addr := s.host + ":" + s.port
+	if err := smtp.SendMail(addr, auth, s.from, []string{msg.To}, buildMIMEMessage(s.from, msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+
+	return nil
+}
+
+// buildMIMEMessage renders the headers and body of an email; messages with an HTML body are sent
+// as multipart/alternative so clients without HTML support show the plain text
+func buildMIMEMessage(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(from) + "\r\n")
+	b.WriteString("To: " + headerValue(msg.To) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", headerValue(msg.Subject)) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(crlf(msg.Body))
+		return []byte(b.String())
+	}
+
+	boundary := mimeBoundary()
+	b.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(crlf(msg.Body) + "\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(crlf(msg.HTMLBody) + "\r\n")
+	b.WriteString("--" + boundary + "--\r\n")
+	return []byte(b.String())
+}
+
+// crlf converts line endings to the CRLF required by SMTP
+func crlf(body string) string {
+	return strings.ReplaceAll(body, "\n", "\r\n")
+}
+
+// mimeBoundary returns a random multipart boundary that cannot occur in the rendered parts
+func mimeBoundary() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return "academy-sync-" + hex.EncodeToString(buf)
+}
+
+// headerValue strips line breaks so values cannot inject additional headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(value)
+}
@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+)
+
+// Notification kinds recorded in the notification log
+const (
+	KindQuietFailure = "quiet_failure"
+)
+
+// DefaultMinNotificationInterval is the minimum time between any two notifications to the same user
+const DefaultMinNotificationInterval = 24 * time.Hour
+
+// NotificationLog records sent notifications and answers throttling queries
+type NotificationLog interface {
+	GetLastNotification(ctx context.Context, userID int, kind string) (*database.NotificationRecord, error)
+	RecordNotification(ctx context.Context, userID int, kind string, level int) error
+}
+
+// Throttle enforces the notification throttling rules: a user receives at most one notification
+// of any kind per MinInterval
+type Throttle struct {
+	log         NotificationLog
+	minInterval time.Duration
+}
+
+// NewThrottle creates a throttle backed by the notification log
+func NewThrottle(log NotificationLog, minInterval time.Duration) *Throttle {
+	return &Throttle{log: log, minInterval: minInterval}
+}
+
+// Allow reports whether a notification may be sent to the user at now
+func (t *Throttle) Allow(ctx context.Context, userID int, now time.Time) (bool, error) {
+	last, err := t.log.GetLastNotification(ctx, userID, "")
+	if err != nil {
+		return false, err
+	}
+	return last == nil || now.Sub(last.SentAt) >= t.minInterval, nil
+}
@@ -0,0 +1,74 @@
+// Package objectstore reads and writes objects in Cloud Storage buckets, such as the retention
+// archive and captured Strava responses.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// Content types of the stored objects
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// GCSBucket stores objects in a Cloud Storage bucket
+type GCSBucket struct {
+	objects *storage.ObjectsService
+	bucket  string
+}
+
+// NewGCSBucket creates a client of bucket with the application default credentials unless opts
+// say otherwise
+func NewGCSBucket(ctx context.Context, bucket string, opts ...option.ClientOption) (*GCSBucket, error) {
+	opts = append([]option.ClientOption{option.WithScopes(storage.DevstorageReadWriteScope)}, opts...)
+	service, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+	return &GCSBucket{objects: service.Objects, bucket: bucket}, nil
+}
+
+// Put uploads data as the object name, replacing any object of that name
+func (b *GCSBucket) Put(ctx context.Context, name, contentType string, data []byte) error {
+	object := &storage.Object{Name: name, ContentType: contentType}
+	if _, err := b.objects.Insert(b.bucket, object).Media(bytes.NewReader(data)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload gs://%s/%s: %w", b.bucket, name, err)
+	}
+	return nil
+}
+
+// Get downloads the object name
+func (b *GCSBucket) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := b.objects.Get(b.bucket, name).Context(ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download gs://%s/%s: %w", b.bucket, name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gs://%s/%s: %w", b.bucket, name, err)
+	}
+	return data, nil
+}
+
+// ParseURL splits a gs://bucket/object URL into its bucket and object name
+func ParseURL(url string) (bucket, name string, err error) {
+	path, ok := strings.CutPrefix(url, "gs://")
+	if !ok {
+		return "", "", fmt.Errorf("%q is not a gs:// URL", url)
+	}
+	bucket, name, _ = strings.Cut(path, "/")
+	if bucket == "" || name == "" {
+		return "", "", fmt.Errorf("%q does not name a bucket and object", url)
+	}
+	return bucket, name, nil
+}
@@ -0,0 +1,64 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestGCSBucket(t *testing.T) {
+	var gotPath, gotQuery, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte("{\"id\":1}\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"automation_runs/2026-10-16/1-2.ndjson","bucket":"academy-archive"}`))
+	}))
+	defer server.Close()
+
+	bucket, err := NewGCSBucket(context.Background(), "academy-archive",
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("NewGCSBucket failed: %v", err)
+	}
+
+	if err := bucket.Put(context.Background(), "automation_runs/2026-10-16/1-2.ndjson", ContentTypeNDJSON, []byte("{\"id\":1}\n{\"id\":2}\n")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !strings.HasSuffix(gotPath, "/b/academy-archive/o") || !strings.Contains(gotQuery, "uploadType=multipart") {
+		t.Errorf("Unexpected upload request %s?%s", gotPath, gotQuery)
+	}
+	if !strings.Contains(gotBody, `"name":"automation_runs/2026-10-16/1-2.ndjson"`) || !strings.Contains(gotBody, "{\"id\":1}\n{\"id\":2}\n") {
+		t.Errorf("Expected the object metadata and rows in the upload, got %q", gotBody)
+	}
+
+	data, err := bucket.Get(context.Background(), "strava/7/capture.json")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(data) != "{\"id\":1}\n" || !strings.HasSuffix(gotPath, "/b/academy-archive/o/strava/7/capture.json") || !strings.Contains(gotQuery, "alt=media") {
+		t.Errorf("Unexpected download %q from %s?%s", data, gotPath, gotQuery)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	bucket, name, err := ParseURL("gs://academy-captures/strava/7/capture.json")
+	if err != nil || bucket != "academy-captures" || name != "strava/7/capture.json" {
+		t.Errorf("Unexpected split %q %q (%v)", bucket, name, err)
+	}
+	for _, url := range []string{"strava/7/capture.json", "gs://academy-captures", "gs:///capture.json"} {
+		if _, _, err := ParseURL(url); err == nil {
+			t.Errorf("Expected %q to be refused", url)
+		}
+	}
+}
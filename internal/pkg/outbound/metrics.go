@@ -0,0 +1,108 @@
+package outbound
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProviderStats are the outbound request counters of one provider since the process started
+type ProviderStats struct {
+	Requests      int64   `json:"requests"`       // Attempts, retries included
+	NetworkErrors int64   `json:"network_errors"` // Attempts that got no response
+	Status2xx     int64   `json:"status_2xx"`
+	Status4xx     int64   `json:"status_4xx"`
+	Status429     int64   `json:"status_429"` // Also counted in Status4xx
+	Status5xx     int64   `json:"status_5xx"`
+	Retries       int64   `json:"retries"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`
+	MaxLatencyMs  int64   `json:"max_latency_ms"`
+}
+
+type providerCounters struct {
+	requests, networkErrors         atomic.Int64
+	status2xx, status4xx, status429 atomic.Int64
+	status5xx, retries              atomic.Int64
+	totalLatencyMs, maxLatencyMs    atomic.Int64
+}
+
+var (
+	countersMu sync.Mutex
+	providers  = map[string]*providerCounters{}
+)
+
+// counters returns the counters of provider, creating them on first use
+func counters(provider string) *providerCounters {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+
+	c, ok := providers[provider]
+	if !ok {
+		c = &providerCounters{}
+		providers[provider] = c
+	}
+	return c
+}
+
+// Snapshot returns the counters of every provider called so far
+func Snapshot() map[string]ProviderStats {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+
+	snapshot := make(map[string]ProviderStats, len(providers))
+	for provider, c := range providers {
+		stats := ProviderStats{
+			Requests:      c.requests.Load(),
+			NetworkErrors: c.networkErrors.Load(),
+			Status2xx:     c.status2xx.Load(),
+			Status4xx:     c.status4xx.Load(),
+			Status429:     c.status429.Load(),
+			Status5xx:     c.status5xx.Load(),
+			Retries:       c.retries.Load(),
+			MaxLatencyMs:  c.maxLatencyMs.Load(),
+		}
+		if stats.Requests > 0 {
+			stats.AvgLatencyMs = float64(c.totalLatencyMs.Load()) / float64(stats.Requests)
+		}
+		snapshot[provider] = stats
+	}
+	return snapshot
+}
+
+// metricsTransport counts every attempt by outcome and records its latency
+type metricsTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Milliseconds()
+
+	c := counters(t.provider)
+	c.requests.Add(1)
+	c.totalLatencyMs.Add(latency)
+	for {
+		max := c.maxLatencyMs.Load()
+		if latency <= max || c.maxLatencyMs.CompareAndSwap(max, latency) {
+			break
+		}
+	}
+
+	switch {
+	case err != nil:
+		c.networkErrors.Add(1)
+	case resp.StatusCode >= 500:
+		c.status5xx.Add(1)
+	case resp.StatusCode >= 400:
+		c.status4xx.Add(1)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.status429.Add(1)
+		}
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		c.status2xx.Add(1)
+	}
+	return resp, err
+}
@@ -0,0 +1,120 @@
+// Package outbound provides the instrumented HTTP transport every call to Strava and Google goes
+// through, so all outbound traffic is observed the same way. The chain, outermost first:
+//
+//	user agent   identifies the service to the provider
+//	tracing      sends the trace_id of the job or request as X-Request-ID
+//	retry        retries idempotent requests after network errors and 502, 503 and 504
+//	metrics      counts attempts, statuses and latency per provider (see Snapshot)
+//	logging      logs each attempt at debug level and failures as warnings
+//
+// Retries sit outside metrics and logging, so every attempt is counted and logged.
+package outbound
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Providers label metrics and logs
+const (
+	ProviderStrava = "strava"
+	ProviderGoogle = "google"
+)
+
+// UserAgent is sent with every outbound request; Google's client libraries append their own
+const UserAgent = "Academy-Sync-Automation/1.0"
+
+// RequestIDHeader carries the trace_id of the job or request an outbound call is made for
+const RequestIDHeader = "X-Request-ID"
+
+// NewTransport wraps base (nil for http.DefaultTransport) in the instrumented chain. Requests
+// are logged with log, which may be nil to log nothing.
+func NewTransport(provider string, log *logger.Logger, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var rt http.RoundTripper = loggingTransport{provider: provider, log: log, next: base}
+	rt = metricsTransport{provider: provider, next: rt}
+	rt = retryTransport{provider: provider, log: log, policy: DefaultRetryPolicy(), next: rt}
+	rt = tracingTransport{next: rt}
+	return userAgentTransport{next: rt}
+}
+
+// NewClient returns an HTTP client whose requests go through the chain
+func NewClient(provider string, log *logger.Logger, timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport(provider, log, nil)}
+}
+
+// GoogleOption authorizes Google API requests with source and sends them through the chain
+func GoogleOption(log *logger.Logger, source oauth2.TokenSource) option.ClientOption {
+	return option.WithHTTPClient(&http.Client{
+		Transport: &oauth2.Transport{Source: source, Base: NewTransport(ProviderGoogle, log, nil)},
+	})
+}
+
+// userAgentTransport sets the User-Agent, keeping any agent a client library set after it
+type userAgentTransport struct {
+	next http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	agent := UserAgent
+	if existing := req.Header.Get("User-Agent"); existing != "" {
+		agent += " " + existing
+	}
+	req.Header.Set("User-Agent", agent)
+	return t.next.RoundTrip(req)
+}
+
+// tracingTransport propagates the trace_id log attribute of the request context
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if traceID, ok := logger.ContextAttr(req.Context(), "trace_id"); ok && req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, fmt.Sprint(traceID))
+	}
+	return t.next.RoundTrip(req)
+}
+
+// loggingTransport logs every attempt with the job attributes of its context
+type loggingTransport struct {
+	provider string
+	log      *logger.Logger
+	next     http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if t.log == nil {
+		return resp, err
+	}
+
+	log := t.log.ForContext(req.Context())
+	attrs := []any{
+		"provider", t.provider,
+		"method", req.Method,
+		"host", req.URL.Host,
+		"path", req.URL.Path,
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	switch {
+	case err != nil:
+		log.Warn("Outbound request failed", append(attrs, "error", err)...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		log.Warn("Outbound request returned a server error", append(attrs, "status", resp.StatusCode)...)
+	default:
+		log.Debug("Outbound request", append(attrs, "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
@@ -0,0 +1,97 @@
+package outbound
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestTransport_HeadersAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var userAgent, requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, requestID = r.Header.Get("User-Agent"), r.Header.Get(RequestIDHeader)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("test-get", logger.New("test"), nil)}
+	ctx := logger.ContextWithAttrs(context.Background(), "trace_id", "trace-123")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/athlete", nil)
+	req.Header.Set("User-Agent", "google-api-go-client/0.5")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("Expected the 503 to be retried once, got status %d after %d attempts", resp.StatusCode, attempts.Load())
+	}
+	if userAgent != UserAgent+" google-api-go-client/0.5" || requestID != "trace-123" {
+		t.Errorf("Expected the user agent and trace ID headers, got %q and %q", userAgent, requestID)
+	}
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("Expected the caller's request to be left unmodified")
+	}
+
+	stats := Snapshot()["test-get"]
+	if stats.Requests != 2 || stats.Status5xx != 1 || stats.Status2xx != 1 || stats.Retries != 1 {
+		t.Errorf("Expected both attempts counted, got %+v", stats)
+	}
+}
+
+func TestTransport_DoesNotRetryWrites(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("test-post", nil, nil)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts.Load() != 1 || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected a single attempt returning the 502, got %d attempts and status %d", attempts.Load(), resp.StatusCode)
+	}
+}
+
+func TestTransport_StopsRetryingWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer server.Close()
+
+	transport := retryTransport{
+		provider: "test-cancel",
+		policy:   RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour},
+		next:     http.DefaultTransport,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	start := time.Now()
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("Expected the cancelled wait to fail the request")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected the retry wait to stop with the context, took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,119 @@
+package outbound
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// RetryPolicy bounds the retries of idempotent requests
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts including the first
+	BaseDelay   time.Duration // Backoff before the first retry, doubled for each further one
+	MaxDelay    time.Duration // Cap on the backoff; each wait is a random delay up to it ("full jitter")
+}
+
+// DefaultRetryPolicy retries twice within about a second, short enough to stay inside the
+// clients' request timeouts and the engine's step budgets
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+}
+
+// delay is the random wait before retry number retry (1-based)
+func (p RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.BaseDelay << (retry - 1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff + 1)
+}
+
+// retryTransport retries GET and HEAD requests after network errors and gateway failures.
+// Other methods are sent once: a write that timed out may have been applied. 429 responses are
+// left to the clients, which surface the provider's rate limit to the caller.
+type retryTransport struct {
+	provider string
+	log      *logger.Logger
+	policy   RetryPolicy
+	next     http.RoundTripper
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !idempotent(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		delay := t.policy.delay(attempt)
+		counters(t.provider).retries.Add(1)
+		if t.log != nil {
+			t.log.ForContext(req.Context()).Warn("Retrying outbound request",
+				"provider", t.provider,
+				"method", req.Method,
+				"path", req.URL.Path,
+				"attempt", attempt,
+				"next_retry_in", delay.String(),
+				"reason", retryReason(resp, err))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// idempotent reports whether req may be sent again: a GET or HEAD without a body to replay
+func idempotent(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// retryable reports whether the attempt failed transiently; a cancelled or expired request
+// context is final
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
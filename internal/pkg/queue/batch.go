@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Batch groups jobs enqueued together, e.g. by a remediation run, so their completion can be tracked
+type Batch struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	TraceIDs    []string  `json:"trace_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BatchProgress counts the jobs of a batch by status
+// Jobs whose result expired or was never stored are counted as Unknown
+type BatchProgress struct {
+	Total     int   `json:"total"`
+	Queued    int   `json:"queued"`
+	Running   int   `json:"running"`
+	Completed int   `json:"completed"`
+	Failed    int   `json:"failed"`
+	Unknown   int   `json:"unknown"`
+	FailedIDs []int `json:"failed_user_ids,omitempty"`
+}
+
+// Done reports whether no job of the batch is still queued or running
+func (p BatchProgress) Done() bool {
+	return p.Queued == 0 && p.Running == 0
+}
+
+// SaveBatch stores a batch for as long as its job results are kept, assigning an ID when missing
+func (c *Client) SaveBatch(ctx context.Context, batch *Batch) error {
+	if batch.ID == "" {
+		batch.ID = uuid.NewString()
+	}
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	if err := c.redis.Set(ctx, batchKeyPrefix+batch.ID, payload, c.resultTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store batch: %w", err)
+	}
+
+	return nil
+}
+
+// GetBatch returns a stored batch, or nil if it is unknown or expired
+func (c *Client) GetBatch(ctx context.Context, batchID string) (*Batch, error) {
+	payload, err := c.redis.Get(ctx, batchKeyPrefix+batchID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+
+	var batch Batch
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return nil, fmt.Errorf("failed to decode batch: %w", err)
+	}
+
+	return &batch, nil
+}
+
+// GetBatchProgress reads the current result of every job in the batch
+func (c *Client) GetBatchProgress(ctx context.Context, batch *Batch) (*BatchProgress, error) {
+	progress := &BatchProgress{Total: len(batch.TraceIDs)}
+	for _, traceID := range batch.TraceIDs {
+		result, err := c.GetResult(ctx, traceID)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			progress.Unknown++
+			continue
+		}
+
+		switch result.Status {
+		case JobStatusQueued:
+			progress.Queued++
+		case JobStatusRunning:
+			progress.Running++
+		case JobStatusCompleted:
+			progress.Completed++
+		case JobStatusFailed:
+			progress.Failed++
+			progress.FailedIDs = append(progress.FailedIDs, result.UserID)
+		default:
+			progress.Unknown++
+		}
+	}
+
+	return progress, nil
+}
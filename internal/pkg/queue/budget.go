@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// budgetKeyPrefix prefixes the per-user, per-day processing budget counters
+const budgetKeyPrefix = "academy-sync:budget:"
+
+// budgetTTL keeps a day's counters long enough to cover every timezone's version of that day
+const budgetTTL = 48 * time.Hour
+
+// Fields of a budget counter hash
+const (
+	budgetFieldCalls  = "provider_calls"
+	budgetFieldWrites = "sheets_writes"
+)
+
+// GetBudgetUsage returns the provider calls and Sheets writes recorded for the user on day
+// (YYYY-MM-DD in the user's timezone); both are zero when nothing was recorded
+func (c *Client) GetBudgetUsage(ctx context.Context, userID int, day string) (calls, writes int, err error) {
+	values, err := c.redis.HMGet(ctx, budgetKey(userID, day), budgetFieldCalls, budgetFieldWrites).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read processing budget: %w", err)
+	}
+	return budgetCount(values[0]), budgetCount(values[1]), nil
+}
+
+// AddBudgetUsage adds a job's provider calls and Sheets writes to the user's usage on day
+func (c *Client) AddBudgetUsage(ctx context.Context, userID int, day string, calls, writes int) error {
+	key := budgetKey(userID, day)
+	pipe := c.redis.TxPipeline()
+	pipe.HIncrBy(ctx, key, budgetFieldCalls, int64(calls))
+	pipe.HIncrBy(ctx, key, budgetFieldWrites, int64(writes))
+	pipe.Expire(ctx, key, budgetTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record processing budget: %w", err)
+	}
+	return nil
+}
+
+// stravaCallsKeyPrefix prefixes the per-user, per-UTC-day Strava API call counters
+const stravaCallsKeyPrefix = "academy-sync:strava-calls:"
+
+// IncrStravaCalls records one Strava API call for the user on day (YYYY-MM-DD in UTC) and
+// returns the day's count including it. Counting as calls are made keeps concurrent jobs for
+// the same user from overrunning the limit together.
+func (c *Client) IncrStravaCalls(ctx context.Context, userID int, day string) (int, error) {
+	key := stravaCallsKeyPrefix + day + ":" + strconv.Itoa(userID)
+	pipe := c.redis.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, budgetTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count Strava call: %w", err)
+	}
+	return int(count.Val()), nil
+}
+
+func budgetKey(userID int, day string) string {
+	return budgetKeyPrefix + day + ":" + strconv.Itoa(userID)
+}
+
+// budgetCount parses an HMGET value; missing fields are nil
+func budgetCount(value interface{}) int {
+	s, _ := value.(string)
+	n, _ := strconv.Atoi(s)
+	return n
+}
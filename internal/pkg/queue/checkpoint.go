@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// jobCheckpointsKeyPrefix prefixes the per-job streams of processing checkpoints
+const jobCheckpointsKeyPrefix = "academy-sync:job-checkpoints:"
+
+// maxJobCheckpoints bounds the checkpoint stream of one job; a sync records fewer than ten
+const maxJobCheckpoints = 100
+
+// JobStep names a processing step a job has completed
+type JobStep string
+
+const (
+	StepConfigLoaded         JobStep = "config_loaded"
+	StepTokensReady          JobStep = "tokens_ready"
+	StepDestinationValidated JobStep = "destination_validated"
+	StepActivitiesFetched    JobStep = "activities_fetched"
+	StepRowsWritten          JobStep = "rows_written"
+	// StepRowsPreviewed replaces StepRowsWritten in dry runs
+	StepRowsPreviewed JobStep = "rows_previewed"
+)
+
+// JobCheckpoint records a completed processing step of a job. Counts carries the step's numbers,
+// e.g. "activities" for StepActivitiesFetched or "written" and "updated" for StepRowsWritten.
+type JobCheckpoint struct {
+	TraceID string         `json:"trace_id"`
+	UserID  int            `json:"user_id"`
+	Step    JobStep        `json:"step"`
+	Counts  map[string]int `json:"counts,omitempty"`
+	At      time.Time      `json:"at"`
+}
+
+// RecordJobCheckpoint appends checkpoint to its job's stream, kept as long as the job result, and
+// publishes it as a running job event on the user's channel. Publishing is best effort, as the
+// stream keeps the checkpoint for later readers.
+func (c *Client) RecordJobCheckpoint(ctx context.Context, checkpoint *JobCheckpoint) error {
+	if checkpoint.At.IsZero() {
+		checkpoint.At = time.Now()
+	}
+
+	payload, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode job checkpoint: %w", err)
+	}
+
+	key := jobCheckpointsKeyPrefix + checkpoint.TraceID
+	pipe := c.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: maxJobCheckpoints,
+		Approx: true,
+		Values: map[string]interface{}{"checkpoint": payload},
+	})
+	pipe.Expire(ctx, key, c.resultTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record job checkpoint: %w", err)
+	}
+
+	if err := c.PublishJobEvent(ctx, &JobEvent{
+		TraceID:    checkpoint.TraceID,
+		UserID:     checkpoint.UserID,
+		Status:     JobStatusRunning,
+		Checkpoint: checkpoint,
+		At:         checkpoint.At,
+	}); err != nil {
+		c.logger.Warn("Failed to publish job checkpoint event",
+			"error", err,
+			"trace_id", checkpoint.TraceID,
+			"user_id", checkpoint.UserID,
+			"step", checkpoint.Step)
+	}
+
+	return nil
+}
+
+// GetJobCheckpoints returns the checkpoints recorded for a trace ID, oldest first; it is empty when
+// the job is unknown, has not completed a step yet, or expired
+func (c *Client) GetJobCheckpoints(ctx context.Context, traceID string) ([]JobCheckpoint, error) {
+	messages, err := c.redis.XRange(ctx, jobCheckpointsKeyPrefix+traceID, "-", "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job checkpoints: %w", err)
+	}
+
+	checkpoints := make([]JobCheckpoint, 0, len(messages))
+	for _, message := range messages {
+		payload, ok := message.Values["checkpoint"].(string)
+		if !ok {
+			continue
+		}
+		var checkpoint JobCheckpoint
+		if err := json.Unmarshal([]byte(payload), &checkpoint); err != nil {
+			return nil, fmt.Errorf("failed to decode job checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	return checkpoints, nil
+}
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// deadLetterKey lists jobs that failed with a transient error, and queue entries that could not
+// be decoded, newest first, until an operator requeues them
+const deadLetterKey = "academy-sync:jobs:dead-letter"
+
+// DeadLetter is a job set aside after failing
+type DeadLetter struct {
+	Job *Job `json:"job,omitempty"`
+	// Payload is the raw queue entry when it could not be decoded into a job
+	Payload  string    `json:"payload,omitempty"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Stats counts the jobs waiting in each part of the queue
+type Stats struct {
+	Queued       int64 `json:"queued"`
+	Deferred     int64 `json:"deferred"`
+	DeadLettered int64 `json:"dead_lettered"`
+}
+
+// DeadLetterJob sets a failed job aside so an operator can requeue it with RequeueDeadLetters
+func (c *Client) DeadLetterJob(ctx context.Context, job *Job, reason string) error {
+	if err := c.pushDeadLetter(ctx, &DeadLetter{Job: job, Reason: reason}); err != nil {
+		return err
+	}
+
+	c.logger.Warn("Dead-lettered automation job",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"trigger_type", job.TriggerType,
+		"reason", reason)
+
+	return nil
+}
+
+func (c *Client) pushDeadLetter(ctx context.Context, entry *DeadLetter) error {
+	entry.FailedAt = time.Now()
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	if err := c.redis.LPush(ctx, deadLetterKey, payload).Err(); err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns up to limit dead letters, oldest first
+func (c *Client) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	values, err := c.redis.LRange(ctx, deadLetterKey, int64(-limit), -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	entries := make([]DeadLetter, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		var entry DeadLetter
+		if err := json.Unmarshal([]byte(values[i]), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// RequeueDeadLetters moves up to limit dead-lettered jobs, oldest first, back onto the queue with
+// a new trace ID and returns how many were requeued. Entries without a decodable job stay in the
+// dead-letter list for inspection.
+func (c *Client) RequeueDeadLetters(ctx context.Context, limit int) (int, error) {
+	length, err := c.redis.LLen(ctx, deadLetterKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	requeued := 0
+	for i := int64(0); i < length && requeued < limit; i++ {
+		value, err := c.redis.RPop(ctx, deadLetterKey).Result()
+		if err != nil {
+			return requeued, fmt.Errorf("failed to claim dead letter: %w", err)
+		}
+
+		var entry DeadLetter
+		if err := json.Unmarshal([]byte(value), &entry); err != nil || entry.Job == nil {
+			// Rotated to the head so the remaining entries are still reached
+			if err := c.redis.LPush(ctx, deadLetterKey, value).Err(); err != nil {
+				return requeued, fmt.Errorf("failed to keep dead letter: %w", err)
+			}
+			continue
+		}
+
+		job := entry.Job
+		job.TraceID = ""
+		job.EnqueuedAt = time.Time{}
+		if err := c.Enqueue(ctx, job); err != nil {
+			// Put back at the tail, where it was claimed from
+			c.redis.RPush(ctx, deadLetterKey, value)
+			return requeued, err
+		}
+		requeued++
+	}
+
+	return requeued, nil
+}
+
+// Stats counts the queued, deferred and dead-lettered jobs
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	pipe := c.redis.Pipeline()
+	queued := pipe.LLen(ctx, JobQueueKey)
+	deferred := pipe.ZCard(ctx, deferredJobsKey)
+	deadLettered := pipe.LLen(ctx, deadLetterKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read queue stats: %w", err)
+	}
+
+	return &Stats{
+		Queued:       queued.Val(),
+		Deferred:     deferred.Val(),
+		DeadLettered: deadLettered.Val(),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys holding jobs deferred to a later time: a sorted set of job keys scored by when they
+// become due, and a hash of their payloads
+const (
+	deferredJobsKey        = "academy-sync:deferred-jobs"
+	deferredJobPayloadsKey = "academy-sync:deferred-job-payloads"
+)
+
+// Defer schedules job to be enqueued again at until. A user has at most one deferred job per
+// trigger type, so repeated manual syncs on an exhausted budget collapse into one; the latest
+// job replaces earlier ones.
+func (c *Client) Defer(ctx context.Context, job *Job, until time.Time) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	key := deferredJobKey(job)
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, deferredJobPayloadsKey, key, payload)
+	pipe.ZAdd(ctx, deferredJobsKey, redis.Z{Score: float64(until.Unix()), Member: key})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to defer job: %w", err)
+	}
+
+	c.logger.Info("Deferred automation job",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"trigger_type", job.TriggerType,
+		"until", until.Format(time.RFC3339))
+
+	return nil
+}
+
+// EnqueueDueJobs moves deferred jobs that are due at now back onto the queue with a new trace ID
+// and returns how many were enqueued. A job is claimed by removing it from the schedule first, so
+// concurrent engines never enqueue the same job twice.
+func (c *Client) EnqueueDueJobs(ctx context.Context, now time.Time) (int, error) {
+	keys, err := c.redis.ZRangeByScore(ctx, deferredJobsKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deferred jobs: %w", err)
+	}
+
+	enqueued := 0
+	for _, key := range keys {
+		claimed, err := c.redis.ZRem(ctx, deferredJobsKey, key).Result()
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to claim deferred job: %w", err)
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		payload, err := c.redis.HGet(ctx, deferredJobPayloadsKey, key).Bytes()
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to read deferred job: %w", err)
+		}
+		c.redis.HDel(ctx, deferredJobPayloadsKey, key)
+
+		var job Job
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return enqueued, fmt.Errorf("failed to decode deferred job: %w", err)
+		}
+		job.TraceID = ""
+		job.EnqueuedAt = time.Time{}
+		if err := c.Enqueue(ctx, &job); err != nil {
+			return enqueued, err
+		}
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+func deferredJobKey(job *Job) string {
+	return strconv.Itoa(job.UserID) + ":" + job.TriggerType
+}
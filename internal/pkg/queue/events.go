@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// jobEventsChannelPrefix prefixes the per-user pub/sub channels job events are published on
+const jobEventsChannelPrefix = "academy-sync:job-events:"
+
+// jobEventBuffer is how many events a subscriber may fall behind before delivery blocks
+const jobEventBuffer = 16
+
+// JobEvent is a change in a job's status, published to the job owner's channel so the API can
+// push it to the browser. Events are fire-and-forget: a subscriber that is not connected misses
+// them and reads the job result instead.
+type JobEvent struct {
+	TraceID string          `json:"trace_id"`
+	UserID  int             `json:"user_id"`
+	Status  JobStatus       `json:"status"`
+	DryRun  bool            `json:"dry_run,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	At      time.Time       `json:"at"`
+
+	// Checkpoint is set on events announcing a completed processing step of a running job
+	Checkpoint *JobCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// PublishJobEvent publishes event on its user's channel
+func (c *Client) PublishJobEvent(ctx context.Context, event *JobEvent) error {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode job event: %w", err)
+	}
+
+	if err := c.redis.Publish(ctx, jobEventsChannel(event.UserID), payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish job event: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeJobEvents delivers the job events of userID until ctx is done, when the returned
+// channel is closed. Events published before SubscribeJobEvents returns are not delivered.
+func (c *Client) SubscribeJobEvents(ctx context.Context, userID int) (<-chan JobEvent, error) {
+	pubsub := c.redis.Subscribe(ctx, jobEventsChannel(userID))
+
+	// Wait for the subscription to be confirmed, so no event published after we return is lost
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to job events: %w", err)
+	}
+
+	events := make(chan JobEvent, jobEventBuffer)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event JobEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					c.logger.Warn("Dropping malformed job event",
+						"error", err,
+						"user_id", userID)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func jobEventsChannel(userID int) string {
+	return jobEventsChannelPrefix + strconv.Itoa(userID)
+}
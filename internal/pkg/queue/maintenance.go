@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maintenanceKey holds the maintenance switch. It lives in Redis rather than the database so it
+// keeps working while operators migrate the schema.
+const maintenanceKey = "academy-sync:maintenance"
+
+// Maintenance describes maintenance in progress: the API refuses non-admin requests and the
+// automation engine stops dequeuing jobs until it is cleared
+type Maintenance struct {
+	Message   string     `json:"message"`
+	StartedAt time.Time  `json:"started_at"`
+	StartedBy int        `json:"started_by"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"` // Expected end, shown to users; maintenance lasts until cleared
+}
+
+// SetMaintenance turns maintenance mode on, replacing any maintenance in progress
+func (c *Client) SetMaintenance(ctx context.Context, maintenance *Maintenance) error {
+	payload, err := json.Marshal(maintenance)
+	if err != nil {
+		return fmt.Errorf("failed to encode maintenance: %w", err)
+	}
+	if err := c.redis.Set(ctx, maintenanceKey, payload, 0).Err(); err != nil {
+		return fmt.Errorf("failed to store maintenance: %w", err)
+	}
+	return nil
+}
+
+// ClearMaintenance turns maintenance mode off and reports whether it was on
+func (c *Client) ClearMaintenance(ctx context.Context) (bool, error) {
+	deleted, err := c.redis.Del(ctx, maintenanceKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to clear maintenance: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// GetMaintenance returns the maintenance in progress, or nil when the service is up
+func (c *Client) GetMaintenance(ctx context.Context) (*Maintenance, error) {
+	payload, err := c.redis.Get(ctx, maintenanceKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance: %w", err)
+	}
+
+	var maintenance Maintenance
+	if err := json.Unmarshal(payload, &maintenance); err != nil {
+		return nil, fmt.Errorf("failed to decode maintenance: %w", err)
+	}
+	return &maintenance, nil
+}
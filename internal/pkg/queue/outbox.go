@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+const (
+	// outboxBatchSize bounds the jobs published in one outbox transaction
+	outboxBatchSize = 100
+
+	// outboxPruneInterval is how often published jobs past their retention are deleted
+	outboxPruneInterval = time.Hour
+)
+
+// OutboxStore is the database side of the job outbox
+type OutboxStore interface {
+	PublishOutboxJobs(ctx context.Context, limit int, publish func(ctx context.Context, job database.OutboxJob) error) (int, error)
+	PruneOutboxJobs(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewOutboxJob prepares job for the outbox, assigning its trace ID and enqueue time up front so
+// the caller can return the trace ID before the job reaches the queue
+func NewOutboxJob(job *Job) (*database.OutboxJob, error) {
+	if job.TraceID == "" {
+		job.TraceID = uuid.NewString()
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job: %w", err)
+	}
+	return &database.OutboxJob{UserID: job.UserID, TraceID: job.TraceID, Payload: payload}, nil
+}
+
+// OutboxRelay publishes the jobs recorded in the outbox to the queue
+type OutboxRelay struct {
+	store     OutboxStore
+	queue     *Client
+	retention time.Duration
+	logger    *logger.Logger
+}
+
+// NewOutboxRelay creates a relay; published jobs are kept for retention so they can be replayed
+func NewOutboxRelay(store OutboxStore, queue *Client, retention time.Duration, logger *logger.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		queue:     queue,
+		retention: retention,
+		logger:    logger.WithContext("component", "outbox_relay"),
+	}
+}
+
+// RelayOnce publishes pending jobs until the outbox is empty or publishing fails
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := r.store.PublishOutboxJobs(ctx, outboxBatchSize, r.publish)
+		total += published
+		if err != nil || published < outboxBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Run relays the outbox every interval and prunes published jobs hourly until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if published, err := r.RelayOnce(ctx); err != nil {
+			r.logger.Warn("Failed to publish outbox jobs, retrying",
+				"published", published,
+				"error", err)
+		} else if published > 0 {
+			r.logger.Info("Published outbox jobs",
+				"count", published)
+		}
+
+		if r.retention > 0 && time.Since(lastPrune) >= outboxPruneInterval {
+			lastPrune = time.Now()
+			if pruned, err := r.store.PruneOutboxJobs(ctx, lastPrune.Add(-r.retention)); err != nil {
+				r.logger.Warn("Failed to prune published outbox jobs",
+					"error", err)
+			} else if pruned > 0 {
+				r.logger.Info("Pruned published outbox jobs",
+					"count", pruned)
+			}
+		}
+	}
+}
+
+// publish enqueues one outbox job. A payload that cannot be decoded would block the outbox
+// forever, so it is logged and treated as published.
+func (r *OutboxRelay) publish(ctx context.Context, outboxJob database.OutboxJob) error {
+	var job Job
+	if err := json.Unmarshal(outboxJob.Payload, &job); err != nil {
+		r.logger.Error("Dropping undecodable outbox job",
+			"outbox_id", outboxJob.ID,
+			"trace_id", outboxJob.TraceID,
+			"error", err)
+		return nil
+	}
+	return r.queue.Enqueue(ctx, &job)
+}
@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/redisclient"
+)
+
+// Redis keys used by the job queue
+const (
+	JobQueueKey        = "academy-sync:jobs"
+	jobResultKeyPrefix = "academy-sync:job-results:"
+	batchKeyPrefix     = "academy-sync:batches:"
+)
+
+// DefaultResultTTL is how long job results stay available for polling
+const DefaultResultTTL = 24 * time.Hour
+
+// Trigger types recorded for each job
+const (
+	TriggerSchedule   = "schedule"
+	TriggerManualSync = "manual_sync"
+	// TriggerBackfill imports the user's Strava history in checkpointed monthly windows
+	TriggerBackfill = "backfill"
+	// TriggerRemediation re-processes a fixed date range after an incident
+	TriggerRemediation = "remediation"
+
+	// TriggerTestMode marks runs from the engine's development loop, which bypasses the queue
+	TriggerTestMode = "test_mode"
+)
+
+// JobStatus is the lifecycle state of a queued job
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+	// JobStatusDeferred jobs exhausted the user's daily processing budget and run again later
+	JobStatusDeferred JobStatus = "deferred"
+)
+
+// Job is the payload placed on the job queue for the automation engine
+type Job struct {
+	TraceID     string    `json:"trace_id"`
+	UserID      int       `json:"user_id"`
+	TriggerType string    `json:"trigger_type"`
+	DryRun      bool      `json:"dry_run,omitempty"`
+	EnqueuedAt  time.Time `json:"enqueued_at"`
+
+	// BackfillFrom is where a backfill job starts; nil imports the athlete's full history
+	BackfillFrom *time.Time `json:"backfill_from,omitempty"`
+
+	// SyncFrom and SyncTo replace the default fetch window with a fixed range, e.g. to correct
+	// rows written by a faulty run; both are set or neither
+	SyncFrom *time.Time `json:"sync_from,omitempty"`
+	SyncTo   *time.Time `json:"sync_to,omitempty"`
+}
+
+// JobResult is the status and outcome of a job, stored for the API to poll
+type JobResult struct {
+	TraceID   string          `json:"trace_id"`
+	UserID    int             `json:"user_id"`
+	Status    JobStatus       `json:"status"`
+	DryRun    bool            `json:"dry_run,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Client wraps the Redis connection used for the job queue
+type Client struct {
+	redis     *redis.Client
+	resultTTL time.Duration
+	logger    *logger.Logger
+}
+
+// NewClient creates a queue client with its own Redis connection from a redis:// URL. Services
+// share their connection through the redisclient factory and NewClientFromRedis instead.
+func NewClient(redisURL string, logger *logger.Logger) (*Client, error) {
+	rdb, err := redisclient.New(redisURL, redisclient.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientFromRedis(rdb, logger), nil
+}
+
+// NewClientFromRedis creates a queue client around an existing Redis connection, which stays
+// owned by the caller: Close must not be called on a client built on a shared connection
+func NewClientFromRedis(rdb *redis.Client, logger *logger.Logger) *Client {
+	return &Client{
+		redis:     rdb,
+		resultTTL: DefaultResultTTL,
+		logger:    logger.WithContext("component", "job_queue"),
+	}
+}
+
+// Ping checks connectivity to Redis
+func (c *Client) Ping(ctx context.Context) error {
+	return c.redis.Ping(ctx).Err()
+}
+
+// Close releases the Redis connection
+func (c *Client) Close() error {
+	return c.redis.Close()
+}
+
+// Enqueue pushes a job onto the queue, assigning a trace ID when missing, and records it as queued
+func (c *Client) Enqueue(ctx context.Context, job *Job) error {
+	if job.TraceID == "" {
+		job.TraceID = uuid.NewString()
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	if err := c.SetResult(ctx, &JobResult{
+		TraceID: job.TraceID,
+		UserID:  job.UserID,
+		Status:  JobStatusQueued,
+		DryRun:  job.DryRun,
+	}); err != nil {
+		return err
+	}
+
+	if err := c.redis.LPush(ctx, JobQueueKey, payload).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	c.logger.Info("Enqueued automation job",
+		"trace_id", job.TraceID,
+		"user_id", job.UserID,
+		"trigger_type", job.TriggerType,
+		"dry_run", job.DryRun)
+
+	return nil
+}
+
+// Dequeue blocks up to timeout for the next job; it returns nil without error when the queue stays empty
+func (c *Client) Dequeue(ctx context.Context, timeout time.Duration) (*Job, error) {
+	values, err := c.redis.BRPop(ctx, timeout, JobQueueKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dequeue job: %w", err)
+	}
+
+	// BRPOP returns [key, value]; an entry that is not a job is kept in the dead-letter list
+	var job Job
+	if err := json.Unmarshal([]byte(values[1]), &job); err != nil {
+		if dlqErr := c.pushDeadLetter(ctx, &DeadLetter{Payload: values[1], Reason: err.Error()}); dlqErr != nil {
+			c.logger.Error("Failed to dead-letter undecodable job",
+				"error", dlqErr)
+		}
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// SetResult stores a job's status and outcome and announces it as a job event. The event is
+// best effort: failing to publish it is logged, as pollers still find the stored result.
+func (c *Client) SetResult(ctx context.Context, result *JobResult) error {
+	result.UpdatedAt = time.Now()
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+
+	if err := c.redis.Set(ctx, jobResultKeyPrefix+result.TraceID, payload, c.resultTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store job result: %w", err)
+	}
+
+	if err := c.PublishJobEvent(ctx, &JobEvent{
+		TraceID: result.TraceID,
+		UserID:  result.UserID,
+		Status:  result.Status,
+		DryRun:  result.DryRun,
+		Result:  result.Result,
+		At:      result.UpdatedAt,
+	}); err != nil {
+		c.logger.Warn("Failed to publish job status event",
+			"error", err,
+			"trace_id", result.TraceID,
+			"user_id", result.UserID,
+			"status", result.Status)
+	}
+
+	return nil
+}
+
+// GetResult returns the stored result for a trace ID, or nil if it is unknown or expired
+func (c *Client) GetResult(ctx context.Context, traceID string) (*JobResult, error) {
+	payload, err := c.redis.Get(ctx, jobResultKeyPrefix+traceID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job result: %w", err)
+	}
+
+	var result JobResult
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode job result: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,680 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := NewClient("redis://"+server.Addr(), logger.New("test"))
+	if err != nil {
+		t.Fatalf("Failed to create queue client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, server
+}
+
+func TestClient_EnqueueDequeue(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	job := &Job{UserID: 42, TriggerType: TriggerManualSync, DryRun: true}
+	if err := client.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	if job.TraceID == "" {
+		t.Fatal("Expected trace ID to be assigned")
+	}
+
+	// Enqueued jobs are immediately visible as queued
+	result, err := client.GetResult(ctx, job.TraceID)
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	if result == nil || result.Status != JobStatusQueued || result.UserID != 42 || !result.DryRun {
+		t.Errorf("Expected queued dry-run result for user 42, got %+v", result)
+	}
+
+	dequeued, err := client.Dequeue(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if dequeued == nil {
+		t.Fatal("Expected a job to be dequeued")
+	}
+	if dequeued.TraceID != job.TraceID || dequeued.UserID != 42 || !dequeued.DryRun {
+		t.Errorf("Dequeued job does not match enqueued job: %+v", dequeued)
+	}
+}
+
+func TestClient_DequeueFIFO(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	for _, userID := range []int{1, 2, 3} {
+		if err := client.Enqueue(ctx, &Job{UserID: userID, TriggerType: TriggerSchedule}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	for _, expected := range []int{1, 2, 3} {
+		job, err := client.Dequeue(ctx, time.Second)
+		if err != nil || job == nil {
+			t.Fatalf("Dequeue failed: job=%v err=%v", job, err)
+		}
+		if job.UserID != expected {
+			t.Errorf("Expected user %d, got %d", expected, job.UserID)
+		}
+	}
+}
+
+func TestClient_SetAndGetResult(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	payload, _ := json.Marshal(map[string]interface{}{"success": true})
+	if err := client.SetResult(ctx, &JobResult{
+		TraceID: "trace-1",
+		UserID:  7,
+		Status:  JobStatusCompleted,
+		Result:  payload,
+	}); err != nil {
+		t.Fatalf("SetResult failed: %v", err)
+	}
+
+	result, err := client.GetResult(ctx, "trace-1")
+	if err != nil || result == nil {
+		t.Fatalf("GetResult failed: result=%v err=%v", result, err)
+	}
+	if result.Status != JobStatusCompleted || string(result.Result) != `{"success":true}` {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Results expire after the TTL
+	server.FastForward(DefaultResultTTL + time.Minute)
+	result, err = client.GetResult(ctx, "trace-1")
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected expired result to be gone, got %+v", result)
+	}
+}
+
+func TestClient_BatchProgress(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	batch := &Batch{Description: "rerun 2024-05-01"}
+	for _, userID := range []int{1, 2, 3} {
+		job := &Job{UserID: userID, TriggerType: TriggerRemediation}
+		if err := client.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		batch.TraceIDs = append(batch.TraceIDs, job.TraceID)
+	}
+	if err := client.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("SaveBatch failed: %v", err)
+	}
+
+	if err := client.SetResult(ctx, &JobResult{TraceID: batch.TraceIDs[0], UserID: 1, Status: JobStatusCompleted}); err != nil {
+		t.Fatalf("SetResult failed: %v", err)
+	}
+	if err := client.SetResult(ctx, &JobResult{TraceID: batch.TraceIDs[1], UserID: 2, Status: JobStatusFailed}); err != nil {
+		t.Fatalf("SetResult failed: %v", err)
+	}
+
+	stored, err := client.GetBatch(ctx, batch.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetBatch failed: %v", err)
+	}
+
+	progress, err := client.GetBatchProgress(ctx, stored)
+	if err != nil {
+		t.Fatalf("GetBatchProgress failed: %v", err)
+	}
+	if progress.Total != 3 || progress.Completed != 1 || progress.Failed != 1 || progress.Queued != 1 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if progress.Done() || len(progress.FailedIDs) != 1 || progress.FailedIDs[0] != 2 {
+		t.Errorf("Expected an unfinished batch with user 2 failed, got %+v", progress)
+	}
+
+	if missing, err := client.GetBatch(ctx, "unknown"); err != nil || missing != nil {
+		t.Errorf("Expected nil for unknown batch, got %+v, %v", missing, err)
+	}
+}
+
+func TestReauthMarkers(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if marked, err := client.IsReauthRequired(ctx, 7, "strava", "fp1"); err != nil || marked {
+		t.Fatalf("Expected no marker, got %t, %v", marked, err)
+	}
+
+	if err := client.MarkReauthRequired(ctx, 7, "strava", "fp1", time.Minute); err != nil {
+		t.Fatalf("MarkReauthRequired failed: %v", err)
+	}
+	if marked, _ := client.IsReauthRequired(ctx, 7, "strava", "fp1"); !marked {
+		t.Error("Expected the marker to match the same credentials")
+	}
+	if marked, _ := client.IsReauthRequired(ctx, 7, "strava", "fp2"); marked {
+		t.Error("Expected the marker not to match reconnected credentials")
+	}
+	if marked, _ := client.IsReauthRequired(ctx, 7, "google", "fp1"); marked {
+		t.Error("Expected markers to be per provider")
+	}
+
+	server.FastForward(2 * time.Minute)
+	if marked, _ := client.IsReauthRequired(ctx, 7, "strava", "fp1"); marked {
+		t.Error("Expected the marker to expire")
+	}
+}
+
+func TestClient_TokenRefreshLock(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if acquired, err := client.AcquireTokenRefreshLock(ctx, "strava:7:fp", "engine-a", time.Minute); err != nil || !acquired {
+		t.Fatalf("Expected to acquire a free lock, got %t, %v", acquired, err)
+	}
+	if acquired, _ := client.AcquireTokenRefreshLock(ctx, "strava:7:fp", "engine-b", time.Minute); acquired {
+		t.Error("Expected a held lock to be refused")
+	}
+
+	// Only the owner can release the lock
+	if err := client.ReleaseTokenRefreshLock(ctx, "strava:7:fp", "engine-b"); err != nil {
+		t.Fatalf("ReleaseTokenRefreshLock failed: %v", err)
+	}
+	if acquired, _ := client.AcquireTokenRefreshLock(ctx, "strava:7:fp", "engine-b", time.Minute); acquired {
+		t.Error("Expected the lock to survive a release by another owner")
+	}
+	if err := client.ReleaseTokenRefreshLock(ctx, "strava:7:fp", "engine-a"); err != nil {
+		t.Fatalf("ReleaseTokenRefreshLock failed: %v", err)
+	}
+	if acquired, _ := client.AcquireTokenRefreshLock(ctx, "strava:7:fp", "engine-b", time.Minute); !acquired {
+		t.Error("Expected the released lock to be free")
+	}
+
+	// Results are shared until they expire
+	if value, err := client.GetRefreshedToken(ctx, "strava:7:fp"); err != nil || value != nil {
+		t.Fatalf("Expected no shared result, got %q, %v", value, err)
+	}
+	if err := client.StoreRefreshedToken(ctx, "strava:7:fp", []byte("sealed"), time.Minute); err != nil {
+		t.Fatalf("StoreRefreshedToken failed: %v", err)
+	}
+	if value, _ := client.GetRefreshedToken(ctx, "strava:7:fp"); string(value) != "sealed" {
+		t.Errorf("Expected the shared result, got %q", value)
+	}
+	server.FastForward(2 * time.Minute)
+	if value, _ := client.GetRefreshedToken(ctx, "strava:7:fp"); value != nil {
+		t.Error("Expected the shared result to expire")
+	}
+}
+
+func TestClient_UserLock(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if acquired, err := client.AcquireUserLock(ctx, 7, "job-a", time.Minute); err != nil || !acquired {
+		t.Fatalf("Expected to acquire a free lock, got %t, %v", acquired, err)
+	}
+	if acquired, _ := client.AcquireUserLock(ctx, 7, "job-b", time.Minute); acquired {
+		t.Error("Expected a second job for the same user to be refused")
+	}
+	if acquired, _ := client.AcquireUserLock(ctx, 8, "job-b", time.Minute); !acquired {
+		t.Error("Expected locks to be per user")
+	}
+
+	// Renewal keeps the lock past its original TTL, but only for its owner
+	server.FastForward(50 * time.Second)
+	if renewed, err := client.RenewUserLock(ctx, 7, "job-a", time.Minute); err != nil || !renewed {
+		t.Fatalf("Expected the owner to renew, got %t, %v", renewed, err)
+	}
+	if renewed, _ := client.RenewUserLock(ctx, 7, "job-b", time.Minute); renewed {
+		t.Error("Expected another job not to renew the lock")
+	}
+	server.FastForward(50 * time.Second)
+	if acquired, _ := client.AcquireUserLock(ctx, 7, "job-b", time.Minute); acquired {
+		t.Error("Expected the renewed lock to still be held")
+	}
+
+	if err := client.ReleaseUserLock(ctx, 7, "job-a"); err != nil {
+		t.Fatalf("ReleaseUserLock failed: %v", err)
+	}
+	if acquired, _ := client.AcquireUserLock(ctx, 7, "job-b", time.Minute); !acquired {
+		t.Error("Expected the released lock to be free")
+	}
+
+	// An expired lock is lost to its owner
+	server.FastForward(2 * time.Minute)
+	if renewed, _ := client.RenewUserLock(ctx, 7, "job-b", time.Minute); renewed {
+		t.Error("Expected an expired lock not to be renewed")
+	}
+}
+
+func TestClient_BudgetUsage(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	calls, writes, err := client.GetBudgetUsage(ctx, 42, "2026-10-16")
+	if err != nil || calls != 0 || writes != 0 {
+		t.Fatalf("Expected no usage before any job, got %d/%d (%v)", calls, writes, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := client.AddBudgetUsage(ctx, 42, "2026-10-16", 12, 3); err != nil {
+			t.Fatalf("AddBudgetUsage failed: %v", err)
+		}
+	}
+
+	calls, writes, err = client.GetBudgetUsage(ctx, 42, "2026-10-16")
+	if err != nil || calls != 24 || writes != 6 {
+		t.Errorf("Expected usage to accumulate to 24/6, got %d/%d (%v)", calls, writes, err)
+	}
+	if calls, _, _ := client.GetBudgetUsage(ctx, 42, "2026-10-17"); calls != 0 {
+		t.Errorf("Expected a new day to start from zero, got %d", calls)
+	}
+	if ttl := server.TTL(budgetKey(42, "2026-10-16")); ttl <= 0 {
+		t.Errorf("Expected budget counters to expire, got TTL %v", ttl)
+	}
+}
+
+func TestClient_StravaCalls(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	for expected := 1; expected <= 3; expected++ {
+		count, err := client.IncrStravaCalls(ctx, 42, "2026-10-16")
+		if err != nil || count != expected {
+			t.Fatalf("Expected call %d, got %d (%v)", expected, count, err)
+		}
+	}
+	if count, _ := client.IncrStravaCalls(ctx, 7, "2026-10-16"); count != 1 {
+		t.Errorf("Expected users to be counted separately, got %d", count)
+	}
+	if count, _ := client.IncrStravaCalls(ctx, 42, "2026-10-17"); count != 1 {
+		t.Errorf("Expected a new day to start from zero, got %d", count)
+	}
+	if ttl := server.TTL(stravaCallsKeyPrefix + "2026-10-16:42"); ttl <= 0 {
+		t.Errorf("Expected call counters to expire, got TTL %v", ttl)
+	}
+}
+
+func TestClient_SheetsWriteTokens(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	// 60 writes a minute with a burst of 2: two tokens at once, then one a second
+	for i := 0; i < 2; i++ {
+		if wait, err := client.TakeSheetsWriteToken(ctx, 60, 2); err != nil || wait != 0 {
+			t.Fatalf("Expected token %d to be taken, got wait %v (%v)", i+1, wait, err)
+		}
+	}
+	wait, err := client.TakeSheetsWriteToken(ctx, 60, 2)
+	if err != nil || wait != time.Second {
+		t.Fatalf("Expected to wait a second for the next token, got %v (%v)", wait, err)
+	}
+
+	// The bucket refills on the Redis server's clock, whatever the caller's
+	server.SetTime(now.Add(500 * time.Millisecond))
+	if wait, _ := client.TakeSheetsWriteToken(ctx, 60, 2); wait != 500*time.Millisecond {
+		t.Errorf("Expected half a second left, got %v", wait)
+	}
+	server.SetTime(now.Add(time.Second))
+	if wait, _ := client.TakeSheetsWriteToken(ctx, 60, 2); wait != 0 {
+		t.Errorf("Expected a token after a second, got wait %v", wait)
+	}
+	if ttl := server.TTL(sheetsWriteBucketKey); ttl <= 0 {
+		t.Errorf("Expected the bucket to expire when unused, got TTL %v", ttl)
+	}
+}
+
+func TestClient_CachedResponses(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if _, found, err := client.GetCachedResponse(ctx, "strava:athlete:1"); err != nil || found {
+		t.Fatalf("Expected a miss before caching, got found=%v (%v)", found, err)
+	}
+
+	if err := client.SetCachedResponse(ctx, "strava:athlete:1", []byte(`{"id":42}`), time.Minute); err != nil {
+		t.Fatalf("SetCachedResponse failed: %v", err)
+	}
+	value, found, err := client.GetCachedResponse(ctx, "strava:athlete:1")
+	if err != nil || !found || string(value) != `{"id":42}` {
+		t.Errorf("Expected the cached response, got %q found=%v (%v)", value, found, err)
+	}
+	if ttl := server.TTL(responseCacheKeyPrefix + "strava:athlete:1"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected the response to expire within a minute, got TTL %v", ttl)
+	}
+
+	if err := client.DeleteCachedResponses(ctx, "strava:athlete:1", "unknown"); err != nil {
+		t.Fatalf("DeleteCachedResponses failed: %v", err)
+	}
+	if _, found, _ := client.GetCachedResponse(ctx, "strava:athlete:1"); found {
+		t.Error("Expected the response to be gone after deletion")
+	}
+}
+
+func TestClient_DeferredJobs(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Two deferred manual syncs for the same user collapse into one
+	for _, traceID := range []string{"first", "second"} {
+		job := &Job{TraceID: traceID, UserID: 42, TriggerType: TriggerManualSync}
+		if err := client.Defer(ctx, job, now.Add(time.Hour)); err != nil {
+			t.Fatalf("Defer failed: %v", err)
+		}
+	}
+	if err := client.Defer(ctx, &Job{TraceID: "backfill", UserID: 42, TriggerType: TriggerBackfill}, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+
+	if enqueued, err := client.EnqueueDueJobs(ctx, now); err != nil || enqueued != 0 {
+		t.Fatalf("Expected no job to be due yet, got %d (%v)", enqueued, err)
+	}
+
+	enqueued, err := client.EnqueueDueJobs(ctx, now.Add(time.Hour))
+	if err != nil || enqueued != 1 {
+		t.Fatalf("Expected the manual sync to be enqueued, got %d (%v)", enqueued, err)
+	}
+	job, err := client.Dequeue(ctx, time.Second)
+	if err != nil || job == nil {
+		t.Fatalf("Expected a job on the queue: %v", err)
+	}
+	if job.TriggerType != TriggerManualSync || job.TraceID == "" || job.TraceID == "second" {
+		t.Errorf("Expected the deferred sync under a new trace ID, got %+v", job)
+	}
+
+	if enqueued, _ := client.EnqueueDueJobs(ctx, now.Add(time.Hour)); enqueued != 0 {
+		t.Errorf("Expected an enqueued job not to be enqueued again, got %d", enqueued)
+	}
+	if enqueued, _ := client.EnqueueDueJobs(ctx, now.Add(3*time.Hour)); enqueued != 1 {
+		t.Errorf("Expected the deferred backfill to be enqueued, got %d", enqueued)
+	}
+}
+
+type fakeOutboxStore struct {
+	jobs      []database.OutboxJob
+	published map[int64]bool
+}
+
+func (f *fakeOutboxStore) PublishOutboxJobs(ctx context.Context, limit int, publish func(ctx context.Context, job database.OutboxJob) error) (int, error) {
+	count := 0
+	for _, job := range f.jobs {
+		if f.published[job.ID] || count == limit {
+			continue
+		}
+		if err := publish(ctx, job); err != nil {
+			return count, err
+		}
+		f.published[job.ID] = true
+		count++
+	}
+	return count, nil
+}
+
+func (f *fakeOutboxStore) PruneOutboxJobs(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestOutboxRelay_PublishesJobs(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	job := &Job{UserID: 42, TriggerType: TriggerManualSync}
+	outboxJob, err := NewOutboxJob(job)
+	if err != nil {
+		t.Fatalf("NewOutboxJob failed: %v", err)
+	}
+	if job.TraceID == "" || outboxJob.TraceID != job.TraceID || outboxJob.UserID != 42 {
+		t.Fatalf("Expected the trace ID to be assigned before publishing: %+v", outboxJob)
+	}
+	outboxJob.ID = 1
+	store := &fakeOutboxStore{
+		jobs:      []database.OutboxJob{*outboxJob, {ID: 2, TraceID: "broken", Payload: []byte("{")}},
+		published: map[int64]bool{},
+	}
+	relay := NewOutboxRelay(store, client, 24*time.Hour, logger.New("test"))
+
+	// A Redis outage leaves the jobs in the outbox
+	server.SetError("connection refused")
+	if _, err := relay.RelayOnce(ctx); err == nil {
+		t.Fatal("Expected publishing to fail while Redis is down")
+	}
+	server.SetError("")
+
+	published, err := relay.RelayOnce(ctx)
+	if err != nil || published != 2 {
+		t.Fatalf("Expected both jobs to leave the outbox, got %d (%v)", published, err)
+	}
+	dequeued, err := client.Dequeue(ctx, time.Second)
+	if err != nil || dequeued == nil || dequeued.TraceID != job.TraceID {
+		t.Fatalf("Expected the outbox job to be queued with its trace ID, got %+v (%v)", dequeued, err)
+	}
+	if extra, _ := client.Dequeue(ctx, 10*time.Millisecond); extra != nil {
+		t.Errorf("Expected the undecodable job to be dropped, got %+v", extra)
+	}
+}
+
+func TestClient_JobEvents(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.SubscribeJobEvents(ctx, 42)
+	if err != nil {
+		t.Fatalf("SubscribeJobEvents failed: %v", err)
+	}
+
+	// Other users' jobs are not delivered
+	if err := client.SetResult(ctx, &JobResult{TraceID: "other", UserID: 7, Status: JobStatusQueued}); err != nil {
+		t.Fatalf("SetResult failed: %v", err)
+	}
+	job := &Job{UserID: 42, TriggerType: TriggerManualSync}
+	if err := client.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := client.SetResult(ctx, &JobResult{TraceID: job.TraceID, UserID: 42, Status: JobStatusCompleted, Result: json.RawMessage(`{"rows":3}`)}); err != nil {
+		t.Fatalf("SetResult failed: %v", err)
+	}
+
+	for _, expected := range []JobStatus{JobStatusQueued, JobStatusCompleted} {
+		select {
+		case event := <-events:
+			if event.TraceID != job.TraceID || event.UserID != 42 || event.Status != expected || event.At.IsZero() {
+				t.Errorf("Expected %s event for %s, got %+v", expected, job.TraceID, event)
+			}
+			if expected == JobStatusCompleted && string(event.Result) != `{"rows":3}` {
+				t.Errorf("Expected the result to be included, got %s", event.Result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %s event", expected)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no further events")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the event channel to close when the context is canceled")
+	}
+}
+
+func TestClient_JobCheckpoints(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.SubscribeJobEvents(ctx, 42)
+	if err != nil {
+		t.Fatalf("SubscribeJobEvents failed: %v", err)
+	}
+
+	for _, checkpoint := range []*JobCheckpoint{
+		{TraceID: "trace-1", UserID: 42, Step: StepConfigLoaded},
+		{TraceID: "trace-1", UserID: 42, Step: StepActivitiesFetched, Counts: map[string]int{"activities": 5}},
+	} {
+		if err := client.RecordJobCheckpoint(ctx, checkpoint); err != nil {
+			t.Fatalf("RecordJobCheckpoint failed: %v", err)
+		}
+	}
+
+	checkpoints, err := client.GetJobCheckpoints(ctx, "trace-1")
+	if err != nil {
+		t.Fatalf("GetJobCheckpoints failed: %v", err)
+	}
+	if len(checkpoints) != 2 || checkpoints[0].Step != StepConfigLoaded || checkpoints[1].Step != StepActivitiesFetched {
+		t.Fatalf("Expected both checkpoints in order, got %+v", checkpoints)
+	}
+	if checkpoints[1].Counts["activities"] != 5 || checkpoints[1].At.IsZero() {
+		t.Errorf("Expected counts and time to round-trip, got %+v", checkpoints[1])
+	}
+	if ttl := server.TTL(jobCheckpointsKeyPrefix + "trace-1"); ttl <= 0 {
+		t.Errorf("Expected the checkpoint stream to expire, got TTL %v", ttl)
+	}
+
+	for _, expected := range []JobStep{StepConfigLoaded, StepActivitiesFetched} {
+		select {
+		case event := <-events:
+			if event.Status != JobStatusRunning || event.Checkpoint == nil || event.Checkpoint.Step != expected {
+				t.Errorf("Expected running event for %s, got %+v", expected, event)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %s event", expected)
+		}
+	}
+
+	unknown, err := client.GetJobCheckpoints(ctx, "unknown")
+	if err != nil || len(unknown) != 0 {
+		t.Errorf("Expected no checkpoints for an unknown job, got %+v (err %v)", unknown, err)
+	}
+}
+
+func TestClient_Maintenance(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	if maintenance, err := client.GetMaintenance(ctx); err != nil || maintenance != nil {
+		t.Fatalf("Expected no maintenance, got %+v, %v", maintenance, err)
+	}
+
+	endsAt := time.Date(2024, 6, 20, 23, 0, 0, 0, time.UTC)
+	if err := client.SetMaintenance(ctx, &Maintenance{Message: "Database upgrade", StartedBy: 1, StartedAt: endsAt.Add(-time.Hour), EndsAt: &endsAt}); err != nil {
+		t.Fatalf("SetMaintenance failed: %v", err)
+	}
+	maintenance, err := client.GetMaintenance(ctx)
+	if err != nil || maintenance == nil || maintenance.Message != "Database upgrade" || !maintenance.EndsAt.Equal(endsAt) {
+		t.Fatalf("Expected the stored maintenance, got %+v, %v", maintenance, err)
+	}
+
+	if cleared, err := client.ClearMaintenance(ctx); err != nil || !cleared {
+		t.Fatalf("Expected maintenance to be cleared, got %t, %v", cleared, err)
+	}
+	if cleared, _ := client.ClearMaintenance(ctx); cleared {
+		t.Error("Expected nothing to clear once maintenance ended")
+	}
+	if maintenance, _ := client.GetMaintenance(ctx); maintenance != nil {
+		t.Errorf("Expected no maintenance after clearing, got %+v", maintenance)
+	}
+}
+
+func TestClient_TaskLock(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if acquired, err := client.AcquireTaskLock(ctx, "retention", "engine-a", time.Hour); err != nil || !acquired {
+		t.Fatalf("Expected to claim an unclaimed task, got %t, %v", acquired, err)
+	}
+	if acquired, _ := client.AcquireTaskLock(ctx, "retention", "engine-b", time.Hour); acquired {
+		t.Error("Expected another instance to be refused within the interval")
+	}
+	if acquired, _ := client.AcquireTaskLock(ctx, "other", "engine-b", time.Hour); !acquired {
+		t.Error("Expected claims to be per task")
+	}
+
+	server.FastForward(time.Hour)
+	if acquired, _ := client.AcquireTaskLock(ctx, "retention", "engine-b", time.Hour); !acquired {
+		t.Error("Expected the task to be claimable once the interval passed")
+	}
+}
+
+func TestClient_DeadLetters(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	first := &Job{TraceID: "trace-1", UserID: 1, TriggerType: TriggerSchedule}
+	second := &Job{TraceID: "trace-2", UserID: 2, TriggerType: TriggerManualSync}
+	if err := client.DeadLetterJob(ctx, first, "STRAVA_UNAVAILABLE"); err != nil {
+		t.Fatalf("DeadLetterJob failed: %v", err)
+	}
+	if err := client.DeadLetterJob(ctx, second, "JOB_TIMEOUT"); err != nil {
+		t.Fatalf("DeadLetterJob failed: %v", err)
+	}
+
+	// An entry that is not a job is dead-lettered by Dequeue instead of being lost
+	server.Lpush(JobQueueKey, "not json")
+	if _, err := client.Dequeue(ctx, time.Second); err == nil {
+		t.Fatal("Expected an undecodable entry to fail Dequeue")
+	}
+	if err := client.Defer(ctx, &Job{UserID: 3, TriggerType: TriggerSchedule}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+
+	stats, err := client.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Queued != 0 || stats.Deferred != 1 || stats.DeadLettered != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	entries, err := client.ListDeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Job.TraceID != "trace-1" || entries[1].Reason != "JOB_TIMEOUT" || entries[2].Payload != "not json" {
+		t.Errorf("Expected dead letters oldest first, got %+v", entries)
+	}
+
+	requeued, err := client.RequeueDeadLetters(ctx, 1)
+	if err != nil || requeued != 1 {
+		t.Fatalf("Expected one job requeued, got %d, %v", requeued, err)
+	}
+	job, err := client.Dequeue(ctx, time.Second)
+	if err != nil || job == nil {
+		t.Fatalf("Expected the requeued job, got %v, %v", job, err)
+	}
+	if job.UserID != 1 || job.TraceID == "trace-1" || job.TraceID == "" {
+		t.Errorf("Expected the oldest job requeued with a new trace ID, got %+v", job)
+	}
+
+	// The undecodable entry is kept for inspection
+	if requeued, _ := client.RequeueDeadLetters(ctx, 10); requeued != 1 {
+		t.Errorf("Expected the remaining job requeued, got %d", requeued)
+	}
+	if entries, _ := client.ListDeadLetters(ctx, 10); len(entries) != 1 || entries[0].Payload != "not json" {
+		t.Errorf("Expected only the undecodable entry left, got %+v", entries)
+	}
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reauthKeyPrefix prefixes the per-user, per-provider reauth markers
+const reauthKeyPrefix = "academy-sync:reauth-required:"
+
+// DefaultReauthMarkerTTL is how long a confirmed "reauth required" marker short-circuits jobs.
+// It is long enough to cover a burst of queued jobs for the same user and short enough that a
+// provider-side fix (or a marker set in error) heals without user action.
+const DefaultReauthMarkerTTL = 15 * time.Minute
+
+// MarkReauthRequired records that the provider rejected the user's credentials. fingerprint
+// identifies the rejected credentials, so the marker stops matching once the user reconnects.
+func (c *Client) MarkReauthRequired(ctx context.Context, userID int, provider, fingerprint string, ttl time.Duration) error {
+	if err := c.redis.Set(ctx, reauthKey(userID, provider), fingerprint, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store reauth marker: %w", err)
+	}
+	return nil
+}
+
+// IsReauthRequired reports whether a marker for the same credentials is still live
+func (c *Client) IsReauthRequired(ctx context.Context, userID int, provider, fingerprint string) (bool, error) {
+	stored, err := c.redis.Get(ctx, reauthKey(userID, provider)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read reauth marker: %w", err)
+	}
+	return stored == fingerprint, nil
+}
+
+func reauthKey(userID int, provider string) string {
+	return reauthKeyPrefix + provider + ":" + strconv.Itoa(userID)
+}
@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// responseCacheKeyPrefix prefixes provider responses shared by the engine instances
+const responseCacheKeyPrefix = "academy-sync:response-cache:"
+
+// GetCachedResponse returns the cached response stored under key, and false when there is none
+func (c *Client) GetCachedResponse(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.redis.Get(ctx, responseCacheKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cached response: %w", err)
+	}
+	return value, true, nil
+}
+
+// SetCachedResponse stores a response under key for ttl
+func (c *Client) SetCachedResponse(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.redis.Set(ctx, responseCacheKeyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+	return nil
+}
+
+// DeleteCachedResponses removes the cached responses stored under keys
+func (c *Client) DeleteCachedResponses(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = responseCacheKeyPrefix + key
+	}
+	if err := c.redis.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cached responses: %w", err)
+	}
+	return nil
+}
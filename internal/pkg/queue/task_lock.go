@@ -0,0 +1,21 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// taskLockKeyPrefix prefixes the claims on periodic tasks shared by the engine's instances
+const taskLockKeyPrefix = "academy-sync:task-lock:"
+
+// AcquireTaskLock claims the named periodic task for owner for ttl, unless another instance
+// claimed it within the last ttl. Claims are left to expire rather than released, so a task
+// claimed once per ttl runs on one instance per interval.
+func (c *Client) AcquireTaskLock(ctx context.Context, task, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := c.redis.SetNX(ctx, taskLockKeyPrefix+task, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire task lock: %w", err)
+	}
+	return acquired, nil
+}
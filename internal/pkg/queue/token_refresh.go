@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Prefixes of the per-refresh locks and shared refresh results
+const (
+	tokenRefreshLockPrefix   = "academy-sync:token-refresh-lock:"
+	tokenRefreshResultPrefix = "academy-sync:token-refresh-result:"
+)
+
+// releaseLockScript deletes a lock only while it is still held by the caller, so a refresh that
+// outlived its lock cannot release the lock of the refresh that took over
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireTokenRefreshLock takes the lock for key on behalf of owner unless another owner holds
+// it; the lock expires after ttl in case its owner dies mid-refresh
+func (c *Client) AcquireTokenRefreshLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := c.redis.SetNX(ctx, tokenRefreshLockPrefix+key, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire token refresh lock: %w", err)
+	}
+	return acquired, nil
+}
+
+// ReleaseTokenRefreshLock releases the lock for key if owner still holds it
+func (c *Client) ReleaseTokenRefreshLock(ctx context.Context, key, owner string) error {
+	if err := releaseLockScript.Run(ctx, c.redis, []string{tokenRefreshLockPrefix + key}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release token refresh lock: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshedToken returns the refresh result shared under key, or nil when there is none
+func (c *Client) GetRefreshedToken(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.redis.Get(ctx, tokenRefreshResultPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refreshed token: %w", err)
+	}
+	return value, nil
+}
+
+// StoreRefreshedToken shares a refresh result under key for ttl
+func (c *Client) StoreRefreshedToken(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.redis.Set(ctx, tokenRefreshResultPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store refreshed token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// userLockKeyPrefix prefixes the per-user processing locks
+const userLockKeyPrefix = "academy-sync:user-lock:"
+
+// DefaultUserLockTTL is how long a user's processing lock outlives its last renewal, which
+// bounds how long a crashed engine keeps the user's other jobs waiting
+const DefaultUserLockTTL = 2 * time.Minute
+
+// renewLockScript extends a lock only while it is still held by the caller
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// AcquireUserLock takes the user's processing lock on behalf of owner unless another job holds it
+func (c *Client) AcquireUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := c.redis.SetNX(ctx, userLockKey(userID), owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire user lock: %w", err)
+	}
+	return acquired, nil
+}
+
+// RenewUserLock extends the user's lock to ttl from now and reports whether owner still held it
+func (c *Client) RenewUserLock(ctx context.Context, userID int, owner string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLockScript.Run(ctx, c.redis, []string{userLockKey(userID)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew user lock: %w", err)
+	}
+	return renewed == 1, nil
+}
+
+// ReleaseUserLock releases the user's lock if owner still holds it
+func (c *Client) ReleaseUserLock(ctx context.Context, userID int, owner string) error {
+	if err := releaseLockScript.Run(ctx, c.redis, []string{userLockKey(userID)}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release user lock: %w", err)
+	}
+	return nil
+}
+
+func userLockKey(userID int) string {
+	return userLockKeyPrefix + strconv.Itoa(userID)
+}
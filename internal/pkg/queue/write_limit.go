@@ -0,0 +1,54 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sheetsWriteBucketKey holds the token bucket shared by every engine instance's Sheets writes
+const sheetsWriteBucketKey = "academy-sync:sheets-write-bucket"
+
+// takeTokenScript refills the bucket for the time since it was last used, then takes a token.
+// It returns 0 when a token was taken, or the milliseconds until one will be available. The time
+// is read from the Redis server, so clock skew between engine instances cannot refill the bucket.
+//
+// ARGV: tokens per minute, bucket capacity
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / 60000
+local capacity = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(bucket[1]) or capacity
+local updated = tonumber(bucket[2]) or now
+if now > updated then
+	tokens = math.min(capacity, tokens + (now - updated) * rate)
+	updated = now
+end
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(updated))
+redis.call("PEXPIRE", KEYS[1], math.ceil(capacity / rate) + 60000)
+return wait
+`)
+
+// TakeSheetsWriteToken takes a token from the Sheets write bucket shared by all engine
+// instances, which refills at perMinute tokens a minute up to burst. It returns zero when a
+// token was taken, or how long to wait before trying again.
+func (c *Client) TakeSheetsWriteToken(ctx context.Context, perMinute, burst int) (time.Duration, error) {
+	wait, err := takeTokenScript.Run(ctx, c.redis, []string{sheetsWriteBucketKey}, perMinute, burst).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to take Sheets write token: %w", err)
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
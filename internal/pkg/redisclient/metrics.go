@@ -0,0 +1,173 @@
+package redisclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stats are the command counters and pool statistics of one client since it was created
+type Stats struct {
+	Addr         string  `json:"addr"`
+	DB           int     `json:"db"`
+	Commands     int64   `json:"commands"`       // Pipelined commands count one each
+	Errors       int64   `json:"errors"`         // Failed commands; a missing key (redis.Nil) is not an error
+	DialErrors   int64   `json:"dial_errors"`    // Connections that could not be opened
+	AvgLatencyMs float64 `json:"avg_latency_ms"` // Per command or pipeline
+	MaxLatencyMs int64   `json:"max_latency_ms"`
+
+	Pool PoolStats `json:"pool"`
+}
+
+// PoolStats describes a client's connection pool
+type PoolStats struct {
+	Hits       uint32 `json:"hits"`     // Commands that found a free connection
+	Misses     uint32 `json:"misses"`   // Commands that had to open a connection
+	Timeouts   uint32 `json:"timeouts"` // Commands that gave up waiting for a connection
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+}
+
+type clientCounters struct {
+	commands, errors, dialErrors atomic.Int64
+	calls                        atomic.Int64
+	totalLatencyMs, maxLatencyMs atomic.Int64
+}
+
+var (
+	registryMu sync.Mutex
+	factories  = map[*Factory]struct{}{}
+)
+
+func register(f *Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	factories[f] = struct{}{}
+}
+
+func unregister(f *Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(factories, f)
+}
+
+// Snapshot returns the counters and pool statistics of the clients of every open factory,
+// ordered by address
+func Snapshot() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var snapshot []Stats
+	for f := range factories {
+		snapshot = append(snapshot, f.stats()...)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Addr != snapshot[j].Addr {
+			return snapshot[i].Addr < snapshot[j].Addr
+		}
+		return snapshot[i].DB < snapshot[j].DB
+	})
+	return snapshot
+}
+
+// stats returns the statistics of the factory's clients
+func (f *Factory) stats() []Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := make([]Stats, 0, len(f.clients))
+	for _, client := range f.clients {
+		rdb, c := client.rdb, client.counters
+		options := rdb.Options()
+		pool := rdb.PoolStats()
+		clientStats := Stats{
+			Addr:         options.Addr,
+			DB:           options.DB,
+			Commands:     c.commands.Load(),
+			Errors:       c.errors.Load(),
+			DialErrors:   c.dialErrors.Load(),
+			MaxLatencyMs: c.maxLatencyMs.Load(),
+			Pool: PoolStats{
+				Hits:       pool.Hits,
+				Misses:     pool.Misses,
+				Timeouts:   pool.Timeouts,
+				TotalConns: pool.TotalConns,
+				IdleConns:  pool.IdleConns,
+				StaleConns: pool.StaleConns,
+			},
+		}
+		if calls := c.calls.Load(); calls > 0 {
+			clientStats.AvgLatencyMs = float64(c.totalLatencyMs.Load()) / float64(calls)
+		}
+		stats = append(stats, clientStats)
+	}
+	return stats
+}
+
+// metricsHook counts the commands of one client and records their latency
+type metricsHook struct {
+	counters *clientCounters
+}
+
+func (h metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.counters.dialErrors.Add(1)
+		}
+		return conn, err
+	}
+}
+
+func (h metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(time.Since(start), 1)
+		h.countError(err)
+		return err
+	}
+}
+
+func (h metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(time.Since(start), len(cmds))
+		for _, cmd := range cmds {
+			h.countError(cmd.Err())
+		}
+		return err
+	}
+}
+
+// record counts a command or pipeline of commands that took elapsed
+func (h metricsHook) record(elapsed time.Duration, commands int) {
+	c := h.counters
+	latency := elapsed.Milliseconds()
+	c.calls.Add(1)
+	c.totalLatencyMs.Add(latency)
+	for {
+		max := c.maxLatencyMs.Load()
+		if latency <= max || c.maxLatencyMs.CompareAndSwap(max, latency) {
+			break
+		}
+	}
+
+	c.commands.Add(int64(commands))
+}
+
+// countError counts a command's error. The error of a single command is only set on it after
+// the hooks return, so ProcessHook passes the error returned by the command.
+func (h metricsHook) countError(err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		h.counters.errors.Add(1)
+	}
+}
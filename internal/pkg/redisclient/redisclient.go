@@ -0,0 +1,126 @@
+// Package redisclient creates the Redis connections of a process. Redis users (the job queue,
+// locks, caches and event streams) take their client from one Factory, so they share a
+// connection pool per Redis URL, URLs are parsed in one place and every command is counted in
+// the same metrics.
+package redisclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PingTimeout bounds the health check of a connection
+const PingTimeout = 5 * time.Second
+
+// Options are the connection pool settings applied to every client; zero values keep the
+// go-redis defaults
+type Options struct {
+	PoolSize        int
+	MinIdleConns    int
+	PoolTimeout     time.Duration
+	DialTimeout     time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// New creates a standalone client for a redis:// or rediss:// URL with the pool settings of opts;
+// the caller closes it. Clients of a Factory are shared and counted in the metrics instead.
+func New(url string, opts Options) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if opts.PoolSize > 0 {
+		options.PoolSize = opts.PoolSize
+	}
+	if opts.MinIdleConns > 0 {
+		options.MinIdleConns = opts.MinIdleConns
+	}
+	if opts.PoolTimeout > 0 {
+		options.PoolTimeout = opts.PoolTimeout
+	}
+	if opts.DialTimeout > 0 {
+		options.DialTimeout = opts.DialTimeout
+	}
+	if opts.ConnMaxIdleTime > 0 {
+		options.ConnMaxIdleTime = opts.ConnMaxIdleTime
+	}
+
+	return redis.NewClient(options), nil
+}
+
+// Ping checks that rdb can reach Redis, waiting at most PingTimeout
+func Ping(ctx context.Context, rdb *redis.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping failed: %w", err)
+	}
+	return nil
+}
+
+// Factory hands out one shared client per Redis URL and counts their commands (see Snapshot).
+// The clients belong to the factory: users must not close them, and Close closes them all.
+type Factory struct {
+	opts Options
+
+	mu      sync.Mutex
+	clients map[string]*sharedClient
+	closed  bool
+}
+
+// sharedClient is a factory's client for one URL and its command counters
+type sharedClient struct {
+	rdb      *redis.Client
+	counters *clientCounters
+}
+
+// NewFactory creates a factory whose clients use the pool settings of opts
+func NewFactory(opts Options) *Factory {
+	f := &Factory{opts: opts, clients: make(map[string]*sharedClient)}
+	register(f)
+	return f
+}
+
+// Client returns the client for url, creating it on first use; later calls for the same URL
+// reuse its connection pool
+func (f *Factory) Client(url string) (*redis.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, fmt.Errorf("redis client factory is closed")
+	}
+	if client, ok := f.clients[url]; ok {
+		return client.rdb, nil
+	}
+	rdb, err := New(url, f.opts)
+	if err != nil {
+		return nil, err
+	}
+	counters := &clientCounters{}
+	rdb.AddHook(metricsHook{counters: counters})
+	f.clients[url] = &sharedClient{rdb: rdb, counters: counters}
+	return rdb, nil
+}
+
+// Close closes every client created by the factory and drops them from the metrics
+func (f *Factory) Close() error {
+	unregister(f)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var firstErr error
+	for url, client := range f.clients {
+		if err := client.rdb.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(f.clients, url)
+	}
+	f.closed = true
+	return firstErr
+}
@@ -0,0 +1,115 @@
+package redisclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNew_AppliesPoolOptions(t *testing.T) {
+	rdb, err := New("redis://localhost:6379/2", Options{PoolSize: 7, MinIdleConns: 2, PoolTimeout: 3 * time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer rdb.Close()
+
+	options := rdb.Options()
+	if options.PoolSize != 7 || options.MinIdleConns != 2 || options.PoolTimeout != 3*time.Second {
+		t.Errorf("Expected the pool options to be applied, got %+v", options)
+	}
+	if options.DB != 2 {
+		t.Errorf("Expected the database from the URL, got %d", options.DB)
+	}
+	if options.DialTimeout != 5*time.Second {
+		t.Errorf("Expected a zero dial timeout to keep the default, got %v", options.DialTimeout)
+	}
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	if _, err := New("http://localhost", Options{}); err == nil {
+		t.Error("Expected an error for a non-Redis URL")
+	}
+}
+
+func TestFactory_ReusesClientPerURL(t *testing.T) {
+	server := miniredis.RunT(t)
+	factory := NewFactory(Options{})
+	defer factory.Close()
+
+	first, err := factory.Client("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("Client failed: %v", err)
+	}
+	second, _ := factory.Client("redis://" + server.Addr())
+	if first != second {
+		t.Error("Expected the same client for the same URL")
+	}
+	other, _ := factory.Client("redis://" + server.Addr() + "/1")
+	if other == first {
+		t.Error("Expected a separate client for another database")
+	}
+
+	if err := Ping(context.Background(), first); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestFactory_Close(t *testing.T) {
+	server := miniredis.RunT(t)
+	factory := NewFactory(Options{})
+	rdb, _ := factory.Client("redis://" + server.Addr())
+
+	if err := factory.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := rdb.Ping(context.Background()).Err(); !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("Expected the client to be closed, got %v", err)
+	}
+	if _, err := factory.Client("redis://" + server.Addr()); err == nil {
+		t.Error("Expected an error from a closed factory")
+	}
+	for _, stats := range Snapshot() {
+		if stats.Addr == server.Addr() {
+			t.Error("Expected the closed client to be dropped from the metrics")
+		}
+	}
+}
+
+func TestSnapshot_CountsCommands(t *testing.T) {
+	server := miniredis.RunT(t)
+	factory := NewFactory(Options{})
+	defer factory.Close()
+	rdb, _ := factory.Client("redis://" + server.Addr())
+
+	ctx := context.Background()
+	rdb.Set(ctx, "key", "value", 0)
+	rdb.Get(ctx, "missing")      // redis.Nil is not an error
+	rdb.Do(ctx, "NOSUCHCOMMAND") // An error
+	pipe := rdb.Pipeline()
+	pipe.Get(ctx, "key")
+	pipe.Incr(ctx, "counter")
+	pipe.Exec(ctx)
+
+	var stats *Stats
+	for _, s := range Snapshot() {
+		if s.Addr == server.Addr() {
+			stats = &s
+		}
+	}
+	if stats == nil {
+		t.Fatal("Expected the client in the snapshot")
+	}
+	if stats.Commands != 5 {
+		t.Errorf("Expected 5 commands, got %d", stats.Commands)
+	}
+	if stats.Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", stats.Errors)
+	}
+	if stats.Pool.TotalConns == 0 {
+		t.Error("Expected the pool statistics to show an open connection")
+	}
+}
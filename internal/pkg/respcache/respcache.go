@@ -0,0 +1,180 @@
+// Package respcache caches provider responses that rarely change, such as the Strava athlete
+// profile and spreadsheet metadata, so repeated jobs do not spend API latency and quota on them.
+// Entries live in memory for the process and, when a shared store is attached, in Redis for every
+// process. Keys name the credentials and resource a response was fetched with, so reconnecting an
+// account or choosing another spreadsheet reads a fresh response instead of a stale one.
+package respcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// Defaults for the engine's response cache
+const (
+	DefaultTTL        = 15 * time.Minute
+	DefaultMaxEntries = 1000
+)
+
+// Store is a cache shared between processes (implemented by *queue.Client)
+type Store interface {
+	GetCachedResponse(ctx context.Context, key string) ([]byte, bool, error)
+	SetCachedResponse(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	DeleteCachedResponses(ctx context.Context, keys ...string) error
+}
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// Cache is a TTL cache of JSON-encoded responses. A nil *Cache caches nothing, so clients can
+// call it unconditionally. Store failures are logged and treated as misses.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	logger     *logger.Logger
+
+	mu      sync.Mutex
+	entries map[string]entry
+
+	// Optional shared store (see SetStore)
+	store Store
+}
+
+// New creates an in-memory cache whose entries expire after ttl; at most maxEntries are kept
+func New(ttl time.Duration, maxEntries int, logger *logger.Logger) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]entry),
+		logger:     logger.WithContext("component", "response_cache"),
+	}
+}
+
+// SetStore shares cached responses with other processes through store. A process that misses in
+// memory reads the shared entry and keeps it in memory for the rest of its TTL.
+func (c *Cache) SetStore(store Store) {
+	c.store = store
+}
+
+// Get decodes the cached response for key into dst and reports whether there was one
+func (c *Cache) Get(ctx context.Context, key string, dst interface{}) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	if ok && time.Now().After(cached.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok && c.store != nil {
+		value, found, err := c.store.GetCachedResponse(ctx, key)
+		if err != nil {
+			c.logger.Warn("Failed to read shared response cache", "error", err, "key", key)
+		}
+		if found {
+			// The shared entry may be older; keeping it for a full TTL bounds staleness at twice the TTL
+			cached, ok = entry{value: value, expiresAt: time.Now().Add(c.ttl)}, true
+			c.remember(key, cached)
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	if err := json.Unmarshal(cached.value, dst); err != nil {
+		c.logger.Warn("Dropping undecodable cached response", "error", err, "key", key)
+		c.Invalidate(ctx, key)
+		return false
+	}
+	return true
+}
+
+// Set caches value, encoded as JSON, under key
+func (c *Cache) Set(ctx context.Context, key string, value interface{}) {
+	if c == nil {
+		return
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Failed to encode response for caching", "error", err, "key", key)
+		return
+	}
+
+	c.remember(key, entry{value: payload, expiresAt: time.Now().Add(c.ttl)})
+	if c.store != nil {
+		if err := c.store.SetCachedResponse(ctx, key, payload, c.ttl); err != nil {
+			c.logger.Warn("Failed to write shared response cache", "error", err, "key", key)
+		}
+	}
+}
+
+// Invalidate drops the cached responses for keys, e.g. after a change through the same client
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.DeleteCachedResponses(ctx, keys...); err != nil {
+			c.logger.Warn("Failed to invalidate shared response cache", "error", err, "keys", keys)
+		}
+	}
+}
+
+// remember keeps an entry in memory, first evicting expired entries and then arbitrary ones when full
+func (c *Cache) remember(key string, cached entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cached
+}
+
+// AthleteProfileKey is the key of a user's Strava athlete profile fetched with refreshToken
+func AthleteProfileKey(userID int, refreshToken string) string {
+	return fmt.Sprintf("strava:athlete:%d:%s", userID, fingerprint(refreshToken))
+}
+
+// SpreadsheetKey is the key of a spreadsheet's metadata as read by a user with refreshToken
+func SpreadsheetKey(userID int, refreshToken, spreadsheetID string) string {
+	return fmt.Sprintf("google:spreadsheet:%d:%s:%s", userID, fingerprint(refreshToken), spreadsheetID)
+}
+
+// fingerprint identifies a credential in cache keys without revealing it
+func fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
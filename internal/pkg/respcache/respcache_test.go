@@ -0,0 +1,125 @@
+package respcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type memoryStore struct {
+	values map[string][]byte
+}
+
+func (s *memoryStore) GetCachedResponse(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func (s *memoryStore) SetCachedResponse(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryStore) DeleteCachedResponses(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	return nil
+}
+
+type profile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestCache_GetSetAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := New(time.Minute, 10, logger.New("test"))
+
+	var got profile
+	if cache.Get(ctx, "athlete", &got) {
+		t.Fatal("Expected a miss before anything was cached")
+	}
+
+	cache.Set(ctx, "athlete", profile{ID: 7, Name: "Ada"})
+	if !cache.Get(ctx, "athlete", &got) || got.ID != 7 || got.Name != "Ada" {
+		t.Fatalf("Expected the cached profile, got %+v", got)
+	}
+
+	cache.entries["athlete"] = entry{value: cache.entries["athlete"].value, expiresAt: time.Now().Add(-time.Second)}
+	if cache.Get(ctx, "athlete", &got) {
+		t.Fatal("Expected an expired entry to miss")
+	}
+
+	cache.Set(ctx, "athlete", profile{ID: 7})
+	cache.Invalidate(ctx, "athlete")
+	if cache.Get(ctx, "athlete", &got) {
+		t.Fatal("Expected an invalidated entry to miss")
+	}
+}
+
+func TestCache_SharesEntriesThroughStore(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{values: make(map[string][]byte)}
+
+	writer := New(time.Minute, 10, logger.New("test"))
+	writer.SetStore(store)
+	writer.Set(ctx, "athlete", profile{ID: 7})
+
+	reader := New(time.Minute, 10, logger.New("test"))
+	reader.SetStore(store)
+	var got profile
+	if !reader.Get(ctx, "athlete", &got) || got.ID != 7 {
+		t.Fatalf("Expected the entry cached by another process, got %+v", got)
+	}
+
+	// Invalidation reaches the shared store, so other processes stop serving the entry once
+	// their own copy expires
+	writer.Invalidate(ctx, "athlete")
+	if _, ok := store.values["athlete"]; ok {
+		t.Fatal("Expected the shared entry to be deleted")
+	}
+}
+
+func TestCache_EvictsWhenFull(t *testing.T) {
+	ctx := context.Background()
+	cache := New(time.Minute, 2, logger.New("test"))
+
+	cache.Set(ctx, "a", profile{ID: 1})
+	cache.Set(ctx, "b", profile{ID: 2})
+	cache.Set(ctx, "c", profile{ID: 3})
+
+	if len(cache.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(cache.entries))
+	}
+	var got profile
+	if !cache.Get(ctx, "c", &got) || got.ID != 3 {
+		t.Fatalf("Expected the newest entry to be kept, got %+v", got)
+	}
+}
+
+func TestCache_NilCachesNothing(t *testing.T) {
+	ctx := context.Background()
+	var cache *Cache
+
+	cache.Set(ctx, "athlete", profile{ID: 7})
+	cache.Invalidate(ctx, "athlete")
+	var got profile
+	if cache.Get(ctx, "athlete", &got) {
+		t.Fatal("Expected a nil cache to miss")
+	}
+}
+
+func TestKeys_ChangeWithCredentialsAndSpreadsheet(t *testing.T) {
+	if AthleteProfileKey(1, "token-a") == AthleteProfileKey(1, "token-b") {
+		t.Error("Expected reconnecting Strava to change the athlete profile key")
+	}
+	if SpreadsheetKey(1, "token", "sheet-a") == SpreadsheetKey(1, "token", "sheet-b") {
+		t.Error("Expected another spreadsheet to change the key")
+	}
+	if SpreadsheetKey(1, "token-a", "sheet") == SpreadsheetKey(1, "token-b", "sheet") {
+		t.Error("Expected reconnecting Google to change the spreadsheet key")
+	}
+}
@@ -0,0 +1,165 @@
+// Package retention prunes automation runs and audit events older than the retention period,
+// exporting them as newline-delimited JSON before they are deleted.
+package retention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/objectstore"
+)
+
+// taskName names the pruning task's claim shared by the engine's instances
+const taskName = "retention"
+
+// Store finds and deletes rows past the retention period
+type Store interface {
+	ListExpiredRows(ctx context.Context, table string, before time.Time, limit int) ([]database.ExpiredRow, error)
+	DeleteRows(ctx context.Context, table string, ids []int64) (int64, error)
+}
+
+// Archiver stores an export of pruned rows under name; *objectstore.GCSBucket satisfies it
+type Archiver interface {
+	Put(ctx context.Context, name, contentType string, data []byte) error
+}
+
+// TaskLocker claims a periodic task for one instance per interval
+type TaskLocker interface {
+	AcquireTaskLock(ctx context.Context, task, owner string, ttl time.Duration) (bool, error)
+}
+
+// Pruner deletes rows of database.RetentionTables dated before the retention period, a batch at
+// a time. With an archiver each batch is exported first and kept when the export fails.
+type Pruner struct {
+	store     Store
+	archiver  Archiver
+	period    time.Duration
+	batchSize int
+	logger    *logger.Logger
+}
+
+// NewPruner creates a pruner; archiver may be nil to delete rows without exporting them
+func NewPruner(store Store, archiver Archiver, period time.Duration, batchSize int, logger *logger.Logger) *Pruner {
+	return &Pruner{
+		store:     store,
+		archiver:  archiver,
+		period:    period,
+		batchSize: batchSize,
+		logger:    logger.WithContext("component", "retention_pruner"),
+	}
+}
+
+// Prune deletes every row older than the retention period at now and returns the number deleted
+// per table. It stops at the first failure, leaving the rest for the next run.
+func (p *Pruner) Prune(ctx context.Context, now time.Time) (map[string]int64, error) {
+	pruned := make(map[string]int64)
+	if p.period <= 0 {
+		return pruned, nil
+	}
+
+	before := now.Add(-p.period)
+	for _, table := range database.RetentionTables {
+		for {
+			rows, err := p.store.ListExpiredRows(ctx, table, before, p.batchSize)
+			if err != nil {
+				return pruned, err
+			}
+			if len(rows) == 0 {
+				break
+			}
+
+			if p.archiver != nil {
+				if err := p.archiver.Put(ctx, archiveName(table, now, rows), objectstore.ContentTypeNDJSON, encodeRows(rows)); err != nil {
+					return pruned, fmt.Errorf("failed to archive expired %s: %w", table, err)
+				}
+			}
+
+			ids := make([]int64, len(rows))
+			for i, row := range rows {
+				ids[i] = row.ID
+			}
+			deleted, err := p.store.DeleteRows(ctx, table, ids)
+			if err != nil {
+				return pruned, err
+			}
+			pruned[table] += deleted
+
+			if len(rows) < p.batchSize {
+				break
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// Run prunes every interval until ctx is cancelled. When locker is set, only the instance that
+// claims the interval prunes.
+func (p *Pruner) Run(ctx context.Context, interval time.Duration, locker TaskLocker) {
+	if p.period <= 0 {
+		p.logger.Info("Retention pruning disabled; automation runs and audit events are kept forever")
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	owner := hostname + ":" + strconv.Itoa(os.Getpid())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if locker != nil {
+			claimed, err := locker.AcquireTaskLock(ctx, taskName, owner, interval)
+			if err != nil {
+				p.logger.Warn("Failed to claim retention pruning", "error", err)
+			}
+			if claimed {
+				p.runOnce(ctx)
+			}
+		} else {
+			p.runOnce(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pruner) runOnce(ctx context.Context) {
+	pruned, err := p.Prune(ctx, time.Now())
+	for table, count := range pruned {
+		if count > 0 {
+			p.logger.Info("Pruned expired rows",
+				"table", table,
+				"count", count,
+				"archived", p.archiver != nil)
+		}
+	}
+	if err != nil {
+		p.logger.Error("Retention pruning failed", "error", err)
+	}
+}
+
+// archiveName names the export of a batch by table, pruning date and ID range, e.g.
+// automation_runs/2026-10-16/000000000101-000000001100.ndjson
+func archiveName(table string, now time.Time, rows []database.ExpiredRow) string {
+	return fmt.Sprintf("%s/%s/%012d-%012d.ndjson", table, now.UTC().Format("2006-01-02"), rows[0].ID, rows[len(rows)-1].ID)
+}
+
+// encodeRows writes one JSON object per line
+func encodeRows(rows []database.ExpiredRow) []byte {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		buf.Write(row.Data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,110 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// fakeStore holds each table's rows by ID with the time that dates them
+type fakeStore struct {
+	rows map[string]map[int64]time.Time
+}
+
+func (s *fakeStore) ListExpiredRows(ctx context.Context, table string, before time.Time, limit int) ([]database.ExpiredRow, error) {
+	var expired []database.ExpiredRow
+	for id := int64(1); len(expired) < limit && id <= 100; id++ {
+		if at, ok := s.rows[table][id]; ok && at.Before(before) {
+			expired = append(expired, database.ExpiredRow{ID: id, Data: json.RawMessage(fmt.Sprintf(`{"id":%d}`, id))})
+		}
+	}
+	return expired, nil
+}
+
+func (s *fakeStore) DeleteRows(ctx context.Context, table string, ids []int64) (int64, error) {
+	for _, id := range ids {
+		delete(s.rows[table], id)
+	}
+	return int64(len(ids)), nil
+}
+
+type fakeArchiver struct {
+	objects map[string]string
+	err     error
+}
+
+func (a *fakeArchiver) Put(ctx context.Context, name, contentType string, data []byte) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.objects[name] = string(data)
+	return nil
+}
+
+func TestPruner_Prune(t *testing.T) {
+	now := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	old, recent := now.AddDate(0, 0, -200), now.AddDate(0, 0, -10)
+	store := &fakeStore{rows: map[string]map[int64]time.Time{
+		"automation_runs": {1: old, 2: old, 3: old, 4: recent},
+		"sign_in_events":  {7: recent},
+		"role_changes":    {9: old},
+	}}
+	archiver := &fakeArchiver{objects: make(map[string]string)}
+	pruner := NewPruner(store, archiver, 180*24*time.Hour, 2, logger.New("test"))
+
+	pruned, err := pruner.Prune(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if pruned["automation_runs"] != 3 || pruned["sign_in_events"] != 0 || pruned["role_changes"] != 1 {
+		t.Errorf("Unexpected pruned counts: %v", pruned)
+	}
+	if _, kept := store.rows["automation_runs"][4]; !kept || len(store.rows["sign_in_events"]) != 1 {
+		t.Error("Expected rows within the retention period to be kept")
+	}
+
+	// Each batch is exported as one JSON object per line
+	want := map[string]string{
+		"automation_runs/2026-10-16/000000000001-000000000002.ndjson": "{\"id\":1}\n{\"id\":2}\n",
+		"automation_runs/2026-10-16/000000000003-000000000003.ndjson": "{\"id\":3}\n",
+		"role_changes/2026-10-16/000000000009-000000000009.ndjson":    "{\"id\":9}\n",
+	}
+	if len(archiver.objects) != len(want) {
+		t.Errorf("Expected %d exports, got %v", len(want), archiver.objects)
+	}
+	for name, data := range want {
+		if archiver.objects[name] != data {
+			t.Errorf("Export %s = %q, want %q", name, archiver.objects[name], data)
+		}
+	}
+}
+
+func TestPruner_ArchiveFailureKeepsRows(t *testing.T) {
+	now := time.Now()
+	store := &fakeStore{rows: map[string]map[int64]time.Time{
+		"automation_runs": {1: now.AddDate(-1, 0, 0)},
+	}}
+	archiver := &fakeArchiver{err: errors.New("bucket unavailable")}
+	pruner := NewPruner(store, archiver, 180*24*time.Hour, 10, logger.New("test"))
+
+	if _, err := pruner.Prune(context.Background(), now); err == nil {
+		t.Fatal("Expected the archive failure to be reported")
+	}
+	if len(store.rows["automation_runs"]) != 1 {
+		t.Error("Expected rows that failed to export not to be deleted")
+	}
+
+	// Without an archiver rows are deleted outright, and a zero period keeps everything
+	if pruned, _ := NewPruner(store, nil, 0, 10, logger.New("test")).Prune(context.Background(), now); len(pruned) != 0 {
+		t.Errorf("Expected nothing pruned with retention disabled, got %v", pruned)
+	}
+	if pruned, err := NewPruner(store, nil, 180*24*time.Hour, 10, logger.New("test")).Prune(context.Background(), now); err != nil || pruned["automation_runs"] != 1 {
+		t.Errorf("Expected the row to be deleted without an archiver, got %v (%v)", pruned, err)
+	}
+}
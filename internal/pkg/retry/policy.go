@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decision is what the retry loop does after a failed attempt
+type Decision struct {
+	// Abort returns the error at once instead of retrying
+	Abort bool
+	// RetryAfter, when positive, replaces the backoff delay before the next attempt
+	RetryAfter time.Duration
+}
+
+// Classifier picks the Decision for an error returned by an operation
+type Classifier func(err error) Decision
+
+// DefaultClassifier aborts on permanent errors, such as rejected credentials, and waits the
+// delay of errors carrying one, such as rate-limited responses with Retry-After. Other errors
+// are retried with backoff.
+//
+// An error is permanent when it, or an error it wraps, was returned by Permanent or has a
+// Permanent() bool method reporting true. It carries a delay when it was returned by After or
+// has a RetryAfter() time.Duration method.
+func DefaultClassifier(err error) Decision {
+	var permanent interface{ Permanent() bool }
+	if errors.As(err, &permanent) && permanent.Permanent() {
+		return Decision{Abort: true}
+	}
+
+	var delayed interface{ RetryAfter() time.Duration }
+	if errors.As(err, &delayed) {
+		return Decision{RetryAfter: delayed.RetryAfter()}
+	}
+
+	return Decision{}
+}
+
+// Permanent marks err as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string   { return e.err.Error() }
+func (e *permanentError) Unwrap() error   { return e.err }
+func (e *permanentError) Permanent() bool { return true }
+
+// After marks err as retryable no sooner than delay, e.g. the Retry-After of a 429 response
+func After(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &delayedError{err: err, delay: delay}
+}
+
+type delayedError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *delayedError) Error() string             { return e.err.Error() }
+func (e *delayedError) Unwrap() error             { return e.err }
+func (e *delayedError) RetryAfter() time.Duration { return e.delay }
+
+// ParseRetryAfter parses a Retry-After header, given in seconds or as an HTTP date, into a delay.
+// It returns zero for a missing or malformed value, or a date in the past.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
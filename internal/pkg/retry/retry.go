@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"math"
+	"math/rand/v2"
 	"time"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
@@ -13,6 +14,20 @@ type Config struct {
 	MaxAttempts int           // Maximum number of retry attempts
 	BaseDelay   time.Duration // Base delay between retries
 	MaxDelay    time.Duration // Maximum delay between retries
+
+	// Jitter waits a random delay between zero and the backoff delay ("full jitter"), so
+	// clients failing together do not retry in lockstep
+	Jitter bool
+
+	// MaxElapsed is the overall retry budget: no retry is started that would begin after
+	// MaxElapsed has passed since the first attempt. Zero means no budget.
+	MaxElapsed time.Duration
+
+	// OnRetry, when set, is called before waiting for each retry, e.g. to count retries
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// Classify decides per error whether to retry and how long to wait; nil uses DefaultClassifier
+	Classify Classifier
 }
 
 // DefaultConfig returns a default retry configuration suitable for most operations
@@ -36,8 +51,15 @@ func CriticalConfig() Config {
 
 // WithExponentialBackoff executes an operation with exponential backoff retry logic
 // It will retry the operation up to MaxAttempts times with exponentially increasing delays
-// If all attempts fail, it returns the last error encountered
+// If all attempts fail, it returns the last error encountered. Errors classified as permanent
+// are returned at once, and no retry is started beyond the MaxElapsed budget.
 func WithExponentialBackoff(ctx context.Context, cfg Config, log *logger.Logger, operationName string, operation func() error) error {
+	classify := cfg.Classify
+	if classify == nil {
+		classify = DefaultClassifier
+	}
+	start := time.Now()
+
 	var lastErr error
 
 	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
@@ -54,10 +76,28 @@ func WithExponentialBackoff(ctx context.Context, cfg Config, log *logger.Logger,
 				return err
 			}
 
-			// Calculate delay for next attempt using exponential backoff
-			delay := time.Duration(float64(cfg.BaseDelay) * math.Pow(2, float64(attempt-1)))
-			if delay > cfg.MaxDelay {
-				delay = cfg.MaxDelay
+			decision := classify(err)
+			if decision.Abort {
+				log.Error("Operation failed with a permanent error, not retrying",
+					"operation", operationName,
+					"attempt", attempt,
+					"error", err.Error())
+				return err
+			}
+
+			delay := decision.RetryAfter
+			if delay <= 0 {
+				delay = backoffDelay(cfg, attempt)
+			}
+
+			if cfg.MaxElapsed > 0 && time.Since(start)+delay > cfg.MaxElapsed {
+				log.Error("Operation failed and the retry budget is used up",
+					"operation", operationName,
+					"attempt", attempt,
+					"elapsed", time.Since(start).String(),
+					"max_elapsed", cfg.MaxElapsed.String(),
+					"error", err.Error())
+				return err
 			}
 
 			log.Warn("Operation failed, retrying",
@@ -67,6 +107,10 @@ func WithExponentialBackoff(ctx context.Context, cfg Config, log *logger.Logger,
 				"next_retry_in", delay.String(),
 				"error", err.Error())
 
+			if cfg.OnRetry != nil {
+				cfg.OnRetry(attempt, err, delay)
+			}
+
 			// Wait for the calculated delay or until context is cancelled
 			select {
 			case <-time.After(delay):
@@ -95,6 +139,19 @@ func WithExponentialBackoff(ctx context.Context, cfg Config, log *logger.Logger,
 	return lastErr
 }
 
+// backoffDelay returns the exponential backoff delay after the given failed attempt, capped at
+// MaxDelay and, with Jitter, drawn uniformly from zero up to it
+func backoffDelay(cfg Config, attempt int) time.Duration {
+	delay := time.Duration(float64(cfg.BaseDelay) * math.Pow(2, float64(attempt-1)))
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+	if cfg.Jitter && delay > 0 {
+		delay = time.Duration(rand.Int64N(int64(delay) + 1))
+	}
+	return delay
+}
+
 // WithSimpleRetry executes an operation with simple retry logic (no exponential backoff)
 // Uses a fixed delay between retries, suitable for operations that don't benefit from exponential backoff
 func WithSimpleRetry(ctx context.Context, maxAttempts int, delay time.Duration, log *logger.Logger, operationName string, operation func() error) error {
@@ -0,0 +1,136 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestWithExponentialBackoff_RetriesUntilSuccess(t *testing.T) {
+	var retries []int
+	cfg := Config{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+		OnRetry:     func(attempt int, err error, delay time.Duration) { retries = append(retries, attempt) },
+	}
+
+	calls := 0
+	err := WithExponentialBackoff(context.Background(), cfg, logger.New("test"), "flaky", func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if calls != 3 || len(retries) != 2 || retries[1] != 2 {
+		t.Errorf("Expected 3 calls and OnRetry for attempts 1 and 2, got %d calls and %v", calls, retries)
+	}
+}
+
+func TestWithExponentialBackoff_PermanentErrorAborts(t *testing.T) {
+	cfg := Config{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	authErr := errors.New("invalid credentials")
+
+	calls := 0
+	err := WithExponentialBackoff(context.Background(), cfg, logger.New("test"), "auth", func() error {
+		calls++
+		return fmt.Errorf("calling API: %w", Permanent(authErr))
+	})
+	if !errors.Is(err, authErr) {
+		t.Errorf("Expected the permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestWithExponentialBackoff_HonorsRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	cfg := Config{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		OnRetry:     func(attempt int, err error, delay time.Duration) { delays = append(delays, delay) },
+	}
+
+	calls := 0
+	_ = WithExponentialBackoff(context.Background(), cfg, logger.New("test"), "rate_limited", func() error {
+		calls++
+		if calls == 1 {
+			return After(errors.New("429 Too Many Requests"), 20*time.Millisecond)
+		}
+		return nil
+	})
+	if len(delays) != 1 || delays[0] != 20*time.Millisecond {
+		t.Errorf("Expected the Retry-After delay to replace the backoff, got %v", delays)
+	}
+}
+
+func TestWithExponentialBackoff_MaxElapsedBudget(t *testing.T) {
+	cfg := Config{
+		MaxAttempts: 10,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    time.Second,
+		MaxElapsed:  120 * time.Millisecond,
+	}
+
+	calls := 0
+	start := time.Now()
+	err := WithExponentialBackoff(context.Background(), cfg, logger.New("test"), "slow", func() error {
+		calls++
+		return errors.New("still failing")
+	})
+	if err == nil {
+		t.Fatal("Expected the last error once the budget is used up")
+	}
+	// Delays of 50ms and 100ms would end past the budget, so only one retry is made
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts within the budget, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > cfg.MaxElapsed {
+		t.Errorf("Expected to stop within %s, took %s", cfg.MaxElapsed, elapsed)
+	}
+}
+
+func TestBackoffDelay_Jitter(t *testing.T) {
+	cfg := Config{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	if delay := backoffDelay(cfg, 3); delay != 300*time.Millisecond {
+		t.Errorf("Expected the delay capped at MaxDelay, got %s", delay)
+	}
+
+	cfg.Jitter = true
+	for i := 0; i < 100; i++ {
+		if delay := backoffDelay(cfg, 2); delay < 0 || delay > 200*time.Millisecond {
+			t.Fatalf("Expected a jittered delay within [0, 200ms], got %s", delay)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{"soon", 0},
+		{"Fri, 16 Oct 2026 12:01:30 GMT", 90 * time.Second},
+		{"Fri, 16 Oct 2026 11:00:00 GMT", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package secure
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultTokenCacheSize bounds the decrypted tokens a process keeps in memory at once
+const DefaultTokenCacheSize = 256
+
+// TokenCache is a bounded LRU of the decrypted tokens in use. Tokens are zeroed when their
+// holder releases them, e.g. when a job completes, or when they are evicted to make room, so
+// a leaked or forgotten token cannot outlive the most recent DefaultTokenCacheSize tokens.
+// Eviction zeroes tokens whether or not they are released, so size the cache for every token
+// held at once.
+type TokenCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // *Token, most recently used at the front
+	elements map[*Token]*list.Element
+}
+
+// NewTokenCache creates a cache holding at most capacity tokens
+func NewTokenCache(capacity int) *TokenCache {
+	if capacity <= 0 {
+		capacity = DefaultTokenCacheSize
+	}
+	return &TokenCache{
+		capacity: capacity,
+		order:    list.New(),
+		elements: make(map[*Token]*list.Element),
+	}
+}
+
+// Hold adds tokens to the cache, evicting and zeroing the least recently used tokens beyond its
+// capacity. It returns a function that zeroes the held tokens, to be deferred by their user.
+// Nil tokens are ignored. A nil cache holds nothing but its release still zeroes the tokens.
+func (c *TokenCache) Hold(tokens ...*Token) (release func()) {
+	if c == nil {
+		return func() {
+			for _, t := range tokens {
+				t.Zero()
+			}
+		}
+	}
+
+	var evicted []*Token
+
+	c.mu.Lock()
+	for _, t := range tokens {
+		if t == nil {
+			continue
+		}
+		if element, ok := c.elements[t]; ok {
+			c.order.MoveToFront(element)
+			continue
+		}
+		c.elements[t] = c.order.PushFront(t)
+		t.cache.Store(c)
+	}
+	for c.order.Len() > c.capacity {
+		evicted = append(evicted, c.remove(c.order.Back()))
+	}
+	c.mu.Unlock()
+
+	// Zeroing takes each token's lock, so it happens outside the cache's lock
+	for _, t := range evicted {
+		t.Zero()
+	}
+	return func() { c.Release(tokens...) }
+}
+
+// Release removes tokens from the cache and zeroes them
+func (c *TokenCache) Release(tokens ...*Token) {
+	c.mu.Lock()
+	for _, t := range tokens {
+		if element, ok := c.elements[t]; ok {
+			c.remove(element)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, t := range tokens {
+		t.Zero()
+	}
+}
+
+// Len returns the number of tokens held
+func (c *TokenCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// touch marks t as recently used
+func (c *TokenCache) touch(t *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.elements[t]; ok {
+		c.order.MoveToFront(element)
+	}
+}
+
+func (c *TokenCache) remove(element *list.Element) *Token {
+	t := c.order.Remove(element).(*Token)
+	delete(c.elements, t)
+	t.cache.CompareAndSwap(c, nil)
+	return t
+}
@@ -0,0 +1,126 @@
+// Package secure holds decrypted credentials in buffers that are zeroed once they are no
+// longer needed, instead of immutable strings that linger in memory until garbage collected.
+package secure
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces a token wherever it is printed or serialized
+const Redacted = "[REDACTED]"
+
+// Token is a decrypted credential such as an OAuth refresh token. Its value can only be read
+// through Reveal or Use and is overwritten with zeros by Zero. Printing or marshaling a token
+// yields Redacted, and the embedded mutex makes go vet flag copies of a Token value, so the
+// buffer is not duplicated by accident. A nil *Token is an empty token.
+type Token struct {
+	mu     sync.Mutex
+	value  []byte
+	zeroed bool
+
+	// The cache holding the token, if any
+	cache atomic.Pointer[TokenCache]
+}
+
+// NewToken takes ownership of value, which the caller must not use afterwards
+func NewToken(value []byte) *Token {
+	return &Token{value: value}
+}
+
+// NewTokenString copies value into a new token. The string itself cannot be zeroed, so prefer
+// NewToken with the decrypted buffer where one is available.
+func NewTokenString(value string) *Token {
+	return &Token{value: []byte(value)}
+}
+
+// Empty reports whether the token has no value, including after it was zeroed
+func (t *Token) Empty() bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.value) == 0
+}
+
+// Zeroed reports whether the token was wiped by Zero
+func (t *Token) Zeroed() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.zeroed
+}
+
+// Reveal returns the value as a string for APIs that require one, such as OAuth clients.
+// The returned copy cannot be zeroed, so reveal as late and as rarely as possible.
+func (t *Token) Reveal() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	value := string(t.value)
+	t.mu.Unlock()
+
+	t.touch()
+	return value
+}
+
+// Use calls fn with the token's buffer, e.g. to hash it without creating a string copy.
+// fn must not retain the slice.
+func (t *Token) Use(fn func(value []byte)) {
+	if t == nil {
+		fn(nil)
+		return
+	}
+	t.mu.Lock()
+	fn(t.value)
+	t.mu.Unlock()
+
+	t.touch()
+}
+
+// Zero overwrites the value with zeros and empties the token
+func (t *Token) Zero() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.value {
+		t.value[i] = 0
+	}
+	t.value = nil
+	t.zeroed = true
+}
+
+// String implements fmt.Stringer without exposing the value
+func (t *Token) String() string {
+	return Redacted
+}
+
+// GoString keeps %#v from printing the buffer
+func (t *Token) GoString() string {
+	return Redacted
+}
+
+// Format keeps every fmt verb, including %x and %q, from printing the buffer
+func (t *Token) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(Redacted))
+}
+
+// MarshalText keeps encoders from serializing the value
+func (t *Token) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// touch marks the token as recently used in the cache holding it
+func (t *Token) touch() {
+	if cache := t.cache.Load(); cache != nil {
+		cache.touch(t)
+	}
+}
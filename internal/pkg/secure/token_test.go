@@ -0,0 +1,99 @@
+package secure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToken_ZeroWipesBuffer(t *testing.T) {
+	buf := []byte("refresh-token")
+	token := NewToken(buf)
+
+	if token.Reveal() != "refresh-token" {
+		t.Fatalf("Unexpected value %q", token.Reveal())
+	}
+	token.Zero()
+
+	for i, b := range buf {
+		if b != 0 {
+			t.Fatalf("Expected byte %d of the buffer to be zeroed, got %q", i, b)
+		}
+	}
+	if !token.Empty() || !token.Zeroed() || token.Reveal() != "" {
+		t.Error("Expected a zeroed token to be empty")
+	}
+}
+
+func TestToken_NilIsEmpty(t *testing.T) {
+	var token *Token
+	if !token.Empty() || token.Reveal() != "" || token.Zeroed() {
+		t.Error("Expected a nil token to be empty")
+	}
+	token.Zero() // Must not panic
+}
+
+func TestToken_NeverPrinted(t *testing.T) {
+	token := NewTokenString("super-secret")
+	config := struct {
+		Name  string
+		Token *Token
+	}{"user", token}
+
+	for _, out := range []string{
+		fmt.Sprint(token),
+		fmt.Sprintf("%v %+v %#v %s %q %x", token, token, token, token, token, token),
+		fmt.Sprintf("%+v %#v", config, config),
+	} {
+		if strings.Contains(out, "super-secret") || strings.Contains(out, "7375706572") {
+			t.Errorf("Token leaked into %q", out)
+		}
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "super-secret") || !strings.Contains(string(data), Redacted) {
+		t.Errorf("Expected a redacted token in %s", data)
+	}
+}
+
+func TestTokenCache_ReleaseZeroes(t *testing.T) {
+	cache := NewTokenCache(10)
+	access, refresh := NewTokenString("access"), NewTokenString("refresh")
+
+	release := cache.Hold(access, refresh, nil)
+	if cache.Len() != 2 {
+		t.Fatalf("Expected 2 held tokens, got %d", cache.Len())
+	}
+
+	release()
+	if cache.Len() != 0 {
+		t.Errorf("Expected the cache to be empty after release, got %d", cache.Len())
+	}
+	if !access.Zeroed() || !refresh.Zeroed() {
+		t.Error("Expected released tokens to be zeroed")
+	}
+}
+
+func TestTokenCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewTokenCache(2)
+	first, second, third := NewTokenString("first"), NewTokenString("second"), NewTokenString("third")
+
+	cache.Hold(first)
+	cache.Hold(second)
+	first.Reveal() // first is now more recently used than second
+	cache.Hold(third)
+
+	if cache.Len() != 2 {
+		t.Fatalf("Expected the cache to stay at its capacity of 2, got %d", cache.Len())
+	}
+	if !second.Zeroed() {
+		t.Error("Expected the least recently used token to be evicted and zeroed")
+	}
+	if first.Zeroed() || third.Zeroed() {
+		t.Error("Expected recently used tokens to be kept")
+	}
+}
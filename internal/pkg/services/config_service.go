@@ -2,14 +2,20 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/destination"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/notification"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
 )
 
 // Pre-compiled regex patterns for better performance
@@ -60,13 +66,61 @@ const (
 	ConfigErrorNetwork        = "NETWORK_ERROR"
 )
 
-// SetSpreadsheetURL validates and sets a user's Google Spreadsheet configuration
-func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, spreadsheetURL string) error {
+// SetSpreadsheetURL validates and sets a user's Google Spreadsheet configuration.
+// templateID names the catalog layout the spreadsheet follows; empty selects the default template.
+func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, spreadsheetURL, templateID string) error {
 	startTime := time.Now()
 	c.logger.Info("Starting spreadsheet URL configuration",
 		"user_id", userID,
-		"url_length", len(spreadsheetURL))
+		"url_length", len(spreadsheetURL),
+		"template_id", templateID)
 
+	if templateID == "" {
+		templateID = templates.DefaultTemplateID
+	}
+	if _, ok := templates.Get(templateID); !ok {
+		return &ConfigError{
+			Type:    ConfigErrorValidation,
+			Message: "Unknown spreadsheet template: " + templateID,
+		}
+	}
+
+	// Steps 1-2: Extract the spreadsheet ID and validate the user's access to it
+	spreadsheetID, err := c.ResolveSpreadsheet(ctx, userID, spreadsheetURL)
+	if err != nil {
+		return err
+	}
+
+	// Step 3: Save spreadsheet ID to database
+	c.logger.Debug("Saving spreadsheet ID to database",
+		"user_id", userID,
+		"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
+
+	err = c.userRepository.UpdateSpreadsheetTemplate(ctx, userID, spreadsheetID, templateID)
+	if err != nil {
+		c.logger.Error("Failed to save spreadsheet ID to database",
+			"error", err,
+			"user_id", userID,
+			"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save spreadsheet configuration. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	duration := time.Since(startTime)
+	c.logger.Info("Spreadsheet configuration completed successfully",
+		"user_id", userID,
+		"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID),
+		"configuration_duration_ms", duration.Milliseconds())
+
+	return nil
+}
+
+// ResolveSpreadsheet extracts the spreadsheet ID from a Google Sheets URL and checks that the
+// user's Google token can open and write it. Failures are returned as *ConfigError.
+func (c *ConfigService) ResolveSpreadsheet(ctx context.Context, userID int, spreadsheetURL string) (string, error) {
 	// Step 1: Validate and extract spreadsheet ID from URL
 	spreadsheetID, err := c.extractSpreadsheetID(spreadsheetURL)
 	if err != nil {
@@ -74,7 +128,7 @@ func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, sprea
 			"user_id", userID,
 			"url", c.sanitizeURL(spreadsheetURL),
 			"error", err)
-		return &ConfigError{
+		return "", &ConfigError{
 			Type:    ConfigErrorInvalidURL,
 			Message: "Invalid Google Spreadsheet URL format. Please ensure you're using a valid Google Sheets URL.",
 			Cause:   err,
@@ -97,31 +151,31 @@ func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, sprea
 		if errors.As(err, &sheetsErr) {
 			switch sheetsErr.Type {
 			case ErrorTypePermissionDenied:
-				return &ConfigError{
+				return "", &ConfigError{
 					Type:    ConfigErrorPermission,
 					Message: sheetsErr.Message,
 					Cause:   sheetsErr.Cause,
 				}
 			case ErrorTypeNotFound:
-				return &ConfigError{
+				return "", &ConfigError{
 					Type:    ConfigErrorNotFound,
 					Message: sheetsErr.Message,
 					Cause:   sheetsErr.Cause,
 				}
 			case ErrorTypeInvalidFormat:
-				return &ConfigError{
+				return "", &ConfigError{
 					Type:    ConfigErrorInvalidURL,
 					Message: sheetsErr.Message,
 					Cause:   sheetsErr.Cause,
 				}
 			case ErrorTypeNetworkError:
-				return &ConfigError{
+				return "", &ConfigError{
 					Type:    ConfigErrorNetwork,
 					Message: sheetsErr.Message,
 					Cause:   sheetsErr.Cause,
 				}
 			default:
-				return &ConfigError{
+				return "", &ConfigError{
 					Type:    ConfigErrorValidation,
 					Message: "Failed to validate spreadsheet access: " + sheetsErr.Message,
 					Cause:   sheetsErr.Cause,
@@ -133,7 +187,7 @@ func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, sprea
 			"error", err,
 			"user_id", userID,
 			"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
-		return &ConfigError{
+		return "", &ConfigError{
 			Type:    ConfigErrorValidation,
 			Message: "Failed to validate spreadsheet access. Please try again.",
 			Cause:   err,
@@ -144,56 +198,388 @@ func (c *ConfigService) SetSpreadsheetURL(ctx context.Context, userID int, sprea
 		"user_id", userID,
 		"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
 
-	// Step 3: Save spreadsheet ID to database
-	c.logger.Debug("Saving spreadsheet ID to database",
-		"user_id", userID,
-		"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
+	return spreadsheetID, nil
+}
+
+// ClearSpreadsheetURL removes a user's spreadsheet configuration
+func (c *ConfigService) ClearSpreadsheetURL(ctx context.Context, userID int) error {
+	c.logger.Info("Clearing spreadsheet configuration",
+		"user_id", userID)
 
-	err = c.userRepository.UpdateSpreadsheetID(ctx, userID, spreadsheetID)
+	err := c.userRepository.ClearSpreadsheetID(ctx, userID)
 	if err != nil {
-		c.logger.Error("Failed to save spreadsheet ID to database",
+		c.logger.Error("Failed to clear spreadsheet configuration",
 			"error", err,
-			"user_id", userID,
-			"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID))
+			"user_id", userID)
 		return &ConfigError{
 			Type:    ConfigErrorDatabase,
-			Message: "Failed to save spreadsheet configuration. Please try again.",
+			Message: "Failed to clear spreadsheet configuration. Please try again.",
 			Cause:   err,
 		}
 	}
 
-	duration := time.Since(startTime)
-	c.logger.Info("Spreadsheet configuration completed successfully",
-		"user_id", userID,
-		"spreadsheet_id", c.sanitizeSpreadsheetID(spreadsheetID),
-		"configuration_duration_ms", duration.Milliseconds())
+	c.logger.Info("Spreadsheet configuration cleared successfully",
+		"user_id", userID)
 
 	return nil
 }
 
-// ClearSpreadsheetURL removes a user's spreadsheet configuration
-func (c *ConfigService) ClearSpreadsheetURL(ctx context.Context, userID int) error {
-	c.logger.Info("Clearing spreadsheet configuration",
+// minWebhookSecretLength is the shortest user-supplied webhook signing secret accepted
+const minWebhookSecretLength = 16
+
+// SetWebhook validates and stores a user's outbound webhook.
+// When secret is empty a random one is generated; the secret in use is returned so it can be
+// shown to the user once, as it is stored encrypted and never returned by other endpoints.
+func (c *ConfigService) SetWebhook(ctx context.Context, userID int, webhookURL, secret string) (string, error) {
+	webhookURL = strings.TrimSpace(webhookURL)
+	c.logger.Info("Starting webhook configuration",
+		"user_id", userID,
+		"url", c.sanitizeURL(webhookURL),
+		"secret_provided", secret != "")
+
+	if err := destination.ValidateWebhookURL(ctx, webhookURL); err != nil {
+		return "", &ConfigError{
+			Type:    ConfigErrorInvalidURL,
+			Message: "Invalid webhook URL. Please use an absolute https:// URL on a public host.",
+			Cause:   err,
+		}
+	}
+
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return "", &ConfigError{
+				Type:    ConfigErrorValidation,
+				Message: "Failed to generate webhook secret. Please try again.",
+				Cause:   err,
+			}
+		}
+		secret = generated
+	} else if len(secret) < minWebhookSecretLength {
+		return "", &ConfigError{
+			Type:    ConfigErrorValidation,
+			Message: fmt.Sprintf("Webhook secret must be at least %d characters", minWebhookSecretLength),
+		}
+	}
+
+	if err := c.userRepository.UpdateWebhook(ctx, userID, webhookURL, secret); err != nil {
+		c.logger.Error("Failed to save webhook configuration",
+			"error", err,
+			"user_id", userID)
+		return "", &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save webhook configuration. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Webhook configuration completed successfully",
+		"user_id", userID,
+		"url", c.sanitizeURL(webhookURL))
+
+	return secret, nil
+}
+
+// ClearWebhook removes a user's outbound webhook
+func (c *ConfigService) ClearWebhook(ctx context.Context, userID int) error {
+	c.logger.Info("Clearing webhook configuration",
 		"user_id", userID)
 
-	err := c.userRepository.ClearSpreadsheetID(ctx, userID)
+	if err := c.userRepository.ClearWebhook(ctx, userID); err != nil {
+		c.logger.Error("Failed to clear webhook configuration",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to clear webhook configuration. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	return nil
+}
+
+// SetNotificationChannel validates and stores where a user's notifications are delivered.
+// Chat channels need the channel's incoming webhook URL; choosing email discards any stored URL.
+func (c *ConfigService) SetNotificationChannel(ctx context.Context, userID int, channel, webhookURL string) error {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	webhookURL = strings.TrimSpace(webhookURL)
+	c.logger.Info("Starting notification channel configuration",
+		"user_id", userID,
+		"channel", channel)
+
+	if !notification.ValidChannel(channel) {
+		return &ConfigError{
+			Type:    ConfigErrorValidation,
+			Message: "Notification channel must be one of email, slack or discord",
+		}
+	}
+
+	if notification.IsChatChannel(channel) {
+		// The webhook URL is a credential for the channel, so it is never logged
+		if err := notification.ValidateChatWebhookURL(channel, webhookURL); err != nil {
+			return &ConfigError{
+				Type:    ConfigErrorInvalidURL,
+				Message: fmt.Sprintf("Invalid %s webhook URL. Please paste the incoming webhook URL from %s.", channel, channel),
+				Cause:   err,
+			}
+		}
+	} else {
+		webhookURL = ""
+	}
+
+	if err := c.userRepository.UpdateNotificationChannel(ctx, userID, channel, webhookURL); err != nil {
+		c.logger.Error("Failed to save notification channel",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save notification channel. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Notification channel configuration completed successfully",
+		"user_id", userID,
+		"channel", channel)
+
+	return nil
+}
+
+// GetNotificationSettings returns the user's notification channel and per-event preferences
+func (c *ConfigService) GetNotificationSettings(ctx context.Context, userID int) (*database.NotificationChannel, error) {
+	settings, err := c.userRepository.GetNotificationChannel(ctx, userID)
 	if err != nil {
-		c.logger.Error("Failed to clear spreadsheet configuration",
+		c.logger.Error("Failed to read notification settings",
+			"error", err,
+			"user_id", userID)
+		return nil, &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to read notification settings. Please try again.",
+			Cause:   err,
+		}
+	}
+	return settings, nil
+}
+
+// SetNotificationPreferences turns the named notification preferences on or off; preferences
+// not in changes keep their current value. It returns the preferences now in effect.
+func (c *ConfigService) SetNotificationPreferences(ctx context.Context, userID int, changes map[string]bool) (*database.NotificationPreferences, error) {
+	for name := range changes {
+		if !slices.Contains(database.NotificationPreferenceNames(), name) {
+			return nil, &ConfigError{
+				Type:    ConfigErrorValidation,
+				Message: fmt.Sprintf("Unknown notification preference %q. Use one of %s.", name, strings.Join(database.NotificationPreferenceNames(), ", ")),
+			}
+		}
+	}
+
+	settings, err := c.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := database.DefaultNotificationPreferences()
+	if settings.Preferences != nil {
+		preferences = *settings.Preferences
+	}
+	for name, enabled := range changes {
+		preferences.Set(name, enabled)
+	}
+
+	if err := c.userRepository.UpdateNotificationPreferences(ctx, userID, preferences); err != nil {
+		c.logger.Error("Failed to save notification preferences",
+			"error", err,
+			"user_id", userID)
+		return nil, &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save notification preferences. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Notification preferences configuration completed successfully",
+		"user_id", userID,
+		"preferences", preferences)
+
+	return &preferences, nil
+}
+
+// SetLocale stores the locale the user's notifications are rendered in
+func (c *ConfigService) SetLocale(ctx context.Context, userID int, locale string) error {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	supported := false
+	for _, candidate := range notification.SupportedLocales() {
+		if candidate == locale {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return &ConfigError{
+			Type:    ConfigErrorValidation,
+			Message: fmt.Sprintf("Locale must be one of %s", strings.Join(notification.SupportedLocales(), ", ")),
+		}
+	}
+
+	if err := c.userRepository.UpdateLocale(ctx, userID, locale); err != nil {
+		c.logger.Error("Failed to save locale",
 			"error", err,
 			"user_id", userID)
 		return &ConfigError{
 			Type:    ConfigErrorDatabase,
-			Message: "Failed to clear spreadsheet configuration. Please try again.",
+			Message: "Failed to save locale. Please try again.",
 			Cause:   err,
 		}
 	}
 
-	c.logger.Info("Spreadsheet configuration cleared successfully",
-		"user_id", userID)
+	c.logger.Info("Locale configuration completed successfully",
+		"user_id", userID,
+		"locale", locale)
 
 	return nil
 }
 
+// SetDigest switches the user between a notification per run and one daily digest sent at
+// digestTime (HH:MM, in the user's timezone). The digest time is kept when digests are turned off.
+func (c *ConfigService) SetDigest(ctx context.Context, userID int, enabled bool, digestTime string) error {
+	digestTime = strings.TrimSpace(digestTime)
+	if _, _, err := notification.ParseDigestTime(digestTime); err != nil {
+		return &ConfigError{
+			Type:    ConfigErrorValidation,
+			Message: "Digest time must be a 24-hour time in HH:MM format",
+			Cause:   err,
+		}
+	}
+
+	mode := database.NotificationModeImmediate
+	if enabled {
+		mode = database.NotificationModeDigest
+	}
+
+	if err := c.userRepository.UpdateDigestSettings(ctx, userID, mode, digestTime); err != nil {
+		c.logger.Error("Failed to save digest settings",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save digest settings. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Digest configuration completed successfully",
+		"user_id", userID,
+		"mode", mode,
+		"digest_time", digestTime)
+
+	return nil
+}
+
+// SetQuietHours sets the local HH:MM window, in the user's timezone, during which non-critical
+// notifications are held. Empty start and end turn quiet hours off.
+func (c *ConfigService) SetQuietHours(ctx context.Context, userID int, start, end string) error {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if start != "" || end != "" {
+		if err := notification.ValidateQuietHours(start, end); err != nil {
+			return &ConfigError{
+				Type:    ConfigErrorValidation,
+				Message: "Quiet hours need different start and end times in 24-hour HH:MM format",
+				Cause:   err,
+			}
+		}
+	}
+
+	if err := c.userRepository.UpdateQuietHours(ctx, userID, start, end); err != nil {
+		c.logger.Error("Failed to save quiet hours",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save quiet hours. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Quiet hours configuration completed successfully",
+		"user_id", userID,
+		"start", start,
+		"end", end)
+
+	return nil
+}
+
+// SetWeeklySummary sets whether runs maintain the weekly summary tab of the user's spreadsheet
+func (c *ConfigService) SetWeeklySummary(ctx context.Context, userID int, enabled bool) error {
+	if err := c.userRepository.SetWeeklySummaryEnabled(ctx, userID, enabled); err != nil {
+		c.logger.Error("Failed to save weekly summary setting",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save weekly summary setting. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Weekly summary configuration completed successfully",
+		"user_id", userID,
+		"enabled", enabled)
+
+	return nil
+}
+
+// SetGearColumn sets whether the user's activity sheet includes a Gear column. The column is
+// appended after the template's columns, so existing rows keep their layout.
+func (c *ConfigService) SetGearColumn(ctx context.Context, userID int, enabled bool) error {
+	if err := c.userRepository.UpdateGearColumn(ctx, userID, enabled); err != nil {
+		c.logger.Error("Failed to save gear column setting",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save gear column setting. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Gear column configuration completed successfully",
+		"user_id", userID,
+		"enabled", enabled)
+
+	return nil
+}
+
+// SetChronologicalOrder sets whether runs re-sort the user's activity rows by date when new
+// activities are appended before older ones that uploaded late
+func (c *ConfigService) SetChronologicalOrder(ctx context.Context, userID int, enabled bool) error {
+	if err := c.userRepository.UpdateChronologicalOrder(ctx, userID, enabled); err != nil {
+		c.logger.Error("Failed to save row order setting",
+			"error", err,
+			"user_id", userID)
+		return &ConfigError{
+			Type:    ConfigErrorDatabase,
+			Message: "Failed to save row order setting. Please try again.",
+			Cause:   err,
+		}
+	}
+
+	c.logger.Info("Row order configuration completed successfully",
+		"user_id", userID,
+		"chronological", enabled)
+
+	return nil
+}
+
+// generateWebhookSecret returns 32 random bytes, hex encoded
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 // extractSpreadsheetID extracts the spreadsheet ID from a Google Sheets URL
 func (c *ConfigService) extractSpreadsheetID(url string) (string, error) {
 	c.logger.Debug("Extracting spreadsheet ID from URL",
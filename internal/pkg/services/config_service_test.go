@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
@@ -192,4 +193,112 @@ func TestConfigError(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestConfigService_SetWebhookValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		secret       string
+		expectedType string
+	}{
+		{"Plain http URL", "http://example.com/hook", "", ConfigErrorInvalidURL},
+		{"Relative URL", "/hook", "", ConfigErrorInvalidURL},
+		{"Loopback host", "https://127.0.0.1/hook", "", ConfigErrorInvalidURL},
+		{"Short secret", "https://93.184.215.14/hook", "short", ConfigErrorValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SetWebhook(context.Background(), 1, tt.url, tt.secret)
+			configErr, ok := err.(*ConfigError)
+			if !ok || configErr.Type != tt.expectedType {
+				t.Errorf("Expected %s error but got %v", tt.expectedType, err)
+			}
+		})
+	}
+}
+
+func TestConfigService_SetNotificationChannelValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	tests := []struct {
+		name         string
+		channel      string
+		url          string
+		expectedType string
+	}{
+		{"Unknown channel", "sms", "", ConfigErrorValidation},
+		{"Slack without URL", "slack", "", ConfigErrorInvalidURL},
+		{"Slack URL on another host", "slack", "https://example.com/services/T000", ConfigErrorInvalidURL},
+		{"Discord URL outside webhooks", "discord", "https://discord.com/channels/1", ConfigErrorInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.SetNotificationChannel(context.Background(), 1, tt.channel, tt.url)
+			configErr, ok := err.(*ConfigError)
+			if !ok || configErr.Type != tt.expectedType {
+				t.Errorf("Expected %s error but got %v", tt.expectedType, err)
+			}
+		})
+	}
+}
+
+func TestConfigService_SetNotificationPreferencesValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	_, err := service.SetNotificationPreferences(context.Background(), 1, map[string]bool{"failures": false, "weekly_digest": false})
+	configErr, ok := err.(*ConfigError)
+	if !ok || configErr.Type != ConfigErrorValidation || !strings.Contains(configErr.Message, "weekly_digest") {
+		t.Errorf("Expected %s error naming the unknown preference but got %v", ConfigErrorValidation, err)
+	}
+}
+
+func TestConfigService_SetQuietHoursValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	for _, window := range [][2]string{{"22:00", ""}, {"", "07:00"}, {"22:00", "22:00"}, {"10pm", "7am"}} {
+		err := service.SetQuietHours(context.Background(), 1, window[0], window[1])
+		configErr, ok := err.(*ConfigError)
+		if !ok || configErr.Type != ConfigErrorValidation {
+			t.Errorf("Expected %s error for %v but got %v", ConfigErrorValidation, window, err)
+		}
+	}
+}
+
+func TestConfigService_SetLocaleValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	err := service.SetLocale(context.Background(), 1, "fr")
+	configErr, ok := err.(*ConfigError)
+	if !ok || configErr.Type != ConfigErrorValidation {
+		t.Errorf("Expected %s error but got %v", ConfigErrorValidation, err)
+	}
+}
+
+func TestConfigService_SetDigestValidation(t *testing.T) {
+	service := &ConfigService{
+		logger: logger.New("config_service_test"),
+	}
+
+	for _, digestTime := range []string{"", "6pm", "25:00"} {
+		err := service.SetDigest(context.Background(), 1, true, digestTime)
+		configErr, ok := err.(*ConfigError)
+		if !ok || configErr.Type != ConfigErrorValidation {
+			t.Errorf("Expected %s error for %q but got %v", ConfigErrorValidation, digestTime, err)
+		}
+	}
+}
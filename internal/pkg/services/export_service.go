@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Supported export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// Export error types
+const (
+	ExportErrorNotConnected   = "STRAVA_NOT_CONNECTED"
+	ExportErrorReauthRequired = "STRAVA_REAUTH_REQUIRED"
+	ExportErrorStrava         = "STRAVA_ERROR"
+	ExportErrorTooLarge       = "EXPORT_TOO_LARGE"
+	ExportErrorDatabase       = "DATABASE_ERROR"
+)
+
+// ExportError represents errors while exporting a user's activities
+type ExportError struct {
+	Type    string
+	Message string
+	Cause   error
+}
+
+func (e *ExportError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s (caused by: %v)", e.Type, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// ExportCSVHeader is the header row of CSV exports
+var ExportCSVHeader = []string{
+	"activity_id", "date", "name", "type", "sport_type", "distance_km",
+	"moving_time_seconds", "elapsed_time_seconds", "elevation_gain_m",
+	"average_heartrate", "max_heartrate", "kudos", "start_date_local",
+}
+
+// ExportService fetches a user's Strava activities for file export
+type ExportService struct {
+	userRepository     *database.UserRepository
+	activityRepository *database.ActivityRepository
+	stravaClientID     string
+	secretMu           sync.RWMutex
+	stravaClientSecret string
+	stravaEndpoints    strava.Endpoints
+	logger             *logger.Logger
+}
+
+// NewExportService creates a new export service.
+// Exports are served from the activity cache while it is fresh and written back to it otherwise.
+func NewExportService(userRepository *database.UserRepository, activityRepository *database.ActivityRepository, stravaClientID, stravaClientSecret string, logger *logger.Logger) *ExportService {
+	return &ExportService{
+		userRepository:     userRepository,
+		activityRepository: activityRepository,
+		stravaClientID:     stravaClientID,
+		stravaClientSecret: stravaClientSecret,
+		stravaEndpoints:    strava.DefaultEndpoints(),
+		logger:             logger.WithContext("component", "export_service"),
+	}
+}
+
+// SetStravaClientSecret replaces the Strava client secret after a rotation
+func (s *ExportService) SetStravaClientSecret(secret string) {
+	s.secretMu.Lock()
+	defer s.secretMu.Unlock()
+
+	s.stravaClientSecret = secret
+}
+
+// SetStravaEndpoints points activity exports at alternate Strava URLs
+func (s *ExportService) SetStravaEndpoints(endpoints strava.Endpoints) {
+	s.stravaEndpoints = endpoints
+}
+
+// GetActivities fetches the user's activities started between from and to
+func (s *ExportService) GetActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, error) {
+	if activities, ok := s.getCachedActivities(ctx, userID, from, to); ok {
+		return activities, nil
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, &ExportError{Type: ExportErrorDatabase, Message: "Failed to load user", Cause: err}
+	}
+	if user == nil || len(user.StravaRefreshToken) == 0 {
+		return nil, &ExportError{Type: ExportErrorNotConnected, Message: "Connect Strava to export activities"}
+	}
+
+	refreshToken, err := s.userRepository.DecryptToken(user.StravaRefreshToken)
+	if err != nil {
+		return nil, &ExportError{Type: ExportErrorDatabase, Message: "Failed to decrypt Strava token", Cause: err}
+	}
+
+	s.secretMu.RLock()
+	clientSecret := s.stravaClientSecret
+	s.secretMu.RUnlock()
+
+	// Refreshed tokens are saved so the next export or sync does not refresh again
+	opts := []strava.Option{
+		strava.WithEndpoints(s.stravaEndpoints),
+		strava.WithOAuthCredentials(s.stravaClientID, clientSecret),
+		strava.WithTokenSaver(s.userRepository),
+	}
+	if len(user.StravaAccessToken) > 0 && user.StravaTokenExpiry != nil && time.Now().Before(*user.StravaTokenExpiry) {
+		if accessToken, err := s.userRepository.DecryptToken(user.StravaAccessToken); err == nil {
+			opts = append(opts, strava.WithInitialToken(accessToken, *user.StravaTokenExpiry))
+		}
+	}
+	client := strava.NewClient(userID, refreshToken, s.logger, opts...)
+
+	fetchedAt := time.Now()
+	activities, err := client.GetActivitiesInRange(ctx, from, to)
+	if err != nil {
+		if strava.IsReauthRequired(err) {
+			return nil, &ExportError{Type: ExportErrorReauthRequired, Message: "Strava connection requires re-authorization", Cause: err}
+		}
+		if errors.Is(err, strava.ErrTooManyActivities) {
+			return nil, &ExportError{Type: ExportErrorTooLarge, Message: "Too many activities in this date range. Please export a shorter range.", Cause: err}
+		}
+		return nil, &ExportError{Type: ExportErrorStrava, Message: "Failed to fetch activities from Strava", Cause: err}
+	}
+
+	s.logger.Info("Fetched activities for export",
+		"user_id", userID,
+		"from", from.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339),
+		"activity_count", len(activities))
+
+	if s.activityRepository != nil {
+		if err := s.activityRepository.StoreFetchedActivities(ctx, userID, from, to, fetchedAt, activities); err != nil {
+			s.logger.Warn("Failed to cache exported activities",
+				"error", err,
+				"user_id", userID)
+		}
+	}
+
+	return activities, nil
+}
+
+// getCachedActivities returns the cached activities for the range when the cache is fresh
+func (s *ExportService) getCachedActivities(ctx context.Context, userID int, from, to time.Time) ([]strava.Activity, bool) {
+	if s.activityRepository == nil {
+		return nil, false
+	}
+
+	fresh, err := s.activityRepository.IsCacheFresh(ctx, userID, from, database.DefaultActivityCacheMaxAge)
+	if err != nil || !fresh {
+		return nil, false
+	}
+
+	activities, err := s.activityRepository.GetActivitiesInRange(ctx, userID, from, to)
+	if err != nil {
+		s.logger.Warn("Failed to read activity cache, fetching from Strava",
+			"error", err,
+			"user_id", userID)
+		return nil, false
+	}
+
+	s.logger.Info("Serving export from activity cache",
+		"user_id", userID,
+		"activity_count", len(activities))
+	return activities, true
+}
+
+// WriteActivitiesCSV writes activities as CSV, one row per activity after the header
+func WriteActivitiesCSV(w io.Writer, activities []strava.Activity) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ExportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, activity := range activities {
+		record := []string{
+			strconv.FormatInt(activity.ID, 10),
+			activity.StartDateLocal.Format("2006-01-02"),
+			activity.Name,
+			activity.Type,
+			activity.SportType,
+			strconv.FormatFloat(activity.Distance/1000, 'f', 2, 64),
+			strconv.Itoa(activity.MovingTime),
+			strconv.Itoa(activity.ElapsedTime),
+			strconv.FormatFloat(activity.TotalElevationGain, 'f', 1, 64),
+			optionalFloat(activity.AverageHeartrate),
+			optionalFloat(activity.MaxHeartrate),
+			strconv.Itoa(activity.Kudos),
+			activity.StartDateLocal.Format("2006-01-02T15:04:05"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteActivitiesJSON writes activities as a JSON array, encoding one activity at a time
+func WriteActivitiesJSON(w io.Writer, activities []strava.Activity) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for i, activity := range activities {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		payload, err := json.Marshal(activity)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(payload); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// optionalFloat formats a metric that Strava reports as zero when unavailable
+func optionalFloat(value float64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
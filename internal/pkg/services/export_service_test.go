@@ -0,0 +1,71 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func exportTestActivities() []strava.Activity {
+	return []strava.Activity{
+		{ID: 9876543210, Name: "Morning Run, easy", Type: "Run", SportType: "Run", Distance: 5230,
+			MovingTime: 1620, ElapsedTime: 1700, TotalElevationGain: 42.5, AverageHeartrate: 148.2,
+			StartDateLocal: time.Date(2024, 6, 3, 7, 15, 0, 0, time.UTC)},
+		{ID: 9876543211, Name: "Commute", Type: "Ride", SportType: "Ride", Distance: 12000,
+			MovingTime: 2400, ElapsedTime: 2600, StartDateLocal: time.Date(2024, 6, 4, 8, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestWriteActivitiesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteActivitiesCSV(&buf, exportTestActivities()); err != nil {
+		t.Fatalf("WriteActivitiesCSV failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+
+	first := records[1]
+	if first[0] != "9876543210" || first[1] != "2024-06-03" || first[2] != "Morning Run, easy" {
+		t.Errorf("Unexpected first row: %v", first)
+	}
+	if first[5] != "5.23" || first[9] != "148.2" {
+		t.Errorf("Unexpected metrics in first row: %v", first)
+	}
+	// Missing heart rate is exported as an empty cell, not zero
+	if records[2][9] != "" {
+		t.Errorf("Expected empty heart rate, got %q", records[2][9])
+	}
+}
+
+func TestWriteActivitiesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteActivitiesJSON(&buf, exportTestActivities()); err != nil {
+		t.Fatalf("WriteActivitiesJSON failed: %v", err)
+	}
+
+	var decoded []strava.Activity
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].ID != 9876543211 {
+		t.Errorf("Unexpected decoded activities: %+v", decoded)
+	}
+
+	buf.Reset()
+	if err := WriteActivitiesJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteActivitiesJSON failed: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("Expected empty array, got %q", buf.String())
+	}
+}
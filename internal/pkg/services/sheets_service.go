@@ -11,12 +11,15 @@ import (
 	"google.golang.org/api/sheets/v4"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/outbound"
 )
 
 // SheetsService handles Google Sheets API operations
 type SheetsService struct {
 	userRepository *database.UserRepository
+	endpoints      google.Endpoints
 	logger         *logger.Logger
 }
 
@@ -24,10 +27,16 @@ type SheetsService struct {
 func NewSheetsService(userRepository *database.UserRepository, logger *logger.Logger) *SheetsService {
 	return &SheetsService{
 		userRepository: userRepository,
+		endpoints:      google.DefaultEndpoints(),
 		logger:         logger.WithContext("component", "sheets_service"),
 	}
 }
 
+// SetEndpoints points spreadsheet validation at alternate Google URLs
+func (s *SheetsService) SetEndpoints(endpoints google.Endpoints) {
+	s.endpoints = endpoints
+}
+
 // SpreadsheetValidationError represents different types of validation failures
 type SpreadsheetValidationError struct {
 	Type    string
@@ -151,7 +160,7 @@ func (s *SheetsService) createSheetsClient(ctx context.Context, token *oauth2.To
 	tokenSource := oauth2.StaticTokenSource(token)
 
 	// Create Sheets service with authenticated client
-	sheetsService, err := sheets.NewService(ctx, option.WithTokenSource(tokenSource))
+	sheetsService, err := sheets.NewService(ctx, outbound.GoogleOption(s.logger, tokenSource), option.WithEndpoint(s.endpoints.SheetsEndpoint()))
 	if err != nil {
 		s.logger.Error("Failed to create Sheets service", "error", err)
 		return nil, err
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/automation"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/format"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+const (
+	// statsWeeks is the number of weeks, including the current one, covered by the totals
+	statsWeeks = 4
+	// streakLookbackDays bounds how far back streaks are computed
+	streakLookbackDays = 90
+)
+
+// StatsError represents errors while computing dashboard stats
+type StatsError struct {
+	Message string
+	Cause   error
+}
+
+func (e *StatsError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s (caused by: %v)", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// PeriodTotals aggregates activities over a period
+type PeriodTotals struct {
+	DistanceKm        float64 `json:"distance_km"`
+	MovingTimeSeconds int     `json:"moving_time_seconds"`
+	ElevationGainM    float64 `json:"elevation_gain_m"`
+	ActivityCount     int     `json:"activity_count"`
+	RunCount          int     `json:"run_count"`
+}
+
+// WeekTotals aggregates activities over a Monday-to-Sunday week
+type WeekTotals struct {
+	WeekStart string `json:"week_start"`
+	PeriodTotals
+}
+
+// LongestRun describes the longest run in the stats window
+type LongestRun struct {
+	ActivityID        int64   `json:"activity_id"`
+	Name              string  `json:"name"`
+	Date              string  `json:"date"`
+	DistanceKm        float64 `json:"distance_km"`
+	MovingTimeSeconds int     `json:"moving_time_seconds"`
+
+	// Display forms, e.g. "01:45:00" and "4:58 /km"
+	MovingTime string `json:"moving_time"`
+	Pace       string `json:"pace,omitempty"`
+}
+
+// StreakStats describes consecutive days with at least one activity
+type StreakStats struct {
+	CurrentDays      int    `json:"current_days"`
+	LongestDays      int    `json:"longest_days"`
+	LastActivityDate string `json:"last_activity_date,omitempty"`
+}
+
+// DashboardStats is the progress summary shown on the dashboard
+type DashboardStats struct {
+	Timezone         string       `json:"timezone"`
+	CurrentWeek      WeekTotals   `json:"current_week"`
+	LastFourWeeks    PeriodTotals `json:"last_four_weeks"`
+	Weeks            []WeekTotals `json:"weeks"`
+	LongestRun       *LongestRun  `json:"longest_run,omitempty"`
+	Streak           StreakStats  `json:"streak"`
+	CacheRefreshedAt *time.Time   `json:"cache_refreshed_at"`
+}
+
+// GearSummary is the mileage of a pair of shoes or a bike
+type GearSummary struct {
+	GearID    string `json:"gear_id"`
+	Kind      string `json:"kind"` // "shoes" or "bike"
+	Name      string `json:"name"`
+	BrandName string `json:"brand_name,omitempty"`
+	ModelName string `json:"model_name,omitempty"`
+	Retired   bool   `json:"retired"`
+
+	// TotalDistanceKm is every activity Strava has recorded with the gear, as of UpdatedAt
+	TotalDistanceKm float64   `json:"total_distance_km"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Synced activities in the local cache recorded with the gear
+	SyncedDistanceKm float64 `json:"synced_distance_km"`
+	ActivityCount    int     `json:"activity_count"`
+	LastUsed         string  `json:"last_used,omitempty"`
+}
+
+// GearMileageSummary lists the user's gear, active gear first and most recently used first
+type GearMileageSummary struct {
+	Timezone string        `json:"timezone"`
+	Gear     []GearSummary `json:"gear"`
+}
+
+// StatsService computes dashboard stats from the local activity cache
+type StatsService struct {
+	userRepository     *database.UserRepository
+	activityRepository *database.ActivityRepository
+	logger             *logger.Logger
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(userRepository *database.UserRepository, activityRepository *database.ActivityRepository, logger *logger.Logger) *StatsService {
+	return &StatsService{
+		userRepository:     userRepository,
+		activityRepository: activityRepository,
+		logger:             logger.WithContext("component", "stats_service"),
+	}
+}
+
+// GetStats computes the user's dashboard stats from cached activities without calling Strava
+func (s *StatsService) GetStats(ctx context.Context, userID int) (*DashboardStats, error) {
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, &StatsError{Message: "Failed to load user", Cause: err}
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now := time.Now().In(loc)
+	// One extra day covers activities whose UTC start precedes the local window start
+	from := now.AddDate(0, 0, -streakLookbackDays-1)
+	activities, err := s.activityRepository.GetActivitiesInRange(ctx, userID, from, now.Add(time.Minute))
+	if err != nil {
+		return nil, &StatsError{Message: "Failed to read cached activities", Cause: err}
+	}
+
+	refreshedAt, err := s.activityRepository.GetCacheRefreshedAt(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to read activity cache state",
+			"error", err,
+			"user_id", userID)
+	}
+
+	stats := ComputeDashboardStats(activities, loc, now)
+	stats.CacheRefreshedAt = refreshedAt
+	return stats, nil
+}
+
+// GetGearMileage summarizes the mileage of the user's gear from the gear and activity caches
+func (s *StatsService) GetGearMileage(ctx context.Context, userID int) (*GearMileageSummary, error) {
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, &StatsError{Message: "Failed to load user", Cause: err}
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	mileage, err := s.activityRepository.GetGearMileage(ctx, userID)
+	if err != nil {
+		return nil, &StatsError{Message: "Failed to read cached gear", Cause: err}
+	}
+
+	return SummarizeGear(mileage, loc), nil
+}
+
+// SummarizeGear converts cached gear mileage into the summary shown to the user
+func SummarizeGear(mileage []database.GearMileage, loc *time.Location) *GearMileageSummary {
+	summary := &GearMileageSummary{
+		Timezone: loc.String(),
+		Gear:     make([]GearSummary, 0, len(mileage)),
+	}
+	for _, gear := range mileage {
+		item := GearSummary{
+			GearID:           gear.GearID,
+			Kind:             gearKind(gear.GearID),
+			Name:             gear.Name,
+			BrandName:        gear.BrandName,
+			ModelName:        gear.ModelName,
+			Retired:          gear.Retired,
+			TotalDistanceKm:  roundKm(gear.Distance),
+			UpdatedAt:        gear.FetchedAt,
+			SyncedDistanceKm: roundKm(gear.SyncedDistance),
+			ActivityCount:    gear.ActivityCount,
+		}
+		if gear.LastUsedAt != nil {
+			item.LastUsed = gear.LastUsedAt.In(loc).Format("2006-01-02")
+		}
+		summary.Gear = append(summary.Gear, item)
+	}
+	return summary
+}
+
+// gearKind tells shoes from bikes by the prefix of Strava gear IDs
+func gearKind(gearID string) string {
+	if strings.HasPrefix(gearID, "b") {
+		return "bike"
+	}
+	return "shoes"
+}
+
+// ComputeDashboardStats aggregates activities into dashboard stats for the week containing now
+func ComputeDashboardStats(activities []strava.Activity, loc *time.Location, now time.Time) *DashboardStats {
+	now = now.In(loc)
+	currentWeekStart := automation.StartOfWeek(now)
+	windowStart := currentWeekStart.AddDate(0, 0, -7*(statsWeeks-1))
+
+	stats := &DashboardStats{
+		Timezone: loc.String(),
+		Weeks:    make([]WeekTotals, statsWeeks),
+	}
+	for i := range stats.Weeks {
+		stats.Weeks[i].WeekStart = windowStart.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+
+	for _, summary := range automation.ComputeWeeklySummaries(activities, loc, windowStart) {
+		index := int(summary.WeekStart.Sub(windowStart).Hours()/24) / 7
+		if index < 0 || index >= statsWeeks {
+			continue
+		}
+		stats.Weeks[index].PeriodTotals = PeriodTotals{
+			DistanceKm:        roundKm(summary.DistanceMeters),
+			MovingTimeSeconds: summary.MovingTimeSeconds,
+			ElevationGainM:    math.Round(summary.ElevationGainMeters),
+			ActivityCount:     summary.ActivityCount,
+			RunCount:          summary.RunCount,
+		}
+	}
+	stats.CurrentWeek = stats.Weeks[statsWeeks-1]
+
+	var totalMeters, totalElevation float64
+	for _, activity := range activities {
+		start := activity.StartDate.In(loc)
+		if start.Before(windowStart) {
+			continue
+		}
+
+		totalMeters += activity.Distance
+		totalElevation += activity.TotalElevationGain
+		stats.LastFourWeeks.MovingTimeSeconds += activity.MovingTime
+		stats.LastFourWeeks.ActivityCount++
+		if activity.Type != "Run" {
+			continue
+		}
+		stats.LastFourWeeks.RunCount++
+
+		if stats.LongestRun == nil || roundKm(activity.Distance) > stats.LongestRun.DistanceKm {
+			stats.LongestRun = &LongestRun{
+				ActivityID:        activity.ID,
+				Name:              activity.Name,
+				Date:              start.Format("2006-01-02"),
+				DistanceKm:        roundKm(activity.Distance),
+				MovingTimeSeconds: activity.MovingTime,
+				MovingTime:        format.Duration(activity.MovingTime),
+				Pace:              format.Default.Pace(activity.MovingTime, activity.Distance),
+			}
+		}
+	}
+	stats.LastFourWeeks.DistanceKm = roundKm(totalMeters)
+	stats.LastFourWeeks.ElevationGainM = math.Round(totalElevation)
+
+	stats.Streak = computeStreak(activities, loc, now)
+	return stats
+}
+
+// computeStreak counts consecutive active days. The current streak stays alive through today
+// until the day is over, so it counts back from yesterday when there is no activity yet today.
+func computeStreak(activities []strava.Activity, loc *time.Location, now time.Time) StreakStats {
+	activeDays := make(map[string]bool)
+	var last time.Time
+	for _, activity := range activities {
+		start := activity.StartDate.In(loc)
+		activeDays[start.Format("2006-01-02")] = true
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	var streak StreakStats
+	if len(activeDays) == 0 {
+		return streak
+	}
+	streak.LastActivityDate = last.Format("2006-01-02")
+
+	day := now
+	if !activeDays[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for activeDays[day.Format("2006-01-02")] {
+		streak.CurrentDays++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	run := 0
+	for day := now.AddDate(0, 0, -streakLookbackDays); !day.After(now); day = day.AddDate(0, 0, 1) {
+		if activeDays[day.Format("2006-01-02")] {
+			run++
+			if run > streak.LongestDays {
+				streak.LongestDays = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	return streak
+}
+
+// roundKm converts meters to kilometers rounded to two decimals
+func roundKm(meters float64) float64 {
+	return math.Round(meters/10) / 100
+}
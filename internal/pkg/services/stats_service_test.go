@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestComputeDashboardStats(t *testing.T) {
+	// Wednesday 2024-06-12; the current week starts Monday 2024-06-10
+	now := time.Date(2024, 6, 12, 18, 0, 0, 0, time.UTC)
+	at := func(day int) time.Time { return time.Date(2024, 6, day, 7, 0, 0, 0, time.UTC) }
+
+	activities := []strava.Activity{
+		{ID: 1, Name: "Old long run", Type: "Run", Distance: 30000, StartDate: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "Long run", Type: "Run", Distance: 21100, MovingTime: 6300, StartDate: at(2)},
+		{ID: 3, Name: "Ride", Type: "Ride", Distance: 40000, MovingTime: 5400, StartDate: at(5)},
+		{ID: 4, Name: "Easy", Type: "Run", Distance: 5000, MovingTime: 1500, StartDate: at(10)},
+		{ID: 5, Name: "Tempo", Type: "Run", Distance: 8000, MovingTime: 2400, StartDate: at(11)},
+		{ID: 6, Name: "Recovery", Type: "Run", Distance: 4000, MovingTime: 1400, StartDate: at(12)},
+	}
+
+	stats := ComputeDashboardStats(activities, time.UTC, now)
+
+	if stats.CurrentWeek.WeekStart != "2024-06-10" || stats.CurrentWeek.DistanceKm != 17 || stats.CurrentWeek.RunCount != 3 {
+		t.Errorf("Unexpected current week: %+v", stats.CurrentWeek)
+	}
+	if len(stats.Weeks) != 4 || stats.Weeks[0].WeekStart != "2024-05-20" {
+		t.Errorf("Expected 4 weeks starting 2024-05-20, got %+v", stats.Weeks)
+	}
+	// The May 1st run is outside the 4-week window
+	if stats.LastFourWeeks.DistanceKm != 78.1 || stats.LastFourWeeks.ActivityCount != 5 {
+		t.Errorf("Unexpected 4-week totals: %+v", stats.LastFourWeeks)
+	}
+	if stats.LongestRun == nil || stats.LongestRun.ActivityID != 2 {
+		t.Errorf("Expected longest run to be activity 2, got %+v", stats.LongestRun)
+	}
+	if stats.LongestRun != nil && (stats.LongestRun.MovingTime != "01:45:00" || stats.LongestRun.Pace != "4:58 /km") {
+		t.Errorf("Expected the longest run to display as 01:45:00 at 4:58 /km, got %+v", stats.LongestRun)
+	}
+	if stats.Streak.CurrentDays != 3 || stats.Streak.LongestDays != 3 || stats.Streak.LastActivityDate != "2024-06-12" {
+		t.Errorf("Unexpected streak: %+v", stats.Streak)
+	}
+}
+
+func TestComputeStreak_TodayNotYetActive(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	activities := []strava.Activity{
+		{StartDate: time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC)},
+		{StartDate: time.Date(2024, 6, 11, 7, 0, 0, 0, time.UTC)},
+	}
+
+	if streak := computeStreak(activities, time.UTC, now); streak.CurrentDays != 2 {
+		t.Errorf("Expected the streak to survive until the end of today, got %+v", streak)
+	}
+
+	if streak := computeStreak(activities, time.UTC, now.AddDate(0, 0, 1)); streak.CurrentDays != 0 || streak.LongestDays != 2 {
+		t.Errorf("Expected a broken streak, got %+v", streak)
+	}
+}
+
+func TestSummarizeGear(t *testing.T) {
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// 22:30 UTC is already the next day in Sofia
+	lastUsed := time.Date(2024, 6, 11, 22, 30, 0, 0, time.UTC)
+	summary := SummarizeGear([]database.GearMileage{
+		{GearID: "g1001", Name: "Daily Trainers", Distance: 412345, ActivityCount: 12, SyncedDistance: 96120, LastUsedAt: &lastUsed},
+		{GearID: "b2002", Name: "Road Bike", Distance: 1500000, Retired: true},
+	}, sofia)
+
+	if len(summary.Gear) != 2 || summary.Timezone != "Europe/Sofia" {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+	shoes, bike := summary.Gear[0], summary.Gear[1]
+	if shoes.Kind != "shoes" || shoes.TotalDistanceKm != 412.35 || shoes.SyncedDistanceKm != 96.12 || shoes.LastUsed != "2024-06-12" {
+		t.Errorf("Unexpected shoes summary: %+v", shoes)
+	}
+	if bike.Kind != "bike" || !bike.Retired || bike.LastUsed != "" {
+		t.Errorf("Unexpected bike summary: %+v", bike)
+	}
+}
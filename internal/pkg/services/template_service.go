@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/outbound"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/templates"
+)
+
+// Template provisioning error types
+const (
+	TemplateErrorNotFound     = "TEMPLATE_NOT_FOUND"
+	TemplateErrorNotConnected = "GOOGLE_NOT_CONNECTED"
+	TemplateErrorReauth       = "GOOGLE_REAUTH_REQUIRED"
+	TemplateErrorGoogle       = "GOOGLE_API_ERROR"
+	TemplateErrorDatabase     = "DATABASE_ERROR"
+)
+
+// templateTokenTimeout bounds a Google token refresh made while provisioning
+const templateTokenTimeout = 30 * time.Second
+
+// provisionedSpreadsheetPrefix prefixes the title of spreadsheets created from the catalog
+const provisionedSpreadsheetPrefix = "Academy Sync"
+
+// TemplateError represents errors while provisioning a spreadsheet template
+type TemplateError struct {
+	Type    string
+	Message string
+	Cause   error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s (caused by: %v)", e.Type, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// ProvisionedSpreadsheet describes a spreadsheet created from a catalog template
+type ProvisionedSpreadsheet struct {
+	TemplateID     string `json:"template_id"`
+	SpreadsheetID  string `json:"spreadsheet_id"`
+	SpreadsheetURL string `json:"spreadsheet_url"`
+}
+
+// TemplateService provisions catalog templates into users' Google Drive
+type TemplateService struct {
+	userRepository     *database.UserRepository
+	sources            map[string]string
+	googleClientID     string
+	secretMu           sync.RWMutex
+	googleClientSecret string
+	googleRedirectURL  string
+	endpoints          google.Endpoints
+	logger             *logger.Logger
+}
+
+// NewTemplateService creates a new template service.
+// sources maps template IDs to the Drive file copied for that template; templates without a
+// source are provisioned as a new spreadsheet containing only the template's header row. The
+// Google client credentials refresh the user's access token when it has expired.
+func NewTemplateService(userRepository *database.UserRepository, sources map[string]string, googleClientID, googleClientSecret, googleRedirectURL string, logger *logger.Logger) *TemplateService {
+	return &TemplateService{
+		userRepository:     userRepository,
+		sources:            sources,
+		googleClientID:     googleClientID,
+		googleClientSecret: googleClientSecret,
+		googleRedirectURL:  googleRedirectURL,
+		endpoints:          google.DefaultEndpoints(),
+		logger:             logger.WithContext("component", "template_service"),
+	}
+}
+
+// SetGoogleClientSecret replaces the Google client secret after a rotation
+func (s *TemplateService) SetGoogleClientSecret(secret string) {
+	s.secretMu.Lock()
+	defer s.secretMu.Unlock()
+
+	s.googleClientSecret = secret
+}
+
+// SetEndpoints points template provisioning at alternate Google URLs
+func (s *TemplateService) SetEndpoints(endpoints google.Endpoints) {
+	s.endpoints = endpoints
+}
+
+// ListTemplates returns the template catalog
+func (s *TemplateService) ListTemplates() []*templates.Template {
+	return templates.All()
+}
+
+// ProvisionTemplate copies the template into the user's Drive and makes it their configured spreadsheet
+func (s *TemplateService) ProvisionTemplate(ctx context.Context, userID int, templateID string) (*ProvisionedSpreadsheet, error) {
+	template, ok := templates.Get(templateID)
+	if !ok {
+		return nil, &TemplateError{Type: TemplateErrorNotFound, Message: "Unknown spreadsheet template: " + templateID}
+	}
+
+	startTime := time.Now()
+	s.logger.Info("Provisioning spreadsheet template",
+		"user_id", userID,
+		"template_id", template.ID,
+		"has_drive_source", s.sources[template.ID] != "")
+
+	accessToken, refreshToken, expiry, err := s.userRepository.GetDecryptedGoogleTokens(ctx, userID)
+	if err != nil {
+		return nil, &TemplateError{Type: TemplateErrorDatabase, Message: "Failed to retrieve authentication tokens", Cause: err}
+	}
+	if refreshToken == "" && (accessToken == "" || expiry == nil) {
+		return nil, &TemplateError{Type: TemplateErrorNotConnected, Message: "No Google authentication found. Please reconnect your Google account."}
+	}
+
+	stored := &oauth2.Token{AccessToken: accessToken, RefreshToken: refreshToken}
+	if expiry != nil {
+		stored.Expiry = *expiry
+	}
+	tokenSource := s.tokenSource(ctx, stored)
+	title := fmt.Sprintf("%s - %s", provisionedSpreadsheetPrefix, template.Name)
+
+	var spreadsheetID string
+	if sourceID := s.sources[template.ID]; sourceID != "" {
+		spreadsheetID, err = s.copyDriveFile(ctx, tokenSource, sourceID, title)
+	} else {
+		spreadsheetID, err = s.createFromHeader(ctx, tokenSource, template, title)
+	}
+	// A token refreshed for the calls is kept even when provisioning failed
+	s.saveRefreshedToken(ctx, userID, stored, tokenSource)
+	if err != nil {
+		s.logger.Error("Failed to provision spreadsheet template",
+			"error", err,
+			"user_id", userID,
+			"template_id", template.ID)
+		return nil, templateGoogleError(err)
+	}
+
+	if err := s.userRepository.UpdateSpreadsheetTemplate(ctx, userID, spreadsheetID, template.ID); err != nil {
+		return nil, &TemplateError{Type: TemplateErrorDatabase, Message: "Failed to save spreadsheet configuration. Please try again.", Cause: err}
+	}
+
+	s.logger.Info("Spreadsheet template provisioned",
+		"user_id", userID,
+		"template_id", template.ID,
+		"spreadsheet_id", spreadsheetID,
+		"duration_ms", time.Since(startTime).Milliseconds())
+
+	return &ProvisionedSpreadsheet{
+		TemplateID:     template.ID,
+		SpreadsheetID:  spreadsheetID,
+		SpreadsheetURL: "https://docs.google.com/spreadsheets/d/" + spreadsheetID,
+	}, nil
+}
+
+// tokenSource returns a source that serves the stored token and refreshes it with the Google
+// OAuth client once it has expired
+func (s *TemplateService) tokenSource(ctx context.Context, stored *oauth2.Token) oauth2.TokenSource {
+	s.secretMu.RLock()
+	clientSecret := s.googleClientSecret
+	s.secretMu.RUnlock()
+
+	config := &oauth2.Config{
+		ClientID:     s.googleClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  s.googleRedirectURL,
+		Endpoint:     s.endpoints.OAuthEndpoint(),
+	}
+	// The token endpoint is reached through the instrumented transport too
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, outbound.NewClient(outbound.ProviderGoogle, s.logger, templateTokenTimeout))
+	return config.TokenSource(ctx, stored)
+}
+
+// saveRefreshedToken stores the token tokenSource holds when it differs from the stored one, so
+// the next request does not refresh again and a rotated refresh token is not lost. Failures are
+// logged; the refreshed token is simply obtained again later.
+func (s *TemplateService) saveRefreshedToken(ctx context.Context, userID int, stored *oauth2.Token, tokenSource oauth2.TokenSource) {
+	current, err := tokenSource.Token()
+	if err != nil || current.AccessToken == stored.AccessToken {
+		return
+	}
+
+	refreshToken := current.RefreshToken
+	if refreshToken == "" {
+		refreshToken = stored.RefreshToken
+	}
+	if err := s.userRepository.UpdateUserTokens(ctx, &database.UpdateUserTokensRequest{
+		UserID:             userID,
+		GoogleAccessToken:  current.AccessToken,
+		GoogleRefreshToken: refreshToken,
+		GoogleTokenExpiry:  &current.Expiry,
+	}); err != nil {
+		s.logger.Warn("Failed to save refreshed Google token",
+			"error", err,
+			"user_id", userID)
+	}
+}
+
+// templateGoogleError maps a failed Google call to a template error. Tokens that can no longer be
+// refreshed, and tokens granted before the app asked for the Drive scope, need the user to sign
+// in again rather than retry.
+func templateGoogleError(err error) *TemplateError {
+	if google.IsReauthRequired(err) || google.IsInsufficientScope(err) {
+		return &TemplateError{Type: TemplateErrorReauth, Message: "Google needs to be reconnected to create spreadsheets. Please sign in with Google again.", Cause: err}
+	}
+	return &TemplateError{Type: TemplateErrorGoogle, Message: "Failed to create the spreadsheet in Google Drive. Please try again.", Cause: err}
+}
+
+// copyDriveFile copies a template spreadsheet into the user's Drive and returns the new file ID
+func (s *TemplateService) copyDriveFile(ctx context.Context, tokenSource oauth2.TokenSource, sourceID, title string) (string, error) {
+	driveService, err := drive.NewService(ctx, outbound.GoogleOption(s.logger, tokenSource), option.WithEndpoint(s.endpoints.DriveEndpoint()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Drive client: %w", err)
+	}
+
+	file, err := driveService.Files.Copy(sourceID, &drive.File{Name: title}).
+		Fields("id").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to copy template file: %w", err)
+	}
+
+	return file.Id, nil
+}
+
+// createFromHeader creates a blank spreadsheet whose activity sheet starts with the template header
+func (s *TemplateService) createFromHeader(ctx context.Context, tokenSource oauth2.TokenSource, template *templates.Template, title string) (string, error) {
+	sheetsService, err := sheets.NewService(ctx, outbound.GoogleOption(s.logger, tokenSource), option.WithEndpoint(s.endpoints.SheetsEndpoint()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Sheets client: %w", err)
+	}
+
+	// The engine writes to Sheet1, so name it explicitly rather than relying on the locale default
+	spreadsheet, err := sheetsService.Spreadsheets.Create(&sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{Title: title},
+		Sheets: []*sheets.Sheet{
+			{Properties: &sheets.SheetProperties{Title: "Sheet1"}},
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create spreadsheet: %w", err)
+	}
+
+	headerRange := fmt.Sprintf("Sheet1!A1:%s1", template.LastColumn())
+	_, err = sheetsService.Spreadsheets.Values.Update(spreadsheet.SpreadsheetId, headerRange, &sheets.ValueRange{
+		Values: [][]interface{}{template.Header()},
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to write template header: %w", err)
+	}
+
+	return spreadsheet.SpreadsheetId, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+func TestTemplateService_ProvisionUnknownTemplate(t *testing.T) {
+	// Unknown templates are rejected before any database or Google call
+	service := NewTemplateService(nil, nil, "client-id", "client-secret", "", logger.New("test"))
+
+	_, err := service.ProvisionTemplate(context.Background(), 1, "marathon_plan")
+
+	var templateErr *TemplateError
+	if !errors.As(err, &templateErr) || templateErr.Type != TemplateErrorNotFound {
+		t.Errorf("Expected %s error, got %v", TemplateErrorNotFound, err)
+	}
+}
+
+func TestTemplateService_ListTemplates(t *testing.T) {
+	service := NewTemplateService(nil, nil, "client-id", "client-secret", "", logger.New("test"))
+
+	if len(service.ListTemplates()) != 3 {
+		t.Errorf("Expected 3 catalog templates, got %d", len(service.ListTemplates()))
+	}
+}
+
+func TestTemplateService_TokenSourceRefreshesExpiredToken(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"fresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	service := NewTemplateService(nil, nil, "client-id", "client-secret", "", logger.New("test"))
+	service.SetEndpoints(google.Endpoints{TokenURL: tokenServer.URL})
+
+	expired := &oauth2.Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}
+	token, err := service.tokenSource(context.Background(), expired).Token()
+	if err != nil {
+		t.Fatalf("Token refresh failed: %v", err)
+	}
+	// Google does not rotate the refresh token, so the stored one is kept
+	if token.AccessToken != "fresh" || token.RefreshToken != "refresh" {
+		t.Errorf("Expected a refreshed access token with the stored refresh token, got %+v", token)
+	}
+}
+
+func TestTemplateService_InsufficientScopeRequiresReauth(t *testing.T) {
+	driveServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":403,"message":"Request had insufficient authentication scopes.",
+			"errors":[{"reason":"insufficientPermissions","message":"Insufficient Permission"}]}}`))
+	}))
+	defer driveServer.Close()
+
+	service := NewTemplateService(nil, nil, "client-id", "client-secret", "", logger.New("test"))
+	service.SetEndpoints(google.Endpoints{DriveBaseURL: driveServer.URL + "/"})
+
+	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)})
+	_, err := service.copyDriveFile(context.Background(), tokenSource, "template-file", "Academy Sync - Plan")
+	if err == nil {
+		t.Fatal("Expected the copy to fail")
+	}
+	if templateErr := templateGoogleError(err); templateErr.Type != TemplateErrorReauth {
+		t.Errorf("Expected %s, got %s", TemplateErrorReauth, templateErr.Type)
+	}
+
+	// Other Google failures stay retryable
+	if templateErr := templateGoogleError(errors.New("connection reset")); templateErr.Type != TemplateErrorGoogle {
+		t.Errorf("Expected %s, got %s", TemplateErrorGoogle, templateErr.Type)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/google"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// DefaultUndoTokenTTL is how long a run undo confirmation token stays valid
+const DefaultUndoTokenTTL = 10 * time.Minute
+
+// Undo error types
+const (
+	UndoErrorNotFound     = "RUN_NOT_FOUND"
+	UndoErrorNotUndoable  = "RUN_NOT_UNDOABLE"
+	UndoErrorUndone       = "RUN_ALREADY_UNDONE"
+	UndoErrorSuperseded   = "RUN_SUPERSEDED"
+	UndoErrorInvalidToken = "INVALID_CONFIRMATION_TOKEN"
+	UndoErrorNotConnected = "GOOGLE_NOT_CONNECTED"
+	UndoErrorGoogle       = "GOOGLE_ERROR"
+	UndoErrorDatabase     = "DATABASE_ERROR"
+)
+
+// UndoError represents errors while undoing a run's sheet writes
+type UndoError struct {
+	Type    string
+	Message string
+	Cause   error
+}
+
+func (e *UndoError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s (caused by: %v)", e.Type, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// RunUndoStore reads and updates the undo state of automation runs; *database.RunRepository
+// satisfies it
+type RunUndoStore interface {
+	GetRunUndo(ctx context.Context, userID, runID int) (*database.RunUndo, error)
+	SetUndoToken(ctx context.Context, runID int, tokenHash string, expiresAt time.Time) error
+	ClaimRunUndo(ctx context.Context, runID int, tokenHash string, now time.Time) error
+	ReleaseRunUndo(ctx context.Context, runID int) error
+}
+
+// UndoConfirmation is returned when an undo is requested; the undo runs once the token is sent back
+type UndoConfirmation struct {
+	RunID             int             `json:"run_id"`
+	SpreadsheetID     string          `json:"spreadsheet_id"`
+	Plan              google.UndoPlan `json:"plan"`
+	ConfirmationToken string          `json:"confirmation_token"`
+	ExpiresAt         time.Time       `json:"expires_at"`
+}
+
+// UndoService undoes the spreadsheet rows written by a user's sync run. An undo is requested first,
+// which returns what it would change and a short-lived confirmation token, and then confirmed with
+// the token. Only the most recent run that wrote rows can be undone, so undos never clash with
+// rows written afterwards.
+type UndoService struct {
+	userRepository     *database.UserRepository
+	runs               RunUndoStore
+	googleClientID     string
+	secretMu           sync.RWMutex
+	googleClientSecret string
+	googleRedirectURL  string
+	endpoints          google.Endpoints
+	tokenTTL           time.Duration
+	now                func() time.Time
+	logger             *logger.Logger
+}
+
+// NewUndoService creates a new run undo service
+func NewUndoService(userRepository *database.UserRepository, runs RunUndoStore, googleClientID, googleClientSecret, googleRedirectURL string, logger *logger.Logger) *UndoService {
+	return &UndoService{
+		userRepository:     userRepository,
+		runs:               runs,
+		googleClientID:     googleClientID,
+		googleClientSecret: googleClientSecret,
+		googleRedirectURL:  googleRedirectURL,
+		endpoints:          google.DefaultEndpoints(),
+		tokenTTL:           DefaultUndoTokenTTL,
+		now:                time.Now,
+		logger:             logger.WithContext("component", "undo_service"),
+	}
+}
+
+// SetGoogleClientSecret replaces the Google client secret after a rotation
+func (s *UndoService) SetGoogleClientSecret(secret string) {
+	s.secretMu.Lock()
+	defer s.secretMu.Unlock()
+
+	s.googleClientSecret = secret
+}
+
+// SetEndpoints points undos at alternate Google URLs
+func (s *UndoService) SetEndpoints(endpoints google.Endpoints) {
+	s.endpoints = endpoints
+}
+
+// RequestUndo checks that the run can be undone and issues a confirmation token for it,
+// replacing any token issued earlier
+func (s *UndoService) RequestUndo(ctx context.Context, userID, runID int) (*UndoConfirmation, error) {
+	writes, err := s.undoableRun(ctx, userID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := auth.NewUndoToken()
+	if err != nil {
+		return nil, &UndoError{Type: UndoErrorDatabase, Message: "Failed to issue a confirmation token", Cause: err}
+	}
+	expiresAt := s.now().Add(s.tokenTTL)
+	if err := s.runs.SetUndoToken(ctx, runID, hash, expiresAt); err != nil {
+		return nil, &UndoError{Type: UndoErrorDatabase, Message: "Failed to issue a confirmation token", Cause: err}
+	}
+
+	return &UndoConfirmation{
+		RunID:             runID,
+		SpreadsheetID:     writes.SpreadsheetID,
+		Plan:              writes.Plan(),
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// ConfirmUndo undoes the run's sheet writes if token is its unexpired confirmation token. The
+// token is used up even when the undo fails; the run can then be undone with a new one.
+func (s *UndoService) ConfirmUndo(ctx context.Context, userID, runID int, token string) (*google.UndoResult, error) {
+	writes, err := s.undoableRun(ctx, userID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, expiry, err := s.userRepository.GetDecryptedGoogleTokens(ctx, userID)
+	if err != nil {
+		return nil, &UndoError{Type: UndoErrorDatabase, Message: "Failed to retrieve authentication tokens", Cause: err}
+	}
+	if refreshToken == "" && (accessToken == "" || expiry == nil) {
+		return nil, &UndoError{Type: UndoErrorNotConnected, Message: "No Google authentication found. Please reconnect your Google account."}
+	}
+
+	// Claiming marks the run undone first, so concurrent confirmations undo it at most once
+	if err := s.runs.ClaimRunUndo(ctx, runID, auth.HashUndoToken(token), s.now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &UndoError{Type: UndoErrorInvalidToken, Message: "The confirmation token is invalid or has expired. Request the undo again."}
+		}
+		return nil, &UndoError{Type: UndoErrorDatabase, Message: "Failed to confirm the undo", Cause: err}
+	}
+
+	s.secretMu.RLock()
+	clientSecret := s.googleClientSecret
+	s.secretMu.RUnlock()
+
+	opts := []google.Option{
+		google.WithOAuthCredentials(s.googleClientID, clientSecret, s.googleRedirectURL),
+		google.WithEndpoints(s.endpoints),
+	}
+	if accessToken != "" && expiry != nil {
+		opts = append(opts, google.WithInitialToken(accessToken, *expiry))
+	}
+	client := google.NewSheetsClient(userID, refreshToken, s.logger, opts...)
+
+	result, err := client.UndoWrites(ctx, writes)
+	if err != nil {
+		if releaseErr := s.runs.ReleaseRunUndo(ctx, runID); releaseErr != nil {
+			s.logger.Error("Failed to release run undo after a failed undo",
+				"error", releaseErr,
+				"user_id", userID,
+				"run_id", runID)
+		}
+		s.logger.Error("Failed to undo run sheet writes",
+			"error", err,
+			"user_id", userID,
+			"run_id", runID)
+		return nil, &UndoError{Type: UndoErrorGoogle, Message: "Failed to undo the changes in Google Sheets. Please try again.", Cause: err}
+	}
+
+	s.logger.Info("Run sheet writes undone",
+		"user_id", userID,
+		"run_id", runID,
+		"rows_deleted", result.RowsDeleted,
+		"flags_cleared", result.FlagsCleared)
+	return result, nil
+}
+
+// undoableRun loads the recorded writes of a run of the user, checking that it can be undone
+func (s *UndoService) undoableRun(ctx context.Context, userID, runID int) (*google.SheetWrites, error) {
+	undo, err := s.runs.GetRunUndo(ctx, userID, runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &UndoError{Type: UndoErrorNotFound, Message: "Run not found"}
+	}
+	if err != nil {
+		return nil, &UndoError{Type: UndoErrorDatabase, Message: "Failed to load the run", Cause: err}
+	}
+
+	switch {
+	case undo.UndoneAt != nil:
+		return nil, &UndoError{Type: UndoErrorUndone, Message: "This run has already been undone"}
+	case len(undo.SheetWrites) == 0:
+		return nil, &UndoError{Type: UndoErrorNotUndoable, Message: "This run wrote no rows that can be undone"}
+	case undo.Superseded:
+		return nil, &UndoError{Type: UndoErrorSuperseded, Message: "A later run wrote to the spreadsheet; only the most recent run can be undone"}
+	}
+
+	var writes google.SheetWrites
+	if err := json.Unmarshal(undo.SheetWrites, &writes); err != nil {
+		return nil, &UndoError{Type: UndoErrorNotUndoable, Message: "The rows written by this run could not be read", Cause: err}
+	}
+	return &writes, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/auth"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/database"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+type mockRunUndoStore struct {
+	runs      map[int]*database.RunUndo
+	tokenHash string
+	expiresAt time.Time
+}
+
+func (m *mockRunUndoStore) GetRunUndo(ctx context.Context, userID, runID int) (*database.RunUndo, error) {
+	undo, ok := m.runs[runID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return undo, nil
+}
+
+func (m *mockRunUndoStore) SetUndoToken(ctx context.Context, runID int, tokenHash string, expiresAt time.Time) error {
+	m.tokenHash, m.expiresAt = tokenHash, expiresAt
+	return nil
+}
+
+func (m *mockRunUndoStore) ClaimRunUndo(ctx context.Context, runID int, tokenHash string, now time.Time) error {
+	return sql.ErrNoRows
+}
+
+func (m *mockRunUndoStore) ReleaseRunUndo(ctx context.Context, runID int) error {
+	return nil
+}
+
+func TestUndoService_RequestUndo(t *testing.T) {
+	undoneAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	writes := []byte(`{"spreadsheet_id":"sheet-1","sheet":"Sheet1","activity_id_column":9,"name_column":1,"rows":[
+		{"action":"append","activity_id":7,"range":"Sheet1!A5:J5"},
+		{"action":"update","activity_id":3,"range":"Sheet1!A3:J3"},
+		{"action":"flag_deleted","activity_id":2,"range":"Sheet1!A2:J2","previous_name":"Tempo"}]}`)
+	store := &mockRunUndoStore{runs: map[int]*database.RunUndo{
+		1: {RunID: 1, SheetWrites: writes},
+		2: {RunID: 2},
+		3: {RunID: 3, SheetWrites: writes, UndoneAt: &undoneAt},
+		4: {RunID: 4, SheetWrites: writes, Superseded: true},
+	}}
+	service := NewUndoService(nil, store, "client-id", "client-secret", "", logger.New("test"))
+	service.now = func() time.Time { return undoneAt }
+
+	confirmation, err := service.RequestUndo(context.Background(), 5, 1)
+	if err != nil {
+		t.Fatalf("RequestUndo failed: %v", err)
+	}
+	if confirmation.SpreadsheetID != "sheet-1" || confirmation.Plan.RowsToDelete != 1 || confirmation.Plan.FlagsToClear != 1 || confirmation.Plan.UpdatesKept != 1 {
+		t.Errorf("Unexpected undo plan: %+v", confirmation)
+	}
+	if store.tokenHash != auth.HashUndoToken(confirmation.ConfirmationToken) || !store.expiresAt.Equal(undoneAt.Add(DefaultUndoTokenTTL)) {
+		t.Errorf("Expected the token hash to be stored until %v, got %q until %v", undoneAt.Add(DefaultUndoTokenTTL), store.tokenHash, store.expiresAt)
+	}
+
+	for runID, wantType := range map[int]string{
+		2: UndoErrorNotUndoable,
+		3: UndoErrorUndone,
+		4: UndoErrorSuperseded,
+		9: UndoErrorNotFound,
+	} {
+		_, err := service.RequestUndo(context.Background(), 5, runID)
+		var undoErr *UndoError
+		if !errors.As(err, &undoErr) || undoErr.Type != wantType {
+			t.Errorf("Run %d: expected %s error, got %v", runID, wantType, err)
+		}
+	}
+}
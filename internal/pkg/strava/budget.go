@@ -0,0 +1,62 @@
+package strava
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CallCounter counts each user's Strava API calls per UTC day (YYYY-MM-DD), across every job and
+// process. IncrStravaCalls records one call and returns the day's count including it.
+type CallCounter interface {
+	IncrStravaCalls(ctx context.Context, userID int, day string) (int, error)
+}
+
+// BudgetExceededError is returned instead of calling Strava once the user's calls today reached
+// their daily limit. Strava's rate limits are per application, so the ceiling keeps one user's
+// large import from starving every other user.
+type BudgetExceededError struct {
+	UserID int
+	Limit  int
+	// ResetAt is the next UTC midnight, when Strava's daily limits and the budget reset
+	ResetAt time.Time
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("strava daily call budget of %d used up for user %d until %s",
+		e.Limit, e.UserID, e.ResetAt.Format(time.RFC3339))
+}
+
+// IsBudgetExceeded reports whether err means the user's daily Strava call budget is used up
+func IsBudgetExceeded(err error) bool {
+	var budgetErr *BudgetExceededError
+	return errors.As(err, &budgetErr)
+}
+
+// reserveCall counts an API call against the user's budget, failing when it is used up
+func (c *Client) reserveCall(ctx context.Context) error {
+	c.mu.RLock()
+	counter, limit := c.callCounter, c.dailyCallLimit
+	c.mu.RUnlock()
+	if counter == nil || limit <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	count, err := counter.IncrStravaCalls(ctx, c.userID, now.Format("2006-01-02"))
+	if err != nil {
+		c.log(ctx).Warn("Failed to count Strava API call against the daily budget, calling anyway",
+			"error", err,
+			"user_id", c.userID)
+		return nil
+	}
+	if count > limit {
+		return &BudgetExceededError{
+			UserID:  c.userID,
+			Limit:   limit,
+			ResetAt: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return nil
+}
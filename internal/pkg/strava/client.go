@@ -1,16 +1,22 @@
 package strava
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
 
 	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/outbound"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/retry"
 )
 
 // Activity represents a Strava activity with essential fields for automation processing
@@ -32,6 +38,9 @@ type Activity struct {
 	MaxHeartrate     float64   `json:"max_heartrate"`
 	Kudos            int       `json:"kudos_count"`
 	Comments         int       `json:"comment_count"`
+	GearID           string    `json:"gear_id"`     // Shoes or bike used, e.g. "g123" or "b456"; empty when none
+	GearName         string    `json:"gear_name"`   // Resolved from GearID by the engine; Strava does not send it
+	Source           string    `json:"source,omitempty"` // Set from the activity cache: "strava" or "manual" for activities logged in the app
 }
 
 // Client provides Strava API access with automatic token lifecycle management
@@ -51,59 +60,72 @@ type Client struct {
 	// OAuth configuration for token refresh
 	oauthConfig *oauth2.Config
 	
+	// Coordinates refreshes with concurrent jobs for the same user; nil refreshes directly
+	tokenRefresher TokenRefresher
+	
+	// Optional store for refreshed tokens (see WithTokenSaver)
+	tokenSaver TokenSaver
+	
+	// Base URLs of the Strava API and OAuth endpoints
+	endpoints Endpoints
+	
+	// Optional per-user daily ceiling on API calls (see WithCallBudget)
+	callCounter    CallCounter
+	dailyCallLimit int
+	
+	// Optional cache of responses that rarely change (see WithResponseCache)
+	responseCache *respcache.Cache
+	
+	// Optional limit on the rate of API calls (see WithRateLimiter)
+	rateLimiter RateLimiter
+	
+	// Optional recipient of raw activity responses (see WithResponseRecorder)
+	responseRecorder ResponseRecorder
+	
 	// Logger for debugging external API interactions
 	logger *logger.Logger
 }
 
 // NewClient creates a new Strava API client for a specific user
 // The client is designed to be instantiated per-user for each processing job
-func NewClient(userID int, refreshToken string, logger *logger.Logger) *Client {
-	// Create OAuth2 config for token refresh operations
-	oauthConfig := &oauth2.Config{
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  "https://www.strava.com/oauth/authorize",
-			TokenURL: "https://www.strava.com/oauth/token",
-		},
-		// Note: Client ID and Secret should be injected via config
-		// For now, we'll set them when needed in token refresh
-	}
-
-	return &Client{
+func NewClient(userID int, refreshToken string, logger *logger.Logger, opts ...Option) *Client {
+	c := &Client{
 		userID:       userID,
 		refreshToken: refreshToken,
-		httpClient:   &http.Client{Timeout: 30 * time.Second},
-		oauthConfig:  oauthConfig,
-		logger:       logger.WithContext("component", "strava_client", "user_id", userID),
+		httpClient:   &http.Client{Timeout: DefaultHTTPTimeout},
+		// OAuth2 config for token refresh operations; client credentials come from WithOAuthCredentials
+		oauthConfig: &oauth2.Config{},
+		endpoints:   DefaultEndpoints(),
+		logger:      logger.WithContext("component", "strava_client", "user_id", userID),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.oauthConfig.Endpoint = c.endpoints.OAuthEndpoint()
+	// API requests go through the instrumented transport, on top of any transport of WithHTTPClient
+	c.httpClient = &http.Client{
+		Timeout:   c.httpClient.Timeout,
+		Transport: outbound.NewTransport(outbound.ProviderStrava, c.logger, c.httpClient.Transport),
+	}
+
+	c.logger.Debug("Strava client created",
+		"client_id", c.oauthConfig.ClientID,
+		"has_client_secret", c.oauthConfig.ClientSecret != "",
+		"has_access_token", c.accessToken != "",
+		"token_expiry", c.tokenExpiry)
+	return c
 }
 
-// SetOAuthCredentials configures the OAuth client credentials for token refresh
-// This should be called during client initialization with application credentials
-func (c *Client) SetOAuthCredentials(clientID, clientSecret string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	c.oauthConfig.ClientID = clientID
-	c.oauthConfig.ClientSecret = clientSecret
-	
-	c.logger.Debug("OAuth credentials configured for Strava client",
-		"client_id", clientID,
-		"has_client_secret", clientSecret != "")
+// log returns the client's logger with the attributes carried by ctx, such as the trace_id and
+// job_id of the job the call is made for
+func (c *Client) log(ctx context.Context) *logger.Logger {
+	return c.logger.ForContext(ctx)
 }
 
-// SetInitialTokens sets initial access token and expiry if available
-// This allows the client to use existing valid tokens before falling back to refresh
-func (c *Client) SetInitialTokens(accessToken string, expiry time.Time) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	c.accessToken = accessToken
-	c.tokenExpiry = expiry
-	
-	c.logger.Debug("Initial access token set for Strava client",
-		"has_access_token", accessToken != "",
-		"token_expiry", expiry,
-		"token_valid", time.Now().Before(expiry))
+// TokenRefresher performs token refreshes on behalf of clients, e.g. so concurrent jobs for the
+// same user share one refresh; refresh makes the OAuth call
+type TokenRefresher interface {
+	Refresh(ctx context.Context, provider string, userID int, refreshToken string, refresh func(context.Context) (*oauth2.Token, error)) (*oauth2.Token, error)
 }
 
 // ensureValidToken implements the "check-then-fetch" token management logic
@@ -112,48 +134,43 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	c.logger.Debug("Checking token validity before Strava API call",
+	c.log(ctx).Debug("Checking token validity before Strava API call",
 		"has_access_token", c.accessToken != "",
 		"token_expiry", c.tokenExpiry,
 		"time_until_expiry_minutes", time.Until(c.tokenExpiry).Minutes())
 	
 	// Check if current access token is valid (with 5-minute buffer)
 	if c.accessToken != "" && time.Now().Add(5*time.Minute).Before(c.tokenExpiry) {
-		c.logger.Debug("Using existing valid access token for Strava API call",
+		c.log(ctx).Debug("Using existing valid access token for Strava API call",
 			"token_expiry", c.tokenExpiry,
 			"minutes_until_expiry", time.Until(c.tokenExpiry).Minutes())
 		return nil
 	}
 	
 	// Need to refresh token
-	c.logger.Debug("Access token invalid or expired, refreshing via Strava OAuth",
+	c.log(ctx).Debug("Access token invalid or expired, refreshing via Strava OAuth",
 		"current_token_expired", c.accessToken != "" && time.Now().After(c.tokenExpiry),
 		"current_token_missing", c.accessToken == "",
 		"refresh_token_available", c.refreshToken != "")
 	
 	if c.refreshToken == "" {
-		c.logger.Error("No refresh token available for Strava token refresh",
+		c.log(ctx).Error("No refresh token available for Strava token refresh",
 			"user_id", c.userID)
 		return ErrReauthRequired
 	}
 	
 	// Call Strava OAuth token endpoint to refresh access token
 	startTime := time.Now()
-	c.logger.Debug("Making token refresh request to Strava OAuth endpoint",
+	c.log(ctx).Debug("Making token refresh request to Strava OAuth endpoint",
 		"endpoint", c.oauthConfig.Endpoint.TokenURL,
 		"user_id", c.userID)
 	
-	token := &oauth2.Token{
-		RefreshToken: c.refreshToken,
-	}
-	
-	tokenSource := c.oauthConfig.TokenSource(ctx, token)
-	newToken, err := tokenSource.Token()
+	newToken, err := c.refreshAccessToken(ctx)
 	
 	requestDuration := time.Since(startTime)
 	
 	if err != nil {
-		c.logger.Error("Failed to refresh Strava access token via OAuth endpoint",
+		c.log(ctx).Error("Failed to refresh Strava access token via OAuth endpoint",
 			"error", err,
 			"user_id", c.userID,
 			"request_duration_ms", requestDuration.Milliseconds(),
@@ -161,7 +178,7 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 		
 		// Check if this is an invalid grant error (requires re-authorization)
 		if IsReauthRequired(err) {
-			c.logger.Warn("Strava refresh token is invalid, user re-authorization required",
+			c.log(ctx).Warn("Strava refresh token is invalid, user re-authorization required",
 				"user_id", c.userID,
 				"error", err)
 			return &AuthError{
@@ -178,11 +195,22 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 		}
 	}
 	
-	// Update cached token
+	// Update cached token, adopting the refresh token if the provider rotated it
 	c.accessToken = newToken.AccessToken
 	c.tokenExpiry = newToken.Expiry
+	if newToken.RefreshToken != "" {
+		c.refreshToken = newToken.RefreshToken
+	}
+	
+	if c.tokenSaver != nil {
+		if err := c.tokenSaver.SaveStravaTokens(ctx, c.userID, c.accessToken, c.refreshToken, c.tokenExpiry); err != nil {
+			c.log(ctx).Warn("Failed to save refreshed Strava token",
+				"error", err,
+				"user_id", c.userID)
+		}
+	}
 	
-	c.logger.Info("Successfully refreshed Strava access token",
+	c.log(ctx).Info("Successfully refreshed Strava access token",
 		"user_id", c.userID,
 		"new_token_expiry", newToken.Expiry,
 		"token_valid_hours", time.Until(newToken.Expiry).Hours(),
@@ -191,19 +219,64 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 	return nil
 }
 
+// refreshAccessToken exchanges the refresh token for a new access token, through the token
+// refresher when one is set. The caller must hold c.mu.
+func (c *Client) refreshAccessToken(ctx context.Context) (*oauth2.Token, error) {
+	refreshToken := c.refreshToken
+	refresh := func(ctx context.Context) (*oauth2.Token, error) {
+		// The token endpoint is reached through the instrumented transport too
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, outbound.NewClient(outbound.ProviderStrava, c.logger, DefaultHTTPTimeout))
+		return c.oauthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	}
+	
+	if c.tokenRefresher == nil {
+		return refresh(ctx)
+	}
+	return c.tokenRefresher.Refresh(ctx, "strava", c.userID, refreshToken, refresh)
+}
+
 // makeAPIRequest performs an authenticated HTTP request to the Strava API
 // This method includes comprehensive logging for debugging external API interactions
 func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, result interface{}) error {
+	// A cancelled job neither refreshes the token nor spends its call budget
+	if err := ctx.Err(); err != nil {
+		return &NetworkError{
+			Operation: "api_request",
+			Message:   "Request cancelled before it was sent",
+			Cause:     err,
+		}
+	}
+	
 	// Ensure we have a valid access token
 	if err := c.ensureValidToken(ctx); err != nil {
 		return err
 	}
 	
+	if err := c.reserveCall(ctx); err != nil {
+		c.log(ctx).Warn("Skipping Strava API request - daily call budget used up",
+			"method", method,
+			"endpoint", endpoint,
+			"user_id", c.userID)
+		return err
+	}
+
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return &NetworkError{
+				Operation: "rate_limit_wait",
+				Message:   "Gave up waiting for the client rate limiter",
+				Cause:     err,
+			}
+		}
+	}
+
 	// Build full URL
-	url := "https://www.strava.com/api/v3" + endpoint
+	c.mu.RLock()
+	url := c.endpoints.APIURL(endpoint)
+	c.mu.RUnlock()
 	
 	startTime := time.Now()
-	c.logger.Debug("Making Strava API request",
+	c.log(ctx).Debug("Making Strava API request",
 		"method", method,
 		"endpoint", endpoint,
 		"full_url", url,
@@ -212,7 +285,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
-		c.logger.Error("Failed to create Strava API request",
+		c.log(ctx).Error("Failed to create Strava API request",
 			"error", err,
 			"method", method,
 			"endpoint", endpoint,
@@ -230,21 +303,19 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 	c.mu.RUnlock()
 	
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "Academy-Sync-Automation/1.0")
 	
 	// Execute request
-	c.logger.Debug("Executing HTTP request to Strava API",
+	c.log(ctx).Debug("Executing HTTP request to Strava API",
 		"method", method,
 		"url", url,
 		"headers", map[string]string{
-			"Accept":     req.Header.Get("Accept"),
-			"User-Agent": req.Header.Get("User-Agent"),
+			"Accept": req.Header.Get("Accept"),
 		})
 	
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		requestDuration := time.Since(startTime)
-		c.logger.Error("Strava API request failed with network error",
+		c.log(ctx).Error("Strava API request failed with network error",
 			"error", err,
 			"method", method,
 			"endpoint", endpoint,
@@ -260,7 +331,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 	
 	requestDuration := time.Since(startTime)
 	
-	c.logger.Debug("Received response from Strava API",
+	c.log(ctx).Debug("Received response from Strava API",
 		"method", method,
 		"endpoint", endpoint,
 		"status_code", resp.StatusCode,
@@ -275,7 +346,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 		// Try to read error details from response body
 		var errorDetails map[string]interface{}
 		if decodeErr := json.NewDecoder(resp.Body).Decode(&errorDetails); decodeErr == nil {
-			c.logger.Error("Strava API returned error response with details",
+			c.log(ctx).Error("Strava API returned error response with details",
 				"status_code", resp.StatusCode,
 				"status", resp.Status,
 				"error_details", errorDetails,
@@ -284,7 +355,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 				"user_id", c.userID,
 				"request_duration_ms", requestDuration.Milliseconds())
 		} else {
-			c.logger.Error("Strava API returned error response",
+			c.log(ctx).Error("Strava API returned error response",
 				"status_code", resp.StatusCode,
 				"status", resp.Status,
 				"method", method,
@@ -297,7 +368,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 		// Handle specific error codes
 		switch resp.StatusCode {
 		case 401:
-			c.logger.Warn("Strava API returned 401 Unauthorized, access token may be invalid",
+			c.log(ctx).Warn("Strava API returned 401 Unauthorized, access token may be invalid",
 				"user_id", c.userID,
 				"endpoint", endpoint)
 			return &AuthError{
@@ -314,6 +385,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 				StatusCode: resp.StatusCode,
 				Type:       "RATE_LIMITED",
 				Message:    "Strava API rate limit exceeded",
+				RetryDelay: retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 			}
 		default:
 			return &APIError{
@@ -324,9 +396,22 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 		}
 	}
 	
-	// Decode successful response
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		c.logger.Error("Failed to decode Strava API response",
+	// Decode successful response; activity responses are recorded as received first
+	var body io.Reader = resp.Body
+	if c.responseRecorder != nil && isActivityEndpoint(endpoint) {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return &NetworkError{
+				Operation: "api_request",
+				Message:   "Network error while reading API response",
+				Cause:     err,
+			}
+		}
+		c.responseRecorder.RecordResponse(endpoint, raw)
+		body = bytes.NewReader(raw)
+	}
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		c.log(ctx).Error("Failed to decode Strava API response",
 			"error", err,
 			"method", method,
 			"endpoint", endpoint,
@@ -342,7 +427,7 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 		}
 	}
 	
-	c.logger.Info("Successfully completed Strava API request",
+	c.log(ctx).Info("Successfully completed Strava API request",
 		"method", method,
 		"endpoint", endpoint,
 		"status_code", resp.StatusCode,
@@ -352,10 +437,15 @@ func (c *Client) makeAPIRequest(ctx context.Context, method, endpoint string, re
 	return nil
 }
 
+// isActivityEndpoint reports whether endpoint lists activities or reads a single activity
+func isActivityEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "/athlete/activities") || strings.HasPrefix(endpoint, "/activities/")
+}
+
 // GetActivities retrieves activities from Strava after a specified time
 // This implements the core functionality needed for automation processing
 func (c *Client) GetActivities(ctx context.Context, after time.Time) ([]Activity, error) {
-	c.logger.Debug("Retrieving activities from Strava",
+	c.log(ctx).Debug("Retrieving activities from Strava",
 		"user_id", c.userID,
 		"after", after.Format(time.RFC3339),
 		"days_back", time.Since(after).Hours()/24)
@@ -366,14 +456,14 @@ func (c *Client) GetActivities(ctx context.Context, after time.Time) ([]Activity
 	
 	var activities []Activity
 	if err := c.makeAPIRequest(ctx, "GET", endpoint, &activities); err != nil {
-		c.logger.Error("Failed to retrieve activities from Strava",
+		c.log(ctx).Error("Failed to retrieve activities from Strava",
 			"error", err,
 			"user_id", c.userID,
 			"after", after.Format(time.RFC3339))
 		return nil, err
 	}
 	
-	c.logger.Info("Successfully retrieved activities from Strava",
+	c.log(ctx).Info("Successfully retrieved activities from Strava",
 		"user_id", c.userID,
 		"activity_count", len(activities),
 		"after", after.Format(time.RFC3339),
@@ -387,9 +477,80 @@ func (c *Client) GetActivities(ctx context.Context, after time.Time) ([]Activity
 	return activities, nil
 }
 
+// maxActivityPages bounds paginated activity listing (100 activities per page)
+const maxActivityPages = 50
+
+// maxStreamPages bounds streamed activity listing; memory does not grow with pages, so the
+// limit only guards against a provider that never returns a short page
+const maxStreamPages = 1000
+
+// activitiesPerPage is the page size used for paginated activity listing
+const activitiesPerPage = 100
+
+// GetActivitiesInRange retrieves all activities started between after and before, following pagination
+// Activities are returned oldest first, the order Strava uses when an after bound is set. Ranges
+// holding more than maxActivityPages pages fail with ErrTooManyActivities rather than being cut short.
+func (c *Client) GetActivitiesInRange(ctx context.Context, after, before time.Time) ([]Activity, error) {
+	c.log(ctx).Debug("Retrieving activity range from Strava",
+		"user_id", c.userID,
+		"after", after.Format(time.RFC3339),
+		"before", before.Format(time.RFC3339))
+	
+	var activities []Activity
+	err := c.forEachActivityPage(ctx, after, before, maxActivityPages, func(page []Activity) error {
+		activities = append(activities, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	c.log(ctx).Info("Successfully retrieved activity range from Strava",
+		"user_id", c.userID,
+		"activity_count", len(activities))
+	
+	return activities, nil
+}
+
+// ForEachActivityPage streams activities started between after and before one page at a time,
+// oldest first, so large ranges (e.g. backfills) can be processed without holding them all in memory
+// The page slice is not reused after fn returns; an error from fn stops the iteration
+func (c *Client) ForEachActivityPage(ctx context.Context, after, before time.Time, fn func(page []Activity) error) error {
+	return c.forEachActivityPage(ctx, after, before, maxStreamPages, fn)
+}
+
+func (c *Client) forEachActivityPage(ctx context.Context, after, before time.Time, maxPages int, fn func(page []Activity) error) error {
+	for page := 1; page <= maxPages; page++ {
+		endpoint := fmt.Sprintf("/athlete/activities?after=%d&before=%d&per_page=%d&page=%d",
+			after.Unix(), before.Unix(), activitiesPerPage, page)
+		
+		var pageActivities []Activity
+		if err := c.makeAPIRequest(ctx, "GET", endpoint, &pageActivities); err != nil {
+			c.log(ctx).Error("Failed to retrieve activity range from Strava",
+				"error", err,
+				"user_id", c.userID,
+				"page", page)
+			return err
+		}
+		
+		if len(pageActivities) > 0 {
+			if err := fn(pageActivities); err != nil {
+				return err
+			}
+		}
+		if len(pageActivities) < activitiesPerPage {
+			return nil
+		}
+	}
+	
+	// Every page was full, so Strava may hold activities past the limit
+	return fmt.Errorf("%w: more than %d activities between %s and %s", ErrTooManyActivities,
+		maxPages*activitiesPerPage, after.Format(time.RFC3339), before.Format(time.RFC3339))
+}
+
 // GetActivity retrieves a specific activity by ID from Strava
 func (c *Client) GetActivity(ctx context.Context, activityID int64) (*Activity, error) {
-	c.logger.Debug("Retrieving specific activity from Strava",
+	c.log(ctx).Debug("Retrieving specific activity from Strava",
 		"user_id", c.userID,
 		"activity_id", activityID)
 	
@@ -397,14 +558,14 @@ func (c *Client) GetActivity(ctx context.Context, activityID int64) (*Activity,
 	
 	var activity Activity
 	if err := c.makeAPIRequest(ctx, "GET", endpoint, &activity); err != nil {
-		c.logger.Error("Failed to retrieve activity from Strava",
+		c.log(ctx).Error("Failed to retrieve activity from Strava",
 			"error", err,
 			"user_id", c.userID,
 			"activity_id", activityID)
 		return nil, err
 	}
 	
-	c.logger.Info("Successfully retrieved activity from Strava",
+	c.log(ctx).Info("Successfully retrieved activity from Strava",
 		"user_id", c.userID,
 		"activity_id", activityID,
 		"activity_name", activity.Name,
@@ -416,12 +577,22 @@ func (c *Client) GetActivity(ctx context.Context, activityID int64) (*Activity,
 
 // GetAthleteProfile retrieves the authenticated athlete's profile information
 func (c *Client) GetAthleteProfile(ctx context.Context) (map[string]interface{}, error) {
-	c.logger.Debug("Retrieving athlete profile from Strava",
+	c.log(ctx).Debug("Retrieving athlete profile from Strava",
 		"user_id", c.userID)
 	
+	c.mu.RLock()
+	cache, cacheKey := c.responseCache, respcache.AthleteProfileKey(c.userID, c.refreshToken)
+	c.mu.RUnlock()
+	
 	var profile map[string]interface{}
+	if cache.Get(ctx, cacheKey, &profile) {
+		c.log(ctx).Debug("Serving athlete profile from response cache",
+			"user_id", c.userID)
+		return profile, nil
+	}
+	
 	if err := c.makeAPIRequest(ctx, "GET", "/athlete", &profile); err != nil {
-		c.logger.Error("Failed to retrieve athlete profile from Strava",
+		c.log(ctx).Error("Failed to retrieve athlete profile from Strava",
 			"error", err,
 			"user_id", c.userID)
 		return nil, err
@@ -433,10 +604,29 @@ func (c *Client) GetAthleteProfile(ctx context.Context) (map[string]interface{},
 		athleteID = fmt.Sprintf("%v", id)
 	}
 	
-	c.logger.Info("Successfully retrieved athlete profile from Strava",
+	c.log(ctx).Info("Successfully retrieved athlete profile from Strava",
 		"user_id", c.userID,
 		"athlete_id", athleteID,
 		"profile_fields", len(profile))
 	
+	cache.Set(ctx, cacheKey, profile)
 	return profile, nil
-}
\ No newline at end of file
+}
+
+// CheckActivityReadAccess verifies that the token grants the activity:read_all scope
+// Strava does not expose granted scopes directly, so this lists a single activity and
+// relies on the API rejecting tokens without activity access
+func (c *Client) CheckActivityReadAccess(ctx context.Context) error {
+	c.log(ctx).Debug("Checking Strava activity read scope",
+		"user_id", c.userID)
+	
+	var activities []Activity
+	if err := c.makeAPIRequest(ctx, "GET", "/athlete/activities?per_page=1", &activities); err != nil {
+		c.log(ctx).Warn("Strava activity read scope check failed",
+			"error", err,
+			"user_id", c.userID)
+		return err
+	}
+	
+	return nil
+}
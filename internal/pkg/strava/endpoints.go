@@ -0,0 +1,60 @@
+package strava
+
+import (
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Production Strava endpoints
+const (
+	DefaultAPIBaseURL   = "https://www.strava.com/api/v3"
+	DefaultOAuthBaseURL = "https://www.strava.com/oauth"
+)
+
+// Endpoints are the base URLs the Strava client talks to. Staging environments point them at
+// a mock server, corporate proxy or sandbox instead of production Strava.
+type Endpoints struct {
+	APIBaseURL   string // REST API, e.g. https://www.strava.com/api/v3
+	OAuthBaseURL string // Serves /authorize, /token and /deauthorize
+}
+
+// DefaultEndpoints returns the production Strava endpoints
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		APIBaseURL:   DefaultAPIBaseURL,
+		OAuthBaseURL: DefaultOAuthBaseURL,
+	}
+}
+
+// withDefaults fills empty base URLs with the production endpoints and drops trailing slashes
+func (e Endpoints) withDefaults() Endpoints {
+	if e.APIBaseURL == "" {
+		e.APIBaseURL = DefaultAPIBaseURL
+	}
+	if e.OAuthBaseURL == "" {
+		e.OAuthBaseURL = DefaultOAuthBaseURL
+	}
+	e.APIBaseURL = strings.TrimRight(e.APIBaseURL, "/")
+	e.OAuthBaseURL = strings.TrimRight(e.OAuthBaseURL, "/")
+	return e
+}
+
+// OAuthEndpoint returns the authorization and token URLs
+func (e Endpoints) OAuthEndpoint() oauth2.Endpoint {
+	e = e.withDefaults()
+	return oauth2.Endpoint{
+		AuthURL:  e.OAuthBaseURL + "/authorize",
+		TokenURL: e.OAuthBaseURL + "/token",
+	}
+}
+
+// DeauthorizeURL returns the endpoint that revokes the app's access to an athlete
+func (e Endpoints) DeauthorizeURL() string {
+	return e.withDefaults().OAuthBaseURL + "/deauthorize"
+}
+
+// APIURL returns the URL of an API path such as /athlete
+func (e Endpoints) APIURL(path string) string {
+	return e.withDefaults().APIBaseURL + path
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ErrReauthRequired is returned when the refresh token is invalid and user re-authorization is needed
@@ -13,6 +14,22 @@ var ErrReauthRequired = &AuthError{
 	Message: "Strava connection requires re-authorization",
 }
 
+// ErrTooManyActivities is returned when an activity range spans more pages than a listing allows;
+// a shorter range has to be requested
+var ErrTooManyActivities error = &rangeLimitError{}
+
+// rangeLimitError is the type of ErrTooManyActivities
+type rangeLimitError struct{}
+
+func (e *rangeLimitError) Error() string {
+	return "too many activities in range"
+}
+
+// Permanent reports that fetching the same range again cannot succeed, so retry.DefaultClassifier aborts
+func (e *rangeLimitError) Permanent() bool {
+	return true
+}
+
 // AuthError represents authentication-related errors in Strava API interactions
 type AuthError struct {
 	Type    string
@@ -31,6 +48,11 @@ func (e *AuthError) Unwrap() error {
 	return e.Cause
 }
 
+// Permanent reports that retrying with the same credentials cannot succeed, so retry.DefaultClassifier aborts
+func (e *AuthError) Permanent() bool {
+	return true
+}
+
 // IsReauthRequired checks if an error indicates that user re-authorization is required
 func IsReauthRequired(err error) bool {
 	if err == nil {
@@ -62,6 +84,9 @@ type APIError struct {
 	Message    string
 	Type       string
 	Cause      error
+
+	// RetryDelay is the Retry-After of a rate-limited response; zero when none was given
+	RetryDelay time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -77,6 +102,11 @@ func (e *APIError) Unwrap() error {
 	return e.Cause
 }
 
+// RetryAfter returns how long to wait before retrying, read by retry.DefaultClassifier
+func (e *APIError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
 // NetworkError represents network-related errors during API calls
 type NetworkError struct {
 	Operation string
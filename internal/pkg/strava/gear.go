@@ -0,0 +1,38 @@
+package strava
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Gear is a pair of shoes or a bike the athlete records activities with
+type Gear struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	BrandName string  `json:"brand_name"`
+	ModelName string  `json:"model_name"`
+	Distance  float64 `json:"distance"` // meters, over every activity recorded with the gear
+	Retired   bool    `json:"retired"`
+}
+
+// GetGear retrieves a piece of the athlete's gear by ID
+func (c *Client) GetGear(ctx context.Context, gearID string) (*Gear, error) {
+	c.log(ctx).Debug("Retrieving gear from Strava",
+		"user_id", c.userID,
+		"gear_id", gearID)
+
+	var gear Gear
+	if err := c.makeAPIRequest(ctx, "GET", "/gear/"+url.PathEscape(gearID), &gear); err != nil {
+		c.log(ctx).Error("Failed to retrieve gear from Strava",
+			"error", err,
+			"user_id", c.userID,
+			"gear_id", gearID)
+		return nil, err
+	}
+	if gear.ID == "" {
+		return nil, fmt.Errorf("strava returned no gear for %s", gearID)
+	}
+
+	return &gear, nil
+}
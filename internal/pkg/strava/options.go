@@ -0,0 +1,122 @@
+package strava
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/respcache"
+)
+
+// DefaultHTTPTimeout bounds each API request unless WithHTTPClient supplies another client
+const DefaultHTTPTimeout = 30 * time.Second
+
+// Option configures a Client at construction; a client is not reconfigured afterwards
+type Option func(*Client)
+
+// RateLimiter paces API calls; Wait blocks until the next call may be made. *rate.Limiter from
+// golang.org/x/time/rate satisfies it.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithEndpoints points the client at alternate Strava base URLs, such as the devstub server or
+// a mock server in staging; empty URLs keep the production endpoints
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(c *Client) {
+		c.endpoints = endpoints.withDefaults()
+	}
+}
+
+// WithBaseURL points API requests at an alternate REST API base URL, keeping the OAuth endpoints
+func WithBaseURL(apiBaseURL string) Option {
+	return func(c *Client) {
+		c.endpoints.APIBaseURL = apiBaseURL
+		c.endpoints = c.endpoints.withDefaults()
+	}
+}
+
+// WithOAuthCredentials sets the application's OAuth client credentials used for token refresh
+func WithOAuthCredentials(clientID, clientSecret string) Option {
+	return func(c *Client) {
+		c.oauthConfig.ClientID = clientID
+		c.oauthConfig.ClientSecret = clientSecret
+	}
+}
+
+// WithInitialToken seeds the client with a stored access token, used until shortly before expiry
+// instead of refreshing on the first call
+func WithInitialToken(accessToken string, expiry time.Time) Option {
+	return func(c *Client) {
+		c.accessToken = accessToken
+		c.tokenExpiry = expiry
+	}
+}
+
+// WithHTTPClient makes API requests with httpClient, e.g. to count them in its transport or change
+// the timeout; token refreshes are not affected
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRateLimiter waits on limiter before every API call; cancelling the call's context stops the wait
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithTokenRefresher routes the client's token refreshes through refresher
+func WithTokenRefresher(refresher TokenRefresher) Option {
+	return func(c *Client) {
+		c.tokenRefresher = refresher
+	}
+}
+
+// TokenSaver stores the tokens a client refreshed, so the next client starts from them instead
+// of refreshing again and a rotated refresh token is not lost
+type TokenSaver interface {
+	SaveStravaTokens(ctx context.Context, userID int, accessToken, refreshToken string, expiry time.Time) error
+}
+
+// WithTokenSaver saves every token the client refreshes through saver. Save failures are logged
+// and the call goes ahead with the refreshed token.
+func WithTokenSaver(saver TokenSaver) Option {
+	return func(c *Client) {
+		c.tokenSaver = saver
+	}
+}
+
+// WithResponseCache serves the athlete profile from cache while it is fresh. Entries are keyed by
+// the refresh token, so reconnecting Strava fetches the profile again.
+func WithResponseCache(cache *respcache.Cache) Option {
+	return func(c *Client) {
+		c.responseCache = cache
+	}
+}
+
+// WithCallBudget caps the user's Strava API calls per UTC day at dailyLimit, counted by counter.
+// Calls beyond it fail with *BudgetExceededError without reaching Strava. Token refreshes are not
+// counted. Counter failures are logged and the call is made. A zero limit disables the budget.
+func WithCallBudget(counter CallCounter, dailyLimit int) Option {
+	return func(c *Client) {
+		c.callCounter = counter
+		c.dailyCallLimit = dailyLimit
+	}
+}
+
+// ResponseRecorder receives the raw body of each successful activity response (see
+// WithResponseRecorder)
+type ResponseRecorder interface {
+	RecordResponse(endpoint string, body []byte)
+}
+
+// WithResponseRecorder hands the body of every successful activity list or detail response to
+// recorder exactly as Strava sent it, before it is decoded, so a sync can be replayed later
+func WithResponseRecorder(recorder ResponseRecorder) Option {
+	return func(c *Client) {
+		c.responseRecorder = recorder
+	}
+}
@@ -0,0 +1,68 @@
+package strava
+
+import (
+	"context"
+	"fmt"
+)
+
+// ActivityTotals is a Strava aggregate for one sport over one period
+type ActivityTotals struct {
+	Count         int     `json:"count"`
+	Distance      float64 `json:"distance"`       // meters
+	MovingTime    int     `json:"moving_time"`    // seconds
+	ElapsedTime   int     `json:"elapsed_time"`   // seconds
+	ElevationGain float64 `json:"elevation_gain"` // meters
+}
+
+// AthleteStats are the athlete's activity totals as reported by Strava
+// Strava only counts activities with "Everyone" visibility, so the totals are a lower bound
+type AthleteStats struct {
+	RecentRunTotals  ActivityTotals `json:"recent_run_totals"` // Last four weeks
+	RecentRideTotals ActivityTotals `json:"recent_ride_totals"`
+	RecentSwimTotals ActivityTotals `json:"recent_swim_totals"`
+	YTDRunTotals     ActivityTotals `json:"ytd_run_totals"`
+	YTDRideTotals    ActivityTotals `json:"ytd_ride_totals"`
+	YTDSwimTotals    ActivityTotals `json:"ytd_swim_totals"`
+	AllRunTotals     ActivityTotals `json:"all_run_totals"`
+	AllRideTotals    ActivityTotals `json:"all_ride_totals"`
+	AllSwimTotals    ActivityTotals `json:"all_swim_totals"`
+}
+
+// Sports covered by athlete stats
+const (
+	StatsSportRun  = "run"
+	StatsSportRide = "ride"
+	StatsSportSwim = "swim"
+)
+
+// StatsSport maps an activity type to the athlete stats sport that counts it, or "" when none does
+func StatsSport(activityType string) string {
+	switch activityType {
+	case "Run", "VirtualRun":
+		return StatsSportRun
+	case "Ride", "VirtualRide", "EBikeRide":
+		return StatsSportRide
+	case "Swim":
+		return StatsSportSwim
+	default:
+		return ""
+	}
+}
+
+// GetAthleteStats retrieves the recent, year-to-date and all-time totals for the athlete
+func (c *Client) GetAthleteStats(ctx context.Context, athleteID int64) (*AthleteStats, error) {
+	c.log(ctx).Debug("Retrieving athlete stats from Strava",
+		"user_id", c.userID,
+		"athlete_id", athleteID)
+
+	var stats AthleteStats
+	if err := c.makeAPIRequest(ctx, "GET", fmt.Sprintf("/athletes/%d/stats", athleteID), &stats); err != nil {
+		c.log(ctx).Error("Failed to retrieve athlete stats from Strava",
+			"error", err,
+			"user_id", c.userID,
+			"athlete_id", athleteID)
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,268 @@
+// Package templates defines the catalog of supported spreadsheet templates and the column
+// layout the automation engine writes for each of them.
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/format"
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+// Field identifies the activity value written to a template column
+type Field string
+
+// Fields the engine knows how to fill
+const (
+	FieldDate       Field = "date"
+	FieldName       Field = "name"
+	FieldType       Field = "type"
+	FieldDiscipline Field = "discipline"
+	FieldDistance   Field = "distance"
+	FieldDuration   Field = "duration"
+	FieldPace       Field = "pace"
+	FieldSpeed      Field = "speed"
+	FieldElevation  Field = "elevation"
+	FieldHeartRate  Field = "heart_rate"
+	FieldKudos      Field = "kudos"
+	FieldActivityID Field = "activity_id"
+
+	// FieldGear is the name of the shoes or bike the activity was recorded with. It is not part of
+	// any catalog template; users opt in and it is appended with WithGear.
+	FieldGear Field = "gear"
+
+	// FieldManual columns belong to the user (e.g. coach comments); the engine never overwrites them
+	FieldManual Field = "manual"
+)
+
+// Template IDs in the catalog
+const (
+	BasicLog  = "basic_log"
+	CoachPlan = "coach_plan"
+	Triathlon = "triathlon"
+
+	// DefaultTemplateID is used for users who configured a spreadsheet without choosing a template
+	DefaultTemplateID = BasicLog
+)
+
+// Column is a single column of a template's activity sheet
+type Column struct {
+	Header string `json:"header"`
+	Field  Field  `json:"field"`
+}
+
+// Template describes a supported spreadsheet layout
+type Template struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Columns     []Column `json:"columns"`
+}
+
+var catalog = map[string]*Template{
+	BasicLog: {
+		ID:          BasicLog,
+		Name:        "Basic training log",
+		Description: "One row per activity with distance, time, pace, elevation and heart rate",
+		Columns: []Column{
+			{"Date", FieldDate},
+			{"Name", FieldName},
+			{"Type", FieldType},
+			{"Distance", FieldDistance},
+			{"Duration", FieldDuration},
+			{"Pace", FieldPace},
+			{"Elevation Gain", FieldElevation},
+			{"Avg Heart Rate", FieldHeartRate},
+			{"Kudos", FieldKudos},
+			{"Activity ID", FieldActivityID},
+		},
+	},
+	CoachPlan: {
+		ID:          CoachPlan,
+		Name:        "Coach plan",
+		Description: "Completed activities next to the planned session, with columns for athlete and coach notes",
+		Columns: []Column{
+			{"Date", FieldDate},
+			{"Planned Session", FieldManual},
+			{"Name", FieldName},
+			{"Type", FieldType},
+			{"Distance", FieldDistance},
+			{"Duration", FieldDuration},
+			{"Pace", FieldPace},
+			{"Avg Heart Rate", FieldHeartRate},
+			{"Athlete Notes", FieldManual},
+			{"Coach Comments", FieldManual},
+			{"Activity ID", FieldActivityID},
+		},
+	},
+	Triathlon: {
+		ID:          Triathlon,
+		Name:        "Triathlon log",
+		Description: "Swim, bike and run activities with the pace or speed unit suited to each discipline",
+		Columns: []Column{
+			{"Date", FieldDate},
+			{"Discipline", FieldDiscipline},
+			{"Name", FieldName},
+			{"Distance", FieldDistance},
+			{"Duration", FieldDuration},
+			{"Pace / Speed", FieldSpeed},
+			{"Elevation Gain", FieldElevation},
+			{"Avg Heart Rate", FieldHeartRate},
+			{"Activity ID", FieldActivityID},
+		},
+	},
+}
+
+// Get returns the template with the given ID
+func Get(id string) (*Template, bool) {
+	template, ok := catalog[id]
+	return template, ok
+}
+
+// GetOrDefault returns the template with the given ID, falling back to the default template
+func GetOrDefault(id string) *Template {
+	if template, ok := catalog[id]; ok {
+		return template
+	}
+	return catalog[DefaultTemplateID]
+}
+
+// All returns every template in the catalog ordered by ID
+func All() []*Template {
+	templates := make([]*Template, 0, len(catalog))
+	for _, template := range catalog {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates
+}
+
+// ColumnIndex returns the position of the first column holding field, or -1
+func (t *Template) ColumnIndex(field Field) int {
+	for i, column := range t.Columns {
+		if column.Field == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// WithGear returns a copy of the template with a Gear column appended, unless it already has one.
+// Appending keeps the existing columns of sheets already written with the template in place.
+func (t *Template) WithGear() *Template {
+	if t.ColumnIndex(FieldGear) >= 0 {
+		return t
+	}
+	withGear := *t
+	withGear.Columns = append(append([]Column{}, t.Columns...), Column{"Gear", FieldGear})
+	return &withGear
+}
+
+// IsManual reports whether the column at index is owned by the user
+func (t *Template) IsManual(index int) bool {
+	return index >= 0 && index < len(t.Columns) && t.Columns[index].Field == FieldManual
+}
+
+// LastColumn returns the A1 letter of the template's last column
+func (t *Template) LastColumn() string {
+	return ColumnLetter(len(t.Columns) - 1)
+}
+
+// Header returns the header row for the activity sheet
+func (t *Template) Header() []interface{} {
+	header := make([]interface{}, len(t.Columns))
+	for i, column := range t.Columns {
+		header[i] = column.Header
+	}
+	return header
+}
+
+// Row converts an activity into a sheet row; manual columns are left empty
+func (t *Template) Row(activity strava.Activity) []interface{} {
+	row := make([]interface{}, len(t.Columns))
+	for i, column := range t.Columns {
+		row[i] = FormatField(column.Field, activity)
+	}
+	return row
+}
+
+// FormatField renders a single activity field the way it is written to the sheet
+func FormatField(field Field, activity strava.Activity) interface{} {
+	switch field {
+	case FieldDate:
+		return activity.StartDateLocal.Format("2006-01-02")
+	case FieldName:
+		return activity.Name
+	case FieldType:
+		return activity.Type
+	case FieldDiscipline:
+		return discipline(activity)
+	case FieldDistance:
+		return format.Default.Distance(activity.Distance)
+	case FieldDuration:
+		return format.Duration(activity.MovingTime)
+	case FieldPace:
+		if activity.Type == "Run" {
+			return format.Default.Pace(activity.MovingTime, activity.Distance)
+		}
+		return ""
+	case FieldSpeed:
+		return speed(activity)
+	case FieldElevation:
+		return format.Default.Elevation(activity.TotalElevationGain)
+	case FieldHeartRate:
+		return format.Default.HeartRate(activity.AverageHeartrate)
+	case FieldKudos:
+		return activity.Kudos
+	case FieldActivityID:
+		// Stored as text so large IDs are not reformatted as numbers
+		return fmt.Sprintf("'%d", activity.ID)
+	case FieldGear:
+		return activity.GearName
+	default:
+		return ""
+	}
+}
+
+// discipline maps Strava activity types onto triathlon disciplines
+func discipline(activity strava.Activity) string {
+	activityType := activity.SportType
+	if activityType == "" {
+		activityType = activity.Type
+	}
+
+	switch {
+	case strings.Contains(activityType, "Swim"):
+		return "Swim"
+	case strings.Contains(activityType, "Ride"):
+		return "Bike"
+	case strings.Contains(activityType, "Run"):
+		return "Run"
+	default:
+		return "Other"
+	}
+}
+
+// speed renders the unit conventional for each discipline: /100m for swims, km/h for rides, /km otherwise
+func speed(activity strava.Activity) string {
+	switch discipline(activity) {
+	case "Swim":
+		return format.Default.SwimPace(activity.MovingTime, activity.Distance)
+	case "Bike":
+		return format.Default.Speed(activity.MovingTime, activity.Distance)
+	default:
+		return format.Default.Pace(activity.MovingTime, activity.Distance)
+	}
+}
+
+// ColumnLetter converts a zero-based column index into its A1 letter (0 -> A, 26 -> AA)
+func ColumnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/strava"
+)
+
+func TestCatalogTemplatesHaveActivityIDColumn(t *testing.T) {
+	for _, template := range All() {
+		if template.ColumnIndex(FieldActivityID) < 0 {
+			t.Errorf("Template %s has no activity ID column", template.ID)
+		}
+		if template.ColumnIndex(FieldDate) < 0 || template.ColumnIndex(FieldName) < 0 {
+			t.Errorf("Template %s needs date and name columns to match legacy rows", template.ID)
+		}
+	}
+}
+
+func TestGetOrDefault(t *testing.T) {
+	if GetOrDefault("").ID != DefaultTemplateID {
+		t.Error("Expected empty template ID to fall back to the default template")
+	}
+	if GetOrDefault("unknown").ID != DefaultTemplateID {
+		t.Error("Expected unknown template ID to fall back to the default template")
+	}
+	if GetOrDefault(CoachPlan).ID != CoachPlan {
+		t.Error("Expected coach plan template")
+	}
+}
+
+func TestTemplateRow(t *testing.T) {
+	run := strava.Activity{ID: 123, Name: "Tempo", Type: "Run", Distance: 10000, MovingTime: 3000,
+		AverageHeartrate: 160, StartDateLocal: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)}
+
+	basic := GetOrDefault(BasicLog).Row(run)
+	expected := []interface{}{"2024-06-03", "Tempo", "Run", "10.00 km", "00:50:00", "5:00 /km", "0 m", "160 bpm", 0, "'123"}
+	for i := range expected {
+		if basic[i] != expected[i] {
+			t.Errorf("Basic log column %d: expected %v, got %v", i, expected[i], basic[i])
+		}
+	}
+
+	coach := GetOrDefault(CoachPlan)
+	row := coach.Row(run)
+	for i := range row {
+		if coach.IsManual(i) && row[i] != "" {
+			t.Errorf("Expected manual column %d to be empty, got %v", i, row[i])
+		}
+	}
+}
+
+func TestWithGear(t *testing.T) {
+	basic := GetOrDefault(BasicLog)
+	withGear := basic.WithGear()
+
+	if basic.ColumnIndex(FieldGear) >= 0 {
+		t.Fatal("Expected WithGear to leave the catalog template unchanged")
+	}
+	if withGear.ColumnIndex(FieldGear) != len(basic.Columns) || withGear.LastColumn() != ColumnLetter(len(basic.Columns)) {
+		t.Fatalf("Expected the Gear column after the template columns, got %+v", withGear.Columns)
+	}
+	if withGear.WithGear() != withGear {
+		t.Error("Expected WithGear to add the Gear column only once")
+	}
+
+	row := withGear.Row(strava.Activity{ID: 1, GearName: "Daily Trainers"})
+	if row[len(row)-1] != "Daily Trainers" {
+		t.Errorf("Expected the gear name in the last column, got %v", row[len(row)-1])
+	}
+}
+
+func TestTriathlonSpeed(t *testing.T) {
+	triathlon := GetOrDefault(Triathlon)
+	speedColumn := triathlon.ColumnIndex(FieldSpeed)
+	disciplineColumn := triathlon.ColumnIndex(FieldDiscipline)
+
+	tests := []struct {
+		activity           strava.Activity
+		expectedDiscipline string
+		expectedSpeed      string
+	}{
+		{strava.Activity{Type: "Swim", Distance: 1500, MovingTime: 1800}, "Swim", "2:00 /100m"},
+		{strava.Activity{Type: "Ride", SportType: "VirtualRide", Distance: 40000, MovingTime: 3600}, "Bike", "40.0 km/h"},
+		{strava.Activity{Type: "Run", SportType: "TrailRun", Distance: 10000, MovingTime: 3600}, "Run", "6:00 /km"},
+	}
+
+	for _, tt := range tests {
+		row := triathlon.Row(tt.activity)
+		if row[disciplineColumn] != tt.expectedDiscipline || row[speedColumn] != tt.expectedSpeed {
+			t.Errorf("Expected %s %s, got %v %v", tt.expectedDiscipline, tt.expectedSpeed, row[disciplineColumn], row[speedColumn])
+		}
+	}
+}
+
+func TestColumnLetter(t *testing.T) {
+	tests := map[int]string{0: "A", 9: "J", 10: "K", 25: "Z", 26: "AA", 27: "AB"}
+	for index, expected := range tests {
+		if got := ColumnLetter(index); got != expected {
+			t.Errorf("ColumnLetter(%d): expected %s, got %s", index, expected, got)
+		}
+	}
+}
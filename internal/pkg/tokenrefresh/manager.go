@@ -0,0 +1,250 @@
+// Package tokenrefresh makes concurrent OAuth token refreshes for the same user share a single
+// call to the provider, within a process and across processes. Providers such as Strava rotate
+// the refresh token on every refresh, so two jobs refreshing independently (a manual and a
+// scheduled sync, say) would invalidate each other's refresh tokens.
+package tokenrefresh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+const (
+	// DefaultLockTTL bounds a refresh; waiters stop waiting for a lock holder after this long
+	DefaultLockTTL = 30 * time.Second
+
+	// DefaultPollInterval is how often waiters check for the lock holder's result
+	DefaultPollInterval = 200 * time.Millisecond
+
+	// expiryBuffer matches the clients, which refresh tokens expiring within five minutes
+	expiryBuffer = 5 * time.Minute
+
+	// releaseTimeout bounds releasing a lock after the refresh's context has ended
+	releaseTimeout = 5 * time.Second
+)
+
+// Store holds the locks and shared results of refreshes in progress across processes
+type Store interface {
+	AcquireTokenRefreshLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	ReleaseTokenRefreshLock(ctx context.Context, key, owner string) error
+	GetRefreshedToken(ctx context.Context, key string) ([]byte, error)
+	StoreRefreshedToken(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Cipher encrypts shared results, which hold live tokens
+type Cipher interface {
+	Encrypt(plaintext string) ([]byte, error)
+	Decrypt(ciphertext []byte) (string, error)
+}
+
+// Manager runs token refreshes single-flight per provider, user and refresh token. Callers in the
+// same process wait on the first caller's refresh; other processes wait on the holder of a lock
+// in the Store and then reuse the result it shared. Store failures are logged and the refresh
+// runs uncoordinated rather than failing the job.
+type Manager struct {
+	store        Store
+	cipher       Cipher
+	owner        string
+	lockTTL      time.Duration
+	pollInterval time.Duration
+	logger       *logger.Logger
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a refresh in progress in this process
+type call struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+// NewManager creates a manager sharing refreshes through store, with results encrypted by cipher
+func NewManager(store Store, cipher Cipher, logger *logger.Logger) *Manager {
+	return &Manager{
+		store:        store,
+		cipher:       cipher,
+		owner:        uuid.New().String(),
+		lockTTL:      DefaultLockTTL,
+		pollInterval: DefaultPollInterval,
+		logger:       logger.WithContext("component", "token_refresh"),
+		calls:        make(map[string]*call),
+	}
+}
+
+// Refresh returns a token refreshed from refreshToken, calling refresh (the OAuth call) only if no concurrent
+// caller is refreshing the same token and no result of an earlier refresh is still valid.
+// provider and userID scope the refresh; the refresh token itself is never stored.
+func (m *Manager) Refresh(ctx context.Context, provider string, userID int, refreshToken string, refresh func(context.Context) (*oauth2.Token, error)) (*oauth2.Token, error) {
+	key := refreshKey(provider, userID, refreshToken)
+
+	m.mu.Lock()
+	if c, ok := m.calls[key]; ok {
+		m.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.token, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &call{done: make(chan struct{})}
+	m.calls[key] = c
+	m.mu.Unlock()
+
+	c.token, c.err = m.refreshShared(ctx, key, provider, userID, refresh)
+
+	m.mu.Lock()
+	delete(m.calls, key)
+	m.mu.Unlock()
+	close(c.done)
+
+	return c.token, c.err
+}
+
+// refreshShared reuses another process's result or takes the lock and refreshes. A lock holder
+// that fails releases the lock without a result, so the next waiter refreshes in its place.
+func (m *Manager) refreshShared(ctx context.Context, key, provider string, userID int, refresh func(context.Context) (*oauth2.Token, error)) (*oauth2.Token, error) {
+	deadline := time.Now().Add(m.lockTTL)
+	for {
+		if token := m.shared(ctx, key); token != nil {
+			m.logger.Debug("Reusing token refreshed by a concurrent job",
+				"provider", provider,
+				"user_id", userID)
+			return token, nil
+		}
+
+		acquired, err := m.store.AcquireTokenRefreshLock(ctx, key, m.owner, m.lockTTL)
+		if err != nil {
+			m.logger.Warn("Failed to take token refresh lock, refreshing without it",
+				"provider", provider,
+				"user_id", userID,
+				"error", err)
+			return refresh(ctx)
+		}
+		if acquired {
+			return m.refreshLocked(ctx, key, provider, userID, refresh)
+		}
+
+		if time.Now().After(deadline) {
+			m.logger.Warn("Timed out waiting for a concurrent token refresh, refreshing without the lock",
+				"provider", provider,
+				"user_id", userID,
+				"waited", m.lockTTL.String())
+			return refresh(ctx)
+		}
+
+		select {
+		case <-time.After(m.pollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// refreshLocked refreshes while holding the lock and shares the result
+func (m *Manager) refreshLocked(ctx context.Context, key, provider string, userID int, refresh func(context.Context) (*oauth2.Token, error)) (*oauth2.Token, error) {
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+		defer cancel()
+		if err := m.store.ReleaseTokenRefreshLock(releaseCtx, key, m.owner); err != nil {
+			m.logger.Warn("Failed to release token refresh lock",
+				"provider", provider,
+				"user_id", userID,
+				"error", err)
+		}
+	}()
+
+	// The previous holder may have shared its result after this caller last looked
+	if token := m.shared(ctx, key); token != nil {
+		return token, nil
+	}
+
+	token, err := refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.share(ctx, key, provider, userID, token)
+	return token, nil
+}
+
+// sharedToken is the stored form of a refresh result
+type sharedToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// shared returns the result shared under key while it is still valid; unreadable results are ignored
+func (m *Manager) shared(ctx context.Context, key string) *oauth2.Token {
+	sealed, err := m.store.GetRefreshedToken(ctx, key)
+	if err != nil || sealed == nil {
+		return nil
+	}
+	plaintext, err := m.cipher.Decrypt(sealed)
+	if err != nil {
+		m.logger.Warn("Failed to decrypt shared token refresh result", "error", err)
+		return nil
+	}
+
+	var stored sharedToken
+	if err := json.Unmarshal([]byte(plaintext), &stored); err != nil {
+		return nil
+	}
+	if time.Now().Add(expiryBuffer).After(stored.Expiry) {
+		return nil
+	}
+	return &oauth2.Token{
+		AccessToken:  stored.AccessToken,
+		TokenType:    stored.TokenType,
+		RefreshToken: stored.RefreshToken,
+		Expiry:       stored.Expiry,
+	}
+}
+
+// share stores token under key until it is due for refresh again
+func (m *Manager) share(ctx context.Context, key, provider string, userID int, token *oauth2.Token) {
+	ttl := time.Until(token.Expiry) - expiryBuffer
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(sharedToken{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	})
+	if err != nil {
+		return
+	}
+	sealed, err := m.cipher.Encrypt(string(data))
+	if err == nil {
+		err = m.store.StoreRefreshedToken(ctx, key, sealed, ttl)
+	}
+	if err != nil {
+		m.logger.Warn("Failed to share refreshed token",
+			"provider", provider,
+			"user_id", userID,
+			"error", err)
+	}
+}
+
+// refreshKey scopes a refresh to the provider, user and refresh token, so a user who reconnects
+// never receives a result refreshed from their previous credentials
+func refreshKey(provider string, userID int, refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return provider + ":" + strconv.Itoa(userID) + ":" + hex.EncodeToString(sum[:16])
+}
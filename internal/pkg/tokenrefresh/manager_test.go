@@ -0,0 +1,184 @@
+package tokenrefresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Perseverance/the-academy-sync-claude/internal/pkg/logger"
+)
+
+// fakeStore is an in-memory Store shared by the managers of several "processes"
+type fakeStore struct {
+	mu      sync.Mutex
+	locks   map[string]string
+	results map[string][]byte
+	err     error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{locks: make(map[string]string), results: make(map[string][]byte)}
+}
+
+func (s *fakeStore) AcquireTokenRefreshLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, held := s.locks[key]; held {
+		return false, nil
+	}
+	s.locks[key] = owner
+	return true, nil
+}
+
+func (s *fakeStore) ReleaseTokenRefreshLock(ctx context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[key] == owner {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+func (s *fakeStore) GetRefreshedToken(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results[key], s.err
+}
+
+func (s *fakeStore) StoreRefreshedToken(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = value
+	return nil
+}
+
+// plainCipher leaves values as they are
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext string) ([]byte, error)  { return []byte(plaintext), nil }
+func (plainCipher) Decrypt(ciphertext []byte) (string, error) { return string(ciphertext), nil }
+
+func newTestManager(store Store) *Manager {
+	m := NewManager(store, plainCipher{}, logger.New("test"))
+	m.pollInterval = time.Millisecond
+	return m
+}
+
+// countingRefresh returns a refresh that counts its calls and blocks until release is closed
+func countingRefresh(calls *atomic.Int32, release <-chan struct{}) func(context.Context) (*oauth2.Token, error) {
+	return func(ctx context.Context) (*oauth2.Token, error) {
+		calls.Add(1)
+		<-release
+		return &oauth2.Token{AccessToken: "access-2", RefreshToken: "refresh-2", Expiry: time.Now().Add(6 * time.Hour)}, nil
+	}
+}
+
+func TestManager_SharesRefreshAcrossCallersAndProcesses(t *testing.T) {
+	store := newFakeStore()
+	processes := []*Manager{newTestManager(store), newTestManager(store)}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	refresh := countingRefresh(&calls, release)
+
+	var wg sync.WaitGroup
+	tokens := make([]*oauth2.Token, 6)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := processes[i%2].Refresh(context.Background(), "strava", 7, "refresh-1", refresh)
+			if err != nil {
+				t.Errorf("Refresh %d failed: %v", i, err)
+			}
+			tokens[i] = token
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single refresh, got %d", calls.Load())
+	}
+	for i, token := range tokens {
+		if token == nil || token.AccessToken != "access-2" || token.RefreshToken != "refresh-2" {
+			t.Errorf("Expected caller %d to receive the shared token, got %+v", i, token)
+		}
+	}
+
+	// A later job with the same credentials reuses the result while it is valid
+	if _, err := processes[0].Refresh(context.Background(), "strava", 7, "refresh-1", refresh); err != nil || calls.Load() != 1 {
+		t.Errorf("Expected the shared result to be reused, got %d refreshes (%v)", calls.Load(), err)
+	}
+
+	// Reconnected credentials are refreshed on their own
+	if _, err := processes[0].Refresh(context.Background(), "strava", 7, "refresh-new", refresh); err != nil || calls.Load() != 2 {
+		t.Errorf("Expected a new refresh for new credentials, got %d refreshes (%v)", calls.Load(), err)
+	}
+}
+
+func TestManager_WaiterRefreshesAfterHolderFails(t *testing.T) {
+	store := newFakeStore()
+	holder, waiter := newTestManager(store), newTestManager(store)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	failing := func(ctx context.Context) (*oauth2.Token, error) {
+		close(started)
+		<-release
+		return nil, errors.New("provider unavailable")
+	}
+
+	holderErr := make(chan error, 1)
+	go func() {
+		_, err := holder.Refresh(context.Background(), "google", 7, "refresh-1", failing)
+		holderErr <- err
+	}()
+	<-started
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	close(done)
+	waiterToken := make(chan *oauth2.Token, 1)
+	go func() {
+		token, _ := waiter.Refresh(context.Background(), "google", 7, "refresh-1", countingRefresh(&calls, done))
+		waiterToken <- token
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Fatal("Expected the waiter not to refresh while the lock is held")
+	}
+	close(release)
+
+	if err := <-holderErr; err == nil {
+		t.Error("Expected the holder's refresh error")
+	}
+	if token := <-waiterToken; token == nil || calls.Load() != 1 {
+		t.Errorf("Expected the waiter to refresh once the lock was released, got %+v after %d refreshes", token, calls.Load())
+	}
+}
+
+func TestManager_RefreshesWithoutStore(t *testing.T) {
+	store := newFakeStore()
+	store.err = errors.New("redis down")
+	manager := newTestManager(store)
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	close(done)
+	token, err := manager.Refresh(context.Background(), "strava", 7, "refresh-1", countingRefresh(&calls, done))
+	if err != nil || token == nil || calls.Load() != 1 {
+		t.Errorf("Expected an uncoordinated refresh when the store fails, got %+v, %v", token, err)
+	}
+}
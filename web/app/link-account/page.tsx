@@ -0,0 +1,79 @@
+"use client"
+
+import { useEffect, useState } from "react"
+import { Loader2 } from "lucide-react"
+
+import { AcademyLogo } from "@/components/icons/academy-logo"
+import { authService, type AccountLink } from "@/services/auth"
+
+type LinkState = "loading" | "idle" | "linking" | "expired" | "failed"
+
+// Reached from the Google sign-in when a Google account new to Academy Sync has the email of an
+// existing account. The accounts are only linked once the user confirms.
+export default function LinkAccountRoute() {
+  const [link, setLink] = useState<AccountLink | null>(null)
+  const [state, setState] = useState<LinkState>("loading")
+
+  useEffect(() => {
+    authService
+      .getAccountLink()
+      .then((pending) => {
+        setLink(pending)
+        setState(pending ? "idle" : "expired")
+      })
+      .catch((error) => {
+        console.error("Error loading account link:", error)
+        setState("failed")
+      })
+  }, [])
+
+  const confirm = async () => {
+    setState("linking")
+    try {
+      if (await authService.confirmAccountLink()) {
+        window.location.href = "/dashboard"
+        return
+      }
+      setState("expired")
+    } catch (error) {
+      console.error("Error linking account:", error)
+      setState("failed")
+    }
+  }
+
+  const cancel = async () => {
+    await authService.cancelAccountLink()
+    window.location.href = "/"
+  }
+
+  return (
+    <div className="min-h-screen flex flex-col items-center justify-center bg-background p-6 text-center">
+      <AcademyLogo className="w-16 h-16 mb-6" />
+      <h1 className="text-3xl font-brand font-bold text-primary mb-4">Link your Google account</h1>
+      {state === "loading" && <Loader2 className="h-8 w-8 animate-spin text-muted-foreground" />}
+      {state === "expired" && (
+        <p className="text-lg text-muted-foreground max-w-xl">
+          This request has expired. Sign in with Google again to link your account.
+        </p>
+      )}
+      {link && (state === "idle" || state === "linking" || state === "failed") && (
+        <>
+          <p className="text-lg text-muted-foreground max-w-xl mb-8">
+            You already have an Academy Sync account for {link.email}. Link the Google account {link.google_email} to
+            it so either one signs you in. Your Strava connection, spreadsheet and history stay as they are.
+          </p>
+          {state === "failed" && <p className="text-destructive mb-4">Something went wrong, please try again.</p>}
+          <div className="flex gap-4">
+            <button onClick={confirm} className="btn-primary-main text-lg px-8 py-3" disabled={state === "linking"}>
+              {state === "linking" && <Loader2 className="h-5 w-5 animate-spin" />}
+              Link accounts
+            </button>
+            <button onClick={cancel} className="text-lg px-8 py-3 text-muted-foreground" disabled={state === "linking"}>
+              Cancel
+            </button>
+          </div>
+        </>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,67 @@
+"use client"
+
+import { Suspense, useState } from "react"
+import { useSearchParams } from "next/navigation"
+import { Loader2 } from "lucide-react"
+
+import { AcademyLogo } from "@/components/icons/academy-logo"
+import { authService } from "@/services/auth"
+
+export const dynamic = 'force-dynamic'
+
+type RevokeState = "idle" | "revoking" | "revoked" | "invalid" | "failed"
+
+// Linked from the new sign-in email. The session is only signed out when the user confirms,
+// so mail scanners that open links do not sign anything out.
+function RevokeSession() {
+  const token = useSearchParams().get("token") ?? ""
+  const [state, setState] = useState<RevokeState>(token ? "idle" : "invalid")
+
+  const revoke = async () => {
+    setState("revoking")
+    try {
+      setState((await authService.revokeSessionFromEmail(token)) ? "revoked" : "invalid")
+    } catch (error) {
+      console.error("Error signing out session:", error)
+      setState("failed")
+    }
+  }
+
+  return (
+    <div className="min-h-screen flex flex-col items-center justify-center bg-background p-6 text-center">
+      <AcademyLogo className="w-16 h-16 mb-6" />
+      <h1 className="text-3xl font-brand font-bold text-primary mb-4">Sign out a session</h1>
+      {state === "revoked" && (
+        <p className="text-lg text-muted-foreground max-w-xl">
+          The session has been signed out. If you didn&apos;t sign in, secure your Google account, as Academy Sync uses
+          it to sign you in.
+        </p>
+      )}
+      {state === "invalid" && (
+        <p className="text-lg text-muted-foreground max-w-xl">
+          This link has expired or was already used. Sign in and sign out from every device if you are still concerned.
+        </p>
+      )}
+      {(state === "idle" || state === "revoking" || state === "failed") && (
+        <>
+          <p className="text-lg text-muted-foreground max-w-xl mb-8">
+            Sign out the session from the new sign-in we emailed you about. Your other sessions stay signed in.
+          </p>
+          {state === "failed" && <p className="text-destructive mb-4">Something went wrong, please try again.</p>}
+          <button onClick={revoke} className="btn-primary-main text-lg px-8 py-3" disabled={state === "revoking"}>
+            {state === "revoking" && <Loader2 className="h-5 w-5 animate-spin" />}
+            Sign out this session
+          </button>
+        </>
+      )}
+    </div>
+  )
+}
+
+export default function RevokeSessionRoute() {
+  return (
+    <Suspense>
+      <RevokeSession />
+    </Suspense>
+  )
+}
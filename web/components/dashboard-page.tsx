@@ -1,5 +1,6 @@
 "use client"
 
+import { useEffect, useState } from "react"
 import { useAppState } from "@/context/app-state-provider"
 import { ConnectionCard } from "@/components/connection-card"
 import { SpreadsheetCard } from "@/components/spreadsheet-card"
@@ -19,10 +20,27 @@ import {
   DropdownMenuTrigger,
 } from "@/components/ui/dropdown-menu"
 import { Button } from "@/components/ui/button" // shadcn button for dropdown trigger
+import { Alert, AlertDescription, AlertTitle } from "@/components/ui/alert"
 import { AcademyLogo } from "./icons/academy-logo"
 
+// Set by the Strava callback when the athlete is already connected to another account
+interface StravaConflict {
+  athleteID: string
+  athleteName: string
+}
+
 export function DashboardPage() {
   const { state, actions } = useAppState()
+  const [stravaConflict, setStravaConflict] = useState<StravaConflict | null>(null)
+
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search)
+    if (params.get("strava_error") !== "STRAVA_ATHLETE_CONFLICT") {
+      return
+    }
+    setStravaConflict({ athleteID: params.get("athlete_id") ?? "", athleteName: params.get("athlete_name") ?? "" })
+    window.history.replaceState(null, "", window.location.pathname)
+  }, [])
 
   if (!state.user) {
     // This should be handled by AppStateProvider redirect, but as a fallback
@@ -73,6 +91,15 @@ export function DashboardPage() {
       <main className="flex-1 bg-background p-4 sm:p-6 lg:p-8">
         <div className="max-w-5xl mx-auto">
           <h2 className="text-3xl font-brand font-bold text-primary mb-6">Configuration Dashboard</h2>
+          {stravaConflict && (
+            <Alert variant="destructive" className="mb-6">
+              <AlertTitle>Strava account already connected</AlertTitle>
+              <AlertDescription>
+                The Strava athlete {stravaConflict.athleteName || stravaConflict.athleteID} is connected to another
+                Academy Sync account. Disconnect it there first, or connect a different Strava account.
+              </AlertDescription>
+            </Alert>
+          )}
           <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
             {/* Google Connection Card */}
             <ConnectionCard
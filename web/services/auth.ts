@@ -21,12 +21,32 @@ export interface User {
   has_strava_connection: boolean
   has_sheets_connection: boolean
   recent_activity_logs: ActivityLog[]
+  last_run: LastRun | null
+  next_scheduled_run_at: string | null
+  strava_reauth_required: boolean
+  google_reauth_required: boolean
+}
+
+export interface LastRun {
+  status: 'running' | 'completed' | 'failed' | 'deferred'
+  trigger_type: string
+  activities_count: number
+  error_type?: string
+  started_at: string
+  completed_at?: string
 }
 
 export interface AuthResponse {
   auth_url: string
 }
 
+export interface AccountLink {
+  email: string
+  google_email: string
+  google_name: string
+  expires_at: string
+}
+
 export interface AuthError {
   error: string
   message: string
@@ -34,6 +54,7 @@ export interface AuthError {
 
 class AuthService {
   private readonly baseURL: string
+  private refreshInFlight: Promise<boolean> | null = null
 
   constructor() {
     // Use environment variable for API URL, fallback to localhost
@@ -45,7 +66,7 @@ class AuthService {
    */
   async getGoogleAuthURL(): Promise<string> {
     try {
-      const response = await fetch(`${this.baseURL}/api/auth/google`, {
+      const response = await fetch(`${this.baseURL}/api/v1/auth/google`, {
         method: 'GET',
         credentials: 'include', // Include cookies for session management
       })
@@ -67,9 +88,8 @@ class AuthService {
    */
   async getCurrentUser(): Promise<User | null> {
     try {
-      const response = await fetch(`${this.baseURL}/api/auth/me`, {
+      const response = await fetchWithRefresh(`${this.baseURL}/api/v1/auth/me`, {
         method: 'GET',
-        credentials: 'include', // Include cookies for session
       })
 
       if (response.status === 401) {
@@ -104,8 +124,18 @@ class AuthService {
    * Refresh the authentication token
    */
   async refreshToken(): Promise<boolean> {
+    // Refresh tokens rotate on every use, so concurrent callers share one refresh
+    if (!this.refreshInFlight) {
+      this.refreshInFlight = this.requestRefresh().finally(() => {
+        this.refreshInFlight = null
+      })
+    }
+    return this.refreshInFlight
+  }
+
+  private async requestRefresh(): Promise<boolean> {
     try {
-      const response = await fetch(`${this.baseURL}/api/auth/refresh`, {
+      const response = await fetch(`${this.baseURL}/api/v1/auth/refresh`, {
         method: 'POST',
         credentials: 'include',
       })
@@ -122,7 +152,7 @@ class AuthService {
    */
   async signOut(): Promise<void> {
     try {
-      await fetch(`${this.baseURL}/api/auth/logout`, {
+      await fetch(`${this.baseURL}/api/v1/auth/logout`, {
         method: 'POST',
         credentials: 'include',
       })
@@ -132,6 +162,74 @@ class AuthService {
     }
   }
 
+  /**
+   * Sign out the session named by the token in a new sign-in email's link.
+   * Resolves to false when the link has expired or was already used.
+   */
+  async revokeSessionFromEmail(token: string): Promise<boolean> {
+    const response = await fetch(`${this.baseURL}/api/v1/auth/sessions/revoke`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ token }),
+    })
+
+    if (response.status === 404) {
+      return false
+    }
+    if (!response.ok) {
+      throw new Error(`Failed to sign out session: ${response.status}`)
+    }
+    return true
+  }
+
+  /**
+   * Get the pending link of a second Google account to the existing account with its email.
+   * Resolves to null when no link is pending or it has expired.
+   */
+  async getAccountLink(): Promise<AccountLink | null> {
+    const response = await fetch(`${this.baseURL}/api/v1/auth/link`, {
+      method: 'GET',
+      credentials: 'include',
+    })
+
+    if (response.status === 404) {
+      return null
+    }
+    if (!response.ok) {
+      throw new Error(`Failed to get account link: ${response.status}`)
+    }
+    return response.json()
+  }
+
+  /**
+   * Link the pending Google account and sign in.
+   * Resolves to false when the link has expired.
+   */
+  async confirmAccountLink(): Promise<boolean> {
+    const response = await fetch(`${this.baseURL}/api/v1/auth/link`, {
+      method: 'POST',
+      credentials: 'include',
+    })
+
+    if (response.status === 404) {
+      return false
+    }
+    if (!response.ok) {
+      throw new Error(`Failed to link account: ${response.status}`)
+    }
+    return true
+  }
+
+  /**
+   * Discard the pending account link
+   */
+  async cancelAccountLink(): Promise<void> {
+    await fetch(`${this.baseURL}/api/v1/auth/link`, {
+      method: 'DELETE',
+      credentials: 'include',
+    })
+  }
+
   /**
    * Initiate Google OAuth flow
    * This redirects the user to Google's consent screen
@@ -147,5 +245,23 @@ class AuthService {
 // Create singleton instance
 export const authService = new AuthService()
 
+/**
+ * Fetch an API endpoint with the session cookies. Access tokens are short-lived; when the API
+ * reports one expired, the refresh token is exchanged for a new one and the request retried once.
+ */
+export async function fetchWithRefresh(url: string, init: RequestInit = {}): Promise<Response> {
+  const request: RequestInit = { ...init, credentials: 'include' }
+  const response = await fetch(url, request)
+  if (response.status !== 401) {
+    return response
+  }
+
+  const body = await response.clone().json().catch(() => null)
+  if (body?.error?.code !== 'TOKEN_EXPIRED' || !(await authService.refreshToken())) {
+    return response
+  }
+  return fetch(url, request)
+}
+
 // Export default instance
 export default authService
\ No newline at end of file
@@ -1,4 +1,5 @@
 // Configuration service for handling spreadsheet configuration and other user settings
+import { fetchWithRefresh } from './auth'
 
 export interface SetSpreadsheetRequest {
   url: string
@@ -10,9 +11,12 @@ export interface SetSpreadsheetResponse {
 }
 
 export interface ConfigError {
-  error: string
-  message: string
-  type?: string
+  error: {
+    code: string
+    message: string
+    details?: { type?: string }
+    request_id?: string
+  }
 }
 
 export class ConfigService {
@@ -31,9 +35,9 @@ export class ConfigService {
     try {
       const errorData: ConfigError = await response.json()
       throw new ConfigApiError(
-        errorData.error || 'UNKNOWN_ERROR',
-        errorData.message || `Failed to ${operation}`,
-        errorData.type,
+        errorData.error?.code || 'UNKNOWN_ERROR',
+        errorData.error?.message || `Failed to ${operation}`,
+        errorData.error?.details?.type,
         response.status
       )
     } catch (parseError) {
@@ -84,12 +88,11 @@ export class ConfigService {
    */
   async setSpreadsheetUrl(url: string): Promise<void> {
     try {
-      const response = await fetch(`${this.baseURL}/api/config/spreadsheet`, {
+      const response = await fetchWithRefresh(`${this.baseURL}/api/v1/config/spreadsheet`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
         },
-        credentials: 'include', // Include cookies for authentication
         body: JSON.stringify({ url }),
       })
 
@@ -109,9 +112,8 @@ export class ConfigService {
    */
   async clearSpreadsheetUrl(): Promise<void> {
     try {
-      const response = await fetch(`${this.baseURL}/api/config/spreadsheet`, {
+      const response = await fetchWithRefresh(`${this.baseURL}/api/v1/config/spreadsheet`, {
         method: 'DELETE',
-        credentials: 'include', // Include cookies for authentication
       })
 
       if (!response.ok) {
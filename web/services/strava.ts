@@ -1,4 +1,6 @@
 // Strava connection service for handling OAuth flow and connection management
+import { fetchWithRefresh } from './auth'
+
 export interface StravaAuthResponse {
   auth_url: string
 }
@@ -21,9 +23,8 @@ class StravaService {
    */
   async initiateStravaConnection(): Promise<void> {
     try {
-      const response = await fetch(`${this.baseURL}/api/connections/strava`, {
+      const response = await fetchWithRefresh(`${this.baseURL}/api/v1/connections/strava`, {
         method: 'GET',
-        credentials: 'include', // Include cookies for session management
       })
 
       if (!response.ok) {
@@ -45,9 +46,8 @@ class StravaService {
    */
   async disconnectStrava(): Promise<void> {
     try {
-      const response = await fetch(`${this.baseURL}/api/connections/strava`, {
+      const response = await fetchWithRefresh(`${this.baseURL}/api/v1/connections/strava`, {
         method: 'DELETE',
-        credentials: 'include', // Include cookies for session
       })
 
       if (!response.ok) {